@@ -7,19 +7,28 @@ import (
 	"chalk-api/pkg/handlers"
 	"chalk-api/pkg/middleware"
 	"chalk-api/pkg/repositories"
+	"chalk-api/pkg/seeds"
 	"chalk-api/pkg/server"
 	"chalk-api/pkg/services"
+	"chalk-api/pkg/stores"
+	"chalk-api/pkg/tracing"
 	"chalk-api/pkg/workers"
+	"context"
+	"flag"
 	"fmt"
 	"log/slog"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 )
 
 func main() {
-	// Setup Logging
-	slog.SetDefault(middleware.SetupLogger(os.Stdout))
+	seedCatalog := flag.Bool("seed", false, "import the bundled exercise and food catalog, then exit")
+	flag.Parse()
+
+	// Setup Logging; redaction defaults on until config says otherwise
+	slog.SetDefault(middleware.SetupLogger(os.Stdout, middleware.RedactOptions{}))
 
 	// Load Config
 	cfg, err := config.LoadConfig()
@@ -27,6 +36,26 @@ func main() {
 		slog.Error("Failed to load config", "err", err)
 		os.Exit(1)
 	}
+	slog.SetDefault(middleware.SetupLogger(os.Stdout, middleware.RedactOptionsFromConfig(cfg)))
+
+	// Initialize tracing before anything instrumented; a no-op without an OTLP endpoint
+	shutdownTracing, err := tracing.Init(context.Background(), tracing.Options{
+		Endpoint:    cfg.OTelExporterEndpoint,
+		ServiceName: cfg.OTelServiceName,
+		Version:     config.DeployVersion,
+		SampleRatio: cfg.OTelSampleRatio,
+	})
+	if err != nil {
+		slog.Error("Failed to initialize tracing", "error", err)
+		os.Exit(1)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			slog.Error("Failed to flush traces", "error", err)
+		}
+	}()
 
 	// Initialize database (returns GORM DB)
 	gormDB, err := db.InitializeDatabase(cfg)
@@ -50,18 +79,35 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Initialize cache stores (fail-open when Redis is unavailable)
+	storesCollection, err := stores.InitializeStores(cfg)
+	if err != nil {
+		slog.Error("Failed to initialize stores", "error", err)
+		os.Exit(1)
+	}
+	defer storesCollection.Close()
+
+	// One-off catalog import; safe to re-run since rows are upserted by external_id
+	if *seedCatalog {
+		if _, err := seeds.InitializeSeedData(context.Background(), repositoriesCollection, storesCollection); err != nil {
+			slog.Error("Failed to seed catalog", "error", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	// Initialize external integrations
 	externalCollection := external.Initialize(cfg)
 
 	// Initialize Services
-	servicesCollection, err := services.InitializeServices(repositoriesCollection, externalCollection, cfg)
+	servicesCollection, err := services.InitializeServices(repositoriesCollection, externalCollection, storesCollection, cfg)
 	if err != nil {
 		slog.Error("Failed to initialize services", "err", err)
 		os.Exit(1)
 	}
 
 	// Initialize Workers (outbox processor, background tasks)
-	workersCollection, err := workers.InitializeWorkers(cfg, repositoriesCollection, externalCollection)
+	workersCollection, err := workers.InitializeWorkers(cfg, repositoriesCollection, externalCollection, servicesCollection)
 	if err != nil {
 		slog.Error("Failed to initialize workers", "error", err)
 		os.Exit(1)
@@ -70,7 +116,7 @@ func main() {
 	defer workersCollection.StopAll()
 
 	// Initialize Handlers
-	handlersCollection, err := handlers.InitializeHandlers(servicesCollection, repositoriesCollection, cfg)
+	handlersCollection, err := handlers.InitializeHandlers(servicesCollection, repositoriesCollection, externalCollection, cfg)
 	if err != nil {
 		slog.Error("Failed to initialize handlers", "error", err)
 		os.Exit(1)
@@ -0,0 +1,193 @@
+// Package calendar renders iCalendar (RFC 5545) files that apps can hand to the OS calendar.
+package calendar
+
+import (
+	"chalk-api/pkg/models"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ContentType is the media type for .ics responses.
+const ContentType = "text/calendar; charset=utf-8"
+
+const (
+	prodID = "-//Chalk//Chalk API//EN"
+
+	// Content lines longer than this many octets must be folded (RFC 5545 section 3.1)
+	maxLineOctets = 75
+
+	utcLayout  = "20060102T150405Z"
+	dateLayout = "20060102"
+)
+
+// Event statuses (RFC 5545 section 3.8.1.11)
+const (
+	StatusTentative = "TENTATIVE"
+	StatusConfirmed = "CONFIRMED"
+	StatusCancelled = "CANCELLED"
+)
+
+// Event is one VEVENT. Times are written in UTC; all-day events use only the dates of Start and
+// End, with End exclusive.
+type Event struct {
+	UID         string
+	Summary     string
+	Description string
+	Location    string
+	Start       time.Time
+	End         time.Time
+	AllDay      bool
+	Status      string // Status*; empty leaves it out
+}
+
+// UID builds a stable event UID from the kind of record and its ID, so re-imports update the
+// existing calendar entry instead of adding a second one.
+func UID(kind string, id uint) string {
+	return fmt.Sprintf("%s-%d@chalk", kind, id)
+}
+
+// Encode renders the events as a VCALENDAR. stamp is written as every event's DTSTAMP.
+func Encode(events []Event, stamp time.Time) string {
+	var b strings.Builder
+	writeLine(&b, "BEGIN:VCALENDAR")
+	writeLine(&b, "VERSION:2.0")
+	writeLine(&b, "PRODID:"+prodID)
+	writeLine(&b, "CALSCALE:GREGORIAN")
+	writeLine(&b, "METHOD:PUBLISH")
+	for i := range events {
+		writeEvent(&b, &events[i], stamp)
+	}
+	writeLine(&b, "END:VCALENDAR")
+	return b.String()
+}
+
+// FormatUTC formats t as an RFC 5545 UTC date-time, e.g. 20260115T093000Z.
+func FormatUTC(t time.Time) string {
+	return t.UTC().Format(utcLayout)
+}
+
+// SessionStatus maps a session status to the event status calendars understand.
+func SessionStatus(status string) string {
+	switch status {
+	case models.SessionStatusPendingConfirmation:
+		return StatusTentative
+	case "cancelled":
+		return StatusCancelled
+	default:
+		return StatusConfirmed
+	}
+}
+
+// SessionEvent describes a session from one side of it. viewerUserID picks the counterpart named in
+// the summary: the coach sees the client (group sessions just show the type), clients see the coach.
+// The session needs Coach.User.Profile, Client.User.Profile and SessionType loaded.
+func SessionEvent(session *models.Session, viewerUserID uint) Event {
+	summary := session.SessionType.Name
+	if summary == "" {
+		summary = "Session"
+	}
+
+	var with string
+	if session.Coach.UserID == viewerUserID {
+		if session.ParticipantCount <= 1 && session.Client.User.Profile != nil {
+			with = strings.TrimSpace(session.Client.User.Profile.FirstName + " " + session.Client.User.Profile.LastName)
+		}
+	} else {
+		if session.Coach.BusinessName != nil {
+			with = strings.TrimSpace(*session.Coach.BusinessName)
+		}
+		if with == "" && session.Coach.User.Profile != nil {
+			with = strings.TrimSpace(session.Coach.User.Profile.FirstName + " " + session.Coach.User.Profile.LastName)
+		}
+	}
+	if with != "" {
+		summary += " with " + with
+	}
+
+	event := Event{
+		UID:     UID("session", session.ID),
+		Summary: summary,
+		Start:   session.ScheduledAt,
+		End:     session.ScheduledAt.Add(time.Duration(session.DurationMinutes) * time.Minute),
+		Status:  SessionStatus(session.Status),
+	}
+	if session.Location != nil {
+		event.Location = *session.Location
+	}
+	if session.Notes != nil {
+		event.Description = *session.Notes
+	}
+	return event
+}
+
+func writeEvent(b *strings.Builder, event *Event, stamp time.Time) {
+	writeLine(b, "BEGIN:VEVENT")
+	writeLine(b, "UID:"+escapeText(event.UID))
+	writeLine(b, "DTSTAMP:"+FormatUTC(stamp))
+	if event.AllDay {
+		writeLine(b, "DTSTART;VALUE=DATE:"+event.Start.Format(dateLayout))
+		end := event.End
+		if !end.After(event.Start) {
+			end = event.Start.AddDate(0, 0, 1)
+		}
+		writeLine(b, "DTEND;VALUE=DATE:"+end.Format(dateLayout))
+	} else {
+		writeLine(b, "DTSTART:"+FormatUTC(event.Start))
+		writeLine(b, "DTEND:"+FormatUTC(event.End))
+	}
+	writeLine(b, "SUMMARY:"+escapeText(event.Summary))
+	if event.Description != "" {
+		writeLine(b, "DESCRIPTION:"+escapeText(event.Description))
+	}
+	if event.Location != "" {
+		writeLine(b, "LOCATION:"+escapeText(event.Location))
+	}
+	if event.Status != "" {
+		writeLine(b, "STATUS:"+event.Status)
+	}
+	writeLine(b, "END:VEVENT")
+}
+
+// writeLine writes one content line, folded, with the CRLF terminator RFC 5545 requires.
+func writeLine(b *strings.Builder, line string) {
+	b.WriteString(foldLine(line))
+	b.WriteString("\r\n")
+}
+
+// escapeText escapes a TEXT value (RFC 5545 section 3.3.11).
+func escapeText(s string) string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	s = strings.ReplaceAll(s, "\r", "\n")
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\n", `\n`,
+	).Replace(s)
+}
+
+// foldLine splits a content line into chunks of at most 75 octets, each continuation starting with
+// a single space. Multi-byte characters are never split across chunks.
+func foldLine(line string) string {
+	if len(line) <= maxLineOctets {
+		return line
+	}
+
+	var b strings.Builder
+	limit := maxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		// The leading space counts toward the continuation line's 75 octets
+		limit = maxLineOctets - 1
+	}
+	b.WriteString(line)
+	return b.String()
+}
@@ -0,0 +1,123 @@
+package calendar
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+// unfold reverses RFC 5545 line folding: a CRLF followed by a single space joins the lines.
+func unfold(s string) string {
+	return strings.ReplaceAll(s, "\r\n ", "")
+}
+
+func TestFoldLineKeepsLinesWithinLimit(t *testing.T) {
+	tests := map[string]string{
+		"short":       "SUMMARY:Leg day",
+		"exactly 75":  "SUMMARY:" + strings.Repeat("a", 75-len("SUMMARY:")),
+		"ascii long":  "DESCRIPTION:" + strings.Repeat("abcdefghij", 20),
+		"multi-byte":  "DESCRIPTION:" + strings.Repeat("é", 80),
+		"mixed width": "LOCATION:" + strings.Repeat("a€😀", 30),
+	}
+	for name, line := range tests {
+		t.Run(name, func(t *testing.T) {
+			folded := foldLine(line)
+			for i, part := range strings.Split(folded, "\r\n") {
+				if len(part) > maxLineOctets {
+					t.Errorf("line %d is %d octets, want at most %d", i, len(part), maxLineOctets)
+				}
+				if i > 0 && (!strings.HasPrefix(part, " ") || strings.HasPrefix(part, "  ")) {
+					t.Errorf("continuation line %d = %q, want exactly one leading space", i, part)
+				}
+				if !utf8.ValidString(part) {
+					t.Errorf("line %d splits a multi-byte character: %q", i, part)
+				}
+			}
+			if got := unfold(folded); got != line {
+				t.Errorf("unfolded = %q, want %q", got, line)
+			}
+		})
+	}
+}
+
+func TestFoldLineLeavesShortLinesAlone(t *testing.T) {
+	line := "SUMMARY:" + strings.Repeat("a", 75-len("SUMMARY:"))
+	if got := foldLine(line); got != line {
+		t.Fatalf("a 75-octet line was folded: %q", got)
+	}
+	if got := foldLine(line + "b"); got != line+"\r\n b" {
+		t.Fatalf("a 76-octet line folded to %q, want the last octet on a continuation line", got)
+	}
+}
+
+func TestFormatUTC(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("tzdata unavailable: %v", err)
+	}
+	tests := []struct {
+		name string
+		in   time.Time
+		want string
+	}{
+		{"utc", time.Date(2026, 1, 15, 9, 30, 0, 0, time.UTC), "20260115T093000Z"},
+		{"converted from local", time.Date(2026, 1, 15, 9, 30, 0, 0, newYork), "20260115T143000Z"},
+		{"crosses midnight", time.Date(2026, 7, 31, 22, 15, 5, 0, newYork), "20260801T021505Z"},
+		{"drops sub-seconds", time.Date(2026, 3, 1, 8, 0, 0, 999_000_000, time.UTC), "20260301T080000Z"},
+	}
+	for _, tt := range tests {
+		if got := FormatUTC(tt.in); got != tt.want {
+			t.Errorf("%s: FormatUTC = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestEncodeWritesUTCTimesAndCRLF(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("tzdata unavailable: %v", err)
+	}
+	start := time.Date(2026, 1, 15, 9, 0, 0, 0, newYork)
+	ics := Encode([]Event{{
+		UID:         UID("session", 7),
+		Summary:     "Strength, conditioning; mobility",
+		Description: "Bring bands\n" + strings.Repeat("and a foam roller ", 10),
+		Start:       start,
+		End:         start.Add(time.Hour),
+		Status:      StatusConfirmed,
+	}}, time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC))
+
+	if !strings.HasSuffix(ics, "\r\n") || strings.Contains(strings.ReplaceAll(ics, "\r\n", ""), "\n") {
+		t.Fatal("every content line must end in CRLF with no bare LF")
+	}
+	unfolded := unfold(ics)
+	for _, want := range []string{
+		"UID:session-7@chalk\r\n",
+		"DTSTAMP:20260101T120000Z\r\n",
+		"DTSTART:20260115T140000Z\r\n",
+		"DTEND:20260115T150000Z\r\n",
+		`SUMMARY:Strength\, conditioning\; mobility` + "\r\n",
+		`DESCRIPTION:Bring bands\nand a foam roller `,
+		"STATUS:CONFIRMED\r\n",
+	} {
+		if !strings.Contains(unfolded, want) {
+			t.Errorf("calendar is missing %q:\n%s", want, unfolded)
+		}
+	}
+	for i, line := range strings.Split(strings.TrimSuffix(ics, "\r\n"), "\r\n") {
+		if len(line) > maxLineOctets {
+			t.Errorf("line %d is %d octets, want at most %d", i, len(line), maxLineOctets)
+		}
+	}
+}
+
+func TestEncodeAllDayEventUsesDates(t *testing.T) {
+	day := time.Date(2026, 3, 8, 0, 0, 0, 0, time.UTC)
+	ics := Encode([]Event{{UID: "x", Summary: "Rest day", Start: day, AllDay: true}}, day)
+	for _, want := range []string{"DTSTART;VALUE=DATE:20260308\r\n", "DTEND;VALUE=DATE:20260309\r\n"} {
+		if !strings.Contains(ics, want) {
+			t.Errorf("calendar is missing %q:\n%s", want, ics)
+		}
+	}
+}
@@ -22,12 +22,36 @@ type Environment struct {
 	DBPassword  string `env:"DB_PASSWORD"`
 	DBName      string `env:"DB_NAME"`
 
+	// Optional read replica; plain reads are routed here when set
+	ReadReplicaURL string `env:"READ_REPLICA_URL"`
+
+	// Database pool and query logging
+	DBMaxOpenConns           int `env:"DB_MAX_OPEN_CONNS,default=25"`
+	DBMaxIdleConns           int `env:"DB_MAX_IDLE_CONNS,default=10"`
+	DBConnMaxLifetimeMinutes int `env:"DB_CONN_MAX_LIFETIME_MINUTES,default=30"`
+	DBSlowQueryThresholdMs   int `env:"DB_SLOW_QUERY_THRESHOLD_MS,default=200"`
+
 	// Redis (optional)
 	RedisURL string `env:"REDIS_URL"`
+	// Warn (and flag in GET /admin/cache/stats and /metrics) when chalk's estimated Redis usage passes
+	// this many megabytes; 0 turns the warning off
+	CacheMemorySoftLimitMB int `env:"CACHE_MEMORY_SOFT_LIMIT_MB,default=256"`
 
 	// JWT Auth (you'll configure these later)
-	JWTSecret          string `env:"JWT_SECRET"`
-	JWTExpirationHours int    `env:"JWT_EXPIRATION_HOURS,default=24"`
+	JWTSecret string `env:"JWT_SECRET"`
+	// Access tokens are short-lived; refresh tokens slide forward on each use until the family
+	// (everything since the original login) reaches its absolute lifetime.
+	AccessTokenTTLMinutes     int `env:"ACCESS_TOKEN_TTL_MINUTES,default=15"`
+	RefreshTokenTTLDays       int `env:"REFRESH_TOKEN_TTL_DAYS,default=30"`
+	RefreshTokenFamilyMaxDays int `env:"REFRESH_TOKEN_FAMILY_MAX_DAYS,default=90"`
+	// Live refresh tokens kept per user for logins without a device ID (each device keeps one)
+	RefreshTokenMaxDeviceless int `env:"REFRESH_TOKEN_MAX_DEVICELESS,default=10"`
+	// Deprecated: overrides ACCESS_TOKEN_TTL_MINUTES when set, for older env files.
+	JWTExpirationHours int `env:"JWT_EXPIRATION_HOURS"`
+	// RS256 signing; when set, tokens carry a kid and JWT_SECRET only verifies older HS256 tokens.
+	// Retired public keys stay in JWT_PREVIOUS_PUBLIC_KEYS until their tokens expire.
+	JWTPrivateKey         string `env:"JWT_PRIVATE_KEY"`
+	JWTPreviousPublicKeys string `env:"JWT_PREVIOUS_PUBLIC_KEYS"`
 
 	// OAuth (you'll configure these later)
 	GoogleClientID       string `env:"GOOGLE_CLIENT_ID"`
@@ -50,6 +74,95 @@ type Environment struct {
 	// Open Food Facts (no auth required, but we track user-agent)
 	OpenFoodFactsUserAgent string `env:"OPENFOODFACTS_USER_AGENT,default=ChalkAPI/1.0"`
 
+	// Circuit breakers on RevenueCat and Expo: consecutive failures before opening, and how long to stay open
+	CircuitBreakerFailureThreshold int `env:"CIRCUIT_BREAKER_FAILURE_THRESHOLD,default=5"`
+	CircuitBreakerCooldownSeconds  int `env:"CIRCUIT_BREAKER_COOLDOWN_SECONDS,default=30"`
+
+	// Object storage (S3-compatible: AWS S3, Cloudflare R2, MinIO)
+	StorageEndpoint        string `env:"STORAGE_ENDPOINT"`
+	StorageRegion          string `env:"STORAGE_REGION,default=us-east-1"`
+	StorageBucket          string `env:"STORAGE_BUCKET"`
+	StorageAccessKeyID     string `env:"STORAGE_ACCESS_KEY_ID"`
+	StorageSecretAccessKey string `env:"STORAGE_SECRET_ACCESS_KEY"`
+	StoragePublicBaseURL   string `env:"STORAGE_PUBLIC_BASE_URL"`
+
+	// Request body size limits; the upload limit applies to multipart upload endpoints such as the avatar
+	MaxRequestBodyBytes int `env:"MAX_REQUEST_BODY_BYTES,default=1048576"`
+	MaxUploadBodyBytes  int `env:"MAX_UPLOAD_BODY_BYTES,default=10485760"`
+
+	// Session check-ins farther than this from the coach's primary location are flagged
+	SessionCheckInRadiusMeters int `env:"SESSION_CHECK_IN_RADIUS_METERS,default=200"`
+
+	// Credit granted to a coach, in cents, when a coach they referred completes onboarding
+	ReferralCreditCents int `env:"REFERRAL_CREDIT_CENTS,default=2000"`
+
+	// Mobile app versions reported by GET /bootstrap; apps older than the minimum must upgrade before
+	// continuing, and apps older than the latest are offered an update. Empty disables either check.
+	MinAppVersion    string `env:"MIN_APP_VERSION"`
+	LatestAppVersion string `env:"LATEST_APP_VERSION"`
+
+	// Weekly coach digest worker; it polls for coaches whose local digest time has arrived
+	DigestWorkerEnabled       bool `env:"DIGEST_WORKER_ENABLED,default=true"`
+	DigestPollIntervalMinutes int  `env:"DIGEST_POLL_INTERVAL_MINUTES,default=15"`
+
+	// At-risk client worker; how often inactivity flags are recomputed
+	AtRiskWorkerEnabled       bool `env:"AT_RISK_WORKER_ENABLED,default=true"`
+	AtRiskPollIntervalMinutes int  `env:"AT_RISK_POLL_INTERVAL_MINUTES,default=60"`
+
+	// Opt-in per-user request analytics for support; events are buffered and flushed in batches
+	RequestAnalyticsEnabled              bool    `env:"REQUEST_ANALYTICS_ENABLED,default=false"`
+	RequestAnalyticsSampleRate           float64 `env:"REQUEST_ANALYTICS_SAMPLE_RATE,default=1"`
+	RequestAnalyticsBufferSize           int     `env:"REQUEST_ANALYTICS_BUFFER_SIZE,default=5000"`
+	RequestAnalyticsFlushIntervalSeconds int     `env:"REQUEST_ANALYTICS_FLUSH_INTERVAL_SECONDS,default=5"`
+
+	// Maintenance worker; prunes expired rows such as request events past their 7-day retention
+	MaintenanceWorkerEnabled       bool `env:"MAINTENANCE_WORKER_ENABLED,default=true"`
+	MaintenancePollIntervalMinutes int  `env:"MAINTENANCE_POLL_INTERVAL_MINUTES,default=60"`
+
+	// Pending session bookings the coach hasn't confirmed within the window are auto-declined
+	SessionConfirmationWorkerEnabled       bool `env:"SESSION_CONFIRMATION_WORKER_ENABLED,default=true"`
+	SessionConfirmationPollIntervalMinutes int  `env:"SESSION_CONFIRMATION_POLL_INTERVAL_MINUTES,default=15"`
+	SessionConfirmationWindowHours         int  `env:"SESSION_CONFIRMATION_WINDOW_HOURS,default=48"`
+
+	// Scheduled sessions still open this long after they ended are auto-resolved or flagged to the coach
+	StaleSessionWorkerEnabled       bool `env:"STALE_SESSION_WORKER_ENABLED,default=true"`
+	StaleSessionPollIntervalMinutes int  `env:"STALE_SESSION_POLL_INTERVAL_MINUTES,default=30"`
+	StaleSessionGraceHours          int  `env:"STALE_SESSION_GRACE_HOURS,default=12"`
+
+	// Workout reminder worker; wakes every quarter hour and reminds clients in timezones at the top of their
+	// local hour about workouts scheduled that day
+	WorkoutReminderWorkerEnabled bool `env:"WORKOUT_REMINDER_WORKER_ENABLED,default=true"`
+
+	// Coach stats worker; once a week, at this UTC weekday (0 = Sunday) and hour, rebuilds every coach's
+	// incremented counters and logs any that drifted
+	CoachStatsWorkerEnabled bool `env:"COACH_STATS_WORKER_ENABLED,default=true"`
+	CoachStatsWeekday       int  `env:"COACH_STATS_WEEKDAY,default=0"`
+	CoachStatsHourUTC       int  `env:"COACH_STATS_HOUR_UTC,default=4"`
+
+	// Profile view worker; copies the daily public profile view counts held in Redis to the database
+	ProfileViewWorkerEnabled        bool `env:"PROFILE_VIEW_WORKER_ENABLED,default=true"`
+	ProfileViewFlushIntervalMinutes int  `env:"PROFILE_VIEW_FLUSH_INTERVAL_MINUTES,default=60"`
+
+	// Client import worker; how often queued CSV client imports (over 50 rows) are picked up
+	ClientImportWorkerEnabled       bool `env:"CLIENT_IMPORT_WORKER_ENABLED,default=true"`
+	ClientImportPollIntervalSeconds int  `env:"CLIENT_IMPORT_POLL_INTERVAL_SECONDS,default=30"`
+
+	// Scheduled message worker; how often due coach messages are delivered
+	ScheduledMessageWorkerEnabled       bool `env:"SCHEDULED_MESSAGE_WORKER_ENABLED,default=true"`
+	ScheduledMessagePollIntervalSeconds int  `env:"SCHEDULED_MESSAGE_POLL_INTERVAL_SECONDS,default=30"`
+
+	// Log redaction; emails, phones, message content and credentials are masked. Extra keys are
+	// comma-separated. The allowlist (keys logged as-is) is ignored outside local development.
+	LogRedactionEnabled   bool   `env:"LOG_REDACTION_ENABLED,default=true"`
+	LogRedactionKeys      string `env:"LOG_REDACTION_KEYS"`
+	LogRedactionAllowlist string `env:"LOG_REDACTION_ALLOWLIST"`
+
+	// OpenTelemetry tracing; spans are exported over OTLP/HTTP to the endpoint, and tracing is a
+	// no-op when it is unset. The ratio samples new traces; inbound sampled traces are always kept.
+	OTelExporterEndpoint string  `env:"OTEL_EXPORTER_OTLP_ENDPOINT"`
+	OTelServiceName      string  `env:"OTEL_SERVICE_NAME,default=chalk-api"`
+	OTelSampleRatio      float64 `env:"OTEL_TRACES_SAMPLE_RATIO,default=1"`
+
 	// Outbox worker tuning
 	OutboxPollIntervalSeconds   int `env:"OUTBOX_POLL_INTERVAL_SECONDS,default=2"`
 	OutboxBatchSize             int `env:"OUTBOX_BATCH_SIZE,default=25"`
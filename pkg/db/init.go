@@ -3,15 +3,21 @@ package db
 import (
 	"chalk-api/pkg/config"
 	"chalk-api/pkg/models"
+	"database/sql"
 	"fmt"
 	"log/slog"
+	"time"
 
+	"github.com/uptrace/opentelemetry-go-extra/otelgorm"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 )
 
-var db *gorm.DB
+var (
+	db        *gorm.DB
+	replicaDB *sql.DB
+)
 
 // InitializeDatabase creates and returns a database connection
 func InitializeDatabase(cfg config.Environment) (*gorm.DB, error) {
@@ -39,20 +45,86 @@ func InitializeDatabase(cfg config.Environment) (*gorm.DB, error) {
 		logLevel = logger.Warn
 	}
 
+	slowThreshold := time.Duration(cfg.DBSlowQueryThresholdMs) * time.Millisecond
+
 	var err error
 	db, err = gorm.Open(postgres.Open(dsn), &gorm.Config{
-		Logger: logger.Default.LogMode(logLevel),
+		Logger: newSlogLogger(logLevel, slowThreshold),
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
 
-	slog.Info("Database connection established")
+	// One span per query under the caller's span. Bound values are left out since they carry user data.
+	if err := db.Use(otelgorm.NewPlugin(otelgorm.WithoutQueryVariables(), otelgorm.WithoutMetrics())); err != nil {
+		return nil, fmt.Errorf("failed to register tracing plugin: %w", err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get underlying sql.DB: %w", err)
+	}
+	ConfigurePool(sqlDB, cfg)
+
+	if cfg.ReadReplicaURL != "" {
+		if err := useReadReplica(db, cfg, logLevel, slowThreshold); err != nil {
+			return nil, err
+		}
+		slog.Info("Read replica configured")
+	}
+
+	slog.Info("Database connection established",
+		"max_open_conns", cfg.DBMaxOpenConns,
+		"max_idle_conns", cfg.DBMaxIdleConns,
+		"conn_max_lifetime_minutes", cfg.DBConnMaxLifetimeMinutes,
+		"slow_query_threshold_ms", cfg.DBSlowQueryThresholdMs,
+	)
 	return db, nil
 }
 
+// ConfigurePool applies the connection pool limits from config to sqlDB.
+// Zero values leave the database/sql defaults in place.
+func ConfigurePool(sqlDB *sql.DB, cfg config.Environment) {
+	if cfg.DBMaxOpenConns > 0 {
+		sqlDB.SetMaxOpenConns(cfg.DBMaxOpenConns)
+	}
+	if cfg.DBMaxIdleConns > 0 {
+		sqlDB.SetMaxIdleConns(cfg.DBMaxIdleConns)
+	}
+	if cfg.DBConnMaxLifetimeMinutes > 0 {
+		sqlDB.SetConnMaxLifetime(time.Duration(cfg.DBConnMaxLifetimeMinutes) * time.Minute)
+	}
+}
+
+// useReadReplica opens the replica with the same pool settings and registers the resolver
+// that routes plain reads to it.
+func useReadReplica(primary *gorm.DB, cfg config.Environment, logLevel logger.LogLevel, slowThreshold time.Duration) error {
+	replica, err := gorm.Open(postgres.Open(cfg.ReadReplicaURL), &gorm.Config{
+		Logger: newSlogLogger(logLevel, slowThreshold),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to connect to read replica: %w", err)
+	}
+
+	replicaDB, err = replica.DB()
+	if err != nil {
+		return fmt.Errorf("failed to get read replica sql.DB: %w", err)
+	}
+	ConfigurePool(replicaDB, cfg)
+
+	if err := registerReplica(primary, replicaDB); err != nil {
+		return fmt.Errorf("failed to register read replica resolver: %w", err)
+	}
+	return nil
+}
+
 // CloseDatabase closes the database connection
 func CloseDatabase() {
+	if replicaDB != nil {
+		if err := replicaDB.Close(); err != nil {
+			slog.Error("Failed to close read replica connection", "error", err)
+		}
+	}
 	if db != nil {
 		sqlDB, err := db.DB()
 		if err != nil {
@@ -76,6 +148,22 @@ func GetDB() *gorm.DB {
 func RunMigrations(db *gorm.DB) error {
 	slog.Info("Running database migrations...")
 
+	// Client tags moved from text[] to jsonb so they can be unnested and filtered; convert the column
+	// before AutoMigrate, which can't cast between the two
+	if err := db.Exec(`
+		DO $$
+		BEGIN
+			IF EXISTS (
+				SELECT 1 FROM information_schema.columns
+				WHERE table_name = 'client_profiles' AND column_name = 'tags' AND data_type = 'ARRAY'
+			) THEN
+				ALTER TABLE client_profiles ALTER COLUMN tags TYPE jsonb USING to_jsonb(tags);
+			END IF;
+		END $$
+	`).Error; err != nil {
+		return fmt.Errorf("failed to convert client tags to jsonb: %w", err)
+	}
+
 	// Auto-migrate models
 	err := db.AutoMigrate(
 		// User models
@@ -83,6 +171,7 @@ func RunMigrations(db *gorm.DB) error {
 		&models.Profile{},
 		&models.OAuthProvider{},
 		&models.RefreshToken{},
+		&models.AccessTokenRevocation{},
 		&models.DeviceToken{},
 		&models.PasswordReset{},
 		&models.EmailVerification{},
@@ -92,27 +181,43 @@ func RunMigrations(db *gorm.DB) error {
 		&models.Certification{},
 		&models.CoachLocation{},
 		&models.CoachStats{},
+		&models.CoachProfileView{},
+		&models.CoachTeamMember{},
 		// Client models
 		&models.ClientProfile{},
+		&models.ClientNote{},
 		&models.InviteCode{},
+		&models.InviteCodeUse{},
+		&models.ConnectionRequest{},
+		&models.ClientInvitation{},
+		&models.ClientImport{},
 		&models.ClientIntakeForm{},
+		&models.CustomIntakeQuestion{},
 		// Subscription models
 		&models.Subscription{},
 		&models.SubscriptionEvent{},
 		// Exercise models
 		&models.Exercise{},
+		&models.ExerciseAlternative{},
 		// Template models
+		&models.TemplateCategory{},
 		&models.WorkoutTemplate{},
 		&models.WorkoutTemplateExercise{},
+		&models.TemplateVersion{},
 		// Workout models
 		&models.Workout{},
 		&models.WorkoutExercise{},
+		&models.ClientExerciseDefault{},
 		&models.WorkoutLog{},
 		// Scheduling models
 		&models.CoachAvailability{},
 		&models.CoachAvailabilityOverride{},
+		&models.AvailabilityPreset{},
 		&models.SessionType{},
 		&models.Session{},
+		&models.SessionParticipant{},
+		&models.CoachTimeBlock{},
+		&models.SessionFeedback{},
 		// Nutrition models
 		&models.NutritionTarget{},
 		&models.FoodItem{},
@@ -121,11 +226,34 @@ func RunMigrations(db *gorm.DB) error {
 		// Progress models
 		&models.BodyMetric{},
 		&models.ProgressPhoto{},
+		&models.ClientGoal{},
+		&models.ClientGoalProgress{},
+		&models.ActivitySample{},
+		&models.ClientStreak{},
+		&models.ClientMilestone{},
 		// Messaging models
 		&models.Conversation{},
 		&models.Message{},
+		&models.SavedReply{},
+		&models.ScheduledMessage{},
+		// Notification models
+		&models.Notification{},
+		&models.PushTicket{},
+		// Privacy models
+		&models.DataExport{},
+		// Reporting models
+		&models.ClientReport{},
 		// Event outbox models
 		&models.OutboxEvent{},
+		// Referral models
+		&models.Referral{},
+		&models.ReferralCredit{},
+		// Webhook inbox models
+		&models.WebhookInboxEntry{},
+		// Support analytics models
+		&models.RequestEvent{},
+		// Feature flag models
+		&models.FeatureFlag{},
 	)
 	if err != nil {
 		return fmt.Errorf("failed to run migrations: %w", err)
@@ -149,11 +277,207 @@ func RunMigrations(db *gorm.DB) error {
 		return fmt.Errorf("failed to create client profile index: %w", err)
 	}
 
+	// Tag filters on the coach's client list use jsonb containment
+	if err := db.Exec(`
+		CREATE INDEX IF NOT EXISTS idx_client_profiles_tags ON client_profiles USING GIN (tags)
+	`).Error; err != nil {
+		return fmt.Errorf("failed to create client tags index: %w", err)
+	}
+
+	// Note search matches whole words with English stemming, so "knee" also finds "knees"
+	if err := db.Exec(`
+		CREATE INDEX IF NOT EXISTS idx_client_notes_body_search
+		ON client_notes USING GIN (to_tsvector('english', body))
+	`).Error; err != nil {
+		return fmt.Errorf("failed to create client note search index: %w", err)
+	}
+
+	// Private notes written before client_notes existed become each client's first note. Clients
+	// with any note, including a deleted one, are skipped so this runs once per client
+	if err := db.Exec(`
+		INSERT INTO client_notes (client_id, coach_id, author_user_id, body, pinned, created_at, updated_at)
+		SELECT cp.id, cp.coach_id, co.user_id, btrim(cp.private_notes), false, cp.updated_at, cp.updated_at
+		FROM client_profiles cp
+		JOIN coach_profiles co ON co.id = cp.coach_id
+		WHERE cp.private_notes IS NOT NULL AND btrim(cp.private_notes) <> ''
+			AND NOT EXISTS (SELECT 1 FROM client_notes n WHERE n.client_id = cp.id)
+	`).Error; err != nil {
+		return fmt.Errorf("failed to migrate client private notes: %w", err)
+	}
+
+	// A user can have only one pending connection request per coach; reviewed requests are kept as history
+	if err := db.Exec(`
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_connection_requests_pending
+		ON connection_requests(user_id, coach_id) WHERE status = 'pending'
+	`).Error; err != nil {
+		return fmt.Errorf("failed to create connection request index: %w", err)
+	}
+
+	// A coach has at most one open invitation or membership per email, and a user assists at most one coach
+	if err := db.Exec(`
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_coach_team_members_open
+		ON coach_team_members(coach_id, email) WHERE status IN ('pending', 'active')
+	`).Error; err != nil {
+		return fmt.Errorf("failed to create coach team invitation index: %w", err)
+	}
+	if err := db.Exec(`
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_coach_team_members_active_user
+		ON coach_team_members(user_id) WHERE status = 'active'
+	`).Error; err != nil {
+		return fmt.Errorf("failed to create coach team membership index: %w", err)
+	}
+
+	// Refresh tokens issued before rotation families existed each start their own family
+	if err := db.Exec(`
+		UPDATE refresh_tokens
+		SET family_id = 'legacy-' || id::text,
+			family_expires_at = COALESCE(family_expires_at, created_at + interval '90 days')
+		WHERE family_id = ''
+	`).Error; err != nil {
+		return fmt.Errorf("failed to backfill refresh token families: %w", err)
+	}
+
+	// Single-use invites accepted before invite_code_uses existed count as one use by used_by
+	if err := db.Exec(`
+		UPDATE invite_codes SET use_count = 1 WHERE used_by IS NOT NULL AND use_count = 0
+	`).Error; err != nil {
+		return fmt.Errorf("failed to backfill invite use counts: %w", err)
+	}
+	if err := db.Exec(`
+		INSERT INTO invite_code_uses (invite_code_id, user_id, client_profile_id, created_at)
+		SELECT i.id, i.used_by, cp.id, COALESCE(i.used_at, i.created_at)
+		FROM invite_codes i
+		LEFT JOIN client_profiles cp ON cp.user_id = i.used_by AND cp.coach_id = i.coach_id
+		WHERE i.used_by IS NOT NULL
+		ON CONFLICT (invite_code_id, user_id) DO NOTHING
+	`).Error; err != nil {
+		return fmt.Errorf("failed to backfill invite code uses: %w", err)
+	}
+
+	// Sessions booked before group sessions existed have no participant rows; add their client
+	if err := db.Exec(`
+		INSERT INTO session_participants (session_id, client_id, status, cancelled_at, cancellation_reason, created_at, updated_at)
+		SELECT s.id, s.client_id,
+			CASE WHEN s.status = 'cancelled' THEN 'cancelled' ELSE 'booked' END,
+			s.cancelled_at, s.cancellation_reason, s.created_at, s.updated_at
+		FROM sessions s
+		WHERE NOT EXISTS (SELECT 1 FROM session_participants p WHERE p.session_id = s.id)
+	`).Error; err != nil {
+		return fmt.Errorf("failed to backfill session participants: %w", err)
+	}
+
+	// Workouts double-booked before the one-per-date rule keep their extras as allowed duplicates
+	if err := db.Exec(`
+		UPDATE workouts SET duplicate_allowed = true
+		WHERE id IN (
+			SELECT id FROM (
+				SELECT id, ROW_NUMBER() OVER (PARTITION BY client_id, scheduled_date ORDER BY id) AS rn
+				FROM workouts
+				WHERE scheduled_date IS NOT NULL AND status <> 'skipped' AND NOT duplicate_allowed
+			) ranked
+			WHERE rn > 1
+		)
+	`).Error; err != nil {
+		return fmt.Errorf("failed to backfill duplicate workouts: %w", err)
+	}
+
+	// Conversations of relationships archived before is_active existed drop out of inboxes too
+	if err := db.Exec(`
+		UPDATE conversations SET is_active = false
+		WHERE is_active AND client_id IN (SELECT id FROM client_profiles WHERE status = 'archived')
+	`).Error; err != nil {
+		return fmt.Errorf("failed to backfill inactive conversations: %w", err)
+	}
+
+	// Template category names are unique per coach regardless of case
+	if err := db.Exec(`
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_template_categories_coach_name
+		ON template_categories(coach_id, lower(name))
+	`).Error; err != nil {
+		return fmt.Errorf("failed to create template category name index: %w", err)
+	}
+
+	// Templates created while category was free text get one category per coach and distinct name
+	// (ignoring case and surrounding spaces), keeping the first spelling used
+	if err := db.Exec(`
+		INSERT INTO template_categories (coach_id, name, created_at, updated_at)
+		SELECT DISTINCT ON (coach_id, lower(btrim(category))) coach_id, btrim(category), NOW(), NOW()
+		FROM workout_templates
+		WHERE category_id IS NULL AND category IS NOT NULL AND btrim(category) <> ''
+		ORDER BY coach_id, lower(btrim(category)), id
+		ON CONFLICT (coach_id, lower(name)) DO NOTHING
+	`).Error; err != nil {
+		return fmt.Errorf("failed to backfill template categories: %w", err)
+	}
+	if err := db.Exec(`
+		UPDATE workout_templates t
+		SET category_id = c.id, category = c.name
+		FROM template_categories c
+		WHERE t.category_id IS NULL AND t.category IS NOT NULL
+			AND c.coach_id = t.coach_id AND lower(c.name) = lower(btrim(t.category))
+	`).Error; err != nil {
+		return fmt.Errorf("failed to link templates to categories: %w", err)
+	}
+
+	// One active workout per client per date unless the coach explicitly allowed a duplicate
+	if err := db.Exec(`
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_workouts_client_date_active
+		ON workouts(client_id, scheduled_date) WHERE status NOT IN ('skipped') AND NOT duplicate_allowed
+	`).Error; err != nil {
+		return fmt.Errorf("failed to create workout date index: %w", err)
+	}
+
+	// Partial unique index for data exports
+	// Ensures a user can only have one export pending or processing at a time
+	if err := db.Exec(`
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_data_exports_user_in_flight
+		ON data_exports(user_id) WHERE status IN ('pending', 'processing')
+	`).Error; err != nil {
+		return fmt.Errorf("failed to create data export in-flight index: %w", err)
+	}
+
+	// One progress report pending or processing per client, so repeated taps can't pile up PDF jobs
+	if err := db.Exec(`
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_client_reports_client_in_flight
+		ON client_reports(client_id) WHERE status IN ('pending', 'processing')
+	`).Error; err != nil {
+		return fmt.Errorf("failed to create client report in-flight index: %w", err)
+	}
+
+	// Partial unique indexes backing the catalog seeder's upserts
+	// Scoped to system rows so third-party caches and custom entries are unaffected
+	if err := db.Exec(`
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_exercises_system_external
+		ON exercises(source, external_id) WHERE is_system AND external_id IS NOT NULL
+	`).Error; err != nil {
+		return fmt.Errorf("failed to create exercise external id index: %w", err)
+	}
+
+	if err := db.Exec(`
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_food_items_system_external
+		ON food_items(source, external_id) WHERE is_system AND external_id IS NOT NULL
+	`).Error; err != nil {
+		return fmt.Errorf("failed to create food item external id index: %w", err)
+	}
+
+	// The scheduled message worker polls for due pending rows
+	if err := db.Exec(`
+		CREATE INDEX IF NOT EXISTS idx_scheduled_messages_due
+		ON scheduled_messages(scheduled_at) WHERE status = 'pending'
+	`).Error; err != nil {
+		return fmt.Errorf("failed to create scheduled messages due index: %w", err)
+	}
+
 	// Add indexes for efficient cleanup queries
 	if err := db.Exec(`CREATE INDEX IF NOT EXISTS idx_refresh_tokens_cleanup ON refresh_tokens(expires_at, revoked)`).Error; err != nil {
 		return fmt.Errorf("failed to create refresh tokens cleanup index: %w", err)
 	}
 
+	// Login revokes the user's live tokens for the same device
+	if err := db.Exec(`CREATE INDEX IF NOT EXISTS idx_refresh_tokens_user_device ON refresh_tokens(user_id, device_id) WHERE revoked = false`).Error; err != nil {
+		return fmt.Errorf("failed to create refresh tokens device index: %w", err)
+	}
+
 	if err := db.Exec(`CREATE INDEX IF NOT EXISTS idx_password_resets_cleanup ON password_resets(expires_at, used)`).Error; err != nil {
 		return fmt.Errorf("failed to create password resets cleanup index: %w", err)
 	}
@@ -174,6 +498,14 @@ func RunMigrations(db *gorm.DB) error {
 		return fmt.Errorf("failed to create conversation index: %w", err)
 	}
 
+	// Coach form-check queue: unreviewed videos, oldest first
+	if err := db.Exec(`
+		CREATE INDEX IF NOT EXISTS idx_workout_logs_unreviewed_form_checks
+		ON workout_logs(video_uploaded_at) WHERE video_url IS NOT NULL AND reviewed_at IS NULL
+	`).Error; err != nil {
+		return fmt.Errorf("failed to create form check index: %w", err)
+	}
+
 	// Outbox processing indexes for worker polling and crash recovery
 	if err := db.Exec(`CREATE INDEX IF NOT EXISTS idx_outbox_pending_available ON outbox_events(status, available_at)`).Error; err != nil {
 		return fmt.Errorf("failed to create outbox pending index: %w", err)
@@ -0,0 +1,93 @@
+package db
+
+import (
+	"chalk-api/pkg/config"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+)
+
+// poolTestDriver hands out connections that can't run statements; the pool tests only need
+// connections to exist so database/sql can open, idle and close them.
+type poolTestDriver struct{}
+
+type poolTestConn struct{}
+
+func (poolTestDriver) Open(name string) (driver.Conn, error) { return poolTestConn{}, nil }
+
+func (poolTestConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("pool test connection runs no statements")
+}
+func (poolTestConn) Close() error { return nil }
+func (poolTestConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("pool test connection runs no transactions")
+}
+
+func init() {
+	sql.Register("chalk-pool-test", poolTestDriver{})
+}
+
+func openPoolTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	sqlDB, err := sql.Open("chalk-pool-test", "")
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+	return sqlDB
+}
+
+// checkOut holds n connections at once and then returns them all to the pool.
+func checkOut(t *testing.T, sqlDB *sql.DB, n int) {
+	t.Helper()
+	conns := make([]*sql.Conn, 0, n)
+	for i := 0; i < n; i++ {
+		conn, err := sqlDB.Conn(context.Background())
+		if err != nil {
+			t.Fatalf("checkout %d: %v", i, err)
+		}
+		conns = append(conns, conn)
+	}
+	for _, conn := range conns {
+		conn.Close()
+	}
+}
+
+func TestConfigurePoolAppliesLimits(t *testing.T) {
+	sqlDB := openPoolTestDB(t)
+	ConfigurePool(sqlDB, config.Environment{
+		DBMaxOpenConns:           8,
+		DBMaxIdleConns:           3,
+		DBConnMaxLifetimeMinutes: 30,
+	})
+
+	if got := sqlDB.Stats().MaxOpenConnections; got != 8 {
+		t.Errorf("max open connections = %d, want 8", got)
+	}
+
+	checkOut(t, sqlDB, 5)
+	stats := sqlDB.Stats()
+	if stats.Idle != 3 {
+		t.Errorf("idle connections = %d, want 3", stats.Idle)
+	}
+	if stats.MaxIdleClosed != 2 {
+		t.Errorf("connections closed for exceeding max idle = %d, want 2", stats.MaxIdleClosed)
+	}
+}
+
+func TestConfigurePoolKeepsDefaultsForZeroValues(t *testing.T) {
+	sqlDB := openPoolTestDB(t)
+	ConfigurePool(sqlDB, config.Environment{})
+
+	if got := sqlDB.Stats().MaxOpenConnections; got != 0 {
+		t.Errorf("max open connections = %d, want 0 (unlimited)", got)
+	}
+
+	// database/sql keeps 2 idle connections by default.
+	checkOut(t, sqlDB, 4)
+	if got := sqlDB.Stats().Idle; got != 2 {
+		t.Errorf("idle connections = %d, want the default 2", got)
+	}
+}
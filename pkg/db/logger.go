@@ -0,0 +1,86 @@
+package db
+
+import (
+	"chalk-api/pkg/utils"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// slogLogger routes GORM output through slog. Queries slower than slowThreshold are logged
+// as warnings with the request ID from context; every query is logged at debug level when
+// the level is logger.Info (local development).
+type slogLogger struct {
+	level         logger.LogLevel
+	slowThreshold time.Duration
+}
+
+func newSlogLogger(level logger.LogLevel, slowThreshold time.Duration) *slogLogger {
+	return &slogLogger{level: level, slowThreshold: slowThreshold}
+}
+
+func (l *slogLogger) LogMode(level logger.LogLevel) logger.Interface {
+	clone := *l
+	clone.level = level
+	return &clone
+}
+
+func (l *slogLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= logger.Info {
+		slog.InfoContext(ctx, fmt.Sprintf(msg, args...), "request_id", utils.RequestIDFromContext(ctx))
+	}
+}
+
+func (l *slogLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= logger.Warn {
+		slog.WarnContext(ctx, fmt.Sprintf(msg, args...), "request_id", utils.RequestIDFromContext(ctx))
+	}
+}
+
+func (l *slogLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= logger.Error {
+		slog.ErrorContext(ctx, fmt.Sprintf(msg, args...), "request_id", utils.RequestIDFromContext(ctx))
+	}
+}
+
+func (l *slogLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	if l.level <= logger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	switch {
+	// Not-found lookups are expected and handled by callers.
+	case err != nil && l.level >= logger.Error && !errors.Is(err, gorm.ErrRecordNotFound):
+		sql, rows := fc()
+		slog.ErrorContext(ctx, "Database query failed",
+			"request_id", utils.RequestIDFromContext(ctx),
+			"duration_ms", elapsed.Milliseconds(),
+			"rows", rows,
+			"sql", sql,
+			"error", err,
+		)
+	case l.slowThreshold > 0 && elapsed > l.slowThreshold && l.level >= logger.Warn:
+		sql, rows := fc()
+		slog.WarnContext(ctx, "Slow database query",
+			"request_id", utils.RequestIDFromContext(ctx),
+			"duration_ms", elapsed.Milliseconds(),
+			"threshold_ms", l.slowThreshold.Milliseconds(),
+			"rows", rows,
+			"sql", sql,
+		)
+	case l.level >= logger.Info:
+		sql, rows := fc()
+		slog.DebugContext(ctx, "Database query",
+			"request_id", utils.RequestIDFromContext(ctx),
+			"duration_ms", elapsed.Milliseconds(),
+			"rows", rows,
+			"sql", sql,
+		)
+	}
+}
@@ -0,0 +1,78 @@
+package db
+
+import (
+	"bytes"
+	"chalk-api/pkg/utils"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/gorm/logger"
+)
+
+// captureSlog sends the default slog output to a buffer for the rest of the test.
+func captureSlog(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+	return &buf
+}
+
+func logRecords(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var records []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var record map[string]any
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("decode log line %q: %v", line, err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestSlowQueriesAreLoggedWithRequestID(t *testing.T) {
+	buf := captureSlog(t)
+	l := newSlogLogger(logger.Warn, 200*time.Millisecond)
+	ctx := utils.WithRequestID(context.Background(), "req-123")
+	query := func() (string, int64) { return `SELECT * FROM "sessions"`, 4 }
+
+	l.Trace(ctx, time.Now().Add(-50*time.Millisecond), query, nil)
+	if records := logRecords(t, buf); len(records) != 0 {
+		t.Fatalf("fast query logged at warn level: %v", records)
+	}
+
+	l.Trace(ctx, time.Now().Add(-time.Second), query, nil)
+	records := logRecords(t, buf)
+	if len(records) != 1 {
+		t.Fatalf("records = %v, want one slow query warning", records)
+	}
+	record := records[0]
+	if record["level"] != "WARN" || record["msg"] != "Slow database query" {
+		t.Errorf("record = %v, want a slow query warning", record)
+	}
+	if record["request_id"] != "req-123" {
+		t.Errorf("request_id = %v, want req-123", record["request_id"])
+	}
+	if record["threshold_ms"] != float64(200) || record["sql"] != `SELECT * FROM "sessions"` {
+		t.Errorf("record = %v, want the threshold and SQL", record)
+	}
+}
+
+func TestSlowQueryLoggingDisabledWithZeroThreshold(t *testing.T) {
+	buf := captureSlog(t)
+	l := newSlogLogger(logger.Warn, 0)
+
+	l.Trace(context.Background(), time.Now().Add(-time.Hour), func() (string, int64) { return "SELECT 1", 1 }, nil)
+	if records := logRecords(t, buf); len(records) != 0 {
+		t.Fatalf("records = %v, want none", records)
+	}
+}
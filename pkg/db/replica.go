@@ -0,0 +1,24 @@
+package db
+
+import (
+	"database/sql"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
+
+// UsePrimary pins every query issued through tx (including preloads) to the primary.
+// Use it for reads that must observe a write made earlier in the same request.
+func UsePrimary(tx *gorm.DB) *gorm.DB {
+	return tx.Clauses(dbresolver.Write)
+}
+
+// registerReplica registers gorm's dbresolver so plain reads go to replica. Writes,
+// raw statements that are not SELECTs, locking reads, anything inside a transaction
+// and queries pinned with UsePrimary stay on the primary.
+func registerReplica(primary *gorm.DB, replica *sql.DB) error {
+	return primary.Use(dbresolver.Register(dbresolver.Config{
+		Replicas: []gorm.Dialector{postgres.New(postgres.Config{Conn: replica})},
+	}))
+}
@@ -0,0 +1,235 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/logger"
+)
+
+// routingDriver records every statement per DSN so a test can tell which pool ran it.
+// Queries against replica_test_parents return one row so preloads have something to load.
+type routingDriver struct {
+	mu         sync.Mutex
+	statements map[string][]string
+}
+
+type routingConn struct {
+	driver *routingDriver
+	name   string
+}
+
+type routingTx struct{ conn *routingConn }
+
+type routingRows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+var routing = &routingDriver{statements: map[string][]string{}}
+
+func init() {
+	sql.Register("chalk-routing-test", routing)
+}
+
+func (d *routingDriver) Open(name string) (driver.Conn, error) {
+	return &routingConn{driver: d, name: name}, nil
+}
+
+func (d *routingDriver) record(name, statement string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.statements[name] = append(d.statements[name], statement)
+}
+
+// take returns and clears what name has run so far.
+func (d *routingDriver) take(name string) []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	statements := d.statements[name]
+	delete(d.statements, name)
+	return statements
+}
+
+func (c *routingConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("routing test connection does not prepare statements")
+}
+func (c *routingConn) Close() error { return nil }
+func (c *routingConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *routingConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	c.driver.record(c.name, "BEGIN")
+	return routingTx{conn: c}, nil
+}
+
+func (c *routingConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.driver.record(c.name, query)
+	return driver.RowsAffected(1), nil
+}
+
+func (c *routingConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.driver.record(c.name, query)
+	if strings.Contains(query, `FROM "replica_test_parents"`) {
+		return &routingRows{columns: []string{"id"}, values: [][]driver.Value{{int64(1)}}}, nil
+	}
+	return &routingRows{}, nil
+}
+
+func (tx routingTx) Commit() error {
+	tx.conn.driver.record(tx.conn.name, "COMMIT")
+	return nil
+}
+func (tx routingTx) Rollback() error {
+	tx.conn.driver.record(tx.conn.name, "ROLLBACK")
+	return nil
+}
+
+func (r *routingRows) Columns() []string { return r.columns }
+func (r *routingRows) Close() error      { return nil }
+func (r *routingRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+type replicaTestParent struct {
+	ID       uint
+	Children []replicaTestChild `gorm:"foreignKey:ParentID"`
+}
+
+type replicaTestChild struct {
+	ID       uint
+	ParentID uint
+}
+
+// openRoutedDB returns a gorm handle on a "primary" pool with a "replica" registered
+// the same way useReadReplica does it.
+func openRoutedDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	open := func(name string) *sql.DB {
+		sqlDB, err := sql.Open("chalk-routing-test", name)
+		if err != nil {
+			t.Fatalf("open %s: %v", name, err)
+		}
+		t.Cleanup(func() { sqlDB.Close() })
+		return sqlDB
+	}
+
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{Conn: open("primary")}), &gorm.Config{
+		Logger:                 logger.Default.LogMode(logger.Silent),
+		SkipDefaultTransaction: true,
+	})
+	if err != nil {
+		t.Fatalf("open primary: %v", err)
+	}
+	if err := registerReplica(gormDB, open("replica")); err != nil {
+		t.Fatalf("register replica: %v", err)
+	}
+	routing.take("primary")
+	routing.take("replica")
+	return gormDB
+}
+
+// expectRouted fails unless primary and replica ran exactly the wanted number of statements.
+func expectRouted(t *testing.T, what string, primary, replica int) {
+	t.Helper()
+	gotPrimary, gotReplica := routing.take("primary"), routing.take("replica")
+	if len(gotPrimary) != primary || len(gotReplica) != replica {
+		t.Errorf("%s: primary ran %q, replica ran %q; want %d on the primary and %d on the replica",
+			what, gotPrimary, gotReplica, primary, replica)
+	}
+}
+
+func TestReplicaRouting(t *testing.T) {
+	gormDB := openRoutedDB(t)
+	ctx := context.Background()
+
+	var children []replicaTestChild
+	if err := gormDB.WithContext(ctx).Find(&children).Error; err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	expectRouted(t, "plain read", 0, 1)
+
+	var count int64
+	if err := gormDB.WithContext(ctx).Raw(`SELECT count(*) FROM "replica_test_children"`).Scan(&count).Error; err != nil {
+		t.Fatalf("raw select: %v", err)
+	}
+	expectRouted(t, "raw select", 0, 1)
+
+	child := replicaTestChild{ParentID: 1}
+	if err := gormDB.WithContext(ctx).Create(&child).Error; err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	expectRouted(t, "create", 1, 0)
+
+	if err := gormDB.WithContext(ctx).Model(&replicaTestChild{}).Where("id = ?", 1).Update("parent_id", 2).Error; err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	expectRouted(t, "update", 1, 0)
+
+	if err := gormDB.WithContext(ctx).Delete(&replicaTestChild{}, 1).Error; err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	expectRouted(t, "delete", 1, 0)
+
+	if err := gormDB.WithContext(ctx).Exec(`UPDATE "replica_test_children" SET parent_id = 3`).Error; err != nil {
+		t.Fatalf("raw exec: %v", err)
+	}
+	expectRouted(t, "raw exec", 1, 0)
+
+	if err := gormDB.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).Find(&children).Error; err != nil {
+		t.Fatalf("locking read: %v", err)
+	}
+	expectRouted(t, "FOR UPDATE read", 1, 0)
+
+	if err := UsePrimary(gormDB.WithContext(ctx)).Find(&children).Error; err != nil {
+		t.Fatalf("pinned read: %v", err)
+	}
+	expectRouted(t, "UsePrimary read", 1, 0)
+}
+
+func TestReplicaRoutingKeepsTransactionsOnPrimary(t *testing.T) {
+	gormDB := openRoutedDB(t)
+
+	err := gormDB.Transaction(func(tx *gorm.DB) error {
+		var children []replicaTestChild
+		if err := tx.Find(&children).Error; err != nil {
+			return err
+		}
+		return tx.Create(&replicaTestChild{ParentID: 1}).Error
+	})
+	if err != nil {
+		t.Fatalf("transaction: %v", err)
+	}
+	// BEGIN, the read, the insert and COMMIT.
+	expectRouted(t, "transaction", 4, 0)
+}
+
+func TestReplicaRoutingPinsPreloads(t *testing.T) {
+	gormDB := openRoutedDB(t)
+
+	var parents []replicaTestParent
+	if err := gormDB.Preload("Children").Find(&parents).Error; err != nil {
+		t.Fatalf("preload: %v", err)
+	}
+	expectRouted(t, "preloaded read", 0, 2)
+
+	if err := UsePrimary(gormDB.WithContext(context.Background())).Preload("Children").Find(&parents).Error; err != nil {
+		t.Fatalf("pinned preload: %v", err)
+	}
+	expectRouted(t, "pinned preloaded read", 2, 0)
+}
@@ -0,0 +1,164 @@
+package events
+
+import (
+	"chalk-api/pkg/i18n"
+	"chalk-api/pkg/models"
+	"chalk-api/pkg/repositories"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+)
+
+// sessionsDeepLink opens the signed-in devices screen, where the user can log a device out
+const sessionsDeepLink = "chalk://settings/sessions"
+
+// NewDeviceLoginHandler warns a user about a login from a device or country they haven't used
+// recently, with an in-app notification and a push that opens the sessions screen.
+type NewDeviceLoginHandler struct {
+	userRepo         *repositories.UserRepository
+	notificationRepo *repositories.NotificationRepository
+	publisher        *Publisher
+}
+
+func NewNewDeviceLoginHandler(
+	userRepo *repositories.UserRepository,
+	notificationRepo *repositories.NotificationRepository,
+	publisher *Publisher,
+) *NewDeviceLoginHandler {
+	return &NewDeviceLoginHandler{
+		userRepo:         userRepo,
+		notificationRepo: notificationRepo,
+		publisher:        publisher,
+	}
+}
+
+func (h *NewDeviceLoginHandler) Handle(ctx context.Context, event models.OutboxEvent) error {
+	var payload AuthNewDevicePayload
+	if err := json.Unmarshal([]byte(event.Payload), &payload); err != nil {
+		return Permanent(fmt.Errorf("decode auth.new_device payload: %w", err))
+	}
+	if payload.UserID == 0 {
+		return Permanent(fmt.Errorf("auth.new_device payload missing user_id"))
+	}
+
+	locale, err := h.userRepo.GetLocale(ctx, payload.UserID)
+	if err != nil {
+		return fmt.Errorf("get locale: %w", err)
+	}
+
+	device := deviceLabel(payload.DeviceInfo)
+	if device == "" {
+		device = i18n.T(locale, "push.new_login.unknown_device")
+	}
+	title := i18n.T(locale, "push.new_login.title")
+	body := i18n.T(locale, "push.new_login.body_no_location", device)
+	if location := loginLocation(payload.City, payload.Country); location != "" {
+		body = i18n.T(locale, "push.new_login.body", device, location)
+	}
+	data := map[string]any{
+		"type":         "new_login",
+		"reason":       payload.Reason,
+		"logged_in_at": payload.LoggedInAt,
+		"deep_link":    sessionsDeepLink,
+	}
+	if payload.DeviceID != "" {
+		data["device_id"] = payload.DeviceID
+	}
+
+	if h.notificationRepo != nil {
+		notification := &models.Notification{
+			UserID: payload.UserID,
+			Type:   "new_login",
+			Title:  title,
+			Body:   &body,
+			Data:   data,
+		}
+		if err := h.notificationRepo.Create(ctx, notification); err != nil {
+			return fmt.Errorf("create new login notification: %w", err)
+		}
+	}
+
+	deviceTokens, err := h.userRepo.GetDeviceTokens(ctx, payload.UserID)
+	if err != nil {
+		return fmt.Errorf("get device tokens: %w", err)
+	}
+	if len(deviceTokens) > 0 {
+		tokens := make([]string, 0, len(deviceTokens))
+		for _, token := range deviceTokens {
+			tokens = append(tokens, token.Token)
+		}
+
+		userID := strconv.FormatUint(uint64(payload.UserID), 10)
+		if err := h.publisher.Publish(
+			ctx,
+			EventTypeNotificationPush,
+			"user",
+			userID,
+			BuildIdempotencyKey(EventTypeNotificationPush, "new_login", event.IdempotencyKey),
+			PushNotificationPayload{Tokens: tokens, Title: title, Body: body, Data: data},
+		); err != nil {
+			return fmt.Errorf("enqueue notification.push: %w", err)
+		}
+	}
+
+	slog.Info("New login alert processed", "event_id", event.ID, "user_id", payload.UserID, "reason", payload.Reason)
+	return nil
+}
+
+// deviceLabel names the kind of device a user agent belongs to ("iPhone", "Android phone",
+// "Chrome on Windows"), or returns "" when it can't tell.
+func deviceLabel(userAgent string) string {
+	ua := strings.ToLower(userAgent)
+	switch {
+	case ua == "":
+		return ""
+	case strings.Contains(ua, "iphone"):
+		return "iPhone"
+	case strings.Contains(ua, "ipad"):
+		return "iPad"
+	case strings.Contains(ua, "android"):
+		if strings.Contains(ua, "mobile") || !strings.Contains(ua, "mozilla") {
+			return "Android phone"
+		}
+		return "Android tablet"
+	}
+
+	var platform string
+	switch {
+	case strings.Contains(ua, "macintosh") || strings.Contains(ua, "mac os"):
+		platform = "Mac"
+	case strings.Contains(ua, "windows"):
+		platform = "Windows"
+	case strings.Contains(ua, "linux"):
+		platform = "Linux"
+	default:
+		return ""
+	}
+
+	// Order matters: Edge and Chrome user agents also mention Safari.
+	for _, browser := range []struct{ token, name string }{
+		{"edg/", "Edge"},
+		{"firefox/", "Firefox"},
+		{"chrome/", "Chrome"},
+		{"safari/", "Safari"},
+	} {
+		if strings.Contains(ua, browser.token) {
+			return browser.name + " on " + platform
+		}
+	}
+	return platform
+}
+
+func loginLocation(city, country string) string {
+	switch {
+	case city != "" && country != "":
+		return city + ", " + country
+	case city != "":
+		return city
+	default:
+		return country
+	}
+}
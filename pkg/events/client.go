@@ -0,0 +1,372 @@
+package events
+
+import (
+	"chalk-api/pkg/i18n"
+	"chalk-api/pkg/models"
+	"chalk-api/pkg/repositories"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// ClientAtRiskHandler tells the coach that one of their clients has gone quiet,
+// with an in-app notification and a push to their devices.
+type ClientAtRiskHandler struct {
+	userRepo         *repositories.UserRepository
+	notificationRepo *repositories.NotificationRepository
+	publisher        *Publisher
+}
+
+func NewClientAtRiskHandler(
+	userRepo *repositories.UserRepository,
+	notificationRepo *repositories.NotificationRepository,
+	publisher *Publisher,
+) *ClientAtRiskHandler {
+	return &ClientAtRiskHandler{
+		userRepo:         userRepo,
+		notificationRepo: notificationRepo,
+		publisher:        publisher,
+	}
+}
+
+func (h *ClientAtRiskHandler) Handle(ctx context.Context, event models.OutboxEvent) error {
+	var payload ClientAtRiskPayload
+	if err := json.Unmarshal([]byte(event.Payload), &payload); err != nil {
+		return Permanent(fmt.Errorf("decode client.at_risk payload: %w", err))
+	}
+	if payload.ClientID == 0 || payload.CoachUserID == 0 {
+		return Permanent(fmt.Errorf("client.at_risk payload missing client_id or coach_user_id"))
+	}
+
+	name := strings.TrimSpace(payload.ClientName)
+	if name == "" {
+		name = "A client"
+	}
+	title := "Client at risk"
+	body := fmt.Sprintf("%s has had no activity for %d days.", name, payload.InactiveDays)
+	data := map[string]any{
+		"type":             "client_at_risk",
+		"client_id":        payload.ClientID,
+		"last_activity_at": payload.LastActivityAt,
+	}
+
+	if h.notificationRepo != nil {
+		notification := &models.Notification{
+			UserID: payload.CoachUserID,
+			Type:   "client_at_risk",
+			Title:  title,
+			Body:   &body,
+			Data:   data,
+		}
+		if err := h.notificationRepo.Create(ctx, notification); err != nil {
+			return fmt.Errorf("create client at-risk notification: %w", err)
+		}
+	}
+
+	deviceTokens, err := h.userRepo.GetDeviceTokens(ctx, payload.CoachUserID)
+	if err != nil {
+		return fmt.Errorf("get device tokens: %w", err)
+	}
+	if len(deviceTokens) > 0 {
+		tokens := make([]string, 0, len(deviceTokens))
+		for _, token := range deviceTokens {
+			tokens = append(tokens, token.Token)
+		}
+
+		clientID := strconv.FormatUint(uint64(payload.ClientID), 10)
+		if err := h.publisher.Publish(
+			ctx,
+			EventTypeNotificationPush,
+			"client",
+			clientID,
+			BuildIdempotencyKey(EventTypeNotificationPush, "client_at_risk", event.IdempotencyKey),
+			PushNotificationPayload{Tokens: tokens, Title: title, Body: body, Data: data},
+		); err != nil {
+			return fmt.Errorf("enqueue notification.push: %w", err)
+		}
+	}
+
+	slog.Info("Client at-risk alert sent", "event_id", event.ID, "client_id", payload.ClientID, "coach_id", payload.CoachID)
+	return nil
+}
+
+// ClientTransferredHandler tells the client and both coaches that the client moved to a new coach,
+// with an in-app notification and a push to each of them.
+type ClientTransferredHandler struct {
+	userRepo         *repositories.UserRepository
+	notificationRepo *repositories.NotificationRepository
+	publisher        *Publisher
+}
+
+func NewClientTransferredHandler(
+	userRepo *repositories.UserRepository,
+	notificationRepo *repositories.NotificationRepository,
+	publisher *Publisher,
+) *ClientTransferredHandler {
+	return &ClientTransferredHandler{
+		userRepo:         userRepo,
+		notificationRepo: notificationRepo,
+		publisher:        publisher,
+	}
+}
+
+func (h *ClientTransferredHandler) Handle(ctx context.Context, event models.OutboxEvent) error {
+	var payload ClientTransferredPayload
+	if err := json.Unmarshal([]byte(event.Payload), &payload); err != nil {
+		return Permanent(fmt.Errorf("decode client.transferred payload: %w", err))
+	}
+	if payload.ClientUserID == 0 || payload.FromCoachUserID == 0 || payload.ToCoachUserID == 0 {
+		return Permanent(fmt.Errorf("client.transferred payload missing client or coach user IDs"))
+	}
+
+	clientName := nameOr(payload.ClientName, "A client")
+	fromCoach := nameOr(payload.FromCoachName, "your previous coach")
+	toCoach := nameOr(payload.ToCoachName, "a new coach")
+
+	recipients := []struct {
+		role   string
+		userID uint
+		body   string
+		data   map[string]any
+	}{
+		{
+			role:   "client",
+			userID: payload.ClientUserID,
+			body:   fmt.Sprintf("You're now coached by %s. Upcoming sessions and workouts with %s were cancelled.", toCoach, fromCoach),
+			data:   map[string]any{"client_id": payload.NewClientProfileID, "coach_id": payload.ToCoachID},
+		},
+		{
+			role:   "from_coach",
+			userID: payload.FromCoachUserID,
+			body:   fmt.Sprintf("%s was transferred to %s.", clientName, toCoach),
+			data:   map[string]any{"client_id": payload.OldClientProfileID},
+		},
+		{
+			role:   "to_coach",
+			userID: payload.ToCoachUserID,
+			body:   fmt.Sprintf("%s was transferred to you from %s.", clientName, fromCoach),
+			data:   map[string]any{"client_id": payload.NewClientProfileID},
+		},
+	}
+
+	title := "Client transferred"
+	for _, recipient := range recipients {
+		recipient.data["type"] = "client_transferred"
+
+		if h.notificationRepo != nil {
+			notification := &models.Notification{
+				UserID: recipient.userID,
+				Type:   "client_transferred",
+				Title:  title,
+				Body:   &recipient.body,
+				Data:   recipient.data,
+			}
+			if err := h.notificationRepo.Create(ctx, notification); err != nil {
+				return fmt.Errorf("create client transferred notification: %w", err)
+			}
+		}
+
+		deviceTokens, err := h.userRepo.GetDeviceTokens(ctx, recipient.userID)
+		if err != nil {
+			return fmt.Errorf("get device tokens: %w", err)
+		}
+		if len(deviceTokens) == 0 {
+			continue
+		}
+		tokens := make([]string, 0, len(deviceTokens))
+		for _, token := range deviceTokens {
+			tokens = append(tokens, token.Token)
+		}
+
+		clientID := strconv.FormatUint(uint64(payload.NewClientProfileID), 10)
+		if err := h.publisher.Publish(
+			ctx,
+			EventTypeNotificationPush,
+			"client",
+			clientID,
+			BuildIdempotencyKey(EventTypeNotificationPush, "client_transferred", recipient.role, event.IdempotencyKey),
+			PushNotificationPayload{Tokens: tokens, Title: title, Body: recipient.body, Data: recipient.data},
+		); err != nil {
+			return fmt.Errorf("enqueue notification.push: %w", err)
+		}
+	}
+
+	slog.Info("Client transfer notifications sent",
+		"event_id", event.ID,
+		"old_client_id", payload.OldClientProfileID,
+		"new_client_id", payload.NewClientProfileID,
+	)
+	return nil
+}
+
+// ClientStatusChangedHandler keeps the conversation's visibility in line with the relationship:
+// archived relationships drop out of inboxes and unread counts, any other status brings them back.
+type ClientStatusChangedHandler struct {
+	clientRepo  *repositories.ClientRepository
+	messageRepo *repositories.MessageRepository
+}
+
+func NewClientStatusChangedHandler(
+	clientRepo *repositories.ClientRepository,
+	messageRepo *repositories.MessageRepository,
+) *ClientStatusChangedHandler {
+	return &ClientStatusChangedHandler{
+		clientRepo:  clientRepo,
+		messageRepo: messageRepo,
+	}
+}
+
+func (h *ClientStatusChangedHandler) Handle(ctx context.Context, event models.OutboxEvent) error {
+	var payload ClientStatusChangedPayload
+	if err := json.Unmarshal([]byte(event.Payload), &payload); err != nil {
+		return Permanent(fmt.Errorf("decode client.status_changed payload: %w", err))
+	}
+	if payload.ClientID == 0 {
+		return Permanent(fmt.Errorf("client.status_changed payload missing client_id"))
+	}
+
+	// Go by the stored status rather than the payload's so events handled out of order settle on the
+	// relationship's current state.
+	client, err := h.clientRepo.GetByID(ctx, payload.ClientID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return Permanent(fmt.Errorf("client profile %d not found", payload.ClientID))
+		}
+		return fmt.Errorf("get client profile: %w", err)
+	}
+
+	active := client.Status != "archived"
+	updated, err := h.messageRepo.SetConversationActive(ctx, client.CoachID, client.ID, active)
+	if err != nil {
+		return fmt.Errorf("set conversation active: %w", err)
+	}
+
+	slog.Info("Conversation visibility synced with client status",
+		"event_id", event.ID,
+		"client_id", client.ID,
+		"status", client.Status,
+		"active", active,
+		"updated", updated,
+	)
+	return nil
+}
+
+func nameOr(name, fallback string) string {
+	if trimmed := strings.TrimSpace(name); trimmed != "" {
+		return trimmed
+	}
+	return fallback
+}
+
+// clientInviteDeepLinkFormat opens the invite preview for a code in the mobile app
+const clientInviteDeepLinkFormat = "chalk://invites/%s"
+
+// ClientInvitedHandler delivers a client import invitation. People who already have an account are
+// told in-app and by push, with their personal invite code; invitations to new emails are left for
+// email delivery.
+type ClientInvitedHandler struct {
+	coachRepo        *repositories.CoachRepository
+	userRepo         *repositories.UserRepository
+	notificationRepo *repositories.NotificationRepository
+	publisher        *Publisher
+}
+
+func NewClientInvitedHandler(
+	coachRepo *repositories.CoachRepository,
+	userRepo *repositories.UserRepository,
+	notificationRepo *repositories.NotificationRepository,
+	publisher *Publisher,
+) *ClientInvitedHandler {
+	return &ClientInvitedHandler{
+		coachRepo:        coachRepo,
+		userRepo:         userRepo,
+		notificationRepo: notificationRepo,
+		publisher:        publisher,
+	}
+}
+
+func (h *ClientInvitedHandler) Handle(ctx context.Context, event models.OutboxEvent) error {
+	var payload ClientInvitedPayload
+	if err := json.Unmarshal([]byte(event.Payload), &payload); err != nil {
+		return Permanent(fmt.Errorf("decode client.invited payload: %w", err))
+	}
+	if payload.InvitationID == 0 || payload.Code == "" {
+		return Permanent(fmt.Errorf("client.invited payload missing invitation_id or code"))
+	}
+	if payload.UserID == 0 {
+		slog.Info("Client invitation awaiting email delivery", "event_id", event.ID, "invitation_id", payload.InvitationID, "coach_id", payload.CoachID)
+		return nil
+	}
+
+	coach, err := h.coachRepo.GetByID(ctx, payload.CoachID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			slog.Info("Inviting coach no longer exists", "event_id", event.ID, "invitation_id", payload.InvitationID)
+			return nil
+		}
+		return fmt.Errorf("get coach profile: %w", err)
+	}
+	if coachUser, err := h.userRepo.GetByID(ctx, coach.UserID); err == nil {
+		coach.User = *coachUser
+	}
+
+	locale, err := h.userRepo.GetLocale(ctx, payload.UserID)
+	if err != nil {
+		return fmt.Errorf("get locale: %w", err)
+	}
+
+	title := i18n.T(locale, "push.client_invited.title")
+	body := i18n.T(locale, "push.client_invited.body", coachName(coach))
+	data := map[string]any{
+		"type":          "client_invited",
+		"invitation_id": payload.InvitationID,
+		"coach_id":      payload.CoachID,
+		"code":          payload.Code,
+		"deep_link":     fmt.Sprintf(clientInviteDeepLinkFormat, payload.Code),
+	}
+
+	if h.notificationRepo != nil {
+		notification := &models.Notification{
+			UserID: payload.UserID,
+			Type:   "client_invited",
+			Title:  title,
+			Body:   &body,
+			Data:   data,
+		}
+		if err := h.notificationRepo.Create(ctx, notification); err != nil {
+			return fmt.Errorf("create client invited notification: %w", err)
+		}
+	}
+
+	deviceTokens, err := h.userRepo.GetDeviceTokens(ctx, payload.UserID)
+	if err != nil {
+		return fmt.Errorf("get device tokens: %w", err)
+	}
+	if len(deviceTokens) > 0 {
+		tokens := make([]string, 0, len(deviceTokens))
+		for _, token := range deviceTokens {
+			tokens = append(tokens, token.Token)
+		}
+
+		invitationID := strconv.FormatUint(uint64(payload.InvitationID), 10)
+		if err := h.publisher.Publish(
+			ctx,
+			EventTypeNotificationPush,
+			"client_invitation",
+			invitationID,
+			BuildIdempotencyKey(EventTypeNotificationPush, "client_invited", event.IdempotencyKey),
+			PushNotificationPayload{Tokens: tokens, Title: title, Body: body, Data: data},
+		); err != nil {
+			return fmt.Errorf("enqueue notification.push: %w", err)
+		}
+	}
+
+	slog.Info("Client invitation delivered in-app", "event_id", event.ID, "invitation_id", payload.InvitationID, "user_id", payload.UserID)
+	return nil
+}
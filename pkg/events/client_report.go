@@ -0,0 +1,505 @@
+package events
+
+import (
+	"chalk-api/pkg/external/storage"
+	"chalk-api/pkg/models"
+	"chalk-api/pkg/pdf"
+	"chalk-api/pkg/repositories"
+	"chalk-api/pkg/utils"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ClientReportDownloadExpiry is how long the signed report link stays valid (the SigV4 maximum).
+const ClientReportDownloadExpiry = 7 * 24 * time.Hour
+
+// A logged day counts as on target when calories are within this fraction of the day's target.
+const calorieAdherenceTolerance = 0.10
+
+// ClientReportHandler renders a client's monthly progress report PDF, uploads it and notifies the coach.
+type ClientReportHandler struct {
+	reportRepo       *repositories.ClientReportRepository
+	clientRepo       *repositories.ClientRepository
+	userRepo         *repositories.UserRepository
+	workoutRepo      *repositories.WorkoutRepository
+	progressRepo     *repositories.ProgressRepository
+	nutritionRepo    *repositories.NutritionRepository
+	notificationRepo *repositories.NotificationRepository
+	storageAPI       storage.API
+	publisher        *Publisher
+}
+
+func NewClientReportHandler(
+	repos *repositories.RepositoriesCollection,
+	storageAPI storage.API,
+	publisher *Publisher,
+) *ClientReportHandler {
+	return &ClientReportHandler{
+		reportRepo:       repos.ClientReport,
+		clientRepo:       repos.Client,
+		userRepo:         repos.User,
+		workoutRepo:      repos.Workout,
+		progressRepo:     repos.Progress,
+		nutritionRepo:    repos.Nutrition,
+		notificationRepo: repos.Notification,
+		storageAPI:       storageAPI,
+		publisher:        publisher,
+	}
+}
+
+func (h *ClientReportHandler) Handle(ctx context.Context, event models.OutboxEvent) error {
+	var payload ClientReportRequestedPayload
+	if err := json.Unmarshal([]byte(event.Payload), &payload); err != nil {
+		return Permanent(fmt.Errorf("decode client.report_requested payload: %w", err))
+	}
+	if payload.ReportID == 0 || payload.CoachUserID == 0 {
+		return Permanent(fmt.Errorf("client.report_requested payload missing report_id or coach_user_id"))
+	}
+
+	report, err := h.reportRepo.GetByID(ctx, payload.ReportID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return Permanent(fmt.Errorf("client report %d not found", payload.ReportID))
+		}
+		return fmt.Errorf("get client report: %w", err)
+	}
+	// Redelivery after the PDF was already produced (or given up on) is a no-op.
+	if report.Status == models.ClientReportStatusReady || report.Status == models.ClientReportStatusFailed {
+		return nil
+	}
+
+	if err := h.reportRepo.MarkProcessing(ctx, report.ID); err != nil {
+		return fmt.Errorf("mark client report processing: %w", err)
+	}
+
+	data, err := h.gather(ctx, report)
+	if err != nil {
+		return h.fail(ctx, report.ID, err)
+	}
+	document := renderClientReport(data, time.Now().UTC())
+
+	suffix, err := utils.GenerateRandomString(16)
+	if err != nil {
+		return err
+	}
+	key := fmt.Sprintf("reports/%d/%d/%d-%s.pdf", report.CoachID, report.ClientID, report.ID, suffix)
+	if err := h.storageAPI.PutObject(ctx, key, pdf.ContentType, document); err != nil {
+		return fmt.Errorf("upload client report: %w", err)
+	}
+
+	downloadURL, err := h.storageAPI.PresignGet(key, ClientReportDownloadExpiry)
+	if err != nil {
+		return h.fail(ctx, report.ID, Permanent(fmt.Errorf("presign client report: %w", err)))
+	}
+	expiresAt := time.Now().UTC().Add(ClientReportDownloadExpiry)
+	if err := h.reportRepo.MarkReady(ctx, report.ID, key, downloadURL, expiresAt); err != nil {
+		return fmt.Errorf("mark client report ready: %w", err)
+	}
+
+	// The PDF is already listed on the reports endpoint, so notification failures are only logged.
+	h.notify(ctx, payload.CoachUserID, report, data.ClientName, downloadURL, expiresAt)
+
+	slog.Info("Client report ready", "event_id", event.ID, "report_id", report.ID, "client_id", report.ClientID, "bytes", len(document))
+	return nil
+}
+
+// fail records permanent failures on the report row so the coach can request a new one.
+// Transient errors are returned as-is and retried by the dispatcher.
+func (h *ClientReportHandler) fail(ctx context.Context, reportID uint, err error) error {
+	if !IsPermanent(err) {
+		return err
+	}
+	if markErr := h.reportRepo.MarkFailed(ctx, reportID, err.Error()); markErr != nil {
+		slog.Error("Failed to mark client report failed", "report_id", reportID, "error", markErr)
+	}
+	return err
+}
+
+// clientReportData is everything the PDF shows, already aggregated by the repositories.
+type clientReportData struct {
+	ClientName  string
+	PeriodStart string
+	PeriodEnd   string
+	Compliance  repositories.WorkoutCompliance
+	Volume      []repositories.WeeklyVolume
+	Metrics     []repositories.MetricChange
+	Nutrition   nutritionAdherence
+}
+
+type nutritionAdherence struct {
+	DaysInPeriod    int
+	DaysLogged      int
+	DaysWithTarget  int // logged days that had a calorie target in effect
+	DaysOnTarget    int // of those, days within calorieAdherenceTolerance
+	AverageCalories int
+	AverageProtein  float64
+	Daily           []repositories.DailyTotal
+	CalorieTargets  map[string]int // date -> target in effect, for the chart
+}
+
+func (h *ClientReportHandler) gather(ctx context.Context, report *models.ClientReport) (*clientReportData, error) {
+	client, err := h.clientRepo.GetByID(ctx, report.ClientID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, Permanent(fmt.Errorf("client %d not found", report.ClientID))
+		}
+		return nil, fmt.Errorf("get client: %w", err)
+	}
+
+	start, err := time.Parse("2006-01-02", dateOnly(report.PeriodStart))
+	if err != nil {
+		return nil, Permanent(fmt.Errorf("invalid report period start %q", report.PeriodStart))
+	}
+	end, err := time.Parse("2006-01-02", dateOnly(report.PeriodEnd))
+	if err != nil {
+		return nil, Permanent(fmt.Errorf("invalid report period end %q", report.PeriodEnd))
+	}
+	startDate, endDate := start.Format("2006-01-02"), end.Format("2006-01-02")
+
+	compliance, err := h.workoutRepo.GetCompliance(ctx, client.ID, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("get workout compliance: %w", err)
+	}
+	volume, err := h.workoutRepo.ListWeeklyVolume(ctx, []uint{client.ID}, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("list weekly volume: %w", err)
+	}
+	metrics, err := h.progressRepo.SummarizeMetrics(ctx, client.ID, start, end.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, fmt.Errorf("summarize body metrics: %w", err)
+	}
+	daily, err := h.nutritionRepo.ListDailyTotals(ctx, client.ID, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("list nutrition totals: %w", err)
+	}
+	targets, err := h.nutritionRepo.ListTargets(ctx, client.ID)
+	if err != nil {
+		return nil, fmt.Errorf("list nutrition targets: %w", err)
+	}
+
+	name := ""
+	if client.User.Profile != nil {
+		name = strings.TrimSpace(client.User.Profile.FirstName + " " + client.User.Profile.LastName)
+	}
+	return &clientReportData{
+		ClientName:  name,
+		PeriodStart: startDate,
+		PeriodEnd:   endDate,
+		Compliance:  *compliance,
+		Volume:      volume,
+		Metrics:     metrics,
+		Nutrition:   summarizeNutrition(daily, targets, int(end.Sub(start).Hours()/24)+1),
+	}, nil
+}
+
+// summarizeNutrition scores each logged day against the calorie target in effect that day. targets
+// are ordered newest effective date first, as ListTargets returns them.
+func summarizeNutrition(daily []repositories.DailyTotal, targets []models.NutritionTarget, daysInPeriod int) nutritionAdherence {
+	summary := nutritionAdherence{
+		DaysInPeriod:   daysInPeriod,
+		DaysLogged:     len(daily),
+		Daily:          daily,
+		CalorieTargets: make(map[string]int),
+	}
+	var calories int
+	var protein float64
+	for _, day := range daily {
+		calories += day.Calories
+		protein += day.ProteinGrams
+
+		for _, target := range targets {
+			if dateOnly(target.EffectiveDate) > day.Date {
+				continue
+			}
+			if target.Calories != nil && *target.Calories > 0 {
+				goal := *target.Calories
+				summary.CalorieTargets[day.Date] = goal
+				summary.DaysWithTarget++
+				if math.Abs(float64(day.Calories-goal)) <= float64(goal)*calorieAdherenceTolerance {
+					summary.DaysOnTarget++
+				}
+			}
+			break
+		}
+	}
+	if len(daily) > 0 {
+		summary.AverageCalories = calories / len(daily)
+		summary.AverageProtein = math.Round(protein/float64(len(daily))*10) / 10
+	}
+	return summary
+}
+
+func (h *ClientReportHandler) notify(ctx context.Context, coachUserID uint, report *models.ClientReport, clientName, downloadURL string, expiresAt time.Time) {
+	title := "Client report ready"
+	body := "The progress report is ready to download for 7 days."
+	if clientName != "" {
+		body = fmt.Sprintf("%s's progress report is ready to download for 7 days.", clientName)
+	}
+	data := map[string]any{
+		"type":       "client_report_ready",
+		"report_id":  report.ID,
+		"client_id":  report.ClientID,
+		"expires_at": expiresAt,
+	}
+
+	if h.notificationRepo != nil {
+		notification := &models.Notification{
+			UserID: coachUserID,
+			Type:   "client_report_ready",
+			Title:  title,
+			Body:   &body,
+			Data: map[string]any{
+				"report_id":    report.ID,
+				"client_id":    report.ClientID,
+				"download_url": downloadURL,
+				"expires_at":   expiresAt,
+			},
+		}
+		if err := h.notificationRepo.Create(ctx, notification); err != nil {
+			slog.Warn("Failed to create client report notification", "report_id", report.ID, "error", err)
+		}
+	}
+
+	deviceTokens, err := h.userRepo.GetDeviceTokens(ctx, coachUserID)
+	if err != nil {
+		slog.Warn("Failed to load device tokens for client report", "report_id", report.ID, "error", err)
+		return
+	}
+	if len(deviceTokens) == 0 {
+		return
+	}
+
+	tokens := make([]string, 0, len(deviceTokens))
+	for _, token := range deviceTokens {
+		tokens = append(tokens, token.Token)
+	}
+
+	id := strconv.FormatUint(uint64(report.ID), 10)
+	if err := h.publisher.Publish(
+		ctx,
+		EventTypeNotificationPush,
+		"client_report",
+		id,
+		BuildIdempotencyKey(EventTypeNotificationPush, "client_report", id),
+		PushNotificationPayload{Tokens: tokens, Title: title, Body: body, Data: data},
+	); err != nil {
+		slog.Warn("Failed to enqueue client report push", "report_id", report.ID, "error", err)
+	}
+}
+
+// dateOnly keeps the YYYY-MM-DD prefix of a date column, which can scan back as a full timestamp.
+func dateOnly(value string) string {
+	if len(value) > len("2006-01-02") {
+		return value[:len("2006-01-02")]
+	}
+	return value
+}
+
+// --- PDF layout ---
+
+const (
+	reportMargin       = 50.0
+	reportContentWidth = pdf.PageWidth - 2*reportMargin
+	reportChartHeight  = 110.0
+)
+
+var (
+	reportAccent = pdf.Color{R: 0.16, G: 0.38, B: 0.74}
+	reportGood   = pdf.Color{R: 0.2, G: 0.6, B: 0.35}
+)
+
+// renderClientReport lays the report out on one or two Letter pages: compliance, weekly volume,
+// body measurements and nutrition adherence, each with a native bar chart where it helps.
+func renderClientReport(data *clientReportData, generatedAt time.Time) []byte {
+	doc := pdf.New()
+	page := doc.AddPage()
+
+	title := "Progress report"
+	if data.ClientName != "" {
+		title += ": " + data.ClientName
+	}
+	page.Text(reportMargin, 70, 20, true, pdf.Black, title)
+	page.Text(reportMargin, 90, 10, false, pdf.Gray,
+		fmt.Sprintf("%s to %s  -  generated %s", data.PeriodStart, data.PeriodEnd, generatedAt.Format("2006-01-02")))
+	page.Line(reportMargin, 100, pdf.PageWidth-reportMargin, 100, 0.5, pdf.LightGray)
+
+	y := 130.0
+	y = drawComplianceSection(page, y, data.Compliance)
+	y = drawVolumeSection(page, y+25, data.Volume)
+
+	// Measurements and nutrition go on a second page when the first is full.
+	if y+25+sectionHeight(len(data.Metrics)) > pdf.PageHeight-reportMargin {
+		page = doc.AddPage()
+		y = reportMargin
+	}
+	y = drawMetricsSection(page, y+25, data.Metrics)
+	if y+25+reportChartHeight+90 > pdf.PageHeight-reportMargin {
+		page = doc.AddPage()
+		y = reportMargin
+	}
+	drawNutritionSection(page, y+25, data.Nutrition)
+
+	return doc.Bytes()
+}
+
+func sectionHeight(rows int) float64 {
+	return 30 + float64(rows+1)*16
+}
+
+func drawSectionTitle(page *pdf.Page, y float64, title string) float64 {
+	page.Text(reportMargin, y, 14, true, reportAccent, title)
+	return y + 22
+}
+
+func drawComplianceSection(page *pdf.Page, y float64, compliance repositories.WorkoutCompliance) float64 {
+	y = drawSectionTitle(page, y, "Workout compliance")
+	if compliance.Scheduled == 0 {
+		page.Text(reportMargin, y, 10, false, pdf.Gray, "No workouts were scheduled in this period.")
+		return y + 10
+	}
+
+	rate := float64(compliance.Completed) / float64(compliance.Scheduled)
+	page.Text(reportMargin, y, 11, false, pdf.Black, fmt.Sprintf(
+		"%d of %d scheduled workouts completed (%.0f%%), %d skipped",
+		compliance.Completed, compliance.Scheduled, rate*100, compliance.Skipped))
+
+	barY := y + 12
+	page.Rect(reportMargin, barY, reportContentWidth, 14, pdf.LightGray)
+	page.Rect(reportMargin, barY, reportContentWidth*rate, 14, reportGood)
+	return barY + 14
+}
+
+func drawVolumeSection(page *pdf.Page, y float64, weeks []repositories.WeeklyVolume) float64 {
+	y = drawSectionTitle(page, y, "Weekly training volume (kg)")
+	if len(weeks) == 0 {
+		page.Text(reportMargin, y, 10, false, pdf.Gray, "No sets were logged in this period.")
+		return y + 10
+	}
+
+	labels := make([]string, len(weeks))
+	values := make([]float64, len(weeks))
+	for i, week := range weeks {
+		labels[i] = "Wk of " + week.WeekStart[5:]
+		values[i] = week.VolumeKg
+	}
+	return drawBarChart(page, y, labels, values, nil)
+}
+
+func drawMetricsSection(page *pdf.Page, y float64, metrics []repositories.MetricChange) float64 {
+	y = drawSectionTitle(page, y, "Body measurements")
+	if len(metrics) == 0 {
+		page.Text(reportMargin, y, 10, false, pdf.Gray, "No measurements were recorded in this period.")
+		return y + 10
+	}
+
+	columns := []float64{reportMargin, reportMargin + 140, reportMargin + 220, reportMargin + 300, reportMargin + 380, reportMargin + 450}
+	headers := []string{"Measurement", "Start", "End", "Change", "Range", "Readings"}
+	for i, header := range headers {
+		page.Text(columns[i], y, 9, true, pdf.Gray, header)
+	}
+	y += 6
+	page.Line(reportMargin, y, pdf.PageWidth-reportMargin, y, 0.5, pdf.LightGray)
+	for _, metric := range metrics {
+		y += 16
+		unit := ""
+		if metric.Unit != nil {
+			unit = " " + *metric.Unit
+		}
+		change := metric.Last - metric.First
+		cells := []string{
+			strings.ReplaceAll(metric.MetricType, "_", " "),
+			formatReportNumber(metric.First) + unit,
+			formatReportNumber(metric.Last) + unit,
+			fmt.Sprintf("%+.1f%s", change, unit),
+			formatReportNumber(metric.Min) + " - " + formatReportNumber(metric.Max),
+			strconv.Itoa(metric.Readings),
+		}
+		for i, cell := range cells {
+			page.Text(columns[i], y, 10, false, pdf.Black, cell)
+		}
+	}
+	return y
+}
+
+func drawNutritionSection(page *pdf.Page, y float64, nutrition nutritionAdherence) float64 {
+	y = drawSectionTitle(page, y, "Nutrition adherence")
+	if nutrition.DaysLogged == 0 {
+		page.Text(reportMargin, y, 10, false, pdf.Gray, "No food was logged in this period.")
+		return y + 10
+	}
+
+	page.Text(reportMargin, y, 11, false, pdf.Black, fmt.Sprintf(
+		"Logged %d of %d days; averaged %d kcal and %s g protein per logged day",
+		nutrition.DaysLogged, nutrition.DaysInPeriod, nutrition.AverageCalories, formatReportNumber(nutrition.AverageProtein)))
+	y += 16
+	if nutrition.DaysWithTarget > 0 {
+		page.Text(reportMargin, y, 11, false, pdf.Black, fmt.Sprintf(
+			"Within %.0f%% of the calorie target on %d of %d days with a target",
+			calorieAdherenceTolerance*100, nutrition.DaysOnTarget, nutrition.DaysWithTarget))
+		y += 16
+	}
+
+	labels := make([]string, len(nutrition.Daily))
+	values := make([]float64, len(nutrition.Daily))
+	targets := make([]float64, len(nutrition.Daily))
+	for i, day := range nutrition.Daily {
+		labels[i] = day.Date[8:]
+		values[i] = float64(day.Calories)
+		targets[i] = float64(nutrition.CalorieTargets[day.Date])
+	}
+	return drawBarChart(page, y+4, labels, values, targets)
+}
+
+// drawBarChart draws one bar per value, scaled to the largest value (or target), with a short tick at
+// each non-zero target. Labels are thinned out when bars get narrow.
+func drawBarChart(page *pdf.Page, y float64, labels []string, values, targets []float64) float64 {
+	maxValue := 0.0
+	for i, value := range values {
+		maxValue = math.Max(maxValue, value)
+		if targets != nil {
+			maxValue = math.Max(maxValue, targets[i])
+		}
+	}
+	if maxValue <= 0 {
+		maxValue = 1
+	}
+
+	baseline := y + reportChartHeight
+	page.Text(reportMargin, y+8, 8, false, pdf.Gray, formatReportNumber(maxValue))
+	chartLeft := reportMargin + 40
+	chartWidth := reportContentWidth - 40
+	slot := chartWidth / float64(len(values))
+	barWidth := math.Min(slot*0.7, 40)
+	labelEvery := int(math.Ceil(45 / slot))
+
+	for i, value := range values {
+		x := chartLeft + float64(i)*slot + (slot-barWidth)/2
+		height := reportChartHeight * value / maxValue
+		page.Rect(x, baseline-height, barWidth, height, reportAccent)
+		if targets != nil && targets[i] > 0 {
+			targetY := baseline - reportChartHeight*targets[i]/maxValue
+			page.Line(x-1, targetY, x+barWidth+1, targetY, 1.5, reportGood)
+		}
+		if i%labelEvery == 0 {
+			page.Text(x, baseline+12, 7, false, pdf.Gray, labels[i])
+		}
+	}
+	page.Line(chartLeft, baseline, pdf.PageWidth-reportMargin, baseline, 0.5, pdf.Gray)
+	return baseline + 14
+}
+
+func formatReportNumber(value float64) string {
+	if value == math.Trunc(value) && math.Abs(value) < 1e9 {
+		return strconv.FormatInt(int64(value), 10)
+	}
+	return strconv.FormatFloat(value, 'f', 1, 64)
+}
@@ -0,0 +1,258 @@
+package events
+
+import (
+	"archive/zip"
+	"bytes"
+	"chalk-api/pkg/external/storage"
+	"chalk-api/pkg/models"
+	"chalk-api/pkg/repositories"
+	"chalk-api/pkg/utils"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DataExportDownloadExpiry is how long the signed download link stays valid (the SigV4 maximum).
+const DataExportDownloadExpiry = 7 * 24 * time.Hour
+
+// DataExportHandler builds a user's personal data archive, uploads it and notifies the user.
+type DataExportHandler struct {
+	userRepo         *repositories.UserRepository
+	coachRepo        *repositories.CoachRepository
+	clientRepo       *repositories.ClientRepository
+	workoutRepo      *repositories.WorkoutRepository
+	sessionRepo      *repositories.SessionRepository
+	nutritionRepo    *repositories.NutritionRepository
+	messageRepo      *repositories.MessageRepository
+	dataExportRepo   *repositories.DataExportRepository
+	notificationRepo *repositories.NotificationRepository
+	storageAPI       storage.API
+	publisher        *Publisher
+}
+
+func NewDataExportHandler(
+	repos *repositories.RepositoriesCollection,
+	storageAPI storage.API,
+	publisher *Publisher,
+) *DataExportHandler {
+	return &DataExportHandler{
+		userRepo:         repos.User,
+		coachRepo:        repos.Coach,
+		clientRepo:       repos.Client,
+		workoutRepo:      repos.Workout,
+		sessionRepo:      repos.Session,
+		nutritionRepo:    repos.Nutrition,
+		messageRepo:      repos.Message,
+		dataExportRepo:   repos.DataExport,
+		notificationRepo: repos.Notification,
+		storageAPI:       storageAPI,
+		publisher:        publisher,
+	}
+}
+
+func (h *DataExportHandler) Handle(ctx context.Context, event models.OutboxEvent) error {
+	var payload DataExportRequestedPayload
+	if err := json.Unmarshal([]byte(event.Payload), &payload); err != nil {
+		return Permanent(fmt.Errorf("decode user.data_export_requested payload: %w", err))
+	}
+	if payload.ExportID == 0 || payload.UserID == 0 {
+		return Permanent(fmt.Errorf("user.data_export_requested payload missing export_id or user_id"))
+	}
+
+	export, err := h.dataExportRepo.GetByID(ctx, payload.ExportID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return Permanent(fmt.Errorf("data export %d not found", payload.ExportID))
+		}
+		return fmt.Errorf("get data export: %w", err)
+	}
+	// Redelivery after the archive was already produced (or given up on) is a no-op.
+	if export.Status == models.DataExportStatusReady || export.Status == models.DataExportStatusFailed {
+		return nil
+	}
+
+	if err := h.dataExportRepo.MarkProcessing(ctx, export.ID); err != nil {
+		return fmt.Errorf("mark data export processing: %w", err)
+	}
+
+	archive, err := h.buildArchive(ctx, payload.UserID)
+	if err != nil {
+		return h.fail(ctx, export.ID, err)
+	}
+
+	suffix, err := utils.GenerateRandomString(16)
+	if err != nil {
+		return err
+	}
+	key := fmt.Sprintf("exports/%d/%d-%s.zip", payload.UserID, export.ID, suffix)
+	if err := h.storageAPI.PutObject(ctx, key, "application/zip", archive); err != nil {
+		return fmt.Errorf("upload data export: %w", err)
+	}
+
+	downloadURL, err := h.storageAPI.PresignGet(key, DataExportDownloadExpiry)
+	if err != nil {
+		return h.fail(ctx, export.ID, Permanent(fmt.Errorf("presign data export: %w", err)))
+	}
+	expiresAt := time.Now().UTC().Add(DataExportDownloadExpiry)
+	if err := h.dataExportRepo.MarkReady(ctx, export.ID, key, downloadURL, expiresAt); err != nil {
+		return fmt.Errorf("mark data export ready: %w", err)
+	}
+
+	// The archive is already available through the status endpoint, so notification failures are only logged.
+	h.notify(ctx, payload.UserID, export.ID, downloadURL, expiresAt)
+
+	slog.Info("Data export ready", "event_id", event.ID, "export_id", export.ID, "user_id", payload.UserID, "bytes", len(archive))
+	return nil
+}
+
+// fail records permanent failures on the export row so the user can request a new one.
+// Transient errors are returned as-is and retried by the dispatcher.
+func (h *DataExportHandler) fail(ctx context.Context, exportID uint, err error) error {
+	if !IsPermanent(err) {
+		return err
+	}
+	if markErr := h.dataExportRepo.MarkFailed(ctx, exportID, err.Error()); markErr != nil {
+		slog.Error("Failed to mark data export failed", "export_id", exportID, "error", markErr)
+	}
+	return err
+}
+
+func (h *DataExportHandler) buildArchive(ctx context.Context, userID uint) ([]byte, error) {
+	user, err := h.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, Permanent(fmt.Errorf("user %d not found", userID))
+		}
+		return nil, fmt.Errorf("get user: %w", err)
+	}
+
+	coachID := uint(0)
+	coachProfile, err := h.coachRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("get coach profile: %w", err)
+		}
+	} else {
+		coachID = coachProfile.ID
+	}
+
+	clientProfiles, err := h.clientRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list client profiles: %w", err)
+	}
+	clientIDs := make([]uint, 0, len(clientProfiles))
+	for _, profile := range clientProfiles {
+		clientIDs = append(clientIDs, profile.ID)
+	}
+
+	workouts, err := h.workoutRepo.ListForDataExport(ctx, clientIDs)
+	if err != nil {
+		return nil, fmt.Errorf("list workouts: %w", err)
+	}
+	sessions, err := h.sessionRepo.ListForDataExport(ctx, coachID, clientIDs)
+	if err != nil {
+		return nil, fmt.Errorf("list sessions: %w", err)
+	}
+	foodLogs, err := h.nutritionRepo.ListFoodLogsForDataExport(ctx, clientIDs)
+	if err != nil {
+		return nil, fmt.Errorf("list food logs: %w", err)
+	}
+	quickMacros, err := h.nutritionRepo.ListQuickMacrosForDataExport(ctx, clientIDs)
+	if err != nil {
+		return nil, fmt.Errorf("list quick macros: %w", err)
+	}
+	messages, err := h.messageRepo.ListBySender(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list messages: %w", err)
+	}
+
+	files := []struct {
+		name string
+		data any
+	}{
+		{"profile.json", user},
+		{"coach_profile.json", coachProfile},
+		{"client_profiles.json", clientProfiles},
+		{"workouts.json", workouts},
+		{"sessions.json", sessions},
+		{"food_logs.json", map[string]any{"entries": foodLogs, "quick_macros": quickMacros}},
+		{"messages_sent.json", messages},
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, file := range files {
+		w, err := zw.Create(file.name)
+		if err != nil {
+			return nil, fmt.Errorf("create %s: %w", file.name, err)
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(file.data); err != nil {
+			return nil, Permanent(fmt.Errorf("encode %s: %w", file.name, err))
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("close archive: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func (h *DataExportHandler) notify(ctx context.Context, userID, exportID uint, downloadURL string, expiresAt time.Time) {
+	title := "Your data export is ready"
+	body := "Download your data within 7 days."
+	data := map[string]any{
+		"type":       "data_export_ready",
+		"export_id":  exportID,
+		"expires_at": expiresAt,
+	}
+
+	if h.notificationRepo != nil {
+		notification := &models.Notification{
+			UserID: userID,
+			Type:   "data_export_ready",
+			Title:  title,
+			Body:   &body,
+			Data: map[string]any{
+				"export_id":    exportID,
+				"download_url": downloadURL,
+				"expires_at":   expiresAt,
+			},
+		}
+		if err := h.notificationRepo.Create(ctx, notification); err != nil {
+			slog.Warn("Failed to create data export notification", "export_id", exportID, "error", err)
+		}
+	}
+
+	deviceTokens, err := h.userRepo.GetDeviceTokens(ctx, userID)
+	if err != nil {
+		slog.Warn("Failed to load device tokens for data export", "export_id", exportID, "error", err)
+		return
+	}
+	if len(deviceTokens) == 0 {
+		return
+	}
+
+	tokens := make([]string, 0, len(deviceTokens))
+	for _, token := range deviceTokens {
+		tokens = append(tokens, token.Token)
+	}
+
+	id := strconv.FormatUint(uint64(exportID), 10)
+	if err := h.publisher.Publish(
+		ctx,
+		EventTypeNotificationPush,
+		"data_export",
+		id,
+		BuildIdempotencyKey(EventTypeNotificationPush, "data_export", id),
+		PushNotificationPayload{Tokens: tokens, Title: title, Body: body, Data: data},
+	); err != nil {
+		slog.Warn("Failed to enqueue data export push", "export_id", exportID, "error", err)
+	}
+}
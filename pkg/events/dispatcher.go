@@ -47,12 +47,18 @@ func IsPermanent(err error) bool {
 // Keep one handler per event_type to avoid duplicate side-effects during retries.
 type Dispatcher struct {
 	handlers map[string]Handler
+	schemas  *SchemaRegistry
 }
 
-func NewDispatcher() *Dispatcher {
+func NewDispatcher() (*Dispatcher, error) {
+	schemas, err := DefaultSchemas()
+	if err != nil {
+		return nil, err
+	}
 	return &Dispatcher{
 		handlers: make(map[string]Handler),
-	}
+		schemas:  schemas,
+	}, nil
 }
 
 func (d *Dispatcher) Register(eventType EventType, handler Handler) error {
@@ -71,5 +77,25 @@ func (d *Dispatcher) Dispatch(ctx context.Context, event models.OutboxEvent) err
 		return nil
 	}
 
+	// Handlers always see plain JSON.
+	if event.PayloadCompressed {
+		payload, err := decompressPayload(event.Payload)
+		if err != nil {
+			return Permanent(fmt.Errorf("event %d: %w", event.ID, err))
+		}
+		event.Payload = payload
+		event.PayloadCompressed = false
+	}
+
+	// ...and always in the current schema version's shape. A version this build doesn't know
+	// can't become readable by retrying, so it fails the event straight away.
+	eventType := EventType(event.EventType)
+	payload, err := d.schemas.Upgrade(eventType, event.SchemaVersion, event.Payload)
+	if err != nil {
+		return Permanent(fmt.Errorf("event %d: %w", event.ID, err))
+	}
+	event.Payload = payload
+	event.SchemaVersion = d.schemas.CurrentVersion(eventType)
+
 	return handler.Handle(ctx, event)
 }
@@ -0,0 +1,98 @@
+package events
+
+import (
+	"chalk-api/pkg/models"
+	"chalk-api/pkg/repositories"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+)
+
+// FormCheckSubmittedHandler tells the coach a client uploaded a form video for review,
+// with an in-app notification and a push to their devices.
+type FormCheckSubmittedHandler struct {
+	userRepo         *repositories.UserRepository
+	notificationRepo *repositories.NotificationRepository
+	publisher        *Publisher
+}
+
+func NewFormCheckSubmittedHandler(
+	userRepo *repositories.UserRepository,
+	notificationRepo *repositories.NotificationRepository,
+	publisher *Publisher,
+) *FormCheckSubmittedHandler {
+	return &FormCheckSubmittedHandler{
+		userRepo:         userRepo,
+		notificationRepo: notificationRepo,
+		publisher:        publisher,
+	}
+}
+
+func (h *FormCheckSubmittedHandler) Handle(ctx context.Context, event models.OutboxEvent) error {
+	var payload FormCheckSubmittedPayload
+	if err := json.Unmarshal([]byte(event.Payload), &payload); err != nil {
+		return Permanent(fmt.Errorf("decode formcheck.submitted payload: %w", err))
+	}
+	if payload.WorkoutLogID == 0 || payload.CoachUserID == 0 {
+		return Permanent(fmt.Errorf("formcheck.submitted payload missing workout_log_id or coach_user_id"))
+	}
+
+	name := strings.TrimSpace(payload.ClientName)
+	if name == "" {
+		name = "A client"
+	}
+	title := "New form check"
+	body := name + " sent a form video"
+	if payload.ExerciseName != "" {
+		body += " for " + payload.ExerciseName
+	}
+	body += "."
+	data := map[string]any{
+		"type":           "form_check_submitted",
+		"workout_log_id": payload.WorkoutLogID,
+		"workout_id":     payload.WorkoutID,
+		"client_id":      payload.ClientID,
+	}
+
+	if h.notificationRepo != nil {
+		notification := &models.Notification{
+			UserID: payload.CoachUserID,
+			Type:   "form_check_submitted",
+			Title:  title,
+			Body:   &body,
+			Data:   data,
+		}
+		if err := h.notificationRepo.Create(ctx, notification); err != nil {
+			return fmt.Errorf("create form check notification: %w", err)
+		}
+	}
+
+	deviceTokens, err := h.userRepo.GetDeviceTokens(ctx, payload.CoachUserID)
+	if err != nil {
+		return fmt.Errorf("get device tokens: %w", err)
+	}
+	if len(deviceTokens) > 0 {
+		tokens := make([]string, 0, len(deviceTokens))
+		for _, token := range deviceTokens {
+			tokens = append(tokens, token.Token)
+		}
+
+		logID := strconv.FormatUint(uint64(payload.WorkoutLogID), 10)
+		if err := h.publisher.Publish(
+			ctx,
+			EventTypeNotificationPush,
+			"workout_log",
+			logID,
+			BuildIdempotencyKey(EventTypeNotificationPush, "form_check_submitted", event.IdempotencyKey),
+			PushNotificationPayload{Tokens: tokens, Title: title, Body: body, Data: data},
+		); err != nil {
+			return fmt.Errorf("enqueue notification.push: %w", err)
+		}
+	}
+
+	slog.Info("Form check alert sent", "event_id", event.ID, "workout_log_id", payload.WorkoutLogID, "coach_id", payload.CoachID)
+	return nil
+}
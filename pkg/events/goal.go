@@ -0,0 +1,101 @@
+package events
+
+import (
+	"chalk-api/pkg/models"
+	"chalk-api/pkg/repositories"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strconv"
+)
+
+// GoalAchievedHandler keeps the coach's achieved-goal count in coach_stats current and sends a
+// congratulation, with an in-app notification and a push, to whoever didn't mark the goal achieved.
+type GoalAchievedHandler struct {
+	coachRepo        *repositories.CoachRepository
+	userRepo         *repositories.UserRepository
+	notificationRepo *repositories.NotificationRepository
+	publisher        *Publisher
+}
+
+func NewGoalAchievedHandler(
+	coachRepo *repositories.CoachRepository,
+	userRepo *repositories.UserRepository,
+	notificationRepo *repositories.NotificationRepository,
+	publisher *Publisher,
+) *GoalAchievedHandler {
+	return &GoalAchievedHandler{
+		coachRepo:        coachRepo,
+		userRepo:         userRepo,
+		notificationRepo: notificationRepo,
+		publisher:        publisher,
+	}
+}
+
+func (h *GoalAchievedHandler) Handle(ctx context.Context, event models.OutboxEvent) error {
+	var payload GoalAchievedPayload
+	if err := json.Unmarshal([]byte(event.Payload), &payload); err != nil {
+		return Permanent(fmt.Errorf("decode goal.achieved payload: %w", err))
+	}
+	if payload.GoalID == 0 || payload.CoachID == 0 || payload.CoachUserID == 0 || payload.ClientUserID == 0 {
+		return Permanent(fmt.Errorf("goal.achieved payload missing goal, coach or client IDs"))
+	}
+
+	if err := h.coachRepo.RefreshGoalStats(ctx, payload.CoachID); err != nil {
+		return fmt.Errorf("refresh coach goal stats: %w", err)
+	}
+
+	recipientID := payload.CoachUserID
+	body := fmt.Sprintf("%s achieved their goal: %s", nameOr(payload.ClientName, "A client"), payload.Title)
+	if payload.AchievedByUserID == payload.CoachUserID {
+		recipientID = payload.ClientUserID
+		body = fmt.Sprintf("Your coach marked your goal achieved: %s", payload.Title)
+	}
+
+	title := "Goal achieved!"
+	data := map[string]any{
+		"type":              "goal_achieved",
+		"goal_id":           payload.GoalID,
+		"client_profile_id": payload.ClientProfileID,
+	}
+
+	if h.notificationRepo != nil {
+		notification := &models.Notification{
+			UserID: recipientID,
+			Type:   "goal_achieved",
+			Title:  title,
+			Body:   &body,
+			Data:   data,
+		}
+		if err := h.notificationRepo.Create(ctx, notification); err != nil {
+			return fmt.Errorf("create goal achieved notification: %w", err)
+		}
+	}
+
+	deviceTokens, err := h.userRepo.GetDeviceTokens(ctx, recipientID)
+	if err != nil {
+		return fmt.Errorf("get device tokens: %w", err)
+	}
+	if len(deviceTokens) > 0 {
+		tokens := make([]string, 0, len(deviceTokens))
+		for _, token := range deviceTokens {
+			tokens = append(tokens, token.Token)
+		}
+
+		goalID := strconv.FormatUint(uint64(payload.GoalID), 10)
+		if err := h.publisher.Publish(
+			ctx,
+			EventTypeNotificationPush,
+			"goal",
+			goalID,
+			BuildIdempotencyKey(EventTypeNotificationPush, "goal_achieved", event.IdempotencyKey),
+			PushNotificationPayload{Tokens: tokens, Title: title, Body: body, Data: data},
+		); err != nil {
+			return fmt.Errorf("enqueue notification.push: %w", err)
+		}
+	}
+
+	slog.Info("Goal achievement processed", "event_id", event.ID, "goal_id", payload.GoalID, "coach_id", payload.CoachID)
+	return nil
+}
@@ -2,15 +2,20 @@ package events
 
 import (
 	"chalk-api/pkg/external"
+	"chalk-api/pkg/external/breaker"
 	"chalk-api/pkg/external/expo"
+	"chalk-api/pkg/external/storage"
+	"chalk-api/pkg/i18n"
 	"chalk-api/pkg/models"
 	"chalk-api/pkg/repositories"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"strconv"
 	"strings"
+	"time"
 )
 
 func RegisterDefaultHandlers(
@@ -18,14 +23,49 @@ func RegisterDefaultHandlers(
 	repos *repositories.RepositoriesCollection,
 	integrations *external.Collection,
 ) error {
+	// Handlers that publish follow-up events share one publisher on the same outbox
+	var publisher *Publisher
+	if repos != nil && repos.Outbox != nil {
+		var err error
+		if publisher, err = NewPublisher(repos.Outbox); err != nil {
+			return err
+		}
+	}
+
 	if integrations != nil && integrations.Expo != nil {
-		if err := dispatcher.Register(EventTypeNotificationPush, NewPushNotificationHandler(integrations.Expo)); err != nil {
+		var userRepo *repositories.UserRepository
+		var notificationRepo *repositories.NotificationRepository
+		if repos != nil {
+			userRepo, notificationRepo = repos.User, repos.Notification
+		}
+		if err := dispatcher.Register(EventTypeNotificationPush, NewPushNotificationHandler(integrations.Expo, userRepo, notificationRepo)); err != nil {
+			return err
+		}
+	}
+
+	if integrations != nil && integrations.Storage != nil && integrations.Storage.IsConfigured() {
+		if err := dispatcher.Register(EventTypeStorageObjectDelete, NewStorageObjectDeleteHandler(integrations.Storage)); err != nil {
+			return err
+		}
+	}
+
+	if integrations != nil && integrations.Storage != nil && integrations.Storage.IsConfigured() &&
+		repos != nil && repos.DataExport != nil && repos.Outbox != nil {
+		handler := NewDataExportHandler(repos, integrations.Storage, publisher)
+		if err := dispatcher.Register(EventTypeDataExportRequested, handler); err != nil {
+			return err
+		}
+	}
+
+	if integrations != nil && integrations.Storage != nil && integrations.Storage.IsConfigured() &&
+		repos != nil && repos.ClientReport != nil && repos.Outbox != nil {
+		handler := NewClientReportHandler(repos, integrations.Storage, publisher)
+		if err := dispatcher.Register(EventTypeClientReportRequest, handler); err != nil {
 			return err
 		}
 	}
 
 	if repos != nil && repos.User != nil && repos.Outbox != nil {
-		publisher := NewPublisher(repos.Outbox)
 		if err := dispatcher.Register(EventTypeMessageSent, NewMessageSentHandler(repos.User, publisher)); err != nil {
 			return err
 		}
@@ -35,21 +75,228 @@ func RegisterDefaultHandlers(
 		}
 	}
 
+	if repos != nil && repos.User != nil && repos.Outbox != nil {
+		if err := dispatcher.Register(EventTypeMessageRead, NewMessageReadHandler(repos.User, publisher)); err != nil {
+			return err
+		}
+	} else {
+		if err := dispatcher.Register(EventTypeMessageRead, NewLoggingHandler("message.read")); err != nil {
+			return err
+		}
+	}
+
+	if repos != nil && repos.User != nil && repos.Outbox != nil {
+		handler := NewSessionCancelledHandler(repos.User, repos.Notification, publisher)
+		if err := dispatcher.Register(EventTypeSessionCancelled, handler); err != nil {
+			return err
+		}
+	} else {
+		if err := dispatcher.Register(EventTypeSessionCancelled, NewLoggingHandler("session.cancelled")); err != nil {
+			return err
+		}
+	}
+
+	if repos != nil && repos.User != nil && repos.Outbox != nil {
+		handler := NewSessionDecisionHandler(repos.User, repos.Notification, publisher)
+		if err := dispatcher.Register(EventTypeSessionConfirmed, handler); err != nil {
+			return err
+		}
+		if err := dispatcher.Register(EventTypeSessionDeclined, handler); err != nil {
+			return err
+		}
+	} else {
+		if err := dispatcher.Register(EventTypeSessionConfirmed, NewLoggingHandler("session.confirmed")); err != nil {
+			return err
+		}
+		if err := dispatcher.Register(EventTypeSessionDeclined, NewLoggingHandler("session.declined")); err != nil {
+			return err
+		}
+	}
+
+	if repos != nil && repos.User != nil && repos.Outbox != nil {
+		handler := NewSessionUpdatedHandler(repos.User, repos.Notification, publisher)
+		if err := dispatcher.Register(EventTypeSessionUpdated, handler); err != nil {
+			return err
+		}
+	} else {
+		if err := dispatcher.Register(EventTypeSessionUpdated, NewLoggingHandler("session.updated")); err != nil {
+			return err
+		}
+	}
+
+	if repos != nil && repos.User != nil && repos.Outbox != nil {
+		handler := NewClientAtRiskHandler(repos.User, repos.Notification, publisher)
+		if err := dispatcher.Register(EventTypeClientAtRisk, handler); err != nil {
+			return err
+		}
+	} else {
+		if err := dispatcher.Register(EventTypeClientAtRisk, NewLoggingHandler("client.at_risk")); err != nil {
+			return err
+		}
+	}
+
+	if repos != nil && repos.User != nil && repos.Outbox != nil {
+		handler := NewFormCheckSubmittedHandler(repos.User, repos.Notification, publisher)
+		if err := dispatcher.Register(EventTypeFormCheckSubmitted, handler); err != nil {
+			return err
+		}
+	} else {
+		if err := dispatcher.Register(EventTypeFormCheckSubmitted, NewLoggingHandler("formcheck.submitted")); err != nil {
+			return err
+		}
+	}
+
+	if repos != nil && repos.User != nil && repos.Outbox != nil {
+		handler := NewClientTransferredHandler(repos.User, repos.Notification, publisher)
+		if err := dispatcher.Register(EventTypeClientTransferred, handler); err != nil {
+			return err
+		}
+	} else {
+		if err := dispatcher.Register(EventTypeClientTransferred, NewLoggingHandler("client.transferred")); err != nil {
+			return err
+		}
+	}
+
+	if repos != nil && repos.Client != nil && repos.Message != nil {
+		handler := NewClientStatusChangedHandler(repos.Client, repos.Message)
+		if err := dispatcher.Register(EventTypeClientStatusChanged, handler); err != nil {
+			return err
+		}
+	} else {
+		if err := dispatcher.Register(EventTypeClientStatusChanged, NewLoggingHandler("client.status_changed")); err != nil {
+			return err
+		}
+	}
+
+	if repos != nil && repos.Coach != nil {
+		if err := dispatcher.Register(EventTypeSessionFeedback, NewSessionFeedbackHandler(repos.Coach)); err != nil {
+			return err
+		}
+	} else {
+		if err := dispatcher.Register(EventTypeSessionFeedback, NewLoggingHandler("session.feedback_submitted")); err != nil {
+			return err
+		}
+	}
+
+	if repos != nil && repos.Coach != nil && repos.User != nil && repos.Outbox != nil {
+		handler := NewGoalAchievedHandler(repos.Coach, repos.User, repos.Notification, publisher)
+		if err := dispatcher.Register(EventTypeGoalAchieved, handler); err != nil {
+			return err
+		}
+	} else {
+		if err := dispatcher.Register(EventTypeGoalAchieved, NewLoggingHandler("goal.achieved")); err != nil {
+			return err
+		}
+	}
+
+	if repos != nil && repos.User != nil && repos.Outbox != nil {
+		handler := NewWorkoutCompletedHandler(
+			repos.Client,
+			repos.User,
+			repos.Workout,
+			repos.Progress,
+			repos.Notification,
+			publisher,
+		)
+		if err := dispatcher.Register(EventTypeWorkoutCompleted, handler); err != nil {
+			return err
+		}
+	} else {
+		if err := dispatcher.Register(EventTypeWorkoutCompleted, NewLoggingHandler("workout.completed")); err != nil {
+			return err
+		}
+	}
+
+	if repos != nil && repos.Coach != nil && repos.User != nil && repos.Outbox != nil {
+		handler := NewClientInvitedHandler(repos.Coach, repos.User, repos.Notification, publisher)
+		if err := dispatcher.Register(EventTypeClientInvited, handler); err != nil {
+			return err
+		}
+	} else {
+		if err := dispatcher.Register(EventTypeClientInvited, NewLoggingHandler("client.invited")); err != nil {
+			return err
+		}
+	}
+
+	if repos != nil && repos.User != nil && repos.Outbox != nil {
+		handler := NewMilestoneReachedHandler(repos.User, repos.Notification, publisher)
+		if err := dispatcher.Register(EventTypeMilestoneReached, handler); err != nil {
+			return err
+		}
+	} else {
+		if err := dispatcher.Register(EventTypeMilestoneReached, NewLoggingHandler("milestone.reached")); err != nil {
+			return err
+		}
+	}
+
+	if repos != nil && repos.User != nil && repos.Outbox != nil {
+		handler := NewNewDeviceLoginHandler(repos.User, repos.Notification, publisher)
+		if err := dispatcher.Register(EventTypeAuthNewDevice, handler); err != nil {
+			return err
+		}
+	} else {
+		if err := dispatcher.Register(EventTypeAuthNewDevice, NewLoggingHandler("auth.new_device")); err != nil {
+			return err
+		}
+	}
+
+	if repos != nil && repos.Client != nil && repos.User != nil && repos.Outbox != nil {
+		handler := NewWorkoutAssignedHandler(repos.Client, repos.User, repos.Notification, publisher)
+		if err := dispatcher.Register(EventTypeWorkoutAssigned, handler); err != nil {
+			return err
+		}
+	} else {
+		if err := dispatcher.Register(EventTypeWorkoutAssigned, NewLoggingHandler("workout.assigned")); err != nil {
+			return err
+		}
+	}
+
+	if repos != nil && repos.Session != nil && repos.User != nil && repos.Outbox != nil {
+		handler := NewSessionBookedHandler(repos.Session, repos.User, repos.Notification, publisher)
+		if err := dispatcher.Register(EventTypeSessionBooked, handler); err != nil {
+			return err
+		}
+	} else {
+		if err := dispatcher.Register(EventTypeSessionBooked, NewLoggingHandler("session.booked")); err != nil {
+			return err
+		}
+	}
+
+	if repos != nil && repos.User != nil && repos.Outbox != nil {
+		handler := NewReferralCompletedHandler(repos.User, repos.Notification, publisher)
+		if err := dispatcher.Register(EventTypeReferralCompleted, handler); err != nil {
+			return err
+		}
+	} else {
+		if err := dispatcher.Register(EventTypeReferralCompleted, NewLoggingHandler("referral.completed")); err != nil {
+			return err
+		}
+	}
+
 	// Domain event handlers are logging placeholders for now.
 	// These are ready to be upgraded into real side-effect handlers as services are implemented.
-	if err := dispatcher.Register(EventTypeWorkoutAssigned, NewLoggingHandler("workout.assigned")); err != nil {
+	if err := dispatcher.Register(EventTypeInviteAccepted, NewLoggingHandler("invite.accepted")); err != nil {
 		return err
 	}
-	if err := dispatcher.Register(EventTypeWorkoutCompleted, NewLoggingHandler("workout.completed")); err != nil {
+	if err := dispatcher.Register(EventTypeConnectionApproved, NewLoggingHandler("connection.approved")); err != nil {
 		return err
 	}
-	if err := dispatcher.Register(EventTypeSessionBooked, NewLoggingHandler("session.booked")); err != nil {
+	if err := dispatcher.Register(EventTypeSubscriptionChanged, NewLoggingHandler("subscription.changed")); err != nil {
 		return err
 	}
-	if err := dispatcher.Register(EventTypeInviteAccepted, NewLoggingHandler("invite.accepted")); err != nil {
+	// Conversation exports only need to land in the outbox as an audit record
+	if err := dispatcher.Register(EventTypeConversationExport, NewLoggingHandler("conversation.exported")); err != nil {
 		return err
 	}
-	if err := dispatcher.Register(EventTypeSubscriptionChanged, NewLoggingHandler("subscription.changed")); err != nil {
+	// Retention runs are likewise kept only as an audit record of what was deleted
+	if err := dispatcher.Register(EventTypeMessageRetention, NewLoggingHandler("message.retention_applied")); err != nil {
+		return err
+	}
+	// Bulk exercise edits record which admin changed the shared library and how
+	if err := dispatcher.Register(EventTypeExerciseBulkUpdate, NewLoggingHandler("exercise.bulk_updated")); err != nil {
+		return err
+	}
+	if err := dispatcher.Register(EventTypeCoachTeamInvited, NewLoggingHandler("coach_team.invited")); err != nil {
 		return err
 	}
 
@@ -93,14 +340,19 @@ func (h *MessageSentHandler) Handle(ctx context.Context, event models.OutboxEven
 		expoTokens = append(expoTokens, token.Token)
 	}
 
-	body := "You have a new message"
+	locale, err := h.userRepo.GetLocale(ctx, payload.RecipientID)
+	if err != nil {
+		return fmt.Errorf("get locale: %w", err)
+	}
+
+	body := i18n.T(locale, "push.message.body")
 	if payload.ContentPreview != nil {
 		body = *payload.ContentPreview
 	}
 
 	pushPayload := PushNotificationPayload{
 		Tokens: expoTokens,
-		Title:  "New message",
+		Title:  i18n.T(locale, "push.message.title"),
 		Body:   body,
 		Data: map[string]any{
 			"type":            "message",
@@ -125,6 +377,72 @@ func (h *MessageSentHandler) Handle(ctx context.Context, event models.OutboxEven
 	return nil
 }
 
+// MessageReadHandler sends the original sender a silent push so their app can show read receipts.
+type MessageReadHandler struct {
+	userRepo  *repositories.UserRepository
+	publisher *Publisher
+}
+
+func NewMessageReadHandler(userRepo *repositories.UserRepository, publisher *Publisher) *MessageReadHandler {
+	return &MessageReadHandler{
+		userRepo:  userRepo,
+		publisher: publisher,
+	}
+}
+
+func (h *MessageReadHandler) Handle(ctx context.Context, event models.OutboxEvent) error {
+	var payload MessageReadPayload
+	if err := json.Unmarshal([]byte(event.Payload), &payload); err != nil {
+		return Permanent(fmt.Errorf("decode message.read payload: %w", err))
+	}
+	if payload.ConversationID == 0 || payload.UpToMessageID == 0 {
+		return Permanent(fmt.Errorf("message.read payload missing conversation_id or up_to_message_id"))
+	}
+	if payload.SenderID == 0 {
+		return Permanent(fmt.Errorf("message.read payload missing sender_id"))
+	}
+
+	deviceTokens, err := h.userRepo.GetDeviceTokens(ctx, payload.SenderID)
+	if err != nil {
+		return fmt.Errorf("get device tokens: %w", err)
+	}
+	if len(deviceTokens) == 0 {
+		return nil
+	}
+
+	expoTokens := make([]string, 0, len(deviceTokens))
+	for _, token := range deviceTokens {
+		expoTokens = append(expoTokens, token.Token)
+	}
+
+	pushPayload := PushNotificationPayload{
+		Tokens: expoTokens,
+		Silent: true,
+		Data: map[string]any{
+			"type":             "message_read",
+			"conversation_id":  payload.ConversationID,
+			"reader_id":        payload.ReaderID,
+			"up_to_message_id": payload.UpToMessageID,
+			"read_at":          payload.ReadAt,
+		},
+	}
+
+	conversationID := strconv.FormatUint(uint64(payload.ConversationID), 10)
+	upToMessageID := strconv.FormatUint(uint64(payload.UpToMessageID), 10)
+	if err := h.publisher.Publish(
+		ctx,
+		EventTypeNotificationPush,
+		"conversation",
+		conversationID,
+		BuildIdempotencyKey(EventTypeNotificationPush, "message_read", conversationID, upToMessageID),
+		pushPayload,
+	); err != nil {
+		return fmt.Errorf("enqueue notification.push: %w", err)
+	}
+
+	return nil
+}
+
 func NewLoggingHandler(eventName string) Handler {
 	return HandlerFunc(func(ctx context.Context, event models.OutboxEvent) error {
 		slog.Info("Processed domain event", "event_name", eventName, "event_id", event.ID, "aggregate_id", event.AggregateID)
@@ -132,12 +450,21 @@ func NewLoggingHandler(eventName string) Handler {
 	})
 }
 
+// PushNotificationHandler sends pushes through Expo. When the repositories are set it also records
+// a PushTicket per token, stamps LastUsedAt on tokens Expo accepted, and deactivates tokens Expo
+// reports as DeviceNotRegistered.
 type PushNotificationHandler struct {
-	expoAPI expo.API
+	expoAPI          expo.API
+	userRepo         *repositories.UserRepository
+	notificationRepo *repositories.NotificationRepository
 }
 
-func NewPushNotificationHandler(expoAPI expo.API) *PushNotificationHandler {
-	return &PushNotificationHandler{expoAPI: expoAPI}
+func NewPushNotificationHandler(
+	expoAPI expo.API,
+	userRepo *repositories.UserRepository,
+	notificationRepo *repositories.NotificationRepository,
+) *PushNotificationHandler {
+	return &PushNotificationHandler{expoAPI: expoAPI, userRepo: userRepo, notificationRepo: notificationRepo}
 }
 
 func (h *PushNotificationHandler) Handle(ctx context.Context, event models.OutboxEvent) error {
@@ -149,7 +476,7 @@ func (h *PushNotificationHandler) Handle(ctx context.Context, event models.Outbo
 	if len(payload.Tokens) == 0 {
 		return Permanent(fmt.Errorf("notification payload missing tokens"))
 	}
-	if payload.Body == "" {
+	if payload.Body == "" && !payload.Silent {
 		return Permanent(fmt.Errorf("notification payload missing body"))
 	}
 
@@ -160,12 +487,28 @@ func (h *PushNotificationHandler) Handle(ctx context.Context, event models.Outbo
 		Data:  payload.Data,
 		Sound: "default",
 	}
+	if payload.Silent {
+		message = expo.PushMessage{
+			To:               payload.Tokens,
+			Data:             payload.Data,
+			Priority:         "normal",
+			ContentAvailable: true,
+		}
+	}
 
-	tickets, err := h.expoAPI.SendPush([]expo.PushMessage{message})
+	tickets, err := h.expoAPI.SendPush(ctx, []expo.PushMessage{message})
+	if errors.Is(err, breaker.ErrOpen) {
+		// Expo is down: drop the push rather than burn outbox retries on it. In-app
+		// notifications are stored separately, so the user still sees them.
+		slog.Warn("Expo circuit open, skipping push", "event_id", event.ID, "tokens", len(payload.Tokens))
+		return nil
+	}
 	if err != nil {
 		return fmt.Errorf("send expo push: %w", err)
 	}
 
+	h.recordTickets(ctx, event.ID, payload.Tokens, tickets)
+
 	var transientFailures []string
 	for _, ticket := range tickets {
 		if ticket.Status != "error" {
@@ -198,3 +541,73 @@ func (h *PushNotificationHandler) Handle(ctx context.Context, event models.Outbo
 
 	return nil
 }
+
+// recordTickets stores the send outcome per token. Expo returns tickets in recipient order, so
+// tickets[i] belongs to tokens[i]. Bookkeeping failures are logged, never returned, so they can't
+// cause the push to be sent twice.
+func (h *PushNotificationHandler) recordTickets(ctx context.Context, eventID uint, tokens []string, tickets []expo.PushTicket) {
+	if h.userRepo == nil || h.notificationRepo == nil {
+		return
+	}
+	if len(tickets) != len(tokens) {
+		slog.Warn("Expo ticket count does not match tokens, skipping ticket records",
+			"event_id", eventID, "tokens", len(tokens), "tickets", len(tickets))
+		return
+	}
+
+	now := time.Now().UTC()
+	records := make([]models.PushTicket, 0, len(tickets))
+	var accepted, notRegistered []string
+	for i, ticket := range tickets {
+		record := models.PushTicket{Token: tokens[i], Status: ticket.Status, CreatedAt: now}
+		if ticket.ID != "" {
+			id := ticket.ID
+			record.TicketID = &id
+		}
+		if ticket.Status == "ok" {
+			accepted = append(accepted, tokens[i])
+		} else if ticket.Details != nil && ticket.Details.Error != "" {
+			code := ticket.Details.Error
+			record.ErrorCode = &code
+			if code == expo.ErrorDeviceNotRegistered {
+				notRegistered = append(notRegistered, tokens[i])
+			}
+		}
+		records = append(records, record)
+	}
+
+	if err := h.notificationRepo.CreatePushTickets(ctx, records); err != nil {
+		slog.Warn("Failed to record push tickets", "event_id", eventID, "error", err)
+	}
+	if err := h.userRepo.TouchDeviceTokens(ctx, accepted, now); err != nil {
+		slog.Warn("Failed to update device token last use", "event_id", eventID, "error", err)
+	}
+	if _, err := h.userRepo.DeactivateDeviceTokens(ctx, notRegistered, models.DeviceTokenDeactivatedNotRegistered, now); err != nil {
+		slog.Warn("Failed to deactivate unregistered device tokens", "event_id", eventID, "error", err)
+	}
+}
+
+type StorageObjectDeleteHandler struct {
+	storageAPI storage.API
+}
+
+func NewStorageObjectDeleteHandler(storageAPI storage.API) *StorageObjectDeleteHandler {
+	return &StorageObjectDeleteHandler{storageAPI: storageAPI}
+}
+
+func (h *StorageObjectDeleteHandler) Handle(ctx context.Context, event models.OutboxEvent) error {
+	var payload StorageObjectDeletePayload
+	if err := json.Unmarshal([]byte(event.Payload), &payload); err != nil {
+		return Permanent(fmt.Errorf("decode storage.object_delete payload: %w", err))
+	}
+	if strings.TrimSpace(payload.Key) == "" {
+		return Permanent(fmt.Errorf("storage.object_delete payload missing key"))
+	}
+
+	if err := h.storageAPI.DeleteObject(ctx, payload.Key); err != nil {
+		return fmt.Errorf("delete storage object: %w", err)
+	}
+
+	slog.Info("Deleted storage object", "event_id", event.ID, "key", payload.Key, "reason", payload.Reason)
+	return nil
+}
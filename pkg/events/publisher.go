@@ -1,11 +1,16 @@
 package events
 
 import (
+	"bytes"
 	"chalk-api/pkg/models"
 	"chalk-api/pkg/repositories"
+	"chalk-api/pkg/tracing"
+	"compress/gzip"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"io"
 	"time"
 
 	"gorm.io/gorm"
@@ -13,11 +18,16 @@ import (
 
 // Publisher writes events into the transactional outbox.
 type Publisher struct {
-	outbox *repositories.OutboxRepository
+	outbox  *repositories.OutboxRepository
+	schemas *SchemaRegistry
 }
 
-func NewPublisher(outbox *repositories.OutboxRepository) *Publisher {
-	return &Publisher{outbox: outbox}
+func NewPublisher(outbox *repositories.OutboxRepository) (*Publisher, error) {
+	schemas, err := DefaultSchemas()
+	if err != nil {
+		return nil, err
+	}
+	return &Publisher{outbox: outbox, schemas: schemas}, nil
 }
 
 func (p *Publisher) Publish(
@@ -28,7 +38,7 @@ func (p *Publisher) Publish(
 	idempotencyKey string,
 	payload any,
 ) error {
-	event, err := buildOutboxEvent(eventType, aggregateType, aggregateID, idempotencyKey, payload)
+	event, err := p.buildOutboxEvent(ctx, eventType, aggregateType, aggregateID, idempotencyKey, payload)
 	if err != nil {
 		return err
 	}
@@ -44,14 +54,41 @@ func (p *Publisher) PublishInTx(
 	idempotencyKey string,
 	payload any,
 ) error {
-	event, err := buildOutboxEvent(eventType, aggregateType, aggregateID, idempotencyKey, payload)
+	event, err := p.buildOutboxEvent(ctx, eventType, aggregateType, aggregateID, idempotencyKey, payload)
 	if err != nil {
 		return err
 	}
 	return p.outbox.EnqueueTx(ctx, tx, event)
 }
 
-func buildOutboxEvent(
+// PendingEvent is one event for PublishManyInTx, with the same fields PublishInTx takes.
+type PendingEvent struct {
+	EventType      EventType
+	AggregateType  string
+	AggregateID    string
+	IdempotencyKey string
+	Payload        any
+}
+
+// PublishManyInTx writes several events in the caller's transaction using batched inserts, for paths
+// that fan out hundreds of events at once. Duplicate idempotency keys are skipped as in PublishInTx.
+func (p *Publisher) PublishManyInTx(ctx context.Context, tx *gorm.DB, pending []PendingEvent) error {
+	outboxEvents := make([]*models.OutboxEvent, 0, len(pending))
+	for _, item := range pending {
+		event, err := p.buildOutboxEvent(ctx, item.EventType, item.AggregateType, item.AggregateID, item.IdempotencyKey, item.Payload)
+		if err != nil {
+			return err
+		}
+		outboxEvents = append(outboxEvents, event)
+	}
+	return p.outbox.EnqueueManyTx(ctx, tx, outboxEvents)
+}
+
+// buildOutboxEvent prepares the row for an event, stamped with the payload's current schema version
+// so a later binary knows how to read it. The trace context of ctx, if any, is kept in the
+// row's metadata so the worker's processing span can link back to the request that published it.
+func (p *Publisher) buildOutboxEvent(
+	ctx context.Context,
 	eventType EventType,
 	aggregateType string,
 	aggregateID string,
@@ -63,13 +100,68 @@ func buildOutboxEvent(
 		return nil, fmt.Errorf("marshal outbox payload: %w", err)
 	}
 
-	return &models.OutboxEvent{
+	event := &models.OutboxEvent{
 		EventType:      string(eventType),
 		AggregateType:  aggregateType,
 		AggregateID:    aggregateID,
 		IdempotencyKey: idempotencyKey,
+		SchemaVersion:  p.schemas.CurrentVersion(eventType),
 		Payload:        string(raw),
 		Status:         models.OutboxStatusPending,
 		AvailableAt:    time.Now().UTC(),
-	}, nil
+	}
+	if traceContext := tracing.Inject(ctx); len(traceContext) > 0 {
+		event.Metadata = traceContext
+	}
+	if len(raw) > compressPayloadThreshold {
+		compressed, err := compressPayload(raw)
+		if err != nil {
+			return nil, err
+		}
+		event.Payload = compressed
+		event.PayloadCompressed = true
+	}
+	return event, nil
+}
+
+// compressPayloadThreshold is the payload size in bytes above which events are stored gzipped.
+const compressPayloadThreshold = 8 * 1024
+
+// compressPayload gzips raw and returns it as a base64 JSON string so it still fits the jsonb column.
+func compressPayload(raw []byte) (string, error) {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(raw); err != nil {
+		return "", fmt.Errorf("compress outbox payload: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return "", fmt.Errorf("compress outbox payload: %w", err)
+	}
+	encoded, err := json.Marshal(base64.StdEncoding.EncodeToString(buf.Bytes()))
+	if err != nil {
+		return "", fmt.Errorf("compress outbox payload: %w", err)
+	}
+	return string(encoded), nil
+}
+
+// decompressPayload reverses compressPayload, returning the original JSON.
+func decompressPayload(stored string) (string, error) {
+	var encoded string
+	if err := json.Unmarshal([]byte(stored), &encoded); err != nil {
+		return "", fmt.Errorf("decode compressed payload: %w", err)
+	}
+	compressed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", fmt.Errorf("decode compressed payload: %w", err)
+	}
+	reader, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return "", fmt.Errorf("decompress payload: %w", err)
+	}
+	defer reader.Close()
+	raw, err := io.ReadAll(reader)
+	if err != nil {
+		return "", fmt.Errorf("decompress payload: %w", err)
+	}
+	return string(raw), nil
 }
@@ -0,0 +1,90 @@
+package events_test
+
+import (
+	"chalk-api/pkg/events"
+	"chalk-api/pkg/repositories"
+	"chalk-api/pkg/testutil"
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const benchmarkEventsPerTx = 500
+
+// benchmarkRun keeps idempotency keys unique across b.N iterations and sub-benchmarks.
+var benchmarkRun int
+
+// atRiskEvents builds one at-risk sweep's worth of events, the fan-out PublishManyInTx was added for.
+func atRiskEvents() []events.PendingEvent {
+	benchmarkRun++
+	run := strconv.Itoa(benchmarkRun)
+	lastActivity := time.Now().Add(-10 * 24 * time.Hour)
+	pending := make([]events.PendingEvent, 0, benchmarkEventsPerTx)
+	for i := 0; i < benchmarkEventsPerTx; i++ {
+		id := strconv.Itoa(i + 1)
+		pending = append(pending, events.PendingEvent{
+			EventType:      events.EventTypeClientAtRisk,
+			AggregateType:  "client",
+			AggregateID:    id,
+			IdempotencyKey: events.BuildIdempotencyKey(events.EventTypeClientAtRisk, "client", id, run),
+			Payload: events.ClientAtRiskPayload{
+				ClientID:       uint(i + 1),
+				CoachID:        1,
+				CoachUserID:    1,
+				ClientName:     "Client " + id,
+				LastActivityAt: &lastActivity,
+				InactiveDays:   7,
+			},
+		})
+	}
+	return pending
+}
+
+// BenchmarkPublish500EventsInOneTx compares the row-per-event path every publisher used before
+// PublishManyInTx with the batched insert. It needs TEST_DATABASE_URL.
+func BenchmarkPublish500EventsInOneTx(b *testing.B) {
+	gormDB := testutil.OpenDB(b)
+	publisher, err := events.NewPublisher(repositories.NewOutboxRepository(gormDB))
+	if err != nil {
+		b.Fatal(err)
+	}
+	ctx := context.Background()
+
+	b.Run("PublishInTx", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			b.StopTimer()
+			pending := atRiskEvents()
+			b.StartTimer()
+
+			err := gormDB.Transaction(func(tx *gorm.DB) error {
+				for _, event := range pending {
+					if err := publisher.PublishInTx(ctx, tx, event.EventType, event.AggregateType, event.AggregateID, event.IdempotencyKey, event.Payload); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+			if err != nil {
+				b.Fatalf("publish: %v", err)
+			}
+		}
+	})
+
+	b.Run("PublishManyInTx", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			b.StopTimer()
+			pending := atRiskEvents()
+			b.StartTimer()
+
+			err := gormDB.Transaction(func(tx *gorm.DB) error {
+				return publisher.PublishManyInTx(ctx, tx, pending)
+			})
+			if err != nil {
+				b.Fatalf("publish: %v", err)
+			}
+		}
+	})
+}
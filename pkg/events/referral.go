@@ -0,0 +1,103 @@
+package events
+
+import (
+	"chalk-api/pkg/i18n"
+	"chalk-api/pkg/models"
+	"chalk-api/pkg/repositories"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strconv"
+)
+
+// referralsDeepLink opens the coach's referral screen with their code and credit balance
+const referralsDeepLink = "chalk://coach/referrals"
+
+// ReferralCompletedHandler tells the referring coach, in-app and by push, that a coach they referred
+// finished onboarding. Billing reads credits from the ledger rather than from this event.
+type ReferralCompletedHandler struct {
+	userRepo         *repositories.UserRepository
+	notificationRepo *repositories.NotificationRepository
+	publisher        *Publisher
+}
+
+func NewReferralCompletedHandler(
+	userRepo *repositories.UserRepository,
+	notificationRepo *repositories.NotificationRepository,
+	publisher *Publisher,
+) *ReferralCompletedHandler {
+	return &ReferralCompletedHandler{
+		userRepo:         userRepo,
+		notificationRepo: notificationRepo,
+		publisher:        publisher,
+	}
+}
+
+func (h *ReferralCompletedHandler) Handle(ctx context.Context, event models.OutboxEvent) error {
+	var payload ReferralCompletedPayload
+	if err := json.Unmarshal([]byte(event.Payload), &payload); err != nil {
+		return Permanent(fmt.Errorf("decode referral.completed payload: %w", err))
+	}
+	if payload.ReferralID == 0 || payload.ReferrerUserID == 0 {
+		return Permanent(fmt.Errorf("referral.completed payload missing referral or referrer IDs"))
+	}
+
+	locale, err := h.userRepo.GetLocale(ctx, payload.ReferrerUserID)
+	if err != nil {
+		return fmt.Errorf("get locale: %w", err)
+	}
+
+	name := payload.ReferredCoachName
+	if name == "" {
+		name = i18n.T(locale, "push.referral_completed.unnamed")
+	}
+	title := i18n.T(locale, "push.referral_completed.title")
+	body := i18n.T(locale, "push.referral_completed.body", name)
+	data := map[string]any{
+		"type":              "referral_completed",
+		"referral_id":       payload.ReferralID,
+		"referred_coach_id": payload.ReferredCoachID,
+		"amount_cents":      payload.AmountCents,
+		"deep_link":         referralsDeepLink,
+	}
+
+	if h.notificationRepo != nil {
+		notification := &models.Notification{
+			UserID: payload.ReferrerUserID,
+			Type:   "referral_completed",
+			Title:  title,
+			Body:   &body,
+			Data:   data,
+		}
+		if err := h.notificationRepo.Create(ctx, notification); err != nil {
+			return fmt.Errorf("create referral notification: %w", err)
+		}
+	}
+
+	deviceTokens, err := h.userRepo.GetDeviceTokens(ctx, payload.ReferrerUserID)
+	if err != nil {
+		return fmt.Errorf("get device tokens: %w", err)
+	}
+	if len(deviceTokens) > 0 {
+		tokens := make([]string, 0, len(deviceTokens))
+		for _, token := range deviceTokens {
+			tokens = append(tokens, token.Token)
+		}
+
+		referralID := strconv.FormatUint(uint64(payload.ReferralID), 10)
+		if err := h.publisher.Publish(
+			ctx,
+			EventTypeNotificationPush,
+			"referral",
+			referralID,
+			BuildIdempotencyKey(EventTypeNotificationPush, "referral_completed", event.IdempotencyKey),
+			PushNotificationPayload{Tokens: tokens, Title: title, Body: body, Data: data},
+		); err != nil {
+			return fmt.Errorf("enqueue notification.push: %w", err)
+		}
+	}
+
+	slog.Info("Referral completion processed", "event_id", event.ID, "referral_id", payload.ReferralID, "referrer_coach_id", payload.ReferrerCoachID)
+	return nil
+}
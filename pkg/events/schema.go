@@ -0,0 +1,155 @@
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrUnknownSchemaVersion is returned by Upgrade for a payload version the registry has no decoder
+// for, typically an event written by a newer binary during a rollback.
+var ErrUnknownSchemaVersion = errors.New("unknown event schema version")
+
+// PayloadDecoder turns a stored payload of one schema version into the current payload struct for
+// its event type. Decoders for older versions do the upgrade, filling or renaming fields as needed.
+type PayloadDecoder func(raw []byte) (any, error)
+
+type schemaKey struct {
+	eventType EventType
+	version   int
+}
+
+// SchemaRegistry maps (event type, schema version) to the decoder for payloads of that version.
+// The highest registered version of a type is its current version, which the Publisher stamps on
+// new events. Event types without any registered version are passed through untouched.
+type SchemaRegistry struct {
+	decoders map[schemaKey]PayloadDecoder
+	current  map[EventType]int
+}
+
+func NewSchemaRegistry() *SchemaRegistry {
+	return &SchemaRegistry{
+		decoders: make(map[schemaKey]PayloadDecoder),
+		current:  make(map[EventType]int),
+	}
+}
+
+// Register adds the decoder for eventType payloads written at version (1 or higher).
+func (r *SchemaRegistry) Register(eventType EventType, version int, decoder PayloadDecoder) error {
+	if version < 1 {
+		return fmt.Errorf("invalid schema version %d for event type %s", version, eventType)
+	}
+	key := schemaKey{eventType: eventType, version: version}
+	if _, exists := r.decoders[key]; exists {
+		return fmt.Errorf("decoder already registered for event type %s version %d", eventType, version)
+	}
+	r.decoders[key] = decoder
+	if version > r.current[eventType] {
+		r.current[eventType] = version
+	}
+	return nil
+}
+
+// CurrentVersion returns the version new eventType payloads are written at; 1 for unregistered types.
+func (r *SchemaRegistry) CurrentVersion(eventType EventType) int {
+	if version, ok := r.current[eventType]; ok {
+		return version
+	}
+	return 1
+}
+
+// Upgrade rewrites a stored payload of the given version as JSON of the current version. Payloads
+// of types without a registered schema are returned as they are.
+func (r *SchemaRegistry) Upgrade(eventType EventType, version int, raw string) (string, error) {
+	current, ok := r.current[eventType]
+	if !ok {
+		return raw, nil
+	}
+	if version == 0 {
+		// Rows enqueued before schema_version existed
+		version = 1
+	}
+	decoder, ok := r.decoders[schemaKey{eventType: eventType, version: version}]
+	if !ok {
+		return "", fmt.Errorf("%w: %s version %d (this build reads up to %d)", ErrUnknownSchemaVersion, eventType, version, current)
+	}
+
+	payload, err := decoder([]byte(raw))
+	if err != nil {
+		return "", fmt.Errorf("decode %s version %d payload: %w", eventType, version, err)
+	}
+	upgraded, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("encode %s payload: %w", eventType, err)
+	}
+	return string(upgraded), nil
+}
+
+// DecodeAs is the tolerant decoder for payloads already in T's shape: unknown fields are ignored and
+// missing ones are left at their zero value, so events from the previous deploy still decode.
+func DecodeAs[T any](raw []byte) (any, error) {
+	var payload T
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+// defaultSchemas is the registry used by the Publisher and Dispatcher; their constructors return
+// defaultSchemasErr so a bad schema definition stops startup.
+var defaultSchemas, defaultSchemasErr = newDefaultSchemas()
+
+// DefaultSchemas returns the registry of payload versions this build writes and reads, or the error
+// building it.
+func DefaultSchemas() (*SchemaRegistry, error) {
+	if defaultSchemasErr != nil {
+		return nil, fmt.Errorf("build default event schemas: %w", defaultSchemasErr)
+	}
+	return defaultSchemas, nil
+}
+
+// newDefaultSchemas registers version 1 of every payload struct. Changing a payload in a way old
+// events can't decode into means registering version 2 with the new struct and a version 1 decoder
+// that upgrades the old shape. subscription.webhook_received carries the provider's own body and is
+// left unversioned.
+func newDefaultSchemas() (*SchemaRegistry, error) {
+	registry := NewSchemaRegistry()
+	var errs []error
+	for eventType, decoder := range map[EventType]PayloadDecoder{
+		EventTypeMessageSent:         DecodeAs[MessageSentPayload],
+		EventTypeMessageRead:         DecodeAs[MessageReadPayload],
+		EventTypeWorkoutAssigned:     DecodeAs[WorkoutAssignedPayload],
+		EventTypeWorkoutCompleted:    DecodeAs[WorkoutCompletedPayload],
+		EventTypeSessionBooked:       DecodeAs[SessionBookedPayload],
+		EventTypeSessionCancelled:    DecodeAs[SessionCancelledPayload],
+		EventTypeSessionConfirmed:    DecodeAs[SessionDecisionPayload],
+		EventTypeSessionDeclined:     DecodeAs[SessionDecisionPayload],
+		EventTypeSessionUpdated:      DecodeAs[SessionUpdatedPayload],
+		EventTypeSessionFeedback:     DecodeAs[SessionFeedbackPayload],
+		EventTypeInviteAccepted:      DecodeAs[InviteAcceptedPayload],
+		EventTypeConnectionApproved:  DecodeAs[ConnectionApprovedPayload],
+		EventTypeSubscriptionChanged: DecodeAs[SubscriptionChangedPayload],
+		EventTypeNotificationPush:    DecodeAs[PushNotificationPayload],
+		EventTypeStorageObjectDelete: DecodeAs[StorageObjectDeletePayload],
+		EventTypeDataExportRequested: DecodeAs[DataExportRequestedPayload],
+		EventTypeClientAtRisk:        DecodeAs[ClientAtRiskPayload],
+		EventTypeFormCheckSubmitted:  DecodeAs[FormCheckSubmittedPayload],
+		EventTypeClientTransferred:   DecodeAs[ClientTransferredPayload],
+		EventTypeGoalAchieved:        DecodeAs[GoalAchievedPayload],
+		EventTypeClientStatusChanged: DecodeAs[ClientStatusChangedPayload],
+		EventTypeClientReportRequest: DecodeAs[ClientReportRequestedPayload],
+		EventTypeAuthNewDevice:       DecodeAs[AuthNewDevicePayload],
+		EventTypeConversationExport:  DecodeAs[ConversationExportedPayload],
+		EventTypeCoachTeamInvited:    DecodeAs[CoachTeamInvitedPayload],
+		EventTypeReferralCompleted:   DecodeAs[ReferralCompletedPayload],
+		EventTypeMilestoneReached:    DecodeAs[MilestoneReachedPayload],
+		EventTypeClientInvited:       DecodeAs[ClientInvitedPayload],
+		EventTypeMessageRetention:    DecodeAs[MessageRetentionAppliedPayload],
+		EventTypeExerciseBulkUpdate:  DecodeAs[ExerciseBulkUpdatedPayload],
+	} {
+		if err := registry.Register(eventType, 1, decoder); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return registry, errors.Join(errs...)
+}
@@ -0,0 +1,145 @@
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestDefaultSchemasRegister(t *testing.T) {
+	registry, err := newDefaultSchemas()
+	if err != nil {
+		t.Fatalf("default schemas: %v", err)
+	}
+	if len(registry.current) == 0 {
+		t.Fatal("default registry has no event types")
+	}
+	if _, ok := registry.current[EventTypeSubscriptionWebhook]; ok {
+		t.Error("subscription.webhook_received carries the provider's body and should stay unversioned")
+	}
+
+	// Rows written before schema_version existed decode as version 1.
+	raw := `{"message_id":7,"conversation_id":3,"sender_id":1,"recipient_id":2,"content_preview":"hi"}`
+	upgraded, err := registry.Upgrade(EventTypeMessageSent, 0, raw)
+	if err != nil {
+		t.Fatalf("upgrade message.sent: %v", err)
+	}
+	var payload MessageSentPayload
+	if err := json.Unmarshal([]byte(upgraded), &payload); err != nil {
+		t.Fatalf("decode upgraded payload: %v", err)
+	}
+	if payload.MessageID != 7 || payload.RecipientID != 2 || payload.ContentPreview == nil || *payload.ContentPreview != "hi" {
+		t.Fatalf("upgraded payload = %+v", payload)
+	}
+}
+
+func TestConstructorsReturnSchemaErrors(t *testing.T) {
+	if _, err := NewDispatcher(); err != nil {
+		t.Fatalf("NewDispatcher: %v", err)
+	}
+
+	broken := errors.New("decoder already registered")
+	defaultSchemasErr = broken
+	defer func() { defaultSchemasErr = nil }()
+
+	if _, err := NewDispatcher(); !errors.Is(err, broken) {
+		t.Errorf("NewDispatcher with a broken registry = %v, want the registry error", err)
+	}
+	if _, err := NewPublisher(nil); !errors.Is(err, broken) {
+		t.Errorf("NewPublisher with a broken registry = %v, want the registry error", err)
+	}
+}
+
+// sessionBookedV2 stands in for a future payload that replaced a single client with a list.
+type sessionBookedV2 struct {
+	SessionID uint   `json:"session_id"`
+	ClientIDs []uint `json:"client_ids"`
+	Source    string `json:"source"`
+}
+
+// v2Registry registers the v2 payload and a version 1 decoder that upgrades the old shape into it.
+func v2Registry(t *testing.T) *SchemaRegistry {
+	t.Helper()
+	registry := NewSchemaRegistry()
+	upgradeV1 := func(raw []byte) (any, error) {
+		var v1 struct {
+			SessionID uint `json:"session_id"`
+			ClientID  uint `json:"client_id"`
+		}
+		if err := json.Unmarshal(raw, &v1); err != nil {
+			return nil, err
+		}
+		return sessionBookedV2{SessionID: v1.SessionID, ClientIDs: []uint{v1.ClientID}, Source: "legacy"}, nil
+	}
+	if err := registry.Register(EventTypeSessionBooked, 1, upgradeV1); err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.Register(EventTypeSessionBooked, 2, DecodeAs[sessionBookedV2]); err != nil {
+		t.Fatal(err)
+	}
+	return registry
+}
+
+func TestUpgradeDecodesStoredV1PayloadAsV2(t *testing.T) {
+	registry := v2Registry(t)
+	if got := registry.CurrentVersion(EventTypeSessionBooked); got != 2 {
+		t.Fatalf("current version = %d, want 2", got)
+	}
+
+	tests := []struct {
+		name    string
+		version int
+		raw     string
+		want    sessionBookedV2
+	}{
+		{"stored v1", 1, `{"session_id":9,"client_id":4,"coach_id":1}`, sessionBookedV2{SessionID: 9, ClientIDs: []uint{4}, Source: "legacy"}},
+		{"before schema_version", 0, `{"session_id":9,"client_id":4}`, sessionBookedV2{SessionID: 9, ClientIDs: []uint{4}, Source: "legacy"}},
+		{"current v2", 2, `{"session_id":9,"client_ids":[4,5],"source":"app","extra":true}`, sessionBookedV2{SessionID: 9, ClientIDs: []uint{4, 5}, Source: "app"}},
+	}
+	for _, tt := range tests {
+		upgraded, err := registry.Upgrade(EventTypeSessionBooked, tt.version, tt.raw)
+		if err != nil {
+			t.Fatalf("%s: upgrade: %v", tt.name, err)
+		}
+		var got sessionBookedV2
+		if err := json.Unmarshal([]byte(upgraded), &got); err != nil {
+			t.Fatalf("%s: decode %s: %v", tt.name, upgraded, err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Fatalf("%s: upgraded = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestUpgradeRejectsNewerVersionsAndPassesUnversionedTypes(t *testing.T) {
+	registry := v2Registry(t)
+
+	if _, err := registry.Upgrade(EventTypeSessionBooked, 3, `{}`); !errors.Is(err, ErrUnknownSchemaVersion) {
+		t.Errorf("upgrade of version 3 = %v, want ErrUnknownSchemaVersion", err)
+	}
+	if _, err := registry.Upgrade(EventTypeSessionBooked, 1, `{"session_id":`); err == nil {
+		t.Error("upgrade of a truncated payload succeeded")
+	}
+
+	raw := `{"anything":"goes"}`
+	if got, err := registry.Upgrade(EventTypeSubscriptionWebhook, 5, raw); err != nil || got != raw {
+		t.Errorf("unversioned type upgrade = %q, %v; want the payload untouched", got, err)
+	}
+	if got := registry.CurrentVersion(EventTypeSubscriptionWebhook); got != 1 {
+		t.Errorf("unversioned type current version = %d, want 1", got)
+	}
+}
+
+func TestRegisterRejectsBadVersions(t *testing.T) {
+	registry := NewSchemaRegistry()
+	if err := registry.Register(EventTypeMessageSent, 0, DecodeAs[MessageSentPayload]); err == nil {
+		t.Error("registered version 0")
+	}
+	if err := registry.Register(EventTypeMessageSent, 1, DecodeAs[MessageSentPayload]); err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.Register(EventTypeMessageSent, 1, DecodeAs[MessageSentPayload]); err == nil {
+		t.Error("registered version 1 twice")
+	}
+}
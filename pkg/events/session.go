@@ -0,0 +1,506 @@
+package events
+
+import (
+	"chalk-api/pkg/calendar"
+	"chalk-api/pkg/models"
+	"chalk-api/pkg/repositories"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// SessionCancelledHandler tells every participant of a coach-cancelled session about it,
+// with an in-app notification and a push to their devices.
+type SessionCancelledHandler struct {
+	userRepo         *repositories.UserRepository
+	notificationRepo *repositories.NotificationRepository
+	publisher        *Publisher
+}
+
+func NewSessionCancelledHandler(
+	userRepo *repositories.UserRepository,
+	notificationRepo *repositories.NotificationRepository,
+	publisher *Publisher,
+) *SessionCancelledHandler {
+	return &SessionCancelledHandler{
+		userRepo:         userRepo,
+		notificationRepo: notificationRepo,
+		publisher:        publisher,
+	}
+}
+
+func (h *SessionCancelledHandler) Handle(ctx context.Context, event models.OutboxEvent) error {
+	var payload SessionCancelledPayload
+	if err := json.Unmarshal([]byte(event.Payload), &payload); err != nil {
+		return Permanent(fmt.Errorf("decode session.cancelled payload: %w", err))
+	}
+	if payload.SessionID == 0 {
+		return Permanent(fmt.Errorf("session.cancelled payload missing session_id"))
+	}
+
+	title := "Session cancelled"
+	body := "Your session on " + payload.ScheduledAt.UTC().Format("Jan 2 at 15:04 UTC") + " was cancelled."
+	if payload.Reason != "" && payload.Reason != "cancelled" {
+		body += " Reason: " + payload.Reason
+	}
+	data := map[string]any{
+		"type":         "session_cancelled",
+		"session_id":   payload.SessionID,
+		"scheduled_at": payload.ScheduledAt,
+	}
+
+	sessionID := strconv.FormatUint(uint64(payload.SessionID), 10)
+	for _, userID := range payload.ParticipantUserIDs {
+		if h.notificationRepo != nil {
+			notification := &models.Notification{
+				UserID: userID,
+				Type:   "session_cancelled",
+				Title:  title,
+				Body:   &body,
+				Data:   data,
+			}
+			// Redelivery may duplicate the inbox entry; that beats dropping it.
+			if err := h.notificationRepo.Create(ctx, notification); err != nil {
+				return fmt.Errorf("create session cancelled notification: %w", err)
+			}
+		}
+
+		deviceTokens, err := h.userRepo.GetDeviceTokens(ctx, userID)
+		if err != nil {
+			return fmt.Errorf("get device tokens: %w", err)
+		}
+		if len(deviceTokens) == 0 {
+			continue
+		}
+
+		tokens := make([]string, 0, len(deviceTokens))
+		for _, token := range deviceTokens {
+			tokens = append(tokens, token.Token)
+		}
+
+		userKey := strconv.FormatUint(uint64(userID), 10)
+		if err := h.publisher.Publish(
+			ctx,
+			EventTypeNotificationPush,
+			"session",
+			sessionID,
+			BuildIdempotencyKey(EventTypeNotificationPush, "session_cancelled", sessionID, userKey),
+			PushNotificationPayload{Tokens: tokens, Title: title, Body: body, Data: data},
+		); err != nil {
+			return fmt.Errorf("enqueue notification.push: %w", err)
+		}
+	}
+
+	slog.Info("Session cancellation fanned out", "event_id", event.ID, "session_id", payload.SessionID, "participants", len(payload.ParticipantUserIDs))
+	return nil
+}
+
+// SessionFeedbackHandler keeps the coach's average rating and rating count in coach_stats current.
+type SessionFeedbackHandler struct {
+	coachRepo *repositories.CoachRepository
+}
+
+func NewSessionFeedbackHandler(coachRepo *repositories.CoachRepository) *SessionFeedbackHandler {
+	return &SessionFeedbackHandler{coachRepo: coachRepo}
+}
+
+func (h *SessionFeedbackHandler) Handle(ctx context.Context, event models.OutboxEvent) error {
+	var payload SessionFeedbackPayload
+	if err := json.Unmarshal([]byte(event.Payload), &payload); err != nil {
+		return Permanent(fmt.Errorf("decode session.feedback_submitted payload: %w", err))
+	}
+	if payload.CoachID == 0 {
+		return Permanent(fmt.Errorf("session.feedback_submitted payload missing coach_id"))
+	}
+
+	if err := h.coachRepo.RefreshRatingStats(ctx, payload.CoachID); err != nil {
+		return fmt.Errorf("refresh coach rating stats: %w", err)
+	}
+
+	slog.Info("Coach rating refreshed", "event_id", event.ID, "coach_id", payload.CoachID, "session_id", payload.SessionID)
+	return nil
+}
+
+// SessionDecisionHandler tells the booked clients that the coach confirmed or declined their
+// pending booking, with an in-app notification and a push to their devices.
+type SessionDecisionHandler struct {
+	userRepo         *repositories.UserRepository
+	notificationRepo *repositories.NotificationRepository
+	publisher        *Publisher
+}
+
+func NewSessionDecisionHandler(
+	userRepo *repositories.UserRepository,
+	notificationRepo *repositories.NotificationRepository,
+	publisher *Publisher,
+) *SessionDecisionHandler {
+	return &SessionDecisionHandler{
+		userRepo:         userRepo,
+		notificationRepo: notificationRepo,
+		publisher:        publisher,
+	}
+}
+
+func (h *SessionDecisionHandler) Handle(ctx context.Context, event models.OutboxEvent) error {
+	var payload SessionDecisionPayload
+	if err := json.Unmarshal([]byte(event.Payload), &payload); err != nil {
+		return Permanent(fmt.Errorf("decode %s payload: %w", event.EventType, err))
+	}
+	if payload.SessionID == 0 {
+		return Permanent(fmt.Errorf("%s payload missing session_id", event.EventType))
+	}
+
+	when := payload.ScheduledAt.UTC().Format("Jan 2 at 15:04 UTC")
+	var notificationType, title, body string
+	switch EventType(event.EventType) {
+	case EventTypeSessionConfirmed:
+		notificationType = "session_confirmed"
+		title = "Session confirmed"
+		body = "Your session on " + when + " is confirmed."
+	case EventTypeSessionDeclined:
+		notificationType = "session_declined"
+		title = "Session declined"
+		if payload.AutoDeclined {
+			body = "Your session request for " + when + " expired before your coach confirmed it."
+		} else {
+			body = "Your coach declined your session request for " + when + "."
+			if payload.Reason != "" {
+				body += " Reason: " + payload.Reason
+			}
+		}
+	default:
+		return Permanent(fmt.Errorf("unexpected event type %s for session decision handler", event.EventType))
+	}
+	data := map[string]any{
+		"type":         notificationType,
+		"session_id":   payload.SessionID,
+		"scheduled_at": payload.ScheduledAt,
+	}
+
+	sessionID := strconv.FormatUint(uint64(payload.SessionID), 10)
+	for _, userID := range payload.ParticipantUserIDs {
+		if h.notificationRepo != nil {
+			notification := &models.Notification{
+				UserID: userID,
+				Type:   notificationType,
+				Title:  title,
+				Body:   &body,
+				Data:   data,
+			}
+			if err := h.notificationRepo.Create(ctx, notification); err != nil {
+				return fmt.Errorf("create %s notification: %w", notificationType, err)
+			}
+		}
+
+		deviceTokens, err := h.userRepo.GetDeviceTokens(ctx, userID)
+		if err != nil {
+			return fmt.Errorf("get device tokens: %w", err)
+		}
+		if len(deviceTokens) == 0 {
+			continue
+		}
+
+		tokens := make([]string, 0, len(deviceTokens))
+		for _, token := range deviceTokens {
+			tokens = append(tokens, token.Token)
+		}
+
+		userKey := strconv.FormatUint(uint64(userID), 10)
+		if err := h.publisher.Publish(
+			ctx,
+			EventTypeNotificationPush,
+			"session",
+			sessionID,
+			BuildIdempotencyKey(EventTypeNotificationPush, notificationType, sessionID, userKey),
+			PushNotificationPayload{Tokens: tokens, Title: title, Body: body, Data: data},
+		); err != nil {
+			return fmt.Errorf("enqueue notification.push: %w", err)
+		}
+	}
+
+	slog.Info("Session decision fanned out", "event_id", event.ID, "event_type", event.EventType, "session_id", payload.SessionID, "participants", len(payload.ParticipantUserIDs))
+	return nil
+}
+
+// SessionUpdatedHandler leaves an in-app notification on each updated session for its booked clients.
+// Pushes are sent only from the first event of an edit, once per client, covering every session the
+// edit changed.
+type SessionUpdatedHandler struct {
+	userRepo         *repositories.UserRepository
+	notificationRepo *repositories.NotificationRepository
+	publisher        *Publisher
+}
+
+func NewSessionUpdatedHandler(
+	userRepo *repositories.UserRepository,
+	notificationRepo *repositories.NotificationRepository,
+	publisher *Publisher,
+) *SessionUpdatedHandler {
+	return &SessionUpdatedHandler{
+		userRepo:         userRepo,
+		notificationRepo: notificationRepo,
+		publisher:        publisher,
+	}
+}
+
+func (h *SessionUpdatedHandler) Handle(ctx context.Context, event models.OutboxEvent) error {
+	var payload SessionUpdatedPayload
+	if err := json.Unmarshal([]byte(event.Payload), &payload); err != nil {
+		return Permanent(fmt.Errorf("decode session.updated payload: %w", err))
+	}
+	if payload.SessionID == 0 || payload.ChangeID == "" {
+		return Permanent(fmt.Errorf("session.updated payload missing session_id or change_id"))
+	}
+
+	fields := strings.Join(payload.ChangedFields, " and ")
+	when := payload.ScheduledAt.UTC().Format("Jan 2 at 15:04 UTC")
+	title := "Session updated"
+	body := "Your coach updated the " + fields + " of your session on " + when + "."
+	if payload.Location != nil && *payload.Location != "" {
+		body += " Location: " + *payload.Location
+	}
+	data := map[string]any{
+		"type":         "session_updated",
+		"session_id":   payload.SessionID,
+		"scheduled_at": payload.ScheduledAt,
+		"change_id":    payload.ChangeID,
+	}
+
+	if h.notificationRepo != nil {
+		for _, userID := range payload.ParticipantUserIDs {
+			notification := &models.Notification{
+				UserID: userID,
+				Type:   "session_updated",
+				Title:  title,
+				Body:   &body,
+				Data:   data,
+			}
+			// Redelivery may duplicate the inbox entry; that beats dropping it.
+			if err := h.notificationRepo.Create(ctx, notification); err != nil {
+				return fmt.Errorf("create session updated notification: %w", err)
+			}
+		}
+	}
+
+	pushBody := body
+	if payload.ChangeCount > 1 {
+		pushBody = fmt.Sprintf("Your coach updated the %s of %d upcoming sessions, starting %s.", fields, payload.ChangeCount, when)
+		if payload.Location != nil && *payload.Location != "" {
+			pushBody += " Location: " + *payload.Location
+		}
+	}
+	pushData := map[string]any{
+		"type":         "session_updated",
+		"session_id":   payload.SessionID,
+		"scheduled_at": payload.ScheduledAt,
+		"change_id":    payload.ChangeID,
+		"change_count": payload.ChangeCount,
+	}
+	sessionID := strconv.FormatUint(uint64(payload.SessionID), 10)
+	for _, userID := range payload.PushUserIDs {
+		deviceTokens, err := h.userRepo.GetDeviceTokens(ctx, userID)
+		if err != nil {
+			return fmt.Errorf("get device tokens: %w", err)
+		}
+		if len(deviceTokens) == 0 {
+			continue
+		}
+
+		tokens := make([]string, 0, len(deviceTokens))
+		for _, token := range deviceTokens {
+			tokens = append(tokens, token.Token)
+		}
+
+		userKey := strconv.FormatUint(uint64(userID), 10)
+		if err := h.publisher.Publish(
+			ctx,
+			EventTypeNotificationPush,
+			"session",
+			sessionID,
+			BuildIdempotencyKey(EventTypeNotificationPush, "session_updated", payload.ChangeID, userKey),
+			PushNotificationPayload{Tokens: tokens, Title: title, Body: pushBody, Data: pushData},
+		); err != nil {
+			return fmt.Errorf("enqueue notification.push: %w", err)
+		}
+	}
+
+	slog.Info("Session update fanned out", "event_id", event.ID, "session_id", payload.SessionID, "change_id", payload.ChangeID,
+		"participants", len(payload.ParticipantUserIDs), "pushed", len(payload.PushUserIDs))
+	return nil
+}
+
+// SessionBookedHandler tells the other side about a new booking: the coach when a client booked
+// (or asked to book) and the client when the coach booked for them. The in-app notification
+// carries an ICS snippet of the session so the app can offer "add to calendar".
+type SessionBookedHandler struct {
+	sessionRepo      *repositories.SessionRepository
+	userRepo         *repositories.UserRepository
+	notificationRepo *repositories.NotificationRepository
+	publisher        *Publisher
+}
+
+func NewSessionBookedHandler(
+	sessionRepo *repositories.SessionRepository,
+	userRepo *repositories.UserRepository,
+	notificationRepo *repositories.NotificationRepository,
+	publisher *Publisher,
+) *SessionBookedHandler {
+	return &SessionBookedHandler{
+		sessionRepo:      sessionRepo,
+		userRepo:         userRepo,
+		notificationRepo: notificationRepo,
+		publisher:        publisher,
+	}
+}
+
+func (h *SessionBookedHandler) Handle(ctx context.Context, event models.OutboxEvent) error {
+	var payload SessionBookedPayload
+	if err := json.Unmarshal([]byte(event.Payload), &payload); err != nil {
+		return Permanent(fmt.Errorf("decode session.booked payload: %w", err))
+	}
+	if payload.SessionID == 0 {
+		return Permanent(fmt.Errorf("session.booked payload missing session_id"))
+	}
+
+	session, err := h.sessionRepo.GetSession(ctx, payload.SessionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			slog.Info("Booked session no longer exists", "event_id", event.ID, "session_id", payload.SessionID)
+			return nil
+		}
+		return fmt.Errorf("get session: %w", err)
+	}
+	if session.Status == "cancelled" {
+		slog.Info("Booked session already cancelled", "event_id", event.ID, "session_id", payload.SessionID)
+		return nil
+	}
+
+	typeName := session.SessionType.Name
+	if typeName == "" {
+		typeName = "a session"
+	}
+	when := payload.ScheduledAt.UTC().Format("Jan 2 at 15:04 UTC")
+
+	var recipientID uint
+	var title, body string
+	if payload.BookedBy == "coach" {
+		client := bookedClient(session, payload.ClientID)
+		if client == nil || client.UserID == 0 {
+			slog.Info("Booked client not found on session", "event_id", event.ID, "session_id", payload.SessionID, "client_id", payload.ClientID)
+			return nil
+		}
+		recipientID = client.UserID
+		title = "Session booked"
+		body = coachName(&session.Coach) + " booked you for " + typeName + " on " + when + "."
+	} else {
+		recipientID = session.Coach.UserID
+		name := "A client"
+		if client := bookedClient(session, payload.ClientID); client != nil && client.User.Profile != nil {
+			if full := strings.TrimSpace(client.User.Profile.FirstName + " " + client.User.Profile.LastName); full != "" {
+				name = full
+			}
+		}
+		switch {
+		case payload.Status == models.SessionStatusPendingConfirmation:
+			title = "New booking request"
+			body = name + " asked to book " + typeName + " on " + when + ". Confirm or decline it."
+		case payload.Joined:
+			title = "Client joined session"
+			body = name + " joined " + typeName + " on " + when + "."
+		default:
+			title = "New session booked"
+			body = name + " booked " + typeName + " on " + when + "."
+		}
+	}
+	if recipientID == 0 {
+		return Permanent(fmt.Errorf("session.booked recipient missing for session %d", payload.SessionID))
+	}
+
+	// The push stays small; the ICS only goes on the in-app notification and from GET /sessions/:id/ics.
+	pushData := map[string]any{
+		"type":         "session_booked",
+		"session_id":   payload.SessionID,
+		"scheduled_at": payload.ScheduledAt,
+		"status":       session.Status,
+	}
+
+	if h.notificationRepo != nil {
+		data := make(map[string]any, len(pushData)+1)
+		for key, value := range pushData {
+			data[key] = value
+		}
+		data["ics"] = calendar.Encode([]calendar.Event{calendar.SessionEvent(session, recipientID)}, event.CreatedAt)
+
+		notification := &models.Notification{
+			UserID: recipientID,
+			Type:   "session_booked",
+			Title:  title,
+			Body:   &body,
+			Data:   data,
+		}
+		if err := h.notificationRepo.Create(ctx, notification); err != nil {
+			return fmt.Errorf("create session booked notification: %w", err)
+		}
+	}
+
+	deviceTokens, err := h.userRepo.GetDeviceTokens(ctx, recipientID)
+	if err != nil {
+		return fmt.Errorf("get device tokens: %w", err)
+	}
+	if len(deviceTokens) > 0 {
+		tokens := make([]string, 0, len(deviceTokens))
+		for _, token := range deviceTokens {
+			tokens = append(tokens, token.Token)
+		}
+
+		sessionID := strconv.FormatUint(uint64(payload.SessionID), 10)
+		if err := h.publisher.Publish(
+			ctx,
+			EventTypeNotificationPush,
+			"session",
+			sessionID,
+			BuildIdempotencyKey(EventTypeNotificationPush, "session_booked", event.IdempotencyKey),
+			PushNotificationPayload{Tokens: tokens, Title: title, Body: body, Data: pushData},
+		); err != nil {
+			return fmt.Errorf("enqueue notification.push: %w", err)
+		}
+	}
+
+	slog.Info("Session booking alert sent", "event_id", event.ID, "session_id", payload.SessionID, "booked_by", payload.BookedBy)
+	return nil
+}
+
+// bookedClient finds the client profile a booking was made for among the preloaded participants,
+// falling back to the client who created the session.
+func bookedClient(session *models.Session, clientID uint) *models.ClientProfile {
+	for i := range session.Participants {
+		if session.Participants[i].ClientID == clientID {
+			return &session.Participants[i].Client
+		}
+	}
+	if session.ClientID == clientID {
+		return &session.Client
+	}
+	return nil
+}
+
+// coachName is the coach's business name, or their own name when they haven't set one.
+func coachName(coach *models.CoachProfile) string {
+	if coach.BusinessName != nil {
+		if name := strings.TrimSpace(*coach.BusinessName); name != "" {
+			return name
+		}
+	}
+	if coach.User.Profile != nil {
+		if name := strings.TrimSpace(coach.User.Profile.FirstName + " " + coach.User.Profile.LastName); name != "" {
+			return name
+		}
+	}
+	return "Your coach"
+}
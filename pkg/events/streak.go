@@ -0,0 +1,294 @@
+package events
+
+import (
+	"chalk-api/pkg/i18n"
+	"chalk-api/pkg/models"
+	"chalk-api/pkg/repositories"
+	"chalk-api/pkg/utils"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// StreakWeeklyTarget is how many completed workouts a week needs to extend a streak
+const StreakWeeklyTarget = 3
+
+// TotalWorkoutMilestones are the completed-workout counts celebrated with a milestone.reached event
+var TotalWorkoutMilestones = []int{10, 50, 100}
+
+// streaksDeepLink opens the client's streaks and milestones on the home screen
+const streaksDeepLink = "chalk://streaks"
+
+// StreakSummary is a streak rebuilt from weekly completion counts.
+type StreakSummary struct {
+	CurrentWeeks int
+	LongestWeeks int
+	CurrentEnd   string // Monday of the current streak's last week; empty when there is no current streak
+	Total        int
+	LastAt       time.Time
+}
+
+// ComputeStreak rebuilds a streak from weekly counts (any order, weeks starting Monday) as of the week
+// starting thisWeek. A week counts when it has at least target completions. The week in progress
+// only extends the streak once it reaches the target, so a streak ending last week is still current.
+func ComputeStreak(weeks []repositories.WeeklyCompletions, target int, thisWeek string) StreakSummary {
+	var summary StreakSummary
+	qualifying := make(map[string]bool, len(weeks))
+	for _, week := range weeks {
+		summary.Total += week.Completed
+		if week.LastCompletedAt.After(summary.LastAt) {
+			summary.LastAt = week.LastCompletedAt
+		}
+		if week.Completed >= target {
+			qualifying[week.WeekStart] = true
+		}
+	}
+
+	runs := make(map[string]int, len(qualifying)) // week -> length of the run ending at it
+	var ordered []time.Time
+	for week := range qualifying {
+		if start, err := time.Parse("2006-01-02", week); err == nil {
+			ordered = append(ordered, start)
+		}
+	}
+	sort.Slice(ordered, func(i, j int) bool { return ordered[i].Before(ordered[j]) })
+	for i, start := range ordered {
+		run := 1
+		if i > 0 && start.Sub(ordered[i-1]) == 7*24*time.Hour {
+			run = runs[ordered[i-1].Format("2006-01-02")] + 1
+		}
+		runs[start.Format("2006-01-02")] = run
+		if run > summary.LongestWeeks {
+			summary.LongestWeeks = run
+		}
+	}
+
+	current, err := time.Parse("2006-01-02", thisWeek)
+	if err != nil {
+		return summary
+	}
+	for _, end := range []time.Time{current, current.AddDate(0, 0, -7)} {
+		if run, ok := runs[end.Format("2006-01-02")]; ok {
+			summary.CurrentWeeks = run
+			summary.CurrentEnd = end.Format("2006-01-02")
+			break
+		}
+	}
+	return summary
+}
+
+// WeekStart returns the Monday, as YYYY-MM-DD, of the week containing t in loc.
+func WeekStart(t time.Time, loc *time.Location) string {
+	local := t.In(loc)
+	offset := (int(local.Weekday()) + 6) % 7
+	return time.Date(local.Year(), local.Month(), local.Day()-offset, 0, 0, 0, 0, time.UTC).Format("2006-01-02")
+}
+
+// streakTracker rebuilds a user's streak from their whole completion history, so it converges on
+// the same answer whatever order workout.completed events arrive in, and publishes milestone.reached
+// for any milestone crossed. Milestones are published before they are recorded; the idempotency key
+// keeps a retry from publishing twice.
+type streakTracker struct {
+	userRepo     *repositories.UserRepository
+	workoutRepo  *repositories.WorkoutRepository
+	progressRepo *repositories.ProgressRepository
+	publisher    *Publisher
+}
+
+func (t *streakTracker) refresh(ctx context.Context, userID uint, completed WorkoutCompletedPayload) error {
+	tz, err := t.userRepo.GetTimezone(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("get timezone: %w", err)
+	}
+	loc := utils.ResolveLocation(tz)
+	tz = loc.String()
+
+	weeks, err := t.workoutRepo.ListWeeklyCompletions(ctx, userID, tz)
+	if err != nil {
+		return fmt.Errorf("list weekly completions: %w", err)
+	}
+	now := time.Now().UTC()
+	summary := ComputeStreak(weeks, StreakWeeklyTarget, WeekStart(now, loc))
+
+	streak := &models.ClientStreak{
+		UserID:         userID,
+		WeeklyTarget:   StreakWeeklyTarget,
+		CurrentWeeks:   summary.CurrentWeeks,
+		LongestWeeks:   summary.LongestWeeks,
+		TotalCompleted: summary.Total,
+		Timezone:       tz,
+	}
+	if summary.CurrentEnd != "" {
+		streak.CurrentStreakEndWeek = &summary.CurrentEnd
+	}
+	if !summary.LastAt.IsZero() {
+		streak.LastCompletedAt = &summary.LastAt
+	}
+	if err := t.progressRepo.UpsertStreak(ctx, streak); err != nil {
+		return fmt.Errorf("save streak: %w", err)
+	}
+
+	reached, err := t.progressRepo.ListMilestones(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("list milestones: %w", err)
+	}
+	have := make(map[int]bool, len(reached))
+	for _, milestone := range reached {
+		if milestone.Kind == models.MilestoneKindTotalWorkouts {
+			have[milestone.Value] = true
+		}
+	}
+
+	userIDStr := strconv.FormatUint(uint64(userID), 10)
+	for _, value := range TotalWorkoutMilestones {
+		if value > summary.Total || have[value] {
+			continue
+		}
+		if err := t.publisher.Publish(
+			ctx,
+			EventTypeMilestoneReached,
+			"user",
+			userIDStr,
+			BuildIdempotencyKey(EventTypeMilestoneReached, userIDStr, models.MilestoneKindTotalWorkouts, strconv.Itoa(value)),
+			MilestoneReachedPayload{
+				UserID:      userID,
+				Kind:        models.MilestoneKindTotalWorkouts,
+				Value:       value,
+				ClientID:    completed.ClientID,
+				CoachUserID: completed.CoachUserID,
+				ClientName:  completed.ClientName,
+				ReachedAt:   now,
+			},
+		); err != nil {
+			return fmt.Errorf("enqueue milestone.reached: %w", err)
+		}
+		milestone := &models.ClientMilestone{
+			UserID:    userID,
+			Kind:      models.MilestoneKindTotalWorkouts,
+			Value:     value,
+			ReachedAt: now,
+		}
+		if _, err := t.progressRepo.CreateMilestone(ctx, milestone); err != nil {
+			return fmt.Errorf("record milestone: %w", err)
+		}
+	}
+	return nil
+}
+
+// MilestoneReachedHandler celebrates a client's milestone with an in-app notification and push, and
+// leaves their coach an in-app note without a push.
+type MilestoneReachedHandler struct {
+	userRepo         *repositories.UserRepository
+	notificationRepo *repositories.NotificationRepository
+	publisher        *Publisher
+}
+
+func NewMilestoneReachedHandler(
+	userRepo *repositories.UserRepository,
+	notificationRepo *repositories.NotificationRepository,
+	publisher *Publisher,
+) *MilestoneReachedHandler {
+	return &MilestoneReachedHandler{
+		userRepo:         userRepo,
+		notificationRepo: notificationRepo,
+		publisher:        publisher,
+	}
+}
+
+func (h *MilestoneReachedHandler) Handle(ctx context.Context, event models.OutboxEvent) error {
+	var payload MilestoneReachedPayload
+	if err := json.Unmarshal([]byte(event.Payload), &payload); err != nil {
+		return Permanent(fmt.Errorf("decode milestone.reached payload: %w", err))
+	}
+	if payload.UserID == 0 || payload.Value <= 0 {
+		return Permanent(fmt.Errorf("milestone.reached payload missing user_id or value"))
+	}
+	if payload.Kind != models.MilestoneKindTotalWorkouts {
+		slog.Info("Milestone reached", "event_id", event.ID, "user_id", payload.UserID, "kind", payload.Kind, "value", payload.Value)
+		return nil
+	}
+
+	locale, err := h.userRepo.GetLocale(ctx, payload.UserID)
+	if err != nil {
+		return fmt.Errorf("get locale: %w", err)
+	}
+
+	title := i18n.T(locale, "push.milestone_reached.title")
+	body := i18n.T(locale, "push.milestone_reached.body", payload.Value)
+	data := map[string]any{
+		"type":      "milestone_reached",
+		"kind":      payload.Kind,
+		"value":     payload.Value,
+		"deep_link": streaksDeepLink,
+	}
+
+	if h.notificationRepo != nil {
+		notification := &models.Notification{
+			UserID: payload.UserID,
+			Type:   "milestone_reached",
+			Title:  title,
+			Body:   &body,
+			Data:   data,
+		}
+		if err := h.notificationRepo.Create(ctx, notification); err != nil {
+			return fmt.Errorf("create milestone notification: %w", err)
+		}
+
+		if payload.CoachUserID != 0 {
+			coachLocale, err := h.userRepo.GetLocale(ctx, payload.CoachUserID)
+			if err != nil {
+				return fmt.Errorf("get coach locale: %w", err)
+			}
+			name := strings.TrimSpace(payload.ClientName)
+			if name == "" {
+				name = i18n.T(coachLocale, "push.milestone_reached.unnamed_client")
+			}
+			coachBody := i18n.T(coachLocale, "push.milestone_reached.coach_body", name, payload.Value)
+			coachNotification := &models.Notification{
+				UserID: payload.CoachUserID,
+				Type:   "client_milestone_reached",
+				Title:  i18n.T(coachLocale, "push.milestone_reached.coach_title"),
+				Body:   &coachBody,
+				Data: map[string]any{
+					"type":      "client_milestone_reached",
+					"client_id": payload.ClientID,
+					"kind":      payload.Kind,
+					"value":     payload.Value,
+				},
+			}
+			if err := h.notificationRepo.Create(ctx, coachNotification); err != nil {
+				return fmt.Errorf("create coach milestone notification: %w", err)
+			}
+		}
+	}
+
+	deviceTokens, err := h.userRepo.GetDeviceTokens(ctx, payload.UserID)
+	if err != nil {
+		return fmt.Errorf("get device tokens: %w", err)
+	}
+	if len(deviceTokens) > 0 {
+		tokens := make([]string, 0, len(deviceTokens))
+		for _, token := range deviceTokens {
+			tokens = append(tokens, token.Token)
+		}
+
+		if err := h.publisher.Publish(
+			ctx,
+			EventTypeNotificationPush,
+			"user",
+			strconv.FormatUint(uint64(payload.UserID), 10),
+			BuildIdempotencyKey(EventTypeNotificationPush, "milestone_reached", event.IdempotencyKey),
+			PushNotificationPayload{Tokens: tokens, Title: title, Body: body, Data: data},
+		); err != nil {
+			return fmt.Errorf("enqueue notification.push: %w", err)
+		}
+	}
+
+	slog.Info("Milestone celebrated", "event_id", event.ID, "user_id", payload.UserID, "kind", payload.Kind, "value", payload.Value)
+	return nil
+}
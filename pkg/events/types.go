@@ -10,12 +10,36 @@ type EventType string
 
 const (
 	EventTypeMessageSent         EventType = "message.sent"
+	EventTypeMessageRead         EventType = "message.read"
 	EventTypeWorkoutAssigned     EventType = "workout.assigned"
 	EventTypeWorkoutCompleted    EventType = "workout.completed"
 	EventTypeSessionBooked       EventType = "session.booked"
+	EventTypeSessionCancelled    EventType = "session.cancelled"
 	EventTypeInviteAccepted      EventType = "invite.accepted"
+	EventTypeConnectionApproved  EventType = "connection.approved"
 	EventTypeSubscriptionChanged EventType = "subscription.changed"
 	EventTypeNotificationPush    EventType = "notification.push"
+	EventTypeStorageObjectDelete EventType = "storage.object_delete"
+	EventTypeDataExportRequested EventType = "user.data_export_requested"
+	EventTypeClientAtRisk        EventType = "client.at_risk"
+	EventTypeFormCheckSubmitted  EventType = "formcheck.submitted"
+	EventTypeClientTransferred   EventType = "client.transferred"
+	EventTypeSessionFeedback     EventType = "session.feedback_submitted"
+	EventTypeGoalAchieved        EventType = "goal.achieved"
+	EventTypeSessionConfirmed    EventType = "session.confirmed"
+	EventTypeSessionDeclined     EventType = "session.declined"
+	EventTypeSessionUpdated      EventType = "session.updated"
+	EventTypeClientStatusChanged EventType = "client.status_changed"
+	EventTypeSubscriptionWebhook EventType = "subscription.webhook_received"
+	EventTypeClientReportRequest EventType = "client.report_requested"
+	EventTypeAuthNewDevice       EventType = "auth.new_device"
+	EventTypeConversationExport  EventType = "conversation.exported"
+	EventTypeCoachTeamInvited    EventType = "coach_team.invited"
+	EventTypeReferralCompleted   EventType = "referral.completed"
+	EventTypeMilestoneReached    EventType = "milestone.reached"
+	EventTypeClientInvited       EventType = "client.invited"
+	EventTypeMessageRetention    EventType = "message.retention_applied"
+	EventTypeExerciseBulkUpdate  EventType = "exercise.bulk_updated"
 )
 
 type MessageSentPayload struct {
@@ -26,6 +50,17 @@ type MessageSentPayload struct {
 	ContentPreview *string `json:"content_preview,omitempty"`
 }
 
+// MessageReadPayload is used by message.read events when a participant marks messages read.
+// SenderID is the other participant, who receives the read receipt.
+type MessageReadPayload struct {
+	ConversationID uint      `json:"conversation_id"`
+	ReaderID       uint      `json:"reader_id"`
+	SenderID       uint      `json:"sender_id"`
+	UpToMessageID  uint      `json:"up_to_message_id"`
+	MarkedCount    int64     `json:"marked_count"`
+	ReadAt         time.Time `json:"read_at"`
+}
+
 type WorkoutAssignedPayload struct {
 	WorkoutID      uint   `json:"workout_id"`
 	CoachID        uint   `json:"coach_id"`
@@ -35,11 +70,18 @@ type WorkoutAssignedPayload struct {
 	AssignedByUser uint   `json:"assigned_by_user"`
 }
 
+// WorkoutCompletedPayload carries the client's completion note so the coach notification can preview it.
 type WorkoutCompletedPayload struct {
-	WorkoutID   uint      `json:"workout_id"`
-	CoachID     uint      `json:"coach_id"`
-	ClientID    uint      `json:"client_id"`
-	CompletedAt time.Time `json:"completed_at"`
+	WorkoutID      uint      `json:"workout_id"`
+	CoachID        uint      `json:"coach_id"`
+	ClientID       uint      `json:"client_id"`
+	CompletedAt    time.Time `json:"completed_at"`
+	CoachUserID    uint      `json:"coach_user_id"`
+	ClientName     string    `json:"client_name"`
+	WorkoutName    string    `json:"workout_name"`
+	CompletionNote *string   `json:"completion_note,omitempty"`
+	HasPhoto       bool      `json:"has_photo"`
+	ClientUserID   uint      `json:"client_user_id,omitempty"`
 }
 
 type SessionBookedPayload struct {
@@ -48,6 +90,57 @@ type SessionBookedPayload struct {
 	ClientID    uint      `json:"client_id"`
 	ScheduledAt time.Time `json:"scheduled_at"`
 	BookedBy    string    `json:"booked_by"` // "coach" or "client"
+	// Joined is true when the client was added to an existing group session
+	Joined bool `json:"joined,omitempty"`
+	// Status is pending_confirmation when the session type needs the coach to confirm
+	Status string `json:"status,omitempty"`
+}
+
+// SessionDecisionPayload is used by session.confirmed and session.declined events when a pending
+// booking is resolved. AutoDeclined marks declines by the expiry worker rather than the coach.
+type SessionDecisionPayload struct {
+	SessionID          uint      `json:"session_id"`
+	CoachID            uint      `json:"coach_id"`
+	ScheduledAt        time.Time `json:"scheduled_at"`
+	Reason             string    `json:"reason,omitempty"`
+	AutoDeclined       bool      `json:"auto_declined,omitempty"`
+	ParticipantUserIDs []uint    `json:"participant_user_ids"`
+}
+
+// SessionCancelledPayload is used by session.cancelled events when the coach cancels a session.
+// ParticipantUserIDs lists every client who was still booked so each one is notified.
+type SessionCancelledPayload struct {
+	SessionID          uint      `json:"session_id"`
+	CoachID            uint      `json:"coach_id"`
+	ScheduledAt        time.Time `json:"scheduled_at"`
+	CancelledBy        string    `json:"cancelled_by"`
+	Reason             string    `json:"reason"`
+	ParticipantUserIDs []uint    `json:"participant_user_ids"`
+}
+
+// SessionUpdatedPayload is used by session.updated events when the coach changes a session's
+// location or notes. An edit applied to a recurring series publishes one event per session, all
+// sharing ChangeID; only the first carries PushUserIDs, so each client gets a single push
+// summarizing the ChangeCount sessions instead of one per session.
+type SessionUpdatedPayload struct {
+	SessionID          uint      `json:"session_id"`
+	CoachID            uint      `json:"coach_id"`
+	ScheduledAt        time.Time `json:"scheduled_at"`
+	ChangedFields      []string  `json:"changed_fields"` // "location", "notes"
+	Location           *string   `json:"location,omitempty"`
+	ChangeID           string    `json:"change_id"`
+	ChangeCount        int       `json:"change_count"`
+	ParticipantUserIDs []uint    `json:"participant_user_ids"`
+	PushUserIDs        []uint    `json:"push_user_ids,omitempty"`
+}
+
+// SessionFeedbackPayload is used by session.feedback_submitted events; the handler refreshes the
+// coach's rating stats.
+type SessionFeedbackPayload struct {
+	FeedbackID uint `json:"feedback_id"`
+	SessionID  uint `json:"session_id"`
+	CoachID    uint `json:"coach_id"`
+	Rating     int  `json:"rating"`
 }
 
 type InviteAcceptedPayload struct {
@@ -58,6 +151,14 @@ type InviteAcceptedPayload struct {
 	Code            string `json:"code"`
 }
 
+// ConnectionApprovedPayload is the invite.accepted equivalent for relationships started by a connection request.
+type ConnectionApprovedPayload struct {
+	ConnectionRequestID uint `json:"connection_request_id"`
+	CoachID             uint `json:"coach_id"`
+	ClientUserID        uint `json:"client_user_id"`
+	ClientProfileID     uint `json:"client_profile_id"`
+}
+
 type SubscriptionChangedPayload struct {
 	SubscriptionID    uint    `json:"subscription_id"`
 	UserID            uint    `json:"user_id"`
@@ -74,6 +175,199 @@ type PushNotificationPayload struct {
 	Title  string         `json:"title"`
 	Body   string         `json:"body"`
 	Data   map[string]any `json:"data,omitempty"`
+	// Silent pushes carry only Data for the app to act on in the background; Title and Body are ignored.
+	Silent bool `json:"silent,omitempty"`
+}
+
+// StorageObjectDeletePayload is used by storage.object_delete events.
+// Replaced uploads are removed asynchronously so the request that replaced them stays fast.
+type StorageObjectDeletePayload struct {
+	Key    string `json:"key"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// ClientAtRiskPayload is used by client.at_risk events, published once each time a client is flagged.
+type ClientAtRiskPayload struct {
+	ClientID       uint       `json:"client_id"`
+	CoachID        uint       `json:"coach_id"`
+	CoachUserID    uint       `json:"coach_user_id"`
+	ClientName     string     `json:"client_name"`
+	LastActivityAt *time.Time `json:"last_activity_at,omitempty"`
+	InactiveDays   int        `json:"inactive_days"`
+}
+
+// FormCheckSubmittedPayload is used by formcheck.submitted events when a client attaches a form video to a set.
+type FormCheckSubmittedPayload struct {
+	WorkoutLogID uint   `json:"workout_log_id"`
+	WorkoutID    uint   `json:"workout_id"`
+	ClientID     uint   `json:"client_id"`
+	CoachID      uint   `json:"coach_id"`
+	CoachUserID  uint   `json:"coach_user_id"`
+	ClientName   string `json:"client_name"`
+	ExerciseName string `json:"exercise_name"`
+}
+
+// ClientTransferredPayload is used by client.transferred events when a client moves to another coach.
+// The client and both coaches are notified.
+type ClientTransferredPayload struct {
+	ClientUserID       uint   `json:"client_user_id"`
+	OldClientProfileID uint   `json:"old_client_profile_id"`
+	NewClientProfileID uint   `json:"new_client_profile_id"`
+	FromCoachID        uint   `json:"from_coach_id"`
+	FromCoachUserID    uint   `json:"from_coach_user_id"`
+	ToCoachID          uint   `json:"to_coach_id"`
+	ToCoachUserID      uint   `json:"to_coach_user_id"`
+	ClientName         string `json:"client_name"`
+	FromCoachName      string `json:"from_coach_name"`
+	ToCoachName        string `json:"to_coach_name"`
+	ConversationMoved  bool   `json:"conversation_moved"`
+	CancelledSessions  int64  `json:"cancelled_sessions"`
+	SkippedWorkouts    int64  `json:"skipped_workouts"`
+}
+
+// GoalAchievedPayload is used by goal.achieved events. The handler refreshes the coach's goal stats
+// and congratulates the other side of the relationship: the coach when the client marked the goal
+// achieved, the client when the coach did.
+type GoalAchievedPayload struct {
+	GoalID           uint      `json:"goal_id"`
+	ClientProfileID  uint      `json:"client_profile_id"`
+	CoachID          uint      `json:"coach_id"`
+	CoachUserID      uint      `json:"coach_user_id"`
+	ClientUserID     uint      `json:"client_user_id"`
+	AchievedByUserID uint      `json:"achieved_by_user_id"`
+	ClientName       string    `json:"client_name"`
+	Title            string    `json:"title"`
+	AchievedAt       time.Time `json:"achieved_at"`
+}
+
+// ClientStatusChangedPayload is used by client.status_changed events when a coach-client relationship
+// is paused, archived or reactivated. The handler hides or restores the pair's conversation.
+type ClientStatusChangedPayload struct {
+	ClientID       uint   `json:"client_id"`
+	CoachID        uint   `json:"coach_id"`
+	Status         string `json:"status"`
+	PreviousStatus string `json:"previous_status"`
+}
+
+// DataExportRequestedPayload is used by user.data_export_requested events.
+// The handler builds the archive; the data_exports row tracks progress for the status endpoint.
+type DataExportRequestedPayload struct {
+	ExportID uint `json:"export_id"`
+	UserID   uint `json:"user_id"`
+}
+
+// ClientReportRequestedPayload is used by client.report_requested events. The handler renders the
+// PDF; the client_reports row carries the period and tracks progress for the list endpoint.
+type ClientReportRequestedPayload struct {
+	ReportID    uint `json:"report_id"`
+	CoachUserID uint `json:"coach_user_id"`
+}
+
+// AuthNewDevicePayload is used by auth.new_device events when a login comes from a device or country
+// the user hasn't logged in from recently. Reason is "new_device" or "new_country".
+type AuthNewDevicePayload struct {
+	UserID     uint      `json:"user_id"`
+	Reason     string    `json:"reason"`
+	DeviceID   string    `json:"device_id,omitempty"`
+	DeviceInfo string    `json:"device_info,omitempty"` // user agent
+	IPAddress  string    `json:"ip_address,omitempty"`
+	City       string    `json:"city,omitempty"`
+	Country    string    `json:"country,omitempty"`
+	LoggedInAt time.Time `json:"logged_in_at"`
+}
+
+// ConversationExportedPayload is used by conversation.exported events; it is the audit trail for
+// message exports requested during disputes.
+type ConversationExportedPayload struct {
+	ConversationID uint      `json:"conversation_id"`
+	UserID         uint      `json:"user_id"`
+	Format         string    `json:"format"`
+	ExportedAt     time.Time `json:"exported_at"`
+}
+
+// MessageRetentionAppliedPayload is used by message.retention_applied events, the audit record of a
+// retention run that deleted messages from a coach's conversations.
+type MessageRetentionAppliedPayload struct {
+	CoachID                  uint      `json:"coach_id"`
+	RetentionDays            int       `json:"retention_days"`
+	Cutoff                   time.Time `json:"cutoff"`
+	MessagesDeleted          int64     `json:"messages_deleted"`
+	ScheduledMessagesDeleted int64     `json:"scheduled_messages_deleted"`
+	Complete                 bool      `json:"complete"` // false when the run stopped with messages left
+	RanAt                    time.Time `json:"ran_at"`
+}
+
+// ExerciseBulkUpdatedPayload is used by exercise.bulk_updated events, the audit record of an admin's
+// bulk edit of system exercises with the filter and mutation exactly as they were applied.
+type ExerciseBulkUpdatedPayload struct {
+	AdminUserID  uint                 `json:"admin_user_id"`
+	Filter       ExerciseBulkFilter   `json:"filter"`
+	Mutation     ExerciseBulkMutation `json:"mutation"`
+	ExerciseIDs  []uint               `json:"exercise_ids"`
+	UpdatedCount int64                `json:"updated_count"`
+	UpdatedAt    time.Time            `json:"updated_at"`
+}
+
+type ExerciseBulkFilter struct {
+	IDs         []uint `json:"ids,omitempty"`
+	NamePattern string `json:"name_pattern,omitempty"`
+	Tag         string `json:"tag,omitempty"`
+}
+
+type ExerciseBulkMutation struct {
+	AddTags               []string  `json:"add_tags,omitempty"`
+	RemoveTags            []string  `json:"remove_tags,omitempty"`
+	Difficulty            *string   `json:"difficulty,omitempty"`
+	PrimaryMuscleGroups   *[]string `json:"primary_muscle_groups,omitempty"`
+	SecondaryMuscleGroups *[]string `json:"secondary_muscle_groups,omitempty"`
+}
+
+// CoachTeamInvitedPayload is used by coach_team.invited events when a coach invites an assistant by
+// email; delivering the invitation email hangs off this event.
+type CoachTeamInvitedPayload struct {
+	TeamMemberID    uint   `json:"team_member_id"`
+	CoachID         uint   `json:"coach_id"`
+	InvitedByUserID uint   `json:"invited_by_user_id"`
+	Email           string `json:"email"`
+	Role            string `json:"role"`
+}
+
+// ReferralCompletedPayload is used by referral.completed events when a referred coach finishes
+// onboarding. CreditID is 0 when no credit was granted; billing consumes credits from the ledger.
+type ReferralCompletedPayload struct {
+	ReferralID        uint   `json:"referral_id"`
+	ReferrerCoachID   uint   `json:"referrer_coach_id"`
+	ReferrerUserID    uint   `json:"referrer_user_id"`
+	ReferredCoachID   uint   `json:"referred_coach_id"`
+	ReferredCoachName string `json:"referred_coach_name,omitempty"`
+	CreditID          uint   `json:"credit_id,omitempty"`
+	AmountCents       int    `json:"amount_cents"`
+}
+
+// MilestoneReachedPayload is used by milestone.reached events, published once per user and milestone
+// by the workout.completed handler. ClientID and CoachUserID identify the coaching relationship whose
+// workout crossed the threshold; that coach gets a lighter in-app note.
+type MilestoneReachedPayload struct {
+	UserID      uint      `json:"user_id"`
+	Kind        string    `json:"kind"`
+	Value       int       `json:"value"`
+	ClientID    uint      `json:"client_id,omitempty"`
+	CoachUserID uint      `json:"coach_user_id,omitempty"`
+	ClientName  string    `json:"client_name,omitempty"`
+	ReachedAt   time.Time `json:"reached_at"`
+}
+
+// ClientInvitedPayload is used by client.invited events when a coach's client import invites
+// someone. UserID is 0 when the email has no account yet; those invitations go out by email, which
+// hangs off this event.
+type ClientInvitedPayload struct {
+	InvitationID uint      `json:"invitation_id"`
+	CoachID      uint      `json:"coach_id"`
+	UserID       uint      `json:"user_id,omitempty"`
+	Email        string    `json:"email"`
+	FirstName    string    `json:"first_name"`
+	Code         string    `json:"code"`
+	ExpiresAt    time.Time `json:"expires_at"`
 }
 
 func BuildIdempotencyKey(eventType EventType, parts ...string) string {
@@ -0,0 +1,264 @@
+package events
+
+import (
+	"chalk-api/pkg/i18n"
+	"chalk-api/pkg/models"
+	"chalk-api/pkg/repositories"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// completionNotePreviewRunes caps how much of the client's note is shown in the notification
+const completionNotePreviewRunes = 120
+
+// workoutDeepLinkFormat opens the workout in the mobile app
+const workoutDeepLinkFormat = "chalk://workouts/%d"
+
+// WorkoutAssignedHandler tells the client about a newly assigned workout. Notifications carry a
+// deep link to the workout and its scheduled date so the app can open it or add it to a calendar.
+type WorkoutAssignedHandler struct {
+	clientRepo       *repositories.ClientRepository
+	userRepo         *repositories.UserRepository
+	notificationRepo *repositories.NotificationRepository
+	publisher        *Publisher
+}
+
+func NewWorkoutAssignedHandler(
+	clientRepo *repositories.ClientRepository,
+	userRepo *repositories.UserRepository,
+	notificationRepo *repositories.NotificationRepository,
+	publisher *Publisher,
+) *WorkoutAssignedHandler {
+	return &WorkoutAssignedHandler{
+		clientRepo:       clientRepo,
+		userRepo:         userRepo,
+		notificationRepo: notificationRepo,
+		publisher:        publisher,
+	}
+}
+
+func (h *WorkoutAssignedHandler) Handle(ctx context.Context, event models.OutboxEvent) error {
+	var payload WorkoutAssignedPayload
+	if err := json.Unmarshal([]byte(event.Payload), &payload); err != nil {
+		return Permanent(fmt.Errorf("decode workout.assigned payload: %w", err))
+	}
+	if payload.WorkoutID == 0 {
+		return Permanent(fmt.Errorf("workout.assigned payload missing workout_id"))
+	}
+
+	clientProfile, err := h.clientRepo.GetByID(ctx, payload.ClientID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			slog.Info("Assigned workout's client no longer exists", "event_id", event.ID, "workout_id", payload.WorkoutID)
+			return nil
+		}
+		return fmt.Errorf("get client profile: %w", err)
+	}
+
+	locale, err := h.userRepo.GetLocale(ctx, clientProfile.UserID)
+	if err != nil {
+		return fmt.Errorf("get locale: %w", err)
+	}
+
+	title := i18n.T(locale, "push.workout_assigned.title")
+	workoutName := payload.WorkoutName
+	if workoutName == "" {
+		workoutName = i18n.T(locale, "push.workout_assigned.unnamed")
+	}
+	body := i18n.T(locale, "push.workout_assigned.body", workoutName)
+	if date, err := time.Parse("2006-01-02", payload.ScheduledDate); err == nil {
+		body = i18n.T(locale, "push.workout_assigned.body_dated", workoutName, i18n.ShortDate(locale, date))
+	}
+	data := map[string]any{
+		"type":       "workout_assigned",
+		"workout_id": payload.WorkoutID,
+		"deep_link":  fmt.Sprintf(workoutDeepLinkFormat, payload.WorkoutID),
+	}
+	if payload.ScheduledDate != "" {
+		data["scheduled_date"] = payload.ScheduledDate
+	}
+
+	if h.notificationRepo != nil {
+		notification := &models.Notification{
+			UserID: clientProfile.UserID,
+			Type:   "workout_assigned",
+			Title:  title,
+			Body:   &body,
+			Data:   data,
+		}
+		if err := h.notificationRepo.Create(ctx, notification); err != nil {
+			return fmt.Errorf("create workout assigned notification: %w", err)
+		}
+	}
+
+	deviceTokens, err := h.userRepo.GetDeviceTokens(ctx, clientProfile.UserID)
+	if err != nil {
+		return fmt.Errorf("get device tokens: %w", err)
+	}
+	if len(deviceTokens) > 0 {
+		tokens := make([]string, 0, len(deviceTokens))
+		for _, token := range deviceTokens {
+			tokens = append(tokens, token.Token)
+		}
+
+		workoutID := strconv.FormatUint(uint64(payload.WorkoutID), 10)
+		if err := h.publisher.Publish(
+			ctx,
+			EventTypeNotificationPush,
+			"workout",
+			workoutID,
+			BuildIdempotencyKey(EventTypeNotificationPush, "workout_assigned", event.IdempotencyKey),
+			PushNotificationPayload{Tokens: tokens, Title: title, Body: body, Data: data},
+		); err != nil {
+			return fmt.Errorf("enqueue notification.push: %w", err)
+		}
+	}
+
+	slog.Info("Workout assignment alert sent", "event_id", event.ID, "workout_id", payload.WorkoutID, "client_id", payload.ClientID)
+	return nil
+}
+
+// WorkoutCompletedHandler refreshes the client's streak and milestones, then tells the coach a
+// client finished a workout, previewing the completion note when there is one. Events from before
+// coach_user_id was added skip the coach notification.
+type WorkoutCompletedHandler struct {
+	clientRepo       *repositories.ClientRepository
+	userRepo         *repositories.UserRepository
+	notificationRepo *repositories.NotificationRepository
+	publisher        *Publisher
+	streaks          *streakTracker
+}
+
+func NewWorkoutCompletedHandler(
+	clientRepo *repositories.ClientRepository,
+	userRepo *repositories.UserRepository,
+	workoutRepo *repositories.WorkoutRepository,
+	progressRepo *repositories.ProgressRepository,
+	notificationRepo *repositories.NotificationRepository,
+	publisher *Publisher,
+) *WorkoutCompletedHandler {
+	return &WorkoutCompletedHandler{
+		clientRepo:       clientRepo,
+		userRepo:         userRepo,
+		notificationRepo: notificationRepo,
+		publisher:        publisher,
+		streaks: &streakTracker{
+			userRepo:     userRepo,
+			workoutRepo:  workoutRepo,
+			progressRepo: progressRepo,
+			publisher:    publisher,
+		},
+	}
+}
+
+func (h *WorkoutCompletedHandler) Handle(ctx context.Context, event models.OutboxEvent) error {
+	var payload WorkoutCompletedPayload
+	if err := json.Unmarshal([]byte(event.Payload), &payload); err != nil {
+		return Permanent(fmt.Errorf("decode workout.completed payload: %w", err))
+	}
+	if payload.WorkoutID == 0 {
+		return Permanent(fmt.Errorf("workout.completed payload missing workout_id"))
+	}
+
+	clientUserID := payload.ClientUserID
+	if clientUserID == 0 && payload.ClientID != 0 {
+		// Events from before client_user_id was added
+		clientProfile, err := h.clientRepo.GetByID(ctx, payload.ClientID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("get client profile: %w", err)
+		}
+		if clientProfile != nil {
+			clientUserID = clientProfile.UserID
+		}
+	}
+	if clientUserID != 0 {
+		if err := h.streaks.refresh(ctx, clientUserID, payload); err != nil {
+			return fmt.Errorf("refresh streak: %w", err)
+		}
+	}
+
+	if payload.CoachUserID == 0 {
+		slog.Info("Workout completed", "event_id", event.ID, "workout_id", payload.WorkoutID)
+		return nil
+	}
+
+	name := strings.TrimSpace(payload.ClientName)
+	if name == "" {
+		name = "A client"
+	}
+	title := "Workout completed"
+	body := name + " completed"
+	if payload.WorkoutName != "" {
+		body += " " + payload.WorkoutName
+	} else {
+		body += " a workout"
+	}
+	body += "."
+	if payload.CompletionNote != nil {
+		if note := strings.TrimSpace(*payload.CompletionNote); note != "" {
+			body += " \"" + truncateRunes(note, completionNotePreviewRunes) + "\""
+		}
+	}
+	data := map[string]any{
+		"type":       "workout_completed",
+		"workout_id": payload.WorkoutID,
+		"client_id":  payload.ClientID,
+		"has_photo":  payload.HasPhoto,
+	}
+
+	if h.notificationRepo != nil {
+		notification := &models.Notification{
+			UserID: payload.CoachUserID,
+			Type:   "workout_completed",
+			Title:  title,
+			Body:   &body,
+			Data:   data,
+		}
+		if err := h.notificationRepo.Create(ctx, notification); err != nil {
+			return fmt.Errorf("create workout completed notification: %w", err)
+		}
+	}
+
+	deviceTokens, err := h.userRepo.GetDeviceTokens(ctx, payload.CoachUserID)
+	if err != nil {
+		return fmt.Errorf("get device tokens: %w", err)
+	}
+	if len(deviceTokens) > 0 {
+		tokens := make([]string, 0, len(deviceTokens))
+		for _, token := range deviceTokens {
+			tokens = append(tokens, token.Token)
+		}
+
+		workoutID := strconv.FormatUint(uint64(payload.WorkoutID), 10)
+		if err := h.publisher.Publish(
+			ctx,
+			EventTypeNotificationPush,
+			"workout",
+			workoutID,
+			BuildIdempotencyKey(EventTypeNotificationPush, "workout_completed", event.IdempotencyKey),
+			PushNotificationPayload{Tokens: tokens, Title: title, Body: body, Data: data},
+		); err != nil {
+			return fmt.Errorf("enqueue notification.push: %w", err)
+		}
+	}
+
+	slog.Info("Workout completed alert sent", "event_id", event.ID, "workout_id", payload.WorkoutID, "coach_id", payload.CoachID)
+	return nil
+}
+
+// truncateRunes shortens s to at most n runes, marking the cut like message previews do.
+func truncateRunes(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n]) + "..."
+}
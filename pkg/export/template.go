@@ -0,0 +1,482 @@
+// Package export turns workout templates into files a coach can print or open in a spreadsheet.
+// NewTemplateSheet builds a layout model from the template; PDF and WriteCSV render that model, so
+// the grouping rules live in one place.
+package export
+
+import (
+	"chalk-api/pkg/models"
+	"chalk-api/pkg/pdf"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// CSVContentType is the media type for .csv files.
+const CSVContentType = "text/csv; charset=utf-8"
+
+// TemplateSheet is a template laid out for printing: sections in order, each holding blocks of
+// exercises that are performed together.
+type TemplateSheet struct {
+	TemplateID   uint
+	Title        string
+	BusinessName string // empty when the coach has none
+	Description  string
+	Sections     []SheetSection
+}
+
+// SheetSection is a run of consecutive exercises sharing a section label. Label is empty for
+// exercises outside any section.
+type SheetSection struct {
+	Label  string
+	Blocks []SheetBlock
+}
+
+// SheetBlock is a single exercise, or the members of a superset or circuit in order.
+type SheetBlock struct {
+	GroupType string // "superset", "circuit" or "drop_set"; empty for a single exercise
+	Rows      []SheetRow
+}
+
+// Grouped reports whether the block is a superset or circuit rather than a lone exercise.
+func (b SheetBlock) Grouped() bool {
+	return b.GroupType != ""
+}
+
+// SheetRow is one exercise's prescription as printed.
+type SheetRow struct {
+	Label    string // "3", or "3a", "3b" within a group
+	Section  string
+	Exercise string
+	Sets     string
+	Reps     string
+	Load     string
+	Rest     string
+	Tempo    string
+	Notes    string
+}
+
+// SetsReps formats the prescription as "3 x 8-12", falling back to whichever half is known.
+func (r SheetRow) SetsReps() string {
+	switch {
+	case r.Sets != "" && r.Reps != "":
+		return r.Sets + " x " + r.Reps
+	case r.Sets != "":
+		return r.Sets + " sets"
+	default:
+		return r.Reps
+	}
+}
+
+// NewTemplateSheet lays out template's exercises in order_index order. A new section starts
+// whenever the section label changes, and consecutive exercises sharing a superset group form one
+// block; a group split by other exercises is printed as separate blocks, as it would be performed.
+func NewTemplateSheet(template *models.WorkoutTemplate, businessName string) *TemplateSheet {
+	sheet := &TemplateSheet{
+		TemplateID:   template.ID,
+		Title:        template.Name,
+		BusinessName: strings.TrimSpace(businessName),
+		Description:  strings.TrimSpace(derefString(template.Description)),
+	}
+
+	exercises := append([]models.WorkoutTemplateExercise(nil), template.Exercises...)
+	sort.SliceStable(exercises, func(i, j int) bool { return exercises[i].OrderIndex < exercises[j].OrderIndex })
+
+	blockNumber := 0
+	var section *SheetSection
+	var block *SheetBlock
+	var blockGroup *int
+	for i := range exercises {
+		exercise := &exercises[i]
+		label := strings.TrimSpace(derefString(exercise.SectionLabel))
+		if section == nil || section.Label != label {
+			sheet.Sections = append(sheet.Sections, SheetSection{Label: label})
+			section = &sheet.Sections[len(sheet.Sections)-1]
+			block = nil
+		}
+		if block == nil || exercise.SupersetGroup == nil || blockGroup == nil || *exercise.SupersetGroup != *blockGroup {
+			blockNumber++
+			section.Blocks = append(section.Blocks, SheetBlock{})
+			block = &section.Blocks[len(section.Blocks)-1]
+			blockGroup = exercise.SupersetGroup
+			if blockGroup != nil {
+				block.GroupType = "superset"
+				if groupType := derefString(exercise.GroupType); groupType != "" {
+					block.GroupType = groupType
+				}
+			}
+		}
+
+		row := sheetRow(exercise)
+		row.Section = label
+		row.Label = strconv.Itoa(blockNumber)
+		block.Rows = append(block.Rows, row)
+	}
+
+	// Letters only make sense once a group has more than one member.
+	for s := range sheet.Sections {
+		for b := range sheet.Sections[s].Blocks {
+			rows := sheet.Sections[s].Blocks[b].Rows
+			if len(rows) < 2 {
+				continue
+			}
+			for r := range rows {
+				rows[r].Label += string(rune('a' + r%26))
+			}
+		}
+	}
+	return sheet
+}
+
+func sheetRow(exercise *models.WorkoutTemplateExercise) SheetRow {
+	row := SheetRow{
+		Exercise: exercise.Exercise.Name,
+		Tempo:    strings.TrimSpace(derefString(exercise.Tempo)),
+	}
+	if row.Exercise == "" {
+		row.Exercise = fmt.Sprintf("Exercise #%d", exercise.ExerciseID)
+	}
+	if exercise.Sets != nil {
+		row.Sets = strconv.Itoa(*exercise.Sets)
+	}
+	if exercise.RepsMin != nil {
+		row.Reps = strconv.Itoa(*exercise.RepsMin)
+		if exercise.RepsMax != nil && *exercise.RepsMax != *exercise.RepsMin {
+			row.Reps += "-" + strconv.Itoa(*exercise.RepsMax)
+		}
+	}
+	row.Load = formatLoad(exercise.WeightValue, derefString(exercise.WeightUnit))
+	if exercise.RestSeconds != nil && *exercise.RestSeconds > 0 {
+		row.Rest = formatRest(*exercise.RestSeconds)
+	}
+
+	var notes []string
+	if note := strings.TrimSpace(derefString(exercise.PrescriptionNote)); note != "" {
+		// Free-text prescriptions ("AMRAP") stand in for reps when none are structured.
+		if row.Reps == "" {
+			row.Reps = note
+		} else {
+			notes = append(notes, note)
+		}
+	}
+	if note := strings.TrimSpace(derefString(exercise.Notes)); note != "" {
+		notes = append(notes, note)
+	}
+	row.Notes = strings.Join(notes, "; ")
+	return row
+}
+
+func formatLoad(value *float64, unit string) string {
+	switch unit {
+	case "bodyweight":
+		if value == nil || *value == 0 {
+			return "Bodyweight"
+		}
+		if *value > 0 {
+			return "BW + " + formatNumber(*value)
+		}
+		return "BW - " + formatNumber(-*value)
+	case "percent_1rm":
+		if value != nil {
+			return formatNumber(*value) + "% 1RM"
+		}
+	case "rpe":
+		if value != nil {
+			return "RPE " + formatNumber(*value)
+		}
+	default:
+		if value != nil {
+			return strings.TrimSpace(formatNumber(*value) + " " + unit)
+		}
+	}
+	return ""
+}
+
+func formatRest(seconds int) string {
+	if seconds >= 60 && seconds%60 == 0 {
+		return fmt.Sprintf("%d min", seconds/60)
+	}
+	if seconds > 60 {
+		return fmt.Sprintf("%d:%02d", seconds/60, seconds%60)
+	}
+	return fmt.Sprintf("%ds", seconds)
+}
+
+func formatNumber(value float64) string {
+	if value == math.Trunc(value) && math.Abs(value) < 1e9 {
+		return strconv.FormatInt(int64(value), 10)
+	}
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+// Filename is the download name for the sheet with the given extension ("pdf" or "csv").
+func (s *TemplateSheet) Filename(extension string) string {
+	var slug strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(s.Title) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			slug.WriteRune(r)
+			dash = false
+		} else if !dash && slug.Len() > 0 {
+			slug.WriteByte('-')
+			dash = true
+		}
+	}
+	name := strings.TrimSuffix(slug.String(), "-")
+	if len(name) > 60 {
+		name = strings.TrimSuffix(name[:60], "-")
+	}
+	if name == "" {
+		name = fmt.Sprintf("template-%d", s.TemplateID)
+	}
+	return name + "." + extension
+}
+
+// WriteCSV writes one line per exercise, with the section and group repeated on each so the
+// file still reads correctly after sorting or filtering in a spreadsheet.
+func (s *TemplateSheet) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"order", "section", "group_type", "exercise", "sets", "reps", "load", "rest", "tempo", "notes"}); err != nil {
+		return err
+	}
+	for _, section := range s.Sections {
+		for _, block := range section.Blocks {
+			for _, row := range block.Rows {
+				if err := writer.Write([]string{
+					row.Label,
+					csvCell(section.Label),
+					block.GroupType,
+					csvCell(row.Exercise),
+					row.Sets,
+					csvCell(row.Reps),
+					csvCell(row.Load),
+					row.Rest,
+					csvCell(row.Tempo),
+					csvCell(row.Notes),
+				}); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// csvCell keeps spreadsheet apps from evaluating coach-entered text as a formula.
+func csvCell(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// --- PDF layout ---
+
+const (
+	sheetMargin     = 50.0
+	sheetRowHeight  = 18.0
+	sheetNoteHeight = 12.0
+	sheetGroupInset = 10.0 // room for the bar that marks a superset
+)
+
+var (
+	sheetAccent   = pdf.Color{R: 0.16, G: 0.38, B: 0.74}
+	sheetGroupBar = pdf.Color{R: 0.7, G: 0.8, B: 0.94}
+)
+
+// sheetColumns are the left edges of the table columns.
+var sheetColumns = struct {
+	label, exercise, setsReps, load, rest, tempo float64
+}{
+	label:    sheetMargin,
+	exercise: sheetMargin + 24 + sheetGroupInset,
+	setsReps: sheetMargin + 250,
+	load:     sheetMargin + 330,
+	rest:     sheetMargin + 410,
+	tempo:    sheetMargin + 460,
+}
+
+// PDF renders the sheet on as many Letter pages as it needs. Members of a superset or circuit are
+// joined by a tinted bar down their left edge under a small group heading, and each page repeats
+// the column headers.
+func (s *TemplateSheet) PDF() []byte {
+	doc := pdf.New()
+	page := doc.AddPage()
+
+	page.Text(sheetMargin, 70, 20, true, pdf.Black, fitText(s.Title, 20, pdf.PageWidth-2*sheetMargin))
+	y := 88.0
+	if s.BusinessName != "" {
+		page.Text(sheetMargin, y, 11, false, pdf.Gray, s.BusinessName)
+		y += 16
+	}
+	for _, line := range wrapText(s.Description, 10, pdf.PageWidth-2*sheetMargin) {
+		page.Text(sheetMargin, y, 10, false, pdf.Black, line)
+		y += 13
+	}
+	page.Line(sheetMargin, y, pdf.PageWidth-sheetMargin, y, 0.5, pdf.LightGray)
+	y += 24
+
+	if len(s.Sections) == 0 {
+		page.Text(sheetMargin, y, 10, false, pdf.Gray, "This template has no exercises yet.")
+		return doc.Bytes()
+	}
+
+	bottom := pdf.PageHeight - sheetMargin
+	y = drawSheetHeader(page, y)
+	for _, section := range s.Sections {
+		if section.Label != "" {
+			if y+20+sheetRowHeight > bottom {
+				page = doc.AddPage()
+				y = drawSheetHeader(page, sheetMargin)
+			}
+			page.Text(sheetMargin, y+14, 13, true, sheetAccent, section.Label)
+			y += 22
+		}
+		for _, block := range section.Blocks {
+			height := blockHeight(block)
+			// Keep a group on one page when it fits on one at all.
+			if y+height > bottom && height <= bottom-sheetMargin-20 {
+				page = doc.AddPage()
+				y = drawSheetHeader(page, sheetMargin)
+			}
+			y = drawSheetBlock(doc, &page, y, block)
+		}
+	}
+	return doc.Bytes()
+}
+
+func drawSheetHeader(page *pdf.Page, y float64) float64 {
+	columns := sheetColumns
+	headers := []struct {
+		x    float64
+		text string
+	}{
+		{columns.label, "#"},
+		{columns.exercise, "Exercise"},
+		{columns.setsReps, "Sets x Reps"},
+		{columns.load, "Load"},
+		{columns.rest, "Rest"},
+		{columns.tempo, "Tempo"},
+	}
+	for _, header := range headers {
+		page.Text(header.x, y, 9, true, pdf.Gray, header.text)
+	}
+	y += 6
+	page.Line(sheetMargin, y, pdf.PageWidth-sheetMargin, y, 0.5, pdf.LightGray)
+	return y + 4
+}
+
+func blockHeight(block SheetBlock) float64 {
+	height := 0.0
+	if block.Grouped() {
+		height += 14
+	}
+	for _, row := range block.Rows {
+		height += sheetRowHeight
+		if row.Notes != "" {
+			height += sheetNoteHeight
+		}
+	}
+	return height
+}
+
+// drawSheetBlock draws one block starting at y and returns the y below it. A block taller than a
+// page continues on a new one, moving page along with it.
+func drawSheetBlock(doc *pdf.Document, page **pdf.Page, y float64, block SheetBlock) float64 {
+	bottom := pdf.PageHeight - sheetMargin
+	columns := sheetColumns
+
+	if block.Grouped() {
+		heading := strings.ToUpper(strings.ReplaceAll(block.GroupType, "_", " "))
+		(*page).Text(columns.exercise, y+10, 8, true, sheetAccent, heading)
+		y += 14
+	}
+	barTop := y
+
+	for _, row := range block.Rows {
+		rowHeight := sheetRowHeight
+		if row.Notes != "" {
+			rowHeight += sheetNoteHeight
+		}
+		if y+rowHeight > bottom {
+			if block.Grouped() {
+				(*page).Rect(columns.exercise-sheetGroupInset, barTop+3, 3, y-barTop-3, sheetGroupBar)
+			}
+			*page = doc.AddPage()
+			y = drawSheetHeader(*page, sheetMargin)
+			barTop = y
+		}
+
+		baseline := y + 13
+		(*page).Text(columns.label, baseline, 10, true, pdf.Black, row.Label)
+		(*page).Text(columns.exercise, baseline, 10, false, pdf.Black, fitText(row.Exercise, 10, columns.setsReps-columns.exercise-8))
+		(*page).Text(columns.setsReps, baseline, 10, false, pdf.Black, fitText(row.SetsReps(), 10, columns.load-columns.setsReps-6))
+		(*page).Text(columns.load, baseline, 10, false, pdf.Black, fitText(row.Load, 10, columns.rest-columns.load-6))
+		(*page).Text(columns.rest, baseline, 10, false, pdf.Black, row.Rest)
+		(*page).Text(columns.tempo, baseline, 10, false, pdf.Black, fitText(row.Tempo, 10, pdf.PageWidth-sheetMargin-columns.tempo))
+		y += sheetRowHeight
+		if row.Notes != "" {
+			(*page).Text(columns.exercise, y+6, 8.5, false, pdf.Gray, fitText(row.Notes, 8.5, pdf.PageWidth-sheetMargin-columns.exercise))
+			y += sheetNoteHeight
+		}
+	}
+
+	if block.Grouped() {
+		(*page).Rect(columns.exercise-sheetGroupInset, barTop+3, 3, y-barTop-3, sheetGroupBar)
+	}
+	(*page).Line(sheetMargin, y+2, pdf.PageWidth-sheetMargin, y+2, 0.25, pdf.LightGray)
+	return y + 4
+}
+
+// fitText shortens s with an ellipsis until its estimated width fits.
+func fitText(s string, size, width float64) string {
+	if pdf.TextWidth(s, size) <= width {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && pdf.TextWidth(string(runes)+"...", size) > width {
+		runes = runes[:len(runes)-1]
+	}
+	return strings.TrimSpace(string(runes)) + "..."
+}
+
+// wrapText breaks s into lines of at most width at word boundaries, capped at four lines.
+func wrapText(s string, size, width float64) []string {
+	const maxLines = 4
+	var lines []string
+	var current string
+	for _, word := range strings.Fields(s) {
+		candidate := word
+		if current != "" {
+			candidate = current + " " + word
+		}
+		if current != "" && pdf.TextWidth(candidate, size) > width {
+			lines = append(lines, current)
+			current = word
+			continue
+		}
+		current = candidate
+	}
+	if current != "" {
+		lines = append(lines, current)
+	}
+	if len(lines) > maxLines {
+		lines = lines[:maxLines]
+		lines[maxLines-1] = fitText(lines[maxLines-1]+" ...", size, width)
+	}
+	for i := range lines {
+		lines[i] = fitText(lines[i], size, width)
+	}
+	return lines
+}
+
+func derefString(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
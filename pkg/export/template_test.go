@@ -0,0 +1,227 @@
+package export
+
+import (
+	"bytes"
+	"chalk-api/pkg/models"
+	"encoding/csv"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func ptr[T any](v T) *T { return &v }
+
+// templateExercise builds a template exercise named name at order.
+func templateExercise(order int, name string) models.WorkoutTemplateExercise {
+	return models.WorkoutTemplateExercise{
+		ExerciseID: uint(order + 100),
+		OrderIndex: order,
+		Exercise:   models.Exercise{Name: name},
+	}
+}
+
+func inSection(e models.WorkoutTemplateExercise, label string) models.WorkoutTemplateExercise {
+	e.SectionLabel = &label
+	return e
+}
+
+func inGroup(e models.WorkoutTemplateExercise, group int, groupType string) models.WorkoutTemplateExercise {
+	e.SupersetGroup = &group
+	if groupType != "" {
+		e.GroupType = &groupType
+	}
+	return e
+}
+
+// sheetShape summarises a sheet as one "section: type[label=exercise,...] ..." line per section.
+func sheetShape(sheet *TemplateSheet) []string {
+	var shape []string
+	for _, section := range sheet.Sections {
+		var blocks []string
+		for _, block := range section.Blocks {
+			var labels []string
+			for _, row := range block.Rows {
+				labels = append(labels, row.Label+"="+row.Exercise)
+			}
+			blocks = append(blocks, block.GroupType+"["+strings.Join(labels, ",")+"]")
+		}
+		shape = append(shape, section.Label+": "+strings.Join(blocks, " "))
+	}
+	return shape
+}
+
+func TestNewTemplateSheetGroupsSectionsAndSupersets(t *testing.T) {
+	template := &models.WorkoutTemplate{
+		ID:          4,
+		Name:        "Upper A",
+		Description: ptr("  Push focus  "),
+		// Deliberately out of order; the sheet follows order_index.
+		Exercises: []models.WorkoutTemplateExercise{
+			inSection(inGroup(templateExercise(3, "Dip"), 1, ""), "Main"),
+			inSection(templateExercise(0, "Band Pull-apart"), "Warm-up"),
+			inSection(templateExercise(1, "Arm Circles"), "Warm-up"),
+			inSection(templateExercise(2, "Bench Press"), "Main"),
+			inSection(inGroup(templateExercise(4, "Push-up"), 1, ""), "Main"),
+			inSection(inGroup(templateExercise(5, "Curl"), 2, "circuit"), "Accessories"),
+			inSection(inGroup(templateExercise(6, "Pushdown"), 2, "circuit"), "Accessories"),
+			inSection(inGroup(templateExercise(7, "Face Pull"), 2, "circuit"), "Accessories"),
+			templateExercise(8, "Walk"),
+		},
+	}
+
+	sheet := NewTemplateSheet(template, "  Iron Works ")
+	if sheet.Title != "Upper A" || sheet.BusinessName != "Iron Works" || sheet.Description != "Push focus" {
+		t.Fatalf("header = %q %q %q, want trimmed title, business name and description", sheet.Title, sheet.BusinessName, sheet.Description)
+	}
+
+	want := []string{
+		"Warm-up: [1=Band Pull-apart] [2=Arm Circles]",
+		"Main: [3=Bench Press] superset[4a=Dip,4b=Push-up]",
+		"Accessories: circuit[5a=Curl,5b=Pushdown,5c=Face Pull]",
+		": [6=Walk]",
+	}
+	if got := sheetShape(sheet); !reflect.DeepEqual(got, want) {
+		t.Fatalf("sheet =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if !sheet.Sections[1].Blocks[1].Grouped() || sheet.Sections[1].Blocks[0].Grouped() {
+		t.Fatal("only the superset block should report Grouped")
+	}
+}
+
+func TestNewTemplateSheetSplitsInterruptedGroups(t *testing.T) {
+	template := &models.WorkoutTemplate{
+		Exercises: []models.WorkoutTemplateExercise{
+			inGroup(templateExercise(0, "Squat"), 1, ""),
+			templateExercise(1, "Plank"),
+			inGroup(templateExercise(2, "Lunge"), 1, ""),
+			// A lone member of a group gets no letter.
+			inGroup(templateExercise(3, "Calf Raise"), 2, ""),
+		},
+	}
+
+	want := []string{": superset[1=Squat] [2=Plank] superset[3=Lunge] superset[4=Calf Raise]"}
+	if got := sheetShape(NewTemplateSheet(template, "")); !reflect.DeepEqual(got, want) {
+		t.Fatalf("sheet = %q, want %q", got, want)
+	}
+}
+
+func TestSheetRowFormatsPrescriptions(t *testing.T) {
+	tests := []struct {
+		name     string
+		exercise models.WorkoutTemplateExercise
+		want     SheetRow
+	}{
+		{
+			name: "rep range with load and rest",
+			exercise: models.WorkoutTemplateExercise{
+				Exercise: models.Exercise{Name: "Row"}, Sets: ptr(3), RepsMin: ptr(8), RepsMax: ptr(12),
+				WeightValue: ptr(62.5), WeightUnit: ptr("kg"), RestSeconds: ptr(90), Tempo: ptr(" 3-1-1-0 "),
+			},
+			want: SheetRow{Exercise: "Row", Sets: "3", Reps: "8-12", Load: "62.5 kg", Rest: "1:30", Tempo: "3-1-1-0"},
+		},
+		{
+			name: "free-text prescription stands in for reps",
+			exercise: models.WorkoutTemplateExercise{
+				ExerciseID: 9, Sets: ptr(2), PrescriptionNote: ptr("AMRAP"), Notes: ptr("Strict form"),
+				WeightUnit: ptr("bodyweight"), RestSeconds: ptr(120),
+			},
+			want: SheetRow{Exercise: "Exercise #9", Sets: "2", Reps: "AMRAP", Load: "Bodyweight", Rest: "2 min", Notes: "Strict form"},
+		},
+		{
+			name: "note alongside structured reps",
+			exercise: models.WorkoutTemplateExercise{
+				Exercise: models.Exercise{Name: "Deadlift"}, RepsMin: ptr(5), RepsMax: ptr(5), PrescriptionNote: ptr("Last set AMRAP"),
+				WeightValue: ptr(80.0), WeightUnit: ptr("percent_1rm"), RestSeconds: ptr(45),
+			},
+			want: SheetRow{Exercise: "Deadlift", Reps: "5", Load: "80% 1RM", Rest: "45s", Notes: "Last set AMRAP"},
+		},
+		{
+			name: "assisted bodyweight",
+			exercise: models.WorkoutTemplateExercise{
+				Exercise: models.Exercise{Name: "Pull-up"}, WeightValue: ptr(-10.0), WeightUnit: ptr("bodyweight"),
+			},
+			want: SheetRow{Exercise: "Pull-up", Load: "BW - 10"},
+		},
+		{
+			name: "rpe target",
+			exercise: models.WorkoutTemplateExercise{
+				Exercise: models.Exercise{Name: "Squat"}, WeightValue: ptr(8.5), WeightUnit: ptr("rpe"),
+			},
+			want: SheetRow{Exercise: "Squat", Load: "RPE 8.5"},
+		},
+	}
+	for _, tt := range tests {
+		if got := sheetRow(&tt.exercise); got != tt.want {
+			t.Errorf("%s: row = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSheetRowSetsReps(t *testing.T) {
+	tests := map[SheetRow]string{
+		{Sets: "3", Reps: "8-12"}: "3 x 8-12",
+		{Sets: "4"}:               "4 sets",
+		{Reps: "AMRAP"}:           "AMRAP",
+		{}:                        "",
+	}
+	for row, want := range tests {
+		if got := row.SetsReps(); got != want {
+			t.Errorf("%+v.SetsReps() = %q, want %q", row, got, want)
+		}
+	}
+}
+
+func TestTemplateSheetFilename(t *testing.T) {
+	tests := []struct {
+		title string
+		want  string
+	}{
+		{"Upper Body A — Week 1!", "upper-body-a-week-1.pdf"},
+		{"  ", "template-7.pdf"},
+		{"💪", "template-7.pdf"},
+		{strings.Repeat("long ", 20), "long-long-long-long-long-long-long-long-long-long-long-long.pdf"},
+	}
+	for _, tt := range tests {
+		sheet := &TemplateSheet{TemplateID: 7, Title: tt.title}
+		if got := sheet.Filename("pdf"); got != tt.want {
+			t.Errorf("Filename(%q) = %q, want %q", tt.title, got, tt.want)
+		}
+	}
+}
+
+func TestWriteCSVRepeatsGroupingAndNeutralisesFormulas(t *testing.T) {
+	template := &models.WorkoutTemplate{
+		Exercises: []models.WorkoutTemplateExercise{
+			inSection(inGroup(templateExercise(0, "=HYPERLINK(\"x\")"), 1, "circuit"), "Main"),
+			inSection(inGroup(templateExercise(1, "Burpee"), 1, "circuit"), "Main"),
+		},
+	}
+	var buf bytes.Buffer
+	if err := NewTemplateSheet(template, "").WriteCSV(&buf); err != nil {
+		t.Fatalf("write csv: %v", err)
+	}
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("read csv: %v", err)
+	}
+	want := [][]string{
+		{"order", "section", "group_type", "exercise", "sets", "reps", "load", "rest", "tempo", "notes"},
+		{"1a", "Main", "circuit", `'=HYPERLINK("x")`, "", "", "", "", "", ""},
+		{"1b", "Main", "circuit", "Burpee", "", "", "", "", "", ""},
+	}
+	if !reflect.DeepEqual(records, want) {
+		t.Fatalf("csv = %q, want %q", records, want)
+	}
+}
+
+func TestPDFRendersADocument(t *testing.T) {
+	template := &models.WorkoutTemplate{
+		Name:      "Full Body",
+		Exercises: []models.WorkoutTemplateExercise{templateExercise(0, "Squat"), templateExercise(1, "Press")},
+	}
+	doc := NewTemplateSheet(template, "Iron Works").PDF()
+	if !bytes.HasPrefix(doc, []byte("%PDF")) {
+		t.Fatalf("PDF starts with %q, want a %%PDF header", doc[:min(len(doc), 8)])
+	}
+}
@@ -0,0 +1,228 @@
+package breaker
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	defaultFailureThreshold = 5
+	defaultCooldown         = 30 * time.Second
+)
+
+// ErrOpen is returned instead of calling the provider while the breaker is open.
+var ErrOpen = errors.New("circuit breaker is open")
+
+// State is the breaker's current position.
+type State int
+
+const (
+	// StateClosed lets every call through and counts consecutive failures
+	StateClosed State = iota
+	// StateOpen rejects calls until the cooldown has passed
+	StateOpen
+	// StateHalfOpen lets a single probe through; its result closes or reopens the breaker
+	StateHalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half_open"
+	default:
+		return fmt.Sprintf("state(%d)", int(s))
+	}
+}
+
+// Config tunes a breaker. Zero values fall back to the defaults.
+type Config struct {
+	Name             string
+	FailureThreshold int
+	Cooldown         time.Duration
+	// Now is the breaker's clock; tests swap in a fake one
+	Now func() time.Time
+}
+
+// Stats is a point-in-time view of a breaker, used by /metrics.
+type Stats struct {
+	Name                string
+	State               State
+	ConsecutiveFailures int
+	Trips               int64
+	Rejected            int64
+	OpenedAt            *time.Time
+}
+
+// Breaker is a consecutive-failure circuit breaker shared by every call to one provider.
+type Breaker struct {
+	name             string
+	failureThreshold int
+	cooldown         time.Duration
+	now              func() time.Time
+
+	mu       sync.Mutex
+	state    State
+	failures int
+	openedAt time.Time
+	probing  bool
+	trips    int64
+	rejected int64
+}
+
+// New creates a closed breaker.
+func New(cfg Config) *Breaker {
+	if cfg.FailureThreshold <= 0 {
+		cfg.FailureThreshold = defaultFailureThreshold
+	}
+	if cfg.Cooldown <= 0 {
+		cfg.Cooldown = defaultCooldown
+	}
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+
+	return &Breaker{
+		name:             cfg.Name,
+		failureThreshold: cfg.FailureThreshold,
+		cooldown:         cfg.Cooldown,
+		now:              cfg.Now,
+	}
+}
+
+// Name returns the provider name the breaker was created with.
+func (b *Breaker) Name() string {
+	return b.name
+}
+
+// Allow reports whether a call may proceed. Every nil return must be followed by Success or Failure.
+func (b *Breaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case StateOpen:
+		if b.now().Sub(b.openedAt) < b.cooldown {
+			b.rejected++
+			return ErrOpen
+		}
+		b.setState(StateHalfOpen)
+		b.probing = true
+		return nil
+	case StateHalfOpen:
+		// Only one probe at a time; everyone else keeps failing fast until it reports back
+		if b.probing {
+			b.rejected++
+			return ErrOpen
+		}
+		b.probing = true
+		return nil
+	default:
+		return nil
+	}
+}
+
+// Success records a healthy call and closes the breaker.
+func (b *Breaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures = 0
+	b.probing = false
+	if b.state != StateClosed {
+		b.setState(StateClosed)
+	}
+}
+
+// Failure records a failed call, opening the breaker once the threshold is reached
+// or immediately when the half-open probe fails.
+func (b *Breaker) Failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.probing = false
+	if b.state == StateHalfOpen || (b.state == StateClosed && b.failures >= b.failureThreshold) {
+		b.openedAt = b.now()
+		b.trips++
+		b.setState(StateOpen)
+	}
+}
+
+// State returns the current state, reporting half-open once an open breaker's cooldown has passed.
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.currentState()
+}
+
+func (b *Breaker) Stats() Stats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	stats := Stats{
+		Name:                b.name,
+		State:               b.currentState(),
+		ConsecutiveFailures: b.failures,
+		Trips:               b.trips,
+		Rejected:            b.rejected,
+	}
+	if b.state != StateClosed {
+		openedAt := b.openedAt
+		stats.OpenedAt = &openedAt
+	}
+	return stats
+}
+
+func (b *Breaker) currentState() State {
+	if b.state == StateOpen && b.now().Sub(b.openedAt) >= b.cooldown {
+		return StateHalfOpen
+	}
+	return b.state
+}
+
+// setState must be called with mu held.
+func (b *Breaker) setState(state State) {
+	from := b.state
+	b.state = state
+	if state == StateOpen {
+		slog.Warn("Circuit breaker opened", "name", b.name, "failures", b.failures, "cooldown", b.cooldown.String())
+		return
+	}
+	slog.Info("Circuit breaker state changed", "name", b.name, "from", from.String(), "to", state.String())
+}
+
+// Transport wraps next so every request through it goes through the breaker. Network errors,
+// 5xx and 429 responses count as failures; other responses count as successes.
+func (b *Breaker) Transport(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &transport{breaker: b, next: next}
+}
+
+type transport struct {
+	breaker *Breaker
+	next    http.RoundTripper
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.breaker.Allow(); err != nil {
+		return nil, fmt.Errorf("%s: %w", t.breaker.name, err)
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil || resp.StatusCode >= http.StatusInternalServerError || resp.StatusCode == http.StatusTooManyRequests {
+		t.breaker.Failure()
+	} else {
+		t.breaker.Success()
+	}
+	return resp, err
+}
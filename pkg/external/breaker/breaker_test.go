@@ -0,0 +1,180 @@
+package breaker
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// fakeClock is a manually advanced Config.Now.
+type fakeClock struct{ now time.Time }
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time          { return c.now }
+func (c *fakeClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func newTestBreaker(clock *fakeClock) *Breaker {
+	return New(Config{Name: "test", FailureThreshold: 3, Cooldown: time.Minute, Now: clock.Now})
+}
+
+// trip records failures until the breaker opens.
+func trip(t *testing.T, b *Breaker) {
+	t.Helper()
+	for i := 0; i < 3; i++ {
+		if err := b.Allow(); err != nil {
+			t.Fatalf("call %d rejected before the threshold: %v", i+1, err)
+		}
+		b.Failure()
+	}
+	if got := b.State(); got != StateOpen {
+		t.Fatalf("state after threshold = %s, want open", got)
+	}
+}
+
+func TestBreakerOpensAtThreshold(t *testing.T) {
+	b := newTestBreaker(newFakeClock())
+
+	for i := 0; i < 2; i++ {
+		if err := b.Allow(); err != nil {
+			t.Fatalf("call %d: %v", i+1, err)
+		}
+		b.Failure()
+	}
+	if got := b.State(); got != StateClosed {
+		t.Fatalf("state after 2 failures = %s, want closed", got)
+	}
+
+	// A success resets the run of consecutive failures.
+	b.Success()
+	for i := 0; i < 2; i++ {
+		b.Failure()
+	}
+	if got := b.State(); got != StateClosed {
+		t.Fatalf("state after a success and 2 failures = %s, want closed", got)
+	}
+
+	b.Failure()
+	if got := b.State(); got != StateOpen {
+		t.Fatalf("state after 3 consecutive failures = %s, want open", got)
+	}
+	if err := b.Allow(); !errors.Is(err, ErrOpen) {
+		t.Fatalf("Allow while open = %v, want ErrOpen", err)
+	}
+
+	stats := b.Stats()
+	if stats.Trips != 1 || stats.Rejected != 1 || stats.OpenedAt == nil {
+		t.Fatalf("stats = %+v, want 1 trip, 1 rejection and an opened_at", stats)
+	}
+}
+
+func TestBreakerHalfOpensAfterCooldown(t *testing.T) {
+	clock := newFakeClock()
+	b := newTestBreaker(clock)
+	trip(t, b)
+
+	clock.Advance(time.Minute - time.Second)
+	if err := b.Allow(); !errors.Is(err, ErrOpen) {
+		t.Fatalf("Allow before the cooldown = %v, want ErrOpen", err)
+	}
+	if got := b.State(); got != StateOpen {
+		t.Fatalf("state before the cooldown = %s, want open", got)
+	}
+
+	clock.Advance(time.Second)
+	if got := b.State(); got != StateHalfOpen {
+		t.Fatalf("state after the cooldown = %s, want half_open", got)
+	}
+	if err := b.Allow(); err != nil {
+		t.Fatalf("probe rejected: %v", err)
+	}
+	// Only the one probe gets through while it is outstanding.
+	if err := b.Allow(); !errors.Is(err, ErrOpen) {
+		t.Fatalf("second call during the probe = %v, want ErrOpen", err)
+	}
+}
+
+func TestBreakerHalfOpenProbeSuccessCloses(t *testing.T) {
+	clock := newFakeClock()
+	b := newTestBreaker(clock)
+	trip(t, b)
+	clock.Advance(time.Minute)
+
+	if err := b.Allow(); err != nil {
+		t.Fatalf("probe rejected: %v", err)
+	}
+	b.Success()
+
+	if got := b.State(); got != StateClosed {
+		t.Fatalf("state after a successful probe = %s, want closed", got)
+	}
+	stats := b.Stats()
+	if stats.ConsecutiveFailures != 0 || stats.OpenedAt != nil {
+		t.Fatalf("stats = %+v, want a reset closed breaker", stats)
+	}
+	// A closed breaker needs the full threshold again to open.
+	b.Failure()
+	if got := b.State(); got != StateClosed {
+		t.Fatalf("state after one failure = %s, want closed", got)
+	}
+}
+
+func TestBreakerHalfOpenProbeFailureReopens(t *testing.T) {
+	clock := newFakeClock()
+	b := newTestBreaker(clock)
+	trip(t, b)
+	clock.Advance(time.Minute)
+
+	if err := b.Allow(); err != nil {
+		t.Fatalf("probe rejected: %v", err)
+	}
+	b.Failure()
+
+	if got := b.State(); got != StateOpen {
+		t.Fatalf("state after a failed probe = %s, want open", got)
+	}
+	stats := b.Stats()
+	if stats.Trips != 2 || stats.OpenedAt == nil || !stats.OpenedAt.Equal(clock.Now()) {
+		t.Fatalf("stats = %+v, want a second trip opened at %s", stats, clock.Now())
+	}
+
+	// The cooldown restarts from the failed probe.
+	clock.Advance(time.Minute - time.Second)
+	if err := b.Allow(); !errors.Is(err, ErrOpen) {
+		t.Fatalf("Allow before the new cooldown = %v, want ErrOpen", err)
+	}
+	clock.Advance(time.Second)
+	if err := b.Allow(); err != nil {
+		t.Fatalf("probe after the new cooldown rejected: %v", err)
+	}
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func TestTransportCountsServerErrorsAsFailures(t *testing.T) {
+	b := newTestBreaker(newFakeClock())
+	calls := 0
+	client := &http.Client{Transport: b.Transport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		calls++
+		return &http.Response{StatusCode: http.StatusServiceUnavailable, Body: http.NoBody, Request: req}, nil
+	}))}
+
+	for i := 0; i < 3; i++ {
+		resp, err := client.Get("http://provider.test/")
+		if err != nil {
+			t.Fatalf("call %d: %v", i+1, err)
+		}
+		resp.Body.Close()
+	}
+	if _, err := client.Get("http://provider.test/"); !errors.Is(err, ErrOpen) {
+		t.Fatalf("call while open = %v, want ErrOpen", err)
+	}
+	if calls != 3 {
+		t.Fatalf("provider calls = %d, want 3", calls)
+	}
+}
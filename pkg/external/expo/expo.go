@@ -2,12 +2,20 @@ package expo
 
 import (
 	"bytes"
+	"chalk-api/pkg/external/breaker"
+	"chalk-api/pkg/i18n"
+	"chalk-api/pkg/tracing"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
 	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
@@ -20,9 +28,9 @@ const (
 // API defines the interface for Expo Push operations
 type API interface {
 	// SendPush sends push notifications to one or more devices
-	SendPush(messages []PushMessage) ([]PushTicket, error)
+	SendPush(ctx context.Context, messages []PushMessage) ([]PushTicket, error)
 	// GetReceipts fetches delivery receipts for sent notifications
-	GetReceipts(ticketIDs []string) (map[string]PushReceipt, error)
+	GetReceipts(ctx context.Context, ticketIDs []string) (map[string]PushReceipt, error)
 }
 
 // Expo implements the API interface
@@ -31,12 +39,18 @@ type Expo struct {
 	accessToken string
 }
 
-// New creates a new Expo Push API instance
-func New(accessToken string) *Expo {
+// New creates a new Expo Push API instance. When cb is set, requests fail fast with
+// breaker.ErrOpen while Expo is unhealthy instead of waiting out the timeout.
+func New(accessToken string, cb *breaker.Breaker) *Expo {
+	httpClient := &http.Client{
+		Timeout: defaultTimeout,
+	}
+	if cb != nil {
+		httpClient.Transport = cb.Transport(http.DefaultTransport)
+	}
+
 	return &Expo{
-		httpClient: &http.Client{
-			Timeout: defaultTimeout,
-		},
+		httpClient:  httpClient,
 		accessToken: accessToken,
 	}
 }
@@ -49,7 +63,7 @@ func (e *Expo) IsConfigured() bool {
 
 // SendPush sends push notifications
 // Automatically batches large requests to respect Expo's limits
-func (e *Expo) SendPush(messages []PushMessage) ([]PushTicket, error) {
+func (e *Expo) SendPush(ctx context.Context, messages []PushMessage) ([]PushTicket, error) {
 	if len(messages) == 0 {
 		return nil, nil
 	}
@@ -79,7 +93,7 @@ func (e *Expo) SendPush(messages []PushMessage) ([]PushTicket, error) {
 		}
 		batch := expandedMessages[i:end]
 
-		tickets, err := e.sendBatch(batch)
+		tickets, err := e.sendBatch(ctx, batch)
 		if err != nil {
 			return allTickets, fmt.Errorf("batch %d failed: %w", i/maxBatchSize, err)
 		}
@@ -89,14 +103,24 @@ func (e *Expo) SendPush(messages []PushMessage) ([]PushTicket, error) {
 	return allTickets, nil
 }
 
-// sendBatch sends a single batch of messages
-func (e *Expo) sendBatch(messages []PushMessage) ([]PushTicket, error) {
+// sendBatch sends a single batch of messages in its own client span
+func (e *Expo) sendBatch(ctx context.Context, messages []PushMessage) ([]PushTicket, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "expo.SendPush",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.Int("expo.message_count", len(messages))),
+	)
+	tickets, err := e.postBatch(ctx, messages)
+	tracing.End(span, err)
+	return tickets, err
+}
+
+func (e *Expo) postBatch(ctx context.Context, messages []PushMessage) ([]PushTicket, error) {
 	body, err := json.Marshal(messages)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal messages: %w", err)
 	}
 
-	req, err := http.NewRequest(http.MethodPost, pushURL, bytes.NewReader(body))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, pushURL, bytes.NewReader(body))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -114,6 +138,7 @@ func (e *Expo) sendBatch(messages []PushMessage) ([]PushTicket, error) {
 		return nil, fmt.Errorf("request failed: %w", err)
 	}
 	defer resp.Body.Close()
+	trace.SpanFromContext(ctx).SetAttributes(semconv.HTTPResponseStatusCode(resp.StatusCode))
 
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
@@ -133,18 +158,28 @@ func (e *Expo) sendBatch(messages []PushMessage) ([]PushTicket, error) {
 }
 
 // GetReceipts fetches delivery receipts for the given ticket IDs
-func (e *Expo) GetReceipts(ticketIDs []string) (map[string]PushReceipt, error) {
+func (e *Expo) GetReceipts(ctx context.Context, ticketIDs []string) (map[string]PushReceipt, error) {
 	if len(ticketIDs) == 0 {
 		return nil, nil
 	}
 
+	ctx, span := tracing.Tracer().Start(ctx, "expo.GetReceipts",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.Int("expo.ticket_count", len(ticketIDs))),
+	)
+	receipts, err := e.fetchReceipts(ctx, ticketIDs)
+	tracing.End(span, err)
+	return receipts, err
+}
+
+func (e *Expo) fetchReceipts(ctx context.Context, ticketIDs []string) (map[string]PushReceipt, error) {
 	payload := map[string][]string{"ids": ticketIDs}
 	body, err := json.Marshal(payload)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	req, err := http.NewRequest(http.MethodPost, receiptsURL, bytes.NewReader(body))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, receiptsURL, bytes.NewReader(body))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -160,6 +195,7 @@ func (e *Expo) GetReceipts(ticketIDs []string) (map[string]PushReceipt, error) {
 		return nil, fmt.Errorf("request failed: %w", err)
 	}
 	defer resp.Body.Close()
+	trace.SpanFromContext(ctx).SetAttributes(semconv.HTTPResponseStatusCode(resp.StatusCode))
 
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
@@ -178,14 +214,15 @@ func (e *Expo) GetReceipts(ticketIDs []string) (map[string]PushReceipt, error) {
 	return result.Data, nil
 }
 
-// Helper functions for building common notifications
+// Helper functions for building common notifications. locale is the recipient's profile locale;
+// text for unsupported locales falls back to English.
 
 // NewWorkoutAssignedNotification creates a notification for when a workout is assigned
-func NewWorkoutAssignedNotification(token string, coachName, workoutName string) PushMessage {
+func NewWorkoutAssignedNotification(token, locale string, coachName, workoutName string) PushMessage {
 	return PushMessage{
 		To:    []string{token},
-		Title: "New Workout Assigned",
-		Body:  fmt.Sprintf("%s assigned you a workout: %s", coachName, workoutName),
+		Title: i18n.T(locale, "push.workout_assigned.title"),
+		Body:  i18n.T(locale, "push.workout_assigned.body_coach", coachName, workoutName),
 		Sound: "default",
 		Data: map[string]any{
 			"type": NotificationTypeWorkoutAssigned,
@@ -193,8 +230,11 @@ func NewWorkoutAssignedNotification(token string, coachName, workoutName string)
 	}
 }
 
-// NewMessageNotification creates a notification for a new message
-func NewMessageNotification(token string, senderName, preview string) PushMessage {
+// NewMessageNotification creates a notification for a new message. An empty preview shows a generic body.
+func NewMessageNotification(token, locale string, senderName, preview string) PushMessage {
+	if preview == "" {
+		preview = i18n.T(locale, "push.message.body")
+	}
 	return PushMessage{
 		To:    []string{token},
 		Title: senderName,
@@ -207,11 +247,11 @@ func NewMessageNotification(token string, senderName, preview string) PushMessag
 }
 
 // NewSessionReminderNotification creates a reminder notification for an upcoming session
-func NewSessionReminderNotification(token string, sessionTime time.Time, otherPartyName string) PushMessage {
+func NewSessionReminderNotification(token, locale string, sessionTime time.Time, otherPartyName string) PushMessage {
 	return PushMessage{
 		To:    []string{token},
-		Title: "Session Reminder",
-		Body:  fmt.Sprintf("Your session with %s starts in 1 hour", otherPartyName),
+		Title: i18n.T(locale, "push.session_reminder.title"),
+		Body:  i18n.T(locale, "push.session_reminder.body", otherPartyName),
 		Sound: "default",
 		Data: map[string]any{
 			"type":        NotificationTypeSessionReminder,
@@ -4,7 +4,7 @@ package expo
 type PushMessage struct {
 	To       []string          `json:"to"`                 // Expo push tokens
 	Title    string            `json:"title,omitempty"`
-	Body     string            `json:"body"`
+	Body     string            `json:"body,omitempty"`
 	Data     map[string]any    `json:"data,omitempty"`     // Custom data payload
 	Sound    string            `json:"sound,omitempty"`    // "default" or custom sound
 	Badge    *int              `json:"badge,omitempty"`    // iOS badge count
@@ -13,6 +13,7 @@ type PushMessage struct {
 	Subtitle string            `json:"subtitle,omitempty"` // iOS subtitle
 	ChannelID string           `json:"channelId,omitempty"` // Android notification channel
 	CategoryID string          `json:"categoryId,omitempty"` // Notification category for actions
+	ContentAvailable bool      `json:"_contentAvailable,omitempty"` // iOS background delivery for data-only pushes
 }
 
 // PushTicket is the response from sending a push notification
@@ -0,0 +1,32 @@
+// Package geoip resolves client IP addresses to a coarse location. It only defines the interface
+// login alerts depend on plus a disabled default, so new-login detection falls back to comparing
+// devices until a provider is plugged in.
+package geoip
+
+import "context"
+
+// Location is where an IP address appears to be. Country is an ISO 3166-1 alpha-2 code; City may be
+// empty when the provider only resolves countries.
+type Location struct {
+	City    string
+	Country string
+}
+
+// API defines the interface for IP geolocation providers
+type API interface {
+	// Lookup returns the location of ip, or nil when it can't be placed (private ranges, unknown).
+	Lookup(ctx context.Context, ip string) (*Location, error)
+	// IsConfigured returns true when lookups can return locations
+	IsConfigured() bool
+}
+
+// Disabled is the API used when no provider is configured; it never resolves an address.
+type Disabled struct{}
+
+func (Disabled) Lookup(ctx context.Context, ip string) (*Location, error) {
+	return nil, nil
+}
+
+func (Disabled) IsConfigured() bool {
+	return false
+}
@@ -2,10 +2,14 @@ package external
 
 import (
 	"chalk-api/pkg/config"
+	"chalk-api/pkg/external/breaker"
 	"chalk-api/pkg/external/expo"
+	"chalk-api/pkg/external/geoip"
 	"chalk-api/pkg/external/openfoodfacts"
 	"chalk-api/pkg/external/revenuecat"
+	"chalk-api/pkg/external/storage"
 	"log/slog"
+	"time"
 )
 
 // Collection contains all external API integrations
@@ -13,6 +17,11 @@ type Collection struct {
 	OpenFoodFacts openfoodfacts.API
 	RevenueCat    revenuecat.API
 	Expo          expo.API
+	Storage       storage.API
+	GeoIP         geoip.API
+
+	// Breakers guards the HTTP providers; their state is exported on /metrics
+	Breakers []*breaker.Breaker
 }
 
 // Initialize creates all external API integrations
@@ -23,10 +32,31 @@ func Initialize(cfg config.Environment) *Collection {
 		webhookAuthorization = cfg.RevenueCatWebhookSecret
 	}
 
+	breakerConfig := func(name string) breaker.Config {
+		return breaker.Config{
+			Name:             name,
+			FailureThreshold: cfg.CircuitBreakerFailureThreshold,
+			Cooldown:         time.Duration(cfg.CircuitBreakerCooldownSeconds) * time.Second,
+		}
+	}
+	revenueCatBreaker := breaker.New(breakerConfig("revenuecat"))
+	expoBreaker := breaker.New(breakerConfig("expo"))
+
 	collection := &Collection{
 		OpenFoodFacts: openfoodfacts.New(cfg.OpenFoodFactsUserAgent),
-		RevenueCat:    revenuecat.New(cfg.RevenueCatAPIKey, webhookAuthorization),
-		Expo:          expo.New(cfg.ExpoAccessToken),
+		RevenueCat:    revenuecat.New(cfg.RevenueCatAPIKey, webhookAuthorization, revenueCatBreaker),
+		Expo:          expo.New(cfg.ExpoAccessToken, expoBreaker),
+		Storage: storage.New(storage.Config{
+			Endpoint:        cfg.StorageEndpoint,
+			Region:          cfg.StorageRegion,
+			Bucket:          cfg.StorageBucket,
+			AccessKeyID:     cfg.StorageAccessKeyID,
+			SecretAccessKey: cfg.StorageSecretAccessKey,
+			PublicBaseURL:   cfg.StoragePublicBaseURL,
+		}),
+		// No geolocation provider is wired in yet; new-login alerts compare devices only.
+		GeoIP:    geoip.Disabled{},
+		Breakers: []*breaker.Breaker{revenueCatBreaker, expoBreaker},
 	}
 
 	// Log which integrations are configured
@@ -42,6 +72,16 @@ func Initialize(cfg config.Environment) *Collection {
 		slog.Info("Expo push notifications configured without auth (rate limited)")
 	}
 
+	if collection.Storage.IsConfigured() {
+		slog.Info("Object storage configured", "bucket", cfg.StorageBucket)
+	} else {
+		slog.Warn("Object storage not configured, uploads disabled")
+	}
+
+	if !collection.GeoIP.IsConfigured() {
+		slog.Info("IP geolocation not configured, new-login alerts compare devices only")
+	}
+
 	slog.Info("Open Food Facts integration configured", "userAgent", cfg.OpenFoodFactsUserAgent)
 
 	return collection
@@ -1,6 +1,9 @@
 package revenuecat
 
 import (
+	"chalk-api/pkg/external/breaker"
+	"chalk-api/pkg/tracing"
+	"context"
 	"crypto/subtle"
 	"encoding/json"
 	"fmt"
@@ -8,6 +11,9 @@ import (
 	"log/slog"
 	"net/http"
 	"time"
+
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
@@ -18,7 +24,7 @@ const (
 // API defines the interface for RevenueCat operations
 type API interface {
 	// GetSubscriber fetches subscriber info by app user ID
-	GetSubscriber(appUserID string) (*Subscriber, error)
+	GetSubscriber(ctx context.Context, appUserID string) (*Subscriber, error)
 	// ValidateWebhook validates webhook authorization and parses the event
 	ValidateWebhook(body []byte, authorization string) (*WebhookEvent, error)
 }
@@ -30,12 +36,18 @@ type RevenueCat struct {
 	webhookAuthorization string
 }
 
-// New creates a new RevenueCat API instance
-func New(apiKey, webhookAuthorization string) *RevenueCat {
+// New creates a new RevenueCat API instance. When cb is set, requests fail fast with
+// breaker.ErrOpen while RevenueCat is unhealthy instead of waiting out the timeout.
+func New(apiKey, webhookAuthorization string, cb *breaker.Breaker) *RevenueCat {
+	httpClient := &http.Client{
+		Timeout: defaultTimeout,
+	}
+	if cb != nil {
+		httpClient.Transport = cb.Transport(http.DefaultTransport)
+	}
+
 	return &RevenueCat{
-		httpClient: &http.Client{
-			Timeout: defaultTimeout,
-		},
+		httpClient:           httpClient,
 		apiKey:               apiKey,
 		webhookAuthorization: webhookAuthorization,
 	}
@@ -46,8 +58,15 @@ func (r *RevenueCat) IsConfigured() bool {
 	return r.apiKey != ""
 }
 
-// GetSubscriber fetches subscriber info from RevenueCat
-func (r *RevenueCat) GetSubscriber(appUserID string) (*Subscriber, error) {
+// GetSubscriber fetches subscriber info from RevenueCat, traced as a client span
+func (r *RevenueCat) GetSubscriber(ctx context.Context, appUserID string) (*Subscriber, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "revenuecat.GetSubscriber", trace.WithSpanKind(trace.SpanKindClient))
+	subscriber, err := r.getSubscriber(ctx, appUserID)
+	tracing.End(span, err)
+	return subscriber, err
+}
+
+func (r *RevenueCat) getSubscriber(ctx context.Context, appUserID string) (*Subscriber, error) {
 	if !r.IsConfigured() {
 		return nil, fmt.Errorf("RevenueCat API key not configured")
 	}
@@ -58,7 +77,7 @@ func (r *RevenueCat) GetSubscriber(appUserID string) (*Subscriber, error) {
 
 	endpoint := fmt.Sprintf("%s/subscribers/%s", baseURL, appUserID)
 
-	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -73,6 +92,7 @@ func (r *RevenueCat) GetSubscriber(appUserID string) (*Subscriber, error) {
 		return nil, fmt.Errorf("request failed: %w", err)
 	}
 	defer resp.Body.Close()
+	trace.SpanFromContext(ctx).SetAttributes(semconv.HTTPResponseStatusCode(resp.StatusCode))
 
 	if resp.StatusCode == http.StatusNotFound {
 		return nil, nil // Subscriber not found
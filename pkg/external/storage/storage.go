@@ -0,0 +1,247 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultTimeout   = 30 * time.Second
+	maxPresignExpiry = 7 * 24 * time.Hour // SigV4 hard limit
+)
+
+// ErrNotConfigured is returned when object storage credentials are missing.
+var ErrNotConfigured = errors.New("object storage is not configured")
+
+// ErrObjectNotFound is returned by HeadObject when the key does not exist.
+var ErrObjectNotFound = errors.New("object not found")
+
+// API defines the interface for S3-compatible object storage operations
+type API interface {
+	// PutObject uploads body under key
+	PutObject(ctx context.Context, key, contentType string, body []byte) error
+	// PresignPut returns a URL the client can PUT to directly. Content type and length are
+	// part of the signature, so the upload is rejected if the client sends anything else.
+	PresignPut(key, contentType string, contentLength int64, expires time.Duration) (*PresignedRequest, error)
+	// PresignGet returns a time-limited download URL for a private object
+	PresignGet(key string, expires time.Duration) (string, error)
+	// HeadObject returns object metadata, or ErrObjectNotFound
+	HeadObject(ctx context.Context, key string) (*ObjectInfo, error)
+	// DeleteObject removes key. Deleting a missing key is not an error.
+	DeleteObject(ctx context.Context, key string) error
+	// PublicURL returns the public URL for key
+	PublicURL(key string) string
+	// KeyFromURL reverses PublicURL; false when the URL is not one of ours
+	KeyFromURL(rawURL string) (string, bool)
+	// IsConfigured returns true when credentials and bucket are set
+	IsConfigured() bool
+}
+
+// Config holds the connection settings for an S3-compatible bucket
+type Config struct {
+	Endpoint        string // e.g. https://s3.us-east-1.amazonaws.com or https://<account>.r2.cloudflarestorage.com
+	Region          string
+	Bucket          string
+	AccessKeyID     string
+	SecretAccessKey string
+	PublicBaseURL   string // optional CDN base; defaults to path-style bucket URL
+}
+
+// Storage implements the API interface using path-style S3 requests signed with SigV4
+type Storage struct {
+	httpClient *http.Client
+	cfg        Config
+}
+
+// New creates a new object storage client
+func New(cfg Config) *Storage {
+	cfg.Endpoint = strings.TrimRight(cfg.Endpoint, "/")
+	cfg.PublicBaseURL = strings.TrimRight(cfg.PublicBaseURL, "/")
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	return &Storage{
+		httpClient: &http.Client{
+			Timeout: defaultTimeout,
+		},
+		cfg: cfg,
+	}
+}
+
+// IsConfigured returns true if endpoint, bucket and credentials are set
+func (s *Storage) IsConfigured() bool {
+	return s.cfg.Endpoint != "" && s.cfg.Bucket != "" && s.cfg.AccessKeyID != "" && s.cfg.SecretAccessKey != ""
+}
+
+func (s *Storage) PutObject(ctx context.Context, key, contentType string, body []byte) error {
+	if !s.IsConfigured() {
+		return ErrNotConfigured
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.objectURL(key), bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.ContentLength = int64(len(body))
+
+	sum := sha256.Sum256(body)
+	s.signRequest(req, hex.EncodeToString(sum[:]), time.Now().UTC())
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("put object returned status %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	slog.Debug("Storage object uploaded", "key", key, "bytes", len(body))
+	return nil
+}
+
+func (s *Storage) PresignPut(key, contentType string, contentLength int64, expires time.Duration) (*PresignedRequest, error) {
+	if !s.IsConfigured() {
+		return nil, ErrNotConfigured
+	}
+
+	headers := map[string]string{
+		"content-type":   contentType,
+		"content-length": strconv.FormatInt(contentLength, 10),
+	}
+	signedURL, expiresAt, err := s.presign(http.MethodPut, key, headers, expires)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PresignedRequest{
+		Method: http.MethodPut,
+		URL:    signedURL,
+		Headers: map[string]string{
+			"Content-Type":   contentType,
+			"Content-Length": strconv.FormatInt(contentLength, 10),
+		},
+		ObjectKey: key,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+func (s *Storage) PresignGet(key string, expires time.Duration) (string, error) {
+	if !s.IsConfigured() {
+		return "", ErrNotConfigured
+	}
+	signedURL, _, err := s.presign(http.MethodGet, key, nil, expires)
+	return signedURL, err
+}
+
+func (s *Storage) HeadObject(ctx context.Context, key string) (*ObjectInfo, error) {
+	if !s.IsConfigured() {
+		return nil, ErrNotConfigured
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, s.objectURL(key), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	s.signRequest(req, emptyPayloadHash, time.Now().UTC())
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrObjectNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("head object returned status %d", resp.StatusCode)
+	}
+
+	return &ObjectInfo{
+		Key:         key,
+		Size:        resp.ContentLength,
+		ContentType: resp.Header.Get("Content-Type"),
+	}, nil
+}
+
+func (s *Storage) DeleteObject(ctx context.Context, key string) error {
+	if !s.IsConfigured() {
+		return ErrNotConfigured
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, s.objectURL(key), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	s.signRequest(req, emptyPayloadHash, time.Now().UTC())
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// S3 returns 204 for both existing and missing keys; some compatible stores return 404.
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("delete object returned status %d: %s", resp.StatusCode, string(respBody))
+	}
+	return nil
+}
+
+func (s *Storage) PublicURL(key string) string {
+	return s.publicBase() + "/" + escapePath(key)
+}
+
+func (s *Storage) KeyFromURL(rawURL string) (string, bool) {
+	prefix := s.publicBase() + "/"
+	if rawURL == "" || !strings.HasPrefix(rawURL, prefix) {
+		return "", false
+	}
+	key, err := url.PathUnescape(strings.TrimPrefix(rawURL, prefix))
+	if err != nil || key == "" {
+		return "", false
+	}
+	return key, true
+}
+
+func (s *Storage) publicBase() string {
+	if s.cfg.PublicBaseURL != "" {
+		return s.cfg.PublicBaseURL
+	}
+	return s.cfg.Endpoint + "/" + s.cfg.Bucket
+}
+
+func (s *Storage) objectURL(key string) string {
+	return s.cfg.Endpoint + "/" + s.cfg.Bucket + "/" + escapePath(key)
+}
+
+func (s *Storage) presign(method, key string, headers map[string]string, expires time.Duration) (string, time.Time, error) {
+	if expires <= 0 || expires > maxPresignExpiry {
+		return "", time.Time{}, fmt.Errorf("presign expiry must be between 1s and %s", maxPresignExpiry)
+	}
+
+	endpoint, err := url.Parse(s.objectURL(key))
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("invalid object url: %w", err)
+	}
+
+	now := time.Now().UTC()
+	signed := s.presignURL(method, endpoint, headers, expires, now)
+	return signed, now.Add(expires), nil
+}
@@ -0,0 +1,19 @@
+package storage
+
+import "time"
+
+// PresignedRequest describes a direct-to-storage request the client must perform
+type PresignedRequest struct {
+	Method    string            `json:"method"`
+	URL       string            `json:"url"`
+	Headers   map[string]string `json:"headers"` // must be sent exactly as given; they are signed
+	ObjectKey string            `json:"object_key"`
+	ExpiresAt time.Time         `json:"expires_at"`
+}
+
+// ObjectInfo is the subset of object metadata we care about
+type ObjectInfo struct {
+	Key         string `json:"key"`
+	Size        int64  `json:"size"`
+	ContentType string `json:"content_type"`
+}
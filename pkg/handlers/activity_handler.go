@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"chalk-api/pkg/handlers/errmap"
+	"chalk-api/pkg/services"
+	"chalk-api/pkg/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ActivityHandler struct {
+	activityService *services.ActivityService
+}
+
+func NewActivityHandler(activityService *services.ActivityService) *ActivityHandler {
+	return &ActivityHandler{activityService: activityService}
+}
+
+// ImportMySamples stores a batch of up to 500 synced HealthKit or Google Fit samples.
+func (h *ActivityHandler) ImportMySamples(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var input services.ImportActivitySamplesInput
+	if err := bindJSON(c, &input); err != nil {
+		errmap.RespondBindError(c, err)
+		return
+	}
+
+	result, err := h.activityService.ImportMySamples(c.Request.Context(), userID, input)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetMyDailyActivity returns daily totals for ?start_date=&end_date=, with ?client_profile_id= when
+// the client has more than one active coach.
+func (h *ActivityHandler) GetMyDailyActivity(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var clientProfileID *uint
+	if raw := c.Query("client_profile_id"); raw != "" {
+		id, valid := parseUintParam(raw)
+		if !valid {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid client_profile_id"})
+			return
+		}
+		clientProfileID = &id
+	}
+
+	summary, err := h.activityService.GetMyDailyActivity(c.Request.Context(), userID, clientProfileID, c.Query("start_date"), c.Query("end_date"))
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
+
+func (h *ActivityHandler) GetClientDailyActivity(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	clientProfileID, valid := parseUintParam(c.Param("id"))
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid client id"})
+		return
+	}
+
+	summary, err := h.activityService.GetClientDailyActivity(c.Request.Context(), userID, clientProfileID, c.Query("start_date"), c.Query("end_date"))
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
@@ -0,0 +1,330 @@
+package handlers
+
+import (
+	"chalk-api/pkg/handlers/errmap"
+	"chalk-api/pkg/services"
+	"chalk-api/pkg/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AdminHandler struct {
+	adminService *services.AdminService
+}
+
+func NewAdminHandler(adminService *services.AdminService) *AdminHandler {
+	return &AdminHandler{adminService: adminService}
+}
+
+// TransferClient moves a client to another coach. Only admins may call it.
+func (h *AdminHandler) TransferClient(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	clientProfileID, valid := parseUintPathParam(c.Param("client_profile_id"))
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid client profile id"})
+		return
+	}
+
+	var input services.TransferClientInput
+	if err := bindJSON(c, &input); err != nil {
+		errmap.RespondBindError(c, err)
+		return
+	}
+
+	result, err := h.adminService.TransferClient(c.Request.Context(), userID, clientProfileID, input)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetUserActivity returns a user's recent API requests for support debugging. Only admins may call it.
+func (h *AdminHandler) GetUserActivity(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	targetUserID, valid := parseUintPathParam(c.Param("id"))
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	limit := parseQueryInt(c.DefaultQuery("limit", "100"), 100)
+
+	events, err := h.adminService.GetUserActivity(c.Request.Context(), userID, targetUserID, limit)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": events})
+}
+
+// RecomputeCoachStats rebuilds a coach's counters from source data and reports which had drifted.
+// Only admins may call it.
+func (h *AdminHandler) RecomputeCoachStats(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	coachID, valid := parseUintPathParam(c.Param("id"))
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid coach id"})
+		return
+	}
+
+	result, err := h.adminService.RecomputeCoachStats(c.Request.Context(), userID, coachID)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// ListFeatureFlags returns every feature flag. Only admins may call it.
+func (h *AdminHandler) ListFeatureFlags(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	flags, err := h.adminService.ListFeatureFlags(c.Request.Context(), userID)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": flags})
+}
+
+// CreateFeatureFlag adds a feature flag. Only admins may call it.
+func (h *AdminHandler) CreateFeatureFlag(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var input services.CreateFeatureFlagInput
+	if err := bindJSON(c, &input); err != nil {
+		errmap.RespondBindError(c, err)
+		return
+	}
+
+	flag, err := h.adminService.CreateFeatureFlag(c.Request.Context(), userID, input)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, flag)
+}
+
+// UpdateFeatureFlag changes a flag's switch, description or rules. Only admins may call it.
+func (h *AdminHandler) UpdateFeatureFlag(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	flagID, valid := parseUintPathParam(c.Param("id"))
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid feature flag id"})
+		return
+	}
+
+	var input services.UpdateFeatureFlagInput
+	if err := bindJSON(c, &input); err != nil {
+		errmap.RespondBindError(c, err)
+		return
+	}
+
+	flag, err := h.adminService.UpdateFeatureFlag(c.Request.Context(), userID, flagID, input)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, flag)
+}
+
+// DeleteFeatureFlag removes a feature flag. Only admins may call it.
+func (h *AdminHandler) DeleteFeatureFlag(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	flagID, valid := parseUintPathParam(c.Param("id"))
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid feature flag id"})
+		return
+	}
+
+	if err := h.adminService.DeleteFeatureFlag(c.Request.Context(), userID, flagID); err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "feature flag deleted"})
+}
+
+// ListWebhookInbox lists stored webhook deliveries, filterable by provider and status. Only admins
+// may call it.
+func (h *AdminHandler) ListWebhookInbox(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	limit := parseQueryInt(c.DefaultQuery("limit", "50"), 50)
+	offset := parseQueryInt(c.DefaultQuery("offset", "0"), 0)
+
+	entries, total, err := h.adminService.ListWebhookInbox(c.Request.Context(), userID, c.Query("provider"), c.Query("status"), limit, offset)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":   entries,
+		"total":  total,
+		"limit":  limit,
+		"offset": offset,
+	})
+}
+
+// ReprocessWebhook replays a stored webhook delivery and returns the entry with the outcome. Only
+// admins may call it.
+func (h *AdminHandler) ReprocessWebhook(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	entryID, valid := parseUintPathParam(c.Param("id"))
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid webhook id"})
+		return
+	}
+
+	entry, err := h.adminService.ReprocessWebhook(c.Request.Context(), userID, entryID)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, entry)
+}
+
+// ListReferrals lists completed coach referrals, optionally for one referring coach. Only admins may
+// call it.
+func (h *AdminHandler) ListReferrals(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var referrerCoachID uint
+	if raw := c.Query("referrer_coach_id"); raw != "" {
+		id, valid := parseUintPathParam(raw)
+		if !valid {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid referrer coach id"})
+			return
+		}
+		referrerCoachID = id
+	}
+	limit := parseQueryInt(c.DefaultQuery("limit", "50"), 50)
+	offset := parseQueryInt(c.DefaultQuery("offset", "0"), 0)
+
+	referrals, total, err := h.adminService.ListReferrals(c.Request.Context(), userID, referrerCoachID, limit, offset)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":   referrals,
+		"total":  total,
+		"limit":  limit,
+		"offset": offset,
+	})
+}
+
+// GetCacheStats reports Redis usage per keyspace with hit/miss counters. Only admins may call it.
+func (h *AdminHandler) GetCacheStats(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	stats, err := h.adminService.GetCacheStats(c.Request.Context(), userID)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+// GetPushStats reports device token counts and 7-day push delivery rates. Only admins may call it.
+func (h *AdminHandler) GetPushStats(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	stats, err := h.adminService.GetPushStats(c.Request.Context(), userID)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+// BulkUpdateExercises edits tags, difficulty and muscle groups across system exercises. A dry run
+// previews the change and returns the token the real run needs. Only admins may call it.
+func (h *AdminHandler) BulkUpdateExercises(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var input services.BulkUpdateExercisesInput
+	if err := bindJSON(c, &input); err != nil {
+		errmap.RespondBindError(c, err)
+		return
+	}
+
+	result, err := h.adminService.BulkUpdateExercises(c.Request.Context(), userID, input)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
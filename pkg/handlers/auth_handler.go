@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"chalk-api/pkg/handlers/errmap"
 	"chalk-api/pkg/services"
 	"chalk-api/pkg/utils"
 	"errors"
@@ -10,6 +11,9 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// deviceIDHeader carries the app's per-install ID when the request body doesn't
+const deviceIDHeader = "X-Device-ID"
+
 type AuthHandler struct {
 	authService *services.AuthService
 }
@@ -20,10 +24,13 @@ func NewAuthHandler(authService *services.AuthService) *AuthHandler {
 
 func (h *AuthHandler) Register(c *gin.Context) {
 	var input services.RegisterInput
-	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+	if err := bindJSON(c, &input); err != nil {
+		errmap.RespondBindError(c, err)
 		return
 	}
+	if input.DeviceID == "" {
+		input.DeviceID = c.GetHeader(deviceIDHeader)
+	}
 
 	result, err := h.authService.Register(c.Request.Context(), input, c.GetHeader("User-Agent"), c.ClientIP())
 	if err != nil {
@@ -41,10 +48,13 @@ func (h *AuthHandler) Register(c *gin.Context) {
 
 func (h *AuthHandler) Login(c *gin.Context) {
 	var input services.LoginInput
-	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+	if err := bindJSON(c, &input); err != nil {
+		errmap.RespondBindError(c, err)
 		return
 	}
+	if input.DeviceID == "" {
+		input.DeviceID = c.GetHeader(deviceIDHeader)
+	}
 
 	result, err := h.authService.Login(c.Request.Context(), input, c.GetHeader("User-Agent"), c.ClientIP())
 	if err != nil {
@@ -64,16 +74,21 @@ func (h *AuthHandler) Login(c *gin.Context) {
 
 func (h *AuthHandler) Refresh(c *gin.Context) {
 	var input services.RefreshInput
-	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+	if err := bindJSON(c, &input); err != nil {
+		errmap.RespondBindError(c, err)
 		return
 	}
+	if input.DeviceID == "" {
+		input.DeviceID = c.GetHeader(deviceIDHeader)
+	}
 
 	result, err := h.authService.Refresh(c.Request.Context(), input, c.GetHeader("User-Agent"), c.ClientIP())
 	if err != nil {
 		switch {
 		case errors.Is(err, services.ErrInvalidRefresh):
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid refresh token"})
+		case errors.Is(err, services.ErrRefreshExpired):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "session expired, log in again", "code": "refresh_session_expired"})
 		case errors.Is(err, services.ErrUserDisabled):
 			c.JSON(http.StatusForbidden, gin.H{"error": "account is disabled"})
 		default:
@@ -94,8 +109,8 @@ func (h *AuthHandler) Logout(c *gin.Context) {
 
 	var input services.LogoutInput
 	// Allow empty JSON body for default logout behavior.
-	if err := c.ShouldBindJSON(&input); err != nil && !errors.Is(err, io.EOF) {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+	if err := bindJSON(c, &input); err != nil && !errors.Is(err, io.EOF) {
+		errmap.RespondBindError(c, err)
 		return
 	}
 
@@ -111,3 +126,20 @@ func (h *AuthHandler) Logout(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"message": "logged out"})
 }
+
+// ListSessions returns the caller's signed-in devices.
+func (h *AuthHandler) ListSessions(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	sessions, err := h.authService.ListSessions(c.Request.Context(), userID)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": sessions})
+}
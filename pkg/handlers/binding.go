@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// errTrailingJSON is returned by bindJSON when the body holds more than one JSON value.
+var errTrailingJSON = errors.New("request body must contain a single JSON value")
+
+// bindJSON is a strict ShouldBindJSON: unknown fields and anything after the JSON value are rejected
+// instead of silently ignored, so a typo like "duration_minuts" fails loudly. An empty body returns io.EOF unvalidated, matching gin,
+// so handlers with optional bodies keep their errors.Is(err, io.EOF) checks.
+func bindJSON(c *gin.Context, obj any) error {
+	if c.Request.Body == nil {
+		return io.EOF
+	}
+
+	decoder := json.NewDecoder(c.Request.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(obj); err != nil {
+		return err
+	}
+	if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return err
+		}
+		return errTrailingJSON
+	}
+
+	return binding.Validator.ValidateStruct(obj)
+}
+
+// ifMatchVersion reads the record version from an If-Match header, as a bare number or an entity tag
+// like "3" or W/"3". ok is false when the header is set but isn't a version; a missing header is fine.
+func ifMatchVersion(c *gin.Context) (*int, bool) {
+	raw := strings.TrimSpace(c.GetHeader("If-Match"))
+	if raw == "" {
+		return nil, true
+	}
+
+	version, err := strconv.Atoi(strings.Trim(strings.TrimPrefix(raw, "W/"), `"`))
+	if err != nil || version < 1 {
+		return nil, false
+	}
+	return &version, true
+}
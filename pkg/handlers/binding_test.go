@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"chalk-api/pkg/handlers/errmap"
+	"chalk-api/pkg/middleware"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+type bindTestInput struct {
+	Name  string `json:"name" binding:"required"`
+	Count int    `json:"count"`
+}
+
+// bindTestRouter serves POST /bind, which binds bindTestInput the way the handlers do, behind
+// the body size limit.
+func bindTestRouter(maxBytes int64) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.BodySizeLimit(maxBytes, nil))
+	router.POST("/bind", func(c *gin.Context) {
+		var input bindTestInput
+		if err := bindJSON(c, &input); err != nil {
+			errmap.RespondBindError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, input)
+	})
+	return router
+}
+
+type bindTestResponse struct {
+	Code    string              `json:"code"`
+	Details []errmap.FieldError `json:"details"`
+}
+
+func postBind(t *testing.T, router *gin.Engine, body io.Reader) (*httptest.ResponseRecorder, bindTestResponse) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/bind", body)
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	var resp bindTestResponse
+	if rec.Code != http.StatusOK {
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("decode %d response %q: %v", rec.Code, rec.Body.String(), err)
+		}
+	}
+	return rec, resp
+}
+
+func TestBindJSONAcceptsASingleKnownObject(t *testing.T) {
+	rec, _ := postBind(t, bindTestRouter(1024), strings.NewReader(`{"name":"squat","count":3}`+"\n"))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestBindJSONRejectsUnknownFields(t *testing.T) {
+	rec, resp := postBind(t, bindTestRouter(1024), strings.NewReader(`{"name":"squat","cuont":3}`))
+	if rec.Code != http.StatusBadRequest || resp.Code != "validation_failed" {
+		t.Fatalf("status = %d code = %q, want 400 validation_failed", rec.Code, resp.Code)
+	}
+	if len(resp.Details) != 1 || resp.Details[0].Field != "cuont" || resp.Details[0].Rule != "unknown" {
+		t.Fatalf("details = %+v, want cuont flagged as unknown", resp.Details)
+	}
+}
+
+func TestBindJSONRejectsTrailingData(t *testing.T) {
+	for name, body := range map[string]string{
+		"second object":  `{"name":"squat"}{"name":"lunge"}`,
+		"stray brace":    `{"name":"squat"}}`,
+		"trailing junk":  `{"name":"squat"} garbage`,
+		"trailing value": `{"name":"squat"} 1`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			rec, resp := postBind(t, bindTestRouter(1024), strings.NewReader(body))
+			if rec.Code != http.StatusBadRequest || resp.Code != "invalid_request_body" {
+				t.Fatalf("status = %d code = %q, want 400 invalid_request_body", rec.Code, resp.Code)
+			}
+		})
+	}
+}
+
+func TestBindJSONRejectsOversizedPayloads(t *testing.T) {
+	oversized := `{"name":"` + strings.Repeat("a", 2048) + `"}`
+
+	// A declared Content-Length is refused before the handler runs.
+	rec, resp := postBind(t, bindTestRouter(1024), strings.NewReader(oversized))
+	if rec.Code != http.StatusRequestEntityTooLarge || resp.Code != "request_body_too_large" {
+		t.Fatalf("declared length: status = %d code = %q, want 413 request_body_too_large", rec.Code, resp.Code)
+	}
+
+	// A chunked body is cut off while binding.
+	rec, resp = postBind(t, bindTestRouter(1024), io.MultiReader(strings.NewReader(oversized)))
+	if rec.Code != http.StatusRequestEntityTooLarge || resp.Code != "request_body_too_large" {
+		t.Fatalf("chunked: status = %d code = %q, want 413 request_body_too_large", rec.Code, resp.Code)
+	}
+}
+
+func TestBindJSONReturnsEOFForAnEmptyBody(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/bind", http.NoBody)
+
+	var input bindTestInput
+	if err := bindJSON(c, &input); !errors.Is(err, io.EOF) {
+		t.Fatalf("err = %v, want io.EOF", err)
+	}
+}
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"chalk-api/pkg/handlers/errmap"
+	"chalk-api/pkg/services"
+	"chalk-api/pkg/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// appVersionHeader carries the installed app version, compared against MIN_APP_VERSION and LATEST_APP_VERSION
+const appVersionHeader = "X-App-Version"
+
+type BootstrapHandler struct {
+	bootstrapService *services.BootstrapService
+}
+
+func NewBootstrapHandler(bootstrapService *services.BootstrapService) *BootstrapHandler {
+	return &BootstrapHandler{bootstrapService: bootstrapService}
+}
+
+// GetBootstrap returns the app's startup payload. The app reports its version in the X-App-Version
+// header (or ?app_version=) to learn whether it must upgrade.
+func (h *BootstrapHandler) GetBootstrap(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	appVersion := c.GetHeader(appVersionHeader)
+	if appVersion == "" {
+		appVersion = c.Query("app_version")
+	}
+
+	bootstrap, err := h.bootstrapService.GetBootstrap(c.Request.Context(), userID, appVersion)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, bootstrap)
+}
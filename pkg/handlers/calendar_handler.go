@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"chalk-api/pkg/calendar"
+	"chalk-api/pkg/handlers/errmap"
+	"chalk-api/pkg/services"
+	"chalk-api/pkg/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CalendarHandler struct {
+	calendarService *services.CalendarService
+}
+
+func NewCalendarHandler(calendarService *services.CalendarService) *CalendarHandler {
+	return &CalendarHandler{calendarService: calendarService}
+}
+
+func (h *CalendarHandler) GetClientCalendar(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	calendar, err := h.calendarService.GetClientCalendar(c.Request.Context(), userID, c.Query("start"), c.Query("end"))
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, calendar)
+}
+
+func (h *CalendarHandler) GetCoachCalendar(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	calendar, err := h.calendarService.GetCoachCalendar(c.Request.Context(), userID, c.Query("start"), c.Query("end"))
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, calendar)
+}
+
+// GetCoachSchedule returns the coach's week planner for ?week=2026-W12 or ?week=YYYY-MM-DD (the week
+// containing that date), defaulting to the current week.
+func (h *CalendarHandler) GetCoachSchedule(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	week := c.Query("week")
+	if week == "" {
+		week = c.Query("start")
+	}
+	schedule, err := h.calendarService.GetCoachSchedule(c.Request.Context(), userID, week)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, schedule)
+}
+
+// ExportCoachCalendar returns the coach's sessions and blocked time as an .ics file, using the same
+// start/end range as the calendar feed.
+func (h *CalendarHandler) ExportCoachCalendar(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	export, err := h.calendarService.ExportCoachCalendar(c.Request.Context(), userID, c.Query("start"), c.Query("end"))
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="`+export.Filename+`"`)
+	c.Header("Cache-Control", "no-store")
+	c.Data(http.StatusOK, calendar.ContentType, []byte(export.Body))
+}
@@ -1,15 +1,22 @@
 package handlers
 
 import (
+	"chalk-api/pkg/handlers/errmap"
 	"chalk-api/pkg/services"
 	"chalk-api/pkg/utils"
 	"errors"
+	"io"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
 
+// MaxClientImportRequestBytes caps a client import request: the CSV limit plus headroom for
+// multipart framing, so a file right at the limit is still accepted.
+const MaxClientImportRequestBytes int64 = services.MaxClientImportBytes + 1<<20
+
 type CoachHandler struct {
 	coachService *services.CoachService
 }
@@ -27,12 +34,7 @@ func (h *CoachHandler) GetMyProfile(c *gin.Context) {
 
 	profile, err := h.coachService.GetMyProfile(c.Request.Context(), userID)
 	if err != nil {
-		switch {
-		case errors.Is(err, services.ErrCoachProfileNotFound):
-			c.JSON(http.StatusNotFound, gin.H{"error": "coach profile not found"})
-		default:
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch coach profile"})
-		}
+		errmap.RespondError(c, err)
 		return
 	}
 
@@ -47,20 +49,528 @@ func (h *CoachHandler) UpsertMyProfile(c *gin.Context) {
 	}
 
 	var input services.UpsertCoachProfileInput
-	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+	if err := bindJSON(c, &input); err != nil {
+		errmap.RespondBindError(c, err)
 		return
 	}
 
+	if input.Version == nil {
+		version, ok := ifMatchVersion(c)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "If-Match must be a version number"})
+			return
+		}
+		input.Version = version
+	}
+
 	profile, err := h.coachService.UpsertMyProfile(c.Request.Context(), userID, input)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save coach profile"})
+		errmap.RespondError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, profile)
 }
 
+// GetMyOnboarding returns the coach's onboarding checklist with each step's completion and deep link.
+func (h *CoachHandler) GetMyOnboarding(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	progress, err := h.coachService.GetMyOnboarding(c.Request.Context(), userID)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, progress)
+}
+
+func (h *CoachHandler) GetPublicProfile(c *gin.Context) {
+	coachID, ok := parseUintPathParam(c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid coach id"})
+		return
+	}
+
+	profile, err := h.coachService.GetPublicProfile(c.Request.Context(), coachID)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	viewerID, _ := utils.GetUserIDFromContext(c)
+	h.coachService.RecordPublicProfileView(profile, viewerID, c.ClientIP())
+	c.JSON(http.StatusOK, profile)
+}
+
+// GetProfileViews returns the caller's daily public profile views for the last 30 days.
+func (h *CoachHandler) GetProfileViews(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	series, err := h.coachService.GetProfileViewSeries(c.Request.Context(), userID)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, series)
+}
+
+func (h *CoachHandler) CreateCoverPhotoUpload(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var input services.CoverPhotoUploadInput
+	if err := bindJSON(c, &input); err != nil {
+		errmap.RespondBindError(c, err)
+		return
+	}
+
+	upload, err := h.coachService.CreateCoverPhotoUpload(c.Request.Context(), userID, input)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, upload)
+}
+
+func (h *CoachHandler) SetCoverPhoto(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var input services.SetCoverPhotoInput
+	if err := bindJSON(c, &input); err != nil {
+		errmap.RespondBindError(c, err)
+		return
+	}
+
+	profile, err := h.coachService.SetCoverPhoto(c.Request.Context(), userID, input)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+// ListMyClients returns the coach's clients. Query: status, at_risk=true|false,
+// tags=a,b (repeatable) with tag_match=any|all, sort=created_at|last_activity_at, limit, offset.
+func (h *CoachHandler) ListMyClients(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	input := services.ClientListInput{
+		Status:   c.Query("status"),
+		Sort:     c.Query("sort"),
+		Limit:    parseQueryInt(c.DefaultQuery("limit", "20"), 20),
+		Offset:   parseQueryInt(c.DefaultQuery("offset", "0"), 0),
+		TagMatch: c.Query("tag_match"),
+	}
+	for _, raw := range c.QueryArray("tags") {
+		input.Tags = append(input.Tags, strings.Split(raw, ",")...)
+	}
+	switch c.Query("at_risk") {
+	case "":
+	case "true", "false":
+		atRisk := c.Query("at_risk") == "true"
+		input.AtRisk = &atRisk
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "at_risk must be true or false"})
+		return
+	}
+
+	clients, total, err := h.coachService.ListMyClients(c.Request.Context(), userID, input)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":   clients,
+		"total":  total,
+		"limit":  input.Limit,
+		"offset": input.Offset,
+	})
+}
+
+// GetMyClient returns a client's profile with their active goals and latest goal progress.
+func (h *CoachHandler) GetMyClient(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	clientProfileID, valid := parseUintParam(c.Param("id"))
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid client id"})
+		return
+	}
+
+	client, err := h.coachService.GetMyClient(c.Request.Context(), userID, clientProfileID)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, client)
+}
+
+// UpdateMyClient changes coach-controlled client settings such as require_completion_note.
+func (h *CoachHandler) UpdateMyClient(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	clientProfileID, valid := parseUintParam(c.Param("id"))
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid client id"})
+		return
+	}
+
+	var input services.UpdateClientSettingsInput
+	if err := bindJSON(c, &input); err != nil {
+		errmap.RespondBindError(c, err)
+		return
+	}
+
+	client, err := h.coachService.UpdateMyClient(c.Request.Context(), userID, clientProfileID, input)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, client)
+}
+
+// SetMyClientTags replaces the tags on one of the coach's clients.
+func (h *CoachHandler) SetMyClientTags(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	clientProfileID, valid := parseUintParam(c.Param("id"))
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid client id"})
+		return
+	}
+
+	var input services.SetClientTagsInput
+	if err := bindJSON(c, &input); err != nil {
+		errmap.RespondBindError(c, err)
+		return
+	}
+
+	client, err := h.coachService.SetMyClientTags(c.Request.Context(), userID, clientProfileID, input)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, client)
+}
+
+// CreateClientNote adds a private note to a client's timeline.
+func (h *CoachHandler) CreateClientNote(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	clientProfileID, valid := parseUintParam(c.Param("id"))
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid client id"})
+		return
+	}
+
+	var input services.CreateClientNoteInput
+	if err := bindJSON(c, &input); err != nil {
+		errmap.RespondBindError(c, err)
+		return
+	}
+
+	note, err := h.coachService.CreateClientNote(c.Request.Context(), userID, clientProfileID, input)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, note)
+}
+
+// ListClientNotes returns a client's notes, pinned first then newest first. Query: q, limit, offset.
+func (h *CoachHandler) ListClientNotes(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	clientProfileID, valid := parseUintParam(c.Param("id"))
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid client id"})
+		return
+	}
+
+	input := clientNoteListInput(c)
+	notes, total, err := h.coachService.ListClientNotes(c.Request.Context(), userID, clientProfileID, input)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":   notes,
+		"total":  total,
+		"limit":  input.Limit,
+		"offset": input.Offset,
+	})
+}
+
+// SearchClientNotes searches notes across all of the coach's clients. Query: q, limit, offset.
+func (h *CoachHandler) SearchClientNotes(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	input := clientNoteListInput(c)
+	notes, total, err := h.coachService.SearchMyClientNotes(c.Request.Context(), userID, input)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":   notes,
+		"total":  total,
+		"limit":  input.Limit,
+		"offset": input.Offset,
+	})
+}
+
+func clientNoteListInput(c *gin.Context) services.ClientNoteListInput {
+	return services.ClientNoteListInput{
+		Query:  c.Query("q"),
+		Limit:  parseQueryInt(c.DefaultQuery("limit", "20"), 20),
+		Offset: parseQueryInt(c.DefaultQuery("offset", "0"), 0),
+	}
+}
+
+// UpdateClientNote edits a note's body or pins and unpins it.
+func (h *CoachHandler) UpdateClientNote(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	clientProfileID, valid := parseUintParam(c.Param("id"))
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid client id"})
+		return
+	}
+	noteID, valid := parseUintParam(c.Param("note_id"))
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid note id"})
+		return
+	}
+
+	var input services.UpdateClientNoteInput
+	if err := bindJSON(c, &input); err != nil {
+		errmap.RespondBindError(c, err)
+		return
+	}
+
+	note, err := h.coachService.UpdateClientNote(c.Request.Context(), userID, clientProfileID, noteID, input)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, note)
+}
+
+func (h *CoachHandler) DeleteClientNote(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	clientProfileID, valid := parseUintParam(c.Param("id"))
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid client id"})
+		return
+	}
+	noteID, valid := parseUintParam(c.Param("note_id"))
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid note id"})
+		return
+	}
+
+	if err := h.coachService.DeleteClientNote(c.Request.Context(), userID, clientProfileID, noteID); err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "note deleted"})
+}
+
+// ImportClients invites clients from a CSV, sent either as a multipart "file" or as a raw text/csv
+// body. Small files are imported right away (200); larger ones are queued (202) and can be followed
+// with GetClientImport.
+func (h *CoachHandler) ImportClients(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, MaxClientImportRequestBytes)
+
+	var reader io.Reader
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				errmap.RespondError(c, services.ErrUploadTooLarge)
+				return
+			}
+			c.JSON(http.StatusBadRequest, gin.H{"error": "csv file is required"})
+			return
+		}
+		if fileHeader.Size > services.MaxClientImportBytes {
+			errmap.RespondError(c, services.ErrUploadTooLarge)
+			return
+		}
+		file, err := fileHeader.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "csv file is unreadable"})
+			return
+		}
+		defer file.Close()
+		reader = file
+	} else {
+		reader = c.Request.Body
+	}
+
+	data, err := io.ReadAll(io.LimitReader(reader, services.MaxClientImportBytes+1))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			errmap.RespondError(c, services.ErrUploadTooLarge)
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "csv file is unreadable"})
+		return
+	}
+	if len(data) > services.MaxClientImportBytes {
+		errmap.RespondError(c, services.ErrUploadTooLarge)
+		return
+	}
+
+	clientImport, err := h.coachService.ImportClients(c.Request.Context(), userID, data)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	status := http.StatusOK
+	if clientImport.CompletedAt == nil {
+		status = http.StatusAccepted
+	}
+	c.JSON(status, clientImport)
+}
+
+// GetClientImport reports an import's status, counts and per-row results.
+func (h *CoachHandler) GetClientImport(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	importID, valid := parseUintParam(c.Param("id"))
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid import id"})
+		return
+	}
+
+	clientImport, err := h.coachService.GetClientImport(c.Request.Context(), userID, importID)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, clientImport)
+}
+
+// ListClientTags returns the tags used across the coach's clients with usage counts.
+func (h *CoachHandler) ListClientTags(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	tags, err := h.coachService.ListMyClientTags(c.Request.Context(), userID)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": tags})
+}
+
+// RenameClientTag renames a tag across all of the coach's clients.
+func (h *CoachHandler) RenameClientTag(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var input services.RenameClientTagInput
+	if err := bindJSON(c, &input); err != nil {
+		errmap.RespondBindError(c, err)
+		return
+	}
+
+	updated, err := h.coachService.RenameMyClientTag(c.Request.Context(), userID, input)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"updated_clients": updated})
+}
+
 func (h *CoachHandler) CreateInviteCode(c *gin.Context) {
 	userID, ok := utils.GetUserIDFromContext(c)
 	if !ok {
@@ -69,19 +579,15 @@ func (h *CoachHandler) CreateInviteCode(c *gin.Context) {
 	}
 
 	var input services.CreateInviteCodeInput
-	if err := c.ShouldBindJSON(&input); err != nil {
-		// Allow empty body and defaults.
-		input = services.CreateInviteCodeInput{}
+	// An empty body creates a plain invite with the default expiry.
+	if err := bindJSON(c, &input); err != nil && !errors.Is(err, io.EOF) {
+		errmap.RespondBindError(c, err)
+		return
 	}
 
 	invite, err := h.coachService.CreateInviteCode(c.Request.Context(), userID, input)
 	if err != nil {
-		switch {
-		case errors.Is(err, services.ErrCoachProfileNotFound):
-			c.JSON(http.StatusNotFound, gin.H{"error": "coach profile not found"})
-		default:
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create invite code"})
-		}
+		errmap.RespondError(c, err)
 		return
 	}
 
@@ -97,12 +603,7 @@ func (h *CoachHandler) ListInviteCodes(c *gin.Context) {
 
 	invites, err := h.coachService.ListInviteCodes(c.Request.Context(), userID)
 	if err != nil {
-		switch {
-		case errors.Is(err, services.ErrCoachProfileNotFound):
-			c.JSON(http.StatusNotFound, gin.H{"error": "coach profile not found"})
-		default:
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list invite codes"})
-		}
+		errmap.RespondError(c, err)
 		return
 	}
 
@@ -123,18 +624,243 @@ func (h *CoachHandler) DeactivateInviteCode(c *gin.Context) {
 	}
 
 	if err := h.coachService.DeactivateInviteCode(c.Request.Context(), userID, uint(inviteID)); err != nil {
-		switch {
-		case errors.Is(err, services.ErrCoachProfileNotFound):
-			c.JSON(http.StatusNotFound, gin.H{"error": "coach profile not found"})
-		case errors.Is(err, services.ErrInviteCodeNotFound):
-			c.JSON(http.StatusNotFound, gin.H{"error": "invite code not found"})
-		case errors.Is(err, services.ErrInviteForbidden):
-			c.JSON(http.StatusForbidden, gin.H{"error": "invite code does not belong to this coach"})
-		default:
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to deactivate invite code"})
-		}
+		errmap.RespondError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "invite code deactivated"})
 }
+
+func (h *CoachHandler) CreateConnectionRequest(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	coachID, valid := parseUintPathParam(c.Param("id"))
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid coach id"})
+		return
+	}
+
+	var input services.CreateConnectionRequestInput
+	if err := bindJSON(c, &input); err != nil {
+		errmap.RespondBindError(c, err)
+		return
+	}
+
+	request, err := h.coachService.CreateConnectionRequest(c.Request.Context(), userID, coachID, input)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, request)
+}
+
+func (h *CoachHandler) ListConnectionRequests(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	requests, err := h.coachService.ListMyConnectionRequests(c.Request.Context(), userID, c.Query("status"))
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": requests})
+}
+
+func (h *CoachHandler) ApproveConnectionRequest(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	requestID, valid := parseUintPathParam(c.Param("id"))
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid connection request id"})
+		return
+	}
+
+	result, err := h.coachService.ApproveConnectionRequest(c.Request.Context(), userID, requestID)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *CoachHandler) DeclineConnectionRequest(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	requestID, valid := parseUintPathParam(c.Param("id"))
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid connection request id"})
+		return
+	}
+
+	if err := h.coachService.DeclineConnectionRequest(c.Request.Context(), userID, requestID); err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "connection request declined"})
+}
+
+func (h *CoachHandler) GetActingCoach(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	acting, err := h.coachService.GetActingCoach(c.Request.Context(), userID)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, acting)
+}
+
+func (h *CoachHandler) InviteTeamMember(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var input services.InviteTeamMemberInput
+	if err := bindJSON(c, &input); err != nil {
+		errmap.RespondBindError(c, err)
+		return
+	}
+
+	member, err := h.coachService.InviteTeamMember(c.Request.Context(), userID, input)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, member)
+}
+
+func (h *CoachHandler) ListTeamMembers(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	members, err := h.coachService.ListTeamMembers(c.Request.Context(), userID)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": members})
+}
+
+// GetMyReferral returns the coach's referral code with their completed referrals and credit balance.
+func (h *CoachHandler) GetMyReferral(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	summary, err := h.coachService.GetMyReferral(c.Request.Context(), userID)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
+
+// GetMyReferralCredits returns the coach's unconsumed credit balance and credit ledger.
+func (h *CoachHandler) GetMyReferralCredits(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	balance, err := h.coachService.GetMyReferralCredits(c.Request.Context(), userID)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, balance)
+}
+
+func (h *CoachHandler) RemoveTeamMember(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	memberID, valid := parseUintPathParam(c.Param("id"))
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid team member id"})
+		return
+	}
+
+	if err := h.coachService.RemoveTeamMember(c.Request.Context(), userID, memberID); err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "team member removed"})
+}
+
+func (h *CoachHandler) ListMyTeamInvitations(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	invitations, err := h.coachService.ListMyTeamInvitations(c.Request.Context(), userID)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": invitations})
+}
+
+func (h *CoachHandler) AcceptTeamInvitation(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	invitationID, valid := parseUintPathParam(c.Param("id"))
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid team invitation id"})
+		return
+	}
+
+	member, err := h.coachService.AcceptTeamInvitation(c.Request.Context(), userID, invitationID)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, member)
+}
@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"chalk-api/pkg/handlers/errmap"
+	"chalk-api/pkg/services"
+	"chalk-api/pkg/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type DigestHandler struct {
+	digestService *services.DigestService
+}
+
+func NewDigestHandler(digestService *services.DigestService) *DigestHandler {
+	return &DigestHandler{digestService: digestService}
+}
+
+// GetLatestDigest returns the coach's most recent weekly digest notification; the summary is in data.
+func (h *DigestHandler) GetLatestDigest(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	digest, err := h.digestService.GetLatestDigest(c.Request.Context(), userID)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, digest)
+}
@@ -0,0 +1,312 @@
+package errmap
+
+import (
+	"chalk-api/pkg/i18n"
+	"chalk-api/pkg/middleware"
+	"chalk-api/pkg/services"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Entry describes how a service error is presented to API clients.
+// Code is a stable machine-readable identifier; Message is safe to show to users.
+type Entry struct {
+	Status  int
+	Code    string
+	Message string
+}
+
+type registration struct {
+	err   error
+	entry Entry
+}
+
+// registry is the single source of truth for translating service sentinel errors to HTTP responses.
+// Add every new services.Err* here so handlers never need their own switch statements.
+var registry = []registration{
+	// Shared / profiles
+	{services.ErrUserNotFound, Entry{http.StatusNotFound, "user_not_found", "user not found"}},
+	{services.ErrCoachProfileNotFound, Entry{http.StatusNotFound, "coach_profile_not_found", "coach profile not found"}},
+	{services.ErrClientProfileNotFound, Entry{http.StatusNotFound, "client_profile_not_found", "client profile not found"}},
+	{services.ErrClientProfileForbidden, Entry{http.StatusForbidden, "client_profile_forbidden", "client profile does not belong to this coach"}},
+	{services.ErrClientProfileRequired, Entry{http.StatusBadRequest, "client_profile_required", "client_profile_id is required"}},
+	{services.ErrClientProfileInvalid, Entry{http.StatusForbidden, "client_profile_invalid", "client profile does not belong to this user"}},
+	{services.ErrInvalidTimezone, Entry{http.StatusBadRequest, "invalid_timezone", "timezone must be a valid IANA name (e.g. America/New_York)"}},
+	{services.ErrInvalidLocale, Entry{http.StatusBadRequest, "invalid_locale", "locale must be one of: en, es"}},
+	{services.ErrInvalidUnit, Entry{http.StatusBadRequest, "invalid_unit", "weight_unit must be kg or lbs and distance_unit km or mi"}},
+	{services.ErrInvalidReminderHour, Entry{http.StatusBadRequest, "invalid_reminder_hour", "reminder hours must be 0-23 and the evening reminder must come after the morning one"}},
+	{services.ErrProfileNameRequired, Entry{http.StatusBadRequest, "profile_name_required", "first_name and last_name cannot be empty"}},
+	{services.ErrInvalidBrandColor, Entry{http.StatusBadRequest, "invalid_brand_color", "brand_color must be a hex color like #1A2B3C"}},
+	{services.ErrInvalidCoachSlug, Entry{http.StatusBadRequest, "invalid_coach_slug", "slug must be 3-50 lowercase letters, digits or dashes"}},
+	{services.ErrCoachSlugTaken, Entry{http.StatusConflict, "coach_slug_taken", "this slug is already taken"}},
+	{services.ErrVersionConflict, Entry{http.StatusConflict, "version_conflict", "this was changed by someone else; reload and try again"}},
+	{services.ErrInvalidClientFilter, Entry{http.StatusBadRequest, "invalid_client_filter", "status must be active, paused or archived, sort must be created_at or last_activity_at and tag_match must be any or all"}},
+	{services.ErrInvalidClientTag, Entry{http.StatusBadRequest, "invalid_client_tag", "tags must be 1-30 characters"}},
+	{services.ErrTooManyClientTags, Entry{http.StatusBadRequest, "too_many_client_tags", "a client can have at most 20 tags"}},
+	{services.ErrClientNoteInvalid, Entry{http.StatusBadRequest, "client_note_invalid", "note body is required"}},
+	{services.ErrClientNoteNotFound, Entry{http.StatusNotFound, "client_note_not_found", "client note not found"}},
+	{services.ErrInvalidClientImportFile, Entry{http.StatusBadRequest, "invalid_client_import_file", "import must be a CSV file with email, first_name and last_name columns"}},
+	{services.ErrClientImportEmpty, Entry{http.StatusBadRequest, "client_import_empty", "the import file has no client rows"}},
+	{services.ErrClientImportTooLarge, Entry{http.StatusBadRequest, "client_import_too_large", "an import can have at most 500 rows"}},
+	{services.ErrClientImportNotFound, Entry{http.StatusNotFound, "client_import_not_found", "client import not found"}},
+
+	// Goals
+	{services.ErrGoalNotFound, Entry{http.StatusNotFound, "goal_not_found", "goal not found"}},
+	{services.ErrGoalForbidden, Entry{http.StatusForbidden, "goal_forbidden", "goal does not belong to this user"}},
+	{services.ErrGoalTitleRequired, Entry{http.StatusBadRequest, "goal_title_required", "title cannot be empty"}},
+	{services.ErrGoalAlreadyAchieved, Entry{http.StatusConflict, "goal_already_achieved", "an achieved goal can't change status"}},
+	{services.ErrGoalNotActive, Entry{http.StatusConflict, "goal_not_active", "progress can only be recorded on active goals"}},
+	{services.ErrInvalidGoalFilter, Entry{http.StatusBadRequest, "invalid_goal_filter", "status must be active, achieved or abandoned"}},
+
+	// Activity
+	{services.ErrInvalidActivitySamples, Entry{http.StatusBadRequest, "invalid_activity_samples", "samples need a known unit, must not end in the future and must not overlap others of the same type and source"}},
+
+	// Intake forms
+	{services.ErrIntakeQuestionNotFound, Entry{http.StatusNotFound, "intake_question_not_found", "intake question not found"}},
+	{services.ErrIntakeQuestionForbidden, Entry{http.StatusForbidden, "intake_question_forbidden", "intake question does not belong to this coach"}},
+	{services.ErrIntakeQuestionInvalid, Entry{http.StatusBadRequest, "intake_question_invalid", "label is required; select questions need at least 2 distinct options and other types take none"}},
+	{services.ErrIntakeQuestionLimit, Entry{http.StatusConflict, "intake_question_limit_reached", "coaches can have at most 50 custom intake questions"}},
+	{services.ErrInvalidIntakeAnswer, Entry{http.StatusBadRequest, "intake_answer_invalid", "an intake answer does not match its question"}},
+
+	// Uploads
+	{services.ErrUploadTooLarge, Entry{http.StatusRequestEntityTooLarge, "upload_too_large", "uploaded file is too large"}},
+	{services.ErrUploadContentType, Entry{http.StatusUnsupportedMediaType, "upload_content_type", "unsupported file type"}},
+	{services.ErrUploadNotFound, Entry{http.StatusNotFound, "upload_not_found", "uploaded file not found; request a new upload URL"}},
+	{services.ErrStorageUnavailable, Entry{http.StatusServiceUnavailable, "storage_unavailable", "file uploads are not available"}},
+
+	// Data exports
+	{services.ErrDataExportInProgress, Entry{http.StatusConflict, "data_export_in_progress", "a data export is already in progress"}},
+	{services.ErrDataExportNotFound, Entry{http.StatusNotFound, "data_export_not_found", "no data export has been requested"}},
+
+	// Notifications
+	{services.ErrNotificationNotFound, Entry{http.StatusNotFound, "notification_not_found", "notification not found"}},
+	{services.ErrInvalidDeviceToken, Entry{http.StatusBadRequest, "invalid_device_token", "token must be an Expo push token"}},
+
+	// Digests
+	{services.ErrDigestNotFound, Entry{http.StatusNotFound, "digest_not_found", "no weekly digest has been generated yet"}},
+
+	// Auth
+	{services.ErrInvalidCredentials, Entry{http.StatusUnauthorized, "invalid_credentials", "invalid email or password"}},
+	{services.ErrEmailAlreadyExists, Entry{http.StatusConflict, "email_already_exists", "email already exists"}},
+	{services.ErrUserDisabled, Entry{http.StatusForbidden, "user_disabled", "user account is inactive or banned"}},
+	{services.ErrInvalidRefresh, Entry{http.StatusUnauthorized, "invalid_refresh_token", "invalid or expired refresh token"}},
+	{services.ErrRefreshExpired, Entry{http.StatusUnauthorized, "refresh_session_expired", "session expired, log in again"}},
+	{services.ErrTokenKeysNotConfigured, Entry{http.StatusServiceUnavailable, "auth_unavailable", "sign-in is temporarily unavailable"}},
+
+	// Invites
+	{services.ErrInviteCodeNotFound, Entry{http.StatusNotFound, "invite_code_not_found", "invite code not found"}},
+	{services.ErrInviteForbidden, Entry{http.StatusForbidden, "invite_forbidden", "invite code does not belong to this coach"}},
+	{services.ErrInviteCodeExhausted, Entry{http.StatusConflict, "invite_code_exhausted", "this invite code has reached its maximum number of uses"}},
+	{services.ErrConnectionRequestSelf, Entry{http.StatusBadRequest, "connection_request_self", "you cannot request to connect with yourself"}},
+	{services.ErrInvalidConnectionFilter, Entry{http.StatusBadRequest, "invalid_connection_filter", "status must be pending, approved or declined"}},
+	{services.ErrCoachNotAcceptingClients, Entry{http.StatusConflict, "coach_not_accepting_clients", "this coach is not accepting new clients"}},
+	{services.ErrAlreadyClient, Entry{http.StatusConflict, "already_client", "you are already a client of this coach"}},
+	{services.ErrConnectionRequestExists, Entry{http.StatusConflict, "connection_request_exists", "you already have a pending request to this coach"}},
+	{services.ErrConnectionRequestCooldown, Entry{http.StatusConflict, "connection_request_cooldown", "your last request was declined; you can ask again 30 days after it was declined"}},
+	{services.ErrConnectionRequestNotFound, Entry{http.StatusNotFound, "connection_request_not_found", "connection request not found"}},
+	{services.ErrConnectionRequestForbidden, Entry{http.StatusForbidden, "connection_request_forbidden", "connection request does not belong to this coach"}},
+	{services.ErrConnectionRequestNotPending, Entry{http.StatusConflict, "connection_request_not_pending", "connection request has already been approved or declined"}},
+
+	// Coach teams
+	{services.ErrInvalidTeamEmail, Entry{http.StatusBadRequest, "invalid_team_email", "email must be a valid address"}},
+	{services.ErrTeamInviteSelf, Entry{http.StatusBadRequest, "team_invite_self", "you cannot invite yourself to your own team"}},
+	{services.ErrTeamInvitationExists, Entry{http.StatusConflict, "team_invitation_exists", "this email already has an open invitation or is on your team"}},
+	{services.ErrTeamInvitationNotFound, Entry{http.StatusNotFound, "team_invitation_not_found", "team invitation not found"}},
+	{services.ErrTeamInvitationNotPending, Entry{http.StatusConflict, "team_invitation_not_pending", "team invitation has already been accepted or withdrawn"}},
+	{services.ErrTeamEmailNotVerified, Entry{http.StatusForbidden, "team_email_not_verified", "verify your email before accepting a team invitation"}},
+	{services.ErrAlreadyOnCoachTeam, Entry{http.StatusConflict, "already_on_coach_team", "you already have a coach profile or work on another coach's team"}},
+	{services.ErrTeamMemberNotFound, Entry{http.StatusNotFound, "team_member_not_found", "team member not found"}},
+	{services.ErrCoachTeamMemberUnavailable, Entry{http.StatusConflict, "team_member_unavailable", "team member has already been removed"}},
+	{services.ErrCoachTeamOwnerOnly, Entry{http.StatusForbidden, "coach_team_owner_only", "only the coach profile owner can do this"}},
+	{services.ErrReferralCodeNotFound, Entry{http.StatusNotFound, "referral_code_not_found", "referral code not found"}},
+	{services.ErrSelfReferral, Entry{http.StatusBadRequest, "self_referral", "you cannot use your own referral code"}},
+	{services.ErrCircularReferral, Entry{http.StatusBadRequest, "circular_referral", "this coach was referred by you, directly or through others"}},
+	{services.ErrReferralLocked, Entry{http.StatusConflict, "referral_locked", "a referral code can only be added once, before onboarding is completed"}},
+
+	// Messaging
+	{services.ErrConversationNotFound, Entry{http.StatusNotFound, "conversation_not_found", "conversation not found"}},
+	{services.ErrConversationForbidden, Entry{http.StatusForbidden, "conversation_forbidden", "conversation does not belong to this user"}},
+	{services.ErrConversationClosed, Entry{http.StatusConflict, "conversation_closed", "this conversation is closed; the client has moved to another coach"}},
+	{services.ErrMessageContentRequired, Entry{http.StatusBadRequest, "message_content_required", "content or media_url is required"}},
+	{services.ErrMessageNotFound, Entry{http.StatusNotFound, "message_not_found", "message not found in this conversation"}},
+	{services.ErrSavedReplyInvalid, Entry{http.StatusBadRequest, "saved_reply_invalid", "title and body are required"}},
+	{services.ErrSavedReplyNotFound, Entry{http.StatusNotFound, "saved_reply_not_found", "saved reply not found"}},
+	{services.ErrSavedReplyForbidden, Entry{http.StatusForbidden, "saved_reply_forbidden", "saved reply does not belong to this coach"}},
+	{services.ErrSavedReplyLimit, Entry{http.StatusConflict, "saved_reply_limit_reached", "coaches can keep at most 100 saved replies"}},
+	{services.ErrInvalidConversationExportFormat, Entry{http.StatusBadRequest, "invalid_export_format", "format must be json or txt"}},
+	{services.ErrConversationExportLimit, Entry{http.StatusTooManyRequests, "conversation_export_limit_reached", "conversation exports are limited to 5 per day"}},
+	{services.ErrInvalidLocalScheduledAt, Entry{http.StatusBadRequest, "invalid_scheduled_at", "with local_time, scheduled_at must look like 2006-01-02T15:04"}},
+	{services.ErrScheduledTooFar, Entry{http.StatusBadRequest, "scheduled_too_far", "messages can be scheduled at most 30 days ahead"}},
+	{services.ErrSchedulingCoachOnly, Entry{http.StatusForbidden, "scheduling_coach_only", "only the coach can schedule messages"}},
+	{services.ErrInvalidScheduledStatus, Entry{http.StatusBadRequest, "invalid_scheduled_status", "status must be pending, sent, cancelled or failed"}},
+	{services.ErrScheduledMessageNotFound, Entry{http.StatusNotFound, "scheduled_message_not_found", "scheduled message not found"}},
+	{services.ErrScheduledMessageForbidden, Entry{http.StatusForbidden, "scheduled_message_forbidden", "scheduled message does not belong to this user"}},
+	{services.ErrScheduledMessageNotCancelable, Entry{http.StatusConflict, "scheduled_message_not_pending", "scheduled message was already sent, cancelled or failed"}},
+
+	// Scheduling
+	{services.ErrSessionTypeInvalid, Entry{http.StatusBadRequest, "session_type_invalid", "name is required"}},
+	{services.ErrSessionTypeNotFound, Entry{http.StatusNotFound, "session_type_not_found", "session type not found"}},
+	{services.ErrSessionTypeForbidden, Entry{http.StatusForbidden, "session_type_forbidden", "session type does not belong to this coach"}},
+	{services.ErrSessionTypeInactive, Entry{http.StatusConflict, "session_type_inactive", "session type is inactive"}},
+	{services.ErrSessionTypeNotBookable, Entry{http.StatusForbidden, "session_type_not_bookable", "this session type can only be booked by the coach"}},
+	{services.ErrSelfBookingDisabled, Entry{http.StatusForbidden, "self_booking_disabled", "your coach books your sessions; contact them to schedule"}},
+	{services.ErrSessionTypeOrderInvalid, Entry{http.StatusBadRequest, "session_type_order_invalid", "session_type_ids must list every active session type exactly once"}},
+	{services.ErrSessionNotFound, Entry{http.StatusNotFound, "session_not_found", "session not found"}},
+	{services.ErrSessionForbidden, Entry{http.StatusForbidden, "session_forbidden", "session does not belong to this user"}},
+	{services.ErrSessionActionForbidden, Entry{http.StatusForbidden, "session_action_forbidden", "only the coach can perform this action"}},
+	{services.ErrSessionStateInvalid, Entry{http.StatusConflict, "session_state_invalid", "session is not in a valid state for this action"}},
+	{services.ErrSessionConflict, Entry{http.StatusConflict, "session_conflict", "requested time conflicts with another session"}},
+	{services.ErrClientSessionConflict, Entry{http.StatusConflict, "client_session_conflict", "you already have a session at this time"}},
+	{services.ErrOutsideAvailability, Entry{http.StatusConflict, "outside_availability", "requested time is outside coach availability"}},
+	{services.ErrAvailabilitySlotInvalid, Entry{http.StatusBadRequest, "availability_slot_invalid", "invalid availability slot payload"}},
+	{services.ErrPresetInvalid, Entry{http.StatusBadRequest, "availability_preset_invalid", "name is required"}},
+	{services.ErrPresetNotFound, Entry{http.StatusNotFound, "availability_preset_not_found", "availability preset not found"}},
+	{services.ErrPresetForbidden, Entry{http.StatusForbidden, "availability_preset_forbidden", "availability preset does not belong to this coach"}},
+	{services.ErrPresetNameTaken, Entry{http.StatusConflict, "availability_preset_name_taken", "an availability preset with this name already exists"}},
+	{services.ErrPresetLimit, Entry{http.StatusConflict, "availability_preset_limit_reached", "coaches can keep at most 20 availability presets"}},
+	{services.ErrOverrideNotFound, Entry{http.StatusNotFound, "override_not_found", "availability override not found"}},
+	{services.ErrOverrideForbidden, Entry{http.StatusForbidden, "override_forbidden", "override does not belong to this coach"}},
+	{services.ErrInvalidDateRange, Entry{http.StatusBadRequest, "invalid_date_range", "invalid date range"}},
+	{services.ErrInvalidDateFormat, Entry{http.StatusBadRequest, "invalid_date_format", "dates must be YYYY-MM-DD"}},
+	{services.ErrInvalidWeek, Entry{http.StatusBadRequest, "invalid_week", "week must be an ISO week such as 2026-W12 or a date (YYYY-MM-DD)"}},
+	{services.ErrInvalidScheduledAt, Entry{http.StatusBadRequest, "invalid_scheduled_at", "scheduled_at must be an RFC3339 datetime"}},
+	{services.ErrInvalidSessionStatus, Entry{http.StatusBadRequest, "invalid_session_status", "status must list pending_confirmation, scheduled, completed, cancelled or no_show"}},
+	{services.ErrInvalidSessionDuration, Entry{http.StatusBadRequest, "invalid_session_duration", "invalid duration_minutes"}},
+	{services.ErrSessionUnpriced, Entry{http.StatusConflict, "session_unpriced", "session has no price to mark as paid"}},
+	{services.ErrSessionFull, Entry{http.StatusConflict, "session_full", "this group session is full"}},
+	{services.ErrSessionAlreadyJoined, Entry{http.StatusConflict, "session_already_joined", "client is already booked into this session"}},
+	{services.ErrInvalidMaxParticipants, Entry{http.StatusBadRequest, "invalid_max_participants", "max_participants must be between 1 and 50"}},
+	{services.ErrCheckInForbidden, Entry{http.StatusForbidden, "check_in_forbidden", "only a booked client can check in"}},
+	{services.ErrCheckInWindowClosed, Entry{http.StatusConflict, "check_in_window_closed", "check-in opens 30 minutes before and closes 30 minutes after the scheduled time"}},
+	{services.ErrInvalidCheckInLocation, Entry{http.StatusBadRequest, "invalid_check_in_location", "latitude and longitude must be sent together"}},
+	{services.ErrSessionCheckedIn, Entry{http.StatusConflict, "session_checked_in", "client checked in; session cannot be marked as a no-show"}},
+	{services.ErrSessionUpdateInvalid, Entry{http.StatusBadRequest, "session_update_invalid", "location or notes is required"}},
+	{services.ErrSessionNotInSeries, Entry{http.StatusBadRequest, "session_not_in_series", "session is not part of a recurring series"}},
+	{services.ErrTimeBlockInvalid, Entry{http.StatusBadRequest, "time_block_invalid", "end_at must be after start_at and within 7 days of it"}},
+	{services.ErrTimeBlockNotFound, Entry{http.StatusNotFound, "time_block_not_found", "time block not found"}},
+	{services.ErrTimeBlockForbidden, Entry{http.StatusForbidden, "time_block_forbidden", "time block does not belong to this coach"}},
+	{services.ErrFeedbackForbidden, Entry{http.StatusForbidden, "feedback_forbidden", "only the session's client can leave feedback"}},
+	{services.ErrFeedbackWindowClosed, Entry{http.StatusConflict, "feedback_window_closed", "feedback can only be left within 14 days of a completed session"}},
+	{services.ErrFeedbackExists, Entry{http.StatusConflict, "feedback_exists", "feedback was already submitted for this session"}},
+
+	// Reports
+	{services.ErrInvalidMonthFormat, Entry{http.StatusBadRequest, "invalid_month_format", "months must be YYYY-MM"}},
+	{services.ErrClientReportInProgress, Entry{http.StatusConflict, "client_report_in_progress", "a report for this client is already being generated"}},
+	{services.ErrClientReportFuture, Entry{http.StatusBadRequest, "client_report_future_month", "reports can only cover the current or past months"}},
+	{services.ErrInvalidAdherenceRange, Entry{http.StatusBadRequest, "invalid_adherence_range", "days must be between 1 and 90 and tolerance between 1 and 50"}},
+	{services.ErrInvalidVolumeWeeks, Entry{http.StatusBadRequest, "invalid_volume_weeks", "weeks must be between 1 and 52"}},
+
+	// Workouts
+	{services.ErrTemplateNotFound, Entry{http.StatusNotFound, "template_not_found", "template not found"}},
+	{services.ErrTemplateForbidden, Entry{http.StatusForbidden, "template_forbidden", "template does not belong to this coach"}},
+	{services.ErrTemplateVersionNotFound, Entry{http.StatusNotFound, "template_version_not_found", "template version not found"}},
+	{services.ErrTemplateCategoryInvalid, Entry{http.StatusBadRequest, "template_category_invalid", "name is required"}},
+	{services.ErrTemplateCategoryNotFound, Entry{http.StatusNotFound, "template_category_not_found", "template category not found"}},
+	{services.ErrTemplateCategoryForbidden, Entry{http.StatusForbidden, "template_category_forbidden", "template category does not belong to this coach"}},
+	{services.ErrTemplateCategoryNameTaken, Entry{http.StatusConflict, "template_category_name_taken", "a template category with this name already exists"}},
+	{services.ErrWorkoutNotFound, Entry{http.StatusNotFound, "workout_not_found", "workout not found"}},
+	{services.ErrWorkoutForbidden, Entry{http.StatusForbidden, "workout_forbidden", "workout does not belong to this user"}},
+	{services.ErrWorkoutExerciseNotFound, Entry{http.StatusNotFound, "workout_exercise_not_found", "workout exercise not found"}},
+	{services.ErrClientExerciseDefaultInvalid, Entry{http.StatusBadRequest, "client_exercise_default_invalid", "set weight_value with weight_unit, reps_min (with an optional reps_max no lower) or rest_seconds; reps only apply to reps exercises"}},
+	{services.ErrClientExerciseDefaultNotFound, Entry{http.StatusNotFound, "client_exercise_default_not_found", "client exercise default not found"}},
+	{services.ErrExerciseNotFound, Entry{http.StatusNotFound, "exercise_not_found", "exercise not found"}},
+	{services.ErrInvalidExerciseGroups, Entry{http.StatusBadRequest, "invalid_exercise_groups", "each exercise group needs at least 2 adjacent exercises sharing one group_type (superset, circuit or giant_set)"}},
+	{services.ErrInvalidExercisePrescription, Entry{http.StatusBadRequest, "invalid_exercise_prescription", "prescriptions must match each exercise's measurement_type: sets and reps for reps, prescribed_duration_seconds for time, prescribed_distance and unit for distance"}},
+	{services.ErrExerciseForbidden, Entry{http.StatusForbidden, "exercise_forbidden", "exercise does not belong to this coach"}},
+	{services.ErrInvalidAlternative, Entry{http.StatusBadRequest, "invalid_exercise_alternative", "alternative must be a different active exercise from your library"}},
+	{services.ErrAlternativeNotFound, Entry{http.StatusNotFound, "exercise_alternative_not_found", "exercise alternative not found"}},
+	{services.ErrWorkoutLogNotFound, Entry{http.StatusNotFound, "workout_log_not_found", "workout log not found"}},
+	{services.ErrInvalidWorkoutLog, Entry{http.StatusBadRequest, "invalid_workout_log", "logged sets must match the exercise's measurement_type: reps_completed for reps, duration_seconds for time, distance and distance_unit for distance; rpe is 1-10 and amounts cannot be negative"}},
+	{services.ErrInvalidWorkoutState, Entry{http.StatusConflict, "invalid_workout_state", "workout is already finalized"}},
+	{services.ErrCompletionNoteRequired, Entry{http.StatusUnprocessableEntity, "completion_note_required", "your coach asks for a completion note with each workout"}},
+	{services.ErrFormCheckVideoMissing, Entry{http.StatusConflict, "form_check_video_missing", "workout log has no form video to review"}},
+	{services.ErrFormFeedbackRequired, Entry{http.StatusBadRequest, "form_feedback_required", "form_feedback cannot be empty"}},
+	{services.ErrWorkoutStateTooLarge, Entry{http.StatusRequestEntityTooLarge, "workout_state_too_large", "workout state must be 8KB or smaller"}},
+	{services.ErrWorkoutAlreadyScheduled, Entry{http.StatusConflict, "workout_already_scheduled", "client already has a workout on this date"}},
+	{services.ErrInvalidScheduledDate, Entry{http.StatusBadRequest, "invalid_scheduled_date", "scheduled_date must be YYYY-MM-DD"}},
+
+	// Admin
+	{services.ErrAdminRequired, Entry{http.StatusForbidden, "admin_required", "admin access required"}},
+	{services.ErrTransferSameCoach, Entry{http.StatusBadRequest, "transfer_same_coach", "client already belongs to the target coach"}},
+	{services.ErrTransferTargetConnected, Entry{http.StatusConflict, "transfer_target_connected", "client already has a profile with the target coach"}},
+	{services.ErrClientAlreadyTransferred, Entry{http.StatusConflict, "client_already_transferred", "client profile was already transferred"}},
+	{services.ErrFeatureFlagNotFound, Entry{http.StatusNotFound, "feature_flag_not_found", "feature flag not found"}},
+	{services.ErrFeatureFlagKeyTaken, Entry{http.StatusConflict, "feature_flag_key_taken", "a feature flag with this key already exists"}},
+	{services.ErrInvalidFeatureFlagKey, Entry{http.StatusBadRequest, "invalid_feature_flag_key", "key must be lowercase letters and digits separated by _, . or -"}},
+	{services.ErrInvalidFeatureFlagRules, Entry{http.StatusBadRequest, "invalid_feature_flag_rules", "rules.percentage must be between 0 and 100"}},
+	{services.ErrWebhookEntryNotFound, Entry{http.StatusNotFound, "webhook_entry_not_found", "webhook inbox entry not found"}},
+	{services.ErrInvalidWebhookStatus, Entry{http.StatusBadRequest, "invalid_webhook_status", "status must be pending, processed, failed or ignored"}},
+	{services.ErrWebhookProviderNoReplay, Entry{http.StatusConflict, "webhook_provider_no_replay", "this webhook provider does not support replay"}},
+	{services.ErrBulkExerciseFilterRequired, Entry{http.StatusBadRequest, "bulk_exercise_filter_required", "filter needs ids, a name_pattern or a tag"}},
+	{services.ErrBulkExerciseMutationRequired, Entry{http.StatusBadRequest, "bulk_exercise_mutation_required", "mutation must change tags, difficulty or muscle groups"}},
+	{services.ErrBulkExerciseMutationInvalid, Entry{http.StatusBadRequest, "bulk_exercise_mutation_invalid", "a tag cannot be both added and removed"}},
+	{services.ErrBulkExerciseTooMany, Entry{http.StatusBadRequest, "bulk_exercise_too_many", "filter matches more than 5000 exercises"}},
+	{services.ErrBulkExerciseConfirmationRequired, Entry{http.StatusBadRequest, "bulk_exercise_confirmation_required", "run with dry_run first and send back its confirmation_token"}},
+	{services.ErrBulkExerciseConfirmationInvalid, Entry{http.StatusConflict, "bulk_exercise_confirmation_invalid", "confirmation_token expired or no longer matches the affected exercises; run a new dry run"}},
+
+	// Subscriptions
+	{services.ErrInvalidSubscriptionWebhookAuth, Entry{http.StatusUnauthorized, "invalid_webhook_authorization", "invalid webhook authorization"}},
+	{services.ErrSubscriptionWebhookPayload, Entry{http.StatusBadRequest, "invalid_webhook_payload", "invalid webhook payload"}},
+	{services.ErrFeatureNameRequired, Entry{http.StatusBadRequest, "feature_name_required", "feature is required"}},
+}
+
+// Lookup returns the registered entry for err, matching wrapped errors via errors.Is.
+func Lookup(err error) (Entry, bool) {
+	for _, r := range registry {
+		if errors.Is(err, r.err) {
+			return r.entry, true
+		}
+	}
+	return Entry{}, false
+}
+
+// detailedError is implemented by service errors that carry extra fields for the client,
+// such as the ID of the record that caused a conflict.
+type detailedError interface {
+	ErrorDetails() map[string]any
+}
+
+// RespondError writes the registered response for err, plus any fields from a detailedError in its chain.
+// Unregistered errors are logged with the request ID and surfaced as a generic 500 so internals never leak.
+// Messages are translated to the language the client asks for with Accept-Language when the catalog has it.
+func RespondError(c *gin.Context, err error) {
+	if entry, ok := Lookup(err); ok {
+		body := gin.H{"error": message(c, entry.Code, entry.Message), "code": entry.Code}
+		var detailed detailedError
+		if errors.As(err, &detailed) {
+			for key, value := range detailed.ErrorDetails() {
+				if _, reserved := body[key]; !reserved {
+					body[key] = value
+				}
+			}
+		}
+		c.JSON(entry.Status, body)
+		return
+	}
+
+	slog.Error("Unhandled service error",
+		"request_id", c.GetString(middleware.RequestIDKey),
+		"method", c.Request.Method,
+		"path", c.FullPath(),
+		"error", err,
+	)
+	c.JSON(http.StatusInternalServerError, gin.H{"error": message(c, "internal_error", "internal server error"), "code": "internal_error"})
+}
+
+// Describe returns the localized error and code for a registered err, for responses that report
+// failures per item instead of failing the whole request. It reports false for unregistered errors.
+func Describe(c *gin.Context, err error) (gin.H, bool) {
+	entry, ok := Lookup(err)
+	if !ok {
+		return nil, false
+	}
+	return gin.H{"error": message(c, entry.Code, entry.Message), "code": entry.Code}, true
+}
+
+// message localizes the English message registered for code into the request's language.
+func message(c *gin.Context, code, english string) string {
+	return i18n.ErrorMessage(i18n.FromAcceptLanguage(c.GetHeader("Accept-Language")), code, english)
+}
@@ -0,0 +1,104 @@
+package errmap
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"net/http"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// serviceSentinels returns the names of every exported package-level Err* variable in pkg/services.
+func serviceSentinels(t *testing.T) []string {
+	t.Helper()
+
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, "../../services", func(fi fs.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		t.Fatalf("parse services: %v", err)
+	}
+	pkg, ok := pkgs["services"]
+	if !ok {
+		t.Fatalf("package services not found")
+	}
+
+	var names []string
+	for _, file := range pkg.Files {
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.VAR {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				for _, name := range spec.(*ast.ValueSpec).Names {
+					if strings.HasPrefix(name.Name, "Err") && name.IsExported() {
+						names = append(names, name.Name)
+					}
+				}
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// registeredSentinels returns the services.Err* names referenced by the registry in errmap.go.
+func registeredSentinels(t *testing.T) map[string]bool {
+	t.Helper()
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "errmap.go", nil, 0)
+	if err != nil {
+		t.Fatalf("parse errmap.go: %v", err)
+	}
+
+	registered := make(map[string]bool)
+	ast.Inspect(file, func(n ast.Node) bool {
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		if pkg, ok := sel.X.(*ast.Ident); ok && pkg.Name == "services" && strings.HasPrefix(sel.Sel.Name, "Err") {
+			registered[sel.Sel.Name] = true
+		}
+		return true
+	})
+	return registered
+}
+
+func TestEveryServiceErrorIsRegistered(t *testing.T) {
+	sentinels := serviceSentinels(t)
+	if len(sentinels) == 0 {
+		t.Fatal("found no services.Err* variables; is the services path right?")
+	}
+
+	registered := registeredSentinels(t)
+	for _, name := range sentinels {
+		if !registered[name] {
+			t.Errorf("services.%s has no errmap registry entry and would surface as a 500", name)
+		}
+	}
+}
+
+func TestRegistryEntriesAreComplete(t *testing.T) {
+	for _, r := range registry {
+		if r.err == nil {
+			t.Errorf("registry has a nil error for code %q", r.entry.Code)
+			continue
+		}
+		if r.entry.Code == "" || r.entry.Message == "" {
+			t.Errorf("%v: code and message are required", r.err)
+		}
+		if r.entry.Status < http.StatusBadRequest || r.entry.Status > 599 {
+			t.Errorf("%v: status %d is not an error status", r.err, r.entry.Status)
+		}
+		if entry, ok := Lookup(r.err); !ok || entry != r.entry {
+			t.Errorf("%v: Lookup returned %+v, want the first registration %+v", r.err, entry, r.entry)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package errmap
+
+import (
+	"chalk-api/pkg/validators"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// FieldError describes a single invalid field in a request payload.
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// RespondBindError writes a 400 for a JSON binding failure, listing each offending field when the
+// failure came from struct validation, a JSON type mismatch or an unknown field. A body cut off by
+// http.MaxBytesReader gets a 413 instead.
+func RespondBindError(c *gin.Context, err error) {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		RespondBodyTooLarge(c, maxBytesErr.Limit)
+		return
+	}
+
+	body := gin.H{"error": message(c, "invalid_request_body", "invalid request body"), "code": "invalid_request_body"}
+	if details := FieldErrors(err); len(details) > 0 {
+		body["error"] = message(c, "validation_failed", "invalid request body")
+		body["code"] = "validation_failed"
+		body["details"] = details
+	}
+	c.JSON(http.StatusBadRequest, body)
+}
+
+// FieldErrors converts binding errors into field-level details. Returns nil for malformed JSON.
+func FieldErrors(err error) []FieldError {
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		details := make([]FieldError, 0, len(validationErrs))
+		for _, fe := range validationErrs {
+			details = append(details, FieldError{
+				Field:   fieldPath(fe.Namespace()),
+				Rule:    fe.Tag(),
+				Message: ruleMessage(fe),
+			})
+		}
+		return details
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return []FieldError{{
+			Field:   typeErr.Field,
+			Rule:    "type",
+			Message: "must be " + jsonTypeName(typeErr.Type.Kind()),
+		}}
+	}
+
+	// encoding/json has no typed error for DisallowUnknownFields, only this message
+	if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		return []FieldError{{
+			Field:   strings.Trim(field, `"`),
+			Rule:    "unknown",
+			Message: "is not a recognized field",
+		}}
+	}
+
+	return nil
+}
+
+// RespondBodyTooLarge writes a 413 for a request body over the configured size limit.
+func RespondBodyTooLarge(c *gin.Context, limit int64) {
+	c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{
+		"error":     message(c, "request_body_too_large", "request body too large"),
+		"code":      "request_body_too_large",
+		"max_bytes": limit,
+	})
+}
+
+// fieldPath drops the root struct name so clients see "slots[0].start_time" rather than
+// "SetAvailabilityInput.slots[0].start_time".
+func fieldPath(namespace string) string {
+	if idx := strings.Index(namespace, "."); idx >= 0 {
+		return namespace[idx+1:]
+	}
+	return namespace
+}
+
+func ruleMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	case "min":
+		return "must be at least " + fe.Param()
+	case "max":
+		return "must be at most " + fe.Param()
+	case "oneof":
+		return "must be one of: " + strings.ReplaceAll(fe.Param(), " ", ", ")
+	case "hhmm":
+		return "must be a time in HH:MM format"
+	case "date":
+		return "must be a date in YYYY-MM-DD format"
+	case "rfc3339":
+		return "must be an RFC3339 datetime"
+	case "weight_unit":
+		return "must be one of: " + strings.Join(validators.WeightUnits, ", ")
+	case "meal_type":
+		return "must be one of: " + strings.Join(validators.MealTypes, ", ")
+	case "iso4217":
+		return "must be an uppercase ISO 4217 currency code (e.g. USD)"
+	default:
+		return "failed " + fe.Tag() + " validation"
+	}
+}
+
+func jsonTypeName(kind reflect.Kind) string {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	case reflect.Map, reflect.Struct:
+		return "an object"
+	default:
+		return "a string"
+	}
+}
@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"chalk-api/pkg/services"
+	"chalk-api/pkg/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type FlagHandler struct {
+	flagService *services.FlagService
+}
+
+func NewFlagHandler(flagService *services.FlagService) *FlagHandler {
+	return &FlagHandler{flagService: flagService}
+}
+
+// GetMyFlags returns every feature flag's state for the caller so the apps can gate UI.
+func (h *FlagHandler) GetMyFlags(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	flags, err := h.flagService.EvaluateAll(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to evaluate feature flags"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"flags": flags})
+}
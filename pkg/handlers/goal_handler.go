@@ -0,0 +1,225 @@
+package handlers
+
+import (
+	"chalk-api/pkg/handlers/errmap"
+	"chalk-api/pkg/services"
+	"chalk-api/pkg/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type GoalHandler struct {
+	goalService *services.GoalService
+}
+
+func NewGoalHandler(goalService *services.GoalService) *GoalHandler {
+	return &GoalHandler{goalService: goalService}
+}
+
+func (h *GoalHandler) CreateMyGoal(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var input services.CreateGoalInput
+	if err := bindJSON(c, &input); err != nil {
+		errmap.RespondBindError(c, err)
+		return
+	}
+
+	goal, err := h.goalService.CreateMyGoal(c.Request.Context(), userID, input)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, goal)
+}
+
+// ListMyGoals returns the client's goals, optionally filtered by ?status=active|achieved|abandoned.
+func (h *GoalHandler) ListMyGoals(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	goals, err := h.goalService.ListMyGoals(c.Request.Context(), userID, c.Query("status"))
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": goals})
+}
+
+func (h *GoalHandler) CreateClientGoal(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	clientProfileID, valid := parseUintParam(c.Param("id"))
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid client id"})
+		return
+	}
+
+	var input services.CreateGoalInput
+	if err := bindJSON(c, &input); err != nil {
+		errmap.RespondBindError(c, err)
+		return
+	}
+
+	goal, err := h.goalService.CreateClientGoal(c.Request.Context(), userID, clientProfileID, input)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, goal)
+}
+
+func (h *GoalHandler) ListClientGoals(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	clientProfileID, valid := parseUintParam(c.Param("id"))
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid client id"})
+		return
+	}
+
+	goals, err := h.goalService.ListClientGoals(c.Request.Context(), userID, clientProfileID, c.Query("status"))
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": goals})
+}
+
+// UpdateGoal is shared by the client and their coach; status achieved notifies the other side.
+func (h *GoalHandler) UpdateGoal(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	goalID, valid := parseUintParam(c.Param("id"))
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid goal id"})
+		return
+	}
+
+	var input services.UpdateGoalInput
+	if err := bindJSON(c, &input); err != nil {
+		errmap.RespondBindError(c, err)
+		return
+	}
+
+	goal, err := h.goalService.UpdateGoal(c.Request.Context(), userID, goalID, input)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, goal)
+}
+
+func (h *GoalHandler) DeleteGoal(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	goalID, valid := parseUintParam(c.Param("id"))
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid goal id"})
+		return
+	}
+
+	if err := h.goalService.DeleteGoal(c.Request.Context(), userID, goalID); err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "goal deleted"})
+}
+
+func (h *GoalHandler) AddGoalProgress(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	goalID, valid := parseUintParam(c.Param("id"))
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid goal id"})
+		return
+	}
+
+	var input services.GoalProgressInput
+	if err := bindJSON(c, &input); err != nil {
+		errmap.RespondBindError(c, err)
+		return
+	}
+
+	entry, err := h.goalService.AddGoalProgress(c.Request.Context(), userID, goalID, input)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, entry)
+}
+
+func (h *GoalHandler) ListGoalProgress(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	goalID, valid := parseUintParam(c.Param("id"))
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid goal id"})
+		return
+	}
+
+	preferredUnits, valid := parseUnitsQuery(c)
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "units must be preferred or stored"})
+		return
+	}
+
+	limit := parseQueryInt(c.DefaultQuery("limit", "20"), 20)
+	offset := parseQueryInt(c.DefaultQuery("offset", "0"), 0)
+
+	page, err := h.goalService.ListGoalProgress(c.Request.Context(), userID, goalID, limit, offset, preferredUnits)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	response := gin.H{
+		"data":   page.Entries,
+		"total":  page.Total,
+		"limit":  limit,
+		"offset": offset,
+	}
+	if page.Unit != nil {
+		response["unit"] = *page.Unit
+	}
+	c.JSON(http.StatusOK, response)
+}
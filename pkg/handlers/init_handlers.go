@@ -2,32 +2,73 @@ package handlers
 
 import (
 	"chalk-api/pkg/config"
+	"chalk-api/pkg/external"
 	"chalk-api/pkg/repositories"
 	"chalk-api/pkg/services"
+	"chalk-api/pkg/stores"
+	"chalk-api/pkg/validators"
 )
 
 // InitializeHandlers initializes all the handlers
-func InitializeHandlers(services *services.ServicesCollection, repos *repositories.RepositoriesCollection, cfg config.Environment) (*HandlersCollection, error) {
+func InitializeHandlers(services *services.ServicesCollection, repos *repositories.RepositoriesCollection, integrations *external.Collection, cfg config.Environment) (*HandlersCollection, error) {
+	if err := validators.Register(); err != nil {
+		return nil, err
+	}
+
 	return &HandlersCollection{
-		Auth:         NewAuthHandler(services.Auth),
-		User:         NewUserHandler(services.User),
-		Coach:        NewCoachHandler(services.Coach),
-		Session:      NewSessionHandler(services.Session),
-		Invite:       NewInviteHandler(services.Coach),
-		Workout:      NewWorkoutHandler(services.Workout),
-		Message:      NewMessageHandler(services.Message),
-		Subscription: NewSubscriptionHandler(services.Subscription),
+		TokenKeys:        services.TokenKeys,
+		Revocations:      services.Revocations,
+		RateLimiter:      services.RateLimiter,
+		RequestAnalytics: services.RequestAnalytics,
+		Auth:             NewAuthHandler(services.Auth),
+		User:             NewUserHandler(services.User),
+		Coach:            NewCoachHandler(services.Coach),
+		Session:          NewSessionHandler(services.Session),
+		Invite:           NewInviteHandler(services.Coach),
+		Workout:          NewWorkoutHandler(services.Workout),
+		Message:          NewMessageHandler(services.Message),
+		Subscription:     NewSubscriptionHandler(services.Subscription),
+		Report:           NewReportHandler(services.Report),
+		Notification:     NewNotificationHandler(services.Notification),
+		Digest:           NewDigestHandler(services.Digest),
+		Admin:            NewAdminHandler(services.Admin),
+		Calendar:         NewCalendarHandler(services.Calendar),
+		Goal:             NewGoalHandler(services.Goal),
+		Streak:           NewStreakHandler(services.Streak),
+		Activity:         NewActivityHandler(services.Activity),
+		Intake:           NewIntakeHandler(services.Intake),
+		Flag:             NewFlagHandler(services.Flag),
+		Bootstrap:        NewBootstrapHandler(services.Bootstrap),
+		Metrics:          NewMetricsHandler(repos, integrations, services.CacheInspector),
 	}, nil
 }
 
 // HandlersCollection contains all the handlers
 type HandlersCollection struct {
-	Auth         *AuthHandler
-	User         *UserHandler
-	Coach        *CoachHandler
-	Session      *SessionHandler
-	Invite       *InviteHandler
-	Workout      *WorkoutHandler
-	Message      *MessageHandler
-	Subscription *SubscriptionHandler
+	// TokenKeys verifies access tokens in the auth middleware and backs the JWKS endpoint
+	TokenKeys   *services.TokenKeys
+	Revocations *services.TokenRevocations
+	RateLimiter *stores.RateLimiter
+	// RequestAnalytics records authenticated requests for support when enabled
+	RequestAnalytics *services.RequestAnalytics
+	Auth             *AuthHandler
+	User             *UserHandler
+	Coach            *CoachHandler
+	Session          *SessionHandler
+	Invite           *InviteHandler
+	Workout          *WorkoutHandler
+	Message          *MessageHandler
+	Subscription     *SubscriptionHandler
+	Report           *ReportHandler
+	Notification     *NotificationHandler
+	Digest           *DigestHandler
+	Admin            *AdminHandler
+	Calendar         *CalendarHandler
+	Goal             *GoalHandler
+	Streak           *StreakHandler
+	Activity         *ActivityHandler
+	Intake           *IntakeHandler
+	Flag             *FlagHandler
+	Bootstrap        *BootstrapHandler
+	Metrics          *MetricsHandler
 }
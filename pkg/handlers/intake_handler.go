@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"chalk-api/pkg/handlers/errmap"
+	"chalk-api/pkg/services"
+	"chalk-api/pkg/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type IntakeHandler struct {
+	intakeService *services.IntakeService
+}
+
+func NewIntakeHandler(intakeService *services.IntakeService) *IntakeHandler {
+	return &IntakeHandler{intakeService: intakeService}
+}
+
+func (h *IntakeHandler) CreateQuestion(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var input services.CreateIntakeQuestionInput
+	if err := bindJSON(c, &input); err != nil {
+		errmap.RespondBindError(c, err)
+		return
+	}
+
+	question, err := h.intakeService.CreateMyQuestion(c.Request.Context(), userID, input)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, question)
+}
+
+func (h *IntakeHandler) ListQuestions(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	questions, err := h.intakeService.ListMyQuestions(c.Request.Context(), userID)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": questions})
+}
+
+func (h *IntakeHandler) UpdateQuestion(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	questionID, valid := parseUintPathParam(c.Param("id"))
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid intake question id"})
+		return
+	}
+
+	var input services.UpdateIntakeQuestionInput
+	if err := bindJSON(c, &input); err != nil {
+		errmap.RespondBindError(c, err)
+		return
+	}
+
+	question, err := h.intakeService.UpdateMyQuestion(c.Request.Context(), userID, questionID, input)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, question)
+}
+
+func (h *IntakeHandler) DeleteQuestion(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	questionID, valid := parseUintPathParam(c.Param("id"))
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid intake question id"})
+		return
+	}
+
+	if err := h.intakeService.DeleteMyQuestion(c.Request.Context(), userID, questionID); err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "intake question deleted"})
+}
+
+// GetMyIntakeForm returns the client's intake form and their coach's custom questions.
+// ?client_profile_id= picks the coach when the client has more than one.
+func (h *IntakeHandler) GetMyIntakeForm(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var clientProfileID *uint
+	if raw := c.Query("client_profile_id"); raw != "" {
+		id, valid := parseUintPathParam(raw)
+		if !valid {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid client profile id"})
+			return
+		}
+		clientProfileID = &id
+	}
+
+	view, err := h.intakeService.GetMyIntakeForm(c.Request.Context(), userID, clientProfileID)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, view)
+}
+
+func (h *IntakeHandler) SubmitMyIntakeForm(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var input services.SubmitIntakeFormInput
+	if err := bindJSON(c, &input); err != nil {
+		errmap.RespondBindError(c, err)
+		return
+	}
+
+	if input.Version == nil {
+		version, ok := ifMatchVersion(c)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "If-Match must be a version number"})
+			return
+		}
+		input.Version = version
+	}
+
+	view, err := h.intakeService.SubmitMyIntakeForm(c.Request.Context(), userID, input)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, view)
+}
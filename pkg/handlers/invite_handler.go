@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"chalk-api/pkg/handlers/errmap"
 	"chalk-api/pkg/services"
 	"chalk-api/pkg/utils"
 	"errors"
@@ -44,19 +45,14 @@ func (h *InviteHandler) Accept(c *gin.Context) {
 	}
 
 	var input services.AcceptInviteInput
-	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+	if err := bindJSON(c, &input); err != nil {
+		errmap.RespondBindError(c, err)
 		return
 	}
 
 	result, err := h.coachService.AcceptInvite(c.Request.Context(), userID, input)
 	if err != nil {
-		switch {
-		case errors.Is(err, services.ErrInviteCodeNotFound):
-			c.JSON(http.StatusNotFound, gin.H{"error": "invite code not found or expired"})
-		default:
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to accept invite"})
-		}
+		errmap.RespondError(c, err)
 		return
 	}
 
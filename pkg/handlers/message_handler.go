@@ -1,9 +1,13 @@
 package handlers
 
 import (
+	"chalk-api/pkg/handlers/errmap"
+	"chalk-api/pkg/middleware"
 	"chalk-api/pkg/services"
 	"chalk-api/pkg/utils"
 	"errors"
+	"io"
+	"log/slog"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -24,9 +28,10 @@ func (h *MessageHandler) ListConversations(c *gin.Context) {
 		return
 	}
 
-	conversations, err := h.messageService.ListConversations(c.Request.Context(), userID)
+	includeInactive := c.Query("include_inactive") == "true"
+	conversations, err := h.messageService.ListConversations(c.Request.Context(), userID, includeInactive)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list conversations"})
+		errmap.RespondError(c, err)
 		return
 	}
 
@@ -41,21 +46,14 @@ func (h *MessageHandler) GetOrCreateConversation(c *gin.Context) {
 	}
 
 	var input services.CreateConversationInput
-	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+	if err := bindJSON(c, &input); err != nil {
+		errmap.RespondBindError(c, err)
 		return
 	}
 
 	conversation, err := h.messageService.GetOrCreateConversationByClientProfile(c.Request.Context(), userID, input)
 	if err != nil {
-		switch {
-		case errors.Is(err, services.ErrClientProfileRequired):
-			c.JSON(http.StatusBadRequest, gin.H{"error": "client_profile_id is required"})
-		case errors.Is(err, services.ErrClientProfileInvalid):
-			c.JSON(http.StatusForbidden, gin.H{"error": "client profile does not belong to this user"})
-		default:
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get or create conversation"})
-		}
+		errmap.RespondError(c, err)
 		return
 	}
 
@@ -77,14 +75,7 @@ func (h *MessageHandler) GetConversation(c *gin.Context) {
 
 	conversation, err := h.messageService.GetConversation(c.Request.Context(), userID, conversationID)
 	if err != nil {
-		switch {
-		case errors.Is(err, services.ErrConversationNotFound):
-			c.JSON(http.StatusNotFound, gin.H{"error": "conversation not found"})
-		case errors.Is(err, services.ErrConversationForbidden):
-			c.JSON(http.StatusForbidden, gin.H{"error": "conversation does not belong to this user"})
-		default:
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch conversation"})
-		}
+		errmap.RespondError(c, err)
 		return
 	}
 
@@ -109,14 +100,7 @@ func (h *MessageHandler) ListMessages(c *gin.Context) {
 
 	messages, total, err := h.messageService.ListMessages(c.Request.Context(), userID, conversationID, limit, offset)
 	if err != nil {
-		switch {
-		case errors.Is(err, services.ErrConversationNotFound):
-			c.JSON(http.StatusNotFound, gin.H{"error": "conversation not found"})
-		case errors.Is(err, services.ErrConversationForbidden):
-			c.JSON(http.StatusForbidden, gin.H{"error": "conversation does not belong to this user"})
-		default:
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list messages"})
-		}
+		errmap.RespondError(c, err)
 		return
 	}
 
@@ -128,6 +112,40 @@ func (h *MessageHandler) ListMessages(c *gin.Context) {
 	})
 }
 
+// ExportConversation streams the whole conversation as a download; ?format=json (default) or txt.
+func (h *MessageHandler) ExportConversation(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	conversationID, valid := parseUintParam(c.Param("id"))
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid conversation id"})
+		return
+	}
+
+	export, err := h.messageService.ExportConversation(c.Request.Context(), userID, conversationID, c.Query("format"))
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.Header("Content-Type", export.ContentType)
+	c.Header("Content-Disposition", `attachment; filename="`+export.Filename+`"`)
+	c.Header("Cache-Control", "no-store")
+	c.Status(http.StatusOK)
+
+	if err := export.WriteTranscript(c.Writer); err != nil {
+		slog.Error("Conversation export failed mid-stream",
+			"request_id", c.GetString(middleware.RequestIDKey),
+			"conversation_id", conversationID,
+			"error", err,
+		)
+	}
+}
+
 func (h *MessageHandler) SendMessage(c *gin.Context) {
 	userID, ok := utils.GetUserIDFromContext(c)
 	if !ok {
@@ -142,23 +160,27 @@ func (h *MessageHandler) SendMessage(c *gin.Context) {
 	}
 
 	var input services.SendMessageInput
-	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+	if err := bindJSON(c, &input); err != nil {
+		errmap.RespondBindError(c, err)
 		return
 	}
 
+	if input.ScheduledAt != nil {
+		scheduled, err := h.messageService.ScheduleMessage(c.Request.Context(), userID, conversationID, input)
+		if err != nil {
+			errmap.RespondError(c, err)
+			return
+		}
+		// A scheduled_at that has already passed falls through and sends now
+		if scheduled != nil {
+			c.JSON(http.StatusAccepted, scheduled)
+			return
+		}
+	}
+
 	message, err := h.messageService.SendMessage(c.Request.Context(), userID, conversationID, input)
 	if err != nil {
-		switch {
-		case errors.Is(err, services.ErrConversationNotFound):
-			c.JSON(http.StatusNotFound, gin.H{"error": "conversation not found"})
-		case errors.Is(err, services.ErrConversationForbidden):
-			c.JSON(http.StatusForbidden, gin.H{"error": "conversation does not belong to this user"})
-		case errors.Is(err, services.ErrMessageContentRequired):
-			c.JSON(http.StatusBadRequest, gin.H{"error": "content or media_url is required"})
-		default:
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to send message"})
-		}
+		errmap.RespondError(c, err)
 		return
 	}
 
@@ -178,19 +200,20 @@ func (h *MessageHandler) MarkAsRead(c *gin.Context) {
 		return
 	}
 
-	if err := h.messageService.MarkAsRead(c.Request.Context(), userID, conversationID); err != nil {
-		switch {
-		case errors.Is(err, services.ErrConversationNotFound):
-			c.JSON(http.StatusNotFound, gin.H{"error": "conversation not found"})
-		case errors.Is(err, services.ErrConversationForbidden):
-			c.JSON(http.StatusForbidden, gin.H{"error": "conversation does not belong to this user"})
-		default:
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to mark conversation as read"})
-		}
+	// The body is optional; without up_to_message_id every unread message is marked.
+	var input services.MarkAsReadInput
+	if err := bindJSON(c, &input); err != nil && !errors.Is(err, io.EOF) {
+		errmap.RespondBindError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "conversation marked as read"})
+	result, err := h.messageService.MarkAsRead(c.Request.Context(), userID, conversationID, input)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
 }
 
 func (h *MessageHandler) GetUnreadCount(c *gin.Context) {
@@ -200,11 +223,137 @@ func (h *MessageHandler) GetUnreadCount(c *gin.Context) {
 		return
 	}
 
-	count, err := h.messageService.GetUnreadCount(c.Request.Context(), userID)
+	includeInactive := c.Query("include_inactive") == "true"
+	count, err := h.messageService.GetUnreadCount(c.Request.Context(), userID, includeInactive)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get unread count"})
+		errmap.RespondError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"unread_count": count})
 }
+
+func (h *MessageHandler) CreateSavedReply(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var input services.CreateSavedReplyInput
+	if err := bindJSON(c, &input); err != nil {
+		errmap.RespondBindError(c, err)
+		return
+	}
+
+	reply, err := h.messageService.CreateMySavedReply(c.Request.Context(), userID, input)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, reply)
+}
+
+func (h *MessageHandler) ListSavedReplies(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	replies, err := h.messageService.ListMySavedReplies(c.Request.Context(), userID, c.Query("search"))
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": replies})
+}
+
+func (h *MessageHandler) UpdateSavedReply(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	replyID, valid := parseUintPathParam(c.Param("id"))
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid saved reply id"})
+		return
+	}
+
+	var input services.UpdateSavedReplyInput
+	if err := bindJSON(c, &input); err != nil {
+		errmap.RespondBindError(c, err)
+		return
+	}
+
+	reply, err := h.messageService.UpdateMySavedReply(c.Request.Context(), userID, replyID, input)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, reply)
+}
+
+func (h *MessageHandler) DeleteSavedReply(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	replyID, valid := parseUintPathParam(c.Param("id"))
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid saved reply id"})
+		return
+	}
+
+	if err := h.messageService.DeleteMySavedReply(c.Request.Context(), userID, replyID); err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "saved reply deleted"})
+}
+
+func (h *MessageHandler) ListScheduledMessages(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	scheduled, err := h.messageService.ListMyScheduledMessages(c.Request.Context(), userID, c.Query("status"))
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": scheduled})
+}
+
+func (h *MessageHandler) CancelScheduledMessage(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	scheduledID, valid := parseUintPathParam(c.Param("id"))
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid scheduled message id"})
+		return
+	}
+
+	scheduled, err := h.messageService.CancelScheduledMessage(c.Request.Context(), userID, scheduledID)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, scheduled)
+}
@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"chalk-api/pkg/external"
+	"chalk-api/pkg/external/breaker"
+	"chalk-api/pkg/repositories"
+	"chalk-api/pkg/stores"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MetricsHandler serves operational metrics in the Prometheus text exposition format.
+type MetricsHandler struct {
+	repos    *repositories.RepositoriesCollection
+	breakers []*breaker.Breaker
+	cache    *stores.CacheInspector
+}
+
+// cacheStatsMaxAge bounds how often a scrape triggers a fresh Redis SCAN; scrapes in between reuse
+// the last key counts and memory estimates
+const cacheStatsMaxAge = time.Minute
+
+func NewMetricsHandler(repos *repositories.RepositoriesCollection, integrations *external.Collection, cache *stores.CacheInspector) *MetricsHandler {
+	h := &MetricsHandler{repos: repos, cache: cache}
+	if integrations != nil {
+		h.breakers = integrations.Breakers
+	}
+	return h
+}
+
+func (h *MetricsHandler) GetMetrics(c *gin.Context) {
+	stats, err := h.repos.PoolStats()
+	if err != nil {
+		slog.Error("Failed to read database pool stats", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error", "code": "internal_error"})
+		return
+	}
+
+	metrics := []struct {
+		name  string
+		kind  string
+		help  string
+		value float64
+	}{
+		{"chalk_db_max_open_connections", "gauge", "Maximum number of open connections to the database.", float64(stats.MaxOpenConnections)},
+		{"chalk_db_open_connections", "gauge", "Number of established connections, in use and idle.", float64(stats.OpenConnections)},
+		{"chalk_db_in_use_connections", "gauge", "Number of connections currently in use.", float64(stats.InUse)},
+		{"chalk_db_idle_connections", "gauge", "Number of idle connections.", float64(stats.Idle)},
+		{"chalk_db_wait_count_total", "counter", "Total number of connections waited for.", float64(stats.WaitCount)},
+		{"chalk_db_wait_duration_seconds_total", "counter", "Total time blocked waiting for a new connection.", stats.WaitDuration.Seconds()},
+		{"chalk_db_max_idle_closed_total", "counter", "Total connections closed due to SetMaxIdleConns.", float64(stats.MaxIdleClosed)},
+		{"chalk_db_max_idle_time_closed_total", "counter", "Total connections closed due to SetConnMaxIdleTime.", float64(stats.MaxIdleTimeClosed)},
+		{"chalk_db_max_lifetime_closed_total", "counter", "Total connections closed due to SetConnMaxLifetime.", float64(stats.MaxLifetimeClosed)},
+	}
+
+	var b strings.Builder
+	for _, m := range metrics {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s %s\n%s %g\n", m.name, m.help, m.name, m.kind, m.name, m.value)
+	}
+	writeBreakerMetrics(&b, h.breakers)
+	writeCacheMetrics(c.Request.Context(), &b, h.cache)
+
+	c.Data(http.StatusOK, "text/plain; version=0.0.4; charset=utf-8", []byte(b.String()))
+}
+
+// writeBreakerMetrics adds one labelled series per external provider breaker.
+func writeBreakerMetrics(b *strings.Builder, breakers []*breaker.Breaker) {
+	if len(breakers) == 0 {
+		return
+	}
+
+	stats := make([]breaker.Stats, 0, len(breakers))
+	for _, cb := range breakers {
+		stats = append(stats, cb.Stats())
+	}
+
+	families := []struct {
+		name  string
+		kind  string
+		help  string
+		value func(breaker.Stats) float64
+	}{
+		{"chalk_external_circuit_state", "gauge", "Circuit breaker state per provider: 0 closed, 1 open, 2 half-open.", func(s breaker.Stats) float64 { return float64(s.State) }},
+		{"chalk_external_circuit_consecutive_failures", "gauge", "Consecutive failed calls counted by the breaker.", func(s breaker.Stats) float64 { return float64(s.ConsecutiveFailures) }},
+		{"chalk_external_circuit_trips_total", "counter", "Total times the breaker opened.", func(s breaker.Stats) float64 { return float64(s.Trips) }},
+		{"chalk_external_circuit_rejected_total", "counter", "Total calls rejected without contacting the provider.", func(s breaker.Stats) float64 { return float64(s.Rejected) }},
+	}
+
+	for _, f := range families {
+		fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s %s\n", f.name, f.help, f.name, f.kind)
+		for _, s := range stats {
+			fmt.Fprintf(b, "%s{provider=%q} %g\n", f.name, s.Name, f.value(s))
+		}
+	}
+}
+
+// writeCacheMetrics adds per-keyspace Redis series. Hit and miss counters are always current; key
+// counts and memory estimates come from a stats run at most cacheStatsMaxAge old.
+func writeCacheMetrics(ctx context.Context, b *strings.Builder, cache *stores.CacheInspector) {
+	stats, err := cache.Stats(ctx, cacheStatsMaxAge)
+	if err != nil {
+		slog.Warn("Failed to read cache stats for metrics", "error", err)
+		return
+	}
+	if !stats.Available {
+		return
+	}
+
+	families := []struct {
+		name  string
+		kind  string
+		help  string
+		value func(stores.KeyspaceStats) float64
+	}{
+		{"chalk_cache_keys", "gauge", "Keys in the Redis keyspace.", func(k stores.KeyspaceStats) float64 { return float64(k.Keys) }},
+		{"chalk_cache_estimated_bytes", "gauge", "Estimated Redis memory used by the keyspace, extrapolated from sampled keys.", func(k stores.KeyspaceStats) float64 { return float64(k.EstimatedBytes) }},
+		{"chalk_cache_hits_total", "counter", "Cache reads served from Redis.", func(k stores.KeyspaceStats) float64 { return float64(k.Hits) }},
+		{"chalk_cache_misses_total", "counter", "Cache reads that found nothing in Redis.", func(k stores.KeyspaceStats) float64 { return float64(k.Misses) }},
+	}
+	for _, f := range families {
+		fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s %s\n", f.name, f.help, f.name, f.kind)
+		for _, k := range stats.Keyspaces {
+			fmt.Fprintf(b, "%s{keyspace=%q} %g\n", f.name, k.Keyspace, f.value(k))
+		}
+	}
+
+	overSoftLimit := 0.0
+	if stats.OverSoftLimit {
+		overSoftLimit = 1
+	}
+	gauges := []struct {
+		name  string
+		help  string
+		value float64
+	}{
+		{"chalk_cache_used_memory_bytes", "Memory Redis reports in use, including keys from other apps.", float64(stats.UsedMemoryBytes)},
+		{"chalk_cache_soft_limit_bytes", "Configured soft limit for chalk's estimated Redis memory; 0 when off.", float64(stats.SoftLimitBytes)},
+		{"chalk_cache_over_soft_limit", "1 when chalk's estimated Redis memory is above the soft limit.", overSoftLimit},
+	}
+	for _, g := range gauges {
+		fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s gauge\n%s %g\n", g.name, g.help, g.name, g.name, g.value)
+	}
+}
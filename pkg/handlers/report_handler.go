@@ -0,0 +1,213 @@
+package handlers
+
+import (
+	"chalk-api/pkg/handlers/errmap"
+	"chalk-api/pkg/middleware"
+	"chalk-api/pkg/services"
+	"chalk-api/pkg/utils"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ReportHandler struct {
+	reportService *services.ReportService
+}
+
+func NewReportHandler(reportService *services.ReportService) *ReportHandler {
+	return &ReportHandler{reportService: reportService}
+}
+
+func (h *ReportHandler) GetEarningsReport(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	report, err := h.reportService.GetEarningsReport(c.Request.Context(), userID, c.Query("start"), c.Query("end"))
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// RequestClientReport queues a progress report PDF for one month (default: the previous month). The
+// coach is notified with a download link when it's ready.
+func (h *ReportHandler) RequestClientReport(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	clientID, valid := parseUintPathParam(c.Param("id"))
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid client id"})
+		return
+	}
+
+	var input services.RequestClientReportInput
+	if err := bindJSON(c, &input); err != nil && !errors.Is(err, io.EOF) {
+		errmap.RespondBindError(c, err)
+		return
+	}
+
+	report, err := h.reportService.RequestClientReport(c.Request.Context(), userID, clientID, input)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, report)
+}
+
+func (h *ReportHandler) ListClientReports(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	clientID, valid := parseUintPathParam(c.Param("id"))
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid client id"})
+		return
+	}
+
+	reports, err := h.reportService.ListClientReports(c.Request.Context(), userID, clientID)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": reports})
+}
+
+// GetClientNutritionAdherence compares a client's logged calories and macros with their targets per day.
+func (h *ReportHandler) GetClientNutritionAdherence(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	clientID, valid := parseUintPathParam(c.Param("id"))
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid client id"})
+		return
+	}
+
+	days := parseQueryInt(c.Query("days"), 0)
+	tolerance := parseQueryInt(c.Query("tolerance"), 0)
+
+	report, err := h.reportService.GetClientNutritionAdherence(c.Request.Context(), userID, clientID, days, tolerance)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// GetMyTrainingVolume returns the caller's weekly sets and tonnage per muscle group. Query: weeks (default 8).
+func (h *ReportHandler) GetMyTrainingVolume(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	weeks := parseQueryInt(c.Query("weeks"), 0)
+	report, err := h.reportService.GetMyTrainingVolume(c.Request.Context(), userID, weeks)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+func (h *ReportHandler) GetClientTrainingVolume(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	clientID, valid := parseUintPathParam(c.Param("id"))
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid client id"})
+		return
+	}
+
+	weeks := parseQueryInt(c.Query("weeks"), 0)
+	report, err := h.reportService.GetClientTrainingVolume(c.Request.Context(), userID, clientID, weeks)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+func (h *ReportHandler) ExportClientWorkoutHistory(c *gin.Context) {
+	preferredUnits, valid := parseUnitsQuery(c)
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "units must be preferred or stored"})
+		return
+	}
+
+	h.exportClientCSV(c, func(ctx context.Context, userID, clientID uint, startRaw, endRaw string) (*services.CSVExport, error) {
+		return h.reportService.ExportClientWorkoutHistory(ctx, userID, clientID, startRaw, endRaw, preferredUnits)
+	})
+}
+
+func (h *ReportHandler) ExportClientSessions(c *gin.Context) {
+	h.exportClientCSV(c, h.reportService.ExportClientSessions)
+}
+
+func (h *ReportHandler) exportClientCSV(
+	c *gin.Context,
+	prepare func(ctx context.Context, userID, clientID uint, startRaw, endRaw string) (*services.CSVExport, error),
+) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	clientID, valid := parseUintPathParam(c.Param("id"))
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid client id"})
+		return
+	}
+
+	export, err := prepare(c.Request.Context(), userID, clientID, c.Query("start_date"), c.Query("end_date"))
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	// No Content-Length: the body is streamed, so net/http uses chunked transfer encoding.
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", `attachment; filename="`+export.Filename+`"`)
+	c.Header("Cache-Control", "no-store")
+	c.Status(http.StatusOK)
+
+	if err := export.WriteCSV(c.Writer); err != nil {
+		// Headers are already sent; all we can do is log and cut the stream short.
+		slog.Error("CSV export failed mid-stream",
+			"request_id", c.GetString(middleware.RequestIDKey),
+			"path", c.FullPath(),
+			"client_id", clientID,
+			"error", err,
+		)
+	}
+}
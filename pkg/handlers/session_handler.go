@@ -1,12 +1,16 @@
 package handlers
 
 import (
+	"chalk-api/pkg/calendar"
+	"chalk-api/pkg/handlers/errmap"
+	"chalk-api/pkg/models"
 	"chalk-api/pkg/services"
 	"chalk-api/pkg/utils"
 	"errors"
 	"io"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -28,12 +32,7 @@ func (h *SessionHandler) GetMyAvailability(c *gin.Context) {
 
 	slots, err := h.sessionService.GetMyAvailability(c.Request.Context(), userID)
 	if err != nil {
-		switch {
-		case errors.Is(err, services.ErrCoachProfileNotFound):
-			c.JSON(http.StatusNotFound, gin.H{"error": "coach profile not found"})
-		default:
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch availability"})
-		}
+		errmap.RespondError(c, err)
 		return
 	}
 
@@ -48,27 +47,128 @@ func (h *SessionHandler) SetMyAvailability(c *gin.Context) {
 	}
 
 	var input services.SetAvailabilityInput
-	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+	if err := bindJSON(c, &input); err != nil {
+		errmap.RespondBindError(c, err)
 		return
 	}
 
-	slots, err := h.sessionService.SetMyAvailability(c.Request.Context(), userID, input)
+	dryRun := c.Query("dry_run") == "true"
+	update, err := h.sessionService.SetMyAvailability(c.Request.Context(), userID, input, dryRun)
 	if err != nil {
-		switch {
-		case errors.Is(err, services.ErrCoachProfileNotFound):
-			c.JSON(http.StatusNotFound, gin.H{"error": "coach profile not found"})
-		case errors.Is(err, services.ErrAvailabilitySlotInvalid):
-			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid availability slot payload"})
-		default:
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save availability"})
-		}
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":              update.Slots,
+		"affected_sessions": update.AffectedSessions,
+		"dry_run":           dryRun,
+	})
+}
+
+func (h *SessionHandler) CopyAvailabilityDay(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var input services.CopyAvailabilityDayInput
+	if err := bindJSON(c, &input); err != nil {
+		errmap.RespondBindError(c, err)
+		return
+	}
+
+	slots, err := h.sessionService.CopyAvailabilityDay(c.Request.Context(), userID, input)
+	if err != nil {
+		errmap.RespondError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"data": slots})
 }
 
+func (h *SessionHandler) CreateAvailabilityPreset(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var input services.CreateAvailabilityPresetInput
+	if err := bindJSON(c, &input); err != nil {
+		errmap.RespondBindError(c, err)
+		return
+	}
+
+	preset, err := h.sessionService.CreateMyAvailabilityPreset(c.Request.Context(), userID, input)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, preset)
+}
+
+func (h *SessionHandler) ListAvailabilityPresets(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	presets, err := h.sessionService.ListMyAvailabilityPresets(c.Request.Context(), userID)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": presets})
+}
+
+func (h *SessionHandler) ApplyAvailabilityPreset(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	presetID, valid := parseUintPathParam(c.Param("id"))
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid preset id"})
+		return
+	}
+
+	slots, err := h.sessionService.ApplyMyAvailabilityPreset(c.Request.Context(), userID, presetID)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": slots})
+}
+
+func (h *SessionHandler) DeleteAvailabilityPreset(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	presetID, valid := parseUintPathParam(c.Param("id"))
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid preset id"})
+		return
+	}
+
+	if err := h.sessionService.DeleteMyAvailabilityPreset(c.Request.Context(), userID, presetID); err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "availability preset deleted"})
+}
+
 func (h *SessionHandler) CreateAvailabilityOverride(c *gin.Context) {
 	userID, ok := utils.GetUserIDFromContext(c)
 	if !ok {
@@ -77,25 +177,23 @@ func (h *SessionHandler) CreateAvailabilityOverride(c *gin.Context) {
 	}
 
 	var input services.CreateAvailabilityOverrideInput
-	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+	if err := bindJSON(c, &input); err != nil {
+		errmap.RespondBindError(c, err)
 		return
 	}
 
-	override, err := h.sessionService.CreateAvailabilityOverride(c.Request.Context(), userID, input)
+	dryRun := c.Query("dry_run") == "true"
+	result, err := h.sessionService.CreateAvailabilityOverride(c.Request.Context(), userID, input, dryRun)
 	if err != nil {
-		switch {
-		case errors.Is(err, services.ErrCoachProfileNotFound):
-			c.JSON(http.StatusNotFound, gin.H{"error": "coach profile not found"})
-		case errors.Is(err, services.ErrInvalidDateFormat), errors.Is(err, services.ErrAvailabilitySlotInvalid):
-			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid override payload"})
-		default:
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create override"})
-		}
+		errmap.RespondError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, override)
+	if dryRun {
+		c.JSON(http.StatusOK, result)
+		return
+	}
+	c.JSON(http.StatusCreated, result)
 }
 
 func (h *SessionHandler) ListAvailabilityOverrides(c *gin.Context) {
@@ -112,14 +210,7 @@ func (h *SessionHandler) ListAvailabilityOverrides(c *gin.Context) {
 		c.Query("end"),
 	)
 	if err != nil {
-		switch {
-		case errors.Is(err, services.ErrCoachProfileNotFound):
-			c.JSON(http.StatusNotFound, gin.H{"error": "coach profile not found"})
-		case errors.Is(err, services.ErrInvalidDateRange), errors.Is(err, services.ErrInvalidDateFormat):
-			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid date range"})
-		default:
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch overrides"})
-		}
+		errmap.RespondError(c, err)
 		return
 	}
 
@@ -140,22 +231,72 @@ func (h *SessionHandler) DeleteAvailabilityOverride(c *gin.Context) {
 	}
 
 	if err := h.sessionService.DeleteMyAvailabilityOverride(c.Request.Context(), userID, overrideID); err != nil {
-		switch {
-		case errors.Is(err, services.ErrCoachProfileNotFound):
-			c.JSON(http.StatusNotFound, gin.H{"error": "coach profile not found"})
-		case errors.Is(err, services.ErrOverrideNotFound):
-			c.JSON(http.StatusNotFound, gin.H{"error": "availability override not found"})
-		case errors.Is(err, services.ErrOverrideForbidden):
-			c.JSON(http.StatusForbidden, gin.H{"error": "override does not belong to this coach"})
-		default:
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete override"})
-		}
+		errmap.RespondError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "availability override deleted"})
 }
 
+func (h *SessionHandler) CreateTimeBlock(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var input services.CreateTimeBlockInput
+	if err := bindJSON(c, &input); err != nil {
+		errmap.RespondBindError(c, err)
+		return
+	}
+
+	block, err := h.sessionService.CreateMyTimeBlock(c.Request.Context(), userID, input)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, block)
+}
+
+func (h *SessionHandler) ListTimeBlocks(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	blocks, err := h.sessionService.ListMyTimeBlocks(c.Request.Context(), userID, c.Query("start"), c.Query("end"))
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": blocks})
+}
+
+func (h *SessionHandler) DeleteTimeBlock(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	blockID, valid := parseUintPathParam(c.Param("id"))
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid time block id"})
+		return
+	}
+
+	if err := h.sessionService.DeleteMyTimeBlock(c.Request.Context(), userID, blockID); err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "time block deleted"})
+}
+
 func (h *SessionHandler) CreateSessionType(c *gin.Context) {
 	userID, ok := utils.GetUserIDFromContext(c)
 	if !ok {
@@ -164,23 +305,14 @@ func (h *SessionHandler) CreateSessionType(c *gin.Context) {
 	}
 
 	var input services.CreateSessionTypeInput
-	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+	if err := bindJSON(c, &input); err != nil {
+		errmap.RespondBindError(c, err)
 		return
 	}
 
 	sessionType, err := h.sessionService.CreateMySessionType(c.Request.Context(), userID, input)
 	if err != nil {
-		switch {
-		case errors.Is(err, services.ErrCoachProfileNotFound):
-			c.JSON(http.StatusNotFound, gin.H{"error": "coach profile not found"})
-		case errors.Is(err, services.ErrSessionTypeInvalid):
-			c.JSON(http.StatusBadRequest, gin.H{"error": "name is required"})
-		case errors.Is(err, services.ErrInvalidSessionDuration):
-			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid duration_minutes"})
-		default:
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create session type"})
-		}
+		errmap.RespondError(c, err)
 		return
 	}
 
@@ -196,12 +328,50 @@ func (h *SessionHandler) ListSessionTypes(c *gin.Context) {
 
 	sessionTypes, err := h.sessionService.ListMySessionTypes(c.Request.Context(), userID)
 	if err != nil {
-		switch {
-		case errors.Is(err, services.ErrCoachProfileNotFound):
-			c.JSON(http.StatusNotFound, gin.H{"error": "coach profile not found"})
-		default:
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch session types"})
-		}
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": sessionTypes})
+}
+
+func (h *SessionHandler) ReorderSessionTypes(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var input services.ReorderSessionTypesInput
+	if err := bindJSON(c, &input); err != nil {
+		errmap.RespondBindError(c, err)
+		return
+	}
+
+	sessionTypes, err := h.sessionService.ReorderMySessionTypes(c.Request.Context(), userID, input)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": sessionTypes})
+}
+
+func (h *SessionHandler) ListBookableSessionTypes(c *gin.Context) {
+	if _, ok := utils.GetUserIDFromContext(c); !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	coachID, valid := parseUintPathParam(c.Param("id"))
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid coach id"})
+		return
+	}
+
+	sessionTypes, err := h.sessionService.ListBookableSessionTypes(c.Request.Context(), coachID)
+	if err != nil {
+		errmap.RespondError(c, err)
 		return
 	}
 
@@ -222,34 +392,72 @@ func (h *SessionHandler) UpdateSessionType(c *gin.Context) {
 	}
 
 	var input services.UpdateSessionTypeInput
-	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+	if err := bindJSON(c, &input); err != nil {
+		errmap.RespondBindError(c, err)
 		return
 	}
 
 	sessionType, err := h.sessionService.UpdateMySessionType(c.Request.Context(), userID, sessionTypeID, input)
 	if err != nil {
-		switch {
-		case errors.Is(err, services.ErrCoachProfileNotFound):
-			c.JSON(http.StatusNotFound, gin.H{"error": "coach profile not found"})
-		case errors.Is(err, services.ErrSessionTypeNotFound):
-			c.JSON(http.StatusNotFound, gin.H{"error": "session type not found"})
-		case errors.Is(err, services.ErrSessionTypeForbidden):
-			c.JSON(http.StatusForbidden, gin.H{"error": "session type does not belong to this coach"})
-		case errors.Is(err, services.ErrInvalidSessionDuration):
-			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid duration_minutes"})
-		default:
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update session type"})
-		}
+		errmap.RespondError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, sessionType)
 }
 
+// GetNextAvailableSlot returns the coach's earliest open slot in the next 30 days, or a null slot,
+// for the home screen's per-coach "next available" chip.
+func (h *SessionHandler) GetNextAvailableSlot(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	coachID, valid := parseUintPathParam(c.Param("id"))
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid coach id"})
+		return
+	}
+
+	sessionTypeID, hasSessionType, err := parseOptionalUintQuery(c.Query("session_type_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid session_type_id"})
+		return
+	}
+	var sessionTypeRef *uint
+	if hasSessionType {
+		sessionTypeRef = &sessionTypeID
+	}
+
+	blocked, err := h.sessionService.SelfBookingBlocked(c.Request.Context(), userID, coachID)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+	if blocked {
+		c.JSON(http.StatusOK, services.NextAvailableSlot{
+			CoachID:       coachID,
+			SessionTypeID: sessionTypeRef,
+			Reason:        "self_booking_disabled",
+		})
+		return
+	}
+
+	next, err := h.sessionService.GetNextAvailableSlot(c.Request.Context(), coachID, sessionTypeRef)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, next)
+}
+
 func (h *SessionHandler) GetBookableSlots(c *gin.Context) {
 	// Keep this protected for now (clients/coaches in app), but no ownership restriction.
-	if _, ok := utils.GetUserIDFromContext(c); !ok {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
 		return
 	}
@@ -270,6 +478,16 @@ func (h *SessionHandler) GetBookableSlots(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid duration_minutes"})
 		return
 	}
+	daysLimit, _, err := parseOptionalIntQuery(c.Query("days_limit"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid days_limit"})
+		return
+	}
+	clientProfileID, hasClientProfile, err := parseOptionalUintQuery(c.Query("client_profile_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid client_profile_id"})
+		return
+	}
 
 	var sessionTypeRef *uint
 	if hasSessionType {
@@ -280,36 +498,109 @@ func (h *SessionHandler) GetBookableSlots(c *gin.Context) {
 		durationRef = &duration
 	}
 
-	slots, serviceErr := h.sessionService.GetBookableSlots(
+	// Clients their coach books for get an empty result with a reason instead of slots they can't use.
+	blocked, err := h.sessionService.SelfBookingBlocked(c.Request.Context(), userID, coachID)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+	if blocked {
+		if c.Query("legacy") == "true" {
+			c.JSON(http.StatusOK, gin.H{"data": []services.BookableSlot{}, "total": 0, "reason": "self_booking_disabled"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"data":     gin.H{},
+			"summary":  gin.H{},
+			"total":    0,
+			"coach_id": coachID,
+			"reason":   "self_booking_disabled",
+		})
+		return
+	}
+
+	result, serviceErr := h.sessionService.GetBookableSlots(
 		c.Request.Context(),
 		coachID,
 		c.Query("start"),
 		c.Query("end"),
 		sessionTypeRef,
 		durationRef,
+		daysLimit,
 	)
 	if serviceErr != nil {
-		switch {
-		case errors.Is(serviceErr, services.ErrCoachProfileNotFound):
-			c.JSON(http.StatusNotFound, gin.H{"error": "coach profile not found"})
-		case errors.Is(serviceErr, services.ErrSessionTypeNotFound):
-			c.JSON(http.StatusNotFound, gin.H{"error": "session type not found"})
-		case errors.Is(serviceErr, services.ErrSessionTypeForbidden):
-			c.JSON(http.StatusForbidden, gin.H{"error": "session type does not belong to this coach"})
-		case errors.Is(serviceErr, services.ErrSessionTypeInactive):
-			c.JSON(http.StatusConflict, gin.H{"error": "session type is inactive"})
-		case errors.Is(serviceErr, services.ErrInvalidDateRange), errors.Is(serviceErr, services.ErrInvalidDateFormat), errors.Is(serviceErr, services.ErrInvalidSessionDuration):
-			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid query parameters"})
-		default:
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to build bookable slots"})
+		errmap.RespondError(c, serviceErr)
+		return
+	}
+	// Slots the client is already booked over, with this coach or another, are dropped up front.
+	if hasClientProfile {
+		result, serviceErr = h.sessionService.WithoutClientConflicts(c.Request.Context(), userID, clientProfileID, result)
+		if serviceErr != nil {
+			errmap.RespondError(c, serviceErr)
+			return
 		}
+	}
+
+	// Flat list kept for clients that predate day grouping.
+	if c.Query("legacy") == "true" {
+		slots := result.Flatten()
+		c.JSON(http.StatusOK, gin.H{
+			"data":  slots,
+			"total": len(slots),
+		})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"data":  slots,
-		"total": len(slots),
-	})
+	compact := c.Query("compact") == "true"
+	data := make(gin.H, len(result.Days))
+	summary := make(gin.H, len(result.Days))
+	for _, day := range result.Days {
+		summary[day.Date] = len(day.Slots)
+		if !compact {
+			data[day.Date] = day.Slots
+			continue
+		}
+		starts := make([]string, len(day.Slots))
+		for i, slot := range day.Slots {
+			starts[i] = slot.StartAt.Format(time.RFC3339)
+		}
+		data[day.Date] = starts
+	}
+
+	response := gin.H{
+		"data":             data,
+		"summary":          summary,
+		"total":            result.Total,
+		"duration_minutes": result.DurationMinutes,
+		"coach_id":         result.CoachID,
+	}
+	if result.SessionTypeID != nil {
+		response["session_type_id"] = *result.SessionTypeID
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// GetPublicBookingPage serves a coach's shareable booking link. It is unauthenticated and rate-limited by IP.
+func (h *SessionHandler) GetPublicBookingPage(c *gin.Context) {
+	sessionTypeID, hasSessionType, err := parseOptionalUintQuery(c.Query("session_type_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid session_type_id"})
+		return
+	}
+	var sessionTypeRef *uint
+	if hasSessionType {
+		sessionTypeRef = &sessionTypeID
+	}
+
+	page, err := h.sessionService.GetPublicBookingPage(c.Request.Context(), c.Param("slug"), c.Query("start"), c.Query("end"), sessionTypeRef)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	h.sessionService.RecordBookingPageView(page, c.ClientIP())
+	c.Header("Cache-Control", "public, max-age=60")
+	c.JSON(http.StatusOK, page)
 }
 
 func (h *SessionHandler) BookSession(c *gin.Context) {
@@ -320,79 +611,105 @@ func (h *SessionHandler) BookSession(c *gin.Context) {
 	}
 
 	var input services.BookSessionInput
-	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
-		return
-	}
-
-	session, err := h.sessionService.BookSession(c.Request.Context(), userID, input)
-	if err != nil {
-		switch {
-		case errors.Is(err, services.ErrClientProfileNotFound):
-			c.JSON(http.StatusNotFound, gin.H{"error": "client profile not found"})
-		case errors.Is(err, services.ErrSessionTypeNotFound):
-			c.JSON(http.StatusNotFound, gin.H{"error": "session type not found"})
-		case errors.Is(err, services.ErrSessionTypeForbidden), errors.Is(err, services.ErrSessionForbidden):
-			c.JSON(http.StatusForbidden, gin.H{"error": "booking is not allowed for this user"})
-		case errors.Is(err, services.ErrSessionTypeInactive):
-			c.JSON(http.StatusConflict, gin.H{"error": "session type is inactive"})
-		case errors.Is(err, services.ErrInvalidScheduledAt), errors.Is(err, services.ErrInvalidSessionDuration):
-			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid booking payload"})
-		case errors.Is(err, services.ErrOutsideAvailability):
-			c.JSON(http.StatusConflict, gin.H{"error": "requested time is outside coach availability"})
-		case errors.Is(err, services.ErrSessionConflict):
-			c.JSON(http.StatusConflict, gin.H{"error": "requested time conflicts with another session"})
-		default:
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to book session"})
-		}
+	if err := bindJSON(c, &input); err != nil {
+		errmap.RespondBindError(c, err)
+		return
+	}
+	// Conflicts come back with nearby open slots unless the app opts out to save the lookup.
+	input.SkipSuggestedSlots = c.Query("suggest_slots") == "false"
+
+	session, warnings, err := h.sessionService.BookSession(c.Request.Context(), userID, input)
+	if err != nil {
+		errmap.RespondError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, session)
+	response, err := h.sessionService.SessionResponseFor(c.Request.Context(), userID, session)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+	response.Warnings = warnings
+	c.JSON(http.StatusCreated, response)
 }
 
+// ListMySessions returns the client's sessions. Query: start, end, status=a,b and
+// exclude_cancelled=true|false (default true; an explicit status list takes precedence).
 func (h *SessionHandler) ListMySessions(c *gin.Context) {
 	userID, ok := utils.GetUserIDFromContext(c)
 	if !ok {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
 		return
 	}
+	excludeCancelled, ok := parseExcludeCancelled(c)
+	if !ok {
+		return
+	}
 
-	sessions, err := h.sessionService.ListMySessions(c.Request.Context(), userID, c.Query("start"), c.Query("end"))
+	sessions, err := h.sessionService.ListMySessions(c.Request.Context(), userID, c.Query("start"), c.Query("end"), c.Query("status"), excludeCancelled)
 	if err != nil {
-		switch {
-		case errors.Is(err, services.ErrInvalidDateRange), errors.Is(err, services.ErrInvalidDateFormat):
-			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid date range"})
-		default:
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch sessions"})
-		}
+		errmap.RespondError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"data": sessions})
+	h.respondSessions(c, userID, gin.H{}, sessions)
 }
 
+// ListCoachSessions returns the coach's sessions and time blocks, with the same filters as ListMySessions.
 func (h *SessionHandler) ListCoachSessions(c *gin.Context) {
 	userID, ok := utils.GetUserIDFromContext(c)
 	if !ok {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
 		return
 	}
+	excludeCancelled, ok := parseExcludeCancelled(c)
+	if !ok {
+		return
+	}
 
-	sessions, err := h.sessionService.ListCoachSessions(c.Request.Context(), userID, c.Query("start"), c.Query("end"))
+	sessions, err := h.sessionService.ListCoachSessions(c.Request.Context(), userID, c.Query("start"), c.Query("end"), c.Query("status"), excludeCancelled)
 	if err != nil {
-		switch {
-		case errors.Is(err, services.ErrCoachProfileNotFound):
-			c.JSON(http.StatusNotFound, gin.H{"error": "coach profile not found"})
-		case errors.Is(err, services.ErrInvalidDateRange), errors.Is(err, services.ErrInvalidDateFormat):
-			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid date range"})
-		default:
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch sessions"})
-		}
+		errmap.RespondError(c, err)
+		return
+	}
+	// Blocked time is returned alongside sessions so the calendar shows everything that consumes availability.
+	blocks, err := h.sessionService.ListMyTimeBlocks(c.Request.Context(), userID, c.Query("start"), c.Query("end"))
+	if err != nil {
+		errmap.RespondError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"data": sessions})
+	h.respondSessions(c, userID, gin.H{"time_blocks": blocks}, sessions)
+}
+
+// GetSessionSummary returns the coach's session counts for a month. Query: month=YYYY-MM.
+func (h *SessionHandler) GetSessionSummary(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	summary, err := h.sessionService.GetSessionSummary(c.Request.Context(), userID, c.Query("month"))
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, summary)
+}
+
+// parseExcludeCancelled reads the exclude_cancelled query flag, which defaults to true. It writes a
+// 400 and returns false when the value isn't a boolean.
+func parseExcludeCancelled(c *gin.Context) (bool, bool) {
+	switch c.Query("exclude_cancelled") {
+	case "", "true":
+		return true, true
+	case "false":
+		return false, true
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "exclude_cancelled must be true or false"})
+		return false, false
+	}
 }
 
 func (h *SessionHandler) CancelSession(c *gin.Context) {
@@ -409,27 +726,105 @@ func (h *SessionHandler) CancelSession(c *gin.Context) {
 	}
 
 	var input services.CancelSessionInput
-	if err := c.ShouldBindJSON(&input); err != nil && !errors.Is(err, io.EOF) {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+	if err := bindJSON(c, &input); err != nil && !errors.Is(err, io.EOF) {
+		errmap.RespondBindError(c, err)
 		return
 	}
 
 	session, err := h.sessionService.CancelSession(c.Request.Context(), userID, sessionID, input)
 	if err != nil {
-		switch {
-		case errors.Is(err, services.ErrSessionNotFound):
-			c.JSON(http.StatusNotFound, gin.H{"error": "session not found"})
-		case errors.Is(err, services.ErrSessionForbidden):
-			c.JSON(http.StatusForbidden, gin.H{"error": "session does not belong to this user"})
-		case errors.Is(err, services.ErrSessionStateInvalid):
-			c.JSON(http.StatusConflict, gin.H{"error": "session can no longer be cancelled"})
-		default:
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to cancel session"})
-		}
+		errmap.RespondError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, session)
+	h.respondSession(c, http.StatusOK, userID, session)
+}
+
+// ConfirmSession lets the coach accept a pending_confirmation booking.
+func (h *SessionHandler) ConfirmSession(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	sessionID, valid := parseUintPathParam(c.Param("id"))
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid session id"})
+		return
+	}
+
+	session, err := h.sessionService.ConfirmSession(c.Request.Context(), userID, sessionID)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	h.respondSession(c, http.StatusOK, userID, session)
+}
+
+// UpdateSession edits a session's location or notes, optionally for the rest of its recurring series,
+// and reports how many sessions changed.
+func (h *SessionHandler) UpdateSession(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	sessionID, valid := parseUintPathParam(c.Param("id"))
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid session id"})
+		return
+	}
+
+	var input services.UpdateSessionInput
+	if err := bindJSON(c, &input); err != nil {
+		errmap.RespondBindError(c, err)
+		return
+	}
+
+	result, err := h.sessionService.UpdateSession(c.Request.Context(), userID, sessionID, input)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	response, err := h.sessionService.SessionResponseFor(c.Request.Context(), userID, result.Session)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"session": response, "updated_count": result.UpdatedCount})
+}
+
+// DeclineSession lets the coach reject a pending_confirmation booking with an optional reason.
+func (h *SessionHandler) DeclineSession(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	sessionID, valid := parseUintPathParam(c.Param("id"))
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid session id"})
+		return
+	}
+
+	var input services.DeclineSessionInput
+	if err := bindJSON(c, &input); err != nil && !errors.Is(err, io.EOF) {
+		errmap.RespondBindError(c, err)
+		return
+	}
+
+	session, err := h.sessionService.DeclineSession(c.Request.Context(), userID, sessionID, input)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	h.respondSession(c, http.StatusOK, userID, session)
 }
 
 func (h *SessionHandler) CompleteSession(c *gin.Context) {
@@ -447,20 +842,39 @@ func (h *SessionHandler) CompleteSession(c *gin.Context) {
 
 	session, err := h.sessionService.CompleteSession(c.Request.Context(), userID, sessionID)
 	if err != nil {
-		switch {
-		case errors.Is(err, services.ErrSessionNotFound):
-			c.JSON(http.StatusNotFound, gin.H{"error": "session not found"})
-		case errors.Is(err, services.ErrSessionForbidden), errors.Is(err, services.ErrSessionActionForbidden):
-			c.JSON(http.StatusForbidden, gin.H{"error": "only coach can complete this session"})
-		case errors.Is(err, services.ErrSessionStateInvalid):
-			c.JSON(http.StatusConflict, gin.H{"error": "session is not in a completable state"})
-		default:
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to complete session"})
-		}
+		errmap.RespondError(c, err)
+		return
+	}
+
+	h.respondSession(c, http.StatusOK, userID, session)
+}
+
+func (h *SessionHandler) CheckIn(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	sessionID, valid := parseUintPathParam(c.Param("id"))
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid session id"})
 		return
 	}
 
-	c.JSON(http.StatusOK, session)
+	var input services.CheckInSessionInput
+	if err := bindJSON(c, &input); err != nil && !errors.Is(err, io.EOF) {
+		errmap.RespondBindError(c, err)
+		return
+	}
+
+	session, err := h.sessionService.CheckIn(c.Request.Context(), userID, sessionID, input)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	h.respondSession(c, http.StatusOK, userID, session)
 }
 
 func (h *SessionHandler) MarkNoShow(c *gin.Context) {
@@ -478,20 +892,106 @@ func (h *SessionHandler) MarkNoShow(c *gin.Context) {
 
 	session, err := h.sessionService.MarkNoShow(c.Request.Context(), userID, sessionID)
 	if err != nil {
-		switch {
-		case errors.Is(err, services.ErrSessionNotFound):
-			c.JSON(http.StatusNotFound, gin.H{"error": "session not found"})
-		case errors.Is(err, services.ErrSessionForbidden), errors.Is(err, services.ErrSessionActionForbidden):
-			c.JSON(http.StatusForbidden, gin.H{"error": "only coach can mark no-show"})
-		case errors.Is(err, services.ErrSessionStateInvalid):
-			c.JSON(http.StatusConflict, gin.H{"error": "session is not in a no-show state"})
-		default:
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to mark no-show"})
+		errmap.RespondError(c, err)
+		return
+	}
+
+	h.respondSession(c, http.StatusOK, userID, session)
+}
+
+// ResolveStaleSessions records outcomes for several past sessions at once. Items succeed or fail
+// independently; failures carry the same error and code the single-session endpoints return.
+func (h *SessionHandler) ResolveStaleSessions(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var input services.ResolveStaleSessionsInput
+	if err := bindJSON(c, &input); err != nil {
+		errmap.RespondBindError(c, err)
+		return
+	}
+
+	results := h.sessionService.ResolveStaleSessions(c.Request.Context(), userID, input)
+	resolved := make([]models.Session, 0, len(results))
+	for _, result := range results {
+		if result.Err == nil {
+			resolved = append(resolved, *result.Session)
+		}
+	}
+	rendered, err := h.sessionService.SessionResponsesFor(c.Request.Context(), userID, resolved)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	data := make([]gin.H, 0, len(results))
+	for _, result := range results {
+		item := gin.H{"session_id": result.SessionID, "action": result.Action}
+		if result.Err != nil {
+			failure, known := errmap.Describe(c, result.Err)
+			if !known {
+				errmap.RespondError(c, result.Err)
+				return
+			}
+			item["resolved"] = false
+			item["error"] = failure["error"]
+			item["code"] = failure["code"]
+		} else {
+			item["resolved"] = true
+			item["session"] = rendered[0]
+			rendered = rendered[1:]
 		}
+		data = append(data, item)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": data})
+}
+
+// respondSession writes session as the viewer sees it, with start times in their timezone.
+func (h *SessionHandler) respondSession(c *gin.Context, status int, userID uint, session *models.Session) {
+	response, err := h.sessionService.SessionResponseFor(c.Request.Context(), userID, session)
+	if err != nil {
+		errmap.RespondError(c, err)
 		return
 	}
+	c.JSON(status, response)
+}
 
-	c.JSON(http.StatusOK, session)
+// respondSessions writes a session list under "data", merged into body for endpoints that return
+// more alongside it.
+func (h *SessionHandler) respondSessions(c *gin.Context, userID uint, body gin.H, sessions []models.Session) {
+	responses, err := h.sessionService.SessionResponsesFor(c.Request.Context(), userID, sessions)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+	body["data"] = responses
+	c.JSON(http.StatusOK, body)
+}
+
+func (h *SessionHandler) MarkPaid(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	sessionID, valid := parseUintPathParam(c.Param("id"))
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid session id"})
+		return
+	}
+
+	session, err := h.sessionService.MarkSessionPaid(c.Request.Context(), userID, sessionID)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	h.respondSession(c, http.StatusOK, userID, session)
 }
 
 func parseUintPathParam(raw string) (uint, bool) {
@@ -523,3 +1023,81 @@ func parseOptionalIntQuery(raw string) (int, bool, error) {
 	}
 	return value, true, nil
 }
+
+// GetSessionICS returns the session as a single-event calendar file for the OS calendar.
+func (h *SessionHandler) GetSessionICS(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	sessionID, valid := parseUintPathParam(c.Param("id"))
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid session id"})
+		return
+	}
+
+	ics, err := h.sessionService.GetSessionICS(c.Request.Context(), userID, sessionID)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="`+ics.Filename+`"`)
+	c.Header("Cache-Control", "no-store")
+	c.Data(http.StatusOK, calendar.ContentType, []byte(ics.Body))
+}
+
+func (h *SessionHandler) SubmitFeedback(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	sessionID, valid := parseUintPathParam(c.Param("id"))
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid session id"})
+		return
+	}
+
+	var input services.SubmitSessionFeedbackInput
+	if err := bindJSON(c, &input); err != nil {
+		errmap.RespondBindError(c, err)
+		return
+	}
+
+	feedback, err := h.sessionService.SubmitSessionFeedback(c.Request.Context(), userID, sessionID, input)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, feedback)
+}
+
+// ListMyFeedback returns the coach's session feedback, newest first.
+func (h *SessionHandler) ListMyFeedback(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	limit := parseQueryInt(c.DefaultQuery("limit", "20"), 20)
+	offset := parseQueryInt(c.DefaultQuery("offset", "0"), 0)
+
+	feedback, total, err := h.sessionService.ListMyFeedback(c.Request.Context(), userID, limit, offset)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":   feedback,
+		"total":  total,
+		"limit":  limit,
+		"offset": offset,
+	})
+}
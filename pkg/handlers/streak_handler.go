@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"chalk-api/pkg/handlers/errmap"
+	"chalk-api/pkg/services"
+	"chalk-api/pkg/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type StreakHandler struct {
+	streakService *services.StreakService
+}
+
+func NewStreakHandler(streakService *services.StreakService) *StreakHandler {
+	return &StreakHandler{streakService: streakService}
+}
+
+// GetMyStreaks returns the client's weekly workout streak and the milestones they've reached.
+func (h *StreakHandler) GetMyStreaks(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	streaks, err := h.streakService.GetMyStreaks(c.Request.Context(), userID)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, streaks)
+}
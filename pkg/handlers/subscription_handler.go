@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"chalk-api/pkg/middleware"
 	"chalk-api/pkg/services"
 	"chalk-api/pkg/utils"
 	"errors"
@@ -29,6 +30,7 @@ func (h *SubscriptionHandler) RevenueCatWebhook(c *gin.Context) {
 		c.Request.Context(),
 		body,
 		c.GetHeader("Authorization"),
+		webhookInboxHeaders(c),
 	); err != nil {
 		switch {
 		case errors.Is(err, services.ErrInvalidSubscriptionWebhookAuth):
@@ -44,6 +46,21 @@ func (h *SubscriptionHandler) RevenueCatWebhook(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"status": "ok"})
 }
 
+// webhookInboxHeaders picks the request headers kept with a stored webhook delivery. Credentials
+// such as Authorization are deliberately left out.
+func webhookInboxHeaders(c *gin.Context) map[string]string {
+	headers := map[string]string{}
+	for _, name := range []string{"Content-Type", "User-Agent"} {
+		if value := c.GetHeader(name); value != "" {
+			headers[name] = value
+		}
+	}
+	if requestID := c.GetString(middleware.RequestIDKey); requestID != "" {
+		headers[middleware.RequestIDHeader] = requestID
+	}
+	return headers
+}
+
 func (h *SubscriptionHandler) GetMySubscription(c *gin.Context) {
 	userID, ok := utils.GetUserIDFromContext(c)
 	if !ok {
@@ -1,9 +1,11 @@
 package handlers
 
 import (
+	"chalk-api/pkg/handlers/errmap"
 	"chalk-api/pkg/services"
 	"chalk-api/pkg/utils"
 	"errors"
+	"io"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -26,11 +28,7 @@ func (h *UserHandler) GetMe(c *gin.Context) {
 
 	user, err := h.userService.GetMe(c.Request.Context(), userID)
 	if err != nil {
-		if errors.Is(err, services.ErrUserNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch user"})
+		errmap.RespondError(c, err)
 		return
 	}
 
@@ -45,18 +43,62 @@ func (h *UserHandler) UpdateMe(c *gin.Context) {
 	}
 
 	var input services.UpdateMeInput
-	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+	if err := bindJSON(c, &input); err != nil {
+		errmap.RespondBindError(c, err)
 		return
 	}
 
 	user, err := h.userService.UpdateMe(c.Request.Context(), userID, input)
 	if err != nil {
-		if errors.Is(err, services.ErrUserNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// UploadAvatar accepts a multipart "avatar" file; the service resizes it before storing.
+func (h *UserHandler) UploadAvatar(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	// Leave headroom for multipart framing so a file right at the limit is still accepted.
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, services.MaxAvatarBytes+(1<<20))
+
+	fileHeader, err := c.FormFile("avatar")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			errmap.RespondError(c, services.ErrUploadTooLarge)
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update profile"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "avatar file is required"})
+		return
+	}
+	if fileHeader.Size > services.MaxAvatarBytes {
+		errmap.RespondError(c, services.ErrUploadTooLarge)
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "avatar file is unreadable"})
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, services.MaxAvatarBytes+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "avatar file is unreadable"})
+		return
+	}
+
+	user, err := h.userService.UploadAvatar(c.Request.Context(), userID, data)
+	if err != nil {
+		errmap.RespondError(c, err)
 		return
 	}
 
@@ -78,3 +120,58 @@ func (h *UserHandler) GetCapabilities(c *gin.Context) {
 
 	c.JSON(http.StatusOK, capabilities)
 }
+
+// RequestDataExport queues an export of everything we hold about the user; poll the status endpoint for the link.
+func (h *UserHandler) RegisterDeviceToken(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var input services.RegisterDeviceTokenInput
+	if err := bindJSON(c, &input); err != nil {
+		errmap.RespondBindError(c, err)
+		return
+	}
+
+	deviceToken, err := h.userService.RegisterDeviceToken(c.Request.Context(), userID, input)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, deviceToken)
+}
+
+func (h *UserHandler) RequestDataExport(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	status, err := h.userService.RequestDataExport(c.Request.Context(), userID)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, status)
+}
+
+func (h *UserHandler) GetDataExportStatus(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	status, err := h.userService.GetDataExportStatus(c.Request.Context(), userID)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
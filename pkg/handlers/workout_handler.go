@@ -1,11 +1,17 @@
 package handlers
 
 import (
+	"bytes"
+	"chalk-api/pkg/export"
+	"chalk-api/pkg/handlers/errmap"
+	"chalk-api/pkg/pdf"
 	"chalk-api/pkg/services"
 	"chalk-api/pkg/utils"
 	"errors"
+	"io"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
@@ -26,19 +32,14 @@ func (h *WorkoutHandler) CreateTemplate(c *gin.Context) {
 	}
 
 	var input services.CreateWorkoutTemplateInput
-	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+	if err := bindJSON(c, &input); err != nil {
+		errmap.RespondBindError(c, err)
 		return
 	}
 
 	template, err := h.workoutService.CreateTemplate(c.Request.Context(), userID, input)
 	if err != nil {
-		switch {
-		case errors.Is(err, services.ErrCoachProfileNotFound):
-			c.JSON(http.StatusNotFound, gin.H{"error": "coach profile not found"})
-		default:
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create template"})
-		}
+		errmap.RespondError(c, err)
 		return
 	}
 
@@ -55,22 +56,33 @@ func (h *WorkoutHandler) ListMyTemplates(c *gin.Context) {
 	limit := parseQueryInt(c.DefaultQuery("limit", "20"), 20)
 	offset := parseQueryInt(c.DefaultQuery("offset", "0"), 0)
 
-	templates, total, err := h.workoutService.ListMyTemplates(c.Request.Context(), userID, limit, offset)
-	if err != nil {
-		switch {
-		case errors.Is(err, services.ErrCoachProfileNotFound):
-			c.JSON(http.StatusNotFound, gin.H{"error": "coach profile not found"})
-		default:
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list templates"})
+	// category_id=none lists uncategorized templates
+	var filter services.TemplateListFilter
+	if raw := c.Query("category_id"); raw == "none" {
+		filter.Uncategorized = true
+	} else {
+		categoryID, hasCategory, err := parseOptionalUintQuery(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid category_id"})
+			return
 		}
+		if hasCategory {
+			filter.CategoryID = &categoryID
+		}
+	}
+
+	result, err := h.workoutService.ListMyTemplates(c.Request.Context(), userID, filter, limit, offset)
+	if err != nil {
+		errmap.RespondError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"data":   templates,
-		"total":  total,
-		"limit":  limit,
-		"offset": offset,
+		"data":            result.Templates,
+		"total":           result.Total,
+		"limit":           limit,
+		"offset":          offset,
+		"category_facets": result.Categories,
 	})
 }
 
@@ -89,20 +101,54 @@ func (h *WorkoutHandler) GetMyTemplate(c *gin.Context) {
 
 	template, err := h.workoutService.GetMyTemplate(c.Request.Context(), userID, templateID)
 	if err != nil {
-		switch {
-		case errors.Is(err, services.ErrCoachProfileNotFound), errors.Is(err, services.ErrTemplateNotFound):
-			c.JSON(http.StatusNotFound, gin.H{"error": "template not found"})
-		case errors.Is(err, services.ErrTemplateForbidden):
-			c.JSON(http.StatusForbidden, gin.H{"error": "template does not belong to this coach"})
-		default:
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch template"})
-		}
+		errmap.RespondError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, template)
 }
 
+// ExportTemplate downloads a template as a printable PDF (the default) or a CSV.
+func (h *WorkoutHandler) ExportTemplate(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	templateID, valid := parseUintParam(c.Param("id"))
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid template id"})
+		return
+	}
+
+	format := c.DefaultQuery("format", "pdf")
+	if format != "pdf" && format != "csv" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be pdf or csv"})
+		return
+	}
+
+	sheet, err := h.workoutService.ExportTemplate(c.Request.Context(), userID, templateID)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="`+sheet.Filename(format)+`"`)
+	c.Header("Cache-Control", "no-store")
+	if format == "pdf" {
+		c.Data(http.StatusOK, pdf.ContentType, sheet.PDF())
+		return
+	}
+
+	var body bytes.Buffer
+	if err := sheet.WriteCSV(&body); err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+	c.Data(http.StatusOK, export.CSVContentType, body.Bytes())
+}
+
 func (h *WorkoutHandler) UpdateMyTemplate(c *gin.Context) {
 	userID, ok := utils.GetUserIDFromContext(c)
 	if !ok {
@@ -117,27 +163,165 @@ func (h *WorkoutHandler) UpdateMyTemplate(c *gin.Context) {
 	}
 
 	var input services.UpdateWorkoutTemplateInput
-	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+	if err := bindJSON(c, &input); err != nil {
+		errmap.RespondBindError(c, err)
 		return
 	}
 
+	if input.Version == nil {
+		version, ok := ifMatchVersion(c)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "If-Match must be a version number"})
+			return
+		}
+		input.Version = version
+	}
+
 	template, err := h.workoutService.UpdateMyTemplate(c.Request.Context(), userID, templateID, input)
 	if err != nil {
-		switch {
-		case errors.Is(err, services.ErrCoachProfileNotFound), errors.Is(err, services.ErrTemplateNotFound):
-			c.JSON(http.StatusNotFound, gin.H{"error": "template not found"})
-		case errors.Is(err, services.ErrTemplateForbidden):
-			c.JSON(http.StatusForbidden, gin.H{"error": "template does not belong to this coach"})
-		default:
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update template"})
-		}
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, template)
+}
+
+func (h *WorkoutHandler) ListTemplateVersions(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	templateID, valid := parseUintParam(c.Param("id"))
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid template id"})
+		return
+	}
+
+	versions, err := h.workoutService.ListMyTemplateVersions(c.Request.Context(), userID, templateID)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": versions})
+}
+
+func (h *WorkoutHandler) RestoreTemplateVersion(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	templateID, valid := parseUintParam(c.Param("id"))
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid template id"})
+		return
+	}
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil || version <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid version"})
+		return
+	}
+
+	template, err := h.workoutService.RestoreMyTemplateVersion(c.Request.Context(), userID, templateID, version)
+	if err != nil {
+		errmap.RespondError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, template)
 }
 
+func (h *WorkoutHandler) CreateTemplateCategory(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var input services.TemplateCategoryInput
+	if err := bindJSON(c, &input); err != nil {
+		errmap.RespondBindError(c, err)
+		return
+	}
+
+	category, err := h.workoutService.CreateMyTemplateCategory(c.Request.Context(), userID, input)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, category)
+}
+
+func (h *WorkoutHandler) ListTemplateCategories(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	categories, err := h.workoutService.ListMyTemplateCategories(c.Request.Context(), userID)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": categories})
+}
+
+func (h *WorkoutHandler) UpdateTemplateCategory(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	categoryID, valid := parseUintParam(c.Param("id"))
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid category id"})
+		return
+	}
+
+	var input services.TemplateCategoryInput
+	if err := bindJSON(c, &input); err != nil {
+		errmap.RespondBindError(c, err)
+		return
+	}
+
+	category, err := h.workoutService.RenameMyTemplateCategory(c.Request.Context(), userID, categoryID, input)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, category)
+}
+
+func (h *WorkoutHandler) DeleteTemplateCategory(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	categoryID, valid := parseUintParam(c.Param("id"))
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid category id"})
+		return
+	}
+
+	if err := h.workoutService.DeleteMyTemplateCategory(c.Request.Context(), userID, categoryID); err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "template category deleted"})
+}
+
 func (h *WorkoutHandler) AssignWorkout(c *gin.Context) {
 	userID, ok := utils.GetUserIDFromContext(c)
 	if !ok {
@@ -146,29 +330,14 @@ func (h *WorkoutHandler) AssignWorkout(c *gin.Context) {
 	}
 
 	var input services.AssignWorkoutInput
-	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+	if err := bindJSON(c, &input); err != nil {
+		errmap.RespondBindError(c, err)
 		return
 	}
 
 	workout, err := h.workoutService.AssignTemplateToClient(c.Request.Context(), userID, input)
 	if err != nil {
-		switch {
-		case errors.Is(err, services.ErrCoachProfileNotFound):
-			c.JSON(http.StatusNotFound, gin.H{"error": "coach profile not found"})
-		case errors.Is(err, services.ErrTemplateNotFound):
-			c.JSON(http.StatusNotFound, gin.H{"error": "template not found"})
-		case errors.Is(err, services.ErrTemplateForbidden):
-			c.JSON(http.StatusForbidden, gin.H{"error": "template does not belong to this coach"})
-		case errors.Is(err, services.ErrClientProfileNotFound):
-			c.JSON(http.StatusNotFound, gin.H{"error": "client profile not found"})
-		case errors.Is(err, services.ErrClientProfileForbidden):
-			c.JSON(http.StatusForbidden, gin.H{"error": "client profile does not belong to this coach"})
-		case errors.Is(err, services.ErrInvalidScheduledDate):
-			c.JSON(http.StatusBadRequest, gin.H{"error": "scheduled_date must be YYYY-MM-DD"})
-		default:
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to assign workout"})
-		}
+		errmap.RespondError(c, err)
 		return
 	}
 
@@ -187,7 +356,7 @@ func (h *WorkoutHandler) ListMyWorkouts(c *gin.Context) {
 
 	workouts, total, err := h.workoutService.ListMyWorkouts(c.Request.Context(), userID, limit, offset)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list workouts"})
+		errmap.RespondError(c, err)
 		return
 	}
 
@@ -212,16 +381,15 @@ func (h *WorkoutHandler) GetMyWorkout(c *gin.Context) {
 		return
 	}
 
-	workout, err := h.workoutService.GetMyWorkout(c.Request.Context(), userID, workoutID)
+	preferredUnits, valid := parseUnitsQuery(c)
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "units must be preferred or stored"})
+		return
+	}
+
+	workout, err := h.workoutService.GetMyWorkoutDetail(c.Request.Context(), userID, workoutID, parseEquipmentQuery(c), preferredUnits)
 	if err != nil {
-		switch {
-		case errors.Is(err, services.ErrWorkoutNotFound):
-			c.JSON(http.StatusNotFound, gin.H{"error": "workout not found"})
-		case errors.Is(err, services.ErrWorkoutForbidden):
-			c.JSON(http.StatusForbidden, gin.H{"error": "workout does not belong to this user"})
-		default:
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch workout"})
-		}
+		errmap.RespondError(c, err)
 		return
 	}
 
@@ -243,16 +411,44 @@ func (h *WorkoutHandler) StartMyWorkout(c *gin.Context) {
 
 	workout, err := h.workoutService.StartMyWorkout(c.Request.Context(), userID, workoutID)
 	if err != nil {
-		switch {
-		case errors.Is(err, services.ErrWorkoutNotFound):
-			c.JSON(http.StatusNotFound, gin.H{"error": "workout not found"})
-		case errors.Is(err, services.ErrWorkoutForbidden):
-			c.JSON(http.StatusForbidden, gin.H{"error": "workout does not belong to this user"})
-		case errors.Is(err, services.ErrInvalidWorkoutState):
-			c.JSON(http.StatusConflict, gin.H{"error": "workout is already finalized"})
-		default:
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to start workout"})
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, workout)
+}
+
+// UpdateMyWorkoutState replaces the resumable state of an unfinished workout (max 8KB encoded).
+func (h *WorkoutHandler) UpdateMyWorkoutState(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	workoutID, valid := parseUintParam(c.Param("id"))
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid workout id"})
+		return
+	}
+
+	// The stored state is re-encoded and checked against the limit; this only stops oversized bodies early.
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, 2*services.MaxWorkoutStateBytes)
+
+	var input services.UpdateWorkoutStateInput
+	if err := bindJSON(c, &input); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			errmap.RespondError(c, services.ErrWorkoutStateTooLarge)
+			return
 		}
+		errmap.RespondBindError(c, err)
+		return
+	}
+
+	workout, err := h.workoutService.UpdateMyWorkoutState(c.Request.Context(), userID, workoutID, input)
+	if err != nil {
+		errmap.RespondError(c, err)
 		return
 	}
 
@@ -272,24 +468,51 @@ func (h *WorkoutHandler) CompleteMyWorkout(c *gin.Context) {
 		return
 	}
 
-	workout, err := h.workoutService.CompleteMyWorkout(c.Request.Context(), userID, workoutID)
+	// The body is optional; a bare POST completes the workout without a note or photo.
+	var input services.CompleteWorkoutInput
+	if err := bindJSON(c, &input); err != nil && !errors.Is(err, io.EOF) {
+		errmap.RespondBindError(c, err)
+		return
+	}
+
+	workout, err := h.workoutService.CompleteMyWorkout(c.Request.Context(), userID, workoutID, input)
 	if err != nil {
-		switch {
-		case errors.Is(err, services.ErrWorkoutNotFound):
-			c.JSON(http.StatusNotFound, gin.H{"error": "workout not found"})
-		case errors.Is(err, services.ErrWorkoutForbidden):
-			c.JSON(http.StatusForbidden, gin.H{"error": "workout does not belong to this user"})
-		case errors.Is(err, services.ErrInvalidWorkoutState):
-			c.JSON(http.StatusConflict, gin.H{"error": "workout is already finalized"})
-		default:
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to complete workout"})
-		}
+		errmap.RespondError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, workout)
 }
 
+// CreateCompletionPhotoUpload returns a presigned PUT for a completion photo (jpeg, png or webp, up to 10MB).
+func (h *WorkoutHandler) CreateCompletionPhotoUpload(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	workoutID, valid := parseUintParam(c.Param("id"))
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid workout id"})
+		return
+	}
+
+	var input services.CompletionPhotoUploadInput
+	if err := bindJSON(c, &input); err != nil {
+		errmap.RespondBindError(c, err)
+		return
+	}
+
+	upload, err := h.workoutService.CreateCompletionPhotoUpload(c.Request.Context(), userID, workoutID, input)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, upload)
+}
+
 func (h *WorkoutHandler) MarkExerciseCompleted(c *gin.Context) {
 	userID, ok := utils.GetUserIDFromContext(c)
 	if !ok {
@@ -305,16 +528,7 @@ func (h *WorkoutHandler) MarkExerciseCompleted(c *gin.Context) {
 
 	exercise, err := h.workoutService.MarkMyExerciseCompleted(c.Request.Context(), userID, exerciseID)
 	if err != nil {
-		switch {
-		case errors.Is(err, services.ErrWorkoutExerciseNotFound):
-			c.JSON(http.StatusNotFound, gin.H{"error": "workout exercise not found"})
-		case errors.Is(err, services.ErrWorkoutNotFound):
-			c.JSON(http.StatusNotFound, gin.H{"error": "workout not found"})
-		case errors.Is(err, services.ErrWorkoutForbidden):
-			c.JSON(http.StatusForbidden, gin.H{"error": "workout does not belong to this user"})
-		default:
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to mark exercise completed"})
-		}
+		errmap.RespondError(c, err)
 		return
 	}
 
@@ -335,23 +549,14 @@ func (h *WorkoutHandler) SkipExercise(c *gin.Context) {
 	}
 
 	var input services.SkipWorkoutExerciseInput
-	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+	if err := bindJSON(c, &input); err != nil {
+		errmap.RespondBindError(c, err)
 		return
 	}
 
 	exercise, err := h.workoutService.SkipMyExercise(c.Request.Context(), userID, exerciseID, input)
 	if err != nil {
-		switch {
-		case errors.Is(err, services.ErrWorkoutExerciseNotFound):
-			c.JSON(http.StatusNotFound, gin.H{"error": "workout exercise not found"})
-		case errors.Is(err, services.ErrWorkoutNotFound):
-			c.JSON(http.StatusNotFound, gin.H{"error": "workout not found"})
-		case errors.Is(err, services.ErrWorkoutForbidden):
-			c.JSON(http.StatusForbidden, gin.H{"error": "workout does not belong to this user"})
-		default:
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to skip exercise"})
-		}
+		errmap.RespondError(c, err)
 		return
 	}
 
@@ -372,23 +577,14 @@ func (h *WorkoutHandler) CreateExerciseLog(c *gin.Context) {
 	}
 
 	var input services.CreateWorkoutLogInput
-	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+	if err := bindJSON(c, &input); err != nil {
+		errmap.RespondBindError(c, err)
 		return
 	}
 
 	logEntry, err := h.workoutService.CreateMyExerciseLog(c.Request.Context(), userID, exerciseID, input)
 	if err != nil {
-		switch {
-		case errors.Is(err, services.ErrWorkoutExerciseNotFound):
-			c.JSON(http.StatusNotFound, gin.H{"error": "workout exercise not found"})
-		case errors.Is(err, services.ErrWorkoutNotFound):
-			c.JSON(http.StatusNotFound, gin.H{"error": "workout not found"})
-		case errors.Is(err, services.ErrWorkoutForbidden):
-			c.JSON(http.StatusForbidden, gin.H{"error": "workout does not belong to this user"})
-		default:
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create workout log"})
-		}
+		errmap.RespondError(c, err)
 		return
 	}
 
@@ -409,31 +605,226 @@ func (h *WorkoutHandler) UpdateWorkoutLog(c *gin.Context) {
 	}
 
 	var input services.UpdateWorkoutLogInput
-	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+	if err := bindJSON(c, &input); err != nil {
+		errmap.RespondBindError(c, err)
 		return
 	}
 
 	logEntry, err := h.workoutService.UpdateMyWorkoutLog(c.Request.Context(), userID, logID, input)
 	if err != nil {
-		switch {
-		case errors.Is(err, services.ErrWorkoutLogNotFound):
-			c.JSON(http.StatusNotFound, gin.H{"error": "workout log not found"})
-		case errors.Is(err, services.ErrWorkoutExerciseNotFound):
-			c.JSON(http.StatusNotFound, gin.H{"error": "workout exercise not found"})
-		case errors.Is(err, services.ErrWorkoutNotFound):
-			c.JSON(http.StatusNotFound, gin.H{"error": "workout not found"})
-		case errors.Is(err, services.ErrWorkoutForbidden):
-			c.JSON(http.StatusForbidden, gin.H{"error": "workout does not belong to this user"})
-		default:
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update workout log"})
-		}
+		errmap.RespondError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, logEntry)
 }
 
+// CreateFormCheckUpload returns a presigned PUT for a form video (mp4 or mov, up to 200MB) on a logged set.
+func (h *WorkoutHandler) CreateFormCheckUpload(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	logID, valid := parseUintParam(c.Param("id"))
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid workout log id"})
+		return
+	}
+
+	var input services.FormCheckUploadInput
+	if err := bindJSON(c, &input); err != nil {
+		errmap.RespondBindError(c, err)
+		return
+	}
+
+	upload, err := h.workoutService.CreateFormCheckUpload(c.Request.Context(), userID, logID, input)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, upload)
+}
+
+func (h *WorkoutHandler) SetFormCheckVideo(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	logID, valid := parseUintParam(c.Param("id"))
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid workout log id"})
+		return
+	}
+
+	var input services.SetFormCheckVideoInput
+	if err := bindJSON(c, &input); err != nil {
+		errmap.RespondBindError(c, err)
+		return
+	}
+
+	logEntry, err := h.workoutService.SetFormCheckVideo(c.Request.Context(), userID, logID, input)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, logEntry)
+}
+
+// ListFormChecks returns the coach's unreviewed form videos, oldest first.
+func (h *WorkoutHandler) ListFormChecks(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	limit := parseQueryInt(c.DefaultQuery("limit", "20"), 20)
+	offset := parseQueryInt(c.DefaultQuery("offset", "0"), 0)
+
+	checks, total, err := h.workoutService.ListMyFormChecks(c.Request.Context(), userID, limit, offset)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":   checks,
+		"total":  total,
+		"limit":  limit,
+		"offset": offset,
+	})
+}
+
+func (h *WorkoutHandler) ReviewFormCheck(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	logID, valid := parseUintParam(c.Param("id"))
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid workout log id"})
+		return
+	}
+
+	var input services.FormFeedbackInput
+	if err := bindJSON(c, &input); err != nil {
+		errmap.RespondBindError(c, err)
+		return
+	}
+
+	logEntry, err := h.workoutService.ReviewFormCheck(c.Request.Context(), userID, logID, input)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, logEntry)
+}
+
+func (h *WorkoutHandler) ListExerciseAlternatives(c *gin.Context) {
+	if _, ok := utils.GetUserIDFromContext(c); !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	exerciseID, valid := parseUintParam(c.Param("id"))
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid exercise id"})
+		return
+	}
+
+	alternatives, err := h.workoutService.ListExerciseAlternatives(c.Request.Context(), exerciseID, parseEquipmentQuery(c))
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": alternatives})
+}
+
+func (h *WorkoutHandler) AddExerciseAlternative(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	exerciseID, valid := parseUintParam(c.Param("id"))
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid exercise id"})
+		return
+	}
+
+	var input services.ExerciseAlternativeInput
+	if err := bindJSON(c, &input); err != nil {
+		errmap.RespondBindError(c, err)
+		return
+	}
+
+	alternatives, err := h.workoutService.AddExerciseAlternative(c.Request.Context(), userID, exerciseID, input)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": alternatives})
+}
+
+func (h *WorkoutHandler) RemoveExerciseAlternative(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	exerciseID, valid := parseUintParam(c.Param("id"))
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid exercise id"})
+		return
+	}
+	alternativeID, valid := parseUintParam(c.Param("alternative_id"))
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid alternative exercise id"})
+		return
+	}
+
+	if err := h.workoutService.RemoveExerciseAlternative(c.Request.Context(), userID, exerciseID, alternativeID); err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "exercise alternative removed"})
+}
+
+// parseEquipmentQuery reads ?equipment=dumbbells,bench (repeatable); nil when absent.
+func parseEquipmentQuery(c *gin.Context) []string {
+	var equipment []string
+	for _, raw := range c.QueryArray("equipment") {
+		equipment = append(equipment, strings.Split(raw, ",")...)
+	}
+	return equipment
+}
+
+// parseUnitsQuery reads ?units=preferred, which converts weights and distances into the viewer's
+// preferred units; "stored" or no value keeps them as logged. Other values report false.
+func parseUnitsQuery(c *gin.Context) (preferred bool, ok bool) {
+	switch c.Query("units") {
+	case "", "stored":
+		return false, true
+	case "preferred":
+		return true, true
+	}
+	return false, false
+}
+
 func parseUintParam(raw string) (uint, bool) {
 	id, err := strconv.ParseUint(raw, 10, 64)
 	if err != nil || id == 0 {
@@ -449,3 +840,85 @@ func parseQueryInt(raw string, fallback int) int {
 	}
 	return parsed
 }
+
+func (h *WorkoutHandler) ListClientExerciseDefaults(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	clientProfileID, valid := parseUintParam(c.Param("id"))
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid client id"})
+		return
+	}
+
+	defaults, err := h.workoutService.ListClientExerciseDefaults(c.Request.Context(), userID, clientProfileID)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": defaults})
+}
+
+// SetClientExerciseDefault creates or replaces the client's default for the exercise in the path.
+func (h *WorkoutHandler) SetClientExerciseDefault(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	clientProfileID, valid := parseUintParam(c.Param("id"))
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid client id"})
+		return
+	}
+	exerciseID, valid := parseUintParam(c.Param("exercise_id"))
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid exercise id"})
+		return
+	}
+
+	var input services.SetClientExerciseDefaultInput
+	if err := bindJSON(c, &input); err != nil {
+		errmap.RespondBindError(c, err)
+		return
+	}
+
+	exerciseDefault, err := h.workoutService.SetClientExerciseDefault(c.Request.Context(), userID, clientProfileID, exerciseID, input)
+	if err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, exerciseDefault)
+}
+
+func (h *WorkoutHandler) DeleteClientExerciseDefault(c *gin.Context) {
+	userID, ok := utils.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	clientProfileID, valid := parseUintParam(c.Param("id"))
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid client id"})
+		return
+	}
+	exerciseID, valid := parseUintParam(c.Param("exercise_id"))
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid exercise id"})
+		return
+	}
+
+	if err := h.workoutService.DeleteClientExerciseDefault(c.Request.Context(), userID, clientProfileID, exerciseID); err != nil {
+		errmap.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "client exercise default deleted"})
+}
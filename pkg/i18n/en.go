@@ -0,0 +1,58 @@
+package i18n
+
+// en is the source catalog. API error messages are not listed here: errmap's registry holds their
+// English text, and other catalogs translate them under "error.<code>".
+var en = map[string]string{
+	// Push notifications
+	"push.workout_assigned.title":           "New workout assigned",
+	"push.workout_assigned.body":            "Your coach assigned you %s.",
+	"push.workout_assigned.body_dated":      "Your coach assigned you %s for %s.",
+	"push.workout_assigned.unnamed":         "a workout",
+	"push.workout_assigned.body_coach":      "%s assigned you a workout: %s",
+	"push.message.title":                    "New message",
+	"push.message.body":                     "You have a new message",
+	"push.workout_reminder.title":           "Workout today",
+	"push.workout_reminder.body":            "%s is on the plan today",
+	"push.workout_reminder.unnamed":         "Your workout",
+	"push.workout_pending.title":            "Workout pending",
+	"push.workout_pending.body":             "You still have %s pending",
+	"push.workout_pending.unnamed":          "a workout",
+	"push.session_reminder.title":           "Session reminder",
+	"push.session_reminder.body":            "Your session with %s starts in 1 hour",
+	"push.new_login.title":                  "New login to your account",
+	"push.new_login.body":                   "New login from %s, %s. Was this you?",
+	"push.new_login.body_no_location":       "New login from %s. Was this you?",
+	"push.new_login.unknown_device":         "a new device",
+	"push.referral_completed.title":         "Referral complete",
+	"push.referral_completed.body":          "%s finished setting up with your referral code.",
+	"push.referral_completed.unnamed":       "A coach you referred",
+	"push.milestone_reached.title":          "Milestone reached",
+	"push.milestone_reached.body":           "You just completed your %dth workout. Keep it going!",
+	"push.milestone_reached.coach_title":    "Client milestone",
+	"push.milestone_reached.coach_body":     "%s just completed their %dth workout.",
+	"push.milestone_reached.unnamed_client": "A client",
+	"push.client_invited.title":             "New coaching invitation",
+	"push.client_invited.body":              "%s invited you to train with them.",
+
+	// Dates: weekday, month name, day of month
+	"date.short":     "%[1]s, %[2]s %[3]d",
+	"date.weekday.0": "Sun",
+	"date.weekday.1": "Mon",
+	"date.weekday.2": "Tue",
+	"date.weekday.3": "Wed",
+	"date.weekday.4": "Thu",
+	"date.weekday.5": "Fri",
+	"date.weekday.6": "Sat",
+	"date.month.1":   "Jan",
+	"date.month.2":   "Feb",
+	"date.month.3":   "Mar",
+	"date.month.4":   "Apr",
+	"date.month.5":   "May",
+	"date.month.6":   "Jun",
+	"date.month.7":   "Jul",
+	"date.month.8":   "Aug",
+	"date.month.9":   "Sep",
+	"date.month.10":  "Oct",
+	"date.month.11":  "Nov",
+	"date.month.12":  "Dec",
+}
@@ -0,0 +1,290 @@
+package i18n
+
+var es = map[string]string{
+	// Push notifications
+	"push.workout_assigned.title":           "Nuevo entrenamiento asignado",
+	"push.workout_assigned.body":            "Tu coach te asignó %s.",
+	"push.workout_assigned.body_dated":      "Tu coach te asignó %s para el %s.",
+	"push.workout_assigned.unnamed":         "un entrenamiento",
+	"push.workout_assigned.body_coach":      "%s te asignó un entrenamiento: %s",
+	"push.message.title":                    "Nuevo mensaje",
+	"push.message.body":                     "Tienes un mensaje nuevo",
+	"push.workout_reminder.title":           "Entrenamiento de hoy",
+	"push.workout_reminder.body":            "%s está en el plan de hoy",
+	"push.workout_reminder.unnamed":         "Tu entrenamiento",
+	"push.workout_pending.title":            "Entrenamiento pendiente",
+	"push.workout_pending.body":             "Todavía tienes %s pendiente",
+	"push.workout_pending.unnamed":          "un entrenamiento",
+	"push.session_reminder.title":           "Recordatorio de sesión",
+	"push.session_reminder.body":            "Tu sesión con %s empieza en 1 hora",
+	"push.new_login.title":                  "Nuevo inicio de sesión en tu cuenta",
+	"push.new_login.body":                   "Nuevo inicio de sesión desde %s, %s. ¿Fuiste tú?",
+	"push.new_login.body_no_location":       "Nuevo inicio de sesión desde %s. ¿Fuiste tú?",
+	"push.new_login.unknown_device":         "un dispositivo nuevo",
+	"push.referral_completed.title":         "Referido completado",
+	"push.referral_completed.body":          "%s terminó de configurar su cuenta con tu código de referido.",
+	"push.referral_completed.unnamed":       "Un coach que referiste",
+	"push.milestone_reached.title":          "Meta alcanzada",
+	"push.milestone_reached.body":           "Acabas de completar tu entrenamiento número %d. ¡Sigue así!",
+	"push.milestone_reached.coach_title":    "Meta de cliente",
+	"push.milestone_reached.coach_body":     "%s acaba de completar su entrenamiento número %d.",
+	"push.milestone_reached.unnamed_client": "Un cliente",
+	"push.client_invited.title":             "Nueva invitación de coaching",
+	"push.client_invited.body":              "%s te invitó a ser su cliente.",
+
+	"date.short":     "%[1]s %[3]d %[2]s",
+	"date.weekday.0": "dom",
+	"date.weekday.1": "lun",
+	"date.weekday.2": "mar",
+	"date.weekday.3": "mié",
+	"date.weekday.4": "jue",
+	"date.weekday.5": "vie",
+	"date.weekday.6": "sáb",
+	"date.month.1":   "ene",
+	"date.month.2":   "feb",
+	"date.month.3":   "mar",
+	"date.month.4":   "abr",
+	"date.month.5":   "may",
+	"date.month.6":   "jun",
+	"date.month.7":   "jul",
+	"date.month.8":   "ago",
+	"date.month.9":   "sep",
+	"date.month.10":  "oct",
+	"date.month.11":  "nov",
+	"date.month.12":  "dic",
+
+	// API errors, by code
+	"error.internal_error":         "error interno del servidor",
+	"error.invalid_request_body":   "el cuerpo de la solicitud no es válido",
+	"error.validation_failed":      "el cuerpo de la solicitud no es válido",
+	"error.request_body_too_large": "el cuerpo de la solicitud es demasiado grande",
+
+	// Shared / profiles
+	"error.user_not_found":             "usuario no encontrado",
+	"error.coach_profile_not_found":    "perfil de coach no encontrado",
+	"error.client_profile_not_found":   "perfil de cliente no encontrado",
+	"error.client_profile_forbidden":   "el perfil de cliente no pertenece a este coach",
+	"error.client_profile_required":    "client_profile_id es obligatorio",
+	"error.client_profile_invalid":     "el perfil de cliente no pertenece a este usuario",
+	"error.invalid_timezone":           "timezone debe ser un nombre IANA válido (p. ej. America/Mexico_City)",
+	"error.invalid_locale":             "locale debe ser uno de: en, es",
+	"error.invalid_unit":               "weight_unit debe ser kg o lbs y distance_unit km o mi",
+	"error.invalid_reminder_hour":      "las horas de recordatorio deben estar entre 0 y 23 y el recordatorio de la tarde debe ser después del de la mañana",
+	"error.profile_name_required":      "first_name y last_name no pueden estar vacíos",
+	"error.invalid_brand_color":        "brand_color debe ser un color hexadecimal como #1A2B3C",
+	"error.invalid_coach_slug":         "el slug debe tener de 3 a 50 letras minúsculas, dígitos o guiones",
+	"error.coach_slug_taken":           "este slug ya está en uso",
+	"error.version_conflict":           "alguien más modificó esto; recarga e inténtalo de nuevo",
+	"error.invalid_client_filter":      "status debe ser active, paused o archived, sort debe ser created_at o last_activity_at y tag_match debe ser any o all",
+	"error.invalid_client_tag":         "las etiquetas deben tener entre 1 y 30 caracteres",
+	"error.too_many_client_tags":       "un cliente puede tener como máximo 20 etiquetas",
+	"error.client_note_invalid":        "el texto de la nota es obligatorio",
+	"error.client_note_not_found":      "nota del cliente no encontrada",
+	"error.invalid_client_import_file": "la importación debe ser un archivo CSV con las columnas email, first_name y last_name",
+	"error.client_import_empty":        "el archivo de importación no tiene filas de clientes",
+	"error.client_import_too_large":    "una importación puede tener como máximo 500 filas",
+	"error.client_import_not_found":    "importación de clientes no encontrada",
+
+	// Goals
+	"error.goal_not_found":        "objetivo no encontrado",
+	"error.goal_forbidden":        "el objetivo no pertenece a este usuario",
+	"error.goal_title_required":   "el título no puede estar vacío",
+	"error.goal_already_achieved": "un objetivo alcanzado no puede cambiar de estado",
+	"error.goal_not_active":       "solo se puede registrar progreso en objetivos activos",
+	"error.invalid_goal_filter":   "status debe ser active, achieved o abandoned",
+
+	// Activity
+	"error.invalid_activity_samples": "las muestras necesitan una unidad conocida, no pueden terminar en el futuro ni solaparse con otras del mismo tipo y origen",
+
+	// Intake forms
+	"error.intake_question_not_found":     "pregunta de admisión no encontrada",
+	"error.intake_question_forbidden":     "la pregunta de admisión no pertenece a este coach",
+	"error.intake_question_invalid":       "la etiqueta es obligatoria; las preguntas de selección necesitan al menos 2 opciones distintas y los demás tipos ninguna",
+	"error.intake_question_limit_reached": "los coaches pueden tener como máximo 50 preguntas de admisión propias",
+	"error.intake_answer_invalid":         "una respuesta del formulario de admisión no coincide con su pregunta",
+
+	// Uploads
+	"error.upload_too_large":    "el archivo subido es demasiado grande",
+	"error.upload_content_type": "tipo de archivo no admitido",
+	"error.upload_not_found":    "no se encontró el archivo subido; solicita una nueva URL de subida",
+	"error.storage_unavailable": "la subida de archivos no está disponible",
+
+	// Data exports
+	"error.data_export_in_progress": "ya hay una exportación de datos en curso",
+	"error.data_export_not_found":   "no se ha solicitado ninguna exportación de datos",
+
+	// Notifications
+	"error.notification_not_found": "notificación no encontrada",
+	"error.invalid_device_token":   "token debe ser un token de notificaciones push de Expo",
+
+	// Digests
+	"error.digest_not_found": "todavía no se ha generado ningún resumen semanal",
+
+	// Auth
+	"error.invalid_credentials":     "correo o contraseña incorrectos",
+	"error.email_already_exists":    "el correo ya está registrado",
+	"error.user_disabled":           "la cuenta está inactiva o suspendida",
+	"error.invalid_refresh_token":   "token de actualización no válido o caducado",
+	"error.refresh_session_expired": "la sesión caducó, inicia sesión de nuevo",
+	"error.auth_unavailable":        "el inicio de sesión no está disponible por ahora",
+
+	// Invites
+	"error.invite_code_not_found":          "código de invitación no encontrado",
+	"error.invite_forbidden":               "el código de invitación no pertenece a este coach",
+	"error.invite_code_exhausted":          "este código de invitación alcanzó su número máximo de usos",
+	"error.connection_request_self":        "no puedes enviarte una solicitud de conexión a ti mismo",
+	"error.invalid_connection_filter":      "status debe ser pending, approved o declined",
+	"error.coach_not_accepting_clients":    "este coach no está aceptando clientes nuevos",
+	"error.already_client":                 "ya eres cliente de este coach",
+	"error.connection_request_exists":      "ya tienes una solicitud pendiente con este coach",
+	"error.connection_request_cooldown":    "tu última solicitud fue rechazada; puedes volver a intentarlo 30 días después del rechazo",
+	"error.connection_request_not_found":   "solicitud de conexión no encontrada",
+	"error.connection_request_forbidden":   "la solicitud de conexión no pertenece a este coach",
+	"error.connection_request_not_pending": "la solicitud de conexión ya fue aprobada o rechazada",
+
+	// Coach teams
+	"error.invalid_team_email":          "el correo debe ser una dirección válida",
+	"error.team_invite_self":            "no puedes invitarte a tu propio equipo",
+	"error.team_invitation_exists":      "este correo ya tiene una invitación abierta o ya está en tu equipo",
+	"error.team_invitation_not_found":   "invitación de equipo no encontrada",
+	"error.team_invitation_not_pending": "la invitación de equipo ya fue aceptada o retirada",
+	"error.team_email_not_verified":     "verifica tu correo antes de aceptar una invitación de equipo",
+	"error.already_on_coach_team":       "ya tienes un perfil de coach o trabajas en el equipo de otro coach",
+	"error.team_member_not_found":       "miembro del equipo no encontrado",
+	"error.team_member_unavailable":     "el miembro del equipo ya fue eliminado",
+	"error.coach_team_owner_only":       "solo el dueño del perfil de coach puede hacer esto",
+	"error.referral_code_not_found":     "código de referido no encontrado",
+	"error.self_referral":               "no puedes usar tu propio código de referido",
+	"error.circular_referral":           "este coach fue referido por ti, directamente o a través de otros",
+	"error.referral_locked":             "un código de referido solo se puede agregar una vez, antes de completar el onboarding",
+
+	// Messaging
+	"error.conversation_not_found":            "conversación no encontrada",
+	"error.conversation_forbidden":            "la conversación no pertenece a este usuario",
+	"error.conversation_closed":               "esta conversación está cerrada; el cliente se cambió a otro coach",
+	"error.message_content_required":          "content o media_url es obligatorio",
+	"error.message_not_found":                 "mensaje no encontrado en esta conversación",
+	"error.saved_reply_invalid":               "el título y el cuerpo son obligatorios",
+	"error.saved_reply_not_found":             "respuesta guardada no encontrada",
+	"error.saved_reply_forbidden":             "la respuesta guardada no pertenece a este coach",
+	"error.saved_reply_limit_reached":         "los coaches pueden guardar como máximo 100 respuestas",
+	"error.invalid_export_format":             "format debe ser json o txt",
+	"error.conversation_export_limit_reached": "las exportaciones de conversaciones están limitadas a 5 por día",
+	"error.invalid_scheduled_at":              "scheduled_at no es una fecha y hora válida",
+	"error.scheduled_too_far":                 "los mensajes se pueden programar con un máximo de 30 días de antelación",
+	"error.scheduling_coach_only":             "solo el coach puede programar mensajes",
+	"error.invalid_scheduled_status":          "status debe ser pending, sent, cancelled o failed",
+	"error.scheduled_message_not_found":       "mensaje programado no encontrado",
+	"error.scheduled_message_forbidden":       "el mensaje programado no pertenece a este usuario",
+	"error.scheduled_message_not_pending":     "el mensaje programado ya se envió, se canceló o falló",
+
+	// Scheduling
+	"error.session_type_invalid":              "el nombre es obligatorio",
+	"error.session_type_not_found":            "tipo de sesión no encontrado",
+	"error.session_type_forbidden":            "el tipo de sesión no pertenece a este coach",
+	"error.session_type_inactive":             "el tipo de sesión está inactivo",
+	"error.session_type_not_bookable":         "solo el coach puede reservar este tipo de sesión",
+	"error.self_booking_disabled":             "tu coach reserva tus sesiones; contáctalo para agendar",
+	"error.session_type_order_invalid":        "session_type_ids debe incluir cada tipo de sesión activo exactamente una vez",
+	"error.session_not_found":                 "sesión no encontrada",
+	"error.session_forbidden":                 "la sesión no pertenece a este usuario",
+	"error.session_action_forbidden":          "solo el coach puede realizar esta acción",
+	"error.session_state_invalid":             "la sesión no está en un estado válido para esta acción",
+	"error.session_conflict":                  "el horario solicitado coincide con otra sesión",
+	"error.client_session_conflict":           "ya tienes una sesión a esta hora",
+	"error.outside_availability":              "el horario solicitado está fuera de la disponibilidad del coach",
+	"error.availability_slot_invalid":         "franja de disponibilidad no válida",
+	"error.availability_preset_invalid":       "el nombre es obligatorio",
+	"error.availability_preset_not_found":     "plantilla de disponibilidad no encontrada",
+	"error.availability_preset_forbidden":     "la plantilla de disponibilidad no pertenece a este coach",
+	"error.availability_preset_name_taken":    "ya existe una plantilla de disponibilidad con este nombre",
+	"error.availability_preset_limit_reached": "los coaches pueden guardar como máximo 20 plantillas de disponibilidad",
+	"error.override_not_found":                "excepción de disponibilidad no encontrada",
+	"error.override_forbidden":                "la excepción no pertenece a este coach",
+	"error.invalid_date_range":                "rango de fechas no válido",
+	"error.invalid_date_format":               "las fechas deben tener el formato AAAA-MM-DD",
+	"error.invalid_week":                      "la semana debe ser una semana ISO como 2026-W12 o una fecha (AAAA-MM-DD)",
+	"error.invalid_session_status":            "status debe incluir solo pending_confirmation, scheduled, completed, cancelled o no_show",
+	"error.invalid_session_duration":          "duration_minutes no es válido",
+	"error.session_unpriced":                  "la sesión no tiene precio para marcarla como pagada",
+	"error.session_full":                      "esta sesión grupal está completa",
+	"error.session_already_joined":            "el cliente ya está reservado en esta sesión",
+	"error.invalid_max_participants":          "max_participants debe estar entre 1 y 50",
+	"error.check_in_forbidden":                "solo un cliente reservado puede registrar su llegada",
+	"error.check_in_window_closed":            "el registro de llegada abre 30 minutos antes y cierra 30 minutos después de la hora programada",
+	"error.invalid_check_in_location":         "latitude y longitude deben enviarse juntas",
+	"error.session_checked_in":                "el cliente registró su llegada; la sesión no se puede marcar como inasistencia",
+	"error.session_update_invalid":            "se requiere location o notes",
+	"error.session_not_in_series":             "la sesión no forma parte de una serie recurrente",
+	"error.time_block_invalid":                "end_at debe ser posterior a start_at y estar a menos de 7 días",
+	"error.time_block_not_found":              "bloqueo de horario no encontrado",
+	"error.time_block_forbidden":              "el bloqueo de horario no pertenece a este coach",
+	"error.feedback_forbidden":                "solo el cliente de la sesión puede dejar una valoración",
+	"error.feedback_window_closed":            "solo se puede valorar una sesión en los 14 días siguientes a completarla",
+	"error.feedback_exists":                   "ya se envió una valoración para esta sesión",
+
+	// Reports
+	"error.invalid_month_format":       "los meses deben tener el formato AAAA-MM",
+	"error.client_report_in_progress":  "ya se está generando un informe para este cliente",
+	"error.client_report_future_month": "los informes solo pueden cubrir el mes actual o meses anteriores",
+	"error.invalid_adherence_range":    "days debe estar entre 1 y 90 y tolerance entre 1 y 50",
+	"error.invalid_volume_weeks":       "weeks debe estar entre 1 y 52",
+
+	// Workouts
+	"error.template_not_found":             "plantilla no encontrada",
+	"error.template_forbidden":             "la plantilla no pertenece a este coach",
+	"error.template_version_not_found":     "versión de la plantilla no encontrada",
+	"error.template_category_invalid":      "el nombre es obligatorio",
+	"error.template_category_not_found":    "categoría de plantillas no encontrada",
+	"error.template_category_forbidden":    "la categoría de plantillas no pertenece a este coach",
+	"error.template_category_name_taken":   "ya existe una categoría de plantillas con este nombre",
+	"error.workout_not_found":              "entrenamiento no encontrado",
+	"error.workout_forbidden":              "el entrenamiento no pertenece a este usuario",
+	"error.workout_exercise_not_found":     "ejercicio del entrenamiento no encontrado",
+	"error.exercise_not_found":             "ejercicio no encontrado",
+	"error.invalid_exercise_prescription":  "las prescripciones deben coincidir con el measurement_type de cada ejercicio: series y repeticiones para reps, prescribed_duration_seconds para time, prescribed_distance y su unidad para distance",
+	"error.invalid_exercise_groups":        "cada grupo de ejercicios necesita al menos 2 ejercicios seguidos con el mismo group_type (superset, circuit o giant_set)",
+	"error.exercise_forbidden":             "el ejercicio no pertenece a este coach",
+	"error.invalid_exercise_alternative":   "la alternativa debe ser otro ejercicio activo de tu biblioteca",
+	"error.exercise_alternative_not_found": "alternativa de ejercicio no encontrada",
+	"error.workout_log_not_found":          "registro de entrenamiento no encontrado",
+	"error.invalid_workout_log":            "las series registradas deben coincidir con el measurement_type del ejercicio: reps_completed para reps, duration_seconds para time, distance y distance_unit para distance; rpe va de 1 a 10 y los valores no pueden ser negativos",
+	"error.invalid_workout_state":          "el entrenamiento ya está finalizado",
+	"error.completion_note_required":       "tu coach pide una nota de finalización con cada entrenamiento",
+	"error.form_check_video_missing":       "el registro de entrenamiento no tiene un video de técnica para revisar",
+	"error.form_feedback_required":         "form_feedback no puede estar vacío",
+	"error.workout_state_too_large":        "el estado del entrenamiento debe ocupar 8 KB o menos",
+	"error.workout_already_scheduled":      "el cliente ya tiene un entrenamiento en esta fecha",
+	"error.invalid_scheduled_date":         "scheduled_date debe tener el formato AAAA-MM-DD",
+
+	// Client exercise defaults
+	"error.client_exercise_default_invalid":   "indica weight_value con weight_unit, reps_min (con un reps_max opcional no menor) o rest_seconds; las repeticiones solo aplican a ejercicios por repeticiones",
+	"error.client_exercise_default_not_found": "valor predeterminado del ejercicio para el cliente no encontrado",
+
+	// Admin
+	"error.admin_required":             "se requiere acceso de administrador",
+	"error.transfer_same_coach":        "el cliente ya pertenece al coach de destino",
+	"error.transfer_target_connected":  "el cliente ya tiene un perfil con el coach de destino",
+	"error.client_already_transferred": "el perfil de cliente ya fue transferido",
+	"error.feature_flag_not_found":     "feature flag no encontrado",
+	"error.feature_flag_key_taken":     "ya existe un feature flag con esta clave",
+	"error.invalid_feature_flag_key":   "la clave debe tener letras minúsculas y dígitos separados por _, . o -",
+	"error.invalid_feature_flag_rules": "rules.percentage debe estar entre 0 y 100",
+	"error.webhook_entry_not_found":    "entrada de webhook no encontrada",
+	"error.invalid_webhook_status":     "status debe ser pending, processed, failed o ignored",
+	"error.webhook_provider_no_replay": "este proveedor de webhooks no admite reprocesamiento",
+
+	"error.bulk_exercise_filter_required":       "filter necesita ids, un name_pattern o un tag",
+	"error.bulk_exercise_mutation_required":     "mutation debe cambiar etiquetas, dificultad o grupos musculares",
+	"error.bulk_exercise_mutation_invalid":      "una etiqueta no puede agregarse y eliminarse a la vez",
+	"error.bulk_exercise_too_many":              "el filtro coincide con más de 5000 ejercicios",
+	"error.bulk_exercise_confirmation_required": "ejecuta primero con dry_run y envía su confirmation_token",
+	"error.bulk_exercise_confirmation_invalid":  "el confirmation_token expiró o ya no coincide con los ejercicios afectados; ejecuta un nuevo dry run",
+
+	// Subscriptions
+	"error.invalid_webhook_authorization": "autorización de webhook no válida",
+	"error.invalid_webhook_payload":       "contenido de webhook no válido",
+
+	// Features
+	"error.feature_name_required": "feature es obligatorio",
+}
@@ -0,0 +1,130 @@
+// Package i18n holds the message catalogs for text the API shows to people: push notifications and
+// error messages. English is the source language and other catalogs must translate every English key
+// (the package tests check this); a key a catalog lacks falls back to English at runtime.
+package i18n
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultLocale is used for users who never picked a language and for keys a catalog lacks.
+const DefaultLocale = "en"
+
+var catalogs = map[string]map[string]string{
+	"en": en,
+	"es": es,
+}
+
+// Supported reports whether locale (already normalized) has a catalog.
+func Supported(locale string) bool {
+	_, ok := catalogs[locale]
+	return ok
+}
+
+// Locales lists the supported locales, sorted.
+func Locales() []string {
+	locales := make([]string, 0, len(catalogs))
+	for locale := range catalogs {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// Normalize reduces a language tag such as "es-MX" or "ES_es" to its base language, returning
+// DefaultLocale when that language isn't supported.
+func Normalize(tag string) string {
+	if language := baseLanguage(tag); Supported(language) {
+		return language
+	}
+	return DefaultLocale
+}
+
+// FromAcceptLanguage picks the supported language the client prefers most from an Accept-Language
+// header, honouring q-values. Headers naming no supported language give DefaultLocale.
+func FromAcceptLanguage(header string) string {
+	best, bestQ := DefaultLocale, 0.0
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		language := baseLanguage(tag)
+		if !Supported(language) {
+			continue
+		}
+
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q > bestQ {
+			best, bestQ = language, q
+		}
+	}
+	return best
+}
+
+// Lookup returns key's message in locale without falling back to English.
+func Lookup(locale, key string) (string, bool) {
+	message, ok := catalogs[locale][key]
+	return message, ok
+}
+
+// T returns key's message in locale formatted with args, falling back to English when the locale
+// or key is unknown, and to the key itself as a last resort.
+func T(locale, key string, args ...any) string {
+	message, ok := Lookup(locale, key)
+	if !ok {
+		if message, ok = Lookup(DefaultLocale, key); !ok {
+			return key
+		}
+	}
+	if len(args) == 0 {
+		return message
+	}
+	return fmt.Sprintf(message, args...)
+}
+
+// ErrorMessage returns the message for an API error code in locale, or english when the locale has
+// no translation for it.
+func ErrorMessage(locale, code, english string) string {
+	if message, ok := Lookup(locale, "error."+code); ok {
+		return message
+	}
+	return english
+}
+
+// ShortDate formats t like "Mon, Jan 2" in locale, using the catalog's day and month names.
+func ShortDate(locale string, t time.Time) string {
+	weekday := T(locale, "date.weekday."+strconv.Itoa(int(t.Weekday())))
+	month := T(locale, "date.month."+strconv.Itoa(int(t.Month())))
+	return T(locale, "date.short", weekday, month, t.Day())
+}
+
+// MissingKeys lists the English keys locale has no translation for, sorted.
+func MissingKeys(locale string) []string {
+	catalog := catalogs[locale]
+	var missing []string
+	for key := range catalogs[DefaultLocale] {
+		if _, ok := catalog[key]; !ok {
+			missing = append(missing, key)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+// baseLanguage lowercases a language tag and drops its region or script subtags.
+func baseLanguage(tag string) string {
+	language := strings.ToLower(strings.TrimSpace(tag))
+	if i := strings.IndexAny(language, "-_"); i >= 0 {
+		language = language[:i]
+	}
+	return language
+}
@@ -0,0 +1,51 @@
+package i18n
+
+import (
+	"regexp"
+	"testing"
+)
+
+// verbs matches fmt verbs, skipping the %% escape.
+var verbs = regexp.MustCompile(`%[-+# 0-9.]*[a-zA-Z]`)
+
+func TestEveryCatalogTranslatesEveryEnglishKey(t *testing.T) {
+	for _, locale := range Locales() {
+		if missing := MissingKeys(locale); len(missing) > 0 {
+			t.Errorf("%q catalog is missing %v", locale, missing)
+		}
+	}
+}
+
+func TestTranslationsKeepFormatVerbs(t *testing.T) {
+	for _, locale := range Locales() {
+		for key, english := range en {
+			translated, ok := catalogs[locale][key]
+			if !ok {
+				continue
+			}
+			want, got := verbs.FindAllString(english, -1), verbs.FindAllString(translated, -1)
+			if len(want) != len(got) {
+				t.Errorf("%s %q has verbs %v, English has %v", locale, key, got, want)
+				continue
+			}
+			for i := range want {
+				if want[i] != got[i] {
+					t.Errorf("%s %q has verbs %v, English has %v", locale, key, got, want)
+					break
+				}
+			}
+		}
+	}
+}
+
+func TestTFallsBackToEnglish(t *testing.T) {
+	catalogs["xx"] = map[string]string{}
+	defer delete(catalogs, "xx")
+
+	if got, want := T("xx", "push.message.title"), en["push.message.title"]; got != want {
+		t.Errorf("T for a key the catalog lacks = %q, want English %q", got, want)
+	}
+	if got := T("es", "no.such.key"); got != "no.such.key" {
+		t.Errorf("T for an unknown key = %q, want the key itself", got)
+	}
+}
@@ -0,0 +1,86 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	_ "image/png" // register PNG decoder for image.Decode
+)
+
+// MaxPixels caps the width times height Decode accepts. A few kilobytes of PNG or JPEG can declare
+// dimensions that need gigabytes once decoded, so the header is checked before any pixel data.
+const MaxPixels = 40_000_000
+
+// ErrTooManyPixels is returned by Decode for images whose declared dimensions exceed MaxPixels.
+var ErrTooManyPixels = errors.New("image dimensions too large")
+
+// ResizeToFit scales img down so neither side exceeds maxSide, preserving aspect ratio.
+// Images already within bounds are returned unchanged. Uses box (area-average) sampling,
+// which is plenty for downscaling photos and needs nothing beyond the standard library.
+func ResizeToFit(img image.Image, maxSide int) image.Image {
+	bounds := img.Bounds()
+	srcW, srcH := bounds.Dx(), bounds.Dy()
+	if srcW <= maxSide && srcH <= maxSide {
+		return img
+	}
+
+	dstW, dstH := maxSide, maxSide
+	if srcW > srcH {
+		dstH = max(1, srcH*maxSide/srcW)
+	} else {
+		dstW = max(1, srcW*maxSide/srcH)
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dstW, dstH))
+	for y := 0; y < dstH; y++ {
+		y0 := bounds.Min.Y + y*srcH/dstH
+		y1 := max(y0+1, bounds.Min.Y+(y+1)*srcH/dstH)
+		for x := 0; x < dstW; x++ {
+			x0 := bounds.Min.X + x*srcW/dstW
+			x1 := max(x0+1, bounds.Min.X+(x+1)*srcW/dstW)
+
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := img.At(sx, sy).RGBA()
+					r += uint64(cr)
+					g += uint64(cg)
+					b += uint64(cb)
+					a += uint64(ca)
+					n++
+				}
+			}
+			dst.Set(x, y, color.RGBA64{
+				R: uint16(r / n),
+				G: uint16(g / n),
+				B: uint16(b / n),
+				A: uint16(a / n),
+			})
+		}
+	}
+	return dst
+}
+
+// Decode decodes a JPEG or PNG image and returns it with its format name. Images declaring more
+// than MaxPixels are rejected with ErrTooManyPixels before their pixel data is read.
+func Decode(data []byte) (image.Image, string, error) {
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", err
+	}
+	if config.Width <= 0 || config.Height <= 0 || int64(config.Width)*int64(config.Height) > MaxPixels {
+		return nil, format, ErrTooManyPixels
+	}
+	return image.Decode(bytes.NewReader(data))
+}
+
+// EncodeJPEG encodes img as a JPEG with the given quality (1-100).
+func EncodeJPEG(img image.Image, quality int) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,81 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+// bombPNG encodes a 1x1 PNG and rewrites its IHDR to declare width x height, fixing up the chunk
+// CRC so the header still parses. Decoding the pixels would need width*height*4 bytes.
+func bombPNG(t *testing.T, width, height uint32) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 1, 1))); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	// 8-byte signature, then the IHDR chunk: length, "IHDR", width, height, ..., CRC
+	binary.BigEndian.PutUint32(data[16:20], width)
+	binary.BigEndian.PutUint32(data[20:24], height)
+	binary.BigEndian.PutUint32(data[29:33], crc32.ChecksumIEEE(data[12:29]))
+	return data
+}
+
+// bombJPEG encodes a 1x1 JPEG and rewrites its SOF0 frame header to declare width x height.
+func bombJPEG(t *testing.T, width, height uint16) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, image.NewGray(image.Rect(0, 0, 1, 1)), nil); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	sof := bytes.Index(data, []byte{0xFF, 0xC0})
+	if sof < 0 {
+		t.Fatal("encoded JPEG has no SOF0 marker")
+	}
+	// marker, length (2), precision (1), height (2), width (2)
+	binary.BigEndian.PutUint16(data[sof+5:sof+7], height)
+	binary.BigEndian.PutUint16(data[sof+7:sof+9], width)
+	return data
+}
+
+func TestDecodeRejectsDecompressionBombs(t *testing.T) {
+	tests := map[string][]byte{
+		"png 100000x100000": bombPNG(t, 100_000, 100_000),
+		"png 40000001x1":    bombPNG(t, MaxPixels+1, 1),
+		"jpeg 65535x65535":  bombJPEG(t, 65_535, 65_535),
+	}
+	for name, data := range tests {
+		if len(data) > 1024 {
+			t.Fatalf("%s: crafted file is %d bytes, want a tiny one", name, len(data))
+		}
+		img, _, err := Decode(data)
+		if !errors.Is(err, ErrTooManyPixels) || img != nil {
+			t.Errorf("%s: Decode = %v, %v; want ErrTooManyPixels", name, img, err)
+		}
+	}
+}
+
+func TestDecodeAcceptsImagesWithinBudget(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 64, 48))); err != nil {
+		t.Fatal(err)
+	}
+	img, format, err := Decode(buf.Bytes())
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if format != "png" || img.Bounds().Dx() != 64 || img.Bounds().Dy() != 48 {
+		t.Errorf("Decode = %s %v, want a 64x48 png", format, img.Bounds())
+	}
+
+	if _, _, err := Decode([]byte("not an image")); err == nil || errors.Is(err, ErrTooManyPixels) {
+		t.Errorf("Decode of garbage = %v, want a format error", err)
+	}
+}
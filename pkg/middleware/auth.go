@@ -9,10 +9,12 @@ import (
 )
 
 // AuthMiddleware validates Bearer JWT tokens and sets user_id in request context.
-func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
+// The verifier is built once at startup and checks tokens against every published key; tokens
+// issued before the user's last revoke-all are then rejected.
+func AuthMiddleware(tokenKeys *services.TokenKeys, revocations *services.TokenRevocations) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		if strings.TrimSpace(jwtSecret) == "" {
-			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "JWT secret is not configured"})
+		if !tokenKeys.Configured() {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "JWT signing key is not configured"})
 			return
 		}
 
@@ -28,13 +30,17 @@ func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
 			return
 		}
 
-		userID, err := services.ValidateAccessToken(parts[1], jwtSecret)
+		token, err := tokenKeys.ValidateAccessToken(parts[1])
 		if err != nil {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid or expired access token"})
 			return
 		}
+		if revocations.IsRevoked(c.Request.Context(), token.UserID, token.IssuedAt) {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "access token has been revoked"})
+			return
+		}
 
-		c.Set("user_id", userID)
+		c.Set("user_id", token.UserID)
 		c.Next()
 	}
 }
@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"chalk-api/pkg/i18n"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// DefaultMaxBodyBytes applies when no limit is configured
+	DefaultMaxBodyBytes int64 = 1 << 20
+	// DefaultMaxUploadBodyBytes applies to upload routes when no limit is configured
+	DefaultMaxUploadBodyBytes int64 = 10 << 20
+)
+
+// BodySizeLimit caps request bodies at maxBytes, or at the override for the matched route pattern
+// (e.g. "/api/v1/users/me/avatar"). A declared Content-Length over the limit is rejected with 413
+// up front; otherwise the body is wrapped in http.MaxBytesReader so binding fails once it is exceeded.
+func BodySizeLimit(maxBytes int64, overrides map[string]int64) gin.HandlerFunc {
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxBodyBytes
+	}
+
+	return func(c *gin.Context) {
+		if c.Request.Body == nil || c.Request.Body == http.NoBody {
+			c.Next()
+			return
+		}
+
+		limit := maxBytes
+		if override, ok := overrides[c.FullPath()]; ok && override > 0 {
+			limit = override
+		}
+
+		if c.Request.ContentLength > limit {
+			c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{
+				"error":     i18n.ErrorMessage(i18n.FromAcceptLanguage(c.GetHeader("Accept-Language")), "request_body_too_large", "request body too large"),
+				"code":      "request_body_too_large",
+				"max_bytes": limit,
+			})
+			return
+		}
+
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+		c.Next()
+	}
+}
@@ -5,12 +5,13 @@ import (
 	"log/slog"
 )
 
-func SetupLogger(w io.Writer) *slog.Logger {
+// SetupLogger builds the JSON logger, with sensitive values redacted unless opts turns that off.
+func SetupLogger(w io.Writer, opts RedactOptions) *slog.Logger {
 	h := slog.NewJSONHandler(w, &slog.HandlerOptions{
 		AddSource: false,
 		Level:     slog.LevelDebug,
 	})
-	l := slog.New(h)
+	l := slog.New(NewRedactingHandler(h, opts))
 	l = l.With("app", slog.GroupValue(
 		slog.String("name", "Chalk-API"),
 	))
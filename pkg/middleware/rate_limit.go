@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"chalk-api/pkg/stores"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimitByIP allows at most limit requests per client IP in each window for the given action.
+// The limiter fails open when Redis is unavailable, so this never blocks traffic on a cache outage.
+func RateLimitByIP(limiter *stores.RateLimiter, action string, limit int64, window time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		result := limiter.Check(action+":"+c.ClientIP(), limit, window)
+
+		c.Header("X-RateLimit-Limit", strconv.FormatInt(result.Limit, 10))
+		c.Header("X-RateLimit-Remaining", strconv.FormatInt(result.Remaining, 10))
+		if !result.Allowed {
+			retryAfter := int(math.Ceil(result.ResetIn.Seconds()))
+			if retryAfter < 1 {
+				retryAfter = 1
+			}
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "too many requests, try again later"})
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,166 @@
+package middleware
+
+import (
+	"chalk-api/pkg/config"
+	"context"
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+const redactedValue = "[REDACTED]"
+
+// Attribute keys always masked, matched case-insensitively as the whole key or its last "_" part
+// (so "user_email" and "refresh_token" are covered too).
+var defaultRedactedKeys = []string{
+	"email",
+	"phone",
+	"phone_number",
+	"content",
+	"token",
+	"authorization",
+	"password",
+}
+
+var (
+	emailPattern  = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+	jwtPattern    = regexp.MustCompile(`eyJ[A-Za-z0-9_\-]+\.[A-Za-z0-9_\-]+\.[A-Za-z0-9_\-]*`)
+	bearerPattern = regexp.MustCompile(`(?i)bearer\s+[A-Za-z0-9._~+/=\-]+`)
+)
+
+// RedactOptions configures the redacting log handler.
+type RedactOptions struct {
+	// Disabled passes records through untouched
+	Disabled bool
+	// Keys are masked in addition to the defaults
+	Keys []string
+	// Allow lists keys logged as-is, e.g. email while debugging locally
+	Allow []string
+}
+
+// RedactOptionsFromConfig reads LOG_REDACTION_*; the allowlist only applies in local development.
+func RedactOptionsFromConfig(cfg config.Environment) RedactOptions {
+	opts := RedactOptions{
+		Disabled: !cfg.LogRedactionEnabled,
+		Keys:     splitKeys(cfg.LogRedactionKeys),
+	}
+	if cfg.IsDevelopment() {
+		opts.Allow = splitKeys(cfg.LogRedactionAllowlist)
+	}
+	return opts
+}
+
+// redactingHandler masks sensitive attribute values and scrubs emails, JWTs and bearer tokens out of
+// every string it sees before handing the record on.
+type redactingHandler struct {
+	next  slog.Handler
+	keys  map[string]bool
+	allow map[string]bool
+}
+
+// NewRedactingHandler wraps next so emails, phone numbers, message content and credentials never
+// reach the log output.
+func NewRedactingHandler(next slog.Handler, opts RedactOptions) slog.Handler {
+	if opts.Disabled {
+		return next
+	}
+	h := &redactingHandler{
+		next:  next,
+		keys:  make(map[string]bool),
+		allow: make(map[string]bool),
+	}
+	for _, key := range defaultRedactedKeys {
+		h.keys[key] = true
+	}
+	for _, key := range opts.Keys {
+		h.keys[strings.ToLower(key)] = true
+	}
+	for _, key := range opts.Allow {
+		h.allow[strings.ToLower(key)] = true
+	}
+	return h
+}
+
+func (h *redactingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *redactingHandler) Handle(ctx context.Context, record slog.Record) error {
+	redacted := slog.NewRecord(record.Time, record.Level, scrubString(record.Message), record.PC)
+	record.Attrs(func(attr slog.Attr) bool {
+		redacted.AddAttrs(h.redactAttr(attr))
+		return true
+	})
+	return h.next.Handle(ctx, redacted)
+}
+
+func (h *redactingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, attr := range attrs {
+		redacted[i] = h.redactAttr(attr)
+	}
+	return &redactingHandler{next: h.next.WithAttrs(redacted), keys: h.keys, allow: h.allow}
+}
+
+func (h *redactingHandler) WithGroup(name string) slog.Handler {
+	return &redactingHandler{next: h.next.WithGroup(name), keys: h.keys, allow: h.allow}
+}
+
+func (h *redactingHandler) redactAttr(attr slog.Attr) slog.Attr {
+	attr.Value = attr.Value.Resolve()
+	key := strings.ToLower(attr.Key)
+	if h.allow[key] {
+		return attr
+	}
+	if h.sensitiveKey(key) && attr.Value.Kind() != slog.KindGroup {
+		return slog.String(attr.Key, redactedValue)
+	}
+
+	switch attr.Value.Kind() {
+	case slog.KindString:
+		return slog.String(attr.Key, scrubString(attr.Value.String()))
+	case slog.KindGroup:
+		group := attr.Value.Group()
+		redacted := make([]slog.Attr, len(group))
+		for i, member := range group {
+			redacted[i] = h.redactAttr(member)
+		}
+		return slog.Attr{Key: attr.Key, Value: slog.GroupValue(redacted...)}
+	case slog.KindAny:
+		// Errors often quote the input that failed, which can be an email or a token
+		if err, ok := attr.Value.Any().(error); ok {
+			return slog.String(attr.Key, scrubString(err.Error()))
+		}
+	}
+	return attr
+}
+
+func (h *redactingHandler) sensitiveKey(key string) bool {
+	if h.keys[key] {
+		return true
+	}
+	if i := strings.LastIndexByte(key, '_'); i >= 0 {
+		return h.keys[key[i+1:]]
+	}
+	return false
+}
+
+// scrubString replaces anything that looks like an email address, JWT or bearer credential.
+func scrubString(value string) string {
+	if !strings.ContainsAny(value, "@.") && !strings.Contains(strings.ToLower(value), "bearer") {
+		return value
+	}
+	value = bearerPattern.ReplaceAllString(value, "Bearer "+redactedValue)
+	value = jwtPattern.ReplaceAllString(value, redactedValue)
+	return emailPattern.ReplaceAllString(value, redactedValue)
+}
+
+func splitKeys(raw string) []string {
+	var keys []string
+	for _, key := range strings.Split(raw, ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"chalk-api/pkg/models"
+	"chalk-api/pkg/services"
+	"chalk-api/pkg/utils"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestAnalytics records each authenticated request (user, route template, status, latency) into
+// the analytics sink after the handler runs. Unauthenticated and unmatched requests are skipped.
+// With a nil or disabled sink it is a no-op.
+func RequestAnalytics(sink *services.RequestAnalytics) gin.HandlerFunc {
+	if !sink.Enabled() {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+
+	return func(c *gin.Context) {
+		started := time.Now()
+		c.Next()
+
+		userID, ok := utils.GetUserIDFromContext(c)
+		if !ok {
+			return
+		}
+		route := c.FullPath()
+		if route == "" {
+			return
+		}
+
+		sink.Record(models.RequestEvent{
+			UserID:    userID,
+			Method:    c.Request.Method,
+			Route:     route,
+			Status:    c.Writer.Status(),
+			LatencyMs: int(time.Since(started).Milliseconds()),
+			RequestID: c.GetString(RequestIDKey),
+			CreatedAt: started.UTC(),
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"chalk-api/pkg/utils"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// RequestIDHeader is echoed back on every response so clients can quote it in bug reports.
+	RequestIDHeader = "X-Request-ID"
+	// RequestIDKey is the gin context key holding the current request ID.
+	RequestIDKey = "request_id"
+)
+
+// RequestIDMiddleware reuses an inbound X-Request-ID (e.g. from a load balancer) or generates one.
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := strings.TrimSpace(c.GetHeader(RequestIDHeader))
+		if requestID == "" || len(requestID) > 128 {
+			generated, err := utils.GenerateRandomString(32)
+			if err == nil {
+				requestID = generated
+			}
+		}
+
+		c.Set(RequestIDKey, requestID)
+		c.Request = c.Request.WithContext(utils.WithRequestID(c.Request.Context(), requestID))
+		c.Header(RequestIDHeader, requestID)
+		c.Next()
+	}
+}
@@ -1,6 +1,10 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 // ClientProfile - Relationship between a user (client) and their coach
 type ClientProfile struct {
@@ -16,13 +20,32 @@ type ClientProfile struct {
 	ProgramType     *string `json:"program_type"` // "strength", "weight_loss", "general_fitness"
 	SessionsPerWeek *int    `json:"sessions_per_week"`
 
+	// Completing a workout requires a completion note from the client
+	RequireCompletionNote bool `gorm:"not null;default:false" json:"require_completion_note"`
+
+	// Client may book sessions themselves; coaches can turn it off (e.g. unpaid balance) without pausing
+	CanSelfBook bool `gorm:"not null;default:true" json:"can_self_book"`
+
 	// Organization (coach-only)
-	Tags         []string `gorm:"type:text[];serializer:json" json:"tags"` // ["priority", "beginner"]
-	PrivateNotes *string  `gorm:"type:text" json:"-"`                      // NEVER sent to client
+	Tags         []string `gorm:"type:jsonb;serializer:json" json:"tags"` // ["priority", "beginner"], lowercase
+	PrivateNotes *string  `gorm:"type:text" json:"-"`                    // NEVER sent to client; superseded by client_notes, copied there by the migration
 
 	// Tracking
 	LastContactAt *time.Time `json:"last_contact_at"` // Last message/session
 
+	// Inactivity - maintained by the at-risk worker from workouts, workout logs, client messages and attended sessions
+	LastActivityAt *time.Time `gorm:"index" json:"last_activity_at"`
+	AtRisk         bool       `gorm:"not null;default:false;index" json:"at_risk"`
+	AtRiskSince    *time.Time `json:"at_risk_since"`
+
+	// Client cancellations that fell inside the coach's cancellation window
+	LateCancelCount int `gorm:"not null;default:0" json:"late_cancel_count"`
+
+	// Coach-to-coach transfer - the archived profile keeps the history, the new one links back to it
+	TransferredFromID *uint      `gorm:"index" json:"transferred_from_id"`
+	TransferredToID   *uint      `gorm:"index" json:"transferred_to_id"`
+	TransferredAt     *time.Time `json:"transferred_at"`
+
 	// Timestamps
 	InvitedAt *time.Time `json:"invited_at"` // When coach created the invite
 	JoinedAt  *time.Time `json:"joined_at"`  // When client accepted invite
@@ -40,6 +63,28 @@ func (ClientProfile) TableName() string {
 	return "client_profiles"
 }
 
+// ClientNote - A coach's private, timestamped note on one client. Pinned notes are listed first.
+// Notes are coach-only: there is deliberately no relation from ClientProfile, so no client-facing
+// response can preload them.
+type ClientNote struct {
+	ID           uint `gorm:"primaryKey" json:"id"`
+	ClientID     uint `gorm:"index;not null" json:"client_id"` // ClientProfile ID
+	CoachID      uint `gorm:"index;not null" json:"coach_id"`
+	AuthorUserID uint `gorm:"not null" json:"author_user_id"`
+
+	Body     string     `gorm:"type:text;not null" json:"body"`
+	Pinned   bool       `gorm:"not null;default:false" json:"pinned"`
+	PinnedAt *time.Time `json:"pinned_at"`
+
+	CreatedAt time.Time      `gorm:"index" json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"` // kept so the private notes migration never recreates a deleted note
+}
+
+func (ClientNote) TableName() string {
+	return "client_notes"
+}
+
 // InviteCode - Coach invitation system with unique codes
 type InviteCode struct {
 	ID      uint   `gorm:"primaryKey" json:"id"`
@@ -49,13 +94,19 @@ type InviteCode struct {
 	// Expiration (always set, e.g., 7 days from creation)
 	ExpiresAt time.Time `gorm:"not null;index" json:"expires_at"`
 
-	// Usage tracking (single-use)
-	UsedBy *uint      `gorm:"index" json:"used_by"` // Which UserID used it (null if unused)
-	UsedAt *time.Time `json:"used_at"`
+	// Usage tracking - each acceptance is recorded in invite_code_uses
+	MaxUses  int        `gorm:"not null;default:1" json:"max_uses"`
+	UseCount int        `gorm:"not null;default:0" json:"use_count"`
+	UsedBy   *uint      `gorm:"index" json:"used_by"` // First UserID to use it (null if unused); kept for single-use clients
+	UsedAt   *time.Time `json:"used_at"`
 
 	// Status
 	IsActive bool `gorm:"default:true;index" json:"is_active"` // Coach can manually deactivate
 
+	// Onboarding - applied when the invite creates a new coach-client relationship
+	TemplateID     *uint   `gorm:"index" json:"template_id"`           // assigned as a workout on the join date
+	WelcomeMessage *string `gorm:"type:text" json:"welcome_message"` // sent as the coach's first message
+
 	CreatedAt time.Time `json:"created_at"`
 
 	// Relations
@@ -67,6 +118,139 @@ func (InviteCode) TableName() string {
 	return "invite_codes"
 }
 
+// InviteCodeUse records one acceptance of an invite code. A user counts against max_uses once.
+type InviteCodeUse struct {
+	ID              uint  `gorm:"primaryKey" json:"id"`
+	InviteCodeID    uint  `gorm:"not null;uniqueIndex:idx_invite_code_uses_code_user" json:"invite_code_id"`
+	UserID          uint  `gorm:"not null;uniqueIndex:idx_invite_code_uses_code_user;index" json:"user_id"`
+	ClientProfileID *uint `json:"client_profile_id"` // null for uses backfilled from legacy single-use rows
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (InviteCodeUse) TableName() string {
+	return "invite_code_uses"
+}
+
+// ClientInvitation - A coach's invitation to one person by email, created by client import. Each
+// has its own single-use invite code; accepting that code marks the invitation accepted and applies
+// its goals and tags to the new client profile.
+type ClientInvitation struct {
+	ID           uint  `gorm:"primaryKey" json:"id"`
+	CoachID      uint  `gorm:"index;not null" json:"coach_id"`
+	InviteCodeID uint  `gorm:"uniqueIndex;not null" json:"invite_code_id"`
+	ImportID     *uint `gorm:"index" json:"import_id"`
+	UserID       *uint `gorm:"index" json:"user_id"` // set when the email already had an account
+
+	Email     string   `gorm:"not null;index" json:"email"` // lowercased
+	FirstName string   `gorm:"not null" json:"first_name"`
+	LastName  string   `gorm:"not null" json:"last_name"`
+	Phone     *string  `json:"phone"`
+	Goals     *string  `gorm:"type:text" json:"goals"`
+	Tags      []string `gorm:"type:jsonb;serializer:json" json:"tags"`
+
+	Status     string     `gorm:"not null;default:'pending';index" json:"status"` // "pending", "accepted"
+	AcceptedAt *time.Time `json:"accepted_at"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// Relations
+	InviteCode *InviteCode `gorm:"foreignKey:InviteCodeID" json:"invite_code,omitempty"`
+}
+
+func (ClientInvitation) TableName() string {
+	return "client_invitations"
+}
+
+// ClientInvitation.Status values
+const (
+	ClientInvitationPending  = "pending"
+	ClientInvitationAccepted = "accepted"
+)
+
+// ClientImport - One CSV client import by a coach. Small files are processed during the upload;
+// larger ones are picked up by the client import worker, and Results grows as rows are processed.
+type ClientImport struct {
+	ID                uint `gorm:"primaryKey" json:"id"`
+	CoachID           uint `gorm:"index;not null" json:"coach_id"`
+	RequestedByUserID uint `gorm:"not null" json:"-"`
+
+	Status    string `gorm:"not null;default:'pending';index" json:"status"` // pending → processing → completed / failed
+	TotalRows int    `gorm:"not null" json:"total_rows"`
+
+	// Rows holds the parsed file until it has been processed
+	Rows    []ClientImportRow    `gorm:"type:jsonb;serializer:json" json:"-"`
+	Results []ClientImportResult `gorm:"type:jsonb;serializer:json" json:"results"`
+
+	InvitedCount   int `gorm:"not null;default:0" json:"invited_count"`
+	RequestedCount int `gorm:"not null;default:0" json:"requested_count"`
+	SkippedCount   int `gorm:"not null;default:0" json:"skipped_count"` // already connected, already invited or repeated in the file
+	FailedCount    int `gorm:"not null;default:0" json:"failed_count"`
+
+	FailureReason *string    `gorm:"type:text" json:"-"`
+	CompletedAt   *time.Time `json:"completed_at"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (ClientImport) TableName() string {
+	return "client_imports"
+}
+
+// ClientImport.Status values
+const (
+	ClientImportPending    = "pending"
+	ClientImportProcessing = "processing"
+	ClientImportCompleted  = "completed"
+	ClientImportFailed     = "failed"
+)
+
+// ClientImportRow is one data row of an import file as uploaded. Line is its line number in the file.
+type ClientImportRow struct {
+	Line      int    `json:"line"`
+	Email     string `json:"email"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Phone     string `json:"phone,omitempty"`
+	Goals     string `json:"goals,omitempty"`
+	Tags      string `json:"tags,omitempty"`
+}
+
+// ClientImportResult is what happened to one import row.
+type ClientImportResult struct {
+	Line            int    `json:"line"`
+	Email           string `json:"email"`
+	Status          string `json:"status"`           // "invited", "requested", "already_connected", "already_invited", "duplicate", "error"
+	Reason          string `json:"reason,omitempty"` // why an "error" row was rejected
+	InvitationID    *uint  `json:"invitation_id,omitempty"`
+	ClientProfileID *uint  `json:"client_profile_id,omitempty"`
+}
+
+// ConnectionRequest - a prospective client asking to join a coach without an invite code,
+// e.g. from the coach's public booking page. The coach reviews pending requests.
+type ConnectionRequest struct {
+	ID      uint    `gorm:"primaryKey" json:"id"`
+	UserID  uint    `gorm:"index;not null" json:"user_id"`
+	CoachID uint    `gorm:"index;not null" json:"coach_id"`
+	Message *string `gorm:"type:text" json:"message"`
+	Status  string  `gorm:"not null;default:'pending';index" json:"status"` // "pending", "approved", "declined"
+
+	// Set when the coach approves or declines; a declined user may ask again 30 days later
+	ReviewedAt *time.Time `json:"reviewed_at"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// Relations
+	User *User `gorm:"foreignKey:UserID" json:"user,omitempty"`
+}
+
+func (ConnectionRequest) TableName() string {
+	return "connection_requests"
+}
+
 // ClientIntakeForm - Initial client assessment filled out once when joining a coach
 type ClientIntakeForm struct {
 	ID       uint `gorm:"primaryKey" json:"id"`
@@ -108,9 +292,15 @@ type ClientIntakeForm struct {
 	// Additional Notes
 	AdditionalInfo *string `gorm:"type:text" json:"additional_info"` // Anything else client wants to share
 
+	// Answers to the coach's custom intake questions, keyed by question ID. Questions added after
+	// submission are simply unanswered; answers are only validated when the form is submitted.
+	CustomAnswers map[string]any `gorm:"type:jsonb;serializer:json" json:"custom_answers"`
+
 	// Completion
 	CompletedAt *time.Time `json:"completed_at"` // When client submitted the form
 
+	Version int `gorm:"not null;default:1" json:"version"` // Incremented on each resubmission
+
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 
@@ -121,3 +311,24 @@ type ClientIntakeForm struct {
 func (ClientIntakeForm) TableName() string {
 	return "client_intake_forms"
 }
+
+// CustomIntakeQuestion - A coach's own question appended to the standard intake form
+type CustomIntakeQuestion struct {
+	ID      uint   `gorm:"primaryKey" json:"id"`
+	CoachID uint   `gorm:"index;not null" json:"coach_id"`
+	Label   string `gorm:"not null;size:300" json:"label"` // "Do you have a pool?"
+	Type    string `gorm:"not null;size:20" json:"type"`   // "text", "number", "boolean", "select"
+
+	// Choices for select questions; empty for other types
+	Options []string `gorm:"type:jsonb;serializer:json" json:"options"`
+
+	Required     bool `gorm:"not null;default:false" json:"required"`
+	DisplayOrder int  `gorm:"not null;default:0" json:"display_order"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (CustomIntakeQuestion) TableName() string {
+	return "custom_intake_questions"
+}
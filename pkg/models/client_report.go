@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+const (
+	ClientReportStatusPending    = "pending"
+	ClientReportStatusProcessing = "processing"
+	ClientReportStatusReady      = "ready"
+	ClientReportStatusFailed     = "failed"
+)
+
+// ClientReport - A monthly progress report PDF a coach generated for one client.
+// A partial unique index (see db.RunMigrations) allows only one pending/processing report per client.
+type ClientReport struct {
+	ID              uint `gorm:"primaryKey" json:"id"`
+	CoachID         uint `gorm:"not null;index" json:"coach_id"`
+	ClientID        uint `gorm:"not null;index" json:"client_id"`
+	RequestedByUser uint `gorm:"not null" json:"-"`
+
+	// Reported period, inclusive calendar dates
+	PeriodStart string `gorm:"type:date;not null" json:"period_start"`
+	PeriodEnd   string `gorm:"type:date;not null" json:"period_end"`
+
+	Status string `gorm:"not null;default:'pending'" json:"status"` // pending → processing → ready / failed
+
+	// Storage location of the PDF; the download URL is presigned and expires with the object link
+	ObjectKey   *string    `json:"-"`
+	DownloadURL *string    `gorm:"type:text" json:"download_url"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+
+	FailureReason *string    `gorm:"type:text" json:"-"`
+	CompletedAt   *time.Time `json:"completed_at"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	Client ClientProfile `gorm:"foreignKey:ClientID" json:"-"`
+}
+
+func (ClientReport) TableName() string {
+	return "client_reports"
+}
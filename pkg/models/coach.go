@@ -14,6 +14,10 @@ type SocialLinks struct {
 	Other     map[string]string `json:"other,omitempty"` // Catch-all for future platforms
 }
 
+// OnboardingState - Completion time of each onboarding checklist step, keyed by step name.
+// Steps are only ever added; a step stays complete even if the data behind it is later removed.
+type OnboardingState map[string]time.Time
+
 // CoachProfile - Coach-specific profile data
 type CoachProfile struct {
 	ID     uint `gorm:"primaryKey" json:"id"`
@@ -23,6 +27,9 @@ type CoachProfile struct {
 	BusinessName *string `json:"business_name"`
 	Bio          *string `gorm:"type:text" json:"bio"`
 	CoverPhotoURL *string `json:"cover_photo_url"`
+	BrandColor    *string `gorm:"size:7" json:"brand_color"` // "#RRGGBB", used to theme the coach's client-facing screens
+	// Slug - public booking page handle (/public/coaches/:slug), lowercase letters, digits and dashes
+	Slug *string `gorm:"uniqueIndex:idx_coach_profiles_slug;size:50" json:"slug"`
 
 	// Expertise
 	Specialties      []string `gorm:"type:text[];serializer:json" json:"specialties"` // ["strength", "weight loss", "bodybuilding"]
@@ -47,10 +54,40 @@ type CoachProfile struct {
 	// Onboarding & Status
 	OnboardingCompleted bool `gorm:"default:false" json:"onboarding_completed"`
 	IsAcceptingClients  bool `gorm:"default:true" json:"is_accepting_clients"`
+	// When each onboarding checklist step was first completed, keyed by step ("profile", "availability", ...)
+	OnboardingState OnboardingState `gorm:"type:jsonb;serializer:json" json:"onboarding_state"`
+
+	// Referrals - ReferralCode is this coach's own code to share; ReferredByCoachID is the coach whose
+	// code they signed up with, settable until onboarding is completed
+	ReferralCode      *string `gorm:"uniqueIndex:idx_coach_profiles_referral_code;size:16" json:"referral_code"`
+	ReferredByCoachID *uint   `gorm:"index" json:"referred_by_coach_id"`
 
 	// Activity
 	LastActiveAt *time.Time `json:"last_active_at"`
 
+	// Weekly digest - delivered on DigestDayOfWeek at DigestHour in the coach's timezone
+	DigestEnabled     bool       `gorm:"not null;default:true" json:"digest_enabled"`
+	DigestPushEnabled bool       `gorm:"not null;default:true" json:"digest_push_enabled"`
+	DigestDayOfWeek   int        `gorm:"not null;default:1" json:"digest_day_of_week"` // 0=Sunday, 1=Monday
+	DigestHour        int        `gorm:"not null;default:8" json:"digest_hour"`        // 0-23, local time
+	LastDigestSentAt  *time.Time `json:"last_digest_sent_at"`
+
+	// Clients with no activity for this many days are flagged at-risk
+	AtRiskInactivityDays int `gorm:"not null;default:10" json:"at_risk_inactivity_days"`
+
+	// Client cancellations within this many hours of the start time are recorded as late (0 = no policy)
+	CancellationWindowHours int `gorm:"not null;default:0" json:"cancellation_window_hours"`
+
+	// What happens to scheduled sessions left without an outcome after they end: "leave_alone" prompts
+	// the coach in-app, "auto_complete" and "auto_no_show" resolve them automatically
+	StaleSessionAction string `gorm:"not null;default:'leave_alone'" json:"stale_session_action"`
+
+	// Messages in the coach's conversations are deleted once they are this many days old (nil = kept forever)
+	MessagesRetentionDays *int `json:"messages_retention_days"`
+
+	// Optimistic locking - bumped on every update; edits that carry an older version are rejected
+	Version int `gorm:"not null;default:1" json:"version"`
+
 	// Timestamps
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
@@ -67,6 +104,54 @@ func (CoachProfile) TableName() string {
 	return "coach_profiles"
 }
 
+// CoachProfile.StaleSessionAction values
+const (
+	StaleSessionLeaveAlone   = "leave_alone"
+	StaleSessionAutoComplete = "auto_complete"
+	StaleSessionAutoNoShow   = "auto_no_show"
+)
+
+// CoachTeamMember - a user working under another coach's profile, e.g. an assistant who handles
+// sessions but doesn't own the business. The owner is implicit (CoachProfile.UserID); rows here are
+// invitations sent by email that link to a user once accepted.
+type CoachTeamMember struct {
+	ID      uint  `gorm:"primaryKey" json:"id"`
+	CoachID uint  `gorm:"index;not null" json:"coach_id"`
+	UserID  *uint `gorm:"index" json:"user_id"` // nil until the invitation is accepted
+
+	Email  string `gorm:"not null;index" json:"email"` // lowercased; the accepting user's email must match
+	Role   string `gorm:"not null;default:'assistant'" json:"role"`
+	Status string `gorm:"not null;default:'pending';index" json:"status"` // "pending", "active", "revoked"
+
+	InvitedByUserID uint       `gorm:"not null" json:"invited_by_user_id"`
+	AcceptedAt      *time.Time `json:"accepted_at"`
+	RevokedAt       *time.Time `json:"revoked_at"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// Relations
+	Coach *CoachProfile `gorm:"foreignKey:CoachID" json:"coach,omitempty"`
+	User  *User         `gorm:"foreignKey:UserID" json:"user,omitempty"`
+}
+
+func (CoachTeamMember) TableName() string {
+	return "coach_team_members"
+}
+
+// CoachTeamMember.Role values. Only assistants are stored; "owner" is what the profile's own user acts as.
+const (
+	CoachTeamRoleOwner     = "owner"
+	CoachTeamRoleAssistant = "assistant"
+)
+
+// CoachTeamMember.Status values
+const (
+	CoachTeamMemberPending = "pending"
+	CoachTeamMemberActive  = "active"
+	CoachTeamMemberRevoked = "revoked"
+)
+
 // Certification - Coach certifications with document upload
 type Certification struct {
 	ID      uint `gorm:"primaryKey" json:"id"`
@@ -151,6 +236,13 @@ type CoachStats struct {
 	MessagesThisWeek       int  `gorm:"default:0" json:"messages_this_week"`
 	AvgResponseTimeMinutes *int `json:"avg_response_time_minutes"`
 
+	// Session feedback from clients - recomputed from session_feedbacks by the outbox handler
+	AverageRating *float64 `json:"average_rating"` // nil until the first rating
+	RatingCount   int      `gorm:"default:0" json:"rating_count"`
+
+	// Client goals marked achieved - recomputed from client_goals by the goal.achieved handler
+	GoalsAchievedTotal int `gorm:"default:0" json:"goals_achieved_total"`
+
 	// Revenue tracking (future)
 	TotalRevenueThisMonth *float64 `json:"total_revenue_this_month"`
 
@@ -162,3 +254,15 @@ type CoachStats struct {
 func (CoachStats) TableName() string {
 	return "coach_stats"
 }
+
+// CoachProfileView holds one day's distinct public profile viewers for a coach. Views are counted in
+// Redis during the day and flushed here by the profile view worker.
+type CoachProfileView struct {
+	ID       uint   `gorm:"primaryKey" json:"id"`
+	CoachID  uint   `gorm:"not null;uniqueIndex:idx_coach_profile_views_coach_date,priority:1" json:"coach_id"`
+	ViewDate string `gorm:"type:date;not null;uniqueIndex:idx_coach_profile_views_coach_date,priority:2" json:"view_date"` // UTC day
+	Views    int64  `gorm:"not null;default:0" json:"views"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
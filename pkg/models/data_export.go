@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+const (
+	DataExportStatusPending    = "pending"
+	DataExportStatusProcessing = "processing"
+	DataExportStatusReady      = "ready"
+	DataExportStatusFailed     = "failed"
+)
+
+// DataExport - A user's request for a copy of their personal data (GDPR access request).
+// A partial unique index (see db.RunMigrations) allows only one pending/processing export per user.
+type DataExport struct {
+	ID     uint   `gorm:"primaryKey" json:"id"`
+	UserID uint   `gorm:"not null;index" json:"user_id"`
+	Status string `gorm:"not null;default:'pending'" json:"status"` // pending → processing → ready / failed
+
+	// Storage location of the zip; the download URL is presigned and expires with the object link
+	ObjectKey   *string    `json:"-"`
+	DownloadURL *string    `gorm:"type:text" json:"download_url"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+
+	FailureReason *string    `gorm:"type:text" json:"-"`
+	CompletedAt   *time.Time `json:"completed_at"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	User User `gorm:"foreignKey:UserID" json:"-"`
+}
+
+func (DataExport) TableName() string {
+	return "data_exports"
+}
@@ -58,3 +58,22 @@ type Exercise struct {
 func (Exercise) TableName() string {
 	return "exercises"
 }
+
+// ExerciseAlternative - A suggested substitute for an exercise ("no barbell? use dumbbell bench").
+// Pairs are directional: A→B doesn't imply B→A. System pairs are seeded from the catalog;
+// coaches add pairs for their own custom exercises.
+type ExerciseAlternative struct {
+	ID                    uint    `gorm:"primaryKey" json:"id"`
+	ExerciseID            uint    `gorm:"not null;uniqueIndex:idx_exercise_alternatives_pair" json:"exercise_id"`
+	AlternativeExerciseID uint    `gorm:"not null;uniqueIndex:idx_exercise_alternatives_pair" json:"alternative_exercise_id"`
+	Reason                *string `gorm:"type:text" json:"reason"` // "no barbell", "knee friendly"
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	Alternative Exercise `gorm:"foreignKey:AlternativeExerciseID" json:"alternative"`
+}
+
+func (ExerciseAlternative) TableName() string {
+	return "exercise_alternatives"
+}
@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// FeatureFlag gates a feature that is being rolled out gradually. A flag that is Enabled is on for
+// everyone; otherwise Rules decide who sees it.
+type FeatureFlag struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	Key         string    `gorm:"size:100;not null;uniqueIndex" json:"key"` // e.g. "group_sessions"
+	Description *string   `json:"description"`
+	Enabled     bool      `gorm:"not null;default:false" json:"enabled"`
+	Rules       FlagRules `gorm:"type:jsonb;serializer:json;not null" json:"rules"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// FlagRules turns a flag on for part of the user base when it isn't enabled globally. A user matches
+// when any rule does.
+type FlagRules struct {
+	// Percentage of users (0-100) to include, bucketed by a hash of the flag key and user ID so each
+	// user keeps their answer as the percentage grows
+	Percentage int    `json:"percentage,omitempty"`
+	UserIDs    []uint `json:"user_ids,omitempty"`
+	// CoachIDs are coach profile IDs; the coach and their active clients match
+	CoachIDs []uint `json:"coach_ids,omitempty"`
+}
+
+func (FeatureFlag) TableName() string {
+	return "feature_flags"
+}
@@ -10,6 +10,11 @@ type Conversation struct {
 	ClientID uint `gorm:"index;not null" json:"client_id"`
 
 	LastMessageAt *time.Time `gorm:"index" json:"last_message_at"` // for sorting inbox by most recent
+	ClosedAt      *time.Time `json:"closed_at"`                     // read-only after the client moved to another coach
+
+	// Cleared while the client relationship is archived; inactive conversations are hidden from the inbox
+	// and unread counts, and come back when the relationship is reactivated
+	IsActive bool `gorm:"not null;default:true;index" json:"is_active"`
 
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
@@ -44,3 +49,63 @@ type Message struct {
 func (Message) TableName() string {
 	return "messages"
 }
+
+// Scheduled message statuses
+const (
+	ScheduledMessagePending    = "pending"
+	ScheduledMessageProcessing = "processing"
+	ScheduledMessageSent       = "sent"
+	ScheduledMessageCancelled  = "cancelled"
+	ScheduledMessageFailed     = "failed"
+)
+
+// ScheduledMessage - A coach's message held back until ScheduledAt, then sent through the normal
+// send path by the scheduled message worker. Only the resolved UTC instant is used for delivery;
+// Timezone records the client's zone when the coach picked a local clock time.
+type ScheduledMessage struct {
+	ID             uint `gorm:"primaryKey" json:"id"`
+	ConversationID uint `gorm:"index;not null" json:"conversation_id"`
+	SenderID       uint `gorm:"index;not null" json:"sender_id"` // coach's UserID
+
+	Content      *string `gorm:"type:text" json:"content"`
+	MediaURL     *string `json:"media_url"`
+	MediaType    *string `json:"media_type"`
+	SavedReplyID *uint   `json:"saved_reply_id"` // expanded at delivery, like a normal send
+
+	ScheduledAt time.Time `gorm:"not null" json:"scheduled_at"`
+	Timezone    *string   `json:"timezone"`
+
+	// Status flow: pending → processing → sent / failed; pending → cancelled
+	Status            string     `gorm:"not null;default:'pending';index" json:"status"`
+	Attempts          int        `gorm:"not null;default:0" json:"attempts"`
+	LastError         *string    `gorm:"type:text" json:"last_error,omitempty"`
+	ProcessingStarted *time.Time `json:"-"`
+	MessageID         *uint      `json:"message_id"` // the delivered message
+	SentAt            *time.Time `json:"sent_at"`
+	CancelledAt       *time.Time `json:"cancelled_at"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (ScheduledMessage) TableName() string {
+	return "scheduled_messages"
+}
+
+// SavedReply - A coach's reusable message body, picked from a list when replying to clients.
+// UsageCount drives the picker's ordering so the most-used replies come first.
+type SavedReply struct {
+	ID      uint   `gorm:"primaryKey" json:"id"`
+	CoachID uint   `gorm:"index;not null" json:"coach_id"`
+	Title   string `gorm:"size:100;not null" json:"title"`
+	Body    string `gorm:"type:text;not null" json:"body"`
+
+	UsageCount int `gorm:"not null;default:0" json:"usage_count"` // times expanded into a sent message
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (SavedReply) TableName() string {
+	return "saved_replies"
+}
@@ -0,0 +1,56 @@
+package models
+
+import "time"
+
+// Notification - In-app notification feed entry.
+// Push delivery is separate (outbox notification.push); this row is what the app's inbox shows.
+type Notification struct {
+	ID     uint `gorm:"primaryKey" json:"id"`
+	UserID uint `gorm:"not null;index:idx_notifications_user_created,priority:1" json:"user_id"`
+
+	Type  string  `gorm:"not null" json:"type"` // "data_export_ready", ...
+	Title string  `gorm:"not null" json:"title"`
+	Body  *string `gorm:"type:text" json:"body"`
+
+	// Data carries deep-link details for the app (ids, URLs); shape depends on Type
+	Data map[string]any `gorm:"type:jsonb;serializer:json" json:"data,omitempty"`
+
+	ReadAt *time.Time `gorm:"index" json:"read_at"`
+
+	CreatedAt time.Time `gorm:"index:idx_notifications_user_created,priority:2" json:"created_at"`
+
+	User User `gorm:"foreignKey:UserID" json:"-"`
+}
+
+func (Notification) TableName() string {
+	return "notifications"
+}
+
+// PushTicket - One push sent to one device token, with Expo's ticket and, once fetched, its receipt.
+// A push is delivered when both the ticket and the receipt are "ok". Kept for delivery statistics.
+type PushTicket struct {
+	ID    uint   `gorm:"primaryKey" json:"id"`
+	Token string `gorm:"not null;size:512;index" json:"-"` // DeviceToken.Token
+
+	// Ticket returned by the send call; TicketID is empty when the send was rejected outright
+	TicketID  *string `gorm:"size:100;uniqueIndex" json:"ticket_id"`
+	Status    string  `gorm:"not null" json:"status"` // "ok", "error"
+	ErrorCode *string `json:"error_code"`             // e.g. "DeviceNotRegistered"
+
+	// Receipt fetched by the maintenance worker about 15 minutes after sending; nil until then
+	ReceiptStatus    *string    `json:"receipt_status"` // "ok", "error"
+	ReceiptErrorCode *string    `json:"receipt_error_code"`
+	ReceiptCheckedAt *time.Time `json:"receipt_checked_at"`
+
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+}
+
+func (PushTicket) TableName() string {
+	return "push_tickets"
+}
+
+// DeviceToken.DeactivationReason values
+const (
+	DeviceTokenDeactivatedStale         = "stale"                 // no successful push for 90 days
+	DeviceTokenDeactivatedNotRegistered = "device_not_registered" // Expo reported the app uninstalled or the token revoked
+)
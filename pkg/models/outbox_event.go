@@ -19,8 +19,18 @@ type OutboxEvent struct {
 	AggregateID    string `gorm:"not null;index" json:"aggregate_id"`   // string to support both numeric and external IDs
 	IdempotencyKey string `gorm:"uniqueIndex;not null" json:"idempotency_key"`
 
-	// Payload is JSON encoded event data.
-	Payload string `gorm:"type:jsonb;not null" json:"payload"`
+	// SchemaVersion is the version of the payload's shape for its event type; the dispatcher upgrades
+	// older versions before handlers see them. Rows enqueued before versioning count as version 1.
+	SchemaVersion int `gorm:"not null;default:1" json:"schema_version"`
+
+	// Payload is JSON encoded event data. Large payloads are gzipped and stored as a base64 JSON string
+	// with PayloadCompressed set; the dispatcher expands them before handlers run.
+	Payload           string `gorm:"type:jsonb;not null" json:"payload"`
+	PayloadCompressed bool   `gorm:"not null;default:false" json:"payload_compressed"`
+
+	// Metadata holds context about where the event was published, currently the publishing request's
+	// W3C trace context (traceparent/tracestate) so processing spans can link back to it.
+	Metadata map[string]string `gorm:"type:jsonb;serializer:json" json:"metadata,omitempty"`
 
 	Status            string     `gorm:"not null;default:'pending';index:idx_outbox_status_available,priority:1;index:idx_outbox_type_status,priority:2" json:"status"`
 	Attempts          int        `gorm:"not null;default:0" json:"attempts"` // failed attempts count
@@ -44,3 +44,113 @@ type ProgressPhoto struct {
 func (ProgressPhoto) TableName() string {
 	return "progress_photos"
 }
+
+// ClientGoal - A trackable goal for one coach-client relationship. Either side can create and
+// update it; progress entries are stored in client_goal_progress.
+type ClientGoal struct {
+	ID              uint `gorm:"primaryKey" json:"id"`
+	ClientProfileID uint `gorm:"index;not null" json:"client_profile_id"`
+
+	Title       string   `gorm:"not null" json:"title"`
+	MetricType  string   `gorm:"not null" json:"metric_type"` // "weight", "strength", "habit", "custom"
+	TargetValue *float64 `json:"target_value"`
+	TargetUnit  *string  `json:"target_unit"`                                   // "lbs", "kg", "reps", "days"
+	TargetDate  *string  `gorm:"type:date" json:"target_date"`                  // "2026-06-30"
+	Status      string   `gorm:"not null;default:'active';index" json:"status"` // "active", "achieved", "abandoned"
+
+	AchievedAt      *time.Time `json:"achieved_at"`
+	CreatedByUserID uint       `gorm:"not null" json:"created_by_user_id"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// Filled in by list and detail endpoints; not a column
+	LatestProgress *ClientGoalProgress `gorm:"-" json:"latest_progress,omitempty"`
+}
+
+func (ClientGoal) TableName() string {
+	return "client_goals"
+}
+
+// ClientGoalProgress - One dated progress value recorded against a goal.
+type ClientGoalProgress struct {
+	ID     uint `gorm:"primaryKey" json:"id"`
+	GoalID uint `gorm:"index;not null" json:"goal_id"`
+
+	Value      float64 `gorm:"not null" json:"value"`
+	RecordedOn string  `gorm:"type:date;not null" json:"recorded_on"` // "2026-03-01"
+	Note       *string `gorm:"type:text" json:"note"`
+
+	RecordedByUserID uint      `gorm:"not null" json:"recorded_by_user_id"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+func (ClientGoalProgress) TableName() string {
+	return "client_goal_progress"
+}
+
+// ActivitySample - One reading synced from HealthKit or Google Fit. Values are stored in a canonical
+// unit per type, and re-syncs of the same reading are dropped by the (client, type, start, source)
+// unique index.
+type ActivitySample struct {
+	ID              uint `gorm:"primaryKey" json:"id"`
+	ClientProfileID uint `gorm:"not null;uniqueIndex:idx_activity_samples_dedupe,priority:1" json:"client_profile_id"`
+
+	Type  string  `gorm:"size:20;not null;uniqueIndex:idx_activity_samples_dedupe,priority:2" json:"type"` // "steps", "active_energy", "distance", "workout"
+	Value float64 `gorm:"not null" json:"value"`
+	Unit  string  `gorm:"size:10;not null" json:"unit"` // "count", "kcal", "m", "min"
+
+	StartTime time.Time `gorm:"not null;uniqueIndex:idx_activity_samples_dedupe,priority:3" json:"start_time"`
+	EndTime   time.Time `gorm:"not null" json:"end_time"`
+	Source    string    `gorm:"size:100;not null;uniqueIndex:idx_activity_samples_dedupe,priority:4" json:"source"` // e.g. "healthkit", "google_fit"
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (ActivitySample) TableName() string {
+	return "activity_samples"
+}
+
+// Milestone kinds
+const (
+	MilestoneKindTotalWorkouts = "total_workouts"
+)
+
+// ClientStreak - A user's weekly workout streak across all of their coaches. Rebuilt from completed
+// workouts each time one is completed, so it is a cache of workout history rather than a source of
+// truth. Weeks start Monday in Timezone.
+type ClientStreak struct {
+	ID     uint `gorm:"primaryKey" json:"-"`
+	UserID uint `gorm:"uniqueIndex;not null" json:"user_id"`
+
+	WeeklyTarget         int     `gorm:"not null" json:"weekly_target"` // completed workouts a week needs to count
+	CurrentWeeks         int     `gorm:"not null;default:0" json:"current_weeks"`
+	LongestWeeks         int     `gorm:"not null;default:0" json:"longest_weeks"`
+	CurrentStreakEndWeek *string `gorm:"type:date" json:"current_streak_end_week"` // Monday of the last week in the current streak
+	TotalCompleted       int     `gorm:"not null;default:0" json:"total_completed"`
+	Timezone             string  `gorm:"size:64;not null;default:'UTC'" json:"timezone"`
+
+	LastCompletedAt *time.Time `json:"last_completed_at"`
+	CreatedAt       time.Time  `json:"-"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+func (ClientStreak) TableName() string {
+	return "client_streaks"
+}
+
+// ClientMilestone - A milestone a user has reached, such as their 50th completed workout. The unique
+// index makes each milestone celebrate only once.
+type ClientMilestone struct {
+	ID     uint `gorm:"primaryKey" json:"id"`
+	UserID uint `gorm:"not null;uniqueIndex:idx_client_milestones_user_kind_value,priority:1" json:"-"`
+
+	Kind  string `gorm:"size:30;not null;uniqueIndex:idx_client_milestones_user_kind_value,priority:2" json:"kind"` // "total_workouts"
+	Value int    `gorm:"not null;uniqueIndex:idx_client_milestones_user_kind_value,priority:3" json:"value"`
+
+	ReachedAt time.Time `gorm:"not null" json:"reached_at"`
+}
+
+func (ClientMilestone) TableName() string {
+	return "client_milestones"
+}
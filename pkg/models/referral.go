@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+// ReferralCredit.Reason values
+const (
+	ReferralCreditReasonReferral = "referral"
+)
+
+// Referral - one coach bringing another onto the platform. Recorded when the referred coach completes
+// onboarding; a coach can only be referred once.
+type Referral struct {
+	ID              uint      `gorm:"primaryKey" json:"id"`
+	ReferrerCoachID uint      `gorm:"index;not null" json:"referrer_coach_id"`
+	ReferredCoachID uint      `gorm:"uniqueIndex;not null" json:"referred_coach_id"`
+	Code            string    `gorm:"size:16;not null" json:"code"` // referrer's code the referred coach entered
+	CompletedAt     time.Time `gorm:"not null" json:"completed_at"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (Referral) TableName() string {
+	return "referrals"
+}
+
+// ReferralCredit - a ledger entry crediting a coach. The balance is the sum of unconsumed entries;
+// billing sets ConsumedAt when it applies a credit.
+type ReferralCredit struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	CoachID     uint       `gorm:"index;not null" json:"coach_id"`
+	AmountCents int        `gorm:"not null" json:"amount_cents"`
+	Reason      string     `gorm:"not null" json:"reason"`         // "referral"
+	ReferralID  *uint      `gorm:"uniqueIndex" json:"referral_id"` // one credit per completed referral
+	ConsumedAt  *time.Time `gorm:"index" json:"consumed_at"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (ReferralCredit) TableName() string {
+	return "referral_credits"
+}
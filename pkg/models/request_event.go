@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// RequestEvent is one authenticated API request, recorded by the opt-in request analytics sink
+// so support can see what a user did. Rows are kept for 7 days.
+type RequestEvent struct {
+	ID uint `gorm:"primaryKey" json:"id"`
+
+	UserID    uint   `gorm:"not null;index:idx_request_events_user_created,priority:1" json:"user_id"`
+	Method    string `gorm:"not null" json:"method"`
+	Route     string `gorm:"not null" json:"route"` // route template, e.g. /api/v1/workouts/:id
+	Status    int    `gorm:"not null" json:"status"`
+	LatencyMs int    `gorm:"not null" json:"latency_ms"`
+	RequestID string `json:"request_id,omitempty"`
+
+	CreatedAt time.Time `gorm:"not null;index:idx_request_events_user_created,priority:2;index" json:"created_at"`
+}
+
+func (RequestEvent) TableName() string {
+	return "request_events"
+}
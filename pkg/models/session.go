@@ -24,6 +24,30 @@ func (CoachAvailability) TableName() string {
 	return "coach_availabilities"
 }
 
+// AvailabilityPreset - A named snapshot of a coach's weekly availability ("Summer hours") that can be
+// applied later to replace the current schedule.
+type AvailabilityPreset struct {
+	ID      uint   `gorm:"primaryKey" json:"id"`
+	CoachID uint   `gorm:"not null;uniqueIndex:idx_availability_preset_name" json:"coach_id"`
+	Name    string `gorm:"size:100;not null;uniqueIndex:idx_availability_preset_name" json:"name"`
+
+	Slots []AvailabilityPresetSlot `gorm:"type:jsonb;serializer:json;not null" json:"slots"` // active slots only
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (AvailabilityPreset) TableName() string {
+	return "availability_presets"
+}
+
+// AvailabilityPresetSlot is one weekly window in a preset, in the same shape as CoachAvailability.
+type AvailabilityPresetSlot struct {
+	DayOfWeek int    `json:"day_of_week"`
+	StartTime string `json:"start_time"` // "09:00" (UTC)
+	EndTime   string `json:"end_time"`
+}
+
 // CoachAvailabilityOverride - Date-specific exceptions to recurring availability.
 // Used to block off days (vacation) or add extra availability (working a Saturday).
 type CoachAvailabilityOverride struct {
@@ -49,8 +73,7 @@ func (CoachAvailabilityOverride) TableName() string {
 	return "coach_availability_overrides"
 }
 
-// SessionType - Types of sessions a coach offers with defined durations.
-// Enables future per-type pricing and consistent booking experience.
+// SessionType - Types of sessions a coach offers with defined durations and optional pricing.
 type SessionType struct {
 	ID      uint `gorm:"primaryKey" json:"id"`
 	CoachID uint `gorm:"index;not null" json:"coach_id"`
@@ -61,6 +84,20 @@ type SessionType struct {
 	Color           *string `json:"color"`                       // hex color for calendar display
 	IsActive        bool    `gorm:"default:true" json:"is_active"`
 
+	// Group sessions - 1 means one-on-one; higher values let clients join the same time slot
+	MaxParticipants int `gorm:"not null;default:1" json:"max_participants"`
+
+	// Pricing - nil price means the coach doesn't charge per session (e.g. covered by a package)
+	Price         *float64 `gorm:"type:numeric(10,2)" json:"price"`
+	PriceCurrency string   `gorm:"default:'USD'" json:"price_currency"`
+
+	// Booking visibility - coach-only types (e.g. comp sessions) are hidden from clients and can only be booked by the coach
+	BookableByClient bool `gorm:"not null;default:true" json:"bookable_by_client"`
+	DisplayOrder     int  `gorm:"not null;default:0" json:"display_order"` // ascending position in the booking UI
+
+	// Client bookings start as pending_confirmation until the coach confirms or declines them
+	RequiresConfirmation bool `gorm:"not null;default:false" json:"requires_confirmation"`
+
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 
@@ -71,6 +108,26 @@ func (SessionType) TableName() string {
 	return "session_types"
 }
 
+// CoachTimeBlock - Personal time a coach blocks off ("Dentist", "Lunch").
+// Blocks consume availability like a session, so clients can't book over them.
+type CoachTimeBlock struct {
+	ID      uint `gorm:"primaryKey" json:"id"`
+	CoachID uint `gorm:"index;not null" json:"coach_id"`
+
+	StartAt time.Time `gorm:"not null;index" json:"start_at"` // UTC
+	EndAt   time.Time `gorm:"not null" json:"end_at"`         // UTC
+	Label   *string   `json:"label"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	Coach CoachProfile `gorm:"foreignKey:CoachID" json:"-"`
+}
+
+func (CoachTimeBlock) TableName() string {
+	return "coach_time_blocks"
+}
+
 // Session - A booked session between a coach and client.
 // Tracks full lifecycle from scheduled through completion or cancellation.
 type Session struct {
@@ -82,26 +139,104 @@ type Session struct {
 	ScheduledAt     time.Time `gorm:"not null;index" json:"scheduled_at"` // UTC
 	DurationMinutes int       `gorm:"not null" json:"duration_minutes"`
 
-	// Status flow: scheduled → completed / cancelled / no_show
+	// Status flow: [pending_confirmation →] scheduled → completed / cancelled / no_show
+	// A pending session is confirmed to scheduled or declined to cancelled; it holds its slot meanwhile.
 	Status   string  `gorm:"default:'scheduled';index" json:"status"`
 	Location *string `json:"location"`
 	Notes    *string `gorm:"type:text" json:"notes"`
 
+	// Sessions of one recurring series share a RecurrenceGroupID; nil for one-off sessions
+	RecurrenceGroupID *string `gorm:"size:36;index" json:"recurrence_group_id"`
+
 	// Cancellation tracking - who cancelled and why
 	CancelledAt        *time.Time `json:"cancelled_at"`
-	CancelledBy        *string    `json:"cancelled_by"`         // "coach" or "client"
+	CancelledBy        *string    `json:"cancelled_by"`         // "coach", "client" or "admin"
 	CancellationReason *string    `gorm:"type:text" json:"cancellation_reason"`
+	LateCancelled      bool       `gorm:"not null;default:false" json:"late_cancelled"` // client cancelled inside the coach's window
 
 	CompletedAt *time.Time `json:"completed_at"`
 
+	// Client check-in - distance is only recorded when the client sent coordinates and the
+	// coach's primary location has them; OutsideRadius flags check-ins beyond the allowed radius
+	CheckedInAt           *time.Time `json:"checked_in_at"`
+	CheckInLatitude       *float64   `json:"check_in_latitude"`
+	CheckInLongitude      *float64   `json:"check_in_longitude"`
+	CheckInDistanceMeters *float64   `json:"check_in_distance_meters"`
+	CheckInOutsideRadius  bool       `gorm:"not null;default:false" json:"check_in_outside_radius"`
+
+	// Set when the coach was prompted to resolve the session after it went stale, so they're only asked once
+	StalePromptedAt *time.Time `json:"stale_prompted_at,omitempty"`
+
+	// Group capacity snapshot from the session type; ParticipantCount counts booked participants.
+	// ClientID stays the client who created the session.
+	MaxParticipants  int `gorm:"not null;default:1" json:"max_participants"`
+	ParticipantCount int `gorm:"not null;default:1" json:"participant_count"`
+
+	// Pricing snapshot taken at booking so later session type price changes don't rewrite history
+	Price         *float64   `gorm:"type:numeric(10,2)" json:"price"`
+	PriceCurrency *string    `json:"price_currency"`
+	PaidAt        *time.Time `json:"paid_at"`
+
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 
 	Coach       CoachProfile  `gorm:"foreignKey:CoachID" json:"coach,omitempty"`
 	Client      ClientProfile `gorm:"foreignKey:ClientID" json:"client,omitempty"`
 	SessionType SessionType   `gorm:"foreignKey:SessionTypeID" json:"session_type,omitempty"`
+
+	Participants []SessionParticipant `gorm:"foreignKey:SessionID" json:"participants,omitempty"`
 }
 
 func (Session) TableName() string {
 	return "sessions"
 }
+
+// SessionStatusPendingConfirmation marks a booking of a RequiresConfirmation session type awaiting the coach.
+const SessionStatusPendingConfirmation = "pending_confirmation"
+
+const (
+	SessionParticipantStatusBooked    = "booked"
+	SessionParticipantStatusCancelled = "cancelled"
+)
+
+// SessionParticipant - A client booked into a session.
+// Every session has at least one row (the creating client); group sessions have one per joined client.
+type SessionParticipant struct {
+	ID        uint `gorm:"primaryKey" json:"id"`
+	SessionID uint `gorm:"not null;uniqueIndex:idx_session_participant" json:"session_id"`
+	ClientID  uint `gorm:"not null;uniqueIndex:idx_session_participant;index" json:"client_id"`
+
+	// Status: booked → cancelled (a participant leaving, or the whole session being cancelled)
+	Status             string     `gorm:"not null;default:'booked'" json:"status"` // SessionParticipantStatus*
+	CancelledAt        *time.Time `json:"cancelled_at"`
+	CancellationReason *string    `gorm:"type:text" json:"cancellation_reason"`
+	LateCancelled      bool       `gorm:"not null;default:false" json:"late_cancelled"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	Client ClientProfile `gorm:"foreignKey:ClientID" json:"client,omitempty"`
+}
+
+func (SessionParticipant) TableName() string {
+	return "session_participants"
+}
+
+// SessionFeedback - A client's rating of a completed session, one per session.
+// Anonymous hides the client from the coach's feedback list; the row still records who left it.
+type SessionFeedback struct {
+	ID        uint `gorm:"primaryKey" json:"id"`
+	SessionID uint `gorm:"uniqueIndex;not null" json:"session_id"`
+	CoachID   uint `gorm:"index;not null" json:"coach_id"`
+	ClientID  uint `gorm:"index;not null" json:"-"`
+
+	Rating    int     `gorm:"not null" json:"rating"` // 1-5
+	Comment   *string `gorm:"type:text" json:"comment"`
+	Anonymous bool    `gorm:"not null;default:false" json:"anonymous"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (SessionFeedback) TableName() string {
+	return "session_feedbacks"
+}
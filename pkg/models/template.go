@@ -11,15 +11,23 @@ type WorkoutTemplate struct {
 	Name        string  `gorm:"not null" json:"name"`
 	Description *string `gorm:"type:text" json:"description"`
 
-	// Categorization for coach's template library
-	Category *string  `json:"category"` // "upper_body", "lower_body", "full_body", "cardio", "recovery"
-	Tags     []string `gorm:"type:text[];serializer:json" json:"tags"`
+	// Categorization for coach's template library. Category is deprecated in favour of CategoryID and is
+	// kept in sync with the category's name until clients stop reading it.
+	CategoryID *uint    `gorm:"index" json:"category_id"`
+	Category   *string  `json:"category"`
+	Tags       []string `gorm:"type:text[];serializer:json" json:"tags"`
 
-	// Estimated duration helps with scheduling
+	// Estimated duration helps with scheduling; computed from the prescription unless the coach sets it
 	EstimatedMinutes *int `json:"estimated_minutes"`
 
+	// Derived from the exercises and recomputed whenever they change
+	Metrics *WorkoutMetrics `gorm:"type:jsonb;serializer:json" json:"metrics,omitempty"`
+
 	IsActive bool `gorm:"default:true;index" json:"is_active"`
 
+	// Incremented by every update so an edit made against a stale copy can be detected
+	Version int `gorm:"not null;default:1" json:"version"`
+
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 
@@ -31,6 +39,31 @@ func (WorkoutTemplate) TableName() string {
 	return "workout_templates"
 }
 
+// TemplateCategory - A coach's named group of templates. Names are unique per coach ignoring case,
+// and templates reference categories by ID so renames apply everywhere at once.
+type TemplateCategory struct {
+	ID      uint   `gorm:"primaryKey" json:"id"`
+	CoachID uint   `gorm:"index;not null" json:"coach_id"`
+	Name    string `gorm:"not null" json:"name"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (TemplateCategory) TableName() string {
+	return "template_categories"
+}
+
+// WorkoutMetrics - Prescribed totals for a template, copied onto workouts at assignment.
+// Volume is sets x reps x weight in kg and only counts exercises prescribed in lbs or kg.
+type WorkoutMetrics struct {
+	TotalExercises      int                `json:"total_exercises"`
+	TotalSets           int                `json:"total_sets"`
+	EstimatedMinutes    int                `json:"estimated_minutes"`
+	TotalVolumeKg       float64            `json:"total_volume_kg"`
+	VolumeByMuscleGroup map[string]float64 `json:"volume_by_muscle_group"` // keyed by primary muscle group
+}
+
 // WorkoutTemplateExercise - Individual exercise within a template with prescribed sets/reps/weight.
 // Uses structured fields for progress tracking with a free-text fallback for unusual prescriptions.
 type WorkoutTemplateExercise struct {
@@ -44,7 +77,7 @@ type WorkoutTemplateExercise struct {
 
 	// Superset/circuit grouping - exercises sharing the same group number are performed together
 	SupersetGroup *int    `json:"superset_group"`
-	GroupType     *string `json:"group_type"` // "superset", "circuit", "drop_set"
+	GroupType     *string `json:"group_type"` // "superset", "circuit", "giant_set"
 
 	// Structured prescription for tracking and analytics
 	Sets      *int     `json:"sets"`
@@ -53,6 +86,11 @@ type WorkoutTemplateExercise struct {
 	WeightValue *float64 `json:"weight_value"`
 	WeightUnit  *string  `json:"weight_unit"` // "lbs", "kg", "percent_1rm", "rpe", "bodyweight"
 
+	// Time and distance targets, for exercises whose measurement_type is "time" or "distance"
+	PrescribedDurationSeconds *int     `json:"prescribed_duration_seconds"`
+	PrescribedDistance        *float64 `json:"prescribed_distance"`
+	PrescribedDistanceUnit    *string  `json:"prescribed_distance_unit"` // "miles", "km", "meters"
+
 	// Free-text override for anything the structured fields can't capture
 	PrescriptionNote *string `gorm:"type:text" json:"prescription_note"` // "AMRAP", "work up to heavy single", etc.
 
@@ -70,3 +108,54 @@ type WorkoutTemplateExercise struct {
 func (WorkoutTemplateExercise) TableName() string {
 	return "workout_template_exercises"
 }
+
+// TemplateVersion - Snapshot of a template taken each time its exercises are replaced, so coaches can
+// see what changed and roll back. Version numbers increase per template; only the newest 50 are kept.
+type TemplateVersion struct {
+	ID         uint `gorm:"primaryKey" json:"id"`
+	TemplateID uint `gorm:"not null;uniqueIndex:idx_template_versions_version" json:"template_id"`
+	Version    int  `gorm:"not null;uniqueIndex:idx_template_versions_version" json:"version"`
+
+	Snapshot   TemplateSnapshot `gorm:"type:jsonb;serializer:json" json:"snapshot"`
+	ChangeNote *string          `gorm:"type:text" json:"change_note"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (TemplateVersion) TableName() string {
+	return "template_versions"
+}
+
+// TemplateSnapshot - The template and its exercises as they were when the version was recorded.
+type TemplateSnapshot struct {
+	Name             string                     `json:"name"`
+	Description      *string                    `json:"description"`
+	Category         *string                    `json:"category"`
+	Tags             []string                   `json:"tags"`
+	EstimatedMinutes *int                       `json:"estimated_minutes"`
+	Exercises        []TemplateSnapshotExercise `json:"exercises"`
+}
+
+// TemplateSnapshotExercise - One prescribed exercise in a TemplateSnapshot. ExerciseName is copied
+// for display only; restores use ExerciseID.
+type TemplateSnapshotExercise struct {
+	ExerciseID       uint     `json:"exercise_id"`
+	ExerciseName     string   `json:"exercise_name,omitempty"`
+	OrderIndex       int      `json:"order_index"`
+	SectionLabel     *string  `json:"section_label"`
+	SupersetGroup    *int     `json:"superset_group"`
+	GroupType        *string  `json:"group_type"`
+	Sets             *int     `json:"sets"`
+	RepsMin          *int     `json:"reps_min"`
+	RepsMax          *int     `json:"reps_max"`
+	WeightValue      *float64 `json:"weight_value"`
+	WeightUnit       *string  `json:"weight_unit"`
+	PrescriptionNote *string  `json:"prescription_note"`
+	RestSeconds      *int     `json:"rest_seconds"`
+	Tempo            *string  `json:"tempo"`
+	Notes            *string  `json:"notes"`
+
+	PrescribedDurationSeconds *int     `json:"prescribed_duration_seconds"`
+	PrescribedDistance        *float64 `json:"prescribed_distance"`
+	PrescribedDistanceUnit    *string  `json:"prescribed_distance_unit"`
+}
@@ -21,6 +21,7 @@ type User struct {
 	// Account status
 	IsActive bool `gorm:"default:true" json:"is_active"`
 	IsBanned bool `gorm:"default:false" json:"is_banned"`
+	IsAdmin  bool `gorm:"not null;default:false" json:"-"` // Platform operator; granted directly in the database
 
 	// Activity tracking
 	LastLoginAt *time.Time `json:"last_login_at"`
@@ -54,6 +55,18 @@ type Profile struct {
 	AvatarURL *string `json:"avatar_url"`
 	Phone     *string `json:"phone"`
 	Timezone  string  `gorm:"default:'UTC'" json:"timezone"`
+	Locale    string  `gorm:"size:10;not null;default:'en'" json:"locale"` // language for notifications, see pkg/i18n
+
+	// Units weights and distances are shown in when a request asks for units=preferred, see pkg/units
+	WeightUnit   string `gorm:"size:3;not null;default:'kg'" json:"weight_unit"`   // "kg", "lbs"
+	DistanceUnit string `gorm:"size:2;not null;default:'km'" json:"distance_unit"` // "km", "mi"
+
+	// Reminders for workouts scheduled today, sent at these local hours (0-23) in Timezone. The evening
+	// "still pending" follow-up is opt-in
+	WorkoutRemindersEnabled       bool `gorm:"not null;default:true" json:"workout_reminders_enabled"`
+	WorkoutReminderHour           int  `gorm:"not null;default:7" json:"workout_reminder_hour"`
+	WorkoutEveningReminderEnabled bool `gorm:"not null;default:false" json:"workout_evening_reminder_enabled"`
+	WorkoutEveningReminderHour    int  `gorm:"not null;default:19" json:"workout_evening_reminder_hour"`
 
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
@@ -101,9 +114,19 @@ type RefreshToken struct {
 	Revoked   bool      `gorm:"default:false;index" json:"revoked"`
 	RevokedAt *time.Time `json:"revoked_at"`
 
+	// Rotation family - every token minted by refreshing inherits the family of the login that
+	// started it. Presenting a rotated (revoked) token revokes the whole family, and no token in
+	// the family can be refreshed after FamilyExpiresAt.
+	FamilyID        string     `gorm:"size:64;not null;default:'';index" json:"-"`
+	FamilyExpiresAt *time.Time `json:"family_expires_at"`
+
 	// Device/session tracking
 	DeviceInfo *string `gorm:"type:text" json:"device_info"` // User agent
+	// Client-generated install ID; a new login from the same device revokes its earlier tokens
+	DeviceID *string `gorm:"size:128" json:"device_id"`
 	IPAddress  *string `json:"ip_address"`
+	// ISO country the login's IP resolved to; null when geolocation is off or couldn't place it
+	Country *string `gorm:"size:2" json:"country"`
 
 	// Last used (for cleanup of stale tokens)
 	LastUsedAt *time.Time `json:"last_used_at"`
@@ -118,6 +141,18 @@ func (RefreshToken) TableName() string {
 	return "refresh_tokens"
 }
 
+// AccessTokenRevocation - Access tokens for UserID issued before RevokedAt are rejected.
+// Redis caches this per user; the table is the fallback when Redis is unavailable.
+type AccessTokenRevocation struct {
+	UserID    uint      `gorm:"primaryKey;autoIncrement:false" json:"user_id"`
+	RevokedAt time.Time `gorm:"not null" json:"revoked_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (AccessTokenRevocation) TableName() string {
+	return "access_token_revocations"
+}
+
 // DeviceToken - Push notification tokens (Expo)
 type DeviceToken struct {
 	ID       uint   `gorm:"primaryKey" json:"id"`
@@ -132,7 +167,11 @@ type DeviceToken struct {
 	OSVersion  *string `json:"os_version"`  // "iOS 17.2"
 
 	// Activity tracking
-	LastUsedAt *time.Time `json:"last_used_at"`
+	LastUsedAt *time.Time `json:"last_used_at"` // last push Expo accepted for this token
+
+	// Deactivated tokens are kept for debugging; registering the same token again reactivates it
+	DeactivatedAt      *time.Time `json:"deactivated_at"`
+	DeactivationReason *string    `json:"deactivation_reason"` // "stale", "device_not_registered"
 
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+const (
+	WebhookProviderRevenueCat = "revenuecat"
+
+	WebhookInboxStatusPending   = "pending"   // stored, waiting on the outbox
+	WebhookInboxStatusProcessed = "processed" // applied
+	WebhookInboxStatusFailed    = "failed"    // bad payload or processing error; see Error
+	WebhookInboxStatusIgnored   = "ignored"   // test or unsupported event type, nothing to apply
+)
+
+// WebhookInboxEntry is an authenticated webhook delivery as it arrived, stored before any processing
+// so it can be inspected and replayed. One row per provider event; redeliveries are deduped on
+// (provider, external_event_id).
+type WebhookInboxEntry struct {
+	ID uint `gorm:"primaryKey" json:"id"`
+
+	Provider        string  `gorm:"not null;uniqueIndex:idx_webhook_inbox_provider_event,priority:1" json:"provider"` // "revenuecat", later "stripe"
+	ExternalEventID string  `gorm:"not null;uniqueIndex:idx_webhook_inbox_provider_event,priority:2" json:"external_event_id"`
+	EventType       *string `json:"event_type"`
+
+	// Body is kept as text rather than jsonb so malformed deliveries are stored verbatim
+	Body    string            `gorm:"type:text;not null" json:"body"`
+	Headers map[string]string `gorm:"type:jsonb;serializer:json" json:"headers,omitempty"` // allowlisted subset, never Authorization
+
+	Status      string     `gorm:"not null;default:'pending';index:idx_webhook_inbox_status_received,priority:1" json:"status"`
+	Attempts    int        `gorm:"not null;default:0" json:"attempts"` // processing runs, replays included
+	Error       *string    `gorm:"type:text" json:"error"`
+	ReceivedAt  time.Time  `gorm:"not null;index:idx_webhook_inbox_status_received,priority:2" json:"received_at"`
+	ProcessedAt *time.Time `json:"processed_at"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (WebhookInboxEntry) TableName() string {
+	return "webhook_inbox"
+}
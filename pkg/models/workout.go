@@ -16,8 +16,17 @@ type Workout struct {
 	Description   *string `gorm:"type:text" json:"description"`
 	ScheduledDate *string `gorm:"type:date;index" json:"scheduled_date"` // "2026-02-15"
 
+	// Copied from the template at assignment so clients see the expected duration
+	EstimatedMinutes *int           `json:"estimated_minutes"`
+	Metrics          *WorkoutMetrics `gorm:"type:jsonb;serializer:json" json:"metrics,omitempty"`
+
+	// Set when the coach deliberately assigned a second workout for the same date; only one
+	// non-skipped workout per client and date may leave this false
+	DuplicateAllowed bool `gorm:"not null;default:false" json:"duplicate_allowed"`
+
 	// Status flow: scheduled → in_progress → completed / skipped
 	Status      string     `gorm:"default:'scheduled';index" json:"status"`
+	SkipReason  *string    `gorm:"type:text" json:"skip_reason"` // set when skipped on the client's behalf, e.g. a coach transfer
 	StartedAt   *time.Time `json:"started_at"`
 	CompletedAt *time.Time `json:"completed_at"`
 
@@ -25,6 +34,17 @@ type Workout struct {
 	ClientNotes *string `gorm:"type:text" json:"client_notes"`
 	CoachNotes  *string `gorm:"type:text" json:"coach_notes"`
 
+	// Proof of completion - only set by the client when completing the workout
+	CompletionNote     *string `gorm:"type:text" json:"completion_note"`
+	CompletionPhotoURL *string `json:"completion_photo_url"`
+
+	// Set when the scheduled-date reminder and the evening follow-up are claimed, so each goes out once
+	ReminderSentAt        *time.Time `json:"-"`
+	EveningReminderSentAt *time.Time `json:"-"`
+
+	// Resumable in-progress state synced across the client's devices; cleared on complete/skip
+	SessionState *WorkoutSessionState `gorm:"type:jsonb;serializer:json" json:"session_state,omitempty"`
+
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 
@@ -38,6 +58,15 @@ func (Workout) TableName() string {
 	return "workouts"
 }
 
+// WorkoutSessionState - Where the client is inside an in-progress workout (active exercise, rest timer, etc.)
+// so another device can pick up where they left off. ClientState is opaque to the API.
+type WorkoutSessionState struct {
+	CurrentExerciseID *uint          `json:"current_exercise_id,omitempty"` // workout_exercises.id
+	StartedAt         *time.Time     `json:"started_at,omitempty"`
+	ClientState       map[string]any `json:"client_state,omitempty"`
+	UpdatedAt         time.Time      `json:"updated_at"`
+}
+
 // WorkoutExercise - Exercise within an assigned workout with completion tracking.
 // Mirrors template exercise structure but adds per-exercise completion status.
 type WorkoutExercise struct {
@@ -59,6 +88,10 @@ type WorkoutExercise struct {
 	WeightValue *float64 `json:"weight_value"`
 	WeightUnit  *string  `json:"weight_unit"`
 
+	PrescribedDurationSeconds *int     `json:"prescribed_duration_seconds"`
+	PrescribedDistance        *float64 `json:"prescribed_distance"`
+	PrescribedDistanceUnit    *string  `json:"prescribed_distance_unit"`
+
 	PrescriptionNote *string `gorm:"type:text" json:"prescription_note"`
 	RestSeconds      *int    `json:"rest_seconds"`
 	Tempo            *string `json:"tempo"`
@@ -68,6 +101,12 @@ type WorkoutExercise struct {
 	IsCompleted bool `gorm:"default:false;index" json:"is_completed"`
 	SkippedReason *string `json:"skipped_reason"` // why client skipped this exercise
 
+	// Set when the client's ClientExerciseDefault replaced some of the template's prescription
+	ClientDefaultApplied bool `gorm:"not null;default:false" json:"client_default_applied"`
+
+	// Substitutes the client can swap in; only filled on the workout detail response
+	Alternatives []ExerciseAlternative `gorm:"-" json:"alternatives,omitempty"`
+
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 
@@ -80,6 +119,31 @@ func (WorkoutExercise) TableName() string {
 	return "workout_exercises"
 }
 
+// ClientExerciseDefault - A coach's standing prescription for one exercise for one client
+// ("for Sarah, goblet squat = 35lb"). Assigning a template copies its exercises and then replaces
+// the template's values with every field set here; nil fields keep the template's value.
+type ClientExerciseDefault struct {
+	ID              uint `gorm:"primaryKey" json:"id"`
+	ClientProfileID uint `gorm:"not null;uniqueIndex:idx_client_exercise_defaults_client_exercise,priority:1" json:"client_profile_id"`
+	ExerciseID      uint `gorm:"not null;uniqueIndex:idx_client_exercise_defaults_client_exercise,priority:2" json:"exercise_id"`
+
+	WeightValue *float64 `json:"weight_value"`
+	WeightUnit  *string  `json:"weight_unit"` // "kg", "lbs"; set whenever WeightValue is
+	RepsMin     *int     `json:"reps_min"`
+	RepsMax     *int     `json:"reps_max"`
+	RestSeconds *int     `json:"rest_seconds"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	ClientProfile ClientProfile `gorm:"foreignKey:ClientProfileID" json:"-"`
+	Exercise      Exercise      `gorm:"foreignKey:ExerciseID" json:"exercise,omitempty"`
+}
+
+func (ClientExerciseDefault) TableName() string {
+	return "client_exercise_defaults"
+}
+
 // WorkoutLog - Actual performance data for a single set.
 // One row per set enables granular progress tracking and analytics.
 type WorkoutLog struct {
@@ -100,6 +164,12 @@ type WorkoutLog struct {
 	Distance        *float64 `json:"distance"`
 	DistanceUnit    *string  `json:"distance_unit"` // "miles", "km", "meters"
 
+	// Form-check video recorded by the client, reviewed by the coach with written feedback
+	VideoURL        *string    `json:"video_url"`
+	VideoUploadedAt *time.Time `json:"video_uploaded_at"`
+	FormFeedback    *string    `gorm:"type:text" json:"form_feedback"`
+	ReviewedAt      *time.Time `json:"reviewed_at"`
+
 	CreatedAt time.Time `json:"created_at"`
 
 	WorkoutExercise WorkoutExercise `gorm:"foreignKey:WorkoutExerciseID" json:"-"`
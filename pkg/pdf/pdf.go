@@ -0,0 +1,157 @@
+// Package pdf writes simple PDF 1.4 documents: text in the standard Helvetica fonts plus filled
+// rectangles and lines, which is enough for reports with tables and bar charts. Coordinates are in
+// points with the origin at the top-left of the page.
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// ContentType is the media type for .pdf files.
+const ContentType = "application/pdf"
+
+// US Letter, in points
+const (
+	PageWidth  = 612.0
+	PageHeight = 792.0
+)
+
+// Color is an RGB color with components from 0 to 1.
+type Color struct {
+	R, G, B float64
+}
+
+var (
+	Black     = Color{0, 0, 0}
+	Gray      = Color{0.55, 0.55, 0.55}
+	LightGray = Color{0.9, 0.9, 0.9}
+)
+
+// Document collects pages and renders them with Bytes.
+type Document struct {
+	pages []*Page
+}
+
+func New() *Document {
+	return &Document{}
+}
+
+// AddPage appends a blank page and returns it for drawing.
+func (d *Document) AddPage() *Page {
+	page := &Page{}
+	d.pages = append(d.pages, page)
+	return page
+}
+
+// Page holds the drawing operators of one page.
+type Page struct {
+	content bytes.Buffer
+}
+
+// Text draws s with its baseline at y. Characters outside Latin-1 are replaced with "?".
+func (p *Page) Text(x, y, size float64, bold bool, color Color, s string) {
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	fmt.Fprintf(&p.content, "BT %s rg /%s %s Tf %s %s Td (%s) Tj ET\n",
+		colorOperands(color), font, num(size), num(x), num(PageHeight-y), escapeText(s))
+}
+
+// Rect fills a rectangle whose top-left corner is (x, y).
+func (p *Page) Rect(x, y, width, height float64, color Color) {
+	fmt.Fprintf(&p.content, "%s rg %s %s %s %s re f\n",
+		colorOperands(color), num(x), num(PageHeight-y-height), num(width), num(height))
+}
+
+// Line strokes a straight line from (x1, y1) to (x2, y2).
+func (p *Page) Line(x1, y1, x2, y2, width float64, color Color) {
+	fmt.Fprintf(&p.content, "%s RG %s w %s %s m %s %s l S\n",
+		colorOperands(color), num(width), num(x1), num(PageHeight-y1), num(x2), num(PageHeight-y2))
+}
+
+// TextWidth estimates the width of s in Helvetica at size, for right-aligning and centering.
+// It uses an average glyph width, so it is approximate.
+func TextWidth(s string, size float64) float64 {
+	return float64(len([]rune(s))) * size * 0.5
+}
+
+// Bytes renders the document. A document without pages gets one blank page.
+func (d *Document) Bytes() []byte {
+	pages := d.pages
+	if len(pages) == 0 {
+		pages = []*Page{{}}
+	}
+
+	// Objects: 1 catalog, 2 page tree, 3-4 fonts, then a page and its content stream per page.
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"", // page tree, filled in once page object numbers are known
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>",
+	}
+	kids := make([]string, 0, len(pages))
+	for _, page := range pages {
+		pageNumber := len(objects) + 1
+		contentNumber := pageNumber + 1
+		kids = append(kids, fmt.Sprintf("%d 0 R", pageNumber))
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+				num(PageWidth), num(PageHeight), contentNumber),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", page.content.Len(), page.content.String()),
+		)
+	}
+	objects[1] = fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages))
+
+	var out bytes.Buffer
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = out.Len()
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return out.Bytes()
+}
+
+// escapeText encodes s as a literal string body in WinAnsi (Latin-1 for the characters we accept).
+func escapeText(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '\n' || r == '\r' || r == '\t':
+			b.WriteByte(' ')
+		case r < 0x20 || r > 0xff:
+			b.WriteByte('?')
+		case r < 0x80:
+			b.WriteRune(r)
+		default:
+			fmt.Fprintf(&b, "\\%03o", r)
+		}
+	}
+	return b.String()
+}
+
+func colorOperands(c Color) string {
+	return num(c.R) + " " + num(c.G) + " " + num(c.B)
+}
+
+// num formats a coordinate compactly; PDF readers don't accept exponent notation.
+func num(v float64) string {
+	s := fmt.Sprintf("%.2f", v)
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	if s == "" || s == "-0" {
+		return "0"
+	}
+	return s
+}
@@ -1,11 +1,13 @@
 package repositories
 
 import (
+	"chalk-api/pkg/db"
 	"chalk-api/pkg/models"
 	"context"
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type AuthRepository struct {
@@ -24,7 +26,7 @@ func (r *AuthRepository) CreateRefreshToken(ctx context.Context, token *models.R
 
 func (r *AuthRepository) GetRefreshToken(ctx context.Context, tokenHash string) (*models.RefreshToken, error) {
 	var token models.RefreshToken
-	err := r.db.WithContext(ctx).
+	err := db.UsePrimary(r.db.WithContext(ctx)).
 		Where("token = ? AND revoked = ? AND expires_at > ?", tokenHash, false, time.Now()).
 		First(&token).Error
 	if err != nil {
@@ -33,6 +35,19 @@ func (r *AuthRepository) GetRefreshToken(ctx context.Context, tokenHash string)
 	return &token, nil
 }
 
+// FindRefreshToken looks a token up by hash whatever its state, so rotation can spot reuse of a
+// token that was already revoked
+func (r *AuthRepository) FindRefreshToken(ctx context.Context, tokenHash string) (*models.RefreshToken, error) {
+	var token models.RefreshToken
+	err := db.UsePrimary(r.db.WithContext(ctx)).
+		Where("token = ?", tokenHash).
+		First(&token).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
 func (r *AuthRepository) RevokeRefreshToken(ctx context.Context, id uint) error {
 	now := time.Now()
 	return r.db.WithContext(ctx).
@@ -44,6 +59,33 @@ func (r *AuthRepository) RevokeRefreshToken(ctx context.Context, id uint) error
 		}).Error
 }
 
+// RotateRefreshToken marks a token used and revoked. It returns false when another request already
+// rotated it, which callers treat as reuse.
+func (r *AuthRepository) RotateRefreshToken(ctx context.Context, id uint) (bool, error) {
+	now := time.Now()
+	result := r.db.WithContext(ctx).
+		Model(&models.RefreshToken{}).
+		Where("id = ? AND revoked = ?", id, false).
+		Updates(map[string]interface{}{
+			"revoked":      true,
+			"revoked_at":   now,
+			"last_used_at": now,
+		})
+	return result.RowsAffected == 1, result.Error
+}
+
+// RevokeRefreshFamily revokes every live token descended from the same login
+func (r *AuthRepository) RevokeRefreshFamily(ctx context.Context, familyID string) error {
+	now := time.Now()
+	return r.db.WithContext(ctx).
+		Model(&models.RefreshToken{}).
+		Where("family_id = ? AND revoked = ?", familyID, false).
+		Updates(map[string]interface{}{
+			"revoked":    true,
+			"revoked_at": now,
+		}).Error
+}
+
 // RevokeAllUserTokens revokes every refresh token for a user (logout everywhere)
 func (r *AuthRepository) RevokeAllUserTokens(ctx context.Context, userID uint) error {
 	now := time.Now()
@@ -56,9 +98,87 @@ func (r *AuthRepository) RevokeAllUserTokens(ctx context.Context, userID uint) e
 		}).Error
 }
 
+// RevokeDeviceTokens revokes the user's live refresh tokens issued to deviceID
+func (r *AuthRepository) RevokeDeviceTokens(ctx context.Context, userID uint, deviceID string) error {
+	now := time.Now()
+	return r.db.WithContext(ctx).
+		Model(&models.RefreshToken{}).
+		Where("user_id = ? AND device_id = ? AND revoked = ?", userID, deviceID, false).
+		Updates(map[string]interface{}{
+			"revoked":    true,
+			"revoked_at": now,
+		}).Error
+}
+
+// PruneDevicelessTokens revokes the user's live refresh tokens without a device ID beyond the newest
+// keep. They are revoked rather than deleted so a pruned token that comes back still counts as reuse.
+func (r *AuthRepository) PruneDevicelessTokens(ctx context.Context, userID uint, keep int) (int64, error) {
+	newest := r.db.
+		Model(&models.RefreshToken{}).
+		Select("id").
+		Where("user_id = ? AND device_id IS NULL AND revoked = ?", userID, false).
+		Order("created_at DESC, id DESC").
+		Limit(keep)
+	result := r.db.WithContext(ctx).
+		Model(&models.RefreshToken{}).
+		Where("user_id = ? AND device_id IS NULL AND revoked = ?", userID, false).
+		Where("id NOT IN (?)", newest).
+		Updates(map[string]interface{}{
+			"revoked":    true,
+			"revoked_at": time.Now(),
+		})
+	return result.RowsAffected, result.Error
+}
+
+// ListActiveRefreshTokens returns the user's unrevoked, unexpired refresh tokens, newest first
+func (r *AuthRepository) ListActiveRefreshTokens(ctx context.Context, userID uint) ([]models.RefreshToken, error) {
+	var tokens []models.RefreshToken
+	err := db.UsePrimary(r.db.WithContext(ctx)).
+		Where("user_id = ? AND revoked = ? AND expires_at > ?", userID, false, time.Now()).
+		Order("created_at DESC, id DESC").
+		Find(&tokens).Error
+	return tokens, err
+}
+
+// ListRecentLogins returns the user's refresh tokens created since the given time, revoked or not,
+// as the history new logins are compared against.
+func (r *AuthRepository) ListRecentLogins(ctx context.Context, userID uint, since time.Time) ([]models.RefreshToken, error) {
+	var tokens []models.RefreshToken
+	err := db.UsePrimary(r.db.WithContext(ctx)).
+		Select("id", "device_id", "device_info", "country", "created_at").
+		Where("user_id = ? AND created_at >= ?", userID, since).
+		Order("created_at DESC, id DESC").
+		Find(&tokens).Error
+	return tokens, err
+}
+
+// RecordTokenRevocation stores the cut-off before which the user's access tokens are rejected
+func (r *AuthRepository) RecordTokenRevocation(ctx context.Context, userID uint, revokedAt time.Time) error {
+	revocation := &models.AccessTokenRevocation{UserID: userID, RevokedAt: revokedAt}
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"revoked_at", "updated_at"}),
+		}).
+		Create(revocation).Error
+}
+
+func (r *AuthRepository) GetTokenRevocation(ctx context.Context, userID uint) (*models.AccessTokenRevocation, error) {
+	var revocation models.AccessTokenRevocation
+	err := db.UsePrimary(r.db.WithContext(ctx)).
+		Where("user_id = ?", userID).
+		First(&revocation).Error
+	if err != nil {
+		return nil, err
+	}
+	return &revocation, nil
+}
+
+// CleanupExpiredTokens deletes expired refresh tokens. Revoked tokens are kept until they expire so
+// a replayed rotated token is still recognized and revokes its family.
 func (r *AuthRepository) CleanupExpiredTokens(ctx context.Context) (int64, error) {
 	result := r.db.WithContext(ctx).
-		Where("expires_at < ? OR revoked = ?", time.Now(), true).
+		Where("expires_at < ?", time.Now()).
 		Delete(&models.RefreshToken{})
 	return result.RowsAffected, result.Error
 }
@@ -71,7 +191,7 @@ func (r *AuthRepository) CreatePasswordReset(ctx context.Context, reset *models.
 
 func (r *AuthRepository) GetPasswordReset(ctx context.Context, tokenHash string) (*models.PasswordReset, error) {
 	var reset models.PasswordReset
-	err := r.db.WithContext(ctx).
+	err := db.UsePrimary(r.db.WithContext(ctx)).
 		Where("token = ? AND used = ? AND expires_at > ?", tokenHash, false, time.Now()).
 		First(&reset).Error
 	if err != nil {
@@ -106,7 +226,7 @@ func (r *AuthRepository) CreateEmailVerification(ctx context.Context, verificati
 
 func (r *AuthRepository) GetEmailVerification(ctx context.Context, tokenHash string) (*models.EmailVerification, error) {
 	var verification models.EmailVerification
-	err := r.db.WithContext(ctx).
+	err := db.UsePrimary(r.db.WithContext(ctx)).
 		Where("token = ? AND used = ? AND expires_at > ?", tokenHash, false, time.Now()).
 		First(&verification).Error
 	if err != nil {
@@ -134,7 +254,7 @@ func (r *AuthRepository) CreateMagicLink(ctx context.Context, link *models.Magic
 
 func (r *AuthRepository) GetMagicLink(ctx context.Context, tokenHash string) (*models.MagicLink, error) {
 	var link models.MagicLink
-	err := r.db.WithContext(ctx).
+	err := db.UsePrimary(r.db.WithContext(ctx)).
 		Where("token = ? AND used = ? AND expires_at > ?", tokenHash, false, time.Now()).
 		First(&link).Error
 	if err != nil {
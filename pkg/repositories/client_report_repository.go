@@ -0,0 +1,91 @@
+package repositories
+
+import (
+	"chalk-api/pkg/db"
+	"chalk-api/pkg/models"
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ClientReportRepository struct {
+	db *gorm.DB
+}
+
+func NewClientReportRepository(db *gorm.DB) *ClientReportRepository {
+	return &ClientReportRepository{db: db}
+}
+
+// Create inserts a pending report. It returns false without an error when the client already has a
+// report in flight (enforced by idx_client_reports_client_in_flight).
+func (r *ClientReportRepository) Create(ctx context.Context, report *models.ClientReport) (bool, error) {
+	if err := r.db.WithContext(ctx).Create(report).Error; err != nil {
+		if isDuplicateKeyError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *ClientReportRepository) GetByID(ctx context.Context, id uint) (*models.ClientReport, error) {
+	var report models.ClientReport
+	err := db.UsePrimary(r.db.WithContext(ctx)).First(&report, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+func (r *ClientReportRepository) GetInFlightByClient(ctx context.Context, clientID uint) (*models.ClientReport, error) {
+	var report models.ClientReport
+	err := db.UsePrimary(r.db.WithContext(ctx)).
+		Where("client_id = ? AND status IN ?", clientID, []string{models.ClientReportStatusPending, models.ClientReportStatusProcessing}).
+		First(&report).Error
+	if err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// ListByClient returns the client's reports, newest first.
+func (r *ClientReportRepository) ListByClient(ctx context.Context, clientID uint, limit int) ([]models.ClientReport, error) {
+	var reports []models.ClientReport
+	err := r.db.WithContext(ctx).
+		Where("client_id = ?", clientID).
+		Order("created_at DESC, id DESC").
+		Limit(limit).
+		Find(&reports).Error
+	return reports, err
+}
+
+func (r *ClientReportRepository) MarkProcessing(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).
+		Model(&models.ClientReport{}).
+		Where("id = ?", id).
+		Update("status", models.ClientReportStatusProcessing).Error
+}
+
+func (r *ClientReportRepository) MarkReady(ctx context.Context, id uint, objectKey, downloadURL string, expiresAt time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&models.ClientReport{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"status":       models.ClientReportStatusReady,
+			"object_key":   objectKey,
+			"download_url": downloadURL,
+			"expires_at":   expiresAt,
+			"completed_at": time.Now(),
+		}).Error
+}
+
+func (r *ClientReportRepository) MarkFailed(ctx context.Context, id uint, reason string) error {
+	return r.db.WithContext(ctx).
+		Model(&models.ClientReport{}).
+		Where("id = ? AND status IN ?", id, []string{models.ClientReportStatusPending, models.ClientReportStatusProcessing}).
+		Updates(map[string]any{
+			"status":         models.ClientReportStatusFailed,
+			"failure_reason": reason,
+		}).Error
+}
@@ -1,15 +1,27 @@
 package repositories
 
 import (
+	"chalk-api/pkg/db"
 	"chalk-api/pkg/models"
 	"context"
+	"encoding/json"
 	"errors"
 	"strings"
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// ErrInviteCodeExhausted is returned by AcceptInvite when the invite reached max_uses before this acceptance
+var ErrInviteCodeExhausted = errors.New("invite code has no uses left")
+
+// ErrConnectionRequestExists is returned by CreateConnectionRequest when the user already has a pending request to the coach
+var ErrConnectionRequestExists = errors.New("connection request already pending")
+
+// ErrConnectionRequestNotPending is returned when a connection request was approved or declined before this review
+var ErrConnectionRequestNotPending = errors.New("connection request is not pending")
+
 type ClientRepository struct {
 	db *gorm.DB
 }
@@ -46,25 +58,61 @@ func (r *ClientRepository) GetByUserAndCoach(ctx context.Context, userID, coachI
 	return &profile, nil
 }
 
-// ListByCoach returns paginated clients for a coach, filterable by status
-func (r *ClientRepository) ListByCoach(ctx context.Context, coachID uint, status string, limit, offset int) ([]models.ClientProfile, int64, error) {
+// ClientListFilter narrows and orders ListByCoach results
+type ClientListFilter struct {
+	Status string
+	AtRisk *bool
+	// Tags keeps clients with any of these tags, or all of them with MatchAllTags
+	Tags         []string
+	MatchAllTags bool
+	// SortBy is "last_activity_at" (least recently active first) or empty for newest clients first
+	SortBy string
+}
+
+// ClientTagCount is a tag in use on a coach's clients and how many clients carry it.
+type ClientTagCount struct {
+	Tag   string `json:"tag"`
+	Count int64  `json:"count"`
+}
+
+// ListByCoach returns paginated clients for a coach, filterable by status and at-risk flag
+func (r *ClientRepository) ListByCoach(ctx context.Context, coachID uint, filter ClientListFilter, limit, offset int) ([]models.ClientProfile, int64, error) {
 	var clients []models.ClientProfile
 	var total int64
 
 	query := r.db.WithContext(ctx).
 		Where("coach_id = ?", coachID)
 
-	if status != "" {
-		query = query.Where("status = ?", status)
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.AtRisk != nil {
+		query = query.Where("at_risk = ?", *filter.AtRisk)
+	}
+	if len(filter.Tags) > 0 {
+		if filter.MatchAllTags {
+			tags, err := json.Marshal(filter.Tags)
+			if err != nil {
+				return nil, 0, err
+			}
+			query = query.Where("tags @> ?::jsonb", string(tags))
+		} else {
+			query = query.Where("EXISTS (SELECT 1 FROM jsonb_array_elements_text(client_profiles.tags) AS tag WHERE tag IN ?)", filter.Tags)
+		}
 	}
 
 	if err := query.Model(&models.ClientProfile{}).Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
+	order := "created_at DESC"
+	if filter.SortBy == "last_activity_at" {
+		order = "last_activity_at ASC NULLS FIRST, id ASC"
+	}
+
 	err := query.
 		Preload("User.Profile").
-		Order("created_at DESC").
+		Order(order).
 		Limit(limit).Offset(offset).
 		Find(&clients).Error
 
@@ -92,6 +140,77 @@ func (r *ClientRepository) UpdateStatus(ctx context.Context, id uint, status str
 		Update("status", status).Error
 }
 
+// ReplaceTags overwrites the client's tags with tags, which the caller has already normalized.
+func (r *ClientRepository) ReplaceTags(ctx context.Context, id uint, tags []string) error {
+	if tags == nil {
+		tags = []string{}
+	}
+	encoded, err := json.Marshal(tags)
+	if err != nil {
+		return err
+	}
+	return r.db.WithContext(ctx).
+		Model(&models.ClientProfile{}).
+		Where("id = ?", id).
+		Update("tags", gorm.Expr("?::jsonb", string(encoded))).Error
+}
+
+// ListTagCounts returns every tag on the coach's clients with the number of clients using it,
+// most used first.
+func (r *ClientRepository) ListTagCounts(ctx context.Context, coachID uint) ([]ClientTagCount, error) {
+	counts := []ClientTagCount{}
+	err := r.db.WithContext(ctx).
+		Raw(`
+			SELECT tag, COUNT(*) AS count
+			FROM client_profiles, jsonb_array_elements_text(client_profiles.tags) AS tag
+			WHERE client_profiles.coach_id = ? AND jsonb_typeof(client_profiles.tags) = 'array'
+			GROUP BY tag
+			ORDER BY count DESC, tag ASC
+		`, coachID).
+		Scan(&counts).Error
+	return counts, err
+}
+
+// RenameTag replaces from with to on all of the coach's clients, keeping each client's tag order and
+// merging the two when a client already had both. It returns how many clients changed.
+func (r *ClientRepository) RenameTag(ctx context.Context, coachID uint, from, to string) (int64, error) {
+	match, err := json.Marshal([]string{from})
+	if err != nil {
+		return 0, err
+	}
+	result := r.db.WithContext(ctx).Exec(`
+		UPDATE client_profiles
+		SET tags = (
+			SELECT COALESCE(jsonb_agg(tag ORDER BY first_position), '[]'::jsonb)
+			FROM (
+				SELECT tag, MIN(position) AS first_position
+				FROM (
+					SELECT CASE WHEN element = ? THEN ? ELSE element END AS tag, position
+					FROM jsonb_array_elements_text(client_profiles.tags) WITH ORDINALITY AS elements(element, position)
+				) renamed
+				GROUP BY tag
+			) deduplicated
+		),
+		updated_at = NOW()
+		WHERE coach_id = ? AND tags @> ?::jsonb
+	`, from, to, coachID, string(match))
+	return result.RowsAffected, result.Error
+}
+
+func (r *ClientRepository) UpdateRequireCompletionNote(ctx context.Context, id uint, required bool) error {
+	return r.db.WithContext(ctx).
+		Model(&models.ClientProfile{}).
+		Where("id = ?", id).
+		Update("require_completion_note", required).Error
+}
+
+func (r *ClientRepository) UpdateCanSelfBook(ctx context.Context, id uint, allowed bool) error {
+	return r.db.WithContext(ctx).
+		Model(&models.ClientProfile{}).
+		Where("id = ?", id).
+		Update("can_self_book", allowed).Error
+}
+
 func (r *ClientRepository) UpdatePrivateNotes(ctx context.Context, id uint, notes string) error {
 	return r.db.WithContext(ctx).
 		Model(&models.ClientProfile{}).
@@ -99,6 +218,85 @@ func (r *ClientRepository) UpdatePrivateNotes(ctx context.Context, id uint, note
 		Update("private_notes", notes).Error
 }
 
+// MarkTransferred archives a client profile that moved to another coach and links it to its replacement.
+func (r *ClientRepository) MarkTransferred(ctx context.Context, id, newProfileID uint, at time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&models.ClientProfile{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"status":            "archived",
+			"transferred_to_id": newProfileID,
+			"transferred_at":    at,
+			"at_risk":           false,
+			"at_risk_since":     nil,
+		}).Error
+}
+
+// IncrementLateCancelCount records one more late cancellation against the client profile.
+func (r *ClientRepository) IncrementLateCancelCount(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).
+		Model(&models.ClientProfile{}).
+		Where("id = ?", id).
+		UpdateColumn("late_cancel_count", gorm.Expr("late_cancel_count + 1")).Error
+}
+
+// --- Client Notes ---
+
+// ClientNoteFilter narrows a coach's notes. ClientID 0 searches across all of the coach's clients.
+type ClientNoteFilter struct {
+	ClientID uint
+	// Query is matched as full-text search (web search syntax, English stemming) against the note body
+	Query string
+}
+
+func (r *ClientRepository) CreateNote(ctx context.Context, note *models.ClientNote) error {
+	return r.db.WithContext(ctx).Create(note).Error
+}
+
+func (r *ClientRepository) GetNoteByID(ctx context.Context, id uint) (*models.ClientNote, error) {
+	var note models.ClientNote
+	err := r.db.WithContext(ctx).First(&note, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &note, nil
+}
+
+func (r *ClientRepository) UpdateNote(ctx context.Context, note *models.ClientNote) error {
+	return r.db.WithContext(ctx).Save(note).Error
+}
+
+func (r *ClientRepository) DeleteNote(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&models.ClientNote{}, id).Error
+}
+
+// ListNotes returns a coach's notes with pinned notes first (most recently pinned first), then the
+// rest newest first.
+func (r *ClientRepository) ListNotes(ctx context.Context, coachID uint, filter ClientNoteFilter, limit, offset int) ([]models.ClientNote, int64, error) {
+	var notes []models.ClientNote
+	var total int64
+
+	query := r.db.WithContext(ctx).
+		Model(&models.ClientNote{}).
+		Where("coach_id = ?", coachID)
+	if filter.ClientID != 0 {
+		query = query.Where("client_id = ?", filter.ClientID)
+	}
+	if filter.Query != "" {
+		query = query.Where("to_tsvector('english', body) @@ websearch_to_tsquery('english', ?)", filter.Query)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.
+		Order("pinned DESC, pinned_at DESC NULLS LAST, created_at DESC, id DESC").
+		Limit(limit).Offset(offset).
+		Find(&notes).Error
+	return notes, total, err
+}
+
 // --- Invite Codes ---
 
 func (r *ClientRepository) CreateInviteCode(ctx context.Context, code *models.InviteCode) error {
@@ -108,7 +306,7 @@ func (r *ClientRepository) CreateInviteCode(ctx context.Context, code *models.In
 func (r *ClientRepository) GetInviteCode(ctx context.Context, code string) (*models.InviteCode, error) {
 	var invite models.InviteCode
 	err := r.db.WithContext(ctx).
-		Where("code = ? AND is_active = ? AND expires_at > ? AND used_by IS NULL", code, true, time.Now()).
+		Where("code = ? AND is_active = ? AND expires_at > ? AND use_count < max_uses", code, true, time.Now()).
 		First(&invite).Error
 	if err != nil {
 		return nil, err
@@ -125,71 +323,45 @@ func (r *ClientRepository) GetInviteCodeByID(ctx context.Context, id uint) (*mod
 	return &invite, nil
 }
 
-func (r *ClientRepository) MarkInviteUsed(ctx context.Context, id uint, userID uint) error {
-	now := time.Now()
-	return r.db.WithContext(ctx).
-		Model(&models.InviteCode{}).
-		Where("id = ?", id).
-		Updates(map[string]interface{}{
-			"used_by": userID,
-			"used_at": now,
-		}).Error
-}
-
-// AcceptInvite creates the coach-client relationship and marks the invite as used in one transaction.
-// Returns alreadyConnected=true when the relationship already exists.
+// AcceptInvite creates the coach-client relationship and records a use of the invite in one transaction.
+// Returns alreadyConnected=true when the relationship already exists; that doesn't consume a use.
+// Returns ErrInviteCodeExhausted when concurrent acceptances used up the invite first.
 func (r *ClientRepository) AcceptInvite(ctx context.Context, invite *models.InviteCode, userID uint) (*models.ClientProfile, bool, error) {
 	var result models.ClientProfile
 	alreadyConnected := false
 	now := time.Now()
 
 	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		var existing models.ClientProfile
-		err := tx.Where("user_id = ? AND coach_id = ?", userID, invite.CoachID).First(&existing).Error
-		if err == nil {
-			alreadyConnected = true
-			result = existing
-			return tx.Model(&models.InviteCode{}).
-				Where("id = ? AND used_by IS NULL", invite.ID).
-				Updates(map[string]any{
-					"used_by": userID,
-					"used_at": now,
-				}).Error
-		}
-		if !errors.Is(err, gorm.ErrRecordNotFound) {
+		profile, existed, err := connectClientTx(tx, userID, invite.CoachID, invite.CreatedAt, now)
+		if err != nil {
 			return err
 		}
+		result = *profile
+		if existed {
+			alreadyConnected = true
+			return nil
+		}
 
-		invitedAt := invite.CreatedAt
-		profile := models.ClientProfile{
-			UserID:    userID,
-			CoachID:   invite.CoachID,
-			Status:    "active",
-			InvitedAt: &invitedAt,
-			JoinedAt:  &now,
+		// The capacity check and increment are one statement, so concurrent acceptances can't exceed max_uses.
+		claim := tx.Model(&models.InviteCode{}).
+			Where("id = ? AND is_active = ? AND expires_at > ? AND use_count < max_uses", invite.ID, true, now).
+			Updates(map[string]any{
+				"use_count": gorm.Expr("use_count + 1"),
+				"used_by":   gorm.Expr("COALESCE(used_by, ?)", userID),
+				"used_at":   gorm.Expr("COALESCE(used_at, ?)", now),
+			})
+		if claim.Error != nil {
+			return claim.Error
 		}
-		if err := tx.Create(&profile).Error; err != nil {
-			// Handle race where another request creates the relation first.
-			if isDuplicateKeyError(err) {
-				if getErr := tx.Where("user_id = ? AND coach_id = ?", userID, invite.CoachID).First(&existing).Error; getErr == nil {
-					alreadyConnected = true
-					result = existing
-				} else {
-					return getErr
-				}
-			} else {
-				return err
-			}
-		} else {
-			result = profile
+		if claim.RowsAffected == 0 {
+			return ErrInviteCodeExhausted
 		}
 
-		return tx.Model(&models.InviteCode{}).
-			Where("id = ? AND used_by IS NULL", invite.ID).
-			Updates(map[string]any{
-				"used_by": userID,
-				"used_at": now,
-			}).Error
+		return tx.Create(&models.InviteCodeUse{
+			InviteCodeID:    invite.ID,
+			UserID:          userID,
+			ClientProfileID: &profile.ID,
+		}).Error
 	})
 	if err != nil {
 		return nil, false, err
@@ -205,6 +377,38 @@ func (r *ClientRepository) AcceptInvite(ctx context.Context, invite *models.Invi
 	return &result, alreadyConnected, nil
 }
 
+// connectClientTx returns the user's client profile under the coach, creating an active one when none exists.
+// existed is true when the relationship was already there, including when a concurrent request created it first.
+func connectClientTx(tx *gorm.DB, userID, coachID uint, invitedAt, joinedAt time.Time) (*models.ClientProfile, bool, error) {
+	var existing models.ClientProfile
+	err := tx.Where("user_id = ? AND coach_id = ?", userID, coachID).First(&existing).Error
+	if err == nil {
+		return &existing, true, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, false, err
+	}
+
+	profile := models.ClientProfile{
+		UserID:    userID,
+		CoachID:   coachID,
+		Status:    "active",
+		InvitedAt: &invitedAt,
+		JoinedAt:  &joinedAt,
+	}
+	if err := tx.Create(&profile).Error; err != nil {
+		// Handle race where another request creates the relation first.
+		if isDuplicateKeyError(err) {
+			if getErr := tx.Where("user_id = ? AND coach_id = ?", userID, coachID).First(&existing).Error; getErr != nil {
+				return nil, false, getErr
+			}
+			return &existing, true, nil
+		}
+		return nil, false, err
+	}
+	return &profile, false, nil
+}
+
 func (r *ClientRepository) ListInviteCodes(ctx context.Context, coachID uint) ([]models.InviteCode, error) {
 	var codes []models.InviteCode
 	err := r.db.WithContext(ctx).
@@ -221,6 +425,110 @@ func (r *ClientRepository) DeactivateInviteCode(ctx context.Context, id uint) er
 		Update("is_active", false).Error
 }
 
+// --- Connection Requests ---
+
+func (r *ClientRepository) CreateConnectionRequest(ctx context.Context, request *models.ConnectionRequest) error {
+	err := r.db.WithContext(ctx).Create(request).Error
+	if err != nil && isUniqueViolation(err) {
+		return ErrConnectionRequestExists
+	}
+	return err
+}
+
+func (r *ClientRepository) GetConnectionRequestByID(ctx context.Context, id uint) (*models.ConnectionRequest, error) {
+	var request models.ConnectionRequest
+	if err := r.db.WithContext(ctx).First(&request, id).Error; err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
+
+// GetLatestConnectionRequest returns the user's most recent request to the coach, in any status.
+func (r *ClientRepository) GetLatestConnectionRequest(ctx context.Context, userID, coachID uint) (*models.ConnectionRequest, error) {
+	var request models.ConnectionRequest
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND coach_id = ?", userID, coachID).
+		Order("created_at DESC").
+		First(&request).Error
+	if err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
+
+// ApproveConnectionRequest marks a pending request approved and creates the coach-client relationship
+// in one transaction. Returns alreadyConnected=true when the relationship already existed, and
+// ErrConnectionRequestNotPending when the request was reviewed concurrently.
+func (r *ClientRepository) ApproveConnectionRequest(ctx context.Context, request *models.ConnectionRequest) (*models.ClientProfile, bool, error) {
+	var (
+		result           models.ClientProfile
+		alreadyConnected bool
+	)
+	now := time.Now()
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := claimConnectionRequestTx(tx, request.ID, "approved"); err != nil {
+			return err
+		}
+		profile, existed, err := connectClientTx(tx, request.UserID, request.CoachID, request.CreatedAt, now)
+		if err != nil {
+			return err
+		}
+		result = *profile
+		alreadyConnected = existed
+		return nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	if err := r.db.WithContext(ctx).
+		Preload("User.Profile").
+		Preload("Coach.User.Profile").
+		First(&result, result.ID).Error; err != nil {
+		return nil, alreadyConnected, err
+	}
+
+	return &result, alreadyConnected, nil
+}
+
+// DeclineConnectionRequest marks a pending request declined.
+// Returns ErrConnectionRequestNotPending when the request was reviewed concurrently.
+func (r *ClientRepository) DeclineConnectionRequest(ctx context.Context, id uint) error {
+	return claimConnectionRequestTx(r.db.WithContext(ctx), id, "declined")
+}
+
+// claimConnectionRequestTx moves a request out of pending; the status check and update are one
+// statement, so a request can only be reviewed once.
+func claimConnectionRequestTx(tx *gorm.DB, id uint, status string) error {
+	claim := tx.Model(&models.ConnectionRequest{}).
+		Where("id = ? AND status = ?", id, "pending").
+		Updates(map[string]any{
+			"status":      status,
+			"reviewed_at": time.Now(),
+		})
+	if claim.Error != nil {
+		return claim.Error
+	}
+	if claim.RowsAffected == 0 {
+		return ErrConnectionRequestNotPending
+	}
+	return nil
+}
+
+// ListConnectionRequests returns a coach's requests, newest first, optionally filtered by status.
+func (r *ClientRepository) ListConnectionRequests(ctx context.Context, coachID uint, status string) ([]models.ConnectionRequest, error) {
+	var requests []models.ConnectionRequest
+	query := r.db.WithContext(ctx).
+		Preload("User.Profile").
+		Where("coach_id = ?", coachID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	err := query.Order("created_at DESC").Find(&requests).Error
+	return requests, err
+}
+
 func isDuplicateKeyError(err error) bool {
 	if err == nil {
 		return false
@@ -228,6 +536,121 @@ func isDuplicateKeyError(err error) bool {
 	return strings.Contains(err.Error(), "duplicate key value violates unique constraint")
 }
 
+// --- Client Invitations ---
+
+func (r *ClientRepository) CreateClientInvitation(ctx context.Context, invitation *models.ClientInvitation) error {
+	return r.db.WithContext(ctx).Create(invitation).Error
+}
+
+// GetOpenClientInvitation returns the coach's pending invitation for email whose invite code can
+// still be accepted, or gorm.ErrRecordNotFound.
+func (r *ClientRepository) GetOpenClientInvitation(ctx context.Context, coachID uint, email string) (*models.ClientInvitation, error) {
+	var invitation models.ClientInvitation
+	err := r.db.WithContext(ctx).
+		Joins("JOIN invite_codes ON invite_codes.id = client_invitations.invite_code_id").
+		Where("client_invitations.coach_id = ? AND client_invitations.email = ? AND client_invitations.status = ?",
+			coachID, email, models.ClientInvitationPending).
+		Where("invite_codes.is_active = ? AND invite_codes.expires_at > ? AND invite_codes.use_count < invite_codes.max_uses",
+			true, time.Now()).
+		First(&invitation).Error
+	if err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+// AcceptClientInvitation marks the pending invitation behind an invite code accepted and copies its
+// goals and tags onto the new client profile, without overwriting goals or tags already set there.
+// It is a no-op for invite codes that weren't created by a client import.
+func (r *ClientRepository) AcceptClientInvitation(ctx context.Context, inviteCodeID, clientProfileID uint, acceptedAt time.Time) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var invitation models.ClientInvitation
+		err := tx.Where("invite_code_id = ? AND status = ?", inviteCodeID, models.ClientInvitationPending).
+			First(&invitation).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Model(&invitation).Updates(map[string]any{
+			"status":      models.ClientInvitationAccepted,
+			"accepted_at": acceptedAt,
+		}).Error; err != nil {
+			return err
+		}
+
+		if invitation.Goals != nil {
+			if err := tx.Model(&models.ClientProfile{}).
+				Where("id = ? AND (goals IS NULL OR goals = '')", clientProfileID).
+				Update("goals", *invitation.Goals).Error; err != nil {
+				return err
+			}
+		}
+		if len(invitation.Tags) > 0 {
+			encoded, err := json.Marshal(invitation.Tags)
+			if err != nil {
+				return err
+			}
+			if err := tx.Model(&models.ClientProfile{}).
+				Where("id = ? AND (tags IS NULL OR tags = '[]'::jsonb)", clientProfileID).
+				Update("tags", gorm.Expr("?::jsonb", string(encoded))).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// --- Client Imports ---
+
+func (r *ClientRepository) CreateClientImport(ctx context.Context, clientImport *models.ClientImport) error {
+	return r.db.WithContext(ctx).Create(clientImport).Error
+}
+
+func (r *ClientRepository) GetClientImport(ctx context.Context, id uint) (*models.ClientImport, error) {
+	var clientImport models.ClientImport
+	err := r.db.WithContext(ctx).First(&clientImport, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &clientImport, nil
+}
+
+// SaveClientImport writes the import's progress, results and status.
+func (r *ClientRepository) SaveClientImport(ctx context.Context, clientImport *models.ClientImport) error {
+	return r.db.WithContext(ctx).Save(clientImport).Error
+}
+
+// ClaimClientImport moves the oldest pending import to processing and returns it, or returns
+// gorm.ErrRecordNotFound when there is none. Imports left processing since before staleBefore,
+// by a worker that stopped mid-file, are claimed again.
+func (r *ClientRepository) ClaimClientImport(ctx context.Context, staleBefore time.Time) (*models.ClientImport, error) {
+	var claimed *models.ClientImport
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var clientImport models.ClientImport
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? OR (status = ? AND updated_at < ?)",
+				models.ClientImportPending, models.ClientImportProcessing, staleBefore).
+			Order("id ASC").
+			First(&clientImport).Error
+		if err != nil {
+			return err
+		}
+		clientImport.Status = models.ClientImportProcessing
+		if err := tx.Model(&clientImport).Update("status", models.ClientImportProcessing).Error; err != nil {
+			return err
+		}
+		claimed = &clientImport
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return claimed, nil
+}
+
 // --- Intake Form ---
 
 func (r *ClientRepository) CreateIntakeForm(ctx context.Context, form *models.ClientIntakeForm) error {
@@ -245,6 +668,128 @@ func (r *ClientRepository) GetIntakeForm(ctx context.Context, clientID uint) (*m
 	return &form, nil
 }
 
+// UpdateIntakeForm replaces the form if nobody resubmitted it since it was read; see saveVersioned
 func (r *ClientRepository) UpdateIntakeForm(ctx context.Context, form *models.ClientIntakeForm) error {
-	return r.db.WithContext(ctx).Save(form).Error
+	return saveVersioned(r.db.WithContext(ctx), form, &form.Version)
+}
+
+// --- Custom Intake Questions ---
+
+func (r *ClientRepository) CreateIntakeQuestion(ctx context.Context, question *models.CustomIntakeQuestion) error {
+	return r.db.WithContext(ctx).Create(question).Error
+}
+
+func (r *ClientRepository) CountIntakeQuestions(ctx context.Context, coachID uint) (int64, error) {
+	var count int64
+	err := db.UsePrimary(r.db.WithContext(ctx)).
+		Model(&models.CustomIntakeQuestion{}).
+		Where("coach_id = ?", coachID).
+		Count(&count).Error
+	return count, err
+}
+
+// ListIntakeQuestions returns a coach's custom intake questions in display order.
+func (r *ClientRepository) ListIntakeQuestions(ctx context.Context, coachID uint) ([]models.CustomIntakeQuestion, error) {
+	var questions []models.CustomIntakeQuestion
+	err := r.db.WithContext(ctx).
+		Where("coach_id = ?", coachID).
+		Order("display_order ASC, id ASC").
+		Find(&questions).Error
+	return questions, err
+}
+
+func (r *ClientRepository) GetIntakeQuestionByID(ctx context.Context, id uint) (*models.CustomIntakeQuestion, error) {
+	var question models.CustomIntakeQuestion
+	err := r.db.WithContext(ctx).First(&question, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &question, nil
+}
+
+func (r *ClientRepository) UpdateIntakeQuestion(ctx context.Context, question *models.CustomIntakeQuestion) error {
+	return r.db.WithContext(ctx).Save(question).Error
+}
+
+func (r *ClientRepository) DeleteIntakeQuestion(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&models.CustomIntakeQuestion{}, id).Error
+}
+
+// --- Inactivity ---
+
+// AtRiskClient is a client the at-risk sweep just flagged
+type AtRiskClient struct {
+	ClientID             uint
+	CoachID              uint
+	CoachUserID          uint
+	FirstName            string
+	LastName             string
+	LastActivityAt       *time.Time
+	AtRiskSince          time.Time
+	AtRiskInactivityDays int
+}
+
+// RefreshLastActivity moves last_activity_at forward for active clients from their latest completed
+// workout, logged set, sent message, or attended (checked-in or completed) session.
+func (r *ClientRepository) RefreshLastActivity(ctx context.Context) (int64, error) {
+	result := r.db.WithContext(ctx).Exec(`
+		UPDATE client_profiles cp
+		SET last_activity_at = activity.latest
+		FROM (
+			SELECT c.id, GREATEST(
+				(SELECT MAX(w.completed_at) FROM workouts w WHERE w.client_id = c.id),
+				(SELECT MAX(wl.created_at) FROM workout_logs wl
+					JOIN workout_exercises we ON we.id = wl.workout_exercise_id
+					JOIN workouts w ON w.id = we.workout_id
+					WHERE w.client_id = c.id),
+				(SELECT MAX(m.created_at) FROM messages m
+					JOIN conversations conv ON conv.id = m.conversation_id
+					WHERE conv.client_id = c.id AND m.sender_id = c.user_id),
+				(SELECT MAX(COALESCE(s.checked_in_at, s.completed_at)) FROM sessions s
+					WHERE s.client_id = c.id AND (s.checked_in_at IS NOT NULL OR s.status = 'completed'))
+			) AS latest
+			FROM client_profiles c
+			WHERE c.status = 'active'
+		) activity
+		WHERE activity.id = cp.id
+			AND activity.latest IS NOT NULL
+			AND (cp.last_activity_at IS NULL OR activity.latest > cp.last_activity_at)`)
+	return result.RowsAffected, result.Error
+}
+
+// FlagAtRisk marks active clients inactive for longer than their coach's threshold and returns
+// only the clients whose flag flipped in this call. Clients with no activity yet count from when they joined.
+func (r *ClientRepository) FlagAtRisk(ctx context.Context, now time.Time) ([]AtRiskClient, error) {
+	var flagged []AtRiskClient
+	err := r.db.WithContext(ctx).Raw(`
+		WITH flipped AS (
+			UPDATE client_profiles cp
+			SET at_risk = true, at_risk_since = ?
+			FROM coach_profiles co
+			WHERE co.id = cp.coach_id
+				AND cp.status = 'active'
+				AND cp.at_risk = false
+				AND COALESCE(cp.last_activity_at, cp.joined_at, cp.created_at) < ?::timestamptz - (co.at_risk_inactivity_days * INTERVAL '1 day')
+			RETURNING cp.id, cp.coach_id, cp.user_id, cp.last_activity_at, cp.at_risk_since, co.user_id AS coach_user_id, co.at_risk_inactivity_days
+		)
+		SELECT f.id AS client_id, f.coach_id, f.coach_user_id,
+			COALESCE(p.first_name, '') AS first_name, COALESCE(p.last_name, '') AS last_name,
+			f.last_activity_at, f.at_risk_since, f.at_risk_inactivity_days
+		FROM flipped f
+		LEFT JOIN profiles p ON p.user_id = f.user_id
+		ORDER BY f.id`, now, now).Scan(&flagged).Error
+	return flagged, err
+}
+
+// ClearAtRisk unflags clients with activity inside their coach's threshold, or who are no longer active
+func (r *ClientRepository) ClearAtRisk(ctx context.Context, now time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Exec(`
+		UPDATE client_profiles cp
+		SET at_risk = false, at_risk_since = NULL
+		FROM coach_profiles co
+		WHERE co.id = cp.coach_id
+			AND cp.at_risk = true
+			AND (cp.status <> 'active'
+				OR cp.last_activity_at >= ?::timestamptz - (co.at_risk_inactivity_days * INTERVAL '1 day'))`, now)
+	return result.RowsAffected, result.Error
 }
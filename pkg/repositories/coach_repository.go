@@ -1,10 +1,31 @@
 package repositories
 
 import (
+	"chalk-api/pkg/db"
 	"chalk-api/pkg/models"
 	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"strings"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrCoachSlugTaken is returned by Create and Update when another coach already uses the slug
+var ErrCoachSlugTaken = errors.New("coach slug already in use")
+
+var (
+	// ErrTeamInvitationExists is returned by CreateTeamMember when the email already has an open invitation or membership
+	ErrTeamInvitationExists = errors.New("team invitation already open for this email")
+	// ErrTeamInvitationNotPending is returned when an invitation was accepted or revoked before this change
+	ErrTeamInvitationNotPending = errors.New("team invitation is not pending")
+	// ErrTeamMembershipExists is returned by AcceptTeamInvitation when the user already assists a coach
+	ErrTeamMembershipExists = errors.New("user is already on a coach team")
+	// ErrReferralCodeTaken is returned by AssignReferralCode when another coach already has the code
+	ErrReferralCodeTaken = errors.New("referral code already in use")
 )
 
 type CoachRepository struct {
@@ -16,12 +37,12 @@ func NewCoachRepository(db *gorm.DB) *CoachRepository {
 }
 
 func (r *CoachRepository) Create(ctx context.Context, profile *models.CoachProfile) error {
-	return r.db.WithContext(ctx).Create(profile).Error
+	return coachSlugError(r.db.WithContext(ctx).Create(profile).Error)
 }
 
 func (r *CoachRepository) GetByID(ctx context.Context, id uint) (*models.CoachProfile, error) {
 	var profile models.CoachProfile
-	err := r.db.WithContext(ctx).
+	err := db.UsePrimary(r.db.WithContext(ctx)).
 		Preload("Certifications").
 		Preload("Locations").
 		Preload("Stats").
@@ -46,8 +67,27 @@ func (r *CoachRepository) GetByUserID(ctx context.Context, userID uint) (*models
 	return &profile, nil
 }
 
+// Update saves the profile if its version is still the one that was loaded, returning
+// ErrVersionConflict otherwise. On success profile.Version holds the new version.
 func (r *CoachRepository) Update(ctx context.Context, profile *models.CoachProfile) error {
-	return r.db.WithContext(ctx).Save(profile).Error
+	return coachSlugError(saveVersioned(r.db.WithContext(ctx), profile, &profile.Version))
+}
+
+// GetBySlug loads the coach behind a public booking page. Relations are not preloaded.
+func (r *CoachRepository) GetBySlug(ctx context.Context, slug string) (*models.CoachProfile, error) {
+	var profile models.CoachProfile
+	if err := r.db.WithContext(ctx).Where("slug = ?", slug).First(&profile).Error; err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+// coachSlugError maps a slug unique violation to ErrCoachSlugTaken; other errors pass through.
+func coachSlugError(err error) error {
+	if err != nil && isUniqueViolation(err) && strings.Contains(err.Error(), "idx_coach_profiles_slug") {
+		return ErrCoachSlugTaken
+	}
+	return err
 }
 
 // --- Certifications ---
@@ -88,6 +128,18 @@ func (r *CoachRepository) ListLocations(ctx context.Context, coachID uint) ([]mo
 	return locations, err
 }
 
+// GetPrimaryLocation returns the coach's active primary location
+func (r *CoachRepository) GetPrimaryLocation(ctx context.Context, coachID uint) (*models.CoachLocation, error) {
+	var location models.CoachLocation
+	err := r.db.WithContext(ctx).
+		Where("coach_id = ? AND is_primary = ? AND is_active = ?", coachID, true, true).
+		First(&location).Error
+	if err != nil {
+		return nil, err
+	}
+	return &location, nil
+}
+
 func (r *CoachRepository) UpdateLocation(ctx context.Context, location *models.CoachLocation) error {
 	return r.db.WithContext(ctx).Save(location).Error
 }
@@ -110,6 +162,259 @@ func (r *CoachRepository) SetPrimaryLocation(ctx context.Context, coachID uint,
 	})
 }
 
+// --- Team members ---
+
+func (r *CoachRepository) CreateTeamMember(ctx context.Context, member *models.CoachTeamMember) error {
+	err := r.db.WithContext(ctx).Create(member).Error
+	if err != nil && isUniqueViolation(err) {
+		return ErrTeamInvitationExists
+	}
+	return err
+}
+
+func (r *CoachRepository) GetTeamMember(ctx context.Context, id uint) (*models.CoachTeamMember, error) {
+	var member models.CoachTeamMember
+	if err := r.db.WithContext(ctx).First(&member, id).Error; err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// ListTeamMembers returns the coach's pending invitations and active members, oldest first.
+func (r *CoachRepository) ListTeamMembers(ctx context.Context, coachID uint) ([]models.CoachTeamMember, error) {
+	var members []models.CoachTeamMember
+	err := r.db.WithContext(ctx).
+		Preload("User.Profile").
+		Where("coach_id = ? AND status IN ?", coachID, []string{models.CoachTeamMemberPending, models.CoachTeamMemberActive}).
+		Order("created_at ASC").
+		Find(&members).Error
+	return members, err
+}
+
+// ListPendingTeamInvitations returns open invitations addressed to email, with the inviting coach.
+func (r *CoachRepository) ListPendingTeamInvitations(ctx context.Context, email string) ([]models.CoachTeamMember, error) {
+	var members []models.CoachTeamMember
+	err := r.db.WithContext(ctx).
+		Preload("Coach").
+		Where("email = ? AND status = ?", email, models.CoachTeamMemberPending).
+		Order("created_at DESC").
+		Find(&members).Error
+	return members, err
+}
+
+// GetActiveTeamMembership returns the membership the user currently assists a coach under.
+func (r *CoachRepository) GetActiveTeamMembership(ctx context.Context, userID uint) (*models.CoachTeamMember, error) {
+	var member models.CoachTeamMember
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND status = ?", userID, models.CoachTeamMemberActive).
+		First(&member).Error
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// AcceptTeamInvitation links a pending invitation to userID and activates it. Returns
+// ErrTeamInvitationNotPending if it was accepted or revoked concurrently.
+func (r *CoachRepository) AcceptTeamInvitation(ctx context.Context, id, userID uint, acceptedAt time.Time) error {
+	result := r.db.WithContext(ctx).
+		Model(&models.CoachTeamMember{}).
+		Where("id = ? AND status = ?", id, models.CoachTeamMemberPending).
+		Updates(map[string]interface{}{
+			"user_id":     userID,
+			"status":      models.CoachTeamMemberActive,
+			"accepted_at": acceptedAt,
+		})
+	if result.Error != nil {
+		if isUniqueViolation(result.Error) {
+			return ErrTeamMembershipExists
+		}
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrTeamInvitationNotPending
+	}
+	return nil
+}
+
+// RevokeTeamMember withdraws a pending invitation or removes an active member.
+func (r *CoachRepository) RevokeTeamMember(ctx context.Context, id uint, revokedAt time.Time) error {
+	result := r.db.WithContext(ctx).
+		Model(&models.CoachTeamMember{}).
+		Where("id = ? AND status IN ?", id, []string{models.CoachTeamMemberPending, models.CoachTeamMemberActive}).
+		Updates(map[string]interface{}{
+			"status":     models.CoachTeamMemberRevoked,
+			"revoked_at": revokedAt,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrTeamInvitationNotPending
+	}
+	return nil
+}
+
+// --- Onboarding ---
+
+// CoachOnboardingData reports which onboarding checklist steps a coach's existing data already satisfies.
+type CoachOnboardingData struct {
+	HasAvailability bool
+	HasSessionTypes bool
+	HasInviteCodes  bool
+	HasTemplates    bool
+}
+
+// GetOnboardingData checks in one query whether the coach has active availability, an active
+// session type, any invite code and any workout template.
+func (r *CoachRepository) GetOnboardingData(ctx context.Context, coachID uint) (CoachOnboardingData, error) {
+	var data CoachOnboardingData
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT
+			EXISTS (SELECT 1 FROM coach_availabilities WHERE coach_id = ? AND is_active) AS has_availability,
+			EXISTS (SELECT 1 FROM session_types WHERE coach_id = ? AND is_active) AS has_session_types,
+			EXISTS (SELECT 1 FROM invite_codes WHERE coach_id = ?) AS has_invite_codes,
+			EXISTS (SELECT 1 FROM workout_templates WHERE coach_id = ?) AS has_templates`,
+		coachID, coachID, coachID, coachID).Scan(&data).Error
+	return data, err
+}
+
+// AddOnboardingSteps merges steps into the coach's onboarding state in a single statement. Steps the
+// coach already completed keep their original time. The profile version is left alone so the
+// coach's pending profile edits aren't rejected because an unrelated action finished a step.
+func (r *CoachRepository) AddOnboardingSteps(ctx context.Context, coachID uint, steps models.OnboardingState) error {
+	payload, err := json.Marshal(steps)
+	if err != nil {
+		return err
+	}
+	return r.db.WithContext(ctx).
+		Model(&models.CoachProfile{}).
+		Where("id = ?", coachID).
+		UpdateColumn("onboarding_state", gorm.Expr("?::jsonb || COALESCE(onboarding_state, '{}'::jsonb)", string(payload))).Error
+}
+
+// MarkOnboardingCompleted sets onboarding_completed, bumping the profile version so an edit based on
+// the old row can't clear it. It reports false when onboarding was already completed.
+func (r *CoachRepository) MarkOnboardingCompleted(ctx context.Context, coachID uint) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&models.CoachProfile{}).
+		Where("id = ? AND onboarding_completed = ?", coachID, false).
+		Updates(map[string]interface{}{
+			"onboarding_completed": true,
+			"version":              gorm.Expr("version + 1"),
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// --- Referrals ---
+
+// GetByReferralCode loads the coach who owns a referral code. Relations are not preloaded.
+func (r *CoachRepository) GetByReferralCode(ctx context.Context, code string) (*models.CoachProfile, error) {
+	var profile models.CoachProfile
+	if err := r.db.WithContext(ctx).Where("referral_code = ?", code).First(&profile).Error; err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+// AssignReferralCode gives a coach without a referral code this one, bumping the profile version so
+// edits based on the old row don't clear it. It reports false when the coach already had a code and
+// returns ErrReferralCodeTaken when another coach holds it.
+func (r *CoachRepository) AssignReferralCode(ctx context.Context, coachID uint, code string) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&models.CoachProfile{}).
+		Where("id = ? AND referral_code IS NULL", coachID).
+		Updates(map[string]interface{}{
+			"referral_code": code,
+			"version":       gorm.Expr("version + 1"),
+		})
+	if result.Error != nil {
+		if isUniqueViolation(result.Error) {
+			return false, ErrReferralCodeTaken
+		}
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// GetReferredByCoachID returns the coach who referred coachID, or nil when nobody did.
+func (r *CoachRepository) GetReferredByCoachID(ctx context.Context, coachID uint) (*uint, error) {
+	var profile models.CoachProfile
+	err := r.db.WithContext(ctx).
+		Select("id", "referred_by_coach_id").
+		First(&profile, coachID).Error
+	if err != nil {
+		return nil, err
+	}
+	return profile.ReferredByCoachID, nil
+}
+
+// CreateReferral records a completed referral unless the referred coach already has one, reporting
+// whether it was new.
+func (r *CoachRepository) CreateReferral(ctx context.Context, referral *models.Referral) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "referred_coach_id"}}, DoNothing: true}).
+		Create(referral)
+	return result.RowsAffected > 0, result.Error
+}
+
+// CountReferrals returns how many coaches the referrer has brought in.
+func (r *CoachRepository) CountReferrals(ctx context.Context, referrerCoachID uint) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&models.Referral{}).
+		Where("referrer_coach_id = ?", referrerCoachID).
+		Count(&count).Error
+	return count, err
+}
+
+// ListReferrals returns completed referrals newest first, all of them when referrerCoachID is 0.
+func (r *CoachRepository) ListReferrals(ctx context.Context, referrerCoachID uint, limit, offset int) ([]models.Referral, int64, error) {
+	var referrals []models.Referral
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&models.Referral{})
+	if referrerCoachID != 0 {
+		query = query.Where("referrer_coach_id = ?", referrerCoachID)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.
+		Order("completed_at DESC, id DESC").
+		Limit(limit).Offset(offset).
+		Find(&referrals).Error
+
+	return referrals, total, err
+}
+
+func (r *CoachRepository) CreateReferralCredit(ctx context.Context, credit *models.ReferralCredit) error {
+	return r.db.WithContext(ctx).Create(credit).Error
+}
+
+// ListReferralCredits returns the coach's credit ledger, newest first.
+func (r *CoachRepository) ListReferralCredits(ctx context.Context, coachID uint) ([]models.ReferralCredit, error) {
+	var credits []models.ReferralCredit
+	err := r.db.WithContext(ctx).
+		Where("coach_id = ?", coachID).
+		Order("created_at DESC, id DESC").
+		Find(&credits).Error
+	return credits, err
+}
+
+// GetReferralCreditBalance sums the coach's unconsumed credits, in cents.
+func (r *CoachRepository) GetReferralCreditBalance(ctx context.Context, coachID uint) (int64, error) {
+	var balance int64
+	err := r.db.WithContext(ctx).
+		Model(&models.ReferralCredit{}).
+		Where("coach_id = ? AND consumed_at IS NULL", coachID).
+		Select("COALESCE(SUM(amount_cents), 0)").
+		Scan(&balance).Error
+	return balance, err
+}
+
 // --- Stats ---
 
 func (r *CoachRepository) GetStats(ctx context.Context, coachID uint) (*models.CoachStats, error) {
@@ -133,3 +438,250 @@ func (r *CoachRepository) IncrementStat(ctx context.Context, coachID uint, field
 		Where("coach_id = ?", coachID).
 		Update(field, gorm.Expr(field+" + ?", amount)).Error
 }
+
+// RefreshRatingStats recomputes the coach's average rating and rating count from session_feedbacks.
+// It recomputes rather than increments so a redelivered event can't count a rating twice.
+func (r *CoachRepository) RefreshRatingStats(ctx context.Context, coachID uint) error {
+	var result struct {
+		Average *float64
+		Count   int
+	}
+	err := r.db.WithContext(ctx).
+		Model(&models.SessionFeedback{}).
+		Select("AVG(rating) AS average, COUNT(*) AS count").
+		Where("coach_id = ?", coachID).
+		Scan(&result).Error
+	if err != nil {
+		return err
+	}
+	if result.Average != nil {
+		rounded := math.Round(*result.Average*100) / 100
+		result.Average = &rounded
+	}
+
+	stats := models.CoachStats{CoachID: coachID, AverageRating: result.Average, RatingCount: result.Count}
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "coach_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"average_rating", "rating_count", "updated_at"}),
+		}).
+		Create(&stats).Error
+}
+
+// RefreshGoalStats recomputes how many client goals the coach's clients have achieved, across every
+// client profile the coach has had. Like RefreshRatingStats it recomputes so redelivery is harmless.
+func (r *CoachRepository) RefreshGoalStats(ctx context.Context, coachID uint) error {
+	var achieved int64
+	err := r.db.WithContext(ctx).
+		Model(&models.ClientGoal{}).
+		Joins("JOIN client_profiles ON client_profiles.id = client_goals.client_profile_id").
+		Where("client_profiles.coach_id = ? AND client_goals.status = ?", coachID, "achieved").
+		Count(&achieved).Error
+	if err != nil {
+		return err
+	}
+
+	stats := models.CoachStats{CoachID: coachID, GoalsAchievedTotal: int(achieved)}
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "coach_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"goals_achieved_total", "updated_at"}),
+		}).
+		Create(&stats).Error
+}
+
+// DerivedCoachStats are the lifetime counters in coach_stats that are kept by increments and can be
+// rebuilt from their source tables.
+type DerivedCoachStats struct {
+	ActiveClients          int `json:"active_clients"`
+	TotalClientsAllTime    int `json:"total_clients_all_time"`
+	WorkoutsAssignedTotal  int `json:"workouts_assigned_total"`
+	WorkoutsCompletedTotal int `json:"workouts_completed_total"`
+	SessionsCompletedTotal int `json:"sessions_completed_total"`
+}
+
+// RecomputeStats rebuilds the coach's incremented counters from client_profiles, workouts and sessions
+// in one transaction. The stats row is locked before counting, so an increment committed meanwhile is
+// either waited for and counted or applied on top of the new values. It returns the values that were
+// stored (zero when the coach had no stats row) and the derived ones now written.
+func (r *CoachRepository) RecomputeStats(ctx context.Context, coachID uint) (previous, derived DerivedCoachStats, err error) {
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var stored []models.CoachStats
+		if err := tx.
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("coach_id = ?", coachID).
+			Limit(1).
+			Find(&stored).Error; err != nil {
+			return err
+		}
+		if len(stored) > 0 {
+			previous = DerivedCoachStats{
+				ActiveClients:          stored[0].ActiveClients,
+				TotalClientsAllTime:    stored[0].TotalClientsAllTime,
+				WorkoutsAssignedTotal:  stored[0].WorkoutsAssignedTotal,
+				WorkoutsCompletedTotal: stored[0].WorkoutsCompletedTotal,
+				SessionsCompletedTotal: stored[0].SessionsCompletedTotal,
+			}
+		}
+
+		if err := tx.Raw(`
+			SELECT
+				(SELECT COUNT(*) FROM client_profiles WHERE coach_id = ? AND status = 'active') AS active_clients,
+				(SELECT COUNT(*) FROM client_profiles WHERE coach_id = ?) AS total_clients_all_time,
+				(SELECT COUNT(*) FROM workouts WHERE coach_id = ?) AS workouts_assigned_total,
+				(SELECT COUNT(*) FROM workouts WHERE coach_id = ? AND status = 'completed') AS workouts_completed_total,
+				(SELECT COUNT(*) FROM sessions WHERE coach_id = ? AND status = 'completed') AS sessions_completed_total`,
+			coachID, coachID, coachID, coachID, coachID,
+		).Scan(&derived).Error; err != nil {
+			return err
+		}
+
+		stats := models.CoachStats{
+			CoachID:                coachID,
+			ActiveClients:          derived.ActiveClients,
+			TotalClientsAllTime:    derived.TotalClientsAllTime,
+			WorkoutsAssignedTotal:  derived.WorkoutsAssignedTotal,
+			WorkoutsCompletedTotal: derived.WorkoutsCompletedTotal,
+			SessionsCompletedTotal: derived.SessionsCompletedTotal,
+		}
+		return tx.
+			Clauses(clause.OnConflict{
+				Columns: []clause.Column{{Name: "coach_id"}},
+				DoUpdates: clause.AssignmentColumns([]string{
+					"active_clients", "total_clients_all_time", "workouts_assigned_total",
+					"workouts_completed_total", "sessions_completed_total", "updated_at",
+				}),
+			}).
+			Create(&stats).Error
+	})
+	return previous, derived, err
+}
+
+// ListCoachIDs pages through coach profile IDs in order, for jobs that visit every coach.
+func (r *CoachRepository) ListCoachIDs(ctx context.Context, afterID uint, limit int) ([]uint, error) {
+	var ids []uint
+	err := r.db.WithContext(ctx).
+		Model(&models.CoachProfile{}).
+		Where("id > ?", afterID).
+		Order("id ASC").
+		Limit(limit).
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+// --- Profile views ---
+
+// ProfileViewDay is one UTC day's profile view count.
+type ProfileViewDay struct {
+	Date  string `json:"date"`
+	Views int64  `json:"views"`
+}
+
+// UpsertProfileViews stores a day's view count for a coach. Counts only move up, so a flush from a
+// Redis that lost part of the day can't shrink what an earlier flush saved.
+func (r *CoachRepository) UpsertProfileViews(ctx context.Context, coachID uint, day string, views int64) error {
+	row := &models.CoachProfileView{CoachID: coachID, ViewDate: day, Views: views}
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "coach_id"}, {Name: "view_date"}},
+			DoUpdates: clause.Assignments(map[string]any{
+				"views":      gorm.Expr("GREATEST(coach_profile_views.views, EXCLUDED.views)"),
+				"updated_at": time.Now().UTC(),
+			}),
+		}).
+		Create(row).Error
+}
+
+// ListProfileViews returns the coach's stored daily view counts between startDate and endDate
+// (YYYY-MM-DD, inclusive), oldest first. Days without views are left out.
+func (r *CoachRepository) ListProfileViews(ctx context.Context, coachID uint, startDate, endDate string) ([]ProfileViewDay, error) {
+	var days []ProfileViewDay
+	err := r.db.WithContext(ctx).
+		Model(&models.CoachProfileView{}).
+		Select("to_char(view_date, 'YYYY-MM-DD') AS date, views").
+		Where("coach_id = ? AND view_date >= ? AND view_date <= ?", coachID, startDate, endDate).
+		Order("view_date ASC").
+		Scan(&days).Error
+	return days, err
+}
+
+// --- Digest ---
+
+// DigestCoach is a coach with the weekly digest enabled, plus the timezone to schedule it in
+type DigestCoach struct {
+	CoachID           uint
+	UserID            uint
+	Timezone          string
+	DigestDayOfWeek   int
+	DigestHour        int
+	DigestPushEnabled bool
+	LastDigestSentAt  *time.Time
+}
+
+// DigestClientActivity summarizes one active client over a digest period
+type DigestClientActivity struct {
+	ClientID          uint       `json:"client_id"`
+	FirstName         string     `json:"first_name"`
+	LastName          string     `json:"last_name"`
+	WorkoutsCompleted int64      `json:"workouts_completed"`
+	WorkoutsMissed    int64      `json:"workouts_missed"`
+	LastContactAt     *time.Time `json:"last_contact_at"` // latest message or completed session
+}
+
+// ListDigestCoaches returns every active coach that has the weekly digest enabled
+func (r *CoachRepository) ListDigestCoaches(ctx context.Context) ([]DigestCoach, error) {
+	var coaches []DigestCoach
+	err := r.db.WithContext(ctx).
+		Table("coach_profiles").
+		Select("coach_profiles.id AS coach_id, coach_profiles.user_id, "+
+			"COALESCE(profiles.timezone, 'UTC') AS timezone, "+
+			"coach_profiles.digest_day_of_week, coach_profiles.digest_hour, "+
+			"coach_profiles.digest_push_enabled, coach_profiles.last_digest_sent_at").
+		Joins("JOIN users ON users.id = coach_profiles.user_id").
+		Joins("LEFT JOIN profiles ON profiles.user_id = coach_profiles.user_id").
+		Where("coach_profiles.digest_enabled = ? AND users.is_active = ? AND users.is_banned = ?", true, true, false).
+		Order("coach_profiles.id ASC").
+		Scan(&coaches).Error
+	return coaches, err
+}
+
+// ClaimDigest stamps last_digest_sent_at unless a digest was already sent after notBefore.
+// It reports false when another run got there first, so each period is delivered once.
+func (r *CoachRepository) ClaimDigest(ctx context.Context, coachID uint, sentAt, notBefore time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&models.CoachProfile{}).
+		Where("id = ? AND (last_digest_sent_at IS NULL OR last_digest_sent_at < ?)", coachID, notBefore).
+		UpdateColumn("last_digest_sent_at", sentAt)
+	return result.RowsAffected > 0, result.Error
+}
+
+// GetDigestClientActivity returns per-client workout counts for [start, end) and the last contact time.
+// Missed workouts are those scheduled in [startDate, endDate] that were never completed.
+func (r *CoachRepository) GetDigestClientActivity(
+	ctx context.Context,
+	coachID uint,
+	start, end time.Time,
+	startDate, endDate string,
+) ([]DigestClientActivity, error) {
+	var rows []DigestClientActivity
+	err := r.db.WithContext(ctx).
+		Table("client_profiles").
+		Select("client_profiles.id AS client_id, "+
+			"COALESCE(profiles.first_name, '') AS first_name, COALESCE(profiles.last_name, '') AS last_name, "+
+			"(SELECT COUNT(*) FROM workouts w WHERE w.client_id = client_profiles.id AND w.coach_id = ? "+
+			"AND w.status = 'completed' AND w.completed_at >= ? AND w.completed_at < ?) AS workouts_completed, "+
+			"(SELECT COUNT(*) FROM workouts w WHERE w.client_id = client_profiles.id AND w.coach_id = ? "+
+			"AND w.status <> 'completed' AND w.scheduled_date >= ? AND w.scheduled_date <= ?) AS workouts_missed, "+
+			"GREATEST("+
+			"(SELECT MAX(c.last_message_at) FROM conversations c WHERE c.client_id = client_profiles.id AND c.coach_id = ?), "+
+			"(SELECT MAX(s.completed_at) FROM sessions s WHERE s.client_id = client_profiles.id AND s.coach_id = ? AND s.status = 'completed')"+
+			") AS last_contact_at",
+			coachID, start, end,
+			coachID, startDate, endDate,
+			coachID, coachID).
+		Joins("LEFT JOIN profiles ON profiles.user_id = client_profiles.user_id").
+		Where("client_profiles.coach_id = ? AND client_profiles.status = ?", coachID, "active").
+		Order("profiles.first_name ASC, profiles.last_name ASC, client_profiles.id ASC").
+		Scan(&rows).Error
+	return rows, err
+}
@@ -0,0 +1,92 @@
+package repositories
+
+import (
+	"chalk-api/pkg/db"
+	"chalk-api/pkg/models"
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type DataExportRepository struct {
+	db *gorm.DB
+}
+
+func NewDataExportRepository(db *gorm.DB) *DataExportRepository {
+	return &DataExportRepository{db: db}
+}
+
+// Create inserts a pending export. It returns false without an error when the user
+// already has an export in flight (enforced by idx_data_exports_user_in_flight).
+func (r *DataExportRepository) Create(ctx context.Context, export *models.DataExport) (bool, error) {
+	if err := r.db.WithContext(ctx).Create(export).Error; err != nil {
+		if isDuplicateKeyError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *DataExportRepository) GetByID(ctx context.Context, id uint) (*models.DataExport, error) {
+	var export models.DataExport
+	err := db.UsePrimary(r.db.WithContext(ctx)).First(&export, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &export, nil
+}
+
+func (r *DataExportRepository) GetLatestByUser(ctx context.Context, userID uint) (*models.DataExport, error) {
+	var export models.DataExport
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("created_at DESC, id DESC").
+		First(&export).Error
+	if err != nil {
+		return nil, err
+	}
+	return &export, nil
+}
+
+func (r *DataExportRepository) GetInFlightByUser(ctx context.Context, userID uint) (*models.DataExport, error) {
+	var export models.DataExport
+	err := db.UsePrimary(r.db.WithContext(ctx)).
+		Where("user_id = ? AND status IN ?", userID, []string{models.DataExportStatusPending, models.DataExportStatusProcessing}).
+		First(&export).Error
+	if err != nil {
+		return nil, err
+	}
+	return &export, nil
+}
+
+func (r *DataExportRepository) MarkProcessing(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).
+		Model(&models.DataExport{}).
+		Where("id = ?", id).
+		Update("status", models.DataExportStatusProcessing).Error
+}
+
+func (r *DataExportRepository) MarkReady(ctx context.Context, id uint, objectKey, downloadURL string, expiresAt time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&models.DataExport{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"status":       models.DataExportStatusReady,
+			"object_key":   objectKey,
+			"download_url": downloadURL,
+			"expires_at":   expiresAt,
+			"completed_at": time.Now(),
+		}).Error
+}
+
+func (r *DataExportRepository) MarkFailed(ctx context.Context, id uint, reason string) error {
+	return r.db.WithContext(ctx).
+		Model(&models.DataExport{}).
+		Where("id = ? AND status IN ?", id, []string{models.DataExportStatusPending, models.DataExportStatusProcessing}).
+		Updates(map[string]any{
+			"status":         models.DataExportStatusFailed,
+			"failure_reason": reason,
+		}).Error
+}
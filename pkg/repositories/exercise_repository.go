@@ -3,8 +3,11 @@ package repositories
 import (
 	"chalk-api/pkg/models"
 	"context"
+	"strings"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type ExerciseRepository struct {
@@ -85,6 +88,17 @@ func (r *ExerciseRepository) Search(ctx context.Context, query string, limit, of
 	return exercises, total, err
 }
 
+// ListByIDs returns the exercises with the given IDs, active or not, in no particular order. IDs
+// that don't exist are left out.
+func (r *ExerciseRepository) ListByIDs(ctx context.Context, ids []uint) ([]models.Exercise, error) {
+	var exercises []models.Exercise
+	if len(ids) == 0 {
+		return exercises, nil
+	}
+	err := r.db.WithContext(ctx).Where("id IN ?", ids).Find(&exercises).Error
+	return exercises, err
+}
+
 // ListByCoach returns a coach's custom exercises
 func (r *ExerciseRepository) ListByCoach(ctx context.Context, coachID uint) ([]models.Exercise, error) {
 	var exercises []models.Exercise
@@ -116,3 +130,172 @@ func (r *ExerciseRepository) GetByExternalID(ctx context.Context, source, extern
 	}
 	return &exercise, nil
 }
+
+// UpsertSystemBatch inserts or refreshes seeded system exercises keyed by (source, external_id),
+// writing batchSize rows per statement. IDs are populated on every row, inserted or updated.
+func (r *ExerciseRepository) UpsertSystemBatch(ctx context.Context, exercises []models.Exercise, batchSize int) error {
+	if len(exercises) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:     []clause.Column{{Name: "source"}, {Name: "external_id"}},
+			TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "is_system AND external_id IS NOT NULL"}}},
+			DoUpdates: clause.AssignmentColumns([]string{
+				"name", "description", "instructions",
+				"gif_url", "video_url", "thumbnail_url",
+				"category", "primary_muscle_groups", "secondary_muscle_groups",
+				"primary_equipment", "optional_equipment",
+				"difficulty", "measurement_type",
+				"coaching_cues", "common_mistakes", "tags",
+				"is_active", "updated_at",
+			}),
+		}).
+		CreateInBatches(&exercises, batchSize).Error
+}
+
+// UpsertAlternatives records exercise→alternative pairs. A pair that already exists keeps its row
+// and takes the new reason, so repeated inserts never duplicate a suggestion.
+func (r *ExerciseRepository) UpsertAlternatives(ctx context.Context, alternatives []models.ExerciseAlternative) error {
+	if len(alternatives) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "exercise_id"}, {Name: "alternative_exercise_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"reason", "updated_at"}),
+		}).
+		Create(&alternatives).Error
+}
+
+// ListAlternatives returns the active alternatives for each of the given exercises, with the
+// alternative exercise preloaded, grouped by exercise in the order they were added.
+func (r *ExerciseRepository) ListAlternatives(ctx context.Context, exerciseIDs []uint) ([]models.ExerciseAlternative, error) {
+	var alternatives []models.ExerciseAlternative
+	if len(exerciseIDs) == 0 {
+		return alternatives, nil
+	}
+	err := r.db.WithContext(ctx).
+		Joins("JOIN exercises alt ON alt.id = exercise_alternatives.alternative_exercise_id AND alt.is_active").
+		Where("exercise_alternatives.exercise_id IN ?", exerciseIDs).
+		Preload("Alternative").
+		Order("exercise_alternatives.exercise_id ASC, exercise_alternatives.id ASC").
+		Find(&alternatives).Error
+	return alternatives, err
+}
+
+// DeleteAlternative removes one exercise→alternative pair; the reverse pair, if any, is kept.
+func (r *ExerciseRepository) DeleteAlternative(ctx context.Context, exerciseID, alternativeExerciseID uint) error {
+	result := r.db.WithContext(ctx).
+		Where("exercise_id = ? AND alternative_exercise_id = ?", exerciseID, alternativeExerciseID).
+		Delete(&models.ExerciseAlternative{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// --- Bulk admin edits ---
+
+// ExerciseBulkFilter selects system exercises for an admin bulk edit. Every criterion that is set
+// must match.
+type ExerciseBulkFilter struct {
+	IDs         []uint
+	NamePattern string // ILIKE pattern, escaped by the caller
+	Tag         string
+}
+
+// ExerciseBulkChanges is what a bulk edit writes. RemoveTags is applied before AddTags; nil fields
+// are left alone.
+type ExerciseBulkChanges struct {
+	AddTags               []string
+	RemoveTags            []string
+	Difficulty            *string
+	PrimaryMuscleGroups   *[]string
+	SecondaryMuscleGroups *[]string
+}
+
+func (r *ExerciseRepository) bulkFilterQuery(ctx context.Context, filter ExerciseBulkFilter) *gorm.DB {
+	query := r.db.WithContext(ctx).Model(&models.Exercise{}).Where("is_system = ?", true)
+	if len(filter.IDs) > 0 {
+		query = query.Where("id IN ?", filter.IDs)
+	}
+	if filter.NamePattern != "" {
+		query = query.Where("name ILIKE ?", filter.NamePattern)
+	}
+	if filter.Tag != "" {
+		query = query.Where("? = ANY(tags)", filter.Tag)
+	}
+	return query
+}
+
+// CountBulkMatches counts the system exercises a bulk edit filter selects, active or not.
+func (r *ExerciseRepository) CountBulkMatches(ctx context.Context, filter ExerciseBulkFilter) (int64, error) {
+	var total int64
+	err := r.bulkFilterQuery(ctx, filter).Count(&total).Error
+	return total, err
+}
+
+// ListBulkMatchIDs returns the IDs of the system exercises a bulk edit filter selects, in ID order.
+func (r *ExerciseRepository) ListBulkMatchIDs(ctx context.Context, filter ExerciseBulkFilter) ([]uint, error) {
+	var ids []uint
+	err := r.bulkFilterQuery(ctx, filter).Order("id ASC").Pluck("id", &ids).Error
+	return ids, err
+}
+
+// BulkUpdateExercises writes changes to the given system exercises in one statement and returns the
+// number of rows updated. Tags keep their order, with added tags appended and duplicates dropped.
+func (r *ExerciseRepository) BulkUpdateExercises(ctx context.Context, ids []uint, changes ExerciseBulkChanges) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	updates := map[string]any{"updated_at": time.Now().UTC()}
+	if changes.Difficulty != nil {
+		updates["difficulty"] = *changes.Difficulty
+	}
+	if changes.PrimaryMuscleGroups != nil {
+		updates["primary_muscle_groups"] = gorm.Expr("?::text[]", textArrayLiteral(*changes.PrimaryMuscleGroups))
+	}
+	if changes.SecondaryMuscleGroups != nil {
+		updates["secondary_muscle_groups"] = gorm.Expr("?::text[]", textArrayLiteral(*changes.SecondaryMuscleGroups))
+	}
+	if len(changes.AddTags) > 0 || len(changes.RemoveTags) > 0 {
+		updates["tags"] = gorm.Expr(`ARRAY(
+			SELECT tag FROM (
+				SELECT tag, MIN(position) AS position
+				FROM unnest(COALESCE(tags, '{}'::text[]) || ?::text[]) WITH ORDINALITY AS t(tag, position)
+				WHERE tag <> ALL(?::text[])
+				GROUP BY tag
+			) kept
+			ORDER BY position
+		)`, textArrayLiteral(changes.AddTags), textArrayLiteral(changes.RemoveTags))
+	}
+
+	result := r.db.WithContext(ctx).
+		Model(&models.Exercise{}).
+		Where("id IN ? AND is_system = ?", ids, true).
+		Updates(updates)
+	return result.RowsAffected, result.Error
+}
+
+var textArrayEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+// textArrayLiteral formats values as a Postgres array literal such as {"push","compound"}. gorm
+// expands slice arguments into row lists, so arrays are bound as a literal and cast with ::text[].
+func textArrayLiteral(values []string) string {
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, value := range values {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteByte('"')
+		b.WriteString(textArrayEscaper.Replace(value))
+		b.WriteByte('"')
+	}
+	b.WriteByte('}')
+	return b.String()
+}
@@ -0,0 +1,78 @@
+package repositories
+
+import (
+	"chalk-api/pkg/models"
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// ErrFeatureFlagKeyTaken is returned by Create when another flag already uses the key
+var ErrFeatureFlagKeyTaken = errors.New("feature flag key already in use")
+
+type FeatureFlagRepository struct {
+	db *gorm.DB
+}
+
+func NewFeatureFlagRepository(db *gorm.DB) *FeatureFlagRepository {
+	return &FeatureFlagRepository{db: db}
+}
+
+// List returns every flag ordered by key.
+func (r *FeatureFlagRepository) List(ctx context.Context) ([]models.FeatureFlag, error) {
+	var flags []models.FeatureFlag
+	err := r.db.WithContext(ctx).Order("key ASC").Find(&flags).Error
+	return flags, err
+}
+
+func (r *FeatureFlagRepository) GetByID(ctx context.Context, id uint) (*models.FeatureFlag, error) {
+	var flag models.FeatureFlag
+	if err := r.db.WithContext(ctx).First(&flag, id).Error; err != nil {
+		return nil, err
+	}
+	return &flag, nil
+}
+
+func (r *FeatureFlagRepository) GetByKey(ctx context.Context, key string) (*models.FeatureFlag, error) {
+	var flag models.FeatureFlag
+	if err := r.db.WithContext(ctx).Where("key = ?", key).First(&flag).Error; err != nil {
+		return nil, err
+	}
+	return &flag, nil
+}
+
+func (r *FeatureFlagRepository) Create(ctx context.Context, flag *models.FeatureFlag) error {
+	err := r.db.WithContext(ctx).Create(flag).Error
+	if err != nil && isUniqueViolation(err) {
+		return ErrFeatureFlagKeyTaken
+	}
+	return err
+}
+
+func (r *FeatureFlagRepository) Update(ctx context.Context, flag *models.FeatureFlag) error {
+	return r.db.WithContext(ctx).Save(flag).Error
+}
+
+func (r *FeatureFlagRepository) Delete(ctx context.Context, id uint) error {
+	result := r.db.WithContext(ctx).Delete(&models.FeatureFlag{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// CoachIDsForUser returns the coach profile IDs a user's coach-scoped flags are checked against: their
+// own coach profile, if any, and the coaches they are an active client of.
+func (r *FeatureFlagRepository) CoachIDsForUser(ctx context.Context, userID uint) ([]uint, error) {
+	var coachIDs []uint
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT id FROM coach_profiles WHERE user_id = ?
+		UNION
+		SELECT coach_id FROM client_profiles WHERE user_id = ? AND status = 'active'
+	`, userID, userID).Scan(&coachIDs).Error
+	return coachIDs, err
+}
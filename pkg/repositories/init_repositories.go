@@ -2,11 +2,17 @@ package repositories
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// ErrVersionConflict is returned by versioned updates when the row changed after it was loaded
+var ErrVersionConflict = errors.New("record was modified concurrently")
+
 type RepositoriesCollection struct {
 	db *gorm.DB
 
@@ -22,7 +28,13 @@ type RepositoriesCollection struct {
 	Nutrition    *NutritionRepository
 	Progress     *ProgressRepository
 	Message      *MessageRepository
+	Notification *NotificationRepository
+	DataExport   *DataExportRepository
+	ClientReport *ClientReportRepository
 	Outbox       *OutboxRepository
+	RequestEvent *RequestEventRepository
+	FeatureFlag  *FeatureFlagRepository
+	WebhookInbox *WebhookInboxRepository
 }
 
 func InitializeRepositories(db *gorm.DB) (*RepositoriesCollection, error) {
@@ -45,7 +57,13 @@ func newRepositoriesCollection(db *gorm.DB) *RepositoriesCollection {
 		Nutrition:    NewNutritionRepository(db),
 		Progress:     NewProgressRepository(db),
 		Message:      NewMessageRepository(db),
+		Notification: NewNotificationRepository(db),
+		DataExport:   NewDataExportRepository(db),
+		ClientReport: NewClientReportRepository(db),
 		Outbox:       NewOutboxRepository(db),
+		RequestEvent: NewRequestEventRepository(db),
+		FeatureFlag:  NewFeatureFlagRepository(db),
+		WebhookInbox: NewWebhookInboxRepository(db),
 	}
 }
 
@@ -63,3 +81,35 @@ func (r *RepositoriesCollection) WithTransaction(
 		return fn(tx, txRepos)
 	})
 }
+
+// PoolStats reports the connection pool statistics of the underlying sql.DB.
+func (r *RepositoriesCollection) PoolStats() (sql.DBStats, error) {
+	if r == nil || r.db == nil {
+		return sql.DBStats{}, fmt.Errorf("repositories collection is not initialized")
+	}
+
+	sqlDB, err := r.db.DB()
+	if err != nil {
+		return sql.DBStats{}, err
+	}
+	return sqlDB.Stats(), nil
+}
+
+// saveVersioned writes every column of model, but not its associations, only if the stored version
+// still matches *version, and bumps *version when it does. Selecting the columns explicitly keeps Save
+// from falling back to an insert when the WHERE matches nothing; *version is left untouched on failure.
+func saveVersioned(db *gorm.DB, model any, version *int) error {
+	expected := *version
+	*version = expected + 1
+
+	result := db.Select("*").Omit(clause.Associations).Where("version = ?", expected).Save(model)
+	if result.Error != nil {
+		*version = expected
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		*version = expected
+		return ErrVersionConflict
+	}
+	return nil
+}
@@ -1,11 +1,13 @@
 package repositories
 
 import (
+	"chalk-api/pkg/db"
 	"chalk-api/pkg/models"
 	"context"
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type MessageRepository struct {
@@ -39,26 +41,69 @@ func (r *MessageRepository) GetOrCreateConversation(ctx context.Context, coachID
 	return &convo, nil
 }
 
-// ListConversations returns all conversations for a user (as coach or client) sorted by most recent message
-func (r *MessageRepository) ListConversations(ctx context.Context, userID uint) ([]models.Conversation, error) {
+// FindConversation returns the conversation for a coach-client pair without creating one
+func (r *MessageRepository) FindConversation(ctx context.Context, coachID, clientID uint) (*models.Conversation, error) {
+	var convo models.Conversation
+	err := r.db.WithContext(ctx).
+		Where("coach_id = ? AND client_id = ?", coachID, clientID).
+		First(&convo).Error
+	if err != nil {
+		return nil, err
+	}
+	return &convo, nil
+}
+
+// MoveConversation hands a conversation, with its history, to a new coach-client pair
+func (r *MessageRepository) MoveConversation(ctx context.Context, id, coachID, clientID uint) error {
+	return r.db.WithContext(ctx).
+		Model(&models.Conversation{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"coach_id":  coachID,
+			"client_id": clientID,
+		}).Error
+}
+
+// CloseConversation makes a conversation read-only
+func (r *MessageRepository) CloseConversation(ctx context.Context, id uint, at time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&models.Conversation{}).
+		Where("id = ? AND closed_at IS NULL", id).
+		Update("closed_at", at).Error
+}
+
+// SetConversationActive shows or hides the coach-client pair's conversation, if there is one
+func (r *MessageRepository) SetConversationActive(ctx context.Context, coachID, clientID uint, active bool) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Model(&models.Conversation{}).
+		Where("coach_id = ? AND client_id = ? AND is_active <> ?", coachID, clientID, active).
+		Update("is_active", active)
+	return result.RowsAffected, result.Error
+}
+
+// ListConversations returns all conversations for a user (as coach or client) sorted by most recent message.
+// Conversations of archived relationships are left out unless includeInactive is set.
+func (r *MessageRepository) ListConversations(ctx context.Context, userID uint, includeInactive bool) ([]models.Conversation, error) {
 	var convos []models.Conversation
 
 	// Find conversations where user is either the coach or the client
-	err := r.db.WithContext(ctx).
+	query := r.db.WithContext(ctx).
 		Preload("Coach.User.Profile").
 		Preload("Client.User.Profile").
 		Joins("LEFT JOIN coach_profiles ON coach_profiles.id = conversations.coach_id").
 		Joins("LEFT JOIN client_profiles ON client_profiles.id = conversations.client_id").
-		Where("coach_profiles.user_id = ? OR client_profiles.user_id = ?", userID, userID).
-		Order("last_message_at DESC NULLS LAST").
-		Find(&convos).Error
+		Where("(coach_profiles.user_id = ? OR client_profiles.user_id = ?)", userID, userID)
+	if !includeInactive {
+		query = query.Where("conversations.is_active = ?", true)
+	}
+	err := query.Order("last_message_at DESC NULLS LAST").Find(&convos).Error
 
 	return convos, err
 }
 
 func (r *MessageRepository) GetConversation(ctx context.Context, id uint) (*models.Conversation, error) {
 	var convo models.Conversation
-	err := r.db.WithContext(ctx).
+	err := db.UsePrimary(r.db.WithContext(ctx)).
 		Preload("Coach.User.Profile").
 		Preload("Client.User.Profile").
 		First(&convo, id).Error
@@ -104,23 +149,77 @@ func (r *MessageRepository) ListMessages(ctx context.Context, conversationID uin
 	return messages, total, err
 }
 
-// MarkAsRead marks all unread messages in a conversation as read for the given user
-func (r *MessageRepository) MarkAsRead(ctx context.Context, conversationID, senderID uint) error {
-	now := time.Now()
+// ListMessagesForExport returns up to limit messages of a conversation with IDs above afterID, oldest
+// first.
+func (r *MessageRepository) ListMessagesForExport(ctx context.Context, conversationID, afterID uint, limit int) ([]models.Message, error) {
+	var messages []models.Message
+	err := r.db.WithContext(ctx).
+		Where("conversation_id = ? AND id > ?", conversationID, afterID).
+		Order("id ASC").
+		Limit(limit).
+		Find(&messages).Error
+	return messages, err
+}
+
+// ListBySender returns every message a user has sent, oldest first
+func (r *MessageRepository) ListBySender(ctx context.Context, senderID uint) ([]models.Message, error) {
+	var messages []models.Message
+	err := r.db.WithContext(ctx).
+		Where("sender_id = ?", senderID).
+		Order("created_at ASC").
+		Find(&messages).Error
+	return messages, err
+}
+
+// GetMessage returns a single message by ID
+func (r *MessageRepository) GetMessage(ctx context.Context, id uint) (*models.Message, error) {
+	var message models.Message
+	err := r.db.WithContext(ctx).First(&message, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &message, nil
+}
+
+// MarkAsReadTx stamps readAt on the other party's unread messages in a conversation, limited to
+// IDs at or before upToMessageID when it is non-zero. It returns how many messages were marked and
+// the highest marked ID (0 when nothing was unread).
+func (r *MessageRepository) MarkAsReadTx(ctx context.Context, tx *gorm.DB, conversationID, readerID, upToMessageID uint, readAt time.Time) (int64, uint, error) {
 	// Mark messages as read where the sender is NOT the current user (you read their messages)
-	return r.db.WithContext(ctx).
+	query := tx.WithContext(ctx).
 		Model(&models.Message{}).
-		Where("conversation_id = ? AND sender_id != ? AND read_at IS NULL", conversationID, senderID).
-		Update("read_at", now).Error
+		Where("conversation_id = ? AND sender_id != ? AND read_at IS NULL", conversationID, readerID)
+	if upToMessageID != 0 {
+		query = query.Where("id <= ?", upToMessageID)
+	}
+
+	var lastID *uint
+	if err := query.Session(&gorm.Session{}).Select("MAX(id)").Scan(&lastID).Error; err != nil {
+		return 0, 0, err
+	}
+	if lastID == nil {
+		return 0, 0, nil
+	}
+
+	result := query.Where("id <= ?", *lastID).Update("read_at", readAt)
+	if result.Error != nil {
+		return 0, 0, result.Error
+	}
+	return result.RowsAffected, *lastID, nil
 }
 
-// GetUnreadCount returns the number of unread messages across all conversations for a user
-func (r *MessageRepository) GetUnreadCount(ctx context.Context, userID uint) (int64, error) {
+// GetUnreadCount returns the number of unread messages across all conversations for a user, skipping
+// inactive conversations unless includeInactive is set
+func (r *MessageRepository) GetUnreadCount(ctx context.Context, userID uint, includeInactive bool) (int64, error) {
 	var count int64
 
-	err := r.db.WithContext(ctx).
+	conversationJoin := "JOIN conversations ON conversations.id = messages.conversation_id"
+	if !includeInactive {
+		conversationJoin += " AND conversations.is_active"
+	}
+	err := db.UsePrimary(r.db.WithContext(ctx)).
 		Model(&models.Message{}).
-		Joins("JOIN conversations ON conversations.id = messages.conversation_id").
+		Joins(conversationJoin).
 		Joins("LEFT JOIN coach_profiles ON coach_profiles.id = conversations.coach_id").
 		Joins("LEFT JOIN client_profiles ON client_profiles.id = conversations.client_id").
 		Where("(coach_profiles.user_id = ? OR client_profiles.user_id = ?) AND messages.sender_id != ? AND messages.read_at IS NULL",
@@ -129,3 +228,274 @@ func (r *MessageRepository) GetUnreadCount(ctx context.Context, userID uint) (in
 
 	return count, err
 }
+
+// --- Saved Replies ---
+
+func (r *MessageRepository) CreateSavedReply(ctx context.Context, reply *models.SavedReply) error {
+	return r.db.WithContext(ctx).Create(reply).Error
+}
+
+func (r *MessageRepository) CountSavedReplies(ctx context.Context, coachID uint) (int64, error) {
+	var count int64
+	err := db.UsePrimary(r.db.WithContext(ctx)).
+		Model(&models.SavedReply{}).
+		Where("coach_id = ?", coachID).
+		Count(&count).Error
+	return count, err
+}
+
+// ListSavedReplies returns a coach's saved replies, most used first. A non-empty search filters by title.
+func (r *MessageRepository) ListSavedReplies(ctx context.Context, coachID uint, search string) ([]models.SavedReply, error) {
+	var replies []models.SavedReply
+	query := r.db.WithContext(ctx).Where("coach_id = ?", coachID)
+	if search != "" {
+		query = query.Where("title ILIKE ?", "%"+search+"%")
+	}
+	err := query.
+		Order("usage_count DESC, title ASC").
+		Find(&replies).Error
+	return replies, err
+}
+
+func (r *MessageRepository) GetSavedReplyByID(ctx context.Context, id uint) (*models.SavedReply, error) {
+	var reply models.SavedReply
+	err := r.db.WithContext(ctx).First(&reply, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &reply, nil
+}
+
+func (r *MessageRepository) UpdateSavedReply(ctx context.Context, reply *models.SavedReply) error {
+	return r.db.WithContext(ctx).Save(reply).Error
+}
+
+func (r *MessageRepository) DeleteSavedReply(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&models.SavedReply{}, id).Error
+}
+
+// IncrementSavedReplyUsageTx bumps usage_count within an existing transaction.
+func (r *MessageRepository) IncrementSavedReplyUsageTx(ctx context.Context, tx *gorm.DB, id uint) error {
+	return tx.WithContext(ctx).
+		Model(&models.SavedReply{}).
+		Where("id = ?", id).
+		UpdateColumn("usage_count", gorm.Expr("usage_count + 1")).Error
+}
+
+// --- Scheduled Messages ---
+
+func (r *MessageRepository) CreateScheduledMessage(ctx context.Context, scheduled *models.ScheduledMessage) error {
+	return r.db.WithContext(ctx).Create(scheduled).Error
+}
+
+func (r *MessageRepository) GetScheduledMessage(ctx context.Context, id uint) (*models.ScheduledMessage, error) {
+	var scheduled models.ScheduledMessage
+	err := db.UsePrimary(r.db.WithContext(ctx)).First(&scheduled, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &scheduled, nil
+}
+
+// ListScheduledMessages returns a sender's scheduled messages with the given status, soonest first.
+func (r *MessageRepository) ListScheduledMessages(ctx context.Context, senderID uint, status string) ([]models.ScheduledMessage, error) {
+	var scheduled []models.ScheduledMessage
+	err := r.db.WithContext(ctx).
+		Where("sender_id = ? AND status = ?", senderID, status).
+		Order("scheduled_at ASC, id ASC").
+		Find(&scheduled).Error
+	return scheduled, err
+}
+
+// CancelScheduledMessage cancels a scheduled message that is still pending.
+// It returns false when the message was already claimed, sent or cancelled.
+func (r *MessageRepository) CancelScheduledMessage(ctx context.Context, id uint, at time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&models.ScheduledMessage{}).
+		Where("id = ? AND status = ?", id, models.ScheduledMessagePending).
+		Updates(map[string]any{
+			"status":       models.ScheduledMessageCancelled,
+			"cancelled_at": at,
+			"updated_at":   at,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// ClaimDueScheduledMessages atomically claims pending scheduled messages due at or before now.
+// Like the outbox, it uses row locks with SKIP LOCKED so multiple workers can run safely.
+func (r *MessageRepository) ClaimDueScheduledMessages(ctx context.Context, now time.Time, limit int) ([]models.ScheduledMessage, error) {
+	if limit <= 0 {
+		limit = 25
+	}
+
+	claimed := make([]models.ScheduledMessage, 0, limit)
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND scheduled_at <= ?", models.ScheduledMessagePending, now).
+			Order("scheduled_at ASC, id ASC").
+			Limit(limit).
+			Find(&claimed).Error; err != nil {
+			return err
+		}
+
+		if len(claimed) == 0 {
+			return nil
+		}
+
+		ids := make([]uint, len(claimed))
+		for i := range claimed {
+			ids[i] = claimed[i].ID
+		}
+
+		if err := tx.Model(&models.ScheduledMessage{}).
+			Where("id IN ?", ids).
+			Updates(map[string]any{
+				"status":             models.ScheduledMessageProcessing,
+				"processing_started": now,
+				"updated_at":         now,
+			}).Error; err != nil {
+			return err
+		}
+
+		for i := range claimed {
+			claimed[i].Status = models.ScheduledMessageProcessing
+			claimed[i].ProcessingStarted = &now
+		}
+		return nil
+	})
+
+	return claimed, err
+}
+
+// MarkScheduledMessageSentTx records delivery in the same transaction that created the message,
+// so a crash can't leave a delivered message looking undelivered and send it twice.
+func (r *MessageRepository) MarkScheduledMessageSentTx(ctx context.Context, tx *gorm.DB, id, messageID uint, sentAt time.Time) error {
+	result := tx.WithContext(ctx).
+		Model(&models.ScheduledMessage{}).
+		Where("id = ? AND status = ?", id, models.ScheduledMessageProcessing).
+		Updates(map[string]any{
+			"status":             models.ScheduledMessageSent,
+			"message_id":         messageID,
+			"sent_at":            sentAt,
+			"processing_started": nil,
+			"last_error":         nil,
+			"updated_at":         sentAt,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// MarkScheduledMessageRetry puts a claimed message back in the queue after a transient failure.
+func (r *MessageRepository) MarkScheduledMessageRetry(ctx context.Context, id uint, attempts int, lastError string) error {
+	return r.db.WithContext(ctx).
+		Model(&models.ScheduledMessage{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"status":             models.ScheduledMessagePending,
+			"attempts":           attempts,
+			"last_error":         lastError,
+			"processing_started": nil,
+			"updated_at":         time.Now().UTC(),
+		}).Error
+}
+
+func (r *MessageRepository) MarkScheduledMessageFailed(ctx context.Context, id uint, attempts int, lastError string) error {
+	return r.db.WithContext(ctx).
+		Model(&models.ScheduledMessage{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"status":             models.ScheduledMessageFailed,
+			"attempts":           attempts,
+			"last_error":         lastError,
+			"processing_started": nil,
+			"updated_at":         time.Now().UTC(),
+		}).Error
+}
+
+// RequeueStuckScheduledMessages moves messages claimed longer than olderThan ago back to pending.
+// Useful when a worker crashes after claiming but before delivering.
+func (r *MessageRepository) RequeueStuckScheduledMessages(ctx context.Context, olderThan time.Duration) (int64, error) {
+	if olderThan <= 0 {
+		olderThan = 10 * time.Minute
+	}
+
+	now := time.Now().UTC()
+	result := r.db.WithContext(ctx).
+		Model(&models.ScheduledMessage{}).
+		Where("status = ? AND processing_started IS NOT NULL AND processing_started < ?", models.ScheduledMessageProcessing, now.Add(-olderThan)).
+		Updates(map[string]any{
+			"status":             models.ScheduledMessagePending,
+			"processing_started": nil,
+			"updated_at":         now,
+		})
+	return result.RowsAffected, result.Error
+}
+
+// --- Retention ---
+
+// RetentionCoach is a coach with a message retention policy.
+type RetentionCoach struct {
+	CoachID               uint
+	MessagesRetentionDays int
+}
+
+// ListRetentionCoaches returns every coach with a message retention policy, by ID.
+func (r *MessageRepository) ListRetentionCoaches(ctx context.Context) ([]RetentionCoach, error) {
+	var coaches []RetentionCoach
+	err := r.db.WithContext(ctx).
+		Model(&models.CoachProfile{}).
+		Select("id AS coach_id, messages_retention_days").
+		Where("messages_retention_days IS NOT NULL AND messages_retention_days > 0").
+		Order("id ASC").
+		Scan(&coaches).Error
+	return coaches, err
+}
+
+// DeleteCoachMessagesBefore deletes up to limit messages created before cutoff in the coach's
+// conversations. Callers repeat it until fewer than limit are removed.
+func (r *MessageRepository) DeleteCoachMessagesBefore(ctx context.Context, coachID uint, cutoff time.Time, limit int) (int64, error) {
+	result := r.db.WithContext(ctx).Exec(
+		`DELETE FROM messages WHERE id IN (
+			SELECT messages.id FROM messages
+			JOIN conversations ON conversations.id = messages.conversation_id
+			WHERE conversations.coach_id = ? AND messages.created_at < ?
+			ORDER BY messages.id
+			LIMIT ?
+		)`,
+		coachID, cutoff, limit,
+	)
+	return result.RowsAffected, result.Error
+}
+
+// DeleteCoachScheduledMessagesBefore removes the copies kept by sent, cancelled and failed scheduled
+// messages due before cutoff in the coach's conversations. Pending ones are left to be delivered.
+func (r *MessageRepository) DeleteCoachScheduledMessagesBefore(ctx context.Context, coachID uint, cutoff time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Exec(
+		`DELETE FROM scheduled_messages
+		WHERE conversation_id IN (SELECT id FROM conversations WHERE coach_id = ?)
+			AND scheduled_at < ? AND status IN ?`,
+		coachID, cutoff,
+		[]string{models.ScheduledMessageSent, models.ScheduledMessageCancelled, models.ScheduledMessageFailed},
+	)
+	return result.RowsAffected, result.Error
+}
+
+// ResetCoachConversationLastMessage points last_message_at of the coach's conversations that had
+// messages before cutoff at their newest remaining message, or clears it when none are left, so the
+// inbox doesn't sort on deleted messages.
+func (r *MessageRepository) ResetCoachConversationLastMessage(ctx context.Context, coachID uint, cutoff time.Time) error {
+	return r.db.WithContext(ctx).Exec(
+		`UPDATE conversations SET last_message_at = (
+			SELECT MAX(messages.created_at) FROM messages
+			WHERE messages.conversation_id = conversations.id
+		)
+		WHERE coach_id = ? AND last_message_at < ?`,
+		coachID, cutoff,
+	).Error
+}
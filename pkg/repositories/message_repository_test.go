@@ -0,0 +1,136 @@
+package repositories_test
+
+import (
+	"chalk-api/pkg/models"
+	"chalk-api/pkg/testutil"
+	"context"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// retentionConversation connects a new client to a new coach, sets the coach's retention policy
+// (nil keeps messages forever) and writes one message per age in days into their conversation.
+func retentionConversation(t *testing.T, stack *testutil.Stack, retentionDays *int, ages ...int) (*models.CoachProfile, uint) {
+	t.Helper()
+	ctx := context.Background()
+
+	coachUser, coach, _ := stack.CreateCoach(t)
+	clientUser, _ := stack.CreateUser(t)
+	client := stack.ConnectClient(t, coachUser, clientUser)
+	if retentionDays != nil {
+		if err := stack.DB.Model(coach).Update("messages_retention_days", *retentionDays).Error; err != nil {
+			t.Fatalf("set retention: %v", err)
+		}
+	}
+
+	conversation, err := stack.Repos.Message.GetOrCreateConversation(ctx, coach.ID, client.ID)
+	if err != nil {
+		t.Fatalf("create conversation: %v", err)
+	}
+	for _, age := range ages {
+		content := ageLabel(age)
+		message := &models.Message{
+			ConversationID: conversation.ID,
+			SenderID:       coachUser.ID,
+			Content:        &content,
+			CreatedAt:      time.Now().AddDate(0, 0, -age),
+		}
+		if err := stack.DB.Create(message).Error; err != nil {
+			t.Fatalf("create message: %v", err)
+		}
+	}
+	return coach, conversation.ID
+}
+
+func ageLabel(days int) string {
+	return strconv.Itoa(days) + " days old"
+}
+
+// remainingAges returns the labels of the messages left in a conversation, oldest first.
+func remainingAges(t *testing.T, stack *testutil.Stack, conversationID uint) []string {
+	t.Helper()
+	var contents []string
+	err := stack.DB.Model(&models.Message{}).
+		Where("conversation_id = ?", conversationID).
+		Order("created_at ASC").
+		Pluck("content", &contents).Error
+	if err != nil {
+		t.Fatalf("list messages: %v", err)
+	}
+	return contents
+}
+
+func labels(ages ...int) []string {
+	out := make([]string, len(ages))
+	for i, age := range ages {
+		out[i] = ageLabel(age)
+	}
+	return out
+}
+
+func TestDeleteCoachMessagesBeforeOnlyTouchesThatCoach(t *testing.T) {
+	stack := testutil.NewStack(t)
+	ctx := context.Background()
+
+	thirty, ninety := 30, 90
+	strict, strictConversation := retentionConversation(t, stack, &thirty, 120, 60, 10)
+	_, lenientConversation := retentionConversation(t, stack, &ninety, 120, 60, 10)
+	_, keepConversation := retentionConversation(t, stack, nil, 120, 60, 10)
+
+	// A cutoff that would cover every conversation's older messages only reaches the strict coach's,
+	// one batch at a time.
+	cutoff := time.Now().AddDate(0, 0, -thirty)
+	for _, want := range []int64{1, 1, 0} {
+		deleted, err := stack.Repos.Message.DeleteCoachMessagesBefore(ctx, strict.ID, cutoff, 1)
+		if err != nil {
+			t.Fatalf("delete: %v", err)
+		}
+		if deleted != want {
+			t.Fatalf("deleted %d messages in a batch of 1, want %d", deleted, want)
+		}
+	}
+
+	if got, want := remainingAges(t, stack, strictConversation), labels(10); !reflect.DeepEqual(got, want) {
+		t.Errorf("strict coach messages = %v, want %v", got, want)
+	}
+	for _, conversationID := range []uint{lenientConversation, keepConversation} {
+		if got, want := remainingAges(t, stack, conversationID), labels(120, 60, 10); !reflect.DeepEqual(got, want) {
+			t.Errorf("conversation %d messages = %v, want all of %v", conversationID, got, want)
+		}
+	}
+}
+
+func TestApplyMessageRetentionUsesEachCoachsWindow(t *testing.T) {
+	stack := testutil.NewStack(t)
+	ctx := context.Background()
+
+	thirty, ninety := 30, 90
+	_, strictConversation := retentionConversation(t, stack, &thirty, 120, 60, 10)
+	_, lenientConversation := retentionConversation(t, stack, &ninety, 120, 60, 10)
+	_, keepConversation := retentionConversation(t, stack, nil, 400, 120, 10)
+
+	deleted, err := stack.Services.Message.ApplyMessageRetention(ctx, time.Now())
+	if err != nil {
+		t.Fatalf("apply retention: %v", err)
+	}
+	if deleted != 3 {
+		t.Errorf("deleted %d messages, want 2 for the 30-day coach and 1 for the 90-day coach", deleted)
+	}
+
+	tests := []struct {
+		name           string
+		conversationID uint
+		want           []string
+	}{
+		{"30-day coach", strictConversation, labels(10)},
+		{"90-day coach", lenientConversation, labels(60, 10)},
+		{"coach without a policy", keepConversation, labels(400, 120, 10)},
+	}
+	for _, tt := range tests {
+		if got := remainingAges(t, stack, tt.conversationID); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s messages = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
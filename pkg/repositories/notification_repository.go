@@ -0,0 +1,171 @@
+package repositories
+
+import (
+	"chalk-api/pkg/models"
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type NotificationRepository struct {
+	db *gorm.DB
+}
+
+func NewNotificationRepository(db *gorm.DB) *NotificationRepository {
+	return &NotificationRepository{db: db}
+}
+
+func (r *NotificationRepository) Create(ctx context.Context, notification *models.Notification) error {
+	return r.db.WithContext(ctx).Create(notification).Error
+}
+
+func (r *NotificationRepository) GetByID(ctx context.Context, id uint) (*models.Notification, error) {
+	var notification models.Notification
+	err := r.db.WithContext(ctx).First(&notification, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &notification, nil
+}
+
+// GetLatestByType returns the user's newest notification of the given type
+func (r *NotificationRepository) GetLatestByType(ctx context.Context, userID uint, notificationType string) (*models.Notification, error) {
+	var notification models.Notification
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND type = ?", userID, notificationType).
+		Order("created_at DESC, id DESC").
+		First(&notification).Error
+	if err != nil {
+		return nil, err
+	}
+	return &notification, nil
+}
+
+// ListByUser returns a user's notifications, newest first
+func (r *NotificationRepository) ListByUser(ctx context.Context, userID uint, unreadOnly bool, limit, offset int) ([]models.Notification, int64, error) {
+	var notifications []models.Notification
+	var total int64
+
+	query := r.db.WithContext(ctx).Where("user_id = ?", userID)
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+
+	if err := query.Model(&models.Notification{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.
+		Order("created_at DESC, id DESC").
+		Limit(limit).Offset(offset).
+		Find(&notifications).Error
+
+	return notifications, total, err
+}
+
+func (r *NotificationRepository) CountUnread(ctx context.Context, userID uint) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *NotificationRepository) MarkRead(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).
+		Model(&models.Notification{}).
+		Where("id = ? AND read_at IS NULL", id).
+		Update("read_at", time.Now()).Error
+}
+
+func (r *NotificationRepository) MarkAllRead(ctx context.Context, userID uint) error {
+	return r.db.WithContext(ctx).
+		Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", time.Now()).Error
+}
+
+// --- Push Tickets ---
+
+func (r *NotificationRepository) CreatePushTickets(ctx context.Context, tickets []models.PushTicket) error {
+	if len(tickets) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Create(&tickets).Error
+}
+
+// ListPushTicketsAwaitingReceipt returns accepted tickets sent in [sentFrom, sentBefore) whose
+// receipt hasn't been fetched yet, oldest first.
+func (r *NotificationRepository) ListPushTicketsAwaitingReceipt(ctx context.Context, sentFrom, sentBefore time.Time, limit int) ([]models.PushTicket, error) {
+	var tickets []models.PushTicket
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND ticket_id IS NOT NULL AND receipt_checked_at IS NULL", "ok").
+		Where("created_at >= ? AND created_at < ?", sentFrom, sentBefore).
+		Order("created_at ASC, id ASC").
+		Limit(limit).
+		Find(&tickets).Error
+	return tickets, err
+}
+
+// SavePushReceipt stores the receipt Expo returned for a ticket.
+func (r *NotificationRepository) SavePushReceipt(ctx context.Context, id uint, status string, errorCode *string, checkedAt time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&models.PushTicket{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"receipt_status":     status,
+			"receipt_error_code": errorCode,
+			"receipt_checked_at": checkedAt,
+		}).Error
+}
+
+// PushDeliveryCount is the outcome of the pushes sent to one platform. Delivered pushes have an ok
+// ticket and an ok receipt; failed ones have an error on either; the rest are still pending.
+type PushDeliveryCount struct {
+	Platform  string
+	Sent      int64
+	Delivered int64
+	Failed    int64
+	Pending   int64
+}
+
+// CountPushDeliveries groups the tickets created since by the platform of their device token.
+func (r *NotificationRepository) CountPushDeliveries(ctx context.Context, since time.Time) ([]PushDeliveryCount, error) {
+	var counts []PushDeliveryCount
+	err := r.db.WithContext(ctx).
+		Table("push_tickets").
+		Select("COALESCE(device_tokens.platform, 'unknown') AS platform, "+
+			"COUNT(*) AS sent, "+
+			"COUNT(*) FILTER (WHERE push_tickets.status = 'ok' AND push_tickets.receipt_status = 'ok') AS delivered, "+
+			"COUNT(*) FILTER (WHERE push_tickets.status = 'error' OR push_tickets.receipt_status = 'error') AS failed, "+
+			"COUNT(*) FILTER (WHERE push_tickets.status = 'ok' AND push_tickets.receipt_status IS NULL) AS pending").
+		Joins("LEFT JOIN device_tokens ON device_tokens.token = push_tickets.token").
+		Where("push_tickets.created_at >= ?", since).
+		Group("COALESCE(device_tokens.platform, 'unknown')").
+		Order("platform").
+		Scan(&counts).Error
+	return counts, err
+}
+
+// PushErrorCount is how often one Expo error code came back on a ticket or a receipt.
+type PushErrorCount struct {
+	Code  string
+	Count int64
+}
+
+// CountPushErrors counts the ticket and receipt error codes of tickets created since.
+func (r *NotificationRepository) CountPushErrors(ctx context.Context, since time.Time) ([]PushErrorCount, error) {
+	var counts []PushErrorCount
+	err := r.db.WithContext(ctx).
+		Raw(`SELECT code, COUNT(*) AS count FROM (
+			SELECT COALESCE(error_code, 'unknown') AS code FROM push_tickets
+			WHERE created_at >= ? AND status = 'error'
+			UNION ALL
+			SELECT COALESCE(receipt_error_code, 'unknown') AS code FROM push_tickets
+			WHERE created_at >= ? AND receipt_status = 'error'
+		) errors GROUP BY code ORDER BY count DESC, code`, since, since).
+		Scan(&counts).Error
+	return counts, err
+}
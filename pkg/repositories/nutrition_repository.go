@@ -5,6 +5,7 @@ import (
 	"context"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type NutritionRepository struct {
@@ -100,6 +101,26 @@ func (r *NutritionRepository) GetByExternalID(ctx context.Context, source, exter
 	return &item, nil
 }
 
+// UpsertSystemFoodItems inserts or refreshes seeded system foods keyed by (source, external_id),
+// writing batchSize rows per statement. IDs are populated on every row, inserted or updated.
+func (r *NutritionRepository) UpsertSystemFoodItems(ctx context.Context, items []models.FoodItem, batchSize int) error {
+	if len(items) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:     []clause.Column{{Name: "source"}, {Name: "external_id"}},
+			TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "is_system AND external_id IS NOT NULL"}}},
+			DoUpdates: clause.AssignmentColumns([]string{
+				"name", "brand", "serving_size", "serving_size_grams",
+				"calories", "protein_grams", "carbs_grams", "fat_grams",
+				"fiber_grams", "sugar_grams", "sodium_mg",
+				"barcode", "image_url", "is_active", "updated_at",
+			}),
+		}).
+		CreateInBatches(&items, batchSize).Error
+}
+
 // --- Food Logs ---
 
 func (r *NutritionRepository) CreateFoodLog(ctx context.Context, entry *models.FoodLogEntry) error {
@@ -124,6 +145,21 @@ func (r *NutritionRepository) ListFoodLogs(ctx context.Context, clientID uint, d
 	return entries, err
 }
 
+// ListFoodLogsForDataExport returns every food log for the given client profiles
+func (r *NutritionRepository) ListFoodLogsForDataExport(ctx context.Context, clientIDs []uint) ([]models.FoodLogEntry, error) {
+	if len(clientIDs) == 0 {
+		return []models.FoodLogEntry{}, nil
+	}
+
+	var entries []models.FoodLogEntry
+	err := r.db.WithContext(ctx).
+		Preload("FoodItem").
+		Where("client_id IN ?", clientIDs).
+		Order("logged_date ASC, created_at ASC").
+		Find(&entries).Error
+	return entries, err
+}
+
 // DailySummary holds aggregated macros for a single day
 type DailySummary struct {
 	Calories     int     `json:"calories"`
@@ -161,6 +197,36 @@ func (r *NutritionRepository) GetDailySummary(ctx context.Context, clientID uint
 	return &summary, nil
 }
 
+// DailyTotal is one logged day's combined food log and quick macro totals.
+type DailyTotal struct {
+	Date string
+	DailySummary
+}
+
+// ListDailyTotals sums food logs and quick macros per logged day between startDate and endDate
+// (YYYY-MM-DD, inclusive), oldest first. Days with nothing logged are left out.
+func (r *NutritionRepository) ListDailyTotals(ctx context.Context, clientID uint, startDate, endDate string) ([]DailyTotal, error) {
+	var totals []DailyTotal
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT to_char(logged_date, 'YYYY-MM-DD') AS date,
+			COALESCE(SUM(calories), 0) AS calories,
+			COALESCE(SUM(protein_grams), 0) AS protein_grams,
+			COALESCE(SUM(carbs_grams), 0) AS carbs_grams,
+			COALESCE(SUM(fat_grams), 0) AS fat_grams
+		FROM (
+			SELECT logged_date, calories, protein_grams, carbs_grams, fat_grams
+			FROM food_log_entries WHERE client_id = ? AND logged_date >= ? AND logged_date <= ?
+			UNION ALL
+			SELECT logged_date, calories, protein_grams, carbs_grams, fat_grams
+			FROM quick_macro_entries WHERE client_id = ? AND logged_date >= ? AND logged_date <= ?
+		) logged
+		GROUP BY logged_date
+		ORDER BY logged_date ASC`,
+		clientID, startDate, endDate, clientID, startDate, endDate,
+	).Scan(&totals).Error
+	return totals, err
+}
+
 // --- Quick Macros ---
 
 func (r *NutritionRepository) CreateQuickMacro(ctx context.Context, entry *models.QuickMacroEntry) error {
@@ -183,3 +249,17 @@ func (r *NutritionRepository) ListQuickMacros(ctx context.Context, clientID uint
 		Find(&entries).Error
 	return entries, err
 }
+
+// ListQuickMacrosForDataExport returns every quick macro entry for the given client profiles
+func (r *NutritionRepository) ListQuickMacrosForDataExport(ctx context.Context, clientIDs []uint) ([]models.QuickMacroEntry, error) {
+	if len(clientIDs) == 0 {
+		return []models.QuickMacroEntry{}, nil
+	}
+
+	var entries []models.QuickMacroEntry
+	err := r.db.WithContext(ctx).
+		Where("client_id IN ?", clientIDs).
+		Order("logged_date ASC, created_at ASC").
+		Find(&entries).Error
+	return entries, err
+}
@@ -31,6 +31,30 @@ func (r *OutboxRepository) EnqueueTx(ctx context.Context, tx *gorm.DB, event *mo
 	return r.enqueueWithDB(db, event)
 }
 
+// outboxInsertBatchSize caps the rows per INSERT statement in EnqueueManyTx.
+const outboxInsertBatchSize = 100
+
+// EnqueueManyTx inserts outbox events inside an existing transaction, outboxInsertBatchSize rows per
+// statement. Rows whose idempotency key already exists are skipped, like Enqueue, without aborting
+// the transaction.
+func (r *OutboxRepository) EnqueueManyTx(ctx context.Context, tx *gorm.DB, events []*models.OutboxEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	now := time.Now().UTC()
+	for _, event := range events {
+		if event.Status == "" {
+			event.Status = models.OutboxStatusPending
+		}
+		if event.AvailableAt.IsZero() {
+			event.AvailableAt = now
+		}
+	}
+	return tx.WithContext(ctx).
+		Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "idempotency_key"}}, DoNothing: true}).
+		CreateInBatches(events, outboxInsertBatchSize).Error
+}
+
 func (r *OutboxRepository) enqueueWithDB(db *gorm.DB, event *models.OutboxEvent) error {
 	now := time.Now().UTC()
 	if event.Status == "" {
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type ProgressRepository struct {
@@ -88,3 +89,219 @@ func (r *ProgressRepository) ListPhotos(ctx context.Context, clientID uint, phot
 func (r *ProgressRepository) DeletePhoto(ctx context.Context, id uint) error {
 	return r.db.WithContext(ctx).Delete(&models.ProgressPhoto{}, id).Error
 }
+
+// --- Goals ---
+
+func (r *ProgressRepository) CreateGoal(ctx context.Context, goal *models.ClientGoal) error {
+	return r.db.WithContext(ctx).Create(goal).Error
+}
+
+func (r *ProgressRepository) GetGoal(ctx context.Context, id uint) (*models.ClientGoal, error) {
+	var goal models.ClientGoal
+	err := r.db.WithContext(ctx).First(&goal, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &goal, nil
+}
+
+// ListGoals returns goals for the given client profiles, newest first. An empty status returns every status.
+func (r *ProgressRepository) ListGoals(ctx context.Context, clientProfileIDs []uint, status string) ([]models.ClientGoal, error) {
+	goals := []models.ClientGoal{}
+	if len(clientProfileIDs) == 0 {
+		return goals, nil
+	}
+
+	query := r.db.WithContext(ctx).
+		Where("client_profile_id IN ?", clientProfileIDs)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	err := query.Order("created_at DESC, id DESC").Find(&goals).Error
+	return goals, err
+}
+
+func (r *ProgressRepository) UpdateGoal(ctx context.Context, goal *models.ClientGoal) error {
+	return r.db.WithContext(ctx).Save(goal).Error
+}
+
+// DeleteGoal removes a goal and its progress entries.
+func (r *ProgressRepository) DeleteGoal(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("goal_id = ?", id).Delete(&models.ClientGoalProgress{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.ClientGoal{}, id).Error
+	})
+}
+
+func (r *ProgressRepository) CreateGoalProgress(ctx context.Context, entry *models.ClientGoalProgress) error {
+	return r.db.WithContext(ctx).Create(entry).Error
+}
+
+// ListGoalProgress returns a goal's progress entries, most recent first.
+func (r *ProgressRepository) ListGoalProgress(ctx context.Context, goalID uint, limit, offset int) ([]models.ClientGoalProgress, int64, error) {
+	var entries []models.ClientGoalProgress
+	var total int64
+
+	query := r.db.WithContext(ctx).
+		Model(&models.ClientGoalProgress{}).
+		Where("goal_id = ?", goalID)
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.
+		Order("recorded_on DESC, id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&entries).Error
+	return entries, total, err
+}
+
+// LatestGoalProgress returns the most recent progress entry for each goal that has one, keyed by goal ID.
+func (r *ProgressRepository) LatestGoalProgress(ctx context.Context, goalIDs []uint) (map[uint]models.ClientGoalProgress, error) {
+	latest := make(map[uint]models.ClientGoalProgress, len(goalIDs))
+	if len(goalIDs) == 0 {
+		return latest, nil
+	}
+
+	var entries []models.ClientGoalProgress
+	err := r.db.WithContext(ctx).
+		Raw(`SELECT DISTINCT ON (goal_id) *
+			FROM client_goal_progress
+			WHERE goal_id IN ?
+			ORDER BY goal_id, recorded_on DESC, id DESC`, goalIDs).
+		Scan(&entries).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		latest[entry.GoalID] = entry
+	}
+	return latest, nil
+}
+
+// MetricChange summarizes one body metric over a period: its first and last readings and range.
+type MetricChange struct {
+	MetricType string
+	Unit       *string // unit of the latest reading
+	Readings   int
+	First      float64
+	Last       float64
+	Min        float64
+	Max        float64
+}
+
+// SummarizeMetrics aggregates the client's body metrics recorded in [start, end) per metric type,
+// ordered by type.
+func (r *ProgressRepository) SummarizeMetrics(ctx context.Context, clientID uint, start, end time.Time) ([]MetricChange, error) {
+	var changes []MetricChange
+	err := r.db.WithContext(ctx).
+		Model(&models.BodyMetric{}).
+		Select("metric_type, "+
+			"(array_agg(unit ORDER BY recorded_at DESC, id DESC))[1] AS unit, "+
+			"COUNT(*) AS readings, "+
+			"(array_agg(value ORDER BY recorded_at ASC, id ASC))[1] AS first, "+
+			"(array_agg(value ORDER BY recorded_at DESC, id DESC))[1] AS last, "+
+			"MIN(value) AS min, MAX(value) AS max").
+		Where("client_id = ? AND recorded_at >= ? AND recorded_at < ?", clientID, start, end).
+		Group("metric_type").
+		Order("metric_type ASC").
+		Scan(&changes).Error
+	return changes, err
+}
+
+// --- Streaks ---
+
+// GetStreak returns the user's stored streak, or gorm.ErrRecordNotFound before their first completed workout.
+func (r *ProgressRepository) GetStreak(ctx context.Context, userID uint) (*models.ClientStreak, error) {
+	var streak models.ClientStreak
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).First(&streak).Error
+	if err != nil {
+		return nil, err
+	}
+	return &streak, nil
+}
+
+// UpsertStreak writes the user's recomputed streak over whatever was stored.
+func (r *ProgressRepository) UpsertStreak(ctx context.Context, streak *models.ClientStreak) error {
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{
+				"weekly_target", "current_weeks", "longest_weeks", "current_streak_end_week",
+				"total_completed", "timezone", "last_completed_at", "updated_at",
+			}),
+		}).
+		Create(streak).Error
+}
+
+// ListMilestones returns the milestones the user has reached, oldest first.
+func (r *ProgressRepository) ListMilestones(ctx context.Context, userID uint) ([]models.ClientMilestone, error) {
+	var milestones []models.ClientMilestone
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("reached_at ASC, id ASC").
+		Find(&milestones).Error
+	return milestones, err
+}
+
+// CreateMilestone records a reached milestone. It reports false when the user already had it.
+func (r *ProgressRepository) CreateMilestone(ctx context.Context, milestone *models.ClientMilestone) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(milestone)
+	return result.RowsAffected > 0, result.Error
+}
+
+// --- Activity Samples ---
+
+// ActivityDailyTotal is the sum of one activity type's samples on one local day.
+type ActivityDailyTotal struct {
+	Date  string // YYYY-MM-DD in the requested timezone
+	Type  string
+	Total float64
+}
+
+// CreateActivitySamples inserts the samples, silently skipping any already stored for the same
+// client, type, start time and source. It returns how many rows were new.
+func (r *ProgressRepository) CreateActivitySamples(ctx context.Context, samples []models.ActivitySample) (int64, error) {
+	if len(samples) == 0 {
+		return 0, nil
+	}
+	result := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&samples)
+	return result.RowsAffected, result.Error
+}
+
+// ListActivitySamplesBetween returns the client's samples whose time span intersects [start, end).
+func (r *ProgressRepository) ListActivitySamplesBetween(ctx context.Context, clientProfileID uint, start, end time.Time) ([]models.ActivitySample, error) {
+	var samples []models.ActivitySample
+	err := r.db.WithContext(ctx).
+		Where("client_profile_id = ? AND start_time < ? AND end_time > ?", clientProfileID, end, start).
+		Order("start_time ASC").
+		Find(&samples).Error
+	return samples, err
+}
+
+// ListActivityDailyTotals sums the client's samples per type and per local day in tz, for samples
+// starting in [start, end). Days without samples are omitted.
+func (r *ProgressRepository) ListActivityDailyTotals(ctx context.Context, clientProfileID uint, tz string, start, end time.Time) ([]ActivityDailyTotal, error) {
+	var totals []ActivityDailyTotal
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT to_char(start_time AT TIME ZONE ?, 'YYYY-MM-DD') AS date,
+			type,
+			SUM(value) AS total
+		FROM activity_samples
+		WHERE client_profile_id = ? AND start_time >= ? AND start_time < ?
+		GROUP BY 1, type
+		ORDER BY 1 ASC, type ASC`,
+		tz, clientProfileID, start, end,
+	).Scan(&totals).Error
+	return totals, err
+}
@@ -0,0 +1,44 @@
+package repositories
+
+import (
+	"chalk-api/pkg/models"
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type RequestEventRepository struct {
+	db *gorm.DB
+}
+
+func NewRequestEventRepository(db *gorm.DB) *RequestEventRepository {
+	return &RequestEventRepository{db: db}
+}
+
+// CreateBatch inserts a flushed batch of request events in one statement per 500 rows.
+func (r *RequestEventRepository) CreateBatch(ctx context.Context, events []models.RequestEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).CreateInBatches(events, 500).Error
+}
+
+// ListByUser returns the user's most recent request events, newest first.
+func (r *RequestEventRepository) ListByUser(ctx context.Context, userID uint, limit int) ([]models.RequestEvent, error) {
+	var events []models.RequestEvent
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("created_at DESC, id DESC").
+		Limit(limit).
+		Find(&events).Error
+	return events, err
+}
+
+// DeleteOlderThan removes request events created before cutoff.
+func (r *RequestEventRepository) DeleteOlderThan(ctx context.Context, cutoff time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Where("created_at < ?", cutoff).
+		Delete(&models.RequestEvent{})
+	return result.RowsAffected, result.Error
+}
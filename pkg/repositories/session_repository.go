@@ -1,11 +1,14 @@
 package repositories
 
 import (
+	"chalk-api/pkg/db"
 	"chalk-api/pkg/models"
 	"context"
+	"errors"
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type SessionRepository struct {
@@ -16,6 +19,9 @@ func NewSessionRepository(db *gorm.DB) *SessionRepository {
 	return &SessionRepository{db: db}
 }
 
+// slotHoldingStatuses are the session statuses that occupy the coach's time in conflict checks.
+var slotHoldingStatuses = []string{"scheduled", models.SessionStatusPendingConfirmation}
+
 // --- Availability ---
 
 // SetAvailability replaces all recurring slots for a coach in a transaction
@@ -33,7 +39,7 @@ func (r *SessionRepository) SetAvailability(ctx context.Context, coachID uint, s
 
 func (r *SessionRepository) GetAvailability(ctx context.Context, coachID uint) ([]models.CoachAvailability, error) {
 	var slots []models.CoachAvailability
-	err := r.db.WithContext(ctx).
+	err := db.UsePrimary(r.db.WithContext(ctx)).
 		Where("coach_id = ? AND is_active = ?", coachID, true).
 		Order("day_of_week ASC, start_time ASC").
 		Find(&slots).Error
@@ -48,6 +54,41 @@ func (r *SessionRepository) DeleteAvailabilitySlot(ctx context.Context, id uint)
 	return r.db.WithContext(ctx).Delete(&models.CoachAvailability{}, id).Error
 }
 
+// --- Availability Presets ---
+
+// ErrAvailabilityPresetExists is returned by CreateAvailabilityPreset when the coach already has a preset with that name
+var ErrAvailabilityPresetExists = errors.New("availability preset name already in use")
+
+func (r *SessionRepository) CreateAvailabilityPreset(ctx context.Context, preset *models.AvailabilityPreset) error {
+	err := r.db.WithContext(ctx).Create(preset).Error
+	if err != nil && isUniqueViolation(err) {
+		return ErrAvailabilityPresetExists
+	}
+	return err
+}
+
+func (r *SessionRepository) ListAvailabilityPresets(ctx context.Context, coachID uint) ([]models.AvailabilityPreset, error) {
+	var presets []models.AvailabilityPreset
+	err := db.UsePrimary(r.db.WithContext(ctx)).
+		Where("coach_id = ?", coachID).
+		Order("name ASC").
+		Find(&presets).Error
+	return presets, err
+}
+
+func (r *SessionRepository) GetAvailabilityPresetByID(ctx context.Context, id uint) (*models.AvailabilityPreset, error) {
+	var preset models.AvailabilityPreset
+	err := r.db.WithContext(ctx).First(&preset, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &preset, nil
+}
+
+func (r *SessionRepository) DeleteAvailabilityPreset(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&models.AvailabilityPreset{}, id).Error
+}
+
 // --- Overrides ---
 
 func (r *SessionRepository) CreateOverride(ctx context.Context, override *models.CoachAvailabilityOverride) error {
@@ -79,18 +120,53 @@ func (r *SessionRepository) GetOverrideByID(ctx context.Context, id uint) (*mode
 // --- Session Types ---
 
 func (r *SessionRepository) CreateSessionType(ctx context.Context, st *models.SessionType) error {
-	return r.db.WithContext(ctx).Create(st).Error
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(st).Error; err != nil {
+			return err
+		}
+		// gorm skips zero values for columns with a default, so an explicit false has to be written separately.
+		if !st.BookableByClient {
+			return tx.Model(st).Update("bookable_by_client", false).Error
+		}
+		return nil
+	})
 }
 
 func (r *SessionRepository) ListSessionTypes(ctx context.Context, coachID uint) ([]models.SessionType, error) {
 	var types []models.SessionType
 	err := r.db.WithContext(ctx).
 		Where("coach_id = ? AND is_active = ?", coachID, true).
-		Order("name ASC").
+		Order("display_order ASC, name ASC").
 		Find(&types).Error
 	return types, err
 }
 
+// ListClientBookableSessionTypes returns the active types a client may book themselves, in booking UI order.
+func (r *SessionRepository) ListClientBookableSessionTypes(ctx context.Context, coachID uint) ([]models.SessionType, error) {
+	var types []models.SessionType
+	err := r.db.WithContext(ctx).
+		Where("coach_id = ? AND is_active = ? AND bookable_by_client = ?", coachID, true, true).
+		Order("display_order ASC, name ASC").
+		Find(&types).Error
+	return types, err
+}
+
+// ReorderSessionTypes sets display_order to each ID's position in orderedIDs.
+// IDs that don't belong to the coach are ignored.
+func (r *SessionRepository) ReorderSessionTypes(ctx context.Context, coachID uint, orderedIDs []uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for position, id := range orderedIDs {
+			err := tx.Model(&models.SessionType{}).
+				Where("id = ? AND coach_id = ?", id, coachID).
+				Updates(map[string]any{"display_order": position, "updated_at": time.Now().UTC()}).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
 func (r *SessionRepository) GetSessionTypeByID(ctx context.Context, id uint) (*models.SessionType, error) {
 	var sessionType models.SessionType
 	err := r.db.WithContext(ctx).First(&sessionType, id).Error
@@ -119,10 +195,12 @@ func (r *SessionRepository) CreateSession(ctx context.Context, session *models.S
 
 func (r *SessionRepository) GetSession(ctx context.Context, id uint) (*models.Session, error) {
 	var session models.Session
-	err := r.db.WithContext(ctx).
+	err := db.UsePrimary(r.db.WithContext(ctx)).
 		Preload("Coach.User.Profile").
 		Preload("Client.User.Profile").
 		Preload("SessionType").
+		Preload("Participants", func(tx *gorm.DB) *gorm.DB { return tx.Order("id ASC") }).
+		Preload("Participants.Client.User.Profile").
 		First(&session, id).Error
 	if err != nil {
 		return nil, err
@@ -131,7 +209,37 @@ func (r *SessionRepository) GetSession(ctx context.Context, id uint) (*models.Se
 }
 
 // ListSessions returns sessions for a coach or client within a date range
-func (r *SessionRepository) ListSessions(ctx context.Context, coachID, clientID uint, startDate, endDate time.Time) ([]models.Session, error) {
+// SessionStatusFilter narrows a session list by status. Statuses, when set, is the exact set to
+// return and overrides ExcludeCancelled; the zero value returns every session.
+type SessionStatusFilter struct {
+	Statuses         []string
+	ExcludeCancelled bool
+}
+
+// Matches reports whether a session with status passes the filter.
+func (f SessionStatusFilter) Matches(status string) bool {
+	if len(f.Statuses) > 0 {
+		for _, s := range f.Statuses {
+			if s == status {
+				return true
+			}
+		}
+		return false
+	}
+	return !f.ExcludeCancelled || status != "cancelled"
+}
+
+func (f SessionStatusFilter) apply(query *gorm.DB) *gorm.DB {
+	if len(f.Statuses) > 0 {
+		return query.Where("sessions.status IN ?", f.Statuses)
+	}
+	if f.ExcludeCancelled {
+		return query.Where("sessions.status <> ?", "cancelled")
+	}
+	return query
+}
+
+func (r *SessionRepository) ListSessions(ctx context.Context, coachID, clientID uint, startDate, endDate time.Time, filter SessionStatusFilter) ([]models.Session, error) {
 	var sessions []models.Session
 
 	query := r.db.WithContext(ctx).
@@ -139,6 +247,7 @@ func (r *SessionRepository) ListSessions(ctx context.Context, coachID, clientID
 		Preload("Client.User.Profile").
 		Preload("SessionType").
 		Where("scheduled_at >= ? AND scheduled_at <= ?", startDate, endDate)
+	query = filter.apply(query)
 
 	if coachID > 0 {
 		query = query.Where("coach_id = ?", coachID)
@@ -151,17 +260,23 @@ func (r *SessionRepository) ListSessions(ctx context.Context, coachID, clientID
 	return sessions, err
 }
 
-func (r *SessionRepository) ListSessionsByClients(ctx context.Context, clientIDs []uint, startDate, endDate time.Time) ([]models.Session, error) {
+func (r *SessionRepository) ListSessionsByClients(ctx context.Context, clientIDs []uint, startDate, endDate time.Time, filter SessionStatusFilter) ([]models.Session, error) {
 	if len(clientIDs) == 0 {
 		return []models.Session{}, nil
 	}
 
+	// Includes group sessions the clients joined but did not create.
 	var sessions []models.Session
-	err := r.db.WithContext(ctx).
+	query := r.db.WithContext(ctx).
 		Preload("Coach.User.Profile").
 		Preload("Client.User.Profile").
 		Preload("SessionType").
-		Where("client_id IN ? AND scheduled_at >= ? AND scheduled_at <= ?", clientIDs, startDate, endDate).
+		Where(
+			"(client_id IN ? OR id IN (SELECT session_id FROM session_participants WHERE client_id IN ? AND status = ?))",
+			clientIDs, clientIDs, models.SessionParticipantStatusBooked,
+		).
+		Where("scheduled_at >= ? AND scheduled_at <= ?", startDate, endDate)
+	err := filter.apply(query).
 		Order("scheduled_at ASC").
 		Find(&sessions).Error
 	return sessions, err
@@ -182,17 +297,133 @@ func (r *SessionRepository) CompleteSession(ctx context.Context, id uint) error
 		}).Error
 }
 
+// CancelSession cancels the session and every participant still booked into it
 func (r *SessionRepository) CancelSession(ctx context.Context, id uint, cancelledBy, reason string) error {
 	now := time.Now()
-	return r.db.WithContext(ctx).
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.
+			Model(&models.Session{}).
+			Where("id = ?", id).
+			Updates(map[string]interface{}{
+				"status":              "cancelled",
+				"cancelled_at":        now,
+				"cancelled_by":        cancelledBy,
+				"cancellation_reason": reason,
+			}).Error; err != nil {
+			return err
+		}
+		return tx.
+			Model(&models.SessionParticipant{}).
+			Where("session_id = ? AND status = ?", id, models.SessionParticipantStatusBooked).
+			Updates(map[string]interface{}{
+				"status":              models.SessionParticipantStatusCancelled,
+				"cancelled_at":        now,
+				"cancellation_reason": reason,
+			}).Error
+	})
+}
+
+// SessionDetails are the descriptive fields of a session that can be edited after booking. A nil
+// field is left as is; an empty string clears it.
+type SessionDetails struct {
+	Location *string
+	Notes    *string
+}
+
+// ListUpcomingSeriesSessions locks the scheduled sessions of a recurring series starting at or after
+// from, earliest first, with their booked participants.
+func (r *SessionRepository) ListUpcomingSeriesSessions(ctx context.Context, groupID string, from time.Time) ([]models.Session, error) {
+	var sessions []models.Session
+	err := r.db.WithContext(ctx).
+		Preload("Participants", "status = ?", models.SessionParticipantStatusBooked).
+		Preload("Participants.Client").
+		Where("recurrence_group_id = ? AND status = ? AND scheduled_at >= ?", groupID, "scheduled", from).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Order("scheduled_at ASC, id ASC").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+// UpdateSessionDetails applies details to every session in ids and returns how many were updated.
+func (r *SessionRepository) UpdateSessionDetails(ctx context.Context, ids []uint, details SessionDetails) (int64, error) {
+	updates := make(map[string]interface{}, 2)
+	if details.Location != nil {
+		updates["location"] = nullIfEmpty(*details.Location)
+	}
+	if details.Notes != nil {
+		updates["notes"] = nullIfEmpty(*details.Notes)
+	}
+	if len(ids) == 0 || len(updates) == 0 {
+		return 0, nil
+	}
+	result := r.db.WithContext(ctx).
 		Model(&models.Session{}).
-		Where("id = ?", id).
-		Updates(map[string]interface{}{
-			"status":              "cancelled",
-			"cancelled_at":        now,
-			"cancelled_by":        cancelledBy,
-			"cancellation_reason": reason,
-		}).Error
+		Where("id IN ?", ids).
+		Updates(updates)
+	return result.RowsAffected, result.Error
+}
+
+// nullIfEmpty maps "" to NULL for column updates.
+func nullIfEmpty(value string) interface{} {
+	if value == "" {
+		return nil
+	}
+	return value
+}
+
+// ConfirmSession moves a pending session to scheduled. It reports false when the session was no
+// longer pending, so a confirm racing a decline or the expiry worker loses cleanly.
+func (r *SessionRepository) ConfirmSession(ctx context.Context, id uint) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&models.Session{}).
+		Where("id = ? AND status = ?", id, models.SessionStatusPendingConfirmation).
+		Update("status", "scheduled")
+	return result.RowsAffected > 0, result.Error
+}
+
+// DeclinePendingSession cancels a pending session and its booked participants. It reports false
+// when the session was no longer pending.
+func (r *SessionRepository) DeclinePendingSession(ctx context.Context, id uint, declinedBy, reason string) (bool, error) {
+	declined := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.
+			Model(&models.Session{}).
+			Where("id = ? AND status = ?", id, models.SessionStatusPendingConfirmation).
+			Updates(map[string]interface{}{
+				"status":              "cancelled",
+				"cancelled_at":        now,
+				"cancelled_by":        declinedBy,
+				"cancellation_reason": reason,
+			})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		declined = true
+		return tx.
+			Model(&models.SessionParticipant{}).
+			Where("session_id = ? AND status = ?", id, models.SessionParticipantStatusBooked).
+			Updates(map[string]interface{}{
+				"status":              models.SessionParticipantStatusCancelled,
+				"cancelled_at":        now,
+				"cancellation_reason": reason,
+			}).Error
+	})
+	return declined, err
+}
+
+// ListExpiredPendingSessions returns pending sessions booked before createdBefore or already
+// due to start, oldest first.
+func (r *SessionRepository) ListExpiredPendingSessions(ctx context.Context, createdBefore, now time.Time, limit int) ([]models.Session, error) {
+	var sessions []models.Session
+	err := db.UsePrimary(r.db.WithContext(ctx)).
+		Preload("Participants", "status = ?", models.SessionParticipantStatusBooked).
+		Preload("Participants.Client").
+		Where("status = ? AND (created_at < ? OR scheduled_at <= ?)", models.SessionStatusPendingConfirmation, createdBefore, now).
+		Order("created_at ASC").
+		Limit(limit).
+		Find(&sessions).Error
+	return sessions, err
 }
 
 func (r *SessionRepository) MarkNoShow(ctx context.Context, id uint) error {
@@ -202,6 +433,65 @@ func (r *SessionRepository) MarkNoShow(ctx context.Context, id uint) error {
 		Update("status", "no_show").Error
 }
 
+// ListStaleSessions returns scheduled sessions without a check-in that ended before endedBefore and
+// whose coach hasn't been prompted about them yet, oldest id first after afterID. Coach is preloaded
+// for its stale session setting.
+func (r *SessionRepository) ListStaleSessions(ctx context.Context, endedBefore time.Time, afterID uint, limit int) ([]models.Session, error) {
+	var sessions []models.Session
+	err := db.UsePrimary(r.db.WithContext(ctx)).
+		Preload("Coach").
+		Where("status = ? AND checked_in_at IS NULL AND stale_prompted_at IS NULL", "scheduled").
+		Where("scheduled_at + duration_minutes * interval '1 minute' < ?", endedBefore).
+		Where("id > ?", afterID).
+		Order("id ASC").
+		Limit(limit).
+		Find(&sessions).Error
+	return sessions, err
+}
+
+// ResolveStaleSession moves a session that is still scheduled and has no check-in to status
+// ("completed" or "no_show"). It reports false when the session was resolved or checked in meanwhile.
+func (r *SessionRepository) ResolveStaleSession(ctx context.Context, id uint, status string) (bool, error) {
+	updates := map[string]interface{}{"status": status}
+	if status == "completed" {
+		updates["completed_at"] = time.Now()
+	}
+	result := r.db.WithContext(ctx).
+		Model(&models.Session{}).
+		Where("id = ? AND status = ? AND checked_in_at IS NULL", id, "scheduled").
+		Updates(updates)
+	return result.RowsAffected > 0, result.Error
+}
+
+// MarkStalePrompted records that the coach was asked to resolve the sessions, returning how many
+// weren't already marked.
+func (r *SessionRepository) MarkStalePrompted(ctx context.Context, ids []uint, at time.Time) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	result := r.db.WithContext(ctx).
+		Model(&models.Session{}).
+		Where("id IN ? AND stale_prompted_at IS NULL", ids).
+		Update("stale_prompted_at", at)
+	return result.RowsAffected, result.Error
+}
+
+// RecordCheckIn stores the check-in fields from session. Only the first check-in is kept;
+// it reports false when the session was already checked in.
+func (r *SessionRepository) RecordCheckIn(ctx context.Context, session *models.Session) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&models.Session{}).
+		Where("id = ? AND checked_in_at IS NULL", session.ID).
+		Updates(map[string]interface{}{
+			"checked_in_at":            session.CheckedInAt,
+			"check_in_latitude":        session.CheckInLatitude,
+			"check_in_longitude":       session.CheckInLongitude,
+			"check_in_distance_meters": session.CheckInDistanceMeters,
+			"check_in_outside_radius":  session.CheckInOutsideRadius,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
 func (r *SessionRepository) HasCoachConflict(
 	ctx context.Context,
 	coachID uint,
@@ -209,9 +499,9 @@ func (r *SessionRepository) HasCoachConflict(
 	endAt time.Time,
 	excludeSessionID *uint,
 ) (bool, error) {
-	query := r.db.WithContext(ctx).
+	query := db.UsePrimary(r.db.WithContext(ctx)).
 		Model(&models.Session{}).
-		Where("coach_id = ? AND status = ?", coachID, "scheduled").
+		Where("coach_id = ? AND status IN ?", coachID, slotHoldingStatuses).
 		Where("scheduled_at < ? AND (scheduled_at + (duration_minutes * INTERVAL '1 minute')) > ?", endAt, startAt)
 
 	if excludeSessionID != nil && *excludeSessionID > 0 {
@@ -222,5 +512,553 @@ func (r *SessionRepository) HasCoachConflict(
 	if err := query.Count(&count).Error; err != nil {
 		return false, err
 	}
+	if count > 0 {
+		return true, nil
+	}
+
+	// Blocked personal time conflicts the same way a session does.
+	err := db.UsePrimary(r.db.WithContext(ctx)).
+		Model(&models.CoachTimeBlock{}).
+		Where("coach_id = ? AND start_at < ? AND end_at > ?", coachID, endAt, startAt).
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
 	return count > 0, nil
 }
+
+// ClientBusyTime is a session holding a client's time, with whichever coach.
+type ClientBusyTime struct {
+	SessionID uint
+	CoachID   uint
+	StartAt   time.Time
+	EndAt     time.Time
+}
+
+// HasClientConflict returns the earliest session overlapping [startAt, endAt) that the client user is
+// booked into under any of their client profiles, or nil when they are free. Group sessions count
+// while the client's participant row is booked.
+func (r *SessionRepository) HasClientConflict(
+	ctx context.Context,
+	clientUserID uint,
+	startAt time.Time,
+	endAt time.Time,
+	excludeSessionID *uint,
+) (*ClientBusyTime, error) {
+	var busy []ClientBusyTime
+	err := r.clientBusyQuery(ctx, clientUserID, startAt, endAt, excludeSessionID).
+		Limit(1).
+		Scan(&busy).Error
+	if err != nil || len(busy) == 0 {
+		return nil, err
+	}
+	return &busy[0], nil
+}
+
+// ListClientBusyTimes returns every session the client user is booked into that overlaps
+// [startAt, endAt), across all of their coaches, earliest first.
+func (r *SessionRepository) ListClientBusyTimes(ctx context.Context, clientUserID uint, startAt, endAt time.Time) ([]ClientBusyTime, error) {
+	var busy []ClientBusyTime
+	err := r.clientBusyQuery(ctx, clientUserID, startAt, endAt, nil).Scan(&busy).Error
+	return busy, err
+}
+
+func (r *SessionRepository) clientBusyQuery(ctx context.Context, clientUserID uint, startAt, endAt time.Time, excludeSessionID *uint) *gorm.DB {
+	query := db.UsePrimary(r.db.WithContext(ctx)).
+		Table("sessions").
+		Select("sessions.id AS session_id, sessions.coach_id, sessions.scheduled_at AS start_at, "+
+			"sessions.scheduled_at + (sessions.duration_minutes * INTERVAL '1 minute') AS end_at").
+		Where("sessions.status IN ?", slotHoldingStatuses).
+		Where("sessions.scheduled_at < ? AND (sessions.scheduled_at + (sessions.duration_minutes * INTERVAL '1 minute')) > ?", endAt, startAt).
+		// The owning client_id only counts for 1:1 sessions; in a group the creator may have left.
+		Where(`(EXISTS (
+			SELECT 1 FROM session_participants sp
+			JOIN client_profiles cp ON cp.id = sp.client_id
+			WHERE sp.session_id = sessions.id AND sp.status = ? AND cp.user_id = ?
+		) OR (sessions.max_participants <= 1 AND sessions.client_id IN (SELECT id FROM client_profiles WHERE user_id = ?)))`,
+			models.SessionParticipantStatusBooked, clientUserID, clientUserID).
+		Order("sessions.scheduled_at ASC")
+
+	if excludeSessionID != nil && *excludeSessionID > 0 {
+		query = query.Where("sessions.id <> ?", *excludeSessionID)
+	}
+	return query
+}
+
+// --- Time Blocks ---
+
+func (r *SessionRepository) CreateTimeBlock(ctx context.Context, block *models.CoachTimeBlock) error {
+	return r.db.WithContext(ctx).Create(block).Error
+}
+
+func (r *SessionRepository) GetTimeBlockByID(ctx context.Context, id uint) (*models.CoachTimeBlock, error) {
+	var block models.CoachTimeBlock
+	err := db.UsePrimary(r.db.WithContext(ctx)).First(&block, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &block, nil
+}
+
+// ListTimeBlocks returns a coach's blocks that overlap the given range
+func (r *SessionRepository) ListTimeBlocks(ctx context.Context, coachID uint, startAt, endAt time.Time) ([]models.CoachTimeBlock, error) {
+	var blocks []models.CoachTimeBlock
+	err := r.db.WithContext(ctx).
+		Where("coach_id = ? AND start_at <= ? AND end_at > ?", coachID, endAt, startAt).
+		Order("start_at ASC").
+		Find(&blocks).Error
+	return blocks, err
+}
+
+func (r *SessionRepository) DeleteTimeBlock(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&models.CoachTimeBlock{}, id).Error
+}
+
+// --- Participants ---
+
+// FindJoinableGroupSession returns the scheduled (or pending) group session of this type at exactly scheduledAt, locking
+// the row so concurrent joins serialize on capacity. Call it inside a transaction.
+func (r *SessionRepository) FindJoinableGroupSession(ctx context.Context, coachID, sessionTypeID uint, scheduledAt time.Time) (*models.Session, error) {
+	var session models.Session
+	err := r.db.WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("coach_id = ? AND session_type_id = ? AND scheduled_at = ?", coachID, sessionTypeID, scheduledAt).
+		Where("status IN ? AND max_participants > 1", slotHoldingStatuses).
+		Order("id ASC").
+		First(&session).Error
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// LockSession reloads the session and locks its row so participant changes serialize on it.
+// Call it inside a transaction.
+func (r *SessionRepository) LockSession(ctx context.Context, id uint) (*models.Session, error) {
+	var session models.Session
+	err := r.db.WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&session, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *SessionRepository) GetParticipant(ctx context.Context, sessionID, clientID uint) (*models.SessionParticipant, error) {
+	var participant models.SessionParticipant
+	err := r.db.WithContext(ctx).
+		Where("session_id = ? AND client_id = ?", sessionID, clientID).
+		First(&participant).Error
+	if err != nil {
+		return nil, err
+	}
+	return &participant, nil
+}
+
+// AddParticipant books a client into a session, reviving their row if they had left earlier
+func (r *SessionRepository) AddParticipant(ctx context.Context, sessionID, clientID uint) (*models.SessionParticipant, error) {
+	participant := &models.SessionParticipant{
+		SessionID: sessionID,
+		ClientID:  clientID,
+		Status:    models.SessionParticipantStatusBooked,
+	}
+	err := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "session_id"}, {Name: "client_id"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"status":              models.SessionParticipantStatusBooked,
+				"cancelled_at":        nil,
+				"cancellation_reason": nil,
+				"updated_at":          time.Now(),
+			}),
+		}).
+		Create(participant).Error
+	if err != nil {
+		return nil, err
+	}
+	return participant, nil
+}
+
+// RemoveParticipant cancels one participant's booking without touching the session. removed is
+// false when the client had no booked row left, e.g. a concurrent cancellation got there first.
+func (r *SessionRepository) RemoveParticipant(ctx context.Context, sessionID, clientID uint, reason string) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&models.SessionParticipant{}).
+		Where("session_id = ? AND client_id = ? AND status = ?", sessionID, clientID, models.SessionParticipantStatusBooked).
+		Updates(map[string]interface{}{
+			"status":              models.SessionParticipantStatusCancelled,
+			"cancelled_at":        time.Now(),
+			"cancellation_reason": reason,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// MarkLateCancelled flags the client's cancelled participant row as late, and the session itself
+// when the cancellation took the whole session down.
+func (r *SessionRepository) MarkLateCancelled(ctx context.Context, sessionID, clientID uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.
+			Model(&models.SessionParticipant{}).
+			Where("session_id = ? AND client_id = ? AND status = ?", sessionID, clientID, models.SessionParticipantStatusCancelled).
+			Update("late_cancelled", true).Error; err != nil {
+			return err
+		}
+		return tx.
+			Model(&models.Session{}).
+			Where("id = ? AND status = ?", sessionID, "cancelled").
+			Update("late_cancelled", true).Error
+	})
+}
+
+// CancelUpcomingForClient takes the client out of every scheduled or pending session with coachID after the given time.
+// Sessions the client shares with other participants lose only the client; the rest are cancelled outright.
+// It returns how many sessions the client was removed from.
+func (r *SessionRepository) CancelUpcomingForClient(ctx context.Context, coachID, clientID uint, after time.Time, cancelledBy, reason string) (int64, error) {
+	var affected int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var sessions []models.Session
+		err := tx.
+			Joins("JOIN session_participants p ON p.session_id = sessions.id AND p.client_id = ? AND p.status = ?",
+				clientID, models.SessionParticipantStatusBooked).
+			Where("sessions.coach_id = ? AND sessions.status IN ? AND sessions.scheduled_at > ?", coachID, slotHoldingStatuses, after).
+			Clauses(clause.Locking{Strength: "UPDATE", Table: clause.Table{Name: "sessions"}}).
+			Find(&sessions).Error
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		for _, session := range sessions {
+			if session.ParticipantCount > 1 {
+				if err := tx.Model(&models.SessionParticipant{}).
+					Where("session_id = ? AND client_id = ? AND status = ?", session.ID, clientID, models.SessionParticipantStatusBooked).
+					Updates(map[string]interface{}{
+						"status":              models.SessionParticipantStatusCancelled,
+						"cancelled_at":        now,
+						"cancellation_reason": reason,
+					}).Error; err != nil {
+					return err
+				}
+				if err := tx.Model(&models.Session{}).
+					Where("id = ?", session.ID).
+					Update("participant_count", gorm.Expr("participant_count - 1")).Error; err != nil {
+					return err
+				}
+			} else {
+				if err := tx.Model(&models.Session{}).
+					Where("id = ?", session.ID).
+					Updates(map[string]interface{}{
+						"status":              "cancelled",
+						"cancelled_at":        now,
+						"cancelled_by":        cancelledBy,
+						"cancellation_reason": reason,
+					}).Error; err != nil {
+					return err
+				}
+				if err := tx.Model(&models.SessionParticipant{}).
+					Where("session_id = ? AND status = ?", session.ID, models.SessionParticipantStatusBooked).
+					Updates(map[string]interface{}{
+						"status":              models.SessionParticipantStatusCancelled,
+						"cancelled_at":        now,
+						"cancellation_reason": reason,
+					}).Error; err != nil {
+					return err
+				}
+			}
+			affected++
+		}
+		return nil
+	})
+	return affected, err
+}
+
+// AdjustParticipantCount adds delta to a session's participant_count. The count never goes below
+// zero: a decrement that would is skipped.
+func (r *SessionRepository) AdjustParticipantCount(ctx context.Context, sessionID uint, delta int) error {
+	return r.db.WithContext(ctx).
+		Model(&models.Session{}).
+		Where("id = ? AND participant_count + ? >= 0", sessionID, delta).
+		Update("participant_count", gorm.Expr("participant_count + ?", delta)).Error
+}
+
+func (r *SessionRepository) MarkPaid(ctx context.Context, id uint, paidAt time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&models.Session{}).
+		Where("id = ?", id).
+		Update("paid_at", paidAt).Error
+}
+
+// --- Feedback ---
+
+// ErrSessionFeedbackExists is returned by CreateFeedback when the session already has feedback
+var ErrSessionFeedbackExists = errors.New("session already has feedback")
+
+func (r *SessionRepository) CreateFeedback(ctx context.Context, feedback *models.SessionFeedback) error {
+	err := r.db.WithContext(ctx).Create(feedback).Error
+	if err != nil && isUniqueViolation(err) {
+		return ErrSessionFeedbackExists
+	}
+	return err
+}
+
+// SessionFeedbackEntry is one row of a coach's feedback list. The client columns are NULL for
+// anonymous feedback so the identity never leaves the database.
+type SessionFeedbackEntry struct {
+	ID                 uint      `json:"id"`
+	SessionID          uint      `json:"session_id"`
+	SessionScheduledAt time.Time `json:"session_scheduled_at"`
+	Rating             int       `json:"rating"`
+	Comment            *string   `json:"comment"`
+	Anonymous          bool      `json:"anonymous"`
+	ClientID           *uint     `json:"client_id"`
+	FirstName          *string   `json:"first_name"`
+	LastName           *string   `json:"last_name"`
+	CreatedAt          time.Time `json:"created_at"`
+}
+
+// ListCoachFeedback returns the coach's session feedback, newest first
+func (r *SessionRepository) ListCoachFeedback(ctx context.Context, coachID uint, limit, offset int) ([]SessionFeedbackEntry, int64, error) {
+	query := r.db.WithContext(ctx).
+		Table("session_feedbacks").
+		Where("session_feedbacks.coach_id = ?", coachID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	entries := []SessionFeedbackEntry{}
+	err := query.
+		Select(`session_feedbacks.id,
+			session_feedbacks.session_id,
+			sessions.scheduled_at AS session_scheduled_at,
+			session_feedbacks.rating,
+			session_feedbacks.comment,
+			session_feedbacks.anonymous,
+			CASE WHEN session_feedbacks.anonymous THEN NULL ELSE session_feedbacks.client_id END AS client_id,
+			CASE WHEN session_feedbacks.anonymous THEN NULL ELSE COALESCE(profiles.first_name, '') END AS first_name,
+			CASE WHEN session_feedbacks.anonymous THEN NULL ELSE COALESCE(profiles.last_name, '') END AS last_name,
+			session_feedbacks.created_at`).
+		Joins("JOIN sessions ON sessions.id = session_feedbacks.session_id").
+		Joins("JOIN client_profiles ON client_profiles.id = session_feedbacks.client_id").
+		Joins("LEFT JOIN profiles ON profiles.user_id = client_profiles.user_id").
+		Order("session_feedbacks.created_at DESC, session_feedbacks.id DESC").
+		Limit(limit).Offset(offset).
+		Scan(&entries).Error
+	return entries, total, err
+}
+
+// --- Earnings ---
+
+// EarningsMonthCounts holds completed-session counts for one calendar month (UTC)
+type EarningsMonthCounts struct {
+	Month             string `json:"month"` // YYYY-MM
+	CompletedSessions int64  `json:"completed_sessions"`
+	PaidSessions      int64  `json:"paid_sessions"`
+	UnpaidSessions    int64  `json:"unpaid_sessions"`
+	UnpricedSessions  int64  `json:"unpriced_sessions"`
+}
+
+// LateCancelMonthCount holds late client cancellations for one calendar month (UTC)
+type LateCancelMonthCount struct {
+	Month             string `json:"month"`
+	LateCancellations int64  `json:"late_cancellations"`
+}
+
+// EarningsMonthAmount holds the gross amount for one month in one currency
+type EarningsMonthAmount struct {
+	Month    string  `json:"month"`
+	Currency string  `json:"currency"`
+	Amount   float64 `json:"amount"`
+}
+
+// EarningsClientTotal holds a client's revenue in one currency over a range
+type EarningsClientTotal struct {
+	ClientID     uint    `json:"client_id"`
+	FirstName    string  `json:"first_name"`
+	LastName     string  `json:"last_name"`
+	Currency     string  `json:"currency"`
+	Amount       float64 `json:"amount"`
+	SessionCount int64   `json:"session_count"`
+}
+
+const earningsMonthExpr = "to_char(date_trunc('month', sessions.scheduled_at AT TIME ZONE 'UTC'), 'YYYY-MM')"
+
+// GetEarningsMonthCounts counts completed sessions per month in [start, end).
+// Paid and unpaid only cover priced sessions; unpriced sessions are counted separately.
+func (r *SessionRepository) GetEarningsMonthCounts(ctx context.Context, coachID uint, start, end time.Time) ([]EarningsMonthCounts, error) {
+	var rows []EarningsMonthCounts
+	err := r.db.WithContext(ctx).
+		Model(&models.Session{}).
+		Select(earningsMonthExpr+" AS month, "+
+			"COUNT(*) AS completed_sessions, "+
+			"COUNT(*) FILTER (WHERE price IS NOT NULL AND paid_at IS NOT NULL) AS paid_sessions, "+
+			"COUNT(*) FILTER (WHERE price IS NOT NULL AND paid_at IS NULL) AS unpaid_sessions, "+
+			"COUNT(*) FILTER (WHERE price IS NULL) AS unpriced_sessions").
+		Where("coach_id = ? AND status = ? AND scheduled_at >= ? AND scheduled_at < ?", coachID, "completed", start, end).
+		Group("month").
+		Order("month ASC").
+		Scan(&rows).Error
+	return rows, err
+}
+
+// GetLateCancelMonthCounts counts participant bookings the client cancelled inside the coach's
+// cancellation window, per month of the session start, in [start, end)
+func (r *SessionRepository) GetLateCancelMonthCounts(ctx context.Context, coachID uint, start, end time.Time) ([]LateCancelMonthCount, error) {
+	var rows []LateCancelMonthCount
+	err := r.db.WithContext(ctx).
+		Table("session_participants").
+		Joins("JOIN sessions ON sessions.id = session_participants.session_id").
+		Select(earningsMonthExpr+" AS month, COUNT(*) AS late_cancellations").
+		Where("sessions.coach_id = ? AND sessions.scheduled_at >= ? AND sessions.scheduled_at < ?", coachID, start, end).
+		Where("session_participants.late_cancelled = ?", true).
+		Group("month").
+		Order("month ASC").
+		Scan(&rows).Error
+	return rows, err
+}
+
+// GetEarningsMonthAmounts sums priced completed sessions per month and currency in [start, end)
+func (r *SessionRepository) GetEarningsMonthAmounts(ctx context.Context, coachID uint, start, end time.Time) ([]EarningsMonthAmount, error) {
+	var rows []EarningsMonthAmount
+	err := r.db.WithContext(ctx).
+		Model(&models.Session{}).
+		Select(earningsMonthExpr+" AS month, price_currency AS currency, COALESCE(SUM(price), 0) AS amount").
+		Where("coach_id = ? AND status = ? AND scheduled_at >= ? AND scheduled_at < ?", coachID, "completed", start, end).
+		Where("price IS NOT NULL").
+		Group("month, price_currency").
+		Order("month ASC, price_currency ASC").
+		Scan(&rows).Error
+	return rows, err
+}
+
+// GetTopEarningClients ranks clients by revenue from priced completed sessions in [start, end)
+func (r *SessionRepository) GetTopEarningClients(ctx context.Context, coachID uint, start, end time.Time, limit int) ([]EarningsClientTotal, error) {
+	var rows []EarningsClientTotal
+	err := r.db.WithContext(ctx).
+		Model(&models.Session{}).
+		Select("sessions.client_id, COALESCE(profiles.first_name, '') AS first_name, COALESCE(profiles.last_name, '') AS last_name, "+
+			"sessions.price_currency AS currency, "+
+			"COALESCE(SUM(sessions.price), 0) AS amount, COUNT(*) AS session_count").
+		Joins("JOIN client_profiles ON client_profiles.id = sessions.client_id").
+		Joins("LEFT JOIN profiles ON profiles.user_id = client_profiles.user_id").
+		Where("sessions.coach_id = ? AND sessions.status = ? AND sessions.scheduled_at >= ? AND sessions.scheduled_at < ?", coachID, "completed", start, end).
+		Where("sessions.price IS NOT NULL").
+		Group("sessions.client_id, profiles.first_name, profiles.last_name, sessions.price_currency").
+		Order("amount DESC, sessions.client_id ASC").
+		Limit(limit).
+		Scan(&rows).Error
+	return rows, err
+}
+
+// --- Summary ---
+
+// SessionStatusTypeCount counts a coach's sessions of one session type in one status
+type SessionStatusTypeCount struct {
+	SessionTypeID   uint   `json:"session_type_id"`
+	SessionTypeName string `json:"session_type_name"`
+	Status          string `json:"status"`
+	Count           int64  `json:"count"`
+}
+
+// CountSessionsByStatusAndType groups the coach's sessions starting in [start, end) by session type and status
+func (r *SessionRepository) CountSessionsByStatusAndType(ctx context.Context, coachID uint, start, end time.Time) ([]SessionStatusTypeCount, error) {
+	var rows []SessionStatusTypeCount
+	err := r.db.WithContext(ctx).
+		Model(&models.Session{}).
+		Select("sessions.session_type_id, COALESCE(session_types.name, '') AS session_type_name, sessions.status, COUNT(*) AS count").
+		Joins("LEFT JOIN session_types ON session_types.id = sessions.session_type_id").
+		Where("sessions.coach_id = ? AND sessions.scheduled_at >= ? AND sessions.scheduled_at < ?", coachID, start, end).
+		Group("sessions.session_type_id, session_types.name, sessions.status").
+		Order("sessions.session_type_id ASC, sessions.status ASC").
+		Scan(&rows).Error
+	return rows, err
+}
+
+// CountLateCancellations counts participant bookings clients cancelled inside the coach's
+// cancellation window, for the coach's sessions starting in [start, end)
+func (r *SessionRepository) CountLateCancellations(ctx context.Context, coachID uint, start, end time.Time) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Table("session_participants").
+		Joins("JOIN sessions ON sessions.id = session_participants.session_id").
+		Where("sessions.coach_id = ? AND sessions.scheduled_at >= ? AND sessions.scheduled_at < ?", coachID, start, end).
+		Where("session_participants.late_cancelled = ?", true).
+		Count(&count).Error
+	return count, err
+}
+
+// --- Exports ---
+
+// SessionHistoryRow is one session in a client's session history export
+type SessionHistoryRow struct {
+	ScheduledAt     time.Time
+	DurationMinutes int
+	SessionType     string
+	Status          string
+	Notes           *string
+}
+
+// StreamClientSessions calls fn for each of the client's sessions with a coach, oldest first.
+// A zero start or end leaves that side of the range open.
+func (r *SessionRepository) StreamClientSessions(
+	ctx context.Context,
+	coachID uint,
+	clientID uint,
+	start time.Time,
+	end time.Time,
+	fn func(row SessionHistoryRow) error,
+) error {
+	query := r.db.WithContext(ctx).
+		Table("sessions").
+		Select("sessions.scheduled_at, sessions.duration_minutes, COALESCE(session_types.name, '') AS session_type, "+
+			"sessions.status, sessions.notes").
+		Joins("LEFT JOIN session_types ON session_types.id = sessions.session_type_id").
+		Where("sessions.coach_id = ? AND sessions.client_id = ?", coachID, clientID)
+
+	if !start.IsZero() {
+		query = query.Where("sessions.scheduled_at >= ?", start)
+	}
+	if !end.IsZero() {
+		query = query.Where("sessions.scheduled_at < ?", end)
+	}
+
+	rows, err := query.Order("sessions.scheduled_at ASC").Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row SessionHistoryRow
+		if err := r.db.ScanRows(rows, &row); err != nil {
+			return err
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// ListForDataExport returns every session the user took part in, as coach or as client
+func (r *SessionRepository) ListForDataExport(ctx context.Context, coachID uint, clientIDs []uint) ([]models.Session, error) {
+	if coachID == 0 && len(clientIDs) == 0 {
+		return []models.Session{}, nil
+	}
+
+	query := r.db.WithContext(ctx).Preload("SessionType")
+	switch {
+	case coachID > 0 && len(clientIDs) > 0:
+		query = query.Where("coach_id = ? OR client_id IN ?", coachID, clientIDs)
+	case coachID > 0:
+		query = query.Where("coach_id = ?", coachID)
+	default:
+		query = query.Where("client_id IN ?", clientIDs)
+	}
+
+	var sessions []models.Session
+	err := query.Order("scheduled_at ASC").Find(&sessions).Error
+	return sessions, err
+}
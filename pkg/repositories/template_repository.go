@@ -1,10 +1,13 @@
 package repositories
 
 import (
+	"chalk-api/pkg/db"
 	"chalk-api/pkg/models"
 	"context"
+	"errors"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type TemplateRepository struct {
@@ -22,7 +25,7 @@ func (r *TemplateRepository) Create(ctx context.Context, template *models.Workou
 
 func (r *TemplateRepository) GetByID(ctx context.Context, id uint) (*models.WorkoutTemplate, error) {
 	var template models.WorkoutTemplate
-	err := r.db.WithContext(ctx).
+	err := db.UsePrimary(r.db.WithContext(ctx)).
 		Preload("Exercises", func(db *gorm.DB) *gorm.DB {
 			return db.Order("order_index ASC")
 		}).
@@ -34,12 +37,30 @@ func (r *TemplateRepository) GetByID(ctx context.Context, id uint) (*models.Work
 	return &template, nil
 }
 
-func (r *TemplateRepository) ListByCoach(ctx context.Context, coachID uint, limit, offset int) ([]models.WorkoutTemplate, int64, error) {
+// TemplateListFilter narrows ListByCoach results
+type TemplateListFilter struct {
+	CategoryID *uint
+	// Uncategorized keeps only templates without a category; ignored when CategoryID is set
+	Uncategorized bool
+}
+
+// TemplateCategoryCount is the number of active templates in one category; CategoryID is nil for uncategorized.
+type TemplateCategoryCount struct {
+	CategoryID *uint
+	Count      int64
+}
+
+func (r *TemplateRepository) ListByCoach(ctx context.Context, coachID uint, filter TemplateListFilter, limit, offset int) ([]models.WorkoutTemplate, int64, error) {
 	var templates []models.WorkoutTemplate
 	var total int64
 
 	query := r.db.WithContext(ctx).
 		Where("coach_id = ? AND is_active = ?", coachID, true)
+	if filter.CategoryID != nil {
+		query = query.Where("category_id = ?", *filter.CategoryID)
+	} else if filter.Uncategorized {
+		query = query.Where("category_id IS NULL")
+	}
 
 	if err := query.Model(&models.WorkoutTemplate{}).Count(&total).Error; err != nil {
 		return nil, 0, err
@@ -53,8 +74,30 @@ func (r *TemplateRepository) ListByCoach(ctx context.Context, coachID uint, limi
 	return templates, total, err
 }
 
+// CountByCategory returns active template counts per category for the coach, including a nil-ID row
+// for uncategorized templates when there are any.
+func (r *TemplateRepository) CountByCategory(ctx context.Context, coachID uint) ([]TemplateCategoryCount, error) {
+	var counts []TemplateCategoryCount
+	err := r.db.WithContext(ctx).
+		Model(&models.WorkoutTemplate{}).
+		Select("category_id, COUNT(*) AS count").
+		Where("coach_id = ? AND is_active = ?", coachID, true).
+		Group("category_id").
+		Scan(&counts).Error
+	return counts, err
+}
+
+// Update saves the template's own columns guarded by its version (ErrVersionConflict when stale)
 func (r *TemplateRepository) Update(ctx context.Context, template *models.WorkoutTemplate) error {
-	return r.db.WithContext(ctx).Save(template).Error
+	return saveVersioned(r.db.WithContext(ctx), template, &template.Version)
+}
+
+// UpdateMetrics stores recomputed metrics and the estimated duration without touching other columns
+func (r *TemplateRepository) UpdateMetrics(ctx context.Context, id uint, estimatedMinutes *int, metrics *models.WorkoutMetrics) error {
+	return r.db.WithContext(ctx).
+		Model(&models.WorkoutTemplate{ID: id}).
+		Select("estimated_minutes", "metrics").
+		Updates(&models.WorkoutTemplate{EstimatedMinutes: estimatedMinutes, Metrics: metrics}).Error
 }
 
 func (r *TemplateRepository) Delete(ctx context.Context, id uint) error {
@@ -110,3 +153,140 @@ func (r *TemplateRepository) ReplaceExercises(ctx context.Context, templateID ui
 		return tx.Create(&exercises).Error
 	})
 }
+
+// --- Categories ---
+
+// ErrTemplateCategoryExists is returned when the coach already has a category with the name, ignoring case
+var ErrTemplateCategoryExists = errors.New("template category name already in use")
+
+func (r *TemplateRepository) CreateCategory(ctx context.Context, category *models.TemplateCategory) error {
+	err := r.db.WithContext(ctx).Create(category).Error
+	if err != nil && isUniqueViolation(err) {
+		return ErrTemplateCategoryExists
+	}
+	return err
+}
+
+func (r *TemplateRepository) GetCategory(ctx context.Context, id uint) (*models.TemplateCategory, error) {
+	var category models.TemplateCategory
+	err := db.UsePrimary(r.db.WithContext(ctx)).First(&category, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+// FindCategoryByName looks up one of the coach's categories by name, ignoring case.
+func (r *TemplateRepository) FindCategoryByName(ctx context.Context, coachID uint, name string) (*models.TemplateCategory, error) {
+	var category models.TemplateCategory
+	err := db.UsePrimary(r.db.WithContext(ctx)).
+		Where("coach_id = ? AND lower(name) = lower(?)", coachID, name).
+		First(&category).Error
+	if err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+// ListCategories returns the coach's categories ordered by name.
+func (r *TemplateRepository) ListCategories(ctx context.Context, coachID uint) ([]models.TemplateCategory, error) {
+	var categories []models.TemplateCategory
+	err := r.db.WithContext(ctx).
+		Where("coach_id = ?", coachID).
+		Order("lower(name) ASC, id ASC").
+		Find(&categories).Error
+	return categories, err
+}
+
+// RenameCategory renames a category and rewrites the deprecated category string on its templates
+// in one transaction.
+func (r *TemplateRepository) RenameCategory(ctx context.Context, id uint, name string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.TemplateCategory{}).
+			Where("id = ?", id).
+			Update("name", name).Error; err != nil {
+			if isUniqueViolation(err) {
+				return ErrTemplateCategoryExists
+			}
+			return err
+		}
+		return tx.Model(&models.WorkoutTemplate{}).
+			Where("category_id = ?", id).
+			UpdateColumn("category", name).Error
+	})
+}
+
+// DeleteCategory deletes a category and clears it from its templates; the templates are kept.
+func (r *TemplateRepository) DeleteCategory(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.WorkoutTemplate{}).
+			Where("category_id = ?", id).
+			UpdateColumns(map[string]any{"category_id": nil, "category": nil}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.TemplateCategory{}, id).Error
+	})
+}
+
+// --- Versions ---
+
+// CreateVersion stores version as the template's next version number and prunes all but the newest keep
+// versions. The template row is locked so concurrent edits can't claim the same number.
+func (r *TemplateRepository) CreateVersion(ctx context.Context, version *models.TemplateVersion, keep int) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var template models.WorkoutTemplate
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id").
+			First(&template, version.TemplateID).Error; err != nil {
+			return err
+		}
+
+		var latest int
+		if err := tx.Model(&models.TemplateVersion{}).
+			Where("template_id = ?", version.TemplateID).
+			Select("COALESCE(MAX(version), 0)").
+			Scan(&latest).Error; err != nil {
+			return err
+		}
+		version.Version = latest + 1
+		if err := tx.Create(version).Error; err != nil {
+			return err
+		}
+
+		if keep <= 0 {
+			return nil
+		}
+		return tx.Where("template_id = ? AND version <= ?", version.TemplateID, version.Version-keep).
+			Delete(&models.TemplateVersion{}).Error
+	})
+}
+
+func (r *TemplateRepository) CountVersions(ctx context.Context, templateID uint) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&models.TemplateVersion{}).
+		Where("template_id = ?", templateID).
+		Count(&count).Error
+	return count, err
+}
+
+// ListVersions returns the template's retained versions, newest first.
+func (r *TemplateRepository) ListVersions(ctx context.Context, templateID uint) ([]models.TemplateVersion, error) {
+	var versions []models.TemplateVersion
+	err := r.db.WithContext(ctx).
+		Where("template_id = ?", templateID).
+		Order("version DESC").
+		Find(&versions).Error
+	return versions, err
+}
+
+func (r *TemplateRepository) GetVersion(ctx context.Context, templateID uint, version int) (*models.TemplateVersion, error) {
+	var result models.TemplateVersion
+	err := r.db.WithContext(ctx).
+		Where("template_id = ? AND version = ?", templateID, version).
+		First(&result).Error
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
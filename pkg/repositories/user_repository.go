@@ -1,11 +1,14 @@
 package repositories
 
 import (
+	"chalk-api/pkg/db"
 	"chalk-api/pkg/models"
+	"chalk-api/pkg/units"
 	"context"
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type UserRepository struct {
@@ -29,7 +32,7 @@ func (r *UserRepository) Create(ctx context.Context, user *models.User, profile
 
 func (r *UserRepository) GetByID(ctx context.Context, id uint) (*models.User, error) {
 	var user models.User
-	err := r.db.WithContext(ctx).
+	err := db.UsePrimary(r.db.WithContext(ctx)).
 		Preload("Profile").
 		First(&user, id).Error
 	if err != nil {
@@ -58,6 +61,51 @@ func (r *UserRepository) UpdateProfile(ctx context.Context, profile *models.Prof
 	return r.db.WithContext(ctx).Save(profile).Error
 }
 
+// GetLocale returns the language the user reads notifications in; "en" when they have no profile.
+func (r *UserRepository) GetLocale(ctx context.Context, userID uint) (string, error) {
+	var locales []string
+	err := r.db.WithContext(ctx).
+		Model(&models.Profile{}).
+		Where("user_id = ?", userID).
+		Limit(1).
+		Pluck("locale", &locales).Error
+	if err != nil || len(locales) == 0 || locales[0] == "" {
+		return "en", err
+	}
+	return locales[0], nil
+}
+
+// GetTimezone returns the IANA zone from the user's profile, or "UTC" when they have no profile or
+// never set one.
+func (r *UserRepository) GetTimezone(ctx context.Context, userID uint) (string, error) {
+	var zones []string
+	err := r.db.WithContext(ctx).
+		Model(&models.Profile{}).
+		Where("user_id = ?", userID).
+		Limit(1).
+		Pluck("timezone", &zones).Error
+	if err != nil || len(zones) == 0 || zones[0] == "" {
+		return "UTC", err
+	}
+	return zones[0], nil
+}
+
+// GetUnitPreferences returns the units the user wants weights and distances shown in, falling back to
+// units.DefaultPreferences when they have no profile.
+func (r *UserRepository) GetUnitPreferences(ctx context.Context, userID uint) (units.Preferences, error) {
+	var prefs []units.Preferences
+	err := r.db.WithContext(ctx).
+		Model(&models.Profile{}).
+		Select("weight_unit AS weight, distance_unit AS distance").
+		Where("user_id = ?", userID).
+		Limit(1).
+		Scan(&prefs).Error
+	if err != nil || len(prefs) == 0 {
+		return units.DefaultPreferences, err
+	}
+	return prefs[0], nil
+}
+
 func (r *UserRepository) UpdateLastLogin(ctx context.Context, userID uint) error {
 	now := time.Now()
 	return r.db.WithContext(ctx).
@@ -97,8 +145,21 @@ func (r *UserRepository) RemoveOAuthProvider(ctx context.Context, id uint) error
 
 // --- Device Tokens ---
 
+// AddDeviceToken registers token, or takes over an existing row with the same token value. A
+// token registered again after deactivation is reactivated in place, so its history stays attached.
 func (r *UserRepository) AddDeviceToken(ctx context.Context, token *models.DeviceToken) error {
-	return r.db.WithContext(ctx).Create(token).Error
+	token.IsActive = true
+	token.DeactivatedAt = nil
+	token.DeactivationReason = nil
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "token"}},
+			DoUpdates: clause.AssignmentColumns([]string{
+				"user_id", "platform", "is_active", "device_name", "app_version", "os_version",
+				"deactivated_at", "deactivation_reason", "updated_at",
+			}),
+		}).
+		Create(token).Error
 }
 
 func (r *UserRepository) GetDeviceTokens(ctx context.Context, userID uint) ([]models.DeviceToken, error) {
@@ -109,9 +170,67 @@ func (r *UserRepository) GetDeviceTokens(ctx context.Context, userID uint) ([]mo
 	return tokens, err
 }
 
-func (r *UserRepository) DeactivateDeviceToken(ctx context.Context, token string) error {
+// DeactivateDeviceTokens marks the active tokens among tokens inactive with reason. Rows are kept.
+func (r *UserRepository) DeactivateDeviceTokens(ctx context.Context, tokens []string, reason string, at time.Time) (int64, error) {
+	if len(tokens) == 0 {
+		return 0, nil
+	}
+	result := r.db.WithContext(ctx).
+		Model(&models.DeviceToken{}).
+		Where("token IN ? AND is_active = ?", tokens, true).
+		Updates(map[string]interface{}{
+			"is_active":           false,
+			"deactivated_at":      at,
+			"deactivation_reason": reason,
+			"updated_at":          at,
+		})
+	return result.RowsAffected, result.Error
+}
+
+// DeactivateStaleDeviceTokens deactivates active tokens with no accepted push, and no registration,
+// since cutoff. A token that has never been pushed to counts from when it was registered.
+func (r *UserRepository) DeactivateStaleDeviceTokens(ctx context.Context, cutoff, at time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Model(&models.DeviceToken{}).
+		Where("is_active = ? AND COALESCE(last_used_at, created_at) < ? AND updated_at < ?", true, cutoff, cutoff).
+		Updates(map[string]interface{}{
+			"is_active":           false,
+			"deactivated_at":      at,
+			"deactivation_reason": models.DeviceTokenDeactivatedStale,
+			"updated_at":          at,
+		})
+	return result.RowsAffected, result.Error
+}
+
+// TouchDeviceTokens records at as the last successful push for tokens.
+func (r *UserRepository) TouchDeviceTokens(ctx context.Context, tokens []string, at time.Time) error {
+	if len(tokens) == 0 {
+		return nil
+	}
 	return r.db.WithContext(ctx).
 		Model(&models.DeviceToken{}).
-		Where("token = ?", token).
-		Update("is_active", false).Error
+		Where("token IN ?", tokens).
+		UpdateColumn("last_used_at", at).Error
+}
+
+// DeviceTokenCount is the number of tokens for one platform and app version.
+type DeviceTokenCount struct {
+	Platform   string
+	AppVersion *string
+	Active     int64
+	Inactive   int64
+}
+
+// CountDeviceTokens groups every device token by platform and app version.
+func (r *UserRepository) CountDeviceTokens(ctx context.Context) ([]DeviceTokenCount, error) {
+	var counts []DeviceTokenCount
+	err := r.db.WithContext(ctx).
+		Model(&models.DeviceToken{}).
+		Select("platform, app_version, " +
+			"COUNT(*) FILTER (WHERE is_active) AS active, " +
+			"COUNT(*) FILTER (WHERE NOT is_active) AS inactive").
+		Group("platform, app_version").
+		Order("platform, app_version").
+		Scan(&counts).Error
+	return counts, err
 }
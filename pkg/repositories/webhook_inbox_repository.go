@@ -0,0 +1,93 @@
+package repositories
+
+import (
+	"chalk-api/pkg/models"
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type WebhookInboxRepository struct {
+	db *gorm.DB
+}
+
+func NewWebhookInboxRepository(db *gorm.DB) *WebhookInboxRepository {
+	return &WebhookInboxRepository{db: db}
+}
+
+// Create stores a delivery unless one with the same provider and external event ID is already
+// there. It reports false for such a redelivery; entry is left unsaved in that case.
+func (r *WebhookInboxRepository) Create(ctx context.Context, entry *models.WebhookInboxEntry) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "provider"}, {Name: "external_event_id"}},
+			DoNothing: true,
+		}).
+		Create(entry)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *WebhookInboxRepository) GetByID(ctx context.Context, id uint) (*models.WebhookInboxEntry, error) {
+	var entry models.WebhookInboxEntry
+	if err := r.db.WithContext(ctx).First(&entry, id).Error; err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// List returns entries newest first, optionally narrowed to one provider and status.
+func (r *WebhookInboxRepository) List(ctx context.Context, provider, status string, limit, offset int) ([]models.WebhookInboxEntry, int64, error) {
+	var entries []models.WebhookInboxEntry
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&models.WebhookInboxEntry{})
+	if provider != "" {
+		query = query.Where("provider = ?", provider)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.
+		Order("received_at DESC, id DESC").
+		Limit(limit).Offset(offset).
+		Find(&entries).Error
+
+	return entries, total, err
+}
+
+// MarkResult records the outcome of a processing run on the provider's event: status, and
+// processErr's message when the run failed. Every call counts as an attempt.
+func (r *WebhookInboxRepository) MarkResult(ctx context.Context, provider, externalEventID, status string, processErr error) error {
+	now := time.Now().UTC()
+	updates := map[string]any{
+		"status":     status,
+		"attempts":   gorm.Expr("attempts + 1"),
+		"error":      nil,
+		"updated_at": now,
+	}
+	if processErr != nil {
+		updates["error"] = processErr.Error()
+	} else {
+		updates["processed_at"] = now
+	}
+	return r.db.WithContext(ctx).
+		Model(&models.WebhookInboxEntry{}).
+		Where("provider = ? AND external_event_id = ?", provider, externalEventID).
+		Updates(updates).Error
+}
+
+// DeleteProcessedBefore removes processed and ignored entries received before cutoff. Failed and
+// pending entries are kept until someone replays or looks at them.
+func (r *WebhookInboxRepository) DeleteProcessedBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Where("status IN ? AND received_at < ?", []string{models.WebhookInboxStatusProcessed, models.WebhookInboxStatusIgnored}, cutoff).
+		Delete(&models.WebhookInboxEntry{})
+	return result.RowsAffected, result.Error
+}
@@ -1,13 +1,19 @@
 package repositories
 
 import (
+	"chalk-api/pkg/db"
 	"chalk-api/pkg/models"
 	"context"
+	"errors"
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// ErrWorkoutDateTaken is returned by Create when the client already has an active workout on that date
+var ErrWorkoutDateTaken = errors.New("client already has a workout on this date")
+
 type WorkoutRepository struct {
 	db *gorm.DB
 }
@@ -18,12 +24,16 @@ func NewWorkoutRepository(db *gorm.DB) *WorkoutRepository {
 
 // Create creates a workout with all exercises in one transaction (deep copy from template)
 func (r *WorkoutRepository) Create(ctx context.Context, workout *models.Workout) error {
-	return r.db.WithContext(ctx).Create(workout).Error
+	err := r.db.WithContext(ctx).Create(workout).Error
+	if err != nil && isUniqueViolation(err) {
+		return ErrWorkoutDateTaken
+	}
+	return err
 }
 
 func (r *WorkoutRepository) GetByID(ctx context.Context, id uint) (*models.Workout, error) {
 	var workout models.Workout
-	err := r.db.WithContext(ctx).
+	err := db.UsePrimary(r.db.WithContext(ctx)).
 		Preload("Exercises", func(db *gorm.DB) *gorm.DB {
 			return db.Order("order_index ASC")
 		}).
@@ -49,6 +59,21 @@ func (r *WorkoutRepository) GetByClientAndDate(ctx context.Context, clientID uin
 			return db.Order("set_number ASC")
 		}).
 		Where("client_id = ? AND scheduled_date = ?", clientID, date).
+		// Prefer the primary workout over skipped ones and deliberate duplicates
+		Order("status = 'skipped' ASC, duplicate_allowed ASC, id ASC").
+		First(&workout).Error
+	if err != nil {
+		return nil, err
+	}
+	return &workout, nil
+}
+
+// FindActiveOnDate returns the client's non-skipped workout on a date, primary one first
+func (r *WorkoutRepository) FindActiveOnDate(ctx context.Context, clientID uint, date string) (*models.Workout, error) {
+	var workout models.Workout
+	err := db.UsePrimary(r.db.WithContext(ctx)).
+		Where("client_id = ? AND scheduled_date = ? AND status <> ?", clientID, date, "skipped").
+		Order("duplicate_allowed ASC, id ASC").
 		First(&workout).Error
 	if err != nil {
 		return nil, err
@@ -99,6 +124,46 @@ func (r *WorkoutRepository) ListByClients(ctx context.Context, clientIDs []uint,
 	return workouts, total, err
 }
 
+// ListScheduledBetween returns workouts scheduled on [startDate, endDate] (YYYY-MM-DD) for the given
+// clients, or for every client of coachID when clientIDs is nil. Exercises are not loaded.
+func (r *WorkoutRepository) ListScheduledBetween(ctx context.Context, coachID uint, clientIDs []uint, startDate, endDate string) ([]models.Workout, error) {
+	var workouts []models.Workout
+	query := r.db.WithContext(ctx).
+		Where("scheduled_date >= ? AND scheduled_date <= ?", startDate, endDate)
+	if coachID > 0 {
+		query = query.Where("coach_id = ?", coachID)
+	}
+	if clientIDs != nil {
+		if len(clientIDs) == 0 {
+			return []models.Workout{}, nil
+		}
+		query = query.Where("client_id IN ?", clientIDs)
+	}
+	err := query.Order("scheduled_date ASC, id ASC").Find(&workouts).Error
+	return workouts, err
+}
+
+// WorkoutDayCount tallies one scheduled date's workouts.
+type WorkoutDayCount struct {
+	Date      string
+	Scheduled int
+	Completed int
+}
+
+// CountScheduledByDate counts the coach's client workouts per scheduled date between startDate and
+// endDate (YYYY-MM-DD, inclusive), oldest first. Dates without workouts are left out.
+func (r *WorkoutRepository) CountScheduledByDate(ctx context.Context, coachID uint, startDate, endDate string) ([]WorkoutDayCount, error) {
+	var counts []WorkoutDayCount
+	err := r.db.WithContext(ctx).
+		Model(&models.Workout{}).
+		Select("to_char(scheduled_date, 'YYYY-MM-DD') AS date, COUNT(*) AS scheduled, COUNT(*) FILTER (WHERE status = 'completed') AS completed").
+		Where("coach_id = ? AND scheduled_date >= ? AND scheduled_date <= ?", coachID, startDate, endDate).
+		Group("scheduled_date").
+		Order("scheduled_date ASC").
+		Scan(&counts).Error
+	return counts, err
+}
+
 func (r *WorkoutRepository) Update(ctx context.Context, workout *models.Workout) error {
 	return r.db.WithContext(ctx).Save(workout).Error
 }
@@ -120,14 +185,17 @@ func (r *WorkoutRepository) StartWorkout(ctx context.Context, id uint) error {
 		}).Error
 }
 
-func (r *WorkoutRepository) CompleteWorkout(ctx context.Context, id uint) error {
-	now := time.Now()
+// CompleteWorkout marks the workout completed with the client's optional note and photo.
+func (r *WorkoutRepository) CompleteWorkout(ctx context.Context, id uint, completedAt time.Time, note, photoURL *string) error {
 	return r.db.WithContext(ctx).
 		Model(&models.Workout{}).
 		Where("id = ?", id).
 		Updates(map[string]interface{}{
-			"status":       "completed",
-			"completed_at": now,
+			"status":               "completed",
+			"completed_at":         completedAt,
+			"completion_note":      note,
+			"completion_photo_url": photoURL,
+			"session_state":        nil,
 		}).Error
 }
 
@@ -135,7 +203,37 @@ func (r *WorkoutRepository) SkipWorkout(ctx context.Context, id uint) error {
 	return r.db.WithContext(ctx).
 		Model(&models.Workout{}).
 		Where("id = ?", id).
-		Update("status", "skipped").Error
+		Updates(map[string]interface{}{
+			"status":        "skipped",
+			"session_state": nil,
+		}).Error
+}
+
+// SkipUpcomingForClient skips the client's unfinished workouts dated fromDate (YYYY-MM-DD) or later,
+// plus undated ones that haven't been started, recording reason. It returns how many were skipped.
+func (r *WorkoutRepository) SkipUpcomingForClient(ctx context.Context, clientID uint, fromDate, reason string) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Model(&models.Workout{}).
+		Where("client_id = ?", clientID).
+		Where("(status IN ? AND scheduled_date >= ?) OR (status = ? AND scheduled_date IS NULL)",
+			[]string{"scheduled", "in_progress"}, fromDate, "scheduled").
+		Updates(map[string]interface{}{
+			"status":        "skipped",
+			"skip_reason":   reason,
+			"session_state": nil,
+		})
+	return result.RowsAffected, result.Error
+}
+
+// UpdateSessionState replaces the workout's resumable state unless it has been finalized.
+// It reports false when the workout is already completed or skipped.
+func (r *WorkoutRepository) UpdateSessionState(ctx context.Context, id uint, state *models.WorkoutSessionState) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&models.Workout{ID: id}).
+		Where("status NOT IN ?", []string{"completed", "skipped"}).
+		Select("session_state").
+		Updates(&models.Workout{SessionState: state})
+	return result.RowsAffected > 0, result.Error
 }
 
 // --- Exercise Completion ---
@@ -159,7 +257,7 @@ func (r *WorkoutRepository) SkipExercise(ctx context.Context, id uint, reason st
 
 func (r *WorkoutRepository) GetExerciseByID(ctx context.Context, id uint) (*models.WorkoutExercise, error) {
 	var exercise models.WorkoutExercise
-	err := r.db.WithContext(ctx).
+	err := db.UsePrimary(r.db.WithContext(ctx)).
 		Preload("Workout").
 		First(&exercise, id).Error
 	if err != nil {
@@ -180,7 +278,7 @@ func (r *WorkoutRepository) UpdateLog(ctx context.Context, log *models.WorkoutLo
 
 func (r *WorkoutRepository) GetLogByID(ctx context.Context, id uint) (*models.WorkoutLog, error) {
 	var log models.WorkoutLog
-	err := r.db.WithContext(ctx).
+	err := db.UsePrimary(r.db.WithContext(ctx)).
 		Preload("WorkoutExercise").
 		First(&log, id).Error
 	if err != nil {
@@ -189,6 +287,56 @@ func (r *WorkoutRepository) GetLogByID(ctx context.Context, id uint) (*models.Wo
 	return &log, nil
 }
 
+// FormCheck is an unreviewed form video with enough context for the coach's review queue
+type FormCheck struct {
+	WorkoutLogID      uint      `json:"workout_log_id"`
+	WorkoutID         uint      `json:"workout_id"`
+	WorkoutExerciseID uint      `json:"workout_exercise_id"`
+	ExerciseID        uint      `json:"exercise_id"`
+	ExerciseName      string    `json:"exercise_name"`
+	ClientID          uint      `json:"client_id"`
+	FirstName         string    `json:"first_name"`
+	LastName          string    `json:"last_name"`
+	SetNumber         int       `json:"set_number"`
+	VideoURL          string    `json:"video_url"`
+	VideoUploadedAt   time.Time `json:"video_uploaded_at"`
+}
+
+// ListUnreviewedFormChecks returns form videos across a coach's clients that have no feedback yet, oldest first
+func (r *WorkoutRepository) ListUnreviewedFormChecks(ctx context.Context, coachID uint, limit, offset int) ([]FormCheck, int64, error) {
+	query := r.db.WithContext(ctx).
+		Table("workout_logs").
+		Joins("JOIN workout_exercises ON workout_exercises.id = workout_logs.workout_exercise_id").
+		Joins("JOIN workouts ON workouts.id = workout_exercises.workout_id").
+		Where("workouts.coach_id = ? AND workout_logs.video_url IS NOT NULL AND workout_logs.reviewed_at IS NULL", coachID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	checks := []FormCheck{}
+	err := query.
+		Select(`workout_logs.id AS workout_log_id,
+			workouts.id AS workout_id,
+			workout_exercises.id AS workout_exercise_id,
+			exercises.id AS exercise_id,
+			exercises.name AS exercise_name,
+			workouts.client_id,
+			COALESCE(profiles.first_name, '') AS first_name,
+			COALESCE(profiles.last_name, '') AS last_name,
+			workout_logs.set_number,
+			workout_logs.video_url,
+			workout_logs.video_uploaded_at`).
+		Joins("JOIN exercises ON exercises.id = workout_exercises.exercise_id").
+		Joins("JOIN client_profiles ON client_profiles.id = workouts.client_id").
+		Joins("LEFT JOIN profiles ON profiles.user_id = client_profiles.user_id").
+		Order("workout_logs.video_uploaded_at ASC, workout_logs.id ASC").
+		Limit(limit).Offset(offset).
+		Scan(&checks).Error
+	return checks, total, err
+}
+
 func (r *WorkoutRepository) ListLogsByExercise(ctx context.Context, workoutExerciseID uint) ([]models.WorkoutLog, error) {
 	var logs []models.WorkoutLog
 	err := r.db.WithContext(ctx).
@@ -197,3 +345,331 @@ func (r *WorkoutRepository) ListLogsByExercise(ctx context.Context, workoutExerc
 		Find(&logs).Error
 	return logs, err
 }
+
+// --- Exports ---
+
+// WorkoutHistoryRow is one logged set from a completed workout
+type WorkoutHistoryRow struct {
+	Date          string
+	WorkoutName   string
+	ExerciseName  string
+	SetNumber     int
+	RepsCompleted *int
+	WeightUsed    *float64
+	WeightUnit    *string
+	RPE           *int
+}
+
+const workoutHistoryDateExpr = "COALESCE(workouts.scheduled_date, (workouts.completed_at AT TIME ZONE 'UTC')::date)"
+
+// workoutLogVolumeKgExpr sums reps x weight over logged sets, with pounds converted to kilograms
+const workoutLogVolumeKgExpr = "COALESCE(SUM(workout_logs.reps_completed * CASE WHEN lower(workout_logs.weight_unit) IN ('lb', 'lbs') " +
+	"THEN workout_logs.weight_used * 0.45359237 ELSE workout_logs.weight_used END), 0)"
+
+// WorkoutCompliance counts a client's workouts scheduled in a period by outcome.
+type WorkoutCompliance struct {
+	Scheduled int
+	Completed int
+	Skipped   int
+}
+
+// GetCompliance counts the client's workouts scheduled between startDate and endDate
+// (YYYY-MM-DD, inclusive) in one aggregate query.
+func (r *WorkoutRepository) GetCompliance(ctx context.Context, clientID uint, startDate, endDate string) (*WorkoutCompliance, error) {
+	var compliance WorkoutCompliance
+	err := r.db.WithContext(ctx).
+		Model(&models.Workout{}).
+		Select("COUNT(*) AS scheduled, "+
+			"COUNT(*) FILTER (WHERE status = 'completed') AS completed, "+
+			"COUNT(*) FILTER (WHERE status = 'skipped') AS skipped").
+		Where("client_id = ? AND scheduled_date >= ? AND scheduled_date <= ?", clientID, startDate, endDate).
+		Scan(&compliance).Error
+	if err != nil {
+		return nil, err
+	}
+	return &compliance, nil
+}
+
+// WeeklyVolume is the training volume logged in one week, starting Monday.
+type WeeklyVolume struct {
+	WeekStart string
+	Sets      int
+	VolumeKg  float64 // sum of reps x weight, with pounds converted to kilograms
+}
+
+// ListWeeklyVolume sums the logged sets of the clients' completed workouts per week between startDate
+// and endDate (YYYY-MM-DD, inclusive), oldest first. Weeks without logs are left out.
+func (r *WorkoutRepository) ListWeeklyVolume(ctx context.Context, clientIDs []uint, startDate, endDate string) ([]WeeklyVolume, error) {
+	if len(clientIDs) == 0 {
+		return []WeeklyVolume{}, nil
+	}
+	weekExpr := "to_char(date_trunc('week', " + workoutHistoryDateExpr + "::timestamp), 'YYYY-MM-DD')"
+	var weeks []WeeklyVolume
+	err := r.db.WithContext(ctx).
+		Table("workout_logs").
+		Select(weekExpr+" AS week_start, COUNT(*) AS sets, "+workoutLogVolumeKgExpr+" AS volume_kg").
+		Joins("JOIN workout_exercises ON workout_exercises.id = workout_logs.workout_exercise_id").
+		Joins("JOIN workouts ON workouts.id = workout_exercises.workout_id").
+		Where("workouts.client_id IN ? AND workouts.status = ?", clientIDs, "completed").
+		Where(workoutHistoryDateExpr+" >= ? AND "+workoutHistoryDateExpr+" <= ?", startDate, endDate).
+		Group("week_start").
+		Order("week_start ASC").
+		Scan(&weeks).Error
+	return weeks, err
+}
+
+// MuscleGroupWeeklyVolume is the volume logged for one primary muscle group in one week, starting
+// Monday. A set of an exercise with several primary muscle groups counts toward each of them;
+// exercises with none are grouped under "other".
+type MuscleGroupWeeklyVolume struct {
+	WeekStart   string
+	MuscleGroup string
+	Sets        int
+	VolumeKg    float64
+}
+
+// ListWeeklyMuscleGroupVolume is ListWeeklyVolume split by primary muscle group, across the given
+// client profiles, in one query. Weeks and groups without logs are left out.
+func (r *WorkoutRepository) ListWeeklyMuscleGroupVolume(ctx context.Context, clientIDs []uint, startDate, endDate string) ([]MuscleGroupWeeklyVolume, error) {
+	if len(clientIDs) == 0 {
+		return []MuscleGroupWeeklyVolume{}, nil
+	}
+	weekExpr := "to_char(date_trunc('week', " + workoutHistoryDateExpr + "::timestamp), 'YYYY-MM-DD')"
+	var volumes []MuscleGroupWeeklyVolume
+	err := r.db.WithContext(ctx).
+		Table("workout_logs").
+		Select(weekExpr+" AS week_start, muscle_groups.muscle_group, COUNT(*) AS sets, "+workoutLogVolumeKgExpr+" AS volume_kg").
+		Joins("JOIN workout_exercises ON workout_exercises.id = workout_logs.workout_exercise_id").
+		Joins("JOIN workouts ON workouts.id = workout_exercises.workout_id").
+		Joins("JOIN exercises ON exercises.id = workout_exercises.exercise_id").
+		Joins("CROSS JOIN LATERAL unnest(CASE WHEN cardinality(exercises.primary_muscle_groups) > 0 "+
+			"THEN exercises.primary_muscle_groups ELSE ARRAY['other'] END) AS muscle_groups(muscle_group)").
+		Where("workouts.client_id IN ? AND workouts.status = ?", clientIDs, "completed").
+		Where(workoutHistoryDateExpr+" >= ? AND "+workoutHistoryDateExpr+" <= ?", startDate, endDate).
+		Group("week_start, muscle_groups.muscle_group").
+		Order("week_start ASC, muscle_groups.muscle_group ASC").
+		Scan(&volumes).Error
+	return volumes, err
+}
+
+// WeeklyCompletions is how many workouts a user completed in one week, starting Monday.
+type WeeklyCompletions struct {
+	WeekStart       string // YYYY-MM-DD in the requested timezone
+	Completed       int
+	LastCompletedAt time.Time
+}
+
+// ListWeeklyCompletions counts the user's completed workouts, across every coach they train with, per
+// week in tz (an IANA zone name), oldest first. Weeks without a completed workout are left out.
+func (r *WorkoutRepository) ListWeeklyCompletions(ctx context.Context, userID uint, tz string) ([]WeeklyCompletions, error) {
+	weekExpr := "to_char(date_trunc('week', workouts.completed_at AT TIME ZONE ?), 'YYYY-MM-DD')"
+	var weeks []WeeklyCompletions
+	err := r.db.WithContext(ctx).
+		Model(&models.Workout{}).
+		Select(weekExpr+" AS week_start, COUNT(*) AS completed, MAX(workouts.completed_at) AS last_completed_at", tz).
+		Where("workouts.status = ? AND workouts.completed_at IS NOT NULL", "completed").
+		Where("workouts.client_id IN (?)", r.db.Model(&models.ClientProfile{}).Select("id").Where("user_id = ?", userID)).
+		Group("week_start").
+		Order("week_start ASC").
+		Scan(&weeks).Error
+	return weeks, err
+}
+
+// StreamCompletedWorkoutHistory calls fn for every logged set of the client's completed workouts,
+// oldest first, reading rows from the cursor so large histories never sit in memory.
+// startDate and endDate (YYYY-MM-DD, inclusive) are optional.
+func (r *WorkoutRepository) StreamCompletedWorkoutHistory(
+	ctx context.Context,
+	clientID uint,
+	startDate string,
+	endDate string,
+	fn func(row WorkoutHistoryRow) error,
+) error {
+	query := r.db.WithContext(ctx).
+		Table("workout_logs").
+		Select("to_char("+workoutHistoryDateExpr+", 'YYYY-MM-DD') AS date, "+
+			"workouts.name AS workout_name, exercises.name AS exercise_name, "+
+			"workout_logs.set_number, workout_logs.reps_completed, workout_logs.weight_used, "+
+			"workout_logs.weight_unit, workout_logs.rpe").
+		Joins("JOIN workout_exercises ON workout_exercises.id = workout_logs.workout_exercise_id").
+		Joins("JOIN workouts ON workouts.id = workout_exercises.workout_id").
+		Joins("JOIN exercises ON exercises.id = workout_exercises.exercise_id").
+		Where("workouts.client_id = ? AND workouts.status = ?", clientID, "completed")
+
+	if startDate != "" {
+		query = query.Where(workoutHistoryDateExpr+" >= ?", startDate)
+	}
+	if endDate != "" {
+		query = query.Where(workoutHistoryDateExpr+" <= ?", endDate)
+	}
+
+	rows, err := query.
+		Order(workoutHistoryDateExpr + " ASC, workouts.id ASC, workout_exercises.order_index ASC, workout_logs.set_number ASC").
+		Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row WorkoutHistoryRow
+		if err := r.db.ScanRows(rows, &row); err != nil {
+			return err
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// ListForDataExport returns every workout for the given client profiles with exercises and set logs
+func (r *WorkoutRepository) ListForDataExport(ctx context.Context, clientIDs []uint) ([]models.Workout, error) {
+	if len(clientIDs) == 0 {
+		return []models.Workout{}, nil
+	}
+
+	var workouts []models.Workout
+	err := r.db.WithContext(ctx).
+		Preload("Exercises", func(db *gorm.DB) *gorm.DB {
+			return db.Order("order_index ASC")
+		}).
+		Preload("Exercises.Exercise").
+		Preload("Exercises.Logs", func(db *gorm.DB) *gorm.DB {
+			return db.Order("set_number ASC")
+		}).
+		Where("client_id IN ?", clientIDs).
+		Order("created_at ASC").
+		Find(&workouts).Error
+	return workouts, err
+}
+
+// --- Reminders ---
+
+// WorkoutReminderTarget is a scheduled workout due a reminder, with who to send it to.
+type WorkoutReminderTarget struct {
+	WorkoutID     uint
+	WorkoutName   string
+	ScheduledDate string
+	ClientUserID  uint
+	Locale        string
+}
+
+// ListReminderTimezones returns every timezone with at least one active client who wants workout
+// reminders, so the reminder worker can bucket clients by zone.
+func (r *WorkoutRepository) ListReminderTimezones(ctx context.Context) ([]string, error) {
+	var timezones []string
+	err := r.db.WithContext(ctx).
+		Table("profiles").
+		Joins("JOIN client_profiles ON client_profiles.user_id = profiles.user_id").
+		Where("client_profiles.status = ?", "active").
+		Where("profiles.workout_reminders_enabled OR profiles.workout_evening_reminder_enabled").
+		Distinct("profiles.timezone").
+		Pluck("profiles.timezone", &timezones).Error
+	return timezones, err
+}
+
+// ListDueWorkoutReminders returns still-scheduled workouts dated date (YYYY-MM-DD, local to timezone)
+// for active clients in timezone whose reminder hour is localHour or earlier and who haven't had
+// that reminder yet. Morning reminders skip workouts assigned after the client's reminder time, which
+// they already heard about from the assignment push. dayStart is midnight of date in timezone.
+func (r *WorkoutRepository) ListDueWorkoutReminders(
+	ctx context.Context,
+	timezone, date string,
+	dayStart time.Time,
+	localHour int,
+	evening bool,
+	limit int,
+) ([]WorkoutReminderTarget, error) {
+	query := r.db.WithContext(ctx).
+		Table("workouts").
+		Select(`workouts.id AS workout_id,
+			workouts.name AS workout_name,
+			workouts.scheduled_date::text AS scheduled_date,
+			client_profiles.user_id AS client_user_id,
+			profiles.locale`).
+		Joins("JOIN client_profiles ON client_profiles.id = workouts.client_id").
+		Joins("JOIN profiles ON profiles.user_id = client_profiles.user_id").
+		Where("profiles.timezone = ? AND client_profiles.status = ?", timezone, "active").
+		Where("workouts.scheduled_date = ? AND workouts.status = ?", date, "scheduled")
+	if evening {
+		query = query.
+			Where("profiles.workout_evening_reminder_enabled AND profiles.workout_evening_reminder_hour <= ?", localHour).
+			Where("workouts.evening_reminder_sent_at IS NULL")
+	} else {
+		query = query.
+			Where("profiles.workout_reminders_enabled AND profiles.workout_reminder_hour <= ?", localHour).
+			Where("workouts.reminder_sent_at IS NULL").
+			Where("workouts.created_at < ?::timestamptz + make_interval(hours => profiles.workout_reminder_hour)", dayStart)
+	}
+
+	targets := []WorkoutReminderTarget{}
+	err := query.Order("workouts.id ASC").Limit(limit).Scan(&targets).Error
+	return targets, err
+}
+
+// ClaimWorkoutReminder stamps the morning (or evening) reminder as sent if nobody has yet and the
+// workout is still scheduled. It reports false when another run got there first.
+func (r *WorkoutRepository) ClaimWorkoutReminder(ctx context.Context, workoutID uint, evening bool, sentAt time.Time) (bool, error) {
+	column := "reminder_sent_at"
+	if evening {
+		column = "evening_reminder_sent_at"
+	}
+	result := r.db.WithContext(ctx).
+		Model(&models.Workout{}).
+		Where("id = ? AND status = ?", workoutID, "scheduled").
+		Where(column+" IS NULL").
+		Update(column, sentAt)
+	return result.RowsAffected > 0, result.Error
+}
+
+// --- Client Exercise Defaults ---
+
+// UpsertClientExerciseDefault saves the default for its client and exercise, replacing every
+// override on an existing one.
+func (r *WorkoutRepository) UpsertClientExerciseDefault(ctx context.Context, exerciseDefault *models.ClientExerciseDefault) error {
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "client_profile_id"}, {Name: "exercise_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"weight_value", "weight_unit", "reps_min", "reps_max", "rest_seconds", "updated_at"}),
+		}).
+		Create(exerciseDefault).Error
+}
+
+func (r *WorkoutRepository) ListClientExerciseDefaults(ctx context.Context, clientProfileID uint) ([]models.ClientExerciseDefault, error) {
+	var defaults []models.ClientExerciseDefault
+	err := r.db.WithContext(ctx).
+		Preload("Exercise").
+		Where("client_profile_id = ?", clientProfileID).
+		Order("exercise_id ASC").
+		Find(&defaults).Error
+	return defaults, err
+}
+
+// GetClientExerciseDefaults loads the client's defaults for exerciseIDs in one query, keyed by
+// exercise ID. Exercises without a default are absent from the map.
+func (r *WorkoutRepository) GetClientExerciseDefaults(ctx context.Context, clientProfileID uint, exerciseIDs []uint) (map[uint]models.ClientExerciseDefault, error) {
+	if len(exerciseIDs) == 0 {
+		return map[uint]models.ClientExerciseDefault{}, nil
+	}
+	var defaults []models.ClientExerciseDefault
+	err := r.db.WithContext(ctx).
+		Where("client_profile_id = ? AND exercise_id IN ?", clientProfileID, exerciseIDs).
+		Find(&defaults).Error
+	if err != nil {
+		return nil, err
+	}
+	byExercise := make(map[uint]models.ClientExerciseDefault, len(defaults))
+	for _, exerciseDefault := range defaults {
+		byExercise[exerciseDefault.ExerciseID] = exerciseDefault
+	}
+	return byExercise, nil
+}
+
+// DeleteClientExerciseDefault reports false when the client had no default for the exercise.
+func (r *WorkoutRepository) DeleteClientExerciseDefault(ctx context.Context, clientProfileID, exerciseID uint) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Where("client_profile_id = ? AND exercise_id = ?", clientProfileID, exerciseID).
+		Delete(&models.ClientExerciseDefault{})
+	return result.RowsAffected > 0, result.Error
+}
@@ -4,6 +4,7 @@ import (
 	"chalk-api/pkg/config"
 	"chalk-api/pkg/handlers"
 	"chalk-api/pkg/middleware"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -12,6 +13,17 @@ import (
 func SetupRouter(h *handlers.HandlersCollection, cfg config.Environment) *gin.Engine {
 	router := gin.New()
 	router.Use(gin.Recovery())
+	router.Use(middleware.RequestIDMiddleware())
+	router.Use(middleware.TracingMiddleware())
+	router.Use(middleware.RequestAnalytics(h.RequestAnalytics))
+	uploadBodyLimit := int64(cfg.MaxUploadBodyBytes)
+	if uploadBodyLimit <= 0 {
+		uploadBodyLimit = middleware.DefaultMaxUploadBodyBytes
+	}
+	router.Use(middleware.BodySizeLimit(int64(cfg.MaxRequestBodyBytes), map[string]int64{
+		"/api/v1/users/me/avatar":           uploadBodyLimit,
+		"/api/v1/coaches/me/clients/import": handlers.MaxClientImportRequestBytes,
+	}))
 
 	// Health check endpoint
 	router.GET("/health", func(c *gin.Context) {
@@ -21,6 +33,15 @@ func SetupRouter(h *handlers.HandlersCollection, cfg config.Environment) *gin.En
 		})
 	})
 
+	// Public signing keys so other services can verify access tokens without the private key
+	router.GET("/.well-known/jwks.json", func(c *gin.Context) {
+		c.Header("Cache-Control", "public, max-age=300")
+		c.JSON(200, h.TokenKeys.JWKS())
+	})
+
+	// Prometheus scrape endpoint (database pool stats)
+	router.GET("/metrics", h.Metrics.GetMetrics)
+
 	// API v1 routes
 	v1 := router.Group("/api/v1")
 	{
@@ -37,50 +58,166 @@ func SetupRouter(h *handlers.HandlersCollection, cfg config.Environment) *gin.En
 			invites.GET("/:code", h.Invite.GetPreview)
 		}
 
+		// Public booking pages shared by coaches; no token, so requests are limited per IP.
+		public := v1.Group("/public")
+		public.Use(middleware.RateLimitByIP(h.RateLimiter, "public_booking", 60, time.Minute))
+		{
+			public.GET("/coaches/:slug/bookable-slots", h.Session.GetPublicBookingPage)
+		}
+
 		subscriptions := v1.Group("/subscriptions")
 		{
 			subscriptions.POST("/revenuecat/webhook", h.Subscription.RevenueCatWebhook)
 		}
 
 		protected := v1.Group("")
-		protected.Use(middleware.AuthMiddleware(cfg.JWTSecret))
+		protected.Use(middleware.AuthMiddleware(h.TokenKeys, h.Revocations))
 		{
 			protected.POST("/auth/logout", h.Auth.Logout)
+			protected.GET("/auth/sessions", h.Auth.ListSessions)
 			protected.POST("/invites/accept", h.Invite.Accept)
+			protected.GET("/bootstrap", h.Bootstrap.GetBootstrap)
 
 			users := protected.Group("/users")
 			{
 				users.GET("/me", h.User.GetMe)
 				users.PATCH("/me", h.User.UpdateMe)
+				users.POST("/me/avatar", h.User.UploadAvatar)
+				users.POST("/me/device-tokens", h.User.RegisterDeviceToken)
+				users.POST("/me/export", h.User.RequestDataExport)
+				users.GET("/me/export/status", h.User.GetDataExportStatus)
 				users.GET("/capabilities", h.User.GetCapabilities)
+				users.GET("/me/flags", h.Flag.GetMyFlags)
+				users.GET("/me/team-invitations", h.Coach.ListMyTeamInvitations)
+				users.POST("/me/team-invitations/:id/accept", h.Coach.AcceptTeamInvitation)
 			}
 
 			coaches := protected.Group("/coaches")
 			{
 				coaches.GET("/me", h.Coach.GetMyProfile)
 				coaches.PUT("/me", h.Coach.UpsertMyProfile)
+				coaches.GET("/me/onboarding", h.Coach.GetMyOnboarding)
+				coaches.POST("/me/cover-photo/upload-url", h.Coach.CreateCoverPhotoUpload)
+				coaches.PUT("/me/cover-photo", h.Coach.SetCoverPhoto)
 				coaches.POST("/invite-codes", h.Coach.CreateInviteCode)
 				coaches.GET("/invite-codes", h.Coach.ListInviteCodes)
 				coaches.PATCH("/invite-codes/:id/deactivate", h.Coach.DeactivateInviteCode)
+				coaches.GET("/me/context", h.Coach.GetActingCoach)
+				coaches.POST("/me/team", h.Coach.InviteTeamMember)
+				coaches.GET("/me/team", h.Coach.ListTeamMembers)
+				coaches.DELETE("/me/team/:id", h.Coach.RemoveTeamMember)
+				coaches.GET("/me/referral", h.Coach.GetMyReferral)
+				coaches.GET("/me/referral/credits", h.Coach.GetMyReferralCredits)
+				coaches.GET("/me/analytics/profile-views", h.Coach.GetProfileViews)
 
 				coaches.GET("/me/availability", h.Session.GetMyAvailability)
 				coaches.PUT("/me/availability", h.Session.SetMyAvailability)
+				coaches.POST("/me/availability/copy-day", h.Session.CopyAvailabilityDay)
+				coaches.POST("/me/availability-presets", h.Session.CreateAvailabilityPreset)
+				coaches.GET("/me/availability-presets", h.Session.ListAvailabilityPresets)
+				coaches.POST("/me/availability-presets/:id/apply", h.Session.ApplyAvailabilityPreset)
+				coaches.DELETE("/me/availability-presets/:id", h.Session.DeleteAvailabilityPreset)
 				coaches.POST("/me/availability-overrides", h.Session.CreateAvailabilityOverride)
 				coaches.GET("/me/availability-overrides", h.Session.ListAvailabilityOverrides)
 				coaches.DELETE("/me/availability-overrides/:id", h.Session.DeleteAvailabilityOverride)
+				coaches.POST("/me/time-blocks", h.Session.CreateTimeBlock)
+				coaches.GET("/me/time-blocks", h.Session.ListTimeBlocks)
+				coaches.DELETE("/me/time-blocks/:id", h.Session.DeleteTimeBlock)
 
 				coaches.POST("/me/session-types", h.Session.CreateSessionType)
 				coaches.GET("/me/session-types", h.Session.ListSessionTypes)
+				coaches.PATCH("/me/session-types/reorder", h.Session.ReorderSessionTypes)
 				coaches.PATCH("/me/session-types/:id", h.Session.UpdateSessionType)
 				coaches.GET("/me/sessions", h.Session.ListCoachSessions)
+				coaches.GET("/me/sessions/summary", h.Session.GetSessionSummary)
+				coaches.POST("/me/sessions/resolve-stale", h.Session.ResolveStaleSessions)
+				coaches.GET("/me/feedback", h.Session.ListMyFeedback)
+				coaches.POST("/me/saved-replies", h.Message.CreateSavedReply)
+				coaches.GET("/me/saved-replies", h.Message.ListSavedReplies)
+				coaches.PATCH("/me/saved-replies/:id", h.Message.UpdateSavedReply)
+				coaches.DELETE("/me/saved-replies/:id", h.Message.DeleteSavedReply)
+				coaches.GET("/me/scheduled-messages", h.Message.ListScheduledMessages)
+				coaches.POST("/me/scheduled-messages/:id/cancel", h.Message.CancelScheduledMessage)
+				coaches.GET("/me/calendar", h.Calendar.GetCoachCalendar)
+				coaches.GET("/me/calendar.ics", h.Calendar.ExportCoachCalendar)
+				coaches.GET("/me/schedule", h.Calendar.GetCoachSchedule)
+				coaches.GET("/me/reports/earnings", h.Report.GetEarningsReport)
+				coaches.GET("/me/digest/latest", h.Digest.GetLatestDigest)
+				coaches.GET("/me/clients", h.Coach.ListMyClients)
+				coaches.POST("/me/clients/import", h.Coach.ImportClients)
+				coaches.GET("/me/clients/imports/:id", h.Coach.GetClientImport)
+				coaches.GET("/me/clients/:id", h.Coach.GetMyClient)
+				coaches.PATCH("/me/clients/:id", h.Coach.UpdateMyClient)
+				coaches.PATCH("/me/clients/:id/tags", h.Coach.SetMyClientTags)
+				coaches.POST("/me/clients/:id/notes", h.Coach.CreateClientNote)
+				coaches.GET("/me/clients/:id/notes", h.Coach.ListClientNotes)
+				coaches.PATCH("/me/clients/:id/notes/:note_id", h.Coach.UpdateClientNote)
+				coaches.DELETE("/me/clients/:id/notes/:note_id", h.Coach.DeleteClientNote)
+				coaches.GET("/me/clients/:id/exercise-defaults", h.Workout.ListClientExerciseDefaults)
+				coaches.PUT("/me/clients/:id/exercise-defaults/:exercise_id", h.Workout.SetClientExerciseDefault)
+				coaches.DELETE("/me/clients/:id/exercise-defaults/:exercise_id", h.Workout.DeleteClientExerciseDefault)
+				coaches.GET("/me/client-tags", h.Coach.ListClientTags)
+				coaches.GET("/me/client-notes", h.Coach.SearchClientNotes)
+				coaches.POST("/me/client-tags/rename", h.Coach.RenameClientTag)
+				coaches.POST("/me/clients/:id/goals", h.Goal.CreateClientGoal)
+				coaches.GET("/me/clients/:id/goals", h.Goal.ListClientGoals)
+				coaches.GET("/me/clients/:id/activity", h.Activity.GetClientDailyActivity)
+				coaches.POST("/me/intake-questions", h.Intake.CreateQuestion)
+				coaches.GET("/me/intake-questions", h.Intake.ListQuestions)
+				coaches.PATCH("/me/intake-questions/:id", h.Intake.UpdateQuestion)
+				coaches.DELETE("/me/intake-questions/:id", h.Intake.DeleteQuestion)
+				coaches.GET("/me/connection-requests", h.Coach.ListConnectionRequests)
+				coaches.POST("/me/connection-requests/:id/approve", h.Coach.ApproveConnectionRequest)
+				coaches.POST("/me/connection-requests/:id/decline", h.Coach.DeclineConnectionRequest)
+				coaches.GET("/me/clients/:id/export.csv", h.Report.ExportClientWorkoutHistory)
+				coaches.GET("/me/clients/:id/export/sessions.csv", h.Report.ExportClientSessions)
+				coaches.POST("/me/clients/:id/reports", h.Report.RequestClientReport)
+				coaches.GET("/me/clients/:id/reports", h.Report.ListClientReports)
+				coaches.GET("/me/clients/:id/nutrition/adherence", h.Report.GetClientNutritionAdherence)
+				coaches.GET("/me/clients/:id/analytics/volume", h.Report.GetClientTrainingVolume)
 
 				coaches.POST("/templates", h.Workout.CreateTemplate)
 				coaches.GET("/templates", h.Workout.ListMyTemplates)
 				coaches.GET("/templates/:id", h.Workout.GetMyTemplate)
+				coaches.GET("/templates/:id/export", h.Workout.ExportTemplate)
 				coaches.PATCH("/templates/:id", h.Workout.UpdateMyTemplate)
+				coaches.GET("/templates/:id/versions", h.Workout.ListTemplateVersions)
+				coaches.POST("/templates/:id/versions/:version/restore", h.Workout.RestoreTemplateVersion)
+				coaches.POST("/me/template-categories", h.Workout.CreateTemplateCategory)
+				coaches.GET("/me/template-categories", h.Workout.ListTemplateCategories)
+				coaches.PATCH("/me/template-categories/:id", h.Workout.UpdateTemplateCategory)
+				coaches.DELETE("/me/template-categories/:id", h.Workout.DeleteTemplateCategory)
 
 				coaches.POST("/workouts/assign", h.Workout.AssignWorkout)
+				coaches.GET("/me/form-checks", h.Workout.ListFormChecks)
+				coaches.PATCH("/workout-logs/:id/feedback", h.Workout.ReviewFormCheck)
+				coaches.GET("/:id", h.Coach.GetPublicProfile)
 				coaches.GET("/:id/bookable-slots", h.Session.GetBookableSlots)
+				coaches.GET("/:id/next-available-slot", h.Session.GetNextAvailableSlot)
+				coaches.GET("/:id/session-types", h.Session.ListBookableSessionTypes)
+				coaches.POST("/:id/connection-requests", h.Coach.CreateConnectionRequest)
+			}
+
+			clients := protected.Group("/clients")
+			{
+				clients.GET("/me/calendar", h.Calendar.GetClientCalendar)
+				clients.POST("/me/goals", h.Goal.CreateMyGoal)
+				clients.GET("/me/goals", h.Goal.ListMyGoals)
+				clients.GET("/me/streaks", h.Streak.GetMyStreaks)
+				clients.GET("/me/analytics/volume", h.Report.GetMyTrainingVolume)
+				clients.POST("/me/activity-samples", h.Activity.ImportMySamples)
+				clients.GET("/me/activity", h.Activity.GetMyDailyActivity)
+				clients.GET("/me/intake-form", h.Intake.GetMyIntakeForm)
+				clients.PUT("/me/intake-form", h.Intake.SubmitMyIntakeForm)
+			}
+
+			// Goal routes are shared by the client and their coach; the service checks which one the caller is.
+			goals := protected.Group("/goals")
+			{
+				goals.PATCH("/:id", h.Goal.UpdateGoal)
+				goals.DELETE("/:id", h.Goal.DeleteGoal)
+				goals.POST("/:id/progress", h.Goal.AddGoalProgress)
+				goals.GET("/:id/progress", h.Goal.ListGoalProgress)
 			}
 
 			workouts := protected.Group("/workouts")
@@ -89,11 +226,22 @@ func SetupRouter(h *handlers.HandlersCollection, cfg config.Environment) *gin.En
 				workouts.GET("/me/:id", h.Workout.GetMyWorkout)
 				workouts.POST("/me/:id/start", h.Workout.StartMyWorkout)
 				workouts.POST("/me/:id/complete", h.Workout.CompleteMyWorkout)
+				workouts.POST("/me/:id/completion-photo/upload-url", h.Workout.CreateCompletionPhotoUpload)
+				workouts.PATCH("/me/:id/state", h.Workout.UpdateMyWorkoutState)
 
 				workouts.POST("/exercises/:id/complete", h.Workout.MarkExerciseCompleted)
 				workouts.POST("/exercises/:id/skip", h.Workout.SkipExercise)
 				workouts.POST("/exercises/:id/logs", h.Workout.CreateExerciseLog)
 				workouts.PATCH("/logs/:id", h.Workout.UpdateWorkoutLog)
+				workouts.POST("/logs/:id/video/upload-url", h.Workout.CreateFormCheckUpload)
+				workouts.PUT("/logs/:id/video", h.Workout.SetFormCheckVideo)
+			}
+
+			exercises := protected.Group("/exercises")
+			{
+				exercises.GET("/:id/alternatives", h.Workout.ListExerciseAlternatives)
+				exercises.POST("/:id/alternatives", h.Workout.AddExerciseAlternative)
+				exercises.DELETE("/:id/alternatives/:alternative_id", h.Workout.RemoveExerciseAlternative)
 			}
 
 			messages := protected.Group("/messages")
@@ -102,18 +250,52 @@ func SetupRouter(h *handlers.HandlersCollection, cfg config.Environment) *gin.En
 				messages.POST("/conversations", h.Message.GetOrCreateConversation)
 				messages.GET("/conversations/:id", h.Message.GetConversation)
 				messages.GET("/conversations/:id/messages", h.Message.ListMessages)
+				messages.GET("/conversations/:id/export", h.Message.ExportConversation)
 				messages.POST("/conversations/:id/messages", h.Message.SendMessage)
 				messages.POST("/conversations/:id/read", h.Message.MarkAsRead)
 				messages.GET("/unread-count", h.Message.GetUnreadCount)
 			}
 
+			notifications := protected.Group("/notifications")
+			{
+				notifications.GET("", h.Notification.ListNotifications)
+				notifications.GET("/unread-count", h.Notification.GetUnreadCount)
+				notifications.POST("/read-all", h.Notification.MarkAllRead)
+				notifications.POST("/:id/read", h.Notification.MarkRead)
+			}
+
 			sessions := protected.Group("/sessions")
 			{
 				sessions.POST("/book", h.Session.BookSession)
 				sessions.GET("/me", h.Session.ListMySessions)
+				sessions.GET("/:id/ics", h.Session.GetSessionICS)
+				sessions.PATCH("/:id", h.Session.UpdateSession)
 				sessions.POST("/:id/cancel", h.Session.CancelSession)
+				sessions.POST("/:id/confirm", h.Session.ConfirmSession)
+				sessions.POST("/:id/decline", h.Session.DeclineSession)
 				sessions.POST("/:id/complete", h.Session.CompleteSession)
+				sessions.POST("/:id/check-in", h.Session.CheckIn)
 				sessions.POST("/:id/no-show", h.Session.MarkNoShow)
+				sessions.POST("/:id/paid", h.Session.MarkPaid)
+				sessions.POST("/:id/feedback", h.Session.SubmitFeedback)
+			}
+
+			// Admin routes check the caller's is_admin flag in the service layer.
+			admin := protected.Group("/admin")
+			{
+				admin.POST("/clients/:client_profile_id/transfer", h.Admin.TransferClient)
+				admin.GET("/users/:id/activity", h.Admin.GetUserActivity)
+				admin.POST("/coaches/:id/recompute-stats", h.Admin.RecomputeCoachStats)
+				admin.GET("/feature-flags", h.Admin.ListFeatureFlags)
+				admin.POST("/feature-flags", h.Admin.CreateFeatureFlag)
+				admin.PATCH("/feature-flags/:id", h.Admin.UpdateFeatureFlag)
+				admin.DELETE("/feature-flags/:id", h.Admin.DeleteFeatureFlag)
+				admin.GET("/webhooks", h.Admin.ListWebhookInbox)
+				admin.POST("/webhooks/:id/reprocess", h.Admin.ReprocessWebhook)
+				admin.GET("/referrals", h.Admin.ListReferrals)
+				admin.GET("/cache/stats", h.Admin.GetCacheStats)
+				admin.GET("/push/stats", h.Admin.GetPushStats)
+				admin.PATCH("/exercises/bulk", h.Admin.BulkUpdateExercises)
 			}
 
 			protected.GET("/subscriptions/me", h.Subscription.GetMySubscription)
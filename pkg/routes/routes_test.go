@@ -0,0 +1,512 @@
+package routes_test
+
+import (
+	"bytes"
+	"chalk-api/pkg/events"
+	"chalk-api/pkg/models"
+	"chalk-api/pkg/testutil"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// These tests drive the real router against TEST_DATABASE_URL and skip without it.
+
+func TestInviteAcceptFlow(t *testing.T) {
+	stack := testutil.NewStack(t)
+	_, coachProfile, coachToken := stack.CreateCoach(t)
+	_, clientToken := stack.CreateUser(t)
+	_, otherToken := stack.CreateUser(t)
+
+	var invite models.InviteCode
+	rec := stack.Request(t, http.MethodPost, "/api/v1/coaches/invite-codes", coachToken, map[string]any{"expires_in_days": 3})
+	testutil.DecodeJSON(t, rec, http.StatusCreated, &invite)
+	if invite.CoachID != coachProfile.ID || invite.Code == "" {
+		t.Fatalf("invite = %+v, want a code for coach %d", invite, coachProfile.ID)
+	}
+
+	var preview struct {
+		Code    string `json:"code"`
+		CoachID uint   `json:"coach_id"`
+	}
+	rec = stack.Request(t, http.MethodGet, "/api/v1/invites/"+invite.Code, "", nil)
+	testutil.DecodeJSON(t, rec, http.StatusOK, &preview)
+	if preview.CoachID != coachProfile.ID {
+		t.Fatalf("preview coach = %d, want %d", preview.CoachID, coachProfile.ID)
+	}
+
+	var accepted struct {
+		ClientProfile    models.ClientProfile `json:"client_profile"`
+		AlreadyConnected bool                 `json:"already_connected"`
+	}
+	rec = stack.Request(t, http.MethodPost, "/api/v1/invites/accept", clientToken, map[string]string{"code": invite.Code})
+	testutil.DecodeJSON(t, rec, http.StatusOK, &accepted)
+	if accepted.AlreadyConnected {
+		t.Fatal("first acceptance reported already_connected")
+	}
+	if accepted.ClientProfile.CoachID != coachProfile.ID || accepted.ClientProfile.Status != "active" {
+		t.Fatalf("client profile = %+v, want an active profile under coach %d", accepted.ClientProfile, coachProfile.ID)
+	}
+	if got := stack.OutboxEvents(t, string(events.EventTypeInviteAccepted)); len(got) != 1 {
+		t.Fatalf("invite accepted events = %d, want 1", len(got))
+	}
+
+	// Accepting again is idempotent for the same client.
+	rec = stack.Request(t, http.MethodPost, "/api/v1/invites/accept", clientToken, map[string]string{"code": invite.Code})
+	testutil.DecodeJSON(t, rec, http.StatusOK, &accepted)
+	if !accepted.AlreadyConnected {
+		t.Fatal("repeat acceptance did not report already_connected")
+	}
+
+	// The single-use code is spent for anyone else.
+	rec = stack.Request(t, http.MethodPost, "/api/v1/invites/accept", otherToken, map[string]string{"code": invite.Code})
+	var failure struct {
+		Code string `json:"code"`
+	}
+	testutil.DecodeJSON(t, rec, http.StatusConflict, &failure)
+	if failure.Code != "invite_code_exhausted" {
+		t.Fatalf("code = %q, want invite_code_exhausted", failure.Code)
+	}
+
+	var clients int64
+	if err := stack.DB.Model(&models.ClientProfile{}).Where("coach_id = ?", coachProfile.ID).Count(&clients).Error; err != nil {
+		t.Fatalf("count client profiles: %v", err)
+	}
+	if clients != 1 {
+		t.Fatalf("client profiles = %d, want 1", clients)
+	}
+}
+
+func TestSessionBookingConflict(t *testing.T) {
+	stack := testutil.NewStack(t)
+	coach, _, coachToken := stack.CreateCoach(t)
+	firstUser, firstToken := stack.CreateUser(t)
+	secondUser, secondToken := stack.CreateUser(t)
+	firstClient := stack.ConnectClient(t, coach, firstUser)
+	secondClient := stack.ConnectClient(t, coach, secondUser)
+
+	slots := make([]map[string]any, 0, 7)
+	for day := 0; day < 7; day++ {
+		slots = append(slots, map[string]any{"day_of_week": day, "start_time": "08:00", "end_time": "18:00"})
+	}
+	rec := stack.Request(t, http.MethodPut, "/api/v1/coaches/me/availability", coachToken, map[string]any{"slots": slots})
+	testutil.DecodeJSON(t, rec, http.StatusOK, nil)
+
+	var sessionType models.SessionType
+	rec = stack.Request(t, http.MethodPost, "/api/v1/coaches/me/session-types", coachToken, map[string]any{
+		"name":             "1:1 Training",
+		"duration_minutes": 60,
+	})
+	testutil.DecodeJSON(t, rec, http.StatusCreated, &sessionType)
+
+	tomorrow := time.Now().UTC().AddDate(0, 0, 1)
+	at := func(hour, minute int) string {
+		return time.Date(tomorrow.Year(), tomorrow.Month(), tomorrow.Day(), hour, minute, 0, 0, time.UTC).Format(time.RFC3339)
+	}
+	book := func(clientProfileID uint, scheduledAt string) map[string]any {
+		return map[string]any{
+			"client_profile_id": clientProfileID,
+			"session_type_id":   sessionType.ID,
+			"scheduled_at":      scheduledAt,
+		}
+	}
+
+	var booked struct {
+		ID     uint   `json:"id"`
+		Status string `json:"status"`
+	}
+	rec = stack.Request(t, http.MethodPost, "/api/v1/sessions/book", firstToken, book(firstClient.ID, at(10, 0)))
+	testutil.DecodeJSON(t, rec, http.StatusCreated, &booked)
+	if booked.Status != "scheduled" {
+		t.Fatalf("status = %q, want scheduled", booked.Status)
+	}
+
+	// An overlapping booking for another client is refused without creating anything.
+	var conflict struct {
+		Code string `json:"code"`
+	}
+	rec = stack.Request(t, http.MethodPost, "/api/v1/sessions/book?suggest_slots=false", secondToken, book(secondClient.ID, at(10, 30)))
+	testutil.DecodeJSON(t, rec, http.StatusConflict, &conflict)
+	if conflict.Code != "session_conflict" {
+		t.Fatalf("code = %q, want session_conflict", conflict.Code)
+	}
+
+	// Back-to-back is fine.
+	rec = stack.Request(t, http.MethodPost, "/api/v1/sessions/book", secondToken, book(secondClient.ID, at(11, 0)))
+	testutil.DecodeJSON(t, rec, http.StatusCreated, nil)
+
+	var count int64
+	if err := stack.DB.Model(&models.Session{}).Where("status <> ?", "cancelled").Count(&count).Error; err != nil {
+		t.Fatalf("count sessions: %v", err)
+	}
+	if count != 2 {
+		t.Fatalf("sessions = %d, want 2", count)
+	}
+	if got := stack.OutboxEvents(t, string(events.EventTypeSessionBooked)); len(got) != 2 {
+		t.Fatalf("session booked events = %d, want 2", len(got))
+	}
+}
+
+func TestWorkoutAssignToCompleteFlow(t *testing.T) {
+	stack := testutil.NewStack(t)
+	coach, _, coachToken := stack.CreateCoach(t)
+	client, clientToken := stack.CreateUser(t)
+	clientProfile := stack.ConnectClient(t, coach, client)
+	exerciseID := stack.CreateExercise(t, "Goblet Squat")
+
+	var template models.WorkoutTemplate
+	rec := stack.Request(t, http.MethodPost, "/api/v1/coaches/templates", coachToken, map[string]any{
+		"name": "Lower Body A",
+		"exercises": []map[string]any{
+			{"exercise_id": exerciseID, "order_index": 0, "sets": 3, "reps_min": 10},
+		},
+	})
+	testutil.DecodeJSON(t, rec, http.StatusCreated, &template)
+
+	var assigned models.Workout
+	rec = stack.Request(t, http.MethodPost, "/api/v1/coaches/workouts/assign", coachToken, map[string]any{
+		"template_id":       template.ID,
+		"client_profile_id": clientProfile.ID,
+		"scheduled_date":    time.Now().UTC().Format("2006-01-02"),
+	})
+	testutil.DecodeJSON(t, rec, http.StatusCreated, &assigned)
+	if assigned.ClientID != clientProfile.ID {
+		t.Fatalf("workout client = %d, want %d", assigned.ClientID, clientProfile.ID)
+	}
+
+	var workout models.Workout
+	path := fmt.Sprintf("/api/v1/workouts/me/%d", assigned.ID)
+	rec = stack.Request(t, http.MethodGet, path, clientToken, nil)
+	testutil.DecodeJSON(t, rec, http.StatusOK, &workout)
+	if len(workout.Exercises) != 1 || workout.Exercises[0].ExerciseID != exerciseID {
+		t.Fatalf("workout exercises = %+v, want the template's exercise", workout.Exercises)
+	}
+
+	// The coach can't complete a client's workout through the client endpoints.
+	rec = stack.Request(t, http.MethodPost, path+"/complete", coachToken, nil)
+	if rec.Code == http.StatusOK {
+		t.Fatal("coach completed the client's workout")
+	}
+
+	rec = stack.Request(t, http.MethodPost, path+"/start", clientToken, nil)
+	testutil.DecodeJSON(t, rec, http.StatusOK, nil)
+	rec = stack.Request(t, http.MethodPost, fmt.Sprintf("/api/v1/workouts/exercises/%d/complete", workout.Exercises[0].ID), clientToken, nil)
+	testutil.DecodeJSON(t, rec, http.StatusOK, nil)
+
+	var completed models.Workout
+	rec = stack.Request(t, http.MethodPost, path+"/complete", clientToken, map[string]string{"completion_note": "Felt strong"})
+	testutil.DecodeJSON(t, rec, http.StatusOK, &completed)
+	if completed.Status != "completed" || completed.CompletedAt == nil {
+		t.Fatalf("workout = status %q completed_at %v, want completed", completed.Status, completed.CompletedAt)
+	}
+	if got := stack.OutboxEvents(t, string(events.EventTypeWorkoutCompleted)); len(got) != 1 {
+		t.Fatalf("workout completed events = %d, want 1", len(got))
+	}
+
+	// Completing twice is rejected.
+	rec = stack.Request(t, http.MethodPost, path+"/complete", clientToken, nil)
+	testutil.DecodeJSON(t, rec, http.StatusConflict, nil)
+}
+
+func TestArchivedConversationLeavesUnreadCount(t *testing.T) {
+	stack := testutil.NewStack(t)
+	coach, _, coachToken := stack.CreateCoach(t)
+	client, clientToken := stack.CreateUser(t)
+	clientProfile := stack.ConnectClient(t, coach, client)
+
+	var conversation models.Conversation
+	rec := stack.Request(t, http.MethodPost, "/api/v1/messages/conversations", clientToken, map[string]any{"client_profile_id": clientProfile.ID})
+	testutil.DecodeJSON(t, rec, http.StatusOK, &conversation)
+	for _, content := range []string{"Hi coach", "Quick question about Monday"} {
+		rec = stack.Request(t, http.MethodPost, fmt.Sprintf("/api/v1/messages/conversations/%d/messages", conversation.ID), clientToken, map[string]string{"content": content})
+		testutil.DecodeJSON(t, rec, http.StatusCreated, nil)
+	}
+
+	unread := func(query string) int64 {
+		t.Helper()
+		var body struct {
+			UnreadCount int64 `json:"unread_count"`
+		}
+		rec := stack.Request(t, http.MethodGet, "/api/v1/messages/unread-count"+query, coachToken, nil)
+		testutil.DecodeJSON(t, rec, http.StatusOK, &body)
+		return body.UnreadCount
+	}
+	inbox := func(query string) int {
+		t.Helper()
+		var conversations []models.Conversation
+		rec := stack.Request(t, http.MethodGet, "/api/v1/messages/conversations"+query, coachToken, nil)
+		testutil.DecodeJSON(t, rec, http.StatusOK, &conversations)
+		return len(conversations)
+	}
+	// setStatus changes the relationship and runs the client.status_changed handler the outbox
+	// dispatcher would.
+	setStatus := func(status string) {
+		t.Helper()
+		rec := stack.Request(t, http.MethodPatch, fmt.Sprintf("/api/v1/coaches/me/clients/%d", clientProfile.ID), coachToken, map[string]string{"status": status})
+		testutil.DecodeJSON(t, rec, http.StatusOK, nil)
+
+		queued := stack.OutboxEvents(t, string(events.EventTypeClientStatusChanged))
+		if len(queued) == 0 {
+			t.Fatal("no client.status_changed event queued")
+		}
+		handler := events.NewClientStatusChangedHandler(stack.Repos.Client, stack.Repos.Message)
+		if err := handler.Handle(context.Background(), queued[len(queued)-1]); err != nil {
+			t.Fatalf("handle client.status_changed: %v", err)
+		}
+	}
+
+	if got := unread(""); got != 2 {
+		t.Fatalf("unread before archiving = %d, want 2", got)
+	}
+
+	setStatus("archived")
+	if got := unread(""); got != 0 {
+		t.Fatalf("unread after archiving = %d, want 0", got)
+	}
+	if got := unread("?include_inactive=true"); got != 2 {
+		t.Fatalf("unread including inactive = %d, want 2", got)
+	}
+	if got := inbox(""); got != 0 {
+		t.Fatalf("inbox after archiving = %d conversations, want 0", got)
+	}
+	if got := inbox("?include_inactive=true"); got != 1 {
+		t.Fatalf("inbox including inactive = %d conversations, want 1", got)
+	}
+
+	setStatus("active")
+	if got := unread(""); got != 2 {
+		t.Fatalf("unread after reactivation = %d, want 2", got)
+	}
+	if got := inbox(""); got != 1 {
+		t.Fatalf("inbox after reactivation = %d conversations, want 1", got)
+	}
+}
+
+func TestDuplicateRevenueCatWebhookAppliesOnce(t *testing.T) {
+	stack := testutil.NewStack(t)
+	user, _ := stack.CreateUser(t)
+
+	expiresAt := time.Now().Add(30 * 24 * time.Hour).UnixMilli()
+	body, err := json.Marshal(map[string]any{
+		"api_version": "1.0",
+		"event": map[string]any{
+			"id":               "evt-duplicate-1",
+			"type":             "INITIAL_PURCHASE",
+			"app_user_id":      strconv.FormatUint(uint64(user.ID), 10),
+			"product_id":       "chalk_pro_monthly",
+			"store":            "APP_STORE",
+			"environment":      "SANDBOX",
+			"purchased_at_ms":  time.Now().UnixMilli(),
+			"expiration_at_ms": expiresAt,
+		},
+	})
+	if err != nil {
+		t.Fatalf("encode webhook: %v", err)
+	}
+
+	// RevenueCat retries deliveries it didn't see acknowledged, so the same event arrives twice.
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/subscriptions/revenuecat/webhook", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", stack.RevenueCat.WebhookAuthorization)
+		rec := httptest.NewRecorder()
+		stack.Router.ServeHTTP(rec, req)
+		testutil.DecodeJSON(t, rec, http.StatusOK, nil)
+	}
+
+	var inbox int64
+	if err := stack.DB.Model(&models.WebhookInboxEntry{}).Count(&inbox).Error; err != nil {
+		t.Fatalf("count inbox entries: %v", err)
+	}
+	if inbox != 1 {
+		t.Fatalf("inbox entries = %d, want 1", inbox)
+	}
+	queued := stack.OutboxEvents(t, string(events.EventTypeSubscriptionWebhook))
+	if len(queued) != 1 {
+		t.Fatalf("queued webhook events = %d, want 1", len(queued))
+	}
+
+	// The outbox may still hand the event over more than once; applying it again changes nothing.
+	for i := 0; i < 2; i++ {
+		if err := stack.Services.Subscription.ProcessRevenueCatWebhook(context.Background(), queued[0]); err != nil {
+			t.Fatalf("process webhook (attempt %d): %v", i+1, err)
+		}
+	}
+
+	var subscriptions []models.Subscription
+	if err := stack.DB.Where("user_id = ?", user.ID).Find(&subscriptions).Error; err != nil {
+		t.Fatalf("list subscriptions: %v", err)
+	}
+	if len(subscriptions) != 1 {
+		t.Fatalf("subscriptions = %d, want 1", len(subscriptions))
+	}
+	subscription := subscriptions[0]
+	if subscription.ProductID == nil || *subscription.ProductID != "chalk_pro_monthly" || subscription.ExpiresAt == nil || subscription.ExpiresAt.UnixMilli() != expiresAt {
+		t.Fatalf("subscription = %+v, want the purchased product expiring at the event's expiration", subscription)
+	}
+
+	var applied int64
+	if err := stack.DB.Model(&models.SubscriptionEvent{}).Where("subscription_id = ?", subscription.ID).Count(&applied).Error; err != nil {
+		t.Fatalf("count subscription events: %v", err)
+	}
+	if applied != 1 {
+		t.Fatalf("subscription events = %d, want 1", applied)
+	}
+}
+
+// createInvite issues a single-use invite code for the coach.
+func createInvite(t *testing.T, stack *testutil.Stack, coachToken string) models.InviteCode {
+	t.Helper()
+	var invite models.InviteCode
+	rec := stack.Request(t, http.MethodPost, "/api/v1/coaches/invite-codes", coachToken, map[string]any{"expires_in_days": 3})
+	testutil.DecodeJSON(t, rec, http.StatusCreated, &invite)
+	return invite
+}
+
+type registerResponse struct {
+	AccessToken string `json:"access_token"`
+	User        struct {
+		ID uint `json:"id"`
+	} `json:"user"`
+	Invite *struct {
+		ClientProfile models.ClientProfile `json:"client_profile"`
+	} `json:"invite"`
+	Warnings []string `json:"warnings"`
+}
+
+func registerWithInvite(t *testing.T, stack *testutil.Stack, email, code string, wantStatus int) registerResponse {
+	t.Helper()
+	var resp registerResponse
+	rec := stack.Request(t, http.MethodPost, "/api/v1/auth/register", "", map[string]any{
+		"email":       email,
+		"password":    "password123",
+		"first_name":  "Invited",
+		"last_name":   "Client",
+		"timezone":    "UTC",
+		"invite_code": code,
+	})
+	testutil.DecodeJSON(t, rec, wantStatus, &resp)
+	return resp
+}
+
+func TestRegisterWithInviteCodeConnectsClient(t *testing.T) {
+	stack := testutil.NewStack(t)
+	_, coachProfile, coachToken := stack.CreateCoach(t)
+	invite := createInvite(t, stack, coachToken)
+
+	resp := registerWithInvite(t, stack, "invited@example.test", invite.Code, http.StatusCreated)
+	if resp.AccessToken == "" || len(resp.Warnings) != 0 {
+		t.Fatalf("register = %+v, want tokens and no warnings", resp)
+	}
+	if resp.Invite == nil || resp.Invite.ClientProfile.CoachID != coachProfile.ID || resp.Invite.ClientProfile.UserID != resp.User.ID {
+		t.Fatalf("invite = %+v, want the new user connected to coach %d", resp.Invite, coachProfile.ID)
+	}
+	if got := stack.OutboxEvents(t, string(events.EventTypeInviteAccepted)); len(got) != 1 {
+		t.Fatalf("invite accepted events = %d, want 1", len(got))
+	}
+}
+
+func TestRegisterWithExpiredInviteCodeWarns(t *testing.T) {
+	stack := testutil.NewStack(t)
+	_, coachProfile, coachToken := stack.CreateCoach(t)
+	invite := createInvite(t, stack, coachToken)
+	if err := stack.DB.Model(&models.InviteCode{}).Where("id = ?", invite.ID).
+		Update("expires_at", time.Now().Add(-time.Hour)).Error; err != nil {
+		t.Fatalf("expire invite: %v", err)
+	}
+
+	// The account is still created; only the connection is skipped.
+	resp := registerWithInvite(t, stack, "late@example.test", invite.Code, http.StatusCreated)
+	if resp.AccessToken == "" || resp.User.ID == 0 {
+		t.Fatalf("register = %+v, want the account created", resp)
+	}
+	if resp.Invite != nil || len(resp.Warnings) != 1 || resp.Warnings[0] != "invite_code_invalid" {
+		t.Fatalf("invite = %+v warnings = %v, want no connection and invite_code_invalid", resp.Invite, resp.Warnings)
+	}
+
+	var clients int64
+	if err := stack.DB.Model(&models.ClientProfile{}).Where("coach_id = ?", coachProfile.ID).Count(&clients).Error; err != nil {
+		t.Fatalf("count client profiles: %v", err)
+	}
+	if clients != 0 {
+		t.Fatalf("client profiles = %d, want 0", clients)
+	}
+}
+
+func TestRegisterWithInviteCodeAndExistingEmail(t *testing.T) {
+	stack := testutil.NewStack(t)
+	_, _, coachToken := stack.CreateCoach(t)
+	invite := createInvite(t, stack, coachToken)
+	registerWithInvite(t, stack, "taken@example.test", "", http.StatusCreated)
+
+	resp := registerWithInvite(t, stack, "taken@example.test", invite.Code, http.StatusConflict)
+	if resp.AccessToken != "" || resp.Invite != nil {
+		t.Fatalf("register = %+v, want a conflict with nothing issued", resp)
+	}
+
+	// The failed sign-up must not spend the single-use code.
+	var stored models.InviteCode
+	if err := stack.DB.First(&stored, invite.ID).Error; err != nil {
+		t.Fatalf("reload invite: %v", err)
+	}
+	if stored.UseCount != 0 {
+		t.Fatalf("invite use count = %d, want 0", stored.UseCount)
+	}
+	if got := stack.OutboxEvents(t, string(events.EventTypeInviteAccepted)); len(got) != 0 {
+		t.Fatalf("invite accepted events = %d, want 0", len(got))
+	}
+}
+
+func TestLoginTwiceOnSameDeviceKeepsOneRefreshToken(t *testing.T) {
+	stack := testutil.NewStack(t)
+	user, _ := stack.CreateUser(t)
+
+	type tokens struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	login := func(deviceID string) tokens {
+		t.Helper()
+		var result tokens
+		rec := stack.Request(t, http.MethodPost, "/api/v1/auth/login", "", map[string]string{
+			"email":     user.Email,
+			"password":  "password123",
+			"device_id": deviceID,
+		})
+		testutil.DecodeJSON(t, rec, http.StatusOK, &result)
+		return result
+	}
+	activeTokens := func(deviceID string) int64 {
+		t.Helper()
+		var count int64
+		err := stack.DB.Model(&models.RefreshToken{}).
+			Where("user_id = ? AND device_id = ? AND revoked = ?", user.ID, deviceID, false).
+			Count(&count).Error
+		if err != nil {
+			t.Fatalf("count refresh tokens: %v", err)
+		}
+		return count
+	}
+
+	first := login("phone-1")
+	second := login("phone-1")
+	login("tablet-1")
+
+	if got := activeTokens("phone-1"); got != 1 {
+		t.Fatalf("active refresh tokens for phone-1 = %d, want 1", got)
+	}
+	if got := activeTokens("tablet-1"); got != 1 {
+		t.Fatalf("active refresh tokens for tablet-1 = %d, want 1", got)
+	}
+
+	rec := stack.Request(t, http.MethodPost, "/api/v1/auth/refresh", "", map[string]string{"refresh_token": second.RefreshToken})
+	testutil.DecodeJSON(t, rec, http.StatusOK, nil)
+
+	// The first login's token was replaced and can't be used any more.
+	rec = stack.Request(t, http.MethodPost, "/api/v1/auth/refresh", "", map[string]string{"refresh_token": first.RefreshToken})
+	testutil.DecodeJSON(t, rec, http.StatusUnauthorized, nil)
+}
@@ -0,0 +1,322 @@
+package seeds
+
+import (
+	"bytes"
+	"chalk-api/pkg/models"
+	"chalk-api/pkg/repositories"
+	"chalk-api/pkg/stores"
+	"context"
+	"embed"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+)
+
+// BatchSize is the number of rows written per INSERT statement.
+const BatchSize = 500
+
+// catalogSource marks seeded rows; it is part of the upsert key.
+const catalogSource = "chalk"
+
+//go:embed data/exercises.json data/exercise_alternatives.json data/foods.csv
+var catalog embed.FS
+
+// Result reports how many catalog rows were written.
+type Result struct {
+	Exercises            int
+	ExerciseAlternatives int
+	FoodItems            int
+}
+
+type exerciseRecord struct {
+	ExternalID            string   `json:"external_id"`
+	Name                  string   `json:"name"`
+	Description           *string  `json:"description"`
+	Instructions          *string  `json:"instructions"`
+	GifURL                *string  `json:"gif_url"`
+	VideoURL              *string  `json:"video_url"`
+	ThumbnailURL          *string  `json:"thumbnail_url"`
+	Category              string   `json:"category"`
+	PrimaryMuscleGroups   []string `json:"primary_muscle_groups"`
+	SecondaryMuscleGroups []string `json:"secondary_muscle_groups"`
+	PrimaryEquipment      []string `json:"primary_equipment"`
+	OptionalEquipment     []string `json:"optional_equipment"`
+	Difficulty            *string  `json:"difficulty"`
+	MeasurementType       string   `json:"measurement_type"`
+	CoachingCues          *string  `json:"coaching_cues"`
+	CommonMistakes        *string  `json:"common_mistakes"`
+	Tags                  []string `json:"tags"`
+}
+
+// alternativeRecord pairs two catalog exercises by external_id; the pair is one-way.
+type alternativeRecord struct {
+	Exercise    string  `json:"exercise"`
+	Alternative string  `json:"alternative"`
+	Reason      *string `json:"reason"`
+}
+
+// importCatalog imports the bundled catalogs and clears the exercise and food caches.
+func importCatalog(ctx context.Context, repos *repositories.RepositoriesCollection, cacheStores *stores.StoresCollection) (*Result, error) {
+	exercises, err := loadExercises()
+	if err != nil {
+		return nil, err
+	}
+	foods, err := loadFoodItems()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := repos.Exercise.UpsertSystemBatch(ctx, exercises, BatchSize); err != nil {
+		return nil, fmt.Errorf("upsert exercises: %w", err)
+	}
+	alternatives, err := loadAlternatives(exercises)
+	if err != nil {
+		return nil, err
+	}
+	if err := repos.Exercise.UpsertAlternatives(ctx, alternatives); err != nil {
+		return nil, fmt.Errorf("upsert exercise alternatives: %w", err)
+	}
+	if err := repos.Nutrition.UpsertSystemFoodItems(ctx, foods, BatchSize); err != nil {
+		return nil, fmt.Errorf("upsert food items: %w", err)
+	}
+
+	invalidateCaches(cacheStores, exercises, foods)
+
+	slog.Info("Catalog seed completed", "exercises", len(exercises), "exercise_alternatives", len(alternatives), "food_items", len(foods))
+	return &Result{Exercises: len(exercises), ExerciseAlternatives: len(alternatives), FoodItems: len(foods)}, nil
+}
+
+func invalidateCaches(cacheStores *stores.StoresCollection, exercises []models.Exercise, foods []models.FoodItem) {
+	if cacheStores == nil {
+		return
+	}
+	if cacheStores.Exercise != nil {
+		for i := range exercises {
+			cacheStores.Exercise.Invalidate(exercises[i].ID)
+		}
+		cacheStores.Exercise.InvalidateSystemLists()
+	}
+	if cacheStores.Nutrition != nil {
+		for i := range foods {
+			if foods[i].Barcode != nil {
+				cacheStores.Nutrition.InvalidateByBarcode(*foods[i].Barcode)
+			}
+		}
+		cacheStores.Nutrition.InvalidateSearchResults()
+	}
+}
+
+func loadExercises() ([]models.Exercise, error) {
+	raw, err := catalog.ReadFile("data/exercises.json")
+	if err != nil {
+		return nil, err
+	}
+
+	var records []exerciseRecord
+	if err := json.Unmarshal(raw, &records); err != nil {
+		return nil, fmt.Errorf("decode exercises.json: %w", err)
+	}
+
+	seen := make(map[string]bool, len(records))
+	exercises := make([]models.Exercise, 0, len(records))
+	for i, record := range records {
+		externalID := strings.TrimSpace(record.ExternalID)
+		if externalID == "" || strings.TrimSpace(record.Name) == "" || record.Category == "" || record.MeasurementType == "" {
+			return nil, fmt.Errorf("exercises.json entry %d: external_id, name, category and measurement_type are required", i)
+		}
+		// Postgres rejects an upsert that touches the same row twice in one statement.
+		if seen[externalID] {
+			return nil, fmt.Errorf("exercises.json: duplicate external_id %q", externalID)
+		}
+		seen[externalID] = true
+
+		exercises = append(exercises, models.Exercise{
+			Name:                  strings.TrimSpace(record.Name),
+			Description:           record.Description,
+			Instructions:          record.Instructions,
+			GifURL:                record.GifURL,
+			VideoURL:              record.VideoURL,
+			ThumbnailURL:          record.ThumbnailURL,
+			Category:              record.Category,
+			PrimaryMuscleGroups:   record.PrimaryMuscleGroups,
+			SecondaryMuscleGroups: record.SecondaryMuscleGroups,
+			PrimaryEquipment:      record.PrimaryEquipment,
+			OptionalEquipment:     record.OptionalEquipment,
+			Difficulty:            record.Difficulty,
+			MeasurementType:       record.MeasurementType,
+			CoachingCues:          record.CoachingCues,
+			CommonMistakes:        record.CommonMistakes,
+			Tags:                  record.Tags,
+			Source:                catalogSource,
+			ExternalID:            &externalID,
+			IsSystem:              true,
+			IsActive:              true,
+		})
+	}
+	return exercises, nil
+}
+
+// loadAlternatives resolves the bundled alternative pairs against the just-upserted exercises,
+// which carry their database IDs.
+func loadAlternatives(exercises []models.Exercise) ([]models.ExerciseAlternative, error) {
+	raw, err := catalog.ReadFile("data/exercise_alternatives.json")
+	if err != nil {
+		return nil, err
+	}
+
+	var records []alternativeRecord
+	if err := json.Unmarshal(raw, &records); err != nil {
+		return nil, fmt.Errorf("decode exercise_alternatives.json: %w", err)
+	}
+
+	ids := make(map[string]uint, len(exercises))
+	for i := range exercises {
+		ids[*exercises[i].ExternalID] = exercises[i].ID
+	}
+
+	seen := make(map[[2]uint]bool, len(records))
+	alternatives := make([]models.ExerciseAlternative, 0, len(records))
+	for i, record := range records {
+		exerciseID, ok := ids[record.Exercise]
+		if !ok {
+			return nil, fmt.Errorf("exercise_alternatives.json entry %d: unknown exercise %q", i, record.Exercise)
+		}
+		alternativeID, ok := ids[record.Alternative]
+		if !ok {
+			return nil, fmt.Errorf("exercise_alternatives.json entry %d: unknown alternative %q", i, record.Alternative)
+		}
+		if exerciseID == alternativeID {
+			return nil, fmt.Errorf("exercise_alternatives.json entry %d: %q cannot be its own alternative", i, record.Exercise)
+		}
+		// Same constraint as the exercise upsert: one statement can't touch a row twice.
+		pair := [2]uint{exerciseID, alternativeID}
+		if seen[pair] {
+			return nil, fmt.Errorf("exercise_alternatives.json: duplicate pair %q -> %q", record.Exercise, record.Alternative)
+		}
+		seen[pair] = true
+
+		alternatives = append(alternatives, models.ExerciseAlternative{
+			ExerciseID:            exerciseID,
+			AlternativeExerciseID: alternativeID,
+			Reason:                record.Reason,
+		})
+	}
+	return alternatives, nil
+}
+
+func loadFoodItems() ([]models.FoodItem, error) {
+	raw, err := catalog.ReadFile("data/foods.csv")
+	if err != nil {
+		return nil, err
+	}
+
+	reader := csv.NewReader(bytes.NewReader(raw))
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("read foods.csv header: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.TrimSpace(name)] = i
+	}
+	for _, required := range []string{"external_id", "name"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("foods.csv is missing the %s column", required)
+		}
+	}
+
+	seen := make(map[string]bool)
+	var foods []models.FoodItem
+	for line := 2; ; line++ {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("foods.csv line %d: %w", line, err)
+		}
+
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(row) {
+				return strings.TrimSpace(row[i])
+			}
+			return ""
+		}
+
+		externalID := field("external_id")
+		name := field("name")
+		if externalID == "" || name == "" {
+			return nil, fmt.Errorf("foods.csv line %d: external_id and name are required", line)
+		}
+		if seen[externalID] {
+			return nil, fmt.Errorf("foods.csv line %d: duplicate external_id %q", line, externalID)
+		}
+		seen[externalID] = true
+
+		item := models.FoodItem{
+			Name:        name,
+			Brand:       optionalString(field("brand")),
+			ServingSize: optionalString(field("serving_size")),
+			Barcode:     optionalString(field("barcode")),
+			ImageURL:    optionalString(field("image_url")),
+			Source:      catalogSource,
+			ExternalID:  &externalID,
+			IsSystem:    true,
+			IsActive:    true,
+		}
+
+		numbers := []struct {
+			column string
+			dest   **float64
+		}{
+			{"serving_size_grams", &item.ServingSizeGrams},
+			{"protein_grams", &item.ProteinGrams},
+			{"carbs_grams", &item.CarbsGrams},
+			{"fat_grams", &item.FatGrams},
+			{"fiber_grams", &item.FiberGrams},
+			{"sugar_grams", &item.SugarGrams},
+			{"sodium_mg", &item.SodiumMg},
+		}
+		for _, n := range numbers {
+			value, err := optionalFloat(field(n.column))
+			if err != nil {
+				return nil, fmt.Errorf("foods.csv line %d: invalid %s: %w", line, n.column, err)
+			}
+			*n.dest = value
+		}
+
+		if raw := field("calories"); raw != "" {
+			calories, err := strconv.Atoi(raw)
+			if err != nil {
+				return nil, fmt.Errorf("foods.csv line %d: invalid calories: %w", line, err)
+			}
+			item.Calories = &calories
+		}
+
+		foods = append(foods, item)
+	}
+	return foods, nil
+}
+
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+func optionalFloat(value string) (*float64, error) {
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &parsed, nil
+}
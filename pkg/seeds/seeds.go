@@ -1,21 +1,25 @@
+// Package seeds loads the bundled system exercise and food catalogs so fresh deployments
+// are usable without hand-entered data. Imports are idempotent: rows are upserted by a
+// stable external_id, so re-running refreshes the catalog instead of duplicating it.
 package seeds
 
 import (
+	"chalk-api/pkg/repositories"
+	"chalk-api/pkg/stores"
+	"context"
 	"log/slog"
-
-	"gorm.io/gorm"
 )
 
-// InitializeSeedData seeds the database with initial data
-func InitializeSeedData(db *gorm.DB) error {
+// InitializeSeedData seeds the database with the bundled catalogs. cacheStores may be nil when
+// Redis isn't configured.
+func InitializeSeedData(ctx context.Context, repos *repositories.RepositoriesCollection, cacheStores *stores.StoresCollection) (*Result, error) {
 	slog.Info("Initializing seed data...")
 
-	// Add your seed logic here
-	// Example:
-	// if err := seedUsers(db); err != nil {
-	// 	return err
-	// }
+	result, err := importCatalog(ctx, repos, cacheStores)
+	if err != nil {
+		return nil, err
+	}
 
 	slog.Info("Seed data initialization completed")
-	return nil
+	return result, nil
 }
@@ -0,0 +1,388 @@
+package services
+
+import (
+	"chalk-api/pkg/models"
+	"chalk-api/pkg/repositories"
+	"chalk-api/pkg/units"
+	"chalk-api/pkg/utils"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrInvalidActivitySamples is returned when an activity import has samples that can't be stored.
+var ErrInvalidActivitySamples = errors.New("invalid activity samples")
+
+// Activity sample types a device sync can send.
+const (
+	ActivityTypeSteps        = "steps"
+	ActivityTypeActiveEnergy = "active_energy"
+	ActivityTypeDistance     = "distance"
+	ActivityTypeWorkout      = "workout"
+)
+
+// Reasons a sample is rejected
+const (
+	ActivityViolationInvalidUnit      = "invalid_unit"
+	ActivityViolationInvalidRange     = "end_before_start"
+	ActivityViolationInFuture         = "in_future"
+	ActivityViolationOverlapsBatch    = "overlaps_batch"    // overlaps another sample of the same type and source in this request
+	ActivityViolationOverlapsExisting = "overlaps_existing" // overlaps a stored sample of the same type and source
+)
+
+const (
+	// Device clocks drift; readings this far past the server clock are still accepted
+	activityClockSkew = 5 * time.Minute
+
+	defaultActivityRangeDays = 30
+	maxActivityRangeDays     = 92
+	activitySparklineDays    = 7
+
+	kcalPerKilojoule = 0.239005736
+)
+
+// ActivitySampleInput is one reading from HealthKit or Google Fit. Units are converted on import:
+// steps are counts, active energy kcal (or kJ), distance m/km/mi, and workouts minutes (or s/h).
+type ActivitySampleInput struct {
+	Type      string    `json:"type" binding:"required,oneof=steps active_energy distance workout"`
+	Value     *float64  `json:"value" binding:"required,gte=0"`
+	Unit      string    `json:"unit" binding:"required,max=20"`
+	StartTime time.Time `json:"start_time" binding:"required"`
+	EndTime   time.Time `json:"end_time" binding:"required"`
+	Source    string    `json:"source" binding:"required,max=100"`
+}
+
+// ImportActivitySamplesInput is one sync batch. ClientProfileID is only needed when the client has
+// more than one active coach.
+type ImportActivitySamplesInput struct {
+	ClientProfileID *uint                 `json:"client_profile_id"`
+	Samples         []ActivitySampleInput `json:"samples" binding:"required,min=1,max=500,dive"`
+}
+
+// ActivityImportResult counts what one import stored; duplicates are readings already synced.
+type ActivityImportResult struct {
+	Received   int   `json:"received"`
+	Imported   int64 `json:"imported"`
+	Duplicates int64 `json:"duplicates"`
+}
+
+// ActivitySampleViolation is one rejected sample, by its index in the request.
+type ActivitySampleViolation struct {
+	Index  int    `json:"index"`
+	Reason string `json:"reason"`
+}
+
+// ActivitySampleError is ErrInvalidActivitySamples with every rejected sample, ordered by index.
+type ActivitySampleError struct {
+	Violations []ActivitySampleViolation
+}
+
+func (e *ActivitySampleError) Error() string {
+	return fmt.Sprintf("%s: %v", ErrInvalidActivitySamples, e.Violations)
+}
+
+func (e *ActivitySampleError) Is(target error) bool {
+	return target == ErrInvalidActivitySamples
+}
+
+// ErrorDetails is merged into the API error response.
+func (e *ActivitySampleError) ErrorDetails() map[string]any {
+	return map[string]any{"samples": e.Violations}
+}
+
+// ActivityDay is one local day of activity totals; days without samples are all zero.
+type ActivityDay struct {
+	Date             string  `json:"date"`
+	Steps            float64 `json:"steps"`
+	ActiveEnergyKcal float64 `json:"active_energy_kcal"`
+	DistanceMeters   float64 `json:"distance_meters"`
+	WorkoutMinutes   float64 `json:"workout_minutes"`
+}
+
+// ActivitySummary is a client's daily activity over an inclusive date range in their timezone.
+type ActivitySummary struct {
+	ClientProfileID uint          `json:"client_profile_id"`
+	Timezone        string        `json:"timezone"`
+	StartDate       string        `json:"start_date"`
+	EndDate         string        `json:"end_date"`
+	Days            []ActivityDay `json:"days"`
+}
+
+// ActivityService stores synced device activity and serves daily totals to the client and coach.
+type ActivityService struct {
+	repos *repositories.RepositoriesCollection
+}
+
+func NewActivityService(repos *repositories.RepositoriesCollection) *ActivityService {
+	return &ActivityService{repos: repos}
+}
+
+// ImportMySamples stores a batch of the calling client's activity samples. The batch is rejected as
+// a whole when any sample has an unknown unit, ends in the future, or overlaps another sample of the
+// same type and source; samples already stored are skipped, so a re-sync is a no-op.
+func (s *ActivityService) ImportMySamples(ctx context.Context, userID uint, input ImportActivitySamplesInput) (*ActivityImportResult, error) {
+	clientProfile, err := resolveMyClientProfile(ctx, s.repos, userID, input.ClientProfileID)
+	if err != nil {
+		return nil, err
+	}
+
+	samples, err := s.buildActivitySamples(ctx, clientProfile.ID, input.Samples, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	imported, err := s.repos.Progress.CreateActivitySamples(ctx, samples)
+	if err != nil {
+		return nil, err
+	}
+	return &ActivityImportResult{
+		Received:   len(input.Samples),
+		Imported:   imported,
+		Duplicates: int64(len(input.Samples)) - imported,
+	}, nil
+}
+
+// GetMyDailyActivity returns the calling client's daily totals. Dates are optional inclusive
+// YYYY-MM-DD bounds in the client's timezone, defaulting to the last 30 days.
+func (s *ActivityService) GetMyDailyActivity(ctx context.Context, userID uint, clientProfileID *uint, startRaw, endRaw string) (*ActivitySummary, error) {
+	clientProfile, err := resolveMyClientProfile(ctx, s.repos, userID, clientProfileID)
+	if err != nil {
+		return nil, err
+	}
+	return s.dailyActivity(ctx, clientProfile, startRaw, endRaw)
+}
+
+// GetClientDailyActivity returns daily totals for one of the calling coach's clients.
+func (s *ActivityService) GetClientDailyActivity(ctx context.Context, userID, clientProfileID uint, startRaw, endRaw string) (*ActivitySummary, error) {
+	clientProfile, err := getTeamClientProfile(ctx, s.repos, userID, clientProfileID)
+	if err != nil {
+		return nil, err
+	}
+	return s.dailyActivity(ctx, clientProfile, startRaw, endRaw)
+}
+
+func (s *ActivityService) dailyActivity(ctx context.Context, clientProfile *models.ClientProfile, startRaw, endRaw string) (*ActivitySummary, error) {
+	tz, err := s.repos.User.GetTimezone(ctx, clientProfile.UserID)
+	if err != nil {
+		return nil, err
+	}
+	loc := utils.ResolveLocation(tz)
+
+	startDate, endDate, err := parseActivityRange(startRaw, endRaw, time.Now().In(loc))
+	if err != nil {
+		return nil, err
+	}
+
+	days, err := loadActivityDays(ctx, s.repos.Progress, clientProfile.ID, loc, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	return &ActivitySummary{
+		ClientProfileID: clientProfile.ID,
+		Timezone:        loc.String(),
+		StartDate:       startDate.Format("2006-01-02"),
+		EndDate:         endDate.Format("2006-01-02"),
+		Days:            days,
+	}, nil
+}
+
+// buildActivitySamples converts the inputs to canonical units and checks them against each other and
+// against what is already stored. Exact re-sends of a stored sample are kept; the insert skips them.
+func (s *ActivityService) buildActivitySamples(ctx context.Context, clientProfileID uint, inputs []ActivitySampleInput, now time.Time) ([]models.ActivitySample, error) {
+	var violations []ActivitySampleViolation
+	samples := make([]models.ActivitySample, len(inputs))
+	var windowStart, windowEnd time.Time
+
+	for i, in := range inputs {
+		sample := models.ActivitySample{
+			ClientProfileID: clientProfileID,
+			Type:            in.Type,
+			StartTime:       in.StartTime.UTC(),
+			EndTime:         in.EndTime.UTC(),
+			Source:          strings.ToLower(strings.TrimSpace(in.Source)),
+		}
+		value, unit, ok := normalizeActivityValue(in.Type, *in.Value, in.Unit)
+		switch {
+		case !ok:
+			violations = append(violations, ActivitySampleViolation{Index: i, Reason: ActivityViolationInvalidUnit})
+		case sample.EndTime.Before(sample.StartTime):
+			violations = append(violations, ActivitySampleViolation{Index: i, Reason: ActivityViolationInvalidRange})
+		case sample.EndTime.After(now.Add(activityClockSkew)):
+			violations = append(violations, ActivitySampleViolation{Index: i, Reason: ActivityViolationInFuture})
+		}
+		sample.Value = value
+		sample.Unit = unit
+		samples[i] = sample
+
+		if windowStart.IsZero() || sample.StartTime.Before(windowStart) {
+			windowStart = sample.StartTime
+		}
+		if sample.EndTime.After(windowEnd) {
+			windowEnd = sample.EndTime
+		}
+	}
+	if len(violations) > 0 {
+		return nil, &ActivitySampleError{Violations: violations}
+	}
+
+	violations = append(violations, batchActivityOverlaps(samples)...)
+
+	// A zero-length sample still has to find a stored sample starting at the same instant
+	existing, err := s.repos.Progress.ListActivitySamplesBetween(ctx, clientProfileID, windowStart, windowEnd.Add(time.Nanosecond))
+	if err != nil {
+		return nil, err
+	}
+	for i, sample := range samples {
+		for _, stored := range existing {
+			if stored.Type != sample.Type || stored.Source != sample.Source || stored.StartTime.Equal(sample.StartTime) {
+				continue
+			}
+			if activitySpansOverlap(sample, stored) {
+				violations = append(violations, ActivitySampleViolation{Index: i, Reason: ActivityViolationOverlapsExisting})
+				break
+			}
+		}
+	}
+
+	if len(violations) > 0 {
+		sort.SliceStable(violations, func(a, b int) bool { return violations[a].Index < violations[b].Index })
+		return nil, &ActivitySampleError{Violations: violations}
+	}
+	return samples, nil
+}
+
+// batchActivityOverlaps reports samples that overlap an earlier-starting sample of the same type and
+// source in the batch. Samples sharing a start time are duplicates of one reading, not overlaps.
+func batchActivityOverlaps(samples []models.ActivitySample) []ActivitySampleViolation {
+	order := make([]int, len(samples))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		sa, sb := samples[order[a]], samples[order[b]]
+		if sa.Type != sb.Type {
+			return sa.Type < sb.Type
+		}
+		if sa.Source != sb.Source {
+			return sa.Source < sb.Source
+		}
+		return sa.StartTime.Before(sb.StartTime)
+	})
+
+	var violations []ActivitySampleViolation
+	for k := 1; k < len(order); k++ {
+		prev, cur := samples[order[k-1]], samples[order[k]]
+		if prev.Type != cur.Type || prev.Source != cur.Source || prev.StartTime.Equal(cur.StartTime) {
+			continue
+		}
+		if activitySpansOverlap(prev, cur) {
+			violations = append(violations, ActivitySampleViolation{Index: order[k], Reason: ActivityViolationOverlapsBatch})
+		}
+	}
+	return violations
+}
+
+// activitySpansOverlap reports whether two [start, end) spans share any time.
+func activitySpansOverlap(a, b models.ActivitySample) bool {
+	return a.StartTime.Before(b.EndTime) && b.StartTime.Before(a.EndTime)
+}
+
+// normalizeActivityValue converts value to the unit stored for its type: count, kcal, m or min.
+func normalizeActivityValue(activityType string, value float64, unit string) (float64, string, bool) {
+	unit = strings.ToLower(strings.TrimSpace(unit))
+	switch activityType {
+	case ActivityTypeSteps:
+		if unit == "count" || unit == "steps" {
+			return math.Round(value), "count", true
+		}
+	case ActivityTypeActiveEnergy:
+		switch unit {
+		case "kcal", "cal", "kilocalories":
+			return value, "kcal", true
+		case "kj", "kilojoules":
+			return math.Round(value*kcalPerKilojoule*100) / 100, "kcal", true
+		}
+	case ActivityTypeDistance:
+		if meters, ok := units.ConvertDistance(value, unit, units.Meters); ok {
+			return meters, units.Meters, true
+		}
+	case ActivityTypeWorkout:
+		switch unit {
+		case "min", "mins", "minutes":
+			return value, "min", true
+		case "s", "sec", "seconds":
+			return math.Round(value/60*100) / 100, "min", true
+		case "h", "hr", "hours":
+			return value * 60, "min", true
+		}
+	}
+	return 0, "", false
+}
+
+// parseActivityRange parses inclusive YYYY-MM-DD bounds, defaulting to the 30 days ending today.
+// Both dates are returned as midnight in today's location.
+func parseActivityRange(startRaw, endRaw string, today time.Time) (time.Time, time.Time, error) {
+	loc := today.Location()
+	endDate := time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, loc)
+	if strings.TrimSpace(endRaw) != "" {
+		parsed, err := parseDateOnly(endRaw)
+		if err != nil {
+			return time.Time{}, time.Time{}, ErrInvalidDateFormat
+		}
+		endDate = time.Date(parsed.Year(), parsed.Month(), parsed.Day(), 0, 0, 0, 0, loc)
+	}
+
+	startDate := endDate.AddDate(0, 0, -(defaultActivityRangeDays - 1))
+	if strings.TrimSpace(startRaw) != "" {
+		parsed, err := parseDateOnly(startRaw)
+		if err != nil {
+			return time.Time{}, time.Time{}, ErrInvalidDateFormat
+		}
+		startDate = time.Date(parsed.Year(), parsed.Month(), parsed.Day(), 0, 0, 0, 0, loc)
+	}
+
+	if endDate.Before(startDate) || !startDate.AddDate(0, 0, maxActivityRangeDays).After(endDate) {
+		return time.Time{}, time.Time{}, ErrInvalidDateRange
+	}
+	return startDate, endDate, nil
+}
+
+// loadActivityDays returns one ActivityDay per date from startDate to endDate inclusive, both
+// midnight in loc.
+func loadActivityDays(ctx context.Context, progressRepo *repositories.ProgressRepository, clientProfileID uint, loc *time.Location, startDate, endDate time.Time) ([]ActivityDay, error) {
+	totals, err := progressRepo.ListActivityDailyTotals(ctx, clientProfileID, loc.String(), startDate, endDate.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+
+	byDate := make(map[string]*ActivityDay)
+	var days []ActivityDay
+	for d := startDate; !d.After(endDate); d = d.AddDate(0, 0, 1) {
+		days = append(days, ActivityDay{Date: d.Format("2006-01-02")})
+	}
+	for i := range days {
+		byDate[days[i].Date] = &days[i]
+	}
+
+	for _, total := range totals {
+		day, ok := byDate[total.Date]
+		if !ok {
+			continue
+		}
+		switch total.Type {
+		case ActivityTypeSteps:
+			day.Steps = total.Total
+		case ActivityTypeActiveEnergy:
+			day.ActiveEnergyKcal = math.Round(total.Total*10) / 10
+		case ActivityTypeDistance:
+			day.DistanceMeters = math.Round(total.Total)
+		case ActivityTypeWorkout:
+			day.WorkoutMinutes = math.Round(total.Total*10) / 10
+		}
+	}
+	return days, nil
+}
@@ -0,0 +1,778 @@
+package services
+
+import (
+	"chalk-api/pkg/events"
+	"chalk-api/pkg/models"
+	"chalk-api/pkg/repositories"
+	"chalk-api/pkg/stores"
+	"chalk-api/pkg/utils"
+	"context"
+	"errors"
+	"log/slog"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrAdminRequired            = errors.New("admin access required")
+	ErrTransferSameCoach        = errors.New("client already belongs to the target coach")
+	ErrTransferTargetConnected  = errors.New("client already has a profile with the target coach")
+	ErrClientAlreadyTransferred = errors.New("client profile was already transferred")
+	ErrFeatureFlagNotFound      = errors.New("feature flag not found")
+	ErrFeatureFlagKeyTaken      = errors.New("feature flag key already in use")
+	ErrInvalidFeatureFlagKey    = errors.New("invalid feature flag key")
+	ErrInvalidFeatureFlagRules  = errors.New("invalid feature flag rules")
+	ErrWebhookEntryNotFound     = errors.New("webhook inbox entry not found")
+	ErrInvalidWebhookStatus     = errors.New("invalid webhook inbox status")
+	ErrWebhookProviderNoReplay  = errors.New("webhook provider does not support replay")
+)
+
+const (
+	TransferConversationMove  = "move"
+	TransferConversationClose = "close"
+
+	defaultTransferReason = "Client transferred to another coach"
+
+	defaultUserActivityLimit = 100
+	maxUserActivityLimit     = 500
+
+	coachStatsBatchSize = 100
+
+	maxFeatureFlagKeyLength = 100
+
+	defaultWebhookInboxLimit = 50
+	maxWebhookInboxLimit     = 200
+
+	defaultReferralListLimit = 50
+	maxReferralListLimit     = 200
+
+	pushStatsWindowDays = 7
+)
+
+// featureFlagKeyPattern accepts lowercase keys like "group_sessions" or "booking.public_page"
+var featureFlagKeyPattern = regexp.MustCompile(`^[a-z0-9]+(?:[_.-][a-z0-9]+)*$`)
+
+// TransferClientInput moves a client to TargetCoachID. Conversation is "move" to hand the existing
+// thread to the new coach, or "close" (the default) to leave it read-only with the old coach.
+type TransferClientInput struct {
+	TargetCoachID uint    `json:"target_coach_id" binding:"required"`
+	Conversation  string  `json:"conversation" binding:"omitempty,oneof=move close"`
+	Reason        *string `json:"reason" binding:"omitempty,max=500"`
+}
+
+// CreateFeatureFlagInput defines a new flag. Rules apply only while Enabled is false.
+type CreateFeatureFlagInput struct {
+	Key         string            `json:"key" binding:"required,max=100"`
+	Description *string           `json:"description" binding:"omitempty,max=500"`
+	Enabled     bool              `json:"enabled"`
+	Rules       *models.FlagRules `json:"rules"`
+}
+
+// UpdateFeatureFlagInput changes the fields that are set; Rules replaces the stored rules.
+type UpdateFeatureFlagInput struct {
+	Description *string           `json:"description" binding:"omitempty,max=500"`
+	Enabled     *bool             `json:"enabled"`
+	Rules       *models.FlagRules `json:"rules"`
+}
+
+type TransferClientResult struct {
+	OldClientProfile  *models.ClientProfile `json:"old_client_profile"`
+	NewClientProfile  *models.ClientProfile `json:"new_client_profile"`
+	ConversationID    *uint                 `json:"conversation_id,omitempty"`
+	ConversationMoved bool                  `json:"conversation_moved"`
+	CancelledSessions int64                 `json:"cancelled_sessions"`
+	SkippedWorkouts   int64                 `json:"skipped_workouts"`
+}
+
+// AdminService holds platform-operator actions. Every request-facing method checks the caller's is_admin
+// flag; RecomputeAllCoachStats is for the background job.
+type AdminService struct {
+	repos      *repositories.RepositoriesCollection
+	userRepo   *repositories.UserRepository
+	coachRepo  *repositories.CoachRepository
+	clientRepo *repositories.ClientRepository
+	events     *events.Publisher
+	coachStore *stores.CoachStore
+	cacheStats *stores.CacheInspector
+	// subscriptions replays stored RevenueCat webhooks
+	subscriptions *SubscriptionService
+	// exerciseStore is cleared after bulk exercise edits
+	exerciseStore *stores.ExerciseStore
+	// availabilityStore drops the old coach's cached bookable slots when a transfer cancels sessions
+	availabilityStore *stores.AvailabilityStore
+	// bulkEditKey signs bulk exercise edit confirmation tokens; nil when no JWT key is configured
+	bulkEditKey []byte
+}
+
+func NewAdminService(
+	repos *repositories.RepositoriesCollection,
+	eventsPublisher *events.Publisher,
+	coachStore *stores.CoachStore,
+	availabilityStore *stores.AvailabilityStore,
+	cacheStats *stores.CacheInspector,
+	subscriptionService *SubscriptionService,
+	exerciseStore *stores.ExerciseStore,
+	bulkEditKey []byte,
+) *AdminService {
+	return &AdminService{
+		repos:             repos,
+		userRepo:          repos.User,
+		coachRepo:         repos.Coach,
+		clientRepo:        repos.Client,
+		events:            eventsPublisher,
+		coachStore:        coachStore,
+		availabilityStore: availabilityStore,
+		cacheStats:        cacheStats,
+		subscriptions:     subscriptionService,
+		exerciseStore:     exerciseStore,
+		bulkEditKey:       bulkEditKey,
+	}
+}
+
+// TransferClient archives the client's profile with their current coach and creates a new one under
+// the target coach, copying goals, program details, tags and the intake form. Upcoming sessions and
+// workouts with the old coach are cancelled; history stays on the archived profile.
+func (s *AdminService) TransferClient(ctx context.Context, userID, clientProfileID uint, input TransferClientInput) (*TransferClientResult, error) {
+	if err := s.requireAdmin(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	oldProfile, err := s.clientRepo.GetByID(ctx, clientProfileID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrClientProfileNotFound
+		}
+		return nil, err
+	}
+	if oldProfile.TransferredToID != nil {
+		return nil, ErrClientAlreadyTransferred
+	}
+	if oldProfile.CoachID == input.TargetCoachID {
+		return nil, ErrTransferSameCoach
+	}
+
+	fromCoach, err := s.coachRepo.GetByID(ctx, oldProfile.CoachID)
+	if err != nil {
+		return nil, err
+	}
+	toCoach, err := s.coachRepo.GetByID(ctx, input.TargetCoachID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCoachProfileNotFound
+		}
+		return nil, err
+	}
+	if _, err := s.clientRepo.GetByUserAndCoach(ctx, oldProfile.UserID, toCoach.ID); err == nil {
+		return nil, ErrTransferTargetConnected
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	reason := defaultTransferReason
+	if input.Reason != nil && strings.TrimSpace(*input.Reason) != "" {
+		reason = strings.TrimSpace(*input.Reason)
+	}
+	moveConversation := input.Conversation == TransferConversationMove
+
+	timezone := ""
+	if oldProfile.User.Profile != nil {
+		timezone = oldProfile.User.Profile.Timezone
+	}
+	now := time.Now().UTC()
+	today := now.In(utils.ResolveLocation(timezone)).Format("2006-01-02")
+
+	result := &TransferClientResult{}
+	var newProfileID uint
+
+	err = s.repos.WithTransaction(ctx, func(tx *gorm.DB, txRepos *repositories.RepositoriesCollection) error {
+		newProfile := &models.ClientProfile{
+			UserID:            oldProfile.UserID,
+			CoachID:           toCoach.ID,
+			Status:            "active",
+			Goals:             oldProfile.Goals,
+			ProgramType:       oldProfile.ProgramType,
+			SessionsPerWeek:   oldProfile.SessionsPerWeek,
+			Tags:              oldProfile.Tags,
+			LastActivityAt:    oldProfile.LastActivityAt,
+			TransferredFromID: &oldProfile.ID,
+			JoinedAt:          &now,
+		}
+		if err := txRepos.Client.Create(ctx, newProfile); err != nil {
+			return err
+		}
+		newProfileID = newProfile.ID
+
+		if oldProfile.IntakeForm != nil {
+			intake := *oldProfile.IntakeForm
+			intake.ID = 0
+			intake.ClientID = newProfile.ID
+			intake.CreatedAt = time.Time{}
+			intake.UpdatedAt = time.Time{}
+			if err := txRepos.Client.CreateIntakeForm(ctx, &intake); err != nil {
+				return err
+			}
+		}
+
+		if err := txRepos.Client.MarkTransferred(ctx, oldProfile.ID, newProfile.ID, now); err != nil {
+			return err
+		}
+
+		if oldProfile.Status == "active" {
+			if err := txRepos.Coach.IncrementStat(ctx, fromCoach.ID, "active_clients", -1); err != nil {
+				return err
+			}
+		}
+		if err := txRepos.Coach.IncrementStat(ctx, toCoach.ID, "active_clients", 1); err != nil {
+			return err
+		}
+		if err := txRepos.Coach.IncrementStat(ctx, toCoach.ID, "total_clients_all_time", 1); err != nil {
+			return err
+		}
+
+		cancelled, err := txRepos.Session.CancelUpcomingForClient(ctx, fromCoach.ID, oldProfile.ID, now, "admin", reason)
+		if err != nil {
+			return err
+		}
+		result.CancelledSessions = cancelled
+
+		skipped, err := txRepos.Workout.SkipUpcomingForClient(ctx, oldProfile.ID, today, reason)
+		if err != nil {
+			return err
+		}
+		result.SkippedWorkouts = skipped
+
+		conversation, err := txRepos.Message.FindConversation(ctx, fromCoach.ID, oldProfile.ID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if conversation != nil {
+			if moveConversation {
+				err = txRepos.Message.MoveConversation(ctx, conversation.ID, toCoach.ID, newProfile.ID)
+			} else {
+				err = txRepos.Message.CloseConversation(ctx, conversation.ID, now)
+			}
+			if err != nil {
+				return err
+			}
+			result.ConversationID = &conversation.ID
+			result.ConversationMoved = moveConversation
+		}
+
+		if s.events == nil {
+			return nil
+		}
+		id := strconv.FormatUint(uint64(oldProfile.ID), 10)
+		if err := s.events.PublishInTx(
+			ctx,
+			tx,
+			events.EventTypeClientStatusChanged,
+			"client_profile",
+			id,
+			events.BuildIdempotencyKey(events.EventTypeClientStatusChanged, id, "transferred"),
+			events.ClientStatusChangedPayload{
+				ClientID:       oldProfile.ID,
+				CoachID:        fromCoach.ID,
+				Status:         "archived",
+				PreviousStatus: oldProfile.Status,
+			},
+		); err != nil {
+			return err
+		}
+		return s.events.PublishInTx(
+			ctx,
+			tx,
+			events.EventTypeClientTransferred,
+			"client_profile",
+			id,
+			events.BuildIdempotencyKey(events.EventTypeClientTransferred, id),
+			events.ClientTransferredPayload{
+				ClientUserID:       oldProfile.UserID,
+				OldClientProfileID: oldProfile.ID,
+				NewClientProfileID: newProfile.ID,
+				FromCoachID:        fromCoach.ID,
+				FromCoachUserID:    fromCoach.UserID,
+				ToCoachID:          toCoach.ID,
+				ToCoachUserID:      toCoach.UserID,
+				ClientName:         digestClientName(*oldProfile),
+				FromCoachName:      s.userDisplayName(ctx, fromCoach.UserID),
+				ToCoachName:        s.userDisplayName(ctx, toCoach.UserID),
+				ConversationMoved:  result.ConversationMoved,
+				CancelledSessions:  result.CancelledSessions,
+				SkippedWorkouts:    result.SkippedWorkouts,
+			},
+		)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if result.CancelledSessions > 0 {
+		s.coachStore.InvalidateSessionSummaries(fromCoach.ID)
+		s.availabilityStore.InvalidateBookableSlots(fromCoach.ID)
+	}
+
+	if result.OldClientProfile, err = s.clientRepo.GetByID(ctx, oldProfile.ID); err != nil {
+		return nil, err
+	}
+	if result.NewClientProfile, err = s.clientRepo.GetByID(ctx, newProfileID); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetUserActivity returns the user's most recent API requests recorded by the request analytics
+// sink, newest first. It is empty when the sink is disabled.
+func (s *AdminService) GetUserActivity(ctx context.Context, adminUserID, userID uint, limit int) ([]models.RequestEvent, error) {
+	if err := s.requireAdmin(ctx, adminUserID); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.userRepo.GetByID(ctx, userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	if limit <= 0 {
+		limit = defaultUserActivityLimit
+	}
+	if limit > maxUserActivityLimit {
+		limit = maxUserActivityLimit
+	}
+
+	return s.repos.RequestEvent.ListByUser(ctx, userID, limit)
+}
+
+// CoachStatsRecompute is one coach's incremented counters before and after they were rebuilt.
+// Drifted names the counters whose stored value was wrong.
+type CoachStatsRecompute struct {
+	CoachID  uint                           `json:"coach_id"`
+	Previous repositories.DerivedCoachStats `json:"previous"`
+	Current  repositories.DerivedCoachStats `json:"current"`
+	Drifted  []string                       `json:"drifted"`
+}
+
+// CoachStatsSweep summarizes a RecomputeAllCoachStats run.
+type CoachStatsSweep struct {
+	Coaches int
+	Drifted int // coaches with at least one wrong counter
+}
+
+// RecomputeCoachStats rebuilds one coach's client, workout and session counters from the source
+// tables, repairing any drift left by increments.
+func (s *AdminService) RecomputeCoachStats(ctx context.Context, userID, coachID uint) (*CoachStatsRecompute, error) {
+	if err := s.requireAdmin(ctx, userID); err != nil {
+		return nil, err
+	}
+	if _, err := s.coachRepo.GetByID(ctx, coachID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCoachProfileNotFound
+		}
+		return nil, err
+	}
+
+	result, err := s.recomputeCoachStats(ctx, coachID)
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Drifted) > 0 {
+		slog.Warn("Coach stats drift repaired", "coach_id", coachID, "fields", result.Drifted, "requested_by", userID)
+	}
+	return result, nil
+}
+
+// RecomputeAllCoachStats recomputes every coach's counters in batches of coachStatsBatchSize, logging
+// each coach whose stored values had drifted. A coach that fails is logged and skipped so one bad row
+// doesn't stop the sweep; the first such error is returned at the end.
+func (s *AdminService) RecomputeAllCoachStats(ctx context.Context) (CoachStatsSweep, error) {
+	var sweep CoachStatsSweep
+	var firstErr error
+	var afterID uint
+	for {
+		ids, err := s.coachRepo.ListCoachIDs(ctx, afterID, coachStatsBatchSize)
+		if err != nil {
+			return sweep, err
+		}
+		for _, coachID := range ids {
+			result, err := s.recomputeCoachStats(ctx, coachID)
+			if err != nil {
+				if ctx.Err() != nil {
+					return sweep, ctx.Err()
+				}
+				slog.Error("Failed to recompute coach stats", "coach_id", coachID, "error", err)
+				if firstErr == nil {
+					firstErr = err
+				}
+				continue
+			}
+			sweep.Coaches++
+			if len(result.Drifted) > 0 {
+				sweep.Drifted++
+				slog.Warn("Coach stats drift repaired", "coach_id", coachID, "fields", result.Drifted,
+					"previous", result.Previous, "current", result.Current)
+			}
+		}
+		if len(ids) < coachStatsBatchSize {
+			return sweep, firstErr
+		}
+		afterID = ids[len(ids)-1]
+	}
+}
+
+func (s *AdminService) recomputeCoachStats(ctx context.Context, coachID uint) (*CoachStatsRecompute, error) {
+	previous, current, err := s.coachRepo.RecomputeStats(ctx, coachID)
+	if err != nil {
+		return nil, err
+	}
+	if s.coachStore != nil {
+		s.coachStore.InvalidateStats(coachID)
+	}
+	return &CoachStatsRecompute{
+		CoachID:  coachID,
+		Previous: previous,
+		Current:  current,
+		Drifted:  driftedCoachStats(previous, current),
+	}, nil
+}
+
+func driftedCoachStats(previous, current repositories.DerivedCoachStats) []string {
+	drifted := []string{}
+	for _, field := range []struct {
+		name           string
+		stored, actual int
+	}{
+		{"active_clients", previous.ActiveClients, current.ActiveClients},
+		{"total_clients_all_time", previous.TotalClientsAllTime, current.TotalClientsAllTime},
+		{"workouts_assigned_total", previous.WorkoutsAssignedTotal, current.WorkoutsAssignedTotal},
+		{"workouts_completed_total", previous.WorkoutsCompletedTotal, current.WorkoutsCompletedTotal},
+		{"sessions_completed_total", previous.SessionsCompletedTotal, current.SessionsCompletedTotal},
+	} {
+		if field.stored != field.actual {
+			drifted = append(drifted, field.name)
+		}
+	}
+	return drifted
+}
+
+// ListFeatureFlags returns every feature flag ordered by key.
+func (s *AdminService) ListFeatureFlags(ctx context.Context, userID uint) ([]models.FeatureFlag, error) {
+	if err := s.requireAdmin(ctx, userID); err != nil {
+		return nil, err
+	}
+	return s.repos.FeatureFlag.List(ctx)
+}
+
+// CreateFeatureFlag adds a flag. Keys can't change afterwards since the apps gate UI on them.
+func (s *AdminService) CreateFeatureFlag(ctx context.Context, userID uint, input CreateFeatureFlagInput) (*models.FeatureFlag, error) {
+	if err := s.requireAdmin(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	key := strings.ToLower(strings.TrimSpace(input.Key))
+	if len(key) > maxFeatureFlagKeyLength || !featureFlagKeyPattern.MatchString(key) {
+		return nil, ErrInvalidFeatureFlagKey
+	}
+	flag := &models.FeatureFlag{
+		Key:         key,
+		Description: trimPtr(input.Description),
+		Enabled:     input.Enabled,
+	}
+	if input.Rules != nil {
+		flag.Rules = *input.Rules
+	}
+	if err := validateFlagRules(flag.Rules); err != nil {
+		return nil, err
+	}
+
+	if err := s.repos.FeatureFlag.Create(ctx, flag); err != nil {
+		if errors.Is(err, repositories.ErrFeatureFlagKeyTaken) {
+			return nil, ErrFeatureFlagKeyTaken
+		}
+		return nil, err
+	}
+	slog.Info("Feature flag created", "flag_id", flag.ID, "key", flag.Key, "admin_user_id", userID)
+	return flag, nil
+}
+
+// UpdateFeatureFlag changes a flag's description, global switch or rules. Rules are replaced whole.
+func (s *AdminService) UpdateFeatureFlag(ctx context.Context, userID, flagID uint, input UpdateFeatureFlagInput) (*models.FeatureFlag, error) {
+	if err := s.requireAdmin(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	flag, err := s.repos.FeatureFlag.GetByID(ctx, flagID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrFeatureFlagNotFound
+		}
+		return nil, err
+	}
+	if input.Description != nil {
+		flag.Description = trimPtr(input.Description)
+	}
+	if input.Enabled != nil {
+		flag.Enabled = *input.Enabled
+	}
+	if input.Rules != nil {
+		if err := validateFlagRules(*input.Rules); err != nil {
+			return nil, err
+		}
+		flag.Rules = *input.Rules
+	}
+
+	if err := s.repos.FeatureFlag.Update(ctx, flag); err != nil {
+		return nil, err
+	}
+	slog.Info("Feature flag updated", "flag_id", flag.ID, "key", flag.Key, "enabled", flag.Enabled, "admin_user_id", userID)
+	return flag, nil
+}
+
+// DeleteFeatureFlag removes a flag; it evaluates as off once cached results expire.
+func (s *AdminService) DeleteFeatureFlag(ctx context.Context, userID, flagID uint) error {
+	if err := s.requireAdmin(ctx, userID); err != nil {
+		return err
+	}
+	if err := s.repos.FeatureFlag.Delete(ctx, flagID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrFeatureFlagNotFound
+		}
+		return err
+	}
+	slog.Info("Feature flag deleted", "flag_id", flagID, "admin_user_id", userID)
+	return nil
+}
+
+func validateFlagRules(rules models.FlagRules) error {
+	if rules.Percentage < 0 || rules.Percentage > 100 {
+		return ErrInvalidFeatureFlagRules
+	}
+	return nil
+}
+
+// ListWebhookInbox returns stored webhook deliveries newest first, optionally filtered by provider and
+// status, with the total number matching.
+func (s *AdminService) ListWebhookInbox(ctx context.Context, userID uint, provider, status string, limit, offset int) ([]models.WebhookInboxEntry, int64, error) {
+	if err := s.requireAdmin(ctx, userID); err != nil {
+		return nil, 0, err
+	}
+
+	switch status {
+	case "", models.WebhookInboxStatusPending, models.WebhookInboxStatusProcessed,
+		models.WebhookInboxStatusFailed, models.WebhookInboxStatusIgnored:
+	default:
+		return nil, 0, ErrInvalidWebhookStatus
+	}
+	if limit <= 0 {
+		limit = defaultWebhookInboxLimit
+	}
+	if limit > maxWebhookInboxLimit {
+		limit = maxWebhookInboxLimit
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	return s.repos.WebhookInbox.List(ctx, strings.ToLower(strings.TrimSpace(provider)), status, limit, offset)
+}
+
+// ReprocessWebhook re-runs a stored delivery through its provider's webhook handling and returns the
+// entry with the outcome recorded. Events the subscription already has on record are skipped, so
+// replaying one that was applied is harmless.
+func (s *AdminService) ReprocessWebhook(ctx context.Context, userID, entryID uint) (*models.WebhookInboxEntry, error) {
+	if err := s.requireAdmin(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	entry, err := s.repos.WebhookInbox.GetByID(ctx, entryID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWebhookEntryNotFound
+		}
+		return nil, err
+	}
+
+	switch entry.Provider {
+	case models.WebhookProviderRevenueCat:
+		err = s.subscriptions.ReplayRevenueCatWebhook(ctx, entry)
+	default:
+		return nil, ErrWebhookProviderNoReplay
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.repos.WebhookInbox.GetByID(ctx, entry.ID)
+}
+
+// ListReferrals returns completed coach referrals newest first, optionally only those made by
+// referrerCoachID, with the total number matching.
+func (s *AdminService) ListReferrals(ctx context.Context, userID, referrerCoachID uint, limit, offset int) ([]models.Referral, int64, error) {
+	if err := s.requireAdmin(ctx, userID); err != nil {
+		return nil, 0, err
+	}
+
+	if limit <= 0 {
+		limit = defaultReferralListLimit
+	}
+	if limit > maxReferralListLimit {
+		limit = maxReferralListLimit
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return s.coachRepo.ListReferrals(ctx, referrerCoachID, limit, offset)
+}
+
+// GetCacheStats scans Redis for per-keyspace key counts and memory estimates. The scan walks every
+// key with SCAN, so it is never served from the copy the metrics endpoint reuses.
+func (s *AdminService) GetCacheStats(ctx context.Context, userID uint) (*stores.CacheStats, error) {
+	if err := s.requireAdmin(ctx, userID); err != nil {
+		return nil, err
+	}
+	return s.cacheStats.Stats(ctx, 0)
+}
+
+// PushTokenCount is the number of device tokens for a platform, or a platform and app version.
+type PushTokenCount struct {
+	Platform   string  `json:"platform"`
+	AppVersion *string `json:"app_version,omitempty"`
+	Active     int64   `json:"active"`
+	Inactive   int64   `json:"inactive"`
+}
+
+// PushDeliveryStats counts pushes by outcome. SuccessRate is delivered / (delivered + failed), so
+// pending pushes don't drag it down; it is null when nothing has resolved yet.
+type PushDeliveryStats struct {
+	Platform    string   `json:"platform,omitempty"`
+	Sent        int64    `json:"sent"`
+	Delivered   int64    `json:"delivered"`
+	Failed      int64    `json:"failed"`
+	Pending     int64    `json:"pending"`
+	SuccessRate *float64 `json:"success_rate"`
+}
+
+type PushErrorCount struct {
+	Code  string `json:"code"`
+	Count int64  `json:"count"`
+}
+
+// PushStats summarizes registered device tokens and the delivery of pushes sent in the last
+// WindowDays, from the stored Expo tickets and receipts.
+type PushStats struct {
+	GeneratedAt        time.Time           `json:"generated_at"`
+	ActiveTokens       int64               `json:"active_tokens"`
+	InactiveTokens     int64               `json:"inactive_tokens"`
+	TokensByPlatform   []PushTokenCount    `json:"tokens_by_platform"`
+	TokensByVersion    []PushTokenCount    `json:"tokens_by_app_version"`
+	WindowDays         int                 `json:"window_days"`
+	Delivery           PushDeliveryStats   `json:"delivery"`
+	DeliveryByPlatform []PushDeliveryStats `json:"delivery_by_platform"`
+	Errors             []PushErrorCount    `json:"errors"`
+}
+
+// GetPushStats reports device token counts and 7-day push delivery rates.
+func (s *AdminService) GetPushStats(ctx context.Context, userID uint) (*PushStats, error) {
+	if err := s.requireAdmin(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	tokenCounts, err := s.userRepo.CountDeviceTokens(ctx)
+	if err != nil {
+		return nil, err
+	}
+	deliveries, err := s.repos.Notification.CountPushDeliveries(ctx, now.AddDate(0, 0, -pushStatsWindowDays))
+	if err != nil {
+		return nil, err
+	}
+	errorCounts, err := s.repos.Notification.CountPushErrors(ctx, now.AddDate(0, 0, -pushStatsWindowDays))
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &PushStats{
+		GeneratedAt:        now,
+		TokensByPlatform:   []PushTokenCount{},
+		TokensByVersion:    make([]PushTokenCount, 0, len(tokenCounts)),
+		WindowDays:         pushStatsWindowDays,
+		DeliveryByPlatform: make([]PushDeliveryStats, 0, len(deliveries)),
+		Errors:             make([]PushErrorCount, 0, len(errorCounts)),
+	}
+
+	// Rows arrive ordered by platform, so each platform's versions are contiguous
+	for _, count := range tokenCounts {
+		stats.ActiveTokens += count.Active
+		stats.InactiveTokens += count.Inactive
+		stats.TokensByVersion = append(stats.TokensByVersion, PushTokenCount{
+			Platform:   count.Platform,
+			AppVersion: count.AppVersion,
+			Active:     count.Active,
+			Inactive:   count.Inactive,
+		})
+		last := len(stats.TokensByPlatform) - 1
+		if last < 0 || stats.TokensByPlatform[last].Platform != count.Platform {
+			stats.TokensByPlatform = append(stats.TokensByPlatform, PushTokenCount{Platform: count.Platform})
+			last++
+		}
+		stats.TokensByPlatform[last].Active += count.Active
+		stats.TokensByPlatform[last].Inactive += count.Inactive
+	}
+
+	for _, count := range deliveries {
+		platform := PushDeliveryStats{
+			Platform:  count.Platform,
+			Sent:      count.Sent,
+			Delivered: count.Delivered,
+			Failed:    count.Failed,
+			Pending:   count.Pending,
+		}
+		platform.SuccessRate = pushSuccessRate(platform.Delivered, platform.Failed)
+		stats.DeliveryByPlatform = append(stats.DeliveryByPlatform, platform)
+
+		stats.Delivery.Sent += count.Sent
+		stats.Delivery.Delivered += count.Delivered
+		stats.Delivery.Failed += count.Failed
+		stats.Delivery.Pending += count.Pending
+	}
+	stats.Delivery.SuccessRate = pushSuccessRate(stats.Delivery.Delivered, stats.Delivery.Failed)
+
+	for _, count := range errorCounts {
+		stats.Errors = append(stats.Errors, PushErrorCount{Code: count.Code, Count: count.Count})
+	}
+	return stats, nil
+}
+
+func pushSuccessRate(delivered, failed int64) *float64 {
+	if delivered+failed == 0 {
+		return nil
+	}
+	rate := math.Round(float64(delivered)/float64(delivered+failed)*10000) / 10000
+	return &rate
+}
+
+func (s *AdminService) requireAdmin(ctx context.Context, userID uint) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrAdminRequired
+		}
+		return err
+	}
+	if !user.IsAdmin {
+		return ErrAdminRequired
+	}
+	return nil
+}
+
+// userDisplayName returns "First Last" for notifications, or "" when the user has no profile.
+func (s *AdminService) userDisplayName(ctx context.Context, userID uint) string {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil || user.Profile == nil {
+		return ""
+	}
+	return strings.TrimSpace(user.Profile.FirstName + " " + user.Profile.LastName)
+}
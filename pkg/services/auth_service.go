@@ -1,6 +1,10 @@
 package services
 
 import (
+	"chalk-api/pkg/config"
+	"chalk-api/pkg/events"
+	"chalk-api/pkg/external/geoip"
+	"chalk-api/pkg/i18n"
 	"chalk-api/pkg/models"
 	"chalk-api/pkg/repositories"
 	"context"
@@ -10,6 +14,7 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"log/slog"
 	"strconv"
 	"strings"
 	"time"
@@ -24,6 +29,7 @@ var (
 	ErrEmailAlreadyExists = errors.New("email already exists")
 	ErrUserDisabled       = errors.New("user account is inactive or banned")
 	ErrInvalidRefresh     = errors.New("invalid refresh token")
+	ErrRefreshExpired     = errors.New("session has reached its maximum lifetime, log in again")
 )
 
 type RegisterInput struct {
@@ -33,15 +39,23 @@ type RegisterInput struct {
 	LastName  string  `json:"last_name" binding:"required"`
 	Phone     *string `json:"phone"`
 	Timezone  string  `json:"timezone"`
+	Locale    string  `json:"locale"` // e.g. "es" or the device's "es-MX"; unsupported languages fall back to English
+	// Invite the user opened before signing up; accepted right after the account is created
+	InviteCode *string `json:"invite_code"`
+	DeviceID   string  `json:"device_id"` // or the X-Device-ID header
 }
 
 type LoginInput struct {
 	Email    string `json:"email" binding:"required,email"`
 	Password string `json:"password" binding:"required"`
+	// Stable per-install ID from the app (or the X-Device-ID header); logging in again on the same
+	// device replaces that device's session instead of adding one
+	DeviceID string `json:"device_id"`
 }
 
 type RefreshInput struct {
 	RefreshToken string `json:"refresh_token" binding:"required"`
+	DeviceID     string `json:"device_id"` // defaults to the device the token was issued to
 }
 
 type LogoutInput struct {
@@ -50,11 +64,79 @@ type LogoutInput struct {
 }
 
 type AuthResult struct {
-	AccessToken  string       `json:"access_token"`
-	RefreshToken string       `json:"refresh_token"`
-	TokenType    string       `json:"token_type"`
-	ExpiresAt    time.Time    `json:"expires_at"`
-	User         *models.User `json:"user"`
+	AccessToken  string    `json:"access_token"`
+	RefreshToken string    `json:"refresh_token"`
+	TokenType    string    `json:"token_type"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	// When the refresh token stops working; clients should re-authenticate before then
+	RefreshExpiresAt time.Time    `json:"refresh_expires_at"`
+	User             *models.User `json:"user"`
+
+	// Set on registration with an invite code: the connection made, or warnings when the code could not be used
+	Invite   *AcceptInviteResult `json:"invite,omitempty"`
+	Warnings []string            `json:"warnings,omitempty"`
+}
+
+// Warnings returned by Register when the invite code does not connect the new user. The account is
+// still created; the app can send the user to the invite screen to try another code.
+const (
+	RegisterWarningInviteInvalid   = "invite_code_invalid"
+	RegisterWarningInviteExhausted = "invite_code_exhausted"
+	RegisterWarningInviteFailed    = "invite_code_not_applied"
+)
+
+// inviteAcceptor connects a user to a coach through an invite code; CoachService implements it.
+type inviteAcceptor interface {
+	AcceptInvite(ctx context.Context, userID uint, input AcceptInviteInput) (*AcceptInviteResult, error)
+}
+
+// TokenLifetimes controls how long issued tokens last. Refresh is a sliding window renewed on each
+// refresh; FamilyMax is the absolute limit counted from the login that started the family.
+type TokenLifetimes struct {
+	Access    time.Duration
+	Refresh   time.Duration
+	FamilyMax time.Duration
+}
+
+// TokenLifetimesFromConfig reads token lifetimes from the environment, falling back to 15 minutes,
+// 30 days and 90 days. The deprecated JWT_EXPIRATION_HOURS still overrides the access lifetime.
+func TokenLifetimesFromConfig(cfg config.Environment) TokenLifetimes {
+	lifetimes := TokenLifetimes{
+		Access:    15 * time.Minute,
+		Refresh:   30 * 24 * time.Hour,
+		FamilyMax: 90 * 24 * time.Hour,
+	}
+	if cfg.AccessTokenTTLMinutes > 0 {
+		lifetimes.Access = time.Duration(cfg.AccessTokenTTLMinutes) * time.Minute
+	}
+	if cfg.JWTExpirationHours > 0 {
+		lifetimes.Access = time.Duration(cfg.JWTExpirationHours) * time.Hour
+	}
+	if cfg.RefreshTokenTTLDays > 0 {
+		lifetimes.Refresh = time.Duration(cfg.RefreshTokenTTLDays) * 24 * time.Hour
+	}
+	if cfg.RefreshTokenFamilyMaxDays > 0 {
+		lifetimes.FamilyMax = time.Duration(cfg.RefreshTokenFamilyMaxDays) * 24 * time.Hour
+	}
+	if lifetimes.Refresh > lifetimes.FamilyMax {
+		lifetimes.Refresh = lifetimes.FamilyMax
+	}
+	return lifetimes
+}
+
+// refreshFamily is what a rotated refresh token passes on to its replacement.
+// Reasons a login is reported by auth.new_device
+const (
+	NewLoginDevice  = "new_device"
+	NewLoginCountry = "new_country"
+)
+
+// loginHistoryWindow is how far back logins are searched for the device or country of a new one
+const loginHistoryWindow = 90 * 24 * time.Hour
+
+type refreshFamily struct {
+	ID        string
+	ExpiresAt time.Time
 }
 
 type accessTokenClaims struct {
@@ -63,32 +145,47 @@ type accessTokenClaims struct {
 	jwt.RegisteredClaims
 }
 
+// maxDeviceIDLength matches the refresh_tokens.device_id column
+const maxDeviceIDLength = 128
+
 type AuthService struct {
-	userRepo        *repositories.UserRepository
-	authRepo        *repositories.AuthRepository
-	jwtSecret       []byte
-	accessTokenTTL  time.Duration
-	refreshTokenTTL time.Duration
+	userRepo    *repositories.UserRepository
+	authRepo    *repositories.AuthRepository
+	tokenKeys   *TokenKeys
+	revocations *TokenRevocations
+	lifetimes   TokenLifetimes
+	invites     inviteAcceptor
+	geo         geoip.API
+	events      *events.Publisher
+	// Live refresh tokens kept per user for logins that sent no device ID; older ones are revoked
+	maxDevicelessTokens int
 }
 
 func NewAuthService(
 	userRepo *repositories.UserRepository,
 	authRepo *repositories.AuthRepository,
-	jwtSecret string,
-	jwtExpirationHours int,
+	tokenKeys *TokenKeys,
+	revocations *TokenRevocations,
+	lifetimes TokenLifetimes,
+	invites inviteAcceptor,
+	geo geoip.API,
+	eventsPublisher *events.Publisher,
+	maxDevicelessTokens int,
 ) *AuthService {
-	accessHours := jwtExpirationHours
-	if accessHours <= 0 {
-		accessHours = 24
+	if maxDevicelessTokens <= 0 {
+		maxDevicelessTokens = 10
 	}
-
 	return &AuthService{
-		userRepo:       userRepo,
-		authRepo:       authRepo,
-		jwtSecret:      []byte(jwtSecret),
-		accessTokenTTL: time.Duration(accessHours) * time.Hour,
-		// Keep refresh tokens longer than access tokens for mobile/web session continuity.
-		refreshTokenTTL: 30 * 24 * time.Hour,
+		userRepo:    userRepo,
+		authRepo:    authRepo,
+		tokenKeys:   tokenKeys,
+		revocations: revocations,
+		lifetimes:   lifetimes,
+		invites:     invites,
+		geo:         geo,
+		events:      eventsPublisher,
+
+		maxDevicelessTokens: maxDevicelessTokens,
 	}
 }
 
@@ -129,6 +226,7 @@ func (s *AuthService) Register(ctx context.Context, input RegisterInput, userAge
 		LastName:  strings.TrimSpace(input.LastName),
 		Phone:     input.Phone,
 		Timezone:  timezone,
+		Locale:    i18n.Normalize(input.Locale),
 	}
 
 	if err := s.userRepo.Create(ctx, user, profile); err != nil {
@@ -139,12 +237,42 @@ func (s *AuthService) Register(ctx context.Context, input RegisterInput, userAge
 		return nil, err
 	}
 
+	// The account exists from here on, so a code that can't be used only adds a warning.
+	var invite *AcceptInviteResult
+	var warnings []string
+	if input.InviteCode != nil && strings.TrimSpace(*input.InviteCode) != "" && s.invites != nil {
+		invite, warnings = s.acceptRegistrationInvite(ctx, user.ID, *input.InviteCode)
+	}
+
 	freshUser, err := s.userRepo.GetByID(ctx, user.ID)
 	if err != nil {
 		return nil, err
 	}
 
-	return s.issueTokens(ctx, freshUser, userAgent, ipAddress)
+	result, err := s.issueTokens(ctx, freshUser, userAgent, ipAddress, input.DeviceID, locationCountry(s.locate(ctx, ipAddress)), nil)
+	if err != nil {
+		return nil, err
+	}
+	result.Invite = invite
+	result.Warnings = warnings
+	return result, nil
+}
+
+// acceptRegistrationInvite accepts the invite a new user signed up with, turning any failure into a
+// warning code.
+func (s *AuthService) acceptRegistrationInvite(ctx context.Context, userID uint, code string) (*AcceptInviteResult, []string) {
+	result, err := s.invites.AcceptInvite(ctx, userID, AcceptInviteInput{Code: code})
+	switch {
+	case err == nil:
+		return result, nil
+	case errors.Is(err, ErrInviteCodeNotFound):
+		return nil, []string{RegisterWarningInviteInvalid}
+	case errors.Is(err, ErrInviteCodeExhausted):
+		return nil, []string{RegisterWarningInviteExhausted}
+	default:
+		slog.Error("Failed to accept invite on registration", "user_id", userID, "error", err)
+		return nil, []string{RegisterWarningInviteFailed}
+	}
 }
 
 func (s *AuthService) Login(ctx context.Context, input LoginInput, userAgent, ipAddress string) (*AuthResult, error) {
@@ -178,7 +306,18 @@ func (s *AuthService) Login(ctx context.Context, input LoginInput, userAgent, ip
 		return nil, err
 	}
 
-	return s.issueTokens(ctx, updatedUser, userAgent, ipAddress)
+	// Compare before issuing, since the new token would otherwise count as history.
+	location := s.locate(ctx, ipAddress)
+	reason := s.newLoginReason(ctx, user.ID, userAgent, input.DeviceID, location)
+
+	result, err := s.issueTokens(ctx, updatedUser, userAgent, ipAddress, input.DeviceID, locationCountry(location), nil)
+	if err != nil {
+		return nil, err
+	}
+	if reason != "" {
+		s.publishNewLogin(ctx, user.ID, reason, userAgent, ipAddress, input.DeviceID, location)
+	}
+	return result, nil
 }
 
 func (s *AuthService) Refresh(ctx context.Context, input RefreshInput, userAgent, ipAddress string) (*AuthResult, error) {
@@ -188,7 +327,7 @@ func (s *AuthService) Refresh(ctx context.Context, input RefreshInput, userAgent
 	}
 
 	tokenHash := hashRefreshToken(refreshToken)
-	storedToken, err := s.authRepo.GetRefreshToken(ctx, tokenHash)
+	storedToken, err := s.authRepo.FindRefreshToken(ctx, tokenHash)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, ErrInvalidRefresh
@@ -196,6 +335,27 @@ func (s *AuthService) Refresh(ctx context.Context, input RefreshInput, userAgent
 		return nil, err
 	}
 
+	// A revoked token coming back means it was copied: the legitimate holder already rotated it.
+	// Revoke every token in the family so neither copy can refresh again.
+	if storedToken.Revoked {
+		return nil, s.revokeReusedFamily(ctx, storedToken)
+	}
+
+	now := time.Now().UTC()
+	if !storedToken.ExpiresAt.After(now) {
+		return nil, ErrInvalidRefresh
+	}
+	family := refreshFamily{ID: storedToken.FamilyID, ExpiresAt: now.Add(s.lifetimes.FamilyMax)}
+	if storedToken.FamilyExpiresAt != nil {
+		family.ExpiresAt = *storedToken.FamilyExpiresAt
+	}
+	if !family.ExpiresAt.After(now) {
+		if err := s.authRepo.RevokeRefreshFamily(ctx, family.ID); err != nil {
+			return nil, err
+		}
+		return nil, ErrRefreshExpired
+	}
+
 	user, err := s.userRepo.GetByID(ctx, storedToken.UserID)
 	if err != nil {
 		return nil, err
@@ -204,16 +364,37 @@ func (s *AuthService) Refresh(ctx context.Context, input RefreshInput, userAgent
 		return nil, ErrUserDisabled
 	}
 
-	if err := s.authRepo.RevokeRefreshToken(ctx, storedToken.ID); err != nil {
+	rotated, err := s.authRepo.RotateRefreshToken(ctx, storedToken.ID)
+	if err != nil {
 		return nil, err
 	}
+	if !rotated {
+		// A concurrent refresh got there first; treat the loser as reuse.
+		return nil, s.revokeReusedFamily(ctx, storedToken)
+	}
+
+	deviceID := input.DeviceID
+	if strings.TrimSpace(deviceID) == "" && storedToken.DeviceID != nil {
+		deviceID = *storedToken.DeviceID
+	}
+	var country string
+	if storedToken.Country != nil {
+		country = *storedToken.Country
+	}
+	return s.issueTokens(ctx, user, userAgent, ipAddress, deviceID, country, &family)
+}
 
-	return s.issueTokens(ctx, user, userAgent, ipAddress)
+func (s *AuthService) revokeReusedFamily(ctx context.Context, token *models.RefreshToken) error {
+	slog.Warn("Refresh token reuse detected, revoking family", "user_id", token.UserID, "refresh_token_id", token.ID)
+	if err := s.authRepo.RevokeRefreshFamily(ctx, token.FamilyID); err != nil {
+		return err
+	}
+	return ErrInvalidRefresh
 }
 
 func (s *AuthService) Logout(ctx context.Context, userID uint, input LogoutInput) error {
 	if input.AllDevices || strings.TrimSpace(input.RefreshToken) == "" {
-		return s.authRepo.RevokeAllUserTokens(ctx, userID)
+		return s.RevokeAllUserTokens(ctx, userID)
 	}
 
 	tokenHash := hashRefreshToken(input.RefreshToken)
@@ -232,12 +413,80 @@ func (s *AuthService) Logout(ctx context.Context, userID uint, input LogoutInput
 	return s.authRepo.RevokeRefreshToken(ctx, token.ID)
 }
 
-func (s *AuthService) issueTokens(ctx context.Context, user *models.User, userAgent, ipAddress string) (*AuthResult, error) {
+// DeviceSession is one signed-in device: the user's live refresh tokens sharing a device ID. Tokens
+// from logins that sent no device ID are grouped together with a nil DeviceID.
+type DeviceSession struct {
+	DeviceID     *string   `json:"device_id"`
+	DeviceInfo   *string   `json:"device_info"` // user agent of the most recent token
+	IPAddress    *string   `json:"ip_address"`
+	ActiveTokens int       `json:"active_tokens"`
+	LastActiveAt time.Time `json:"last_active_at"` // when the most recent token was issued by login or refresh
+}
+
+// ListSessions returns the user's signed-in devices, most recently active first.
+func (s *AuthService) ListSessions(ctx context.Context, userID uint) ([]DeviceSession, error) {
+	tokens, err := s.authRepo.ListActiveRefreshTokens(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]DeviceSession, 0, len(tokens))
+	index := make(map[string]int)
+	for _, token := range tokens {
+		key := ""
+		if token.DeviceID != nil {
+			key = "device:" + *token.DeviceID
+		}
+		// Tokens come newest first, so the first token of each device describes it.
+		if i, ok := index[key]; ok {
+			sessions[i].ActiveTokens++
+			continue
+		}
+		index[key] = len(sessions)
+		sessions = append(sessions, DeviceSession{
+			DeviceID:     token.DeviceID,
+			DeviceInfo:   token.DeviceInfo,
+			IPAddress:    token.IPAddress,
+			ActiveTokens: 1,
+			LastActiveAt: token.CreatedAt,
+		})
+	}
+	return sessions, nil
+}
+
+// RevokeAllUserTokens signs the user out everywhere: every refresh token is revoked and access
+// tokens issued until now are denylisted. Use it for logout-all, bans and password changes.
+func (s *AuthService) RevokeAllUserTokens(ctx context.Context, userID uint) error {
+	now := time.Now()
+	if err := s.authRepo.RevokeAllUserTokens(ctx, userID); err != nil {
+		return err
+	}
+	return s.revocations.RevokeAll(ctx, userID, now)
+}
+
+// issueTokens mints an access token and a refresh token. family is nil on login, which starts a new
+// family; on refresh the new token joins the old one's family and can't outlive it. A device keeps one
+// live refresh token: its earlier ones are revoked first. Tokens without a device are capped at
+// maxDevicelessTokens, oldest revoked first. country is the ISO code the login's IP resolved to, if any.
+func (s *AuthService) issueTokens(ctx context.Context, user *models.User, userAgent, ipAddress, deviceID, country string, family *refreshFamily) (*AuthResult, error) {
 	accessToken, expiresAt, err := s.generateAccessToken(user)
 	if err != nil {
 		return nil, err
 	}
 
+	now := time.Now().UTC()
+	if family == nil {
+		familyID, err := generateRefreshToken()
+		if err != nil {
+			return nil, err
+		}
+		family = &refreshFamily{ID: familyID, ExpiresAt: now.Add(s.lifetimes.FamilyMax)}
+	}
+	refreshExpiresAt := now.Add(s.lifetimes.Refresh)
+	if refreshExpiresAt.After(family.ExpiresAt) {
+		refreshExpiresAt = family.ExpiresAt
+	}
+
 	refreshToken, err := generateRefreshToken()
 	if err != nil {
 		return nil, err
@@ -256,33 +505,161 @@ func (s *AuthService) issueTokens(ctx context.Context, user *models.User, userAg
 		ip = &trimmed
 	}
 
+	device := normalizeDeviceID(deviceID)
+	if device != nil {
+		if err := s.authRepo.RevokeDeviceTokens(ctx, user.ID, *device); err != nil {
+			return nil, err
+		}
+	}
+
 	dbToken := &models.RefreshToken{
-		UserID:     user.ID,
-		Token:      tokenHash,
-		ExpiresAt:  time.Now().UTC().Add(s.refreshTokenTTL),
-		DeviceInfo: deviceInfo,
-		IPAddress:  ip,
+		UserID:          user.ID,
+		Token:           tokenHash,
+		ExpiresAt:       refreshExpiresAt,
+		FamilyID:        family.ID,
+		FamilyExpiresAt: &family.ExpiresAt,
+		DeviceInfo:      deviceInfo,
+		DeviceID:        device,
+		IPAddress:       ip,
+	}
+	if country != "" {
+		dbToken.Country = &country
 	}
 	if err := s.authRepo.CreateRefreshToken(ctx, dbToken); err != nil {
 		return nil, err
 	}
 
+	if device == nil {
+		if _, err := s.authRepo.PruneDevicelessTokens(ctx, user.ID, s.maxDevicelessTokens); err != nil {
+			return nil, err
+		}
+	}
+
 	return &AuthResult{
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
-		TokenType:    "Bearer",
-		ExpiresAt:    expiresAt,
-		User:         user,
+		AccessToken:      accessToken,
+		RefreshToken:     refreshToken,
+		TokenType:        "Bearer",
+		ExpiresAt:        expiresAt,
+		RefreshExpiresAt: refreshExpiresAt,
+		User:             user,
 	}, nil
 }
 
+// locate resolves ipAddress with the configured geolocation provider. Lookups are best effort: a
+// login never fails because the provider did.
+func (s *AuthService) locate(ctx context.Context, ipAddress string) *geoip.Location {
+	ipAddress = strings.TrimSpace(ipAddress)
+	if s.geo == nil || !s.geo.IsConfigured() || ipAddress == "" {
+		return nil
+	}
+	location, err := s.geo.Lookup(ctx, ipAddress)
+	if err != nil {
+		slog.Warn("IP geolocation failed", "error", err)
+		return nil
+	}
+	return location
+}
+
+func locationCountry(location *geoip.Location) string {
+	if location == nil {
+		return ""
+	}
+	return strings.ToUpper(strings.TrimSpace(location.Country))
+}
+
+// newLoginReason compares a login with the user's logins over the last loginHistoryWindow. It returns
+// NewLoginDevice when neither the device ID (or, without one, the user agent) has been seen,
+// NewLoginCountry when the device is known but the country isn't, and "" otherwise. Users with no
+// history yet, and countries when no earlier login was placed, are never reported.
+func (s *AuthService) newLoginReason(ctx context.Context, userID uint, userAgent, deviceID string, location *geoip.Location) string {
+	history, err := s.authRepo.ListRecentLogins(ctx, userID, time.Now().Add(-loginHistoryWindow))
+	if err != nil {
+		slog.Warn("Failed to load login history", "user_id", userID, "error", err)
+		return ""
+	}
+	if len(history) == 0 {
+		return ""
+	}
+
+	device := normalizeDeviceID(deviceID)
+	userAgent = strings.TrimSpace(userAgent)
+	country := locationCountry(location)
+
+	knownDevice := device == nil && userAgent == "" // nothing to compare
+	knownCountry, placed := false, false
+	for _, login := range history {
+		switch {
+		case device != nil:
+			if login.DeviceID != nil && *login.DeviceID == *device {
+				knownDevice = true
+			}
+		case userAgent != "":
+			if login.DeviceInfo != nil && *login.DeviceInfo == userAgent {
+				knownDevice = true
+			}
+		}
+		if login.Country != nil {
+			placed = true
+			if *login.Country == country {
+				knownCountry = true
+			}
+		}
+	}
+
+	switch {
+	case !knownDevice:
+		return NewLoginDevice
+	case country != "" && placed && !knownCountry:
+		return NewLoginCountry
+	default:
+		return ""
+	}
+}
+
+// publishNewLogin queues the auth.new_device alert. The login has already succeeded, so failures are
+// only logged.
+func (s *AuthService) publishNewLogin(ctx context.Context, userID uint, reason, userAgent, ipAddress, deviceID string, location *geoip.Location) {
+	if s.events == nil {
+		return
+	}
+
+	now := time.Now().UTC()
+	payload := events.AuthNewDevicePayload{
+		UserID:     userID,
+		Reason:     reason,
+		DeviceInfo: strings.TrimSpace(userAgent),
+		IPAddress:  strings.TrimSpace(ipAddress),
+		LoggedInAt: now,
+	}
+	if device := normalizeDeviceID(deviceID); device != nil {
+		payload.DeviceID = *device
+	}
+	if location != nil {
+		payload.City = strings.TrimSpace(location.City)
+		payload.Country = locationCountry(location)
+	}
+
+	id := strconv.FormatUint(uint64(userID), 10)
+	err := s.events.Publish(
+		ctx,
+		events.EventTypeAuthNewDevice,
+		"user",
+		id,
+		events.BuildIdempotencyKey(events.EventTypeAuthNewDevice, id, strconv.FormatInt(now.UnixNano(), 10)),
+		payload,
+	)
+	if err != nil {
+		slog.Error("Failed to publish new login alert", "user_id", userID, "error", err)
+	}
+}
+
 func (s *AuthService) generateAccessToken(user *models.User) (string, time.Time, error) {
-	if len(s.jwtSecret) == 0 {
-		return "", time.Time{}, fmt.Errorf("JWT_SECRET is not configured")
+	if !s.tokenKeys.Configured() {
+		return "", time.Time{}, fmt.Errorf("JWT_SECRET or JWT_PRIVATE_KEY is not configured")
 	}
 
 	now := time.Now().UTC()
-	expiresAt := now.Add(s.accessTokenTTL)
+	expiresAt := now.Add(s.lifetimes.Access)
 
 	jti, err := generateRefreshToken()
 	if err != nil {
@@ -301,8 +678,7 @@ func (s *AuthService) generateAccessToken(user *models.User) (string, time.Time,
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	signedToken, err := token.SignedString(s.jwtSecret)
+	signedToken, err := s.tokenKeys.Sign(claims)
 	if err != nil {
 		return "", time.Time{}, fmt.Errorf("sign access token: %w", err)
 	}
@@ -310,31 +686,20 @@ func (s *AuthService) generateAccessToken(user *models.User) (string, time.Time,
 	return signedToken, expiresAt, nil
 }
 
-func ValidateAccessToken(tokenString string, jwtSecret string) (uint, error) {
-	if strings.TrimSpace(tokenString) == "" {
-		return 0, ErrInvalidCredentials
-	}
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
 
-	claims := &accessTokenClaims{}
-	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (any, error) {
-		if token.Method != jwt.SigningMethodHS256 {
-			return nil, fmt.Errorf("unexpected signing method")
-		}
-		return []byte(jwtSecret), nil
-	})
-	if err != nil || token == nil || !token.Valid {
-		return 0, ErrInvalidCredentials
+// normalizeDeviceID trims a client-supplied device ID and cuts it to the column size. Blank IDs are nil.
+func normalizeDeviceID(raw string) *string {
+	deviceID := strings.TrimSpace(raw)
+	if deviceID == "" {
+		return nil
 	}
-
-	if claims.UserID == 0 {
-		return 0, ErrInvalidCredentials
+	if len(deviceID) > maxDeviceIDLength {
+		deviceID = deviceID[:maxDeviceIDLength]
 	}
-
-	return claims.UserID, nil
-}
-
-func normalizeEmail(email string) string {
-	return strings.ToLower(strings.TrimSpace(email))
+	return &deviceID
 }
 
 func generateRefreshToken() (string, error) {
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+)
+
+// maxSuggestedSlots caps the alternatives offered when a requested time can't be booked
+const maxSuggestedSlots = 5
+
+// ConflictError is ErrSessionConflict or ErrOutsideAvailability with open slots near the requested
+// time, so the app can offer them without refetching the slot grid. SuggestedSlots is nil when
+// suggestions were skipped and empty when none were found.
+type ConflictError struct {
+	Err            error
+	SuggestedSlots []BookableTime
+}
+
+func (e *ConflictError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ConflictError) Unwrap() error {
+	return e.Err
+}
+
+// ErrorDetails is merged into the API error response.
+func (e *ConflictError) ErrorDetails() map[string]any {
+	if e.SuggestedSlots == nil {
+		return nil
+	}
+	return map[string]any{"suggested_slots": e.SuggestedSlots}
+}
+
+// withSuggestedSlots turns a booking conflict into a ConflictError listing the open slots closest to
+// scheduledAt on its UTC day and the day after. Other errors pass through; if the slots can't be
+// loaded the conflict is returned without suggestions rather than failing differently.
+func (s *SessionService) withSuggestedSlots(ctx context.Context, err error, coachID, sessionTypeID uint, scheduledAt time.Time) error {
+	if !errors.Is(err, ErrSessionConflict) && !errors.Is(err, ErrOutsideAvailability) {
+		return err
+	}
+
+	day := scheduledAt.UTC()
+	startDate := day.Format(calendarDateLayout)
+	endDate := day.AddDate(0, 0, 1).Format(calendarDateLayout)
+	slots, slotsErr := s.GetBookableSlots(ctx, coachID, startDate, endDate, &sessionTypeID, nil, 0)
+	if slotsErr != nil {
+		return err
+	}
+
+	return &ConflictError{Err: err, SuggestedSlots: nearestSlots(slots, scheduledAt, maxSuggestedSlots)}
+}
+
+// nearestSlots returns up to limit slots closest to target, in chronological order.
+func nearestSlots(slots *BookableSlots, target time.Time, limit int) []BookableTime {
+	candidates := make([]BookableTime, 0, slots.Total)
+	for _, day := range slots.Days {
+		for _, slot := range day.Slots {
+			if !slot.StartAt.Equal(target) {
+				candidates = append(candidates, slot)
+			}
+		}
+	}
+
+	distance := func(slot BookableTime) time.Duration {
+		if d := slot.StartAt.Sub(target); d >= 0 {
+			return d
+		}
+		return target.Sub(slot.StartAt)
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return distance(candidates[i]) < distance(candidates[j])
+	})
+	if len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].StartAt.Before(candidates[j].StartAt)
+	})
+	return candidates
+}
@@ -0,0 +1,204 @@
+package services
+
+import (
+	"chalk-api/pkg/models"
+	"chalk-api/pkg/stores"
+	"context"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Bootstrap sections that can be missing from a response when their lookup fails
+const (
+	BootstrapSectionRoles               = "roles"
+	BootstrapSectionSubscription        = "subscription"
+	BootstrapSectionFlags               = "flags"
+	BootstrapSectionUnreadMessages      = "unread_messages"
+	BootstrapSectionUnreadNotifications = "unread_notifications"
+)
+
+// AppVersionInfo tells the app whether it can keep running. UpgradeRequired and UpdateAvailable are
+// only set when the app sent its version; a null minimum or latest version disables that check.
+type AppVersionInfo struct {
+	MinAppVersion    *string `json:"min_app_version"`
+	LatestAppVersion *string `json:"latest_app_version"`
+	UpgradeRequired  bool    `json:"upgrade_required"`
+	UpdateAvailable  bool    `json:"update_available"`
+}
+
+// BootstrapResponse is everything the mobile app needs to configure itself at startup. A section
+// that couldn't be loaded is null and listed in Unavailable, so the app can fetch it from its own
+// endpoint instead of failing to start.
+type BootstrapResponse struct {
+	User                *models.User                 `json:"user"`
+	Roles               *AccountCapabilitiesResponse `json:"roles"`
+	Subscription        *stores.CachedSubscription   `json:"subscription"`
+	Flags               map[string]bool              `json:"flags"`
+	UnreadMessages      *int64                       `json:"unread_messages"`
+	UnreadNotifications *int64                       `json:"unread_notifications"`
+	AppVersion          AppVersionInfo               `json:"app_version"`
+	Unavailable         []string                     `json:"unavailable"`
+}
+
+// BootstrapService assembles the app's startup payload. Each lookup runs concurrently:
+//
+//   - flags and subscription are read from Redis (stores.UserFlagsTTL, stores.SubscriptionTTL) and
+//     only hit the database on a miss
+//   - the user, roles and both unread counts query the database on every call, each through a
+//     single indexed lookup
+type BootstrapService struct {
+	users            *UserService
+	subscriptions    *SubscriptionService
+	flags            *FlagService
+	messages         *MessageService
+	notifications    *NotificationService
+	minAppVersion    string
+	latestAppVersion string
+}
+
+func NewBootstrapService(
+	userService *UserService,
+	subscriptionService *SubscriptionService,
+	flagService *FlagService,
+	messageService *MessageService,
+	notificationService *NotificationService,
+	minAppVersion string,
+	latestAppVersion string,
+) *BootstrapService {
+	return &BootstrapService{
+		users:            userService,
+		subscriptions:    subscriptionService,
+		flags:            flagService,
+		messages:         messageService,
+		notifications:    notificationService,
+		minAppVersion:    strings.TrimSpace(minAppVersion),
+		latestAppVersion: strings.TrimSpace(latestAppVersion),
+	}
+}
+
+// GetBootstrap loads the caller's startup payload. appVersion is the version the app reports, and
+// may be empty. Only the user is required; any other failed lookup is logged and left out.
+func (s *BootstrapService) GetBootstrap(ctx context.Context, userID uint, appVersion string) (*BootstrapResponse, error) {
+	response := &BootstrapResponse{
+		AppVersion:  s.appVersionInfo(appVersion),
+		Unavailable: []string{},
+	}
+
+	var (
+		wg                                           sync.WaitGroup
+		user                                         *models.User
+		userErr, rolesErr, subscriptionErr, flagsErr error
+		unreadMessages, unreadNotifications          int64
+		unreadMessagesErr, unreadNotificationsErr    error
+	)
+	wg.Add(6)
+	go func() {
+		defer wg.Done()
+		user, userErr = s.users.GetMe(ctx, userID)
+	}()
+	go func() {
+		defer wg.Done()
+		response.Roles, rolesErr = s.users.GetCapabilities(ctx, userID)
+	}()
+	go func() {
+		defer wg.Done()
+		response.Subscription, subscriptionErr = s.subscriptions.GetCachedSubscription(ctx, userID)
+	}()
+	go func() {
+		defer wg.Done()
+		response.Flags, flagsErr = s.flags.EvaluateAll(ctx, userID)
+	}()
+	go func() {
+		defer wg.Done()
+		unreadMessages, unreadMessagesErr = s.messages.GetUnreadCount(ctx, userID, false)
+	}()
+	go func() {
+		defer wg.Done()
+		unreadNotifications, unreadNotificationsErr = s.notifications.GetUnreadCount(ctx, userID)
+	}()
+	wg.Wait()
+
+	if userErr != nil {
+		return nil, userErr
+	}
+	response.User = user
+
+	if unreadMessagesErr == nil {
+		response.UnreadMessages = &unreadMessages
+	}
+	if unreadNotificationsErr == nil {
+		response.UnreadNotifications = &unreadNotifications
+	}
+	for _, section := range []struct {
+		name string
+		err  error
+	}{
+		{BootstrapSectionRoles, rolesErr},
+		{BootstrapSectionSubscription, subscriptionErr},
+		{BootstrapSectionFlags, flagsErr},
+		{BootstrapSectionUnreadMessages, unreadMessagesErr},
+		{BootstrapSectionUnreadNotifications, unreadNotificationsErr},
+	} {
+		if section.err != nil {
+			slog.Warn("Bootstrap section unavailable", "user_id", userID, "section", section.name, "error", section.err)
+			response.Unavailable = append(response.Unavailable, section.name)
+		}
+	}
+	return response, nil
+}
+
+func (s *BootstrapService) appVersionInfo(appVersion string) AppVersionInfo {
+	info := AppVersionInfo{
+		MinAppVersion:    trimToPtr(s.minAppVersion),
+		LatestAppVersion: trimToPtr(s.latestAppVersion),
+	}
+	appVersion = strings.TrimSpace(appVersion)
+	if appVersion == "" {
+		return info
+	}
+	if info.MinAppVersion != nil && compareAppVersions(appVersion, *info.MinAppVersion) < 0 {
+		info.UpgradeRequired = true
+	}
+	if info.LatestAppVersion != nil && compareAppVersions(appVersion, *info.LatestAppVersion) < 0 {
+		info.UpdateAvailable = true
+	}
+	return info
+}
+
+// compareAppVersions compares dotted versions such as "2.4.1" part by part, returning -1, 0 or 1.
+// Missing parts count as 0, a leading "v" is ignored, and so is anything after the digits of a part,
+// so "2.4.1-beta" compares equal to "2.4.1".
+func compareAppVersions(a, b string) int {
+	left := strings.Split(strings.TrimPrefix(a, "v"), ".")
+	right := strings.Split(strings.TrimPrefix(b, "v"), ".")
+	for i := 0; i < len(left) || i < len(right); i++ {
+		l, r := appVersionPart(left, i), appVersionPart(right, i)
+		if l < r {
+			return -1
+		}
+		if l > r {
+			return 1
+		}
+	}
+	return 0
+}
+
+func appVersionPart(parts []string, i int) int {
+	if i >= len(parts) {
+		return 0
+	}
+	digits := parts[i]
+	for j, r := range digits {
+		if r < '0' || r > '9' {
+			digits = digits[:j]
+			break
+		}
+	}
+	n, err := strconv.Atoi(digits)
+	if err != nil {
+		return 0
+	}
+	return n
+}
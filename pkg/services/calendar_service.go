@@ -0,0 +1,504 @@
+package services
+
+import (
+	"chalk-api/pkg/calendar"
+	"chalk-api/pkg/models"
+	"chalk-api/pkg/repositories"
+	"chalk-api/pkg/utils"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	CalendarItemWorkout   = "workout"
+	CalendarItemSession   = "session"
+	CalendarItemTimeBlock = "time_block"
+
+	calendarDateLayout = "2006-01-02"
+)
+
+var ErrInvalidWeek = errors.New("week must be an ISO week (2026-W12) or a date (YYYY-MM-DD)")
+
+// CalendarItem is one entry in the merged calendar. Workouts are date-only; sessions and time
+// blocks also carry start and end times (UTC) and are dated by their UTC start.
+type CalendarItem struct {
+	Type     string     `json:"type"` // CalendarItem*
+	ID       uint       `json:"id"`
+	Date     string     `json:"date"` // YYYY-MM-DD
+	StartAt  *time.Time `json:"start_at,omitempty"`
+	EndAt    *time.Time `json:"end_at,omitempty"`
+	Status   string     `json:"status,omitempty"` // empty for time blocks
+	Title    string     `json:"title"`
+	ClientID uint       `json:"client_id,omitempty"`
+	CoachID  uint       `json:"coach_id,omitempty"`
+}
+
+// CalendarDay counts a day's items for month-view dots. Only days with at least one item are listed.
+type CalendarDay struct {
+	Date       string `json:"date"`
+	Workouts   int    `json:"workouts"`
+	Sessions   int    `json:"sessions"`
+	TimeBlocks int    `json:"time_blocks"`
+	Total      int    `json:"total"`
+}
+
+type Calendar struct {
+	Start string         `json:"start"`
+	End   string         `json:"end"`
+	Items []CalendarItem `json:"items"`
+	Days  []CalendarDay  `json:"days"`
+}
+
+// CalendarService merges workouts, sessions and (for coaches) blocked time into a single feed so
+// apps don't have to stitch separate lists together.
+type CalendarService struct {
+	coachRepo   *repositories.CoachRepository
+	clientRepo  *repositories.ClientRepository
+	sessionRepo *repositories.SessionRepository
+	workoutRepo *repositories.WorkoutRepository
+	userRepo    *repositories.UserRepository
+}
+
+func NewCalendarService(repos *repositories.RepositoriesCollection) *CalendarService {
+	return &CalendarService{
+		coachRepo:   repos.Coach,
+		clientRepo:  repos.Client,
+		sessionRepo: repos.Session,
+		workoutRepo: repos.Workout,
+		userRepo:    repos.User,
+	}
+}
+
+// GetClientCalendar returns workouts and sessions across all of the user's client profiles.
+func (s *CalendarService) GetClientCalendar(ctx context.Context, userID uint, startRaw, endRaw string) (*Calendar, error) {
+	startDate, endDate, err := parseDateRange(startRaw, endRaw, defaultListRangeDays)
+	if err != nil {
+		return nil, err
+	}
+
+	clientProfiles, err := s.clientRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	clientIDs := make([]uint, 0, len(clientProfiles))
+	for i := range clientProfiles {
+		clientIDs = append(clientIDs, clientProfiles[i].ID)
+	}
+	if len(clientIDs) == 0 {
+		return buildCalendar(startDate, endDate, nil, nil, nil), nil
+	}
+
+	var (
+		wg                     sync.WaitGroup
+		workouts               []models.Workout
+		sessions               []models.Session
+		workoutErr, sessionErr error
+	)
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		workouts, workoutErr = s.workoutRepo.ListScheduledBetween(ctx, 0, clientIDs, startDate.Format(calendarDateLayout), endDate.Format(calendarDateLayout))
+	}()
+	go func() {
+		defer wg.Done()
+		sessions, sessionErr = s.sessionRepo.ListSessionsByClients(ctx, clientIDs, startDate, endDate, repositories.SessionStatusFilter{})
+	}()
+	wg.Wait()
+
+	if err := errors.Join(workoutErr, sessionErr); err != nil {
+		return nil, err
+	}
+	return buildCalendar(startDate, endDate, workouts, sessions, nil), nil
+}
+
+// GetCoachCalendar returns the coach's sessions, every workout assigned to their clients and their
+// blocked time.
+func (s *CalendarService) GetCoachCalendar(ctx context.Context, userID uint, startRaw, endRaw string) (*Calendar, error) {
+	coach, err := s.coachRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCoachProfileNotFound
+		}
+		return nil, err
+	}
+
+	startDate, endDate, err := parseDateRange(startRaw, endRaw, defaultListRangeDays)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		wg                               sync.WaitGroup
+		workouts                         []models.Workout
+		sessions                         []models.Session
+		blocks                           []models.CoachTimeBlock
+		workoutErr, sessionErr, blockErr error
+	)
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		workouts, workoutErr = s.workoutRepo.ListScheduledBetween(ctx, coach.ID, nil, startDate.Format(calendarDateLayout), endDate.Format(calendarDateLayout))
+	}()
+	go func() {
+		defer wg.Done()
+		sessions, sessionErr = s.sessionRepo.ListSessions(ctx, coach.ID, 0, startDate, endDate, repositories.SessionStatusFilter{})
+	}()
+	go func() {
+		defer wg.Done()
+		blocks, blockErr = s.sessionRepo.ListTimeBlocks(ctx, coach.ID, startDate, endDate)
+	}()
+	wg.Wait()
+
+	if err := errors.Join(workoutErr, sessionErr, blockErr); err != nil {
+		return nil, err
+	}
+	return buildCalendar(startDate, endDate, workouts, sessions, blocks), nil
+}
+
+// CoachSchedule is one Monday-to-Sunday week in the coach's timezone, shaped for the weekly planner.
+// Days always holds all seven days, even empty ones.
+type CoachSchedule struct {
+	Week         string        `json:"week"`  // ISO week, e.g. "2026-W12"
+	Start        string        `json:"start"` // Monday, YYYY-MM-DD
+	End          string        `json:"end"`   // Sunday
+	Timezone     string        `json:"timezone"`
+	PreviousWeek string        `json:"previous_week"`
+	NextWeek     string        `json:"next_week"`
+	Days         []ScheduleDay `json:"days"`
+}
+
+type ScheduleDay struct {
+	Date              string            `json:"date"`
+	Weekday           string            `json:"weekday"` // "monday" ... "sunday"
+	Sessions          []ScheduleSession `json:"sessions"`
+	WorkoutsScheduled int               `json:"workouts_scheduled"`
+	WorkoutsCompleted int               `json:"workouts_completed"`
+	PendingCheckIns   int               `json:"pending_check_ins"`
+}
+
+// ScheduleSession is a session placed on the coach's local day. For group sessions the client is the
+// one who booked it.
+type ScheduleSession struct {
+	ID               uint      `json:"id"`
+	StartAt          time.Time `json:"start_at"` // UTC
+	EndAt            time.Time `json:"end_at"`
+	LocalTime        string    `json:"local_time"` // HH:MM in the coach's timezone
+	Status           string    `json:"status"`
+	SessionType      string    `json:"session_type"`
+	ClientID         uint      `json:"client_id"`
+	ClientName       string    `json:"client_name"`
+	ParticipantCount int       `json:"participant_count"`
+	MaxParticipants  int       `json:"max_participants"`
+	CheckedIn        bool      `json:"checked_in"`
+	// Scheduled, not checked in, and the check-in window hasn't closed yet
+	CheckInPending bool `json:"check_in_pending"`
+}
+
+// GetCoachSchedule returns the coach's week: sessions per day in start order, counts of client workouts
+// scheduled and completed, and sessions still waiting for a check-in. weekRaw is an ISO week
+// ("2026-W12") or any date inside the week; empty means the current week. Week boundaries and session
+// days follow the coach's profile timezone.
+func (s *CalendarService) GetCoachSchedule(ctx context.Context, userID uint, weekRaw string) (*CoachSchedule, error) {
+	coach, err := s.coachRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCoachProfileNotFound
+		}
+		return nil, err
+	}
+
+	timezone := ""
+	if user, err := s.userRepo.GetByID(ctx, userID); err == nil && user.Profile != nil {
+		timezone = user.Profile.Timezone
+	}
+	loc := utils.ResolveLocation(timezone)
+
+	now := time.Now()
+	monday, err := parseScheduleWeek(weekRaw, now.In(loc), loc)
+	if err != nil {
+		return nil, err
+	}
+	nextMonday := monday.AddDate(0, 0, 7)
+	sunday := monday.AddDate(0, 0, 6)
+
+	var (
+		wg                   sync.WaitGroup
+		sessions             []models.Session
+		workoutCounts        []repositories.WorkoutDayCount
+		sessionErr, countErr error
+	)
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		sessions, sessionErr = s.sessionRepo.ListSessions(ctx, coach.ID, 0, monday.UTC(), nextMonday.UTC(), repositories.SessionStatusFilter{})
+	}()
+	go func() {
+		defer wg.Done()
+		workoutCounts, countErr = s.workoutRepo.CountScheduledByDate(ctx, coach.ID, monday.Format(calendarDateLayout), sunday.Format(calendarDateLayout))
+	}()
+	wg.Wait()
+
+	if err := errors.Join(sessionErr, countErr); err != nil {
+		return nil, err
+	}
+
+	days := make([]ScheduleDay, 7)
+	dayIndex := make(map[string]int, 7)
+	for i := range days {
+		date := monday.AddDate(0, 0, i)
+		days[i] = ScheduleDay{
+			Date:     date.Format(calendarDateLayout),
+			Weekday:  strings.ToLower(date.Weekday().String()),
+			Sessions: []ScheduleSession{},
+		}
+		dayIndex[days[i].Date] = i
+	}
+
+	for _, count := range workoutCounts {
+		if i, ok := dayIndex[count.Date]; ok {
+			days[i].WorkoutsScheduled = count.Scheduled
+			days[i].WorkoutsCompleted = count.Completed
+		}
+	}
+
+	for _, session := range sessions {
+		localStart := session.ScheduledAt.In(loc)
+		i, ok := dayIndex[localStart.Format(calendarDateLayout)]
+		if !ok {
+			// The range query includes the next Monday's midnight.
+			continue
+		}
+		startAt := session.ScheduledAt.UTC()
+		item := ScheduleSession{
+			ID:               session.ID,
+			StartAt:          startAt,
+			EndAt:            startAt.Add(time.Duration(session.DurationMinutes) * time.Minute),
+			LocalTime:        localStart.Format("15:04"),
+			Status:           session.Status,
+			SessionType:      session.SessionType.Name,
+			ClientID:         session.ClientID,
+			ClientName:       strings.TrimSpace(digestClientName(session.Client)),
+			ParticipantCount: session.ParticipantCount,
+			MaxParticipants:  session.MaxParticipants,
+			CheckedIn:        session.CheckedInAt != nil,
+		}
+		item.CheckInPending = session.Status == "scheduled" && !item.CheckedIn && now.Before(startAt.Add(checkInWindow))
+		if item.CheckInPending {
+			days[i].PendingCheckIns++
+		}
+		days[i].Sessions = append(days[i].Sessions, item)
+	}
+	for i := range days {
+		sort.SliceStable(days[i].Sessions, func(a, b int) bool {
+			left, right := days[i].Sessions[a], days[i].Sessions[b]
+			if !left.StartAt.Equal(right.StartAt) {
+				return left.StartAt.Before(right.StartAt)
+			}
+			return left.ID < right.ID
+		})
+	}
+
+	return &CoachSchedule{
+		Week:         isoWeekLabel(monday),
+		Start:        monday.Format(calendarDateLayout),
+		End:          sunday.Format(calendarDateLayout),
+		Timezone:     loc.String(),
+		PreviousWeek: isoWeekLabel(monday.AddDate(0, 0, -7)),
+		NextWeek:     isoWeekLabel(nextMonday),
+		Days:         days,
+	}, nil
+}
+
+// parseScheduleWeek returns local midnight of the Monday starting the requested week.
+func parseScheduleWeek(raw string, now time.Time, loc *time.Location) (time.Time, error) {
+	raw = strings.TrimSpace(raw)
+	var day time.Time
+	switch {
+	case raw == "":
+		day = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	case strings.Contains(strings.ToUpper(raw), "-W"):
+		yearRaw, weekRaw, _ := strings.Cut(strings.ToUpper(raw), "-W")
+		year, yearErr := strconv.Atoi(yearRaw)
+		week, weekErr := strconv.Atoi(weekRaw)
+		if yearErr != nil || weekErr != nil || len(yearRaw) != 4 || len(weekRaw) != 2 || week < 1 || week > 53 {
+			return time.Time{}, ErrInvalidWeek
+		}
+		// ISO week 1 is the week holding January 4th.
+		jan4 := time.Date(year, time.January, 4, 0, 0, 0, 0, loc)
+		day = startOfISOWeek(jan4).AddDate(0, 0, (week-1)*7)
+		if isoYear, isoWeek := day.ISOWeek(); isoYear != year || isoWeek != week {
+			return time.Time{}, ErrInvalidWeek
+		}
+		return day, nil
+	default:
+		parsed, err := time.ParseInLocation(calendarDateLayout, raw, loc)
+		if err != nil {
+			return time.Time{}, ErrInvalidWeek
+		}
+		day = parsed
+	}
+	return startOfISOWeek(day), nil
+}
+
+func startOfISOWeek(day time.Time) time.Time {
+	offset := (int(day.Weekday()) + 6) % 7 // Monday = 0
+	return day.AddDate(0, 0, -offset)
+}
+
+func isoWeekLabel(day time.Time) string {
+	year, week := day.ISOWeek()
+	return fmt.Sprintf("%d-W%02d", year, week)
+}
+
+// CalendarExport is the coach calendar as an .ics file.
+type CalendarExport struct {
+	Filename string
+	Body     string
+}
+
+// ExportCoachCalendar renders the coach calendar's sessions and blocked time for the same range as
+// an .ics file. Workouts are left out: they belong on the clients' calendars.
+func (s *CalendarService) ExportCoachCalendar(ctx context.Context, userID uint, startRaw, endRaw string) (*CalendarExport, error) {
+	feed, err := s.GetCoachCalendar(ctx, userID, startRaw, endRaw)
+	if err != nil {
+		return nil, err
+	}
+
+	icsEvents := make([]calendar.Event, 0, len(feed.Items))
+	for _, item := range feed.Items {
+		if item.StartAt == nil || item.EndAt == nil {
+			continue
+		}
+		event := calendar.Event{
+			UID:     calendar.UID(item.Type, item.ID),
+			Summary: item.Title,
+			Start:   *item.StartAt,
+			End:     *item.EndAt,
+		}
+		if item.Type == CalendarItemSession {
+			event.Status = calendar.SessionStatus(item.Status)
+		}
+		icsEvents = append(icsEvents, event)
+	}
+
+	return &CalendarExport{
+		Filename: fmt.Sprintf("calendar-%s-%s.ics", feed.Start, feed.End),
+		Body:     calendar.Encode(icsEvents, time.Now().UTC()),
+	}, nil
+}
+
+// buildCalendar merges the three sources into one list ordered by date, then start time (workouts,
+// having none, come first on their day), and tallies per-day counts.
+func buildCalendar(
+	startDate time.Time,
+	endDate time.Time,
+	workouts []models.Workout,
+	sessions []models.Session,
+	blocks []models.CoachTimeBlock,
+) *Calendar {
+	items := make([]CalendarItem, 0, len(workouts)+len(sessions)+len(blocks))
+
+	for _, workout := range workouts {
+		if workout.ScheduledDate == nil {
+			continue
+		}
+		// The date column can scan back as a full timestamp; keep the YYYY-MM-DD prefix.
+		date := *workout.ScheduledDate
+		if len(date) > len(calendarDateLayout) {
+			date = date[:len(calendarDateLayout)]
+		}
+		items = append(items, CalendarItem{
+			Type:     CalendarItemWorkout,
+			ID:       workout.ID,
+			Date:     date,
+			Status:   workout.Status,
+			Title:    workout.Name,
+			ClientID: workout.ClientID,
+			CoachID:  workout.CoachID,
+		})
+	}
+
+	for _, session := range sessions {
+		startAt := session.ScheduledAt.UTC()
+		endAt := startAt.Add(time.Duration(session.DurationMinutes) * time.Minute)
+		items = append(items, CalendarItem{
+			Type:     CalendarItemSession,
+			ID:       session.ID,
+			Date:     startAt.Format(calendarDateLayout),
+			StartAt:  &startAt,
+			EndAt:    &endAt,
+			Status:   session.Status,
+			Title:    session.SessionType.Name,
+			ClientID: session.ClientID,
+			CoachID:  session.CoachID,
+		})
+	}
+
+	for _, block := range blocks {
+		startAt := block.StartAt.UTC()
+		endAt := block.EndAt.UTC()
+		// A block that began before the range is shown on the range's first day.
+		day := startAt
+		if day.Before(startDate) {
+			day = startDate
+		}
+		title := "Blocked"
+		if block.Label != nil && *block.Label != "" {
+			title = *block.Label
+		}
+		items = append(items, CalendarItem{
+			Type:    CalendarItemTimeBlock,
+			ID:      block.ID,
+			Date:    day.Format(calendarDateLayout),
+			StartAt: &startAt,
+			EndAt:   &endAt,
+			Title:   title,
+			CoachID: block.CoachID,
+		})
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].Date != items[j].Date {
+			return items[i].Date < items[j].Date
+		}
+		if (items[i].StartAt == nil) != (items[j].StartAt == nil) {
+			return items[i].StartAt == nil
+		}
+		if items[i].StartAt != nil && !items[i].StartAt.Equal(*items[j].StartAt) {
+			return items[i].StartAt.Before(*items[j].StartAt)
+		}
+		return false
+	})
+
+	days := []CalendarDay{}
+	for _, item := range items {
+		if len(days) == 0 || days[len(days)-1].Date != item.Date {
+			days = append(days, CalendarDay{Date: item.Date})
+		}
+		day := &days[len(days)-1]
+		switch item.Type {
+		case CalendarItemWorkout:
+			day.Workouts++
+		case CalendarItemSession:
+			day.Sessions++
+		case CalendarItemTimeBlock:
+			day.TimeBlocks++
+		}
+		day.Total++
+	}
+
+	return &Calendar{
+		Start: startDate.Format(calendarDateLayout),
+		End:   endDate.Format(calendarDateLayout),
+		Items: items,
+		Days:  days,
+	}
+}
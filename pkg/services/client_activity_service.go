@@ -0,0 +1,87 @@
+package services
+
+import (
+	"chalk-api/pkg/events"
+	"chalk-api/pkg/repositories"
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// AtRiskSweepResult reports what one at-risk sweep changed.
+type AtRiskSweepResult struct {
+	ActivityUpdated int64
+	Flagged         int
+	Cleared         int64
+}
+
+// ClientActivityService maintains each client's last activity and at-risk flag.
+// The at-risk worker calls RefreshAtRisk on an interval.
+type ClientActivityService struct {
+	repos  *repositories.RepositoriesCollection
+	events *events.Publisher
+}
+
+func NewClientActivityService(repos *repositories.RepositoriesCollection, eventsPublisher *events.Publisher) *ClientActivityService {
+	return &ClientActivityService{
+		repos:  repos,
+		events: eventsPublisher,
+	}
+}
+
+// RefreshAtRisk recomputes last_activity_at from workouts, workout logs, messages and attended
+// sessions, clears the flag for clients who have been active again, then flags clients inactive
+// past their coach's threshold. A client.at_risk event is published only when the flag flips on.
+func (s *ClientActivityService) RefreshAtRisk(ctx context.Context, now time.Time) (*AtRiskSweepResult, error) {
+	result := &AtRiskSweepResult{}
+
+	updated, err := s.repos.Client.RefreshLastActivity(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result.ActivityUpdated = updated
+
+	cleared, err := s.repos.Client.ClearAtRisk(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+	result.Cleared = cleared
+
+	err = s.repos.WithTransaction(ctx, func(tx *gorm.DB, txRepos *repositories.RepositoriesCollection) error {
+		flagged, err := txRepos.Client.FlagAtRisk(ctx, now)
+		if err != nil {
+			return err
+		}
+		result.Flagged = len(flagged)
+
+		if s.events == nil {
+			return nil
+		}
+		pending := make([]events.PendingEvent, 0, len(flagged))
+		for _, client := range flagged {
+			id := strconv.FormatUint(uint64(client.ClientID), 10)
+			pending = append(pending, events.PendingEvent{
+				EventType:      events.EventTypeClientAtRisk,
+				AggregateType:  "client",
+				AggregateID:    id,
+				IdempotencyKey: events.BuildIdempotencyKey(events.EventTypeClientAtRisk, "client", id, strconv.FormatInt(client.AtRiskSince.Unix(), 10)),
+				Payload: events.ClientAtRiskPayload{
+					ClientID:       client.ClientID,
+					CoachID:        client.CoachID,
+					CoachUserID:    client.CoachUserID,
+					ClientName:     strings.TrimSpace(client.FirstName + " " + client.LastName),
+					LastActivityAt: client.LastActivityAt,
+					InactiveDays:   client.AtRiskInactivityDays,
+				},
+			})
+		}
+		return s.events.PublishManyInTx(ctx, tx, pending)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,113 @@
+package services
+
+import (
+	"chalk-api/pkg/repositories"
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrClientSessionConflict is returned when a client books themselves over another of their sessions,
+// possibly with a different coach.
+var ErrClientSessionConflict = errors.New("client already has a session at this time")
+
+// SessionWarningClientDoubleBooked is set when a coach books a client who already has a session
+// overlapping the new one.
+const SessionWarningClientDoubleBooked = "client_double_booked"
+
+// SessionWarning is something the booker should know about a session that was still booked. The
+// other session's coach is deliberately left out; it may not be the booker.
+type SessionWarning struct {
+	Code    string    `json:"code"`
+	StartAt time.Time `json:"start_at"`
+	EndAt   time.Time `json:"end_at"`
+}
+
+// checkClientConflict looks for another session the client user is booked into over
+// [startAt, startAt+durationMinutes). A client booking themselves is refused; a coach booking on their
+// behalf gets the overlap back as a warning and the booking goes ahead.
+func checkClientConflict(
+	ctx context.Context,
+	sessionRepo *repositories.SessionRepository,
+	clientUserID uint,
+	startAt time.Time,
+	durationMinutes int,
+	excludeSessionID *uint,
+	bookedBy string,
+) ([]SessionWarning, error) {
+	endAt := startAt.Add(time.Duration(durationMinutes) * time.Minute)
+	busy, err := sessionRepo.HasClientConflict(ctx, clientUserID, startAt, endAt, excludeSessionID)
+	if err != nil || busy == nil {
+		return nil, err
+	}
+	if bookedBy == "client" {
+		return nil, ErrClientSessionConflict
+	}
+	return []SessionWarning{{
+		Code:    SessionWarningClientDoubleBooked,
+		StartAt: busy.StartAt.UTC(),
+		EndAt:   busy.EndAt.UTC(),
+	}}, nil
+}
+
+// WithoutClientConflicts returns a copy of slots minus any that overlap a session the client behind
+// clientProfileID is already booked into, with any coach. The caller must be that client or the coach
+// the profile belongs to, and the profile must be with the coach whose slots these are.
+func (s *SessionService) WithoutClientConflicts(ctx context.Context, userID, clientProfileID uint, slots *BookableSlots) (*BookableSlots, error) {
+	clientProfile, err := s.clientRepo.GetByID(ctx, clientProfileID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrClientProfileNotFound
+		}
+		return nil, err
+	}
+	if clientProfile.CoachID != slots.CoachID {
+		return nil, ErrClientProfileForbidden
+	}
+	if _, err := s.resolveBookedBy(ctx, userID, clientProfile.CoachID, clientProfile.UserID); err != nil {
+		return nil, err
+	}
+
+	filtered := &BookableSlots{
+		CoachID:         slots.CoachID,
+		SessionTypeID:   slots.SessionTypeID,
+		DurationMinutes: slots.DurationMinutes,
+		Days:            []BookableDay{},
+	}
+	if slots.Total == 0 {
+		return filtered, nil
+	}
+
+	first := slots.Days[0].Slots[0].StartAt
+	lastDay := slots.Days[len(slots.Days)-1]
+	last := lastDay.Slots[len(lastDay.Slots)-1].EndAt
+	busy, err := s.sessionRepo.ListClientBusyTimes(ctx, clientProfile.UserID, first, last)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, day := range slots.Days {
+		open := make([]BookableTime, 0, len(day.Slots))
+		for _, slot := range day.Slots {
+			if !overlapsClientBusy(slot, busy) {
+				open = append(open, slot)
+			}
+		}
+		if len(open) > 0 {
+			filtered.Days = append(filtered.Days, BookableDay{Date: day.Date, Slots: open})
+			filtered.Total += len(open)
+		}
+	}
+	return filtered, nil
+}
+
+func overlapsClientBusy(slot BookableTime, busy []repositories.ClientBusyTime) bool {
+	for _, b := range busy {
+		if slot.StartAt.Before(b.EndAt) && b.StartAt.Before(slot.EndAt) {
+			return true
+		}
+	}
+	return false
+}
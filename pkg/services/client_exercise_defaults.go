@@ -0,0 +1,149 @@
+package services
+
+import (
+	"chalk-api/pkg/models"
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrClientExerciseDefaultInvalid  = errors.New("invalid client exercise default")
+	ErrClientExerciseDefaultNotFound = errors.New("client exercise default not found")
+)
+
+// SetClientExerciseDefaultInput replaces a client's default for one exercise. At least one override
+// is required; a weight needs its unit, reps_max needs reps_min, and reps only apply to reps
+// exercises.
+type SetClientExerciseDefaultInput struct {
+	WeightValue *float64 `json:"weight_value" binding:"omitempty,gt=0"`
+	WeightUnit  *string  `json:"weight_unit" binding:"omitempty,weight_unit"`
+	RepsMin     *int     `json:"reps_min" binding:"omitempty,gt=0"`
+	RepsMax     *int     `json:"reps_max" binding:"omitempty,gt=0"`
+	RestSeconds *int     `json:"rest_seconds" binding:"omitempty,gte=0"`
+}
+
+// SetClientExerciseDefault saves the calling coach's default prescription of an exercise for one of
+// their clients. Later template assignments to the client use it in place of the template's values.
+func (s *WorkoutService) SetClientExerciseDefault(ctx context.Context, userID, clientProfileID, exerciseID uint, input SetClientExerciseDefaultInput) (*models.ClientExerciseDefault, error) {
+	clientProfile, err := s.getCoachClient(ctx, userID, clientProfileID)
+	if err != nil {
+		return nil, err
+	}
+	exercise, err := s.getActiveExercise(ctx, exerciseID)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.WeightValue == nil && input.RepsMin == nil && input.RestSeconds == nil {
+		return nil, ErrClientExerciseDefaultInvalid
+	}
+	if (input.WeightValue == nil) != (input.WeightUnit == nil) {
+		return nil, ErrClientExerciseDefaultInvalid
+	}
+	if input.RepsMax != nil && (input.RepsMin == nil || *input.RepsMax < *input.RepsMin) {
+		return nil, ErrClientExerciseDefaultInvalid
+	}
+	if (input.RepsMin != nil || input.RepsMax != nil) && exercise.MeasurementType != MeasurementTypeReps {
+		return nil, ErrClientExerciseDefaultInvalid
+	}
+
+	exerciseDefault := &models.ClientExerciseDefault{
+		ClientProfileID: clientProfile.ID,
+		ExerciseID:      exercise.ID,
+		WeightValue:     input.WeightValue,
+		WeightUnit:      input.WeightUnit,
+		RepsMin:         input.RepsMin,
+		RepsMax:         input.RepsMax,
+		RestSeconds:     input.RestSeconds,
+	}
+	if err := s.workoutRepo.UpsertClientExerciseDefault(ctx, exerciseDefault); err != nil {
+		return nil, err
+	}
+	exerciseDefault.Exercise = *exercise
+	return exerciseDefault, nil
+}
+
+// ListClientExerciseDefaults returns every exercise default set for the client.
+func (s *WorkoutService) ListClientExerciseDefaults(ctx context.Context, userID, clientProfileID uint) ([]models.ClientExerciseDefault, error) {
+	clientProfile, err := s.getCoachClient(ctx, userID, clientProfileID)
+	if err != nil {
+		return nil, err
+	}
+	return s.workoutRepo.ListClientExerciseDefaults(ctx, clientProfile.ID)
+}
+
+// DeleteClientExerciseDefault removes the client's default for an exercise, so assignments go back
+// to the template's values. Workouts already assigned keep what they were given.
+func (s *WorkoutService) DeleteClientExerciseDefault(ctx context.Context, userID, clientProfileID, exerciseID uint) error {
+	clientProfile, err := s.getCoachClient(ctx, userID, clientProfileID)
+	if err != nil {
+		return err
+	}
+	deleted, err := s.workoutRepo.DeleteClientExerciseDefault(ctx, clientProfile.ID, exerciseID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrClientExerciseDefaultNotFound
+	}
+	return nil
+}
+
+// getCoachClient loads a client profile and checks it belongs to the calling coach.
+func (s *WorkoutService) getCoachClient(ctx context.Context, userID, clientProfileID uint) (*models.ClientProfile, error) {
+	coachProfile, err := s.getCoachProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	clientProfile, err := s.clientRepo.GetByID(ctx, clientProfileID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrClientProfileNotFound
+		}
+		return nil, err
+	}
+	if clientProfile.CoachID != coachProfile.ID {
+		return nil, ErrClientProfileForbidden
+	}
+	return clientProfile, nil
+}
+
+// withClientExerciseDefaults returns a copy of template whose exercises carry the client's defaults
+// in place of the template's values, and the IDs of the exercises that got one. The template itself
+// is left untouched. Metrics are cleared so they are recomputed from the overridden prescription.
+func withClientExerciseDefaults(template *models.WorkoutTemplate, defaults map[uint]models.ClientExerciseDefault) (*models.WorkoutTemplate, map[uint]bool) {
+	applied := make(map[uint]bool, len(defaults))
+	if len(defaults) == 0 {
+		return template, applied
+	}
+
+	copied := *template
+	copied.Exercises = make([]models.WorkoutTemplateExercise, len(template.Exercises))
+	copy(copied.Exercises, template.Exercises)
+	for i := range copied.Exercises {
+		exercise := &copied.Exercises[i]
+		exerciseDefault, ok := defaults[exercise.ExerciseID]
+		if !ok {
+			continue
+		}
+		if exerciseDefault.WeightValue != nil {
+			exercise.WeightValue = exerciseDefault.WeightValue
+			exercise.WeightUnit = exerciseDefault.WeightUnit
+		}
+		if exerciseDefault.RepsMin != nil {
+			// Reps replace the template's range as a whole; a lone reps_min is a fixed target
+			exercise.RepsMin = exerciseDefault.RepsMin
+			exercise.RepsMax = exerciseDefault.RepsMax
+		}
+		if exerciseDefault.RestSeconds != nil {
+			exercise.RestSeconds = exerciseDefault.RestSeconds
+		}
+		applied[exercise.ExerciseID] = true
+	}
+	if len(applied) > 0 {
+		copied.Metrics = nil
+	}
+	return &copied, applied
+}
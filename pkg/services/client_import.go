@@ -0,0 +1,492 @@
+package services
+
+import (
+	"bytes"
+	"chalk-api/pkg/events"
+	"chalk-api/pkg/models"
+	"chalk-api/pkg/repositories"
+	"context"
+	"encoding/csv"
+	"errors"
+	"io"
+	"log/slog"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidClientImportFile = errors.New("client import must be a CSV file with email, first_name and last_name columns")
+	ErrClientImportEmpty       = errors.New("client import has no rows")
+	ErrClientImportTooLarge    = errors.New("client import has too many rows")
+	ErrClientImportNotFound    = errors.New("client import not found")
+)
+
+const (
+	// MaxClientImportBytes caps the uploaded CSV; 500 rows fit comfortably
+	MaxClientImportBytes = 1 << 20
+
+	maxClientImportRows = 500
+	// Imports up to this size are processed during the upload; larger ones go to the client import worker
+	clientImportInlineRows = 50
+	// Results are saved every this many rows so the status endpoint shows progress
+	clientImportProgressRows = 25
+	// A processing import not updated for this long was abandoned by its worker and is claimed again
+	clientImportStaleAfter = 15 * time.Minute
+
+	clientInvitationExpiryDays = 30
+	maxImportNameLength        = 100
+	maxImportPhoneLength       = 30
+	maxImportGoalsLength       = 2000
+)
+
+// Client import row outcomes
+const (
+	ClientImportRowInvited          = "invited"   // no account yet; an invitation email is queued
+	ClientImportRowRequested        = "requested" // existing account; invited in-app with a personal code
+	ClientImportRowAlreadyConnected = "already_connected"
+	ClientImportRowAlreadyInvited   = "already_invited" // an earlier invitation's code is still open
+	ClientImportRowDuplicate        = "duplicate"       // the email appeared earlier in the file
+	ClientImportRowError            = "error"
+)
+
+// Reasons an import row is rejected
+const (
+	ClientImportReasonInvalidEmail     = "invalid_email"
+	ClientImportReasonMissingFirstName = "missing_first_name"
+	ClientImportReasonMissingLastName  = "missing_last_name"
+	ClientImportReasonFieldTooLong     = "field_too_long"
+	ClientImportReasonInvalidTag       = "invalid_tag"
+	ClientImportReasonTooManyTags      = "too_many_tags"
+	ClientImportReasonSelfInvite       = "self_invite"
+	ClientImportReasonFailed           = "import_failed" // an unexpected error; the row can be imported again
+)
+
+// clientImportColumns maps accepted header spellings, after normalizeImportHeader, to row fields.
+var clientImportColumns = map[string]string{
+	"email":         "email",
+	"email_address": "email",
+	"e_mail":        "email",
+	"first_name":    "first_name",
+	"firstname":     "first_name",
+	"last_name":     "last_name",
+	"lastname":      "last_name",
+	"phone":         "phone",
+	"phone_number":  "phone",
+	"goals":         "goals",
+	"goal":          "goals",
+	"tags":          "tags",
+}
+
+// ImportClients reads a coach's client list from CSV and invites each row: people with an account
+// get an in-app invitation, new emails an invitation email, each with a personal single-use invite
+// code. Files of up to 50 rows are processed before returning; larger ones are queued for the client
+// import worker and the returned import is still pending.
+func (s *CoachService) ImportClients(ctx context.Context, userID uint, data []byte) (*models.ClientImport, error) {
+	profile, err := s.coachRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCoachProfileNotFound
+		}
+		return nil, err
+	}
+
+	rows, err := parseClientImportCSV(data)
+	if err != nil {
+		return nil, err
+	}
+
+	clientImport := &models.ClientImport{
+		CoachID:           profile.ID,
+		RequestedByUserID: userID,
+		Status:            models.ClientImportPending,
+		TotalRows:         len(rows),
+		Rows:              rows,
+		Results:           []models.ClientImportResult{},
+	}
+	if len(rows) <= clientImportInlineRows {
+		clientImport.Status = models.ClientImportProcessing
+	}
+	if err := s.clientRepo.CreateClientImport(ctx, clientImport); err != nil {
+		return nil, err
+	}
+	if clientImport.Status == models.ClientImportPending {
+		return clientImport, nil
+	}
+
+	if err := s.processClientImport(ctx, clientImport); err != nil {
+		return nil, err
+	}
+	return clientImport, nil
+}
+
+// GetClientImport returns one of the calling coach's imports with the results so far.
+func (s *CoachService) GetClientImport(ctx context.Context, userID, importID uint) (*models.ClientImport, error) {
+	profile, err := s.coachRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCoachProfileNotFound
+		}
+		return nil, err
+	}
+
+	clientImport, err := s.clientRepo.GetClientImport(ctx, importID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrClientImportNotFound
+		}
+		return nil, err
+	}
+	if clientImport.CoachID != profile.ID {
+		return nil, ErrClientImportNotFound
+	}
+	return clientImport, nil
+}
+
+// ProcessPendingClientImports works through queued imports one at a time until none are left or
+// ctx ends, and returns how many it finished.
+func (s *CoachService) ProcessPendingClientImports(ctx context.Context) (int, error) {
+	processed := 0
+	for {
+		if err := ctx.Err(); err != nil {
+			return processed, err
+		}
+		clientImport, err := s.clientRepo.ClaimClientImport(ctx, time.Now().UTC().Add(-clientImportStaleAfter))
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return processed, nil
+			}
+			return processed, err
+		}
+		if err := s.processClientImport(ctx, clientImport); err != nil {
+			return processed, err
+		}
+		processed++
+	}
+}
+
+// processClientImport imports every row not yet in Results, saving progress as it goes so an
+// interrupted import resumes where it stopped. The import is marked failed if the coach is gone.
+func (s *CoachService) processClientImport(ctx context.Context, clientImport *models.ClientImport) error {
+	coach, err := s.coachRepo.GetByID(ctx, clientImport.CoachID)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		reason := "coach profile no longer exists"
+		clientImport.FailureReason = &reason
+		clientImport.Status = models.ClientImportFailed
+		return s.clientRepo.SaveClientImport(ctx, clientImport)
+	}
+
+	// Emails already handled by earlier rows, including rows done before an interruption
+	seen := make(map[string]bool, len(clientImport.Rows))
+	for _, result := range clientImport.Results {
+		if result.Email != "" {
+			seen[result.Email] = true
+		}
+	}
+
+	for i := len(clientImport.Results); i < len(clientImport.Rows); i++ {
+		result := s.importClientRow(ctx, coach, clientImport.ID, clientImport.Rows[i], seen)
+		clientImport.Results = append(clientImport.Results, result)
+		countClientImportResult(clientImport, result)
+
+		if (i+1)%clientImportProgressRows == 0 && i+1 < len(clientImport.Rows) {
+			if err := s.clientRepo.SaveClientImport(ctx, clientImport); err != nil {
+				return err
+			}
+		}
+	}
+
+	now := time.Now().UTC()
+	clientImport.Status = models.ClientImportCompleted
+	clientImport.CompletedAt = &now
+	clientImport.Rows = nil
+	if err := s.clientRepo.SaveClientImport(ctx, clientImport); err != nil {
+		return err
+	}
+	slog.Info("Client import completed",
+		"import_id", clientImport.ID,
+		"coach_id", clientImport.CoachID,
+		"invited", clientImport.InvitedCount,
+		"requested", clientImport.RequestedCount,
+		"skipped", clientImport.SkippedCount,
+		"failed", clientImport.FailedCount,
+	)
+	return nil
+}
+
+func countClientImportResult(clientImport *models.ClientImport, result models.ClientImportResult) {
+	switch result.Status {
+	case ClientImportRowInvited:
+		clientImport.InvitedCount++
+	case ClientImportRowRequested:
+		clientImport.RequestedCount++
+	case ClientImportRowError:
+		clientImport.FailedCount++
+	default:
+		clientImport.SkippedCount++
+	}
+}
+
+// importClientRow validates one row and invites the person unless they are already this coach's
+// client or hold an open invitation. Unexpected errors fail only the row.
+func (s *CoachService) importClientRow(
+	ctx context.Context,
+	coach *models.CoachProfile,
+	importID uint,
+	row models.ClientImportRow,
+	seen map[string]bool,
+) models.ClientImportResult {
+	result := models.ClientImportResult{Line: row.Line, Email: normalizeEmail(row.Email)}
+	fail := func(reason string) models.ClientImportResult {
+		result.Status = ClientImportRowError
+		result.Reason = reason
+		return result
+	}
+
+	invitation, reason := validateClientImportRow(row)
+	if reason != "" {
+		return fail(reason)
+	}
+	if seen[invitation.Email] {
+		result.Status = ClientImportRowDuplicate
+		return result
+	}
+	seen[invitation.Email] = true
+
+	user, err := s.repos.User.GetByEmail(ctx, invitation.Email)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		slog.Warn("Client import user lookup failed", "import_id", importID, "line", row.Line, "error", err)
+		return fail(ClientImportReasonFailed)
+	}
+	if user != nil {
+		if user.ID == coach.UserID {
+			return fail(ClientImportReasonSelfInvite)
+		}
+		existing, err := s.clientRepo.GetByUserAndCoach(ctx, user.ID, coach.ID)
+		if err == nil {
+			result.Status = ClientImportRowAlreadyConnected
+			result.ClientProfileID = &existing.ID
+			return result
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			slog.Warn("Client import connection lookup failed", "import_id", importID, "line", row.Line, "error", err)
+			return fail(ClientImportReasonFailed)
+		}
+		invitation.UserID = &user.ID
+	}
+
+	open, err := s.clientRepo.GetOpenClientInvitation(ctx, coach.ID, invitation.Email)
+	if err == nil {
+		result.Status = ClientImportRowAlreadyInvited
+		result.InvitationID = &open.ID
+		return result
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		slog.Warn("Client import invitation lookup failed", "import_id", importID, "line", row.Line, "error", err)
+		return fail(ClientImportReasonFailed)
+	}
+
+	invitation.CoachID = coach.ID
+	invitation.ImportID = &importID
+	if err := s.createClientInvitation(ctx, invitation); err != nil {
+		slog.Warn("Client import invitation failed", "import_id", importID, "line", row.Line, "error", err)
+		return fail(ClientImportReasonFailed)
+	}
+
+	result.Status = ClientImportRowInvited
+	if invitation.UserID != nil {
+		result.Status = ClientImportRowRequested
+	}
+	result.InvitationID = &invitation.ID
+	return result
+}
+
+// createClientInvitation gives the invitation its own single-use invite code, then stores it and
+// queues client.invited in one transaction.
+func (s *CoachService) createClientInvitation(ctx context.Context, invitation *models.ClientInvitation) error {
+	invite := &models.InviteCode{
+		CoachID:   invitation.CoachID,
+		ExpiresAt: time.Now().UTC().AddDate(0, 0, clientInvitationExpiryDays),
+		IsActive:  true,
+		MaxUses:   1,
+	}
+	if err := s.createInviteCode(ctx, invite); err != nil {
+		return err
+	}
+	invitation.InviteCodeID = invite.ID
+
+	return s.repos.WithTransaction(ctx, func(tx *gorm.DB, txRepos *repositories.RepositoriesCollection) error {
+		if err := txRepos.Client.CreateClientInvitation(ctx, invitation); err != nil {
+			return err
+		}
+		if s.eventsPublisher == nil {
+			return nil
+		}
+
+		payload := events.ClientInvitedPayload{
+			InvitationID: invitation.ID,
+			CoachID:      invitation.CoachID,
+			Email:        invitation.Email,
+			FirstName:    invitation.FirstName,
+			Code:         invite.Code,
+			ExpiresAt:    invite.ExpiresAt,
+		}
+		if invitation.UserID != nil {
+			payload.UserID = *invitation.UserID
+		}
+		id := strconv.FormatUint(uint64(invitation.ID), 10)
+		return s.eventsPublisher.PublishInTx(
+			ctx,
+			tx,
+			events.EventTypeClientInvited,
+			"client_invitation",
+			id,
+			events.BuildIdempotencyKey(events.EventTypeClientInvited, id),
+			payload,
+		)
+	})
+}
+
+// validateClientImportRow turns a row into an unsaved invitation, or returns why it can't be imported.
+// Tags may be separated by commas or semicolons and follow the same rules as client tags.
+func validateClientImportRow(row models.ClientImportRow) (*models.ClientInvitation, string) {
+	email := normalizeEmail(row.Email)
+	if address, err := mail.ParseAddress(email); err != nil || address.Address != email {
+		return nil, ClientImportReasonInvalidEmail
+	}
+	firstName := strings.TrimSpace(row.FirstName)
+	if firstName == "" {
+		return nil, ClientImportReasonMissingFirstName
+	}
+	lastName := strings.TrimSpace(row.LastName)
+	if lastName == "" {
+		return nil, ClientImportReasonMissingLastName
+	}
+	phone := strings.TrimSpace(row.Phone)
+	goals := strings.TrimSpace(row.Goals)
+	if utf8.RuneCountInString(firstName) > maxImportNameLength ||
+		utf8.RuneCountInString(lastName) > maxImportNameLength ||
+		utf8.RuneCountInString(phone) > maxImportPhoneLength ||
+		utf8.RuneCountInString(goals) > maxImportGoalsLength {
+		return nil, ClientImportReasonFieldTooLong
+	}
+
+	tags := []string{}
+	seen := map[string]bool{}
+	for _, raw := range strings.FieldsFunc(row.Tags, func(r rune) bool { return r == ',' || r == ';' }) {
+		if strings.TrimSpace(raw) == "" {
+			continue
+		}
+		tag, err := validateClientTag(raw)
+		if err != nil {
+			return nil, ClientImportReasonInvalidTag
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+	if len(tags) > maxClientTags {
+		return nil, ClientImportReasonTooManyTags
+	}
+
+	return &models.ClientInvitation{
+		Email:     email,
+		FirstName: firstName,
+		LastName:  lastName,
+		Phone:     trimPtr(&phone),
+		Goals:     trimPtr(&goals),
+		Tags:      tags,
+		Status:    models.ClientInvitationPending,
+	}, ""
+}
+
+// parseClientImportCSV reads the header and data rows of an import file. Header names are matched
+// case-insensitively with spaces and dashes treated as underscores, so "First Name" works; unknown
+// columns are ignored and blank lines skipped.
+func parseClientImportCSV(data []byte) ([]models.ClientImportRow, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")) // spreadsheet exports often start with a BOM
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, ErrClientImportEmpty
+		}
+		return nil, ErrInvalidClientImportFile
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		if field, ok := clientImportColumns[normalizeImportHeader(name)]; ok {
+			if _, dup := columns[field]; !dup {
+				columns[field] = i
+			}
+		}
+	}
+	for _, required := range []string{"email", "first_name", "last_name"} {
+		if _, ok := columns[required]; !ok {
+			return nil, ErrInvalidClientImportFile
+		}
+	}
+
+	var rows []models.ClientImportRow
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, ErrInvalidClientImportFile
+		}
+
+		blank := true
+		for _, value := range record {
+			if strings.TrimSpace(value) != "" {
+				blank = false
+				break
+			}
+		}
+		if blank {
+			continue
+		}
+		if len(rows) == maxClientImportRows {
+			return nil, ErrClientImportTooLarge
+		}
+
+		line, _ := reader.FieldPos(0)
+		field := func(name string) string {
+			i, ok := columns[name]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+		rows = append(rows, models.ClientImportRow{
+			Line:      line,
+			Email:     field("email"),
+			FirstName: field("first_name"),
+			LastName:  field("last_name"),
+			Phone:     field("phone"),
+			Goals:     field("goals"),
+			Tags:      field("tags"),
+		})
+	}
+	if len(rows) == 0 {
+		return nil, ErrClientImportEmpty
+	}
+	return rows, nil
+}
+
+func normalizeImportHeader(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	return strings.NewReplacer(" ", "_", "-", "_").Replace(name)
+}
@@ -0,0 +1,162 @@
+package services
+
+import (
+	"chalk-api/pkg/models"
+	"chalk-api/pkg/repositories"
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrClientNoteInvalid  = errors.New("note body is required")
+	ErrClientNoteNotFound = errors.New("client note not found")
+)
+
+type CreateClientNoteInput struct {
+	Body   string `json:"body" binding:"required,max=10000"`
+	Pinned bool   `json:"pinned"`
+}
+
+type UpdateClientNoteInput struct {
+	Body   *string `json:"body" binding:"omitempty,max=10000"`
+	Pinned *bool   `json:"pinned"`
+}
+
+// ClientNoteListInput pages through notes; Query is a full-text search such as "knee pain".
+type ClientNoteListInput struct {
+	Query  string
+	Limit  int
+	Offset int
+}
+
+// CreateClientNote adds a private note to one of the calling coach's clients.
+func (s *CoachService) CreateClientNote(ctx context.Context, userID, clientProfileID uint, input CreateClientNoteInput) (*models.ClientNote, error) {
+	clientProfile, err := getCoachClientProfile(ctx, s.repos, userID, clientProfileID)
+	if err != nil {
+		return nil, err
+	}
+
+	body := strings.TrimSpace(input.Body)
+	if body == "" {
+		return nil, ErrClientNoteInvalid
+	}
+
+	note := &models.ClientNote{
+		ClientID:     clientProfile.ID,
+		CoachID:      clientProfile.CoachID,
+		AuthorUserID: userID,
+		Body:         body,
+	}
+	if input.Pinned {
+		now := time.Now().UTC()
+		note.Pinned = true
+		note.PinnedAt = &now
+	}
+	if err := s.clientRepo.CreateNote(ctx, note); err != nil {
+		return nil, err
+	}
+	return note, nil
+}
+
+// ListClientNotes returns the notes on one client, pinned first and then newest first.
+func (s *CoachService) ListClientNotes(ctx context.Context, userID, clientProfileID uint, input ClientNoteListInput) ([]models.ClientNote, int64, error) {
+	clientProfile, err := getCoachClientProfile(ctx, s.repos, userID, clientProfileID)
+	if err != nil {
+		return nil, 0, err
+	}
+	return s.listClientNotes(ctx, clientProfile.CoachID, clientProfile.ID, input)
+}
+
+// SearchMyClientNotes searches the notes on all of the calling coach's clients, so a coach can find
+// a note without remembering which client it was about.
+func (s *CoachService) SearchMyClientNotes(ctx context.Context, userID uint, input ClientNoteListInput) ([]models.ClientNote, int64, error) {
+	profile, err := s.coachRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, 0, ErrCoachProfileNotFound
+		}
+		return nil, 0, err
+	}
+	return s.listClientNotes(ctx, profile.ID, 0, input)
+}
+
+func (s *CoachService) listClientNotes(ctx context.Context, coachID, clientProfileID uint, input ClientNoteListInput) ([]models.ClientNote, int64, error) {
+	limit := input.Limit
+	if limit <= 0 {
+		limit = 20
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	offset := input.Offset
+	if offset < 0 {
+		offset = 0
+	}
+
+	return s.clientRepo.ListNotes(ctx, coachID, repositories.ClientNoteFilter{
+		ClientID: clientProfileID,
+		Query:    strings.TrimSpace(input.Query),
+	}, limit, offset)
+}
+
+// UpdateClientNote edits a note's body and pins or unpins it. Pinning an already pinned note keeps
+// its original pin time.
+func (s *CoachService) UpdateClientNote(ctx context.Context, userID, clientProfileID, noteID uint, input UpdateClientNoteInput) (*models.ClientNote, error) {
+	note, err := s.getOwnedClientNote(ctx, userID, clientProfileID, noteID)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Body != nil {
+		body := strings.TrimSpace(*input.Body)
+		if body == "" {
+			return nil, ErrClientNoteInvalid
+		}
+		note.Body = body
+	}
+	if input.Pinned != nil && *input.Pinned != note.Pinned {
+		note.Pinned = *input.Pinned
+		note.PinnedAt = nil
+		if note.Pinned {
+			now := time.Now().UTC()
+			note.PinnedAt = &now
+		}
+	}
+
+	if err := s.clientRepo.UpdateNote(ctx, note); err != nil {
+		return nil, err
+	}
+	return note, nil
+}
+
+func (s *CoachService) DeleteClientNote(ctx context.Context, userID, clientProfileID, noteID uint) error {
+	if _, err := s.getOwnedClientNote(ctx, userID, clientProfileID, noteID); err != nil {
+		return err
+	}
+	return s.clientRepo.DeleteNote(ctx, noteID)
+}
+
+// getOwnedClientNote loads a note on one of the calling coach's clients; a note on another client
+// reads as not found.
+func (s *CoachService) getOwnedClientNote(ctx context.Context, userID, clientProfileID, noteID uint) (*models.ClientNote, error) {
+	clientProfile, err := getCoachClientProfile(ctx, s.repos, userID, clientProfileID)
+	if err != nil {
+		return nil, err
+	}
+
+	note, err := s.clientRepo.GetNoteByID(ctx, noteID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrClientNoteNotFound
+		}
+		return nil, err
+	}
+	if note.ClientID != clientProfile.ID || note.CoachID != clientProfile.CoachID {
+		return nil, ErrClientNoteNotFound
+	}
+	return note, nil
+}
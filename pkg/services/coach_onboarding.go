@@ -0,0 +1,198 @@
+package services
+
+import (
+	"chalk-api/pkg/models"
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// Onboarding checklist steps, in the order the app presents them
+const (
+	OnboardingStepProfile       = "profile"
+	OnboardingStepAvailability  = "availability"
+	OnboardingStepSessionTypes  = "session_types"
+	OnboardingStepFirstInvite   = "first_invite"
+	OnboardingStepFirstTemplate = "first_template"
+)
+
+// onboardingStepDefinition describes one checklist step. Optional steps are shown but don't hold
+// back OnboardingCompleted.
+type onboardingStepDefinition struct {
+	Key      string
+	Title    string
+	Required bool
+	DeepLink string
+}
+
+var onboardingSteps = []onboardingStepDefinition{
+	{Key: OnboardingStepProfile, Title: "Complete your profile", Required: true, DeepLink: "chalk://coach/profile"},
+	{Key: OnboardingStepAvailability, Title: "Set your weekly availability", Required: true, DeepLink: "chalk://coach/availability"},
+	{Key: OnboardingStepSessionTypes, Title: "Create a session type", Required: true, DeepLink: "chalk://coach/session-types"},
+	{Key: OnboardingStepFirstInvite, Title: "Invite your first client", Required: true, DeepLink: "chalk://coach/invites"},
+	{Key: OnboardingStepFirstTemplate, Title: "Build a workout template", Required: false, DeepLink: "chalk://coach/templates"},
+}
+
+// onboardingTracker records checklist steps finished in other services; CoachService implements it.
+type onboardingTracker interface {
+	CompleteOnboardingStep(ctx context.Context, coachID uint, step string)
+}
+
+// OnboardingStep is one checklist entry. DeepLink opens the screen where the step is done.
+type OnboardingStep struct {
+	Key         string     `json:"key"`
+	Title       string     `json:"title"`
+	Required    bool       `json:"required"`
+	Completed   bool       `json:"completed"`
+	CompletedAt *time.Time `json:"completed_at"`
+	DeepLink    string     `json:"deep_link"`
+}
+
+// OnboardingProgress is the coach's checklist. NextStep is the first incomplete step, required or
+// not, and is null once every step is done.
+type OnboardingProgress struct {
+	Steps               []OnboardingStep `json:"steps"`
+	CompletedSteps      int              `json:"completed_steps"`
+	TotalSteps          int              `json:"total_steps"`
+	OnboardingCompleted bool             `json:"onboarding_completed"`
+	NextStep            *string          `json:"next_step"`
+}
+
+// GetMyOnboarding returns the caller's checklist. Steps are first recomputed from the coach's data,
+// so work done before the checklist existed (or missed by a hook) is picked up, and onboarding is
+// marked completed once every required step is done.
+func (s *CoachService) GetMyOnboarding(ctx context.Context, userID uint) (*OnboardingProgress, error) {
+	profile, err := s.GetMyProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := s.coachRepo.GetOnboardingData(ctx, profile.ID)
+	if err != nil {
+		return nil, err
+	}
+	satisfied := map[string]bool{
+		OnboardingStepProfile:       coachProfileComplete(profile),
+		OnboardingStepAvailability:  data.HasAvailability,
+		OnboardingStepSessionTypes:  data.HasSessionTypes,
+		OnboardingStepFirstInvite:   data.HasInviteCodes,
+		OnboardingStepFirstTemplate: data.HasTemplates,
+	}
+
+	now := time.Now().UTC()
+	missing := models.OnboardingState{}
+	for _, step := range onboardingSteps {
+		if _, done := profile.OnboardingState[step.Key]; !done && satisfied[step.Key] {
+			missing[step.Key] = now
+		}
+	}
+	if len(missing) > 0 {
+		if err := s.coachRepo.AddOnboardingSteps(ctx, profile.ID, missing); err != nil {
+			return nil, err
+		}
+		if profile.OnboardingState == nil {
+			profile.OnboardingState = models.OnboardingState{}
+		}
+		for step, completedAt := range missing {
+			profile.OnboardingState[step] = completedAt
+		}
+	}
+	if err := s.syncOnboardingCompleted(ctx, profile); err != nil {
+		return nil, err
+	}
+
+	return buildOnboardingProgress(profile), nil
+}
+
+// CompleteOnboardingStep records step for the coach and completes onboarding when it was the last
+// required one. It runs after the action that finished the step has succeeded, so failures are
+// logged rather than returned; the next GetMyOnboarding recomputes the step anyway.
+func (s *CoachService) CompleteOnboardingStep(ctx context.Context, coachID uint, step string) {
+	if err := s.completeOnboardingStep(ctx, coachID, step); err != nil {
+		slog.Warn("Failed to record onboarding step", "coach_id", coachID, "step", step, "error", err)
+	}
+}
+
+func (s *CoachService) completeOnboardingStep(ctx context.Context, coachID uint, step string) error {
+	profile, err := s.coachRepo.GetByID(ctx, coachID)
+	if err != nil {
+		return err
+	}
+	if _, done := profile.OnboardingState[step]; done {
+		return nil
+	}
+
+	completedAt := time.Now().UTC()
+	if err := s.coachRepo.AddOnboardingSteps(ctx, coachID, models.OnboardingState{step: completedAt}); err != nil {
+		return err
+	}
+	if profile.OnboardingState == nil {
+		profile.OnboardingState = models.OnboardingState{}
+	}
+	profile.OnboardingState[step] = completedAt
+	return s.syncOnboardingCompleted(ctx, profile)
+}
+
+// syncOnboardingCompleted sets OnboardingCompleted once every required step is in the profile's
+// state, then completes the coach's referral the same way a profile save would.
+func (s *CoachService) syncOnboardingCompleted(ctx context.Context, profile *models.CoachProfile) error {
+	if profile.OnboardingCompleted || !requiredOnboardingStepsDone(profile.OnboardingState) {
+		return nil
+	}
+	completed, err := s.coachRepo.MarkOnboardingCompleted(ctx, profile.ID)
+	if err != nil || !completed {
+		return err
+	}
+	profile.OnboardingCompleted = true
+	profile.Version++
+	s.coachStore.InvalidateProfile(profile.ID)
+	return s.completeReferral(ctx, profile)
+}
+
+// coachProfileComplete reports whether the profile has what clients need to judge a coach: a
+// business name, a bio and at least one specialty.
+func coachProfileComplete(profile *models.CoachProfile) bool {
+	if profile.BusinessName == nil || strings.TrimSpace(*profile.BusinessName) == "" {
+		return false
+	}
+	if profile.Bio == nil || strings.TrimSpace(*profile.Bio) == "" {
+		return false
+	}
+	return len(profile.Specialties) > 0
+}
+
+func requiredOnboardingStepsDone(state models.OnboardingState) bool {
+	for _, step := range onboardingSteps {
+		if _, done := state[step.Key]; step.Required && !done {
+			return false
+		}
+	}
+	return true
+}
+
+func buildOnboardingProgress(profile *models.CoachProfile) *OnboardingProgress {
+	progress := &OnboardingProgress{
+		Steps:               make([]OnboardingStep, 0, len(onboardingSteps)),
+		TotalSteps:          len(onboardingSteps),
+		OnboardingCompleted: profile.OnboardingCompleted,
+	}
+	for _, definition := range onboardingSteps {
+		step := OnboardingStep{
+			Key:      definition.Key,
+			Title:    definition.Title,
+			Required: definition.Required,
+			DeepLink: definition.DeepLink,
+		}
+		if completedAt, done := profile.OnboardingState[definition.Key]; done {
+			step.Completed = true
+			step.CompletedAt = &completedAt
+			progress.CompletedSteps++
+		} else if progress.NextStep == nil {
+			key := definition.Key
+			progress.NextStep = &key
+		}
+		progress.Steps = append(progress.Steps, step)
+	}
+	return progress
+}
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"chalk-api/pkg/repositories"
+	"chalk-api/pkg/stores"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"strconv"
+	"time"
+)
+
+const (
+	profileViewSeriesDays = 30
+
+	// Days still held in Redis; flushed by the worker and overlaid on stored counts when reading.
+	// Keep in step with stores.ProfileViewTTL.
+	profileViewLiveDays = 3
+)
+
+// ProfileViewSeries is a coach's daily public profile views, oldest first, with every day present.
+type ProfileViewSeries struct {
+	StartDate string                        `json:"start_date"`
+	EndDate   string                        `json:"end_date"`
+	Total     int64                         `json:"total"`
+	Days      []repositories.ProfileViewDay `json:"days"`
+}
+
+// RecordPublicProfileView counts a view of profile by the signed-in viewer. Coaches looking at
+// their own profile are not counted.
+func (s *CoachService) RecordPublicProfileView(profile *stores.CachedCoachProfile, viewerUserID uint, viewerIP string) {
+	if profile == nil || profile.UserID == viewerUserID {
+		return
+	}
+	recordProfileView(s.coachStore, profile.ID, profileViewer(viewerUserID, viewerIP))
+}
+
+// RecordBookingPageView counts an anonymous view of a coach's public booking page.
+func (s *SessionService) RecordBookingPageView(page *PublicBookingPage, viewerIP string) {
+	if page == nil {
+		return
+	}
+	recordProfileView(s.coachStore, page.Coach.ID, profileViewer(0, viewerIP))
+}
+
+// profileViewer identifies a viewer for per-day deduplication: signed-in users by ID, anyone else
+// by a hash of their IP so raw addresses are never written to Redis.
+func profileViewer(userID uint, ip string) string {
+	if userID != 0 {
+		return "u:" + strconv.FormatUint(uint64(userID), 10)
+	}
+	if ip == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(ip))
+	return "ip:" + hex.EncodeToString(sum[:16])
+}
+
+// recordProfileView writes the view in the background so public endpoints never wait on Redis or
+// see its errors. Without Redis, views aren't counted at all.
+func recordProfileView(coachStore *stores.CoachStore, coachID uint, viewer string) {
+	if coachID == 0 || viewer == "" || !coachStore.ProfileViewsEnabled() {
+		return
+	}
+	day := time.Now().UTC().Format("2006-01-02")
+	go coachStore.RecordProfileView(coachID, day, viewer)
+}
+
+// GetProfileViewSeries returns the caller's profile views for the last 30 UTC days, today included.
+// Stored counts are topped up with the live Redis counts for days not yet flushed.
+func (s *CoachService) GetProfileViewSeries(ctx context.Context, userID uint) (*ProfileViewSeries, error) {
+	profile, err := s.GetMyProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	start := today.AddDate(0, 0, -(profileViewSeriesDays - 1))
+	series := &ProfileViewSeries{
+		StartDate: start.Format("2006-01-02"),
+		EndDate:   today.Format("2006-01-02"),
+		Days:      make([]repositories.ProfileViewDay, 0, profileViewSeriesDays),
+	}
+
+	stored, err := s.coachRepo.ListProfileViews(ctx, profile.ID, series.StartDate, series.EndDate)
+	if err != nil {
+		return nil, err
+	}
+	byDate := make(map[string]int64, len(stored))
+	for _, day := range stored {
+		byDate[day.Date] = day.Views
+	}
+
+	for i := 0; i < profileViewSeriesDays; i++ {
+		date := start.AddDate(0, 0, i).Format("2006-01-02")
+		views := byDate[date]
+		if i >= profileViewSeriesDays-profileViewLiveDays {
+			if live, ok := s.coachStore.GetProfileViews(profile.ID, date); ok && live > views {
+				views = live
+			}
+		}
+		series.Days = append(series.Days, repositories.ProfileViewDay{Date: date, Views: views})
+		series.Total += views
+	}
+	return series, nil
+}
+
+// FlushProfileViews copies the daily view counts still in Redis to coach_profile_views and returns
+// how many coach-days were written. Days are rewritten on every run until their Redis keys expire,
+// which is harmless because stored counts only move up.
+func (s *CoachService) FlushProfileViews(ctx context.Context, now time.Time) (int64, error) {
+	if !s.coachStore.ProfileViewsEnabled() {
+		return 0, nil
+	}
+
+	var written int64
+	today := now.UTC().Truncate(24 * time.Hour)
+	for i := 0; i < profileViewLiveDays; i++ {
+		date := today.AddDate(0, 0, -i).Format("2006-01-02")
+		coachIDs, ok := s.coachStore.ListProfileViewedCoaches(date)
+		if !ok {
+			continue
+		}
+		for _, coachID := range coachIDs {
+			if err := ctx.Err(); err != nil {
+				return written, err
+			}
+			views, ok := s.coachStore.GetProfileViews(coachID, date)
+			if !ok || views == 0 {
+				continue
+			}
+			if err := s.coachRepo.UpsertProfileViews(ctx, coachID, date, views); err != nil {
+				slog.Warn("Failed to flush profile views", "coach_id", coachID, "date", date, "error", err)
+				continue
+			}
+			written++
+		}
+	}
+	return written, nil
+}
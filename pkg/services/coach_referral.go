@@ -0,0 +1,235 @@
+package services
+
+import (
+	"chalk-api/pkg/events"
+	"chalk-api/pkg/models"
+	"chalk-api/pkg/repositories"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrReferralCodeNotFound = errors.New("referral code not found")
+	ErrSelfReferral         = errors.New("cannot use your own referral code")
+	ErrCircularReferral     = errors.New("referral would be circular")
+	ErrReferralLocked       = errors.New("referral can no longer be changed")
+)
+
+const (
+	referralCodeLength = 8
+
+	// Referral chains are walked at most this far when checking for cycles
+	maxReferralChainDepth = 100
+)
+
+// ReferralSummary is the caller's referral code and how it has done.
+type ReferralSummary struct {
+	Code               string `json:"code"`
+	ReferredByCoachID  *uint  `json:"referred_by_coach_id"`
+	CompletedReferrals int64  `json:"completed_referrals"`
+	CreditBalanceCents int64  `json:"credit_balance_cents"`
+}
+
+// ReferralCreditBalance is the caller's unconsumed credit and the ledger it comes from.
+type ReferralCreditBalance struct {
+	BalanceCents int64                   `json:"balance_cents"`
+	Credits      []models.ReferralCredit `json:"credits"`
+}
+
+// GetMyReferral returns the coach's referral code, generating it for profiles created before
+// referrals existed, with their completed referral count and credit balance.
+func (s *CoachService) GetMyReferral(ctx context.Context, userID uint) (*ReferralSummary, error) {
+	profile, err := s.GetMyProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if profile.ReferralCode == nil {
+		if err := s.ensureReferralCode(ctx, profile.ID); err != nil {
+			return nil, err
+		}
+		if profile, err = s.coachRepo.GetByID(ctx, profile.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	completed, err := s.coachRepo.CountReferrals(ctx, profile.ID)
+	if err != nil {
+		return nil, err
+	}
+	balance, err := s.coachRepo.GetReferralCreditBalance(ctx, profile.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ReferralSummary{
+		Code:               *profile.ReferralCode,
+		ReferredByCoachID:  profile.ReferredByCoachID,
+		CompletedReferrals: completed,
+		CreditBalanceCents: balance,
+	}, nil
+}
+
+// GetMyReferralCredits returns the coach's credit balance and full credit ledger.
+func (s *CoachService) GetMyReferralCredits(ctx context.Context, userID uint) (*ReferralCreditBalance, error) {
+	profile, err := s.GetMyProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	balance, err := s.coachRepo.GetReferralCreditBalance(ctx, profile.ID)
+	if err != nil {
+		return nil, err
+	}
+	credits, err := s.coachRepo.ListReferralCredits(ctx, profile.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &ReferralCreditBalance{BalanceCents: balance, Credits: credits}, nil
+}
+
+// applyReferralCode links profile to the coach owning code. It runs before the profile is saved, so
+// profile.OnboardingCompleted still holds the stored value. Resubmitting the code already applied is
+// a no-op; a different code is refused once one is set or onboarding is done. An empty code is
+// ignored.
+func (s *CoachService) applyReferralCode(ctx context.Context, profile *models.CoachProfile, code string) error {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if code == "" {
+		return nil
+	}
+
+	referrer, err := s.coachRepo.GetByReferralCode(ctx, code)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrReferralCodeNotFound
+		}
+		return err
+	}
+	if referrer.UserID == profile.UserID {
+		return ErrSelfReferral
+	}
+	if profile.ReferredByCoachID != nil {
+		if *profile.ReferredByCoachID == referrer.ID {
+			return nil
+		}
+		return ErrReferralLocked
+	}
+	if profile.OnboardingCompleted {
+		return ErrReferralLocked
+	}
+
+	// A new profile can't appear in anyone's chain yet; an existing one must not be the referrer's
+	// referrer, or theirs, and so on.
+	if profile.ID != 0 {
+		next := referrer.ReferredByCoachID
+		for depth := 0; next != nil && depth < maxReferralChainDepth; depth++ {
+			if *next == profile.ID {
+				return ErrCircularReferral
+			}
+			if next, err = s.coachRepo.GetReferredByCoachID(ctx, *next); err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					break
+				}
+				return err
+			}
+		}
+	}
+
+	profile.ReferredByCoachID = &referrer.ID
+	return nil
+}
+
+// ensureReferralCode gives the coach a referral code if they don't have one, retrying on collisions.
+func (s *CoachService) ensureReferralCode(ctx context.Context, coachID uint) error {
+	for i := 0; i < 5; i++ {
+		code, err := generateInviteCode(referralCodeLength)
+		if err != nil {
+			return err
+		}
+		if _, err := s.coachRepo.AssignReferralCode(ctx, coachID, code); err != nil {
+			if errors.Is(err, repositories.ErrReferralCodeTaken) {
+				continue
+			}
+			return err
+		}
+		return nil
+	}
+	return fmt.Errorf("failed to generate unique referral code")
+}
+
+// completeReferral records the referral, credits the referrer and publishes referral.completed once a
+// referred coach has completed onboarding. The referral is unique per referred coach, so it runs on
+// every save of such a profile and does nothing after the first success; a save whose referral
+// step failed is completed by the next one.
+func (s *CoachService) completeReferral(ctx context.Context, profile *models.CoachProfile) error {
+	if !profile.OnboardingCompleted || profile.ReferredByCoachID == nil {
+		return nil
+	}
+
+	referrer, err := s.coachRepo.GetByID(ctx, *profile.ReferredByCoachID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	code := ""
+	if referrer.ReferralCode != nil {
+		code = *referrer.ReferralCode
+	}
+
+	return s.repos.WithTransaction(ctx, func(tx *gorm.DB, txRepos *repositories.RepositoriesCollection) error {
+		referral := &models.Referral{
+			ReferrerCoachID: referrer.ID,
+			ReferredCoachID: profile.ID,
+			Code:            code,
+			CompletedAt:     time.Now().UTC(),
+		}
+		created, err := txRepos.Coach.CreateReferral(ctx, referral)
+		if err != nil || !created {
+			return err
+		}
+
+		payload := events.ReferralCompletedPayload{
+			ReferralID:      referral.ID,
+			ReferrerCoachID: referrer.ID,
+			ReferrerUserID:  referrer.UserID,
+			ReferredCoachID: profile.ID,
+		}
+		if profile.BusinessName != nil {
+			payload.ReferredCoachName = strings.TrimSpace(*profile.BusinessName)
+		}
+		if s.referralCreditCents > 0 {
+			credit := &models.ReferralCredit{
+				CoachID:     referrer.ID,
+				AmountCents: s.referralCreditCents,
+				Reason:      models.ReferralCreditReasonReferral,
+				ReferralID:  &referral.ID,
+			}
+			if err := txRepos.Coach.CreateReferralCredit(ctx, credit); err != nil {
+				return err
+			}
+			payload.CreditID = credit.ID
+			payload.AmountCents = credit.AmountCents
+		}
+
+		if s.eventsPublisher == nil {
+			return nil
+		}
+		id := strconv.FormatUint(uint64(referral.ID), 10)
+		return s.eventsPublisher.PublishInTx(
+			ctx,
+			tx,
+			events.EventTypeReferralCompleted,
+			"referral",
+			id,
+			events.BuildIdempotencyKey(events.EventTypeReferralCompleted, id),
+			payload,
+		)
+	})
+}
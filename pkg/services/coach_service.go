@@ -2,15 +2,19 @@ package services
 
 import (
 	"chalk-api/pkg/events"
+	"chalk-api/pkg/external/storage"
 	"chalk-api/pkg/models"
 	"chalk-api/pkg/repositories"
+	"chalk-api/pkg/stores"
 	"chalk-api/pkg/utils"
 	"context"
 	"errors"
 	"fmt"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"gorm.io/gorm"
 )
@@ -19,8 +23,61 @@ var (
 	ErrCoachProfileNotFound = errors.New("coach profile not found")
 	ErrInviteCodeNotFound   = errors.New("invite code not found")
 	ErrInviteForbidden      = errors.New("invite does not belong to coach")
+	ErrInviteCodeExhausted  = errors.New("invite code has no uses left")
+	ErrInvalidBrandColor    = errors.New("brand color must be a hex color")
+	ErrUploadNotFound       = errors.New("uploaded object not found")
+	ErrInvalidClientFilter  = errors.New("invalid client list filter")
+	ErrInvalidCoachSlug     = errors.New("invalid coach slug")
+	ErrCoachSlugTaken       = errors.New("coach slug already in use")
+	ErrInvalidClientTag     = errors.New("invalid client tag")
+	ErrTooManyClientTags    = errors.New("too many client tags")
+
+	ErrConnectionRequestSelf       = errors.New("cannot request to connect with yourself")
+	ErrInvalidConnectionFilter     = errors.New("invalid connection request filter")
+	ErrCoachNotAcceptingClients    = errors.New("coach is not accepting new clients")
+	ErrAlreadyClient               = errors.New("already a client of this coach")
+	ErrConnectionRequestExists     = errors.New("connection request already pending")
+	ErrConnectionRequestCooldown   = errors.New("connection request was declined recently")
+	ErrConnectionRequestNotFound   = errors.New("connection request not found")
+	ErrConnectionRequestForbidden  = errors.New("connection request does not belong to coach")
+	ErrConnectionRequestNotPending = errors.New("connection request is not pending")
+)
+
+const (
+	MaxCoverPhotoBytes     = 10 << 20
+	coverPhotoUploadExpiry = 15 * time.Minute
+
+	maxClientTags      = 20
+	maxClientTagLength = 30
+)
+
+// Cover photos are uploaded directly to storage and served as-is, so browsers must be able to render them.
+var coverPhotoExtensions = map[string]string{
+	"image/jpeg": "jpg",
+	"image/png":  "png",
+	"image/webp": "webp",
+}
+
+var brandColorPattern = regexp.MustCompile(`^#(?:[0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// Slugs appear in public URLs: lowercase letters and digits in dash-separated words, no leading or trailing dash.
+var coachSlugPattern = regexp.MustCompile(`^[a-z0-9]+(?:-[a-z0-9]+)*$`)
+
+const (
+	minCoachSlugLength = 3
+	maxCoachSlugLength = 50
 )
 
+// Connection request statuses
+const (
+	ConnectionRequestPending  = "pending"
+	ConnectionRequestApproved = "approved"
+	ConnectionRequestDeclined = "declined"
+)
+
+// A declined user can send a new connection request to the same coach after this long.
+const connectionRequestCooldown = 30 * 24 * time.Hour
+
 type UpsertCoachProfileInput struct {
 	BusinessName        *string             `json:"business_name"`
 	Bio                 *string             `json:"bio"`
@@ -35,10 +92,80 @@ type UpsertCoachProfileInput struct {
 	SocialLinks         *models.SocialLinks `json:"social_links"`
 	OnboardingCompleted *bool               `json:"onboarding_completed"`
 	IsAcceptingClients  *bool               `json:"is_accepting_clients"`
+	BrandColor          *string             `json:"brand_color"` // "#RGB" or "#RRGGBB"; empty string clears it
+	Slug                *string             `json:"slug"`        // public booking page handle; empty string clears it
+	DigestEnabled       *bool               `json:"digest_enabled"`
+	DigestPushEnabled   *bool               `json:"digest_push_enabled"`
+	DigestDayOfWeek     *int                `json:"digest_day_of_week" binding:"omitempty,min=0,max=6"`
+	DigestHour          *int                `json:"digest_hour" binding:"omitempty,min=0,max=23"`
+	// Days without a completed workout, message or attended session before a client is flagged at-risk
+	AtRiskInactivityDays *int `json:"at_risk_inactivity_days" binding:"omitempty,min=1,max=365"`
+	// Client cancellations within this many hours of the start are recorded as late; 0 disables it
+	CancellationWindowHours *int `json:"cancellation_window_hours" binding:"omitempty,min=0,max=168"`
+	// "leave_alone", "auto_complete" or "auto_no_show"; see models.CoachProfile.StaleSessionAction
+	StaleSessionAction *string `json:"stale_session_action" binding:"omitempty,oneof=leave_alone auto_complete auto_no_show"`
+	// Days messages are kept before the retention task deletes them; 0 removes the policy
+	MessagesRetentionDays *int `json:"messages_retention_days" binding:"omitempty,min=0,max=3650"`
+	// Code of the coach who referred this one; accepted until onboarding is completed
+	ReferralCode *string `json:"referral_code" binding:"omitempty,max=16"`
+	// Version of the profile the edit was based on (or the If-Match header); ignored when creating
+	Version *int `json:"version"`
+}
+
+// ClientListInput filters the coach's client list. Sort is "last_activity_at" (least recently
+// active first) or empty for newest clients first.
+type ClientListInput struct {
+	Status string
+	AtRisk *bool
+	Sort   string
+	Limit  int
+	Offset int
+
+	// Tags keeps clients with any of them, or with all of them when TagMatch is "all"
+	Tags     []string
+	TagMatch string
+}
+
+// UpdateClientSettingsInput holds the coach-controlled settings on a client profile.
+type UpdateClientSettingsInput struct {
+	RequireCompletionNote *bool `json:"require_completion_note"`
+	// Turning this off stops the client booking sessions themselves; the coach can still book for them
+	CanSelfBook *bool `json:"can_self_book"`
+	// Archiving hides the client's conversation from both inboxes; setting it back to active or paused restores it
+	Status *string `json:"status" binding:"omitempty,oneof=active paused archived"`
+}
+
+// SetClientTagsInput replaces a client's tags; an empty list clears them.
+type SetClientTagsInput struct {
+	Tags []string `json:"tags" binding:"required"`
+}
+
+// RenameClientTagInput renames a tag on every one of the coach's clients.
+type RenameClientTagInput struct {
+	From string `json:"from" binding:"required"`
+	To   string `json:"to" binding:"required"`
+}
+
+type CoverPhotoUploadInput struct {
+	ContentType   string `json:"content_type" binding:"required"`
+	ContentLength int64  `json:"content_length" binding:"required,min=1"`
+}
+
+type SetCoverPhotoInput struct {
+	ObjectKey string `json:"object_key" binding:"required"`
 }
 
 type CreateInviteCodeInput struct {
 	ExpiresInDays int `json:"expires_in_days"`
+	// How many clients can join with the code; defaults to 1
+	MaxUses *int `json:"max_uses" binding:"omitempty,min=1,max=500"`
+	// Optional onboarding: an active template assigned on the join date and a first message from the coach
+	TemplateID     *uint   `json:"template_id"`
+	WelcomeMessage *string `json:"welcome_message" binding:"omitempty,max=2000"`
+}
+
+type CreateConnectionRequestInput struct {
+	Message *string `json:"message" binding:"omitempty,max=1000"`
 }
 
 type InvitePreview struct {
@@ -55,24 +182,47 @@ type AcceptInviteInput struct {
 type AcceptInviteResult struct {
 	ClientProfile    *models.ClientProfile `json:"client_profile"`
 	AlreadyConnected bool                  `json:"already_connected"`
+	WorkoutID        *uint                 `json:"workout_id,omitempty"`
+	ConversationID   *uint                 `json:"conversation_id,omitempty"`
+	Warnings         []string              `json:"warnings,omitempty"`
 }
 
+type ApproveConnectionResult struct {
+	ClientProfile    *models.ClientProfile `json:"client_profile"`
+	AlreadyConnected bool                  `json:"already_connected"`
+}
+
+// InviteWarningTemplateUnavailable is returned when the invite's template was deleted or deactivated
+// after the invite was created, or its exercise groups no longer validate; the client still joins,
+// just without the workout.
+const InviteWarningTemplateUnavailable = "template_unavailable"
+
 type CoachService struct {
 	repos           *repositories.RepositoriesCollection
 	coachRepo       *repositories.CoachRepository
 	clientRepo      *repositories.ClientRepository
 	eventsPublisher *events.Publisher
+	storage         storage.API
+	coachStore      *stores.CoachStore
+	// referralCreditCents is credited to a referrer when their referral completes; 0 grants nothing
+	referralCreditCents int
 }
 
 func NewCoachService(
 	repos *repositories.RepositoriesCollection,
 	eventsPublisher *events.Publisher,
+	storageAPI storage.API,
+	coachStore *stores.CoachStore,
+	referralCreditCents int,
 ) *CoachService {
 	return &CoachService{
-		repos:           repos,
-		coachRepo:       repos.Coach,
-		clientRepo:      repos.Client,
-		eventsPublisher: eventsPublisher,
+		repos:               repos,
+		coachRepo:           repos.Coach,
+		clientRepo:          repos.Client,
+		eventsPublisher:     eventsPublisher,
+		storage:             storageAPI,
+		coachStore:          coachStore,
+		referralCreditCents: referralCreditCents,
 	}
 }
 
@@ -87,7 +237,40 @@ func (s *CoachService) GetMyProfile(ctx context.Context, userID uint) (*models.C
 	return profile, nil
 }
 
+// GetPublicProfile returns the client-facing view of a coach profile, served from cache when possible.
+func (s *CoachService) GetPublicProfile(ctx context.Context, coachID uint) (*stores.CachedCoachProfile, error) {
+	if cached, ok := s.coachStore.GetProfile(coachID); ok {
+		return cached, nil
+	}
+
+	profile, err := s.coachRepo.GetByID(ctx, coachID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCoachProfileNotFound
+		}
+		return nil, err
+	}
+
+	s.coachStore.SetProfile(profile)
+	return stores.ToCachedCoachProfile(profile), nil
+}
+
 func (s *CoachService) UpsertMyProfile(ctx context.Context, userID uint, input UpsertCoachProfileInput) (*models.CoachProfile, error) {
+	if input.BrandColor != nil {
+		brandColor, err := normalizeBrandColor(*input.BrandColor)
+		if err != nil {
+			return nil, err
+		}
+		input.BrandColor = &brandColor
+	}
+	if input.Slug != nil {
+		slug, err := normalizeCoachSlug(*input.Slug)
+		if err != nil {
+			return nil, err
+		}
+		input.Slug = &slug
+	}
+
 	profile, err := s.coachRepo.GetByUserID(ctx, userID)
 	if err != nil {
 		if !errors.Is(err, gorm.ErrRecordNotFound) {
@@ -110,12 +293,27 @@ func (s *CoachService) UpsertMyProfile(ctx context.Context, userID uint, input U
 		if input.SocialLinks != nil {
 			profile.SocialLinks = *input.SocialLinks
 		}
+		if input.ReferralCode != nil {
+			if err := s.applyReferralCode(ctx, profile, *input.ReferralCode); err != nil {
+				return nil, err
+			}
+		}
 
 		applyCoachProfileUpdates(profile, input)
 
 		if err := s.coachRepo.Create(ctx, profile); err != nil {
+			if errors.Is(err, repositories.ErrCoachSlugTaken) {
+				return nil, ErrCoachSlugTaken
+			}
 			return nil, err
 		}
+		// Create skips zero values for columns with defaults (false, Sunday, midnight); save them explicitly.
+		if input.DigestEnabled != nil || input.DigestPushEnabled != nil || input.DigestDayOfWeek != nil || input.DigestHour != nil {
+			applyCoachProfileUpdates(profile, input)
+			if err := s.coachRepo.Update(ctx, profile); err != nil {
+				return nil, err
+			}
+		}
 
 		// Initialize coach stats row on profile creation.
 		stats := &models.CoachStats{CoachID: profile.ID}
@@ -123,16 +321,414 @@ func (s *CoachService) UpsertMyProfile(ctx context.Context, userID uint, input U
 			return nil, err
 		}
 
+		if err := s.ensureReferralCode(ctx, profile.ID); err != nil {
+			return nil, err
+		}
+		if coachProfileComplete(profile) {
+			s.CompleteOnboardingStep(ctx, profile.ID, OnboardingStepProfile)
+		}
+		if err := s.completeReferral(ctx, profile); err != nil {
+			return nil, err
+		}
+
 		return s.coachRepo.GetByID(ctx, profile.ID)
 	}
 
+	if err := checkVersion(input.Version, profile.Version); err != nil {
+		return nil, err
+	}
+	if input.ReferralCode != nil {
+		if err := s.applyReferralCode(ctx, profile, *input.ReferralCode); err != nil {
+			return nil, err
+		}
+	}
 	applyCoachProfileUpdates(profile, input)
 	if err := s.coachRepo.Update(ctx, profile); err != nil {
+		if errors.Is(err, repositories.ErrCoachSlugTaken) {
+			return nil, ErrCoachSlugTaken
+		}
+		if errors.Is(err, repositories.ErrVersionConflict) {
+			return nil, s.profileVersionConflict(ctx, profile.ID)
+		}
+		return nil, err
+	}
+	s.coachStore.InvalidateProfile(profile.ID)
+	if coachProfileComplete(profile) {
+		s.CompleteOnboardingStep(ctx, profile.ID, OnboardingStepProfile)
+	}
+	if err := s.completeReferral(ctx, profile); err != nil {
 		return nil, err
 	}
 	return s.coachRepo.GetByID(ctx, profile.ID)
 }
 
+// profileVersionConflict reports the version written by the update that won the race.
+func (s *CoachService) profileVersionConflict(ctx context.Context, profileID uint) error {
+	current, err := s.coachRepo.GetByID(ctx, profileID)
+	if err != nil {
+		return err
+	}
+	return &VersionConflictError{CurrentVersion: current.Version}
+}
+
+// CreateCoverPhotoUpload returns a presigned PUT the app uses to upload a cover photo straight to storage.
+// The object only becomes the cover photo once SetCoverPhoto confirms it.
+func (s *CoachService) CreateCoverPhotoUpload(ctx context.Context, userID uint, input CoverPhotoUploadInput) (*storage.PresignedRequest, error) {
+	if !s.storage.IsConfigured() {
+		return nil, ErrStorageUnavailable
+	}
+
+	contentType := strings.ToLower(strings.TrimSpace(input.ContentType))
+	extension, ok := coverPhotoExtensions[contentType]
+	if !ok {
+		return nil, ErrUploadContentType
+	}
+	if input.ContentLength > MaxCoverPhotoBytes {
+		return nil, ErrUploadTooLarge
+	}
+
+	profile, err := s.GetMyProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	suffix, err := utils.GenerateRandomString(16)
+	if err != nil {
+		return nil, err
+	}
+	key := fmt.Sprintf("%s%s.%s", coverPhotoKeyPrefix(profile.ID), suffix, extension)
+
+	return s.storage.PresignPut(key, contentType, input.ContentLength, coverPhotoUploadExpiry)
+}
+
+// SetCoverPhoto verifies an uploaded object and makes it the coach's cover photo.
+// The replaced object is deleted by the outbox worker so the request never waits on storage.
+func (s *CoachService) SetCoverPhoto(ctx context.Context, userID uint, input SetCoverPhotoInput) (*models.CoachProfile, error) {
+	if !s.storage.IsConfigured() {
+		return nil, ErrStorageUnavailable
+	}
+
+	profile, err := s.GetMyProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	key := strings.TrimSpace(input.ObjectKey)
+	if !strings.HasPrefix(key, coverPhotoKeyPrefix(profile.ID)) || strings.Contains(key, "..") {
+		return nil, ErrUploadNotFound
+	}
+
+	info, err := s.storage.HeadObject(ctx, key)
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotFound) {
+			return nil, ErrUploadNotFound
+		}
+		return nil, err
+	}
+	if info.Size > MaxCoverPhotoBytes {
+		return nil, ErrUploadTooLarge
+	}
+	if _, ok := coverPhotoExtensions[strings.ToLower(info.ContentType)]; !ok {
+		return nil, ErrUploadContentType
+	}
+
+	coverPhotoURL := s.storage.PublicURL(key)
+	previousURL := profile.CoverPhotoURL
+	if previousURL != nil && *previousURL == coverPhotoURL {
+		return profile, nil
+	}
+	profile.CoverPhotoURL = &coverPhotoURL
+
+	err = s.repos.WithTransaction(ctx, func(tx *gorm.DB, txRepos *repositories.RepositoriesCollection) error {
+		if err := txRepos.Coach.Update(ctx, profile); err != nil {
+			return err
+		}
+		if previousURL == nil || s.eventsPublisher == nil {
+			return nil
+		}
+		previousKey, ok := s.storage.KeyFromURL(*previousURL)
+		if !ok {
+			return nil
+		}
+		return s.eventsPublisher.PublishInTx(
+			ctx,
+			tx,
+			events.EventTypeStorageObjectDelete,
+			"coach_profile",
+			strconv.FormatUint(uint64(profile.ID), 10),
+			events.BuildIdempotencyKey(events.EventTypeStorageObjectDelete, previousKey),
+			events.StorageObjectDeletePayload{Key: previousKey, Reason: "cover_photo_replaced"},
+		)
+	})
+	if err != nil {
+		if errors.Is(err, repositories.ErrVersionConflict) {
+			return nil, s.profileVersionConflict(ctx, profile.ID)
+		}
+		return nil, err
+	}
+
+	s.coachStore.InvalidateProfile(profile.ID)
+	return s.coachRepo.GetByID(ctx, profile.ID)
+}
+
+// ListMyClients returns the coach's clients, optionally filtered by status and at-risk flag. Assistants
+// see the clients of the coach they work for.
+func (s *CoachService) ListMyClients(ctx context.Context, userID uint, input ClientListInput) ([]models.ClientProfile, int64, error) {
+	acting, err := resolveActingCoach(ctx, s.coachRepo, userID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	switch input.Status {
+	case "", "active", "paused", "archived":
+	default:
+		return nil, 0, ErrInvalidClientFilter
+	}
+	switch input.Sort {
+	case "", "created_at", "last_activity_at":
+	default:
+		return nil, 0, ErrInvalidClientFilter
+	}
+	switch input.TagMatch {
+	case "", "any", "all":
+	default:
+		return nil, 0, ErrInvalidClientFilter
+	}
+	tags := make([]string, 0, len(input.Tags))
+	for _, tag := range input.Tags {
+		if tag = normalizeClientTag(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+
+	limit := input.Limit
+	if limit <= 0 {
+		limit = 20
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	offset := input.Offset
+	if offset < 0 {
+		offset = 0
+	}
+
+	return s.clientRepo.ListByCoach(ctx, acting.Coach.ID, repositories.ClientListFilter{
+		Status:       input.Status,
+		AtRisk:       input.AtRisk,
+		Tags:         tags,
+		MatchAllTags: input.TagMatch == "all",
+		SortBy:       input.Sort,
+	}, limit, offset)
+}
+
+// SetMyClientTags replaces the tags on one of the coach's clients. Tags are trimmed and lowercased,
+// duplicates are dropped, and each must be 1-30 characters, at most 20 per client.
+func (s *CoachService) SetMyClientTags(ctx context.Context, userID, clientProfileID uint, input SetClientTagsInput) (*ClientDetail, error) {
+	clientProfile, err := getCoachClientProfile(ctx, s.repos, userID, clientProfileID)
+	if err != nil {
+		return nil, err
+	}
+
+	tags := make([]string, 0, len(input.Tags))
+	seen := make(map[string]bool, len(input.Tags))
+	for _, raw := range input.Tags {
+		tag, err := validateClientTag(raw)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+	if len(tags) > maxClientTags {
+		return nil, ErrTooManyClientTags
+	}
+
+	if err := s.repos.Client.ReplaceTags(ctx, clientProfile.ID, tags); err != nil {
+		return nil, err
+	}
+	return s.GetMyClient(ctx, userID, clientProfile.ID)
+}
+
+// ListMyClientTags returns the tags in use across the coach's clients with how many clients have
+// each, for autocomplete.
+func (s *CoachService) ListMyClientTags(ctx context.Context, userID uint) ([]repositories.ClientTagCount, error) {
+	profile, err := s.coachRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCoachProfileNotFound
+		}
+		return nil, err
+	}
+	return s.repos.Client.ListTagCounts(ctx, profile.ID)
+}
+
+// RenameMyClientTag renames a tag on all of the coach's clients, merging it into the new name where a
+// client already has both. It returns how many clients were updated.
+func (s *CoachService) RenameMyClientTag(ctx context.Context, userID uint, input RenameClientTagInput) (int64, error) {
+	profile, err := s.coachRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, ErrCoachProfileNotFound
+		}
+		return 0, err
+	}
+
+	from, err := validateClientTag(input.From)
+	if err != nil {
+		return 0, err
+	}
+	to, err := validateClientTag(input.To)
+	if err != nil {
+		return 0, err
+	}
+	if from == to {
+		return 0, nil
+	}
+	return s.repos.Client.RenameTag(ctx, profile.ID, from, to)
+}
+
+func normalizeClientTag(raw string) string {
+	return strings.ToLower(strings.TrimSpace(raw))
+}
+
+func validateClientTag(raw string) (string, error) {
+	tag := normalizeClientTag(raw)
+	if tag == "" || utf8.RuneCountInString(tag) > maxClientTagLength {
+		return "", ErrInvalidClientTag
+	}
+	return tag, nil
+}
+
+// ClientDetail is one of the coach's clients with their active goals and each goal's latest progress.
+type ClientDetail struct {
+	models.ClientProfile
+	ActiveGoals []models.ClientGoal `json:"active_goals"`
+	// The coach's custom intake questions with the client's answers; the standard fields are on intake_form
+	CustomIntake []CustomIntakeAnswer `json:"custom_intake"`
+	// Daily activity totals for the last 7 days in the client's timezone, oldest first, for the sparkline
+	ActivityLast7Days []ActivityDay `json:"activity_last_7_days"`
+}
+
+// GetMyClient returns one of the calling coach's clients for the client detail screen; assistants
+// can read it too.
+func (s *CoachService) GetMyClient(ctx context.Context, userID, clientProfileID uint) (*ClientDetail, error) {
+	clientProfile, err := getTeamClientProfile(ctx, s.repos, userID, clientProfileID)
+	if err != nil {
+		return nil, err
+	}
+
+	goals, err := s.repos.Progress.ListGoals(ctx, []uint{clientProfile.ID}, GoalStatusActive)
+	if err != nil {
+		return nil, err
+	}
+	if err := attachLatestGoalProgress(ctx, s.repos.Progress, goals); err != nil {
+		return nil, err
+	}
+
+	questions, err := s.repos.Client.ListIntakeQuestions(ctx, clientProfile.CoachID)
+	if err != nil {
+		return nil, err
+	}
+
+	tz, err := s.repos.User.GetTimezone(ctx, clientProfile.UserID)
+	if err != nil {
+		return nil, err
+	}
+	loc := utils.ResolveLocation(tz)
+	now := time.Now().In(loc)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	activity, err := loadActivityDays(ctx, s.repos.Progress, clientProfile.ID, loc, today.AddDate(0, 0, -(activitySparklineDays-1)), today)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ClientDetail{
+		ClientProfile:     *clientProfile,
+		ActiveGoals:       goals,
+		CustomIntake:      buildCustomIntakeAnswers(questions, clientProfile.IntakeForm),
+		ActivityLast7Days: activity,
+	}, nil
+}
+
+// UpdateMyClient changes the coach-controlled settings on one of the calling coach's clients.
+func (s *CoachService) UpdateMyClient(ctx context.Context, userID, clientProfileID uint, input UpdateClientSettingsInput) (*ClientDetail, error) {
+	clientProfile, err := getCoachClientProfile(ctx, s.repos, userID, clientProfileID)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.RequireCompletionNote != nil && *input.RequireCompletionNote != clientProfile.RequireCompletionNote {
+		if err := s.repos.Client.UpdateRequireCompletionNote(ctx, clientProfile.ID, *input.RequireCompletionNote); err != nil {
+			return nil, err
+		}
+	}
+
+	if input.CanSelfBook != nil && *input.CanSelfBook != clientProfile.CanSelfBook {
+		if err := s.repos.Client.UpdateCanSelfBook(ctx, clientProfile.ID, *input.CanSelfBook); err != nil {
+			return nil, err
+		}
+	}
+
+	if input.Status != nil && *input.Status != clientProfile.Status {
+		if err := s.updateClientStatus(ctx, clientProfile, *input.Status); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.GetMyClient(ctx, userID, clientProfile.ID)
+}
+
+// updateClientStatus moves a relationship between active, paused and archived, keeping the coach's
+// active client count in step. The client.status_changed event updates the conversation afterwards.
+func (s *CoachService) updateClientStatus(ctx context.Context, clientProfile *models.ClientProfile, status string) error {
+	// A transferred profile stays archived; the relationship lives on under the new coach.
+	if clientProfile.TransferredToID != nil {
+		return ErrClientAlreadyTransferred
+	}
+
+	previous := clientProfile.Status
+	return s.repos.WithTransaction(ctx, func(tx *gorm.DB, txRepos *repositories.RepositoriesCollection) error {
+		if err := txRepos.Client.UpdateStatus(ctx, clientProfile.ID, status); err != nil {
+			return err
+		}
+
+		delta := 0
+		if previous == "active" {
+			delta--
+		}
+		if status == "active" {
+			delta++
+		}
+		if delta != 0 {
+			if err := txRepos.Coach.IncrementStat(ctx, clientProfile.CoachID, "active_clients", delta); err != nil {
+				return err
+			}
+		}
+
+		if s.eventsPublisher == nil {
+			return nil
+		}
+		id := strconv.FormatUint(uint64(clientProfile.ID), 10)
+		return s.eventsPublisher.PublishInTx(
+			ctx,
+			tx,
+			events.EventTypeClientStatusChanged,
+			"client_profile",
+			id,
+			events.BuildIdempotencyKey(events.EventTypeClientStatusChanged, id, status, strconv.FormatInt(time.Now().UnixNano(), 10)),
+			events.ClientStatusChangedPayload{
+				ClientID:       clientProfile.ID,
+				CoachID:        clientProfile.CoachID,
+				Status:         status,
+				PreviousStatus: previous,
+			},
+		)
+	})
+}
+
 func (s *CoachService) CreateInviteCode(ctx context.Context, userID uint, input CreateInviteCodeInput) (*models.InviteCode, error) {
 	profile, err := s.coachRepo.GetByUserID(ctx, userID)
 	if err != nil {
@@ -142,6 +738,27 @@ func (s *CoachService) CreateInviteCode(ctx context.Context, userID uint, input
 		return nil, err
 	}
 
+	if input.TemplateID != nil {
+		template, err := s.repos.Template.GetByID(ctx, *input.TemplateID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrTemplateNotFound
+			}
+			return nil, err
+		}
+		if template.CoachID != profile.ID {
+			return nil, ErrTemplateForbidden
+		}
+		if !template.IsActive {
+			return nil, ErrTemplateNotFound
+		}
+	}
+	welcomeMessage := trimPtr(input.WelcomeMessage)
+	maxUses := 1
+	if input.MaxUses != nil {
+		maxUses = *input.MaxUses
+	}
+
 	days := input.ExpiresInDays
 	if days <= 0 {
 		days = 7
@@ -150,36 +767,42 @@ func (s *CoachService) CreateInviteCode(ctx context.Context, userID uint, input
 		days = 90
 	}
 
-	var invite *models.InviteCode
-	for i := 0; i < 5; i++ {
-		code, codeErr := generateInviteCode(10)
-		if codeErr != nil {
-			return nil, codeErr
-		}
+	invite := &models.InviteCode{
+		CoachID:   profile.ID,
+		ExpiresAt: time.Now().UTC().Add(time.Duration(days) * 24 * time.Hour),
+		IsActive:  true,
+		MaxUses:   maxUses,
+
+		TemplateID:     input.TemplateID,
+		WelcomeMessage: welcomeMessage,
+	}
+	if err := s.createInviteCode(ctx, invite); err != nil {
+		return nil, err
+	}
+	s.CompleteOnboardingStep(ctx, profile.ID, OnboardingStepFirstInvite)
+	return invite, nil
+}
 
-		candidate := &models.InviteCode{
-			CoachID:   profile.ID,
-			Code:      code,
-			ExpiresAt: time.Now().UTC().Add(time.Duration(days) * 24 * time.Hour),
-			IsActive:  true,
+// createInviteCode stores invite under a freshly generated code, retrying on code collisions.
+func (s *CoachService) createInviteCode(ctx context.Context, invite *models.InviteCode) error {
+	for i := 0; i < 5; i++ {
+		code, err := generateInviteCode(10)
+		if err != nil {
+			return err
 		}
+		invite.Code = code
 
-		if err := s.clientRepo.CreateInviteCode(ctx, candidate); err != nil {
+		if err := s.clientRepo.CreateInviteCode(ctx, invite); err != nil {
 			// Retry on code collisions from unique constraint.
 			if strings.Contains(err.Error(), "duplicate key value violates unique constraint") {
+				invite.ID = 0
 				continue
 			}
-			return nil, err
+			return err
 		}
-
-		invite = candidate
-		break
-	}
-	if invite == nil {
-		return nil, fmt.Errorf("failed to generate unique invite code")
+		return nil
 	}
-
-	return invite, nil
+	return fmt.Errorf("failed to generate unique invite code")
 }
 
 func (s *CoachService) ListInviteCodes(ctx context.Context, userID uint) ([]models.InviteCode, error) {
@@ -261,45 +884,197 @@ func (s *CoachService) AcceptInvite(ctx context.Context, userID uint, input Acce
 
 		clientProfile, alreadyConnected, err := txRepos.Client.AcceptInvite(ctx, invite, userID)
 		if err != nil {
+			if errors.Is(err, repositories.ErrInviteCodeExhausted) {
+				return ErrInviteCodeExhausted
+			}
 			return err
 		}
 
-		if !alreadyConnected {
-			if err := txRepos.Coach.IncrementStat(ctx, invite.CoachID, "active_clients", 1); err != nil {
-				return err
-			}
-			if err := txRepos.Coach.IncrementStat(ctx, invite.CoachID, "total_clients_all_time", 1); err != nil {
-				return err
-			}
+		payload := events.InviteAcceptedPayload{
+			InviteCodeID:    invite.ID,
+			CoachID:         invite.CoachID,
+			ClientUserID:    userID,
+			ClientProfileID: clientProfile.ID,
+			Code:            invite.Code,
+		}
+		idempotencyKey := events.BuildIdempotencyKey(
+			events.EventTypeInviteAccepted,
+			strconv.FormatUint(uint64(invite.ID), 10),
+			strconv.FormatUint(uint64(userID), 10),
+		)
+		if err := s.recordClientConnection(ctx, tx, txRepos, clientProfile, alreadyConnected, events.EventTypeInviteAccepted, idempotencyKey, payload); err != nil {
+			return err
 		}
 
-		if s.eventsPublisher != nil {
-			payload := events.InviteAcceptedPayload{
-				InviteCodeID:    invite.ID,
-				CoachID:         invite.CoachID,
-				ClientUserID:    userID,
-				ClientProfileID: clientProfile.ID,
-				Code:            invite.Code,
+		result = &AcceptInviteResult{
+			ClientProfile:    clientProfile,
+			AlreadyConnected: alreadyConnected,
+		}
+		// Existing clients re-using an invite are not onboarded a second time.
+		if alreadyConnected {
+			return nil
+		}
+		// Personal invite codes from a client import carry the goals and tags the coach imported.
+		if err := txRepos.Client.AcceptClientInvitation(ctx, invite.ID, clientProfile.ID, time.Now().UTC()); err != nil {
+			return err
+		}
+		return s.applyInviteOnboarding(ctx, tx, txRepos, invite, clientProfile, result)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// recordClientConnection applies the side effects shared by every way a client joins a coach: the coach's
+// client counts (new relationships only) and the connection event, inside the joining transaction.
+func (s *CoachService) recordClientConnection(
+	ctx context.Context,
+	tx *gorm.DB,
+	txRepos *repositories.RepositoriesCollection,
+	clientProfile *models.ClientProfile,
+	alreadyConnected bool,
+	eventType events.EventType,
+	idempotencyKey string,
+	payload any,
+) error {
+	if !alreadyConnected {
+		if err := txRepos.Coach.IncrementStat(ctx, clientProfile.CoachID, "active_clients", 1); err != nil {
+			return err
+		}
+		if err := txRepos.Coach.IncrementStat(ctx, clientProfile.CoachID, "total_clients_all_time", 1); err != nil {
+			return err
+		}
+	}
+
+	if s.eventsPublisher == nil {
+		return nil
+	}
+	return s.eventsPublisher.PublishInTx(
+		ctx,
+		tx,
+		eventType,
+		"client_profile",
+		strconv.FormatUint(uint64(clientProfile.ID), 10),
+		idempotencyKey,
+		payload,
+	)
+}
+
+// CreateConnectionRequest asks a coach to take the user on as a client, for users without an invite code.
+// The coach must be accepting clients; a user has at most one pending request per coach and must wait
+// connectionRequestCooldown after a decline before asking again.
+func (s *CoachService) CreateConnectionRequest(ctx context.Context, userID, coachID uint, input CreateConnectionRequestInput) (*models.ConnectionRequest, error) {
+	coach, err := s.coachRepo.GetByID(ctx, coachID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCoachProfileNotFound
+		}
+		return nil, err
+	}
+	if coach.UserID == userID {
+		return nil, ErrConnectionRequestSelf
+	}
+	if !coach.IsAcceptingClients {
+		return nil, ErrCoachNotAcceptingClients
+	}
+
+	if _, err := s.clientRepo.GetByUserAndCoach(ctx, userID, coach.ID); err == nil {
+		return nil, ErrAlreadyClient
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	latest, err := s.clientRepo.GetLatestConnectionRequest(ctx, userID, coach.ID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if latest != nil {
+		switch latest.Status {
+		case ConnectionRequestPending:
+			return nil, ErrConnectionRequestExists
+		case ConnectionRequestDeclined:
+			if latest.ReviewedAt != nil && time.Since(*latest.ReviewedAt) < connectionRequestCooldown {
+				return nil, ErrConnectionRequestCooldown
 			}
-			idempotencyKey := events.BuildIdempotencyKey(
-				events.EventTypeInviteAccepted,
-				strconv.FormatUint(uint64(invite.ID), 10),
-				strconv.FormatUint(uint64(userID), 10),
-			)
-			if err := s.eventsPublisher.PublishInTx(
-				ctx,
-				tx,
-				events.EventTypeInviteAccepted,
-				"client_profile",
-				strconv.FormatUint(uint64(clientProfile.ID), 10),
-				idempotencyKey,
-				payload,
-			); err != nil {
-				return err
+		}
+	}
+
+	request := &models.ConnectionRequest{
+		UserID:  userID,
+		CoachID: coach.ID,
+		Status:  ConnectionRequestPending,
+	}
+	if input.Message != nil {
+		if message := strings.TrimSpace(*input.Message); message != "" {
+			request.Message = &message
+		}
+	}
+	if err := s.clientRepo.CreateConnectionRequest(ctx, request); err != nil {
+		if errors.Is(err, repositories.ErrConnectionRequestExists) {
+			return nil, ErrConnectionRequestExists
+		}
+		return nil, err
+	}
+	return request, nil
+}
+
+// ListMyConnectionRequests returns the coach's connection requests, newest first.
+// Status filters to "pending", "approved" or "declined"; empty returns all.
+func (s *CoachService) ListMyConnectionRequests(ctx context.Context, userID uint, status string) ([]models.ConnectionRequest, error) {
+	switch status {
+	case "", ConnectionRequestPending, ConnectionRequestApproved, ConnectionRequestDeclined:
+	default:
+		return nil, ErrInvalidConnectionFilter
+	}
+
+	profile, err := s.coachRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCoachProfileNotFound
+		}
+		return nil, err
+	}
+	return s.clientRepo.ListConnectionRequests(ctx, profile.ID, status)
+}
+
+// ApproveConnectionRequest connects the requesting user to the coach as an active client. It has the same
+// stat and event side effects as accepting an invite, with a connection.approved event.
+func (s *CoachService) ApproveConnectionRequest(ctx context.Context, userID, requestID uint) (*ApproveConnectionResult, error) {
+	request, err := s.getOwnedConnectionRequest(ctx, userID, requestID)
+	if err != nil {
+		return nil, err
+	}
+	if request.Status != ConnectionRequestPending {
+		return nil, ErrConnectionRequestNotPending
+	}
+
+	var result *ApproveConnectionResult
+	err = s.repos.WithTransaction(ctx, func(tx *gorm.DB, txRepos *repositories.RepositoriesCollection) error {
+		clientProfile, alreadyConnected, err := txRepos.Client.ApproveConnectionRequest(ctx, request)
+		if err != nil {
+			if errors.Is(err, repositories.ErrConnectionRequestNotPending) {
+				return ErrConnectionRequestNotPending
 			}
+			return err
 		}
 
-		result = &AcceptInviteResult{
+		payload := events.ConnectionApprovedPayload{
+			ConnectionRequestID: request.ID,
+			CoachID:             request.CoachID,
+			ClientUserID:        request.UserID,
+			ClientProfileID:     clientProfile.ID,
+		}
+		idempotencyKey := events.BuildIdempotencyKey(
+			events.EventTypeConnectionApproved,
+			strconv.FormatUint(uint64(request.ID), 10),
+		)
+		if err := s.recordClientConnection(ctx, tx, txRepos, clientProfile, alreadyConnected, events.EventTypeConnectionApproved, idempotencyKey, payload); err != nil {
+			return err
+		}
+
+		result = &ApproveConnectionResult{
 			ClientProfile:    clientProfile,
 			AlreadyConnected: alreadyConnected,
 		}
@@ -312,6 +1087,116 @@ func (s *CoachService) AcceptInvite(ctx context.Context, userID uint, input Acce
 	return result, nil
 }
 
+// DeclineConnectionRequest declines a pending request. The user can ask again after connectionRequestCooldown.
+func (s *CoachService) DeclineConnectionRequest(ctx context.Context, userID, requestID uint) error {
+	request, err := s.getOwnedConnectionRequest(ctx, userID, requestID)
+	if err != nil {
+		return err
+	}
+	if err := s.clientRepo.DeclineConnectionRequest(ctx, request.ID); err != nil {
+		if errors.Is(err, repositories.ErrConnectionRequestNotPending) {
+			return ErrConnectionRequestNotPending
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *CoachService) getOwnedConnectionRequest(ctx context.Context, userID, requestID uint) (*models.ConnectionRequest, error) {
+	profile, err := s.coachRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCoachProfileNotFound
+		}
+		return nil, err
+	}
+
+	request, err := s.clientRepo.GetConnectionRequestByID(ctx, requestID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrConnectionRequestNotFound
+		}
+		return nil, err
+	}
+	if request.CoachID != profile.ID {
+		return nil, ErrConnectionRequestForbidden
+	}
+	return request, nil
+}
+
+// applyInviteOnboarding assigns the invite's template and sends its welcome message inside the accept transaction.
+func (s *CoachService) applyInviteOnboarding(
+	ctx context.Context,
+	tx *gorm.DB,
+	txRepos *repositories.RepositoriesCollection,
+	invite *models.InviteCode,
+	clientProfile *models.ClientProfile,
+	result *AcceptInviteResult,
+) error {
+	if invite.TemplateID == nil && invite.WelcomeMessage == nil {
+		return nil
+	}
+
+	coach, err := txRepos.Coach.GetByID(ctx, invite.CoachID)
+	if err != nil {
+		return err
+	}
+
+	if invite.TemplateID != nil {
+		template, err := txRepos.Template.GetByID(ctx, *invite.TemplateID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if template == nil || !template.IsActive || template.CoachID != invite.CoachID {
+			result.Warnings = append(result.Warnings, InviteWarningTemplateUnavailable)
+		} else {
+			// The join date is the client's local calendar day.
+			timezone := ""
+			if user, err := txRepos.User.GetByID(ctx, clientProfile.UserID); err == nil && user.Profile != nil {
+				timezone = user.Profile.Timezone
+			}
+			joinDate := time.Now().In(utils.ResolveLocation(timezone)).Format("2006-01-02")
+
+			workout, err := newWorkoutFromTemplate(template, clientProfile.ID, &joinDate)
+			if errors.Is(err, ErrInvalidExerciseGroups) {
+				// The coach has to fix the template's groups; the client still joins.
+				result.Warnings = append(result.Warnings, InviteWarningTemplateUnavailable)
+			} else if err != nil {
+				return err
+			} else {
+				if err := txRepos.Workout.Create(ctx, workout); err != nil {
+					return err
+				}
+				if err := publishWorkoutAssigned(ctx, s.eventsPublisher, tx, workout, coach.UserID); err != nil {
+					return err
+				}
+				result.WorkoutID = &workout.ID
+			}
+		}
+	}
+
+	if invite.WelcomeMessage != nil {
+		conversation, err := txRepos.Message.GetOrCreateConversation(ctx, coach.ID, clientProfile.ID)
+		if err != nil {
+			return err
+		}
+		message := &models.Message{
+			ConversationID: conversation.ID,
+			SenderID:       coach.UserID,
+			Content:        invite.WelcomeMessage,
+		}
+		if err := txRepos.Message.CreateMessageTx(ctx, tx, message); err != nil {
+			return err
+		}
+		if err := publishMessageSent(ctx, s.eventsPublisher, tx, message, clientProfile.UserID); err != nil {
+			return err
+		}
+		result.ConversationID = &conversation.ID
+	}
+
+	return nil
+}
+
 func applyCoachProfileUpdates(profile *models.CoachProfile, input UpsertCoachProfileInput) {
 	if input.BusinessName != nil {
 		profile.BusinessName = input.BusinessName
@@ -352,6 +1237,81 @@ func applyCoachProfileUpdates(profile *models.CoachProfile, input UpsertCoachPro
 	if input.IsAcceptingClients != nil {
 		profile.IsAcceptingClients = *input.IsAcceptingClients
 	}
+	if input.BrandColor != nil {
+		if *input.BrandColor == "" {
+			profile.BrandColor = nil
+		} else {
+			profile.BrandColor = input.BrandColor
+		}
+	}
+	if input.Slug != nil {
+		if *input.Slug == "" {
+			profile.Slug = nil
+		} else {
+			profile.Slug = input.Slug
+		}
+	}
+	if input.DigestEnabled != nil {
+		profile.DigestEnabled = *input.DigestEnabled
+	}
+	if input.DigestPushEnabled != nil {
+		profile.DigestPushEnabled = *input.DigestPushEnabled
+	}
+	if input.DigestDayOfWeek != nil {
+		profile.DigestDayOfWeek = *input.DigestDayOfWeek
+	}
+	if input.DigestHour != nil {
+		profile.DigestHour = *input.DigestHour
+	}
+	if input.AtRiskInactivityDays != nil {
+		profile.AtRiskInactivityDays = *input.AtRiskInactivityDays
+	}
+	if input.CancellationWindowHours != nil {
+		profile.CancellationWindowHours = *input.CancellationWindowHours
+	}
+	if input.StaleSessionAction != nil {
+		profile.StaleSessionAction = *input.StaleSessionAction
+	}
+	if input.MessagesRetentionDays != nil {
+		if *input.MessagesRetentionDays == 0 {
+			profile.MessagesRetentionDays = nil
+		} else {
+			profile.MessagesRetentionDays = input.MessagesRetentionDays
+		}
+	}
+}
+
+// normalizeBrandColor expands shorthand hex colors to "#RRGGBB" in uppercase.
+// An empty value is returned as-is so callers can clear the color.
+func normalizeBrandColor(raw string) (string, error) {
+	color := strings.TrimSpace(raw)
+	if color == "" {
+		return "", nil
+	}
+	if !brandColorPattern.MatchString(color) {
+		return "", ErrInvalidBrandColor
+	}
+	if len(color) == 4 {
+		color = string([]byte{'#', color[1], color[1], color[2], color[2], color[3], color[3]})
+	}
+	return strings.ToUpper(color), nil
+}
+
+// normalizeCoachSlug trims and lowercases a slug and checks it is URL-safe.
+// An empty value is returned as-is so callers can clear the slug.
+func normalizeCoachSlug(raw string) (string, error) {
+	slug := strings.ToLower(strings.TrimSpace(raw))
+	if slug == "" {
+		return "", nil
+	}
+	if len(slug) < minCoachSlugLength || len(slug) > maxCoachSlugLength || !coachSlugPattern.MatchString(slug) {
+		return "", ErrInvalidCoachSlug
+	}
+	return slug, nil
+}
+
+func coverPhotoKeyPrefix(coachID uint) string {
+	return fmt.Sprintf("covers/%d/", coachID)
 }
 
 func generateInviteCode(length int) (string, error) {
@@ -0,0 +1,269 @@
+package services
+
+import (
+	"chalk-api/pkg/events"
+	"chalk-api/pkg/models"
+	"chalk-api/pkg/repositories"
+	"context"
+	"errors"
+	"net/mail"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidTeamEmail           = errors.New("invalid team member email")
+	ErrTeamInviteSelf             = errors.New("cannot invite yourself to your own team")
+	ErrTeamInvitationExists       = errors.New("team invitation already open for this email")
+	ErrTeamInvitationNotFound     = errors.New("team invitation not found")
+	ErrTeamInvitationNotPending   = errors.New("team invitation is not pending")
+	ErrTeamEmailNotVerified       = errors.New("verify your email before accepting a team invitation")
+	ErrAlreadyOnCoachTeam         = errors.New("already a coach or on a coach team")
+	ErrTeamMemberNotFound         = errors.New("team member not found")
+	ErrCoachTeamOwnerOnly         = errors.New("only the coach profile owner can do this")
+	ErrCoachTeamMemberUnavailable = errors.New("team member is no longer active")
+)
+
+// ActingCoach is the coach profile a request acts on, and whether the caller owns it or assists on it.
+type ActingCoach struct {
+	Coach *models.CoachProfile `json:"coach"`
+	Role  string               `json:"role"` // models.CoachTeamRoleOwner or models.CoachTeamRoleAssistant
+}
+
+// IsOwner reports whether the caller owns the coach profile rather than assisting on it.
+func (a *ActingCoach) IsOwner() bool {
+	return a.Role == models.CoachTeamRoleOwner
+}
+
+// resolveActingCoach finds the coach profile userID works under: their own profile if they have one,
+// otherwise the coach whose team they are active on. Returns ErrCoachProfileNotFound for neither.
+func resolveActingCoach(ctx context.Context, coachRepo coachProfileReader, userID uint) (*ActingCoach, error) {
+	coach, err := coachRepo.GetByUserID(ctx, userID)
+	if err == nil {
+		return &ActingCoach{Coach: coach, Role: models.CoachTeamRoleOwner}, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	membership, err := coachRepo.GetActiveTeamMembership(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCoachProfileNotFound
+		}
+		return nil, err
+	}
+	coach, err = coachRepo.GetByID(ctx, membership.CoachID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCoachProfileNotFound
+		}
+		return nil, err
+	}
+	return &ActingCoach{Coach: coach, Role: membership.Role}, nil
+}
+
+// assistsCoach reports whether userID is an active assistant on coachID's team.
+func assistsCoach(ctx context.Context, coachRepo coachProfileReader, userID, coachID uint) (bool, error) {
+	membership, err := coachRepo.GetActiveTeamMembership(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return membership.CoachID == coachID, nil
+}
+
+// getTeamClientProfile is getCoachClientProfile for read paths that assistants share with the owner.
+func getTeamClientProfile(ctx context.Context, repos *repositories.RepositoriesCollection, userID, clientProfileID uint) (*models.ClientProfile, error) {
+	acting, err := resolveActingCoach(ctx, repos.Coach, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	clientProfile, err := repos.Client.GetByID(ctx, clientProfileID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrClientProfileNotFound
+		}
+		return nil, err
+	}
+	if clientProfile.CoachID != acting.Coach.ID {
+		return nil, ErrClientProfileForbidden
+	}
+	return clientProfile, nil
+}
+
+type InviteTeamMemberInput struct {
+	Email string `json:"email" binding:"required"`
+}
+
+// GetActingCoach returns the coach profile the caller works under and their role on it, so the app
+// can tell an assistant's coach screens from the owner's.
+func (s *CoachService) GetActingCoach(ctx context.Context, userID uint) (*ActingCoach, error) {
+	return resolveActingCoach(ctx, s.coachRepo, userID)
+}
+
+// InviteTeamMember invites someone by email to assist on the calling coach's profile. The invitee
+// sees it once they sign in with that email; nobody has to have an account yet.
+func (s *CoachService) InviteTeamMember(ctx context.Context, userID uint, input InviteTeamMemberInput) (*models.CoachTeamMember, error) {
+	coach, err := s.getTeamOwnerProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	email := normalizeEmail(input.Email)
+	if address, err := mail.ParseAddress(email); err != nil || address.Address != email {
+		return nil, ErrInvalidTeamEmail
+	}
+	owner, err := s.repos.User.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if normalizeEmail(owner.Email) == email {
+		return nil, ErrTeamInviteSelf
+	}
+
+	member := &models.CoachTeamMember{
+		CoachID:         coach.ID,
+		Email:           email,
+		Role:            models.CoachTeamRoleAssistant,
+		Status:          models.CoachTeamMemberPending,
+		InvitedByUserID: userID,
+	}
+	err = s.repos.WithTransaction(ctx, func(tx *gorm.DB, txRepos *repositories.RepositoriesCollection) error {
+		if err := txRepos.Coach.CreateTeamMember(ctx, member); err != nil {
+			if errors.Is(err, repositories.ErrTeamInvitationExists) {
+				return ErrTeamInvitationExists
+			}
+			return err
+		}
+		if s.eventsPublisher == nil {
+			return nil
+		}
+		id := strconv.FormatUint(uint64(member.ID), 10)
+		return s.eventsPublisher.PublishInTx(
+			ctx,
+			tx,
+			events.EventTypeCoachTeamInvited,
+			"coach_team_member",
+			id,
+			events.BuildIdempotencyKey(events.EventTypeCoachTeamInvited, id),
+			events.CoachTeamInvitedPayload{
+				TeamMemberID:    member.ID,
+				CoachID:         coach.ID,
+				InvitedByUserID: userID,
+				Email:           email,
+				Role:            member.Role,
+			},
+		)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return member, nil
+}
+
+// ListTeamMembers returns the owner's pending invitations and active assistants.
+func (s *CoachService) ListTeamMembers(ctx context.Context, userID uint) ([]models.CoachTeamMember, error) {
+	coach, err := s.getTeamOwnerProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.coachRepo.ListTeamMembers(ctx, coach.ID)
+}
+
+// RemoveTeamMember withdraws a pending invitation or removes an assistant. Removal takes effect on
+// the assistant's next request; they keep no access to the coach's clients.
+func (s *CoachService) RemoveTeamMember(ctx context.Context, userID, memberID uint) error {
+	coach, err := s.getTeamOwnerProfile(ctx, userID)
+	if err != nil {
+		return err
+	}
+	member, err := s.coachRepo.GetTeamMember(ctx, memberID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrTeamMemberNotFound
+		}
+		return err
+	}
+	if member.CoachID != coach.ID {
+		return ErrTeamMemberNotFound
+	}
+	if err := s.coachRepo.RevokeTeamMember(ctx, member.ID, time.Now().UTC()); err != nil {
+		if errors.Is(err, repositories.ErrTeamInvitationNotPending) {
+			return ErrCoachTeamMemberUnavailable
+		}
+		return err
+	}
+	return nil
+}
+
+// ListMyTeamInvitations returns the pending team invitations addressed to the caller's email.
+func (s *CoachService) ListMyTeamInvitations(ctx context.Context, userID uint) ([]models.CoachTeamMember, error) {
+	user, err := s.repos.User.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.coachRepo.ListPendingTeamInvitations(ctx, normalizeEmail(user.Email))
+}
+
+// AcceptTeamInvitation joins the caller to the inviting coach's team as an assistant. The invitation
+// must be addressed to the caller's verified email, and a user can only work under one coach profile:
+// coaches with their own profile and existing assistants are refused.
+func (s *CoachService) AcceptTeamInvitation(ctx context.Context, userID, invitationID uint) (*models.CoachTeamMember, error) {
+	user, err := s.repos.User.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	member, err := s.coachRepo.GetTeamMember(ctx, invitationID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTeamInvitationNotFound
+		}
+		return nil, err
+	}
+	// Invitations for other addresses are reported as missing so their existence isn't leaked
+	if member.Email != normalizeEmail(user.Email) {
+		return nil, ErrTeamInvitationNotFound
+	}
+	if member.Status != models.CoachTeamMemberPending {
+		return nil, ErrTeamInvitationNotPending
+	}
+	if !user.EmailVerified {
+		return nil, ErrTeamEmailNotVerified
+	}
+
+	if _, err := resolveActingCoach(ctx, s.coachRepo, userID); err == nil {
+		return nil, ErrAlreadyOnCoachTeam
+	} else if !errors.Is(err, ErrCoachProfileNotFound) {
+		return nil, err
+	}
+
+	if err := s.coachRepo.AcceptTeamInvitation(ctx, member.ID, userID, time.Now().UTC()); err != nil {
+		switch {
+		case errors.Is(err, repositories.ErrTeamInvitationNotPending):
+			return nil, ErrTeamInvitationNotPending
+		case errors.Is(err, repositories.ErrTeamMembershipExists):
+			return nil, ErrAlreadyOnCoachTeam
+		}
+		return nil, err
+	}
+	return s.coachRepo.GetTeamMember(ctx, member.ID)
+}
+
+// getTeamOwnerProfile loads the caller's own coach profile for team management, which assistants
+// can't do.
+func (s *CoachService) getTeamOwnerProfile(ctx context.Context, userID uint) (*models.CoachProfile, error) {
+	acting, err := resolveActingCoach(ctx, s.coachRepo, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !acting.IsOwner() {
+		return nil, ErrCoachTeamOwnerOnly
+	}
+	return acting.Coach, nil
+}
@@ -0,0 +1,225 @@
+package services
+
+import (
+	"chalk-api/pkg/events"
+	"chalk-api/pkg/models"
+	"chalk-api/pkg/utils"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidConversationExportFormat = errors.New("export format must be json or txt")
+	ErrConversationExportLimit         = errors.New("conversation export limit reached")
+)
+
+const (
+	ConversationExportJSON = "json"
+	ConversationExportText = "txt"
+
+	// Messages are read in keyset pages of this size so a long thread never sits in memory at once
+	conversationExportBatchSize = 500
+)
+
+// ConversationExport is a checked, audited export whose messages are only read once WriteTranscript runs.
+type ConversationExport struct {
+	Filename    string
+	ContentType string
+	write       func(w io.Writer, flush func()) error
+}
+
+// ExportedMessage is one message as it appears in a JSON conversation export.
+type ExportedMessage struct {
+	ID         uint    `json:"id"`
+	SenderID   uint    `json:"sender_id"`
+	SenderName string  `json:"sender_name"`
+	SentAt     string  `json:"sent_at"`
+	Content    *string `json:"content"`
+	MediaURL   *string `json:"media_url,omitempty"`
+	MediaType  *string `json:"media_type,omitempty"`
+}
+
+// ExportConversation prepares a full transcript of a conversation for either participant, in
+// format "json" or "txt" (default json). Timestamps are written in the requester's timezone.
+// Exports are capped per user per day and each one is recorded as a conversation.exported event
+// before anything is streamed.
+func (s *MessageService) ExportConversation(ctx context.Context, userID, conversationID uint, format string) (*ConversationExport, error) {
+	format = strings.ToLower(strings.TrimSpace(format))
+	if format == "" {
+		format = ConversationExportJSON
+	}
+	if format != ConversationExportJSON && format != ConversationExportText {
+		return nil, ErrInvalidConversationExportFormat
+	}
+
+	conversation, err := s.GetConversation(ctx, userID, conversationID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !s.securityStore.CheckConversationExportAllowed(userID) {
+		return nil, ErrConversationExportLimit
+	}
+
+	tz, err := s.userRepo.GetTimezone(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	loc := utils.ResolveLocation(tz)
+
+	now := time.Now().UTC()
+	id := strconv.FormatUint(uint64(conversation.ID), 10)
+	if err := s.events.Publish(
+		ctx,
+		events.EventTypeConversationExport,
+		"conversation",
+		id,
+		events.BuildIdempotencyKey(events.EventTypeConversationExport, id, strconv.FormatUint(uint64(userID), 10), strconv.FormatInt(now.UnixNano(), 10)),
+		events.ConversationExportedPayload{
+			ConversationID: conversation.ID,
+			UserID:         userID,
+			Format:         format,
+			ExportedAt:     now,
+		},
+	); err != nil {
+		return nil, err
+	}
+
+	names := map[uint]string{
+		conversation.Coach.UserID:  participantName(conversation.Coach.User, "Coach"),
+		conversation.Client.UserID: participantName(conversation.Client.User, "Client"),
+	}
+	senderName := func(senderID uint) string {
+		if name, ok := names[senderID]; ok {
+			return name
+		}
+		return "User " + strconv.FormatUint(uint64(senderID), 10)
+	}
+
+	export := &ConversationExport{
+		Filename: fmt.Sprintf("conversation-%d.%s", conversation.ID, format),
+	}
+	if format == ConversationExportText {
+		export.ContentType = "text/plain; charset=utf-8"
+		export.write = func(w io.Writer, flush func()) error {
+			return s.eachExportBatch(ctx, conversation.ID, func(batch []models.Message) error {
+				var b strings.Builder
+				for _, m := range batch {
+					fmt.Fprintf(&b, "[%s] %s: ", m.CreatedAt.In(loc).Format("2006-01-02 15:04 MST"), senderName(m.SenderID))
+					b.WriteString(derefString(m.Content))
+					b.WriteString("\n")
+					if m.MediaURL != nil {
+						mediaType := derefString(m.MediaType)
+						if mediaType == "" {
+							mediaType = "file"
+						}
+						fmt.Fprintf(&b, "    Attachment (%s): %s\n", mediaType, *m.MediaURL)
+					}
+				}
+				if _, err := io.WriteString(w, b.String()); err != nil {
+					return err
+				}
+				flush()
+				return nil
+			})
+		}
+		return export, nil
+	}
+
+	export.ContentType = "application/json"
+	export.write = func(w io.Writer, flush func()) error {
+		header := fmt.Sprintf(`{"conversation_id":%d,"exported_at":%q,"timezone":%q,"messages":[`,
+			conversation.ID, now.In(loc).Format(time.RFC3339), loc.String())
+		if _, err := io.WriteString(w, header); err != nil {
+			return err
+		}
+		first := true
+		err := s.eachExportBatch(ctx, conversation.ID, func(batch []models.Message) error {
+			for _, m := range batch {
+				row := ExportedMessage{
+					ID:         m.ID,
+					SenderID:   m.SenderID,
+					SenderName: senderName(m.SenderID),
+					SentAt:     m.CreatedAt.In(loc).Format(time.RFC3339),
+					Content:    m.Content,
+					MediaURL:   m.MediaURL,
+					MediaType:  m.MediaType,
+				}
+				encoded, err := json.Marshal(row)
+				if err != nil {
+					return err
+				}
+				if !first {
+					if _, err := io.WriteString(w, ","); err != nil {
+						return err
+					}
+				}
+				first = false
+				if _, err := w.Write(encoded); err != nil {
+					return err
+				}
+			}
+			flush()
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(w, "]}\n")
+		return err
+	}
+	return export, nil
+}
+
+// WriteTranscript streams the transcript to w a batch at a time, flushing between batches.
+func (e *ConversationExport) WriteTranscript(w io.Writer) error {
+	flush := func() {
+		if flusher, ok := w.(http.Flusher); ok {
+			flusher.Flush()
+		}
+	}
+	if err := e.write(w, flush); err != nil {
+		return err
+	}
+	flush()
+	return nil
+}
+
+// eachExportBatch walks a conversation oldest-first in keyset pages, deleted messages included.
+func (s *MessageService) eachExportBatch(ctx context.Context, conversationID uint, fn func([]models.Message) error) error {
+	var afterID uint
+	for {
+		batch, err := s.messageRepo.ListMessagesForExport(ctx, conversationID, afterID, conversationExportBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := fn(batch); err != nil {
+			return err
+		}
+		if len(batch) < conversationExportBatchSize {
+			return nil
+		}
+		afterID = batch[len(batch)-1].ID
+	}
+}
+
+// participantName is the profile's full name, or fallback when the user never filled one in.
+func participantName(user models.User, fallback string) string {
+	if user.Profile == nil {
+		return fallback
+	}
+	if name := strings.TrimSpace(user.Profile.FirstName + " " + user.Profile.LastName); name != "" {
+		return name
+	}
+	return fallback
+}
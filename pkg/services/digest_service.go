@@ -0,0 +1,298 @@
+package services
+
+import (
+	"chalk-api/pkg/events"
+	"chalk-api/pkg/models"
+	"chalk-api/pkg/repositories"
+	"chalk-api/pkg/utils"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var ErrDigestNotFound = errors.New("no weekly digest has been generated yet")
+
+const (
+	digestNotificationType = "weekly_digest"
+	digestPeriodDays       = 7
+	digestNoContactDays    = 7
+	digestUpcomingLimit    = 20
+	// A coach gets at most one digest in this window, even if they move their digest day.
+	digestMinInterval = 6 * 24 * time.Hour
+)
+
+// DigestSession is an upcoming session listed in the digest.
+type DigestSession struct {
+	SessionID       uint      `json:"session_id"`
+	ClientID        uint      `json:"client_id"`
+	ClientName      string    `json:"client_name"`
+	ScheduledAt     time.Time `json:"scheduled_at"`
+	DurationMinutes int       `json:"duration_minutes"`
+}
+
+// CoachDigest is the weekly summary stored in the notification's data.
+// The period is the coach's previous seven local days, ending when the digest day starts.
+type CoachDigest struct {
+	PeriodStart       string                              `json:"period_start"` // YYYY-MM-DD, coach local
+	PeriodEnd         string                              `json:"period_end"`   // YYYY-MM-DD, inclusive
+	WorkoutsCompleted int64                               `json:"workouts_completed"`
+	WorkoutsMissed    int64                               `json:"workouts_missed"`
+	UpcomingSessions  int                                 `json:"upcoming_sessions"`
+	NoContactClients  int                                 `json:"no_contact_clients"`
+	Clients           []repositories.DigestClientActivity `json:"clients"`
+	NoContact         []repositories.DigestClientActivity `json:"no_contact"` // no message or session in 7+ days
+	Upcoming          []DigestSession                     `json:"upcoming"`   // next 7 days, capped at 20
+}
+
+// DigestService builds and delivers the weekly coach digest. The digest worker calls SendDueDigests;
+// coaches read the latest one back through GetLatestDigest.
+type DigestService struct {
+	repos            *repositories.RepositoriesCollection
+	coachRepo        *repositories.CoachRepository
+	sessionRepo      *repositories.SessionRepository
+	notificationRepo *repositories.NotificationRepository
+	events           *events.Publisher
+}
+
+func NewDigestService(repos *repositories.RepositoriesCollection, eventsPublisher *events.Publisher) *DigestService {
+	return &DigestService{
+		repos:            repos,
+		coachRepo:        repos.Coach,
+		sessionRepo:      repos.Session,
+		notificationRepo: repos.Notification,
+		events:           eventsPublisher,
+	}
+}
+
+// GetLatestDigest returns the coach's most recent digest notification.
+func (s *DigestService) GetLatestDigest(ctx context.Context, userID uint) (*models.Notification, error) {
+	if _, err := s.coachRepo.GetByUserID(ctx, userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCoachProfileNotFound
+		}
+		return nil, err
+	}
+
+	notification, err := s.notificationRepo.GetLatestByType(ctx, userID, digestNotificationType)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrDigestNotFound
+		}
+		return nil, err
+	}
+	return notification, nil
+}
+
+// SendDueDigests delivers a digest to every coach whose local digest day and hour have arrived
+// and who hasn't had one this week. It returns how many digests were sent. One coach failing
+// doesn't stop the others; they are retried on the next run.
+func (s *DigestService) SendDueDigests(ctx context.Context, now time.Time) (int, error) {
+	coaches, err := s.coachRepo.ListDigestCoaches(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for i := range coaches {
+		coach := coaches[i]
+		if !isDigestDue(coach, now) {
+			continue
+		}
+
+		delivered, err := s.sendDigest(ctx, coach, now)
+		if err != nil {
+			slog.Error("Failed to send weekly digest", "coach_id", coach.CoachID, "error", err)
+			continue
+		}
+		if delivered {
+			sent++
+		}
+	}
+	return sent, nil
+}
+
+func (s *DigestService) sendDigest(ctx context.Context, coach repositories.DigestCoach, now time.Time) (bool, error) {
+	digest, err := s.buildDigest(ctx, coach, now)
+	if err != nil {
+		return false, err
+	}
+
+	data, err := digestNotificationData(digest)
+	if err != nil {
+		return false, err
+	}
+	title := "Your weekly summary"
+	body := digestHeadline(digest)
+
+	delivered := false
+	err = s.repos.WithTransaction(ctx, func(tx *gorm.DB, txRepos *repositories.RepositoriesCollection) error {
+		claimed, err := txRepos.Coach.ClaimDigest(ctx, coach.CoachID, now, now.Add(-digestMinInterval))
+		if err != nil || !claimed {
+			return err
+		}
+
+		notification := &models.Notification{
+			UserID: coach.UserID,
+			Type:   digestNotificationType,
+			Title:  title,
+			Body:   &body,
+			Data:   data,
+		}
+		if err := txRepos.Notification.Create(ctx, notification); err != nil {
+			return err
+		}
+		delivered = true
+
+		if !coach.DigestPushEnabled || s.events == nil {
+			return nil
+		}
+		deviceTokens, err := txRepos.User.GetDeviceTokens(ctx, coach.UserID)
+		if err != nil {
+			return err
+		}
+		if len(deviceTokens) == 0 {
+			return nil
+		}
+		tokens := make([]string, 0, len(deviceTokens))
+		for _, token := range deviceTokens {
+			tokens = append(tokens, token.Token)
+		}
+
+		id := strconv.FormatUint(uint64(coach.CoachID), 10)
+		return s.events.PublishInTx(
+			ctx,
+			tx,
+			events.EventTypeNotificationPush,
+			"coach_digest",
+			id,
+			events.BuildIdempotencyKey(events.EventTypeNotificationPush, "coach_digest", id, digest.PeriodStart),
+			events.PushNotificationPayload{
+				Tokens: tokens,
+				Title:  title,
+				Body:   body,
+				Data: map[string]any{
+					"type":            digestNotificationType,
+					"notification_id": notification.ID,
+				},
+			},
+		)
+	})
+	if err != nil {
+		return false, err
+	}
+	return delivered, nil
+}
+
+func (s *DigestService) buildDigest(ctx context.Context, coach repositories.DigestCoach, now time.Time) (*CoachDigest, error) {
+	loc := utils.ResolveLocation(coach.Timezone)
+	local := now.In(loc)
+	periodEnd := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+	periodStart := periodEnd.AddDate(0, 0, -digestPeriodDays)
+	lastDay := periodEnd.AddDate(0, 0, -1)
+
+	clients, err := s.coachRepo.GetDigestClientActivity(
+		ctx,
+		coach.CoachID,
+		periodStart.UTC(),
+		periodEnd.UTC(),
+		periodStart.Format("2006-01-02"),
+		lastDay.Format("2006-01-02"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions, err := s.sessionRepo.ListSessions(ctx, coach.CoachID, 0, now.UTC(), now.UTC().AddDate(0, 0, digestPeriodDays), repositories.SessionStatusFilter{})
+	if err != nil {
+		return nil, err
+	}
+
+	digest := &CoachDigest{
+		PeriodStart: periodStart.Format("2006-01-02"),
+		PeriodEnd:   lastDay.Format("2006-01-02"),
+		Clients:     clients,
+		NoContact:   []repositories.DigestClientActivity{},
+		Upcoming:    []DigestSession{},
+	}
+	if digest.Clients == nil {
+		digest.Clients = []repositories.DigestClientActivity{}
+	}
+
+	contactCutoff := now.AddDate(0, 0, -digestNoContactDays)
+	for _, client := range clients {
+		digest.WorkoutsCompleted += client.WorkoutsCompleted
+		digest.WorkoutsMissed += client.WorkoutsMissed
+		if client.LastContactAt == nil || client.LastContactAt.Before(contactCutoff) {
+			digest.NoContact = append(digest.NoContact, client)
+		}
+	}
+	digest.NoContactClients = len(digest.NoContact)
+
+	for _, session := range sessions {
+		if session.Status != "scheduled" {
+			continue
+		}
+		digest.UpcomingSessions++
+		if len(digest.Upcoming) >= digestUpcomingLimit {
+			continue
+		}
+		digest.Upcoming = append(digest.Upcoming, DigestSession{
+			SessionID:       session.ID,
+			ClientID:        session.ClientID,
+			ClientName:      digestClientName(session.Client),
+			ScheduledAt:     session.ScheduledAt,
+			DurationMinutes: session.DurationMinutes,
+		})
+	}
+
+	return digest, nil
+}
+
+// isDigestDue reports whether the coach's local digest day and hour have arrived and no digest
+// went out within digestMinInterval. Polling late in the day still delivers; the claim dedupes.
+func isDigestDue(coach repositories.DigestCoach, now time.Time) bool {
+	local := now.In(utils.ResolveLocation(coach.Timezone))
+	if int(local.Weekday()) != coach.DigestDayOfWeek || local.Hour() < coach.DigestHour {
+		return false
+	}
+	return coach.LastDigestSentAt == nil || coach.LastDigestSentAt.Before(now.Add(-digestMinInterval))
+}
+
+func digestHeadline(digest *CoachDigest) string {
+	headline := fmt.Sprintf(
+		"%d workouts completed, %d missed, %d sessions coming up",
+		digest.WorkoutsCompleted,
+		digest.WorkoutsMissed,
+		digest.UpcomingSessions,
+	)
+	if digest.NoContactClients > 0 {
+		headline += fmt.Sprintf(". %d clients haven't heard from you in a week", digest.NoContactClients)
+	}
+	return headline
+}
+
+func digestClientName(client models.ClientProfile) string {
+	if client.User.Profile == nil {
+		return ""
+	}
+	return client.User.Profile.FirstName + " " + client.User.Profile.LastName
+}
+
+// digestNotificationData converts the digest to the notification's JSON data map.
+func digestNotificationData(digest *CoachDigest) (map[string]any, error) {
+	raw, err := json.Marshal(digest)
+	if err != nil {
+		return nil, err
+	}
+	var data map[string]any
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
@@ -0,0 +1,409 @@
+package services
+
+import (
+	"chalk-api/pkg/events"
+	"chalk-api/pkg/models"
+	"chalk-api/pkg/repositories"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrBulkExerciseFilterRequired       = errors.New("bulk exercise edit needs ids, a name pattern or a tag")
+	ErrBulkExerciseMutationRequired     = errors.New("bulk exercise edit has nothing to change")
+	ErrBulkExerciseMutationInvalid      = errors.New("bulk exercise edit adds and removes the same tag")
+	ErrBulkExerciseTooMany              = errors.New("bulk exercise edit matches too many exercises")
+	ErrBulkExerciseConfirmationRequired = errors.New("bulk exercise edit needs a confirmation token from a dry run")
+	ErrBulkExerciseConfirmationInvalid  = errors.New("bulk exercise confirmation token is expired or no longer matches")
+)
+
+const (
+	maxBulkExerciseRows         = 5000
+	bulkExerciseBatchSize       = 500
+	bulkExerciseSampleSize      = 20
+	bulkExerciseConfirmationTTL = 15 * time.Minute
+)
+
+// BulkExerciseFilter selects system exercises; every criterion that is set must match. NamePattern
+// is case-insensitive with * as a wildcard, and a pattern without * matches names containing it.
+type BulkExerciseFilter struct {
+	IDs         []uint `json:"ids" binding:"omitempty,max=5000"`
+	NamePattern string `json:"name_pattern" binding:"omitempty,max=100"`
+	Tag         string `json:"tag" binding:"omitempty,max=50"`
+}
+
+// BulkExerciseMutation is applied to every matched exercise. Tags are removed before they are added,
+// and the muscle group lists replace the stored ones.
+type BulkExerciseMutation struct {
+	AddTags               []string  `json:"add_tags" binding:"omitempty,max=20,dive,max=50"`
+	RemoveTags            []string  `json:"remove_tags" binding:"omitempty,max=20,dive,max=50"`
+	Difficulty            *string   `json:"difficulty" binding:"omitempty,oneof=beginner intermediate advanced"`
+	PrimaryMuscleGroups   *[]string `json:"primary_muscle_groups" binding:"omitempty,max=10,dive,max=50"`
+	SecondaryMuscleGroups *[]string `json:"secondary_muscle_groups" binding:"omitempty,max=10,dive,max=50"`
+}
+
+// BulkUpdateExercisesInput is one bulk edit. A dry run only reports what would change and returns
+// the confirmation token the real run has to send back.
+type BulkUpdateExercisesInput struct {
+	Filter            BulkExerciseFilter   `json:"filter"`
+	Mutation          BulkExerciseMutation `json:"mutation"`
+	DryRun            bool                 `json:"dry_run"`
+	ConfirmationToken string               `json:"confirmation_token" binding:"omitempty,max=200"`
+}
+
+// BulkExerciseSample is one affected exercise as it is now and as the edit leaves it.
+type BulkExerciseSample struct {
+	ID     uint                     `json:"id"`
+	Name   string                   `json:"name"`
+	Before BulkExerciseSampleValues `json:"before"`
+	After  BulkExerciseSampleValues `json:"after"`
+}
+
+type BulkExerciseSampleValues struct {
+	Tags                  []string `json:"tags"`
+	Difficulty            *string  `json:"difficulty"`
+	PrimaryMuscleGroups   []string `json:"primary_muscle_groups"`
+	SecondaryMuscleGroups []string `json:"secondary_muscle_groups"`
+}
+
+// BulkUpdateExercisesResult reports a bulk edit. Dry runs fill in the confirmation token and its
+// expiry; real runs fill in UpdatedCount.
+type BulkUpdateExercisesResult struct {
+	DryRun            bool                 `json:"dry_run"`
+	AffectedCount     int                  `json:"affected_count"`
+	Sample            []BulkExerciseSample `json:"sample"`
+	ConfirmationToken *string              `json:"confirmation_token,omitempty"`
+	ExpiresAt         *time.Time           `json:"expires_at,omitempty"`
+	UpdatedCount      int64                `json:"updated_count"`
+}
+
+// BulkExerciseLimitError is ErrBulkExerciseTooMany with the number of exercises the filter matched.
+type BulkExerciseLimitError struct {
+	Matched int64
+}
+
+func (e *BulkExerciseLimitError) Error() string {
+	return fmt.Sprintf("%s: %d matched, at most %d allowed", ErrBulkExerciseTooMany, e.Matched, maxBulkExerciseRows)
+}
+
+func (e *BulkExerciseLimitError) Is(target error) bool {
+	return target == ErrBulkExerciseTooMany
+}
+
+// ErrorDetails is merged into the API error response.
+func (e *BulkExerciseLimitError) ErrorDetails() map[string]any {
+	return map[string]any{"matched": e.Matched, "max_rows": maxBulkExerciseRows}
+}
+
+// BulkUpdateExercises edits tags, difficulty and muscle groups across system exercises. A real run
+// must send the confirmation token of a dry run with the same filter and mutation, made by the same
+// admin in the last 15 minutes, and is refused if the filter now matches different exercises.
+// The token is a checksum against mistakes rather than a secret. Updates are written in batches
+// inside one transaction with an exercise.bulk_updated audit event, and the exercise caches are
+// cleared afterwards. One operation can touch at most 5,000 exercises.
+func (s *AdminService) BulkUpdateExercises(ctx context.Context, userID uint, input BulkUpdateExercisesInput) (*BulkUpdateExercisesResult, error) {
+	if err := s.requireAdmin(ctx, userID); err != nil {
+		return nil, err
+	}
+	if len(s.bulkEditKey) == 0 {
+		return nil, ErrTokenKeysNotConfigured
+	}
+	if !input.DryRun && strings.TrimSpace(input.ConfirmationToken) == "" {
+		return nil, ErrBulkExerciseConfirmationRequired
+	}
+
+	filter, err := normalizeBulkExerciseFilter(input.Filter)
+	if err != nil {
+		return nil, err
+	}
+	mutation, err := normalizeBulkExerciseMutation(input.Mutation)
+	if err != nil {
+		return nil, err
+	}
+	repoFilter := repositories.ExerciseBulkFilter{
+		IDs:         filter.IDs,
+		NamePattern: bulkExerciseNamePattern(filter.NamePattern),
+		Tag:         filter.Tag,
+	}
+
+	matched, err := s.repos.Exercise.CountBulkMatches(ctx, repoFilter)
+	if err != nil {
+		return nil, err
+	}
+	if matched > maxBulkExerciseRows {
+		return nil, &BulkExerciseLimitError{Matched: matched}
+	}
+	ids, err := s.repos.Exercise.ListBulkMatchIDs(ctx, repoFilter)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) > maxBulkExerciseRows {
+		return nil, &BulkExerciseLimitError{Matched: int64(len(ids))}
+	}
+
+	now := time.Now().UTC()
+	if input.DryRun {
+		return s.previewBulkExerciseUpdate(ctx, userID, filter, mutation, ids, now)
+	}
+
+	expiresAt, ok := parseBulkExerciseToken(input.ConfirmationToken)
+	if !ok || now.After(expiresAt) {
+		return nil, ErrBulkExerciseConfirmationInvalid
+	}
+	expected := s.bulkExerciseToken(userID, filter, mutation, ids, expiresAt)
+	if subtle.ConstantTimeCompare([]byte(expected), []byte(strings.TrimSpace(input.ConfirmationToken))) != 1 {
+		return nil, ErrBulkExerciseConfirmationInvalid
+	}
+	if len(ids) == 0 {
+		return &BulkUpdateExercisesResult{Sample: []BulkExerciseSample{}}, nil
+	}
+
+	changes := repositories.ExerciseBulkChanges{
+		AddTags:               mutation.AddTags,
+		RemoveTags:            mutation.RemoveTags,
+		Difficulty:            mutation.Difficulty,
+		PrimaryMuscleGroups:   mutation.PrimaryMuscleGroups,
+		SecondaryMuscleGroups: mutation.SecondaryMuscleGroups,
+	}
+	var updated int64
+	if err := s.repos.WithTransaction(ctx, func(tx *gorm.DB, txRepos *repositories.RepositoriesCollection) error {
+		for start := 0; start < len(ids); start += bulkExerciseBatchSize {
+			batch := ids[start:min(start+bulkExerciseBatchSize, len(ids))]
+			rows, err := txRepos.Exercise.BulkUpdateExercises(ctx, batch, changes)
+			if err != nil {
+				return err
+			}
+			updated += rows
+		}
+		if s.events == nil {
+			return nil
+		}
+		id := strconv.FormatUint(uint64(userID), 10)
+		return s.events.PublishInTx(
+			ctx,
+			tx,
+			events.EventTypeExerciseBulkUpdate,
+			"user",
+			id,
+			events.BuildIdempotencyKey(events.EventTypeExerciseBulkUpdate, id, strconv.FormatInt(now.UnixNano(), 10)),
+			events.ExerciseBulkUpdatedPayload{
+				AdminUserID: userID,
+				Filter: events.ExerciseBulkFilter{
+					IDs:         filter.IDs,
+					NamePattern: filter.NamePattern,
+					Tag:         filter.Tag,
+				},
+				Mutation: events.ExerciseBulkMutation{
+					AddTags:               mutation.AddTags,
+					RemoveTags:            mutation.RemoveTags,
+					Difficulty:            mutation.Difficulty,
+					PrimaryMuscleGroups:   mutation.PrimaryMuscleGroups,
+					SecondaryMuscleGroups: mutation.SecondaryMuscleGroups,
+				},
+				ExerciseIDs:  ids,
+				UpdatedCount: updated,
+				UpdatedAt:    now,
+			},
+		)
+	}); err != nil {
+		return nil, err
+	}
+
+	if s.exerciseStore != nil {
+		for _, id := range ids {
+			s.exerciseStore.Invalidate(id)
+		}
+		s.exerciseStore.InvalidateSystemLists()
+	}
+	slog.Info("Exercises bulk updated", "admin_user_id", userID, "matched", len(ids), "updated", updated)
+
+	return &BulkUpdateExercisesResult{
+		AffectedCount: len(ids),
+		Sample:        []BulkExerciseSample{},
+		UpdatedCount:  updated,
+	}, nil
+}
+
+// previewBulkExerciseUpdate builds a dry run's result: the first matched exercises before and after
+// the mutation, and the token that confirms the real run.
+func (s *AdminService) previewBulkExerciseUpdate(
+	ctx context.Context,
+	userID uint,
+	filter BulkExerciseFilter,
+	mutation BulkExerciseMutation,
+	ids []uint,
+	now time.Time,
+) (*BulkUpdateExercisesResult, error) {
+	exercises, err := s.repos.Exercise.ListByIDs(ctx, ids[:min(len(ids), bulkExerciseSampleSize)])
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(exercises, func(i, j int) bool { return exercises[i].ID < exercises[j].ID })
+
+	sample := make([]BulkExerciseSample, 0, len(exercises))
+	for i := range exercises {
+		before := bulkExerciseSampleValues(&exercises[i])
+		sample = append(sample, BulkExerciseSample{
+			ID:     exercises[i].ID,
+			Name:   exercises[i].Name,
+			Before: before,
+			After:  applyBulkExerciseMutation(before, mutation),
+		})
+	}
+
+	expiresAt := now.Add(bulkExerciseConfirmationTTL).Truncate(time.Second)
+	token := s.bulkExerciseToken(userID, filter, mutation, ids, expiresAt)
+	return &BulkUpdateExercisesResult{
+		DryRun:            true,
+		AffectedCount:     len(ids),
+		Sample:            sample,
+		ConfirmationToken: &token,
+		ExpiresAt:         &expiresAt,
+	}, nil
+}
+
+func normalizeBulkExerciseFilter(filter BulkExerciseFilter) (BulkExerciseFilter, error) {
+	normalized := BulkExerciseFilter{
+		NamePattern: strings.TrimSpace(filter.NamePattern),
+		Tag:         normalizeExerciseLabel(filter.Tag),
+	}
+	if len(filter.IDs) > 0 {
+		normalized.IDs = slices.Clone(filter.IDs)
+		slices.Sort(normalized.IDs)
+		normalized.IDs = slices.Compact(normalized.IDs)
+	}
+	if len(normalized.IDs) == 0 && strings.Trim(normalized.NamePattern, "*") == "" && normalized.Tag == "" {
+		return normalized, ErrBulkExerciseFilterRequired
+	}
+	return normalized, nil
+}
+
+func normalizeBulkExerciseMutation(mutation BulkExerciseMutation) (BulkExerciseMutation, error) {
+	normalized := BulkExerciseMutation{
+		AddTags:    normalizeExerciseLabels(mutation.AddTags),
+		RemoveTags: normalizeExerciseLabels(mutation.RemoveTags),
+		Difficulty: mutation.Difficulty,
+	}
+	if mutation.PrimaryMuscleGroups != nil {
+		groups := normalizeExerciseLabels(*mutation.PrimaryMuscleGroups)
+		normalized.PrimaryMuscleGroups = &groups
+	}
+	if mutation.SecondaryMuscleGroups != nil {
+		groups := normalizeExerciseLabels(*mutation.SecondaryMuscleGroups)
+		normalized.SecondaryMuscleGroups = &groups
+	}
+
+	if len(normalized.AddTags) == 0 && len(normalized.RemoveTags) == 0 && normalized.Difficulty == nil &&
+		normalized.PrimaryMuscleGroups == nil && normalized.SecondaryMuscleGroups == nil {
+		return normalized, ErrBulkExerciseMutationRequired
+	}
+	for _, tag := range normalized.AddTags {
+		if slices.Contains(normalized.RemoveTags, tag) {
+			return normalized, ErrBulkExerciseMutationInvalid
+		}
+	}
+	return normalized, nil
+}
+
+// normalizeExerciseLabel lowercases and trims a tag or muscle group the way the seeded library
+// stores them.
+func normalizeExerciseLabel(raw string) string {
+	return strings.ToLower(strings.TrimSpace(raw))
+}
+
+// normalizeExerciseLabels normalizes labels, dropping blanks and repeats; the result is never nil.
+func normalizeExerciseLabels(raw []string) []string {
+	labels := make([]string, 0, len(raw))
+	for _, value := range raw {
+		label := normalizeExerciseLabel(value)
+		if label != "" && !slices.Contains(labels, label) {
+			labels = append(labels, label)
+		}
+	}
+	return labels
+}
+
+// bulkExerciseNamePattern turns a name pattern into an ILIKE pattern, escaping % and _ so only *
+// is a wildcard.
+func bulkExerciseNamePattern(pattern string) string {
+	if pattern == "" {
+		return ""
+	}
+	escaped := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`, `*`, `%`).Replace(pattern)
+	if !strings.Contains(pattern, "*") {
+		escaped = "%" + escaped + "%"
+	}
+	return escaped
+}
+
+func bulkExerciseSampleValues(exercise *models.Exercise) BulkExerciseSampleValues {
+	return BulkExerciseSampleValues{
+		Tags:                  slices.Clone(exercise.Tags),
+		Difficulty:            exercise.Difficulty,
+		PrimaryMuscleGroups:   slices.Clone(exercise.PrimaryMuscleGroups),
+		SecondaryMuscleGroups: slices.Clone(exercise.SecondaryMuscleGroups),
+	}
+}
+
+// applyBulkExerciseMutation mirrors ExerciseRepository.BulkUpdateExercises for the dry run sample.
+func applyBulkExerciseMutation(values BulkExerciseSampleValues, mutation BulkExerciseMutation) BulkExerciseSampleValues {
+	tags := make([]string, 0, len(values.Tags)+len(mutation.AddTags))
+	for _, tag := range append(slices.Clone(values.Tags), mutation.AddTags...) {
+		if !slices.Contains(mutation.RemoveTags, tag) && !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	values.Tags = tags
+	if mutation.Difficulty != nil {
+		values.Difficulty = mutation.Difficulty
+	}
+	if mutation.PrimaryMuscleGroups != nil {
+		values.PrimaryMuscleGroups = *mutation.PrimaryMuscleGroups
+	}
+	if mutation.SecondaryMuscleGroups != nil {
+		values.SecondaryMuscleGroups = *mutation.SecondaryMuscleGroups
+	}
+	return values
+}
+
+// bulkExerciseToken is "<expiry unix seconds>.<HMAC-SHA256 hex>" over the expiry, the admin, the
+// normalized filter and mutation, and the matched IDs. Keying it on a key derived from the JWT
+// signing key means a caller can't mint a token for a request it never dry-ran.
+func (s *AdminService) bulkExerciseToken(userID uint, filter BulkExerciseFilter, mutation BulkExerciseMutation, ids []uint, expiresAt time.Time) string {
+	request, _ := json.Marshal(struct {
+		Filter   BulkExerciseFilter   `json:"filter"`
+		Mutation BulkExerciseMutation `json:"mutation"`
+		IDs      []uint               `json:"ids"`
+	}{filter, mutation, ids})
+
+	expiry := strconv.FormatInt(expiresAt.Unix(), 10)
+	mac := hmac.New(sha256.New, s.bulkEditKey)
+	mac.Write([]byte("bulk-exercise-edit|" + expiry + "|" + strconv.FormatUint(uint64(userID), 10) + "|" + string(request)))
+	return expiry + "." + hex.EncodeToString(mac.Sum(nil))
+}
+
+func parseBulkExerciseToken(token string) (time.Time, bool) {
+	expiry, _, found := strings.Cut(strings.TrimSpace(token), ".")
+	if !found {
+		return time.Time{}, false
+	}
+	seconds, err := strconv.ParseInt(expiry, 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(seconds, 0).UTC(), true
+}
@@ -0,0 +1,97 @@
+package services
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBulkExerciseTokenIsKeyedOnTheServerSecret(t *testing.T) {
+	filter := BulkExerciseFilter{Tag: "legacy"}
+	mutation := BulkExerciseMutation{AddTags: []string{"strength"}}
+	ids := []uint{3, 5, 8}
+	expiresAt := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	admin := &AdminService{bulkEditKey: []byte("server-secret")}
+	token := admin.bulkExerciseToken(1, filter, mutation, ids, expiresAt)
+
+	if got := admin.bulkExerciseToken(1, filter, mutation, ids, expiresAt); got != token {
+		t.Fatalf("token is not deterministic: %q then %q", token, got)
+	}
+	if parsed, ok := parseBulkExerciseToken(token); !ok || !parsed.Equal(expiresAt) {
+		t.Fatalf("parsed expiry = %v, %v; want %v", parsed, ok, expiresAt)
+	}
+
+	other := &AdminService{bulkEditKey: []byte("another-secret")}
+	if other.bulkExerciseToken(1, filter, mutation, ids, expiresAt) == token {
+		t.Error("a different secret produced the same token")
+	}
+	for name, got := range map[string]string{
+		"another admin":    admin.bulkExerciseToken(2, filter, mutation, ids, expiresAt),
+		"other rows":       admin.bulkExerciseToken(1, filter, mutation, []uint{3, 5}, expiresAt),
+		"later expiry":     admin.bulkExerciseToken(1, filter, mutation, ids, expiresAt.Add(time.Minute)),
+		"other mutation":   admin.bulkExerciseToken(1, filter, BulkExerciseMutation{AddTags: []string{"cardio"}}, ids, expiresAt),
+		"other filter tag": admin.bulkExerciseToken(1, BulkExerciseFilter{Tag: "new"}, mutation, ids, expiresAt),
+	} {
+		if got == token {
+			t.Errorf("%s produced the same token", name)
+		}
+	}
+}
+
+// Every API instance builds its own TokenKeys from the same environment; a token from one instance's
+// dry run has to confirm on another, including on RSA-only deployments without JWT_SECRET.
+func TestBulkEditKeyIsSharedAcrossInstances(t *testing.T) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	privatePEM := string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)}))
+
+	derive := func(cfg TokenKeyConfig, purpose string) []byte {
+		t.Helper()
+		keys, err := NewTokenKeys(cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		key, err := keys.DeriveKey(purpose)
+		if err != nil {
+			t.Fatalf("derive key: %v", err)
+		}
+		return key
+	}
+
+	const purpose = "bulk exercise edit confirmation"
+	rsaOnly := TokenKeyConfig{PrivateKeyPEM: privatePEM}
+	first, second := derive(rsaOnly, purpose), derive(rsaOnly, purpose)
+	if len(first) != 32 || !bytes.Equal(first, second) {
+		t.Fatalf("instances derived %x and %x, want the same 32-byte key", first, second)
+	}
+	if bytes.Equal(first, derive(rsaOnly, "another purpose")) {
+		t.Error("different purposes derived the same key")
+	}
+	if hmacOnly := derive(TokenKeyConfig{HMACSecret: "shared"}, purpose); !bytes.Equal(hmacOnly, derive(TokenKeyConfig{HMACSecret: "shared"}, purpose)) {
+		t.Error("HMAC-only instances derived different keys")
+	}
+
+	filter := BulkExerciseFilter{IDs: []uint{1, 2}}
+	mutation := BulkExerciseMutation{RemoveTags: []string{"old"}}
+	expiresAt := time.Now().Add(time.Minute).Truncate(time.Second)
+	issued := (&AdminService{bulkEditKey: first}).bulkExerciseToken(1, filter, mutation, filter.IDs, expiresAt)
+	if confirmed := (&AdminService{bulkEditKey: second}).bulkExerciseToken(1, filter, mutation, filter.IDs, expiresAt); confirmed != issued {
+		t.Errorf("second instance computed %q, want the first instance's %q", confirmed, issued)
+	}
+
+	unconfigured, err := NewTokenKeys(TokenKeyConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := unconfigured.DeriveKey(purpose); !errors.Is(err, ErrTokenKeysNotConfigured) {
+		t.Errorf("derive without key material = %v, want ErrTokenKeysNotConfigured", err)
+	}
+}
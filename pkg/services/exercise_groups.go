@@ -0,0 +1,136 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// ErrInvalidExerciseGroups is returned when a template's superset or circuit groups can't be rendered.
+var ErrInvalidExerciseGroups = errors.New("invalid exercise groups")
+
+// Group types an exercise group can have; exercises in a group without a type are supersets.
+const (
+	GroupTypeSuperset = "superset"
+	GroupTypeCircuit  = "circuit"
+	GroupTypeGiantSet = "giant_set"
+)
+
+// Reasons an exercise group is rejected
+const (
+	GroupViolationInvalidNumber = "invalid_group_number" // superset_group must be positive
+	GroupViolationTooFew        = "too_few_exercises"    // a group needs at least two exercises
+	GroupViolationInvalidType   = "invalid_group_type"
+	GroupViolationMixedTypes    = "mixed_group_types"
+	GroupViolationNotContiguous = "not_contiguous" // another exercise sits between two of the group's
+)
+
+var validGroupTypes = map[string]bool{
+	GroupTypeSuperset: true,
+	GroupTypeCircuit:  true,
+	GroupTypeGiantSet: true,
+}
+
+// ExerciseGroupViolation is one problem with one group.
+type ExerciseGroupViolation struct {
+	Group  int    `json:"group"`
+	Reason string `json:"reason"`
+}
+
+// ExerciseGroupError is ErrInvalidExerciseGroups with every offending group, ordered by group number.
+type ExerciseGroupError struct {
+	Violations []ExerciseGroupViolation
+}
+
+func (e *ExerciseGroupError) Error() string {
+	return fmt.Sprintf("%s: %v", ErrInvalidExerciseGroups, e.Violations)
+}
+
+func (e *ExerciseGroupError) Is(target error) bool {
+	return target == ErrInvalidExerciseGroups
+}
+
+// ErrorDetails is merged into the API error response.
+func (e *ExerciseGroupError) ErrorDetails() map[string]any {
+	return map[string]any{"groups": e.Violations}
+}
+
+// exerciseSlot is the part of a template or workout exercise that decides where it goes and how it groups.
+type exerciseSlot struct {
+	order     int
+	group     *int
+	groupType *string
+}
+
+// arrangeExercises puts exercises in display order and checks their groups. It returns the input indexes
+// in that order, to be renumbered 1..n so duplicate order_index values can't survive, and each
+// exercise's group type: nil outside a group, superset when the group gave none.
+func arrangeExercises(slots []exerciseSlot) ([]int, []*string, error) {
+	order := make([]int, len(slots))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return slots[order[a]].order < slots[order[b]].order
+	})
+
+	groupTypes := make([]*string, len(slots))
+	members := make(map[int][]int) // group number -> positions in display order
+	for position, index := range order {
+		slot := slots[index]
+		if slot.group == nil {
+			continue
+		}
+		groupType := GroupTypeSuperset
+		if slot.groupType != nil && *slot.groupType != "" {
+			groupType = *slot.groupType
+		}
+		groupTypes[index] = &groupType
+		members[*slot.group] = append(members[*slot.group], position)
+	}
+
+	groups := make([]int, 0, len(members))
+	for group := range members {
+		groups = append(groups, group)
+	}
+	sort.Ints(groups)
+
+	var violations []ExerciseGroupViolation
+	for _, group := range groups {
+		positions := members[group]
+		if group <= 0 {
+			violations = append(violations, ExerciseGroupViolation{Group: group, Reason: GroupViolationInvalidNumber})
+			continue
+		}
+		if len(positions) < 2 {
+			violations = append(violations, ExerciseGroupViolation{Group: group, Reason: GroupViolationTooFew})
+		}
+
+		first := *groupTypes[order[positions[0]]]
+		mixed, invalid := false, false
+		for _, position := range positions {
+			groupType := *groupTypes[order[position]]
+			if !validGroupTypes[groupType] {
+				invalid = true
+			}
+			if groupType != first {
+				mixed = true
+			}
+		}
+		if invalid {
+			violations = append(violations, ExerciseGroupViolation{Group: group, Reason: GroupViolationInvalidType})
+		}
+		if mixed {
+			violations = append(violations, ExerciseGroupViolation{Group: group, Reason: GroupViolationMixedTypes})
+		}
+
+		if positions[len(positions)-1]-positions[0] != len(positions)-1 {
+			violations = append(violations, ExerciseGroupViolation{Group: group, Reason: GroupViolationNotContiguous})
+		}
+	}
+
+	if len(violations) > 0 {
+		return nil, nil, &ExerciseGroupError{Violations: violations}
+	}
+	return order, groupTypes, nil
+}
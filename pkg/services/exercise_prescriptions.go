@@ -0,0 +1,191 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidExercisePrescription is returned when an exercise is prescribed in a way its
+// measurement_type can't be tracked by, such as reps on a timed plank.
+var ErrInvalidExercisePrescription = errors.New("invalid exercise prescription")
+
+// Exercise measurement types
+const (
+	MeasurementTypeReps     = "reps"
+	MeasurementTypeTime     = "time"
+	MeasurementTypeDistance = "distance"
+)
+
+// Reasons an exercise's prescription is rejected
+const (
+	PrescriptionViolationUnknownExercise     = "unknown_exercise"
+	PrescriptionViolationMissingReps         = "missing_sets_or_reps"       // reps exercises need sets and reps_min
+	PrescriptionViolationMissingDuration     = "missing_duration"           // time exercises need prescribed_duration_seconds
+	PrescriptionViolationMissingDistance     = "missing_distance"           // distance exercises need prescribed_distance and its unit
+	PrescriptionViolationRepsNotAllowed      = "reps_not_allowed"           // reps on a time or distance exercise
+	PrescriptionViolationDurationNotAllowed  = "duration_not_allowed"       // prescribed_duration_seconds on a reps exercise
+	PrescriptionViolationDistanceNotAllowed  = "distance_not_allowed"       // prescribed_distance on a reps or time exercise
+	PrescriptionViolationInvalidTargetAmount = "invalid_prescription_value" // a zero or negative target
+)
+
+// ExercisePrescriptionViolation is one problem with the exercise at Index in the request's list.
+type ExercisePrescriptionViolation struct {
+	Index           int    `json:"index"`
+	ExerciseID      uint   `json:"exercise_id"`
+	MeasurementType string `json:"measurement_type,omitempty"`
+	Reason          string `json:"reason"`
+}
+
+// ExercisePrescriptionError is ErrInvalidExercisePrescription with every offending exercise, in
+// request order.
+type ExercisePrescriptionError struct {
+	Violations []ExercisePrescriptionViolation
+}
+
+func (e *ExercisePrescriptionError) Error() string {
+	return fmt.Sprintf("%s: %v", ErrInvalidExercisePrescription, e.Violations)
+}
+
+func (e *ExercisePrescriptionError) Is(target error) bool {
+	return target == ErrInvalidExercisePrescription
+}
+
+// ErrorDetails is merged into the API error response.
+func (e *ExercisePrescriptionError) ErrorDetails() map[string]any {
+	return map[string]any{"exercises": e.Violations}
+}
+
+// exercisePrescription is the part of a template or workout exercise checked against the exercise's
+// measurement_type.
+type exercisePrescription struct {
+	exerciseID      uint
+	sets            *int
+	repsMin         *int
+	repsMax         *int
+	durationSeconds *int
+	distance        *float64
+	distanceUnit    *string
+	note            *string
+}
+
+// validateExercisePrescriptions loads the prescribed exercises in one query and checks each
+// prescription against its exercise's measurement_type. It returns an ExercisePrescriptionError
+// listing every problem, or nil.
+func (s *WorkoutService) validateExercisePrescriptions(ctx context.Context, prescriptions []exercisePrescription) error {
+	if len(prescriptions) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, 0, len(prescriptions))
+	seen := make(map[uint]bool, len(prescriptions))
+	for _, prescription := range prescriptions {
+		if !seen[prescription.exerciseID] {
+			seen[prescription.exerciseID] = true
+			ids = append(ids, prescription.exerciseID)
+		}
+	}
+	exercises, err := s.exerciseRepo.ListByIDs(ctx, ids)
+	if err != nil {
+		return err
+	}
+	measurementTypes := make(map[uint]string, len(exercises))
+	for _, exercise := range exercises {
+		measurementTypes[exercise.ID] = exercise.MeasurementType
+	}
+
+	var violations []ExercisePrescriptionViolation
+	for i, prescription := range prescriptions {
+		measurementType, ok := measurementTypes[prescription.exerciseID]
+		if !ok {
+			violations = append(violations, ExercisePrescriptionViolation{
+				Index:      i,
+				ExerciseID: prescription.exerciseID,
+				Reason:     PrescriptionViolationUnknownExercise,
+			})
+			continue
+		}
+		for _, reason := range prescriptionViolations(measurementType, prescription) {
+			violations = append(violations, ExercisePrescriptionViolation{
+				Index:           i,
+				ExerciseID:      prescription.exerciseID,
+				MeasurementType: measurementType,
+				Reason:          reason,
+			})
+		}
+	}
+	if len(violations) > 0 {
+		return &ExercisePrescriptionError{Violations: violations}
+	}
+	return nil
+}
+
+// prescriptionViolations returns why p doesn't fit measurementType. Sets apply to every type. A
+// prescription_note stands in for a missing target (e.g. "AMRAP"), but never for a target of the
+// wrong kind. Unrecognized measurement types are not checked.
+func prescriptionViolations(measurementType string, p exercisePrescription) []string {
+	var reasons []string
+	hasReps := p.repsMin != nil || p.repsMax != nil
+	hasDuration := p.durationSeconds != nil
+	hasDistance := p.distance != nil || p.distanceUnit != nil
+	hasNote := p.note != nil && strings.TrimSpace(*p.note) != ""
+
+	if (p.sets != nil && *p.sets <= 0) ||
+		(p.repsMin != nil && *p.repsMin <= 0) ||
+		(p.repsMax != nil && *p.repsMax <= 0) ||
+		(p.durationSeconds != nil && *p.durationSeconds <= 0) ||
+		(p.distance != nil && *p.distance <= 0) {
+		reasons = append(reasons, PrescriptionViolationInvalidTargetAmount)
+	}
+
+	switch measurementType {
+	case MeasurementTypeReps:
+		if (p.sets == nil || p.repsMin == nil) && !hasNote {
+			reasons = append(reasons, PrescriptionViolationMissingReps)
+		}
+		if hasDuration {
+			reasons = append(reasons, PrescriptionViolationDurationNotAllowed)
+		}
+		if hasDistance {
+			reasons = append(reasons, PrescriptionViolationDistanceNotAllowed)
+		}
+	case MeasurementTypeTime:
+		if !hasDuration && !hasNote {
+			reasons = append(reasons, PrescriptionViolationMissingDuration)
+		}
+		if hasReps {
+			reasons = append(reasons, PrescriptionViolationRepsNotAllowed)
+		}
+		if hasDistance {
+			reasons = append(reasons, PrescriptionViolationDistanceNotAllowed)
+		}
+	case MeasurementTypeDistance:
+		if (p.distance == nil || p.distanceUnit == nil) && !hasNote {
+			reasons = append(reasons, PrescriptionViolationMissingDistance)
+		}
+		// A duration alongside the distance is a pace target and is allowed
+		if hasReps {
+			reasons = append(reasons, PrescriptionViolationRepsNotAllowed)
+		}
+	}
+	return reasons
+}
+
+// validateTemplateExercises checks a template's exercise inputs; see validateExercisePrescriptions.
+func (s *WorkoutService) validateTemplateExercises(ctx context.Context, inputs []TemplateExerciseInput) error {
+	prescriptions := make([]exercisePrescription, len(inputs))
+	for i, input := range inputs {
+		prescriptions[i] = exercisePrescription{
+			exerciseID:      input.ExerciseID,
+			sets:            input.Sets,
+			repsMin:         input.RepsMin,
+			repsMax:         input.RepsMax,
+			durationSeconds: input.PrescribedDurationSeconds,
+			distance:        input.PrescribedDistance,
+			distanceUnit:    input.PrescribedDistanceUnit,
+			note:            input.PrescriptionNote,
+		}
+	}
+	return s.validateExercisePrescriptions(ctx, prescriptions)
+}
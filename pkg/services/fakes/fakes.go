@@ -0,0 +1,227 @@
+// Package fakes provides in-memory stand-ins for the repository interfaces the services
+// depend on, so service logic can be unit-tested without a database. Missing rows return
+// gorm.ErrRecordNotFound, matching the real repositories.
+package fakes
+
+import (
+	"chalk-api/pkg/models"
+	"chalk-api/pkg/repositories"
+	"chalk-api/pkg/units"
+	"context"
+	"errors"
+	"sort"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// ErrTransactionsUnsupported is returned by Transactor: transactional paths need a real database.
+var ErrTransactionsUnsupported = errors.New("fakes: transactions require a database")
+
+// Transactor fails every transaction. Service code that only reaches the transaction after
+// its validation can still be tested up to that point.
+type Transactor struct {
+	Calls int
+}
+
+func (t *Transactor) WithTransaction(ctx context.Context, fn func(tx *gorm.DB, txRepos *repositories.RepositoriesCollection) error) error {
+	t.Calls++
+	return ErrTransactionsUnsupported
+}
+
+// CoachRepository stores coach profiles by ID, primary locations by coach ID and active team
+// memberships by user ID.
+type CoachRepository struct {
+	mu        sync.Mutex
+	profiles  map[uint]models.CoachProfile
+	locations map[uint]models.CoachLocation
+	members   map[uint]models.CoachTeamMember
+	nextID    uint
+}
+
+func NewCoachRepository() *CoachRepository {
+	return &CoachRepository{
+		profiles:  make(map[uint]models.CoachProfile),
+		locations: make(map[uint]models.CoachLocation),
+		members:   make(map[uint]models.CoachTeamMember),
+	}
+}
+
+// Add stores profile, assigning an ID when it has none.
+func (r *CoachRepository) Add(profile models.CoachProfile) models.CoachProfile {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	profile.ID = assignID(&r.nextID, profile.ID)
+	r.profiles[profile.ID] = profile
+	return profile
+}
+
+func (r *CoachRepository) GetByID(ctx context.Context, id uint) (*models.CoachProfile, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	profile, ok := r.profiles[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &profile, nil
+}
+
+func (r *CoachRepository) GetByUserID(ctx context.Context, userID uint) (*models.CoachProfile, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, profile := range r.profiles {
+		if profile.UserID == userID {
+			return &profile, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *CoachRepository) GetBySlug(ctx context.Context, slug string) (*models.CoachProfile, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, profile := range r.profiles {
+		if profile.Slug != nil && *profile.Slug == slug {
+			return &profile, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+// SetPrimaryLocation stores location as its coach's primary location.
+func (r *CoachRepository) SetPrimaryLocation(location models.CoachLocation) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.locations[location.CoachID] = location
+}
+
+func (r *CoachRepository) GetPrimaryLocation(ctx context.Context, coachID uint) (*models.CoachLocation, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	location, ok := r.locations[coachID]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &location, nil
+}
+
+// AddTeamMember makes userID an active member of coachID's team with role.
+func (r *CoachRepository) AddTeamMember(coachID, userID uint, role string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.members[userID] = models.CoachTeamMember{
+		CoachID: coachID,
+		UserID:  &userID,
+		Role:    role,
+		Status:  models.CoachTeamMemberActive,
+	}
+}
+
+func (r *CoachRepository) GetActiveTeamMembership(ctx context.Context, userID uint) (*models.CoachTeamMember, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	member, ok := r.members[userID]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &member, nil
+}
+
+// ClientRepository stores client profiles by ID.
+type ClientRepository struct {
+	mu       sync.Mutex
+	profiles map[uint]models.ClientProfile
+	nextID   uint
+}
+
+func NewClientRepository() *ClientRepository {
+	return &ClientRepository{profiles: make(map[uint]models.ClientProfile)}
+}
+
+// Add stores profile, assigning an ID when it has none.
+func (r *ClientRepository) Add(profile models.ClientProfile) models.ClientProfile {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	profile.ID = assignID(&r.nextID, profile.ID)
+	r.profiles[profile.ID] = profile
+	return profile
+}
+
+func (r *ClientRepository) GetByID(ctx context.Context, id uint) (*models.ClientProfile, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	profile, ok := r.profiles[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &profile, nil
+}
+
+func (r *ClientRepository) ListByUser(ctx context.Context, userID uint) ([]models.ClientProfile, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	profiles := []models.ClientProfile{}
+	for _, profile := range r.profiles {
+		if profile.UserID == userID {
+			profiles = append(profiles, profile)
+		}
+	}
+	sort.Slice(profiles, func(i, j int) bool { return profiles[i].ID < profiles[j].ID })
+	return profiles, nil
+}
+
+// UserRepository stores unit preferences and timezones by user ID; users without any get
+// units.DefaultPreferences and UTC.
+type UserRepository struct {
+	mu        sync.Mutex
+	prefs     map[uint]units.Preferences
+	timezones map[uint]string
+}
+
+func NewUserRepository() *UserRepository {
+	return &UserRepository{prefs: make(map[uint]units.Preferences), timezones: make(map[uint]string)}
+}
+
+// SetUnitPreferences stores the units userID wants to see.
+func (r *UserRepository) SetUnitPreferences(userID uint, prefs units.Preferences) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.prefs[userID] = prefs
+}
+
+func (r *UserRepository) GetUnitPreferences(ctx context.Context, userID uint) (units.Preferences, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if prefs, ok := r.prefs[userID]; ok {
+		return prefs, nil
+	}
+	return units.DefaultPreferences, nil
+}
+
+// SetTimezone stores the profile timezone of userID.
+func (r *UserRepository) SetTimezone(userID uint, timezone string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.timezones[userID] = timezone
+}
+
+func (r *UserRepository) GetTimezone(ctx context.Context, userID uint) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if timezone, ok := r.timezones[userID]; ok && timezone != "" {
+		return timezone, nil
+	}
+	return "UTC", nil
+}
+
+// assignID returns id, or the next sequence value when id is zero.
+func assignID(next *uint, id uint) uint {
+	if id == 0 {
+		*next++
+		return *next
+	}
+	if id > *next {
+		*next = id
+	}
+	return id
+}
@@ -0,0 +1,53 @@
+package fakes
+
+import (
+	"context"
+	"sync"
+)
+
+// FlagEvaluator forces feature flag states for tests. Flags that were never set are off, the same
+// as unknown keys in the real service; a flag set per user wins over its global state.
+type FlagEvaluator struct {
+	mu      sync.Mutex
+	global  map[string]bool
+	perUser map[string]map[uint]bool
+
+	// Err, when set, is returned by every Evaluate call
+	Err error
+}
+
+func NewFlagEvaluator() *FlagEvaluator {
+	return &FlagEvaluator{
+		global:  make(map[string]bool),
+		perUser: make(map[string]map[uint]bool),
+	}
+}
+
+// Set turns a flag on or off for everyone.
+func (f *FlagEvaluator) Set(key string, on bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.global[key] = on
+}
+
+// SetForUser turns a flag on or off for one user.
+func (f *FlagEvaluator) SetForUser(key string, userID uint, on bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.perUser[key] == nil {
+		f.perUser[key] = make(map[uint]bool)
+	}
+	f.perUser[key][userID] = on
+}
+
+func (f *FlagEvaluator) Evaluate(ctx context.Context, userID uint, key string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.Err != nil {
+		return false, f.Err
+	}
+	if on, ok := f.perUser[key][userID]; ok {
+		return on, nil
+	}
+	return f.global[key], nil
+}
@@ -0,0 +1,576 @@
+package fakes
+
+import (
+	"chalk-api/pkg/models"
+	"chalk-api/pkg/repositories"
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SessionRepository keeps availability, presets, overrides, session types, sessions, time blocks
+// and feedback in memory.
+type SessionRepository struct {
+	mu           sync.Mutex
+	availability map[uint][]models.CoachAvailability
+	presets      map[uint]models.AvailabilityPreset
+	overrides    map[uint]models.CoachAvailabilityOverride
+	sessionTypes map[uint]models.SessionType
+	sessions     map[uint]models.Session
+	timeBlocks   map[uint]models.CoachTimeBlock
+	feedback     map[uint]models.SessionFeedback
+	nextID       uint
+}
+
+func NewSessionRepository() *SessionRepository {
+	return &SessionRepository{
+		availability: make(map[uint][]models.CoachAvailability),
+		presets:      make(map[uint]models.AvailabilityPreset),
+		overrides:    make(map[uint]models.CoachAvailabilityOverride),
+		sessionTypes: make(map[uint]models.SessionType),
+		sessions:     make(map[uint]models.Session),
+		timeBlocks:   make(map[uint]models.CoachTimeBlock),
+		feedback:     make(map[uint]models.SessionFeedback),
+	}
+}
+
+// AddSession stores a session directly, bypassing booking rules.
+func (r *SessionRepository) AddSession(session models.Session) models.Session {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	session.ID = assignID(&r.nextID, session.ID)
+	r.sessions[session.ID] = session
+	return session
+}
+
+// --- Availability ---
+
+func (r *SessionRepository) SetAvailability(ctx context.Context, coachID uint, slots []models.CoachAvailability) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored := make([]models.CoachAvailability, len(slots))
+	for i := range slots {
+		slots[i].ID = assignID(&r.nextID, slots[i].ID)
+		stored[i] = slots[i]
+	}
+	r.availability[coachID] = stored
+	return nil
+}
+
+func (r *SessionRepository) GetAvailability(ctx context.Context, coachID uint) ([]models.CoachAvailability, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	slots := []models.CoachAvailability{}
+	for _, slot := range r.availability[coachID] {
+		if slot.IsActive {
+			slots = append(slots, slot)
+		}
+	}
+	sort.SliceStable(slots, func(i, j int) bool {
+		if slots[i].DayOfWeek != slots[j].DayOfWeek {
+			return slots[i].DayOfWeek < slots[j].DayOfWeek
+		}
+		return slots[i].StartTime < slots[j].StartTime
+	})
+	return slots, nil
+}
+
+// --- Availability Presets ---
+
+func (r *SessionRepository) CreateAvailabilityPreset(ctx context.Context, preset *models.AvailabilityPreset) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, existing := range r.presets {
+		if existing.CoachID == preset.CoachID && existing.Name == preset.Name {
+			return repositories.ErrAvailabilityPresetExists
+		}
+	}
+	preset.ID = assignID(&r.nextID, preset.ID)
+	r.presets[preset.ID] = *preset
+	return nil
+}
+
+func (r *SessionRepository) ListAvailabilityPresets(ctx context.Context, coachID uint) ([]models.AvailabilityPreset, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	presets := []models.AvailabilityPreset{}
+	for _, preset := range r.presets {
+		if preset.CoachID == coachID {
+			presets = append(presets, preset)
+		}
+	}
+	sort.SliceStable(presets, func(i, j int) bool { return presets[i].Name < presets[j].Name })
+	return presets, nil
+}
+
+func (r *SessionRepository) GetAvailabilityPresetByID(ctx context.Context, id uint) (*models.AvailabilityPreset, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	preset, ok := r.presets[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &preset, nil
+}
+
+func (r *SessionRepository) DeleteAvailabilityPreset(ctx context.Context, id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.presets, id)
+	return nil
+}
+
+// --- Overrides ---
+
+func (r *SessionRepository) CreateOverride(ctx context.Context, override *models.CoachAvailabilityOverride) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	override.ID = assignID(&r.nextID, override.ID)
+	r.overrides[override.ID] = *override
+	return nil
+}
+
+func (r *SessionRepository) ListOverrides(ctx context.Context, coachID uint, startDate, endDate string) ([]models.CoachAvailabilityOverride, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	overrides := []models.CoachAvailabilityOverride{}
+	for _, override := range r.overrides {
+		// Dates are YYYY-MM-DD, so string comparison orders them correctly.
+		if override.CoachID == coachID && override.Date >= startDate && override.Date <= endDate {
+			overrides = append(overrides, override)
+		}
+	}
+	sort.SliceStable(overrides, func(i, j int) bool { return overrides[i].Date < overrides[j].Date })
+	return overrides, nil
+}
+
+func (r *SessionRepository) GetOverrideByID(ctx context.Context, id uint) (*models.CoachAvailabilityOverride, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	override, ok := r.overrides[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &override, nil
+}
+
+func (r *SessionRepository) DeleteOverride(ctx context.Context, id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.overrides, id)
+	return nil
+}
+
+// --- Session Types ---
+
+func (r *SessionRepository) CreateSessionType(ctx context.Context, st *models.SessionType) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	st.ID = assignID(&r.nextID, st.ID)
+	r.sessionTypes[st.ID] = *st
+	return nil
+}
+
+func (r *SessionRepository) ListSessionTypes(ctx context.Context, coachID uint) ([]models.SessionType, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	types := []models.SessionType{}
+	for _, st := range r.sessionTypes {
+		if st.CoachID == coachID && st.IsActive {
+			types = append(types, st)
+		}
+	}
+	sortSessionTypes(types)
+	return types, nil
+}
+
+func (r *SessionRepository) ListClientBookableSessionTypes(ctx context.Context, coachID uint) ([]models.SessionType, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	types := []models.SessionType{}
+	for _, st := range r.sessionTypes {
+		if st.CoachID == coachID && st.IsActive && st.BookableByClient {
+			types = append(types, st)
+		}
+	}
+	sortSessionTypes(types)
+	return types, nil
+}
+
+func (r *SessionRepository) ReorderSessionTypes(ctx context.Context, coachID uint, orderedIDs []uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for position, id := range orderedIDs {
+		st, ok := r.sessionTypes[id]
+		if !ok || st.CoachID != coachID {
+			continue
+		}
+		st.DisplayOrder = position
+		r.sessionTypes[id] = st
+	}
+	return nil
+}
+
+func sortSessionTypes(types []models.SessionType) {
+	sort.SliceStable(types, func(i, j int) bool {
+		if types[i].DisplayOrder != types[j].DisplayOrder {
+			return types[i].DisplayOrder < types[j].DisplayOrder
+		}
+		return types[i].Name < types[j].Name
+	})
+}
+
+func (r *SessionRepository) GetSessionTypeByID(ctx context.Context, id uint) (*models.SessionType, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	st, ok := r.sessionTypes[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &st, nil
+}
+
+func (r *SessionRepository) UpdateSessionType(ctx context.Context, st *models.SessionType) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sessionTypes[st.ID] = *st
+	return nil
+}
+
+// --- Sessions ---
+
+func (r *SessionRepository) GetSession(ctx context.Context, id uint) (*models.Session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	session, ok := r.sessions[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &session, nil
+}
+
+func (r *SessionRepository) ListSessions(ctx context.Context, coachID, clientID uint, startDate, endDate time.Time, filter repositories.SessionStatusFilter) ([]models.Session, error) {
+	return r.filterSessions(func(s models.Session) bool {
+		return (coachID == 0 || s.CoachID == coachID) &&
+			(clientID == 0 || s.ClientID == clientID) &&
+			!s.ScheduledAt.Before(startDate) && !s.ScheduledAt.After(endDate) &&
+			filter.Matches(s.Status)
+	}), nil
+}
+
+func (r *SessionRepository) ListSessionsByClients(ctx context.Context, clientIDs []uint, startDate, endDate time.Time, filter repositories.SessionStatusFilter) ([]models.Session, error) {
+	wanted := make(map[uint]bool, len(clientIDs))
+	for _, id := range clientIDs {
+		wanted[id] = true
+	}
+	return r.filterSessions(func(s models.Session) bool {
+		if s.ScheduledAt.Before(startDate) || s.ScheduledAt.After(endDate) || !filter.Matches(s.Status) {
+			return false
+		}
+		if wanted[s.ClientID] {
+			return true
+		}
+		for _, participant := range s.Participants {
+			if wanted[participant.ClientID] && participant.Status == models.SessionParticipantStatusBooked {
+				return true
+			}
+		}
+		return false
+	}), nil
+}
+
+func (r *SessionRepository) CompleteSession(ctx context.Context, id uint) error {
+	return r.updateSession(id, func(s *models.Session) {
+		now := time.Now()
+		s.Status = "completed"
+		s.CompletedAt = &now
+	})
+}
+
+func (r *SessionRepository) MarkNoShow(ctx context.Context, id uint) error {
+	return r.updateSession(id, func(s *models.Session) { s.Status = "no_show" })
+}
+
+func (r *SessionRepository) RecordCheckIn(ctx context.Context, checkIn *models.Session) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	session, ok := r.sessions[checkIn.ID]
+	if !ok || session.CheckedInAt != nil {
+		return false, nil
+	}
+	session.CheckedInAt = checkIn.CheckedInAt
+	session.CheckInLatitude = checkIn.CheckInLatitude
+	session.CheckInLongitude = checkIn.CheckInLongitude
+	session.CheckInDistanceMeters = checkIn.CheckInDistanceMeters
+	session.CheckInOutsideRadius = checkIn.CheckInOutsideRadius
+	r.sessions[checkIn.ID] = session
+	return true, nil
+}
+
+func (r *SessionRepository) MarkPaid(ctx context.Context, id uint, paidAt time.Time) error {
+	return r.updateSession(id, func(s *models.Session) { s.PaidAt = &paidAt })
+}
+
+func (r *SessionRepository) ListExpiredPendingSessions(ctx context.Context, createdBefore, now time.Time, limit int) ([]models.Session, error) {
+	sessions := r.filterSessions(func(s models.Session) bool {
+		return s.Status == models.SessionStatusPendingConfirmation &&
+			(s.CreatedAt.Before(createdBefore) || !s.ScheduledAt.After(now))
+	})
+	sort.SliceStable(sessions, func(i, j int) bool { return sessions[i].CreatedAt.Before(sessions[j].CreatedAt) })
+	if len(sessions) > limit {
+		sessions = sessions[:limit]
+	}
+	return sessions, nil
+}
+
+func (r *SessionRepository) ListStaleSessions(ctx context.Context, endedBefore time.Time, afterID uint, limit int) ([]models.Session, error) {
+	sessions := r.filterSessions(func(s models.Session) bool {
+		end := s.ScheduledAt.Add(time.Duration(s.DurationMinutes) * time.Minute)
+		return s.Status == "scheduled" && s.CheckedInAt == nil && s.StalePromptedAt == nil &&
+			end.Before(endedBefore) && s.ID > afterID
+	})
+	sort.SliceStable(sessions, func(i, j int) bool { return sessions[i].ID < sessions[j].ID })
+	if len(sessions) > limit {
+		sessions = sessions[:limit]
+	}
+	return sessions, nil
+}
+
+func (r *SessionRepository) ResolveStaleSession(ctx context.Context, id uint, status string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	session, ok := r.sessions[id]
+	if !ok || session.Status != "scheduled" || session.CheckedInAt != nil {
+		return false, nil
+	}
+	session.Status = status
+	if status == "completed" {
+		now := time.Now()
+		session.CompletedAt = &now
+	}
+	r.sessions[id] = session
+	return true, nil
+}
+
+func (r *SessionRepository) HasCoachConflict(ctx context.Context, coachID uint, startAt, endAt time.Time, excludeSessionID *uint) (bool, error) {
+	conflicts := r.filterSessions(func(s models.Session) bool {
+		if s.CoachID != coachID || (s.Status != "scheduled" && s.Status != models.SessionStatusPendingConfirmation) {
+			return false
+		}
+		if excludeSessionID != nil && *excludeSessionID > 0 && s.ID == *excludeSessionID {
+			return false
+		}
+		sessionEnd := s.ScheduledAt.Add(time.Duration(s.DurationMinutes) * time.Minute)
+		return s.ScheduledAt.Before(endAt) && sessionEnd.After(startAt)
+	})
+	if len(conflicts) > 0 {
+		return true, nil
+	}
+	blocks, _ := r.ListTimeBlocks(ctx, coachID, startAt, endAt)
+	for _, block := range blocks {
+		if block.StartAt.Before(endAt) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// HasClientConflict matches sessions by their preloaded Client's UserID; the fake doesn't track
+// group participants.
+func (r *SessionRepository) HasClientConflict(ctx context.Context, clientUserID uint, startAt, endAt time.Time, excludeSessionID *uint) (*repositories.ClientBusyTime, error) {
+	busy := r.clientBusyTimes(clientUserID, startAt, endAt, excludeSessionID)
+	if len(busy) == 0 {
+		return nil, nil
+	}
+	return &busy[0], nil
+}
+
+func (r *SessionRepository) ListClientBusyTimes(ctx context.Context, clientUserID uint, startAt, endAt time.Time) ([]repositories.ClientBusyTime, error) {
+	return r.clientBusyTimes(clientUserID, startAt, endAt, nil), nil
+}
+
+func (r *SessionRepository) clientBusyTimes(clientUserID uint, startAt, endAt time.Time, excludeSessionID *uint) []repositories.ClientBusyTime {
+	sessions := r.filterSessions(func(s models.Session) bool {
+		if s.Client.UserID != clientUserID || (s.Status != "scheduled" && s.Status != models.SessionStatusPendingConfirmation) {
+			return false
+		}
+		if excludeSessionID != nil && *excludeSessionID > 0 && s.ID == *excludeSessionID {
+			return false
+		}
+		sessionEnd := s.ScheduledAt.Add(time.Duration(s.DurationMinutes) * time.Minute)
+		return s.ScheduledAt.Before(endAt) && sessionEnd.After(startAt)
+	})
+	busy := make([]repositories.ClientBusyTime, 0, len(sessions))
+	for _, s := range sessions {
+		busy = append(busy, repositories.ClientBusyTime{
+			SessionID: s.ID,
+			CoachID:   s.CoachID,
+			StartAt:   s.ScheduledAt,
+			EndAt:     s.ScheduledAt.Add(time.Duration(s.DurationMinutes) * time.Minute),
+		})
+	}
+	return busy
+}
+
+// --- Time Blocks ---
+
+func (r *SessionRepository) CreateTimeBlock(ctx context.Context, block *models.CoachTimeBlock) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	block.ID = assignID(&r.nextID, block.ID)
+	r.timeBlocks[block.ID] = *block
+	return nil
+}
+
+func (r *SessionRepository) GetTimeBlockByID(ctx context.Context, id uint) (*models.CoachTimeBlock, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	block, ok := r.timeBlocks[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &block, nil
+}
+
+func (r *SessionRepository) ListTimeBlocks(ctx context.Context, coachID uint, startAt, endAt time.Time) ([]models.CoachTimeBlock, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	blocks := []models.CoachTimeBlock{}
+	for _, block := range r.timeBlocks {
+		if block.CoachID == coachID && !block.StartAt.After(endAt) && block.EndAt.After(startAt) {
+			blocks = append(blocks, block)
+		}
+	}
+	sort.Slice(blocks, func(i, j int) bool { return blocks[i].StartAt.Before(blocks[j].StartAt) })
+	return blocks, nil
+}
+
+func (r *SessionRepository) DeleteTimeBlock(ctx context.Context, id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.timeBlocks, id)
+	return nil
+}
+
+// --- Feedback ---
+
+// AddFeedback stores session feedback directly; SessionService creates it inside a transaction.
+func (r *SessionRepository) AddFeedback(feedback models.SessionFeedback) models.SessionFeedback {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	feedback.ID = assignID(&r.nextID, feedback.ID)
+	r.feedback[feedback.ID] = feedback
+	return feedback
+}
+
+func (r *SessionRepository) ListCoachFeedback(ctx context.Context, coachID uint, limit, offset int) ([]repositories.SessionFeedbackEntry, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	entries := []repositories.SessionFeedbackEntry{}
+	for _, feedback := range r.feedback {
+		if feedback.CoachID != coachID {
+			continue
+		}
+		entry := repositories.SessionFeedbackEntry{
+			ID:                 feedback.ID,
+			SessionID:          feedback.SessionID,
+			SessionScheduledAt: r.sessions[feedback.SessionID].ScheduledAt,
+			Rating:             feedback.Rating,
+			Comment:            feedback.Comment,
+			Anonymous:          feedback.Anonymous,
+			CreatedAt:          feedback.CreatedAt,
+		}
+		if !feedback.Anonymous {
+			clientID := feedback.ClientID
+			entry.ClientID = &clientID
+		}
+		entries = append(entries, entry)
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		if !entries[i].CreatedAt.Equal(entries[j].CreatedAt) {
+			return entries[i].CreatedAt.After(entries[j].CreatedAt)
+		}
+		return entries[i].ID > entries[j].ID
+	})
+	return paginate(entries, limit, offset), int64(len(entries)), nil
+}
+
+// --- Summary ---
+
+func (r *SessionRepository) CountSessionsByStatusAndType(ctx context.Context, coachID uint, start, end time.Time) ([]repositories.SessionStatusTypeCount, error) {
+	type groupKey struct {
+		sessionTypeID uint
+		status        string
+	}
+	counts := make(map[groupKey]int64)
+	for _, session := range r.coachSessionsBetween(coachID, start, end) {
+		counts[groupKey{session.SessionTypeID, session.Status}]++
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rows := make([]repositories.SessionStatusTypeCount, 0, len(counts))
+	for key, count := range counts {
+		rows = append(rows, repositories.SessionStatusTypeCount{
+			SessionTypeID:   key.sessionTypeID,
+			SessionTypeName: r.sessionTypes[key.sessionTypeID].Name,
+			Status:          key.status,
+			Count:           count,
+		})
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].SessionTypeID != rows[j].SessionTypeID {
+			return rows[i].SessionTypeID < rows[j].SessionTypeID
+		}
+		return rows[i].Status < rows[j].Status
+	})
+	return rows, nil
+}
+
+func (r *SessionRepository) CountLateCancellations(ctx context.Context, coachID uint, start, end time.Time) (int64, error) {
+	var count int64
+	for _, session := range r.coachSessionsBetween(coachID, start, end) {
+		for _, participant := range session.Participants {
+			if participant.LateCancelled {
+				count++
+			}
+		}
+	}
+	return count, nil
+}
+
+// coachSessionsBetween returns the coach's sessions starting in [start, end).
+func (r *SessionRepository) coachSessionsBetween(coachID uint, start, end time.Time) []models.Session {
+	return r.filterSessions(func(s models.Session) bool {
+		return s.CoachID == coachID && !s.ScheduledAt.Before(start) && s.ScheduledAt.Before(end)
+	})
+}
+
+func (r *SessionRepository) filterSessions(keep func(models.Session) bool) []models.Session {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	sessions := []models.Session{}
+	for _, session := range r.sessions {
+		if keep(session) {
+			sessions = append(sessions, session)
+		}
+	}
+	sort.SliceStable(sessions, func(i, j int) bool { return sessions[i].ScheduledAt.Before(sessions[j].ScheduledAt) })
+	return sessions
+}
+
+// updateSession mirrors a gorm UPDATE ... WHERE id = ?: a missing row is not an error.
+func (r *SessionRepository) updateSession(id uint, apply func(*models.Session)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	session, ok := r.sessions[id]
+	if !ok {
+		return nil
+	}
+	apply(&session)
+	r.sessions[id] = session
+	return nil
+}
@@ -0,0 +1,646 @@
+package fakes
+
+import (
+	"chalk-api/pkg/models"
+	"chalk-api/pkg/repositories"
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TemplateRepository keeps workout templates, their versions and categories in memory.
+type TemplateRepository struct {
+	mu         sync.Mutex
+	templates  map[uint]models.WorkoutTemplate
+	versions   map[uint][]models.TemplateVersion // by template ID, oldest first
+	categories map[uint]models.TemplateCategory
+	nextID     uint
+}
+
+func NewTemplateRepository() *TemplateRepository {
+	return &TemplateRepository{
+		templates:  make(map[uint]models.WorkoutTemplate),
+		versions:   make(map[uint][]models.TemplateVersion),
+		categories: make(map[uint]models.TemplateCategory),
+	}
+}
+
+func (r *TemplateRepository) Create(ctx context.Context, template *models.WorkoutTemplate) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	template.ID = assignID(&r.nextID, template.ID)
+	if template.Version == 0 {
+		template.Version = 1 // column default
+	}
+	template.UpdatedAt = time.Now()
+	for i := range template.Exercises {
+		template.Exercises[i].ID = assignID(&r.nextID, template.Exercises[i].ID)
+		template.Exercises[i].TemplateID = template.ID
+	}
+	r.templates[template.ID] = *template
+	return nil
+}
+
+func (r *TemplateRepository) GetByID(ctx context.Context, id uint) (*models.WorkoutTemplate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	template, ok := r.templates[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	template.Exercises = append([]models.WorkoutTemplateExercise(nil), template.Exercises...)
+	sort.SliceStable(template.Exercises, func(i, j int) bool {
+		return template.Exercises[i].OrderIndex < template.Exercises[j].OrderIndex
+	})
+	return &template, nil
+}
+
+func (r *TemplateRepository) ListByCoach(ctx context.Context, coachID uint, filter repositories.TemplateListFilter, limit, offset int) ([]models.WorkoutTemplate, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	templates := []models.WorkoutTemplate{}
+	for _, template := range r.templates {
+		if template.CoachID != coachID || !template.IsActive {
+			continue
+		}
+		if filter.CategoryID != nil {
+			if template.CategoryID == nil || *template.CategoryID != *filter.CategoryID {
+				continue
+			}
+		} else if filter.Uncategorized && template.CategoryID != nil {
+			continue
+		}
+		template.Exercises = nil
+		templates = append(templates, template)
+	}
+	sort.SliceStable(templates, func(i, j int) bool { return templates[i].UpdatedAt.After(templates[j].UpdatedAt) })
+	return paginate(templates, limit, offset), int64(len(templates)), nil
+}
+
+func (r *TemplateRepository) Update(ctx context.Context, template *models.WorkoutTemplate) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	current, ok := r.templates[template.ID]
+	if !ok || current.Version != template.Version {
+		return repositories.ErrVersionConflict
+	}
+	template.Version++
+	template.UpdatedAt = time.Now()
+	stored := *template
+	// Save doesn't touch associations; keep the stored exercises.
+	stored.Exercises = r.templates[template.ID].Exercises
+	r.templates[template.ID] = stored
+	return nil
+}
+
+func (r *TemplateRepository) ReplaceExercises(ctx context.Context, templateID uint, exercises []models.WorkoutTemplateExercise) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	template, ok := r.templates[templateID]
+	if !ok {
+		return nil
+	}
+	for i := range exercises {
+		exercises[i].ID = assignID(&r.nextID, 0)
+		exercises[i].TemplateID = templateID
+	}
+	template.Exercises = append([]models.WorkoutTemplateExercise(nil), exercises...)
+	r.templates[templateID] = template
+	return nil
+}
+
+func (r *TemplateRepository) UpdateMetrics(ctx context.Context, id uint, estimatedMinutes *int, metrics *models.WorkoutMetrics) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	template, ok := r.templates[id]
+	if !ok {
+		return nil
+	}
+	template.EstimatedMinutes = estimatedMinutes
+	template.Metrics = metrics
+	r.templates[id] = template
+	return nil
+}
+
+func (r *TemplateRepository) CreateVersion(ctx context.Context, version *models.TemplateVersion, keep int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.templates[version.TemplateID]; !ok {
+		return gorm.ErrRecordNotFound
+	}
+	versions := r.versions[version.TemplateID]
+	version.ID = assignID(&r.nextID, version.ID)
+	version.Version = 1
+	if len(versions) > 0 {
+		version.Version = versions[len(versions)-1].Version + 1
+	}
+	version.CreatedAt = time.Now()
+	versions = append(versions, *version)
+	if keep > 0 && len(versions) > keep {
+		versions = versions[len(versions)-keep:]
+	}
+	r.versions[version.TemplateID] = versions
+	return nil
+}
+
+func (r *TemplateRepository) CountVersions(ctx context.Context, templateID uint) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return int64(len(r.versions[templateID])), nil
+}
+
+func (r *TemplateRepository) ListVersions(ctx context.Context, templateID uint) ([]models.TemplateVersion, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored := r.versions[templateID]
+	versions := make([]models.TemplateVersion, 0, len(stored))
+	for i := len(stored) - 1; i >= 0; i-- {
+		versions = append(versions, stored[i])
+	}
+	return versions, nil
+}
+
+func (r *TemplateRepository) GetVersion(ctx context.Context, templateID uint, version int) (*models.TemplateVersion, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, stored := range r.versions[templateID] {
+		if stored.Version == version {
+			return &stored, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *TemplateRepository) CountByCategory(ctx context.Context, coachID uint) ([]repositories.TemplateCategoryCount, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	byCategory := map[uint]int64{}
+	var uncategorized int64
+	for _, template := range r.templates {
+		if template.CoachID != coachID || !template.IsActive {
+			continue
+		}
+		if template.CategoryID == nil {
+			uncategorized++
+			continue
+		}
+		byCategory[*template.CategoryID]++
+	}
+	counts := []repositories.TemplateCategoryCount{}
+	for id, count := range byCategory {
+		categoryID := id
+		counts = append(counts, repositories.TemplateCategoryCount{CategoryID: &categoryID, Count: count})
+	}
+	if uncategorized > 0 {
+		counts = append(counts, repositories.TemplateCategoryCount{Count: uncategorized})
+	}
+	return counts, nil
+}
+
+func (r *TemplateRepository) CreateCategory(ctx context.Context, category *models.TemplateCategory) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, existing := range r.categories {
+		if existing.CoachID == category.CoachID && strings.EqualFold(existing.Name, category.Name) {
+			return repositories.ErrTemplateCategoryExists
+		}
+	}
+	category.ID = assignID(&r.nextID, category.ID)
+	category.CreatedAt = time.Now()
+	category.UpdatedAt = category.CreatedAt
+	r.categories[category.ID] = *category
+	return nil
+}
+
+func (r *TemplateRepository) GetCategory(ctx context.Context, id uint) (*models.TemplateCategory, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	category, ok := r.categories[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &category, nil
+}
+
+func (r *TemplateRepository) FindCategoryByName(ctx context.Context, coachID uint, name string) (*models.TemplateCategory, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, category := range r.categories {
+		if category.CoachID == coachID && strings.EqualFold(category.Name, name) {
+			return &category, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *TemplateRepository) ListCategories(ctx context.Context, coachID uint) ([]models.TemplateCategory, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	categories := []models.TemplateCategory{}
+	for _, category := range r.categories {
+		if category.CoachID == coachID {
+			categories = append(categories, category)
+		}
+	}
+	sort.SliceStable(categories, func(i, j int) bool {
+		return strings.ToLower(categories[i].Name) < strings.ToLower(categories[j].Name)
+	})
+	return categories, nil
+}
+
+func (r *TemplateRepository) RenameCategory(ctx context.Context, id uint, name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	category, ok := r.categories[id]
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+	for _, existing := range r.categories {
+		if existing.ID != id && existing.CoachID == category.CoachID && strings.EqualFold(existing.Name, name) {
+			return repositories.ErrTemplateCategoryExists
+		}
+	}
+	category.Name = name
+	category.UpdatedAt = time.Now()
+	r.categories[id] = category
+	for templateID, template := range r.templates {
+		if template.CategoryID != nil && *template.CategoryID == id {
+			renamed := name
+			template.Category = &renamed
+			r.templates[templateID] = template
+		}
+	}
+	return nil
+}
+
+func (r *TemplateRepository) DeleteCategory(ctx context.Context, id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for templateID, template := range r.templates {
+		if template.CategoryID != nil && *template.CategoryID == id {
+			template.CategoryID = nil
+			template.Category = nil
+			r.templates[templateID] = template
+		}
+	}
+	delete(r.categories, id)
+	return nil
+}
+
+// ExerciseRepository keeps library exercises and their alternatives in memory.
+type ExerciseRepository struct {
+	mu           sync.Mutex
+	exercises    map[uint]models.Exercise
+	alternatives []models.ExerciseAlternative // in insertion order
+	nextID       uint
+}
+
+func NewExerciseRepository() *ExerciseRepository {
+	return &ExerciseRepository{exercises: make(map[uint]models.Exercise)}
+}
+
+// AddExercise stores an exercise directly, assigning an ID if missing.
+func (r *ExerciseRepository) AddExercise(exercise models.Exercise) models.Exercise {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	exercise.ID = assignID(&r.nextID, exercise.ID)
+	r.exercises[exercise.ID] = exercise
+	return exercise
+}
+
+func (r *ExerciseRepository) GetByID(ctx context.Context, id uint) (*models.Exercise, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	exercise, ok := r.exercises[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &exercise, nil
+}
+
+func (r *ExerciseRepository) ListByIDs(ctx context.Context, ids []uint) ([]models.Exercise, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	exercises := []models.Exercise{}
+	for _, id := range ids {
+		if exercise, ok := r.exercises[id]; ok {
+			exercises = append(exercises, exercise)
+		}
+	}
+	return exercises, nil
+}
+
+func (r *ExerciseRepository) UpsertAlternatives(ctx context.Context, alternatives []models.ExerciseAlternative) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	for i := range alternatives {
+		existing := -1
+		for j := range r.alternatives {
+			if r.alternatives[j].ExerciseID == alternatives[i].ExerciseID &&
+				r.alternatives[j].AlternativeExerciseID == alternatives[i].AlternativeExerciseID {
+				existing = j
+				break
+			}
+		}
+		if existing >= 0 {
+			r.alternatives[existing].Reason = alternatives[i].Reason
+			r.alternatives[existing].UpdatedAt = now
+			alternatives[i] = r.alternatives[existing]
+			continue
+		}
+		alternatives[i].ID = assignID(&r.nextID, alternatives[i].ID)
+		alternatives[i].CreatedAt = now
+		alternatives[i].UpdatedAt = now
+		r.alternatives = append(r.alternatives, alternatives[i])
+	}
+	return nil
+}
+
+func (r *ExerciseRepository) ListAlternatives(ctx context.Context, exerciseIDs []uint) ([]models.ExerciseAlternative, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	wanted := make(map[uint]bool, len(exerciseIDs))
+	for _, id := range exerciseIDs {
+		wanted[id] = true
+	}
+
+	alternatives := []models.ExerciseAlternative{}
+	for _, alternative := range r.alternatives {
+		exercise, ok := r.exercises[alternative.AlternativeExerciseID]
+		if !wanted[alternative.ExerciseID] || !ok || !exercise.IsActive {
+			continue
+		}
+		alternative.Alternative = exercise
+		alternatives = append(alternatives, alternative)
+	}
+	sort.SliceStable(alternatives, func(i, j int) bool {
+		return alternatives[i].ExerciseID < alternatives[j].ExerciseID
+	})
+	return alternatives, nil
+}
+
+func (r *ExerciseRepository) DeleteAlternative(ctx context.Context, exerciseID, alternativeExerciseID uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range r.alternatives {
+		if r.alternatives[i].ExerciseID == exerciseID && r.alternatives[i].AlternativeExerciseID == alternativeExerciseID {
+			r.alternatives = append(r.alternatives[:i], r.alternatives[i+1:]...)
+			return nil
+		}
+	}
+	return gorm.ErrRecordNotFound
+}
+
+// WorkoutRepository keeps workouts, their exercises and set logs in memory.
+type WorkoutRepository struct {
+	mu        sync.Mutex
+	workouts  map[uint]models.Workout
+	exercises map[uint]models.WorkoutExercise
+	logs      map[uint]models.WorkoutLog
+	nextID    uint
+}
+
+func NewWorkoutRepository() *WorkoutRepository {
+	return &WorkoutRepository{
+		workouts:  make(map[uint]models.Workout),
+		exercises: make(map[uint]models.WorkoutExercise),
+		logs:      make(map[uint]models.WorkoutLog),
+	}
+}
+
+// AddWorkout stores a workout and its exercises directly, assigning IDs where missing.
+func (r *WorkoutRepository) AddWorkout(workout models.Workout) models.Workout {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	workout.ID = assignID(&r.nextID, workout.ID)
+	if workout.CreatedAt.IsZero() {
+		workout.CreatedAt = time.Now()
+	}
+	for i := range workout.Exercises {
+		workout.Exercises[i].ID = assignID(&r.nextID, workout.Exercises[i].ID)
+		workout.Exercises[i].WorkoutID = workout.ID
+		r.exercises[workout.Exercises[i].ID] = workout.Exercises[i]
+	}
+	workout.Exercises = nil
+	r.workouts[workout.ID] = workout
+	return workout
+}
+
+func (r *WorkoutRepository) GetByID(ctx context.Context, id uint) (*models.Workout, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	workout, ok := r.workouts[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	workout.Exercises = r.exercisesFor(id, true)
+	return &workout, nil
+}
+
+func (r *WorkoutRepository) FindActiveOnDate(ctx context.Context, clientID uint, date string) (*models.Workout, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var found *models.Workout
+	for _, workout := range r.workouts {
+		if workout.ClientID != clientID || workout.ScheduledDate == nil || *workout.ScheduledDate != date || workout.Status == "skipped" {
+			continue
+		}
+		if found == nil || (found.DuplicateAllowed && !workout.DuplicateAllowed) ||
+			(found.DuplicateAllowed == workout.DuplicateAllowed && workout.ID < found.ID) {
+			w := workout
+			found = &w
+		}
+	}
+	if found == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return found, nil
+}
+
+func (r *WorkoutRepository) ListByClients(ctx context.Context, clientIDs []uint, limit, offset int) ([]models.Workout, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	wanted := make(map[uint]bool, len(clientIDs))
+	for _, id := range clientIDs {
+		wanted[id] = true
+	}
+
+	workouts := []models.Workout{}
+	for _, workout := range r.workouts {
+		if wanted[workout.ClientID] {
+			workout.Exercises = r.exercisesFor(workout.ID, false)
+			workouts = append(workouts, workout)
+		}
+	}
+	// scheduled_date DESC NULLS LAST, created_at DESC
+	sort.SliceStable(workouts, func(i, j int) bool {
+		a, b := workouts[i].ScheduledDate, workouts[j].ScheduledDate
+		switch {
+		case a != nil && b != nil && *a != *b:
+			return *a > *b
+		case a != nil && b == nil:
+			return true
+		case a == nil && b != nil:
+			return false
+		}
+		return workouts[i].CreatedAt.After(workouts[j].CreatedAt)
+	})
+	return paginate(workouts, limit, offset), int64(len(workouts)), nil
+}
+
+func (r *WorkoutRepository) StartWorkout(ctx context.Context, id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if workout, ok := r.workouts[id]; ok {
+		now := time.Now()
+		workout.Status = "in_progress"
+		workout.StartedAt = &now
+		r.workouts[id] = workout
+	}
+	return nil
+}
+
+func (r *WorkoutRepository) UpdateSessionState(ctx context.Context, id uint, state *models.WorkoutSessionState) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	workout, ok := r.workouts[id]
+	if !ok || workout.Status == "completed" || workout.Status == "skipped" {
+		return false, nil
+	}
+	workout.SessionState = state
+	r.workouts[id] = workout
+	return true, nil
+}
+
+func (r *WorkoutRepository) GetExerciseByID(ctx context.Context, id uint) (*models.WorkoutExercise, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	exercise, ok := r.exercises[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	exercise.Workout = r.workouts[exercise.WorkoutID]
+	return &exercise, nil
+}
+
+func (r *WorkoutRepository) MarkExerciseCompleted(ctx context.Context, id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if exercise, ok := r.exercises[id]; ok {
+		exercise.IsCompleted = true
+		r.exercises[id] = exercise
+	}
+	return nil
+}
+
+func (r *WorkoutRepository) SkipExercise(ctx context.Context, id uint, reason string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if exercise, ok := r.exercises[id]; ok {
+		exercise.IsCompleted = false
+		exercise.SkippedReason = &reason
+		r.exercises[id] = exercise
+	}
+	return nil
+}
+
+func (r *WorkoutRepository) CreateLog(ctx context.Context, log *models.WorkoutLog) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	log.ID = assignID(&r.nextID, log.ID)
+	r.logs[log.ID] = *log
+	return nil
+}
+
+func (r *WorkoutRepository) UpdateLog(ctx context.Context, log *models.WorkoutLog) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.logs[log.ID] = *log
+	return nil
+}
+
+func (r *WorkoutRepository) GetLogByID(ctx context.Context, id uint) (*models.WorkoutLog, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	log, ok := r.logs[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	log.WorkoutExercise = r.exercises[log.WorkoutExerciseID]
+	return &log, nil
+}
+
+func (r *WorkoutRepository) ListUnreviewedFormChecks(ctx context.Context, coachID uint, limit, offset int) ([]repositories.FormCheck, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	checks := []repositories.FormCheck{}
+	for _, log := range r.logs {
+		if log.VideoURL == nil || log.ReviewedAt != nil {
+			continue
+		}
+		exercise := r.exercises[log.WorkoutExerciseID]
+		workout, ok := r.workouts[exercise.WorkoutID]
+		if !ok || workout.CoachID != coachID {
+			continue
+		}
+		check := repositories.FormCheck{
+			WorkoutLogID:      log.ID,
+			WorkoutID:         workout.ID,
+			WorkoutExerciseID: exercise.ID,
+			ExerciseID:        exercise.ExerciseID,
+			ExerciseName:      exercise.Exercise.Name,
+			ClientID:          workout.ClientID,
+			SetNumber:         log.SetNumber,
+			VideoURL:          *log.VideoURL,
+		}
+		if log.VideoUploadedAt != nil {
+			check.VideoUploadedAt = *log.VideoUploadedAt
+		}
+		checks = append(checks, check)
+	}
+	sort.SliceStable(checks, func(i, j int) bool {
+		if !checks[i].VideoUploadedAt.Equal(checks[j].VideoUploadedAt) {
+			return checks[i].VideoUploadedAt.Before(checks[j].VideoUploadedAt)
+		}
+		return checks[i].WorkoutLogID < checks[j].WorkoutLogID
+	})
+	return paginate(checks, limit, offset), int64(len(checks)), nil
+}
+
+// exercisesFor returns a workout's exercises in order; callers must hold r.mu.
+func (r *WorkoutRepository) exercisesFor(workoutID uint, withLogs bool) []models.WorkoutExercise {
+	exercises := []models.WorkoutExercise{}
+	for _, exercise := range r.exercises {
+		if exercise.WorkoutID != workoutID {
+			continue
+		}
+		if withLogs {
+			exercise.Logs = nil
+			for _, log := range r.logs {
+				if log.WorkoutExerciseID == exercise.ID {
+					exercise.Logs = append(exercise.Logs, log)
+				}
+			}
+			sort.SliceStable(exercise.Logs, func(i, j int) bool { return exercise.Logs[i].SetNumber < exercise.Logs[j].SetNumber })
+		}
+		exercises = append(exercises, exercise)
+	}
+	sort.SliceStable(exercises, func(i, j int) bool { return exercises[i].OrderIndex < exercises[j].OrderIndex })
+	return exercises
+}
+
+func paginate[T any](items []T, limit, offset int) []T {
+	if offset >= len(items) {
+		return []T{}
+	}
+	items = items[offset:]
+	if limit > 0 && limit < len(items) {
+		items = items[:limit]
+	}
+	return items
+}
@@ -0,0 +1,473 @@
+package services
+
+import (
+	"chalk-api/pkg/events"
+	"chalk-api/pkg/models"
+	"chalk-api/pkg/repositories"
+	"chalk-api/pkg/units"
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrGoalNotFound        = errors.New("goal not found")
+	ErrGoalTitleRequired   = errors.New("goal title cannot be empty")
+	ErrGoalForbidden       = errors.New("goal does not belong to this user")
+	ErrGoalAlreadyAchieved = errors.New("goal is already achieved")
+	ErrGoalNotActive       = errors.New("goal is not active")
+	ErrInvalidGoalFilter   = errors.New("invalid goal status filter")
+)
+
+const (
+	GoalStatusActive    = "active"
+	GoalStatusAchieved  = "achieved"
+	GoalStatusAbandoned = "abandoned"
+)
+
+// CreateGoalInput creates a client goal. ClientProfileID is only read on the client route, and only
+// needed when the client has more than one active coach.
+type CreateGoalInput struct {
+	ClientProfileID *uint    `json:"client_profile_id"`
+	Title           string   `json:"title" binding:"required,max=200"`
+	MetricType      string   `json:"metric_type" binding:"required,oneof=weight strength habit custom"`
+	TargetValue     *float64 `json:"target_value"`
+	TargetUnit      *string  `json:"target_unit" binding:"omitempty,max=20"`
+	TargetDate      *string  `json:"target_date" binding:"omitempty,date"`
+}
+
+// UpdateGoalInput changes a goal. An empty target_unit or target_date clears it. Setting status to
+// achieved publishes goal.achieved; an achieved goal can't change status again.
+type UpdateGoalInput struct {
+	Title       *string  `json:"title" binding:"omitempty,min=1,max=200"`
+	MetricType  *string  `json:"metric_type" binding:"omitempty,oneof=weight strength habit custom"`
+	TargetValue *float64 `json:"target_value"`
+	TargetUnit  *string  `json:"target_unit" binding:"omitempty,max=20"`
+	TargetDate  *string  `json:"target_date" binding:"omitempty,date"`
+	Status      *string  `json:"status" binding:"omitempty,oneof=active achieved abandoned"`
+}
+
+// GoalProgressInput records one progress value; recorded_on defaults to today (UTC).
+type GoalProgressInput struct {
+	Value      *float64 `json:"value" binding:"required"`
+	RecordedOn *string  `json:"recorded_on" binding:"omitempty,date"`
+	Note       *string  `json:"note" binding:"omitempty,max=500"`
+}
+
+// GoalProgressPage is one page of a goal's progress entries. Unit is only set when the values were
+// converted into the viewer's preferred unit, and names that unit.
+type GoalProgressPage struct {
+	Entries []models.ClientGoalProgress
+	Total   int64
+	Unit    *string
+}
+
+// GoalService manages client goals. Both sides of a coach-client relationship can create, update
+// and record progress on its goals.
+type GoalService struct {
+	repos  *repositories.RepositoriesCollection
+	events *events.Publisher
+}
+
+func NewGoalService(repos *repositories.RepositoriesCollection, eventsPublisher *events.Publisher) *GoalService {
+	return &GoalService{
+		repos:  repos,
+		events: eventsPublisher,
+	}
+}
+
+// CreateMyGoal creates a goal for the calling client.
+func (s *GoalService) CreateMyGoal(ctx context.Context, userID uint, input CreateGoalInput) (*models.ClientGoal, error) {
+	clientProfile, err := resolveMyClientProfile(ctx, s.repos, userID, input.ClientProfileID)
+	if err != nil {
+		return nil, err
+	}
+	return s.createGoal(ctx, userID, clientProfile.ID, input)
+}
+
+// ListMyGoals returns the calling client's goals across all of their coaches, each with its latest progress.
+func (s *GoalService) ListMyGoals(ctx context.Context, userID uint, status string) ([]models.ClientGoal, error) {
+	if err := validateGoalStatusFilter(status); err != nil {
+		return nil, err
+	}
+
+	clientProfiles, err := s.repos.Client.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	clientIDs := make([]uint, 0, len(clientProfiles))
+	for i := range clientProfiles {
+		clientIDs = append(clientIDs, clientProfiles[i].ID)
+	}
+
+	goals, err := s.repos.Progress.ListGoals(ctx, clientIDs, status)
+	if err != nil {
+		return nil, err
+	}
+	if err := attachLatestGoalProgress(ctx, s.repos.Progress, goals); err != nil {
+		return nil, err
+	}
+	return goals, nil
+}
+
+// CreateClientGoal creates a goal for one of the calling coach's clients.
+func (s *GoalService) CreateClientGoal(ctx context.Context, userID, clientProfileID uint, input CreateGoalInput) (*models.ClientGoal, error) {
+	if _, err := getCoachClientProfile(ctx, s.repos, userID, clientProfileID); err != nil {
+		return nil, err
+	}
+	return s.createGoal(ctx, userID, clientProfileID, input)
+}
+
+// ListClientGoals returns one of the calling coach's client's goals, each with its latest progress.
+func (s *GoalService) ListClientGoals(ctx context.Context, userID, clientProfileID uint, status string) ([]models.ClientGoal, error) {
+	if err := validateGoalStatusFilter(status); err != nil {
+		return nil, err
+	}
+	if _, err := getTeamClientProfile(ctx, s.repos, userID, clientProfileID); err != nil {
+		return nil, err
+	}
+
+	goals, err := s.repos.Progress.ListGoals(ctx, []uint{clientProfileID}, status)
+	if err != nil {
+		return nil, err
+	}
+	if err := attachLatestGoalProgress(ctx, s.repos.Progress, goals); err != nil {
+		return nil, err
+	}
+	return goals, nil
+}
+
+// UpdateGoal applies changes from either the client or their coach.
+func (s *GoalService) UpdateGoal(ctx context.Context, userID, goalID uint, input UpdateGoalInput) (*models.ClientGoal, error) {
+	access, err := s.getGoalAccess(ctx, userID, goalID)
+	if err != nil {
+		return nil, err
+	}
+	goal := access.goal
+
+	if input.Title != nil {
+		title := strings.TrimSpace(*input.Title)
+		if title == "" {
+			return nil, ErrGoalTitleRequired
+		}
+		goal.Title = title
+	}
+	if input.MetricType != nil {
+		goal.MetricType = *input.MetricType
+	}
+	if input.TargetValue != nil {
+		goal.TargetValue = input.TargetValue
+	}
+	if input.TargetUnit != nil {
+		goal.TargetUnit = trimToPtr(*input.TargetUnit)
+	}
+	if input.TargetDate != nil {
+		goal.TargetDate = trimToPtr(*input.TargetDate)
+	}
+
+	achieved := false
+	if input.Status != nil && *input.Status != goal.Status {
+		if goal.Status == GoalStatusAchieved {
+			return nil, ErrGoalAlreadyAchieved
+		}
+		goal.Status = *input.Status
+		if goal.Status == GoalStatusAchieved {
+			now := time.Now().UTC()
+			goal.AchievedAt = &now
+			achieved = true
+		}
+	}
+
+	if !achieved {
+		if err := s.repos.Progress.UpdateGoal(ctx, goal); err != nil {
+			return nil, err
+		}
+		return goal, nil
+	}
+
+	err = s.repos.WithTransaction(ctx, func(tx *gorm.DB, txRepos *repositories.RepositoriesCollection) error {
+		if err := txRepos.Progress.UpdateGoal(ctx, goal); err != nil {
+			return err
+		}
+		if s.events == nil {
+			return nil
+		}
+
+		goalID := strconv.FormatUint(uint64(goal.ID), 10)
+		return s.events.PublishInTx(
+			ctx,
+			tx,
+			events.EventTypeGoalAchieved,
+			"goal",
+			goalID,
+			events.BuildIdempotencyKey(events.EventTypeGoalAchieved, goalID),
+			events.GoalAchievedPayload{
+				GoalID:           goal.ID,
+				ClientProfileID:  goal.ClientProfileID,
+				CoachID:          access.coach.ID,
+				CoachUserID:      access.coach.UserID,
+				ClientUserID:     access.client.UserID,
+				AchievedByUserID: userID,
+				ClientName:       strings.TrimSpace(digestClientName(*access.client)),
+				Title:            goal.Title,
+				AchievedAt:       *goal.AchievedAt,
+			},
+		)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return goal, nil
+}
+
+// DeleteGoal removes a goal and its progress. Deleting an achieved goal refreshes the coach's goal stats.
+func (s *GoalService) DeleteGoal(ctx context.Context, userID, goalID uint) error {
+	access, err := s.getGoalAccess(ctx, userID, goalID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repos.Progress.DeleteGoal(ctx, goalID); err != nil {
+		return err
+	}
+	if access.goal.Status == GoalStatusAchieved {
+		return s.repos.Coach.RefreshGoalStats(ctx, access.coach.ID)
+	}
+	return nil
+}
+
+// AddGoalProgress records a progress value on an active goal.
+func (s *GoalService) AddGoalProgress(ctx context.Context, userID, goalID uint, input GoalProgressInput) (*models.ClientGoalProgress, error) {
+	access, err := s.getGoalAccess(ctx, userID, goalID)
+	if err != nil {
+		return nil, err
+	}
+	if access.goal.Status != GoalStatusActive {
+		return nil, ErrGoalNotActive
+	}
+
+	recordedOn := time.Now().UTC().Format("2006-01-02")
+	if input.RecordedOn != nil && *input.RecordedOn != "" {
+		recordedOn = *input.RecordedOn
+	}
+
+	entry := &models.ClientGoalProgress{
+		GoalID:           goalID,
+		Value:            *input.Value,
+		RecordedOn:       recordedOn,
+		Note:             input.Note,
+		RecordedByUserID: userID,
+	}
+	if err := s.repos.Progress.CreateGoalProgress(ctx, entry); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// ListGoalProgress returns a goal's progress entries, most recent first.
+// ListGoalProgress pages through a goal's progress, newest first. With preferredUnits, values of
+// goals tracked in a weight or distance unit are converted into the viewer's preferred unit.
+func (s *GoalService) ListGoalProgress(ctx context.Context, userID, goalID uint, limit, offset int, preferredUnits bool) (*GoalProgressPage, error) {
+	access, err := s.getGoalAccess(ctx, userID, goalID)
+	if err != nil {
+		return nil, err
+	}
+
+	if limit <= 0 {
+		limit = 20
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	entries, total, err := s.repos.Progress.ListGoalProgress(ctx, goalID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	page := &GoalProgressPage{Entries: entries, Total: total}
+	if !preferredUnits || access.goal.TargetUnit == nil {
+		return page, nil
+	}
+
+	prefs, err := s.repos.User.GetUnitPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	from := *access.goal.TargetUnit
+	to := prefs.Weight
+	convert := units.ConvertWeight
+	if _, isWeight := units.NormalizeWeight(from); !isWeight {
+		if _, isDistance := units.NormalizeDistance(from); !isDistance {
+			return page, nil
+		}
+		to, convert = prefs.Distance, units.ConvertDistance
+	}
+	for i := range page.Entries {
+		page.Entries[i].Value, _ = convert(page.Entries[i].Value, from, to)
+	}
+	page.Unit = &to
+	return page, nil
+}
+
+func (s *GoalService) createGoal(ctx context.Context, userID, clientProfileID uint, input CreateGoalInput) (*models.ClientGoal, error) {
+	title := strings.TrimSpace(input.Title)
+	if title == "" {
+		return nil, ErrGoalTitleRequired
+	}
+
+	goal := &models.ClientGoal{
+		ClientProfileID: clientProfileID,
+		Title:           title,
+		MetricType:      input.MetricType,
+		TargetValue:     input.TargetValue,
+		TargetUnit:      trimPtr(input.TargetUnit),
+		TargetDate:      trimPtr(input.TargetDate),
+		Status:          GoalStatusActive,
+		CreatedByUserID: userID,
+	}
+
+	if err := s.repos.Progress.CreateGoal(ctx, goal); err != nil {
+		return nil, err
+	}
+	return goal, nil
+}
+
+// goalAccess is a goal plus the relationship it belongs to, loaded by getGoalAccess.
+type goalAccess struct {
+	goal   *models.ClientGoal
+	client *models.ClientProfile
+	coach  *models.CoachProfile
+}
+
+// getGoalAccess loads a goal the caller may act on: they must be the client or the client's coach.
+func (s *GoalService) getGoalAccess(ctx context.Context, userID, goalID uint) (*goalAccess, error) {
+	goal, err := s.repos.Progress.GetGoal(ctx, goalID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrGoalNotFound
+		}
+		return nil, err
+	}
+
+	clientProfile, err := s.repos.Client.GetByID(ctx, goal.ClientProfileID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrGoalForbidden
+		}
+		return nil, err
+	}
+	coachProfile, err := s.repos.Coach.GetByID(ctx, clientProfile.CoachID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrGoalForbidden
+		}
+		return nil, err
+	}
+
+	if userID != clientProfile.UserID && userID != coachProfile.UserID {
+		return nil, ErrGoalForbidden
+	}
+
+	return &goalAccess{goal: goal, client: clientProfile, coach: coachProfile}, nil
+}
+
+// resolveMyClientProfile picks the client profile a client-side request applies to: the requested
+// one, or the client's only active profile.
+func resolveMyClientProfile(ctx context.Context, repos *repositories.RepositoriesCollection, userID uint, clientProfileID *uint) (*models.ClientProfile, error) {
+	if clientProfileID != nil {
+		clientProfile, err := repos.Client.GetByID(ctx, *clientProfileID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrClientProfileInvalid
+			}
+			return nil, err
+		}
+		if clientProfile.UserID != userID {
+			return nil, ErrClientProfileInvalid
+		}
+		return clientProfile, nil
+	}
+
+	clientProfiles, err := repos.Client.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var active []models.ClientProfile
+	for i := range clientProfiles {
+		if clientProfiles[i].Status == "active" {
+			active = append(active, clientProfiles[i])
+		}
+	}
+	switch len(active) {
+	case 0:
+		return nil, ErrClientProfileNotFound
+	case 1:
+		return &active[0], nil
+	default:
+		return nil, ErrClientProfileRequired
+	}
+}
+
+// getCoachClientProfile loads a client profile and checks it belongs to the calling coach.
+func getCoachClientProfile(ctx context.Context, repos *repositories.RepositoriesCollection, userID, clientProfileID uint) (*models.ClientProfile, error) {
+	coachProfile, err := repos.Coach.GetByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCoachProfileNotFound
+		}
+		return nil, err
+	}
+
+	clientProfile, err := repos.Client.GetByID(ctx, clientProfileID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrClientProfileNotFound
+		}
+		return nil, err
+	}
+	if clientProfile.CoachID != coachProfile.ID {
+		return nil, ErrClientProfileForbidden
+	}
+	return clientProfile, nil
+}
+
+// attachLatestGoalProgress fills LatestProgress on each goal that has at least one entry.
+func attachLatestGoalProgress(ctx context.Context, progressRepo *repositories.ProgressRepository, goals []models.ClientGoal) error {
+	if len(goals) == 0 {
+		return nil
+	}
+
+	goalIDs := make([]uint, 0, len(goals))
+	for i := range goals {
+		goalIDs = append(goalIDs, goals[i].ID)
+	}
+
+	latest, err := progressRepo.LatestGoalProgress(ctx, goalIDs)
+	if err != nil {
+		return err
+	}
+	for i := range goals {
+		if entry, ok := latest[goals[i].ID]; ok {
+			goals[i].LatestProgress = &entry
+		}
+	}
+	return nil
+}
+
+func validateGoalStatusFilter(status string) error {
+	switch status {
+	case "", GoalStatusActive, GoalStatusAchieved, GoalStatusAbandoned:
+		return nil
+	default:
+		return ErrInvalidGoalFilter
+	}
+}
@@ -5,35 +5,103 @@ import (
 	"chalk-api/pkg/events"
 	"chalk-api/pkg/external"
 	"chalk-api/pkg/repositories"
+	"chalk-api/pkg/stores"
+	"errors"
 )
 
 // InitializeServices initializes all services
 func InitializeServices(
 	repos *repositories.RepositoriesCollection,
 	integrations *external.Collection,
+	cacheStores *stores.StoresCollection,
 	cfg config.Environment,
 ) (*ServicesCollection, error) {
-	eventsPublisher := events.NewPublisher(repos.Outbox)
+	eventsPublisher, err := events.NewPublisher(repos.Outbox)
+	if err != nil {
+		return nil, err
+	}
+
+	tokenKeys, err := NewTokenKeys(TokenKeyConfig{
+		HMACSecret:            cfg.JWTSecret,
+		PrivateKeyPEM:         cfg.JWTPrivateKey,
+		PreviousPublicKeysPEM: cfg.JWTPreviousPublicKeys,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Bulk exercise edit confirmations are signed with a key derived from the JWT signing key, so a
+	// dry run's token confirms on any instance. Without key material bulk edits are unavailable,
+	// as is sign-in.
+	bulkEditKey, err := tokenKeys.DeriveKey("bulk exercise edit confirmation")
+	if err != nil && !errors.Is(err, ErrTokenKeysNotConfigured) {
+		return nil, err
+	}
 
 	if integrations == nil {
 		integrations = &external.Collection{}
 	}
+	if cacheStores == nil {
+		// Stores tolerate a nil Redis client and behave as pass-through caches.
+		cacheStores = &stores.StoresCollection{
+			Coach:        stores.NewCoachStore(nil),
+			Availability: stores.NewAvailabilityStore(nil),
+			Subscription: stores.NewSubscriptionStore(nil),
+			Exercise:     stores.NewExerciseStore(nil),
+			Flag:         stores.NewFlagStore(nil),
+			Security:     stores.NewSecurityStore(nil),
+			RateLimiter:  stores.NewRateLimiter(nil),
+		}
+	}
+	tokenLifetimes := TokenLifetimesFromConfig(cfg)
+	tokenRevocations := NewTokenRevocations(repos.Auth, cacheStores.Security, tokenLifetimes.Access)
+	coachService := NewCoachService(repos, eventsPublisher, integrations.Storage, cacheStores.Coach, cfg.ReferralCreditCents)
+	subscriptionService := NewSubscriptionService(repos, integrations.RevenueCat, eventsPublisher, cacheStores.Subscription)
+	userService := NewUserService(repos, eventsPublisher, integrations.Storage)
+	messageService := NewMessageService(repos, eventsPublisher, cacheStores.Security)
+	notificationService := NewNotificationService(repos, integrations.Expo)
+	flagService := NewFlagService(repos, cacheStores.Flag)
 
 	return &ServicesCollection{
-		Events:       eventsPublisher,
-		Auth:         NewAuthService(repos.User, repos.Auth, cfg.JWTSecret, cfg.JWTExpirationHours),
-		User:         NewUserService(repos.User, repos.Coach, repos.Client),
-		Coach:        NewCoachService(repos, eventsPublisher),
-		Session:      NewSessionService(repos, eventsPublisher),
-		Workout:      NewWorkoutService(repos, eventsPublisher),
-		Message:      NewMessageService(repos, eventsPublisher),
-		Subscription: NewSubscriptionService(repos, integrations.RevenueCat),
+		Events:          eventsPublisher,
+		TokenKeys:       tokenKeys,
+		Revocations:     tokenRevocations,
+		RateLimiter:     cacheStores.RateLimiter,
+		Auth:            NewAuthService(repos.User, repos.Auth, tokenKeys, tokenRevocations, tokenLifetimes, coachService, integrations.GeoIP, eventsPublisher, cfg.RefreshTokenMaxDeviceless),
+		User:            userService,
+		Coach:           coachService,
+		Session:         NewSessionService(repos, repos.Coach, repos.Client, repos.User, repos.Session, eventsPublisher, cacheStores.Coach, cacheStores.Availability, cfg.SessionCheckInRadiusMeters, coachService),
+		Workout:         NewWorkoutService(repos, repos.Template, repos.Workout, repos.Exercise, repos.Coach, repos.Client, repos.User, eventsPublisher, integrations.Storage, cacheStores.Exercise, coachService),
+		Message:         messageService,
+		Subscription:    subscriptionService,
+		Report:          NewReportService(repos, cacheStores.Coach, eventsPublisher, integrations.Storage),
+		Notification:    notificationService,
+		Digest:          NewDigestService(repos, eventsPublisher),
+		WorkoutReminder: NewWorkoutReminderService(repos, eventsPublisher),
+		ClientActivity:  NewClientActivityService(repos, eventsPublisher),
+		Admin:           NewAdminService(repos, eventsPublisher, cacheStores.Coach, cacheStores.Availability, cacheStores.Inspector, subscriptionService, cacheStores.Exercise, bulkEditKey),
+		Calendar:        NewCalendarService(repos),
+		Goal:            NewGoalService(repos, eventsPublisher),
+		Streak:          NewStreakService(repos),
+		Activity:        NewActivityService(repos),
+		Intake:          NewIntakeService(repos),
+		Flag:            flagService,
+		Bootstrap:       NewBootstrapService(userService, subscriptionService, flagService, messageService, notificationService, cfg.MinAppVersion, cfg.LatestAppVersion),
+		RequestAnalytics: NewRequestAnalytics(repos, RequestAnalyticsConfig{
+			Enabled:    cfg.RequestAnalyticsEnabled,
+			SampleRate: cfg.RequestAnalyticsSampleRate,
+			BufferSize: cfg.RequestAnalyticsBufferSize,
+		}),
+		CacheInspector: cacheStores.Inspector,
 	}, nil
 }
 
 // ServicesCollection contains all the services
 type ServicesCollection struct {
 	Events       *events.Publisher
+	TokenKeys    *TokenKeys
+	Revocations  *TokenRevocations
+	RateLimiter  *stores.RateLimiter
 	Auth         *AuthService
 	User         *UserService
 	Coach        *CoachService
@@ -41,4 +109,23 @@ type ServicesCollection struct {
 	Workout      *WorkoutService
 	Message      *MessageService
 	Subscription *SubscriptionService
+	Report       *ReportService
+	Notification *NotificationService
+	Digest       *DigestService
+	// WorkoutReminder sends scheduled-date workout reminders; driven by the workout reminder worker
+	WorkoutReminder *WorkoutReminderService
+	ClientActivity  *ClientActivityService
+	Admin           *AdminService
+	Calendar        *CalendarService
+	Goal            *GoalService
+	Streak          *StreakService
+	Activity        *ActivityService
+	Intake          *IntakeService
+	Flag            *FlagService
+	// Bootstrap assembles the mobile app's startup payload from the user, flag, subscription and inbox services
+	Bootstrap *BootstrapService
+	// RequestAnalytics is the opt-in per-user request sink; flushed by the request analytics worker
+	RequestAnalytics *RequestAnalytics
+	// CacheInspector reports Redis usage per keyspace for the admin and metrics endpoints
+	CacheInspector *stores.CacheInspector
 }
@@ -0,0 +1,523 @@
+package services
+
+import (
+	"chalk-api/pkg/models"
+	"chalk-api/pkg/repositories"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrIntakeQuestionNotFound  = errors.New("intake question not found")
+	ErrIntakeQuestionForbidden = errors.New("intake question does not belong to this coach")
+	ErrIntakeQuestionInvalid   = errors.New("invalid intake question")
+	ErrIntakeQuestionLimit     = errors.New("intake question limit reached")
+	ErrInvalidIntakeAnswer     = errors.New("invalid intake answer")
+)
+
+// Custom intake question types
+const (
+	IntakeQuestionText    = "text"
+	IntakeQuestionNumber  = "number"
+	IntakeQuestionBoolean = "boolean"
+	IntakeQuestionSelect  = "select"
+)
+
+const (
+	// maxIntakeQuestionsPerCoach keeps the intake form short enough for clients to finish.
+	maxIntakeQuestionsPerCoach = 50
+	minIntakeSelectOptions     = 2
+	maxIntakeTextAnswerLength  = 2000
+)
+
+// IntakeAnswerError is ErrInvalidIntakeAnswer with the offending question, so the app can highlight it.
+type IntakeAnswerError struct {
+	QuestionID string
+	Reason     string
+}
+
+func (e *IntakeAnswerError) Error() string {
+	return fmt.Sprintf("%s: question %s %s", ErrInvalidIntakeAnswer, e.QuestionID, e.Reason)
+}
+
+func (e *IntakeAnswerError) Is(target error) bool {
+	return target == ErrInvalidIntakeAnswer
+}
+
+// ErrorDetails is merged into the API error response.
+func (e *IntakeAnswerError) ErrorDetails() map[string]any {
+	return map[string]any{"question_id": e.QuestionID, "reason": e.Reason}
+}
+
+type CreateIntakeQuestionInput struct {
+	Label    string   `json:"label" binding:"required,max=300"`
+	Type     string   `json:"type" binding:"required,oneof=text number boolean select"`
+	Options  []string `json:"options" binding:"omitempty,max=20,dive,max=100"`
+	Required bool     `json:"required"`
+	// Defaults to after the coach's existing questions
+	DisplayOrder *int `json:"display_order" binding:"omitempty,min=0,max=1000"`
+}
+
+// UpdateIntakeQuestionInput changes a question. Answers already given are kept as they are, even if
+// the new definition would reject them; they are only checked again when the client resubmits.
+type UpdateIntakeQuestionInput struct {
+	Label        *string   `json:"label" binding:"omitempty,max=300"`
+	Type         *string   `json:"type" binding:"omitempty,oneof=text number boolean select"`
+	Options      *[]string `json:"options" binding:"omitempty,max=20,dive,max=100"`
+	Required     *bool     `json:"required"`
+	DisplayOrder *int      `json:"display_order" binding:"omitempty,min=0,max=1000"`
+}
+
+// SubmitIntakeFormInput is the client's full intake form. Submitting again replaces the previous
+// answers. ClientProfileID is only needed when the client has more than one active coach.
+type SubmitIntakeFormInput struct {
+	ClientProfileID *uint `json:"client_profile_id"`
+
+	FitnessLevel       string  `json:"fitness_level" binding:"required,oneof=beginner intermediate advanced"`
+	YearsTraining      *int    `json:"years_training" binding:"omitempty,min=0,max=80"`
+	PreviousExperience *string `json:"previous_experience" binding:"omitempty,max=2000"`
+
+	PrimaryGoal     string  `json:"primary_goal" binding:"required,oneof=weight_loss muscle_gain strength athletic_performance general_fitness"`
+	SpecificGoals   *string `json:"specific_goals" binding:"omitempty,max=2000"`
+	MotivationLevel *int    `json:"motivation_level" binding:"omitempty,min=1,max=10"`
+	WhyHireCoach    *string `json:"why_hire_coach" binding:"omitempty,max=2000"`
+
+	Injuries         *string `json:"injuries" binding:"omitempty,max=2000"`
+	HealthConditions *string `json:"health_conditions" binding:"omitempty,max=2000"`
+	Medications      *string `json:"medications" binding:"omitempty,max=2000"`
+	DoctorClearance  bool    `json:"doctor_clearance"`
+
+	AvailableDays      []string `json:"available_days" binding:"omitempty,max=7,dive,oneof=Monday Tuesday Wednesday Thursday Friday Saturday Sunday"`
+	PreferredTimeOfDay string   `json:"preferred_time_of_day" binding:"omitempty,oneof=morning afternoon evening flexible"`
+	SessionDuration    *int     `json:"session_duration" binding:"omitempty,min=15,max=240"`
+
+	TrainingLocation   string  `json:"training_location" binding:"omitempty,oneof=gym home outdoor flexible"`
+	EquipmentAvailable *string `json:"equipment_available" binding:"omitempty,max=2000"`
+	GymMembership      *string `json:"gym_membership" binding:"omitempty,max=200"`
+
+	OccupationType     *string `json:"occupation_type" binding:"omitempty,oneof=sedentary active very_active"`
+	SleepHours         *int    `json:"sleep_hours" binding:"omitempty,min=0,max=24"`
+	StressLevel        *int    `json:"stress_level" binding:"omitempty,min=1,max=10"`
+	DietaryPreferences *string `json:"dietary_preferences" binding:"omitempty,max=2000"`
+
+	AdditionalInfo *string `json:"additional_info" binding:"omitempty,max=5000"`
+
+	// Answers to the coach's custom questions, keyed by question ID
+	CustomAnswers map[string]any `json:"custom_answers"`
+
+	// Version of the submitted form being replaced (or the If-Match header); ignored on first submission
+	Version *int `json:"version"`
+}
+
+// IntakeFormView is what the client sees: their submitted form (nil until submitted) and the
+// coach's current custom questions.
+type IntakeFormView struct {
+	ClientProfileID uint                          `json:"client_profile_id"`
+	IntakeForm      *models.ClientIntakeForm      `json:"intake_form"`
+	CustomQuestions []models.CustomIntakeQuestion `json:"custom_questions"`
+}
+
+// CustomIntakeAnswer pairs one of the coach's questions with the client's answer for the client
+// detail screen. Questions added after the client submitted are listed with Answered false.
+type CustomIntakeAnswer struct {
+	QuestionID uint     `json:"question_id"`
+	Label      string   `json:"label"`
+	Type       string   `json:"type"`
+	Options    []string `json:"options"`
+	Required   bool     `json:"required"`
+	Answered   bool     `json:"answered"`
+	Answer     any      `json:"answer"`
+}
+
+// IntakeService manages coaches' custom intake questions and clients' intake form submissions.
+type IntakeService struct {
+	repos *repositories.RepositoriesCollection
+}
+
+func NewIntakeService(repos *repositories.RepositoriesCollection) *IntakeService {
+	return &IntakeService{repos: repos}
+}
+
+func (s *IntakeService) CreateMyQuestion(ctx context.Context, userID uint, input CreateIntakeQuestionInput) (*models.CustomIntakeQuestion, error) {
+	coach, err := s.getCoachProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	label := strings.TrimSpace(input.Label)
+	if label == "" {
+		return nil, ErrIntakeQuestionInvalid
+	}
+	options, err := normalizeIntakeOptions(input.Type, input.Options)
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := s.repos.Client.CountIntakeQuestions(ctx, coach.ID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= maxIntakeQuestionsPerCoach {
+		return nil, ErrIntakeQuestionLimit
+	}
+
+	displayOrder := int(count)
+	if input.DisplayOrder != nil {
+		displayOrder = *input.DisplayOrder
+	}
+
+	question := &models.CustomIntakeQuestion{
+		CoachID:      coach.ID,
+		Label:        label,
+		Type:         input.Type,
+		Options:      options,
+		Required:     input.Required,
+		DisplayOrder: displayOrder,
+	}
+	if err := s.repos.Client.CreateIntakeQuestion(ctx, question); err != nil {
+		return nil, err
+	}
+	return question, nil
+}
+
+// ListMyQuestions returns the calling coach's custom intake questions in display order.
+func (s *IntakeService) ListMyQuestions(ctx context.Context, userID uint) ([]models.CustomIntakeQuestion, error) {
+	coach, err := s.getCoachProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.repos.Client.ListIntakeQuestions(ctx, coach.ID)
+}
+
+func (s *IntakeService) UpdateMyQuestion(ctx context.Context, userID, questionID uint, input UpdateIntakeQuestionInput) (*models.CustomIntakeQuestion, error) {
+	question, err := s.getOwnedQuestion(ctx, userID, questionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Label != nil {
+		label := strings.TrimSpace(*input.Label)
+		if label == "" {
+			return nil, ErrIntakeQuestionInvalid
+		}
+		question.Label = label
+	}
+	if input.Type != nil {
+		question.Type = *input.Type
+	}
+	options := question.Options
+	if input.Options != nil {
+		options = *input.Options
+	} else if question.Type != IntakeQuestionSelect {
+		// Switching away from select drops the old choices
+		options = nil
+	}
+	question.Options, err = normalizeIntakeOptions(question.Type, options)
+	if err != nil {
+		return nil, err
+	}
+	if input.Required != nil {
+		question.Required = *input.Required
+	}
+	if input.DisplayOrder != nil {
+		question.DisplayOrder = *input.DisplayOrder
+	}
+
+	if err := s.repos.Client.UpdateIntakeQuestion(ctx, question); err != nil {
+		return nil, err
+	}
+	return question, nil
+}
+
+// DeleteMyQuestion removes a question. Answers already given to it stay on the stored forms but are
+// no longer shown.
+func (s *IntakeService) DeleteMyQuestion(ctx context.Context, userID, questionID uint) error {
+	if _, err := s.getOwnedQuestion(ctx, userID, questionID); err != nil {
+		return err
+	}
+	return s.repos.Client.DeleteIntakeQuestion(ctx, questionID)
+}
+
+// GetMyIntakeForm returns the calling client's intake form with their coach's custom questions.
+func (s *IntakeService) GetMyIntakeForm(ctx context.Context, userID uint, clientProfileID *uint) (*IntakeFormView, error) {
+	clientProfile, err := resolveMyClientProfile(ctx, s.repos, userID, clientProfileID)
+	if err != nil {
+		return nil, err
+	}
+
+	form, err := s.getIntakeForm(ctx, clientProfile.ID)
+	if err != nil {
+		return nil, err
+	}
+	questions, err := s.repos.Client.ListIntakeQuestions(ctx, clientProfile.CoachID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &IntakeFormView{ClientProfileID: clientProfile.ID, IntakeForm: form, CustomQuestions: questions}, nil
+}
+
+// SubmitMyIntakeForm saves the calling client's intake form, replacing any earlier submission.
+// Custom answers are checked against the coach's current questions.
+func (s *IntakeService) SubmitMyIntakeForm(ctx context.Context, userID uint, input SubmitIntakeFormInput) (*IntakeFormView, error) {
+	clientProfile, err := resolveMyClientProfile(ctx, s.repos, userID, input.ClientProfileID)
+	if err != nil {
+		return nil, err
+	}
+
+	questions, err := s.repos.Client.ListIntakeQuestions(ctx, clientProfile.CoachID)
+	if err != nil {
+		return nil, err
+	}
+	customAnswers, err := validateCustomIntakeAnswers(questions, input.CustomAnswers)
+	if err != nil {
+		return nil, err
+	}
+
+	form, err := s.getIntakeForm(ctx, clientProfile.ID)
+	if err != nil {
+		return nil, err
+	}
+	isNew := form == nil
+	if isNew {
+		form = &models.ClientIntakeForm{ClientID: clientProfile.ID}
+	} else if err := checkVersion(input.Version, form.Version); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	form.FitnessLevel = input.FitnessLevel
+	form.YearsTraining = input.YearsTraining
+	form.PreviousExperience = trimPtr(input.PreviousExperience)
+	form.PrimaryGoal = input.PrimaryGoal
+	form.SpecificGoals = trimPtr(input.SpecificGoals)
+	form.MotivationLevel = input.MotivationLevel
+	form.WhyHireCoach = trimPtr(input.WhyHireCoach)
+	form.Injuries = trimPtr(input.Injuries)
+	form.HealthConditions = trimPtr(input.HealthConditions)
+	form.Medications = trimPtr(input.Medications)
+	form.DoctorClearance = input.DoctorClearance
+	form.AvailableDays = input.AvailableDays
+	form.PreferredTimeOfDay = input.PreferredTimeOfDay
+	form.SessionDuration = input.SessionDuration
+	form.TrainingLocation = input.TrainingLocation
+	form.EquipmentAvailable = trimPtr(input.EquipmentAvailable)
+	form.GymMembership = trimPtr(input.GymMembership)
+	form.OccupationType = input.OccupationType
+	form.SleepHours = input.SleepHours
+	form.StressLevel = input.StressLevel
+	form.DietaryPreferences = trimPtr(input.DietaryPreferences)
+	form.AdditionalInfo = trimPtr(input.AdditionalInfo)
+	form.CustomAnswers = customAnswers
+	form.CompletedAt = &now
+
+	if isNew {
+		err = s.repos.Client.CreateIntakeForm(ctx, form)
+	} else {
+		err = s.repos.Client.UpdateIntakeForm(ctx, form)
+	}
+	if err != nil {
+		if errors.Is(err, repositories.ErrVersionConflict) {
+			current, getErr := s.repos.Client.GetIntakeForm(ctx, clientProfile.ID)
+			if getErr != nil {
+				return nil, getErr
+			}
+			return nil, &VersionConflictError{CurrentVersion: current.Version}
+		}
+		return nil, err
+	}
+
+	return &IntakeFormView{ClientProfileID: clientProfile.ID, IntakeForm: form, CustomQuestions: questions}, nil
+}
+
+func (s *IntakeService) getIntakeForm(ctx context.Context, clientProfileID uint) (*models.ClientIntakeForm, error) {
+	form, err := s.repos.Client.GetIntakeForm(ctx, clientProfileID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return form, nil
+}
+
+func (s *IntakeService) getOwnedQuestion(ctx context.Context, userID, questionID uint) (*models.CustomIntakeQuestion, error) {
+	coach, err := s.getCoachProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	question, err := s.repos.Client.GetIntakeQuestionByID(ctx, questionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrIntakeQuestionNotFound
+		}
+		return nil, err
+	}
+	if question.CoachID != coach.ID {
+		return nil, ErrIntakeQuestionForbidden
+	}
+	return question, nil
+}
+
+func (s *IntakeService) getCoachProfile(ctx context.Context, userID uint) (*models.CoachProfile, error) {
+	coach, err := s.repos.Coach.GetByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCoachProfileNotFound
+		}
+		return nil, err
+	}
+	return coach, nil
+}
+
+// normalizeIntakeOptions trims select options and rejects blanks and duplicates. Only select
+// questions take options, and they need at least two.
+func normalizeIntakeOptions(questionType string, options []string) ([]string, error) {
+	if questionType != IntakeQuestionSelect {
+		if len(options) > 0 {
+			return nil, ErrIntakeQuestionInvalid
+		}
+		return nil, nil
+	}
+
+	normalized := make([]string, 0, len(options))
+	seen := make(map[string]struct{}, len(options))
+	for _, option := range options {
+		option = strings.TrimSpace(option)
+		if option == "" {
+			return nil, ErrIntakeQuestionInvalid
+		}
+		key := strings.ToLower(option)
+		if _, duplicate := seen[key]; duplicate {
+			return nil, ErrIntakeQuestionInvalid
+		}
+		seen[key] = struct{}{}
+		normalized = append(normalized, option)
+	}
+	if len(normalized) < minIntakeSelectOptions {
+		return nil, ErrIntakeQuestionInvalid
+	}
+	return normalized, nil
+}
+
+// validateCustomIntakeAnswers checks answers against the coach's questions and returns the ones to
+// store. Null and blank answers count as unanswered; every required question needs an answer.
+func validateCustomIntakeAnswers(questions []models.CustomIntakeQuestion, answers map[string]any) (map[string]any, error) {
+	byID := make(map[string]*models.CustomIntakeQuestion, len(questions))
+	for i := range questions {
+		byID[strconv.FormatUint(uint64(questions[i].ID), 10)] = &questions[i]
+	}
+
+	keys := make([]string, 0, len(answers))
+	for key := range answers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if _, ok := byID[key]; !ok {
+			return nil, &IntakeAnswerError{QuestionID: key, Reason: "is not one of the coach's questions"}
+		}
+	}
+
+	normalized := make(map[string]any, len(answers))
+	for i := range questions {
+		question := &questions[i]
+		key := strconv.FormatUint(uint64(question.ID), 10)
+
+		value, err := normalizeIntakeAnswer(question, answers[key])
+		if err != nil {
+			return nil, &IntakeAnswerError{QuestionID: key, Reason: err.Error()}
+		}
+		if value == nil {
+			if question.Required {
+				return nil, &IntakeAnswerError{QuestionID: key, Reason: "is required"}
+			}
+			continue
+		}
+		normalized[key] = value
+	}
+	return normalized, nil
+}
+
+func normalizeIntakeAnswer(question *models.CustomIntakeQuestion, value any) (any, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	switch question.Type {
+	case IntakeQuestionText:
+		text, ok := value.(string)
+		if !ok {
+			return nil, errors.New("must be a string")
+		}
+		text = strings.TrimSpace(text)
+		if text == "" {
+			return nil, nil
+		}
+		if utf8.RuneCountInString(text) > maxIntakeTextAnswerLength {
+			return nil, fmt.Errorf("must be at most %d characters", maxIntakeTextAnswerLength)
+		}
+		return text, nil
+	case IntakeQuestionNumber:
+		number, ok := value.(float64)
+		if !ok {
+			return nil, errors.New("must be a number")
+		}
+		return number, nil
+	case IntakeQuestionBoolean:
+		answer, ok := value.(bool)
+		if !ok {
+			return nil, errors.New("must be true or false")
+		}
+		return answer, nil
+	case IntakeQuestionSelect:
+		choice, ok := value.(string)
+		if !ok {
+			return nil, errors.New("must be one of the options")
+		}
+		choice = strings.TrimSpace(choice)
+		if choice == "" {
+			return nil, nil
+		}
+		for _, option := range question.Options {
+			if option == choice {
+				return choice, nil
+			}
+		}
+		return nil, errors.New("must be one of the options")
+	default:
+		return nil, errors.New("has an unknown question type")
+	}
+}
+
+// buildCustomIntakeAnswers lists every current question with the client's answer, if any.
+func buildCustomIntakeAnswers(questions []models.CustomIntakeQuestion, form *models.ClientIntakeForm) []CustomIntakeAnswer {
+	answers := make([]CustomIntakeAnswer, 0, len(questions))
+	for i := range questions {
+		question := questions[i]
+		entry := CustomIntakeAnswer{
+			QuestionID: question.ID,
+			Label:      question.Label,
+			Type:       question.Type,
+			Options:    question.Options,
+			Required:   question.Required,
+		}
+		if form != nil {
+			if value, ok := form.CustomAnswers[strconv.FormatUint(uint64(question.ID), 10)]; ok && value != nil {
+				entry.Answered = true
+				entry.Answer = value
+			}
+		}
+		answers = append(answers, entry)
+	}
+	return answers
+}
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"chalk-api/pkg/events"
+	"chalk-api/pkg/repositories"
+	"context"
+	"log/slog"
+	"strconv"
+	"time"
+)
+
+// Messages deleted per statement by the retention task; each batch is its own short statement so a
+// coach with years of history doesn't hold locks on the messages table for long
+const messageRetentionBatchSize = 1000
+
+// ApplyMessageRetention deletes messages past each coach's messages_retention_days from that coach's
+// conversations, in batches, along with the copies kept on finished scheduled messages.
+// Conversations of coaches without a policy are never touched. Unread counts are computed from the
+// remaining rows, so they drop with the deleted messages; last_message_at is reset so the inbox
+// sorts on what is left. Each coach with deletions gets a message.retention_applied audit event.
+// A run cut short by ctx resumes on the next one. It returns the number of messages deleted.
+func (s *MessageService) ApplyMessageRetention(ctx context.Context, now time.Time) (int64, error) {
+	coaches, err := s.messageRepo.ListRetentionCoaches(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var total int64
+	for _, coach := range coaches {
+		deleted, err := s.applyCoachMessageRetention(ctx, coach, now)
+		total += deleted
+		if err != nil {
+			if ctx.Err() != nil {
+				return total, err
+			}
+			slog.Error("Message retention failed", "coach_id", coach.CoachID, "error", err)
+		}
+	}
+	return total, nil
+}
+
+func (s *MessageService) applyCoachMessageRetention(ctx context.Context, coach repositories.RetentionCoach, now time.Time) (int64, error) {
+	cutoff := now.AddDate(0, 0, -coach.MessagesRetentionDays)
+
+	var deleted int64
+	complete := false
+	var runErr error
+	for {
+		batch, err := s.messageRepo.DeleteCoachMessagesBefore(ctx, coach.CoachID, cutoff, messageRetentionBatchSize)
+		if err != nil {
+			runErr = err
+			break
+		}
+		deleted += batch
+		if batch > 0 {
+			slog.Info("Message retention batch", "coach_id", coach.CoachID, "deleted", batch, "deleted_so_far", deleted)
+		}
+		if batch < messageRetentionBatchSize {
+			complete = true
+			break
+		}
+	}
+
+	var scheduledDeleted int64
+	if runErr == nil {
+		scheduledDeleted, runErr = s.messageRepo.DeleteCoachScheduledMessagesBefore(ctx, coach.CoachID, cutoff)
+	}
+	if deleted == 0 && scheduledDeleted == 0 {
+		return 0, runErr
+	}
+
+	// Record what was deleted even when the run stopped early; ctx may be done, so use a fresh one
+	recordCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+	defer cancel()
+	if err := s.messageRepo.ResetCoachConversationLastMessage(recordCtx, coach.CoachID, cutoff); err != nil {
+		slog.Warn("Failed to reset conversation last_message_at after retention", "coach_id", coach.CoachID, "error", err)
+	}
+	if s.events != nil {
+		id := strconv.FormatUint(uint64(coach.CoachID), 10)
+		if err := s.events.Publish(
+			recordCtx,
+			events.EventTypeMessageRetention,
+			"coach",
+			id,
+			events.BuildIdempotencyKey(events.EventTypeMessageRetention, id, strconv.FormatInt(now.UnixNano(), 10)),
+			events.MessageRetentionAppliedPayload{
+				CoachID:                  coach.CoachID,
+				RetentionDays:            coach.MessagesRetentionDays,
+				Cutoff:                   cutoff,
+				MessagesDeleted:          deleted,
+				ScheduledMessagesDeleted: scheduledDeleted,
+				Complete:                 complete && runErr == nil,
+				RanAt:                    now,
+			},
+		); err != nil {
+			slog.Error("Failed to record message retention run", "coach_id", coach.CoachID, "error", err)
+		}
+	}
+	slog.Info("Message retention applied",
+		"coach_id", coach.CoachID,
+		"retention_days", coach.MessagesRetentionDays,
+		"messages_deleted", deleted,
+		"scheduled_messages_deleted", scheduledDeleted,
+	)
+	return deleted, runErr
+}
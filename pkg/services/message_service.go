@@ -4,10 +4,13 @@ import (
 	"chalk-api/pkg/events"
 	"chalk-api/pkg/models"
 	"chalk-api/pkg/repositories"
+	"chalk-api/pkg/stores"
+	"chalk-api/pkg/utils"
 	"context"
 	"errors"
 	"strconv"
 	"strings"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -15,11 +18,39 @@ import (
 var (
 	ErrConversationNotFound   = errors.New("conversation not found")
 	ErrConversationForbidden  = errors.New("conversation does not belong to this user")
+	ErrConversationClosed     = errors.New("conversation is closed")
 	ErrMessageContentRequired = errors.New("message content or media is required")
+	ErrMessageNotFound        = errors.New("message not found")
 	ErrClientProfileRequired  = errors.New("client profile id is required")
 	ErrClientProfileInvalid   = errors.New("client profile does not belong to this user")
+	ErrSavedReplyInvalid      = errors.New("saved reply title and body are required")
+	ErrSavedReplyNotFound     = errors.New("saved reply not found")
+	ErrSavedReplyForbidden    = errors.New("saved reply does not belong to this coach")
+	ErrSavedReplyLimit        = errors.New("saved reply limit reached")
+
+	ErrInvalidLocalScheduledAt       = errors.New("invalid scheduled_at, expected local datetime like 2006-01-02T15:04")
+	ErrScheduledTooFar               = errors.New("messages can be scheduled at most 30 days ahead")
+	ErrSchedulingCoachOnly           = errors.New("only the coach can schedule messages")
+	ErrInvalidScheduledStatus        = errors.New("invalid scheduled message status")
+	ErrScheduledMessageNotFound      = errors.New("scheduled message not found")
+	ErrScheduledMessageForbidden     = errors.New("scheduled message does not belong to this user")
+	ErrScheduledMessageNotCancelable = errors.New("scheduled message is no longer pending")
 )
 
+// maxSavedRepliesPerCoach keeps the reply picker short enough to scan.
+const maxSavedRepliesPerCoach = 100
+
+const (
+	maxScheduleAhead = 30 * 24 * time.Hour
+
+	// A scheduled message that keeps failing transiently is given up on after this many tries
+	maxScheduledMessageAttempts = 5
+	scheduledMessageStuckAfter  = 10 * time.Minute
+)
+
+// Clock-time layouts accepted for scheduled_at when local_time is set
+var localScheduleLayouts = []string{"2006-01-02T15:04:05", "2006-01-02T15:04", time.RFC3339}
+
 type CreateConversationInput struct {
 	ClientProfileID uint `json:"client_profile_id" binding:"required"`
 }
@@ -28,31 +59,75 @@ type SendMessageInput struct {
 	Content   *string `json:"content"`
 	MediaURL  *string `json:"media_url"`
 	MediaType *string `json:"media_type"`
+	// SavedReplyID expands one of the sending coach's saved replies; Content, if set, is appended to it.
+	SavedReplyID *uint `json:"saved_reply_id"`
+	// ScheduledAt holds the message back until then (coaches only, at most 30 days ahead). RFC3339,
+	// or with LocalTime a clock time like "2026-03-02T08:00" read in the client's timezone.
+	ScheduledAt *string `json:"scheduled_at"`
+	LocalTime   bool    `json:"local_time"`
+}
+
+// ScheduledDeliveryResult counts what one DeliverDueScheduledMessages pass did.
+type ScheduledDeliveryResult struct {
+	Sent     int
+	Retried  int
+	Failed   int
+	Requeued int64
+}
+
+type MarkAsReadInput struct {
+	// UpToMessageID marks only messages at or before this one, e.g. the last message on screen.
+	// Omitted marks every unread message.
+	UpToMessageID *uint `json:"up_to_message_id"`
+}
+
+type MarkAsReadResult struct {
+	MarkedCount int64 `json:"marked_count"`
+	// Highest message ID marked by this call; nil when nothing was unread
+	UpToMessageID *uint      `json:"up_to_message_id"`
+	ReadAt        *time.Time `json:"read_at"`
+}
+
+type CreateSavedReplyInput struct {
+	Title string `json:"title" binding:"required,max=100"`
+	Body  string `json:"body" binding:"required,max=5000"`
+}
+
+type UpdateSavedReplyInput struct {
+	Title *string `json:"title" binding:"omitempty,max=100"`
+	Body  *string `json:"body" binding:"omitempty,max=5000"`
 }
 
 type MessageService struct {
-	repos       *repositories.RepositoriesCollection
-	messageRepo *repositories.MessageRepository
-	clientRepo  *repositories.ClientRepository
-	coachRepo   *repositories.CoachRepository
-	events      *events.Publisher
+	repos         *repositories.RepositoriesCollection
+	messageRepo   *repositories.MessageRepository
+	clientRepo    *repositories.ClientRepository
+	coachRepo     *repositories.CoachRepository
+	userRepo      *repositories.UserRepository
+	securityStore *stores.SecurityStore
+	events        *events.Publisher
 }
 
 func NewMessageService(
 	repos *repositories.RepositoriesCollection,
 	eventsPublisher *events.Publisher,
+	securityStore *stores.SecurityStore,
 ) *MessageService {
 	return &MessageService{
-		repos:       repos,
-		messageRepo: repos.Message,
-		clientRepo:  repos.Client,
-		coachRepo:   repos.Coach,
-		events:      eventsPublisher,
+		repos:         repos,
+		messageRepo:   repos.Message,
+		clientRepo:    repos.Client,
+		coachRepo:     repos.Coach,
+		userRepo:      repos.User,
+		securityStore: securityStore,
+		events:        eventsPublisher,
 	}
 }
 
-func (s *MessageService) ListConversations(ctx context.Context, userID uint) ([]models.Conversation, error) {
-	return s.messageRepo.ListConversations(ctx, userID)
+// ListConversations returns the user's inbox. Conversations of archived client relationships are
+// only included when includeInactive is set.
+func (s *MessageService) ListConversations(ctx context.Context, userID uint, includeInactive bool) ([]models.Conversation, error) {
+	return s.messageRepo.ListConversations(ctx, userID, includeInactive)
 }
 
 func (s *MessageService) GetConversation(ctx context.Context, userID, conversationID uint) (*models.Conversation, error) {
@@ -126,21 +201,267 @@ func (s *MessageService) ListMessages(ctx context.Context, userID, conversationI
 }
 
 func (s *MessageService) SendMessage(ctx context.Context, userID, conversationID uint, input SendMessageInput) (*models.Message, error) {
+	message, savedReply, recipientID, err := s.prepareMessage(ctx, userID, conversationID, input)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repos.WithTransaction(ctx, func(tx *gorm.DB, txRepos *repositories.RepositoriesCollection) error {
+		return createMessageTx(ctx, tx, txRepos, s.events, message, savedReply, recipientID)
+	}); err != nil {
+		return nil, err
+	}
+
+	return message, nil
+}
+
+// ScheduleMessage stores a coach's message for delivery at input.ScheduledAt. It returns nil and no
+// error when that time is not in the future, in which case the caller sends the message now.
+func (s *MessageService) ScheduleMessage(ctx context.Context, userID, conversationID uint, input SendMessageInput) (*models.ScheduledMessage, error) {
+	if input.ScheduledAt == nil {
+		return nil, ErrInvalidScheduledAt
+	}
+
+	conversation, err := s.GetConversation(ctx, userID, conversationID)
+	if err != nil {
+		return nil, err
+	}
+	if conversation.Coach.UserID != userID {
+		return nil, ErrSchedulingCoachOnly
+	}
+
+	var timezone *string
+	loc := time.UTC
+	if input.LocalTime {
+		name := "UTC"
+		if conversation.Client.User.Profile != nil && conversation.Client.User.Profile.Timezone != "" {
+			name = conversation.Client.User.Profile.Timezone
+		}
+		loc = utils.ResolveLocation(name)
+		name = loc.String()
+		timezone = &name
+	}
+	scheduledAt, err := parseScheduledAt(*input.ScheduledAt, input.LocalTime, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	if !scheduledAt.After(now) {
+		return nil, nil
+	}
+	if scheduledAt.After(now.Add(maxScheduleAhead)) {
+		return nil, ErrScheduledTooFar
+	}
+
+	// Validate now so mistakes surface to the coach instead of failing at delivery.
+	if _, _, _, err := s.prepareMessage(ctx, userID, conversationID, input); err != nil {
+		return nil, err
+	}
+
+	scheduled := &models.ScheduledMessage{
+		ConversationID: conversationID,
+		SenderID:       userID,
+		Content:        trimPtr(input.Content),
+		MediaURL:       trimPtr(input.MediaURL),
+		MediaType:      trimPtr(input.MediaType),
+		SavedReplyID:   input.SavedReplyID,
+		ScheduledAt:    scheduledAt,
+		Timezone:       timezone,
+		Status:         models.ScheduledMessagePending,
+	}
+	if err := s.messageRepo.CreateScheduledMessage(ctx, scheduled); err != nil {
+		return nil, err
+	}
+	return scheduled, nil
+}
+
+// ListMyScheduledMessages returns the coach's scheduled messages with the given status (default pending), soonest first.
+func (s *MessageService) ListMyScheduledMessages(ctx context.Context, userID uint, status string) ([]models.ScheduledMessage, error) {
+	if _, err := s.getCoachProfile(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	status = strings.TrimSpace(status)
+	switch status {
+	case "":
+		status = models.ScheduledMessagePending
+	case models.ScheduledMessagePending, models.ScheduledMessageSent, models.ScheduledMessageCancelled, models.ScheduledMessageFailed:
+	default:
+		return nil, ErrInvalidScheduledStatus
+	}
+
+	return s.messageRepo.ListScheduledMessages(ctx, userID, status)
+}
+
+// CancelScheduledMessage cancels one of the caller's scheduled messages before the worker picks it up.
+func (s *MessageService) CancelScheduledMessage(ctx context.Context, userID, scheduledID uint) (*models.ScheduledMessage, error) {
+	scheduled, err := s.messageRepo.GetScheduledMessage(ctx, scheduledID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrScheduledMessageNotFound
+		}
+		return nil, err
+	}
+	if scheduled.SenderID != userID {
+		return nil, ErrScheduledMessageForbidden
+	}
+
+	cancelled, err := s.messageRepo.CancelScheduledMessage(ctx, scheduledID, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	if !cancelled {
+		return nil, ErrScheduledMessageNotCancelable
+	}
+
+	return s.messageRepo.GetScheduledMessage(ctx, scheduledID)
+}
+
+// DeliverDueScheduledMessages claims up to limit scheduled messages due by now and sends each through
+// the normal send path, so message.sent and its push fire as usual. Messages that can never be sent
+// (closed conversation, deleted saved reply, ...) fail immediately; other errors are retried on the
+// next pass up to maxScheduledMessageAttempts.
+func (s *MessageService) DeliverDueScheduledMessages(ctx context.Context, now time.Time, limit int) (*ScheduledDeliveryResult, error) {
+	result := &ScheduledDeliveryResult{}
+
+	requeued, err := s.messageRepo.RequeueStuckScheduledMessages(ctx, scheduledMessageStuckAfter)
+	if err != nil {
+		return nil, err
+	}
+	result.Requeued = requeued
+
+	claimed, err := s.messageRepo.ClaimDueScheduledMessages(ctx, now, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range claimed {
+		scheduled := &claimed[i]
+		sendErr := s.deliverScheduledMessage(ctx, scheduled)
+		if sendErr == nil {
+			result.Sent++
+			continue
+		}
+
+		attempts := scheduled.Attempts + 1
+		if isPermanentSendError(sendErr) || attempts >= maxScheduledMessageAttempts {
+			if err := s.messageRepo.MarkScheduledMessageFailed(ctx, scheduled.ID, attempts, sendErr.Error()); err != nil {
+				return result, err
+			}
+			result.Failed++
+			continue
+		}
+		if err := s.messageRepo.MarkScheduledMessageRetry(ctx, scheduled.ID, attempts, sendErr.Error()); err != nil {
+			return result, err
+		}
+		result.Retried++
+	}
+
+	return result, nil
+}
+
+func (s *MessageService) deliverScheduledMessage(ctx context.Context, scheduled *models.ScheduledMessage) error {
+	message, savedReply, recipientID, err := s.prepareMessage(ctx, scheduled.SenderID, scheduled.ConversationID, SendMessageInput{
+		Content:      scheduled.Content,
+		MediaURL:     scheduled.MediaURL,
+		MediaType:    scheduled.MediaType,
+		SavedReplyID: scheduled.SavedReplyID,
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.repos.WithTransaction(ctx, func(tx *gorm.DB, txRepos *repositories.RepositoriesCollection) error {
+		if err := createMessageTx(ctx, tx, txRepos, s.events, message, savedReply, recipientID); err != nil {
+			return err
+		}
+		return txRepos.Message.MarkScheduledMessageSentTx(ctx, tx, scheduled.ID, message.ID, message.CreatedAt)
+	})
+}
+
+// isPermanentSendError reports whether a scheduled message can never be delivered as written.
+func isPermanentSendError(err error) bool {
+	for _, permanent := range []error{
+		ErrConversationNotFound,
+		ErrConversationForbidden,
+		ErrConversationClosed,
+		ErrMessageContentRequired,
+		ErrSavedReplyNotFound,
+		ErrSavedReplyForbidden,
+	} {
+		if errors.Is(err, permanent) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseScheduledAt reads scheduled_at as RFC3339, or with localTime as a clock time in loc.
+// A local time keeps only the clock reading; any offset in the value is replaced by loc.
+func parseScheduledAt(raw string, localTime bool, loc *time.Location) (time.Time, error) {
+	raw = strings.TrimSpace(raw)
+	if !localTime {
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return time.Time{}, ErrInvalidScheduledAt
+		}
+		return parsed.UTC(), nil
+	}
+
+	for _, layout := range localScheduleLayouts {
+		parsed, err := time.Parse(layout, raw)
+		if err != nil {
+			continue
+		}
+		local := time.Date(parsed.Year(), parsed.Month(), parsed.Day(), parsed.Hour(), parsed.Minute(), parsed.Second(), 0, loc)
+		return local.UTC(), nil
+	}
+	return time.Time{}, ErrInvalidLocalScheduledAt
+}
+
+// prepareMessage checks the sender may post in the conversation and builds the message,
+// expanding a saved reply if one was picked. It writes nothing.
+func (s *MessageService) prepareMessage(ctx context.Context, userID, conversationID uint, input SendMessageInput) (*models.Message, *models.SavedReply, uint, error) {
 	content := trimPtr(input.Content)
 	mediaURL := trimPtr(input.MediaURL)
 
-	if content == nil && mediaURL == nil {
-		return nil, ErrMessageContentRequired
+	if content == nil && mediaURL == nil && input.SavedReplyID == nil {
+		return nil, nil, 0, ErrMessageContentRequired
 	}
 
 	conversation, err := s.GetConversation(ctx, userID, conversationID)
 	if err != nil {
-		return nil, err
+		return nil, nil, 0, err
+	}
+
+	if conversation.ClosedAt != nil {
+		return nil, nil, 0, ErrConversationClosed
 	}
 
 	recipientID := resolveRecipientUserID(userID, conversation)
 	if recipientID == 0 {
-		return nil, ErrConversationForbidden
+		return nil, nil, 0, ErrConversationForbidden
+	}
+
+	// Saved replies are expanded server-side so the stored message is exactly what was sent.
+	var savedReply *models.SavedReply
+	if input.SavedReplyID != nil {
+		savedReply, err = s.messageRepo.GetSavedReplyByID(ctx, *input.SavedReplyID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, nil, 0, ErrSavedReplyNotFound
+			}
+			return nil, nil, 0, err
+		}
+		if conversation.Coach.UserID != userID || savedReply.CoachID != conversation.CoachID {
+			return nil, nil, 0, ErrSavedReplyForbidden
+		}
+		body := savedReply.Body
+		if content != nil {
+			body += "\n\n" + *content
+		}
+		content = &body
 	}
 
 	message := &models.Message{
@@ -150,54 +471,223 @@ func (s *MessageService) SendMessage(ctx context.Context, userID, conversationID
 		MediaURL:       mediaURL,
 		MediaType:      trimPtr(input.MediaType),
 	}
+	return message, savedReply, recipientID, nil
+}
 
-	if err := s.repos.WithTransaction(ctx, func(tx *gorm.DB, txRepos *repositories.RepositoriesCollection) error {
-		if err := txRepos.Message.CreateMessageTx(ctx, tx, message); err != nil {
+// createMessageTx stores a prepared message, bumps the saved reply's usage and queues message.sent.
+func createMessageTx(ctx context.Context, tx *gorm.DB, txRepos *repositories.RepositoriesCollection, publisher *events.Publisher, message *models.Message, savedReply *models.SavedReply, recipientID uint) error {
+	if err := txRepos.Message.CreateMessageTx(ctx, tx, message); err != nil {
+		return err
+	}
+	if savedReply != nil {
+		if err := txRepos.Message.IncrementSavedReplyUsageTx(ctx, tx, savedReply.ID); err != nil {
 			return err
 		}
+	}
 
-		if s.events != nil {
-			payload := events.MessageSentPayload{
-				MessageID:      message.ID,
-				ConversationID: message.ConversationID,
-				SenderID:       message.SenderID,
-				RecipientID:    recipientID,
-				ContentPreview: buildMessagePreview(content),
-			}
-			idempotencyKey := events.BuildIdempotencyKey(
-				events.EventTypeMessageSent,
-				strconv.FormatUint(uint64(message.ID), 10),
-			)
-			if err := s.events.PublishInTx(
-				ctx,
-				tx,
-				events.EventTypeMessageSent,
-				"message",
-				strconv.FormatUint(uint64(message.ID), 10),
-				idempotencyKey,
-				payload,
-			); err != nil {
-				return err
+	return publishMessageSent(ctx, publisher, tx, message, recipientID)
+}
+
+// MarkAsRead stamps the other party's unread messages as read and queues a read receipt for them.
+func (s *MessageService) MarkAsRead(ctx context.Context, userID, conversationID uint, input MarkAsReadInput) (*MarkAsReadResult, error) {
+	conversation, err := s.GetConversation(ctx, userID, conversationID)
+	if err != nil {
+		return nil, err
+	}
+
+	var upToMessageID uint
+	if input.UpToMessageID != nil {
+		message, err := s.messageRepo.GetMessage(ctx, *input.UpToMessageID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrMessageNotFound
 			}
+			return nil, err
+		}
+		if message.ConversationID != conversationID {
+			return nil, ErrMessageNotFound
 		}
+		upToMessageID = message.ID
+	}
 
-		return nil
+	readAt := time.Now()
+	result := &MarkAsReadResult{}
+	if err := s.repos.WithTransaction(ctx, func(tx *gorm.DB, txRepos *repositories.RepositoriesCollection) error {
+		marked, lastID, err := txRepos.Message.MarkAsReadTx(ctx, tx, conversationID, userID, upToMessageID, readAt)
+		if err != nil {
+			return err
+		}
+		if marked == 0 {
+			return nil
+		}
+		result.MarkedCount = marked
+		result.UpToMessageID = &lastID
+		result.ReadAt = &readAt
+
+		return publishMessageRead(ctx, s.events, tx, conversation, userID, lastID, marked, readAt)
 	}); err != nil {
 		return nil, err
 	}
 
-	return message, nil
+	return result, nil
 }
 
-func (s *MessageService) MarkAsRead(ctx context.Context, userID, conversationID uint) error {
-	if _, err := s.GetConversation(ctx, userID, conversationID); err != nil {
+func (s *MessageService) GetUnreadCount(ctx context.Context, userID uint, includeInactive bool) (int64, error) {
+	return s.messageRepo.GetUnreadCount(ctx, userID, includeInactive)
+}
+
+func (s *MessageService) CreateMySavedReply(ctx context.Context, userID uint, input CreateSavedReplyInput) (*models.SavedReply, error) {
+	coach, err := s.getCoachProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	title := strings.TrimSpace(input.Title)
+	body := strings.TrimSpace(input.Body)
+	if title == "" || body == "" {
+		return nil, ErrSavedReplyInvalid
+	}
+
+	count, err := s.messageRepo.CountSavedReplies(ctx, coach.ID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= maxSavedRepliesPerCoach {
+		return nil, ErrSavedReplyLimit
+	}
+
+	reply := &models.SavedReply{
+		CoachID: coach.ID,
+		Title:   title,
+		Body:    body,
+	}
+	if err := s.messageRepo.CreateSavedReply(ctx, reply); err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
+// ListMySavedReplies returns the coach's saved replies, most used first, optionally filtered by title.
+func (s *MessageService) ListMySavedReplies(ctx context.Context, userID uint, search string) ([]models.SavedReply, error) {
+	coach, err := s.getCoachProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.messageRepo.ListSavedReplies(ctx, coach.ID, strings.TrimSpace(search))
+}
+
+func (s *MessageService) UpdateMySavedReply(ctx context.Context, userID, replyID uint, input UpdateSavedReplyInput) (*models.SavedReply, error) {
+	reply, err := s.getOwnedSavedReply(ctx, userID, replyID)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Title != nil {
+		title := strings.TrimSpace(*input.Title)
+		if title != "" {
+			reply.Title = title
+		}
+	}
+	if input.Body != nil {
+		body := strings.TrimSpace(*input.Body)
+		if body != "" {
+			reply.Body = body
+		}
+	}
+
+	if err := s.messageRepo.UpdateSavedReply(ctx, reply); err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
+func (s *MessageService) DeleteMySavedReply(ctx context.Context, userID, replyID uint) error {
+	if _, err := s.getOwnedSavedReply(ctx, userID, replyID); err != nil {
 		return err
 	}
-	return s.messageRepo.MarkAsRead(ctx, conversationID, userID)
+	return s.messageRepo.DeleteSavedReply(ctx, replyID)
+}
+
+func (s *MessageService) getOwnedSavedReply(ctx context.Context, userID, replyID uint) (*models.SavedReply, error) {
+	coach, err := s.getCoachProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	reply, err := s.messageRepo.GetSavedReplyByID(ctx, replyID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSavedReplyNotFound
+		}
+		return nil, err
+	}
+	if reply.CoachID != coach.ID {
+		return nil, ErrSavedReplyForbidden
+	}
+	return reply, nil
+}
+
+func (s *MessageService) getCoachProfile(ctx context.Context, userID uint) (*models.CoachProfile, error) {
+	coach, err := s.coachRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCoachProfileNotFound
+		}
+		return nil, err
+	}
+	return coach, nil
 }
 
-func (s *MessageService) GetUnreadCount(ctx context.Context, userID uint) (int64, error) {
-	return s.messageRepo.GetUnreadCount(ctx, userID)
+// publishMessageSent queues message.sent in the caller's transaction. publisher may be nil.
+func publishMessageSent(ctx context.Context, publisher *events.Publisher, tx *gorm.DB, message *models.Message, recipientID uint) error {
+	if publisher == nil {
+		return nil
+	}
+	id := strconv.FormatUint(uint64(message.ID), 10)
+	return publisher.PublishInTx(
+		ctx,
+		tx,
+		events.EventTypeMessageSent,
+		"message",
+		id,
+		events.BuildIdempotencyKey(events.EventTypeMessageSent, id),
+		events.MessageSentPayload{
+			MessageID:      message.ID,
+			ConversationID: message.ConversationID,
+			SenderID:       message.SenderID,
+			RecipientID:    recipientID,
+			ContentPreview: buildMessagePreview(message.Content),
+		},
+	)
+}
+
+// publishMessageRead queues message.read for the other participant in the caller's transaction.
+// publisher may be nil.
+func publishMessageRead(ctx context.Context, publisher *events.Publisher, tx *gorm.DB, conversation *models.Conversation, readerID, upToMessageID uint, marked int64, readAt time.Time) error {
+	if publisher == nil {
+		return nil
+	}
+	senderID := resolveRecipientUserID(readerID, conversation)
+	if senderID == 0 {
+		return nil
+	}
+	conversationID := strconv.FormatUint(uint64(conversation.ID), 10)
+	return publisher.PublishInTx(
+		ctx,
+		tx,
+		events.EventTypeMessageRead,
+		"conversation",
+		conversationID,
+		events.BuildIdempotencyKey(events.EventTypeMessageRead, conversationID, strconv.FormatUint(uint64(readerID), 10), strconv.FormatUint(uint64(upToMessageID), 10)),
+		events.MessageReadPayload{
+			ConversationID: conversation.ID,
+			ReaderID:       readerID,
+			SenderID:       senderID,
+			UpToMessageID:  upToMessageID,
+			MarkedCount:    marked,
+			ReadAt:         readAt,
+		},
+	)
 }
 
 func isConversationParticipant(userID uint, conversation *models.Conversation) bool {
@@ -0,0 +1,149 @@
+package services
+
+import (
+	"chalk-api/pkg/external/expo"
+	"chalk-api/pkg/models"
+	"chalk-api/pkg/repositories"
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var ErrNotificationNotFound = errors.New("notification not found")
+
+const (
+	// Expo has receipts ready about 15 minutes after sending and keeps them for a day
+	pushReceiptDelay  = 15 * time.Minute
+	pushReceiptWindow = 24 * time.Hour
+	// Expo accepts at most 1000 ticket IDs per receipts request
+	pushReceiptBatchSize = 1000
+	// Batches per maintenance run, so one run can't hold the worker for long
+	pushReceiptMaxBatches = 10
+	// Active tokens with no accepted push for this long are deactivated
+	deviceTokenStaleAfter = 90 * 24 * time.Hour
+)
+
+// NotificationService serves the in-app notification inbox.
+// Notifications are written by event handlers; users can only list and acknowledge them.
+// It also runs the push bookkeeping tasks: fetching Expo receipts and pruning stale device tokens.
+type NotificationService struct {
+	notificationRepo *repositories.NotificationRepository
+	userRepo         *repositories.UserRepository
+	expoAPI          expo.API
+}
+
+func NewNotificationService(repos *repositories.RepositoriesCollection, expoAPI expo.API) *NotificationService {
+	return &NotificationService{notificationRepo: repos.Notification, userRepo: repos.User, expoAPI: expoAPI}
+}
+
+func (s *NotificationService) ListNotifications(ctx context.Context, userID uint, unreadOnly bool, limit, offset int) ([]models.Notification, int64, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return s.notificationRepo.ListByUser(ctx, userID, unreadOnly, limit, offset)
+}
+
+func (s *NotificationService) GetUnreadCount(ctx context.Context, userID uint) (int64, error) {
+	return s.notificationRepo.CountUnread(ctx, userID)
+}
+
+func (s *NotificationService) MarkRead(ctx context.Context, userID, notificationID uint) error {
+	notification, err := s.notificationRepo.GetByID(ctx, notificationID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrNotificationNotFound
+		}
+		return err
+	}
+	// Report someone else's notification as missing rather than forbidden so IDs can't be probed.
+	if notification.UserID != userID {
+		return ErrNotificationNotFound
+	}
+	return s.notificationRepo.MarkRead(ctx, notificationID)
+}
+
+func (s *NotificationService) MarkAllRead(ctx context.Context, userID uint) error {
+	return s.notificationRepo.MarkAllRead(ctx, userID)
+}
+
+// CheckPushReceipts fetches receipts for tickets that are due, and deactivates tokens whose receipt
+// says DeviceNotRegistered. Tickets Expo has no receipt for yet are retried on later runs until they
+// leave the 24-hour window. Returns how many receipts were stored.
+func (s *NotificationService) CheckPushReceipts(ctx context.Context, now time.Time) (int64, error) {
+	if s.expoAPI == nil {
+		return 0, nil
+	}
+
+	var total int64
+	for batch := 0; batch < pushReceiptMaxBatches; batch++ {
+		tickets, err := s.notificationRepo.ListPushTicketsAwaitingReceipt(ctx, now.Add(-pushReceiptWindow), now.Add(-pushReceiptDelay), pushReceiptBatchSize)
+		if err != nil {
+			return total, err
+		}
+		saved, err := s.savePushReceipts(ctx, tickets, now)
+		total += saved
+		if err != nil {
+			return total, err
+		}
+		// A short batch was the last one; a batch with no receipts at all would only repeat itself
+		if len(tickets) < pushReceiptBatchSize || saved == 0 {
+			break
+		}
+	}
+	return total, nil
+}
+
+func (s *NotificationService) savePushReceipts(ctx context.Context, tickets []models.PushTicket, now time.Time) (int64, error) {
+	if len(tickets) == 0 {
+		return 0, nil
+	}
+
+	ids := make([]string, 0, len(tickets))
+	for _, ticket := range tickets {
+		ids = append(ids, *ticket.TicketID)
+	}
+	receipts, err := s.expoAPI.GetReceipts(ctx, ids)
+	if err != nil {
+		return 0, err
+	}
+
+	var saved int64
+	var notRegistered []string
+	for _, ticket := range tickets {
+		receipt, ok := receipts[*ticket.TicketID]
+		if !ok {
+			continue
+		}
+		var errorCode *string
+		if receipt.Details != nil && receipt.Details.Error != "" {
+			code := receipt.Details.Error
+			errorCode = &code
+			if code == expo.ErrorDeviceNotRegistered {
+				notRegistered = append(notRegistered, ticket.Token)
+			}
+		}
+		if err := s.notificationRepo.SavePushReceipt(ctx, ticket.ID, receipt.Status, errorCode, now); err != nil {
+			return saved, err
+		}
+		saved++
+	}
+
+	if _, err := s.userRepo.DeactivateDeviceTokens(ctx, notRegistered, models.DeviceTokenDeactivatedNotRegistered, now); err != nil {
+		return saved, err
+	}
+	return saved, nil
+}
+
+// DeactivateStaleDeviceTokens deactivates tokens that haven't had a push accepted in 90 days.
+func (s *NotificationService) DeactivateStaleDeviceTokens(ctx context.Context, now time.Time) (int64, error) {
+	cutoff := now.Add(-deviceTokenStaleAfter)
+	return s.userRepo.DeactivateStaleDeviceTokens(ctx, cutoff, now)
+}
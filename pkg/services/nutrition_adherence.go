@@ -0,0 +1,196 @@
+package services
+
+import (
+	"chalk-api/pkg/models"
+	"chalk-api/pkg/repositories"
+	"chalk-api/pkg/utils"
+	"context"
+	"errors"
+	"math"
+	"time"
+)
+
+var ErrInvalidAdherenceRange = errors.New("invalid nutrition adherence range")
+
+const (
+	defaultAdherenceDays      = 28
+	maxAdherenceDays          = 90
+	defaultAdherenceTolerance = 10 // percent either side of the calorie target
+	maxAdherenceTolerance     = 50
+)
+
+// NutritionTargetSnapshot is the target in effect on a day.
+type NutritionTargetSnapshot struct {
+	EffectiveDate string `json:"effective_date"`
+	Calories      *int   `json:"calories"`
+	ProteinGrams  *int   `json:"protein_grams"`
+	CarbsGrams    *int   `json:"carbs_grams"`
+	FatGrams      *int   `json:"fat_grams"`
+}
+
+// NutritionAdherenceDay compares one day's logged totals with its target. CaloriePercent is logged
+// calories as a percentage of the calorie target; ProteinGap is target minus logged protein, so a
+// positive gap means the client fell short. Both are null when the day has no such target or nothing logged.
+type NutritionAdherenceDay struct {
+	Date           string                   `json:"date"`
+	Logged         bool                     `json:"logged"`
+	Calories       int                      `json:"calories"`
+	ProteinGrams   float64                  `json:"protein_grams"`
+	CarbsGrams     float64                  `json:"carbs_grams"`
+	FatGrams       float64                  `json:"fat_grams"`
+	Target         *NutritionTargetSnapshot `json:"target"`
+	CaloriePercent *float64                 `json:"calorie_percent"`
+	ProteinGap     *float64                 `json:"protein_gap"`
+	Adherent       bool                     `json:"adherent"`
+}
+
+// NutritionAdherenceSummary scores only logged days: a day with nothing logged says nothing about intake.
+type NutritionAdherenceSummary struct {
+	Days              int      `json:"days"`
+	DaysLogged        int      `json:"days_logged"`
+	DaysWithTarget    int      `json:"days_with_target"` // logged days that had a calorie target
+	AdherentDays      int      `json:"adherent_days"`
+	AdherencePercent  *float64 `json:"adherence_percent"`   // adherent_days / days_with_target
+	AverageProteinGap *float64 `json:"average_protein_gap"` // over logged days with a protein target
+}
+
+type NutritionAdherenceReport struct {
+	ClientID         uint                      `json:"client_id"`
+	StartDate        string                    `json:"start_date"`
+	EndDate          string                    `json:"end_date"`
+	Timezone         string                    `json:"timezone"` // the client's; days are their calendar days
+	TolerancePercent int                       `json:"tolerance_percent"`
+	Days             []NutritionAdherenceDay   `json:"days"`
+	Summary          NutritionAdherenceSummary `json:"summary"`
+}
+
+// GetClientNutritionAdherence scores the client's last `days` calendar days, ending today in the client's
+// timezone, against the nutrition target in effect each day. A day is adherent when logged calories are
+// within tolerancePercent of its calorie target. Pass 0 for either to use the defaults.
+func (s *ReportService) GetClientNutritionAdherence(ctx context.Context, userID, clientID uint, days, tolerancePercent int) (*NutritionAdherenceReport, error) {
+	if days == 0 {
+		days = defaultAdherenceDays
+	}
+	if tolerancePercent == 0 {
+		tolerancePercent = defaultAdherenceTolerance
+	}
+	if days < 1 || days > maxAdherenceDays || tolerancePercent < 1 || tolerancePercent > maxAdherenceTolerance {
+		return nil, ErrInvalidAdherenceRange
+	}
+
+	client, err := s.getOwnedClient(ctx, userID, clientID)
+	if err != nil {
+		return nil, err
+	}
+
+	timezone := ""
+	if client.User.Profile != nil {
+		timezone = client.User.Profile.Timezone
+	}
+	loc := utils.ResolveLocation(timezone)
+	end := time.Now().In(loc)
+	end = time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, time.UTC)
+	start := end.AddDate(0, 0, -(days - 1))
+	startDate, endDate := start.Format(calendarDateLayout), end.Format(calendarDateLayout)
+
+	targets, err := s.repos.Nutrition.ListTargets(ctx, client.ID)
+	if err != nil {
+		return nil, err
+	}
+	totals, err := s.repos.Nutrition.ListDailyTotals(ctx, client.ID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &NutritionAdherenceReport{
+		ClientID:         client.ID,
+		StartDate:        startDate,
+		EndDate:          endDate,
+		Timezone:         loc.String(),
+		TolerancePercent: tolerancePercent,
+		Days:             buildAdherenceDays(start, days, totals, targets, float64(tolerancePercent)/100),
+	}
+	report.Summary = summarizeAdherence(report.Days)
+	return report, nil
+}
+
+// buildAdherenceDays emits every day from start, logged or not. targets must be newest effective date
+// first, as ListTargets returns them.
+func buildAdherenceDays(start time.Time, days int, totals []repositories.DailyTotal, targets []models.NutritionTarget, tolerance float64) []NutritionAdherenceDay {
+	byDate := make(map[string]repositories.DailySummary, len(totals))
+	for _, total := range totals {
+		byDate[total.Date] = total.DailySummary
+	}
+
+	result := make([]NutritionAdherenceDay, 0, days)
+	for i := 0; i < days; i++ {
+		date := start.AddDate(0, 0, i).Format(calendarDateLayout)
+		day := NutritionAdherenceDay{Date: date}
+		if total, ok := byDate[date]; ok {
+			day.Logged = true
+			day.Calories = total.Calories
+			day.ProteinGrams = total.ProteinGrams
+			day.CarbsGrams = total.CarbsGrams
+			day.FatGrams = total.FatGrams
+		}
+
+		for _, target := range targets {
+			if dateOnlyPrefix(target.EffectiveDate) > date {
+				continue
+			}
+			day.Target = &NutritionTargetSnapshot{
+				EffectiveDate: dateOnlyPrefix(target.EffectiveDate),
+				Calories:      target.Calories,
+				ProteinGrams:  target.ProteinGrams,
+				CarbsGrams:    target.CarbsGrams,
+				FatGrams:      target.FatGrams,
+			}
+			break
+		}
+
+		if day.Logged && day.Target != nil {
+			if goal := day.Target.Calories; goal != nil && *goal > 0 {
+				percent := math.Round(float64(day.Calories)/float64(*goal)*1000) / 10
+				day.CaloriePercent = &percent
+				day.Adherent = math.Abs(float64(day.Calories-*goal)) <= float64(*goal)*tolerance
+			}
+			if goal := day.Target.ProteinGrams; goal != nil && *goal > 0 {
+				gap := math.Round((float64(*goal)-day.ProteinGrams)*10) / 10
+				day.ProteinGap = &gap
+			}
+		}
+		result = append(result, day)
+	}
+	return result
+}
+
+func summarizeAdherence(days []NutritionAdherenceDay) NutritionAdherenceSummary {
+	summary := NutritionAdherenceSummary{Days: len(days)}
+	var proteinGap float64
+	var proteinDays int
+	for _, day := range days {
+		if !day.Logged {
+			continue
+		}
+		summary.DaysLogged++
+		if day.CaloriePercent != nil {
+			summary.DaysWithTarget++
+			if day.Adherent {
+				summary.AdherentDays++
+			}
+		}
+		if day.ProteinGap != nil {
+			proteinGap += *day.ProteinGap
+			proteinDays++
+		}
+	}
+	if summary.DaysWithTarget > 0 {
+		percent := math.Round(float64(summary.AdherentDays)/float64(summary.DaysWithTarget)*1000) / 10
+		summary.AdherencePercent = &percent
+	}
+	if proteinDays > 0 {
+		average := math.Round(proteinGap/float64(proteinDays)*10) / 10
+		summary.AverageProteinGap = &average
+	}
+	return summary
+}
@@ -0,0 +1,596 @@
+package services
+
+import (
+	"chalk-api/pkg/events"
+	"chalk-api/pkg/external/storage"
+	"chalk-api/pkg/models"
+	"chalk-api/pkg/repositories"
+	"chalk-api/pkg/stores"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidMonthFormat     = errors.New("invalid month format, expected YYYY-MM")
+	ErrClientReportInProgress = errors.New("a report for this client is already being generated")
+	ErrClientReportFuture     = errors.New("reports can only cover the current or past months")
+)
+
+const (
+	monthLayout            = "2006-01"
+	defaultEarningsMonths  = 6
+	maxEarningsMonths      = 24
+	topEarningClientsLimit = 5
+	csvFlushEveryRows      = 500
+
+	clientReportStaleAfter = time.Hour
+	clientReportListLimit  = 24
+)
+
+type CurrencyAmount struct {
+	Currency string  `json:"currency"`
+	Amount   float64 `json:"amount"`
+}
+
+type EarningsMonth struct {
+	Month             string           `json:"month"` // YYYY-MM
+	CompletedSessions int64            `json:"completed_sessions"`
+	PaidSessions      int64            `json:"paid_sessions"`
+	UnpaidSessions    int64            `json:"unpaid_sessions"`
+	UnpricedSessions  int64            `json:"unpriced_sessions"`  // counted as completed, excluded from amounts
+	LateCancellations int64            `json:"late_cancellations"` // client cancellations inside the cancellation window
+	Gross             []CurrencyAmount `json:"gross"`
+}
+
+type EarningsReport struct {
+	StartMonth string                             `json:"start_month"`
+	EndMonth   string                             `json:"end_month"`
+	Months     []EarningsMonth                    `json:"months"`
+	Totals     []CurrencyAmount                   `json:"totals"`
+	TopClients []repositories.EarningsClientTotal `json:"top_clients"`
+}
+
+// CSVExport is a validated export that has not been written yet, so callers can still
+// report errors as JSON before committing to a CSV response.
+type CSVExport struct {
+	Filename string
+	write    func(w *csv.Writer, flush func()) error
+}
+
+type ReportService struct {
+	repos           *repositories.RepositoriesCollection
+	coachRepo       *repositories.CoachRepository
+	clientRepo      *repositories.ClientRepository
+	sessionRepo     *repositories.SessionRepository
+	workoutRepo     *repositories.WorkoutRepository
+	userRepo        *repositories.UserRepository
+	reportRepo      *repositories.ClientReportRepository
+	coachStore      *stores.CoachStore
+	eventsPublisher *events.Publisher
+	storage         storage.API
+}
+
+func NewReportService(
+	repos *repositories.RepositoriesCollection,
+	coachStore *stores.CoachStore,
+	eventsPublisher *events.Publisher,
+	storageAPI storage.API,
+) *ReportService {
+	return &ReportService{
+		repos:           repos,
+		coachRepo:       repos.Coach,
+		clientRepo:      repos.Client,
+		sessionRepo:     repos.Session,
+		workoutRepo:     repos.Workout,
+		userRepo:        repos.User,
+		reportRepo:      repos.ClientReport,
+		coachStore:      coachStore,
+		eventsPublisher: eventsPublisher,
+		storage:         storageAPI,
+	}
+}
+
+// GetEarningsReport aggregates completed-session revenue per month (UTC) over an inclusive month range.
+// Ranges ending in the current month back the coach dashboard and are cached briefly.
+func (s *ReportService) GetEarningsReport(ctx context.Context, userID uint, startRaw, endRaw string) (*EarningsReport, error) {
+	coach, err := s.getCoachProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	currentMonth := time.Now().UTC().Format(monthLayout)
+	startMonth, endMonth, err := parseMonthRange(startRaw, endRaw, currentMonth)
+	if err != nil {
+		return nil, err
+	}
+	startKey, endKey := startMonth.Format(monthLayout), endMonth.Format(monthLayout)
+
+	cacheable := endKey == currentMonth
+	if cacheable {
+		var cached EarningsReport
+		if s.coachStore.GetEarningsReport(coach.ID, startKey, endKey, &cached) {
+			return &cached, nil
+		}
+	}
+
+	rangeEnd := endMonth.AddDate(0, 1, 0)
+	counts, err := s.sessionRepo.GetEarningsMonthCounts(ctx, coach.ID, startMonth, rangeEnd)
+	if err != nil {
+		return nil, err
+	}
+	amounts, err := s.sessionRepo.GetEarningsMonthAmounts(ctx, coach.ID, startMonth, rangeEnd)
+	if err != nil {
+		return nil, err
+	}
+	lateCancels, err := s.sessionRepo.GetLateCancelMonthCounts(ctx, coach.ID, startMonth, rangeEnd)
+	if err != nil {
+		return nil, err
+	}
+	topClients, err := s.sessionRepo.GetTopEarningClients(ctx, coach.ID, startMonth, rangeEnd, topEarningClientsLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	report := buildEarningsReport(startMonth, endMonth, counts, amounts, lateCancels, topClients)
+	if cacheable {
+		s.coachStore.SetEarningsReport(coach.ID, startKey, endKey, report)
+	}
+	return report, nil
+}
+
+// ExportClientWorkoutHistory prepares a CSV of every logged set from the client's completed workouts.
+// Dates are optional inclusive YYYY-MM-DD bounds. With preferredUnits, weights are written in the
+// coach's preferred unit instead of the one each set was logged in.
+func (s *ReportService) ExportClientWorkoutHistory(ctx context.Context, userID, clientID uint, startRaw, endRaw string, preferredUnits bool) (*CSVExport, error) {
+	client, err := s.getOwnedClient(ctx, userID, clientID)
+	if err != nil {
+		return nil, err
+	}
+	var weightUnit string
+	if preferredUnits {
+		prefs, err := s.userRepo.GetUnitPreferences(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		weightUnit = prefs.Weight
+	}
+	startDate, endDate, err := parseOptionalExportRange(startRaw, endRaw)
+	if err != nil {
+		return nil, err
+	}
+
+	var startKey, endKey string
+	if !startDate.IsZero() {
+		startKey = startDate.Format("2006-01-02")
+	}
+	if !endDate.IsZero() {
+		endKey = endDate.Format("2006-01-02")
+	}
+
+	return &CSVExport{
+		Filename: fmt.Sprintf("client-%d-workout-history.csv", client.ID),
+		write: func(w *csv.Writer, flush func()) error {
+			if err := w.Write([]string{"date", "workout", "exercise", "set", "reps", "weight", "weight_unit", "rpe"}); err != nil {
+				return err
+			}
+			written := 0
+			return s.workoutRepo.StreamCompletedWorkoutHistory(ctx, client.ID, startKey, endKey, func(row repositories.WorkoutHistoryRow) error {
+				if weightUnit != "" {
+					convertWeight(row.WeightUsed, row.WeightUnit, weightUnit)
+				}
+				if err := w.Write([]string{
+					row.Date,
+					csvSafe(row.WorkoutName),
+					csvSafe(row.ExerciseName),
+					strconv.Itoa(row.SetNumber),
+					formatOptionalInt(row.RepsCompleted),
+					formatOptionalFloat(row.WeightUsed),
+					derefString(row.WeightUnit),
+					formatOptionalInt(row.RPE),
+				}); err != nil {
+					return err
+				}
+				if written++; written%csvFlushEveryRows == 0 {
+					flush()
+				}
+				return nil
+			})
+		},
+	}, nil
+}
+
+// ExportClientSessions prepares a CSV of the client's sessions with this coach.
+// Dates are optional inclusive YYYY-MM-DD bounds on the scheduled date (UTC).
+func (s *ReportService) ExportClientSessions(ctx context.Context, userID, clientID uint, startRaw, endRaw string) (*CSVExport, error) {
+	client, err := s.getOwnedClient(ctx, userID, clientID)
+	if err != nil {
+		return nil, err
+	}
+	startDate, endDate, err := parseOptionalExportRange(startRaw, endRaw)
+	if err != nil {
+		return nil, err
+	}
+	if !endDate.IsZero() {
+		endDate = endDate.AddDate(0, 0, 1)
+	}
+
+	return &CSVExport{
+		Filename: fmt.Sprintf("client-%d-sessions.csv", client.ID),
+		write: func(w *csv.Writer, flush func()) error {
+			if err := w.Write([]string{"date", "time_utc", "duration_minutes", "session_type", "status", "notes"}); err != nil {
+				return err
+			}
+			written := 0
+			return s.sessionRepo.StreamClientSessions(ctx, client.CoachID, client.ID, startDate, endDate, func(row repositories.SessionHistoryRow) error {
+				scheduledAt := row.ScheduledAt.UTC()
+				if err := w.Write([]string{
+					scheduledAt.Format("2006-01-02"),
+					scheduledAt.Format("15:04"),
+					strconv.Itoa(row.DurationMinutes),
+					csvSafe(row.SessionType),
+					row.Status,
+					csvSafe(derefString(row.Notes)),
+				}); err != nil {
+					return err
+				}
+				if written++; written%csvFlushEveryRows == 0 {
+					flush()
+				}
+				return nil
+			})
+		},
+	}, nil
+}
+
+// WriteCSV streams the export to w, flushing periodically so large exports go out as chunks.
+func (e *CSVExport) WriteCSV(w io.Writer) error {
+	csvWriter := csv.NewWriter(w)
+	flush := func() {
+		csvWriter.Flush()
+		if flusher, ok := w.(http.Flusher); ok {
+			flusher.Flush()
+		}
+	}
+
+	if err := e.write(csvWriter, flush); err != nil {
+		return err
+	}
+	flush()
+	return csvWriter.Error()
+}
+
+// RequestClientReportInput picks the month to report on; empty means the previous month.
+type RequestClientReportInput struct {
+	Month string `json:"month"` // YYYY-MM
+}
+
+// ClientReportResponse is one generated report as listed to the coach.
+type ClientReportResponse struct {
+	ID          uint       `json:"id"`
+	ClientID    uint       `json:"client_id"`
+	PeriodStart string     `json:"period_start"`
+	PeriodEnd   string     `json:"period_end"`
+	Status      string     `json:"status"` // pending, processing, ready, failed or expired
+	RequestedAt time.Time  `json:"requested_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	DownloadURL *string    `json:"download_url,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+}
+
+// RequestClientReport queues a monthly progress report PDF for one of the coach's clients. The
+// client.report_requested handler renders it; only one report may be pending or processing per client.
+func (s *ReportService) RequestClientReport(ctx context.Context, userID, clientID uint, input RequestClientReportInput) (*ClientReportResponse, error) {
+	if !s.storage.IsConfigured() {
+		return nil, ErrStorageUnavailable
+	}
+	client, err := s.getOwnedClient(ctx, userID, clientID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	currentMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	month := currentMonth.AddDate(0, -1, 0)
+	if raw := strings.TrimSpace(input.Month); raw != "" {
+		month, err = time.Parse(monthLayout, raw)
+		if err != nil {
+			return nil, ErrInvalidMonthFormat
+		}
+		if month.After(currentMonth) {
+			return nil, ErrClientReportFuture
+		}
+	}
+
+	inFlight, err := s.reportRepo.GetInFlightByClient(ctx, client.ID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if inFlight != nil {
+		if time.Since(inFlight.CreatedAt) < clientReportStaleAfter {
+			return nil, ErrClientReportInProgress
+		}
+		if err := s.reportRepo.MarkFailed(ctx, inFlight.ID, "timed out"); err != nil {
+			return nil, err
+		}
+	}
+
+	report := &models.ClientReport{
+		CoachID:         client.CoachID,
+		ClientID:        client.ID,
+		RequestedByUser: userID,
+		PeriodStart:     month.Format(calendarDateLayout),
+		PeriodEnd:       month.AddDate(0, 1, -1).Format(calendarDateLayout),
+		Status:          models.ClientReportStatusPending,
+	}
+	err = s.repos.WithTransaction(ctx, func(tx *gorm.DB, txRepos *repositories.RepositoriesCollection) error {
+		created, err := txRepos.ClientReport.Create(ctx, report)
+		if err != nil {
+			return err
+		}
+		if !created {
+			return ErrClientReportInProgress
+		}
+
+		reportID := strconv.FormatUint(uint64(report.ID), 10)
+		return s.eventsPublisher.PublishInTx(
+			ctx,
+			tx,
+			events.EventTypeClientReportRequest,
+			"client_report",
+			reportID,
+			events.BuildIdempotencyKey(events.EventTypeClientReportRequest, reportID),
+			events.ClientReportRequestedPayload{
+				ReportID:    report.ID,
+				CoachUserID: userID,
+			},
+		)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toClientReportResponse(report), nil
+}
+
+// ListClientReports returns the client's most recent reports, newest first.
+func (s *ReportService) ListClientReports(ctx context.Context, userID, clientID uint) ([]ClientReportResponse, error) {
+	client, err := s.getOwnedClient(ctx, userID, clientID)
+	if err != nil {
+		return nil, err
+	}
+	reports, err := s.reportRepo.ListByClient(ctx, client.ID, clientReportListLimit)
+	if err != nil {
+		return nil, err
+	}
+	responses := make([]ClientReportResponse, 0, len(reports))
+	for i := range reports {
+		responses = append(responses, *toClientReportResponse(&reports[i]))
+	}
+	return responses, nil
+}
+
+func toClientReportResponse(report *models.ClientReport) *ClientReportResponse {
+	response := &ClientReportResponse{
+		ID:          report.ID,
+		ClientID:    report.ClientID,
+		PeriodStart: dateOnlyPrefix(report.PeriodStart),
+		PeriodEnd:   dateOnlyPrefix(report.PeriodEnd),
+		Status:      report.Status,
+		RequestedAt: report.CreatedAt,
+		CompletedAt: report.CompletedAt,
+	}
+	if report.Status != models.ClientReportStatusReady {
+		return response
+	}
+	// The signed link is dead after ExpiresAt; the coach can generate the report again.
+	if report.ExpiresAt != nil && time.Now().After(*report.ExpiresAt) {
+		response.Status = "expired"
+		return response
+	}
+	response.DownloadURL = report.DownloadURL
+	response.ExpiresAt = report.ExpiresAt
+	return response
+}
+
+func (s *ReportService) getOwnedClient(ctx context.Context, userID, clientID uint) (*models.ClientProfile, error) {
+	coach, err := s.getCoachProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := s.clientRepo.GetByID(ctx, clientID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrClientProfileNotFound
+		}
+		return nil, err
+	}
+	if client.CoachID != coach.ID {
+		return nil, ErrClientProfileForbidden
+	}
+	return client, nil
+}
+
+func (s *ReportService) getCoachProfile(ctx context.Context, userID uint) (*models.CoachProfile, error) {
+	coach, err := s.coachRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCoachProfileNotFound
+		}
+		return nil, err
+	}
+	return coach, nil
+}
+
+// buildEarningsReport emits a row for every month in range, including months with no sessions.
+func buildEarningsReport(
+	startMonth time.Time,
+	endMonth time.Time,
+	counts []repositories.EarningsMonthCounts,
+	amounts []repositories.EarningsMonthAmount,
+	lateCancels []repositories.LateCancelMonthCount,
+	topClients []repositories.EarningsClientTotal,
+) *EarningsReport {
+	countsByMonth := make(map[string]repositories.EarningsMonthCounts, len(counts))
+	for _, row := range counts {
+		countsByMonth[row.Month] = row
+	}
+	lateByMonth := make(map[string]int64, len(lateCancels))
+	for _, row := range lateCancels {
+		lateByMonth[row.Month] = row.LateCancellations
+	}
+
+	grossByMonth := make(map[string][]CurrencyAmount)
+	totalsByCurrency := make(map[string]float64)
+	var currencies []string
+	for _, row := range amounts {
+		grossByMonth[row.Month] = append(grossByMonth[row.Month], CurrencyAmount{
+			Currency: row.Currency,
+			Amount:   roundMoney(row.Amount),
+		})
+		if _, seen := totalsByCurrency[row.Currency]; !seen {
+			currencies = append(currencies, row.Currency)
+		}
+		totalsByCurrency[row.Currency] += row.Amount
+	}
+
+	report := &EarningsReport{
+		StartMonth: startMonth.Format(monthLayout),
+		EndMonth:   endMonth.Format(monthLayout),
+		Months:     []EarningsMonth{},
+		Totals:     []CurrencyAmount{},
+		TopClients: topClients,
+	}
+	if report.TopClients == nil {
+		report.TopClients = []repositories.EarningsClientTotal{}
+	}
+
+	for month := startMonth; !month.After(endMonth); month = month.AddDate(0, 1, 0) {
+		key := month.Format(monthLayout)
+		row := countsByMonth[key]
+		gross := grossByMonth[key]
+		if gross == nil {
+			gross = []CurrencyAmount{}
+		}
+		report.Months = append(report.Months, EarningsMonth{
+			Month:             key,
+			CompletedSessions: row.CompletedSessions,
+			PaidSessions:      row.PaidSessions,
+			UnpaidSessions:    row.UnpaidSessions,
+			UnpricedSessions:  row.UnpricedSessions,
+			LateCancellations: lateByMonth[key],
+			Gross:             gross,
+		})
+	}
+
+	for _, currency := range currencies {
+		report.Totals = append(report.Totals, CurrencyAmount{
+			Currency: currency,
+			Amount:   roundMoney(totalsByCurrency[currency]),
+		})
+	}
+	for i := range report.TopClients {
+		report.TopClients[i].Amount = roundMoney(report.TopClients[i].Amount)
+	}
+
+	return report
+}
+
+// parseMonthRange parses inclusive YYYY-MM bounds, defaulting to the trailing months ending at currentMonth.
+func parseMonthRange(startRaw, endRaw, currentMonth string) (time.Time, time.Time, error) {
+	endRaw = strings.TrimSpace(endRaw)
+	if endRaw == "" {
+		endRaw = currentMonth
+	}
+	endMonth, err := time.Parse(monthLayout, endRaw)
+	if err != nil {
+		return time.Time{}, time.Time{}, ErrInvalidMonthFormat
+	}
+
+	startMonth := endMonth.AddDate(0, -(defaultEarningsMonths - 1), 0)
+	if startRaw = strings.TrimSpace(startRaw); startRaw != "" {
+		startMonth, err = time.Parse(monthLayout, startRaw)
+		if err != nil {
+			return time.Time{}, time.Time{}, ErrInvalidMonthFormat
+		}
+	}
+
+	if startMonth.After(endMonth) {
+		return time.Time{}, time.Time{}, ErrInvalidDateRange
+	}
+	if !startMonth.AddDate(0, maxEarningsMonths, 0).After(endMonth) {
+		return time.Time{}, time.Time{}, ErrInvalidDateRange
+	}
+	return startMonth, endMonth, nil
+}
+
+// parseOptionalExportRange parses optional YYYY-MM-DD bounds; a missing bound is returned as the zero time.
+func parseOptionalExportRange(startRaw, endRaw string) (time.Time, time.Time, error) {
+	var startDate, endDate time.Time
+	var err error
+
+	if strings.TrimSpace(startRaw) != "" {
+		if startDate, err = parseDateOnly(startRaw); err != nil {
+			return time.Time{}, time.Time{}, ErrInvalidDateFormat
+		}
+	}
+	if strings.TrimSpace(endRaw) != "" {
+		if endDate, err = parseDateOnly(endRaw); err != nil {
+			return time.Time{}, time.Time{}, ErrInvalidDateFormat
+		}
+	}
+	if !startDate.IsZero() && !endDate.IsZero() && endDate.Before(startDate) {
+		return time.Time{}, time.Time{}, ErrInvalidDateRange
+	}
+	return startDate, endDate, nil
+}
+
+// csvSafe neutralizes user-entered text that spreadsheet apps would otherwise evaluate as a formula.
+func csvSafe(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+func formatOptionalInt(value *int) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.Itoa(*value)
+}
+
+func formatOptionalFloat(value *float64) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*value, 'f', -1, 64)
+}
+
+func derefString(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+func roundMoney(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+// dateOnlyPrefix keeps YYYY-MM-DD from a date column, which can scan back as a full timestamp.
+func dateOnlyPrefix(value string) string {
+	if len(value) > len(calendarDateLayout) {
+		return value[:len(calendarDateLayout)]
+	}
+	return value
+}
@@ -0,0 +1,155 @@
+package services
+
+import (
+	"chalk-api/pkg/models"
+	"chalk-api/pkg/repositories"
+	"chalk-api/pkg/units"
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Services depend on the narrow slice of repository behaviour they actually use, so they can be
+// unit-tested against the in-memory fakes in pkg/services/fakes. The concrete repositories (and
+// RepositoriesCollection for transactions) satisfy these; production wiring is unchanged.
+
+// transactor runs fn in a single DB transaction with tx-scoped repositories.
+type transactor interface {
+	WithTransaction(ctx context.Context, fn func(tx *gorm.DB, txRepos *repositories.RepositoriesCollection) error) error
+}
+
+type coachProfileReader interface {
+	GetByID(ctx context.Context, id uint) (*models.CoachProfile, error)
+	GetByUserID(ctx context.Context, userID uint) (*models.CoachProfile, error)
+	GetBySlug(ctx context.Context, slug string) (*models.CoachProfile, error)
+	GetPrimaryLocation(ctx context.Context, coachID uint) (*models.CoachLocation, error)
+	GetActiveTeamMembership(ctx context.Context, userID uint) (*models.CoachTeamMember, error)
+}
+
+type clientProfileReader interface {
+	GetByID(ctx context.Context, id uint) (*models.ClientProfile, error)
+	ListByUser(ctx context.Context, userID uint) ([]models.ClientProfile, error)
+}
+
+// unitPreferenceReader looks up the units a viewer wants weights and distances converted into.
+type unitPreferenceReader interface {
+	GetUnitPreferences(ctx context.Context, userID uint) (units.Preferences, error)
+}
+
+// timezoneReader looks up the zone a viewer's session times are shown in.
+type timezoneReader interface {
+	GetTimezone(ctx context.Context, userID uint) (string, error)
+}
+
+// sessionRepository is what SessionService needs outside of transactions.
+type sessionRepository interface {
+	SetAvailability(ctx context.Context, coachID uint, slots []models.CoachAvailability) error
+	GetAvailability(ctx context.Context, coachID uint) ([]models.CoachAvailability, error)
+
+	CreateAvailabilityPreset(ctx context.Context, preset *models.AvailabilityPreset) error
+	ListAvailabilityPresets(ctx context.Context, coachID uint) ([]models.AvailabilityPreset, error)
+	GetAvailabilityPresetByID(ctx context.Context, id uint) (*models.AvailabilityPreset, error)
+	DeleteAvailabilityPreset(ctx context.Context, id uint) error
+
+	CreateOverride(ctx context.Context, override *models.CoachAvailabilityOverride) error
+	ListOverrides(ctx context.Context, coachID uint, startDate, endDate string) ([]models.CoachAvailabilityOverride, error)
+	GetOverrideByID(ctx context.Context, id uint) (*models.CoachAvailabilityOverride, error)
+	DeleteOverride(ctx context.Context, id uint) error
+
+	CreateSessionType(ctx context.Context, st *models.SessionType) error
+	ListSessionTypes(ctx context.Context, coachID uint) ([]models.SessionType, error)
+	ListClientBookableSessionTypes(ctx context.Context, coachID uint) ([]models.SessionType, error)
+	ReorderSessionTypes(ctx context.Context, coachID uint, orderedIDs []uint) error
+	GetSessionTypeByID(ctx context.Context, id uint) (*models.SessionType, error)
+	UpdateSessionType(ctx context.Context, st *models.SessionType) error
+
+	GetSession(ctx context.Context, id uint) (*models.Session, error)
+	ListSessions(ctx context.Context, coachID, clientID uint, startDate, endDate time.Time, filter repositories.SessionStatusFilter) ([]models.Session, error)
+	ListSessionsByClients(ctx context.Context, clientIDs []uint, startDate, endDate time.Time, filter repositories.SessionStatusFilter) ([]models.Session, error)
+	CompleteSession(ctx context.Context, id uint) error
+	MarkNoShow(ctx context.Context, id uint) error
+	RecordCheckIn(ctx context.Context, session *models.Session) (bool, error)
+	MarkPaid(ctx context.Context, id uint, paidAt time.Time) error
+	HasCoachConflict(ctx context.Context, coachID uint, startAt, endAt time.Time, excludeSessionID *uint) (bool, error)
+	HasClientConflict(ctx context.Context, clientUserID uint, startAt, endAt time.Time, excludeSessionID *uint) (*repositories.ClientBusyTime, error)
+	ListClientBusyTimes(ctx context.Context, clientUserID uint, startAt, endAt time.Time) ([]repositories.ClientBusyTime, error)
+	ListExpiredPendingSessions(ctx context.Context, createdBefore, now time.Time, limit int) ([]models.Session, error)
+	ListStaleSessions(ctx context.Context, endedBefore time.Time, afterID uint, limit int) ([]models.Session, error)
+	ResolveStaleSession(ctx context.Context, id uint, status string) (bool, error)
+
+	CreateTimeBlock(ctx context.Context, block *models.CoachTimeBlock) error
+	GetTimeBlockByID(ctx context.Context, id uint) (*models.CoachTimeBlock, error)
+	ListTimeBlocks(ctx context.Context, coachID uint, startAt, endAt time.Time) ([]models.CoachTimeBlock, error)
+	DeleteTimeBlock(ctx context.Context, id uint) error
+
+	ListCoachFeedback(ctx context.Context, coachID uint, limit, offset int) ([]repositories.SessionFeedbackEntry, int64, error)
+
+	CountSessionsByStatusAndType(ctx context.Context, coachID uint, start, end time.Time) ([]repositories.SessionStatusTypeCount, error)
+	CountLateCancellations(ctx context.Context, coachID uint, start, end time.Time) (int64, error)
+}
+
+type templateRepository interface {
+	Create(ctx context.Context, template *models.WorkoutTemplate) error
+	GetByID(ctx context.Context, id uint) (*models.WorkoutTemplate, error)
+	ListByCoach(ctx context.Context, coachID uint, filter repositories.TemplateListFilter, limit, offset int) ([]models.WorkoutTemplate, int64, error)
+	CountByCategory(ctx context.Context, coachID uint) ([]repositories.TemplateCategoryCount, error)
+	Update(ctx context.Context, template *models.WorkoutTemplate) error
+	ReplaceExercises(ctx context.Context, templateID uint, exercises []models.WorkoutTemplateExercise) error
+	UpdateMetrics(ctx context.Context, id uint, estimatedMinutes *int, metrics *models.WorkoutMetrics) error
+	CreateVersion(ctx context.Context, version *models.TemplateVersion, keep int) error
+	CountVersions(ctx context.Context, templateID uint) (int64, error)
+	ListVersions(ctx context.Context, templateID uint) ([]models.TemplateVersion, error)
+	GetVersion(ctx context.Context, templateID uint, version int) (*models.TemplateVersion, error)
+
+	CreateCategory(ctx context.Context, category *models.TemplateCategory) error
+	GetCategory(ctx context.Context, id uint) (*models.TemplateCategory, error)
+	FindCategoryByName(ctx context.Context, coachID uint, name string) (*models.TemplateCategory, error)
+	ListCategories(ctx context.Context, coachID uint) ([]models.TemplateCategory, error)
+	RenameCategory(ctx context.Context, id uint, name string) error
+	DeleteCategory(ctx context.Context, id uint) error
+}
+
+// exerciseRepository is the exercise library access WorkoutService needs for substitutions.
+type exerciseRepository interface {
+	GetByID(ctx context.Context, id uint) (*models.Exercise, error)
+	ListByIDs(ctx context.Context, ids []uint) ([]models.Exercise, error)
+	UpsertAlternatives(ctx context.Context, alternatives []models.ExerciseAlternative) error
+	ListAlternatives(ctx context.Context, exerciseIDs []uint) ([]models.ExerciseAlternative, error)
+	DeleteAlternative(ctx context.Context, exerciseID, alternativeExerciseID uint) error
+}
+
+// workoutRepository is what WorkoutService needs outside of transactions.
+type workoutRepository interface {
+	GetByID(ctx context.Context, id uint) (*models.Workout, error)
+	FindActiveOnDate(ctx context.Context, clientID uint, date string) (*models.Workout, error)
+	ListByClients(ctx context.Context, clientIDs []uint, limit, offset int) ([]models.Workout, int64, error)
+	StartWorkout(ctx context.Context, id uint) error
+	UpdateSessionState(ctx context.Context, id uint, state *models.WorkoutSessionState) (bool, error)
+
+	GetExerciseByID(ctx context.Context, id uint) (*models.WorkoutExercise, error)
+	MarkExerciseCompleted(ctx context.Context, id uint) error
+	SkipExercise(ctx context.Context, id uint, reason string) error
+
+	CreateLog(ctx context.Context, log *models.WorkoutLog) error
+	UpdateLog(ctx context.Context, log *models.WorkoutLog) error
+	GetLogByID(ctx context.Context, id uint) (*models.WorkoutLog, error)
+	ListUnreviewedFormChecks(ctx context.Context, coachID uint, limit, offset int) ([]repositories.FormCheck, int64, error)
+
+	UpsertClientExerciseDefault(ctx context.Context, exerciseDefault *models.ClientExerciseDefault) error
+	ListClientExerciseDefaults(ctx context.Context, clientProfileID uint) ([]models.ClientExerciseDefault, error)
+	GetClientExerciseDefaults(ctx context.Context, clientProfileID uint, exerciseIDs []uint) (map[uint]models.ClientExerciseDefault, error)
+	DeleteClientExerciseDefault(ctx context.Context, clientProfileID, exerciseID uint) (bool, error)
+}
+
+var (
+	_ transactor           = (*repositories.RepositoriesCollection)(nil)
+	_ coachProfileReader   = (*repositories.CoachRepository)(nil)
+	_ clientProfileReader  = (*repositories.ClientRepository)(nil)
+	_ unitPreferenceReader = (*repositories.UserRepository)(nil)
+	_ timezoneReader       = (*repositories.UserRepository)(nil)
+	_ sessionRepository    = (*repositories.SessionRepository)(nil)
+	_ templateRepository   = (*repositories.TemplateRepository)(nil)
+	_ exerciseRepository   = (*repositories.ExerciseRepository)(nil)
+	_ workoutRepository    = (*repositories.WorkoutRepository)(nil)
+)
@@ -0,0 +1,181 @@
+package services
+
+import (
+	"chalk-api/pkg/models"
+	"chalk-api/pkg/repositories"
+	"context"
+	"log/slog"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+const (
+	defaultRequestAnalyticsBufferSize = 5000
+
+	// requestEventRetention is how long request events are kept for support lookups
+	requestEventRetention = 7 * 24 * time.Hour
+
+	// The sink stops recording for this long when the database can't keep up
+	requestAnalyticsPauseFor = time.Minute
+	// A flush slower than this means writes are backing up
+	requestAnalyticsSlowFlush = 2 * time.Second
+)
+
+type RequestAnalyticsConfig struct {
+	Enabled bool
+	// SampleRate is the fraction of requests recorded; values outside (0, 1] record every request
+	SampleRate float64
+	// BufferSize caps how many events are held between flushes
+	BufferSize int
+}
+
+// RequestAnalytics buffers per-user request events in memory and writes them to request_events in
+// batches. Record never blocks on the database: the buffer is a fixed-size ring, and the sink
+// pauses itself for a minute whenever the ring overflows, a flush fails or is slow, or the
+// connection pool is saturated. A nil or disabled sink records nothing.
+type RequestAnalytics struct {
+	repos      *repositories.RepositoriesCollection
+	enabled    bool
+	sampleRate float64
+
+	mu          sync.Mutex
+	ring        []models.RequestEvent
+	head        int
+	size        int
+	pausedUntil time.Time
+	dropped     int64
+}
+
+func NewRequestAnalytics(repos *repositories.RepositoriesCollection, cfg RequestAnalyticsConfig) *RequestAnalytics {
+	if cfg.BufferSize <= 0 {
+		cfg.BufferSize = defaultRequestAnalyticsBufferSize
+	}
+	if cfg.SampleRate <= 0 || cfg.SampleRate > 1 {
+		cfg.SampleRate = 1
+	}
+
+	a := &RequestAnalytics{
+		repos:      repos,
+		enabled:    cfg.Enabled,
+		sampleRate: cfg.SampleRate,
+	}
+	if cfg.Enabled {
+		a.ring = make([]models.RequestEvent, cfg.BufferSize)
+	}
+	return a
+}
+
+// Enabled reports whether the sink was turned on in config.
+func (a *RequestAnalytics) Enabled() bool {
+	return a != nil && a.enabled
+}
+
+// Record queues one request event. It is cheap and safe to call from the request path.
+func (a *RequestAnalytics) Record(event models.RequestEvent) {
+	if !a.Enabled() {
+		return
+	}
+	if a.sampleRate < 1 && rand.Float64() >= a.sampleRate {
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if event.CreatedAt.Before(a.pausedUntil) {
+		a.dropped++
+		return
+	}
+
+	if a.size == len(a.ring) {
+		// Full ring: overwrite the oldest event and back off until the next flush catches up
+		a.ring[a.head] = event
+		a.head = (a.head + 1) % len(a.ring)
+		a.dropped++
+		a.pauseLocked(event.CreatedAt, "buffer full")
+		return
+	}
+
+	a.ring[(a.head+a.size)%len(a.ring)] = event
+	a.size++
+}
+
+// Flush writes every buffered event to the database and returns how many were written.
+func (a *RequestAnalytics) Flush(ctx context.Context) (int, error) {
+	if !a.Enabled() {
+		return 0, nil
+	}
+
+	batch := a.drain()
+	if len(batch) == 0 {
+		return 0, nil
+	}
+
+	if stats, err := a.repos.PoolStats(); err == nil && stats.MaxOpenConnections > 0 && stats.InUse >= stats.MaxOpenConnections {
+		a.discard(len(batch), "connection pool saturated")
+		return 0, nil
+	}
+
+	started := time.Now()
+	if err := a.repos.RequestEvent.CreateBatch(ctx, batch); err != nil {
+		a.discard(len(batch), "flush failed")
+		return 0, err
+	}
+	if elapsed := time.Since(started); elapsed > requestAnalyticsSlowFlush {
+		a.mu.Lock()
+		a.pauseLocked(time.Now().UTC(), "slow flush")
+		a.mu.Unlock()
+	}
+
+	return len(batch), nil
+}
+
+// Dropped returns how many events were dropped under backpressure since startup.
+func (a *RequestAnalytics) Dropped() int64 {
+	if a == nil {
+		return 0
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.dropped
+}
+
+// PruneExpired deletes request events past the 7-day retention window. It runs even when the
+// sink is disabled so rows from a previous enablement still age out.
+func (a *RequestAnalytics) PruneExpired(ctx context.Context, now time.Time) (int64, error) {
+	return a.repos.RequestEvent.DeleteOlderThan(ctx, now.Add(-requestEventRetention))
+}
+
+func (a *RequestAnalytics) drain() []models.RequestEvent {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.size == 0 {
+		return nil
+	}
+
+	batch := make([]models.RequestEvent, 0, a.size)
+	for i := 0; i < a.size; i++ {
+		batch = append(batch, a.ring[(a.head+i)%len(a.ring)])
+	}
+	a.head = 0
+	a.size = 0
+	return batch
+}
+
+func (a *RequestAnalytics) discard(count int, reason string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.dropped += int64(count)
+	a.pauseLocked(time.Now().UTC(), reason)
+}
+
+// pauseLocked must be called with mu held.
+func (a *RequestAnalytics) pauseLocked(now time.Time, reason string) {
+	if now.Before(a.pausedUntil) {
+		return
+	}
+	a.pausedUntil = now.Add(requestAnalyticsPauseFor)
+	slog.Warn("Request analytics paused", "reason", reason, "until", a.pausedUntil, "dropped_total", a.dropped)
+}
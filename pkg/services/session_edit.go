@@ -0,0 +1,178 @@
+package services
+
+import (
+	"chalk-api/pkg/events"
+	"chalk-api/pkg/models"
+	"chalk-api/pkg/repositories"
+	"chalk-api/pkg/utils"
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrSessionUpdateInvalid = errors.New("session update needs a location or notes")
+	ErrSessionNotInSeries   = errors.New("session is not part of a recurring series")
+)
+
+// Scopes of a session edit
+const (
+	SessionEditScopeThisOnly      = "this_only"
+	SessionEditScopeThisAndFuture = "this_and_future"
+)
+
+// UpdateSessionInput changes a session's location and notes; an empty string clears a field. Scope
+// defaults to this_only. this_and_future is for the coach only and also edits every later scheduled
+// session of the same recurring series.
+type UpdateSessionInput struct {
+	Location *string `json:"location" binding:"omitempty,max=255"`
+	Notes    *string `json:"notes" binding:"omitempty,max=2000"`
+	Scope    string  `json:"scope" binding:"omitempty,oneof=this_only this_and_future"`
+}
+
+// SessionUpdateResult is the edited session and how many sessions the edit changed, itself included.
+type SessionUpdateResult struct {
+	Session      *models.Session
+	UpdatedCount int64
+}
+
+// UpdateSession edits the location and notes of a session the caller coaches or is booked into.
+// Clients can only edit their own one-on-one sessions. Coach edits notify the booked clients with one
+// session.updated event per changed session, all sharing a change ID so each client gets one push.
+func (s *SessionService) UpdateSession(ctx context.Context, userID, sessionID uint, input UpdateSessionInput) (*SessionUpdateResult, error) {
+	if input.Location == nil && input.Notes == nil {
+		return nil, ErrSessionUpdateInvalid
+	}
+	details := repositories.SessionDetails{
+		Location: trimSessionField(input.Location),
+		Notes:    trimSessionField(input.Notes),
+	}
+	scope := input.Scope
+	if scope == "" {
+		scope = SessionEditScopeThisOnly
+	}
+
+	session, actor, err := s.getSessionForUser(ctx, userID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if actor != "coach" && (scope == SessionEditScopeThisAndFuture || session.MaxParticipants > 1) {
+		return nil, ErrSessionActionForbidden
+	}
+	if session.Status != "scheduled" && session.Status != models.SessionStatusPendingConfirmation {
+		return nil, ErrSessionStateInvalid
+	}
+	if scope == SessionEditScopeThisAndFuture {
+		if session.RecurrenceGroupID == nil {
+			return nil, ErrSessionNotInSeries
+		}
+		if session.Status != "scheduled" {
+			return nil, ErrSessionStateInvalid
+		}
+	}
+
+	var updated int64
+	if err := s.repos.WithTransaction(ctx, func(tx *gorm.DB, txRepos *repositories.RepositoriesCollection) error {
+		targets := []models.Session{*session}
+		if scope == SessionEditScopeThisAndFuture {
+			targets, err = txRepos.Session.ListUpcomingSeriesSessions(ctx, *session.RecurrenceGroupID, session.ScheduledAt)
+			if err != nil {
+				return err
+			}
+		}
+		ids := make([]uint, 0, len(targets))
+		for _, target := range targets {
+			ids = append(ids, target.ID)
+		}
+
+		updated, err = txRepos.Session.UpdateSessionDetails(ctx, ids, details)
+		if err != nil {
+			return err
+		}
+		if actor != "coach" || s.events == nil {
+			return nil
+		}
+		return s.publishSessionUpdates(ctx, tx, targets, details)
+	}); err != nil {
+		return nil, err
+	}
+
+	edited, err := s.sessionRepo.GetSession(ctx, session.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &SessionUpdateResult{Session: edited, UpdatedCount: updated}, nil
+}
+
+// publishSessionUpdates writes one session.updated event per session with booked clients. The first
+// of them carries every client of the edit so the handler pushes each client once.
+func (s *SessionService) publishSessionUpdates(ctx context.Context, tx *gorm.DB, sessions []models.Session, details repositories.SessionDetails) error {
+	changeID, err := utils.GenerateRandomString(16)
+	if err != nil {
+		return err
+	}
+	var fields []string
+	if details.Location != nil {
+		fields = append(fields, "location")
+	}
+	if details.Notes != nil {
+		fields = append(fields, "notes")
+	}
+
+	seen := make(map[uint]bool)
+	var pushUserIDs []uint
+	for i := range sessions {
+		for _, userID := range bookedParticipantUserIDs(&sessions[i]) {
+			if !seen[userID] {
+				seen[userID] = true
+				pushUserIDs = append(pushUserIDs, userID)
+			}
+		}
+	}
+
+	for i := range sessions {
+		session := &sessions[i]
+		participants := bookedParticipantUserIDs(session)
+		if len(participants) == 0 {
+			continue
+		}
+		payload := events.SessionUpdatedPayload{
+			SessionID:          session.ID,
+			CoachID:            session.CoachID,
+			ScheduledAt:        session.ScheduledAt,
+			ChangedFields:      fields,
+			Location:           details.Location,
+			ChangeID:           changeID,
+			ChangeCount:        len(sessions),
+			ParticipantUserIDs: participants,
+			PushUserIDs:        pushUserIDs,
+		}
+		pushUserIDs = nil
+
+		id := strconv.FormatUint(uint64(session.ID), 10)
+		if err := s.events.PublishInTx(
+			ctx,
+			tx,
+			events.EventTypeSessionUpdated,
+			"session",
+			id,
+			events.BuildIdempotencyKey(events.EventTypeSessionUpdated, changeID, id),
+			payload,
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// trimSessionField trims an edited field, keeping "" so the caller can clear it.
+func trimSessionField(value *string) *string {
+	if value == nil {
+		return nil
+	}
+	trimmed := strings.TrimSpace(*value)
+	return &trimmed
+}
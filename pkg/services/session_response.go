@@ -0,0 +1,205 @@
+package services
+
+import (
+	"chalk-api/pkg/models"
+	"chalk-api/pkg/utils"
+	"context"
+	"time"
+)
+
+// SessionResponse is how a session is shown to its coach or one of its clients. Times are UTC, with
+// ScheduledAtLocal, Timezone and DayKey giving the start in the viewer's profile timezone so apps can
+// group and label sessions without converting themselves. Coach and client are reduced to what a
+// session screen shows; participants are only listed for the coach.
+type SessionResponse struct {
+	ID            uint `json:"id"`
+	CoachID       uint `json:"coach_id"`
+	ClientID      uint `json:"client_id"`
+	SessionTypeID uint `json:"session_type_id"`
+
+	ScheduledAt      time.Time `json:"scheduled_at"`       // UTC
+	ScheduledAtLocal string    `json:"scheduled_at_local"` // RFC 3339 with the viewer's offset
+	Timezone         string    `json:"timezone"`           // IANA name the local fields use
+	DayKey           string    `json:"day_key"`            // YYYY-MM-DD of the start in Timezone
+	DurationMinutes  int       `json:"duration_minutes"`
+
+	Status            string  `json:"status"`
+	Location          *string `json:"location"`
+	Notes             *string `json:"notes"`
+	RecurrenceGroupID *string `json:"recurrence_group_id"`
+
+	CancelledAt        *time.Time `json:"cancelled_at"`
+	CancelledBy        *string    `json:"cancelled_by"`
+	CancellationReason *string    `json:"cancellation_reason"`
+	LateCancelled      bool       `json:"late_cancelled"`
+	CompletedAt        *time.Time `json:"completed_at"`
+
+	CheckedInAt           *time.Time `json:"checked_in_at"`
+	CheckInLatitude       *float64   `json:"check_in_latitude"`
+	CheckInLongitude      *float64   `json:"check_in_longitude"`
+	CheckInDistanceMeters *float64   `json:"check_in_distance_meters"`
+	CheckInOutsideRadius  bool       `json:"check_in_outside_radius"`
+	StalePromptedAt       *time.Time `json:"stale_prompted_at,omitempty"`
+
+	MaxParticipants  int `json:"max_participants"`
+	ParticipantCount int `json:"participant_count"`
+
+	Price         *float64   `json:"price"`
+	PriceCurrency *string    `json:"price_currency"`
+	PaidAt        *time.Time `json:"paid_at"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	Coach        *SessionCoach                `json:"coach,omitempty"`
+	Client       *SessionClient               `json:"client,omitempty"` // omitted for other members of a group session
+	SessionType  *models.SessionType          `json:"session_type,omitempty"`
+	Participants []SessionParticipantResponse `json:"participants,omitempty"`
+
+	// Only set on booking, e.g. when a coach books a client who is busy elsewhere at that time
+	Warnings []SessionWarning `json:"warnings,omitempty"`
+}
+
+// SessionCoach is the coach as shown on a session.
+type SessionCoach struct {
+	ID           uint    `json:"id"` // coach profile ID
+	UserID       uint    `json:"user_id"`
+	BusinessName *string `json:"business_name"`
+	FirstName    string  `json:"first_name"`
+	LastName     string  `json:"last_name"`
+	AvatarURL    *string `json:"avatar_url"`
+}
+
+// SessionClient is a client as shown on a session.
+type SessionClient struct {
+	ID        uint    `json:"id"` // client profile ID
+	UserID    uint    `json:"user_id"`
+	FirstName string  `json:"first_name"`
+	LastName  string  `json:"last_name"`
+	AvatarURL *string `json:"avatar_url"`
+}
+
+// SessionParticipantResponse is one client's place in a group session.
+type SessionParticipantResponse struct {
+	ID                 uint           `json:"id"`
+	ClientID           uint           `json:"client_id"`
+	Status             string         `json:"status"`
+	CancelledAt        *time.Time     `json:"cancelled_at"`
+	CancellationReason *string        `json:"cancellation_reason"`
+	LateCancelled      bool           `json:"late_cancelled"`
+	Client             *SessionClient `json:"client,omitempty"`
+}
+
+// SessionResponseFor renders session for userID in their profile timezone.
+func (s *SessionService) SessionResponseFor(ctx context.Context, userID uint, session *models.Session) (*SessionResponse, error) {
+	timezone, err := s.userRepo.GetTimezone(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	response := toSessionResponse(session, userID, utils.ResolveLocation(timezone))
+	return &response, nil
+}
+
+// SessionResponsesFor renders a list of sessions for userID, looking their timezone up once.
+func (s *SessionService) SessionResponsesFor(ctx context.Context, userID uint, sessions []models.Session) ([]SessionResponse, error) {
+	timezone, err := s.userRepo.GetTimezone(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	loc := utils.ResolveLocation(timezone)
+	responses := make([]SessionResponse, 0, len(sessions))
+	for i := range sessions {
+		responses = append(responses, toSessionResponse(&sessions[i], userID, loc))
+	}
+	return responses, nil
+}
+
+func toSessionResponse(session *models.Session, viewerID uint, loc *time.Location) SessionResponse {
+	local := session.ScheduledAt.In(loc)
+	response := SessionResponse{
+		ID:                    session.ID,
+		CoachID:               session.CoachID,
+		ClientID:              session.ClientID,
+		SessionTypeID:         session.SessionTypeID,
+		ScheduledAt:           session.ScheduledAt.UTC(),
+		ScheduledAtLocal:      local.Format(time.RFC3339),
+		Timezone:              loc.String(),
+		DayKey:                local.Format(calendarDateLayout),
+		DurationMinutes:       session.DurationMinutes,
+		Status:                session.Status,
+		Location:              session.Location,
+		Notes:                 session.Notes,
+		RecurrenceGroupID:     session.RecurrenceGroupID,
+		CancelledAt:           session.CancelledAt,
+		CancelledBy:           session.CancelledBy,
+		CancellationReason:    session.CancellationReason,
+		LateCancelled:         session.LateCancelled,
+		CompletedAt:           session.CompletedAt,
+		CheckedInAt:           session.CheckedInAt,
+		CheckInLatitude:       session.CheckInLatitude,
+		CheckInLongitude:      session.CheckInLongitude,
+		CheckInDistanceMeters: session.CheckInDistanceMeters,
+		CheckInOutsideRadius:  session.CheckInOutsideRadius,
+		MaxParticipants:       session.MaxParticipants,
+		ParticipantCount:      session.ParticipantCount,
+		Price:                 session.Price,
+		PriceCurrency:         session.PriceCurrency,
+		PaidAt:                session.PaidAt,
+		CreatedAt:             session.CreatedAt,
+		UpdatedAt:             session.UpdatedAt,
+	}
+
+	isCoach := session.Coach.ID != 0 && session.Coach.UserID == viewerID
+	if isCoach {
+		response.StalePromptedAt = session.StalePromptedAt
+	}
+	if session.Coach.ID != 0 {
+		coach := &SessionCoach{
+			ID:           session.Coach.ID,
+			UserID:       session.Coach.UserID,
+			BusinessName: session.Coach.BusinessName,
+		}
+		if profile := session.Coach.User.Profile; profile != nil {
+			coach.FirstName = profile.FirstName
+			coach.LastName = profile.LastName
+			coach.AvatarURL = profile.AvatarURL
+		}
+		response.Coach = coach
+	}
+	if session.Client.ID != 0 && (isCoach || session.Client.UserID == viewerID) {
+		response.Client = toSessionClient(&session.Client)
+	}
+	if session.SessionType.ID != 0 {
+		sessionType := session.SessionType
+		response.SessionType = &sessionType
+	}
+	if isCoach && len(session.Participants) > 0 {
+		response.Participants = make([]SessionParticipantResponse, 0, len(session.Participants))
+		for i := range session.Participants {
+			participant := &session.Participants[i]
+			entry := SessionParticipantResponse{
+				ID:                 participant.ID,
+				ClientID:           participant.ClientID,
+				Status:             participant.Status,
+				CancelledAt:        participant.CancelledAt,
+				CancellationReason: participant.CancellationReason,
+				LateCancelled:      participant.LateCancelled,
+			}
+			if participant.Client.ID != 0 {
+				entry.Client = toSessionClient(&participant.Client)
+			}
+			response.Participants = append(response.Participants, entry)
+		}
+	}
+	return response
+}
+
+func toSessionClient(client *models.ClientProfile) *SessionClient {
+	summary := &SessionClient{ID: client.ID, UserID: client.UserID}
+	if profile := client.User.Profile; profile != nil {
+		summary.FirstName = profile.FirstName
+		summary.LastName = profile.LastName
+		summary.AvatarURL = profile.AvatarURL
+	}
+	return summary
+}
@@ -1,16 +1,22 @@
 package services
 
 import (
+	"chalk-api/pkg/calendar"
 	"chalk-api/pkg/events"
 	"chalk-api/pkg/models"
 	"chalk-api/pkg/repositories"
+	"chalk-api/pkg/stores"
+	"chalk-api/pkg/utils"
 	"context"
 	"errors"
+	"fmt"
+	"math"
 	"sort"
 	"strconv"
 	"strings"
 	"time"
 
+	"golang.org/x/sync/singleflight"
 	"gorm.io/gorm"
 )
 
@@ -19,6 +25,9 @@ var (
 	ErrSessionTypeNotFound     = errors.New("session type not found")
 	ErrSessionTypeForbidden    = errors.New("session type does not belong to this coach")
 	ErrSessionTypeInactive     = errors.New("session type is inactive")
+	ErrSessionTypeNotBookable  = errors.New("session type can only be booked by the coach")
+	ErrSelfBookingDisabled     = errors.New("coach has turned off self-booking for this client")
+	ErrSessionTypeOrderInvalid = errors.New("session type order must list every active session type once")
 	ErrSessionNotFound         = errors.New("session not found")
 	ErrSessionForbidden        = errors.New("session does not belong to this user")
 	ErrSessionActionForbidden  = errors.New("session action is not allowed for this user")
@@ -26,67 +35,188 @@ var (
 	ErrSessionConflict         = errors.New("requested time conflicts with an existing session")
 	ErrOutsideAvailability     = errors.New("requested time is outside coach availability")
 	ErrAvailabilitySlotInvalid = errors.New("invalid availability slot")
+	ErrPresetInvalid           = errors.New("availability preset name is required")
+	ErrPresetNotFound          = errors.New("availability preset not found")
+	ErrPresetForbidden         = errors.New("availability preset does not belong to this coach")
+	ErrPresetNameTaken         = errors.New("availability preset name already in use")
+	ErrPresetLimit             = errors.New("availability preset limit reached")
 	ErrOverrideNotFound        = errors.New("availability override not found")
 	ErrOverrideForbidden       = errors.New("availability override does not belong to this coach")
 	ErrInvalidDateRange        = errors.New("invalid date range")
 	ErrInvalidDateFormat       = errors.New("invalid date format, expected YYYY-MM-DD")
 	ErrInvalidScheduledAt      = errors.New("invalid scheduled_at, expected RFC3339 datetime")
 	ErrInvalidSessionDuration  = errors.New("invalid session duration")
+	ErrSessionUnpriced         = errors.New("session has no price")
+	ErrSessionFull             = errors.New("group session is full")
+	ErrSessionAlreadyJoined    = errors.New("client is already booked into this session")
+	ErrInvalidMaxParticipants  = errors.New("invalid max participants")
+	ErrCheckInForbidden        = errors.New("only a booked client can check in")
+	ErrCheckInWindowClosed     = errors.New("check-in is only open around the scheduled time")
+	ErrInvalidCheckInLocation  = errors.New("invalid check-in location")
+	ErrSessionCheckedIn        = errors.New("client checked in to this session")
+	ErrTimeBlockInvalid        = errors.New("invalid time block")
+	ErrTimeBlockNotFound       = errors.New("time block not found")
+	ErrTimeBlockForbidden      = errors.New("time block does not belong to this coach")
+	ErrFeedbackForbidden       = errors.New("only the session's client can leave feedback")
+	ErrFeedbackWindowClosed    = errors.New("feedback window has closed")
+	ErrFeedbackExists          = errors.New("feedback already submitted for this session")
+	ErrInvalidSessionStatus    = errors.New("invalid session status filter")
 )
 
+// sessionStatuses lists every session status, in lifecycle order.
+var sessionStatuses = []string{models.SessionStatusPendingConfirmation, "scheduled", "completed", "cancelled", "no_show"}
+
 const (
 	defaultBookableRangeDays = 14
+	nextAvailableSearchDays  = 30
 	defaultListRangeDays     = 30
 	maxRangeDays             = 90
 	slotStepMinutes          = 15
+	maxGroupParticipants     = 50
+	checkInWindow            = 30 * time.Minute
+	defaultCheckInRadius     = 200 // meters
+	maxTimeBlockDays         = 7   // longer absences belong in availability overrides
+	feedbackWindow           = 14 * 24 * time.Hour
+	maxAvailabilityPresets   = 20
+
+	// How far ahead availability changes are checked against booked sessions
+	affectedSessionsHorizonDays = 365
 )
 
 type AvailabilitySlotInput struct {
-	DayOfWeek int    `json:"day_of_week" binding:"required"`
-	StartTime string `json:"start_time" binding:"required"`
-	EndTime   string `json:"end_time" binding:"required"`
+	DayOfWeek int    `json:"day_of_week" binding:"min=0,max=6"`
+	StartTime string `json:"start_time" binding:"required,hhmm"`
+	EndTime   string `json:"end_time" binding:"required,hhmm"`
 	IsActive  *bool  `json:"is_active"`
 }
 
 type SetAvailabilityInput struct {
-	Slots []AvailabilitySlotInput `json:"slots"`
+	Slots []AvailabilitySlotInput `json:"slots" binding:"dive"`
+}
+
+// CopyAvailabilityDayInput replaces each target day's slots with the source day's active slots.
+type CopyAvailabilityDayInput struct {
+	SourceDayOfWeek *int  `json:"source_day_of_week" binding:"required,min=0,max=6"`
+	TargetDays      []int `json:"target_days" binding:"required,min=1,max=7,dive,min=0,max=6"`
+}
+
+type CreateAvailabilityPresetInput struct {
+	Name string `json:"name" binding:"required,max=100"`
 }
 
 type CreateAvailabilityOverrideInput struct {
-	Date        string  `json:"date" binding:"required"`
+	Date        string  `json:"date" binding:"required,date"`
 	IsAvailable bool    `json:"is_available"`
-	StartTime   *string `json:"start_time"`
-	EndTime     *string `json:"end_time"`
+	StartTime   *string `json:"start_time" binding:"omitempty,hhmm"`
+	EndTime     *string `json:"end_time" binding:"omitempty,hhmm"`
 	Reason      *string `json:"reason"`
 }
 
+// AffectedSession is an upcoming booking that an availability change leaves outside the coach's
+// windows. The change is still applied; the coach decides whether to move or cancel the session.
+type AffectedSession struct {
+	SessionID       uint      `json:"session_id"`
+	ClientID        uint      `json:"client_id"`
+	ClientName      string    `json:"client_name"`
+	ScheduledAt     time.Time `json:"scheduled_at"`
+	DurationMinutes int       `json:"duration_minutes"`
+	Status          string    `json:"status"`
+}
+
+// AvailabilityUpdate is the weekly schedule after SetMyAvailability (or the proposed one on a dry
+// run) with the bookings it leaves outside availability.
+type AvailabilityUpdate struct {
+	Slots            []models.CoachAvailability
+	AffectedSessions []AffectedSession
+}
+
+// AvailabilityOverrideResult is a created (or, on a dry run, proposed) override with the bookings
+// it leaves outside availability.
+type AvailabilityOverrideResult struct {
+	*models.CoachAvailabilityOverride
+	AffectedSessions []AffectedSession `json:"affected_sessions"`
+}
+
 type CreateSessionTypeInput struct {
-	Name            string  `json:"name" binding:"required"`
-	DurationMinutes int     `json:"duration_minutes" binding:"required"`
-	Description     *string `json:"description"`
-	Color           *string `json:"color"`
+	Name            string   `json:"name" binding:"required"`
+	DurationMinutes int      `json:"duration_minutes" binding:"required"`
+	Description     *string  `json:"description"`
+	Color           *string  `json:"color"`
+	Price           *float64 `json:"price" binding:"omitempty,min=0"`
+	PriceCurrency   *string  `json:"price_currency" binding:"omitempty,iso4217"`
+	MaxParticipants *int     `json:"max_participants"`
+	// Defaults to true; false makes this a coach-only type.
+	BookableByClient *bool `json:"bookable_by_client"`
+	// Client bookings wait for the coach to confirm them.
+	RequiresConfirmation bool `json:"requires_confirmation"`
 }
 
 type UpdateSessionTypeInput struct {
-	Name            *string `json:"name"`
-	DurationMinutes *int    `json:"duration_minutes"`
-	Description     *string `json:"description"`
-	Color           *string `json:"color"`
-	IsActive        *bool   `json:"is_active"`
+	Name             *string  `json:"name"`
+	DurationMinutes  *int     `json:"duration_minutes"`
+	Description      *string  `json:"description"`
+	Color            *string  `json:"color"`
+	IsActive         *bool    `json:"is_active"`
+	Price            *float64 `json:"price" binding:"omitempty,min=0"`
+	PriceCurrency    *string  `json:"price_currency" binding:"omitempty,iso4217"`
+	MaxParticipants  *int     `json:"max_participants"`
+	BookableByClient *bool    `json:"bookable_by_client"`
+	// Existing pending bookings stay pending when this is turned off.
+	RequiresConfirmation *bool `json:"requires_confirmation"`
+}
+
+// ReorderSessionTypesInput lists every active session type ID in the order clients should see them.
+type ReorderSessionTypesInput struct {
+	SessionTypeIDs []uint `json:"session_type_ids" binding:"required"`
 }
 
 type BookSessionInput struct {
 	ClientProfileID uint    `json:"client_profile_id" binding:"required"`
 	SessionTypeID   uint    `json:"session_type_id" binding:"required"`
-	ScheduledAt     string  `json:"scheduled_at" binding:"required"` // RFC3339, converted to UTC
+	ScheduledAt     string  `json:"scheduled_at" binding:"required,rfc3339"` // converted to UTC
 	Location        *string `json:"location"`
 	Notes           *string `json:"notes"`
+
+	// SkipSuggestedSlots leaves alternative slots off conflict errors; set from the suggest_slots query parameter
+	SkipSuggestedSlots bool `json:"-"`
 }
 
 type CancelSessionInput struct {
 	Reason *string `json:"reason"`
 }
 
+type DeclineSessionInput struct {
+	Reason *string `json:"reason" binding:"omitempty,max=500"`
+}
+
+type CreateTimeBlockInput struct {
+	StartAt string  `json:"start_at" binding:"required,rfc3339"` // converted to UTC
+	EndAt   string  `json:"end_at" binding:"required,rfc3339"`
+	Label   *string `json:"label"`
+}
+
+// CheckInSessionInput optionally carries the client's position; send both or neither.
+type CheckInSessionInput struct {
+	Latitude  *float64 `json:"latitude" binding:"omitempty,min=-90,max=90"`
+	Longitude *float64 `json:"longitude" binding:"omitempty,min=-180,max=180"`
+}
+
+// ResolveStaleSessionsInput records outcomes for sessions the coach was prompted about.
+type ResolveStaleSessionsInput struct {
+	Sessions []StaleSessionOutcome `json:"sessions" binding:"required,min=1,max=100,dive"`
+}
+
+type StaleSessionOutcome struct {
+	SessionID uint   `json:"session_id" binding:"required"`
+	Action    string `json:"action" binding:"required,oneof=completed no_show"`
+}
+
+type SubmitSessionFeedbackInput struct {
+	Rating    int     `json:"rating" binding:"required,min=1,max=5"`
+	Comment   *string `json:"comment" binding:"omitempty,max=2000"`
+	Anonymous bool    `json:"anonymous"` // hides the client's identity from the coach
+}
+
 type BookableSlot struct {
 	StartAt         time.Time `json:"start_at"`
 	EndAt           time.Time `json:"end_at"`
@@ -95,24 +225,133 @@ type BookableSlot struct {
 	SessionTypeID   *uint     `json:"session_type_id,omitempty"`
 }
 
+// BookableTime is a single open slot inside a BookableDay.
+type BookableTime struct {
+	StartAt time.Time `json:"start_at"`
+	EndAt   time.Time `json:"end_at"`
+}
+
+// BookableDay holds the open slots for one UTC date (YYYY-MM-DD).
+type BookableDay struct {
+	Date  string
+	Slots []BookableTime
+}
+
+// NextAvailableSlot is a coach's earliest open slot within the next 30 days; Slot is nil when there
+// is none. Reason is set when the caller can't book the coach themselves.
+type NextAvailableSlot struct {
+	CoachID         uint          `json:"coach_id"`
+	SessionTypeID   *uint         `json:"session_type_id"`
+	DurationMinutes int           `json:"duration_minutes,omitempty"`
+	Slot            *BookableTime `json:"slot"`
+	Reason          string        `json:"reason,omitempty"`
+}
+
+// BookableSlots is the result of a slot lookup, grouped by day in chronological order.
+// Only days with at least one open slot are included.
+type BookableSlots struct {
+	CoachID         uint
+	SessionTypeID   *uint
+	DurationMinutes int
+	Days            []BookableDay
+	Total           int
+}
+
+// Flatten returns the slots as the legacy flat list.
+func (b *BookableSlots) Flatten() []BookableSlot {
+	slots := make([]BookableSlot, 0, b.Total)
+	for _, day := range b.Days {
+		for _, slot := range day.Slots {
+			slots = append(slots, BookableSlot{
+				StartAt:         slot.StartAt,
+				EndAt:           slot.EndAt,
+				DurationMinutes: b.DurationMinutes,
+				CoachID:         b.CoachID,
+				SessionTypeID:   b.SessionTypeID,
+			})
+		}
+	}
+	return slots
+}
+
+// PublicBookingPage is the unauthenticated view behind a coach's shareable booking link.
+// It deliberately carries nothing about the coach beyond what they publish on their profile.
+type PublicBookingPage struct {
+	Coach           PublicBookingCoach        `json:"coach"`
+	SessionTypes    []PublicSessionType       `json:"session_types"`
+	SessionTypeID   *uint                     `json:"session_type_id,omitempty"`
+	DurationMinutes int                       `json:"duration_minutes"`
+	Slots           map[string][]BookableTime `json:"slots"` // keyed by UTC date (YYYY-MM-DD)
+	Total           int                       `json:"total"`
+	Connect         PublicConnectAction       `json:"connect"`
+}
+
+type PublicBookingCoach struct {
+	ID                 uint    `json:"id"`
+	Slug               string  `json:"slug"`
+	BusinessName       *string `json:"business_name"`
+	Bio                *string `json:"bio"`
+	IsAcceptingClients bool    `json:"is_accepting_clients"`
+}
+
+type PublicSessionType struct {
+	ID              uint     `json:"id"`
+	Name            string   `json:"name"`
+	DurationMinutes int      `json:"duration_minutes"`
+	Description     *string  `json:"description"`
+	MaxParticipants int      `json:"max_participants"`
+	Price           *float64 `json:"price"`
+	PriceCurrency   string   `json:"price_currency"`
+}
+
+// PublicConnectAction is the page's "request to connect" call-to-action. Booking still requires an
+// account and a coach relationship, so visitors sign up and then post a connection request.
+type PublicConnectAction struct {
+	Enabled bool   `json:"enabled"`
+	Path    string `json:"path"` // authenticated POST that creates the connection request
+}
+
 type SessionService struct {
-	repos       *repositories.RepositoriesCollection
-	coachRepo   *repositories.CoachRepository
-	clientRepo  *repositories.ClientRepository
-	sessionRepo *repositories.SessionRepository
+	repos       transactor
+	coachRepo   coachProfileReader
+	clientRepo  clientProfileReader
+	userRepo    timezoneReader // viewer timezones for SessionResponse
+	sessionRepo sessionRepository
 	events      *events.Publisher
+	coachStore  *stores.CoachStore
+	// availabilityStore backs GetBookableSlots only; booking validation always reads the database.
+	availabilityStore *stores.AvailabilityStore
+	slotsFlight       singleflight.Group
+	checkInRadius     float64 // meters
+	onboarding        onboardingTracker
 }
 
 func NewSessionService(
-	repos *repositories.RepositoriesCollection,
+	repos transactor,
+	coachRepo coachProfileReader,
+	clientRepo clientProfileReader,
+	userRepo timezoneReader,
+	sessionRepo sessionRepository,
 	eventsPublisher *events.Publisher,
+	coachStore *stores.CoachStore,
+	availabilityStore *stores.AvailabilityStore,
+	checkInRadiusMeters int,
+	onboarding onboardingTracker,
 ) *SessionService {
+	if checkInRadiusMeters <= 0 {
+		checkInRadiusMeters = defaultCheckInRadius
+	}
 	return &SessionService{
-		repos:       repos,
-		coachRepo:   repos.Coach,
-		clientRepo:  repos.Client,
-		sessionRepo: repos.Session,
-		events:      eventsPublisher,
+		repos:             repos,
+		coachRepo:         coachRepo,
+		clientRepo:        clientRepo,
+		userRepo:          userRepo,
+		sessionRepo:       sessionRepo,
+		events:            eventsPublisher,
+		coachStore:        coachStore,
+		availabilityStore: availabilityStore,
+		checkInRadius:     float64(checkInRadiusMeters),
+		onboarding:        onboarding,
 	}
 }
 
@@ -124,7 +363,9 @@ func (s *SessionService) GetMyAvailability(ctx context.Context, userID uint) ([]
 	return s.sessionRepo.GetAvailability(ctx, coach.ID)
 }
 
-func (s *SessionService) SetMyAvailability(ctx context.Context, userID uint, input SetAvailabilityInput) ([]models.CoachAvailability, error) {
+// SetMyAvailability replaces the weekly schedule and reports upcoming bookings that no longer fit it.
+// A dry run validates the slots and reports the same bookings without saving anything.
+func (s *SessionService) SetMyAvailability(ctx context.Context, userID uint, input SetAvailabilityInput, dryRun bool) (*AvailabilityUpdate, error) {
 	coach, err := s.getCoachProfile(ctx, userID)
 	if err != nil {
 		return nil, err
@@ -134,15 +375,184 @@ func (s *SessionService) SetMyAvailability(ctx context.Context, userID uint, inp
 	if err != nil {
 		return nil, err
 	}
+	affected, err := s.findAffectedSessions(ctx, coach.ID, slots, nil)
+	if err != nil {
+		return nil, err
+	}
+	if dryRun {
+		return &AvailabilityUpdate{Slots: slots, AffectedSessions: affected}, nil
+	}
+
+	saved, err := s.replaceAvailability(ctx, coach.ID, input.Slots)
+	if err != nil {
+		return nil, err
+	}
+	if len(saved) > 0 && s.onboarding != nil {
+		s.onboarding.CompleteOnboardingStep(ctx, coach.ID, OnboardingStepAvailability)
+	}
+	return &AvailabilityUpdate{Slots: saved, AffectedSessions: affected}, nil
+}
 
-	if err := s.sessionRepo.SetAvailability(ctx, coach.ID, slots); err != nil {
+// CopyAvailabilityDay duplicates the source day's active slots onto each target day, replacing
+// whatever those days had. Other days are untouched. A target equal to the source is ignored.
+func (s *SessionService) CopyAvailabilityDay(ctx context.Context, userID uint, input CopyAvailabilityDayInput) ([]models.CoachAvailability, error) {
+	coach, err := s.getCoachProfile(ctx, userID)
+	if err != nil {
 		return nil, err
 	}
+	if input.SourceDayOfWeek == nil {
+		return nil, ErrAvailabilitySlotInvalid
+	}
+	sourceDay := *input.SourceDayOfWeek
 
-	return s.sessionRepo.GetAvailability(ctx, coach.ID)
+	targets := make(map[int]bool, len(input.TargetDays))
+	for _, day := range input.TargetDays {
+		if day < 0 || day > 6 {
+			return nil, ErrAvailabilitySlotInvalid
+		}
+		if day != sourceDay {
+			targets[day] = true
+		}
+	}
+
+	current, err := s.sessionRepo.GetAvailability(ctx, coach.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	inputs := make([]AvailabilitySlotInput, 0, len(current))
+	var source []models.CoachAvailability
+	for _, slot := range current {
+		if slot.DayOfWeek == sourceDay {
+			source = append(source, slot)
+		}
+		if !targets[slot.DayOfWeek] {
+			inputs = append(inputs, AvailabilitySlotInput{DayOfWeek: slot.DayOfWeek, StartTime: slot.StartTime, EndTime: slot.EndTime})
+		}
+	}
+	for day := 0; day <= 6; day++ {
+		if !targets[day] {
+			continue
+		}
+		for _, slot := range source {
+			inputs = append(inputs, AvailabilitySlotInput{DayOfWeek: day, StartTime: slot.StartTime, EndTime: slot.EndTime})
+		}
+	}
+
+	return s.replaceAvailability(ctx, coach.ID, inputs)
+}
+
+// CreateMyAvailabilityPreset saves the coach's current active weekly slots under name.
+func (s *SessionService) CreateMyAvailabilityPreset(ctx context.Context, userID uint, input CreateAvailabilityPresetInput) (*models.AvailabilityPreset, error) {
+	coach, err := s.getCoachProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return nil, ErrPresetInvalid
+	}
+
+	existing, err := s.sessionRepo.ListAvailabilityPresets(ctx, coach.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= maxAvailabilityPresets {
+		return nil, ErrPresetLimit
+	}
+
+	current, err := s.sessionRepo.GetAvailability(ctx, coach.ID)
+	if err != nil {
+		return nil, err
+	}
+	slots := make([]models.AvailabilityPresetSlot, 0, len(current))
+	for _, slot := range current {
+		slots = append(slots, models.AvailabilityPresetSlot{DayOfWeek: slot.DayOfWeek, StartTime: slot.StartTime, EndTime: slot.EndTime})
+	}
+
+	preset := &models.AvailabilityPreset{
+		CoachID: coach.ID,
+		Name:    name,
+		Slots:   slots,
+	}
+	if err := s.sessionRepo.CreateAvailabilityPreset(ctx, preset); err != nil {
+		if errors.Is(err, repositories.ErrAvailabilityPresetExists) {
+			return nil, ErrPresetNameTaken
+		}
+		return nil, err
+	}
+	return preset, nil
+}
+
+func (s *SessionService) ListMyAvailabilityPresets(ctx context.Context, userID uint) ([]models.AvailabilityPreset, error) {
+	coach, err := s.getCoachProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.sessionRepo.ListAvailabilityPresets(ctx, coach.ID)
+}
+
+// ApplyMyAvailabilityPreset replaces the weekly schedule with the preset's slots, validated exactly
+// like SetMyAvailability.
+func (s *SessionService) ApplyMyAvailabilityPreset(ctx context.Context, userID, presetID uint) ([]models.CoachAvailability, error) {
+	coach, preset, err := s.getOwnedAvailabilityPreset(ctx, userID, presetID)
+	if err != nil {
+		return nil, err
+	}
+
+	inputs := make([]AvailabilitySlotInput, 0, len(preset.Slots))
+	for _, slot := range preset.Slots {
+		inputs = append(inputs, AvailabilitySlotInput{DayOfWeek: slot.DayOfWeek, StartTime: slot.StartTime, EndTime: slot.EndTime})
+	}
+	return s.replaceAvailability(ctx, coach.ID, inputs)
+}
+
+func (s *SessionService) DeleteMyAvailabilityPreset(ctx context.Context, userID, presetID uint) error {
+	if _, _, err := s.getOwnedAvailabilityPreset(ctx, userID, presetID); err != nil {
+		return err
+	}
+	return s.sessionRepo.DeleteAvailabilityPreset(ctx, presetID)
+}
+
+func (s *SessionService) getOwnedAvailabilityPreset(ctx context.Context, userID, presetID uint) (*models.CoachProfile, *models.AvailabilityPreset, error) {
+	coach, err := s.getCoachProfile(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	preset, err := s.sessionRepo.GetAvailabilityPresetByID(ctx, presetID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrPresetNotFound
+		}
+		return nil, nil, err
+	}
+	if preset.CoachID != coach.ID {
+		return nil, nil, ErrPresetForbidden
+	}
+	return coach, preset, nil
+}
+
+// replaceAvailability validates inputs and swaps them in for the coach's whole weekly schedule.
+func (s *SessionService) replaceAvailability(ctx context.Context, coachID uint, inputs []AvailabilitySlotInput) ([]models.CoachAvailability, error) {
+	slots, err := buildValidatedAvailabilitySlots(coachID, inputs)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.sessionRepo.SetAvailability(ctx, coachID, slots); err != nil {
+		return nil, err
+	}
+	s.availabilityStore.InvalidateAvailability(coachID)
+	s.availabilityStore.InvalidateBookableSlots(coachID)
+
+	return s.sessionRepo.GetAvailability(ctx, coachID)
 }
 
-func (s *SessionService) CreateAvailabilityOverride(ctx context.Context, userID uint, input CreateAvailabilityOverrideInput) (*models.CoachAvailabilityOverride, error) {
+// CreateAvailabilityOverride adds a date override and reports upcoming bookings on that date that no
+// longer fit. A dry run validates the override and reports the same bookings without saving it.
+func (s *SessionService) CreateAvailabilityOverride(ctx context.Context, userID uint, input CreateAvailabilityOverrideInput, dryRun bool) (*AvailabilityOverrideResult, error) {
 	coach, err := s.getCoachProfile(ctx, userID)
 	if err != nil {
 		return nil, err
@@ -171,11 +581,90 @@ func (s *SessionService) CreateAvailabilityOverride(ctx context.Context, userID
 		override.EndTime = &end
 	}
 
+	affected, err := s.findAffectedSessions(ctx, coach.ID, nil, override)
+	if err != nil {
+		return nil, err
+	}
+	if dryRun {
+		return &AvailabilityOverrideResult{CoachAvailabilityOverride: override, AffectedSessions: affected}, nil
+	}
+
 	if err := s.sessionRepo.CreateOverride(ctx, override); err != nil {
 		return nil, err
 	}
+	s.availabilityStore.InvalidateOverrides(coach.ID)
+	s.availabilityStore.InvalidateBookableSlots(coach.ID)
+
+	return &AvailabilityOverrideResult{CoachAvailabilityOverride: override, AffectedSessions: affected}, nil
+}
+
+// findAffectedSessions lists the coach's upcoming scheduled and pending sessions that fit the current
+// availability but not the proposed one. The proposal is either a new weekly schedule or an extra
+// date override (nil leaves that part as it is). Sessions that were already outside availability,
+// such as ones the coach booked by hand, aren't reported.
+func (s *SessionService) findAffectedSessions(
+	ctx context.Context,
+	coachID uint,
+	proposedWeekly []models.CoachAvailability,
+	proposedOverride *models.CoachAvailabilityOverride,
+) ([]AffectedSession, error) {
+	now := time.Now().UTC()
+	horizon := now.AddDate(0, 0, affectedSessionsHorizonDays)
+
+	sessions, err := s.sessionRepo.ListSessions(ctx, coachID, 0, now, horizon, repositories.SessionStatusFilter{})
+	if err != nil {
+		return nil, err
+	}
+	upcoming := sessions[:0]
+	for _, session := range sessions {
+		if session.Status == "scheduled" || session.Status == models.SessionStatusPendingConfirmation {
+			upcoming = append(upcoming, session)
+		}
+	}
+	affected := []AffectedSession{}
+	if len(upcoming) == 0 {
+		return affected, nil
+	}
+
+	currentWeekly, err := s.sessionRepo.GetAvailability(ctx, coachID)
+	if err != nil {
+		return nil, err
+	}
+	if proposedWeekly == nil {
+		proposedWeekly = currentWeekly
+	}
+	overrides, err := s.sessionRepo.ListOverrides(ctx, coachID, now.Format("2006-01-02"), horizon.Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+	overridesByDate := make(map[string][]models.CoachAvailabilityOverride)
+	for _, override := range overrides {
+		overridesByDate[override.Date] = append(overridesByDate[override.Date], override)
+	}
 
-	return override, nil
+	for _, session := range upcoming {
+		at := session.ScheduledAt.UTC()
+		dateKey := at.Format("2006-01-02")
+		currentOverrides := overridesByDate[dateKey]
+		proposedOverrides := currentOverrides
+		if proposedOverride != nil && proposedOverride.Date == dateKey {
+			proposedOverrides = append(append([]models.CoachAvailabilityOverride{}, currentOverrides...), *proposedOverride)
+		}
+
+		if !isWithinAvailabilityWindow(at, session.DurationMinutes, currentWeekly, currentOverrides) ||
+			isWithinAvailabilityWindow(at, session.DurationMinutes, proposedWeekly, proposedOverrides) {
+			continue
+		}
+		affected = append(affected, AffectedSession{
+			SessionID:       session.ID,
+			ClientID:        session.ClientID,
+			ClientName:      digestClientName(session.Client),
+			ScheduledAt:     session.ScheduledAt,
+			DurationMinutes: session.DurationMinutes,
+			Status:          session.Status,
+		})
+	}
+	return affected, nil
 }
 
 func (s *SessionService) ListMyAvailabilityOverrides(ctx context.Context, userID uint, startDateRaw, endDateRaw string) ([]models.CoachAvailabilityOverride, error) {
@@ -214,99 +703,424 @@ func (s *SessionService) DeleteMyAvailabilityOverride(ctx context.Context, userI
 		return ErrOverrideForbidden
 	}
 
-	return s.sessionRepo.DeleteOverride(ctx, overrideID)
+	if err := s.sessionRepo.DeleteOverride(ctx, overrideID); err != nil {
+		return err
+	}
+	s.availabilityStore.InvalidateOverrides(coach.ID)
+	s.availabilityStore.InvalidateBookableSlots(coach.ID)
+	return nil
 }
 
-func (s *SessionService) CreateMySessionType(ctx context.Context, userID uint, input CreateSessionTypeInput) (*models.SessionType, error) {
+// CreateMyTimeBlock blocks off personal time. Blocks may not overlap scheduled sessions or other blocks.
+func (s *SessionService) CreateMyTimeBlock(ctx context.Context, userID uint, input CreateTimeBlockInput) (*models.CoachTimeBlock, error) {
 	coach, err := s.getCoachProfile(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
 
-	name := strings.TrimSpace(input.Name)
-	if name == "" {
-		return nil, ErrSessionTypeInvalid
+	startAt, err := time.Parse(time.RFC3339, strings.TrimSpace(input.StartAt))
+	if err != nil {
+		return nil, ErrTimeBlockInvalid
 	}
-	if !isValidSessionDuration(input.DurationMinutes) {
-		return nil, ErrInvalidSessionDuration
+	endAt, err := time.Parse(time.RFC3339, strings.TrimSpace(input.EndAt))
+	if err != nil {
+		return nil, ErrTimeBlockInvalid
+	}
+	startAt, endAt = startAt.UTC(), endAt.UTC()
+	if !endAt.After(startAt) || endAt.Sub(startAt) > maxTimeBlockDays*24*time.Hour {
+		return nil, ErrTimeBlockInvalid
 	}
 
-	sessionType := &models.SessionType{
-		CoachID:         coach.ID,
-		Name:            name,
-		DurationMinutes: input.DurationMinutes,
-		Description:     trimSessionPtr(input.Description),
-		Color:           trimSessionPtr(input.Color),
-		IsActive:        true,
+	conflict, err := s.sessionRepo.HasCoachConflict(ctx, coach.ID, startAt, endAt, nil)
+	if err != nil {
+		return nil, err
+	}
+	if conflict {
+		return nil, ErrSessionConflict
 	}
 
-	if err := s.sessionRepo.CreateSessionType(ctx, sessionType); err != nil {
+	block := &models.CoachTimeBlock{
+		CoachID: coach.ID,
+		StartAt: startAt,
+		EndAt:   endAt,
+	}
+	if input.Label != nil && strings.TrimSpace(*input.Label) != "" {
+		label := strings.TrimSpace(*input.Label)
+		block.Label = &label
+	}
+
+	if err := s.sessionRepo.CreateTimeBlock(ctx, block); err != nil {
 		return nil, err
 	}
-	return sessionType, nil
+	s.availabilityStore.InvalidateBookableSlots(coach.ID)
+	return block, nil
 }
 
-func (s *SessionService) ListMySessionTypes(ctx context.Context, userID uint) ([]models.SessionType, error) {
+func (s *SessionService) ListMyTimeBlocks(ctx context.Context, userID uint, startDateRaw, endDateRaw string) ([]models.CoachTimeBlock, error) {
 	coach, err := s.getCoachProfile(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
-	return s.sessionRepo.ListSessionTypes(ctx, coach.ID)
+
+	startDate, endDate, err := parseDateRange(startDateRaw, endDateRaw, defaultListRangeDays)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.sessionRepo.ListTimeBlocks(ctx, coach.ID, startDate, endDate)
 }
 
-func (s *SessionService) UpdateMySessionType(ctx context.Context, userID, sessionTypeID uint, input UpdateSessionTypeInput) (*models.SessionType, error) {
+// DeleteMyTimeBlock removes a block, freeing the time for booking again.
+func (s *SessionService) DeleteMyTimeBlock(ctx context.Context, userID, blockID uint) error {
 	coach, err := s.getCoachProfile(ctx, userID)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	sessionType, err := s.sessionRepo.GetSessionTypeByID(ctx, sessionTypeID)
+	block, err := s.sessionRepo.GetTimeBlockByID(ctx, blockID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, ErrSessionTypeNotFound
+			return ErrTimeBlockNotFound
 		}
-		return nil, err
+		return err
 	}
-	if sessionType.CoachID != coach.ID {
-		return nil, ErrSessionTypeForbidden
+	if block.CoachID != coach.ID {
+		return ErrTimeBlockForbidden
 	}
 
-	if input.Name != nil {
-		name := strings.TrimSpace(*input.Name)
-		if name != "" {
-			sessionType.Name = name
-		}
+	if err := s.sessionRepo.DeleteTimeBlock(ctx, blockID); err != nil {
+		return err
 	}
-	if input.DurationMinutes != nil {
-		if !isValidSessionDuration(*input.DurationMinutes) {
-			return nil, ErrInvalidSessionDuration
-		}
-		sessionType.DurationMinutes = *input.DurationMinutes
+	s.availabilityStore.InvalidateBookableSlots(coach.ID)
+	return nil
+}
+
+func (s *SessionService) CreateMySessionType(ctx context.Context, userID uint, input CreateSessionTypeInput) (*models.SessionType, error) {
+	coach, err := s.getOwnerCoachProfile(ctx, userID)
+	if err != nil {
+		return nil, err
 	}
-	if input.Description != nil {
-		sessionType.Description = trimSessionPtr(input.Description)
+
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return nil, ErrSessionTypeInvalid
 	}
-	if input.Color != nil {
-		sessionType.Color = trimSessionPtr(input.Color)
+	if !isValidSessionDuration(input.DurationMinutes) {
+		return nil, ErrInvalidSessionDuration
 	}
-	if input.IsActive != nil {
-		sessionType.IsActive = *input.IsActive
+
+	// New types go to the end of the booking list.
+	existing, err := s.sessionRepo.ListSessionTypes(ctx, coach.ID)
+	if err != nil {
+		return nil, err
 	}
 
-	if err := s.sessionRepo.UpdateSessionType(ctx, sessionType); err != nil {
+	sessionType := &models.SessionType{
+		CoachID:          coach.ID,
+		Name:             name,
+		DurationMinutes:  input.DurationMinutes,
+		Description:      trimSessionPtr(input.Description),
+		Color:            trimSessionPtr(input.Color),
+		IsActive:         true,
+		Price:            input.Price,
+		PriceCurrency:    "USD",
+		MaxParticipants:  1,
+		BookableByClient: true,
+		DisplayOrder:     len(existing),
+
+		RequiresConfirmation: input.RequiresConfirmation,
+	}
+	if input.BookableByClient != nil {
+		sessionType.BookableByClient = *input.BookableByClient
+	}
+	if input.PriceCurrency != nil {
+		sessionType.PriceCurrency = *input.PriceCurrency
+	}
+	if input.MaxParticipants != nil {
+		if !isValidMaxParticipants(*input.MaxParticipants) {
+			return nil, ErrInvalidMaxParticipants
+		}
+		sessionType.MaxParticipants = *input.MaxParticipants
+	}
+
+	if err := s.sessionRepo.CreateSessionType(ctx, sessionType); err != nil {
 		return nil, err
 	}
+	if s.onboarding != nil {
+		s.onboarding.CompleteOnboardingStep(ctx, coach.ID, OnboardingStepSessionTypes)
+	}
 	return sessionType, nil
 }
 
-func (s *SessionService) GetBookableSlots(
-	ctx context.Context,
-	coachID uint,
-	startDateRaw string,
+func (s *SessionService) ListMySessionTypes(ctx context.Context, userID uint) ([]models.SessionType, error) {
+	coach, err := s.getCoachProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.sessionRepo.ListSessionTypes(ctx, coach.ID)
+}
+
+// ListBookableSessionTypes returns the coach's client-bookable session types in display order,
+// for clients choosing what to pass to GetBookableSlots and BookSession.
+func (s *SessionService) ListBookableSessionTypes(ctx context.Context, coachID uint) ([]models.SessionType, error) {
+	if _, err := s.coachRepo.GetByID(ctx, coachID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCoachProfileNotFound
+		}
+		return nil, err
+	}
+	return s.sessionRepo.ListClientBookableSessionTypes(ctx, coachID)
+}
+
+// ReorderMySessionTypes rewrites display_order from the full ordered list of the coach's active types.
+// A partial list is rejected so two types can never end up sharing a position.
+func (s *SessionService) ReorderMySessionTypes(ctx context.Context, userID uint, input ReorderSessionTypesInput) ([]models.SessionType, error) {
+	coach, err := s.getOwnerCoachProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := s.sessionRepo.ListSessionTypes(ctx, coach.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(input.SessionTypeIDs) != len(current) {
+		return nil, ErrSessionTypeOrderInvalid
+	}
+	owned := make(map[uint]bool, len(current))
+	for _, st := range current {
+		owned[st.ID] = true
+	}
+	seen := make(map[uint]bool, len(input.SessionTypeIDs))
+	for _, id := range input.SessionTypeIDs {
+		if !owned[id] || seen[id] {
+			return nil, ErrSessionTypeOrderInvalid
+		}
+		seen[id] = true
+	}
+
+	if err := s.sessionRepo.ReorderSessionTypes(ctx, coach.ID, input.SessionTypeIDs); err != nil {
+		return nil, err
+	}
+	return s.sessionRepo.ListSessionTypes(ctx, coach.ID)
+}
+
+func (s *SessionService) UpdateMySessionType(ctx context.Context, userID, sessionTypeID uint, input UpdateSessionTypeInput) (*models.SessionType, error) {
+	coach, err := s.getOwnerCoachProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	sessionType, err := s.sessionRepo.GetSessionTypeByID(ctx, sessionTypeID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSessionTypeNotFound
+		}
+		return nil, err
+	}
+	if sessionType.CoachID != coach.ID {
+		return nil, ErrSessionTypeForbidden
+	}
+
+	if input.Name != nil {
+		name := strings.TrimSpace(*input.Name)
+		if name != "" {
+			sessionType.Name = name
+		}
+	}
+	if input.DurationMinutes != nil {
+		if !isValidSessionDuration(*input.DurationMinutes) {
+			return nil, ErrInvalidSessionDuration
+		}
+		sessionType.DurationMinutes = *input.DurationMinutes
+	}
+	if input.Description != nil {
+		sessionType.Description = trimSessionPtr(input.Description)
+	}
+	if input.Color != nil {
+		sessionType.Color = trimSessionPtr(input.Color)
+	}
+	if input.IsActive != nil {
+		sessionType.IsActive = *input.IsActive
+	}
+	if input.Price != nil {
+		sessionType.Price = input.Price
+	}
+	if input.PriceCurrency != nil {
+		sessionType.PriceCurrency = *input.PriceCurrency
+	}
+	// Existing sessions keep the capacity they were booked with.
+	if input.MaxParticipants != nil {
+		if !isValidMaxParticipants(*input.MaxParticipants) {
+			return nil, ErrInvalidMaxParticipants
+		}
+		sessionType.MaxParticipants = *input.MaxParticipants
+	}
+	if input.BookableByClient != nil {
+		sessionType.BookableByClient = *input.BookableByClient
+	}
+	if input.RequiresConfirmation != nil {
+		sessionType.RequiresConfirmation = *input.RequiresConfirmation
+	}
+
+	if err := s.sessionRepo.UpdateSessionType(ctx, sessionType); err != nil {
+		return nil, err
+	}
+	s.availabilityStore.InvalidateBookableSlots(sessionType.CoachID)
+	return sessionType, nil
+}
+
+// SelfBookingBlocked reports whether userID is one of the coach's clients with self-booking turned
+// off. Anyone else, including the coach, sees the coach's slots as usual.
+func (s *SessionService) SelfBookingBlocked(ctx context.Context, userID, coachID uint) (bool, error) {
+	profiles, err := s.clientRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	for _, profile := range profiles {
+		if profile.CoachID == coachID && !profile.CanSelfBook {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// GetBookableSlots returns the coach's open slots. Results are cached per coach and query for
+// BookableSlotsTTL and busted by every write that changes them; identical concurrent misses share
+// one computation. Invalid ranges skip the cache so errors keep their usual precedence.
+func (s *SessionService) GetBookableSlots(
+	ctx context.Context,
+	coachID uint,
+	startDateRaw string,
+	endDateRaw string,
+	sessionTypeID *uint,
+	durationMinutes *int,
+	daysLimit int,
+) (*BookableSlots, error) {
+	startDate, endDate, err := parseDateRange(startDateRaw, endDateRaw, defaultBookableRangeDays)
+	if err != nil {
+		return s.computeBookableSlots(ctx, coachID, startDateRaw, endDateRaw, sessionTypeID, durationMinutes, daysLimit)
+	}
+	var cacheTypeID uint
+	if sessionTypeID != nil {
+		cacheTypeID = *sessionTypeID
+	}
+	var cacheDuration int
+	if durationMinutes != nil {
+		cacheDuration = *durationMinutes
+	}
+	variant := stores.BookableSlotsVariant(startDate.Format("2006-01-02"), endDate.Format("2006-01-02"), cacheTypeID, cacheDuration, daysLimit)
+
+	var cached BookableSlots
+	if s.availabilityStore.GetBookableSlots(coachID, variant, &cached) {
+		return &cached, nil
+	}
+
+	flightKey := strconv.FormatUint(uint64(coachID), 10) + ":" + variant
+	result, err, _ := s.slotsFlight.Do(flightKey, func() (interface{}, error) {
+		// Detached from the leader's cancellation so one dropped request does not fail the others.
+		slots, err := s.computeBookableSlots(context.WithoutCancel(ctx), coachID, startDateRaw, endDateRaw, sessionTypeID, durationMinutes, daysLimit)
+		if err != nil {
+			return nil, err
+		}
+		s.availabilityStore.SetBookableSlots(coachID, variant, slots)
+		return slots, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.(*BookableSlots), nil
+}
+
+// GetNextAvailableSlot returns the coach's earliest open slot for the session type (or the default
+// duration), searching day by day from today for up to 30 days. Results are cached per coach and
+// type for NextAvailableSlotTTL and busted with the coach's other computed slots.
+func (s *SessionService) GetNextAvailableSlot(ctx context.Context, coachID uint, sessionTypeID *uint) (*NextAvailableSlot, error) {
+	var cacheTypeID uint
+	if sessionTypeID != nil {
+		cacheTypeID = *sessionTypeID
+	}
+	variant := stores.NextAvailableSlotVariant(cacheTypeID)
+
+	var cached NextAvailableSlot
+	if s.availabilityStore.GetBookableSlots(coachID, variant, &cached) {
+		return &cached, nil
+	}
+
+	flightKey := strconv.FormatUint(uint64(coachID), 10) + ":" + variant
+	result, err, _ := s.slotsFlight.Do(flightKey, func() (interface{}, error) {
+		next, err := s.findNextAvailableSlot(context.WithoutCancel(ctx), coachID, sessionTypeID)
+		if err != nil {
+			return nil, err
+		}
+		s.availabilityStore.SetNextAvailableSlot(coachID, variant, next)
+		return next, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.(*NextAvailableSlot), nil
+}
+
+func (s *SessionService) findNextAvailableSlot(ctx context.Context, coachID uint, sessionTypeID *uint) (*NextAvailableSlot, error) {
+	if _, err := s.coachRepo.GetByID(ctx, coachID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCoachProfileNotFound
+		}
+		return nil, err
+	}
+
+	duration, err := s.resolveBookableDuration(ctx, coachID, sessionTypeID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	startDate := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	endDate := startDate.AddDate(0, 0, nextAvailableSearchDays-1)
+
+	availability, err := s.getCachedAvailability(ctx, coachID)
+	if err != nil {
+		return nil, err
+	}
+	overrides, err := s.getCachedOverrides(ctx, coachID, startDate.Format("2006-01-02"), endDate.Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+	searchEnd := endDate.AddDate(0, 0, 1)
+	sessions, err := s.sessionRepo.ListSessions(ctx, coachID, 0, startDate, searchEnd, repositories.SessionStatusFilter{})
+	if err != nil {
+		return nil, err
+	}
+	blocks, err := s.sessionRepo.ListTimeBlocks(ctx, coachID, startDate, searchEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	next := &NextAvailableSlot{
+		CoachID:         coachID,
+		SessionTypeID:   sessionTypeID,
+		DurationMinutes: duration,
+	}
+	generator := newSlotGenerator(duration, availability, overrides, sessions, blocks)
+	for current := startDate; !current.After(endDate) && next.Slot == nil; current = current.AddDate(0, 0, 1) {
+		generator.eachSlot(current, func(slot BookableTime) bool {
+			next.Slot = &slot
+			return false
+		})
+	}
+	return next, nil
+}
+
+func (s *SessionService) computeBookableSlots(
+	ctx context.Context,
+	coachID uint,
+	startDateRaw string,
 	endDateRaw string,
 	sessionTypeID *uint,
 	durationMinutes *int,
-) ([]BookableSlot, error) {
+	daysLimit int,
+) (*BookableSlots, error) {
 	if _, err := s.coachRepo.GetByID(ctx, coachID); err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, ErrCoachProfileNotFound
@@ -324,226 +1138,1169 @@ func (s *SessionService) GetBookableSlots(
 		return nil, err
 	}
 
-	availability, err := s.sessionRepo.GetAvailability(ctx, coachID)
+	availability, err := s.getCachedAvailability(ctx, coachID)
+	if err != nil {
+		return nil, err
+	}
+	overrides, err := s.getCachedOverrides(ctx, coachID, startDate.Format("2006-01-02"), endDate.Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+	// Busy times are always read fresh; they change on every booking.
+	sessions, err := s.sessionRepo.ListSessions(ctx, coachID, 0, startDate, endDate, repositories.SessionStatusFilter{})
+	if err != nil {
+		return nil, err
+	}
+	blocks, err := s.sessionRepo.ListTimeBlocks(ctx, coachID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildBookableSlots(startDate, endDate, coachID, sessionTypeID, resolvedDuration, daysLimit, availability, overrides, sessions, blocks), nil
+}
+
+// GetPublicBookingPage returns the coach's open slots and client-bookable session types for their public
+// booking link. Slots default to the first bookable type's duration; coach-only types are treated as missing.
+// Pages are cached for CoachBookingPageTTL.
+func (s *SessionService) GetPublicBookingPage(ctx context.Context, slug, startDateRaw, endDateRaw string, sessionTypeID *uint) (*PublicBookingPage, error) {
+	slug = strings.ToLower(strings.TrimSpace(slug))
+	if slug == "" {
+		return nil, ErrCoachProfileNotFound
+	}
+	startDate, endDate, err := parseDateRange(startDateRaw, endDateRaw, defaultBookableRangeDays)
+	if err != nil {
+		return nil, err
+	}
+	start := startDate.Format("2006-01-02")
+	end := endDate.Format("2006-01-02")
+	var cacheTypeID uint
+	if sessionTypeID != nil {
+		cacheTypeID = *sessionTypeID
+	}
+
+	var cached PublicBookingPage
+	if s.coachStore.GetBookingPage(slug, start, end, cacheTypeID, &cached) {
+		return &cached, nil
+	}
+
+	coach, err := s.coachRepo.GetBySlug(ctx, slug)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCoachProfileNotFound
+		}
+		return nil, err
+	}
+
+	sessionTypes, err := s.sessionRepo.ListClientBookableSessionTypes(ctx, coach.ID)
 	if err != nil {
 		return nil, err
 	}
-	overrides, err := s.sessionRepo.ListOverrides(ctx, coachID, startDate.Format("2006-01-02"), endDate.Format("2006-01-02"))
-	if err != nil {
+	publicTypes := make([]PublicSessionType, len(sessionTypes))
+	for i, sessionType := range sessionTypes {
+		publicTypes[i] = PublicSessionType{
+			ID:              sessionType.ID,
+			Name:            sessionType.Name,
+			DurationMinutes: sessionType.DurationMinutes,
+			Description:     sessionType.Description,
+			MaxParticipants: sessionType.MaxParticipants,
+			Price:           sessionType.Price,
+			PriceCurrency:   sessionType.PriceCurrency,
+		}
+	}
+
+	var selectedTypeID *uint
+	if sessionTypeID != nil {
+		for _, sessionType := range sessionTypes {
+			if sessionType.ID == *sessionTypeID {
+				selectedTypeID = &sessionType.ID
+				break
+			}
+		}
+		if selectedTypeID == nil {
+			return nil, ErrSessionTypeNotFound
+		}
+	} else if len(sessionTypes) > 0 {
+		selectedTypeID = &sessionTypes[0].ID
+	}
+
+	slots, err := s.GetBookableSlots(ctx, coach.ID, start, end, selectedTypeID, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &PublicBookingPage{
+		Coach: PublicBookingCoach{
+			ID:                 coach.ID,
+			Slug:               slug,
+			BusinessName:       coach.BusinessName,
+			Bio:                coach.Bio,
+			IsAcceptingClients: coach.IsAcceptingClients,
+		},
+		SessionTypes:    publicTypes,
+		SessionTypeID:   selectedTypeID,
+		DurationMinutes: slots.DurationMinutes,
+		Slots:           make(map[string][]BookableTime, len(slots.Days)),
+		Total:           slots.Total,
+		Connect: PublicConnectAction{
+			Enabled: coach.IsAcceptingClients,
+			Path:    "/api/v1/coaches/" + strconv.FormatUint(uint64(coach.ID), 10) + "/connection-requests",
+		},
+	}
+	for _, day := range slots.Days {
+		page.Slots[day.Date] = day.Slots
+	}
+
+	s.coachStore.SetBookingPage(slug, start, end, cacheTypeID, page)
+	return page, nil
+}
+
+// BookSession books a session, or joins a group session already at that time. Clients can't book over
+// another of their own sessions, with any coach; when the coach books for them instead, the overlap is
+// returned as a warning alongside the session.
+func (s *SessionService) BookSession(ctx context.Context, userID uint, input BookSessionInput) (*models.Session, []SessionWarning, error) {
+	if input.ClientProfileID == 0 {
+		return nil, nil, ErrClientProfileNotFound
+	}
+	if input.SessionTypeID == 0 {
+		return nil, nil, ErrSessionTypeNotFound
+	}
+
+	scheduledAt, err := time.Parse(time.RFC3339, strings.TrimSpace(input.ScheduledAt))
+	if err != nil {
+		return nil, nil, ErrInvalidScheduledAt
+	}
+	scheduledAt = scheduledAt.UTC()
+	if scheduledAt.Before(time.Now().UTC().Add(-1 * time.Minute)) {
+		return nil, nil, ErrInvalidScheduledAt
+	}
+
+	clientProfile, err := s.clientRepo.GetByID(ctx, input.ClientProfileID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrClientProfileNotFound
+		}
+		return nil, nil, err
+	}
+
+	sessionType, err := s.sessionRepo.GetSessionTypeByID(ctx, input.SessionTypeID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrSessionTypeNotFound
+		}
+		return nil, nil, err
+	}
+	if sessionType.CoachID != clientProfile.CoachID {
+		return nil, nil, ErrSessionTypeForbidden
+	}
+	if !sessionType.IsActive {
+		return nil, nil, ErrSessionTypeInactive
+	}
+
+	bookedBy, err := s.resolveBookedBy(ctx, userID, clientProfile.CoachID, clientProfile.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+	// A coach can turn off self-booking for a client; they still book that client themselves.
+	if bookedBy == "client" && !clientProfile.CanSelfBook {
+		return nil, nil, ErrSelfBookingDisabled
+	}
+	// Coach-only types stay bookable by the coach on the client's behalf.
+	if bookedBy == "client" && !sessionType.BookableByClient {
+		return nil, nil, ErrSessionTypeNotBookable
+	}
+
+	// A group session already running at this time is joined rather than re-validated;
+	// its slot was checked when it was created and it is the "conflict" we would find.
+	if sessionType.MaxParticipants > 1 {
+		session, warnings, joined, err := s.joinGroupSession(ctx, clientProfile, sessionType, scheduledAt, bookedBy)
+		if err != nil {
+			return nil, nil, err
+		}
+		if joined {
+			s.availabilityStore.InvalidateBookableSlots(session.CoachID)
+			s.coachStore.InvalidateSessionSummaries(session.CoachID)
+			session, err = s.sessionRepo.GetSession(ctx, session.ID)
+			return session, warnings, err
+		}
+	}
+
+	if err := s.assertSlotBookable(ctx, clientProfile.CoachID, scheduledAt, sessionType.DurationMinutes); err != nil {
+		if input.SkipSuggestedSlots {
+			return nil, nil, err
+		}
+		return nil, nil, s.withSuggestedSlots(ctx, err, clientProfile.CoachID, sessionType.ID, scheduledAt)
+	}
+
+	// Coaches booking on a client's behalf have already agreed to the time.
+	status := "scheduled"
+	if bookedBy == "client" && sessionType.RequiresConfirmation {
+		status = models.SessionStatusPendingConfirmation
+	}
+
+	session := &models.Session{
+		CoachID:          clientProfile.CoachID,
+		ClientID:         clientProfile.ID,
+		SessionTypeID:    sessionType.ID,
+		ScheduledAt:      scheduledAt,
+		DurationMinutes:  sessionType.DurationMinutes,
+		Status:           status,
+		Location:         trimSessionPtr(input.Location),
+		Notes:            trimSessionPtr(input.Notes),
+		Price:            sessionType.Price,
+		MaxParticipants:  max(1, sessionType.MaxParticipants),
+		ParticipantCount: 1,
+	}
+	if sessionType.Price != nil {
+		currency := sessionType.PriceCurrency
+		session.PriceCurrency = &currency
+	}
+
+	var warnings []SessionWarning
+	if err := s.repos.WithTransaction(ctx, func(tx *gorm.DB, txRepos *repositories.RepositoriesCollection) error {
+		if conflict, err := txRepos.Session.HasCoachConflict(
+			ctx,
+			session.CoachID,
+			session.ScheduledAt,
+			session.ScheduledAt.Add(time.Duration(session.DurationMinutes)*time.Minute),
+			nil,
+		); err != nil {
+			return err
+		} else if conflict {
+			return ErrSessionConflict
+		}
+
+		var err error
+		warnings, err = checkClientConflict(ctx, txRepos.Session, clientProfile.UserID, session.ScheduledAt, session.DurationMinutes, nil, bookedBy)
+		if err != nil {
+			return err
+		}
+
+		if err := txRepos.Session.CreateSession(ctx, session); err != nil {
+			return err
+		}
+		if _, err := txRepos.Session.AddParticipant(ctx, session.ID, session.ClientID); err != nil {
+			return err
+		}
+
+		if s.events != nil {
+			payload := events.SessionBookedPayload{
+				SessionID:   session.ID,
+				CoachID:     session.CoachID,
+				ClientID:    session.ClientID,
+				ScheduledAt: session.ScheduledAt,
+				BookedBy:    bookedBy,
+				Status:      session.Status,
+			}
+			idempotencyKey := events.BuildIdempotencyKey(events.EventTypeSessionBooked, strconv.FormatUint(uint64(session.ID), 10))
+			if err := s.events.PublishInTx(
+				ctx,
+				tx,
+				events.EventTypeSessionBooked,
+				"session",
+				strconv.FormatUint(uint64(session.ID), 10),
+				idempotencyKey,
+				payload,
+			); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}); err != nil {
+		// Another booking took the slot between the check above and the insert.
+		if !input.SkipSuggestedSlots {
+			err = s.withSuggestedSlots(ctx, err, session.CoachID, sessionType.ID, scheduledAt)
+		}
+		return nil, nil, err
+	}
+	s.availabilityStore.InvalidateBookableSlots(session.CoachID)
+	s.coachStore.InvalidateSessionSummaries(session.CoachID)
+
+	booked, err := s.sessionRepo.GetSession(ctx, session.ID)
+	return booked, warnings, err
+}
+
+// joinGroupSession adds the client to an existing group session of this type at scheduledAt.
+// joined is false when there is no such session and the caller should create one.
+func (s *SessionService) joinGroupSession(
+	ctx context.Context,
+	clientProfile *models.ClientProfile,
+	sessionType *models.SessionType,
+	scheduledAt time.Time,
+	bookedBy string,
+) (*models.Session, []SessionWarning, bool, error) {
+	var session *models.Session
+	var warnings []SessionWarning
+	err := s.repos.WithTransaction(ctx, func(tx *gorm.DB, txRepos *repositories.RepositoriesCollection) error {
+		existing, err := txRepos.Session.FindJoinableGroupSession(ctx, clientProfile.CoachID, sessionType.ID, scheduledAt)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return err
+		}
+
+		participant, err := txRepos.Session.GetParticipant(ctx, existing.ID, clientProfile.ID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if participant != nil && participant.Status == models.SessionParticipantStatusBooked {
+			return ErrSessionAlreadyJoined
+		}
+		if existing.ParticipantCount >= existing.MaxParticipants {
+			return ErrSessionFull
+		}
+		warnings, err = checkClientConflict(ctx, txRepos.Session, clientProfile.UserID, existing.ScheduledAt, existing.DurationMinutes, &existing.ID, bookedBy)
+		if err != nil {
+			return err
+		}
+
+		participant, err = txRepos.Session.AddParticipant(ctx, existing.ID, clientProfile.ID)
+		if err != nil {
+			return err
+		}
+		if err := txRepos.Session.AdjustParticipantCount(ctx, existing.ID, 1); err != nil {
+			return err
+		}
+
+		if s.events != nil {
+			sessionID := strconv.FormatUint(uint64(existing.ID), 10)
+			payload := events.SessionBookedPayload{
+				SessionID:   existing.ID,
+				CoachID:     existing.CoachID,
+				ClientID:    clientProfile.ID,
+				ScheduledAt: existing.ScheduledAt,
+				BookedBy:    bookedBy,
+				Joined:      true,
+				Status:      existing.Status,
+			}
+			// Keyed by participant row and its update time so leaving and re-joining publishes again.
+			idempotencyKey := events.BuildIdempotencyKey(
+				events.EventTypeSessionBooked,
+				sessionID,
+				"participant",
+				strconv.FormatUint(uint64(participant.ID), 10),
+				strconv.FormatInt(participant.UpdatedAt.UnixNano(), 10),
+			)
+			if err := s.events.PublishInTx(ctx, tx, events.EventTypeSessionBooked, "session", sessionID, idempotencyKey, payload); err != nil {
+				return err
+			}
+		}
+
+		session = existing
+		return nil
+	})
+	if err != nil {
+		return nil, nil, false, err
+	}
+	return session, warnings, session != nil, nil
+}
+
+// ListMySessions returns the caller's sessions as a client in a date range. statusRaw is a
+// comma-separated list of statuses; without one, excludeCancelled leaves cancelled sessions out.
+func (s *SessionService) ListMySessions(ctx context.Context, userID uint, startDateRaw, endDateRaw, statusRaw string, excludeCancelled bool) ([]models.Session, error) {
+	startDate, endDate, err := parseDateRange(startDateRaw, endDateRaw, defaultListRangeDays)
+	if err != nil {
+		return nil, err
+	}
+	filter, err := parseSessionStatusFilter(statusRaw, excludeCancelled)
+	if err != nil {
+		return nil, err
+	}
+
+	clientProfiles, err := s.clientRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(clientProfiles) == 0 {
+		return []models.Session{}, nil
+	}
+
+	clientIDs := make([]uint, 0, len(clientProfiles))
+	for i := range clientProfiles {
+		clientIDs = append(clientIDs, clientProfiles[i].ID)
+	}
+
+	return s.sessionRepo.ListSessionsByClients(ctx, clientIDs, startDate, endDate, filter)
+}
+
+// ListCoachSessions returns the coach's sessions in a date range, filtered like ListMySessions.
+func (s *SessionService) ListCoachSessions(ctx context.Context, userID uint, startDateRaw, endDateRaw, statusRaw string, excludeCancelled bool) ([]models.Session, error) {
+	coach, err := s.getCoachProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	startDate, endDate, err := parseDateRange(startDateRaw, endDateRaw, defaultListRangeDays)
+	if err != nil {
+		return nil, err
+	}
+	filter, err := parseSessionStatusFilter(statusRaw, excludeCancelled)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.sessionRepo.ListSessions(ctx, coach.ID, 0, startDate, endDate, filter)
+}
+
+// SessionSummary counts a coach's sessions starting in one calendar month of their timezone.
+// LateCancellations counts client bookings cancelled late, so one group session can add several.
+type SessionSummary struct {
+	Month             string               `json:"month"`
+	Timezone          string               `json:"timezone"`
+	Total             int64                `json:"total"`
+	ByStatus          map[string]int64     `json:"by_status"`
+	BySessionType     []SessionTypeSummary `json:"by_session_type"`
+	Cancellations     int64                `json:"cancellations"`
+	LateCancellations int64                `json:"late_cancellations"`
+	NoShows           int64                `json:"no_shows"`
+}
+
+// SessionTypeSummary is one session type's share of a SessionSummary.
+type SessionTypeSummary struct {
+	SessionTypeID uint             `json:"session_type_id"`
+	Name          string           `json:"name"`
+	Total         int64            `json:"total"`
+	ByStatus      map[string]int64 `json:"by_status"`
+}
+
+// GetSessionSummary counts the coach's sessions in monthRaw (YYYY-MM, default the current month in
+// the coach's timezone) by status and by session type. Summaries are cached for a few minutes and
+// dropped whenever one of the coach's sessions changes status.
+func (s *SessionService) GetSessionSummary(ctx context.Context, userID uint, monthRaw string) (*SessionSummary, error) {
+	coach, err := s.getCoachProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	timezone, err := s.userRepo.GetTimezone(ctx, coach.UserID)
+	if err != nil {
+		return nil, err
+	}
+	loc := utils.ResolveLocation(timezone)
+
+	monthRaw = strings.TrimSpace(monthRaw)
+	if monthRaw == "" {
+		monthRaw = time.Now().In(loc).Format(monthLayout)
+	}
+	month, err := time.ParseInLocation(monthLayout, monthRaw, loc)
+	if err != nil {
+		return nil, ErrInvalidMonthFormat
+	}
+	monthKey := month.Format(monthLayout)
+
+	var cached SessionSummary
+	if s.coachStore.GetSessionSummary(coach.ID, monthKey, &cached) {
+		return &cached, nil
+	}
+
+	start, end := month.UTC(), month.AddDate(0, 1, 0).UTC()
+	counts, err := s.sessionRepo.CountSessionsByStatusAndType(ctx, coach.ID, start, end)
+	if err != nil {
+		return nil, err
+	}
+	lateCancellations, err := s.sessionRepo.CountLateCancellations(ctx, coach.ID, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &SessionSummary{
+		Month:             monthKey,
+		Timezone:          loc.String(),
+		ByStatus:          emptySessionStatusCounts(),
+		BySessionType:     []SessionTypeSummary{},
+		LateCancellations: lateCancellations,
+	}
+	// Rows arrive ordered by session type, so each type's rows are contiguous
+	for _, row := range counts {
+		summary.Total += row.Count
+		summary.ByStatus[row.Status] += row.Count
+		if n := len(summary.BySessionType); n == 0 || summary.BySessionType[n-1].SessionTypeID != row.SessionTypeID {
+			summary.BySessionType = append(summary.BySessionType, SessionTypeSummary{
+				SessionTypeID: row.SessionTypeID,
+				Name:          row.SessionTypeName,
+				ByStatus:      emptySessionStatusCounts(),
+			})
+		}
+		sessionType := &summary.BySessionType[len(summary.BySessionType)-1]
+		sessionType.Total += row.Count
+		sessionType.ByStatus[row.Status] += row.Count
+	}
+	summary.Cancellations = summary.ByStatus["cancelled"]
+	summary.NoShows = summary.ByStatus["no_show"]
+
+	s.coachStore.SetSessionSummary(coach.ID, monthKey, summary)
+	return summary, nil
+}
+
+func emptySessionStatusCounts() map[string]int64 {
+	counts := make(map[string]int64, len(sessionStatuses))
+	for _, status := range sessionStatuses {
+		counts[status] = 0
+	}
+	return counts
+}
+
+// parseSessionStatusFilter turns a comma-separated status list into a list filter; an explicit list
+// is returned as given, so including "cancelled" in it brings cancelled sessions back.
+func parseSessionStatusFilter(statusRaw string, excludeCancelled bool) (repositories.SessionStatusFilter, error) {
+	filter := repositories.SessionStatusFilter{ExcludeCancelled: excludeCancelled}
+	for _, part := range strings.Split(statusRaw, ",") {
+		status := strings.TrimSpace(part)
+		if status == "" {
+			continue
+		}
+		valid := false
+		for _, known := range sessionStatuses {
+			if status == known {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return repositories.SessionStatusFilter{}, ErrInvalidSessionStatus
+		}
+		filter.Statuses = append(filter.Statuses, status)
+	}
+	return filter, nil
+}
+
+// CancelSession cancels a session. A client leaving a group session that still has other
+// participants only removes themselves; the coach (or the last participant) cancels the whole
+// session, and a coach cancellation notifies every participant.
+func (s *SessionService) CancelSession(ctx context.Context, userID, sessionID uint, input CancelSessionInput) (*models.Session, error) {
+	session, actor, err := s.getSessionForUser(ctx, userID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	// A pending booking can be withdrawn by the client; the coach declines it instead.
+	pending := session.Status == models.SessionStatusPendingConfirmation
+	if session.Status != "scheduled" && !(pending && actor == "client") {
+		return nil, ErrSessionStateInvalid
+	}
+
+	reason := "cancelled"
+	if input.Reason != nil && strings.TrimSpace(*input.Reason) != "" {
+		reason = strings.TrimSpace(*input.Reason)
+	}
+
+	// Only clients can cancel late; coach cancellations are never counted against anyone, and
+	// withdrawing an unconfirmed booking is never late.
+	late := actor == "client" && !pending && isLateCancellation(session, time.Now())
+
+	if actor == "client" {
+		participant := findBookedParticipant(session, userID)
+		if participant == nil && len(session.Participants) > 0 {
+			// The creating client already left this group session.
+			return nil, ErrSessionForbidden
+		}
+		if participant != nil {
+			// The participant count is only trusted under the row lock, so two last cancellations
+			// can't both leave and strand a scheduled session with nobody in it.
+			if err := s.repos.WithTransaction(ctx, func(tx *gorm.DB, txRepos *repositories.RepositoriesCollection) error {
+				locked, err := txRepos.Session.LockSession(ctx, session.ID)
+				if err != nil {
+					return err
+				}
+				if locked.Status != "scheduled" && locked.Status != models.SessionStatusPendingConfirmation {
+					return ErrSessionStateInvalid
+				}
+				removed, err := txRepos.Session.RemoveParticipant(ctx, session.ID, participant.ClientID, reason)
+				if err != nil {
+					return err
+				}
+				if !removed {
+					return ErrSessionStateInvalid
+				}
+				if locked.ParticipantCount <= 1 {
+					// The last participant leaving takes the session down with them.
+					if err := txRepos.Session.CancelSession(ctx, session.ID, actor, reason); err != nil {
+						return err
+					}
+				} else if err := txRepos.Session.AdjustParticipantCount(ctx, session.ID, -1); err != nil {
+					return err
+				}
+				if !late {
+					return nil
+				}
+				return recordLateCancellation(ctx, txRepos, session.ID, participant.ClientID)
+			}); err != nil {
+				return nil, err
+			}
+			if late {
+				s.coachStore.InvalidateEarnings(session.CoachID)
+			}
+			s.availabilityStore.InvalidateBookableSlots(session.CoachID)
+			s.coachStore.InvalidateSessionSummaries(session.CoachID)
+			return s.sessionRepo.GetSession(ctx, session.ID)
+		}
+	}
+
+	if err := s.repos.WithTransaction(ctx, func(tx *gorm.DB, txRepos *repositories.RepositoriesCollection) error {
+		if err := txRepos.Session.CancelSession(ctx, session.ID, actor, reason); err != nil {
+			return err
+		}
+		if late {
+			if err := recordLateCancellation(ctx, txRepos, session.ID, session.ClientID); err != nil {
+				return err
+			}
+		}
+		if actor != "coach" || s.events == nil {
+			return nil
+		}
+
+		payload := events.SessionCancelledPayload{
+			SessionID:          session.ID,
+			CoachID:            session.CoachID,
+			ScheduledAt:        session.ScheduledAt,
+			CancelledBy:        actor,
+			Reason:             reason,
+			ParticipantUserIDs: bookedParticipantUserIDs(session),
+		}
+		if len(payload.ParticipantUserIDs) == 0 {
+			return nil
+		}
+		id := strconv.FormatUint(uint64(session.ID), 10)
+		return s.events.PublishInTx(
+			ctx,
+			tx,
+			events.EventTypeSessionCancelled,
+			"session",
+			id,
+			events.BuildIdempotencyKey(events.EventTypeSessionCancelled, id),
+			payload,
+		)
+	}); err != nil {
+		return nil, err
+	}
+	if late {
+		s.coachStore.InvalidateEarnings(session.CoachID)
+	}
+	s.availabilityStore.InvalidateBookableSlots(session.CoachID)
+	s.coachStore.InvalidateSessionSummaries(session.CoachID)
+
+	return s.sessionRepo.GetSession(ctx, session.ID)
+}
+
+// ConfirmSession moves a pending booking to scheduled and notifies the booked clients. Only the
+// session's coach can confirm.
+func (s *SessionService) ConfirmSession(ctx context.Context, userID, sessionID uint) (*models.Session, error) {
+	session, actor, err := s.getSessionForUser(ctx, userID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if actor != "coach" {
+		return nil, ErrSessionActionForbidden
+	}
+	if session.Status != models.SessionStatusPendingConfirmation {
+		return nil, ErrSessionStateInvalid
+	}
+
+	if err := s.repos.WithTransaction(ctx, func(tx *gorm.DB, txRepos *repositories.RepositoriesCollection) error {
+		confirmed, err := txRepos.Session.ConfirmSession(ctx, session.ID)
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			return ErrSessionStateInvalid
+		}
+		return s.publishSessionDecision(ctx, tx, events.EventTypeSessionConfirmed, session, "", false)
+	}); err != nil {
+		return nil, err
+	}
+	s.coachStore.InvalidateSessionSummaries(session.CoachID)
+
+	return s.sessionRepo.GetSession(ctx, session.ID)
+}
+
+// DeclineSession cancels a pending booking with an optional reason, freeing the slot, and notifies
+// the booked clients. Only the session's coach can decline.
+func (s *SessionService) DeclineSession(ctx context.Context, userID, sessionID uint, input DeclineSessionInput) (*models.Session, error) {
+	session, actor, err := s.getSessionForUser(ctx, userID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if actor != "coach" {
+		return nil, ErrSessionActionForbidden
+	}
+	if session.Status != models.SessionStatusPendingConfirmation {
+		return nil, ErrSessionStateInvalid
+	}
+
+	reason := "declined"
+	if trimmed := trimSessionPtr(input.Reason); trimmed != nil {
+		reason = *trimmed
+	}
+	declined, err := s.declinePendingSession(ctx, session, "coach", reason, false)
+	if err != nil {
+		return nil, err
+	}
+	if !declined {
+		return nil, ErrSessionStateInvalid
+	}
+
+	return s.sessionRepo.GetSession(ctx, session.ID)
+}
+
+// DeclineExpiredPendingSessions auto-declines pending bookings made before createdBefore, and any
+// whose start time has arrived, in batches. It returns how many were declined.
+func (s *SessionService) DeclineExpiredPendingSessions(ctx context.Context, createdBefore, now time.Time) (int, error) {
+	const batchSize = 100
+
+	declinedCount := 0
+	for {
+		sessions, err := s.sessionRepo.ListExpiredPendingSessions(ctx, createdBefore, now, batchSize)
+		if err != nil {
+			return declinedCount, err
+		}
+
+		for i := range sessions {
+			declined, err := s.declinePendingSession(ctx, &sessions[i], "system", "not confirmed in time", true)
+			if err != nil {
+				return declinedCount, err
+			}
+			if declined {
+				declinedCount++
+			}
+		}
+		if len(sessions) < batchSize {
+			return declinedCount, nil
+		}
+	}
+}
+
+// declinePendingSession cancels the session if it is still pending and publishes session.declined
+// in the same transaction. It reports false when the session had already been resolved.
+func (s *SessionService) declinePendingSession(ctx context.Context, session *models.Session, declinedBy, reason string, auto bool) (bool, error) {
+	declined := false
+	err := s.repos.WithTransaction(ctx, func(tx *gorm.DB, txRepos *repositories.RepositoriesCollection) error {
+		ok, err := txRepos.Session.DeclinePendingSession(ctx, session.ID, declinedBy, reason)
+		if err != nil || !ok {
+			return err
+		}
+		declined = true
+		return s.publishSessionDecision(ctx, tx, events.EventTypeSessionDeclined, session, reason, auto)
+	})
+	if err != nil {
+		return false, err
+	}
+	if declined {
+		s.availabilityStore.InvalidateBookableSlots(session.CoachID)
+		s.coachStore.InvalidateSessionSummaries(session.CoachID)
+	}
+	return declined, nil
+}
+
+func (s *SessionService) publishSessionDecision(
+	ctx context.Context,
+	tx *gorm.DB,
+	eventType events.EventType,
+	session *models.Session,
+	reason string,
+	auto bool,
+) error {
+	if s.events == nil {
+		return nil
+	}
+
+	payload := events.SessionDecisionPayload{
+		SessionID:          session.ID,
+		CoachID:            session.CoachID,
+		ScheduledAt:        session.ScheduledAt,
+		AutoDeclined:       auto,
+		ParticipantUserIDs: bookedParticipantUserIDs(session),
+	}
+	if reason != "declined" {
+		payload.Reason = reason
+	}
+	id := strconv.FormatUint(uint64(session.ID), 10)
+	return s.events.PublishInTx(
+		ctx,
+		tx,
+		eventType,
+		"session",
+		id,
+		events.BuildIdempotencyKey(eventType, id),
+		payload,
+	)
+}
+
+// isLateCancellation reports whether now falls inside the coach's cancellation window before the
+// session starts. Coaches without a window (0 hours) never produce late cancellations.
+func isLateCancellation(session *models.Session, now time.Time) bool {
+	window := session.Coach.CancellationWindowHours
+	if window <= 0 {
+		return false
+	}
+	return now.After(session.ScheduledAt.Add(-time.Duration(window) * time.Hour))
+}
+
+// recordLateCancellation flags the client's cancellation as late and bumps their late-cancel count.
+func recordLateCancellation(ctx context.Context, txRepos *repositories.RepositoriesCollection, sessionID, clientID uint) error {
+	if err := txRepos.Session.MarkLateCancelled(ctx, sessionID, clientID); err != nil {
+		return err
+	}
+	return txRepos.Client.IncrementLateCancelCount(ctx, clientID)
+}
+
+func (s *SessionService) CompleteSession(ctx context.Context, userID, sessionID uint) (*models.Session, error) {
+	session, actor, err := s.getSessionForUser(ctx, userID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if actor != "coach" {
+		return nil, ErrSessionActionForbidden
+	}
+	if session.Status != "scheduled" {
+		return nil, ErrSessionStateInvalid
+	}
+
+	if err := s.sessionRepo.CompleteSession(ctx, session.ID); err != nil {
+		return nil, err
+	}
+	s.coachStore.InvalidateEarnings(session.CoachID)
+	s.coachStore.InvalidateSessionSummaries(session.CoachID)
+	return s.sessionRepo.GetSession(ctx, session.ID)
+}
+
+// MarkSessionPaid records payment for a priced session. Marking an already-paid session is a no-op.
+func (s *SessionService) MarkSessionPaid(ctx context.Context, userID, sessionID uint) (*models.Session, error) {
+	session, actor, err := s.getSessionForUser(ctx, userID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if actor != "coach" {
+		return nil, ErrSessionActionForbidden
+	}
+	if session.Status == "cancelled" {
+		return nil, ErrSessionStateInvalid
+	}
+	if session.Price == nil {
+		return nil, ErrSessionUnpriced
+	}
+	if session.PaidAt != nil {
+		return session, nil
+	}
+
+	if err := s.sessionRepo.MarkPaid(ctx, session.ID, time.Now().UTC()); err != nil {
 		return nil, err
 	}
-	sessions, err := s.sessionRepo.ListSessions(ctx, coachID, 0, startDate, endDate)
+	s.coachStore.InvalidateEarnings(session.CoachID)
+	return s.sessionRepo.GetSession(ctx, session.ID)
+}
+
+// CheckIn records that the client arrived, within checkInWindow of the scheduled time. When the
+// client sends coordinates and the coach's primary location has them, the distance is stored and
+// check-ins beyond the configured radius are flagged for the coach. Repeat check-ins are no-ops.
+func (s *SessionService) CheckIn(ctx context.Context, userID, sessionID uint, input CheckInSessionInput) (*models.Session, error) {
+	session, actor, err := s.getSessionForUser(ctx, userID, sessionID)
 	if err != nil {
 		return nil, err
 	}
 
-	return buildBookableSlots(startDate, endDate, coachID, sessionTypeID, resolvedDuration, availability, overrides, sessions), nil
-}
-
-func (s *SessionService) BookSession(ctx context.Context, userID uint, input BookSessionInput) (*models.Session, error) {
-	if input.ClientProfileID == 0 {
-		return nil, ErrClientProfileNotFound
+	if actor != "client" {
+		return nil, ErrCheckInForbidden
 	}
-	if input.SessionTypeID == 0 {
-		return nil, ErrSessionTypeNotFound
+	if participant := findBookedParticipant(session, userID); participant == nil && len(session.Participants) > 0 {
+		return nil, ErrCheckInForbidden
 	}
-
-	scheduledAt, err := time.Parse(time.RFC3339, strings.TrimSpace(input.ScheduledAt))
-	if err != nil {
-		return nil, ErrInvalidScheduledAt
+	if session.Status != "scheduled" {
+		return nil, ErrSessionStateInvalid
 	}
-	scheduledAt = scheduledAt.UTC()
-	if scheduledAt.Before(time.Now().UTC().Add(-1 * time.Minute)) {
-		return nil, ErrInvalidScheduledAt
+	if (input.Latitude == nil) != (input.Longitude == nil) {
+		return nil, ErrInvalidCheckInLocation
+	}
+	if session.CheckedInAt != nil {
+		return session, nil
 	}
 
-	clientProfile, err := s.clientRepo.GetByID(ctx, input.ClientProfileID)
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, ErrClientProfileNotFound
+	now := time.Now().UTC()
+	if now.Before(session.ScheduledAt.Add(-checkInWindow)) || now.After(session.ScheduledAt.Add(checkInWindow)) {
+		return nil, ErrCheckInWindowClosed
+	}
+
+	checkIn := &models.Session{
+		ID:               session.ID,
+		CheckedInAt:      &now,
+		CheckInLatitude:  input.Latitude,
+		CheckInLongitude: input.Longitude,
+	}
+	if input.Latitude != nil {
+		location, err := s.coachRepo.GetPrimaryLocation(ctx, session.CoachID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
 		}
+		if location != nil && location.Latitude != nil && location.Longitude != nil {
+			distance := distanceMeters(*input.Latitude, *input.Longitude, *location.Latitude, *location.Longitude)
+			checkIn.CheckInDistanceMeters = &distance
+			checkIn.CheckInOutsideRadius = distance > s.checkInRadius
+		}
+	}
+
+	if _, err := s.sessionRepo.RecordCheckIn(ctx, checkIn); err != nil {
 		return nil, err
 	}
+	return s.sessionRepo.GetSession(ctx, session.ID)
+}
 
-	sessionType, err := s.sessionRepo.GetSessionTypeByID(ctx, input.SessionTypeID)
+func (s *SessionService) MarkNoShow(ctx context.Context, userID, sessionID uint) (*models.Session, error) {
+	session, actor, err := s.getSessionForUser(ctx, userID, sessionID)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, ErrSessionTypeNotFound
-		}
 		return nil, err
 	}
-	if sessionType.CoachID != clientProfile.CoachID {
-		return nil, ErrSessionTypeForbidden
+
+	if actor != "coach" {
+		return nil, ErrSessionActionForbidden
 	}
-	if !sessionType.IsActive {
-		return nil, ErrSessionTypeInactive
+	if session.Status != "scheduled" {
+		return nil, ErrSessionStateInvalid
 	}
-
-	bookedBy, err := s.resolveBookedBy(ctx, userID, clientProfile.CoachID, clientProfile.UserID)
-	if err != nil {
-		return nil, err
+	if session.CheckedInAt != nil {
+		return nil, ErrSessionCheckedIn
 	}
 
-	if err := s.assertSlotBookable(ctx, clientProfile.CoachID, scheduledAt, sessionType.DurationMinutes); err != nil {
+	if err := s.sessionRepo.MarkNoShow(ctx, session.ID); err != nil {
 		return nil, err
 	}
+	s.coachStore.InvalidateSessionSummaries(session.CoachID)
+	return s.sessionRepo.GetSession(ctx, session.ID)
+}
 
-	session := &models.Session{
-		CoachID:         clientProfile.CoachID,
-		ClientID:        clientProfile.ID,
-		SessionTypeID:   sessionType.ID,
-		ScheduledAt:     scheduledAt,
-		DurationMinutes: sessionType.DurationMinutes,
-		Status:          "scheduled",
-		Location:        trimSessionPtr(input.Location),
-		Notes:           trimSessionPtr(input.Notes),
-	}
+// StaleSessionResult is the outcome of one item in a resolve-stale request. Err holds the service
+// error for items that couldn't be resolved; the handler turns it into a code and message.
+type StaleSessionResult struct {
+	SessionID uint            `json:"session_id"`
+	Action    string          `json:"action"`
+	Session   *models.Session `json:"session,omitempty"`
+	Err       error           `json:"-"`
+}
 
-	if err := s.repos.WithTransaction(ctx, func(tx *gorm.DB, txRepos *repositories.RepositoriesCollection) error {
-		if conflict, err := txRepos.Session.HasCoachConflict(
-			ctx,
-			session.CoachID,
-			session.ScheduledAt,
-			session.ScheduledAt.Add(time.Duration(session.DurationMinutes)*time.Minute),
-			nil,
-		); err != nil {
-			return err
-		} else if conflict {
-			return ErrSessionConflict
+// ResolveStaleSessions applies the coach's chosen outcome to each session, independently: one item
+// failing (already resolved, checked in, someone else's session) doesn't stop the rest.
+func (s *SessionService) ResolveStaleSessions(ctx context.Context, userID uint, input ResolveStaleSessionsInput) []StaleSessionResult {
+	results := make([]StaleSessionResult, 0, len(input.Sessions))
+	for _, item := range input.Sessions {
+		result := StaleSessionResult{SessionID: item.SessionID, Action: item.Action}
+		if item.Action == "no_show" {
+			result.Session, result.Err = s.MarkNoShow(ctx, userID, item.SessionID)
+		} else {
+			result.Session, result.Err = s.CompleteSession(ctx, userID, item.SessionID)
 		}
+		results = append(results, result)
+	}
+	return results
+}
 
-		if err := txRepos.Session.CreateSession(ctx, session); err != nil {
-			return err
+// StaleSessionSweep counts what one SweepStaleSessions run did.
+type StaleSessionSweep struct {
+	Completed int
+	NoShow    int
+	Prompted  int // sessions included in a prompt to their coach
+}
+
+// SweepStaleSessions handles scheduled sessions without a check-in that ended before endedBefore.
+// Coaches who chose an automatic outcome get it applied; everyone else gets one in-app notification
+// per sweep listing their sessions, and those sessions are marked so they aren't listed again.
+// Every write is conditional on the session still being unresolved, so overlapping runs are harmless.
+func (s *SessionService) SweepStaleSessions(ctx context.Context, endedBefore time.Time) (StaleSessionSweep, error) {
+	const batchSize = 100
+
+	var sweep StaleSessionSweep
+	var afterID uint
+	prompts := make(map[uint][]models.Session) // coach user ID -> sessions
+	for {
+		sessions, err := s.sessionRepo.ListStaleSessions(ctx, endedBefore, afterID, batchSize)
+		if err != nil {
+			return sweep, err
 		}
 
-		if s.events != nil {
-			payload := events.SessionBookedPayload{
-				SessionID:   session.ID,
-				CoachID:     session.CoachID,
-				ClientID:    session.ClientID,
-				ScheduledAt: session.ScheduledAt,
-				BookedBy:    bookedBy,
+		for i := range sessions {
+			session := sessions[i]
+			afterID = session.ID
+
+			status := ""
+			switch session.Coach.StaleSessionAction {
+			case models.StaleSessionAutoComplete:
+				status = "completed"
+			case models.StaleSessionAutoNoShow:
+				status = "no_show"
+			default:
+				prompts[session.Coach.UserID] = append(prompts[session.Coach.UserID], session)
+				continue
 			}
-			idempotencyKey := events.BuildIdempotencyKey(events.EventTypeSessionBooked, strconv.FormatUint(uint64(session.ID), 10))
-			if err := s.events.PublishInTx(
-				ctx,
-				tx,
-				events.EventTypeSessionBooked,
-				"session",
-				strconv.FormatUint(uint64(session.ID), 10),
-				idempotencyKey,
-				payload,
-			); err != nil {
-				return err
+
+			resolved, err := s.sessionRepo.ResolveStaleSession(ctx, session.ID, status)
+			if err != nil {
+				return sweep, err
+			}
+			if !resolved {
+				continue
+			}
+			s.coachStore.InvalidateSessionSummaries(session.CoachID)
+			if status == "completed" {
+				sweep.Completed++
+				s.coachStore.InvalidateEarnings(session.CoachID)
+			} else {
+				sweep.NoShow++
 			}
 		}
-
-		return nil
-	}); err != nil {
-		return nil, err
+		if len(sessions) < batchSize {
+			break
+		}
 	}
 
-	return s.sessionRepo.GetSession(ctx, session.ID)
+	for coachUserID, sessions := range prompts {
+		prompted, err := s.promptStaleSessions(ctx, coachUserID, sessions)
+		if err != nil {
+			return sweep, err
+		}
+		sweep.Prompted += prompted
+	}
+	return sweep, nil
 }
 
-func (s *SessionService) ListMySessions(ctx context.Context, userID uint, startDateRaw, endDateRaw string) ([]models.Session, error) {
-	startDate, endDate, err := parseDateRange(startDateRaw, endDateRaw, defaultListRangeDays)
-	if err != nil {
-		return nil, err
+// promptStaleSessions adds an in-app notification asking the coach to resolve sessions and marks
+// them prompted in the same transaction, so a retry never double-notifies. When another run already
+// marked every session, no notification is added.
+func (s *SessionService) promptStaleSessions(ctx context.Context, coachUserID uint, sessions []models.Session) (int, error) {
+	ids := make([]uint, 0, len(sessions))
+	for _, session := range sessions {
+		ids = append(ids, session.ID)
 	}
 
-	clientProfiles, err := s.clientRepo.ListByUser(ctx, userID)
-	if err != nil {
-		return nil, err
-	}
-	if len(clientProfiles) == 0 {
-		return []models.Session{}, nil
+	title := "Sessions need an outcome"
+	body := "1 past session is still marked scheduled. Mark it completed or a no-show."
+	if len(sessions) > 1 {
+		body = fmt.Sprintf("%d past sessions are still marked scheduled. Mark them completed or no-shows.", len(sessions))
 	}
 
-	clientIDs := make([]uint, 0, len(clientProfiles))
-	for i := range clientProfiles {
-		clientIDs = append(clientIDs, clientProfiles[i].ID)
+	prompted := 0
+	err := s.repos.WithTransaction(ctx, func(tx *gorm.DB, txRepos *repositories.RepositoriesCollection) error {
+		marked, err := txRepos.Session.MarkStalePrompted(ctx, ids, time.Now().UTC())
+		if err != nil || marked == 0 {
+			return err
+		}
+		prompted = int(marked)
+		return txRepos.Notification.Create(ctx, &models.Notification{
+			UserID: coachUserID,
+			Type:   "stale_sessions",
+			Title:  title,
+			Body:   &body,
+			Data:   map[string]any{"type": "stale_sessions", "session_ids": ids},
+		})
+	})
+	if err != nil {
+		return 0, err
 	}
-
-	return s.sessionRepo.ListSessionsByClients(ctx, clientIDs, startDate, endDate)
+	return prompted, nil
 }
 
-func (s *SessionService) ListCoachSessions(ctx context.Context, userID uint, startDateRaw, endDateRaw string) ([]models.Session, error) {
-	coach, err := s.getCoachProfile(ctx, userID)
-	if err != nil {
-		return nil, err
-	}
+// SessionICS is a single-event calendar file for a session.
+type SessionICS struct {
+	Filename string
+	Body     string
+}
 
-	startDate, endDate, err := parseDateRange(startDateRaw, endDateRaw, defaultListRangeDays)
+// GetSessionICS renders one of the user's sessions as an .ics file, named from their side of it.
+func (s *SessionService) GetSessionICS(ctx context.Context, userID, sessionID uint) (*SessionICS, error) {
+	session, _, err := s.getSessionForUser(ctx, userID, sessionID)
 	if err != nil {
 		return nil, err
 	}
 
-	return s.sessionRepo.ListSessions(ctx, coach.ID, 0, startDate, endDate)
+	event := calendar.SessionEvent(session, userID)
+	return &SessionICS{
+		Filename: fmt.Sprintf("session-%d.ics", session.ID),
+		Body:     calendar.Encode([]calendar.Event{event}, time.Now().UTC()),
+	}, nil
 }
 
-func (s *SessionService) CancelSession(ctx context.Context, userID, sessionID uint, input CancelSessionInput) (*models.Session, error) {
-	session, err := s.getSessionForUser(ctx, userID, sessionID)
+// SubmitSessionFeedback records the client's rating of a completed session. Only the client who
+// booked the session can rate it, once, within feedbackWindow of completion. The coach's rating
+// stats are refreshed asynchronously by the session.feedback_submitted handler.
+func (s *SessionService) SubmitSessionFeedback(ctx context.Context, userID, sessionID uint, input SubmitSessionFeedbackInput) (*models.SessionFeedback, error) {
+	session, _, err := s.getSessionForUser(ctx, userID, sessionID)
 	if err != nil {
 		return nil, err
 	}
 
-	actor := resolveSessionActor(session, userID)
-	if actor == "" {
-		return nil, ErrSessionForbidden
+	if session.Client.UserID != userID {
+		return nil, ErrFeedbackForbidden
 	}
-	if session.Status != "scheduled" {
+	if session.Status != "completed" {
 		return nil, ErrSessionStateInvalid
 	}
-
-	reason := "cancelled"
-	if input.Reason != nil && strings.TrimSpace(*input.Reason) != "" {
-		reason = strings.TrimSpace(*input.Reason)
+	completedAt := session.ScheduledAt
+	if session.CompletedAt != nil {
+		completedAt = *session.CompletedAt
 	}
-
-	if err := s.sessionRepo.CancelSession(ctx, session.ID, actor, reason); err != nil {
-		return nil, err
+	if time.Since(completedAt) > feedbackWindow {
+		return nil, ErrFeedbackWindowClosed
 	}
 
-	return s.sessionRepo.GetSession(ctx, session.ID)
-}
-
-func (s *SessionService) CompleteSession(ctx context.Context, userID, sessionID uint) (*models.Session, error) {
-	session, err := s.getSessionForUser(ctx, userID, sessionID)
-	if err != nil {
-		return nil, err
+	feedback := &models.SessionFeedback{
+		SessionID: session.ID,
+		CoachID:   session.CoachID,
+		ClientID:  session.ClientID,
+		Rating:    input.Rating,
+		Comment:   trimSessionPtr(input.Comment),
+		Anonymous: input.Anonymous,
 	}
 
-	if resolveSessionActor(session, userID) != "coach" {
-		return nil, ErrSessionActionForbidden
-	}
-	if session.Status != "scheduled" {
-		return nil, ErrSessionStateInvalid
-	}
+	if err := s.repos.WithTransaction(ctx, func(tx *gorm.DB, txRepos *repositories.RepositoriesCollection) error {
+		if err := txRepos.Session.CreateFeedback(ctx, feedback); err != nil {
+			if errors.Is(err, repositories.ErrSessionFeedbackExists) {
+				return ErrFeedbackExists
+			}
+			return err
+		}
 
-	if err := s.sessionRepo.CompleteSession(ctx, session.ID); err != nil {
+		if s.events == nil {
+			return nil
+		}
+		feedbackID := strconv.FormatUint(uint64(feedback.ID), 10)
+		return s.events.PublishInTx(
+			ctx,
+			tx,
+			events.EventTypeSessionFeedback,
+			"session_feedback",
+			feedbackID,
+			events.BuildIdempotencyKey(events.EventTypeSessionFeedback, feedbackID),
+			events.SessionFeedbackPayload{
+				FeedbackID: feedback.ID,
+				SessionID:  session.ID,
+				CoachID:    session.CoachID,
+				Rating:     feedback.Rating,
+			},
+		)
+	}); err != nil {
 		return nil, err
 	}
-	return s.sessionRepo.GetSession(ctx, session.ID)
+
+	return feedback, nil
 }
 
-func (s *SessionService) MarkNoShow(ctx context.Context, userID, sessionID uint) (*models.Session, error) {
-	session, err := s.getSessionForUser(ctx, userID, sessionID)
+// ListMyFeedback returns the coach's session feedback, newest first. Anonymous entries carry no
+// client details.
+func (s *SessionService) ListMyFeedback(ctx context.Context, userID uint, limit, offset int) ([]repositories.SessionFeedbackEntry, int64, error) {
+	coach, err := s.getCoachProfile(ctx, userID)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
-	if resolveSessionActor(session, userID) != "coach" {
-		return nil, ErrSessionActionForbidden
+	if limit <= 0 {
+		limit = 20
 	}
-	if session.Status != "scheduled" {
-		return nil, ErrSessionStateInvalid
+	if limit > 100 {
+		limit = 100
 	}
-
-	if err := s.sessionRepo.MarkNoShow(ctx, session.ID); err != nil {
-		return nil, err
+	if offset < 0 {
+		offset = 0
 	}
-	return s.sessionRepo.GetSession(ctx, session.ID)
+
+	return s.sessionRepo.ListCoachFeedback(ctx, coach.ID, limit, offset)
 }
 
 func (s *SessionService) resolveBookableDuration(ctx context.Context, coachID uint, sessionTypeID *uint, durationMinutes *int) (int, error) {
@@ -574,6 +2331,35 @@ func (s *SessionService) resolveBookableDuration(ctx context.Context, coachID ui
 	return 60, nil
 }
 
+// getCachedAvailability reads recurring availability through the cache, falling back to the
+// database on a miss or when Redis is unavailable.
+func (s *SessionService) getCachedAvailability(ctx context.Context, coachID uint) ([]models.CoachAvailability, error) {
+	if slots, ok := s.availabilityStore.GetAvailability(coachID); ok {
+		return slots, nil
+	}
+
+	slots, err := s.sessionRepo.GetAvailability(ctx, coachID)
+	if err != nil {
+		return nil, err
+	}
+	s.availabilityStore.SetAvailability(coachID, slots)
+	return slots, nil
+}
+
+// getCachedOverrides reads date overrides through the cache, falling back to the database.
+func (s *SessionService) getCachedOverrides(ctx context.Context, coachID uint, startDate, endDate string) ([]models.CoachAvailabilityOverride, error) {
+	if overrides, ok := s.availabilityStore.GetOverrides(coachID, startDate, endDate); ok {
+		return overrides, nil
+	}
+
+	overrides, err := s.sessionRepo.ListOverrides(ctx, coachID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	s.availabilityStore.SetOverrides(coachID, startDate, endDate, overrides)
+	return overrides, nil
+}
+
 func (s *SessionService) assertSlotBookable(ctx context.Context, coachID uint, scheduledAt time.Time, durationMinutes int) error {
 	if !isValidSessionDuration(durationMinutes) {
 		return ErrInvalidSessionDuration
@@ -617,43 +2403,70 @@ func (s *SessionService) resolveBookedBy(ctx context.Context, userID, coachID, c
 		return "client", nil
 	}
 
-	coachProfile, err := s.coachRepo.GetByUserID(ctx, userID)
+	acting, err := resolveActingCoach(ctx, s.coachRepo, userID)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
+		if errors.Is(err, ErrCoachProfileNotFound) {
 			return "", ErrSessionForbidden
 		}
 		return "", err
 	}
-	if coachProfile.ID != coachID {
+	if acting.Coach.ID != coachID {
 		return "", ErrSessionForbidden
 	}
 	return "coach", nil
 }
 
-func (s *SessionService) getSessionForUser(ctx context.Context, userID, sessionID uint) (*models.Session, error) {
+// getSessionForUser loads a session the caller takes part in, with their role on it ("coach" or "client").
+func (s *SessionService) getSessionForUser(ctx context.Context, userID, sessionID uint) (*models.Session, string, error) {
 	session, err := s.sessionRepo.GetSession(ctx, sessionID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, ErrSessionNotFound
+			return nil, "", ErrSessionNotFound
 		}
-		return nil, err
+		return nil, "", err
+	}
+
+	actor, err := s.sessionActor(ctx, session, userID)
+	if err != nil {
+		return nil, "", err
+	}
+	if actor == "" {
+		return nil, "", ErrSessionForbidden
 	}
+	return session, actor, nil
+}
 
-	if resolveSessionActor(session, userID) == "" {
-		return nil, ErrSessionForbidden
+// sessionActor is resolveSessionActor with the coach's assistants acting as the coach.
+func (s *SessionService) sessionActor(ctx context.Context, session *models.Session, userID uint) (string, error) {
+	if actor := resolveSessionActor(session, userID); actor != "" {
+		return actor, nil
+	}
+	assists, err := assistsCoach(ctx, s.coachRepo, userID, session.CoachID)
+	if err != nil || !assists {
+		return "", err
 	}
-	return session, nil
+	return "coach", nil
 }
 
+// getCoachProfile resolves the coach the caller schedules for, whether they own the profile or assist on it.
 func (s *SessionService) getCoachProfile(ctx context.Context, userID uint) (*models.CoachProfile, error) {
-	coach, err := s.coachRepo.GetByUserID(ctx, userID)
+	acting, err := resolveActingCoach(ctx, s.coachRepo, userID)
+	if err != nil {
+		return nil, err
+	}
+	return acting.Coach, nil
+}
+
+// getOwnerCoachProfile is getCoachProfile for settings only the owner may change, like session type pricing.
+func (s *SessionService) getOwnerCoachProfile(ctx context.Context, userID uint) (*models.CoachProfile, error) {
+	acting, err := resolveActingCoach(ctx, s.coachRepo, userID)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, ErrCoachProfileNotFound
-		}
 		return nil, err
 	}
-	return coach, nil
+	if !acting.IsOwner() {
+		return nil, ErrCoachTeamOwnerOnly
+	}
+	return acting.Coach, nil
 }
 
 func buildValidatedAvailabilitySlots(coachID uint, inputs []AvailabilitySlotInput) ([]models.CoachAvailability, error) {
@@ -706,11 +2519,59 @@ func buildBookableSlots(
 	endDate time.Time,
 	coachID uint,
 	sessionTypeID *uint,
+	durationMinutes int,
+	daysLimit int,
+	availability []models.CoachAvailability,
+	overrides []models.CoachAvailabilityOverride,
+	sessions []models.Session,
+	blocks []models.CoachTimeBlock,
+) *BookableSlots {
+	generator := newSlotGenerator(durationMinutes, availability, overrides, sessions, blocks)
+	result := &BookableSlots{
+		CoachID:         coachID,
+		SessionTypeID:   sessionTypeID,
+		DurationMinutes: durationMinutes,
+		Days:            []BookableDay{},
+	}
+
+	for current := startDate; !current.After(endDate); current = current.AddDate(0, 0, 1) {
+		// daysLimit counts days that actually have openings, not calendar days.
+		if daysLimit > 0 && len(result.Days) >= daysLimit {
+			break
+		}
+
+		var daySlots []BookableTime
+		generator.eachSlot(current, func(slot BookableTime) bool {
+			daySlots = append(daySlots, slot)
+			return true
+		})
+
+		if len(daySlots) > 0 {
+			result.Days = append(result.Days, BookableDay{Date: current.Format("2006-01-02"), Slots: daySlots})
+			result.Total += len(daySlots)
+		}
+	}
+
+	return result
+}
+
+// slotGenerator produces a coach's open slots one UTC date at a time from their availability,
+// overrides and busy times, so callers can stop at the first slot instead of building whole ranges.
+type slotGenerator struct {
+	durationMinutes int
+	availability    []models.CoachAvailability
+	overrideByDate  map[string][]models.CoachAvailabilityOverride
+	busyByDate      map[string][]timeRange
+	now             time.Time
+}
+
+func newSlotGenerator(
 	durationMinutes int,
 	availability []models.CoachAvailability,
 	overrides []models.CoachAvailabilityOverride,
 	sessions []models.Session,
-) []BookableSlot {
+	blocks []models.CoachTimeBlock,
+) *slotGenerator {
 	overrideByDate := map[string][]models.CoachAvailabilityOverride{}
 	for i := range overrides {
 		overrideByDate[overrides[i].Date] = append(overrideByDate[overrides[i].Date], overrides[i])
@@ -718,7 +2579,7 @@ func buildBookableSlots(
 
 	busyByDate := map[string][]timeRange{}
 	for i := range sessions {
-		if sessions[i].Status != "scheduled" {
+		if sessions[i].Status != "scheduled" && sessions[i].Status != models.SessionStatusPendingConfirmation {
 			continue
 		}
 		start := sessions[i].ScheduledAt.UTC()
@@ -726,6 +2587,15 @@ func buildBookableSlots(
 		key := start.Format("2006-01-02")
 		busyByDate[key] = append(busyByDate[key], timeRange{start: start, end: end})
 	}
+	// A block can span midnight, so it is busy on every date it touches.
+	for i := range blocks {
+		start, end := blocks[i].StartAt.UTC(), blocks[i].EndAt.UTC()
+		day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+		for ; day.Before(end); day = day.AddDate(0, 0, 1) {
+			key := day.Format("2006-01-02")
+			busyByDate[key] = append(busyByDate[key], timeRange{start: start, end: end})
+		}
+	}
 
 	for key := range busyByDate {
 		sort.Slice(busyByDate[key], func(i, j int) bool {
@@ -733,40 +2603,43 @@ func buildBookableSlots(
 		})
 	}
 
-	nowUTC := time.Now().UTC()
-	var slots []BookableSlot
+	return &slotGenerator{
+		durationMinutes: durationMinutes,
+		availability:    availability,
+		overrideByDate:  overrideByDate,
+		busyByDate:      busyByDate,
+		now:             time.Now().UTC(),
+	}
+}
 
-	for current := startDate; !current.After(endDate); current = current.AddDate(0, 0, 1) {
-		windows := windowsForDate(current, availability, overrideByDate[current.Format("2006-01-02")])
-		if len(windows) == 0 {
-			continue
-		}
+// eachSlot calls yield with every open slot on date in chronological order, stopping early when
+// yield returns false. It reports whether the date was walked to the end.
+func (g *slotGenerator) eachSlot(date time.Time, yield func(BookableTime) bool) bool {
+	dateKey := date.Format("2006-01-02")
+	windows := windowsForDate(date, g.availability, g.overrideByDate[dateKey])
+	if len(windows) == 0 {
+		return true
+	}
 
-		dayBusy := busyByDate[current.Format("2006-01-02")]
-		for _, window := range windows {
-			for minute := window.start; minute+durationMinutes <= window.end; minute += slotStepMinutes {
-				startAt := time.Date(current.Year(), current.Month(), current.Day(), 0, 0, 0, 0, time.UTC).Add(time.Duration(minute) * time.Minute)
-				endAt := startAt.Add(time.Duration(durationMinutes) * time.Minute)
+	dayBusy := g.busyByDate[dateKey]
+	for _, window := range windows {
+		for minute := window.start; minute+g.durationMinutes <= window.end; minute += slotStepMinutes {
+			startAt := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC).Add(time.Duration(minute) * time.Minute)
+			endAt := startAt.Add(time.Duration(g.durationMinutes) * time.Minute)
 
-				if endAt.Before(nowUTC) {
-					continue
-				}
-				if hasBusyConflict(startAt, endAt, dayBusy) {
-					continue
-				}
+			if endAt.Before(g.now) {
+				continue
+			}
+			if hasBusyConflict(startAt, endAt, dayBusy) {
+				continue
+			}
 
-				slots = append(slots, BookableSlot{
-					StartAt:         startAt,
-					EndAt:           endAt,
-					DurationMinutes: durationMinutes,
-					CoachID:         coachID,
-					SessionTypeID:   sessionTypeID,
-				})
+			if !yield(BookableTime{StartAt: startAt, EndAt: endAt}) {
+				return false
 			}
 		}
 	}
-
-	return slots
+	return true
 }
 
 func isWithinAvailabilityWindow(
@@ -885,12 +2758,45 @@ func resolveSessionActor(session *models.Session, userID uint) string {
 	if session.Coach.UserID == userID {
 		return "coach"
 	}
-	if session.Client.UserID == userID {
+	if session.Client.UserID == userID || findBookedParticipant(session, userID) != nil {
 		return "client"
 	}
 	return ""
 }
 
+// findBookedParticipant returns the user's booked participant row, if GetSession preloaded one.
+func findBookedParticipant(session *models.Session, userID uint) *models.SessionParticipant {
+	for i := range session.Participants {
+		participant := &session.Participants[i]
+		if participant.Client.UserID == userID && participant.Status == models.SessionParticipantStatusBooked {
+			return participant
+		}
+	}
+	return nil
+}
+
+func bookedParticipantUserIDs(session *models.Session) []uint {
+	userIDs := make([]uint, 0, len(session.Participants))
+	for _, participant := range session.Participants {
+		if participant.Status == models.SessionParticipantStatusBooked && participant.Client.UserID > 0 {
+			userIDs = append(userIDs, participant.Client.UserID)
+		}
+	}
+	return userIDs
+}
+
+// distanceMeters returns the great-circle (haversine) distance between two coordinates.
+func distanceMeters(lat1, lng1, lat2, lng2 float64) float64 {
+	const earthRadiusMeters = 6371000.0
+	toRadians := func(deg float64) float64 { return deg * math.Pi / 180 }
+
+	dLat := toRadians(lat2 - lat1)
+	dLng := toRadians(lng2 - lng1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRadians(lat1))*math.Cos(toRadians(lat2))*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadiusMeters * math.Asin(math.Sqrt(a))
+}
+
 func parseDateRange(startRaw, endRaw string, defaultDays int) (time.Time, time.Time, error) {
 	var (
 		startDate time.Time
@@ -994,6 +2900,10 @@ func isValidSessionDuration(minutes int) bool {
 	return minutes%5 == 0
 }
 
+func isValidMaxParticipants(n int) bool {
+	return n >= 1 && n <= maxGroupParticipants
+}
+
 func rangesOverlap(startA, endA, startB, endB int) bool {
 	return startA < endB && startB < endA
 }
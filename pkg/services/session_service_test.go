@@ -0,0 +1,370 @@
+package services
+
+import (
+	"chalk-api/pkg/models"
+	"chalk-api/pkg/services/fakes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func weeklyAvailability(coachID uint, start, end string) []models.CoachAvailability {
+	slots := make([]models.CoachAvailability, 0, 7)
+	for day := 0; day < 7; day++ {
+		slots = append(slots, models.CoachAvailability{CoachID: coachID, DayOfWeek: day, StartTime: start, EndTime: end, IsActive: true})
+	}
+	return slots
+}
+
+func slotStarts(day BookableDay) []string {
+	starts := make([]string, len(day.Slots))
+	for i, slot := range day.Slots {
+		starts[i] = slot.StartAt.Format("15:04")
+	}
+	return starts
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestBuildBookableSlotsOverrides(t *testing.T) {
+	start := time.Date(2030, 6, 3, 0, 0, 0, 0, time.UTC) // Monday
+	end := start.AddDate(0, 0, 2)
+	from, to := "14:00", "15:00"
+	overrides := []models.CoachAvailabilityOverride{
+		// Tuesday is blocked entirely.
+		{CoachID: 1, Date: "2030-06-04", IsAvailable: false},
+		// Wednesday's weekly hours are replaced by the override window.
+		{CoachID: 1, Date: "2030-06-05", IsAvailable: true, StartTime: &from, EndTime: &to},
+	}
+
+	result := buildBookableSlots(start, end, 1, nil, 60, 0, weeklyAvailability(1, "09:00", "10:30"), overrides, nil, nil)
+
+	if len(result.Days) != 2 {
+		t.Fatalf("days = %+v, want Monday and Wednesday only", result.Days)
+	}
+	if result.Days[0].Date != "2030-06-03" || !equalStrings(slotStarts(result.Days[0]), []string{"09:00", "09:15", "09:30"}) {
+		t.Errorf("Monday = %s %v, want the weekly 09:00-10:30 slots", result.Days[0].Date, slotStarts(result.Days[0]))
+	}
+	if result.Days[1].Date != "2030-06-05" || !equalStrings(slotStarts(result.Days[1]), []string{"14:00"}) {
+		t.Errorf("Wednesday = %s %v, want only the override's 14:00 slot", result.Days[1].Date, slotStarts(result.Days[1]))
+	}
+	if result.Total != 4 {
+		t.Errorf("total = %d, want 4", result.Total)
+	}
+}
+
+func TestBuildBookableSlotsBlockedDayDoesNotCountTowardsLimit(t *testing.T) {
+	start := time.Date(2030, 6, 3, 0, 0, 0, 0, time.UTC)
+	overrides := []models.CoachAvailabilityOverride{{CoachID: 1, Date: "2030-06-03", IsAvailable: false}}
+
+	result := buildBookableSlots(start, start.AddDate(0, 0, 6), 1, nil, 60, 2, weeklyAvailability(1, "09:00", "10:00"), overrides, nil, nil)
+
+	if len(result.Days) != 2 || result.Days[0].Date != "2030-06-04" || result.Days[1].Date != "2030-06-05" {
+		t.Fatalf("days = %+v, want the two open days after the blocked Monday", result.Days)
+	}
+}
+
+// Slots are generated on UTC dates, so a DST change in the coach's zone must neither drop nor
+// duplicate a slot: every day yields the same UTC times, exactly 24 hours apart.
+func TestBuildBookableSlotsAcrossDSTTransitions(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		start time.Time
+	}{
+		{"US spring forward", time.Date(2030, 3, 9, 0, 0, 0, 0, time.UTC)}, // DST starts March 10
+		{"EU fall back", time.Date(2030, 10, 26, 0, 0, 0, 0, time.UTC)},    // DST ends October 27
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			result := buildBookableSlots(tc.start, tc.start.AddDate(0, 0, 2), 1, nil, 30, 0, weeklyAvailability(1, "09:00", "10:00"), nil, nil, nil)
+
+			if len(result.Days) != 3 {
+				t.Fatalf("days = %d, want 3", len(result.Days))
+			}
+			first := result.Days[0].Slots
+			for i, day := range result.Days {
+				if len(day.Slots) != len(first) {
+					t.Fatalf("%s has %d slots, want %d", day.Date, len(day.Slots), len(first))
+				}
+				for j, slot := range day.Slots {
+					want := first[j].StartAt.Add(time.Duration(i) * 24 * time.Hour)
+					if !slot.StartAt.Equal(want) || slot.EndAt.Sub(slot.StartAt) != 30*time.Minute {
+						t.Errorf("%s slot %d = %s-%s, want %s for 30 minutes", day.Date, j, slot.StartAt, slot.EndAt, want)
+					}
+				}
+			}
+			if got := slotStarts(result.Days[1]); !equalStrings(got, []string{"09:00", "09:15", "09:30"}) {
+				t.Errorf("transition day slots = %v", got)
+			}
+		})
+	}
+}
+
+func TestBuildBookableSlotsTrimsBusyIntervals(t *testing.T) {
+	day := time.Date(2030, 6, 3, 0, 0, 0, 0, time.UTC)
+	at := func(hour, minute int) time.Time {
+		return day.Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute)
+	}
+	sessions := []models.Session{
+		{CoachID: 1, ScheduledAt: at(10, 0), DurationMinutes: 60, Status: "scheduled"},
+		{CoachID: 1, ScheduledAt: at(12, 30), DurationMinutes: 30, Status: models.SessionStatusPendingConfirmation},
+		// Cancelled and completed sessions free their time.
+		{CoachID: 1, ScheduledAt: at(9, 0), DurationMinutes: 60, Status: "cancelled"},
+		{CoachID: 1, ScheduledAt: at(11, 0), DurationMinutes: 30, Status: "completed"},
+	}
+	blocks := []models.CoachTimeBlock{
+		{CoachID: 1, StartAt: at(11, 30), EndAt: at(11, 45)},
+		// A block starting the evening before still covers the early morning.
+		{CoachID: 1, StartAt: day.Add(-2 * time.Hour), EndAt: at(8, 15)},
+	}
+
+	result := buildBookableSlots(day, day, 1, nil, 60, 0, weeklyAvailability(1, "08:00", "14:00"), nil, sessions, blocks)
+
+	if len(result.Days) != 1 {
+		t.Fatalf("days = %+v, want one", result.Days)
+	}
+	// Open slots touch busy intervals at their edges but never overlap them.
+	want := []string{"08:15", "08:30", "08:45", "09:00", "13:00"}
+	if got := slotStarts(result.Days[0]); !equalStrings(got, want) {
+		t.Errorf("slots = %v, want %v", got, want)
+	}
+}
+
+type bookingFixture struct {
+	service      *SessionService
+	transactor   *fakes.Transactor
+	sessions     *fakes.SessionRepository
+	coachUserID  uint
+	clientUserID uint
+	client       models.ClientProfile
+	sessionType  models.SessionType
+	scheduledAt  time.Time
+}
+
+// newBookingFixture sets up a coach available 09:00-17:00 UTC every day with an active 60 minute
+// session type and a client who can book themselves.
+func newBookingFixture(t *testing.T) *bookingFixture {
+	t.Helper()
+	ctx := context.Background()
+
+	coaches := fakes.NewCoachRepository()
+	clients := fakes.NewClientRepository()
+	sessions := fakes.NewSessionRepository()
+	transactor := &fakes.Transactor{}
+
+	coach := coaches.Add(models.CoachProfile{UserID: 10})
+	client := clients.Add(models.ClientProfile{UserID: 20, CoachID: coach.ID, Status: "active", CanSelfBook: true})
+	if err := sessions.SetAvailability(ctx, coach.ID, weeklyAvailability(coach.ID, "09:00", "17:00")); err != nil {
+		t.Fatalf("set availability: %v", err)
+	}
+	sessionType := models.SessionType{CoachID: coach.ID, Name: "1:1", DurationMinutes: 60, IsActive: true, BookableByClient: true}
+	if err := sessions.CreateSessionType(ctx, &sessionType); err != nil {
+		t.Fatalf("create session type: %v", err)
+	}
+
+	next := time.Now().UTC().AddDate(0, 0, 7)
+	return &bookingFixture{
+		service:      NewSessionService(transactor, coaches, clients, fakes.NewUserRepository(), sessions, nil, nil, nil, 0, nil),
+		transactor:   transactor,
+		sessions:     sessions,
+		coachUserID:  coach.UserID,
+		clientUserID: client.UserID,
+		client:       client,
+		sessionType:  sessionType,
+		scheduledAt:  time.Date(next.Year(), next.Month(), next.Day(), 10, 0, 0, 0, time.UTC),
+	}
+}
+
+func (f *bookingFixture) addSessionType(t *testing.T, st models.SessionType) uint {
+	t.Helper()
+	st.CoachID = f.client.CoachID
+	if err := f.sessions.CreateSessionType(context.Background(), &st); err != nil {
+		t.Fatalf("create session type: %v", err)
+	}
+	return st.ID
+}
+
+func TestBookSessionValidationLadder(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		// setup adjusts the fixture and returns the booking to try and the user booking it.
+		setup func(t *testing.T, f *bookingFixture) (uint, BookSessionInput)
+		want  error
+	}{
+		{
+			name: "missing client profile",
+			setup: func(t *testing.T, f *bookingFixture) (uint, BookSessionInput) {
+				return f.clientUserID, BookSessionInput{SessionTypeID: f.sessionType.ID, ScheduledAt: f.scheduledAt.Format(time.RFC3339)}
+			},
+			want: ErrClientProfileNotFound,
+		},
+		{
+			name: "missing session type",
+			setup: func(t *testing.T, f *bookingFixture) (uint, BookSessionInput) {
+				return f.clientUserID, BookSessionInput{ClientProfileID: f.client.ID, ScheduledAt: f.scheduledAt.Format(time.RFC3339)}
+			},
+			want: ErrSessionTypeNotFound,
+		},
+		{
+			name: "unparseable time",
+			setup: func(t *testing.T, f *bookingFixture) (uint, BookSessionInput) {
+				return f.clientUserID, BookSessionInput{ClientProfileID: f.client.ID, SessionTypeID: f.sessionType.ID, ScheduledAt: "next tuesday"}
+			},
+			want: ErrInvalidScheduledAt,
+		},
+		{
+			name: "time in the past",
+			setup: func(t *testing.T, f *bookingFixture) (uint, BookSessionInput) {
+				past := time.Now().UTC().Add(-time.Hour).Format(time.RFC3339)
+				return f.clientUserID, BookSessionInput{ClientProfileID: f.client.ID, SessionTypeID: f.sessionType.ID, ScheduledAt: past}
+			},
+			want: ErrInvalidScheduledAt,
+		},
+		{
+			name: "unknown client profile",
+			setup: func(t *testing.T, f *bookingFixture) (uint, BookSessionInput) {
+				return f.clientUserID, BookSessionInput{ClientProfileID: 999, SessionTypeID: f.sessionType.ID, ScheduledAt: f.scheduledAt.Format(time.RFC3339)}
+			},
+			want: ErrClientProfileNotFound,
+		},
+		{
+			name: "unknown session type",
+			setup: func(t *testing.T, f *bookingFixture) (uint, BookSessionInput) {
+				return f.clientUserID, BookSessionInput{ClientProfileID: f.client.ID, SessionTypeID: 999, ScheduledAt: f.scheduledAt.Format(time.RFC3339)}
+			},
+			want: ErrSessionTypeNotFound,
+		},
+		{
+			name: "another coach's session type",
+			setup: func(t *testing.T, f *bookingFixture) (uint, BookSessionInput) {
+				other := models.SessionType{CoachID: f.client.CoachID + 100, Name: "Other", DurationMinutes: 60, IsActive: true, BookableByClient: true}
+				if err := f.sessions.CreateSessionType(context.Background(), &other); err != nil {
+					t.Fatalf("create session type: %v", err)
+				}
+				return f.clientUserID, BookSessionInput{ClientProfileID: f.client.ID, SessionTypeID: other.ID, ScheduledAt: f.scheduledAt.Format(time.RFC3339)}
+			},
+			want: ErrSessionTypeForbidden,
+		},
+		{
+			name: "inactive session type",
+			setup: func(t *testing.T, f *bookingFixture) (uint, BookSessionInput) {
+				id := f.addSessionType(t, models.SessionType{Name: "Retired", DurationMinutes: 60, IsActive: false, BookableByClient: true})
+				return f.clientUserID, BookSessionInput{ClientProfileID: f.client.ID, SessionTypeID: id, ScheduledAt: f.scheduledAt.Format(time.RFC3339)}
+			},
+			want: ErrSessionTypeInactive,
+		},
+		{
+			name: "user unrelated to the relationship",
+			setup: func(t *testing.T, f *bookingFixture) (uint, BookSessionInput) {
+				return 999, BookSessionInput{ClientProfileID: f.client.ID, SessionTypeID: f.sessionType.ID, ScheduledAt: f.scheduledAt.Format(time.RFC3339)}
+			},
+			want: ErrSessionForbidden,
+		},
+		{
+			name: "client with self-booking disabled",
+			setup: func(t *testing.T, f *bookingFixture) (uint, BookSessionInput) {
+				restricted := f.client
+				restricted.ID = 0
+				restricted.CanSelfBook = false
+				clients := fakes.NewClientRepository()
+				restricted = clients.Add(restricted)
+				f.service.clientRepo = clients
+				return f.clientUserID, BookSessionInput{ClientProfileID: restricted.ID, SessionTypeID: f.sessionType.ID, ScheduledAt: f.scheduledAt.Format(time.RFC3339)}
+			},
+			want: ErrSelfBookingDisabled,
+		},
+		{
+			name: "client booking a coach-only type",
+			setup: func(t *testing.T, f *bookingFixture) (uint, BookSessionInput) {
+				id := f.addSessionType(t, models.SessionType{Name: "Assessment", DurationMinutes: 60, IsActive: true, BookableByClient: false})
+				return f.clientUserID, BookSessionInput{ClientProfileID: f.client.ID, SessionTypeID: id, ScheduledAt: f.scheduledAt.Format(time.RFC3339)}
+			},
+			want: ErrSessionTypeNotBookable,
+		},
+		{
+			name: "unsupported duration",
+			setup: func(t *testing.T, f *bookingFixture) (uint, BookSessionInput) {
+				id := f.addSessionType(t, models.SessionType{Name: "Odd", DurationMinutes: 7, IsActive: true, BookableByClient: true})
+				return f.clientUserID, BookSessionInput{ClientProfileID: f.client.ID, SessionTypeID: id, ScheduledAt: f.scheduledAt.Format(time.RFC3339)}
+			},
+			want: ErrInvalidSessionDuration,
+		},
+		{
+			name: "outside availability",
+			setup: func(t *testing.T, f *bookingFixture) (uint, BookSessionInput) {
+				late := f.scheduledAt.Add(7 * time.Hour) // 17:00, when the coach stops
+				return f.clientUserID, BookSessionInput{ClientProfileID: f.client.ID, SessionTypeID: f.sessionType.ID, ScheduledAt: late.Format(time.RFC3339)}
+			},
+			want: ErrOutsideAvailability,
+		},
+		{
+			name: "overlaps an existing session",
+			setup: func(t *testing.T, f *bookingFixture) (uint, BookSessionInput) {
+				f.sessions.AddSession(models.Session{CoachID: f.client.CoachID, ScheduledAt: f.scheduledAt.Add(-30 * time.Minute), DurationMinutes: 60, Status: "scheduled"})
+				return f.clientUserID, BookSessionInput{ClientProfileID: f.client.ID, SessionTypeID: f.sessionType.ID, ScheduledAt: f.scheduledAt.Format(time.RFC3339)}
+			},
+			want: ErrSessionConflict,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			f := newBookingFixture(t)
+			userID, input := tc.setup(t, f)
+			input.SkipSuggestedSlots = true
+
+			_, _, err := f.service.BookSession(context.Background(), userID, input)
+			if !errors.Is(err, tc.want) {
+				t.Fatalf("err = %v, want %v", err, tc.want)
+			}
+			if f.transactor.Calls != 0 {
+				t.Errorf("a rejected booking opened %d transactions", f.transactor.Calls)
+			}
+		})
+	}
+}
+
+// Valid bookings pass every check and only then write, inside a transaction.
+func TestBookSessionValidBookingReachesTransaction(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		coachOnly bool
+		asCoach   bool
+	}{
+		{name: "client books themselves"},
+		{name: "coach books a coach-only type for the client", coachOnly: true, asCoach: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			f := newBookingFixture(t)
+			typeID := f.sessionType.ID
+			if tc.coachOnly {
+				typeID = f.addSessionType(t, models.SessionType{Name: "Assessment", DurationMinutes: 60, IsActive: true, BookableByClient: false})
+			}
+			userID := f.clientUserID
+			if tc.asCoach {
+				userID = f.coachUserID
+			}
+
+			_, _, err := f.service.BookSession(context.Background(), userID, BookSessionInput{
+				ClientProfileID:    f.client.ID,
+				SessionTypeID:      typeID,
+				ScheduledAt:        f.scheduledAt.Format(time.RFC3339),
+				SkipSuggestedSlots: true,
+			})
+			if !errors.Is(err, fakes.ErrTransactionsUnsupported) {
+				t.Fatalf("err = %v, want the booking to reach its transaction", err)
+			}
+			if f.transactor.Calls != 1 {
+				t.Errorf("transactions = %d, want 1", f.transactor.Calls)
+			}
+		})
+	}
+}
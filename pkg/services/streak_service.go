@@ -0,0 +1,90 @@
+package services
+
+import (
+	"chalk-api/pkg/events"
+	"chalk-api/pkg/models"
+	"chalk-api/pkg/repositories"
+	"chalk-api/pkg/utils"
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ClientStreaks is the streak and milestone summary shown on the client app's home screen.
+type ClientStreaks struct {
+	WeeklyTarget         int                      `json:"weekly_target"`
+	CurrentWeeks         int                      `json:"current_weeks"`
+	LongestWeeks         int                      `json:"longest_weeks"`
+	CurrentStreakEndWeek *string                  `json:"current_streak_end_week"`
+	TotalCompleted       int                      `json:"total_completed"`
+	LastCompletedAt      *time.Time               `json:"last_completed_at"`
+	NextMilestone        *int                     `json:"next_milestone"` // null once every milestone is reached
+	Milestones           []models.ClientMilestone `json:"milestones"`
+}
+
+type StreakService struct {
+	repos *repositories.RepositoriesCollection
+}
+
+func NewStreakService(repos *repositories.RepositoriesCollection) *StreakService {
+	return &StreakService{repos: repos}
+}
+
+// GetMyStreaks returns the calling user's workout streak across all of their coaches. The stored
+// streak is only rebuilt when a workout is completed, so one whose last week is before last week is
+// reported as broken here.
+func (s *StreakService) GetMyStreaks(ctx context.Context, userID uint) (*ClientStreaks, error) {
+	streaks := &ClientStreaks{
+		WeeklyTarget: events.StreakWeeklyTarget,
+		Milestones:   []models.ClientMilestone{},
+	}
+
+	streak, err := s.repos.Progress.GetStreak(ctx, userID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if streak != nil {
+		streaks.WeeklyTarget = streak.WeeklyTarget
+		streaks.LongestWeeks = streak.LongestWeeks
+		streaks.TotalCompleted = streak.TotalCompleted
+		streaks.LastCompletedAt = streak.LastCompletedAt
+		if streak.CurrentStreakEndWeek != nil && !streakLapsed(*streak.CurrentStreakEndWeek, streak.Timezone, time.Now()) {
+			streaks.CurrentWeeks = streak.CurrentWeeks
+			streaks.CurrentStreakEndWeek = streak.CurrentStreakEndWeek
+		}
+	}
+
+	milestones, err := s.repos.Progress.ListMilestones(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if milestones != nil {
+		streaks.Milestones = milestones
+	}
+
+	for _, value := range events.TotalWorkoutMilestones {
+		if value > streaks.TotalCompleted {
+			next := value
+			streaks.NextMilestone = &next
+			break
+		}
+	}
+	return streaks, nil
+}
+
+// streakLapsed reports whether a streak whose last week starts on endWeek missed last week, with
+// weeks in tz.
+func streakLapsed(endWeek, tz string, now time.Time) bool {
+	loc := utils.ResolveLocation(tz)
+	end, err := time.Parse("2006-01-02", endWeek)
+	if err != nil {
+		return true
+	}
+	thisWeek, err := time.Parse("2006-01-02", events.WeekStart(now, loc))
+	if err != nil {
+		return true
+	}
+	return end.Before(thisWeek.AddDate(0, 0, -7))
+}
@@ -1,10 +1,16 @@
 package services
 
 import (
+	"chalk-api/pkg/events"
+	"chalk-api/pkg/external/breaker"
 	"chalk-api/pkg/external/revenuecat"
 	"chalk-api/pkg/models"
 	"chalk-api/pkg/repositories"
+	"chalk-api/pkg/stores"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
@@ -22,12 +28,19 @@ var (
 	ErrFeatureNameRequired            = errors.New("feature name is required")
 )
 
-const freeTierClientLimit = 3
+const (
+	freeTierClientLimit = 3
+
+	// Processed and ignored webhook inbox entries are kept this long for support and replay
+	webhookInboxRetention = 180 * 24 * time.Hour
+)
 
 type SubscriptionService struct {
 	repos                 *repositories.RepositoriesCollection
 	subscriptionRepo      *repositories.SubscriptionRepository
+	subscriptionStore     *stores.SubscriptionStore
 	revenueCat            revenuecat.API
+	events                *events.Publisher
 	supportedWebhookTypes map[string]struct{}
 }
 
@@ -41,11 +54,15 @@ type FeatureAccessResult struct {
 func NewSubscriptionService(
 	repos *repositories.RepositoriesCollection,
 	revenueCatAPI revenuecat.API,
+	eventsPublisher *events.Publisher,
+	subscriptionStore *stores.SubscriptionStore,
 ) *SubscriptionService {
 	return &SubscriptionService{
-		repos:            repos,
-		subscriptionRepo: repos.Subscription,
-		revenueCat:       revenueCatAPI,
+		repos:             repos,
+		subscriptionRepo:  repos.Subscription,
+		subscriptionStore: subscriptionStore,
+		revenueCat:        revenueCatAPI,
+		events:            eventsPublisher,
 		supportedWebhookTypes: map[string]struct{}{
 			revenuecat.EventTypeTest:                 {},
 			revenuecat.EventTypeInitialPurchase:      {},
@@ -61,10 +78,16 @@ func NewSubscriptionService(
 	}
 }
 
+// HandleRevenueCatWebhook authenticates a webhook delivery, stores it in the webhook inbox and queues
+// it on the outbox in the same transaction, so the request is acknowledged without waiting on
+// RevenueCat's subscriber API. Unauthenticated requests are rejected before anything is stored;
+// malformed payloads are kept as failed entries, and TEST or unsupported events as ignored ones.
+// Redeliveries of a stored event are deduped on the inbox; ProcessRevenueCatWebhook applies the event.
 func (s *SubscriptionService) HandleRevenueCatWebhook(
 	ctx context.Context,
 	rawBody []byte,
 	authorizationHeader string,
+	headers map[string]string,
 ) error {
 	if s.revenueCat == nil {
 		return fmt.Errorf("revenuecat integration is not configured")
@@ -76,18 +99,143 @@ func (s *SubscriptionService) HandleRevenueCatWebhook(
 		if strings.Contains(errLower, "authorization") || strings.Contains(errLower, "missing webhook") {
 			return ErrInvalidSubscriptionWebhookAuth
 		}
+		entry := newRevenueCatInboxEntry(webhookBodyKey(rawBody), nil, rawBody, headers)
+		entry.Status = models.WebhookInboxStatusFailed
+		entry.Error = strPtr(err.Error())
+		if _, storeErr := s.repos.WebhookInbox.Create(ctx, entry); storeErr != nil {
+			slog.Error("Failed to store malformed RevenueCat webhook", "error", storeErr)
+		}
 		return ErrSubscriptionWebhookPayload
 	}
 
-	if _, supported := s.supportedWebhookTypes[webhookEvent.Event.Type]; !supported {
-		// Ignore events we intentionally do not process yet.
-		return nil
+	key := strings.TrimSpace(webhookEvent.Event.ID)
+	if key == "" {
+		// Without an event ID, identical bodies are the best signal of a redelivery
+		key = webhookBodyKey(rawBody)
 	}
+	entry := newRevenueCatInboxEntry(key, trimToPtr(webhookEvent.Event.Type), rawBody, headers)
 
-	if webhookEvent.Event.Type == revenuecat.EventTypeTest {
-		return nil
+	if !s.appliesWebhookType(webhookEvent.Event.Type) {
+		// Ignore events we intentionally do not process yet; a replay picks them up once supported.
+		entry.Status = models.WebhookInboxStatusIgnored
+		entry.ProcessedAt = &entry.ReceivedAt
+		_, err := s.repos.WebhookInbox.Create(ctx, entry)
+		return err
 	}
 
+	if s.events == nil {
+		// Applied inline, so a redelivery runs again; applyRevenueCatWebhook skips recorded events
+		if _, err := s.repos.WebhookInbox.Create(ctx, entry); err != nil {
+			return err
+		}
+		applyErr := s.applyRevenueCatWebhook(ctx, webhookEvent, rawBody)
+		s.recordWebhookResult(ctx, key, applyErr)
+		return applyErr
+	}
+
+	return s.repos.WithTransaction(ctx, func(tx *gorm.DB, txRepos *repositories.RepositoriesCollection) error {
+		created, err := txRepos.WebhookInbox.Create(ctx, entry)
+		if err != nil || !created {
+			return err
+		}
+		return s.events.PublishInTx(
+			ctx,
+			tx,
+			events.EventTypeSubscriptionWebhook,
+			"revenuecat_event",
+			key,
+			events.BuildIdempotencyKey(events.EventTypeSubscriptionWebhook, key),
+			json.RawMessage(rawBody),
+		)
+	})
+}
+
+// ProcessRevenueCatWebhook is the outbox handler for queued webhooks: it syncs the subscriber from
+// RevenueCat and applies the event to the user's subscription, recording each attempt's outcome on
+// the inbox entry. Events already recorded in subscription_events are skipped, so a retried or
+// duplicated delivery is applied once.
+func (s *SubscriptionService) ProcessRevenueCatWebhook(ctx context.Context, event models.OutboxEvent) error {
+	var webhookEvent revenuecat.WebhookEvent
+	if err := json.Unmarshal([]byte(event.Payload), &webhookEvent); err != nil {
+		err = fmt.Errorf("decode queued revenuecat webhook: %w", err)
+		s.recordWebhookResult(ctx, event.AggregateID, err)
+		return events.Permanent(err)
+	}
+	err := s.applyRevenueCatWebhook(ctx, &webhookEvent, []byte(event.Payload))
+	s.recordWebhookResult(ctx, event.AggregateID, err)
+	return err
+}
+
+// ReplayRevenueCatWebhook re-runs a stored RevenueCat delivery inline against its saved body and
+// records the outcome on the entry. Authorization is not checked again since only authenticated
+// deliveries are stored. The returned error is for failing to record the outcome; a failed replay
+// is reported through the entry's status and error.
+func (s *SubscriptionService) ReplayRevenueCatWebhook(ctx context.Context, entry *models.WebhookInboxEntry) error {
+	var webhookEvent revenuecat.WebhookEvent
+	if err := json.Unmarshal([]byte(entry.Body), &webhookEvent); err != nil {
+		return s.repos.WebhookInbox.MarkResult(ctx, entry.Provider, entry.ExternalEventID, models.WebhookInboxStatusFailed, fmt.Errorf("decode stored revenuecat webhook: %w", err))
+	}
+	if !s.appliesWebhookType(webhookEvent.Event.Type) {
+		return s.repos.WebhookInbox.MarkResult(ctx, entry.Provider, entry.ExternalEventID, models.WebhookInboxStatusIgnored, nil)
+	}
+
+	applyErr := s.applyRevenueCatWebhook(ctx, &webhookEvent, []byte(entry.Body))
+	if applyErr != nil {
+		slog.Warn("RevenueCat webhook replay failed", "inbox_id", entry.ID, "error", applyErr)
+		return s.repos.WebhookInbox.MarkResult(ctx, entry.Provider, entry.ExternalEventID, models.WebhookInboxStatusFailed, applyErr)
+	}
+	return s.repos.WebhookInbox.MarkResult(ctx, entry.Provider, entry.ExternalEventID, models.WebhookInboxStatusProcessed, nil)
+}
+
+// PruneWebhookInbox deletes processed and ignored inbox entries past the 180-day retention window.
+func (s *SubscriptionService) PruneWebhookInbox(ctx context.Context, now time.Time) (int64, error) {
+	return s.repos.WebhookInbox.DeleteProcessedBefore(ctx, now.Add(-webhookInboxRetention))
+}
+
+// appliesWebhookType reports whether events of eventType change subscriptions. TEST events are
+// supported in the sense that they are acked, but there is nothing to apply.
+func (s *SubscriptionService) appliesWebhookType(eventType string) bool {
+	if eventType == revenuecat.EventTypeTest {
+		return false
+	}
+	_, supported := s.supportedWebhookTypes[eventType]
+	return supported
+}
+
+// recordWebhookResult marks the RevenueCat inbox entry for key as processed or failed. Failing to do
+// so is only logged; the subscription change it describes has already happened or been rolled back.
+func (s *SubscriptionService) recordWebhookResult(ctx context.Context, key string, processErr error) {
+	status := models.WebhookInboxStatusProcessed
+	if processErr != nil {
+		status = models.WebhookInboxStatusFailed
+	}
+	if err := s.repos.WebhookInbox.MarkResult(ctx, models.WebhookProviderRevenueCat, key, status, processErr); err != nil {
+		slog.Error("Failed to update webhook inbox entry", "provider", models.WebhookProviderRevenueCat, "external_event_id", key, "error", err)
+	}
+}
+
+func newRevenueCatInboxEntry(externalEventID string, eventType *string, rawBody []byte, headers map[string]string) *models.WebhookInboxEntry {
+	return &models.WebhookInboxEntry{
+		Provider:        models.WebhookProviderRevenueCat,
+		ExternalEventID: externalEventID,
+		EventType:       eventType,
+		Body:            string(rawBody),
+		Headers:         headers,
+		Status:          models.WebhookInboxStatusPending,
+		ReceivedAt:      time.Now().UTC(),
+	}
+}
+
+func webhookBodyKey(rawBody []byte) string {
+	sum := sha256.Sum256(rawBody)
+	return "body-" + hex.EncodeToString(sum[:])
+}
+
+func (s *SubscriptionService) applyRevenueCatWebhook(
+	ctx context.Context,
+	webhookEvent *revenuecat.WebhookEvent,
+	rawBody []byte,
+) error {
 	lookupAppUserID := deriveLookupAppUserID(&webhookEvent.Event)
 	userID := deriveLocalUserID(&webhookEvent.Event)
 	eventID := strings.TrimSpace(webhookEvent.Event.ID)
@@ -116,7 +264,7 @@ func (s *SubscriptionService) HandleRevenueCatWebhook(
 		return nil
 	}
 
-	return s.repos.WithTransaction(ctx, func(tx *gorm.DB, txRepos *repositories.RepositoriesCollection) error {
+	err := s.repos.WithTransaction(ctx, func(tx *gorm.DB, txRepos *repositories.RepositoriesCollection) error {
 		if eventID != "" {
 			if _, err := txRepos.Subscription.GetEventByRevenueCatID(ctx, eventID); err == nil {
 				return nil
@@ -164,6 +312,11 @@ func (s *SubscriptionService) HandleRevenueCatWebhook(
 
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+	s.subscriptionStore.Invalidate(userID)
+	return nil
 }
 
 func (s *SubscriptionService) GetMySubscription(ctx context.Context, userID uint) (*models.Subscription, error) {
@@ -181,6 +334,22 @@ func (s *SubscriptionService) GetMySubscription(ctx context.Context, userID uint
 	return sub, nil
 }
 
+// GetCachedSubscription returns the user's subscription status through the Redis cache, for reads
+// on every app start. Webhook updates invalidate the entry, so stores.SubscriptionTTL only bounds
+// how long a lost invalidation can go unnoticed.
+func (s *SubscriptionService) GetCachedSubscription(ctx context.Context, userID uint) (*stores.CachedSubscription, error) {
+	if cached, ok := s.subscriptionStore.Get(userID); ok {
+		return cached, nil
+	}
+
+	sub, err := s.GetMySubscription(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	s.subscriptionStore.Set(sub)
+	return stores.ToCachedSubscription(sub), nil
+}
+
 func (s *SubscriptionService) CheckFeatureAccess(ctx context.Context, userID uint, feature string) (*FeatureAccessResult, error) {
 	normalizedFeature := strings.TrimSpace(strings.ToLower(feature))
 	if normalizedFeature == "" {
@@ -268,7 +437,14 @@ func (s *SubscriptionService) fetchSubscriber(ctx context.Context, appUserID str
 	if s.revenueCat == nil {
 		return nil, nil
 	}
-	return s.revenueCat.GetSubscriber(appUserID)
+
+	subscriber, err := s.revenueCat.GetSubscriber(ctx, appUserID)
+	if errors.Is(err, breaker.ErrOpen) {
+		// RevenueCat is known to be down; continue on the webhook payload without a warning per event
+		slog.Info("RevenueCat circuit open, skipping subscriber sync", "app_user_id", appUserID)
+		return nil, nil
+	}
+	return subscriber, err
 }
 
 func applyWebhookToSubscription(
@@ -0,0 +1,234 @@
+package services
+
+import (
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/crypto/hkdf"
+)
+
+var ErrTokenKeysNotConfigured = errors.New("JWT signing key is not configured")
+
+// TokenKeyConfig is the raw key material from the environment. PEM values may use literal "\n"
+// escapes so they fit in a single env var.
+type TokenKeyConfig struct {
+	// HMACSecret signs HS256 tokens when no RSA key is set. With an RSA key it is only used to
+	// verify HS256 tokens issued before the switch; unset it once those have expired.
+	HMACSecret string
+	// PrivateKeyPEM is an RSA private key (PKCS#1 or PKCS#8) used to sign RS256 tokens.
+	PrivateKeyPEM string
+	// PreviousPublicKeysPEM holds retired RSA public keys that still verify tokens during rotation.
+	PreviousPublicKeysPEM string
+}
+
+// JWK is one RSA public key in a JSON Web Key Set.
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// VerifiedAccessToken holds the claims of an access token whose signature and expiry checked out.
+type VerifiedAccessToken struct {
+	UserID   uint
+	IssuedAt time.Time
+}
+
+// TokenKeys signs access tokens with the active key and verifies tokens against every published
+// key. It is built once at startup and shared by AuthService and the auth middleware.
+type TokenKeys struct {
+	hmacSecret []byte
+	signingKey *rsa.PrivateKey
+	signingKID string
+	publicKeys map[string]*rsa.PublicKey // kid -> key, active key included
+	jwks       JWKSet
+}
+
+// NewTokenKeys parses the configured key material. Key IDs are RFC 7638 thumbprints, so a retired
+// key keeps the same kid when it moves to the previous-keys list.
+func NewTokenKeys(cfg TokenKeyConfig) (*TokenKeys, error) {
+	keys := &TokenKeys{
+		hmacSecret: []byte(strings.TrimSpace(cfg.HMACSecret)),
+		publicKeys: make(map[string]*rsa.PublicKey),
+		jwks:       JWKSet{Keys: []JWK{}},
+	}
+
+	if privatePEM := normalizePEM(cfg.PrivateKeyPEM); privatePEM != "" {
+		privateKey, err := parseRSAPrivateKey(privatePEM)
+		if err != nil {
+			return nil, fmt.Errorf("parse JWT private key: %w", err)
+		}
+		keys.signingKey = privateKey
+		keys.signingKID = keys.publish(&privateKey.PublicKey)
+	}
+
+	rest := []byte(normalizePEM(cfg.PreviousPublicKeysPEM))
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		publicKey, err := parseRSAPublicKey(block)
+		if err != nil {
+			return nil, fmt.Errorf("parse previous JWT public key: %w", err)
+		}
+		keys.publish(publicKey)
+	}
+
+	return keys, nil
+}
+
+// DeriveKey returns a 32-byte key for purpose, derived with HKDF-SHA256 from the active signing key
+// (the RSA private key, or the HMAC secret without one). Every instance sharing the JWT
+// configuration derives the same key, and keys for different purposes are independent of each
+// other and of the token signatures.
+func (k *TokenKeys) DeriveKey(purpose string) ([]byte, error) {
+	var secret []byte
+	switch {
+	case k.signingKey != nil:
+		secret = x509.MarshalPKCS1PrivateKey(k.signingKey)
+	case len(k.hmacSecret) > 0:
+		secret = k.hmacSecret
+	default:
+		return nil, ErrTokenKeysNotConfigured
+	}
+
+	key := make([]byte, 32)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, secret, nil, []byte("chalk-api "+purpose)), key); err != nil {
+		return nil, fmt.Errorf("derive %s key: %w", purpose, err)
+	}
+	return key, nil
+}
+
+// Configured reports whether tokens can be signed.
+func (k *TokenKeys) Configured() bool {
+	return k != nil && (k.signingKey != nil || len(k.hmacSecret) > 0)
+}
+
+// JWKS returns the published public keys. It is empty in HS256-only mode.
+func (k *TokenKeys) JWKS() JWKSet {
+	return k.jwks
+}
+
+// Sign issues a token with the active key: RS256 with a kid header when an RSA key is configured,
+// otherwise HS256.
+func (k *TokenKeys) Sign(claims jwt.Claims) (string, error) {
+	if k.signingKey != nil {
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		token.Header["kid"] = k.signingKID
+		return token.SignedString(k.signingKey)
+	}
+	if len(k.hmacSecret) == 0 {
+		return "", ErrTokenKeysNotConfigured
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(k.hmacSecret)
+}
+
+// ValidateAccessToken checks a token's signature against every published key and its expiry.
+// It does not consult the revocation denylist; callers do that with the returned claims.
+func (k *TokenKeys) ValidateAccessToken(tokenString string) (*VerifiedAccessToken, error) {
+	if strings.TrimSpace(tokenString) == "" {
+		return nil, ErrInvalidCredentials
+	}
+
+	claims := &accessTokenClaims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, k.verificationKey, jwt.WithIssuedAt())
+	if err != nil || token == nil || !token.Valid {
+		return nil, ErrInvalidCredentials
+	}
+
+	if claims.UserID == 0 || claims.IssuedAt == nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	return &VerifiedAccessToken{UserID: claims.UserID, IssuedAt: claims.IssuedAt.Time}, nil
+}
+
+func (k *TokenKeys) verificationKey(token *jwt.Token) (any, error) {
+	switch token.Method {
+	case jwt.SigningMethodRS256:
+		kid, _ := token.Header["kid"].(string)
+		publicKey, ok := k.publicKeys[kid]
+		if !ok {
+			return nil, fmt.Errorf("unknown key id")
+		}
+		return publicKey, nil
+	case jwt.SigningMethodHS256:
+		if len(k.hmacSecret) == 0 {
+			return nil, fmt.Errorf("HS256 tokens are not accepted")
+		}
+		return k.hmacSecret, nil
+	default:
+		return nil, fmt.Errorf("unexpected signing method")
+	}
+}
+
+func (k *TokenKeys) publish(publicKey *rsa.PublicKey) string {
+	n := base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes())
+	e := base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes())
+	// RFC 7638: the thumbprint covers the required members in lexicographic order.
+	sum := sha256.Sum256([]byte(`{"e":"` + e + `","kty":"RSA","n":"` + n + `"}`))
+	kid := base64.RawURLEncoding.EncodeToString(sum[:])
+
+	if _, exists := k.publicKeys[kid]; !exists {
+		k.publicKeys[kid] = publicKey
+		k.jwks.Keys = append(k.jwks.Keys, JWK{Kty: "RSA", Use: "sig", Alg: "RS256", Kid: kid, N: n, E: e})
+	}
+	return kid
+}
+
+func normalizePEM(raw string) string {
+	return strings.TrimSpace(strings.ReplaceAll(raw, `\n`, "\n"))
+}
+
+func parseRSAPrivateKey(raw string) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(raw))
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("not an RSA private key")
+	}
+	return key, nil
+}
+
+func parseRSAPublicKey(block *pem.Block) (*rsa.PublicKey, error) {
+	if block.Type == "RSA PUBLIC KEY" {
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	}
+	parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	key, ok := parsed.(*rsa.PublicKey)
+	if !ok {
+		return nil, errors.New("not an RSA public key")
+	}
+	return key, nil
+}
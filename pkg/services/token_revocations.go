@@ -0,0 +1,61 @@
+package services
+
+import (
+	"chalk-api/pkg/repositories"
+	"chalk-api/pkg/stores"
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TokenRevocations is the access token denylist. Revoking records a per-user cut-off; access tokens
+// issued before it are rejected until they would have expired anyway. Redis answers most lookups
+// and the access_token_revocations table backs it.
+type TokenRevocations struct {
+	authRepo *repositories.AuthRepository
+	store    *stores.SecurityStore
+	ttl      time.Duration // access token lifetime; older cut-offs no longer matter
+}
+
+func NewTokenRevocations(authRepo *repositories.AuthRepository, store *stores.SecurityStore, accessTokenTTL time.Duration) *TokenRevocations {
+	return &TokenRevocations{
+		authRepo: authRepo,
+		store:    store,
+		ttl:      accessTokenTTL,
+	}
+}
+
+// RevokeAll rejects every access token the user was issued before at. The cut-off is kept to the
+// second, matching the iat claim, so a token issued right after revoking stays valid.
+func (r *TokenRevocations) RevokeAll(ctx context.Context, userID uint, at time.Time) error {
+	revokedAt := at.UTC().Truncate(time.Second)
+	if err := r.authRepo.RecordTokenRevocation(ctx, userID, revokedAt); err != nil {
+		return err
+	}
+	r.store.SetTokensRevokedAt(userID, revokedAt, r.ttl)
+	return nil
+}
+
+// IsRevoked reports whether a token issued at issuedAt was revoked. It fails open only when
+// neither Redis nor the database can answer.
+func (r *TokenRevocations) IsRevoked(ctx context.Context, userID uint, issuedAt time.Time) bool {
+	revokedAt, found := r.store.GetTokensRevokedAt(userID)
+	if !found {
+		revocation, err := r.authRepo.GetTokenRevocation(ctx, userID)
+		switch {
+		case err == nil:
+			revokedAt = revocation.RevokedAt
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			// Never revoked; cached as a zero cut-off below.
+		default:
+			slog.Warn("Token revocation lookup failed, allowing request", "user_id", userID, "error", err)
+			return false
+		}
+		r.store.SetTokensRevokedAt(userID, revokedAt, r.ttl)
+	}
+
+	return !revokedAt.IsZero() && issuedAt.Before(revokedAt)
+}
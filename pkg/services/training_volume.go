@@ -0,0 +1,197 @@
+package services
+
+import (
+	"chalk-api/pkg/repositories"
+	"chalk-api/pkg/units"
+	"chalk-api/pkg/utils"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+var ErrInvalidVolumeWeeks = errors.New("invalid training volume range")
+
+const (
+	defaultVolumeWeeks = 8
+	maxVolumeWeeks     = 52
+)
+
+// MuscleGroupVolume is the work one primary muscle group got in a week. Volume is the tonnage
+// (reps x weight) in the report's weight unit.
+type MuscleGroupVolume struct {
+	MuscleGroup string  `json:"muscle_group"`
+	Sets        int     `json:"sets"`
+	Volume      float64 `json:"volume"`
+}
+
+// TrainingVolumeWeek totals one Monday-to-Sunday week. Every muscle group in the report is listed,
+// with zeros when it wasn't trained that week. Sets counts each logged set once, even when its
+// exercise works several muscle groups.
+type TrainingVolumeWeek struct {
+	WeekStart    string              `json:"week_start"`
+	ISOWeek      string              `json:"iso_week"` // e.g. "2026-W42"
+	Sets         int                 `json:"sets"`
+	Volume       float64             `json:"volume"`
+	MuscleGroups []MuscleGroupVolume `json:"muscle_groups"`
+}
+
+// TrainingVolumeReport is the completed training of a client per week and primary muscle group,
+// oldest week first. Weeks with nothing logged are included as zeros so charts have no gaps.
+type TrainingVolumeReport struct {
+	StartDate    string               `json:"start_date"`
+	EndDate      string               `json:"end_date"`
+	Timezone     string               `json:"timezone"`    // the client's; weeks are their calendar weeks
+	WeightUnit   string               `json:"weight_unit"` // the viewer's preferred unit
+	MuscleGroups []string             `json:"muscle_groups"`
+	Weeks        []TrainingVolumeWeek `json:"weeks"`
+}
+
+// GetMyTrainingVolume reports the caller's training volume for the last `weeks` weeks, including the
+// current one, across all of their coaches. Pass 0 for the default of 8.
+func (s *ReportService) GetMyTrainingVolume(ctx context.Context, userID uint, weeks int) (*TrainingVolumeReport, error) {
+	weeks, err := normalizeVolumeWeeks(weeks)
+	if err != nil {
+		return nil, err
+	}
+
+	profiles, err := s.clientRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	clientIDs := make([]uint, 0, len(profiles))
+	for i := range profiles {
+		clientIDs = append(clientIDs, profiles[i].ID)
+	}
+
+	timezone, err := s.userRepo.GetTimezone(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.buildTrainingVolume(ctx, userID, clientIDs, timezone, weeks)
+}
+
+// GetClientTrainingVolume is GetMyTrainingVolume for one of the calling coach's clients, covering
+// only the workouts assigned by that coach. Weights are shown in the coach's preferred unit.
+func (s *ReportService) GetClientTrainingVolume(ctx context.Context, userID, clientID uint, weeks int) (*TrainingVolumeReport, error) {
+	weeks, err := normalizeVolumeWeeks(weeks)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := s.getOwnedClient(ctx, userID, clientID)
+	if err != nil {
+		return nil, err
+	}
+	timezone := ""
+	if client.User.Profile != nil {
+		timezone = client.User.Profile.Timezone
+	}
+	return s.buildTrainingVolume(ctx, userID, []uint{client.ID}, timezone, weeks)
+}
+
+func normalizeVolumeWeeks(weeks int) (int, error) {
+	if weeks == 0 {
+		return defaultVolumeWeeks, nil
+	}
+	if weeks < 1 || weeks > maxVolumeWeeks {
+		return 0, ErrInvalidVolumeWeeks
+	}
+	return weeks, nil
+}
+
+func (s *ReportService) buildTrainingVolume(ctx context.Context, viewerID uint, clientIDs []uint, timezone string, weeks int) (*TrainingVolumeReport, error) {
+	prefs, err := s.userRepo.GetUnitPreferences(ctx, viewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	loc := utils.ResolveLocation(timezone)
+	today := time.Now().In(loc)
+	today = time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)
+	currentWeek := today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7))
+	start := currentWeek.AddDate(0, 0, -7*(weeks-1))
+	startDate, endDate := start.Format(calendarDateLayout), today.Format(calendarDateLayout)
+
+	volumes, err := s.workoutRepo.ListWeeklyMuscleGroupVolume(ctx, clientIDs, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	// Sets per week are counted once per logged set, not once per muscle group
+	totals, err := s.workoutRepo.ListWeeklyVolume(ctx, clientIDs, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TrainingVolumeReport{
+		StartDate:    startDate,
+		EndDate:      endDate,
+		Timezone:     loc.String(),
+		WeightUnit:   prefs.Weight,
+		MuscleGroups: volumeMuscleGroups(volumes),
+		Weeks:        buildVolumeWeeks(start, weeks, volumes, totals, prefs.Weight),
+	}, nil
+}
+
+// volumeMuscleGroups lists the muscle groups trained in the window, sorted.
+func volumeMuscleGroups(volumes []repositories.MuscleGroupWeeklyVolume) []string {
+	seen := make(map[string]bool)
+	groups := []string{}
+	for _, volume := range volumes {
+		if !seen[volume.MuscleGroup] {
+			seen[volume.MuscleGroup] = true
+			groups = append(groups, volume.MuscleGroup)
+		}
+	}
+	sort.Strings(groups)
+	return groups
+}
+
+// buildVolumeWeeks emits every week from start, trained or not, with every muscle group of the window.
+func buildVolumeWeeks(start time.Time, weeks int, volumes []repositories.MuscleGroupWeeklyVolume, totals []repositories.WeeklyVolume, weightUnit string) []TrainingVolumeWeek {
+	groups := volumeMuscleGroups(volumes)
+	byWeek := make(map[string]map[string]repositories.MuscleGroupWeeklyVolume)
+	for _, volume := range volumes {
+		if byWeek[volume.WeekStart] == nil {
+			byWeek[volume.WeekStart] = make(map[string]repositories.MuscleGroupWeeklyVolume)
+		}
+		byWeek[volume.WeekStart][volume.MuscleGroup] = volume
+	}
+	totalsByWeek := make(map[string]repositories.WeeklyVolume, len(totals))
+	for _, total := range totals {
+		totalsByWeek[total.WeekStart] = total
+	}
+
+	result := make([]TrainingVolumeWeek, 0, weeks)
+	for i := 0; i < weeks; i++ {
+		weekStart := start.AddDate(0, 0, 7*i)
+		key := weekStart.Format(calendarDateLayout)
+		year, isoWeek := weekStart.ISOWeek()
+
+		week := TrainingVolumeWeek{
+			WeekStart:    key,
+			ISOWeek:      fmt.Sprintf("%d-W%02d", year, isoWeek),
+			Sets:         totalsByWeek[key].Sets,
+			Volume:       volumeInUnit(totalsByWeek[key].VolumeKg, weightUnit),
+			MuscleGroups: make([]MuscleGroupVolume, 0, len(groups)),
+		}
+		for _, group := range groups {
+			volume := byWeek[key][group]
+			week.MuscleGroups = append(week.MuscleGroups, MuscleGroupVolume{
+				MuscleGroup: group,
+				Sets:        volume.Sets,
+				Volume:      volumeInUnit(volume.VolumeKg, weightUnit),
+			})
+		}
+		result = append(result, week)
+	}
+	return result
+}
+
+// volumeInUnit converts a tonnage in kilograms to weightUnit, to one decimal.
+func volumeInUnit(volumeKg float64, weightUnit string) float64 {
+	volume, _ := units.ConvertWeight(volumeKg, units.Kilograms, weightUnit)
+	return math.Round(volume*10) / 10
+}
@@ -1,16 +1,53 @@
 package services
 
 import (
+	"chalk-api/pkg/events"
+	"chalk-api/pkg/external/storage"
+	"chalk-api/pkg/i18n"
+	"chalk-api/pkg/imaging"
 	"chalk-api/pkg/models"
 	"chalk-api/pkg/repositories"
+	"chalk-api/pkg/units"
+	"chalk-api/pkg/utils"
 	"context"
 	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	"gorm.io/gorm"
 )
 
-var ErrUserNotFound = errors.New("user not found")
+var (
+	ErrUserNotFound        = errors.New("user not found")
+	ErrInvalidTimezone     = errors.New("invalid IANA timezone")
+	ErrInvalidLocale       = errors.New("unsupported locale")
+	ErrInvalidUnit         = errors.New("unsupported unit")
+	ErrInvalidReminderHour = errors.New("invalid workout reminder hour")
+	ErrProfileNameRequired = errors.New("first and last name cannot be empty")
+	ErrUploadTooLarge      = errors.New("uploaded file is too large")
+	ErrUploadContentType   = errors.New("unsupported upload content type")
+	ErrStorageUnavailable  = errors.New("file uploads are not available")
+
+	ErrInvalidDeviceToken   = errors.New("invalid Expo push token")
+	ErrDataExportInProgress = errors.New("a data export is already in progress")
+	ErrDataExportNotFound   = errors.New("no data export has been requested")
+)
+
+const (
+	MaxAvatarBytes     = 5 << 20
+	avatarMaxDimension = 512
+	avatarJPEGQuality  = 85
+
+	// An export still pending after this long is assumed lost (dispatcher gave up) so the user can retry.
+	dataExportStaleAfter = 24 * time.Hour
+)
+
+// Avatars are re-encoded as JPEG, so only formats the standard library can decode are accepted.
+var allowedAvatarContentTypes = []string{"image/jpeg", "image/png"}
 
 type UpdateMeInput struct {
 	FirstName *string `json:"first_name"`
@@ -18,26 +55,65 @@ type UpdateMeInput struct {
 	Phone     *string `json:"phone"`
 	AvatarURL *string `json:"avatar_url"`
 	Timezone  *string `json:"timezone"`
+	Locale    *string `json:"locale"`
+
+	WeightUnit   *string `json:"weight_unit"`   // "kg" or "lbs"
+	DistanceUnit *string `json:"distance_unit"` // "km" or "mi"
+
+	// Workout reminder preferences; hours are 0-23 in the user's timezone and the evening follow-up
+	// must come after the morning reminder
+	WorkoutRemindersEnabled       *bool `json:"workout_reminders_enabled"`
+	WorkoutReminderHour           *int  `json:"workout_reminder_hour"`
+	WorkoutEveningReminderEnabled *bool `json:"workout_evening_reminder_enabled"`
+	WorkoutEveningReminderHour    *int  `json:"workout_evening_reminder_hour"`
+}
+
+// RegisterDeviceTokenInput registers the Expo push token of the device the app is running on.
+type RegisterDeviceTokenInput struct {
+	Token      string  `json:"token" binding:"required,max=512"` // "ExponentPushToken[...]"
+	Platform   string  `json:"platform" binding:"required,oneof=ios android"`
+	DeviceName *string `json:"device_name" binding:"omitempty,max=100"`
+	AppVersion *string `json:"app_version" binding:"omitempty,max=50"`
+	OSVersion  *string `json:"os_version" binding:"omitempty,max=50"`
 }
 
 type UserService struct {
-	userRepo   *repositories.UserRepository
-	coachRepo  *repositories.CoachRepository
-	clientRepo *repositories.ClientRepository
+	repos           *repositories.RepositoriesCollection
+	userRepo        *repositories.UserRepository
+	coachRepo       *repositories.CoachRepository
+	clientRepo      *repositories.ClientRepository
+	dataExportRepo  *repositories.DataExportRepository
+	eventsPublisher *events.Publisher
+	storage         storage.API
 }
 
 func NewUserService(
-	userRepo *repositories.UserRepository,
-	coachRepo *repositories.CoachRepository,
-	clientRepo *repositories.ClientRepository,
+	repos *repositories.RepositoriesCollection,
+	eventsPublisher *events.Publisher,
+	storageAPI storage.API,
 ) *UserService {
 	return &UserService{
-		userRepo:   userRepo,
-		coachRepo:  coachRepo,
-		clientRepo: clientRepo,
+		repos:           repos,
+		userRepo:        repos.User,
+		coachRepo:       repos.Coach,
+		clientRepo:      repos.Client,
+		dataExportRepo:  repos.DataExport,
+		eventsPublisher: eventsPublisher,
+		storage:         storageAPI,
 	}
 }
 
+// DataExportStatusResponse is what GET /users/me/export/status returns.
+// Status is one of pending, processing, ready, failed or expired.
+type DataExportStatusResponse struct {
+	ID          uint       `json:"id"`
+	Status      string     `json:"status"`
+	DownloadURL *string    `json:"download_url,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	RequestedAt time.Time  `json:"requested_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
 type ModeCapability struct {
 	Available   bool   `json:"available"`
 	SetupStatus string `json:"setup_status"`
@@ -73,19 +149,68 @@ func (s *UserService) UpdateMe(ctx context.Context, userID uint, input UpdateMeI
 	}
 
 	if input.FirstName != nil {
-		user.Profile.FirstName = strings.TrimSpace(*input.FirstName)
+		firstName := strings.TrimSpace(*input.FirstName)
+		if firstName == "" {
+			return nil, ErrProfileNameRequired
+		}
+		user.Profile.FirstName = firstName
 	}
 	if input.LastName != nil {
-		user.Profile.LastName = strings.TrimSpace(*input.LastName)
+		lastName := strings.TrimSpace(*input.LastName)
+		if lastName == "" {
+			return nil, ErrProfileNameRequired
+		}
+		user.Profile.LastName = lastName
 	}
 	if input.Phone != nil {
-		user.Profile.Phone = input.Phone
+		user.Profile.Phone = trimPtr(input.Phone)
 	}
 	if input.AvatarURL != nil {
 		user.Profile.AvatarURL = input.AvatarURL
 	}
 	if input.Timezone != nil && strings.TrimSpace(*input.Timezone) != "" {
-		user.Profile.Timezone = strings.TrimSpace(*input.Timezone)
+		timezone, err := normalizeTimezone(*input.Timezone)
+		if err != nil {
+			return nil, err
+		}
+		user.Profile.Timezone = timezone
+	}
+	if input.Locale != nil {
+		locale := strings.ToLower(strings.TrimSpace(*input.Locale))
+		if !i18n.Supported(locale) {
+			return nil, ErrInvalidLocale
+		}
+		user.Profile.Locale = locale
+	}
+	if input.WeightUnit != nil {
+		unit, ok := units.NormalizeWeight(*input.WeightUnit)
+		if !ok {
+			return nil, ErrInvalidUnit
+		}
+		user.Profile.WeightUnit = unit
+	}
+	if input.DistanceUnit != nil {
+		// Meters are fine in a log but too fine-grained to show every distance in
+		unit, ok := units.NormalizeDistance(*input.DistanceUnit)
+		if !ok || unit == units.Meters {
+			return nil, ErrInvalidUnit
+		}
+		user.Profile.DistanceUnit = unit
+	}
+	if input.WorkoutRemindersEnabled != nil {
+		user.Profile.WorkoutRemindersEnabled = *input.WorkoutRemindersEnabled
+	}
+	if input.WorkoutReminderHour != nil {
+		user.Profile.WorkoutReminderHour = *input.WorkoutReminderHour
+	}
+	if input.WorkoutEveningReminderEnabled != nil {
+		user.Profile.WorkoutEveningReminderEnabled = *input.WorkoutEveningReminderEnabled
+	}
+	if input.WorkoutEveningReminderHour != nil {
+		user.Profile.WorkoutEveningReminderHour = *input.WorkoutEveningReminderHour
+	}
+	if err := validateReminderHours(user.Profile); err != nil {
+		return nil, err
 	}
 
 	if err := s.userRepo.UpdateProfile(ctx, user.Profile); err != nil {
@@ -132,3 +257,205 @@ func (s *UserService) GetCapabilities(ctx context.Context, userID uint) (*Accoun
 
 	return response, nil
 }
+
+// UploadAvatar validates, resizes and stores a new avatar, then removes the previous object.
+func (s *UserService) UploadAvatar(ctx context.Context, userID uint, data []byte) (*models.User, error) {
+	if !s.storage.IsConfigured() {
+		return nil, ErrStorageUnavailable
+	}
+	if len(data) > MaxAvatarBytes {
+		return nil, ErrUploadTooLarge
+	}
+	// Sniff the bytes rather than trusting the multipart header.
+	if !utils.Contains(allowedAvatarContentTypes, http.DetectContentType(data)) {
+		return nil, ErrUploadContentType
+	}
+	// The header's dimensions are checked before any pixel data is decoded.
+	img, _, err := imaging.Decode(data)
+	if err != nil {
+		if errors.Is(err, imaging.ErrTooManyPixels) {
+			return nil, ErrUploadTooLarge
+		}
+		return nil, ErrUploadContentType
+	}
+	encoded, err := imaging.EncodeJPEG(imaging.ResizeToFit(img, avatarMaxDimension), avatarJPEGQuality)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	if user.Profile == nil {
+		return nil, ErrUserNotFound
+	}
+
+	suffix, err := utils.GenerateRandomString(16)
+	if err != nil {
+		return nil, err
+	}
+	key := fmt.Sprintf("avatars/%d/%s.jpg", userID, suffix)
+	if err := s.storage.PutObject(ctx, key, "image/jpeg", encoded); err != nil {
+		return nil, err
+	}
+
+	previousURL := user.Profile.AvatarURL
+	avatarURL := s.storage.PublicURL(key)
+	user.Profile.AvatarURL = &avatarURL
+	if err := s.userRepo.UpdateProfile(ctx, user.Profile); err != nil {
+		s.deleteStoredObject(ctx, key)
+		return nil, err
+	}
+
+	if previousURL != nil {
+		if previousKey, ok := s.storage.KeyFromURL(*previousURL); ok {
+			s.deleteStoredObject(ctx, previousKey)
+		}
+	}
+
+	return s.userRepo.GetByID(ctx, userID)
+}
+
+// RequestDataExport queues a full export of the user's data. The archive is built by the
+// user.data_export_requested handler; only one export may be pending or processing per user.
+func (s *UserService) RequestDataExport(ctx context.Context, userID uint) (*DataExportStatusResponse, error) {
+	if !s.storage.IsConfigured() {
+		return nil, ErrStorageUnavailable
+	}
+
+	inFlight, err := s.dataExportRepo.GetInFlightByUser(ctx, userID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if inFlight != nil {
+		if time.Since(inFlight.CreatedAt) < dataExportStaleAfter {
+			return nil, ErrDataExportInProgress
+		}
+		if err := s.dataExportRepo.MarkFailed(ctx, inFlight.ID, "timed out"); err != nil {
+			return nil, err
+		}
+	}
+
+	export := &models.DataExport{
+		UserID: userID,
+		Status: models.DataExportStatusPending,
+	}
+	err = s.repos.WithTransaction(ctx, func(tx *gorm.DB, txRepos *repositories.RepositoriesCollection) error {
+		created, err := txRepos.DataExport.Create(ctx, export)
+		if err != nil {
+			return err
+		}
+		if !created {
+			return ErrDataExportInProgress
+		}
+
+		exportID := strconv.FormatUint(uint64(export.ID), 10)
+		return s.eventsPublisher.PublishInTx(
+			ctx,
+			tx,
+			events.EventTypeDataExportRequested,
+			"data_export",
+			exportID,
+			events.BuildIdempotencyKey(events.EventTypeDataExportRequested, exportID),
+			events.DataExportRequestedPayload{
+				ExportID: export.ID,
+				UserID:   userID,
+			},
+		)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return toDataExportStatus(export), nil
+}
+
+// GetDataExportStatus returns the user's most recent export request.
+func (s *UserService) GetDataExportStatus(ctx context.Context, userID uint) (*DataExportStatusResponse, error) {
+	export, err := s.dataExportRepo.GetLatestByUser(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrDataExportNotFound
+		}
+		return nil, err
+	}
+	return toDataExportStatus(export), nil
+}
+
+func toDataExportStatus(export *models.DataExport) *DataExportStatusResponse {
+	response := &DataExportStatusResponse{
+		ID:          export.ID,
+		Status:      export.Status,
+		RequestedAt: export.CreatedAt,
+		CompletedAt: export.CompletedAt,
+	}
+	if export.Status != models.DataExportStatusReady {
+		return response
+	}
+	// Signed links stop working at ExpiresAt, so don't hand out a dead URL.
+	if export.ExpiresAt != nil && time.Now().After(*export.ExpiresAt) {
+		response.Status = "expired"
+		return response
+	}
+	response.DownloadURL = export.DownloadURL
+	response.ExpiresAt = export.ExpiresAt
+	return response
+}
+
+// deleteStoredObject is best-effort: an orphaned object only costs storage, so it must not fail the request.
+// RegisterDeviceToken saves the caller's push token. Registering a token that is already known,
+// including one deactivated earlier, moves it to the caller and reactivates it.
+func (s *UserService) RegisterDeviceToken(ctx context.Context, userID uint, input RegisterDeviceTokenInput) (*models.DeviceToken, error) {
+	token := strings.TrimSpace(input.Token)
+	if !strings.HasPrefix(token, "ExponentPushToken[") && !strings.HasPrefix(token, "ExpoPushToken[") {
+		return nil, ErrInvalidDeviceToken
+	}
+
+	deviceToken := &models.DeviceToken{
+		UserID:     userID,
+		Token:      token,
+		Platform:   input.Platform,
+		DeviceName: trimPtr(input.DeviceName),
+		AppVersion: trimPtr(input.AppVersion),
+		OSVersion:  trimPtr(input.OSVersion),
+	}
+	if err := s.userRepo.AddDeviceToken(ctx, deviceToken); err != nil {
+		return nil, err
+	}
+	return deviceToken, nil
+}
+
+func (s *UserService) deleteStoredObject(ctx context.Context, key string) {
+	if err := s.storage.DeleteObject(ctx, key); err != nil {
+		slog.Warn("Failed to delete stored object", "key", key, "error", err)
+	}
+}
+
+func normalizeTimezone(raw string) (string, error) {
+	timezone := strings.TrimSpace(raw)
+	// time.LoadLocation also accepts "Local", which would silently mean "server timezone".
+	if timezone == "Local" {
+		return "", ErrInvalidTimezone
+	}
+	if _, err := time.LoadLocation(timezone); err != nil {
+		return "", ErrInvalidTimezone
+	}
+	return timezone, nil
+}
+
+// validateReminderHours checks the profile's workout reminder hours are real hours and that the
+// evening follow-up comes after the morning reminder.
+func validateReminderHours(profile *models.Profile) error {
+	morning, evening := profile.WorkoutReminderHour, profile.WorkoutEveningReminderHour
+	if morning < 0 || morning > 23 || evening < 0 || evening > 23 {
+		return ErrInvalidReminderHour
+	}
+	if evening <= morning {
+		return ErrInvalidReminderHour
+	}
+	return nil
+}
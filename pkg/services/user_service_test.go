@@ -0,0 +1,36 @@
+package services
+
+import (
+	"bytes"
+	"chalk-api/pkg/external/storage"
+	"context"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/png"
+	"testing"
+)
+
+// A few hundred bytes of PNG declaring 50000x50000 pixels would need 10 GB once decoded; the upload
+// is refused from the header alone, before the user is looked up or anything is stored.
+func TestUploadAvatarRejectsDecompressionBomb(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 1, 1))); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	binary.BigEndian.PutUint32(data[16:20], 50_000)
+	binary.BigEndian.PutUint32(data[20:24], 50_000)
+	binary.BigEndian.PutUint32(data[29:33], crc32.ChecksumIEEE(data[12:29]))
+
+	users := &UserService{storage: storage.New(storage.Config{
+		Endpoint:        "https://storage.example.test",
+		Bucket:          "avatars",
+		AccessKeyID:     "key",
+		SecretAccessKey: "secret",
+	})}
+	if _, err := users.UploadAvatar(context.Background(), 1, data); !errors.Is(err, ErrUploadTooLarge) {
+		t.Fatalf("UploadAvatar = %v, want ErrUploadTooLarge", err)
+	}
+}
@@ -0,0 +1,37 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrVersionConflict is returned when an edit was made against a copy that has since been updated.
+var ErrVersionConflict = errors.New("record was modified since it was loaded")
+
+// VersionConflictError is ErrVersionConflict with the version now stored, so the app can refetch
+// and merge before retrying.
+type VersionConflictError struct {
+	CurrentVersion int
+}
+
+func (e *VersionConflictError) Error() string {
+	return fmt.Sprintf("%s (current version %d)", ErrVersionConflict, e.CurrentVersion)
+}
+
+func (e *VersionConflictError) Is(target error) bool {
+	return target == ErrVersionConflict
+}
+
+// ErrorDetails is merged into the API error response.
+func (e *VersionConflictError) ErrorDetails() map[string]any {
+	return map[string]any{"current_version": e.CurrentVersion}
+}
+
+// checkVersion rejects an update when the version the client last saw is not the stored one.
+// Clients that don't send a version skip the check; the repository still guards the write itself.
+func checkVersion(expected *int, current int) error {
+	if expected != nil && *expected != current {
+		return &VersionConflictError{CurrentVersion: current}
+	}
+	return nil
+}
@@ -0,0 +1,201 @@
+package services
+
+import (
+	"chalk-api/pkg/validators"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// ErrInvalidWorkoutLog is returned when a logged set doesn't fit its exercise's measurement_type or
+// carries out-of-range values, such as reps on a 5k run or an RPE of 12.
+var ErrInvalidWorkoutLog = errors.New("invalid workout log")
+
+// Rules a logged set's field can break
+const (
+	WorkoutLogRuleRequired    = "required"
+	WorkoutLogRuleNotAllowed  = "not_allowed"
+	WorkoutLogRuleMin         = "min"
+	WorkoutLogRuleMax         = "max"
+	WorkoutLogRuleUnsupported = "distance_unit"
+)
+
+// WorkoutLogFieldError is one rejected field of a logged set, shaped like a request validation error.
+type WorkoutLogFieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// WorkoutLogError is ErrInvalidWorkoutLog with every rejected field, checked in a single pass.
+type WorkoutLogError struct {
+	MeasurementType string
+	Fields          []WorkoutLogFieldError
+}
+
+func (e *WorkoutLogError) Error() string {
+	return fmt.Sprintf("%s: %v", ErrInvalidWorkoutLog, e.Fields)
+}
+
+func (e *WorkoutLogError) Is(target error) bool {
+	return target == ErrInvalidWorkoutLog
+}
+
+// ErrorDetails is merged into the API error response.
+func (e *WorkoutLogError) ErrorDetails() map[string]any {
+	details := map[string]any{"details": e.Fields}
+	if e.MeasurementType != "" {
+		details["measurement_type"] = e.MeasurementType
+	}
+	return details
+}
+
+// workoutLogValues are the fields of a logged set checked against its exercise.
+type workoutLogValues struct {
+	setNumber       *int
+	repsCompleted   *int
+	weightUsed      *float64
+	rpe             *int
+	durationSeconds *int
+	distance        *float64
+	distanceUnit    *string
+}
+
+// validateWorkoutLog checks a set against the exercise's measurement_type: reps sets need
+// reps_completed, time sets duration_seconds and distance sets a distance with a supported unit.
+// Reps, duration and distance of the wrong kind are refused the way prescriptions refuse them;
+// weight is allowed on every type for weighted carries and vests.
+//
+// Ranges are checked on the submitted values and the combination on the resulting set, when
+// there is one. Edits pass a nil result unless they touch a measured field, so sets logged before
+// this validation existed can still have their notes or RPE corrected.
+func (s *WorkoutService) validateWorkoutLog(ctx context.Context, exerciseID uint, submitted workoutLogValues, result *workoutLogValues) error {
+	measurementType, err := s.exerciseMeasurementType(ctx, exerciseID)
+	if err != nil {
+		return err
+	}
+
+	fields := workoutLogRangeViolations(submitted)
+	if result != nil {
+		fields = append(fields, workoutLogCombinationViolations(measurementType, *result)...)
+	}
+	if len(fields) > 0 {
+		return &WorkoutLogError{MeasurementType: measurementType, Fields: fields}
+	}
+	return nil
+}
+
+// exerciseMeasurementType reads an exercise's measurement_type through the exercise cache. Inactive
+// exercises still resolve so sets can be logged on workouts assigned before they were retired.
+func (s *WorkoutService) exerciseMeasurementType(ctx context.Context, exerciseID uint) (string, error) {
+	if s.exerciseStore != nil {
+		if cached, ok := s.exerciseStore.Get(exerciseID); ok {
+			return cached.MeasurementType, nil
+		}
+	}
+
+	exercise, err := s.exerciseRepo.GetByID(ctx, exerciseID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", ErrExerciseNotFound
+		}
+		return "", err
+	}
+	if s.exerciseStore != nil {
+		s.exerciseStore.Set(exercise)
+	}
+	return exercise.MeasurementType, nil
+}
+
+func workoutLogRangeViolations(v workoutLogValues) []WorkoutLogFieldError {
+	var fields []WorkoutLogFieldError
+	if v.setNumber != nil && *v.setNumber < 1 {
+		fields = append(fields, WorkoutLogFieldError{Field: "set_number", Rule: WorkoutLogRuleMin, Message: "must be at least 1"})
+	}
+	if v.repsCompleted != nil && *v.repsCompleted < 0 {
+		fields = append(fields, WorkoutLogFieldError{Field: "reps_completed", Rule: WorkoutLogRuleMin, Message: "must be at least 0"})
+	}
+	if v.weightUsed != nil && *v.weightUsed < 0 {
+		fields = append(fields, WorkoutLogFieldError{Field: "weight_used", Rule: WorkoutLogRuleMin, Message: "must be at least 0"})
+	}
+	if v.rpe != nil && *v.rpe < 1 {
+		fields = append(fields, WorkoutLogFieldError{Field: "rpe", Rule: WorkoutLogRuleMin, Message: "must be at least 1"})
+	}
+	if v.rpe != nil && *v.rpe > 10 {
+		fields = append(fields, WorkoutLogFieldError{Field: "rpe", Rule: WorkoutLogRuleMax, Message: "must be at most 10"})
+	}
+	if v.durationSeconds != nil && *v.durationSeconds < 0 {
+		fields = append(fields, WorkoutLogFieldError{Field: "duration_seconds", Rule: WorkoutLogRuleMin, Message: "must be at least 0"})
+	}
+	if v.distance != nil && *v.distance < 0 {
+		fields = append(fields, WorkoutLogFieldError{Field: "distance", Rule: WorkoutLogRuleMin, Message: "must be at least 0"})
+	}
+	if v.distanceUnit != nil && !slices.Contains(validators.DistanceUnits, *normalizeLogDistanceUnit(v.distanceUnit)) {
+		fields = append(fields, WorkoutLogFieldError{
+			Field:   "distance_unit",
+			Rule:    WorkoutLogRuleUnsupported,
+			Message: "must be one of: " + strings.Join(validators.DistanceUnits, ", "),
+		})
+	}
+	return fields
+}
+
+// workoutLogCombinationViolations mirrors prescriptionViolations for logged sets. Unrecognized
+// measurement types are not checked.
+func workoutLogCombinationViolations(measurementType string, v workoutLogValues) []WorkoutLogFieldError {
+	var fields []WorkoutLogFieldError
+	required := func(field string) {
+		fields = append(fields, WorkoutLogFieldError{Field: field, Rule: WorkoutLogRuleRequired, Message: "is required for " + measurementType + " exercises"})
+	}
+	notAllowed := func(field string) {
+		fields = append(fields, WorkoutLogFieldError{Field: field, Rule: WorkoutLogRuleNotAllowed, Message: "is not allowed for " + measurementType + " exercises"})
+	}
+
+	switch measurementType {
+	case MeasurementTypeReps:
+		if v.repsCompleted == nil {
+			required("reps_completed")
+		}
+		if v.durationSeconds != nil {
+			notAllowed("duration_seconds")
+		}
+		if v.distance != nil {
+			notAllowed("distance")
+		}
+	case MeasurementTypeTime:
+		if v.durationSeconds == nil {
+			required("duration_seconds")
+		}
+		if v.repsCompleted != nil {
+			notAllowed("reps_completed")
+		}
+		if v.distance != nil {
+			notAllowed("distance")
+		}
+	case MeasurementTypeDistance:
+		if v.distance == nil {
+			required("distance")
+		}
+		if v.distanceUnit == nil {
+			required("distance_unit")
+		}
+		// A duration alongside the distance records the pace and is allowed
+		if v.repsCompleted != nil {
+			notAllowed("reps_completed")
+		}
+	}
+	return fields
+}
+
+// normalizeLogDistanceUnit lowercases and trims a logged distance unit so "KM" is stored as "km".
+func normalizeLogDistanceUnit(unit *string) *string {
+	if unit == nil {
+		return nil
+	}
+	normalized := strings.ToLower(strings.TrimSpace(*unit))
+	return &normalized
+}
@@ -0,0 +1,173 @@
+package services
+
+import (
+	"chalk-api/pkg/events"
+	"chalk-api/pkg/i18n"
+	"chalk-api/pkg/models"
+	"chalk-api/pkg/repositories"
+	"chalk-api/pkg/utils"
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	workoutReminderNotificationType = "workout_reminder"
+	workoutPendingNotificationType  = "workout_pending"
+
+	// WorkoutReminderSlot is how often the reminder worker wakes. A timezone is handled in the slot
+	// that starts its local hour, so each zone is looked at once an hour, half-hour zones included.
+	WorkoutReminderSlot = 15 * time.Minute
+
+	// Reminders claimed per timezone and kind in one run; the rest go out in the next run
+	workoutReminderBatchSize = 500
+)
+
+// WorkoutReminderService reminds clients about workouts scheduled for today: a morning "on the plan
+// today" at their reminder hour and, if they opted in, an evening "still pending" follow-up. The
+// workout reminder worker calls SendDueReminders.
+type WorkoutReminderService struct {
+	repos       *repositories.RepositoriesCollection
+	workoutRepo *repositories.WorkoutRepository
+	events      *events.Publisher
+}
+
+func NewWorkoutReminderService(repos *repositories.RepositoriesCollection, eventsPublisher *events.Publisher) *WorkoutReminderService {
+	return &WorkoutReminderService{
+		repos:       repos,
+		workoutRepo: repos.Workout,
+		events:      eventsPublisher,
+	}
+}
+
+// SendDueReminders sends every reminder due at now. Clients are grouped by timezone; unless catchUp
+// is set, only timezones whose local hour started in the current slot are looked at, so a zone's
+// reminders go out once at the top of its hour. Reminder hours at or before the local hour count as
+// due, so a missed slot is caught up by the next one. Each reminder is claimed on the workout before
+// it is sent, so reruns and other instances skip it. Returns how many reminders were sent.
+func (s *WorkoutReminderService) SendDueReminders(ctx context.Context, now time.Time, catchUp bool) (int, error) {
+	timezones, err := s.workoutRepo.ListReminderTimezones(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, timezone := range timezones {
+		local := now.In(utils.ResolveLocation(timezone))
+		if !catchUp && time.Duration(local.Minute())*time.Minute >= WorkoutReminderSlot {
+			continue
+		}
+		dayStart := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, local.Location())
+
+		for _, evening := range []bool{false, true} {
+			n, err := s.sendBucket(ctx, timezone, dayStart, local.Hour(), evening, now)
+			sent += n
+			if err != nil {
+				slog.Error("Failed to send workout reminders", "timezone", timezone, "evening", evening, "error", err)
+			}
+		}
+	}
+	return sent, nil
+}
+
+func (s *WorkoutReminderService) sendBucket(ctx context.Context, timezone string, dayStart time.Time, localHour int, evening bool, now time.Time) (int, error) {
+	targets, err := s.workoutRepo.ListDueWorkoutReminders(ctx, timezone, dayStart.Format("2006-01-02"), dayStart, localHour, evening, workoutReminderBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, target := range targets {
+		delivered, err := s.sendReminder(ctx, target, evening, now)
+		if err != nil {
+			slog.Error("Failed to send workout reminder", "workout_id", target.WorkoutID, "evening", evening, "error", err)
+			continue
+		}
+		if delivered {
+			sent++
+		}
+	}
+	return sent, nil
+}
+
+// sendReminder claims the reminder and writes the in-app notification and push in one transaction,
+// reporting false when it had already been claimed.
+func (s *WorkoutReminderService) sendReminder(ctx context.Context, target repositories.WorkoutReminderTarget, evening bool, now time.Time) (bool, error) {
+	notificationType := workoutReminderNotificationType
+	if evening {
+		notificationType = workoutPendingNotificationType
+	}
+	locale := target.Locale
+	workoutName := target.WorkoutName
+	if workoutName == "" {
+		workoutName = i18n.T(locale, "push."+notificationType+".unnamed")
+	}
+	title := i18n.T(locale, "push."+notificationType+".title")
+	body := i18n.T(locale, "push."+notificationType+".body", workoutName)
+	data := map[string]any{
+		"type":           notificationType,
+		"workout_id":     target.WorkoutID,
+		"scheduled_date": target.ScheduledDate,
+		"deep_link":      fmt.Sprintf("chalk://workouts/%d", target.WorkoutID),
+	}
+
+	delivered := false
+	err := s.repos.WithTransaction(ctx, func(tx *gorm.DB, txRepos *repositories.RepositoriesCollection) error {
+		claimed, err := txRepos.Workout.ClaimWorkoutReminder(ctx, target.WorkoutID, evening, now)
+		if err != nil || !claimed {
+			return err
+		}
+
+		notification := &models.Notification{
+			UserID: target.ClientUserID,
+			Type:   notificationType,
+			Title:  title,
+			Body:   &body,
+			Data:   data,
+		}
+		if err := txRepos.Notification.Create(ctx, notification); err != nil {
+			return err
+		}
+		delivered = true
+
+		if s.events == nil {
+			return nil
+		}
+		deviceTokens, err := txRepos.User.GetDeviceTokens(ctx, target.ClientUserID)
+		if err != nil {
+			return err
+		}
+		if len(deviceTokens) == 0 {
+			return nil
+		}
+		tokens := make([]string, 0, len(deviceTokens))
+		for _, token := range deviceTokens {
+			tokens = append(tokens, token.Token)
+		}
+		data["notification_id"] = notification.ID
+
+		id := strconv.FormatUint(uint64(target.WorkoutID), 10)
+		return s.events.PublishInTx(
+			ctx,
+			tx,
+			events.EventTypeNotificationPush,
+			"workout",
+			id,
+			events.BuildIdempotencyKey(events.EventTypeNotificationPush, notificationType, id, target.ScheduledDate),
+			events.PushNotificationPayload{
+				Tokens: tokens,
+				Title:  title,
+				Body:   body,
+				Data:   data,
+			},
+		)
+	})
+	if err != nil {
+		return false, err
+	}
+	return delivered, nil
+}
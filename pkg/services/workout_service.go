@@ -2,10 +2,19 @@ package services
 
 import (
 	"chalk-api/pkg/events"
+	"chalk-api/pkg/export"
+	"chalk-api/pkg/external/storage"
 	"chalk-api/pkg/models"
 	"chalk-api/pkg/repositories"
+	"chalk-api/pkg/stores"
+	"chalk-api/pkg/units"
+	"chalk-api/pkg/utils"
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"math"
+	"reflect"
 	"strconv"
 	"strings"
 	"time"
@@ -16,16 +25,75 @@ import (
 var (
 	ErrTemplateNotFound        = errors.New("template not found")
 	ErrTemplateForbidden       = errors.New("template does not belong to this coach")
-	ErrWorkoutNotFound         = errors.New("workout not found")
-	ErrWorkoutForbidden        = errors.New("workout does not belong to this user")
-	ErrWorkoutExerciseNotFound = errors.New("workout exercise not found")
-	ErrWorkoutLogNotFound      = errors.New("workout log not found")
-	ErrClientProfileNotFound   = errors.New("client profile not found")
-	ErrClientProfileForbidden  = errors.New("client profile does not belong to this coach")
-	ErrInvalidWorkoutState     = errors.New("invalid workout state transition")
-	ErrInvalidScheduledDate    = errors.New("scheduled date must be YYYY-MM-DD")
+	ErrTemplateVersionNotFound = errors.New("template version not found")
+
+	ErrTemplateCategoryInvalid   = errors.New("template category name is required")
+	ErrTemplateCategoryNotFound  = errors.New("template category not found")
+	ErrTemplateCategoryForbidden = errors.New("template category does not belong to this coach")
+	ErrTemplateCategoryNameTaken = errors.New("template category name already in use")
+	ErrWorkoutNotFound           = errors.New("workout not found")
+	ErrWorkoutForbidden          = errors.New("workout does not belong to this user")
+	ErrWorkoutExerciseNotFound   = errors.New("workout exercise not found")
+	ErrWorkoutLogNotFound        = errors.New("workout log not found")
+	ErrClientProfileNotFound     = errors.New("client profile not found")
+	ErrClientProfileForbidden    = errors.New("client profile does not belong to this coach")
+	ErrInvalidWorkoutState       = errors.New("invalid workout state transition")
+	ErrInvalidScheduledDate      = errors.New("scheduled date must be YYYY-MM-DD")
+	ErrWorkoutStateTooLarge      = errors.New("workout state is too large")
+	ErrFormCheckVideoMissing     = errors.New("workout log has no form video")
+	ErrFormFeedbackRequired      = errors.New("form feedback is required")
+	ErrWorkoutAlreadyScheduled   = errors.New("client already has a workout on this date")
+	ErrExerciseNotFound          = errors.New("exercise not found")
+	ErrExerciseForbidden         = errors.New("exercise does not belong to this coach")
+	ErrInvalidAlternative        = errors.New("alternative must be a different active exercise from your library")
+	ErrAlternativeNotFound       = errors.New("exercise alternative not found")
+	ErrCompletionNoteRequired    = errors.New("coach requires a completion note")
 )
 
+// WorkoutAlreadyScheduledError is ErrWorkoutAlreadyScheduled with the existing workout,
+// so the coach can be offered to replace it.
+type WorkoutAlreadyScheduledError struct {
+	WorkoutID uint
+}
+
+func (e *WorkoutAlreadyScheduledError) Error() string {
+	return fmt.Sprintf("%s (workout %d)", ErrWorkoutAlreadyScheduled, e.WorkoutID)
+}
+
+func (e *WorkoutAlreadyScheduledError) Is(target error) bool {
+	return target == ErrWorkoutAlreadyScheduled
+}
+
+// ErrorDetails is merged into the API error response.
+func (e *WorkoutAlreadyScheduledError) ErrorDetails() map[string]any {
+	return map[string]any{"existing_workout_id": e.WorkoutID}
+}
+
+const (
+	// MaxWorkoutStateBytes caps the encoded size of a workout's resumable session state.
+	MaxWorkoutStateBytes = 8 << 10
+
+	MaxFormCheckVideoBytes = 200 << 20
+	formCheckUploadExpiry  = 30 * time.Minute
+
+	MaxCompletionPhotoBytes     = 10 << 20
+	completionPhotoUploadExpiry = 15 * time.Minute
+
+	// Duration estimate assumptions for sets without an explicit prescription
+	assumedSetWorkSeconds = 40
+	defaultRestSeconds    = 60
+	poundsToKilograms     = 0.45359237
+)
+
+// Completion photos are shown in the coach app next to the workout, so the cover photo formats apply.
+var completionPhotoExtensions = coverPhotoExtensions
+
+// Form videos are played back in the coach app, so only formats iOS and Android both record are accepted.
+var formCheckVideoExtensions = map[string]string{
+	"video/mp4":       "mp4",
+	"video/quicktime": "mov",
+}
+
 type TemplateExerciseInput struct {
 	ExerciseID       uint     `json:"exercise_id" binding:"required"`
 	OrderIndex       int      `json:"order_index"`
@@ -36,47 +104,150 @@ type TemplateExerciseInput struct {
 	RepsMin          *int     `json:"reps_min"`
 	RepsMax          *int     `json:"reps_max"`
 	WeightValue      *float64 `json:"weight_value"`
-	WeightUnit       *string  `json:"weight_unit"`
+	WeightUnit       *string  `json:"weight_unit" binding:"omitempty,weight_unit"`
 	PrescriptionNote *string  `json:"prescription_note"`
 	RestSeconds      *int     `json:"rest_seconds"`
 	Tempo            *string  `json:"tempo"`
 	Notes            *string  `json:"notes"`
+
+	// Targets for time and distance exercises; which fields are required follows the exercise's
+	// measurement_type
+	PrescribedDurationSeconds *int     `json:"prescribed_duration_seconds"`
+	PrescribedDistance        *float64 `json:"prescribed_distance"`
+	PrescribedDistanceUnit    *string  `json:"prescribed_distance_unit" binding:"omitempty,distance_unit"`
 }
 
 type CreateWorkoutTemplateInput struct {
-	Name             string                  `json:"name" binding:"required"`
-	Description      *string                 `json:"description"`
-	Category         *string                 `json:"category"`
+	Name        string  `json:"name" binding:"required"`
+	Description *string `json:"description"`
+	CategoryID  *uint   `json:"category_id"`
+	// Deprecated: use CategoryID. A name here is matched to the coach's categories ignoring case,
+	// creating the category if needed.
+	Category         *string                 `json:"category" binding:"omitempty,max=50"`
 	Tags             []string                `json:"tags"`
 	EstimatedMinutes *int                    `json:"estimated_minutes"`
-	Exercises        []TemplateExerciseInput `json:"exercises"`
+	Exercises        []TemplateExerciseInput `json:"exercises" binding:"dive"`
 }
 
 type UpdateWorkoutTemplateInput struct {
-	Name             *string                  `json:"name"`
-	Description      *string                  `json:"description"`
-	Category         *string                  `json:"category"`
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+	// CategoryID 0 removes the template's category
+	CategoryID *uint `json:"category_id"`
+	// Deprecated: use CategoryID. Matched or created like on create; an empty string removes the category.
+	Category         *string                  `json:"category" binding:"omitempty,max=50"`
 	Tags             *[]string                `json:"tags"`
 	EstimatedMinutes *int                     `json:"estimated_minutes"`
 	IsActive         *bool                    `json:"is_active"`
-	Exercises        *[]TemplateExerciseInput `json:"exercises"`
+	Exercises        *[]TemplateExerciseInput `json:"exercises" binding:"omitempty,dive"`
+	// Stored on the version recorded when exercises are replaced
+	ChangeNote *string `json:"change_note" binding:"omitempty,max=500"`
+	// The template's version when the coach started editing (or the If-Match header). Unrelated to
+	// the exercise history above, which only tracks prescription changes.
+	Version *int `json:"version"`
+}
+
+// maxTemplateVersions is how many versions are kept per template; older ones are pruned.
+const maxTemplateVersions = 50
+
+type TemplateCategoryInput struct {
+	Name string `json:"name" binding:"required,max=50"`
+}
+
+// TemplateListFilter narrows ListMyTemplates to one category, or to templates without one.
+type TemplateListFilter struct {
+	CategoryID    *uint
+	Uncategorized bool
+}
+
+// TemplateCategoryFacet is how many active templates fall in one category; CategoryID and Name are
+// nil for uncategorized templates.
+type TemplateCategoryFacet struct {
+	CategoryID *uint   `json:"category_id"`
+	Name       *string `json:"name"`
+	Count      int64   `json:"count"`
+}
+
+type TemplateListResult struct {
+	Templates  []models.WorkoutTemplate
+	Total      int64
+	Categories []TemplateCategoryFacet
+}
+
+// TemplateVersionChanges counts exercise differences from the previous version. Exercises are matched
+// by exercise_id (nth occurrence to nth occurrence); a matched exercise with any prescription or
+// position change counts as modified.
+type TemplateVersionChanges struct {
+	Added    int `json:"added"`
+	Removed  int `json:"removed"`
+	Modified int `json:"modified"`
+}
+
+type TemplateVersionEntry struct {
+	models.TemplateVersion
+	// Nil when the previous version is not retained (including the first version)
+	Changes *TemplateVersionChanges `json:"changes"`
+}
+
+// maxInlineAlternatives is how many substitutes are attached to each exercise in a workout's detail.
+const maxInlineAlternatives = 3
+
+type ExerciseAlternativeInput struct {
+	AlternativeExerciseID uint    `json:"alternative_exercise_id" binding:"required"`
+	Reason                *string `json:"reason" binding:"omitempty,max=255"`
+	// Also suggest this exercise as an alternative for the other one; both must be the coach's own
+	Symmetric bool `json:"symmetric"`
 }
 
 type AssignWorkoutInput struct {
 	TemplateID      uint    `json:"template_id" binding:"required"`
 	ClientProfileID uint    `json:"client_profile_id" binding:"required"`
-	ScheduledDate   *string `json:"scheduled_date"` // YYYY-MM-DD
+	ScheduledDate   *string `json:"scheduled_date" binding:"omitempty,date"`
+	// AllowDuplicate assigns the workout even if the client already has one on scheduled_date
+	AllowDuplicate bool `json:"allow_duplicate"`
 }
 
 type SkipWorkoutExerciseInput struct {
 	Reason string `json:"reason" binding:"required"`
 }
 
+// UpdateWorkoutStateInput replaces the workout's resumable state as a whole.
+type UpdateWorkoutStateInput struct {
+	CurrentExerciseID *uint          `json:"current_exercise_id"`
+	StartedAt         *time.Time     `json:"started_at"`
+	ClientState       map[string]any `json:"client_state"`
+}
+
+type FormCheckUploadInput struct {
+	ContentType   string `json:"content_type" binding:"required"`
+	ContentLength int64  `json:"content_length" binding:"required,min=1"`
+}
+
+type SetFormCheckVideoInput struct {
+	ObjectKey string `json:"object_key" binding:"required"`
+}
+
+// CompleteWorkoutInput is the optional completion proof. The photo is uploaded first through
+// CreateCompletionPhotoUpload and referenced here by its object key.
+type CompleteWorkoutInput struct {
+	CompletionNote *string `json:"completion_note" binding:"omitempty,max=2000"`
+	PhotoObjectKey *string `json:"photo_object_key"`
+}
+
+type CompletionPhotoUploadInput struct {
+	ContentType   string `json:"content_type" binding:"required"`
+	ContentLength int64  `json:"content_length" binding:"required,min=1"`
+}
+
+type FormFeedbackInput struct {
+	FormFeedback string `json:"form_feedback" binding:"required"`
+}
+
 type CreateWorkoutLogInput struct {
 	SetNumber       int      `json:"set_number" binding:"required"`
 	RepsCompleted   *int     `json:"reps_completed"`
 	WeightUsed      *float64 `json:"weight_used"`
-	WeightUnit      *string  `json:"weight_unit"`
+	WeightUnit      *string  `json:"weight_unit" binding:"omitempty,weight_unit"`
 	RPE             *int     `json:"rpe"`
 	Notes           *string  `json:"notes"`
 	DurationSeconds *int     `json:"duration_seconds"`
@@ -88,7 +259,7 @@ type UpdateWorkoutLogInput struct {
 	SetNumber       *int     `json:"set_number"`
 	RepsCompleted   *int     `json:"reps_completed"`
 	WeightUsed      *float64 `json:"weight_used"`
-	WeightUnit      *string  `json:"weight_unit"`
+	WeightUnit      *string  `json:"weight_unit" binding:"omitempty,weight_unit"`
 	RPE             *int     `json:"rpe"`
 	Notes           *string  `json:"notes"`
 	DurationSeconds *int     `json:"duration_seconds"`
@@ -97,25 +268,44 @@ type UpdateWorkoutLogInput struct {
 }
 
 type WorkoutService struct {
-	repos        *repositories.RepositoriesCollection
-	templateRepo *repositories.TemplateRepository
-	workoutRepo  *repositories.WorkoutRepository
-	coachRepo    *repositories.CoachRepository
-	clientRepo   *repositories.ClientRepository
-	events       *events.Publisher
+	repos         transactor
+	templateRepo  templateRepository
+	workoutRepo   workoutRepository
+	exerciseRepo  exerciseRepository
+	coachRepo     coachProfileReader
+	clientRepo    clientProfileReader
+	userRepo      unitPreferenceReader
+	events        *events.Publisher
+	storage       storage.API
+	exerciseStore *stores.ExerciseStore
+	onboarding    onboardingTracker
 }
 
 func NewWorkoutService(
-	repos *repositories.RepositoriesCollection,
+	repos transactor,
+	templateRepo templateRepository,
+	workoutRepo workoutRepository,
+	exerciseRepo exerciseRepository,
+	coachRepo coachProfileReader,
+	clientRepo clientProfileReader,
+	userRepo unitPreferenceReader,
 	eventsPublisher *events.Publisher,
+	storageAPI storage.API,
+	exerciseStore *stores.ExerciseStore,
+	onboarding onboardingTracker,
 ) *WorkoutService {
 	return &WorkoutService{
-		repos:        repos,
-		templateRepo: repos.Template,
-		workoutRepo:  repos.Workout,
-		coachRepo:    repos.Coach,
-		clientRepo:   repos.Client,
-		events:       eventsPublisher,
+		repos:         repos,
+		templateRepo:  templateRepo,
+		workoutRepo:   workoutRepo,
+		exerciseRepo:  exerciseRepo,
+		coachRepo:     coachRepo,
+		clientRepo:    clientRepo,
+		userRepo:      userRepo,
+		events:        eventsPublisher,
+		storage:       storageAPI,
+		exerciseStore: exerciseStore,
+		onboarding:    onboarding,
 	}
 }
 
@@ -134,25 +324,39 @@ func (s *WorkoutService) CreateTemplate(ctx context.Context, userID uint, input
 		CoachID:          coachProfile.ID,
 		Name:             name,
 		Description:      input.Description,
-		Category:         input.Category,
 		Tags:             input.Tags,
 		EstimatedMinutes: input.EstimatedMinutes,
 		IsActive:         true,
 	}
+	if err := s.applyTemplateCategory(ctx, template, input.CategoryID, input.Category); err != nil {
+		return nil, err
+	}
 
-	template.Exercises = buildTemplateExercises(input.Exercises)
+	exercises, err := buildTemplateExercises(input.Exercises)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.validateTemplateExercises(ctx, input.Exercises); err != nil {
+		return nil, err
+	}
+	template.Exercises = exercises
 
 	if err := s.templateRepo.Create(ctx, template); err != nil {
 		return nil, err
 	}
+	if s.onboarding != nil {
+		s.onboarding.CompleteOnboardingStep(ctx, coachProfile.ID, OnboardingStepFirstTemplate)
+	}
 
-	return s.templateRepo.GetByID(ctx, template.ID)
+	return s.refreshTemplateMetrics(ctx, template.ID, input.EstimatedMinutes == nil)
 }
 
-func (s *WorkoutService) ListMyTemplates(ctx context.Context, userID uint, limit, offset int) ([]models.WorkoutTemplate, int64, error) {
+// ListMyTemplates returns a page of the coach's active templates, optionally in one category, with
+// template counts for every category (and uncategorized) so the library can show facets.
+func (s *WorkoutService) ListMyTemplates(ctx context.Context, userID uint, filter TemplateListFilter, limit, offset int) (*TemplateListResult, error) {
 	coachProfile, err := s.getCoachProfile(ctx, userID)
 	if err != nil {
-		return nil, 0, err
+		return nil, err
 	}
 
 	if limit <= 0 {
@@ -165,7 +369,25 @@ func (s *WorkoutService) ListMyTemplates(ctx context.Context, userID uint, limit
 		offset = 0
 	}
 
-	return s.templateRepo.ListByCoach(ctx, coachProfile.ID, limit, offset)
+	repoFilter := repositories.TemplateListFilter{Uncategorized: filter.Uncategorized}
+	if filter.CategoryID != nil {
+		if _, err := s.getMyTemplateCategory(ctx, coachProfile.ID, *filter.CategoryID); err != nil {
+			return nil, err
+		}
+		repoFilter.CategoryID = filter.CategoryID
+	}
+
+	templates, total, err := s.templateRepo.ListByCoach(ctx, coachProfile.ID, repoFilter, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	facets, err := s.templateCategoryFacets(ctx, coachProfile.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TemplateListResult{Templates: templates, Total: total, Categories: facets}, nil
 }
 
 func (s *WorkoutService) GetMyTemplate(ctx context.Context, userID, templateID uint) (*models.WorkoutTemplate, error) {
@@ -185,14 +407,57 @@ func (s *WorkoutService) GetMyTemplate(ctx context.Context, userID, templateID u
 		return nil, ErrTemplateForbidden
 	}
 
+	// Templates saved before metrics existed get them on first read.
+	if template.Metrics == nil && len(template.Exercises) > 0 {
+		return s.refreshTemplateMetrics(ctx, template.ID, template.EstimatedMinutes == nil)
+	}
+
 	return template, nil
 }
 
+// ExportTemplate lays out one of the coach's templates for printing or spreadsheet export, headed
+// with the coach's business name.
+func (s *WorkoutService) ExportTemplate(ctx context.Context, userID, templateID uint) (*export.TemplateSheet, error) {
+	coachProfile, err := s.getCoachProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	template, err := s.templateRepo.GetByID(ctx, templateID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTemplateNotFound
+		}
+		return nil, err
+	}
+	if template.CoachID != coachProfile.ID {
+		return nil, ErrTemplateForbidden
+	}
+
+	return export.NewTemplateSheet(template, derefString(coachProfile.BusinessName)), nil
+}
+
 func (s *WorkoutService) UpdateMyTemplate(ctx context.Context, userID, templateID uint, input UpdateWorkoutTemplateInput) (*models.WorkoutTemplate, error) {
 	template, err := s.GetMyTemplate(ctx, userID, templateID)
 	if err != nil {
 		return nil, err
 	}
+	if err := checkVersion(input.Version, template.Version); err != nil {
+		return nil, err
+	}
+	original := snapshotTemplate(template)
+
+	// Groups and prescriptions are checked before anything is written so a rejected edit leaves the
+	// template untouched.
+	var exercises []models.WorkoutTemplateExercise
+	if input.Exercises != nil {
+		if exercises, err = buildTemplateExercises(*input.Exercises); err != nil {
+			return nil, err
+		}
+		if err := s.validateTemplateExercises(ctx, *input.Exercises); err != nil {
+			return nil, err
+		}
+	}
 
 	if input.Name != nil {
 		trimmed := strings.TrimSpace(*input.Name)
@@ -203,8 +468,10 @@ func (s *WorkoutService) UpdateMyTemplate(ctx context.Context, userID, templateI
 	if input.Description != nil {
 		template.Description = input.Description
 	}
-	if input.Category != nil {
-		template.Category = input.Category
+	if input.CategoryID != nil || input.Category != nil {
+		if err := s.applyTemplateCategory(ctx, template, input.CategoryID, input.Category); err != nil {
+			return nil, err
+		}
 	}
 	if input.Tags != nil {
 		template.Tags = *input.Tags
@@ -216,186 +483,623 @@ func (s *WorkoutService) UpdateMyTemplate(ctx context.Context, userID, templateI
 		template.IsActive = *input.IsActive
 	}
 
+	// Keep following the computed estimate unless the coach has overridden it.
+	autoEstimate := followsComputedEstimate(template)
+	if input.EstimatedMinutes != nil {
+		autoEstimate = false
+	}
+
 	if err := s.templateRepo.Update(ctx, template); err != nil {
+		if errors.Is(err, repositories.ErrVersionConflict) {
+			return nil, s.templateVersionConflict(ctx, template.ID)
+		}
 		return nil, err
 	}
 
 	if input.Exercises != nil {
-		exercises := buildTemplateExercises(*input.Exercises)
+		// Templates edited before versioning existed get their current state recorded first,
+		// so the first edit can be rolled back too.
+		count, err := s.templateRepo.CountVersions(ctx, template.ID)
+		if err != nil {
+			return nil, err
+		}
+		if count == 0 {
+			note := "Original"
+			baseline := &models.TemplateVersion{TemplateID: template.ID, Snapshot: original, ChangeNote: &note}
+			if err := s.templateRepo.CreateVersion(ctx, baseline, maxTemplateVersions); err != nil {
+				return nil, err
+			}
+		}
+
 		if err := s.templateRepo.ReplaceExercises(ctx, template.ID, exercises); err != nil {
 			return nil, err
 		}
+		updated, err := s.refreshTemplateMetrics(ctx, template.ID, autoEstimate)
+		if err != nil {
+			return nil, err
+		}
+		version := &models.TemplateVersion{TemplateID: updated.ID, Snapshot: snapshotTemplate(updated), ChangeNote: input.ChangeNote}
+		if err := s.templateRepo.CreateVersion(ctx, version, maxTemplateVersions); err != nil {
+			return nil, err
+		}
+		return updated, nil
 	}
 
 	return s.templateRepo.GetByID(ctx, template.ID)
 }
 
-func (s *WorkoutService) AssignTemplateToClient(ctx context.Context, userID uint, input AssignWorkoutInput) (*models.Workout, error) {
-	coachProfile, err := s.getCoachProfile(ctx, userID)
+// ListMyTemplateVersions returns the template's retained versions, newest first, each with its
+// exercise changes from the version before it.
+func (s *WorkoutService) ListMyTemplateVersions(ctx context.Context, userID, templateID uint) ([]TemplateVersionEntry, error) {
+	template, err := s.GetMyTemplate(ctx, userID, templateID)
 	if err != nil {
 		return nil, err
 	}
 
-	template, err := s.templateRepo.GetByID(ctx, input.TemplateID)
+	versions, err := s.templateRepo.ListVersions(ctx, template.ID)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, ErrTemplateNotFound
-		}
 		return nil, err
 	}
-	if template.CoachID != coachProfile.ID {
-		return nil, ErrTemplateForbidden
+
+	entries := make([]TemplateVersionEntry, len(versions))
+	for i, version := range versions {
+		entries[i] = TemplateVersionEntry{TemplateVersion: version}
+		if i+1 < len(versions) && versions[i+1].Version == version.Version-1 {
+			changes := diffTemplateSnapshots(versions[i+1].Snapshot.Exercises, version.Snapshot.Exercises)
+			entries[i].Changes = &changes
+		}
 	}
-	if !template.IsActive {
-		return nil, ErrTemplateNotFound
+	return entries, nil
+}
+
+// RestoreMyTemplateVersion replaces the template's exercises with those of an earlier version and records
+// the result as a new version, in one transaction. Name, description and other fields are left as they are.
+func (s *WorkoutService) RestoreMyTemplateVersion(ctx context.Context, userID, templateID uint, versionNumber int) (*models.WorkoutTemplate, error) {
+	template, err := s.GetMyTemplate(ctx, userID, templateID)
+	if err != nil {
+		return nil, err
 	}
 
-	clientProfile, err := s.clientRepo.GetByID(ctx, input.ClientProfileID)
+	target, err := s.templateRepo.GetVersion(ctx, template.ID, versionNumber)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, ErrClientProfileNotFound
+			return nil, ErrTemplateVersionNotFound
 		}
 		return nil, err
 	}
-	if clientProfile.CoachID != coachProfile.ID {
-		return nil, ErrClientProfileForbidden
+
+	autoEstimate := followsComputedEstimate(template)
+	note := fmt.Sprintf("Restored version %d", target.Version)
+	err = s.repos.WithTransaction(ctx, func(tx *gorm.DB, txRepos *repositories.RepositoriesCollection) error {
+		// Bump the version so edits started before the restore are rejected
+		if err := txRepos.Template.Update(ctx, template); err != nil {
+			return err
+		}
+		if err := txRepos.Template.ReplaceExercises(ctx, template.ID, templateExercisesFromSnapshot(target.Snapshot.Exercises)); err != nil {
+			return err
+		}
+		restored, err := txRepos.Template.GetByID(ctx, template.ID)
+		if err != nil {
+			return err
+		}
+		version := &models.TemplateVersion{TemplateID: restored.ID, Snapshot: snapshotTemplate(restored), ChangeNote: &note}
+		return txRepos.Template.CreateVersion(ctx, version, maxTemplateVersions)
+	})
+	if err != nil {
+		if errors.Is(err, repositories.ErrVersionConflict) {
+			return nil, s.templateVersionConflict(ctx, template.ID)
+		}
+		return nil, err
 	}
 
-	scheduledDate, err := normalizeScheduledDate(input.ScheduledDate)
+	return s.refreshTemplateMetrics(ctx, template.ID, autoEstimate)
+}
+
+// templateVersionConflict reports the version written by the update that won the race.
+func (s *WorkoutService) templateVersionConflict(ctx context.Context, templateID uint) error {
+	current, err := s.templateRepo.GetByID(ctx, templateID)
+	if err != nil {
+		return err
+	}
+	return &VersionConflictError{CurrentVersion: current.Version}
+}
+
+// --- Template categories ---
+
+func (s *WorkoutService) CreateMyTemplateCategory(ctx context.Context, userID uint, input TemplateCategoryInput) (*models.TemplateCategory, error) {
+	coachProfile, err := s.getCoachProfile(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
 
-	workout := &models.Workout{
-		ClientID:      clientProfile.ID,
-		CoachID:       coachProfile.ID,
-		TemplateID:    &template.ID,
-		Name:          template.Name,
-		Description:   template.Description,
-		ScheduledDate: scheduledDate,
-		Status:        "scheduled",
+	name := normalizeTemplateCategoryName(input.Name)
+	if name == "" {
+		return nil, ErrTemplateCategoryInvalid
 	}
-	workout.Exercises = buildWorkoutExercisesFromTemplate(template.Exercises)
 
-	if err := s.repos.WithTransaction(ctx, func(tx *gorm.DB, txRepos *repositories.RepositoriesCollection) error {
-		if err := txRepos.Workout.Create(ctx, workout); err != nil {
-			return err
+	category := &models.TemplateCategory{CoachID: coachProfile.ID, Name: name}
+	if err := s.templateRepo.CreateCategory(ctx, category); err != nil {
+		if errors.Is(err, repositories.ErrTemplateCategoryExists) {
+			return nil, ErrTemplateCategoryNameTaken
 		}
+		return nil, err
+	}
+	return category, nil
+}
 
-		if s.events != nil {
-			payload := events.WorkoutAssignedPayload{
-				WorkoutID:      workout.ID,
-				CoachID:        workout.CoachID,
-				ClientID:       workout.ClientID,
-				ScheduledDate:  safeString(workout.ScheduledDate),
-				WorkoutName:    workout.Name,
-				AssignedByUser: userID,
-			}
-			idempotencyKey := events.BuildIdempotencyKey(
-				events.EventTypeWorkoutAssigned,
-				strconv.FormatUint(uint64(workout.ID), 10),
-			)
-			if err := s.events.PublishInTx(
-				ctx,
-				tx,
-				events.EventTypeWorkoutAssigned,
-				"workout",
-				strconv.FormatUint(uint64(workout.ID), 10),
-				idempotencyKey,
-				payload,
-			); err != nil {
-				return err
-			}
-		}
+func (s *WorkoutService) ListMyTemplateCategories(ctx context.Context, userID uint) ([]models.TemplateCategory, error) {
+	coachProfile, err := s.getCoachProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.templateRepo.ListCategories(ctx, coachProfile.ID)
+}
 
-		return nil
-	}); err != nil {
+// RenameMyTemplateCategory renames a category; templates reference it by ID, so they follow at once.
+func (s *WorkoutService) RenameMyTemplateCategory(ctx context.Context, userID, categoryID uint, input TemplateCategoryInput) (*models.TemplateCategory, error) {
+	coachProfile, err := s.getCoachProfile(ctx, userID)
+	if err != nil {
 		return nil, err
 	}
 
-	return s.workoutRepo.GetByID(ctx, workout.ID)
-}
+	category, err := s.getMyTemplateCategory(ctx, coachProfile.ID, categoryID)
+	if err != nil {
+		return nil, err
+	}
 
-func (s *WorkoutService) ListMyWorkouts(ctx context.Context, userID uint, limit, offset int) ([]models.Workout, int64, error) {
-	if limit <= 0 {
-		limit = 20
+	name := normalizeTemplateCategoryName(input.Name)
+	if name == "" {
+		return nil, ErrTemplateCategoryInvalid
 	}
-	if limit > 100 {
-		limit = 100
+	if name == category.Name {
+		return category, nil
 	}
-	if offset < 0 {
-		offset = 0
+
+	if err := s.templateRepo.RenameCategory(ctx, category.ID, name); err != nil {
+		if errors.Is(err, repositories.ErrTemplateCategoryExists) {
+			return nil, ErrTemplateCategoryNameTaken
+		}
+		return nil, err
 	}
+	return s.templateRepo.GetCategory(ctx, category.ID)
+}
 
-	clientProfiles, err := s.clientRepo.ListByUser(ctx, userID)
+// DeleteMyTemplateCategory deletes a category; its templates are kept and become uncategorized.
+func (s *WorkoutService) DeleteMyTemplateCategory(ctx context.Context, userID, categoryID uint) error {
+	coachProfile, err := s.getCoachProfile(ctx, userID)
 	if err != nil {
-		return nil, 0, err
-	}
-	if len(clientProfiles) == 0 {
-		return []models.Workout{}, 0, nil
+		return err
 	}
 
-	clientIDs := make([]uint, 0, len(clientProfiles))
-	for i := range clientProfiles {
-		clientIDs = append(clientIDs, clientProfiles[i].ID)
+	if _, err := s.getMyTemplateCategory(ctx, coachProfile.ID, categoryID); err != nil {
+		return err
 	}
-
-	return s.workoutRepo.ListByClients(ctx, clientIDs, limit, offset)
+	return s.templateRepo.DeleteCategory(ctx, categoryID)
 }
 
-func (s *WorkoutService) GetMyWorkout(ctx context.Context, userID, workoutID uint) (*models.Workout, error) {
-	workout, err := s.workoutRepo.GetByID(ctx, workoutID)
+func (s *WorkoutService) getMyTemplateCategory(ctx context.Context, coachID, categoryID uint) (*models.TemplateCategory, error) {
+	category, err := s.templateRepo.GetCategory(ctx, categoryID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, ErrWorkoutNotFound
+			return nil, ErrTemplateCategoryNotFound
 		}
 		return nil, err
 	}
-	if err := s.ensureWorkoutOwnedByUser(ctx, userID, workout); err != nil {
-		return nil, err
+	if category.CoachID != coachID {
+		return nil, ErrTemplateCategoryForbidden
 	}
-	return workout, nil
+	return category, nil
 }
 
-func (s *WorkoutService) StartMyWorkout(ctx context.Context, userID, workoutID uint) (*models.Workout, error) {
-	workout, err := s.GetMyWorkout(ctx, userID, workoutID)
-	if err != nil {
-		return nil, err
+// applyTemplateCategory sets the template's category from an ID (0 clears it) or, for older clients,
+// a name that is matched ignoring case or created. Both columns are written so readers of the
+// deprecated category string keep working.
+func (s *WorkoutService) applyTemplateCategory(ctx context.Context, template *models.WorkoutTemplate, categoryID *uint, categoryName *string) error {
+	var category *models.TemplateCategory
+	switch {
+	case categoryID != nil && *categoryID != 0:
+		found, err := s.getMyTemplateCategory(ctx, template.CoachID, *categoryID)
+		if err != nil {
+			return err
+		}
+		category = found
+	case categoryID == nil && categoryName != nil && normalizeTemplateCategoryName(*categoryName) != "":
+		found, err := s.findOrCreateTemplateCategory(ctx, template.CoachID, normalizeTemplateCategoryName(*categoryName))
+		if err != nil {
+			return err
+		}
+		category = found
+	case categoryID == nil && categoryName == nil:
+		return nil
 	}
 
-	if workout.Status == "completed" || workout.Status == "skipped" {
-		return nil, ErrInvalidWorkoutState
+	if category == nil {
+		template.CategoryID = nil
+		template.Category = nil
+		return nil
 	}
+	name := category.Name
+	template.CategoryID = &category.ID
+	template.Category = &name
+	return nil
+}
 
-	if err := s.workoutRepo.StartWorkout(ctx, workoutID); err != nil {
+func (s *WorkoutService) findOrCreateTemplateCategory(ctx context.Context, coachID uint, name string) (*models.TemplateCategory, error) {
+	category, err := s.templateRepo.FindCategoryByName(ctx, coachID, name)
+	if err == nil {
+		return category, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, err
 	}
 
-	return s.workoutRepo.GetByID(ctx, workoutID)
+	category = &models.TemplateCategory{CoachID: coachID, Name: name}
+	if err := s.templateRepo.CreateCategory(ctx, category); err != nil {
+		if errors.Is(err, repositories.ErrTemplateCategoryExists) {
+			// Created concurrently; use that one
+			return s.templateRepo.FindCategoryByName(ctx, coachID, name)
+		}
+		return nil, err
+	}
+	return category, nil
 }
 
-func (s *WorkoutService) CompleteMyWorkout(ctx context.Context, userID, workoutID uint) (*models.Workout, error) {
-	workout, err := s.GetMyWorkout(ctx, userID, workoutID)
+// templateCategoryFacets lists every category with its active template count (zero included), in
+// name order, followed by uncategorized when any template has no category.
+func (s *WorkoutService) templateCategoryFacets(ctx context.Context, coachID uint) ([]TemplateCategoryFacet, error) {
+	categories, err := s.templateRepo.ListCategories(ctx, coachID)
 	if err != nil {
 		return nil, err
 	}
-
-	if workout.Status == "completed" || workout.Status == "skipped" {
-		return nil, ErrInvalidWorkoutState
+	counts, err := s.templateRepo.CountByCategory(ctx, coachID)
+	if err != nil {
+		return nil, err
 	}
 
-	completedAt := time.Now().UTC()
-	if err := s.repos.WithTransaction(ctx, func(tx *gorm.DB, txRepos *repositories.RepositoriesCollection) error {
-		if err := txRepos.Workout.CompleteWorkout(ctx, workoutID); err != nil {
-			return err
+	byCategory := make(map[uint]int64, len(counts))
+	var uncategorized int64
+	for _, count := range counts {
+		if count.CategoryID == nil {
+			uncategorized = count.Count
+			continue
+		}
+		byCategory[*count.CategoryID] = count.Count
+	}
+
+	facets := make([]TemplateCategoryFacet, 0, len(categories)+1)
+	for i := range categories {
+		facets = append(facets, TemplateCategoryFacet{
+			CategoryID: &categories[i].ID,
+			Name:       &categories[i].Name,
+			Count:      byCategory[categories[i].ID],
+		})
+	}
+	if uncategorized > 0 {
+		facets = append(facets, TemplateCategoryFacet{Count: uncategorized})
+	}
+	return facets, nil
+}
+
+// normalizeTemplateCategoryName trims and collapses inner whitespace so "Leg  Day " matches "Leg Day".
+func normalizeTemplateCategoryName(name string) string {
+	return strings.Join(strings.Fields(name), " ")
+}
+
+// followsComputedEstimate reports whether the template's estimated_minutes is unset or still the computed value.
+func followsComputedEstimate(template *models.WorkoutTemplate) bool {
+	return template.EstimatedMinutes == nil ||
+		(template.Metrics != nil && *template.EstimatedMinutes == template.Metrics.EstimatedMinutes)
+}
+
+// refreshTemplateMetrics recomputes a template's metrics from its stored exercises (which carry their
+// muscle groups) and, when autoEstimate is set, replaces estimated_minutes with the computed value.
+func (s *WorkoutService) refreshTemplateMetrics(ctx context.Context, templateID uint, autoEstimate bool) (*models.WorkoutTemplate, error) {
+	template, err := s.templateRepo.GetByID(ctx, templateID)
+	if err != nil {
+		return nil, err
+	}
+
+	metrics := computeTemplateMetrics(template.Exercises)
+	estimatedMinutes := template.EstimatedMinutes
+	if autoEstimate {
+		estimatedMinutes = nil
+		if metrics.EstimatedMinutes > 0 {
+			estimatedMinutes = &metrics.EstimatedMinutes
+		}
+	}
+
+	if err := s.templateRepo.UpdateMetrics(ctx, template.ID, estimatedMinutes, metrics); err != nil {
+		return nil, err
+	}
+	template.EstimatedMinutes = estimatedMinutes
+	template.Metrics = metrics
+	return template, nil
+}
+
+func (s *WorkoutService) AssignTemplateToClient(ctx context.Context, userID uint, input AssignWorkoutInput) (*models.Workout, error) {
+	coachProfile, err := s.getCoachProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	template, err := s.templateRepo.GetByID(ctx, input.TemplateID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTemplateNotFound
+		}
+		return nil, err
+	}
+	if template.CoachID != coachProfile.ID {
+		return nil, ErrTemplateForbidden
+	}
+	if !template.IsActive {
+		return nil, ErrTemplateNotFound
+	}
+
+	clientProfile, err := s.clientRepo.GetByID(ctx, input.ClientProfileID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrClientProfileNotFound
+		}
+		return nil, err
+	}
+	if clientProfile.CoachID != coachProfile.ID {
+		return nil, ErrClientProfileForbidden
+	}
+
+	scheduledDate, err := normalizeScheduledDate(input.ScheduledDate)
+	if err != nil {
+		return nil, err
+	}
+
+	exerciseIDs := make([]uint, 0, len(template.Exercises))
+	for i := range template.Exercises {
+		exerciseIDs = append(exerciseIDs, template.Exercises[i].ExerciseID)
+	}
+	defaults, err := s.workoutRepo.GetClientExerciseDefaults(ctx, clientProfile.ID, exerciseIDs)
+	if err != nil {
+		return nil, err
+	}
+	clientTemplate, overridden := withClientExerciseDefaults(template, defaults)
+
+	workout, err := newWorkoutFromTemplate(clientTemplate, clientProfile.ID, scheduledDate)
+	if err != nil {
+		return nil, err
+	}
+	for i := range workout.Exercises {
+		workout.Exercises[i].ClientDefaultApplied = overridden[workout.Exercises[i].ExerciseID]
+	}
+
+	if err := s.repos.WithTransaction(ctx, func(tx *gorm.DB, txRepos *repositories.RepositoriesCollection) error {
+		if scheduledDate != nil {
+			existing, err := txRepos.Workout.FindActiveOnDate(ctx, clientProfile.ID, *scheduledDate)
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+			if existing != nil {
+				if !input.AllowDuplicate {
+					return &WorkoutAlreadyScheduledError{WorkoutID: existing.ID}
+				}
+				workout.DuplicateAllowed = true
+			}
+		}
+
+		if err := txRepos.Workout.Create(ctx, workout); err != nil {
+			return err
+		}
+
+		return publishWorkoutAssigned(ctx, s.events, tx, workout, userID)
+	}); err != nil {
+		// A concurrent assignment won the unique index; report the workout that got there first.
+		if errors.Is(err, repositories.ErrWorkoutDateTaken) {
+			existing, findErr := s.workoutRepo.FindActiveOnDate(ctx, clientProfile.ID, *scheduledDate)
+			if findErr != nil {
+				return nil, ErrWorkoutAlreadyScheduled
+			}
+			return nil, &WorkoutAlreadyScheduledError{WorkoutID: existing.ID}
+		}
+		return nil, err
+	}
+
+	return s.workoutRepo.GetByID(ctx, workout.ID)
+}
+
+func (s *WorkoutService) ListMyWorkouts(ctx context.Context, userID uint, limit, offset int) ([]models.Workout, int64, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	clientProfiles, err := s.clientRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(clientProfiles) == 0 {
+		return []models.Workout{}, 0, nil
+	}
+
+	clientIDs := make([]uint, 0, len(clientProfiles))
+	for i := range clientProfiles {
+		clientIDs = append(clientIDs, clientProfiles[i].ID)
+	}
+
+	return s.workoutRepo.ListByClients(ctx, clientIDs, limit, offset)
+}
+
+func (s *WorkoutService) GetMyWorkout(ctx context.Context, userID, workoutID uint) (*models.Workout, error) {
+	workout, err := s.workoutRepo.GetByID(ctx, workoutID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWorkoutNotFound
+		}
+		return nil, err
+	}
+	if err := s.ensureWorkoutOwnedByUser(ctx, userID, workout); err != nil {
+		return nil, err
+	}
+	return workout, nil
+}
+
+// GetMyWorkoutDetail is GetMyWorkout with up to three alternatives attached to each exercise so the
+// client can swap one without another request. Alternatives are limited to equipment the client has:
+// the given list, or the equipment from their intake form when none is given. With neither, every
+// alternative is eligible. With preferredUnits, prescribed and logged weights and distances are
+// converted into the viewer's preferred units.
+func (s *WorkoutService) GetMyWorkoutDetail(ctx context.Context, userID, workoutID uint, equipment []string, preferredUnits bool) (*models.Workout, error) {
+	workout, err := s.GetMyWorkout(ctx, userID, workoutID)
+	if err != nil {
+		return nil, err
+	}
+	if len(workout.Exercises) == 0 {
+		return workout, nil
+	}
+
+	if preferredUnits {
+		prefs, err := s.userRepo.GetUnitPreferences(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		convertWorkoutUnits(workout, prefs)
+	}
+
+	if len(equipment) == 0 {
+		clientProfile, err := s.clientRepo.GetByID(ctx, workout.ClientID)
+		if err != nil {
+			return nil, err
+		}
+		if clientProfile.IntakeForm != nil && clientProfile.IntakeForm.EquipmentAvailable != nil {
+			equipment = strings.Split(*clientProfile.IntakeForm.EquipmentAvailable, ",")
+		}
+	}
+	available := equipmentSet(equipment)
+
+	exerciseIDs := make([]uint, 0, len(workout.Exercises))
+	for i := range workout.Exercises {
+		exerciseIDs = append(exerciseIDs, workout.Exercises[i].ExerciseID)
+	}
+	alternatives, err := s.exerciseRepo.ListAlternatives(ctx, exerciseIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	byExercise := make(map[uint][]models.ExerciseAlternative)
+	for _, alternative := range alternatives {
+		if len(byExercise[alternative.ExerciseID]) < maxInlineAlternatives && fitsEquipment(&alternative.Alternative, available) {
+			byExercise[alternative.ExerciseID] = append(byExercise[alternative.ExerciseID], alternative)
+		}
+	}
+	for i := range workout.Exercises {
+		workout.Exercises[i].Alternatives = byExercise[workout.Exercises[i].ExerciseID]
+	}
+	return workout, nil
+}
+
+// convertWorkoutUnits rewrites a loaded workout's weights and distances, and their units, into prefs.
+// Values with a missing or unrecognised unit are left as they are.
+func convertWorkoutUnits(workout *models.Workout, prefs units.Preferences) {
+	for i := range workout.Exercises {
+		exercise := &workout.Exercises[i]
+		convertWeight(exercise.WeightValue, exercise.WeightUnit, prefs.Weight)
+		for j := range exercise.Logs {
+			log := &exercise.Logs[j]
+			convertWeight(log.WeightUsed, log.WeightUnit, prefs.Weight)
+			if log.Distance != nil && log.DistanceUnit != nil {
+				if converted, ok := units.ConvertDistance(*log.Distance, *log.DistanceUnit, prefs.Distance); ok {
+					*log.Distance = converted
+					*log.DistanceUnit = prefs.Distance
+				}
+			}
+		}
+	}
+}
+
+// convertWeight converts *value from *unit into target in place, updating *unit to match.
+func convertWeight(value *float64, unit *string, target string) {
+	if value == nil || unit == nil {
+		return
+	}
+	if converted, ok := units.ConvertWeight(*value, *unit, target); ok {
+		*value = converted
+		*unit = target
+	}
+}
+
+func (s *WorkoutService) StartMyWorkout(ctx context.Context, userID, workoutID uint) (*models.Workout, error) {
+	workout, err := s.GetMyWorkout(ctx, userID, workoutID)
+	if err != nil {
+		return nil, err
+	}
+
+	if workout.Status == "completed" || workout.Status == "skipped" {
+		return nil, ErrInvalidWorkoutState
+	}
+
+	if err := s.workoutRepo.StartWorkout(ctx, workoutID); err != nil {
+		return nil, err
+	}
+
+	return s.workoutRepo.GetByID(ctx, workoutID)
+}
+
+// CompleteMyWorkout finishes the workout with an optional note and photo. Clients whose coach
+// requires a completion note get ErrCompletionNoteRequired without one. The note and photo can only
+// be set here, by the client.
+func (s *WorkoutService) CompleteMyWorkout(ctx context.Context, userID, workoutID uint, input CompleteWorkoutInput) (*models.Workout, error) {
+	workout, err := s.GetMyWorkout(ctx, userID, workoutID)
+	if err != nil {
+		return nil, err
+	}
+
+	if workout.Status == "completed" || workout.Status == "skipped" {
+		return nil, ErrInvalidWorkoutState
+	}
+
+	clientProfile, err := s.clientRepo.GetByID(ctx, workout.ClientID)
+	if err != nil {
+		return nil, err
+	}
+	note := trimPtr(input.CompletionNote)
+	if note == nil && clientProfile.RequireCompletionNote {
+		return nil, ErrCompletionNoteRequired
+	}
+
+	var photoURL *string
+	if key := trimPtr(input.PhotoObjectKey); key != nil {
+		url, err := s.verifyCompletionPhoto(ctx, workout.ID, *key)
+		if err != nil {
+			return nil, err
+		}
+		photoURL = &url
+	}
+
+	coachProfile, err := s.coachRepo.GetByID(ctx, workout.CoachID)
+	if err != nil {
+		return nil, err
+	}
+
+	completedAt := time.Now().UTC()
+	if err := s.repos.WithTransaction(ctx, func(tx *gorm.DB, txRepos *repositories.RepositoriesCollection) error {
+		if err := txRepos.Workout.CompleteWorkout(ctx, workoutID, completedAt, note, photoURL); err != nil {
+			return err
 		}
 
 		if s.events != nil {
 			payload := events.WorkoutCompletedPayload{
-				WorkoutID:   workout.ID,
-				CoachID:     workout.CoachID,
-				ClientID:    workout.ClientID,
-				CompletedAt: completedAt,
+				WorkoutID:      workout.ID,
+				CoachID:        workout.CoachID,
+				ClientID:       workout.ClientID,
+				CompletedAt:    completedAt,
+				CoachUserID:    coachProfile.UserID,
+				ClientName:     digestClientName(*clientProfile),
+				WorkoutName:    workout.Name,
+				CompletionNote: note,
+				HasPhoto:       photoURL != nil,
+				ClientUserID:   clientProfile.UserID,
 			}
 			idempotencyKey := events.BuildIdempotencyKey(
 				events.EventTypeWorkoutCompleted,
@@ -414,132 +1118,660 @@ func (s *WorkoutService) CompleteMyWorkout(ctx context.Context, userID, workoutI
 			}
 		}
 
-		return nil
-	}); err != nil {
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return s.workoutRepo.GetByID(ctx, workoutID)
+}
+
+// CreateCompletionPhotoUpload returns a presigned PUT for a completion photo on an unfinished workout.
+// The client passes the object key to CompleteMyWorkout once the upload finishes.
+func (s *WorkoutService) CreateCompletionPhotoUpload(ctx context.Context, userID, workoutID uint, input CompletionPhotoUploadInput) (*storage.PresignedRequest, error) {
+	if s.storage == nil || !s.storage.IsConfigured() {
+		return nil, ErrStorageUnavailable
+	}
+
+	contentType := strings.ToLower(strings.TrimSpace(input.ContentType))
+	extension, ok := completionPhotoExtensions[contentType]
+	if !ok {
+		return nil, ErrUploadContentType
+	}
+	if input.ContentLength > MaxCompletionPhotoBytes {
+		return nil, ErrUploadTooLarge
+	}
+
+	workout, err := s.GetMyWorkout(ctx, userID, workoutID)
+	if err != nil {
+		return nil, err
+	}
+	if workout.Status == "completed" || workout.Status == "skipped" {
+		return nil, ErrInvalidWorkoutState
+	}
+
+	suffix, err := utils.GenerateRandomString(16)
+	if err != nil {
+		return nil, err
+	}
+	key := fmt.Sprintf("%s%s.%s", completionPhotoKeyPrefix(workoutID), suffix, extension)
+
+	return s.storage.PresignPut(key, contentType, input.ContentLength, completionPhotoUploadExpiry)
+}
+
+// verifyCompletionPhoto checks an uploaded completion photo belongs to the workout and returns its URL.
+func (s *WorkoutService) verifyCompletionPhoto(ctx context.Context, workoutID uint, key string) (string, error) {
+	if s.storage == nil || !s.storage.IsConfigured() {
+		return "", ErrStorageUnavailable
+	}
+	if !strings.HasPrefix(key, completionPhotoKeyPrefix(workoutID)) || strings.Contains(key, "..") {
+		return "", ErrUploadNotFound
+	}
+
+	info, err := s.storage.HeadObject(ctx, key)
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotFound) {
+			return "", ErrUploadNotFound
+		}
+		return "", err
+	}
+	if info.Size > MaxCompletionPhotoBytes {
+		return "", ErrUploadTooLarge
+	}
+	if _, ok := completionPhotoExtensions[strings.ToLower(info.ContentType)]; !ok {
+		return "", ErrUploadContentType
+	}
+
+	return s.storage.PublicURL(key), nil
+}
+
+// UpdateMyWorkoutState stores where the client is in an unfinished workout so another device can resume it.
+// current_exercise_id must be one of the workout's exercises.
+func (s *WorkoutService) UpdateMyWorkoutState(ctx context.Context, userID, workoutID uint, input UpdateWorkoutStateInput) (*models.Workout, error) {
+	workout, err := s.GetMyWorkout(ctx, userID, workoutID)
+	if err != nil {
+		return nil, err
+	}
+
+	if workout.Status == "completed" || workout.Status == "skipped" {
+		return nil, ErrInvalidWorkoutState
+	}
+
+	if input.CurrentExerciseID != nil {
+		found := false
+		for i := range workout.Exercises {
+			if workout.Exercises[i].ID == *input.CurrentExerciseID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, ErrWorkoutExerciseNotFound
+		}
+	}
+
+	state := &models.WorkoutSessionState{
+		CurrentExerciseID: input.CurrentExerciseID,
+		StartedAt:         input.StartedAt,
+		ClientState:       input.ClientState,
+		UpdatedAt:         time.Now().UTC(),
+	}
+	encoded, err := json.Marshal(state)
+	if err != nil {
+		return nil, err
+	}
+	if len(encoded) > MaxWorkoutStateBytes {
+		return nil, ErrWorkoutStateTooLarge
+	}
+
+	updated, err := s.workoutRepo.UpdateSessionState(ctx, workoutID, state)
+	if err != nil {
+		return nil, err
+	}
+	if !updated {
+		return nil, ErrInvalidWorkoutState
+	}
+
+	return s.workoutRepo.GetByID(ctx, workoutID)
+}
+
+func (s *WorkoutService) MarkMyExerciseCompleted(ctx context.Context, userID, workoutExerciseID uint) (*models.WorkoutExercise, error) {
+	exercise, err := s.workoutRepo.GetExerciseByID(ctx, workoutExerciseID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWorkoutExerciseNotFound
+		}
+		return nil, err
+	}
+
+	if err := s.ensureWorkoutOwnershipByID(ctx, userID, exercise.WorkoutID); err != nil {
+		return nil, err
+	}
+
+	if err := s.workoutRepo.MarkExerciseCompleted(ctx, workoutExerciseID); err != nil {
+		return nil, err
+	}
+	return s.workoutRepo.GetExerciseByID(ctx, workoutExerciseID)
+}
+
+func (s *WorkoutService) SkipMyExercise(ctx context.Context, userID, workoutExerciseID uint, input SkipWorkoutExerciseInput) (*models.WorkoutExercise, error) {
+	exercise, err := s.workoutRepo.GetExerciseByID(ctx, workoutExerciseID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWorkoutExerciseNotFound
+		}
+		return nil, err
+	}
+	if err := s.ensureWorkoutOwnershipByID(ctx, userID, exercise.WorkoutID); err != nil {
+		return nil, err
+	}
+
+	reason := strings.TrimSpace(input.Reason)
+	if reason == "" {
+		reason = "skipped"
+	}
+	if err := s.workoutRepo.SkipExercise(ctx, workoutExerciseID, reason); err != nil {
+		return nil, err
+	}
+	return s.workoutRepo.GetExerciseByID(ctx, workoutExerciseID)
+}
+
+func (s *WorkoutService) CreateMyExerciseLog(ctx context.Context, userID, workoutExerciseID uint, input CreateWorkoutLogInput) (*models.WorkoutLog, error) {
+	exercise, err := s.workoutRepo.GetExerciseByID(ctx, workoutExerciseID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWorkoutExerciseNotFound
+		}
+		return nil, err
+	}
+	if err := s.ensureWorkoutOwnershipByID(ctx, userID, exercise.WorkoutID); err != nil {
+		return nil, err
+	}
+
+	input.DistanceUnit = normalizeLogDistanceUnit(input.DistanceUnit)
+	values := workoutLogValues{
+		setNumber:       &input.SetNumber,
+		repsCompleted:   input.RepsCompleted,
+		weightUsed:      input.WeightUsed,
+		rpe:             input.RPE,
+		durationSeconds: input.DurationSeconds,
+		distance:        input.Distance,
+		distanceUnit:    input.DistanceUnit,
+	}
+	if err := s.validateWorkoutLog(ctx, exercise.ExerciseID, values, &values); err != nil {
+		return nil, err
+	}
+
+	log := &models.WorkoutLog{
+		WorkoutExerciseID: workoutExerciseID,
+		SetNumber:         input.SetNumber,
+		RepsCompleted:     input.RepsCompleted,
+		WeightUsed:        input.WeightUsed,
+		WeightUnit:        input.WeightUnit,
+		RPE:               input.RPE,
+		Notes:             input.Notes,
+		DurationSeconds:   input.DurationSeconds,
+		Distance:          input.Distance,
+		DistanceUnit:      input.DistanceUnit,
+	}
+	if err := s.workoutRepo.CreateLog(ctx, log); err != nil {
+		return nil, err
+	}
+
+	return s.workoutRepo.GetLogByID(ctx, log.ID)
+}
+
+func (s *WorkoutService) UpdateMyWorkoutLog(ctx context.Context, userID, workoutLogID uint, input UpdateWorkoutLogInput) (*models.WorkoutLog, error) {
+	logEntry, err := s.workoutRepo.GetLogByID(ctx, workoutLogID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWorkoutLogNotFound
+		}
+		return nil, err
+	}
+
+	if err := s.ensureWorkoutOwnershipByExerciseID(ctx, userID, logEntry.WorkoutExerciseID); err != nil {
+		return nil, err
+	}
+
+	input.DistanceUnit = normalizeLogDistanceUnit(input.DistanceUnit)
+	submitted := workoutLogValues{
+		setNumber:       input.SetNumber,
+		repsCompleted:   input.RepsCompleted,
+		weightUsed:      input.WeightUsed,
+		rpe:             input.RPE,
+		durationSeconds: input.DurationSeconds,
+		distance:        input.Distance,
+		distanceUnit:    input.DistanceUnit,
+	}
+
+	if input.SetNumber != nil {
+		logEntry.SetNumber = *input.SetNumber
+	}
+	if input.RepsCompleted != nil {
+		logEntry.RepsCompleted = input.RepsCompleted
+	}
+	if input.WeightUsed != nil {
+		logEntry.WeightUsed = input.WeightUsed
+	}
+	if input.WeightUnit != nil {
+		logEntry.WeightUnit = input.WeightUnit
+	}
+	if input.RPE != nil {
+		logEntry.RPE = input.RPE
+	}
+	if input.Notes != nil {
+		logEntry.Notes = input.Notes
+	}
+	if input.DurationSeconds != nil {
+		logEntry.DurationSeconds = input.DurationSeconds
+	}
+	if input.Distance != nil {
+		logEntry.Distance = input.Distance
+	}
+	if input.DistanceUnit != nil {
+		logEntry.DistanceUnit = input.DistanceUnit
+	}
+
+	var result *workoutLogValues
+	if input.RepsCompleted != nil || input.WeightUsed != nil || input.DurationSeconds != nil || input.Distance != nil || input.DistanceUnit != nil {
+		result = &workoutLogValues{
+			setNumber:       &logEntry.SetNumber,
+			repsCompleted:   logEntry.RepsCompleted,
+			weightUsed:      logEntry.WeightUsed,
+			rpe:             logEntry.RPE,
+			durationSeconds: logEntry.DurationSeconds,
+			distance:        logEntry.Distance,
+			distanceUnit:    logEntry.DistanceUnit,
+		}
+	}
+	if err := s.validateWorkoutLog(ctx, logEntry.WorkoutExercise.ExerciseID, submitted, result); err != nil {
+		return nil, err
+	}
+
+	if err := s.workoutRepo.UpdateLog(ctx, logEntry); err != nil {
+		return nil, err
+	}
+
+	return s.workoutRepo.GetLogByID(ctx, logEntry.ID)
+}
+
+// CreateFormCheckUpload returns a presigned PUT for a form video on one of the client's logged sets.
+// The client calls SetFormCheckVideo with the object key once the upload finishes.
+func (s *WorkoutService) CreateFormCheckUpload(ctx context.Context, userID, workoutLogID uint, input FormCheckUploadInput) (*storage.PresignedRequest, error) {
+	if s.storage == nil || !s.storage.IsConfigured() {
+		return nil, ErrStorageUnavailable
+	}
+
+	contentType := strings.ToLower(strings.TrimSpace(input.ContentType))
+	extension, ok := formCheckVideoExtensions[contentType]
+	if !ok {
+		return nil, ErrUploadContentType
+	}
+	if input.ContentLength > MaxFormCheckVideoBytes {
+		return nil, ErrUploadTooLarge
+	}
+
+	if _, _, err := s.getMyWorkoutLog(ctx, userID, workoutLogID); err != nil {
+		return nil, err
+	}
+
+	suffix, err := utils.GenerateRandomString(16)
+	if err != nil {
+		return nil, err
+	}
+	key := fmt.Sprintf("%s%s.%s", formCheckKeyPrefix(workoutLogID), suffix, extension)
+
+	return s.storage.PresignPut(key, contentType, input.ContentLength, formCheckUploadExpiry)
+}
+
+// SetFormCheckVideo verifies an uploaded form video and attaches it to the set. A replaced video
+// resets any earlier coach feedback and is deleted by the outbox worker. The coach is notified
+// through a formcheck.submitted event.
+func (s *WorkoutService) SetFormCheckVideo(ctx context.Context, userID, workoutLogID uint, input SetFormCheckVideoInput) (*models.WorkoutLog, error) {
+	if s.storage == nil || !s.storage.IsConfigured() {
+		return nil, ErrStorageUnavailable
+	}
+
+	logEntry, workout, err := s.getMyWorkoutLog(ctx, userID, workoutLogID)
+	if err != nil {
 		return nil, err
 	}
 
-	return s.workoutRepo.GetByID(ctx, workoutID)
-}
+	key := strings.TrimSpace(input.ObjectKey)
+	if !strings.HasPrefix(key, formCheckKeyPrefix(workoutLogID)) || strings.Contains(key, "..") {
+		return nil, ErrUploadNotFound
+	}
 
-func (s *WorkoutService) MarkMyExerciseCompleted(ctx context.Context, userID, workoutExerciseID uint) (*models.WorkoutExercise, error) {
-	exercise, err := s.workoutRepo.GetExerciseByID(ctx, workoutExerciseID)
+	info, err := s.storage.HeadObject(ctx, key)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, ErrWorkoutExerciseNotFound
+		if errors.Is(err, storage.ErrObjectNotFound) {
+			return nil, ErrUploadNotFound
 		}
 		return nil, err
 	}
+	if info.Size > MaxFormCheckVideoBytes {
+		return nil, ErrUploadTooLarge
+	}
+	if _, ok := formCheckVideoExtensions[strings.ToLower(info.ContentType)]; !ok {
+		return nil, ErrUploadContentType
+	}
 
-	if err := s.ensureWorkoutOwnershipByID(ctx, userID, exercise.WorkoutID); err != nil {
-		return nil, err
+	videoURL := s.storage.PublicURL(key)
+	previousURL := logEntry.VideoURL
+	if previousURL != nil && *previousURL == videoURL {
+		return logEntry, nil
 	}
 
-	if err := s.workoutRepo.MarkExerciseCompleted(ctx, workoutExerciseID); err != nil {
+	coachProfile, err := s.coachRepo.GetByID(ctx, workout.CoachID)
+	if err != nil {
 		return nil, err
 	}
-	return s.workoutRepo.GetExerciseByID(ctx, workoutExerciseID)
-}
-
-func (s *WorkoutService) SkipMyExercise(ctx context.Context, userID, workoutExerciseID uint, input SkipWorkoutExerciseInput) (*models.WorkoutExercise, error) {
-	exercise, err := s.workoutRepo.GetExerciseByID(ctx, workoutExerciseID)
+	clientProfile, err := s.clientRepo.GetByID(ctx, workout.ClientID)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, ErrWorkoutExerciseNotFound
-		}
 		return nil, err
 	}
-	if err := s.ensureWorkoutOwnershipByID(ctx, userID, exercise.WorkoutID); err != nil {
+	clientName := ""
+	if clientProfile.User.Profile != nil {
+		clientName = strings.TrimSpace(clientProfile.User.Profile.FirstName + " " + clientProfile.User.Profile.LastName)
+	}
+	exerciseName := ""
+	for i := range workout.Exercises {
+		if workout.Exercises[i].ID == logEntry.WorkoutExerciseID {
+			exerciseName = workout.Exercises[i].Exercise.Name
+			break
+		}
+	}
+
+	uploadedAt := time.Now().UTC()
+	logEntry.VideoURL = &videoURL
+	logEntry.VideoUploadedAt = &uploadedAt
+	logEntry.FormFeedback = nil
+	logEntry.ReviewedAt = nil
+
+	err = s.repos.WithTransaction(ctx, func(tx *gorm.DB, txRepos *repositories.RepositoriesCollection) error {
+		if err := txRepos.Workout.UpdateLog(ctx, logEntry); err != nil {
+			return err
+		}
+		if s.events == nil {
+			return nil
+		}
+
+		id := strconv.FormatUint(uint64(logEntry.ID), 10)
+		if err := s.events.PublishInTx(
+			ctx,
+			tx,
+			events.EventTypeFormCheckSubmitted,
+			"workout_log",
+			id,
+			events.BuildIdempotencyKey(events.EventTypeFormCheckSubmitted, id, key),
+			events.FormCheckSubmittedPayload{
+				WorkoutLogID: logEntry.ID,
+				WorkoutID:    workout.ID,
+				ClientID:     workout.ClientID,
+				CoachID:      workout.CoachID,
+				CoachUserID:  coachProfile.UserID,
+				ClientName:   clientName,
+				ExerciseName: exerciseName,
+			},
+		); err != nil {
+			return err
+		}
+
+		if previousURL == nil {
+			return nil
+		}
+		previousKey, ok := s.storage.KeyFromURL(*previousURL)
+		if !ok {
+			return nil
+		}
+		return s.events.PublishInTx(
+			ctx,
+			tx,
+			events.EventTypeStorageObjectDelete,
+			"workout_log",
+			id,
+			events.BuildIdempotencyKey(events.EventTypeStorageObjectDelete, previousKey),
+			events.StorageObjectDeletePayload{Key: previousKey, Reason: "form_check_replaced"},
+		)
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	reason := strings.TrimSpace(input.Reason)
-	if reason == "" {
-		reason = "skipped"
+	return s.workoutRepo.GetLogByID(ctx, logEntry.ID)
+}
+
+// ListMyFormChecks returns unreviewed form videos across the coach's clients, oldest first.
+func (s *WorkoutService) ListMyFormChecks(ctx context.Context, userID uint, limit, offset int) ([]repositories.FormCheck, int64, error) {
+	coachProfile, err := s.getCoachProfile(ctx, userID)
+	if err != nil {
+		return nil, 0, err
 	}
-	if err := s.workoutRepo.SkipExercise(ctx, workoutExerciseID, reason); err != nil {
-		return nil, err
+
+	if limit <= 0 {
+		limit = 20
 	}
-	return s.workoutRepo.GetExerciseByID(ctx, workoutExerciseID)
+	if limit > 100 {
+		limit = 100
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	return s.workoutRepo.ListUnreviewedFormChecks(ctx, coachProfile.ID, limit, offset)
 }
 
-func (s *WorkoutService) CreateMyExerciseLog(ctx context.Context, userID, workoutExerciseID uint, input CreateWorkoutLogInput) (*models.WorkoutLog, error) {
-	exercise, err := s.workoutRepo.GetExerciseByID(ctx, workoutExerciseID)
+// ReviewFormCheck records the coach's feedback on a form video and marks it reviewed.
+// Only the coach who assigned the workout can review it.
+func (s *WorkoutService) ReviewFormCheck(ctx context.Context, userID, workoutLogID uint, input FormFeedbackInput) (*models.WorkoutLog, error) {
+	coachProfile, err := s.getCoachProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	logEntry, err := s.workoutRepo.GetLogByID(ctx, workoutLogID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, ErrWorkoutExerciseNotFound
+			return nil, ErrWorkoutLogNotFound
 		}
 		return nil, err
 	}
-	if err := s.ensureWorkoutOwnershipByID(ctx, userID, exercise.WorkoutID); err != nil {
+	exercise, err := s.workoutRepo.GetExerciseByID(ctx, logEntry.WorkoutExerciseID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWorkoutLogNotFound
+		}
 		return nil, err
 	}
+	if exercise.Workout.CoachID != coachProfile.ID {
+		return nil, ErrWorkoutForbidden
+	}
+	if logEntry.VideoURL == nil {
+		return nil, ErrFormCheckVideoMissing
+	}
 
-	log := &models.WorkoutLog{
-		WorkoutExerciseID: workoutExerciseID,
-		SetNumber:         input.SetNumber,
-		RepsCompleted:     input.RepsCompleted,
-		WeightUsed:        input.WeightUsed,
-		WeightUnit:        input.WeightUnit,
-		RPE:               input.RPE,
-		Notes:             input.Notes,
-		DurationSeconds:   input.DurationSeconds,
-		Distance:          input.Distance,
-		DistanceUnit:      input.DistanceUnit,
+	feedback := strings.TrimSpace(input.FormFeedback)
+	if feedback == "" {
+		return nil, ErrFormFeedbackRequired
 	}
-	if err := s.workoutRepo.CreateLog(ctx, log); err != nil {
+	reviewedAt := time.Now().UTC()
+	logEntry.FormFeedback = &feedback
+	logEntry.ReviewedAt = &reviewedAt
+
+	if err := s.workoutRepo.UpdateLog(ctx, logEntry); err != nil {
 		return nil, err
 	}
 
-	return s.workoutRepo.GetLogByID(ctx, log.ID)
+	return s.workoutRepo.GetLogByID(ctx, logEntry.ID)
 }
 
-func (s *WorkoutService) UpdateMyWorkoutLog(ctx context.Context, userID, workoutLogID uint, input UpdateWorkoutLogInput) (*models.WorkoutLog, error) {
-	logEntry, err := s.workoutRepo.GetLogByID(ctx, workoutLogID)
+// getMyWorkoutLog loads a set log owned by the user along with its workout (exercises preloaded).
+// ListExerciseAlternatives returns an exercise's alternatives, limited to ones whose primary equipment
+// is all in the given list when one is given.
+func (s *WorkoutService) ListExerciseAlternatives(ctx context.Context, exerciseID uint, equipment []string) ([]models.ExerciseAlternative, error) {
+	if _, err := s.getActiveExercise(ctx, exerciseID); err != nil {
+		return nil, err
+	}
+
+	alternatives, err := s.exerciseRepo.ListAlternatives(ctx, []uint{exerciseID})
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, ErrWorkoutLogNotFound
-		}
 		return nil, err
 	}
 
-	if err := s.ensureWorkoutOwnershipByExerciseID(ctx, userID, logEntry.WorkoutExerciseID); err != nil {
+	available := equipmentSet(equipment)
+	filtered := make([]models.ExerciseAlternative, 0, len(alternatives))
+	for _, alternative := range alternatives {
+		if fitsEquipment(&alternative.Alternative, available) {
+			filtered = append(filtered, alternative)
+		}
+	}
+	return filtered, nil
+}
+
+// AddExerciseAlternative suggests another exercise as a substitute for one of the coach's own exercises.
+// Adding an existing pair again only updates its reason. With Symmetric set the reverse pair is added too.
+func (s *WorkoutService) AddExerciseAlternative(ctx context.Context, userID, exerciseID uint, input ExerciseAlternativeInput) ([]models.ExerciseAlternative, error) {
+	coachProfile, err := s.getCoachProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	exercise, err := s.getActiveExercise(ctx, exerciseID)
+	if err != nil {
 		return nil, err
 	}
+	if !ownsExercise(coachProfile.ID, exercise) {
+		return nil, ErrExerciseForbidden
+	}
 
-	if input.SetNumber != nil {
-		logEntry.SetNumber = *input.SetNumber
+	if input.AlternativeExerciseID == exerciseID {
+		return nil, ErrInvalidAlternative
 	}
-	if input.RepsCompleted != nil {
-		logEntry.RepsCompleted = input.RepsCompleted
+	alternative, err := s.getActiveExercise(ctx, input.AlternativeExerciseID)
+	if err != nil {
+		if errors.Is(err, ErrExerciseNotFound) {
+			return nil, ErrInvalidAlternative
+		}
+		return nil, err
 	}
-	if input.WeightUsed != nil {
-		logEntry.WeightUsed = input.WeightUsed
+	if !alternative.IsSystem && !ownsExercise(coachProfile.ID, alternative) {
+		return nil, ErrInvalidAlternative
 	}
-	if input.WeightUnit != nil {
-		logEntry.WeightUnit = input.WeightUnit
+	// The reverse pair would suggest this coach's exercise for the other one, so they must own both.
+	if input.Symmetric && !ownsExercise(coachProfile.ID, alternative) {
+		return nil, ErrExerciseForbidden
 	}
-	if input.RPE != nil {
-		logEntry.RPE = input.RPE
+
+	reason := trimPtr(input.Reason)
+	pairs := []models.ExerciseAlternative{{ExerciseID: exerciseID, AlternativeExerciseID: alternative.ID, Reason: reason}}
+	if input.Symmetric {
+		pairs = append(pairs, models.ExerciseAlternative{ExerciseID: alternative.ID, AlternativeExerciseID: exerciseID, Reason: reason})
 	}
-	if input.Notes != nil {
-		logEntry.Notes = input.Notes
+	if err := s.exerciseRepo.UpsertAlternatives(ctx, pairs); err != nil {
+		return nil, err
 	}
-	if input.DurationSeconds != nil {
-		logEntry.DurationSeconds = input.DurationSeconds
+
+	return s.exerciseRepo.ListAlternatives(ctx, []uint{exerciseID})
+}
+
+// RemoveExerciseAlternative deletes one suggestion from the coach's own exercise. A reverse pair added
+// with Symmetric is left in place.
+func (s *WorkoutService) RemoveExerciseAlternative(ctx context.Context, userID, exerciseID, alternativeExerciseID uint) error {
+	coachProfile, err := s.getCoachProfile(ctx, userID)
+	if err != nil {
+		return err
 	}
-	if input.Distance != nil {
-		logEntry.Distance = input.Distance
+	exercise, err := s.getActiveExercise(ctx, exerciseID)
+	if err != nil {
+		return err
 	}
-	if input.DistanceUnit != nil {
-		logEntry.DistanceUnit = input.DistanceUnit
+	if !ownsExercise(coachProfile.ID, exercise) {
+		return ErrExerciseForbidden
 	}
 
-	if err := s.workoutRepo.UpdateLog(ctx, logEntry); err != nil {
+	if err := s.exerciseRepo.DeleteAlternative(ctx, exerciseID, alternativeExerciseID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrAlternativeNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *WorkoutService) getActiveExercise(ctx context.Context, exerciseID uint) (*models.Exercise, error) {
+	exercise, err := s.exerciseRepo.GetByID(ctx, exerciseID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrExerciseNotFound
+		}
 		return nil, err
 	}
+	if !exercise.IsActive {
+		return nil, ErrExerciseNotFound
+	}
+	return exercise, nil
+}
 
-	return s.workoutRepo.GetLogByID(ctx, logEntry.ID)
+func ownsExercise(coachID uint, exercise *models.Exercise) bool {
+	return !exercise.IsSystem && exercise.CoachID != nil && *exercise.CoachID == coachID
+}
+
+// equipmentSet normalizes an equipment list for matching; nil means no filter.
+func equipmentSet(equipment []string) map[string]bool {
+	var set map[string]bool
+	for _, item := range equipment {
+		key := equipmentKey(item)
+		if key == "" {
+			continue
+		}
+		if set == nil {
+			set = make(map[string]bool)
+		}
+		set[key] = true
+	}
+	return set
+}
+
+// equipmentKey lowercases and singularizes an equipment name so "Dumbbells" matches "dumbbell".
+func equipmentKey(name string) string {
+	key := strings.ToLower(strings.TrimSpace(name))
+	return strings.TrimSuffix(key, "s")
+}
+
+// fitsEquipment reports whether all of the exercise's primary equipment is available.
+// Bodyweight exercises (no primary equipment) always fit.
+func fitsEquipment(exercise *models.Exercise, available map[string]bool) bool {
+	if available == nil {
+		return true
+	}
+	for _, item := range exercise.PrimaryEquipment {
+		if !available[equipmentKey(item)] {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *WorkoutService) getMyWorkoutLog(ctx context.Context, userID, workoutLogID uint) (*models.WorkoutLog, *models.Workout, error) {
+	logEntry, err := s.workoutRepo.GetLogByID(ctx, workoutLogID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrWorkoutLogNotFound
+		}
+		return nil, nil, err
+	}
+	exercise, err := s.workoutRepo.GetExerciseByID(ctx, logEntry.WorkoutExerciseID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrWorkoutLogNotFound
+		}
+		return nil, nil, err
+	}
+	workout, err := s.GetMyWorkout(ctx, userID, exercise.WorkoutID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return logEntry, workout, nil
 }
 
 func (s *WorkoutService) getCoachProfile(ctx context.Context, userID uint) (*models.CoachProfile, error) {
@@ -591,20 +1823,30 @@ func (s *WorkoutService) ensureWorkoutOwnedByUser(ctx context.Context, userID ui
 	return nil
 }
 
-func buildTemplateExercises(inputs []TemplateExerciseInput) []models.WorkoutTemplateExercise {
-	exercises := make([]models.WorkoutTemplateExercise, 0, len(inputs))
+// buildTemplateExercises orders the coach's exercises, renumbers them 1..n and validates their groups.
+// Exercises without an order_index keep their position in the list.
+func buildTemplateExercises(inputs []TemplateExerciseInput) ([]models.WorkoutTemplateExercise, error) {
+	slots := make([]exerciseSlot, len(inputs))
 	for i := range inputs {
 		order := inputs[i].OrderIndex
 		if order <= 0 {
 			order = i + 1
 		}
+		slots[i] = exerciseSlot{order: order, group: inputs[i].SupersetGroup, groupType: inputs[i].GroupType}
+	}
+	arranged, groupTypes, err := arrangeExercises(slots)
+	if err != nil {
+		return nil, err
+	}
 
+	exercises := make([]models.WorkoutTemplateExercise, 0, len(inputs))
+	for position, i := range arranged {
 		exercises = append(exercises, models.WorkoutTemplateExercise{
 			ExerciseID:       inputs[i].ExerciseID,
-			OrderIndex:       order,
+			OrderIndex:       position + 1,
 			SectionLabel:     inputs[i].SectionLabel,
 			SupersetGroup:    inputs[i].SupersetGroup,
-			GroupType:        inputs[i].GroupType,
+			GroupType:        groupTypes[i],
 			Sets:             inputs[i].Sets,
 			RepsMin:          inputs[i].RepsMin,
 			RepsMax:          inputs[i].RepsMax,
@@ -614,21 +1856,198 @@ func buildTemplateExercises(inputs []TemplateExerciseInput) []models.WorkoutTemp
 			RestSeconds:      inputs[i].RestSeconds,
 			Tempo:            inputs[i].Tempo,
 			Notes:            inputs[i].Notes,
+
+			PrescribedDurationSeconds: inputs[i].PrescribedDurationSeconds,
+			PrescribedDistance:        inputs[i].PrescribedDistance,
+			PrescribedDistanceUnit:    inputs[i].PrescribedDistanceUnit,
 		})
 	}
+	return exercises, nil
+}
+
+// snapshotTemplate copies the template and its exercises (in order) for a TemplateVersion.
+func snapshotTemplate(template *models.WorkoutTemplate) models.TemplateSnapshot {
+	snapshot := models.TemplateSnapshot{
+		Name:             template.Name,
+		Description:      template.Description,
+		Category:         template.Category,
+		Tags:             template.Tags,
+		EstimatedMinutes: template.EstimatedMinutes,
+		Exercises:        make([]models.TemplateSnapshotExercise, len(template.Exercises)),
+	}
+	for i, exercise := range template.Exercises {
+		snapshot.Exercises[i] = models.TemplateSnapshotExercise{
+			ExerciseID:       exercise.ExerciseID,
+			ExerciseName:     exercise.Exercise.Name,
+			OrderIndex:       exercise.OrderIndex,
+			SectionLabel:     exercise.SectionLabel,
+			SupersetGroup:    exercise.SupersetGroup,
+			GroupType:        exercise.GroupType,
+			Sets:             exercise.Sets,
+			RepsMin:          exercise.RepsMin,
+			RepsMax:          exercise.RepsMax,
+			WeightValue:      exercise.WeightValue,
+			WeightUnit:       exercise.WeightUnit,
+			PrescriptionNote: exercise.PrescriptionNote,
+			RestSeconds:      exercise.RestSeconds,
+			Tempo:            exercise.Tempo,
+			Notes:            exercise.Notes,
+
+			PrescribedDurationSeconds: exercise.PrescribedDurationSeconds,
+			PrescribedDistance:        exercise.PrescribedDistance,
+			PrescribedDistanceUnit:    exercise.PrescribedDistanceUnit,
+		}
+	}
+	return snapshot
+}
+
+func templateExercisesFromSnapshot(snapshot []models.TemplateSnapshotExercise) []models.WorkoutTemplateExercise {
+	exercises := make([]models.WorkoutTemplateExercise, len(snapshot))
+	for i, exercise := range snapshot {
+		exercises[i] = models.WorkoutTemplateExercise{
+			ExerciseID:       exercise.ExerciseID,
+			OrderIndex:       exercise.OrderIndex,
+			SectionLabel:     exercise.SectionLabel,
+			SupersetGroup:    exercise.SupersetGroup,
+			GroupType:        exercise.GroupType,
+			Sets:             exercise.Sets,
+			RepsMin:          exercise.RepsMin,
+			RepsMax:          exercise.RepsMax,
+			WeightValue:      exercise.WeightValue,
+			WeightUnit:       exercise.WeightUnit,
+			PrescriptionNote: exercise.PrescriptionNote,
+			RestSeconds:      exercise.RestSeconds,
+			Tempo:            exercise.Tempo,
+			Notes:            exercise.Notes,
+
+			PrescribedDurationSeconds: exercise.PrescribedDurationSeconds,
+			PrescribedDistance:        exercise.PrescribedDistance,
+			PrescribedDistanceUnit:    exercise.PrescribedDistanceUnit,
+		}
+	}
 	return exercises
 }
 
-func buildWorkoutExercisesFromTemplate(templateExercises []models.WorkoutTemplateExercise) []models.WorkoutExercise {
-	result := make([]models.WorkoutExercise, 0, len(templateExercises))
+// diffTemplateSnapshots counts exercises added, removed and modified between two versions.
+func diffTemplateSnapshots(previous, current []models.TemplateSnapshotExercise) TemplateVersionChanges {
+	unmatched := make(map[uint][]models.TemplateSnapshotExercise)
+	for _, exercise := range previous {
+		unmatched[exercise.ExerciseID] = append(unmatched[exercise.ExerciseID], exercise)
+	}
+
+	var changes TemplateVersionChanges
+	for _, exercise := range current {
+		candidates := unmatched[exercise.ExerciseID]
+		if len(candidates) == 0 {
+			changes.Added++
+			continue
+		}
+		// Names are display copies and can change when the exercise is renamed.
+		before := candidates[0]
+		before.ExerciseName, exercise.ExerciseName = "", ""
+		if !reflect.DeepEqual(before, exercise) {
+			changes.Modified++
+		}
+		unmatched[exercise.ExerciseID] = candidates[1:]
+	}
+	for _, remaining := range unmatched {
+		changes.Removed += len(remaining)
+	}
+	return changes
+}
+
+// computeTemplateMetrics totals the prescription. Duration is sets x (rest + assumed work time),
+// rounded up to the minute; an exercise without sets counts as one set. Volume uses the midpoint of
+// a rep range and credits each primary muscle group of the exercise with the full volume.
+func computeTemplateMetrics(exercises []models.WorkoutTemplateExercise) *models.WorkoutMetrics {
+	metrics := &models.WorkoutMetrics{
+		TotalExercises:      len(exercises),
+		VolumeByMuscleGroup: map[string]float64{},
+	}
+
+	totalSeconds := 0
+	for i := range exercises {
+		exercise := exercises[i]
+
+		sets := 1
+		if exercise.Sets != nil && *exercise.Sets > 0 {
+			sets = *exercise.Sets
+		}
+		rest := defaultRestSeconds
+		if exercise.RestSeconds != nil && *exercise.RestSeconds >= 0 {
+			rest = *exercise.RestSeconds
+		}
+		work := assumedSetWorkSeconds
+		if exercise.PrescribedDurationSeconds != nil && *exercise.PrescribedDurationSeconds > 0 {
+			work = *exercise.PrescribedDurationSeconds
+		}
+		metrics.TotalSets += sets
+		totalSeconds += sets * (rest + work)
+
+		volume := prescribedVolumeKg(exercise, sets)
+		if volume <= 0 {
+			continue
+		}
+		metrics.TotalVolumeKg += volume
+		for _, group := range exercise.Exercise.PrimaryMuscleGroups {
+			metrics.VolumeByMuscleGroup[group] += volume
+		}
+	}
+
+	metrics.EstimatedMinutes = (totalSeconds + 59) / 60
+	metrics.TotalVolumeKg = math.Round(metrics.TotalVolumeKg*10) / 10
+	for group, volume := range metrics.VolumeByMuscleGroup {
+		metrics.VolumeByMuscleGroup[group] = math.Round(volume*10) / 10
+	}
+	return metrics
+}
+
+// prescribedVolumeKg returns sets x reps x weight in kg, or 0 when reps or an absolute load aren't prescribed.
+func prescribedVolumeKg(exercise models.WorkoutTemplateExercise, sets int) float64 {
+	if exercise.RepsMin == nil || exercise.WeightValue == nil || exercise.WeightUnit == nil {
+		return 0
+	}
+	reps := float64(*exercise.RepsMin)
+	if exercise.RepsMax != nil && *exercise.RepsMax > *exercise.RepsMin {
+		reps = float64(*exercise.RepsMin+*exercise.RepsMax) / 2
+	}
+
+	weight := *exercise.WeightValue
+	switch *exercise.WeightUnit {
+	case "kg":
+	case "lbs":
+		weight *= poundsToKilograms
+	default:
+		return 0
+	}
+	return float64(sets) * reps * weight
+}
+
+// buildWorkoutExercisesFromTemplate copies a template's exercises onto a workout. Templates saved
+// before groups were validated are checked again here so a broken group never reaches a client.
+func buildWorkoutExercisesFromTemplate(templateExercises []models.WorkoutTemplateExercise) ([]models.WorkoutExercise, error) {
+	slots := make([]exerciseSlot, len(templateExercises))
 	for i := range templateExercises {
+		slots[i] = exerciseSlot{
+			order:     templateExercises[i].OrderIndex,
+			group:     templateExercises[i].SupersetGroup,
+			groupType: templateExercises[i].GroupType,
+		}
+	}
+	arranged, groupTypes, err := arrangeExercises(slots)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]models.WorkoutExercise, 0, len(templateExercises))
+	for position, i := range arranged {
 		templateExercise := templateExercises[i]
 		result = append(result, models.WorkoutExercise{
 			ExerciseID:       templateExercise.ExerciseID,
-			OrderIndex:       templateExercise.OrderIndex,
+			OrderIndex:       position + 1,
 			SectionLabel:     templateExercise.SectionLabel,
 			SupersetGroup:    templateExercise.SupersetGroup,
-			GroupType:        templateExercise.GroupType,
+			GroupType:        groupTypes[i],
 			Sets:             templateExercise.Sets,
 			RepsMin:          templateExercise.RepsMin,
 			RepsMax:          templateExercise.RepsMax,
@@ -638,9 +2057,64 @@ func buildWorkoutExercisesFromTemplate(templateExercises []models.WorkoutTemplat
 			RestSeconds:      templateExercise.RestSeconds,
 			Tempo:            templateExercise.Tempo,
 			Notes:            templateExercise.Notes,
+
+			PrescribedDurationSeconds: templateExercise.PrescribedDurationSeconds,
+			PrescribedDistance:        templateExercise.PrescribedDistance,
+			PrescribedDistanceUnit:    templateExercise.PrescribedDistanceUnit,
 		})
 	}
-	return result
+	return result, nil
+}
+
+// newWorkoutFromTemplate builds an unsaved scheduled workout copying the template's exercises and metrics.
+// It fails with an ExerciseGroupError when the template's groups are invalid.
+func newWorkoutFromTemplate(template *models.WorkoutTemplate, clientID uint, scheduledDate *string) (*models.Workout, error) {
+	metrics := template.Metrics
+	if metrics == nil {
+		metrics = computeTemplateMetrics(template.Exercises)
+	}
+
+	workout := &models.Workout{
+		ClientID:         clientID,
+		CoachID:          template.CoachID,
+		TemplateID:       &template.ID,
+		Name:             template.Name,
+		Description:      template.Description,
+		ScheduledDate:    scheduledDate,
+		EstimatedMinutes: template.EstimatedMinutes,
+		Metrics:          metrics,
+		Status:           "scheduled",
+	}
+	exercises, err := buildWorkoutExercisesFromTemplate(template.Exercises)
+	if err != nil {
+		return nil, err
+	}
+	workout.Exercises = exercises
+	return workout, nil
+}
+
+// publishWorkoutAssigned queues workout.assigned in the caller's transaction. publisher may be nil.
+func publishWorkoutAssigned(ctx context.Context, publisher *events.Publisher, tx *gorm.DB, workout *models.Workout, assignedBy uint) error {
+	if publisher == nil {
+		return nil
+	}
+	id := strconv.FormatUint(uint64(workout.ID), 10)
+	return publisher.PublishInTx(
+		ctx,
+		tx,
+		events.EventTypeWorkoutAssigned,
+		"workout",
+		id,
+		events.BuildIdempotencyKey(events.EventTypeWorkoutAssigned, id),
+		events.WorkoutAssignedPayload{
+			WorkoutID:      workout.ID,
+			CoachID:        workout.CoachID,
+			ClientID:       workout.ClientID,
+			ScheduledDate:  safeString(workout.ScheduledDate),
+			WorkoutName:    workout.Name,
+			AssignedByUser: assignedBy,
+		},
+	)
 }
 
 func normalizeScheduledDate(scheduledDate *string) (*string, error) {
@@ -666,3 +2140,11 @@ func safeString(value *string) string {
 	}
 	return *value
 }
+
+func formCheckKeyPrefix(workoutLogID uint) string {
+	return fmt.Sprintf("form-checks/%d/", workoutLogID)
+}
+
+func completionPhotoKeyPrefix(workoutID uint) string {
+	return fmt.Sprintf("workout-completions/%d/", workoutID)
+}
@@ -0,0 +1,126 @@
+package stores
+
+import (
+	"chalk-api/pkg/models"
+	"fmt"
+)
+
+// AvailabilityStore caches coach recurring availability and date overrides for slot generation,
+// plus short-lived computed slot results. Booked sessions are never cached on their own; computed
+// slots that depend on them are busted on every booking and cancellation.
+type AvailabilityStore struct {
+	redis *RedisClient
+}
+
+// NewAvailabilityStore creates a new availability store
+func NewAvailabilityStore(redis *RedisClient) *AvailabilityStore {
+	return &AvailabilityStore{redis: redis}
+}
+
+// GetAvailability retrieves a coach's cached recurring availability
+func (s *AvailabilityStore) GetAvailability(coachID uint) ([]models.CoachAvailability, bool) {
+	if !s.redis.IsAvailable() {
+		return nil, false
+	}
+
+	var slots []models.CoachAvailability
+	if s.redis.GetJSON(KeyCoachAvailability(coachID), &slots) {
+		return slots, true
+	}
+	return nil, false
+}
+
+// SetAvailability caches a coach's recurring availability
+func (s *AvailabilityStore) SetAvailability(coachID uint, slots []models.CoachAvailability) {
+	if !s.redis.IsAvailable() {
+		return
+	}
+	if slots == nil {
+		// Cache "no availability" as an empty list so it is still a hit.
+		slots = []models.CoachAvailability{}
+	}
+	s.redis.SetJSON(KeyCoachAvailability(coachID), slots, CoachAvailabilityTTL)
+}
+
+// GetOverrides retrieves cached overrides for a coach and date range (YYYY-MM-DD)
+func (s *AvailabilityStore) GetOverrides(coachID uint, startDate, endDate string) ([]models.CoachAvailabilityOverride, bool) {
+	if !s.redis.IsAvailable() {
+		return nil, false
+	}
+
+	var overrides []models.CoachAvailabilityOverride
+	if s.redis.GetJSON(KeyCoachAvailabilityOverrides(coachID, startDate, endDate), &overrides) {
+		return overrides, true
+	}
+	return nil, false
+}
+
+// SetOverrides caches overrides for a coach and date range (YYYY-MM-DD)
+func (s *AvailabilityStore) SetOverrides(coachID uint, startDate, endDate string, overrides []models.CoachAvailabilityOverride) {
+	if !s.redis.IsAvailable() {
+		return
+	}
+	if overrides == nil {
+		overrides = []models.CoachAvailabilityOverride{}
+	}
+	s.redis.SetJSON(KeyCoachAvailabilityOverrides(coachID, startDate, endDate), overrides, CoachAvailabilityTTL)
+}
+
+// InvalidateAvailability removes a coach's cached recurring availability
+func (s *AvailabilityStore) InvalidateAvailability(coachID uint) {
+	if s.redis.IsAvailable() {
+		s.redis.Delete(KeyCoachAvailability(coachID))
+	}
+}
+
+// InvalidateOverrides removes every cached override range for a coach
+func (s *AvailabilityStore) InvalidateOverrides(coachID uint) {
+	if s.redis.IsAvailable() {
+		s.redis.DeletePattern(KeyCoachAvailabilityOverrides(coachID, "*", "*"))
+	}
+}
+
+// BookableSlotsVariant encodes the inputs of a slot lookup into a cache key suffix.
+// Nil session type / duration are encoded as 0 so absent and explicit values do not collide.
+func BookableSlotsVariant(startDate, endDate string, sessionTypeID uint, durationMinutes, daysLimit int) string {
+	return fmt.Sprintf("%s:%s:%d:%d:%d", startDate, endDate, sessionTypeID, durationMinutes, daysLimit)
+}
+
+// GetBookableSlots loads cached computed slots for a coach into dest.
+func (s *AvailabilityStore) GetBookableSlots(coachID uint, variant string, dest interface{}) bool {
+	if !s.redis.IsAvailable() {
+		return false
+	}
+	return s.redis.GetJSON(KeyCoachBookableSlots(coachID, variant), dest)
+}
+
+// SetBookableSlots caches computed slots for BookableSlotsTTL.
+func (s *AvailabilityStore) SetBookableSlots(coachID uint, variant string, slots interface{}) {
+	if !s.redis.IsAvailable() {
+		return
+	}
+	s.redis.SetJSON(KeyCoachBookableSlots(coachID, variant), slots, BookableSlotsTTL)
+}
+
+// NextAvailableSlotVariant is the cache key suffix for a coach's next open slot of a session type
+// (0 for the default duration). It shares the bookable slots key space so InvalidateBookableSlots
+// clears it too.
+func NextAvailableSlotVariant(sessionTypeID uint) string {
+	return fmt.Sprintf("next:%d", sessionTypeID)
+}
+
+// SetNextAvailableSlot caches a coach's next open slot lookup for NextAvailableSlotTTL.
+func (s *AvailabilityStore) SetNextAvailableSlot(coachID uint, variant string, slot interface{}) {
+	if !s.redis.IsAvailable() {
+		return
+	}
+	s.redis.SetJSON(KeyCoachBookableSlots(coachID, variant), slot, NextAvailableSlotTTL)
+}
+
+// InvalidateBookableSlots removes every cached slot computation for a coach. Called on bookings,
+// cancellations, and availability, override, time block, and session type changes.
+func (s *AvailabilityStore) InvalidateBookableSlots(coachID uint) {
+	if s.redis.IsAvailable() {
+		s.redis.DeletePattern(KeyCoachBookableSlots(coachID, "*"))
+	}
+}
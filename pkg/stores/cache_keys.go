@@ -23,6 +23,18 @@ func KeyCoachStats(coachID uint) string {
 	return fmt.Sprintf("coach:stats:%d", coachID)
 }
 
+func KeyCoachEarnings(coachID uint, startMonth, endMonth string) string {
+	return fmt.Sprintf("coach:earnings:%d:%s:%s", coachID, startMonth, endMonth)
+}
+
+func KeyCoachSessionSummary(coachID uint, month string) string {
+	return fmt.Sprintf("coach:session_summary:%d:%s", coachID, month)
+}
+
+func KeyCoachBookingPage(slug, startDate, endDate string, sessionTypeID uint) string {
+	return fmt.Sprintf("coach:booking_page:%s:%s:%s:%d", slug, startDate, endDate, sessionTypeID)
+}
+
 // Subscription keys
 func KeySubscription(userID uint) string {
 	return fmt.Sprintf("subscription:user:%d", userID)
@@ -59,6 +71,24 @@ func KeyCoachAvailability(coachID uint) string {
 	return fmt.Sprintf("coach:availability:%d", coachID)
 }
 
+func KeyCoachAvailabilityOverrides(coachID uint, startDate, endDate string) string {
+	return fmt.Sprintf("coach:availability_overrides:%d:%s:%s", coachID, startDate, endDate)
+}
+
+// KeyCoachBookableSlots scopes computed slots to a coach; variant encodes the query inputs.
+func KeyCoachBookableSlots(coachID uint, variant string) string {
+	return fmt.Sprintf("coach:bookable_slots:%d:%s", coachID, variant)
+}
+
+// Profile view keys; day is a UTC date (YYYY-MM-DD)
+func KeyCoachProfileViewers(coachID uint, day string) string {
+	return fmt.Sprintf("coach:profile_viewers:%d:%s", coachID, day)
+}
+
+func KeyProfileViewedCoaches(day string) string {
+	return fmt.Sprintf("coach:profile_viewed:%s", day)
+}
+
 // Security keys - for rate limiting and attempt tracking
 func KeyLoginAttempts(email string) string {
 	return fmt.Sprintf("security:login:attempts:%s", email)
@@ -68,6 +98,10 @@ func KeyPasswordResetAttempts(email string) string {
 	return fmt.Sprintf("security:reset:attempts:%s", email)
 }
 
+func KeyTokenRevocation(userID uint) string {
+	return fmt.Sprintf("security:tokens:revoked:%d", userID)
+}
+
 func KeyMagicLinkAttempts(email string) string {
 	return fmt.Sprintf("security:magic:attempts:%s", email)
 }
@@ -90,3 +124,8 @@ func KeyJWTBlacklist(tokenID string) string {
 func KeyRefreshToken(tokenHash string) string {
 	return fmt.Sprintf("auth:refresh:%s", tokenHash)
 }
+
+// Feature flag keys
+func KeyUserFlags(userID uint) string {
+	return fmt.Sprintf("flags:user:%d", userID)
+}
@@ -0,0 +1,306 @@
+package stores
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Keyspaces are the first segment of every key built in cache_keys.go
+const (
+	KeyspaceUser         = "user"
+	KeyspaceCoach        = "coach"
+	KeyspaceSubscription = "subscription"
+	KeyspaceExercise     = "exercise"
+	KeyspaceFood         = "food"
+	KeyspaceSecurity     = "security"
+	KeyspaceRateLimit    = "ratelimit"
+	KeyspaceJWT          = "jwt"
+	KeyspaceAuth         = "auth"
+	KeyspaceFlags        = "flags"
+)
+
+// CacheKeyspaces lists the keyspaces reported by CacheInspector, in report order
+var CacheKeyspaces = []string{
+	KeyspaceExercise,
+	KeyspaceFood,
+	KeyspaceCoach,
+	KeyspaceSecurity,
+	KeyspaceUser,
+	KeyspaceSubscription,
+	KeyspaceRateLimit,
+	KeyspaceJWT,
+	KeyspaceAuth,
+	KeyspaceFlags,
+}
+
+const (
+	// Keys asked for per SCAN call, for both stats and DeletePattern
+	scanBatchSize = 1000
+	// A stats run stops counting after this many keys and reports itself truncated
+	maxStatsScannedKeys = 500000
+	// Keys per keyspace measured with MEMORY USAGE; the keyspace estimate extrapolates from them
+	memorySampleKeys = 50
+)
+
+type lookupCounter struct {
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+func newLookupCounters() map[string]*lookupCounter {
+	counters := make(map[string]*lookupCounter, len(CacheKeyspaces))
+	for _, keyspace := range CacheKeyspaces {
+		counters[keyspace] = &lookupCounter{}
+	}
+	return counters
+}
+
+// countLookup records a store read as a hit or miss for keyspace and passes hit through, so stores
+// can wrap their GetJSON calls. Reads while Redis is unavailable aren't counted.
+func (r *RedisClient) countLookup(keyspace string, hit bool) bool {
+	if !r.IsAvailable() {
+		return hit
+	}
+	if counter, ok := r.lookups[keyspace]; ok {
+		if hit {
+			counter.hits.Add(1)
+		} else {
+			counter.misses.Add(1)
+		}
+	}
+	return hit
+}
+
+// CacheLookups is a keyspace's hit and miss counts since the process started.
+type CacheLookups struct {
+	Keyspace string `json:"keyspace"`
+	Hits     int64  `json:"hits"`
+	Misses   int64  `json:"misses"`
+}
+
+// KeyspaceStats is the estimated footprint of one keyspace. Keys is exact unless the run was
+// truncated; EstimatedBytes is the average MEMORY USAGE of the sampled keys times Keys.
+type KeyspaceStats struct {
+	Keyspace       string `json:"keyspace"`
+	Keys           int64  `json:"keys"`
+	SampledKeys    int    `json:"sampled_keys"`
+	EstimatedBytes int64  `json:"estimated_bytes"`
+	Hits           int64  `json:"hits"`
+	Misses         int64  `json:"misses"`
+}
+
+// CacheStats describes how much of Redis chalk is using. OtherKeys counts keys outside chalk's
+// keyspaces, e.g. from another app sharing the instance.
+type CacheStats struct {
+	Available      bool            `json:"available"`
+	GeneratedAt    time.Time       `json:"generated_at"`
+	Keyspaces      []KeyspaceStats `json:"keyspaces"`
+	OtherKeys      int64           `json:"other_keys"`
+	ScannedKeys    int64           `json:"scanned_keys"`
+	Truncated      bool            `json:"truncated"` // stopped after maxStatsScannedKeys; counts are lower bounds
+	EstimatedBytes int64           `json:"estimated_bytes"`
+	// From INFO memory; MaxMemoryBytes is 0 when Redis has no maxmemory set
+	UsedMemoryBytes int64 `json:"used_memory_bytes"`
+	MaxMemoryBytes  int64 `json:"max_memory_bytes"`
+	// SoftLimitBytes is 0 when the soft limit is off
+	SoftLimitBytes int64 `json:"soft_limit_bytes"`
+	OverSoftLimit  bool  `json:"over_soft_limit"`
+}
+
+// CacheInspector reports per-keyspace key counts, memory estimates and hit/miss counters, and logs
+// a warning when chalk's estimated usage passes the configured soft limit. Runs are serialized and
+// the last result is kept so frequent callers such as the metrics endpoint can reuse it.
+type CacheInspector struct {
+	redis          *RedisClient
+	softLimitBytes int64
+
+	mu   sync.Mutex
+	last *CacheStats
+}
+
+func NewCacheInspector(redis *RedisClient, softLimitBytes int64) *CacheInspector {
+	if softLimitBytes < 0 {
+		softLimitBytes = 0
+	}
+	return &CacheInspector{redis: redis, softLimitBytes: softLimitBytes}
+}
+
+// Lookups returns the hit and miss counters without touching Redis.
+func (c *CacheInspector) Lookups() []CacheLookups {
+	if c == nil || c.redis == nil {
+		return nil
+	}
+	lookups := make([]CacheLookups, 0, len(CacheKeyspaces))
+	for _, keyspace := range CacheKeyspaces {
+		counter := c.redis.lookups[keyspace]
+		if counter == nil {
+			continue
+		}
+		lookups = append(lookups, CacheLookups{
+			Keyspace: keyspace,
+			Hits:     counter.hits.Load(),
+			Misses:   counter.misses.Load(),
+		})
+	}
+	return lookups
+}
+
+// Stats returns cache stats no older than maxAge, scanning Redis again when the last run is older.
+// A maxAge of 0 always scans.
+func (c *CacheInspector) Stats(ctx context.Context, maxAge time.Duration) (*CacheStats, error) {
+	if c == nil || !c.redis.IsAvailable() {
+		return &CacheStats{GeneratedAt: time.Now().UTC(), Keyspaces: []KeyspaceStats{}}, nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.last != nil && maxAge > 0 && time.Since(c.last.GeneratedAt) < maxAge {
+		stats := *c.last
+		stats.Keyspaces = c.withLookups(c.last.Keyspaces)
+		return &stats, nil
+	}
+
+	stats, err := c.collect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.last = stats
+	if stats.OverSoftLimit {
+		slog.Warn("Redis cache memory above soft limit",
+			"estimated_bytes", stats.EstimatedBytes,
+			"soft_limit_bytes", stats.SoftLimitBytes,
+			"used_memory_bytes", stats.UsedMemoryBytes,
+			"truncated", stats.Truncated,
+		)
+	}
+	return stats, nil
+}
+
+func (c *CacheInspector) collect(ctx context.Context) (*CacheStats, error) {
+	client := c.redis.client
+	counts := make(map[string]int64, len(CacheKeyspaces))
+	samples := make(map[string][]string, len(CacheKeyspaces))
+	known := make(map[string]bool, len(CacheKeyspaces))
+	for _, keyspace := range CacheKeyspaces {
+		known[keyspace] = true
+	}
+
+	stats := &CacheStats{Available: true, SoftLimitBytes: c.softLimitBytes}
+
+	// One SCAN pass over every key, bucketed by the segment before the first colon
+	var cursor uint64
+	for {
+		keys, next, err := client.Scan(ctx, cursor, "*", scanBatchSize).Result()
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range keys {
+			stats.ScannedKeys++
+			keyspace, _, _ := strings.Cut(key, ":")
+			if !known[keyspace] {
+				stats.OtherKeys++
+				continue
+			}
+			counts[keyspace]++
+			if len(samples[keyspace]) < memorySampleKeys {
+				samples[keyspace] = append(samples[keyspace], key)
+			}
+		}
+
+		cursor = next
+		if cursor == 0 {
+			break
+		}
+		if stats.ScannedKeys >= maxStatsScannedKeys {
+			stats.Truncated = true
+			break
+		}
+	}
+
+	sampled, err := sampleMemoryUsage(ctx, client, samples)
+	if err != nil {
+		return nil, err
+	}
+
+	stats.Keyspaces = make([]KeyspaceStats, 0, len(CacheKeyspaces))
+	for _, keyspace := range CacheKeyspaces {
+		entry := KeyspaceStats{Keyspace: keyspace, Keys: counts[keyspace]}
+		if usage := sampled[keyspace]; len(usage) > 0 {
+			var sum int64
+			for _, bytes := range usage {
+				sum += bytes
+			}
+			entry.SampledKeys = len(usage)
+			entry.EstimatedBytes = sum * entry.Keys / int64(len(usage))
+		}
+		stats.EstimatedBytes += entry.EstimatedBytes
+		stats.Keyspaces = append(stats.Keyspaces, entry)
+	}
+	stats.Keyspaces = c.withLookups(stats.Keyspaces)
+
+	info, err := client.InfoMap(ctx, "memory").Result()
+	if err != nil {
+		return nil, err
+	}
+	if memory, ok := info["Memory"]; ok {
+		stats.UsedMemoryBytes, _ = strconv.ParseInt(memory["used_memory"], 10, 64)
+		stats.MaxMemoryBytes, _ = strconv.ParseInt(memory["maxmemory"], 10, 64)
+	}
+
+	stats.OverSoftLimit = stats.SoftLimitBytes > 0 && stats.EstimatedBytes > stats.SoftLimitBytes
+	stats.GeneratedAt = time.Now().UTC()
+	return stats, nil
+}
+
+// sampleMemoryUsage measures the sampled keys in one pipeline.
+func sampleMemoryUsage(ctx context.Context, client *redis.Client, samples map[string][]string) (map[string][]int64, error) {
+	pipe := client.Pipeline()
+	cmds := make(map[string][]*redis.IntCmd, len(samples))
+	for keyspace, keys := range samples {
+		for _, key := range keys {
+			cmds[keyspace] = append(cmds[keyspace], pipe.MemoryUsage(ctx, key))
+		}
+	}
+	if len(cmds) == 0 {
+		return nil, nil
+	}
+	// Exec reports the first failed command; a key that expired (redis.Nil) or a provider that
+	// blocks MEMORY USAGE only drops samples, so failures are judged per command below
+	if _, err := pipe.Exec(ctx); err != nil && ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	usage := make(map[string][]int64, len(cmds))
+	for keyspace, keyCmds := range cmds {
+		for _, cmd := range keyCmds {
+			if bytes, err := cmd.Result(); err == nil {
+				usage[keyspace] = append(usage[keyspace], bytes)
+			}
+		}
+	}
+	return usage, nil
+}
+
+// withLookups copies entries with the current hit and miss counts filled in.
+func (c *CacheInspector) withLookups(entries []KeyspaceStats) []KeyspaceStats {
+	lookups := make(map[string]CacheLookups, len(CacheKeyspaces))
+	for _, lookup := range c.Lookups() {
+		lookups[lookup.Keyspace] = lookup
+	}
+
+	out := make([]KeyspaceStats, len(entries))
+	for i, entry := range entries {
+		entry.Hits = lookups[entry.Keyspace].Hits
+		entry.Misses = lookups[entry.Keyspace].Misses
+		out[i] = entry
+	}
+	return out
+}
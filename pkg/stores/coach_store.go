@@ -2,6 +2,7 @@ package stores
 
 import (
 	"chalk-api/pkg/models"
+	"strconv"
 	"time"
 )
 
@@ -15,6 +16,17 @@ const (
 	CoachProfileTTL     = 15 * time.Minute
 	CoachStatsTTL       = 30 * time.Minute
 	CoachAvailabilityTTL = 5 * time.Minute
+	CoachEarningsTTL     = 10 * time.Minute
+	// Monthly session summaries are dropped when one of the coach's sessions changes status
+	CoachSessionSummaryTTL = 5 * time.Minute
+	// Public booking pages are unauthenticated and cheap to serve stale, so they are cached briefly
+	CoachBookingPageTTL = 60 * time.Second
+	// Computed bookable slots are busted on every booking/availability change; the TTL is a backstop.
+	BookableSlotsTTL = 30 * time.Second
+	// Next-available-slot chips are busted the same way and only need to be roughly fresh
+	NextAvailableSlotTTL = 60 * time.Second
+	// Daily profile viewer sets outlive their day so the flush worker can persist them after midnight UTC
+	ProfileViewTTL = 72 * time.Hour
 )
 
 // NewCoachStore creates a new coach store
@@ -29,6 +41,9 @@ type CachedCoachProfile struct {
 	UserID             uint               `json:"user_id"`
 	BusinessName       *string            `json:"business_name,omitempty"`
 	Bio                *string            `json:"bio,omitempty"`
+	CoverPhotoURL      *string            `json:"cover_photo_url,omitempty"`
+	BrandColor         *string            `json:"brand_color,omitempty"`
+	Slug               *string            `json:"slug,omitempty"`
 	Specialties        []string           `json:"specialties,omitempty"`
 	YearsExperience    *int               `json:"years_experience,omitempty"`
 	TrainingType       string             `json:"training_type"`
@@ -36,6 +51,8 @@ type CachedCoachProfile struct {
 	IsAcceptingClients bool               `json:"is_accepting_clients"`
 	SocialLinks        models.SocialLinks `json:"social_links,omitempty"`
 	SubscriptionTier   string             `json:"subscription_tier"`
+	// Shown before booking so clients know when a cancellation counts as late
+	CancellationWindowHours int `json:"cancellation_window_hours"`
 }
 
 // CachedCoachStats is a lightweight cache representation
@@ -47,6 +64,7 @@ type CachedCoachStats struct {
 	WorkoutsAssignedTotal  int  `json:"workouts_assigned_total"`
 	WorkoutsCompletedTotal int  `json:"workouts_completed_total"`
 	SessionsCompletedTotal int  `json:"sessions_completed_total"`
+	GoalsAchievedTotal     int  `json:"goals_achieved_total"`
 }
 
 // ToCachedCoachProfile converts a models.CoachProfile to cached version
@@ -54,18 +72,28 @@ func ToCachedCoachProfile(c *models.CoachProfile) *CachedCoachProfile {
 	if c == nil {
 		return nil
 	}
+	// The cached profile backs public views, so the rate is only kept when the coach chose to show it.
+	var hourlyRate *float64
+	if c.ShowRate {
+		hourlyRate = c.HourlyRate
+	}
 	return &CachedCoachProfile{
 		ID:                 c.ID,
 		UserID:             c.UserID,
 		BusinessName:       c.BusinessName,
 		Bio:                c.Bio,
+		CoverPhotoURL:      c.CoverPhotoURL,
+		BrandColor:         c.BrandColor,
+		Slug:               c.Slug,
 		Specialties:        c.Specialties,
 		YearsExperience:    c.YearsExperience,
 		TrainingType:       c.TrainingType,
-		HourlyRate:         c.HourlyRate,
+		HourlyRate:         hourlyRate,
 		IsAcceptingClients: c.IsAcceptingClients,
 		SocialLinks:        c.SocialLinks,
 		SubscriptionTier:   c.SubscriptionTier,
+
+		CancellationWindowHours: c.CancellationWindowHours,
 	}
 }
 
@@ -82,6 +110,7 @@ func ToCachedCoachStats(s *models.CoachStats) *CachedCoachStats {
 		WorkoutsAssignedTotal:  s.WorkoutsAssignedTotal,
 		WorkoutsCompletedTotal: s.WorkoutsCompletedTotal,
 		SessionsCompletedTotal: s.SessionsCompletedTotal,
+		GoalsAchievedTotal:     s.GoalsAchievedTotal,
 	}
 }
 
@@ -92,7 +121,7 @@ func (s *CoachStore) GetProfile(coachID uint) (*CachedCoachProfile, bool) {
 	}
 
 	var profile CachedCoachProfile
-	if s.redis.GetJSON(KeyCoachProfile(coachID), &profile) {
+	if s.redis.countLookup(KeyspaceCoach, s.redis.GetJSON(KeyCoachProfile(coachID), &profile)) {
 		return &profile, true
 	}
 	return nil, false
@@ -115,7 +144,7 @@ func (s *CoachStore) GetStats(coachID uint) (*CachedCoachStats, bool) {
 	}
 
 	var stats CachedCoachStats
-	if s.redis.GetJSON(KeyCoachStats(coachID), &stats) {
+	if s.redis.countLookup(KeyspaceCoach, s.redis.GetJSON(KeyCoachStats(coachID), &stats)) {
 		return &stats, true
 	}
 	return nil, false
@@ -131,6 +160,114 @@ func (s *CoachStore) SetStats(stats *models.CoachStats) {
 	s.redis.SetJSON(KeyCoachStats(stats.CoachID), cached, CoachStatsTTL)
 }
 
+// GetEarningsReport loads a cached earnings report into dest.
+// The report shape is owned by the service layer, so it is stored as opaque JSON.
+func (s *CoachStore) GetEarningsReport(coachID uint, startMonth, endMonth string, dest interface{}) bool {
+	if !s.redis.IsAvailable() {
+		return false
+	}
+	return s.redis.countLookup(KeyspaceCoach, s.redis.GetJSON(KeyCoachEarnings(coachID, startMonth, endMonth), dest))
+}
+
+// SetEarningsReport caches an earnings report
+func (s *CoachStore) SetEarningsReport(coachID uint, startMonth, endMonth string, report interface{}) {
+	if !s.redis.IsAvailable() || report == nil {
+		return
+	}
+	s.redis.SetJSON(KeyCoachEarnings(coachID, startMonth, endMonth), report, CoachEarningsTTL)
+}
+
+// GetSessionSummary loads a cached monthly session summary into dest.
+func (s *CoachStore) GetSessionSummary(coachID uint, month string, dest interface{}) bool {
+	if !s.redis.IsAvailable() {
+		return false
+	}
+	return s.redis.countLookup(KeyspaceCoach, s.redis.GetJSON(KeyCoachSessionSummary(coachID, month), dest))
+}
+
+// SetSessionSummary caches a monthly session summary
+func (s *CoachStore) SetSessionSummary(coachID uint, month string, summary interface{}) {
+	if !s.redis.IsAvailable() || summary == nil {
+		return
+	}
+	s.redis.SetJSON(KeyCoachSessionSummary(coachID, month), summary, CoachSessionSummaryTTL)
+}
+
+// GetBookingPage loads a cached public booking page into dest.
+// The page shape is owned by the service layer, so it is stored as opaque JSON.
+func (s *CoachStore) GetBookingPage(slug, startDate, endDate string, sessionTypeID uint, dest interface{}) bool {
+	if !s.redis.IsAvailable() {
+		return false
+	}
+	return s.redis.countLookup(KeyspaceCoach, s.redis.GetJSON(KeyCoachBookingPage(slug, startDate, endDate, sessionTypeID), dest))
+}
+
+// SetBookingPage caches a public booking page. Entries expire rather than being invalidated.
+func (s *CoachStore) SetBookingPage(slug, startDate, endDate string, sessionTypeID uint, page interface{}) {
+	if !s.redis.IsAvailable() || page == nil {
+		return
+	}
+	s.redis.SetJSON(KeyCoachBookingPage(slug, startDate, endDate, sessionTypeID), page, CoachBookingPageTTL)
+}
+
+// ProfileViewsEnabled reports whether profile views are being counted; they live only in Redis
+func (s *CoachStore) ProfileViewsEnabled() bool {
+	return s.redis.IsAvailable()
+}
+
+// RecordProfileView adds viewer to the coach's viewer set for day and notes that the coach was
+// viewed, so the flush worker can find them. Repeat views by the same viewer are absorbed by the set.
+func (s *CoachStore) RecordProfileView(coachID uint, day, viewer string) {
+	if !s.redis.IsAvailable() {
+		return
+	}
+	if s.redis.SAddWithExpiry(KeyCoachProfileViewers(coachID, day), ProfileViewTTL, viewer) {
+		s.redis.SAddWithExpiry(KeyProfileViewedCoaches(day), ProfileViewTTL, strconv.FormatUint(uint64(coachID), 10))
+	}
+}
+
+// GetProfileViews returns the number of distinct viewers of a coach's profile on day
+func (s *CoachStore) GetProfileViews(coachID uint, day string) (int64, bool) {
+	if !s.redis.IsAvailable() {
+		return 0, false
+	}
+	return s.redis.SCard(KeyCoachProfileViewers(coachID, day))
+}
+
+// ListProfileViewedCoaches returns the IDs of coaches whose profile was viewed on day
+func (s *CoachStore) ListProfileViewedCoaches(day string) ([]uint, bool) {
+	if !s.redis.IsAvailable() {
+		return nil, false
+	}
+	members, ok := s.redis.SMembers(KeyProfileViewedCoaches(day))
+	if !ok {
+		return nil, false
+	}
+	coachIDs := make([]uint, 0, len(members))
+	for _, member := range members {
+		id, err := strconv.ParseUint(member, 10, 64)
+		if err != nil || id == 0 {
+			continue
+		}
+		coachIDs = append(coachIDs, uint(id))
+	}
+	return coachIDs, true
+}
+
+// InvalidateEarnings removes every cached earnings report for a coach
+func (s *CoachStore) InvalidateEarnings(coachID uint) {
+	if s.redis.IsAvailable() {
+		s.redis.DeletePattern(KeyCoachEarnings(coachID, "*", "*"))
+	}
+}
+
+// InvalidateSessionSummaries removes every cached session summary for a coach
+func (s *CoachStore) InvalidateSessionSummaries(coachID uint) {
+	if s.redis.IsAvailable() {
+		s.redis.DeletePattern(KeyCoachSessionSummary(coachID, "*"))
+	}
+}
+
 // InvalidateProfile removes a coach profile from cache
 func (s *CoachStore) InvalidateProfile(coachID uint) {
 	if s.redis.IsAvailable() {
@@ -151,5 +288,6 @@ func (s *CoachStore) InvalidateAll(coachID uint) {
 	s.InvalidateStats(coachID)
 	if s.redis.IsAvailable() {
 		s.redis.Delete(KeyCoachAvailability(coachID))
+		s.redis.DeletePattern(KeyCoachAvailabilityOverrides(coachID, "*", "*"))
 	}
 }
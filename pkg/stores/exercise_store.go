@@ -78,7 +78,7 @@ func (s *ExerciseStore) Get(exerciseID uint) (*CachedExercise, bool) {
 	}
 
 	var exercise CachedExercise
-	if s.redis.GetJSON(KeyExercise(exerciseID), &exercise) {
+	if s.redis.countLookup(KeyspaceExercise, s.redis.GetJSON(KeyExercise(exerciseID), &exercise)) {
 		return &exercise, true
 	}
 	return nil, false
@@ -117,7 +117,7 @@ func (s *ExerciseStore) GetList(coachID uint, page int) ([]CachedExercise, bool)
 	}
 
 	var exercises []CachedExercise
-	if s.redis.GetJSON(KeyExerciseList(coachID, page), &exercises) {
+	if s.redis.countLookup(KeyspaceExercise, s.redis.GetJSON(KeyExerciseList(coachID, page), &exercises)) {
 		return exercises, true
 	}
 	return nil, false
@@ -144,7 +144,7 @@ func (s *ExerciseStore) GetSystemList(page int) ([]CachedExercise, bool) {
 	}
 
 	var exercises []CachedExercise
-	if s.redis.GetJSON(KeySystemExercises(page), &exercises) {
+	if s.redis.countLookup(KeyspaceExercise, s.redis.GetJSON(KeySystemExercises(page), &exercises)) {
 		return exercises, true
 	}
 	return nil, false
@@ -0,0 +1,42 @@
+package stores
+
+import "time"
+
+// FlagStore caches each user's evaluated feature flags. Entries are never invalidated: a flag change
+// reaches every user once their entry expires, which keeps admin edits from scanning Redis.
+type FlagStore struct {
+	redis *RedisClient
+}
+
+const (
+	UserFlagsTTL = 60 * time.Second
+)
+
+// NewFlagStore creates a new flag store
+func NewFlagStore(redis *RedisClient) *FlagStore {
+	return &FlagStore{redis: redis}
+}
+
+// GetUserFlags retrieves a user's cached flag results keyed by flag key
+func (s *FlagStore) GetUserFlags(userID uint) (map[string]bool, bool) {
+	if !s.redis.IsAvailable() {
+		return nil, false
+	}
+
+	var flags map[string]bool
+	if s.redis.GetJSON(KeyUserFlags(userID), &flags) {
+		return flags, true
+	}
+	return nil, false
+}
+
+// SetUserFlags caches a user's flag results
+func (s *FlagStore) SetUserFlags(userID uint, flags map[string]bool) {
+	if !s.redis.IsAvailable() {
+		return
+	}
+	if flags == nil {
+		flags = map[string]bool{}
+	}
+	s.redis.SetJSON(KeyUserFlags(userID), flags, UserFlagsTTL)
+}
@@ -17,10 +17,15 @@ type StoresCollection struct {
 	Exercise     *ExerciseStore
 	Nutrition    *NutritionStore
 	Session      *SessionStore
+	Availability *AvailabilityStore
+	Flag         *FlagStore
 
 	// Security & rate limiting
 	Security    *SecurityStore
 	RateLimiter *RateLimiter
+
+	// Per-keyspace usage and hit/miss reporting
+	Inspector *CacheInspector
 }
 
 // InitializeStores initializes all Redis-backed stores
@@ -45,10 +50,14 @@ func InitializeStores(cfg config.Environment) (*StoresCollection, error) {
 		Exercise:     NewExerciseStore(redis),
 		Nutrition:    NewNutritionStore(redis),
 		Session:      NewSessionStore(redis),
+		Availability: NewAvailabilityStore(redis),
+		Flag:         NewFlagStore(redis),
 
 		// Security
 		Security:    NewSecurityStore(redis),
 		RateLimiter: NewRateLimiter(redis),
+
+		Inspector: NewCacheInspector(redis, int64(cfg.CacheMemorySoftLimitMB)<<20),
 	}
 
 	if redis.IsAvailable() {
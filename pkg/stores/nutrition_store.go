@@ -75,7 +75,7 @@ func (s *NutritionStore) GetByBarcode(barcode string) (*CachedFoodItem, bool) {
 	}
 
 	var food CachedFoodItem
-	if s.redis.GetJSON(KeyFoodByBarcode(barcode), &food) {
+	if s.redis.countLookup(KeyspaceFood, s.redis.GetJSON(KeyFoodByBarcode(barcode), &food)) {
 		return &food, true
 	}
 	return nil, false
@@ -98,7 +98,7 @@ func (s *NutritionStore) GetByExternalID(source, externalID string) (*CachedFood
 	}
 
 	var food CachedFoodItem
-	if s.redis.GetJSON(KeyFoodByExternalID(source, externalID), &food) {
+	if s.redis.countLookup(KeyspaceFood, s.redis.GetJSON(KeyFoodByExternalID(source, externalID), &food)) {
 		return &food, true
 	}
 	return nil, false
@@ -131,7 +131,7 @@ func (s *NutritionStore) GetSearchResults(query string, page int) ([]CachedFoodI
 	}
 
 	var foods []CachedFoodItem
-	if s.redis.GetJSON(KeyFoodSearch(query, page), &foods) {
+	if s.redis.countLookup(KeyspaceFood, s.redis.GetJSON(KeyFoodSearch(query, page), &foods)) {
 		return foods, true
 	}
 	return nil, false
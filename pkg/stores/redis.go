@@ -15,6 +15,9 @@ import (
 type RedisClient struct {
 	client *redis.Client
 	ctx    context.Context
+
+	// lookups counts cache hits and misses per keyspace since the process started
+	lookups map[string]*lookupCounter
 }
 
 // NewRedisClient creates a new Redis client wrapper
@@ -22,7 +25,7 @@ type RedisClient struct {
 func NewRedisClient(redisURL string) (*RedisClient, error) {
 	if redisURL == "" {
 		slog.Warn("Redis URL not configured, caching disabled")
-		return &RedisClient{client: nil, ctx: context.Background()}, nil
+		return &RedisClient{client: nil, ctx: context.Background(), lookups: newLookupCounters()}, nil
 	}
 
 	opts, err := redis.ParseURL(redisURL)
@@ -39,16 +42,16 @@ func NewRedisClient(redisURL string) (*RedisClient, error) {
 	// Test connection but don't fail if Redis is down (fail-open)
 	if err := client.Ping(ctx).Err(); err != nil {
 		slog.Warn("Redis connection failed, caching disabled", "error", err)
-		return &RedisClient{client: nil, ctx: ctx}, nil
+		return &RedisClient{client: nil, ctx: ctx, lookups: newLookupCounters()}, nil
 	}
 
 	slog.Info("Redis connected successfully")
-	return &RedisClient{client: client, ctx: ctx}, nil
+	return &RedisClient{client: client, ctx: ctx, lookups: newLookupCounters()}, nil
 }
 
 // IsAvailable returns true if Redis client is connected
 func (r *RedisClient) IsAvailable() bool {
-	return r.client != nil
+	return r != nil && r.client != nil
 }
 
 // Get retrieves a value by key
@@ -120,27 +123,32 @@ func (r *RedisClient) Delete(key string) bool {
 }
 
 // DeletePattern removes all keys matching a pattern
-// Use with caution in production (KEYS command can be slow)
+// Walks the keyspace with SCAN so invalidation never blocks Redis the way KEYS does
 func (r *RedisClient) DeletePattern(pattern string) bool {
 	if r.client == nil {
 		return false
 	}
 
-	keys, err := r.client.Keys(r.ctx, pattern).Result()
-	if err != nil {
-		slog.Debug("Redis KEYS error", "pattern", pattern, "error", err)
-		return false
-	}
+	var cursor uint64
+	for {
+		keys, next, err := r.client.Scan(r.ctx, cursor, pattern, scanBatchSize).Result()
+		if err != nil {
+			slog.Debug("Redis SCAN error", "pattern", pattern, "error", err)
+			return false
+		}
 
-	if len(keys) == 0 {
-		return true
-	}
+		if len(keys) > 0 {
+			if err := r.client.Del(r.ctx, keys...).Err(); err != nil {
+				slog.Debug("Redis DEL pattern error", "pattern", pattern, "error", err)
+				return false
+			}
+		}
 
-	if err := r.client.Del(r.ctx, keys...).Err(); err != nil {
-		slog.Debug("Redis DEL pattern error", "pattern", pattern, "error", err)
-		return false
+		cursor = next
+		if cursor == 0 {
+			return true
+		}
 	}
-	return true
 }
 
 // Exists checks if a key exists
@@ -220,6 +228,56 @@ func (r *RedisClient) SetNX(key string, value string, expiration time.Duration)
 	return set
 }
 
+// SAddWithExpiry adds members to a set and refreshes its expiry
+func (r *RedisClient) SAddWithExpiry(key string, expiration time.Duration, members ...string) bool {
+	if r.client == nil || len(members) == 0 {
+		return false
+	}
+
+	args := make([]interface{}, len(members))
+	for i, member := range members {
+		args[i] = member
+	}
+
+	pipe := r.client.Pipeline()
+	pipe.SAdd(r.ctx, key, args...)
+	pipe.Expire(r.ctx, key, expiration)
+
+	if _, err := pipe.Exec(r.ctx); err != nil {
+		slog.Debug("Redis SADD with expiry error", "key", key, "error", err)
+		return false
+	}
+	return true
+}
+
+// SCard returns the number of members in a set; a missing set counts as empty
+func (r *RedisClient) SCard(key string) (int64, bool) {
+	if r.client == nil {
+		return 0, false
+	}
+
+	count, err := r.client.SCard(r.ctx, key).Result()
+	if err != nil {
+		slog.Debug("Redis SCARD error", "key", key, "error", err)
+		return 0, false
+	}
+	return count, true
+}
+
+// SMembers returns every member of a set
+func (r *RedisClient) SMembers(key string) ([]string, bool) {
+	if r.client == nil {
+		return nil, false
+	}
+
+	members, err := r.client.SMembers(r.ctx, key).Result()
+	if err != nil {
+		slog.Debug("Redis SMEMBERS error", "key", key, "error", err)
+		return nil, false
+	}
+	return members, true
+}
+
 // Close closes the Redis connection
 func (r *RedisClient) Close() error {
 	if r.client == nil {
@@ -1,6 +1,7 @@
 package stores
 
 import (
+	"strconv"
 	"time"
 )
 
@@ -26,6 +27,10 @@ const (
 	// Invoice creation: 10 per hour per coach-client pair (lenient)
 	InvoiceLimit  = 10
 	InvoiceWindow = time.Hour
+
+	// Conversation exports: 5 per day per user; each one reads a whole message history
+	ConversationExportLimit  = 5
+	ConversationExportWindow = 24 * time.Hour
 )
 
 // NewSecurityStore creates a new security store
@@ -70,6 +75,43 @@ func (s *SecurityStore) ClearLoginAttempts(email string) {
 	}
 }
 
+// --- Access Token Revocation ---
+
+// SetTokensRevokedAt caches the user's access token cut-off. A zero time caches "never revoked" so
+// the database isn't consulted on every request. Returns false when Redis is unavailable.
+func (s *SecurityStore) SetTokensRevokedAt(userID uint, revokedAt time.Time, ttl time.Duration) bool {
+	if !s.redis.IsAvailable() {
+		return false
+	}
+
+	value := "0"
+	if !revokedAt.IsZero() {
+		value = strconv.FormatInt(revokedAt.Unix(), 10)
+	}
+	return s.redis.Set(KeyTokenRevocation(userID), value, ttl)
+}
+
+// GetTokensRevokedAt returns the cached cut-off (zero when never revoked). found is false on a
+// cache miss or when Redis is unavailable.
+func (s *SecurityStore) GetTokensRevokedAt(userID uint) (revokedAt time.Time, found bool) {
+	if !s.redis.IsAvailable() {
+		return time.Time{}, false
+	}
+
+	val, ok := s.redis.Get(KeyTokenRevocation(userID))
+	if !ok {
+		return time.Time{}, false
+	}
+	seconds, err := strconv.ParseInt(val, 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	if seconds == 0 {
+		return time.Time{}, true
+	}
+	return time.Unix(seconds, 0).UTC(), true
+}
+
 // --- Password Reset Rate Limiting ---
 
 // CheckPasswordResetAllowed checks if a password reset request is allowed
@@ -156,6 +198,23 @@ func (s *SecurityStore) GetInvoiceRemaining(coachID, clientID uint) int64 {
 	return max(0, InvoiceLimit-count)
 }
 
+// --- Conversation Export Rate Limiting ---
+
+// CheckConversationExportAllowed counts an export against the user's daily allowance
+func (s *SecurityStore) CheckConversationExportAllowed(userID uint) bool {
+	if !s.redis.IsAvailable() {
+		return true
+	}
+
+	key := KeyRateLimit(formatUintSafe(userID), "conversation_export")
+	count, ok := s.redis.IncrWithExpiry(key, ConversationExportWindow)
+	if !ok {
+		return true
+	}
+
+	return count <= ConversationExportLimit
+}
+
 // formatCoachClient creates a unique identifier for coach-client pair
 func formatCoachClient(coachID, clientID uint) string {
 	return formatUintSafe(coachID) + ":" + formatUintSafe(clientID)
@@ -12,7 +12,8 @@ type SubscriptionStore struct {
 }
 
 const (
-	SubscriptionTTL = 30 * time.Minute
+	// Kept short since the app reads it on every start; webhook updates also invalidate the entry
+	SubscriptionTTL = 60 * time.Second
 )
 
 // NewSubscriptionStore creates a new subscription store
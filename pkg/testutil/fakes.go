@@ -0,0 +1,86 @@
+package testutil
+
+import (
+	"chalk-api/pkg/external/expo"
+	"chalk-api/pkg/external/revenuecat"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+var (
+	_ expo.API       = (*FakeExpo)(nil)
+	_ revenuecat.API = (*FakeRevenueCat)(nil)
+)
+
+// FakeExpo records pushes instead of calling the Expo API. Every message gets an "ok" ticket.
+type FakeExpo struct {
+	mu   sync.Mutex
+	Sent []expo.PushMessage
+}
+
+func (f *FakeExpo) SendPush(ctx context.Context, messages []expo.PushMessage) ([]expo.PushTicket, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	tickets := make([]expo.PushTicket, 0, len(messages))
+	for _, message := range messages {
+		f.Sent = append(f.Sent, message)
+		tickets = append(tickets, expo.PushTicket{ID: fmt.Sprintf("ticket-%d", len(f.Sent)), Status: "ok"})
+	}
+	return tickets, nil
+}
+
+func (f *FakeExpo) GetReceipts(ctx context.Context, ticketIDs []string) (map[string]expo.PushReceipt, error) {
+	receipts := make(map[string]expo.PushReceipt, len(ticketIDs))
+	for _, id := range ticketIDs {
+		receipts[id] = expo.PushReceipt{Status: "ok"}
+	}
+	return receipts, nil
+}
+
+// Messages returns a copy of the pushes sent so far.
+func (f *FakeExpo) Messages() []expo.PushMessage {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]expo.PushMessage(nil), f.Sent...)
+}
+
+// FakeRevenueCat serves subscribers from memory and accepts webhooks carrying WebhookAuthorization.
+type FakeRevenueCat struct {
+	mu                   sync.Mutex
+	Subscribers          map[string]*revenuecat.Subscriber
+	WebhookAuthorization string
+}
+
+func NewFakeRevenueCat() *FakeRevenueCat {
+	return &FakeRevenueCat{
+		Subscribers:          make(map[string]*revenuecat.Subscriber),
+		WebhookAuthorization: "test-webhook-authorization",
+	}
+}
+
+func (f *FakeRevenueCat) GetSubscriber(ctx context.Context, appUserID string) (*revenuecat.Subscriber, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	subscriber, ok := f.Subscribers[appUserID]
+	if !ok {
+		return nil, errors.New("subscriber not found")
+	}
+	return subscriber, nil
+}
+
+func (f *FakeRevenueCat) ValidateWebhook(body []byte, authorization string) (*revenuecat.WebhookEvent, error) {
+	if authorization != f.WebhookAuthorization {
+		return nil, errors.New("invalid webhook authorization")
+	}
+
+	var event revenuecat.WebhookEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil, fmt.Errorf("decode webhook: %w", err)
+	}
+	return &event, nil
+}
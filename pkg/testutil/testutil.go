@@ -0,0 +1,266 @@
+// Package testutil builds the full application stack against a real Postgres database for
+// integration tests. Point TEST_DATABASE_URL at a disposable database; when it is unset the
+// helpers skip the calling test so `go test ./...` stays green on machines without Postgres.
+package testutil
+
+import (
+	"bytes"
+	"chalk-api/pkg/config"
+	"chalk-api/pkg/db"
+	"chalk-api/pkg/external"
+	"chalk-api/pkg/external/openfoodfacts"
+	"chalk-api/pkg/external/storage"
+	"chalk-api/pkg/handlers"
+	"chalk-api/pkg/models"
+	"chalk-api/pkg/repositories"
+	"chalk-api/pkg/routes"
+	"chalk-api/pkg/services"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// DatabaseURLEnv names the environment variable holding the test database DSN.
+const DatabaseURLEnv = "TEST_DATABASE_URL"
+
+const testJWTSecret = "integration-test-secret"
+
+var userSeq atomic.Int64
+
+// Stack is a fully wired application backed by the test database and fake integrations.
+type Stack struct {
+	DB           *gorm.DB
+	Config       config.Environment
+	Repos        *repositories.RepositoriesCollection
+	Services     *services.ServicesCollection
+	Handlers     *handlers.HandlersCollection
+	Router       *gin.Engine
+	Expo         *FakeExpo
+	RevenueCat   *FakeRevenueCat
+	Integrations *external.Collection
+}
+
+// OpenDB connects to TEST_DATABASE_URL, runs migrations and truncates every table so each
+// test starts empty. The test is skipped when no database is configured.
+func OpenDB(tb testing.TB) *gorm.DB {
+	tb.Helper()
+
+	dsn := os.Getenv(DatabaseURLEnv)
+	if dsn == "" {
+		tb.Skipf("%s not set; skipping integration test", DatabaseURLEnv)
+	}
+
+	gormDB, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		tb.Skipf("test database unavailable: %v", err)
+	}
+
+	if err := db.RunMigrations(gormDB); err != nil {
+		tb.Fatalf("run migrations: %v", err)
+	}
+	ResetDB(tb, gormDB)
+
+	tb.Cleanup(func() {
+		if sqlDB, err := gormDB.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return gormDB
+}
+
+// ResetDB truncates all application tables and restarts their ID sequences.
+func ResetDB(tb testing.TB, gormDB *gorm.DB) {
+	tb.Helper()
+
+	var tables []string
+	err := gormDB.Raw(`SELECT tablename FROM pg_tables WHERE schemaname = current_schema()`).Scan(&tables).Error
+	if err != nil {
+		tb.Fatalf("list tables: %v", err)
+	}
+	if len(tables) == 0 {
+		return
+	}
+
+	quoted := make([]string, len(tables))
+	for i, table := range tables {
+		quoted[i] = `"` + table + `"`
+	}
+	stmt := "TRUNCATE TABLE " + strings.Join(quoted, ", ") + " RESTART IDENTITY CASCADE"
+	if err := gormDB.Exec(stmt).Error; err != nil {
+		tb.Fatalf("truncate tables: %v", err)
+	}
+}
+
+// NewStack builds repositories, services, handlers and the real router on top of OpenDB.
+// Object storage is left unconfigured, so upload endpoints answer 503.
+func NewStack(tb testing.TB) *Stack {
+	tb.Helper()
+	gin.SetMode(gin.TestMode)
+
+	gormDB := OpenDB(tb)
+	cfg := config.Environment{
+		RunMode:            "test",
+		JWTSecret:          testJWTSecret,
+		JWTExpirationHours: 1,
+	}
+
+	repos, err := repositories.InitializeRepositories(gormDB)
+	if err != nil {
+		tb.Fatalf("initialize repositories: %v", err)
+	}
+
+	fakeExpo := &FakeExpo{}
+	fakeRevenueCat := NewFakeRevenueCat()
+	integrations := &external.Collection{
+		OpenFoodFacts: openfoodfacts.New("chalk-api-tests"),
+		RevenueCat:    fakeRevenueCat,
+		Expo:          fakeExpo,
+		Storage:       storage.New(storage.Config{}),
+	}
+
+	svc, err := services.InitializeServices(repos, integrations, nil, cfg)
+	if err != nil {
+		tb.Fatalf("initialize services: %v", err)
+	}
+	h, err := handlers.InitializeHandlers(svc, repos, integrations, cfg)
+	if err != nil {
+		tb.Fatalf("initialize handlers: %v", err)
+	}
+
+	return &Stack{
+		DB:           gormDB,
+		Config:       cfg,
+		Repos:        repos,
+		Services:     svc,
+		Handlers:     h,
+		Router:       routes.SetupRouter(h, cfg),
+		Expo:         fakeExpo,
+		RevenueCat:   fakeRevenueCat,
+		Integrations: integrations,
+	}
+}
+
+// CreateUser registers a user through AuthService and returns it with a valid access token.
+func (s *Stack) CreateUser(tb testing.TB) (*models.User, string) {
+	tb.Helper()
+
+	n := userSeq.Add(1)
+	result, err := s.Services.Auth.Register(context.Background(), services.RegisterInput{
+		Email:     fmt.Sprintf("user%d@example.test", n),
+		Password:  "password123",
+		FirstName: "Test",
+		LastName:  fmt.Sprintf("User%d", n),
+		Timezone:  "UTC",
+	}, "testutil", "127.0.0.1")
+	if err != nil {
+		tb.Fatalf("register user: %v", err)
+	}
+	return result.User, result.AccessToken
+}
+
+// CreateCoach registers a user and gives them a coach profile.
+func (s *Stack) CreateCoach(tb testing.TB) (*models.User, *models.CoachProfile, string) {
+	tb.Helper()
+
+	user, token := s.CreateUser(tb)
+	profile := &models.CoachProfile{UserID: user.ID}
+	if err := s.Repos.Coach.Create(context.Background(), profile); err != nil {
+		tb.Fatalf("create coach profile: %v", err)
+	}
+	return user, profile, token
+}
+
+// ConnectClient makes client a client of coach through a single-use invite code.
+func (s *Stack) ConnectClient(tb testing.TB, coach, client *models.User) *models.ClientProfile {
+	tb.Helper()
+
+	ctx := context.Background()
+	invite, err := s.Services.Coach.CreateInviteCode(ctx, coach.ID, services.CreateInviteCodeInput{})
+	if err != nil {
+		tb.Fatalf("create invite code: %v", err)
+	}
+	result, err := s.Services.Coach.AcceptInvite(ctx, client.ID, services.AcceptInviteInput{Code: invite.Code})
+	if err != nil {
+		tb.Fatalf("accept invite: %v", err)
+	}
+	return result.ClientProfile
+}
+
+// CreateExercise inserts an active system exercise measured in reps and returns its ID.
+func (s *Stack) CreateExercise(tb testing.TB, name string) uint {
+	tb.Helper()
+
+	var id uint
+	err := s.DB.Raw(
+		`INSERT INTO exercises (name, category, measurement_type, source, is_system, is_active, created_at, updated_at)
+		VALUES (?, 'strength', 'reps', 'chalk', true, true, NOW(), NOW()) RETURNING id`,
+		name,
+	).Scan(&id).Error
+	if err != nil {
+		tb.Fatalf("create exercise: %v", err)
+	}
+	return id
+}
+
+// Request sends a JSON request through the router. body may be nil; token may be empty.
+func (s *Stack) Request(tb testing.TB, method, path, token string, body any) *httptest.ResponseRecorder {
+	tb.Helper()
+
+	var payload bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&payload).Encode(body); err != nil {
+			tb.Fatalf("encode request body: %v", err)
+		}
+	}
+
+	req := httptest.NewRequest(method, path, &payload)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	rec := httptest.NewRecorder()
+	s.Router.ServeHTTP(rec, req)
+	return rec
+}
+
+// DecodeJSON unmarshals a recorded response, failing the test on unexpected status codes.
+func DecodeJSON(tb testing.TB, rec *httptest.ResponseRecorder, wantStatus int, dest any) {
+	tb.Helper()
+
+	if rec.Code != wantStatus {
+		tb.Fatalf("status = %d, want %d; body: %s", rec.Code, wantStatus, rec.Body.String())
+	}
+	if dest == nil {
+		return
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), dest); err != nil {
+		tb.Fatalf("decode response: %v; body: %s", err, rec.Body.String())
+	}
+}
+
+// OutboxEvents returns the queued outbox events of the given type, oldest first.
+func (s *Stack) OutboxEvents(tb testing.TB, eventType string) []models.OutboxEvent {
+	tb.Helper()
+
+	var events []models.OutboxEvent
+	err := s.DB.Where("event_type = ?", eventType).Order("id ASC").Find(&events).Error
+	if err != nil {
+		tb.Fatalf("list outbox events: %v", err)
+	}
+	return events
+}
@@ -0,0 +1,112 @@
+// Package units converts logged weights and distances into the unit a viewer prefers. Logs keep the
+// unit they were submitted in; conversion only happens on the way out.
+package units
+
+import (
+	"math"
+	"strings"
+)
+
+// Preferred units a profile can pick.
+const (
+	Kilograms  = "kg"
+	Pounds     = "lbs"
+	Kilometers = "km"
+	Miles      = "mi"
+)
+
+// Meters is a distance unit logs may use; it is converted but can't be a preference.
+const Meters = "m"
+
+const (
+	poundsPerKilogram = 2.20462262185
+	metersPerMile     = 1609.344
+)
+
+// Preferences are the units a user wants to see weights and distances in.
+type Preferences struct {
+	Weight   string `json:"weight_unit"`
+	Distance string `json:"distance_unit"`
+}
+
+// DefaultPreferences applies to profiles that never picked units.
+var DefaultPreferences = Preferences{Weight: Kilograms, Distance: Kilometers}
+
+// NormalizeWeight maps the spellings apps send ("kg", "kgs", "lb", "lbs", "pounds") to Kilograms or
+// Pounds, reporting false for anything else.
+func NormalizeWeight(unit string) (string, bool) {
+	switch strings.ToLower(strings.TrimSpace(unit)) {
+	case "kg", "kgs", "kilogram", "kilograms":
+		return Kilograms, true
+	case "lb", "lbs", "pound", "pounds":
+		return Pounds, true
+	}
+	return "", false
+}
+
+// NormalizeDistance maps the spellings apps send to Kilometers, Miles or Meters, reporting false for
+// anything else.
+func NormalizeDistance(unit string) (string, bool) {
+	switch strings.ToLower(strings.TrimSpace(unit)) {
+	case "km", "kms", "kilometer", "kilometers", "kilometre", "kilometres":
+		return Kilometers, true
+	case "mi", "mile", "miles":
+		return Miles, true
+	case "m", "meter", "meters", "metre", "metres":
+		return Meters, true
+	}
+	return "", false
+}
+
+// ConvertWeight converts value from one weight unit to another and rounds it to the nearest plate
+// increment of the target unit: 0.25 kg or 0.5 lbs. A value already in the target unit is returned
+// untouched, so repeated reads of logs in the viewer's unit never drift. Unknown units report false.
+func ConvertWeight(value float64, from, to string) (float64, bool) {
+	from, okFrom := NormalizeWeight(from)
+	to, okTo := NormalizeWeight(to)
+	if !okFrom || !okTo {
+		return value, false
+	}
+	if from == to {
+		return value, true
+	}
+	if to == Pounds {
+		return roundTo(value*poundsPerKilogram, 0.5), true
+	}
+	return roundTo(value/poundsPerKilogram, 0.25), true
+}
+
+// ConvertDistance converts value between kilometers, miles and meters, rounded to 0.01 of the target
+// unit (whole meters). A value already in the target unit is returned untouched; unknown units report
+// false.
+func ConvertDistance(value float64, from, to string) (float64, bool) {
+	from, okFrom := NormalizeDistance(from)
+	to, okTo := NormalizeDistance(to)
+	if !okFrom || !okTo {
+		return value, false
+	}
+	if from == to {
+		return value, true
+	}
+	meters := value * metersIn(from)
+	if to == Meters {
+		return math.Round(meters), true
+	}
+	return roundTo(meters/metersIn(to), 0.01), true
+}
+
+func metersIn(unit string) float64 {
+	switch unit {
+	case Kilometers:
+		return 1000
+	case Miles:
+		return metersPerMile
+	}
+	return 1
+}
+
+// roundTo rounds value to the nearest multiple of step, trimming float noise from the division.
+func roundTo(value, step float64) float64 {
+	rounded := math.Round(value/step) * step
+	return math.Round(rounded*100) / 100
+}
@@ -0,0 +1,96 @@
+package units
+
+import (
+	"math"
+	"testing"
+)
+
+func TestConvertWeightRoundsToPlateIncrements(t *testing.T) {
+	tests := []struct {
+		value    float64
+		from, to string
+		want     float64
+	}{
+		{100, Kilograms, Pounds, 220.5},
+		{20, "kgs", "lb", 44},
+		{45, Pounds, Kilograms, 20.5},
+		{135, "pounds", "kg", 61.25},
+		{2.5, Kilograms, Pounds, 5.5},
+		{0, Pounds, Kilograms, 0},
+		{82.3, Kilograms, Kilograms, 82.3},
+	}
+	for _, tt := range tests {
+		got, ok := ConvertWeight(tt.value, tt.from, tt.to)
+		if !ok || got != tt.want {
+			t.Errorf("ConvertWeight(%v, %q, %q) = %v, %v; want %v", tt.value, tt.from, tt.to, got, ok, tt.want)
+		}
+	}
+}
+
+// A weight converted back and forth settles after the first round trip instead of drifting a plate
+// increment on every read.
+func TestConvertWeightRoundTripIsStable(t *testing.T) {
+	for kg := 0.0; kg <= 300; kg += 0.25 {
+		lbs, _ := ConvertWeight(kg, Kilograms, Pounds)
+		backKg, _ := ConvertWeight(lbs, Pounds, Kilograms)
+		againLbs, _ := ConvertWeight(backKg, Kilograms, Pounds)
+
+		if math.Abs(backKg-kg) > 0.25 {
+			t.Fatalf("%v kg -> %v lbs -> %v kg drifted by more than 0.25 kg", kg, lbs, backKg)
+		}
+		if againLbs != lbs {
+			t.Fatalf("%v kg -> %v lbs -> %v kg -> %v lbs did not settle", kg, lbs, backKg, againLbs)
+		}
+		if remainder := math.Mod(lbs, 0.5); remainder != 0 {
+			t.Fatalf("%v kg converted to %v lbs, not a 0.5 lb increment", kg, lbs)
+		}
+		if remainder := math.Mod(backKg, 0.25); remainder != 0 {
+			t.Fatalf("%v lbs converted to %v kg, not a 0.25 kg increment", lbs, backKg)
+		}
+	}
+
+	for lbs := 0.0; lbs <= 600; lbs += 0.5 {
+		kg, _ := ConvertWeight(lbs, Pounds, Kilograms)
+		backLbs, _ := ConvertWeight(kg, Kilograms, Pounds)
+		againKg, _ := ConvertWeight(backLbs, Pounds, Kilograms)
+
+		if math.Abs(backLbs-lbs) > 0.5 {
+			t.Fatalf("%v lbs -> %v kg -> %v lbs drifted by more than 0.5 lbs", lbs, kg, backLbs)
+		}
+		if againKg != kg {
+			t.Fatalf("%v lbs -> %v kg -> %v lbs -> %v kg did not settle", lbs, kg, backLbs, againKg)
+		}
+	}
+}
+
+func TestConvertDistance(t *testing.T) {
+	tests := []struct {
+		value    float64
+		from, to string
+		want     float64
+	}{
+		{5, Kilometers, Miles, 3.11},
+		{26.2, Miles, Kilometers, 42.16},
+		{1, Miles, Meters, 1609},
+		{400, "metres", "km", 0.4},
+		{10, "kms", "kilometers", 10},
+	}
+	for _, tt := range tests {
+		got, ok := ConvertDistance(tt.value, tt.from, tt.to)
+		if !ok || got != tt.want {
+			t.Errorf("ConvertDistance(%v, %q, %q) = %v, %v; want %v", tt.value, tt.from, tt.to, got, ok, tt.want)
+		}
+	}
+}
+
+func TestConvertRejectsUnknownUnits(t *testing.T) {
+	if got, ok := ConvertWeight(10, "stone", Kilograms); ok || got != 10 {
+		t.Errorf("ConvertWeight from stone = %v, %v; want the value back and false", got, ok)
+	}
+	if got, ok := ConvertDistance(10, Kilometers, "furlong"); ok || got != 10 {
+		t.Errorf("ConvertDistance to furlong = %v, %v; want the value back and false", got, ok)
+	}
+	if _, ok := NormalizeWeight("km"); ok {
+		t.Error("NormalizeWeight accepted a distance unit")
+	}
+}
@@ -1,10 +1,12 @@
 package utils
 
 import (
+	"context"
 	"crypto/rand"
 	"encoding/hex"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -38,6 +40,15 @@ func Contains(slice []string, val string) bool {
 	return false
 }
 
+// ResolveLocation loads an IANA timezone name, falling back to UTC when it is empty or unknown
+func ResolveLocation(tz string) *time.Location {
+	loc, err := time.LoadLocation(tz)
+	if err != nil || tz == "" {
+		return time.UTC
+	}
+	return loc
+}
+
 // StringPtr returns a pointer to a string
 func StringPtr(s string) *string {
 	return &s
@@ -82,3 +93,20 @@ func GetUserIDFromContext(c *gin.Context) (uint, bool) {
 		return 0, false
 	}
 }
+
+type requestIDKey struct{}
+
+// WithRequestID returns a copy of ctx carrying the request ID, so code below the handlers
+// (e.g. the database logger) can correlate its output with the originating request.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestIDFromContext returns the request ID stored by WithRequestID, or "" if none.
+func RequestIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
@@ -0,0 +1,83 @@
+package validators
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// Allowed enum values shared by request inputs and models.
+var (
+	WeightUnits   = []string{"lbs", "kg"}
+	DistanceUnits = []string{"miles", "km", "meters"}
+	MealTypes     = []string{"breakfast", "lunch", "dinner", "snack"}
+)
+
+// Register installs the custom validation tags on gin's binding engine and makes
+// validation errors report JSON field names instead of Go struct field names.
+// Must run before the router starts serving requests.
+func Register() error {
+	engine, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return fmt.Errorf("unexpected binding validator engine %T", binding.Validator.Engine())
+	}
+
+	engine.RegisterTagNameFunc(jsonFieldName)
+
+	custom := map[string]validator.Func{
+		"hhmm":          isHHMM,
+		"date":          isDateOnly,
+		"rfc3339":       isRFC3339,
+		"weight_unit":   oneOf(WeightUnits),
+		"distance_unit": oneOf(DistanceUnits),
+		"meal_type":     oneOf(MealTypes),
+	}
+	for tag, fn := range custom {
+		if err := engine.RegisterValidation(tag, fn); err != nil {
+			return fmt.Errorf("failed to register %s validator: %w", tag, err)
+		}
+	}
+	return nil
+}
+
+func jsonFieldName(field reflect.StructField) string {
+	name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+	if name == "-" {
+		return ""
+	}
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
+
+func isHHMM(fl validator.FieldLevel) bool {
+	_, err := time.Parse("15:04", strings.TrimSpace(fl.Field().String()))
+	return err == nil
+}
+
+func isDateOnly(fl validator.FieldLevel) bool {
+	_, err := time.Parse("2006-01-02", strings.TrimSpace(fl.Field().String()))
+	return err == nil
+}
+
+func isRFC3339(fl validator.FieldLevel) bool {
+	_, err := time.Parse(time.RFC3339, strings.TrimSpace(fl.Field().String()))
+	return err == nil
+}
+
+func oneOf(allowed []string) validator.Func {
+	return func(fl validator.FieldLevel) bool {
+		value := strings.ToLower(strings.TrimSpace(fl.Field().String()))
+		for _, candidate := range allowed {
+			if value == candidate {
+				return true
+			}
+		}
+		return false
+	}
+}
@@ -0,0 +1,80 @@
+package workers
+
+import (
+	"chalk-api/pkg/services"
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// AtRiskWorker periodically refreshes client activity and flags clients who have gone quiet.
+type AtRiskWorker struct {
+	activityService *services.ClientActivityService
+	interval        time.Duration
+
+	stopCh    chan struct{}
+	doneCh    chan struct{}
+	startOnce sync.Once
+	stopOnce  sync.Once
+}
+
+func NewAtRiskWorker(activityService *services.ClientActivityService, interval time.Duration) *AtRiskWorker {
+	if interval <= 0 {
+		interval = time.Hour
+	}
+
+	return &AtRiskWorker{
+		activityService: activityService,
+		interval:        interval,
+		stopCh:          make(chan struct{}),
+		doneCh:          make(chan struct{}),
+	}
+}
+
+func (w *AtRiskWorker) Start() {
+	w.startOnce.Do(func() {
+		go w.loop()
+		slog.Info("At-risk worker started", "interval", w.interval.String())
+	})
+}
+
+func (w *AtRiskWorker) Stop() {
+	w.stopOnce.Do(func() {
+		close(w.stopCh)
+		<-w.doneCh
+		slog.Info("At-risk worker stopped")
+	})
+}
+
+func (w *AtRiskWorker) loop() {
+	defer close(w.doneCh)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	w.runCycle()
+
+	for {
+		select {
+		case <-w.stopCh:
+			return
+		case <-ticker.C:
+			w.runCycle()
+		}
+	}
+}
+
+func (w *AtRiskWorker) runCycle() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	result, err := w.activityService.RefreshAtRisk(ctx, time.Now().UTC())
+	if err != nil {
+		slog.Error("At-risk worker failed to refresh clients", "error", err)
+		return
+	}
+	if result.Flagged > 0 || result.Cleared > 0 {
+		slog.Info("Client at-risk flags updated", "flagged", result.Flagged, "cleared", result.Cleared)
+	}
+}
@@ -0,0 +1,81 @@
+package workers
+
+import (
+	"chalk-api/pkg/services"
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// ClientImportWorker works through client imports too large to process during the upload, and
+// resumes any import whose worker stopped part way through.
+type ClientImportWorker struct {
+	coachService *services.CoachService
+	interval     time.Duration
+
+	stopCh    chan struct{}
+	doneCh    chan struct{}
+	startOnce sync.Once
+	stopOnce  sync.Once
+}
+
+func NewClientImportWorker(coachService *services.CoachService, interval time.Duration) *ClientImportWorker {
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+
+	return &ClientImportWorker{
+		coachService: coachService,
+		interval:     interval,
+		stopCh:       make(chan struct{}),
+		doneCh:       make(chan struct{}),
+	}
+}
+
+func (w *ClientImportWorker) Start() {
+	w.startOnce.Do(func() {
+		go w.loop()
+		slog.Info("Client import worker started", "interval", w.interval.String())
+	})
+}
+
+func (w *ClientImportWorker) Stop() {
+	w.stopOnce.Do(func() {
+		close(w.stopCh)
+		<-w.doneCh
+		slog.Info("Client import worker stopped")
+	})
+}
+
+func (w *ClientImportWorker) loop() {
+	defer close(w.doneCh)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	w.runCycle()
+
+	for {
+		select {
+		case <-w.stopCh:
+			return
+		case <-ticker.C:
+			w.runCycle()
+		}
+	}
+}
+
+func (w *ClientImportWorker) runCycle() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	processed, err := w.coachService.ProcessPendingClientImports(ctx)
+	if err != nil {
+		slog.Error("Client import worker failed", "error", err, "processed", processed)
+		return
+	}
+	if processed > 0 {
+		slog.Info("Client imports processed", "count", processed)
+	}
+}
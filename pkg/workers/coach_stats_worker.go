@@ -0,0 +1,102 @@
+package workers
+
+import (
+	"chalk-api/pkg/services"
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// coachStatsCheckInterval is how often the worker looks at the clock for its weekly slot
+const coachStatsCheckInterval = 10 * time.Minute
+
+// CoachStatsWorker rebuilds every coach's incremented counters once a week and logs the coaches whose
+// stored values had drifted. It runs in a fixed UTC weekday and hour rather than on a week-long ticker,
+// so deploys in between don't keep pushing the run back.
+type CoachStatsWorker struct {
+	adminService *services.AdminService
+	weekday      time.Weekday
+	hour         int
+
+	lastRun time.Time // start of the slot last run in; only touched by the loop goroutine
+
+	stopCh    chan struct{}
+	doneCh    chan struct{}
+	startOnce sync.Once
+	stopOnce  sync.Once
+}
+
+func NewCoachStatsWorker(adminService *services.AdminService, weekday, hour int) *CoachStatsWorker {
+	if weekday < 0 || weekday > 6 {
+		weekday = int(time.Sunday)
+	}
+	if hour < 0 || hour > 23 {
+		hour = 4
+	}
+
+	return &CoachStatsWorker{
+		adminService: adminService,
+		weekday:      time.Weekday(weekday),
+		hour:         hour,
+		stopCh:       make(chan struct{}),
+		doneCh:       make(chan struct{}),
+	}
+}
+
+func (w *CoachStatsWorker) Start() {
+	w.startOnce.Do(func() {
+		go w.loop()
+		slog.Info("Coach stats worker started", "weekday", w.weekday.String(), "hour_utc", w.hour)
+	})
+}
+
+func (w *CoachStatsWorker) Stop() {
+	w.stopOnce.Do(func() {
+		close(w.stopCh)
+		<-w.doneCh
+		slog.Info("Coach stats worker stopped")
+	})
+}
+
+func (w *CoachStatsWorker) loop() {
+	defer close(w.doneCh)
+
+	ticker := time.NewTicker(coachStatsCheckInterval)
+	defer ticker.Stop()
+
+	w.runIfDue(time.Now().UTC())
+
+	for {
+		select {
+		case <-w.stopCh:
+			return
+		case now := <-ticker.C:
+			w.runIfDue(now.UTC())
+		}
+	}
+}
+
+// runIfDue runs the sweep when now falls in the weekly slot and this worker hasn't run in it yet.
+func (w *CoachStatsWorker) runIfDue(now time.Time) {
+	if now.Weekday() != w.weekday || now.Hour() != w.hour {
+		return
+	}
+	slot := now.Truncate(time.Hour)
+	if w.lastRun.Equal(slot) {
+		return
+	}
+	w.lastRun = slot
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	defer cancel()
+
+	started := time.Now()
+	sweep, err := w.adminService.RecomputeAllCoachStats(ctx)
+	if err != nil {
+		slog.Error("Coach stats worker failed", "error", err, "coaches", sweep.Coaches, "drifted", sweep.Drifted)
+		return
+	}
+	slog.Info("Coach stats recomputed", "coaches", sweep.Coaches, "drifted", sweep.Drifted,
+		"duration", time.Since(started).String())
+}
@@ -0,0 +1,82 @@
+package workers
+
+import (
+	"chalk-api/pkg/services"
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// DigestWorker periodically delivers weekly coach digests whose local send time has arrived.
+// Coaches are scheduled in their own timezone, so it polls rather than firing once a week.
+type DigestWorker struct {
+	digestService *services.DigestService
+	interval      time.Duration
+
+	stopCh    chan struct{}
+	doneCh    chan struct{}
+	startOnce sync.Once
+	stopOnce  sync.Once
+}
+
+func NewDigestWorker(digestService *services.DigestService, interval time.Duration) *DigestWorker {
+	if interval <= 0 {
+		interval = 15 * time.Minute
+	}
+
+	return &DigestWorker{
+		digestService: digestService,
+		interval:      interval,
+		stopCh:        make(chan struct{}),
+		doneCh:        make(chan struct{}),
+	}
+}
+
+func (w *DigestWorker) Start() {
+	w.startOnce.Do(func() {
+		go w.loop()
+		slog.Info("Digest worker started", "interval", w.interval.String())
+	})
+}
+
+func (w *DigestWorker) Stop() {
+	w.stopOnce.Do(func() {
+		close(w.stopCh)
+		<-w.doneCh
+		slog.Info("Digest worker stopped")
+	})
+}
+
+func (w *DigestWorker) loop() {
+	defer close(w.doneCh)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	// Run immediately on startup so a deploy near the send time doesn't delay digests.
+	w.runCycle()
+
+	for {
+		select {
+		case <-w.stopCh:
+			return
+		case <-ticker.C:
+			w.runCycle()
+		}
+	}
+}
+
+func (w *DigestWorker) runCycle() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	sent, err := w.digestService.SendDueDigests(ctx, time.Now().UTC())
+	if err != nil {
+		slog.Error("Digest worker failed to send digests", "error", err)
+		return
+	}
+	if sent > 0 {
+		slog.Info("Weekly digests sent", "count", sent)
+	}
+}
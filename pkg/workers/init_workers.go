@@ -5,13 +5,26 @@ import (
 	"chalk-api/pkg/events"
 	"chalk-api/pkg/external"
 	"chalk-api/pkg/repositories"
+	"chalk-api/pkg/services"
 	"log/slog"
 	"time"
 )
 
 // WorkersCollection contains all background workers
 type WorkersCollection struct {
-	Outbox *OutboxWorker
+	Outbox           *OutboxWorker
+	Digest           *DigestWorker
+	AtRisk           *AtRiskWorker
+	ScheduledMessage *ScheduledMessageWorker
+	RequestAnalytics *RequestAnalyticsWorker
+	Maintenance      *MaintenanceWorker
+
+	SessionConfirmation *SessionConfirmationWorker
+	StaleSession        *StaleSessionWorker
+	CoachStats          *CoachStatsWorker
+	WorkoutReminder     *WorkoutReminderWorker
+	ProfileView         *ProfileViewWorker
+	ClientImport        *ClientImportWorker
 }
 
 // InitializeWorkers initializes all background workers
@@ -19,11 +32,22 @@ func InitializeWorkers(
 	cfg config.Environment,
 	repos *repositories.RepositoriesCollection,
 	integrations *external.Collection,
+	svc *services.ServicesCollection,
 ) (*WorkersCollection, error) {
-	dispatcher := events.NewDispatcher()
+	dispatcher, err := events.NewDispatcher()
+	if err != nil {
+		return nil, err
+	}
 	if err := events.RegisterDefaultHandlers(dispatcher, repos, integrations); err != nil {
 		return nil, err
 	}
+	// RevenueCat webhooks are applied by SubscriptionService, which events can't import
+	if svc != nil && svc.Subscription != nil {
+		handler := events.HandlerFunc(svc.Subscription.ProcessRevenueCatWebhook)
+		if err := dispatcher.Register(events.EventTypeSubscriptionWebhook, handler); err != nil {
+			return nil, err
+		}
+	}
 
 	outboxWorker := NewOutboxWorker(repos.Outbox, dispatcher, OutboxWorkerConfig{
 		PollInterval: time.Duration(cfg.OutboxPollIntervalSeconds) * time.Second,
@@ -32,8 +56,96 @@ func InitializeWorkers(
 		StuckAfter:   time.Duration(cfg.OutboxStuckThresholdSeconds) * time.Second,
 	})
 
+	var digestWorker *DigestWorker
+	if cfg.DigestWorkerEnabled && svc != nil && svc.Digest != nil {
+		digestWorker = NewDigestWorker(svc.Digest, time.Duration(cfg.DigestPollIntervalMinutes)*time.Minute)
+	}
+
+	var atRiskWorker *AtRiskWorker
+	if cfg.AtRiskWorkerEnabled && svc != nil && svc.ClientActivity != nil {
+		atRiskWorker = NewAtRiskWorker(svc.ClientActivity, time.Duration(cfg.AtRiskPollIntervalMinutes)*time.Minute)
+	}
+
+	var scheduledMessageWorker *ScheduledMessageWorker
+	if cfg.ScheduledMessageWorkerEnabled && svc != nil && svc.Message != nil {
+		scheduledMessageWorker = NewScheduledMessageWorker(svc.Message, time.Duration(cfg.ScheduledMessagePollIntervalSeconds)*time.Second)
+	}
+
+	var requestAnalyticsWorker *RequestAnalyticsWorker
+	if svc != nil && svc.RequestAnalytics.Enabled() {
+		requestAnalyticsWorker = NewRequestAnalyticsWorker(svc.RequestAnalytics, time.Duration(cfg.RequestAnalyticsFlushIntervalSeconds)*time.Second)
+	}
+
+	var maintenanceWorker *MaintenanceWorker
+	if cfg.MaintenanceWorkerEnabled && svc != nil && svc.RequestAnalytics != nil && svc.Subscription != nil {
+		tasks := []MaintenanceTask{
+			{Name: "request_events", Run: svc.RequestAnalytics.PruneExpired},
+			{Name: "webhook_inbox", Run: svc.Subscription.PruneWebhookInbox},
+		}
+		if svc.Notification != nil {
+			tasks = append(tasks,
+				MaintenanceTask{Name: "push_receipts", Run: svc.Notification.CheckPushReceipts},
+				MaintenanceTask{Name: "stale_device_tokens", Run: svc.Notification.DeactivateStaleDeviceTokens},
+			)
+		}
+		if svc.Message != nil {
+			tasks = append(tasks, MaintenanceTask{Name: "message_retention", Run: svc.Message.ApplyMessageRetention})
+		}
+		maintenanceWorker = NewMaintenanceWorker(tasks, time.Duration(cfg.MaintenancePollIntervalMinutes)*time.Minute)
+	}
+
+	var sessionConfirmationWorker *SessionConfirmationWorker
+	if cfg.SessionConfirmationWorkerEnabled && svc != nil && svc.Session != nil {
+		sessionConfirmationWorker = NewSessionConfirmationWorker(
+			svc.Session,
+			time.Duration(cfg.SessionConfirmationPollIntervalMinutes)*time.Minute,
+			time.Duration(cfg.SessionConfirmationWindowHours)*time.Hour,
+		)
+	}
+
+	var staleSessionWorker *StaleSessionWorker
+	if cfg.StaleSessionWorkerEnabled && svc != nil && svc.Session != nil {
+		staleSessionWorker = NewStaleSessionWorker(
+			svc.Session,
+			time.Duration(cfg.StaleSessionPollIntervalMinutes)*time.Minute,
+			time.Duration(cfg.StaleSessionGraceHours)*time.Hour,
+		)
+	}
+
+	var coachStatsWorker *CoachStatsWorker
+	if cfg.CoachStatsWorkerEnabled && svc != nil && svc.Admin != nil {
+		coachStatsWorker = NewCoachStatsWorker(svc.Admin, cfg.CoachStatsWeekday, cfg.CoachStatsHourUTC)
+	}
+
+	var workoutReminderWorker *WorkoutReminderWorker
+	if cfg.WorkoutReminderWorkerEnabled && svc != nil && svc.WorkoutReminder != nil {
+		workoutReminderWorker = NewWorkoutReminderWorker(svc.WorkoutReminder)
+	}
+
+	var profileViewWorker *ProfileViewWorker
+	if cfg.ProfileViewWorkerEnabled && svc != nil && svc.Coach != nil {
+		profileViewWorker = NewProfileViewWorker(svc.Coach, time.Duration(cfg.ProfileViewFlushIntervalMinutes)*time.Minute)
+	}
+
+	var clientImportWorker *ClientImportWorker
+	if cfg.ClientImportWorkerEnabled && svc != nil && svc.Coach != nil {
+		clientImportWorker = NewClientImportWorker(svc.Coach, time.Duration(cfg.ClientImportPollIntervalSeconds)*time.Second)
+	}
+
 	return &WorkersCollection{
-		Outbox: outboxWorker,
+		Outbox:           outboxWorker,
+		Digest:           digestWorker,
+		AtRisk:           atRiskWorker,
+		ScheduledMessage: scheduledMessageWorker,
+		RequestAnalytics: requestAnalyticsWorker,
+		Maintenance:      maintenanceWorker,
+
+		SessionConfirmation: sessionConfirmationWorker,
+		StaleSession:        staleSessionWorker,
+		CoachStats:          coachStatsWorker,
+		WorkoutReminder:     workoutReminderWorker,
+		ProfileView:         profileViewWorker,
+		ClientImport:        clientImportWorker,
 	}, nil
 }
 
@@ -43,11 +155,77 @@ func (w *WorkersCollection) StartAll(cfg config.Environment) {
 	if w.Outbox != nil {
 		w.Outbox.Start()
 	}
+	if w.Digest != nil {
+		w.Digest.Start()
+	}
+	if w.AtRisk != nil {
+		w.AtRisk.Start()
+	}
+	if w.ScheduledMessage != nil {
+		w.ScheduledMessage.Start()
+	}
+	if w.RequestAnalytics != nil {
+		w.RequestAnalytics.Start()
+	}
+	if w.Maintenance != nil {
+		w.Maintenance.Start()
+	}
+	if w.SessionConfirmation != nil {
+		w.SessionConfirmation.Start()
+	}
+	if w.StaleSession != nil {
+		w.StaleSession.Start()
+	}
+	if w.CoachStats != nil {
+		w.CoachStats.Start()
+	}
+	if w.WorkoutReminder != nil {
+		w.WorkoutReminder.Start()
+	}
+	if w.ProfileView != nil {
+		w.ProfileView.Start()
+	}
+	if w.ClientImport != nil {
+		w.ClientImport.Start()
+	}
 }
 
 // StopAll stops all background workers
 func (w *WorkersCollection) StopAll() {
 	slog.Info("Stopping all workers...")
+	if w.ClientImport != nil {
+		w.ClientImport.Stop()
+	}
+	if w.ProfileView != nil {
+		w.ProfileView.Stop()
+	}
+	if w.WorkoutReminder != nil {
+		w.WorkoutReminder.Stop()
+	}
+	if w.CoachStats != nil {
+		w.CoachStats.Stop()
+	}
+	if w.StaleSession != nil {
+		w.StaleSession.Stop()
+	}
+	if w.SessionConfirmation != nil {
+		w.SessionConfirmation.Stop()
+	}
+	if w.Maintenance != nil {
+		w.Maintenance.Stop()
+	}
+	if w.RequestAnalytics != nil {
+		w.RequestAnalytics.Stop()
+	}
+	if w.ScheduledMessage != nil {
+		w.ScheduledMessage.Stop()
+	}
+	if w.AtRisk != nil {
+		w.AtRisk.Stop()
+	}
+	if w.Digest != nil {
+		w.Digest.Stop()
+	}
 	if w.Outbox != nil {
 		w.Outbox.Stop()
 	}
@@ -0,0 +1,89 @@
+package workers
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// MaintenanceTask deletes expired rows and returns how many were removed.
+type MaintenanceTask struct {
+	Name string
+	Run  func(ctx context.Context, now time.Time) (int64, error)
+}
+
+// MaintenanceWorker periodically runs retention and cleanup tasks. A failing task is logged and
+// does not stop the others.
+type MaintenanceWorker struct {
+	tasks    []MaintenanceTask
+	interval time.Duration
+
+	stopCh    chan struct{}
+	doneCh    chan struct{}
+	startOnce sync.Once
+	stopOnce  sync.Once
+}
+
+func NewMaintenanceWorker(tasks []MaintenanceTask, interval time.Duration) *MaintenanceWorker {
+	if interval <= 0 {
+		interval = time.Hour
+	}
+
+	return &MaintenanceWorker{
+		tasks:    tasks,
+		interval: interval,
+		stopCh:   make(chan struct{}),
+		doneCh:   make(chan struct{}),
+	}
+}
+
+func (w *MaintenanceWorker) Start() {
+	w.startOnce.Do(func() {
+		go w.loop()
+		slog.Info("Maintenance worker started", "interval", w.interval.String(), "tasks", len(w.tasks))
+	})
+}
+
+func (w *MaintenanceWorker) Stop() {
+	w.stopOnce.Do(func() {
+		close(w.stopCh)
+		<-w.doneCh
+		slog.Info("Maintenance worker stopped")
+	})
+}
+
+func (w *MaintenanceWorker) loop() {
+	defer close(w.doneCh)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	w.runCycle()
+
+	for {
+		select {
+		case <-w.stopCh:
+			return
+		case <-ticker.C:
+			w.runCycle()
+		}
+	}
+}
+
+func (w *MaintenanceWorker) runCycle() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	now := time.Now().UTC()
+	for _, task := range w.tasks {
+		removed, err := task.Run(ctx, now)
+		if err != nil {
+			slog.Error("Maintenance task failed", "task", task.Name, "error", err)
+			continue
+		}
+		if removed > 0 {
+			slog.Info("Maintenance task removed rows", "task", task.Name, "count", removed)
+		}
+	}
+}
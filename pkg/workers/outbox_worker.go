@@ -4,10 +4,14 @@ import (
 	"chalk-api/pkg/events"
 	"chalk-api/pkg/models"
 	"chalk-api/pkg/repositories"
+	"chalk-api/pkg/tracing"
 	"context"
 	"log/slog"
 	"sync"
 	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type OutboxWorkerConfig struct {
@@ -117,7 +121,9 @@ func (w *OutboxWorker) runCycle() {
 }
 
 func (w *OutboxWorker) processEvent(ctx context.Context, eventRecord models.OutboxEvent) {
+	ctx, span := startOutboxSpan(ctx, eventRecord)
 	err := w.dispatcher.Dispatch(ctx, eventRecord)
+	defer tracing.End(span, err)
 	if err == nil {
 		if markErr := w.repo.MarkProcessed(ctx, eventRecord.ID); markErr != nil {
 			slog.Error("Outbox worker failed to mark event processed", "event_id", eventRecord.ID, "error", markErr)
@@ -181,3 +187,24 @@ func truncateError(message string, maxLen int) string {
 	}
 	return message[:maxLen]
 }
+
+// startOutboxSpan starts the span for processing one event. Processing can run long after the
+// publishing request finished, so the span starts its own trace and links to the request's span
+// from the trace context stored in the event's metadata.
+func startOutboxSpan(ctx context.Context, event models.OutboxEvent) (context.Context, trace.Span) {
+	opts := []trace.SpanStartOption{
+		trace.WithNewRoot(),
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			attribute.Int64("outbox.event_id", int64(event.ID)),
+			attribute.String("outbox.event_type", event.EventType),
+			attribute.String("outbox.aggregate_type", event.AggregateType),
+			attribute.String("outbox.aggregate_id", event.AggregateID),
+			attribute.Int("outbox.attempt", event.Attempts+1),
+		),
+	}
+	if publisher := tracing.Extract(event.Metadata); publisher.IsValid() {
+		opts = append(opts, trace.WithLinks(trace.Link{SpanContext: publisher}))
+	}
+	return tracing.Tracer().Start(ctx, "outbox.process "+event.EventType, opts...)
+}
@@ -0,0 +1,81 @@
+package workers
+
+import (
+	"chalk-api/pkg/services"
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// ProfileViewWorker periodically persists the public profile view counters kept in Redis so view
+// history survives Redis restarts and key expiry.
+type ProfileViewWorker struct {
+	coachService *services.CoachService
+	interval     time.Duration
+
+	stopCh    chan struct{}
+	doneCh    chan struct{}
+	startOnce sync.Once
+	stopOnce  sync.Once
+}
+
+func NewProfileViewWorker(coachService *services.CoachService, interval time.Duration) *ProfileViewWorker {
+	if interval <= 0 {
+		interval = time.Hour
+	}
+
+	return &ProfileViewWorker{
+		coachService: coachService,
+		interval:     interval,
+		stopCh:       make(chan struct{}),
+		doneCh:       make(chan struct{}),
+	}
+}
+
+func (w *ProfileViewWorker) Start() {
+	w.startOnce.Do(func() {
+		go w.loop()
+		slog.Info("Profile view worker started", "interval", w.interval.String())
+	})
+}
+
+func (w *ProfileViewWorker) Stop() {
+	w.stopOnce.Do(func() {
+		close(w.stopCh)
+		<-w.doneCh
+		slog.Info("Profile view worker stopped")
+	})
+}
+
+func (w *ProfileViewWorker) loop() {
+	defer close(w.doneCh)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	w.runCycle()
+
+	for {
+		select {
+		case <-w.stopCh:
+			return
+		case <-ticker.C:
+			w.runCycle()
+		}
+	}
+}
+
+func (w *ProfileViewWorker) runCycle() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	written, err := w.coachService.FlushProfileViews(ctx, time.Now().UTC())
+	if err != nil {
+		slog.Error("Profile view worker failed", "error", err, "written", written)
+		return
+	}
+	if written > 0 {
+		slog.Info("Profile views flushed", "coach_days", written)
+	}
+}
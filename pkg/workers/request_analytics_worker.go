@@ -0,0 +1,75 @@
+package workers
+
+import (
+	"chalk-api/pkg/services"
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// RequestAnalyticsWorker flushes the request analytics buffer to the database in batches.
+type RequestAnalyticsWorker struct {
+	analytics *services.RequestAnalytics
+	interval  time.Duration
+
+	stopCh    chan struct{}
+	doneCh    chan struct{}
+	startOnce sync.Once
+	stopOnce  sync.Once
+}
+
+func NewRequestAnalyticsWorker(analytics *services.RequestAnalytics, interval time.Duration) *RequestAnalyticsWorker {
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+
+	return &RequestAnalyticsWorker{
+		analytics: analytics,
+		interval:  interval,
+		stopCh:    make(chan struct{}),
+		doneCh:    make(chan struct{}),
+	}
+}
+
+func (w *RequestAnalyticsWorker) Start() {
+	w.startOnce.Do(func() {
+		go w.loop()
+		slog.Info("Request analytics worker started", "interval", w.interval.String())
+	})
+}
+
+func (w *RequestAnalyticsWorker) Stop() {
+	w.stopOnce.Do(func() {
+		close(w.stopCh)
+		<-w.doneCh
+		slog.Info("Request analytics worker stopped")
+	})
+}
+
+func (w *RequestAnalyticsWorker) loop() {
+	defer close(w.doneCh)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.stopCh:
+			// Write what is still buffered so a deploy doesn't lose the last few seconds
+			w.runCycle()
+			return
+		case <-ticker.C:
+			w.runCycle()
+		}
+	}
+}
+
+func (w *RequestAnalyticsWorker) runCycle() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if _, err := w.analytics.Flush(ctx); err != nil {
+		slog.Error("Request analytics worker failed to flush events", "error", err)
+	}
+}
@@ -0,0 +1,85 @@
+package workers
+
+import (
+	"chalk-api/pkg/services"
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+const scheduledMessageBatchSize = 50
+
+// ScheduledMessageWorker delivers coach messages whose scheduled time has arrived.
+type ScheduledMessageWorker struct {
+	messageService *services.MessageService
+	interval       time.Duration
+
+	stopCh    chan struct{}
+	doneCh    chan struct{}
+	startOnce sync.Once
+	stopOnce  sync.Once
+}
+
+func NewScheduledMessageWorker(messageService *services.MessageService, interval time.Duration) *ScheduledMessageWorker {
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+
+	return &ScheduledMessageWorker{
+		messageService: messageService,
+		interval:       interval,
+		stopCh:         make(chan struct{}),
+		doneCh:         make(chan struct{}),
+	}
+}
+
+func (w *ScheduledMessageWorker) Start() {
+	w.startOnce.Do(func() {
+		go w.loop()
+		slog.Info("Scheduled message worker started", "interval", w.interval.String())
+	})
+}
+
+func (w *ScheduledMessageWorker) Stop() {
+	w.stopOnce.Do(func() {
+		close(w.stopCh)
+		<-w.doneCh
+		slog.Info("Scheduled message worker stopped")
+	})
+}
+
+func (w *ScheduledMessageWorker) loop() {
+	defer close(w.doneCh)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	w.runCycle()
+
+	for {
+		select {
+		case <-w.stopCh:
+			return
+		case <-ticker.C:
+			w.runCycle()
+		}
+	}
+}
+
+func (w *ScheduledMessageWorker) runCycle() {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	result, err := w.messageService.DeliverDueScheduledMessages(ctx, time.Now().UTC(), scheduledMessageBatchSize)
+	if err != nil {
+		slog.Error("Scheduled message worker failed to deliver messages", "error", err)
+		return
+	}
+	if result.Requeued > 0 {
+		slog.Warn("Requeued stuck scheduled messages", "count", result.Requeued)
+	}
+	if result.Sent > 0 || result.Retried > 0 || result.Failed > 0 {
+		slog.Info("Scheduled messages processed", "sent", result.Sent, "retried", result.Retried, "failed", result.Failed)
+	}
+}
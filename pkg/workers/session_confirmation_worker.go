@@ -0,0 +1,86 @@
+package workers
+
+import (
+	"chalk-api/pkg/services"
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// SessionConfirmationWorker periodically auto-declines pending bookings the coach never confirmed.
+type SessionConfirmationWorker struct {
+	sessionService *services.SessionService
+	interval       time.Duration
+	window         time.Duration
+
+	stopCh    chan struct{}
+	doneCh    chan struct{}
+	startOnce sync.Once
+	stopOnce  sync.Once
+}
+
+func NewSessionConfirmationWorker(sessionService *services.SessionService, interval, window time.Duration) *SessionConfirmationWorker {
+	if interval <= 0 {
+		interval = 15 * time.Minute
+	}
+	if window <= 0 {
+		window = 48 * time.Hour
+	}
+
+	return &SessionConfirmationWorker{
+		sessionService: sessionService,
+		interval:       interval,
+		window:         window,
+		stopCh:         make(chan struct{}),
+		doneCh:         make(chan struct{}),
+	}
+}
+
+func (w *SessionConfirmationWorker) Start() {
+	w.startOnce.Do(func() {
+		go w.loop()
+		slog.Info("Session confirmation worker started", "interval", w.interval.String(), "window", w.window.String())
+	})
+}
+
+func (w *SessionConfirmationWorker) Stop() {
+	w.stopOnce.Do(func() {
+		close(w.stopCh)
+		<-w.doneCh
+		slog.Info("Session confirmation worker stopped")
+	})
+}
+
+func (w *SessionConfirmationWorker) loop() {
+	defer close(w.doneCh)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	w.runCycle()
+
+	for {
+		select {
+		case <-w.stopCh:
+			return
+		case <-ticker.C:
+			w.runCycle()
+		}
+	}
+}
+
+func (w *SessionConfirmationWorker) runCycle() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	now := time.Now().UTC()
+	declined, err := w.sessionService.DeclineExpiredPendingSessions(ctx, now.Add(-w.window), now)
+	if err != nil {
+		slog.Error("Session confirmation worker failed to decline expired bookings", "error", err, "declined", declined)
+		return
+	}
+	if declined > 0 {
+		slog.Info("Expired pending sessions declined", "declined", declined)
+	}
+}
@@ -0,0 +1,87 @@
+package workers
+
+import (
+	"chalk-api/pkg/services"
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// StaleSessionWorker periodically resolves scheduled sessions that ended without the coach recording
+// an outcome, following each coach's stale session setting.
+type StaleSessionWorker struct {
+	sessionService *services.SessionService
+	interval       time.Duration
+	grace          time.Duration
+
+	stopCh    chan struct{}
+	doneCh    chan struct{}
+	startOnce sync.Once
+	stopOnce  sync.Once
+}
+
+func NewStaleSessionWorker(sessionService *services.SessionService, interval, grace time.Duration) *StaleSessionWorker {
+	if interval <= 0 {
+		interval = 30 * time.Minute
+	}
+	if grace <= 0 {
+		grace = 12 * time.Hour
+	}
+
+	return &StaleSessionWorker{
+		sessionService: sessionService,
+		interval:       interval,
+		grace:          grace,
+		stopCh:         make(chan struct{}),
+		doneCh:         make(chan struct{}),
+	}
+}
+
+func (w *StaleSessionWorker) Start() {
+	w.startOnce.Do(func() {
+		go w.loop()
+		slog.Info("Stale session worker started", "interval", w.interval.String(), "grace", w.grace.String())
+	})
+}
+
+func (w *StaleSessionWorker) Stop() {
+	w.stopOnce.Do(func() {
+		close(w.stopCh)
+		<-w.doneCh
+		slog.Info("Stale session worker stopped")
+	})
+}
+
+func (w *StaleSessionWorker) loop() {
+	defer close(w.doneCh)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	w.runCycle()
+
+	for {
+		select {
+		case <-w.stopCh:
+			return
+		case <-ticker.C:
+			w.runCycle()
+		}
+	}
+}
+
+func (w *StaleSessionWorker) runCycle() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	result, err := w.sessionService.SweepStaleSessions(ctx, time.Now().UTC().Add(-w.grace))
+	if err != nil {
+		slog.Error("Stale session worker failed", "error", err,
+			"completed", result.Completed, "no_show", result.NoShow, "prompted", result.Prompted)
+		return
+	}
+	if result.Completed > 0 || result.NoShow > 0 || result.Prompted > 0 {
+		slog.Info("Stale sessions swept", "completed", result.Completed, "no_show", result.NoShow, "prompted", result.Prompted)
+	}
+}
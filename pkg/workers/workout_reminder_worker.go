@@ -0,0 +1,76 @@
+package workers
+
+import (
+	"chalk-api/pkg/services"
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// WorkoutReminderWorker sends scheduled-date workout reminders. Instead of polling every minute it
+// sleeps until the next quarter-hour boundary, when the timezones starting a new local hour are
+// due; the first run after startup looks at every timezone to catch up on anything a deploy delayed.
+type WorkoutReminderWorker struct {
+	reminderService *services.WorkoutReminderService
+
+	stopCh    chan struct{}
+	doneCh    chan struct{}
+	startOnce sync.Once
+	stopOnce  sync.Once
+}
+
+func NewWorkoutReminderWorker(reminderService *services.WorkoutReminderService) *WorkoutReminderWorker {
+	return &WorkoutReminderWorker{
+		reminderService: reminderService,
+		stopCh:          make(chan struct{}),
+		doneCh:          make(chan struct{}),
+	}
+}
+
+func (w *WorkoutReminderWorker) Start() {
+	w.startOnce.Do(func() {
+		go w.loop()
+		slog.Info("Workout reminder worker started", "slot", services.WorkoutReminderSlot.String())
+	})
+}
+
+func (w *WorkoutReminderWorker) Stop() {
+	w.stopOnce.Do(func() {
+		close(w.stopCh)
+		<-w.doneCh
+		slog.Info("Workout reminder worker stopped")
+	})
+}
+
+func (w *WorkoutReminderWorker) loop() {
+	defer close(w.doneCh)
+
+	w.runCycle(time.Now().UTC(), true)
+
+	for {
+		now := time.Now().UTC()
+		timer := time.NewTimer(now.Truncate(services.WorkoutReminderSlot).Add(services.WorkoutReminderSlot).Sub(now))
+		select {
+		case <-w.stopCh:
+			timer.Stop()
+			return
+		case fired := <-timer.C:
+			w.runCycle(fired.UTC(), false)
+		}
+	}
+}
+
+func (w *WorkoutReminderWorker) runCycle(now time.Time, catchUp bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	sent, err := w.reminderService.SendDueReminders(ctx, now, catchUp)
+	if err != nil {
+		slog.Error("Workout reminder worker failed", "error", err)
+		return
+	}
+	if sent > 0 {
+		slog.Info("Workout reminders sent", "count", sent)
+	}
+}